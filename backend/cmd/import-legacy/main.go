@@ -0,0 +1,452 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/services"
+	"incident-management-system/internal/storage"
+)
+
+// reportErrorLimit caps the row errors listed per file in the migration report
+const reportErrorLimit = 20
+
+// progressInterval is the shortest time between two insert progress lines of a file
+const progressInterval = 2 * time.Second
+
+// importOptions holds the flags every file is read with unless its manifest rule overrides them
+type importOptions struct {
+	mappingProfile string
+	ruleSet        string
+	timezone       string
+	delimiter      rune
+	errorThreshold float64
+	dedupStrategy  string
+	skipAnalyzers  bool
+	dryRun         bool
+	reimport       bool
+	stopOnError    bool
+}
+
+// fileReport is the outcome of importing one export
+type fileReport struct {
+	File            string         `json:"file"`
+	Pattern         string         `json:"pattern,omitempty"`
+	MappingProfile  string         `json:"mapping_profile,omitempty"`
+	UploadID        string         `json:"upload_id,omitempty"`
+	Status          string         `json:"status"`
+	TotalRows       int            `json:"total_rows"`
+	ValidRows       int            `json:"valid_rows"`
+	InsertedRows    int            `json:"inserted_rows"`
+	ErrorCount      int            `json:"error_count"`
+	Errors          []string       `json:"errors,omitempty"`
+	FirstReported   string         `json:"first_reported,omitempty"`
+	LastReported    string         `json:"last_reported,omitempty"`
+	IncidentsByYear map[string]int `json:"incidents_by_year,omitempty"`
+	Duration        string         `json:"duration"`
+}
+
+// migrationReport summarizes a bulk import
+type migrationReport struct {
+	Directory       string         `json:"directory"`
+	StartedAt       time.Time      `json:"started_at"`
+	FinishedAt      time.Time      `json:"finished_at"`
+	Duration        string         `json:"duration"`
+	DryRun          bool           `json:"dry_run"`
+	SkipAnalyzers   bool           `json:"skip_analyzers"`
+	Files           int            `json:"files"`
+	Imported        int            `json:"imported"`
+	Skipped         int            `json:"skipped"`
+	Failed          int            `json:"failed"`
+	TotalRows       int            `json:"total_rows"`
+	InsertedRows    int            `json:"inserted_rows"`
+	ErrorCount      int            `json:"error_count"`
+	IncidentsByYear map[string]int `json:"incidents_by_year"`
+	Results         []fileReport   `json:"results"`
+}
+
+// Import statuses of a file
+const (
+	statusImported = "imported"
+	statusSkipped  = "skipped"
+	statusFailed   = "failed"
+	statusDryRun   = "dry_run"
+)
+
+func main() {
+	var (
+		dbPath         = flag.String("db", "incident_management.db", "Database file path")
+		uploadDir      = flag.String("uploads", "uploads", "Upload directory of the server the files are copied to")
+		dir            = flag.String("dir", "", "Directory of legacy exports to import (required)")
+		manifestPath   = flag.String("manifest", "", "JSON manifest of mapping profiles and per-file rules")
+		profile        = flag.String("profile", "", "Mapping profile of files without a manifest rule")
+		ruleSet        = flag.String("rule-set", "", "Validation rule set rows are checked against")
+		timezone       = flag.String("timezone", "", "IANA time zone dates are stored in")
+		delimiter      = flag.String("delimiter", ",", "Field delimiter of CSV files")
+		errorThreshold = flag.Float64("error-threshold", 0, "Percentage of rows of a file allowed to fail parsing")
+		dedupStrategy  = flag.String("dedup", models.DedupStrategyFirst, "How incident IDs repeated within a file are resolved")
+		skipAnalyzers  = flag.Bool("skip-analyzers", false, "Skip sentiment and automation analysis")
+		dryRun         = flag.Bool("dry-run", false, "Parse and validate the files without storing incidents")
+		reimport       = flag.Bool("reimport", false, "Import files already imported by an earlier run")
+		stopOnError    = flag.Bool("stop-on-error", false, "Stop at the first file that fails")
+		reportPath     = flag.String("report", "migration-report.json", "File the migration report is written to")
+		help           = flag.Bool("help", false, "Show help")
+	)
+	flag.Parse()
+
+	if *help {
+		showHelp()
+		return
+	}
+	if *dir == "" {
+		fmt.Println("The -dir option is required")
+		showHelp()
+		os.Exit(1)
+	}
+	if utf8.RuneCountInString(*delimiter) != 1 {
+		log.Fatal("The -delimiter option must be a single character")
+	}
+
+	var m *manifest
+	if *manifestPath != "" {
+		var err error
+		if m, err = loadManifest(*manifestPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	files, err := findExports(*dir)
+	if err != nil {
+		log.Fatalf("Failed to list exports: %v", err)
+	}
+	if len(files) == 0 {
+		log.Fatalf("No .xlsx, .xls or .csv files found in %s", *dir)
+	}
+
+	db, err := database.NewDB(&database.Config{DatabasePath: *dbPath})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+	if err := db.InitializeDatabase(); err != nil {
+		log.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	// Interrupting stops the file being processed; its stored incidents are removed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if m != nil {
+		if err := m.saveProfiles(ctx, services.NewMappingProfileService(db.GetConnection())); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	processing := services.NewProcessingService(db.GetConnection(), storage.NewFileStore(*uploadDir))
+	processing.SetInsertProgress(newProgressPrinter().print)
+
+	defaults := importOptions{
+		mappingProfile: *profile,
+		ruleSet:        *ruleSet,
+		timezone:       *timezone,
+		delimiter:      []rune(*delimiter)[0],
+		errorThreshold: *errorThreshold,
+		dedupStrategy:  *dedupStrategy,
+		skipAnalyzers:  *skipAnalyzers,
+		dryRun:         *dryRun,
+		reimport:       *reimport,
+		stopOnError:    *stopOnError,
+	}
+	report := runImport(ctx, processing, db, *dir, files, m, defaults)
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to format report: %v", err)
+	}
+	if err := os.WriteFile(*reportPath, data, 0644); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	fmt.Printf("\n%d files: %d imported, %d skipped, %d failed\n", report.Files, report.Imported, report.Skipped, report.Failed)
+	fmt.Printf("%d rows read, %d incidents stored, %d errors in %s\n", report.TotalRows, report.InsertedRows, report.ErrorCount, report.Duration)
+	fmt.Printf("Report written to %s\n", *reportPath)
+	if report.Failed > 0 {
+		db.Close()
+		os.Exit(1)
+	}
+}
+
+// runImport imports the files in order and returns the migration report
+func runImport(ctx context.Context, processing *services.ProcessingService, db *database.DB, dir string, files []string, m *manifest, defaults importOptions) *migrationReport {
+	report := &migrationReport{
+		Directory:       dir,
+		StartedAt:       time.Now(),
+		DryRun:          defaults.dryRun,
+		SkipAnalyzers:   defaults.skipAnalyzers,
+		Files:           len(files),
+		IncidentsByYear: make(map[string]int),
+		Results:         []fileReport{},
+	}
+
+	for i, relPath := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		fmt.Printf("[%d/%d] %s\n", i+1, len(files), relPath)
+		result := importFile(ctx, processing, db, dir, relPath, m.ruleFor(relPath), defaults)
+		fmt.Printf("  %s: %d rows, %d stored, %d errors in %s\n",
+			result.Status, result.TotalRows, result.InsertedRows, result.ErrorCount, result.Duration)
+
+		report.Results = append(report.Results, result)
+		report.TotalRows += result.TotalRows
+		report.InsertedRows += result.InsertedRows
+		report.ErrorCount += result.ErrorCount
+		for year, count := range result.IncidentsByYear {
+			report.IncidentsByYear[year] += count
+		}
+		switch result.Status {
+		case statusImported, statusDryRun:
+			report.Imported++
+		case statusSkipped:
+			report.Skipped++
+		case statusFailed:
+			report.Failed++
+			if defaults.stopOnError {
+				fmt.Println("Stopping at the first failed file")
+				report.finish()
+				return report
+			}
+		}
+	}
+
+	report.finish()
+	return report
+}
+
+// importFile copies one export into the upload directory and processes it with the options of
+// its manifest rule
+func importFile(ctx context.Context, processing *services.ProcessingService, db *database.DB, dir, relPath string, rule *fileRule, defaults importOptions) fileReport {
+	start := time.Now()
+	options := processingOptions(rule, defaults)
+	result := fileReport{File: relPath, MappingProfile: options.MappingProfile}
+	if rule != nil {
+		result.Pattern = rule.Pattern
+	}
+	fail := func(err error) fileReport {
+		result.Status = statusFailed
+		result.Errors = append(result.Errors, err.Error())
+		result.Duration = time.Since(start).Round(time.Millisecond).String()
+		return result
+	}
+
+	if !defaults.reimport && !defaults.dryRun {
+		uploadID, err := processing.FindImportedUpload(ctx, relPath)
+		if err != nil {
+			return fail(err)
+		}
+		if uploadID != "" {
+			result.Status = statusSkipped
+			result.UploadID = uploadID
+			result.Duration = time.Since(start).Round(time.Millisecond).String()
+			return result
+		}
+	}
+
+	delimiter := defaults.delimiter
+	if rule != nil && rule.Delimiter != "" {
+		delimiter = []rune(rule.Delimiter)[0]
+	}
+	upload, err := processing.ImportLocalFile(ctx, filepath.Join(dir, filepath.FromSlash(relPath)), relPath, delimiter)
+	if err != nil {
+		return fail(err)
+	}
+	result.UploadID = upload.ID
+
+	progress, err := processing.ProcessUploadWithOptions(ctx, upload.ID, options)
+	if err != nil {
+		return fail(err)
+	}
+	result.Status = statusImported
+	if progress.DryRun {
+		result.Status = statusDryRun
+	} else if progress.Status == models.UploadStatusFailed || progress.Cancelled {
+		result.Status = statusFailed
+	}
+	result.TotalRows = progress.TotalRows
+	result.ValidRows = progress.ValidRows
+	result.InsertedRows = progress.ProcessedRows
+	result.ErrorCount = progress.ErrorCount
+	if len(progress.Errors) > reportErrorLimit {
+		result.Errors = progress.Errors[:reportErrorLimit]
+	} else if len(progress.Errors) > 0 {
+		result.Errors = progress.Errors
+	}
+
+	if result.InsertedRows > 0 {
+		if err := describeReportDates(ctx, db, &result); err != nil {
+			log.Printf("Warning: Failed to summarize report dates of %s: %v", relPath, err)
+		}
+	}
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	return result
+}
+
+// processingOptions combines a file's manifest rule with the defaults
+func processingOptions(rule *fileRule, defaults importOptions) models.ProcessingOptions {
+	options := models.ProcessingOptions{
+		MappingProfile: defaults.mappingProfile,
+		RuleSet:        defaults.ruleSet,
+		Timezone:       defaults.timezone,
+		DedupStrategy:  defaults.dedupStrategy,
+		ErrorThreshold: defaults.errorThreshold,
+		RunSentiment:   !defaults.skipAnalyzers,
+		RunAutomation:  !defaults.skipAnalyzers,
+		DryRun:         defaults.dryRun,
+	}
+	if rule == nil {
+		return options
+	}
+	if rule.MappingProfile != "" {
+		options.MappingProfile = rule.MappingProfile
+	}
+	if rule.RuleSet != "" {
+		options.RuleSet = rule.RuleSet
+	}
+	if rule.Timezone != "" {
+		options.Timezone = rule.Timezone
+	}
+	if rule.DedupStrategy != "" {
+		options.DedupStrategy = rule.DedupStrategy
+	}
+	if rule.ErrorThreshold != nil {
+		options.ErrorThreshold = *rule.ErrorThreshold
+	}
+	options.Sheet = rule.Sheet
+	return options
+}
+
+// describeReportDates records the report date range and the incidents per year of the file's upload
+func describeReportDates(ctx context.Context, db *database.DB, result *fileReport) error {
+	rows, err := db.GetConnection().QueryContext(ctx, `
+		SELECT CAST(YEAR(report_date) AS VARCHAR), COUNT(*), MIN(report_date), MAX(report_date)
+		FROM incidents
+		WHERE upload_id = ?
+		GROUP BY 1
+		ORDER BY 1
+	`, result.UploadID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	result.IncidentsByYear = make(map[string]int)
+	for rows.Next() {
+		var year string
+		var count int
+		var first, last time.Time
+		if err := rows.Scan(&year, &count, &first, &last); err != nil {
+			return err
+		}
+		result.IncidentsByYear[year] = count
+		if result.FirstReported == "" {
+			result.FirstReported = first.Format("2006-01-02")
+		}
+		result.LastReported = last.Format("2006-01-02")
+	}
+	return rows.Err()
+}
+
+// finish records when the import ended
+func (r *migrationReport) finish() {
+	r.FinishedAt = time.Now()
+	r.Duration = r.FinishedAt.Sub(r.StartedAt).Round(time.Millisecond).String()
+}
+
+// findExports returns the slash-separated paths, relative to dir, of the workbooks and CSV files
+// under it in lexical order, so yearly exports are imported oldest first when named by year
+func findExports(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || strings.HasPrefix(entry.Name(), "~$") {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".xlsx", ".xls", ".csv":
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// progressPrinter prints the insert progress of the file being imported, at most every
+// progressInterval and always when it completes
+type progressPrinter struct {
+	mu      sync.Mutex
+	printed time.Time
+}
+
+func newProgressPrinter() *progressPrinter {
+	return &progressPrinter{}
+}
+
+func (p *progressPrinter) print(uploadID string, inserted, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	if inserted < total && now.Sub(p.printed) < progressInterval {
+		return
+	}
+	p.printed = now
+	fmt.Printf("  stored %d of %d incidents (%.0f%%)\n", inserted, total, float64(inserted)/float64(total)*100)
+}
+
+func showHelp() {
+	fmt.Println("Legacy Data Import Tool")
+	fmt.Println()
+	fmt.Println("Loads a directory of historical incident exports straight into the database, without")
+	fmt.Println("going through the HTTP API. Each file becomes an upload processed like any other, and")
+	fmt.Println("a JSON migration report lists the outcome of every file.")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  import-legacy -dir <directory> [options]")
+	fmt.Println()
+	fmt.Println("Options:")
+	flag.PrintDefaults()
+	fmt.Println()
+	fmt.Println("Manifest:")
+	fmt.Println(`  {`)
+	fmt.Println(`    "profiles": {"legacy-2019": {"columns": {"incident_id": ["Ticket Ref"]}, "date_formats": {"report_date": "DD/MM/YYYY"}}},`)
+	fmt.Println(`    "files": [{"pattern": "2019/*.csv", "mapping_profile": "legacy-2019", "delimiter": ";", "timezone": "Europe/Berlin"}]`)
+	fmt.Println(`  }`)
+	fmt.Println()
+	fmt.Println("  Profiles are saved as mapping profiles before the import. Each file is read with the first")
+	fmt.Println("  rule whose pattern matches its path relative to -dir or its name; other files use the flags.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  import-legacy -dir=./exports -manifest=./exports/manifest.json -skip-analyzers")
+	fmt.Println("  import-legacy -dir=./exports -profile=legacy -dry-run")
+}
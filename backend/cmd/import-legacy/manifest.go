@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"unicode/utf8"
+
+	"incident-management-system/internal/services"
+)
+
+// manifest describes how the exports of a legacy system are read. Profiles are saved as mapping
+// profiles before the import starts; each file is read with the first rule whose pattern matches
+// its path relative to the import directory, or its base name.
+type manifest struct {
+	Profiles map[string]manifestProfile `json:"profiles"`
+	Files    []fileRule                 `json:"files"`
+}
+
+// manifestProfile is a mapping profile defined in the manifest
+type manifestProfile struct {
+	Description     string              `json:"description"`
+	Columns         map[string][]string `json:"columns"`
+	DateFormats     map[string]string   `json:"date_formats"`
+	PendingStatuses []string            `json:"pending_statuses"`
+}
+
+// fileRule sets how the files matching Pattern are read. Empty fields fall back to the flags.
+type fileRule struct {
+	Pattern        string   `json:"pattern"`
+	MappingProfile string   `json:"mapping_profile"`
+	RuleSet        string   `json:"rule_set"`
+	Timezone       string   `json:"timezone"`
+	Sheet          string   `json:"sheet"`
+	Delimiter      string   `json:"delimiter"`
+	ErrorThreshold *float64 `json:"error_threshold"`
+	DedupStrategy  string   `json:"dedup_strategy"`
+}
+
+// loadManifest reads and checks a manifest file
+func loadManifest(filename string) (*manifest, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	for i, rule := range m.Files {
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("manifest file rule %d has no pattern", i+1)
+		}
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("manifest file rule %d has an invalid pattern %q: %w", i+1, rule.Pattern, err)
+		}
+		if rule.Delimiter != "" && utf8.RuneCountInString(rule.Delimiter) != 1 {
+			return nil, fmt.Errorf("manifest file rule %d: delimiter must be a single character", i+1)
+		}
+	}
+	return &m, nil
+}
+
+// saveProfiles stores the manifest's mapping profiles, replacing profiles of the same name
+func (m *manifest) saveProfiles(ctx context.Context, profiles *services.MappingProfileService) error {
+	for name, profile := range m.Profiles {
+		if _, err := profiles.SaveProfile(ctx, name, profile.Description, profile.Columns,
+			profile.DateFormats, profile.PendingStatuses); err != nil {
+			return fmt.Errorf("failed to save mapping profile %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ruleFor returns the first rule matching a file's slash-separated relative path or base name,
+// or nil when none does
+func (m *manifest) ruleFor(relPath string) *fileRule {
+	if m == nil {
+		return nil
+	}
+	for i := range m.Files {
+		rule := &m.Files[i]
+		if matched, _ := path.Match(rule.Pattern, relPath); matched {
+			return rule
+		}
+		if matched, _ := path.Match(rule.Pattern, path.Base(relPath)); matched {
+			return rule
+		}
+	}
+	return nil
+}
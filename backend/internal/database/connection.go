@@ -8,15 +8,18 @@ import (
 	"sync"
 	"time"
 
-	_ "github.com/marcboeker/go-duckdb"
+	"github.com/marcboeker/go-duckdb"
 )
 
 // DB represents the database connection pool
 type DB struct {
-	conn     *sql.DB
-	mu       sync.RWMutex
-	isReady  bool
-	dbPath   string
+	conn    *sql.DB
+	mu      sync.RWMutex
+	isReady bool
+	dbPath  string
+
+	// slowQueries records the statements run past the slow query threshold
+	slowQueries *SlowQueryLog
 }
 
 // Config holds database configuration
@@ -26,16 +29,20 @@ type Config struct {
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
 	ConnMaxIdleTime time.Duration
+	// SlowQueryThreshold is how long a statement runs before the slow query log records it;
+	// zero records nothing
+	SlowQueryThreshold time.Duration
 }
 
 // DefaultConfig returns default database configuration
 func DefaultConfig() *Config {
 	return &Config{
-		DatabasePath:    "./data/incidents.db",
-		MaxOpenConns:    25,
-		MaxIdleConns:    5,
-		ConnMaxLifetime: time.Hour,
-		ConnMaxIdleTime: time.Minute * 10,
+		DatabasePath:       "./data/incidents.db",
+		MaxOpenConns:       25,
+		MaxIdleConns:       5,
+		ConnMaxLifetime:    time.Hour,
+		ConnMaxIdleTime:    time.Minute * 10,
+		SlowQueryThreshold: DefaultSlowQueryThreshold,
 	}
 }
 
@@ -46,7 +53,8 @@ func NewDB(config *Config) (*DB, error) {
 	}
 
 	db := &DB{
-		dbPath: config.DatabasePath,
+		dbPath:      config.DatabasePath,
+		slowQueries: NewSlowQueryLog(config.SlowQueryThreshold),
 	}
 
 	if err := db.connect(config); err != nil {
@@ -61,10 +69,11 @@ func (db *DB) connect(config *Config) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	conn, err := sql.Open("duckdb", config.DatabasePath)
+	connector, err := duckdb.Driver{}.OpenConnector(config.DatabasePath)
 	if err != nil {
 		return fmt.Errorf("failed to open database connection: %w", err)
 	}
+	conn := sql.OpenDB(&recordingConnector{Connector: connector, slowQueries: db.slowQueries})
 
 	// Configure connection pool
 	conn.SetMaxOpenConns(config.MaxOpenConns)
@@ -92,6 +101,11 @@ func (db *DB) GetConnection() *sql.DB {
 	return db.conn
 }
 
+// SlowQueries returns the log of statements run past the slow query threshold
+func (db *DB) SlowQueries() *SlowQueryLog {
+	return db.slowQueries
+}
+
 // IsReady returns true if the database connection is ready
 func (db *DB) IsReady() bool {
 	db.mu.RLock()
@@ -145,4 +159,4 @@ func (db *DB) Stats() sql.DBStats {
 	}
 
 	return db.conn.Stats()
-}
\ No newline at end of file
+}
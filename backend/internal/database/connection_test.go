@@ -119,4 +119,61 @@ func TestDatabaseReset(t *testing.T) {
 	if !exists {
 		t.Error("Schema should exist after reset")
 	}
-}
\ No newline at end of file
+}
+
+func TestUploadStatusCheckUpgrade(t *testing.T) {
+	db, err := NewDB(&Config{DatabasePath: ":memory:", MaxOpenConns: 1, MaxIdleConns: 1, ConnMaxLifetime: time.Minute})
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+	conn := db.GetConnection()
+
+	// An uploads table created before the queued and completed_with_errors statuses
+	_, err = conn.Exec(`
+		CREATE TABLE uploads (
+			id VARCHAR PRIMARY KEY,
+			filename VARCHAR NOT NULL,
+			original_filename VARCHAR NOT NULL,
+			status VARCHAR NOT NULL CHECK (status IN ('uploaded', 'processing', 'completed', 'failed')),
+			record_count INTEGER DEFAULT 0,
+			processed_count INTEGER DEFAULT 0,
+			error_count INTEGER DEFAULT 0,
+			errors TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			processed_at TIMESTAMP,
+			dataset_id VARCHAR
+		);
+		CREATE INDEX idx_uploads_created_at ON uploads(created_at);
+		INSERT INTO uploads (id, filename, original_filename, status, record_count, dataset_id)
+		VALUES ('old', 'old.xlsx', 'Old.xlsx', 'completed', 12, 'ds-1');
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create the original uploads table: %v", err)
+	}
+
+	if err := db.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	var status, datasetID string
+	var records int
+	if err := conn.QueryRow("SELECT status, record_count, dataset_id FROM uploads WHERE id = 'old'").Scan(&status, &records, &datasetID); err != nil {
+		t.Fatalf("Failed to read the existing upload: %v", err)
+	}
+	if status != "completed" || records != 12 || datasetID != "ds-1" {
+		t.Errorf("Expected the existing upload to be kept, got status=%s records=%d dataset=%s", status, records, datasetID)
+	}
+
+	if _, err := conn.Exec("UPDATE uploads SET status = 'completed_with_errors' WHERE id = 'old'"); err != nil {
+		t.Errorf("Expected the new statuses to be allowed: %v", err)
+	}
+	if _, err := conn.Exec("UPDATE uploads SET status = 'unknown' WHERE id = 'old'"); err == nil {
+		t.Error("Expected unknown statuses to be rejected")
+	}
+
+	// A second start leaves the upgraded table alone
+	if err := db.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database again: %v", err)
+	}
+}
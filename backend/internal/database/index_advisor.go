@@ -0,0 +1,296 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxIndexEqualityColumns caps the equality columns of a suggested composite index
+	maxIndexEqualityColumns = 3
+
+	// maxSuggestionExamples caps the slow statements listed with each suggestion
+	maxSuggestionExamples = 3
+)
+
+// IndexSuggestion is an index the slow query log suggests is missing
+type IndexSuggestion struct {
+	Name       string   `json:"name"`
+	Table      string   `json:"table"`
+	Columns    []string `json:"columns"`
+	Statement  string   `json:"statement"`
+	Queries    int      `json:"queries"` // distinct slow statements filtering on the columns
+	Executions int64    `json:"executions"`
+	TotalMs    float64  `json:"total_ms"`
+	Examples   []string `json:"examples"`
+}
+
+// SuggestIndexes reads the filter shapes of the slow query log and suggests an index for each
+// combination of columns slow statements filter a table on that no existing index leads with:
+// the equality columns, then at most one range column. Suggestions come most total slow time
+// first. An index speeds up selective filters; DuckDB scans wide ranges just as fast without
+// one, so check a suggestion against the statements it lists before applying it.
+func (db *DB) SuggestIndexes(ctx context.Context) ([]IndexSuggestion, error) {
+	conn := db.GetConnection()
+	if conn == nil {
+		return nil, ErrConnectionNotReady
+	}
+
+	columns, err := db.tableColumns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := db.indexedColumns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := map[string]*IndexSuggestion{}
+	for _, query := range db.slowQueries.Queries() {
+		for table, candidate := range candidateIndexes(query.Filters, columns) {
+			if isIndexed(indexes[table], candidate) {
+				continue
+			}
+			indexColumns := candidate.Columns
+			name := "idx_" + table + "_" + strings.Join(indexColumns, "_")
+			suggestion, ok := suggestions[name]
+			if !ok {
+				suggestion = &IndexSuggestion{
+					Name:      name,
+					Table:     table,
+					Columns:   indexColumns,
+					Statement: fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s(%s)", name, table, strings.Join(indexColumns, ", ")),
+					Examples:  []string{},
+				}
+				suggestions[name] = suggestion
+			}
+			suggestion.Queries++
+			suggestion.Executions += query.Count
+			suggestion.TotalMs += query.TotalMs
+			if len(suggestion.Examples) < maxSuggestionExamples {
+				suggestion.Examples = append(suggestion.Examples, query.Statement)
+			}
+		}
+	}
+
+	result := make([]IndexSuggestion, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		result = append(result, *suggestion)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalMs != result[j].TotalMs {
+			return result[i].TotalMs > result[j].TotalMs
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// indexCandidate is the columns an index serving a statement's filters on one table would cover
+type indexCandidate struct {
+	Columns []string
+	// Range marks the last column as compared by range, so it must follow the others
+	Range bool
+}
+
+// candidateIndexes returns the candidate index of each table a statement filters: its equality
+// columns by name, then its first range column by name. Columns the table does not have are
+// left out.
+func candidateIndexes(filters []FilterShape, columns map[string]map[string]bool) map[string]indexCandidate {
+	equality := map[string][]string{}
+	ranges := map[string][]string{}
+	for _, filter := range filters {
+		if !columns[filter.Table][filter.Column] {
+			continue
+		}
+		switch filter.Operator {
+		case FilterEquals:
+			equality[filter.Table] = append(equality[filter.Table], filter.Column)
+		case FilterRange:
+			ranges[filter.Table] = append(ranges[filter.Table], filter.Column)
+		}
+	}
+
+	candidates := map[string]indexCandidate{}
+	for table := range columns {
+		candidate := indexCandidate{Columns: uniqueColumns(equality[table])}
+		if len(candidate.Columns) > maxIndexEqualityColumns {
+			candidate.Columns = candidate.Columns[:maxIndexEqualityColumns]
+		}
+		for _, column := range uniqueColumns(ranges[table]) {
+			if !containsColumn(candidate.Columns, column) {
+				candidate.Columns = append(candidate.Columns, column)
+				candidate.Range = true
+				break
+			}
+		}
+		if len(candidate.Columns) > 0 {
+			candidates[table] = candidate
+		}
+	}
+	return candidates
+}
+
+// isIndexed reports whether one of a table's indexes leads with a candidate's columns: the
+// equality columns in any order, then the range column
+func isIndexed(indexes [][]string, candidate indexCandidate) bool {
+	n := len(candidate.Columns)
+	for _, index := range indexes {
+		if len(index) < n {
+			continue
+		}
+		if candidate.Range && index[n-1] != candidate.Columns[n-1] {
+			continue
+		}
+		covered := true
+		for _, column := range index[:n] {
+			if !containsColumn(candidate.Columns, column) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+// tableColumns returns the columns of each table of the database
+func (db *DB) tableColumns(ctx context.Context) (map[string]map[string]bool, error) {
+	rows, err := db.GetConnection().QueryContext(ctx, `
+		SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = 'main'
+	`)
+	if err != nil {
+		return nil, WrapError("list_columns", err)
+	}
+	defer rows.Close()
+
+	columns := map[string]map[string]bool{}
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, WrapError("scan_column", err)
+		}
+		if columns[table] == nil {
+			columns[table] = map[string]bool{}
+		}
+		columns[table][strings.ToLower(column)] = true
+	}
+	return columns, rows.Err()
+}
+
+// indexedColumns returns the column lists of each table's indexes, primary keys and unique
+// constraints
+func (db *DB) indexedColumns(ctx context.Context) (map[string][][]string, error) {
+	rows, err := db.GetConnection().QueryContext(ctx, `
+		SELECT table_name, trim(expressions, '[]') FROM duckdb_indexes()
+		UNION ALL
+		SELECT table_name, array_to_string(constraint_column_names, ', ') FROM duckdb_constraints()
+		WHERE constraint_type IN ('PRIMARY KEY', 'UNIQUE')
+	`)
+	if err != nil {
+		return nil, WrapError("list_indexes", err)
+	}
+	defer rows.Close()
+
+	indexes := map[string][][]string{}
+	for rows.Next() {
+		var table, expressions string
+		if err := rows.Scan(&table, &expressions); err != nil {
+			return nil, WrapError("scan_index", err)
+		}
+		columns := strings.Split(strings.ToLower(expressions), ",")
+		for i := range columns {
+			columns[i] = strings.TrimSpace(columns[i])
+		}
+		indexes[table] = append(indexes[table], columns)
+	}
+	return indexes, rows.Err()
+}
+
+// IndexMigration returns a migration creating the suggested indexes, numbered after the latest
+// migration, for committing to GetMigrations
+func (mm *MigrationManager) IndexMigration(suggestions []IndexSuggestion) Migration {
+	migrations := mm.GetMigrations()
+	migration := Migration{
+		Version: migrations[len(migrations)-1].Version + 1,
+		Name:    "add_suggested_indexes",
+	}
+	if len(suggestions) == 1 {
+		migration.Name = "add_" + suggestions[0].Name
+	}
+
+	var up, down strings.Builder
+	for _, suggestion := range suggestions {
+		fmt.Fprintf(&up, "%s;\n", suggestion.Statement)
+		fmt.Fprintf(&down, "DROP INDEX IF EXISTS %s;\n", suggestion.Name)
+	}
+	migration.UpQuery = up.String()
+	migration.DownQuery = down.String()
+	return migration
+}
+
+// ApplyIndexMigration creates the indexes of a migration from IndexMigration now. The migration
+// is not recorded as applied: its version belongs to it once committed to GetMigrations, and as
+// its indexes are created only if missing, applying it again then changes nothing.
+func (mm *MigrationManager) ApplyIndexMigration(ctx context.Context, migration Migration) error {
+	conn := mm.db.GetConnection()
+	if conn == nil {
+		return fmt.Errorf("database connection not available")
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return WrapError("begin_index_migration", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.UpQuery); err != nil {
+		return WrapError("apply_index_migration", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return WrapError("commit_index_migration", err)
+	}
+
+	log.Printf("Applied suggested indexes of migration %d: %s", migration.Version, migration.Name)
+	return nil
+}
+
+// MigrationSource formats a migration as an entry of GetMigrations
+func MigrationSource(migration Migration) string {
+	indent := func(query string) string {
+		var b strings.Builder
+		for _, line := range strings.Split(strings.TrimSpace(query), "\n") {
+			b.WriteString("\t\t\t\t" + line + "\n")
+		}
+		return b.String()
+	}
+	return fmt.Sprintf("\t\t{\n\t\t\tVersion: %d,\n\t\t\tName:    %q,\n\t\t\tUpQuery: `\n%s\t\t\t`,\n\t\t\tDownQuery: `\n%s\t\t\t`,\n\t\t},\n",
+		migration.Version, migration.Name, indent(migration.UpQuery), indent(migration.DownQuery))
+}
+
+// uniqueColumns returns the columns sorted, without repeats
+func uniqueColumns(columns []string) []string {
+	unique := []string{}
+	for _, column := range columns {
+		if !containsColumn(unique, column) {
+			unique = append(unique, column)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+// containsColumn reports whether the columns include a column
+func containsColumn(columns []string, column string) bool {
+	for _, c := range columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
@@ -62,11 +62,176 @@ func (db *DB) initializeSchema() error {
 		return fmt.Errorf("failed to create uploads table: %w", err)
 	}
 
+	// Add upload columns introduced after the original schema
+	if err := db.addUploadColumns(ctx, tx); err != nil {
+		return fmt.Errorf("failed to add upload columns: %w", err)
+	}
+
+	// Allow the statuses added to the upload lifecycle after the original schema
+	if err := db.upgradeUploadStatusCheck(ctx, tx); err != nil {
+		return fmt.Errorf("failed to upgrade upload status check: %w", err)
+	}
+
+	if err := db.createUploadEventsTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create upload events table: %w", err)
+	}
+
 	// Create incidents table
 	if err := db.createIncidentsTable(ctx, tx); err != nil {
 		return fmt.Errorf("failed to create incidents table: %w", err)
 	}
 
+	// Add columns introduced after the original schema
+	if err := db.addIncidentColumns(ctx, tx); err != nil {
+		return fmt.Errorf("failed to add incident columns: %w", err)
+	}
+
+	if err := db.createIncidentMergesTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create incident merges table: %w", err)
+	}
+
+	// Create application aliases table
+	if err := db.createApplicationAliasesTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create application aliases table: %w", err)
+	}
+
+	// Create org hierarchy table
+	if err := db.createOrgHierarchyTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create org hierarchy table: %w", err)
+	}
+
+	// Create service catalog table
+	if err := db.createServiceCatalogTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create service catalog table: %w", err)
+	}
+
+	// Create cost center assignments table
+	if err := db.createCostCenterAssignmentsTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create cost center assignments table: %w", err)
+	}
+
+	// Create sentiment phrases table
+	if err := db.createSentimentPhrasesTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create sentiment phrases table: %w", err)
+	}
+
+	// Create automation keywords table
+	if err := db.createAutomationKeywordsTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create automation keywords table: %w", err)
+	}
+
+	// Create analyzer feedback table
+	if err := db.createAnalyzerFeedbackTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create analyzer feedback table: %w", err)
+	}
+
+	// Create shadow analyzer tables
+	if err := db.createShadowTables(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create shadow tables: %w", err)
+	}
+
+	// Create mapping profiles table
+	if err := db.createMappingProfilesTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create mapping profiles table: %w", err)
+	}
+
+	// Create datasets table
+	if err := db.createDatasetsTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create datasets table: %w", err)
+	}
+
+	if err := db.createSheetSourcesTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create sheet sources table: %w", err)
+	}
+
+	if err := db.createAutomationTicketsTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create automation tickets table: %w", err)
+	}
+
+	if err := db.createAutomationOpportunityTables(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create automation opportunity tables: %w", err)
+	}
+
+	if err := db.createKPIGoalsTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create KPI goals table: %w", err)
+	}
+
+	if err := db.createValidationRuleSetsTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create validation rule sets table: %w", err)
+	}
+
+	if err := db.createIncidentEventsTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create incident events table: %w", err)
+	}
+
+	if err := db.createUploadProfilesTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create upload profiles table: %w", err)
+	}
+
+	if err := db.createUsageEventsTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create usage events table: %w", err)
+	}
+
+	if err := db.createSystemSettingsTables(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create system settings tables: %w", err)
+	}
+
+	if err := db.createFeatureFlagTables(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create feature flag tables: %w", err)
+	}
+
+	if err := db.createMaintenanceWindowsTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create maintenance windows table: %w", err)
+	}
+
+	if err := db.createHolidaysTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create holidays table: %w", err)
+	}
+
+	if err := db.createUserScopesTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create user scopes table: %w", err)
+	}
+
+	if err := db.createSSOSessionsTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create SSO sessions table: %w", err)
+	}
+
+	if err := db.createAnalysisSandboxesTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create analysis sandboxes table: %w", err)
+	}
+
+	if err := db.createDataQualityTables(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create data quality tables: %w", err)
+	}
+
+	if err := db.createRunbooksTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create runbooks table: %w", err)
+	}
+
+	if err := db.createCMDBItemsTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create CMDB items table: %w", err)
+	}
+
+	if err := db.createWatchlistTables(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create watchlist tables: %w", err)
+	}
+
+	if err := db.createAPIKeysTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create API keys table: %w", err)
+	}
+
+	if err := db.createReportSnapshotsTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create report snapshots table: %w", err)
+	}
+
+	if err := db.createMetricsHistoryTable(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create metrics history table: %w", err)
+	}
+
+	if err := db.createNotificationTables(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create notification tables: %w", err)
+	}
+
 	// Create indexes
 	if err := db.createIndexes(ctx, tx); err != nil {
 		return fmt.Errorf("failed to create indexes: %w", err)
@@ -146,6 +311,47 @@ func (db *DB) ResetDatabase() error {
 
 	// Drop tables
 	dropTables := []string{
+		"DROP TABLE IF EXISTS notification_outbox",
+		"DROP TABLE IF EXISTS notification_preferences",
+		"DROP TABLE IF EXISTS metrics_history",
+		"DROP TABLE IF EXISTS report_snapshots",
+		"DROP TABLE IF EXISTS api_keys",
+		"DROP TABLE IF EXISTS incident_merges",
+		"DROP TABLE IF EXISTS watchlist_alerts",
+		"DROP TABLE IF EXISTS watchlists",
+		"DROP TABLE IF EXISTS upload_events",
+		"DROP TABLE IF EXISTS runbooks",
+		"DROP TABLE IF EXISTS data_quality_alerts",
+		"DROP TABLE IF EXISTS upload_continuity",
+		"DROP TABLE IF EXISTS analysis_sandboxes",
+		"DROP TABLE IF EXISTS sso_sessions",
+		"DROP TABLE IF EXISTS user_scopes",
+		"DROP TABLE IF EXISTS holidays",
+		"DROP TABLE IF EXISTS maintenance_windows",
+		"DROP TABLE IF EXISTS feature_flag_overrides",
+		"DROP TABLE IF EXISTS feature_flags",
+		"DROP TABLE IF EXISTS config_audit",
+		"DROP TABLE IF EXISTS system_settings",
+		"DROP TABLE IF EXISTS usage_events",
+		"DROP TABLE IF EXISTS upload_profiles",
+		"DROP TABLE IF EXISTS incident_events",
+		"DROP TABLE IF EXISTS validation_rule_sets",
+		"DROP TABLE IF EXISTS kpi_goals",
+		"DROP TABLE IF EXISTS automation_opportunity_notes",
+		"DROP TABLE IF EXISTS automation_opportunity_tracking",
+		"DROP TABLE IF EXISTS automation_tickets",
+		"DROP TABLE IF EXISTS sheet_sources",
+		"DROP TABLE IF EXISTS datasets",
+		"DROP TABLE IF EXISTS mapping_profiles",
+		"DROP TABLE IF EXISTS shadow_results",
+		"DROP TABLE IF EXISTS shadow_configs",
+		"DROP TABLE IF EXISTS analyzer_feedback",
+		"DROP TABLE IF EXISTS automation_keywords",
+		"DROP TABLE IF EXISTS sentiment_phrases",
+		"DROP TABLE IF EXISTS cost_center_assignments",
+		"DROP TABLE IF EXISTS service_catalog",
+		"DROP TABLE IF EXISTS org_hierarchy",
+		"DROP TABLE IF EXISTS application_aliases",
 		"DROP TABLE IF EXISTS incidents",
 		"DROP TABLE IF EXISTS uploads",
 	}
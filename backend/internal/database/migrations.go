@@ -209,6 +209,932 @@ func (mm *MigrationManager) GetMigrations() []Migration {
 				DROP VIEW IF EXISTS automation_opportunities;
 			`,
 		},
+		{
+			Version: 5,
+			Name:    "drop_indexes_on_updated_columns",
+			// Updating an indexed column fails the primary key check in DuckDB, so columns
+			// written after insert (upload status, analysis results) must stay unindexed
+			UpQuery: `
+				DROP INDEX IF EXISTS idx_incidents_sentiment_label;
+				DROP INDEX IF EXISTS idx_incidents_it_process_group;
+				DROP INDEX IF EXISTS idx_uploads_status;
+			`,
+			DownQuery: `
+				CREATE INDEX IF NOT EXISTS idx_incidents_sentiment_label ON incidents(sentiment_label);
+				CREATE INDEX IF NOT EXISTS idx_incidents_it_process_group ON incidents(it_process_group);
+				CREATE INDEX IF NOT EXISTS idx_uploads_status ON uploads(status);
+			`,
+		},
+		{
+			Version: 6,
+			Name:    "create_application_aliases",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS application_aliases (
+					alias_key VARCHAR PRIMARY KEY,
+					alias VARCHAR NOT NULL,
+					canonical_name VARCHAR NOT NULL,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS application_name_raw VARCHAR;
+				DROP INDEX IF EXISTS idx_incidents_application;
+			`,
+			// application_name_raw is left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: `
+				CREATE INDEX IF NOT EXISTS idx_incidents_application ON incidents(application_name);
+				DROP TABLE IF EXISTS application_aliases;
+			`,
+		},
+		{
+			Version: 7,
+			Name:    "create_org_hierarchy",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS org_hierarchy (
+					resolution_group VARCHAR PRIMARY KEY,
+					department VARCHAR NOT NULL,
+					division VARCHAR NOT NULL,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS org_hierarchy;
+			`,
+		},
+		{
+			Version: 8,
+			Name:    "create_cost_center_assignments",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS cost_center_assignments (
+					entity_type VARCHAR NOT NULL CHECK (entity_type IN ('application', 'group')),
+					entity_name VARCHAR NOT NULL,
+					cost_center VARCHAR NOT NULL,
+					hourly_rate DOUBLE,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (entity_type, entity_name)
+				);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS cost_center_assignments;
+			`,
+		},
+		{
+			Version: 9,
+			Name:    "create_sentiment_phrases",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS sentiment_phrases (
+					phrase VARCHAR PRIMARY KEY,
+					weight DOUBLE NOT NULL CHECK (weight >= -1 AND weight <= 1),
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS sentiment_phrases;
+			`,
+		},
+		{
+			Version: 10,
+			Name:    "create_automation_keywords",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS automation_keywords (
+					keyword VARCHAR NOT NULL,
+					kind VARCHAR NOT NULL CHECK (kind IN ('automation', 'manual')),
+					weight DOUBLE NOT NULL CHECK (weight >= -1 AND weight <= 1),
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (kind, keyword)
+				);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS automation_keywords;
+			`,
+		},
+		{
+			Version: 11,
+			Name:    "create_analyzer_feedback",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS analyzer_feedback (
+					id VARCHAR PRIMARY KEY,
+					incident_id VARCHAR NOT NULL,
+					analyzer VARCHAR NOT NULL CHECK (analyzer IN ('sentiment', 'automation', 'process_group')),
+					analyzer_version VARCHAR NOT NULL,
+					predicted VARCHAR NOT NULL,
+					actual VARCHAR,
+					correct BOOLEAN NOT NULL,
+					comment VARCHAR,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS sentiment_version VARCHAR;
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS automation_version VARCHAR;
+			`,
+			// The version columns are left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: `
+				DROP TABLE IF EXISTS analyzer_feedback;
+			`,
+		},
+		{
+			Version: 12,
+			Name:    "create_shadow_tables",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS shadow_configs (
+					id VARCHAR PRIMARY KEY,
+					name VARCHAR NOT NULL,
+					sentiment_phrases VARCHAR NOT NULL,
+					automation_keywords VARCHAR NOT NULL,
+					active BOOLEAN NOT NULL DEFAULT false,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				CREATE TABLE IF NOT EXISTS shadow_results (
+					config_id VARCHAR NOT NULL,
+					upload_id VARCHAR NOT NULL,
+					incident_id VARCHAR NOT NULL,
+					sentiment_score DOUBLE,
+					sentiment_label VARCHAR,
+					automation_score DOUBLE,
+					automation_feasible BOOLEAN,
+					it_process_group VARCHAR,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (config_id, upload_id, incident_id)
+				);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS shadow_results;
+				DROP TABLE IF EXISTS shadow_configs;
+			`,
+		},
+		{
+			Version: 13,
+			Name:    "add_processing_options",
+			UpQuery: `
+				ALTER TABLE uploads ADD COLUMN IF NOT EXISTS processing_options VARCHAR;
+				CREATE TABLE IF NOT EXISTS mapping_profiles (
+					name VARCHAR PRIMARY KEY,
+					description VARCHAR,
+					columns VARCHAR NOT NULL,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+			`,
+			// The processing_options column is left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: `
+				DROP TABLE IF EXISTS mapping_profiles;
+			`,
+		},
+		{
+			Version: 14,
+			Name:    "create_datasets",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS datasets (
+					id VARCHAR PRIMARY KEY,
+					name VARCHAR NOT NULL,
+					description VARCHAR,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				ALTER TABLE uploads ADD COLUMN IF NOT EXISTS dataset_id VARCHAR;
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS dataset_id VARCHAR;
+			`,
+			// The dataset_id columns are left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: `
+				DROP TABLE IF EXISTS datasets;
+			`,
+		},
+		{
+			Version: 15,
+			Name:    "create_sheet_sources",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS sheet_sources (
+					id VARCHAR PRIMARY KEY,
+					name VARCHAR NOT NULL,
+					spreadsheet_id VARCHAR NOT NULL,
+					sheet_range VARCHAR,
+					credentials VARCHAR NOT NULL,
+					mapping_profile VARCHAR,
+					sync_interval_minutes INTEGER DEFAULT 0,
+					last_synced_at TIMESTAMP,
+					last_upload_id VARCHAR,
+					last_error VARCHAR,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS sheet_sources;
+			`,
+		},
+		{
+			Version: 16,
+			Name:    "create_automation_tickets",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS automation_tickets (
+					it_process_group VARCHAR PRIMARY KEY,
+					issue_key VARCHAR NOT NULL,
+					issue_url VARCHAR NOT NULL,
+					project_key VARCHAR NOT NULL,
+					issue_type VARCHAR NOT NULL,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS automation_tickets;
+			`,
+		},
+		{
+			Version: 17,
+			Name:    "create_validation_rule_sets",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS validation_rule_sets (
+					name VARCHAR PRIMARY KEY,
+					description VARCHAR,
+					rules VARCHAR NOT NULL,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS validation_rule_sets;
+			`,
+		},
+		{
+			Version: 18,
+			Name:    "create_incident_events",
+			// Incidents loaded before events were recorded get their reported and resolved
+			// events backfilled from the incident dates
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS incident_events (
+					id VARCHAR PRIMARY KEY,
+					incident_id VARCHAR NOT NULL,
+					event_type VARCHAR NOT NULL,
+					occurred_at TIMESTAMP NOT NULL,
+					source VARCHAR,
+					summary VARCHAR,
+					details VARCHAR,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				CREATE INDEX IF NOT EXISTS idx_incident_events_incident_id ON incident_events(incident_id);
+				INSERT INTO incident_events (id, incident_id, event_type, occurred_at, source, summary)
+				SELECT gen_random_uuid()::VARCHAR, id, 'reported', report_date, 'backfill', priority || ' incident reported'
+				FROM incidents
+				WHERE id NOT IN (SELECT incident_id FROM incident_events WHERE event_type = 'reported');
+				INSERT INTO incident_events (id, incident_id, event_type, occurred_at, source, summary)
+				SELECT gen_random_uuid()::VARCHAR, id, 'resolved', resolve_date, 'backfill', 'Incident resolved'
+				FROM incidents
+				WHERE resolve_date IS NOT NULL
+					AND id NOT IN (SELECT incident_id FROM incident_events WHERE event_type = 'resolved');
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS incident_events;
+			`,
+		},
+		{
+			Version: 19,
+			Name:    "create_upload_profiles",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS upload_profiles (
+					upload_id VARCHAR PRIMARY KEY,
+					status VARCHAR NOT NULL,
+					dry_run BOOLEAN DEFAULT FALSE,
+					total_rows INTEGER DEFAULT 0,
+					stored_rows INTEGER DEFAULT 0,
+					parse_ms DOUBLE DEFAULT 0,
+					validation_ms DOUBLE DEFAULT 0,
+					analysis_ms DOUBLE DEFAULT 0,
+					insert_ms DOUBLE DEFAULT 0,
+					total_ms DOUBLE DEFAULT 0,
+					recorded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS upload_profiles;
+			`,
+		},
+		{
+			Version: 20,
+			Name:    "create_usage_events",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS usage_events (
+					recorded_at TIMESTAMP NOT NULL,
+					method VARCHAR NOT NULL,
+					endpoint VARCHAR NOT NULL,
+					filter_keys VARCHAR,
+					filters_hash VARCHAR,
+					status INTEGER,
+					latency_ms DOUBLE,
+					user_hash VARCHAR,
+					tenant_hash VARCHAR
+				);
+				CREATE INDEX IF NOT EXISTS idx_usage_events_recorded_at ON usage_events(recorded_at);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS usage_events;
+			`,
+		},
+		{
+			Version: 21,
+			Name:    "create_system_settings",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS system_settings (
+					key VARCHAR PRIMARY KEY,
+					value VARCHAR NOT NULL,
+					updated_by VARCHAR,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				CREATE TABLE IF NOT EXISTS config_audit (
+					id VARCHAR PRIMARY KEY,
+					setting_key VARCHAR NOT NULL,
+					old_value VARCHAR,
+					new_value VARCHAR NOT NULL,
+					changed_by VARCHAR,
+					changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				CREATE INDEX IF NOT EXISTS idx_config_audit_changed_at ON config_audit(changed_at);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS config_audit;
+				DROP TABLE IF EXISTS system_settings;
+			`,
+		},
+		{
+			Version: 22,
+			Name:    "create_feature_flags",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS feature_flags (
+					key VARCHAR PRIMARY KEY,
+					enabled BOOLEAN NOT NULL,
+					updated_by VARCHAR,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				CREATE TABLE IF NOT EXISTS feature_flag_overrides (
+					flag_key VARCHAR NOT NULL,
+					tenant_id VARCHAR NOT NULL,
+					enabled BOOLEAN NOT NULL,
+					updated_by VARCHAR,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (flag_key, tenant_id)
+				);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS feature_flag_overrides;
+				DROP TABLE IF EXISTS feature_flags;
+			`,
+		},
+		{
+			Version: 23,
+			Name:    "create_maintenance_windows",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS maintenance_windows (
+					id VARCHAR PRIMARY KEY,
+					application VARCHAR,
+					title VARCHAR NOT NULL,
+					description VARCHAR,
+					starts_at TIMESTAMP NOT NULL,
+					ends_at TIMESTAMP NOT NULL,
+					created_by VARCHAR,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT valid_window CHECK (ends_at > starts_at)
+				);
+			`,
+			DownQuery: "DROP TABLE IF EXISTS maintenance_windows",
+		},
+		{
+			Version: 24,
+			Name:    "create_holidays",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS holidays (
+					region VARCHAR NOT NULL,
+					holiday_date DATE NOT NULL,
+					name VARCHAR NOT NULL,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (region, holiday_date)
+				);
+			`,
+			DownQuery: "DROP TABLE IF EXISTS holidays",
+		},
+		{
+			Version: 25,
+			Name:    "create_user_scopes",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS user_scopes (
+					user_id VARCHAR NOT NULL,
+					scope_type VARCHAR NOT NULL CHECK (scope_type IN ('application', 'group')),
+					value VARCHAR NOT NULL,
+					updated_by VARCHAR,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (user_id, scope_type, value)
+				);
+			`,
+			DownQuery: "DROP TABLE IF EXISTS user_scopes",
+		},
+		{
+			Version: 26,
+			Name:    "create_sso_sessions",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS sso_sessions (
+					token_hash VARCHAR PRIMARY KEY,
+					user_id VARCHAR NOT NULL,
+					email VARCHAR,
+					name VARCHAR,
+					role VARCHAR NOT NULL,
+					idp_groups VARCHAR,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					expires_at TIMESTAMP NOT NULL
+				);
+			`,
+			DownQuery: "DROP TABLE IF EXISTS sso_sessions",
+		},
+		{
+			Version: 27,
+			Name:    "create_analysis_sandboxes",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS analysis_sandboxes (
+					token_hash VARCHAR PRIMARY KEY,
+					id VARCHAR NOT NULL,
+					name VARCHAR,
+					overlay VARCHAR NOT NULL,
+					created_by VARCHAR,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					expires_at TIMESTAMP NOT NULL
+				);
+			`,
+			DownQuery: "DROP TABLE IF EXISTS analysis_sandboxes",
+		},
+		{
+			Version: 28,
+			Name:    "add_incident_cost",
+			UpQuery: `
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS cost DOUBLE;
+			`,
+			// cost is left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: "SELECT 1",
+		},
+		{
+			Version: 29,
+			Name:    "create_data_quality_tables",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS upload_continuity (
+					upload_id VARCHAR PRIMARY KEY,
+					previous_upload_id VARCHAR,
+					status VARCHAR NOT NULL,
+					overlap_start DATE,
+					overlap_end DATE,
+					compared_count INTEGER DEFAULT 0,
+					previous_total INTEGER DEFAULT 0,
+					current_total INTEGER DEFAULT 0,
+					threshold_pct DOUBLE NOT NULL,
+					discrepancies VARCHAR,
+					alert_id VARCHAR,
+					checked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				CREATE TABLE IF NOT EXISTS data_quality_alerts (
+					id VARCHAR PRIMARY KEY,
+					type VARCHAR NOT NULL,
+					severity VARCHAR NOT NULL,
+					upload_id VARCHAR,
+					message VARCHAR NOT NULL,
+					details VARCHAR,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS data_quality_alerts;
+				DROP TABLE IF EXISTS upload_continuity;
+			`,
+		},
+		{
+			Version: 30,
+			Name:    "create_runbooks_table",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS runbooks (
+					name VARCHAR PRIMARY KEY,
+					description VARCHAR,
+					keywords VARCHAR NOT NULL,
+					it_process_group VARCHAR,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS runbooks;
+			`,
+		},
+		{
+			Version: 31,
+			Name:    "add_upload_ingested_sheet",
+			UpQuery: `
+				ALTER TABLE uploads ADD COLUMN IF NOT EXISTS ingested_sheet VARCHAR;
+			`,
+			// ingested_sheet is left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: "SELECT 1",
+		},
+		{
+			Version: 32,
+			Name:    "add_date_formats",
+			UpQuery: `
+				ALTER TABLE mapping_profiles ADD COLUMN IF NOT EXISTS date_formats VARCHAR;
+				ALTER TABLE uploads ADD COLUMN IF NOT EXISTS date_quality VARCHAR;
+			`,
+			// Both columns are left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: "SELECT 1",
+		},
+		{
+			Version: 33,
+			Name:    "add_pending_time",
+			UpQuery: `
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS pending_hours DOUBLE;
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS net_resolution_time_hours INTEGER;
+				ALTER TABLE mapping_profiles ADD COLUMN IF NOT EXISTS pending_statuses VARCHAR;
+			`,
+			// The columns are left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: "SELECT 1",
+		},
+		{
+			Version: 34,
+			Name:    "add_upload_lifecycle",
+			// DuckDB cannot alter a check constraint, so uploads is rebuilt to allow the queued
+			// and completed_with_errors statuses
+			UpQuery: `
+				DROP INDEX IF EXISTS idx_uploads_created_at;
+				ALTER TABLE uploads RENAME TO uploads_previous;
+				CREATE TABLE uploads (
+					id VARCHAR PRIMARY KEY,
+					filename VARCHAR NOT NULL,
+					original_filename VARCHAR NOT NULL,
+					status VARCHAR NOT NULL CHECK (status IN ('uploaded', 'queued', 'processing', 'completed', 'completed_with_errors', 'failed')),
+					record_count INTEGER DEFAULT 0,
+					processed_count INTEGER DEFAULT 0,
+					error_count INTEGER DEFAULT 0,
+					errors TEXT[],
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					processed_at TIMESTAMP,
+					processing_options VARCHAR,
+					dataset_id VARCHAR,
+					ingested_sheet VARCHAR,
+					date_quality VARCHAR
+				);
+				INSERT INTO uploads BY NAME SELECT * FROM uploads_previous;
+				DROP TABLE uploads_previous;
+				CREATE INDEX IF NOT EXISTS idx_uploads_created_at ON uploads(created_at);
+				CREATE TABLE IF NOT EXISTS upload_events (
+					id VARCHAR PRIMARY KEY,
+					upload_id VARCHAR NOT NULL,
+					from_status VARCHAR NOT NULL,
+					to_status VARCHAR NOT NULL,
+					record_count INTEGER DEFAULT 0,
+					processed_count INTEGER DEFAULT 0,
+					error_count INTEGER DEFAULT 0,
+					occurred_at TIMESTAMP NOT NULL
+				);
+				CREATE INDEX IF NOT EXISTS idx_upload_events_upload_id ON upload_events(upload_id);
+			`,
+			// The wider status check is kept: uploads may already be in the new statuses
+			DownQuery: `
+				DROP TABLE IF EXISTS upload_events;
+			`,
+		},
+		{
+			Version: 35,
+			Name:    "add_incident_merges",
+			UpQuery: `
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS is_canonical BOOLEAN DEFAULT TRUE;
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS merged_into VARCHAR;
+				CREATE TABLE IF NOT EXISTS incident_merges (
+					id VARCHAR PRIMARY KEY,
+					primary_id VARCHAR NOT NULL,
+					duplicate_ids VARCHAR NOT NULL,
+					reason VARCHAR,
+					merged_by VARCHAR,
+					merged_at TIMESTAMP NOT NULL,
+					undone_by VARCHAR,
+					undone_at TIMESTAMP
+				);
+				CREATE INDEX IF NOT EXISTS idx_incident_merges_primary_id ON incident_merges(primary_id);
+			`,
+			// The incident columns are left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: `
+				DROP TABLE IF EXISTS incident_merges;
+			`,
+		},
+		{
+			Version: 36,
+			Name:    "create_service_catalog",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS service_catalog (
+					service_key VARCHAR PRIMARY KEY,
+					name VARCHAR NOT NULL,
+					business_unit VARCHAR NOT NULL,
+					applications VARCHAR NOT NULL,
+					aliases VARCHAR NOT NULL,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS business_service_raw VARCHAR;
+			`,
+			// business_service_raw is left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: `
+				DROP TABLE IF EXISTS service_catalog;
+			`,
+		},
+		{
+			Version: 37,
+			Name:    "create_automation_opportunity_tracking",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS automation_opportunity_tracking (
+					it_process_group VARCHAR PRIMARY KEY,
+					status VARCHAR NOT NULL CHECK (status IN ('identified', 'under_review', 'approved', 'implemented', 'rejected')),
+					assignee VARCHAR,
+					handling_minutes INTEGER NOT NULL,
+					projected_monthly_hours DOUBLE,
+					implemented_at TIMESTAMP,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				CREATE TABLE IF NOT EXISTS automation_opportunity_notes (
+					id VARCHAR PRIMARY KEY,
+					it_process_group VARCHAR NOT NULL,
+					author VARCHAR,
+					note VARCHAR,
+					from_status VARCHAR,
+					to_status VARCHAR,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				CREATE INDEX IF NOT EXISTS idx_automation_opportunity_notes_group ON automation_opportunity_notes(it_process_group);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS automation_opportunity_notes;
+				DROP TABLE IF EXISTS automation_opportunity_tracking;
+			`,
+		},
+		{
+			Version: 38,
+			Name:    "create_kpi_goals",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS kpi_goals (
+					id VARCHAR PRIMARY KEY,
+					name VARCHAR NOT NULL,
+					team VARCHAR,
+					metric VARCHAR NOT NULL CHECK (metric IN ('incident_count', 'p1_count', 'resolution_rate', 'avg_resolution_time', 'median_resolution_time')),
+					comparison VARCHAR NOT NULL CHECK (comparison IN ('at_least', 'at_most')),
+					target_type VARCHAR NOT NULL CHECK (target_type IN ('absolute', 'change')),
+					target DOUBLE NOT NULL,
+					quarter VARCHAR NOT NULL,
+					applications VARCHAR,
+					created_by VARCHAR,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				CREATE INDEX IF NOT EXISTS idx_kpi_goals_quarter ON kpi_goals(quarter);
+			`,
+			DownQuery: `DROP TABLE IF EXISTS kpi_goals;`,
+		},
+		{
+			Version: 39,
+			Name:    "add_incident_source_and_closure_code",
+			UpQuery: `
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS source VARCHAR;
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS closure_code VARCHAR;
+			`,
+			// The columns are left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: "SELECT 1",
+		},
+		{
+			Version: 40,
+			Name:    "add_incident_region",
+			UpQuery: `
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS region VARCHAR;
+			`,
+			// The column is left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: "SELECT 1",
+		},
+		{
+			Version: 41,
+			Name:    "resolution_metrics_net_hours",
+			// Resolution analytics measure the time net of pending time, added in migration 33
+			UpQuery: `
+				CREATE OR REPLACE VIEW resolution_metrics AS
+				SELECT 
+					application_name,
+					priority,
+					AVG(COALESCE(net_resolution_time_hours, resolution_time_hours)) as avg_resolution_time,
+					PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY COALESCE(net_resolution_time_hours, resolution_time_hours)) as median_resolution_time,
+					COUNT(*) as total_incidents,
+					COUNT(CASE WHEN resolve_date IS NOT NULL THEN 1 END) as resolved_incidents
+				FROM incidents 
+				WHERE resolution_time_hours IS NOT NULL
+				GROUP BY application_name, priority;
+			`,
+			DownQuery: `
+				CREATE OR REPLACE VIEW resolution_metrics AS
+				SELECT 
+					application_name,
+					priority,
+					AVG(resolution_time_hours) as avg_resolution_time,
+					PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY resolution_time_hours) as median_resolution_time,
+					COUNT(*) as total_incidents,
+					COUNT(CASE WHEN resolve_date IS NOT NULL THEN 1 END) as resolved_incidents
+				FROM incidents 
+				WHERE resolution_time_hours IS NOT NULL
+				GROUP BY application_name, priority;
+			`,
+		},
+		{
+			Version: 42,
+			Name:    "add_upload_cleanup",
+			UpQuery: `
+				ALTER TABLE uploads ADD COLUMN IF NOT EXISTS cleanup VARCHAR;
+			`,
+			// The column is left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: "SELECT 1",
+		},
+		{
+			Version: 43,
+			Name:    "add_cmdb_enrichment",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS cmdb_items (
+					entity_type VARCHAR NOT NULL CHECK (entity_type IN ('application', 'service')),
+					entity_key VARCHAR NOT NULL,
+					entity_name VARCHAR NOT NULL,
+					owner VARCHAR,
+					environment VARCHAR,
+					criticality VARCHAR,
+					source VARCHAR NOT NULL,
+					refreshed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (entity_type, entity_key)
+				);
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS cmdb_owner VARCHAR;
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS cmdb_environment VARCHAR;
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS cmdb_criticality VARCHAR;
+			`,
+			// The incident columns are left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: `
+				DROP TABLE IF EXISTS cmdb_items;
+			`,
+		},
+		{
+			Version: 44,
+			Name:    "create_watchlists",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS watchlists (
+					id VARCHAR PRIMARY KEY,
+					user_id VARCHAR NOT NULL,
+					name VARCHAR NOT NULL,
+					entity_type VARCHAR NOT NULL CHECK (entity_type IN ('application', 'group')),
+					entities VARCHAR NOT NULL,
+					volume_increase_pct DOUBLE,
+					resolution_increase_pct DOUBLE,
+					period_days INTEGER NOT NULL DEFAULT 7,
+					baseline_periods INTEGER NOT NULL DEFAULT 4,
+					min_incidents INTEGER NOT NULL DEFAULT 5,
+					email VARCHAR,
+					slack_webhook_url VARCHAR,
+					last_evaluated_at TIMESTAMP,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				CREATE TABLE IF NOT EXISTS watchlist_alerts (
+					id VARCHAR PRIMARY KEY,
+					watchlist_id VARCHAR NOT NULL,
+					entity VARCHAR NOT NULL,
+					metric VARCHAR NOT NULL CHECK (metric IN ('volume', 'resolution_time')),
+					current_value DOUBLE NOT NULL,
+					baseline_value DOUBLE NOT NULL,
+					change_pct DOUBLE NOT NULL,
+					threshold_pct DOUBLE NOT NULL,
+					period_start DATE NOT NULL,
+					period_end DATE NOT NULL,
+					notified VARCHAR,
+					notify_error VARCHAR,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					UNIQUE (watchlist_id, entity, metric, period_end)
+				);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS watchlist_alerts;
+				DROP TABLE IF EXISTS watchlists;
+			`,
+		},
+		{
+			Version: 45,
+			Name:    "add_upload_event_correlation",
+			UpQuery: `
+				ALTER TABLE upload_events ADD COLUMN IF NOT EXISTS request_id VARCHAR;
+				ALTER TABLE upload_events ADD COLUMN IF NOT EXISTS user_id VARCHAR;
+			`,
+			// The columns are left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: "SELECT 1",
+		},
+		{
+			Version: 46,
+			Name:    "add_distribution_check",
+			UpQuery: `
+				ALTER TABLE uploads ADD COLUMN IF NOT EXISTS distribution_check VARCHAR;
+			`,
+			// The column is left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: "SELECT 1",
+		},
+		{
+			Version: 47,
+			Name:    "create_api_keys",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS api_keys (
+					id VARCHAR PRIMARY KEY,
+					key_hash VARCHAR NOT NULL UNIQUE,
+					name VARCHAR NOT NULL,
+					role VARCHAR NOT NULL CHECK (role IN ('observer')),
+					endpoints VARCHAR NOT NULL,
+					start_date DATE,
+					end_date DATE,
+					created_by VARCHAR,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					expires_at TIMESTAMP,
+					last_used_at TIMESTAMP,
+					revoked_at TIMESTAMP
+				);
+			`,
+			DownQuery: "DROP TABLE IF EXISTS api_keys",
+		},
+		{
+			Version: 48,
+			Name:    "create_report_snapshots",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS report_snapshots (
+					id VARCHAR PRIMARY KEY,
+					token_hash VARCHAR NOT NULL UNIQUE,
+					name VARCHAR NOT NULL,
+					view VARCHAR NOT NULL,
+					filters VARCHAR NOT NULL,
+					data VARCHAR NOT NULL,
+					created_by VARCHAR,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					expires_at TIMESTAMP NOT NULL,
+					view_count INTEGER DEFAULT 0,
+					revoked_at TIMESTAMP
+				);
+			`,
+			DownQuery: "DROP TABLE IF EXISTS report_snapshots",
+		},
+		{
+			Version: 49,
+			Name:    "create_metrics_history",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS metrics_history (
+					recorded_at TIMESTAMP PRIMARY KEY,
+					status VARCHAR,
+					total_errors BIGINT DEFAULT 0,
+					interval_errors BIGINT DEFAULT 0,
+					last_hour_errors INTEGER DEFAULT 0,
+					error_rate DOUBLE DEFAULT 0,
+					critical_errors INTEGER DEFAULT 0,
+					request_count BIGINT DEFAULT 0,
+					interval_requests BIGINT DEFAULT 0,
+					avg_response_ms DOUBLE DEFAULT 0,
+					slow_requests INTEGER DEFAULT 0,
+					memory_bytes BIGINT DEFAULT 0,
+					goroutines INTEGER DEFAULT 0
+				);
+			`,
+			DownQuery: "DROP TABLE IF EXISTS metrics_history",
+		},
+		{
+			Version: 50,
+			Name:    "create_notification_preferences",
+			UpQuery: `
+				CREATE TABLE IF NOT EXISTS notification_preferences (
+					user_id VARCHAR PRIMARY KEY,
+					events VARCHAR NOT NULL,
+					channels VARCHAR NOT NULL,
+					delivery VARCHAR NOT NULL CHECK (delivery IN ('immediate', 'digest')),
+					digest_hour INTEGER NOT NULL DEFAULT 8,
+					quiet_hours_start VARCHAR,
+					quiet_hours_end VARCHAR,
+					timezone VARCHAR NOT NULL DEFAULT 'UTC',
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+				CREATE TABLE IF NOT EXISTS notification_outbox (
+					id VARCHAR PRIMARY KEY,
+					user_id VARCHAR NOT NULL,
+					event VARCHAR NOT NULL,
+					channel VARCHAR NOT NULL,
+					destination VARCHAR NOT NULL,
+					subject VARCHAR NOT NULL,
+					body VARCHAR NOT NULL,
+					deliver_after TIMESTAMP NOT NULL,
+					attempts INTEGER DEFAULT 0,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				);
+			`,
+			DownQuery: `
+				DROP TABLE IF EXISTS notification_outbox;
+				DROP TABLE IF EXISTS notification_preferences;
+			`,
+		},
+		{
+			Version: 51,
+			Name:    "add_incident_reported_at",
+			UpQuery: `
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS reported_at TIMESTAMP;
+			`,
+			// The column is left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: "SELECT 1",
+		},
+		{
+			Version: 52,
+			Name:    "add_incident_priority_inferred",
+			UpQuery: `
+				ALTER TABLE incidents ADD COLUMN IF NOT EXISTS priority_inferred BOOLEAN DEFAULT FALSE;
+			`,
+			// The column is left in place: DuckDB cannot drop columns from indexed tables
+			DownQuery: "SELECT 1",
+		},
 	}
 }
 
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"time"
+)
+
+// recordingConnector wraps the DuckDB connector so each statement run through the pool is timed
+// into the slow query log, whichever service runs it
+type recordingConnector struct {
+	driver.Connector
+	slowQueries *SlowQueryLog
+}
+
+// Connect opens a connection whose statements are timed
+func (c *recordingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingConn{conn: conn, slowQueries: c.slowQueries}, nil
+}
+
+// Close closes the wrapped connector, which owns the database
+func (c *recordingConnector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// recordingConn times the statements run on a driver connection. Optional interfaces the
+// wrapped connection lacks return driver.ErrSkip, so database/sql falls back as it would
+// without the wrapper.
+type recordingConn struct {
+	conn        driver.Conn
+	slowQueries *SlowQueryLog
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *recordingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &recordingStmt{Stmt: stmt, query: query, slowQueries: c.slowQueries}, nil
+}
+
+func (c *recordingConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *recordingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.conn.Begin()
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		c.slowQueries.Record(query, time.Since(start))
+	}
+	return result, err
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		c.slowQueries.Record(query, time.Since(start))
+	}
+	return rows, err
+}
+
+func (c *recordingConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// recordingStmt times the executions of a prepared statement
+type recordingStmt struct {
+	driver.Stmt
+	query       string
+	slowQueries *SlowQueryLog
+}
+
+func (s *recordingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var result driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = s.Stmt.Exec(namedValues(args))
+	}
+	s.slowQueries.Record(s.query, time.Since(start))
+	return result, err
+}
+
+func (s *recordingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedValues(args))
+	}
+	s.slowQueries.Record(s.query, time.Since(start))
+	return rows, err
+}
+
+// namedValues drops the names and ordinals of statement arguments
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
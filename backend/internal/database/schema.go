@@ -3,8 +3,12 @@ package database
 import (
 	"context"
 	"database/sql"
+	"fmt"
 )
 
+// uploadStatusCheck constrains uploads.status to the statuses of the upload lifecycle
+const uploadStatusCheck = "CHECK (status IN ('uploaded', 'queued', 'processing', 'completed', 'completed_with_errors', 'failed'))"
+
 // createUploadsTable creates the uploads table
 func (db *DB) createUploadsTable(ctx context.Context, tx *sql.Tx) error {
 	query := `
@@ -12,7 +16,7 @@ func (db *DB) createUploadsTable(ctx context.Context, tx *sql.Tx) error {
 			id VARCHAR PRIMARY KEY,
 			filename VARCHAR NOT NULL,
 			original_filename VARCHAR NOT NULL,
-			status VARCHAR NOT NULL CHECK (status IN ('uploaded', 'processing', 'completed', 'failed')),
+			status VARCHAR NOT NULL ` + uploadStatusCheck + `,
 			record_count INTEGER DEFAULT 0,
 			processed_count INTEGER DEFAULT 0,
 			error_count INTEGER DEFAULT 0,
@@ -75,19 +79,909 @@ func (db *DB) createIncidentsTable(ctx context.Context, tx *sql.Tx) error {
 	return err
 }
 
+// addIncidentColumns adds columns introduced after the original incidents table to existing databases
+func (db *DB) addIncidentColumns(ctx context.Context, tx *sql.Tx) error {
+	columns := []string{
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS application_name_raw VARCHAR",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS sentiment_version VARCHAR",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS automation_version VARCHAR",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS dataset_id VARCHAR",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS cost DOUBLE",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS pending_hours DOUBLE",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS net_resolution_time_hours INTEGER",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS is_canonical BOOLEAN DEFAULT TRUE",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS merged_into VARCHAR",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS business_service_raw VARCHAR",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS source VARCHAR",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS closure_code VARCHAR",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS region VARCHAR",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS cmdb_owner VARCHAR",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS cmdb_environment VARCHAR",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS cmdb_criticality VARCHAR",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS reported_at TIMESTAMP",
+		"ALTER TABLE incidents ADD COLUMN IF NOT EXISTS priority_inferred BOOLEAN DEFAULT FALSE",
+	}
+
+	for _, query := range columns {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addUploadColumns adds columns introduced after the original uploads table to existing databases
+func (db *DB) addUploadColumns(ctx context.Context, tx *sql.Tx) error {
+	columns := []string{
+		"ALTER TABLE uploads ADD COLUMN IF NOT EXISTS processing_options VARCHAR",
+		"ALTER TABLE uploads ADD COLUMN IF NOT EXISTS dataset_id VARCHAR",
+		"ALTER TABLE uploads ADD COLUMN IF NOT EXISTS ingested_sheet VARCHAR",
+		"ALTER TABLE uploads ADD COLUMN IF NOT EXISTS date_quality VARCHAR",
+		"ALTER TABLE uploads ADD COLUMN IF NOT EXISTS cleanup VARCHAR",
+		"ALTER TABLE uploads ADD COLUMN IF NOT EXISTS distribution_check VARCHAR",
+	}
+
+	for _, query := range columns {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upgradeUploadStatusCheck rebuilds an uploads table created before the queued and
+// completed_with_errors statuses, whose status check would reject them. DuckDB cannot alter a
+// check constraint, so the rows are copied into a new table.
+func (db *DB) upgradeUploadStatusCheck(ctx context.Context, tx *sql.Tx) error {
+	var outdated int
+	err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM duckdb_constraints()
+		WHERE table_name = 'uploads' AND constraint_type = 'CHECK'
+			AND expression LIKE '%status%' AND expression NOT LIKE '%queued%'
+	`).Scan(&outdated)
+	if err != nil || outdated == 0 {
+		return err
+	}
+
+	// The table cannot be renamed while an index depends on it; createIndexes recreates it
+	if _, err := tx.ExecContext(ctx, "DROP INDEX IF EXISTS idx_uploads_created_at"); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "ALTER TABLE uploads RENAME TO uploads_previous"); err != nil {
+		return err
+	}
+	if err := db.createUploadsTable(ctx, tx); err != nil {
+		return err
+	}
+	if err := db.addUploadColumns(ctx, tx); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO uploads BY NAME SELECT * FROM uploads_previous"); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "DROP TABLE uploads_previous")
+	return err
+}
+
+// createUploadEventsTable creates the history of upload status transitions
+func (db *DB) createUploadEventsTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS upload_events (
+			id VARCHAR PRIMARY KEY,
+			upload_id VARCHAR NOT NULL,
+			from_status VARCHAR NOT NULL,
+			to_status VARCHAR NOT NULL,
+			record_count INTEGER DEFAULT 0,
+			processed_count INTEGER DEFAULT 0,
+			error_count INTEGER DEFAULT 0,
+			occurred_at TIMESTAMP NOT NULL,
+			request_id VARCHAR,
+			user_id VARCHAR
+		)
+	`
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	// Events recorded before transitions carried the request that caused them
+	for _, column := range []string{"request_id", "user_id"} {
+		if _, err := tx.ExecContext(ctx, "ALTER TABLE upload_events ADD COLUMN IF NOT EXISTS "+column+" VARCHAR"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createIncidentMergesTable creates the record of incidents merged as duplicates of a primary
+// incident, kept after a merge is undone
+func (db *DB) createIncidentMergesTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS incident_merges (
+			id VARCHAR PRIMARY KEY,
+			primary_id VARCHAR NOT NULL,
+			duplicate_ids VARCHAR NOT NULL,
+			reason VARCHAR,
+			merged_by VARCHAR,
+			merged_at TIMESTAMP NOT NULL,
+			undone_by VARCHAR,
+			undone_at TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createApplicationAliasesTable creates the table of admin-managed application name aliases
+func (db *DB) createApplicationAliasesTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS application_aliases (
+			alias_key VARCHAR PRIMARY KEY,
+			alias VARCHAR NOT NULL,
+			canonical_name VARCHAR NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createOrgHierarchyTable creates the table mapping resolution groups to departments and divisions
+func (db *DB) createOrgHierarchyTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS org_hierarchy (
+			resolution_group VARCHAR PRIMARY KEY,
+			department VARCHAR NOT NULL,
+			division VARCHAR NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createServiceCatalogTable creates the registry placing business services under their owning
+// applications and business units
+func (db *DB) createServiceCatalogTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS service_catalog (
+			service_key VARCHAR PRIMARY KEY,
+			name VARCHAR NOT NULL,
+			business_unit VARCHAR NOT NULL,
+			applications VARCHAR NOT NULL,
+			aliases VARCHAR NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createCostCenterAssignmentsTable creates the registry attributing applications and groups to cost centers
+func (db *DB) createCostCenterAssignmentsTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS cost_center_assignments (
+			entity_type VARCHAR NOT NULL CHECK (entity_type IN ('application', 'group')),
+			entity_name VARCHAR NOT NULL,
+			cost_center VARCHAR NOT NULL,
+			hourly_rate DOUBLE,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (entity_type, entity_name)
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createSentimentPhrasesTable creates the configurable phrase lexicon for sentiment scoring
+func (db *DB) createSentimentPhrasesTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS sentiment_phrases (
+			phrase VARCHAR PRIMARY KEY,
+			weight DOUBLE NOT NULL CHECK (weight >= -1 AND weight <= 1),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createAutomationKeywordsTable creates the custom keyword weights used by automation analysis
+func (db *DB) createAutomationKeywordsTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS automation_keywords (
+			keyword VARCHAR NOT NULL,
+			kind VARCHAR NOT NULL CHECK (kind IN ('automation', 'manual')),
+			weight DOUBLE NOT NULL CHECK (weight >= -1 AND weight <= 1),
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (kind, keyword)
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createAnalyzerFeedbackTable creates the table of user verdicts on analyzer outputs
+func (db *DB) createAnalyzerFeedbackTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS analyzer_feedback (
+			id VARCHAR PRIMARY KEY,
+			incident_id VARCHAR NOT NULL,
+			analyzer VARCHAR NOT NULL CHECK (analyzer IN ('sentiment', 'automation', 'process_group')),
+			analyzer_version VARCHAR NOT NULL,
+			predicted VARCHAR NOT NULL,
+			actual VARCHAR,
+			correct BOOLEAN NOT NULL,
+			comment VARCHAR,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createShadowTables creates the shadow analyzer configurations and the results they produce,
+// which are kept apart from the production analysis fields on incidents
+func (db *DB) createShadowTables(ctx context.Context, tx *sql.Tx) error {
+	queries := []string{`
+		CREATE TABLE IF NOT EXISTS shadow_configs (
+			id VARCHAR PRIMARY KEY,
+			name VARCHAR NOT NULL,
+			sentiment_phrases VARCHAR NOT NULL,
+			automation_keywords VARCHAR NOT NULL,
+			active BOOLEAN NOT NULL DEFAULT false,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`, `
+		CREATE TABLE IF NOT EXISTS shadow_results (
+			config_id VARCHAR NOT NULL,
+			upload_id VARCHAR NOT NULL,
+			incident_id VARCHAR NOT NULL,
+			sentiment_score DOUBLE,
+			sentiment_label VARCHAR,
+			automation_score DOUBLE,
+			automation_feasible BOOLEAN,
+			it_process_group VARCHAR,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (config_id, upload_id, incident_id)
+		)
+	`}
+
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createMappingProfilesTable creates the table of named spreadsheet column mappings
+func (db *DB) createMappingProfilesTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS mapping_profiles (
+			name VARCHAR PRIMARY KEY,
+			description VARCHAR,
+			columns VARCHAR NOT NULL,
+			date_formats VARCHAR,
+			pending_statuses VARCHAR,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	// Profiles created before date formats and pending statuses could be set
+	for _, column := range []string{"date_formats", "pending_statuses"} {
+		if _, err := tx.ExecContext(ctx, "ALTER TABLE mapping_profiles ADD COLUMN IF NOT EXISTS "+column+" VARCHAR"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createDatasetsTable creates the table of datasets, groups of uploads processed together
+func (db *DB) createDatasetsTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS datasets (
+			id VARCHAR PRIMARY KEY,
+			name VARCHAR NOT NULL,
+			description VARCHAR,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createSheetSourcesTable creates the table of Google Sheets that are imported as uploads
+func (db *DB) createSheetSourcesTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS sheet_sources (
+			id VARCHAR PRIMARY KEY,
+			name VARCHAR NOT NULL,
+			spreadsheet_id VARCHAR NOT NULL,
+			sheet_range VARCHAR,
+			credentials VARCHAR NOT NULL,
+			mapping_profile VARCHAR,
+			sync_interval_minutes INTEGER DEFAULT 0,
+			last_synced_at TIMESTAMP,
+			last_upload_id VARCHAR,
+			last_error VARCHAR,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createAutomationTicketsTable creates the table of Jira issues raised for automation candidates
+func (db *DB) createAutomationTicketsTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS automation_tickets (
+			it_process_group VARCHAR PRIMARY KEY,
+			issue_key VARCHAR NOT NULL,
+			issue_url VARCHAR NOT NULL,
+			project_key VARCHAR NOT NULL,
+			issue_type VARCHAR NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createAutomationOpportunityTables creates the review status of automation candidates and the
+// notes recorded with each update
+func (db *DB) createAutomationOpportunityTables(ctx context.Context, tx *sql.Tx) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS automation_opportunity_tracking (
+			it_process_group VARCHAR PRIMARY KEY,
+			status VARCHAR NOT NULL CHECK (status IN ('identified', 'under_review', 'approved', 'implemented', 'rejected')),
+			assignee VARCHAR,
+			handling_minutes INTEGER NOT NULL,
+			projected_monthly_hours DOUBLE,
+			implemented_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS automation_opportunity_notes (
+			id VARCHAR PRIMARY KEY,
+			it_process_group VARCHAR NOT NULL,
+			author VARCHAR,
+			note VARCHAR,
+			from_status VARCHAR,
+			to_status VARCHAR,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createKPIGoalsTable creates the table of quarterly KPI goals
+func (db *DB) createKPIGoalsTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS kpi_goals (
+			id VARCHAR PRIMARY KEY,
+			name VARCHAR NOT NULL,
+			team VARCHAR,
+			metric VARCHAR NOT NULL CHECK (metric IN ('incident_count', 'p1_count', 'resolution_rate', 'avg_resolution_time', 'median_resolution_time')),
+			comparison VARCHAR NOT NULL CHECK (comparison IN ('at_least', 'at_most')),
+			target_type VARCHAR NOT NULL CHECK (target_type IN ('absolute', 'change')),
+			target DOUBLE NOT NULL,
+			quarter VARCHAR NOT NULL,
+			applications VARCHAR,
+			created_by VARCHAR,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createValidationRuleSetsTable creates the table of named ingestion validation rule sets
+func (db *DB) createValidationRuleSetsTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS validation_rule_sets (
+			name VARCHAR PRIMARY KEY,
+			description VARCHAR,
+			rules VARCHAR NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createIncidentEventsTable creates the lifecycle event history shown on incident timelines
+func (db *DB) createIncidentEventsTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS incident_events (
+			id VARCHAR PRIMARY KEY,
+			incident_id VARCHAR NOT NULL,
+			event_type VARCHAR NOT NULL,
+			occurred_at TIMESTAMP NOT NULL,
+			source VARCHAR,
+			summary VARCHAR,
+			details VARCHAR,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createUploadProfilesTable creates the stage timings of each upload's last processing run
+func (db *DB) createUploadProfilesTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS upload_profiles (
+			upload_id VARCHAR PRIMARY KEY,
+			status VARCHAR NOT NULL,
+			dry_run BOOLEAN DEFAULT FALSE,
+			total_rows INTEGER DEFAULT 0,
+			stored_rows INTEGER DEFAULT 0,
+			parse_ms DOUBLE DEFAULT 0,
+			validation_ms DOUBLE DEFAULT 0,
+			analysis_ms DOUBLE DEFAULT 0,
+			insert_ms DOUBLE DEFAULT 0,
+			total_ms DOUBLE DEFAULT 0,
+			recorded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createUsageEventsTable creates the anonymized API usage log
+func (db *DB) createUsageEventsTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS usage_events (
+			recorded_at TIMESTAMP NOT NULL,
+			method VARCHAR NOT NULL,
+			endpoint VARCHAR NOT NULL,
+			filter_keys VARCHAR,
+			filters_hash VARCHAR,
+			status INTEGER,
+			latency_ms DOUBLE,
+			user_hash VARCHAR,
+			tenant_hash VARCHAR
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createSystemSettingsTables creates the admin-tunable settings and the audit log of their
+// changes
+func (db *DB) createSystemSettingsTables(ctx context.Context, tx *sql.Tx) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS system_settings (
+			key VARCHAR PRIMARY KEY,
+			value VARCHAR NOT NULL,
+			updated_by VARCHAR,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS config_audit (
+			id VARCHAR PRIMARY KEY,
+			setting_key VARCHAR NOT NULL,
+			old_value VARCHAR,
+			new_value VARCHAR NOT NULL,
+			changed_by VARCHAR,
+			changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createFeatureFlagTables creates the feature flags toggled by admins and their per-tenant
+// overrides
+func (db *DB) createFeatureFlagTables(ctx context.Context, tx *sql.Tx) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS feature_flags (
+			key VARCHAR PRIMARY KEY,
+			enabled BOOLEAN NOT NULL,
+			updated_by VARCHAR,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS feature_flag_overrides (
+			flag_key VARCHAR NOT NULL,
+			tenant_id VARCHAR NOT NULL,
+			enabled BOOLEAN NOT NULL,
+			updated_by VARCHAR,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (flag_key, tenant_id)
+		)`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createMaintenanceWindowsTable creates the calendar of planned outages per application.
+// application is NULL for windows that cover every application.
+func (db *DB) createMaintenanceWindowsTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS maintenance_windows (
+			id VARCHAR PRIMARY KEY,
+			application VARCHAR,
+			title VARCHAR NOT NULL,
+			description VARCHAR,
+			starts_at TIMESTAMP NOT NULL,
+			ends_at TIMESTAMP NOT NULL,
+			created_by VARCHAR,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			CONSTRAINT valid_window CHECK (ends_at > starts_at)
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createHolidaysTable creates the public holiday calendars, one per region, used to annotate
+// timelines and to adjust trends and forecasts
+func (db *DB) createHolidaysTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS holidays (
+			region VARCHAR NOT NULL,
+			holiday_date DATE NOT NULL,
+			name VARCHAR NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (region, holiday_date)
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createUserScopesTable creates the data scopes restricting users to the incidents of certain
+// applications or resolution groups
+func (db *DB) createUserScopesTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS user_scopes (
+			user_id VARCHAR NOT NULL,
+			scope_type VARCHAR NOT NULL CHECK (scope_type IN ('application', 'group')),
+			value VARCHAR NOT NULL,
+			updated_by VARCHAR,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, scope_type, value)
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createSSOSessionsTable creates the sessions of users signed in through single sign-on. Only a
+// hash of each session token is stored.
+func (db *DB) createSSOSessionsTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS sso_sessions (
+			token_hash VARCHAR PRIMARY KEY,
+			user_id VARCHAR NOT NULL,
+			email VARCHAR,
+			name VARCHAR,
+			role VARCHAR NOT NULL,
+			idp_groups VARCHAR,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP NOT NULL
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createAnalysisSandboxesTable creates the temporary analysis sandboxes. Only a hash of each
+// sandbox token is stored.
+func (db *DB) createAnalysisSandboxesTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS analysis_sandboxes (
+			token_hash VARCHAR PRIMARY KEY,
+			id VARCHAR NOT NULL,
+			name VARCHAR,
+			overlay VARCHAR NOT NULL,
+			created_by VARCHAR,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP NOT NULL
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createAPIKeysTable creates the API keys of server-side callers. Only a hash of each key is
+// stored.
+func (db *DB) createAPIKeysTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id VARCHAR PRIMARY KEY,
+			key_hash VARCHAR NOT NULL UNIQUE,
+			name VARCHAR NOT NULL,
+			role VARCHAR NOT NULL CHECK (role IN ('observer')),
+			endpoints VARCHAR NOT NULL,
+			start_date DATE,
+			end_date DATE,
+			created_by VARCHAR,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP,
+			last_used_at TIMESTAMP,
+			revoked_at TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createReportSnapshotsTable creates the frozen analytics results shared through expiring links.
+// Only a hash of each link's token is stored.
+func (db *DB) createReportSnapshotsTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS report_snapshots (
+			id VARCHAR PRIMARY KEY,
+			token_hash VARCHAR NOT NULL UNIQUE,
+			name VARCHAR NOT NULL,
+			view VARCHAR NOT NULL,
+			filters VARCHAR NOT NULL,
+			data VARCHAR NOT NULL,
+			created_by VARCHAR,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP NOT NULL,
+			view_count INTEGER DEFAULT 0,
+			revoked_at TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createMetricsHistoryTable creates the periodic samples of the in-memory error and performance
+// metrics, kept across restarts
+func (db *DB) createMetricsHistoryTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS metrics_history (
+			recorded_at TIMESTAMP PRIMARY KEY,
+			status VARCHAR,
+			total_errors BIGINT DEFAULT 0,
+			interval_errors BIGINT DEFAULT 0,
+			last_hour_errors INTEGER DEFAULT 0,
+			error_rate DOUBLE DEFAULT 0,
+			critical_errors INTEGER DEFAULT 0,
+			request_count BIGINT DEFAULT 0,
+			interval_requests BIGINT DEFAULT 0,
+			avg_response_ms DOUBLE DEFAULT 0,
+			slow_requests INTEGER DEFAULT 0,
+			memory_bytes BIGINT DEFAULT 0,
+			goroutines INTEGER DEFAULT 0
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createDataQualityTables creates the continuity check of each upload against the upload before
+// it, and the data-quality alerts raised by such checks
+func (db *DB) createDataQualityTables(ctx context.Context, tx *sql.Tx) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS upload_continuity (
+			upload_id VARCHAR PRIMARY KEY,
+			previous_upload_id VARCHAR,
+			status VARCHAR NOT NULL,
+			overlap_start DATE,
+			overlap_end DATE,
+			compared_count INTEGER DEFAULT 0,
+			previous_total INTEGER DEFAULT 0,
+			current_total INTEGER DEFAULT 0,
+			threshold_pct DOUBLE NOT NULL,
+			discrepancies VARCHAR,
+			alert_id VARCHAR,
+			checked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS data_quality_alerts (
+			id VARCHAR PRIMARY KEY,
+			type VARCHAR NOT NULL,
+			severity VARCHAR NOT NULL,
+			upload_id VARCHAR,
+			message VARCHAR NOT NULL,
+			details VARCHAR,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createRunbooksTable creates the registry of existing runbooks that automation candidates are
+// checked against
+func (db *DB) createRunbooksTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS runbooks (
+			name VARCHAR PRIMARY KEY,
+			description VARCHAR,
+			keywords VARCHAR NOT NULL,
+			it_process_group VARCHAR,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createCMDBItemsTable creates the local copy of the CMDB attributes incidents are enriched with,
+// keyed by application or business service
+func (db *DB) createCMDBItemsTable(ctx context.Context, tx *sql.Tx) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS cmdb_items (
+			entity_type VARCHAR NOT NULL CHECK (entity_type IN ('application', 'service')),
+			entity_key VARCHAR NOT NULL,
+			entity_name VARCHAR NOT NULL,
+			owner VARCHAR,
+			environment VARCHAR,
+			criticality VARCHAR,
+			source VARCHAR NOT NULL,
+			refreshed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (entity_type, entity_key)
+		)
+	`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// createWatchlistTables creates the users' watchlists of applications or resolution groups and
+// the deterioration alerts raised for them. An alert is raised once per entity, metric and period.
+func (db *DB) createWatchlistTables(ctx context.Context, tx *sql.Tx) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS watchlists (
+			id VARCHAR PRIMARY KEY,
+			user_id VARCHAR NOT NULL,
+			name VARCHAR NOT NULL,
+			entity_type VARCHAR NOT NULL CHECK (entity_type IN ('application', 'group')),
+			entities VARCHAR NOT NULL,
+			volume_increase_pct DOUBLE,
+			resolution_increase_pct DOUBLE,
+			period_days INTEGER NOT NULL DEFAULT 7,
+			baseline_periods INTEGER NOT NULL DEFAULT 4,
+			min_incidents INTEGER NOT NULL DEFAULT 5,
+			email VARCHAR,
+			slack_webhook_url VARCHAR,
+			last_evaluated_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS watchlist_alerts (
+			id VARCHAR PRIMARY KEY,
+			watchlist_id VARCHAR NOT NULL,
+			entity VARCHAR NOT NULL,
+			metric VARCHAR NOT NULL CHECK (metric IN ('volume', 'resolution_time')),
+			current_value DOUBLE NOT NULL,
+			baseline_value DOUBLE NOT NULL,
+			change_pct DOUBLE NOT NULL,
+			threshold_pct DOUBLE NOT NULL,
+			period_start DATE NOT NULL,
+			period_end DATE NOT NULL,
+			notified VARCHAR,
+			notify_error VARCHAR,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (watchlist_id, entity, metric, period_end)
+		)`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createNotificationTables creates the users' notification preferences and the outbox of
+// notifications held for a digest or until quiet hours end
+func (db *DB) createNotificationTables(ctx context.Context, tx *sql.Tx) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS notification_preferences (
+			user_id VARCHAR PRIMARY KEY,
+			events VARCHAR NOT NULL,
+			channels VARCHAR NOT NULL,
+			delivery VARCHAR NOT NULL CHECK (delivery IN ('immediate', 'digest')),
+			digest_hour INTEGER NOT NULL DEFAULT 8,
+			quiet_hours_start VARCHAR,
+			quiet_hours_end VARCHAR,
+			timezone VARCHAR NOT NULL DEFAULT 'UTC',
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS notification_outbox (
+			id VARCHAR PRIMARY KEY,
+			user_id VARCHAR NOT NULL,
+			event VARCHAR NOT NULL,
+			channel VARCHAR NOT NULL,
+			destination VARCHAR NOT NULL,
+			subject VARCHAR NOT NULL,
+			body VARCHAR NOT NULL,
+			deliver_after TIMESTAMP NOT NULL,
+			attempts INTEGER DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // createIndexes creates performance indexes
 func (db *DB) createIndexes(ctx context.Context, tx *sql.Tx) error {
 	indexes := []string{
 		"CREATE INDEX IF NOT EXISTS idx_incidents_upload_id ON incidents(upload_id)",
 		"CREATE INDEX IF NOT EXISTS idx_incidents_report_date ON incidents(report_date)",
 		"CREATE INDEX IF NOT EXISTS idx_incidents_priority ON incidents(priority)",
-		"CREATE INDEX IF NOT EXISTS idx_incidents_application ON incidents(application_name)",
 		"CREATE INDEX IF NOT EXISTS idx_incidents_status ON incidents(status)",
 		"CREATE INDEX IF NOT EXISTS idx_incidents_resolution_group ON incidents(resolution_group)",
-		"CREATE INDEX IF NOT EXISTS idx_incidents_sentiment_label ON incidents(sentiment_label)",
-		"CREATE INDEX IF NOT EXISTS idx_incidents_it_process_group ON incidents(it_process_group)",
-		"CREATE INDEX IF NOT EXISTS idx_uploads_status ON uploads(status)",
 		"CREATE INDEX IF NOT EXISTS idx_uploads_created_at ON uploads(created_at)",
+		"CREATE INDEX IF NOT EXISTS idx_incident_events_incident_id ON incident_events(incident_id)",
+		"CREATE INDEX IF NOT EXISTS idx_upload_events_upload_id ON upload_events(upload_id)",
+		"CREATE INDEX IF NOT EXISTS idx_incident_merges_primary_id ON incident_merges(primary_id)",
+		"CREATE INDEX IF NOT EXISTS idx_automation_opportunity_notes_group ON automation_opportunity_notes(it_process_group)",
+		"CREATE INDEX IF NOT EXISTS idx_kpi_goals_quarter ON kpi_goals(quarter)",
+		"CREATE INDEX IF NOT EXISTS idx_usage_events_recorded_at ON usage_events(recorded_at)",
+		"CREATE INDEX IF NOT EXISTS idx_config_audit_changed_at ON config_audit(changed_at)",
+
+		// DuckDB rewrites an UPDATE of an indexed column as delete+insert, which trips the
+		// primary key check. Columns updated after insert must therefore stay unindexed.
+		"DROP INDEX IF EXISTS idx_incidents_sentiment_label",
+		"DROP INDEX IF EXISTS idx_incidents_it_process_group",
+		"DROP INDEX IF EXISTS idx_uploads_status",
+		"DROP INDEX IF EXISTS idx_incidents_application",
 	}
 
 	for _, indexQuery := range indexes {
@@ -99,11 +993,16 @@ func (db *DB) createIndexes(ctx context.Context, tx *sql.Tx) error {
 	return nil
 }
 
-// createAnalyticsViews creates pre-computed analytics views for dashboard performance
-func (db *DB) createAnalyticsViews(ctx context.Context, tx *sql.Tx) error {
-	views := []string{
-		// Daily incident timeline
-		`CREATE VIEW IF NOT EXISTS incident_timeline AS
+// analyticsView is a view over incidents created with the schema
+type analyticsView struct {
+	name  string
+	query string
+}
+
+// analyticsViews holds the analytics views in creation order
+var analyticsViews = []analyticsView{
+	// Daily incident timeline
+	{"incident_timeline", `CREATE VIEW IF NOT EXISTS incident_timeline AS
 		SELECT 
 			DATE_TRUNC('day', report_date) as date,
 			COUNT(*) as incident_count,
@@ -113,10 +1012,10 @@ func (db *DB) createAnalyticsViews(ctx context.Context, tx *sql.Tx) error {
 			COUNT(CASE WHEN priority = 'P4' THEN 1 END) as p4_count
 		FROM incidents 
 		GROUP BY DATE_TRUNC('day', report_date)
-		ORDER BY date`,
+		ORDER BY date`},
 
-		// Weekly incident timeline
-		`CREATE VIEW IF NOT EXISTS weekly_timeline AS
+	// Weekly incident timeline
+	{"weekly_timeline", `CREATE VIEW IF NOT EXISTS weekly_timeline AS
 		SELECT 
 			DATE_TRUNC('week', report_date) as week,
 			COUNT(*) as incident_count,
@@ -126,43 +1025,44 @@ func (db *DB) createAnalyticsViews(ctx context.Context, tx *sql.Tx) error {
 			COUNT(CASE WHEN priority = 'P4' THEN 1 END) as p4_count
 		FROM incidents 
 		GROUP BY DATE_TRUNC('week', report_date)
-		ORDER BY week`,
+		ORDER BY week`},
 
-		// Resolution metrics by application and priority
-		`CREATE VIEW IF NOT EXISTS resolution_metrics AS
+	// Resolution metrics by application and priority, net of time pending on the customer as
+	// the resolution analytics measure it
+	{"resolution_metrics", `CREATE VIEW IF NOT EXISTS resolution_metrics AS
 		SELECT 
 			application_name,
 			priority,
-			AVG(resolution_time_hours) as avg_resolution_time,
-			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY resolution_time_hours) as median_resolution_time,
+			AVG(COALESCE(net_resolution_time_hours, resolution_time_hours)) as avg_resolution_time,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY COALESCE(net_resolution_time_hours, resolution_time_hours)) as median_resolution_time,
 			COUNT(*) as total_incidents,
 			COUNT(CASE WHEN resolve_date IS NOT NULL THEN 1 END) as resolved_incidents
 		FROM incidents 
 		WHERE resolution_time_hours IS NOT NULL
-		GROUP BY application_name, priority`,
+		GROUP BY application_name, priority`},
 
-		// Priority analysis
-		`CREATE VIEW IF NOT EXISTS priority_analysis AS
+	// Priority analysis
+	{"priority_analysis", `CREATE VIEW IF NOT EXISTS priority_analysis AS
 		SELECT 
 			priority,
 			COUNT(*) as count,
 			ROUND(COUNT(*) * 100.0 / SUM(COUNT(*)) OVER (), 2) as percentage
 		FROM incidents 
 		GROUP BY priority
-		ORDER BY priority`,
+		ORDER BY priority`},
 
-		// Sentiment summary
-		`CREATE VIEW IF NOT EXISTS sentiment_summary AS
+	// Sentiment summary
+	{"sentiment_summary", `CREATE VIEW IF NOT EXISTS sentiment_summary AS
 		SELECT 
 			sentiment_label,
 			COUNT(*) as count,
 			ROUND(AVG(sentiment_score), 3) as avg_score
 		FROM incidents 
 		WHERE sentiment_label IS NOT NULL
-		GROUP BY sentiment_label`,
+		GROUP BY sentiment_label`},
 
-		// Automation opportunities
-		`CREATE VIEW IF NOT EXISTS automation_opportunities AS
+	// Automation opportunities
+	{"automation_opportunities", `CREATE VIEW IF NOT EXISTS automation_opportunities AS
 		SELECT 
 			it_process_group,
 			COUNT(*) as incident_count,
@@ -172,14 +1072,46 @@ func (db *DB) createAnalyticsViews(ctx context.Context, tx *sql.Tx) error {
 		FROM incidents 
 		WHERE it_process_group IS NOT NULL
 		GROUP BY it_process_group
-		ORDER BY automation_percentage DESC`,
+		ORDER BY automation_percentage DESC`},
+}
+
+// AnalyticsViewNames returns the names of the analytics views in creation order
+func AnalyticsViewNames() []string {
+	names := make([]string, len(analyticsViews))
+	for i, view := range analyticsViews {
+		names[i] = view.name
 	}
+	return names
+}
 
-	for _, viewQuery := range views {
-		if _, err := tx.ExecContext(ctx, viewQuery); err != nil {
+// createAnalyticsViews creates pre-computed analytics views for dashboard performance
+func (db *DB) createAnalyticsViews(ctx context.Context, tx *sql.Tx) error {
+	for _, view := range analyticsViews {
+		if _, err := tx.ExecContext(ctx, view.query); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// RebuildAnalyticsViews drops the analytics views and creates them again from their current
+// definitions, so a database created by an older release stops serving outdated views
+func RebuildAnalyticsViews(ctx context.Context, conn *sql.DB) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, view := range analyticsViews {
+		if _, err := tx.ExecContext(ctx, "DROP VIEW IF EXISTS "+view.name); err != nil {
+			return fmt.Errorf("failed to drop view %s: %w", view.name, err)
+		}
+		if _, err := tx.ExecContext(ctx, view.query); err != nil {
+			return fmt.Errorf("failed to create view %s: %w", view.name, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,222 @@
+package database
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultSlowQueryThreshold is how long a statement runs before the slow query log records it
+	DefaultSlowQueryThreshold = 500 * time.Millisecond
+
+	// maxSlowQueries caps the distinct statements the log keeps; the least recently seen is
+	// dropped to make room
+	maxSlowQueries = 200
+
+	// maxStatementLength caps the statement text kept for each slow query
+	maxStatementLength = 2000
+)
+
+// Filter operators of a FilterShape
+const (
+	FilterEquals = "eq"    // =, IN or = ANY
+	FilterRange  = "range" // <, <=, >, >= or BETWEEN
+	FilterOther  = "other" // LIKE, <>, NOT IN and the like, which an index rarely serves
+)
+
+// FilterShape is one condition of a statement's WHERE clause comparing a column to a bound
+// parameter or literal. Only the shape is kept, never the values.
+type FilterShape struct {
+	Table    string `json:"table"`
+	Column   string `json:"column"`
+	Operator string `json:"operator"`
+}
+
+// SlowQuery aggregates the slow executions of one statement
+type SlowQuery struct {
+	Statement string        `json:"statement"`
+	Filters   []FilterShape `json:"filters"`
+	Count     int64         `json:"count"`
+	TotalMs   float64       `json:"total_ms"`
+	AvgMs     float64       `json:"avg_ms"`
+	MaxMs     float64       `json:"max_ms"`
+	LastSeen  time.Time     `json:"last_seen"`
+}
+
+// SlowQueryLog records the statements running longer than a threshold, grouped by their text
+// with literals removed
+type SlowQueryLog struct {
+	threshold atomic.Int64 // nanoseconds, read on every statement without the lock
+	mu        sync.Mutex
+	queries   map[string]*SlowQuery
+}
+
+// NewSlowQueryLog creates a slow query log. A threshold of zero or less records nothing.
+func NewSlowQueryLog(threshold time.Duration) *SlowQueryLog {
+	l := &SlowQueryLog{
+		queries: make(map[string]*SlowQuery),
+	}
+	l.threshold.Store(int64(threshold))
+	return l
+}
+
+// SetThreshold changes how long a statement runs before it is recorded; zero or less stops recording
+func (l *SlowQueryLog) SetThreshold(threshold time.Duration) {
+	l.threshold.Store(int64(threshold))
+}
+
+// Threshold returns how long a statement runs before it is recorded
+func (l *SlowQueryLog) Threshold() time.Duration {
+	return time.Duration(l.threshold.Load())
+}
+
+// Record notes one execution of a statement, keeping it if it ran past the threshold
+func (l *SlowQueryLog) Record(query string, elapsed time.Duration) {
+	threshold := l.Threshold()
+	if threshold <= 0 || elapsed < threshold {
+		return
+	}
+
+	statement := normalizeStatement(query)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.queries[statement]
+	if !ok {
+		if len(l.queries) >= maxSlowQueries {
+			l.evictOldest()
+		}
+		entry = &SlowQuery{Statement: statement, Filters: filterShapes(statement)}
+		l.queries[statement] = entry
+	}
+	ms := float64(elapsed) / float64(time.Millisecond)
+	entry.Count++
+	entry.TotalMs += ms
+	if ms > entry.MaxMs {
+		entry.MaxMs = ms
+	}
+	entry.LastSeen = time.Now().UTC()
+}
+
+// evictOldest drops the least recently seen statement. The caller holds the lock.
+func (l *SlowQueryLog) evictOldest() {
+	var oldest *SlowQuery
+	for _, entry := range l.queries {
+		if oldest == nil || entry.LastSeen.Before(oldest.LastSeen) {
+			oldest = entry
+		}
+	}
+	if oldest != nil {
+		delete(l.queries, oldest.Statement)
+	}
+}
+
+// Queries returns the recorded statements, the most total time first
+func (l *SlowQueryLog) Queries() []SlowQuery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	queries := make([]SlowQuery, 0, len(l.queries))
+	for _, entry := range l.queries {
+		query := *entry
+		query.Filters = append([]FilterShape(nil), entry.Filters...)
+		query.AvgMs = query.TotalMs / float64(query.Count)
+		queries = append(queries, query)
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].TotalMs != queries[j].TotalMs {
+			return queries[i].TotalMs > queries[j].TotalMs
+		}
+		return queries[i].Statement < queries[j].Statement
+	})
+	return queries
+}
+
+// Reset forgets the recorded statements
+func (l *SlowQueryLog) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queries = make(map[string]*SlowQuery)
+}
+
+var (
+	stringLiteralPattern  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numberLiteralPattern  = regexp.MustCompile(`([^\w$.])\d+(?:\.\d+)?\b`)
+	whitespacePattern     = regexp.MustCompile(`\s+`)
+	whereClausePattern    = regexp.MustCompile(`(?i)\bWHERE\b(.*?)(?:\bGROUP\s+BY\b|\bORDER\s+BY\b|\bHAVING\b|\bLIMIT\b|\bWINDOW\b|\bUNION\b|\bRETURNING\b|$)`)
+	tableReferencePattern = regexp.MustCompile(`(?i)\b(?:FROM|JOIN|UPDATE)\s+([a-z_][a-z0-9_]*)(?:\s+(?:AS\s+)?([a-z_][a-z0-9_]*))?`)
+	comparisonPattern     = regexp.MustCompile(`(?i)(?:\b([a-z_][a-z0-9_]*)\.)?\b([a-z_][a-z0-9_]*)\s*(<>|!=|>=|<=|=|>|<|\bNOT\s+IN\b|\bIN\b|\bNOT\s+I?LIKE\b|\bI?LIKE\b|\bBETWEEN\b)\s*(?:ANY\s*)?\(?\s*(?:\$\d+|\?)`)
+)
+
+// notAliases are the keywords that may follow a table name where an alias could
+var notAliases = map[string]bool{
+	"where": true, "join": true, "left": true, "right": true, "inner": true, "outer": true, "full": true,
+	"cross": true, "on": true, "using": true, "group": true, "order": true, "limit": true, "having": true,
+	"set": true, "union": true, "window": true, "natural": true, "returning": true, "by": true,
+}
+
+// normalizeStatement collapses whitespace and replaces literals with ?, so executions of one
+// statement with different values group together
+func normalizeStatement(query string) string {
+	statement := stringLiteralPattern.ReplaceAllString(query, "?")
+	statement = numberLiteralPattern.ReplaceAllString(statement, "${1}?")
+	statement = strings.TrimSpace(whitespacePattern.ReplaceAllString(statement, " "))
+	if len(statement) > maxStatementLength {
+		statement = statement[:maxStatementLength]
+	}
+	return statement
+}
+
+// filterShapes reads the conditions comparing a column to a value from a normalized statement's
+// WHERE clauses. Qualified columns are resolved through the table aliases; unqualified columns
+// are taken to belong to the first table read from. The shapes are a best effort, and the index
+// advisor checks each column exists before suggesting an index on it.
+func filterShapes(statement string) []FilterShape {
+	aliases := map[string]string{}
+	defaultTable := ""
+	for _, match := range tableReferencePattern.FindAllStringSubmatch(statement, -1) {
+		table := strings.ToLower(match[1])
+		if defaultTable == "" {
+			defaultTable = table
+		}
+		aliases[table] = table
+		if alias := strings.ToLower(match[2]); alias != "" && !notAliases[alias] {
+			aliases[alias] = table
+		}
+	}
+
+	seen := map[FilterShape]bool{}
+	shapes := []FilterShape{}
+	for _, clause := range whereClausePattern.FindAllStringSubmatch(statement, -1) {
+		for _, match := range comparisonPattern.FindAllStringSubmatch(clause[1], -1) {
+			table := defaultTable
+			if qualifier := strings.ToLower(match[1]); qualifier != "" {
+				table = aliases[qualifier]
+			}
+			if table == "" {
+				continue
+			}
+			shape := FilterShape{Table: table, Column: strings.ToLower(match[2]), Operator: filterOperator(match[3])}
+			if !seen[shape] {
+				seen[shape] = true
+				shapes = append(shapes, shape)
+			}
+		}
+	}
+	return shapes
+}
+
+// filterOperator classifies a comparison operator by how an index could serve it
+func filterOperator(operator string) string {
+	switch strings.ToUpper(whitespacePattern.ReplaceAllString(operator, " ")) {
+	case "=", "IN":
+		return FilterEquals
+	case "<", "<=", ">", ">=", "BETWEEN":
+		return FilterRange
+	default:
+		return FilterOther
+	}
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilterShapes(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		statement string
+		want      []FilterShape
+	}{
+		{
+			name:      "literals removed and unqualified columns on the first table",
+			query:     "SELECT *\n\tFROM incidents WHERE priority = 'P1' AND report_date >= $1 LIMIT 10",
+			statement: "SELECT * FROM incidents WHERE priority = ? AND report_date >= $1 LIMIT ?",
+			want: []FilterShape{
+				{Table: "incidents", Column: "priority", Operator: FilterEquals},
+				{Table: "incidents", Column: "report_date", Operator: FilterRange},
+			},
+		},
+		{
+			name:      "aliases resolved",
+			query:     "SELECT u.id FROM uploads u JOIN incidents AS i ON i.upload_id = u.id WHERE i.status IN ($1, $2) AND u.dataset_id = ANY($3) AND i.brief_description LIKE $4",
+			statement: "SELECT u.id FROM uploads u JOIN incidents AS i ON i.upload_id = u.id WHERE i.status IN ($1, $2) AND u.dataset_id = ANY($3) AND i.brief_description LIKE $4",
+			want: []FilterShape{
+				{Table: "incidents", Column: "status", Operator: FilterEquals},
+				{Table: "uploads", Column: "dataset_id", Operator: FilterEquals},
+				{Table: "incidents", Column: "brief_description", Operator: FilterOther},
+			},
+		},
+		{
+			name:      "update",
+			query:     "UPDATE incidents SET status = $1 WHERE upload_id = $2",
+			statement: "UPDATE incidents SET status = $1 WHERE upload_id = $2",
+			want:      []FilterShape{{Table: "incidents", Column: "upload_id", Operator: FilterEquals}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement := normalizeStatement(tt.query)
+			if statement != tt.statement {
+				t.Errorf("normalizeStatement() = %q, want %q", statement, tt.statement)
+			}
+			if got := filterShapes(statement); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterShapes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	log := NewSlowQueryLog(100 * time.Millisecond)
+	log.Record("SELECT * FROM incidents WHERE id = 'a'", 50*time.Millisecond)
+	log.Record("SELECT * FROM incidents WHERE id = 'a'", 200*time.Millisecond)
+	log.Record("SELECT * FROM incidents WHERE id = 'b'", 400*time.Millisecond)
+
+	queries := log.Queries()
+	if len(queries) != 1 {
+		t.Fatalf("expected executions with different literals grouped, got %+v", queries)
+	}
+	if queries[0].Count != 2 || queries[0].TotalMs != 600 || queries[0].AvgMs != 300 || queries[0].MaxMs != 400 {
+		t.Errorf("unexpected slow query: %+v", queries[0])
+	}
+
+	log.SetThreshold(0)
+	log.Record("SELECT * FROM uploads", time.Second)
+	if len(log.Queries()) != 1 {
+		t.Errorf("expected nothing recorded with the log disabled")
+	}
+}
+
+func TestSuggestIndexes(t *testing.T) {
+	db, err := NewDB(&Config{DatabasePath: ":memory:", SlowQueryThreshold: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+	if err := db.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	db.SlowQueries().Reset()
+
+	ctx := context.Background()
+	conn := db.GetConnection()
+	queries := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"SELECT COUNT(*) FROM incidents i WHERE i.priority = $1 AND i.status IN ($2, $3) AND i.report_date >= $4", []interface{}{"P1", "Open", "New", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		{"SELECT COUNT(*) FROM incidents WHERE status = $1 AND priority = $2", []interface{}{"Open", "P2"}},
+		// Served by the primary key and an existing index
+		{"SELECT COUNT(*) FROM uploads WHERE id = $1", []interface{}{"upload-1"}},
+		{"SELECT COUNT(*) FROM incidents WHERE upload_id = $1", []interface{}{"upload-1"}},
+	}
+	for _, q := range queries {
+		var count int
+		if err := conn.QueryRowContext(ctx, q.query, q.args...).Scan(&count); err != nil {
+			t.Fatalf("query %q failed: %v", q.query, err)
+		}
+	}
+
+	suggestions, err := db.SuggestIndexes(ctx)
+	if err != nil {
+		t.Fatalf("SuggestIndexes() error = %v", err)
+	}
+	names := []string{}
+	for _, suggestion := range suggestions {
+		names = append(names, suggestion.Name)
+	}
+	sort.Strings(names)
+	want := []string{"idx_incidents_priority_status", "idx_incidents_priority_status_report_date"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("SuggestIndexes() = %v, want %v", names, want)
+	}
+
+	mm := NewMigrationManager(db)
+	migrations := mm.GetMigrations()
+	migration := mm.IndexMigration(suggestions)
+	if migration.Version != migrations[len(migrations)-1].Version+1 || migration.Name != "add_suggested_indexes" {
+		t.Errorf("unexpected migration: %+v", migration)
+	}
+	if source := MigrationSource(migration); !strings.Contains(source, "CREATE INDEX IF NOT EXISTS idx_incidents_priority_status ON incidents(priority, status);") {
+		t.Errorf("expected the index statements in the migration source, got %s", source)
+	}
+	if err := mm.ApplyIndexMigration(ctx, migration); err != nil {
+		t.Fatalf("ApplyIndexMigration() error = %v", err)
+	}
+
+	suggestions, err = db.SuggestIndexes(ctx)
+	if err != nil {
+		t.Fatalf("SuggestIndexes() error = %v", err)
+	}
+	if len(suggestions) != 0 {
+		t.Errorf("expected no suggestions once the indexes exist, got %+v", suggestions)
+	}
+}
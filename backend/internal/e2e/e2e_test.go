@@ -0,0 +1,117 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/services"
+)
+
+func TestUploadToAnalytics(t *testing.T) {
+	h := New(t)
+
+	fixture := WriteFixture(t, []FixtureIncident{
+		{IncidentID: "INC001", ReportDate: "2024-01-15 09:00:00", ResolveDate: "2024-01-15 17:00:00", Application: "Portal",
+			Priority: "P1", Status: "Closed", Description: "Portal down for all users", ResolutionGroup: "Web Team", Resolver: "Jane"},
+		{IncidentID: "INC002", ReportDate: "2024-01-15 10:00:00", Application: "Portal",
+			Priority: "P3", Status: "Open", Description: "Slow page load", ResolutionGroup: "Web Team"},
+		{IncidentID: "INC003", ReportDate: "2024-01-16 11:00:00", ResolveDate: "2024-01-17 11:00:00", Application: "Payroll",
+			Priority: "P3", Status: "Closed", Description: "Password reset request", ResolutionGroup: "Service Desk", Resolver: "Sam"},
+	})
+	uploadID := h.Ingest(fixture)
+
+	if count := h.Incidents(uploadID); count != 3 {
+		t.Fatalf("Expected 3 stored incidents, got %d", count)
+	}
+
+	var priorities []services.PriorityAnalysis
+	h.GetData("/api/analytics/priority", &priorities)
+	counts := map[string]int{}
+	for _, priority := range priorities {
+		counts[priority.Priority] = priority.Count
+	}
+	if counts["P1"] != 1 || counts["P3"] != 2 {
+		t.Errorf("Expected 1 P1 and 2 P3 incidents, got %+v", priorities)
+	}
+
+	var timeline []services.TimelineData
+	h.GetData("/api/analytics/timeline/daily?start_date=2024-01-15&end_date=2024-01-16", &timeline)
+	days := map[string]int{}
+	for _, day := range timeline {
+		days[day.Date] = day.IncidentCount
+	}
+	if days["2024-01-15"] != 2 || days["2024-01-16"] != 1 {
+		t.Errorf("Expected 2 and 1 incidents on the fixture days, got %+v", timeline)
+	}
+
+	// A processed upload cannot be processed again
+	h.Request(http.MethodPost, "/api/uploads/"+uploadID+"/process", nil).RequireStatus(http.StatusBadRequest)
+
+	// Each status the upload moved through was recorded
+	var events []services.UploadEvent
+	h.GetData("/api/v2/uploads/"+uploadID+"/events", &events)
+	var statuses []string
+	for _, event := range events {
+		statuses = append(statuses, event.FromStatus+">"+event.ToStatus)
+	}
+	if len(statuses) != 3 || statuses[0] != "uploaded>queued" || statuses[1] != "queued>processing" || statuses[2] != "processing>completed" {
+		t.Errorf("Expected the upload to be queued, processed and completed, got %v", statuses)
+	}
+	h.Request(http.MethodGet, "/api/v1/uploads/"+uploadID+"/events", nil).RequireStatus(http.StatusNotFound)
+}
+
+func TestReplaceFailedUploadFile(t *testing.T) {
+	h := New(t)
+
+	// A header the importer does not recognise leaves every row without an incident ID
+	broken := WriteWorkbook(t, "broken.xlsx", [][]string{
+		{"Ref", "Report Date", "Application Name", "Priority", "Brief Description"},
+		{"INC101", "2024-02-01 09:00:00", "Portal", "P2", "Login failure"},
+		{"INC102", "2024-02-02 09:00:00", "Portal", "P3", "Slow search"},
+	})
+	uploadID := h.UploadFile(broken)
+	h.ProcessUpload(uploadID, nil)
+	if progress := h.WaitForProcessing(uploadID); progress.Status != models.UploadStatusFailed {
+		t.Fatalf("Expected the broken upload to fail, got %s", progress.Status)
+	}
+
+	fixed := WriteWorkbook(t, "fixed.xlsx", [][]string{
+		{"Incident ID", "Report Date", "Application Name", "Priority", "Brief Description"},
+		{"INC101", "2024-02-01 09:00:00", "Portal", "P2", "Login failure"},
+		{"INC102", "2024-02-02 09:00:00", "Portal", "P3", "Slow search"},
+	})
+	h.ReplaceFile(uploadID, fixed)
+	if progress := h.WaitForProcessing(uploadID); progress.Status != models.UploadStatusCompleted {
+		t.Fatalf("Expected the fixed file to complete, got %s: %v", progress.Status, progress.Errors)
+	}
+	if count := h.Incidents(uploadID); count != 2 {
+		t.Errorf("Expected 2 stored incidents, got %d", count)
+	}
+
+	var result struct {
+		Upload models.Upload `json:"upload"`
+	}
+	h.GetJSON("/api/v2/uploads/"+uploadID, &result)
+	if upload := result.Upload; upload.OriginalFilename != "fixed.xlsx" {
+		t.Errorf("Expected the upload to name the new file, got %s", upload.OriginalFilename)
+	}
+
+	// Only failed uploads have their file replaced
+	body, contentType := h.fileForm(fixed)
+	h.Request(http.MethodPut, "/api/v2/uploads/"+uploadID+"/file", body, "Content-Type", contentType).
+		RequireStatus(http.StatusBadRequest)
+	body, contentType = h.fileForm(fixed)
+	h.Request(http.MethodPut, "/api/v2/uploads/missing/file", body, "Content-Type", contentType).
+		RequireStatus(http.StatusNotFound)
+}
+
+func TestAdminEndpointsRequireToken(t *testing.T) {
+	h := New(t)
+
+	h.Request(http.MethodGet, "/api/admin/config", nil).RequireStatus(http.StatusUnauthorized)
+	body := h.AdminRequest(http.MethodGet, "/api/admin/config", nil).RequireStatus(http.StatusOK).JSON()
+	if _, ok := body["data"]; !ok {
+		t.Errorf("Expected the settings in the data field, got %v", body)
+	}
+}
@@ -0,0 +1,336 @@
+// Package e2e boots the full API server against an in-memory database and temporary storage, so
+// end-to-end tests can drive new endpoints through the real router and middleware.
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/server"
+	"incident-management-system/internal/services"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// AdminToken is the admin token of harness servers
+const AdminToken = "e2e-admin-token"
+
+// DefaultWaitTimeout bounds how long WaitForProcessing polls an upload
+const DefaultWaitTimeout = 30 * time.Second
+
+// initLogging quiets the global logger of the test binary once
+var initLogging sync.Once
+
+// Harness is a running API server with its own in-memory database. It is closed when the test
+// finishes.
+type Harness struct {
+	t      testing.TB
+	Server *server.Server
+	HTTP   *httptest.Server
+	// WaitTimeout bounds how long WaitForProcessing polls an upload
+	WaitTimeout time.Duration
+}
+
+// New boots the full router with an in-memory database and temporary upload, export, archive and
+// dump directories
+func New(t testing.TB) *Harness {
+	t.Helper()
+	initLogging.Do(func() {
+		config := logging.DefaultConfig()
+		config.Level = logging.LevelError
+		if err := logging.InitGlobalLogger(config); err != nil {
+			t.Fatalf("Failed to initialize logger: %v", err)
+		}
+	})
+
+	dir := t.TempDir()
+	srv, err := server.New(context.Background(), server.Config{
+		DatabasePath: ":memory:",
+		UploadDir:    filepath.Join(dir, "uploads"),
+		ExportDir:    filepath.Join(dir, "exports"),
+		ArchiveDir:   filepath.Join(dir, "archive"),
+		DumpDir:      filepath.Join(dir, "dumps"),
+		AdminToken:   AdminToken,
+	})
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	h := &Harness{
+		t:           t,
+		Server:      srv,
+		HTTP:        httptest.NewServer(srv.Router),
+		WaitTimeout: DefaultWaitTimeout,
+	}
+	t.Cleanup(func() {
+		h.HTTP.Close()
+		srv.Close()
+	})
+	return h
+}
+
+// Response is a completed request with its body read
+type Response struct {
+	t          testing.TB
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Request sends a request to the server. A non-nil body is sent as is when it is an io.Reader
+// and encoded as JSON otherwise. Headers are given as name and value pairs.
+func (h *Harness) Request(method, path string, body interface{}, headers ...string) *Response {
+	h.t.Helper()
+	if len(headers)%2 != 0 {
+		h.t.Fatalf("Headers must be name and value pairs, got %v", headers)
+	}
+
+	var reader io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			h.t.Fatalf("Failed to encode request body: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequest(method, h.HTTP.URL+path, reader)
+	if err != nil {
+		h.t.Fatalf("Failed to create request: %v", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for i := 0; i < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	resp, err := h.HTTP.Client().Do(req)
+	if err != nil {
+		h.t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		h.t.Fatalf("Failed to read response of %s %s: %v", method, path, err)
+	}
+
+	return &Response{t: h.t, StatusCode: resp.StatusCode, Header: resp.Header, Body: data}
+}
+
+// AdminRequest sends a request authorized with the admin token
+func (h *Harness) AdminRequest(method, path string, body interface{}, headers ...string) *Response {
+	h.t.Helper()
+	return h.Request(method, path, body, append([]string{"Authorization", "Bearer " + AdminToken}, headers...)...)
+}
+
+// GetJSON sends a GET request, requires a 200 response and decodes its body into v
+func (h *Harness) GetJSON(path string, v interface{}) {
+	h.t.Helper()
+	h.Request(http.MethodGet, path, nil).RequireStatus(http.StatusOK).Decode(v)
+}
+
+// GetData sends a GET request, requires a 200 response and decodes its data field into v, as
+// returned by the analytics endpoints
+func (h *Harness) GetData(path string, v interface{}) {
+	h.t.Helper()
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	h.GetJSON(path, &envelope)
+	if len(envelope.Data) == 0 {
+		h.t.Fatalf("Expected a data field in the response of %s", path)
+	}
+	if err := json.Unmarshal(envelope.Data, v); err != nil {
+		h.t.Fatalf("Failed to decode data of %s: %v", path, err)
+	}
+}
+
+// RequireStatus fails the test unless the response has the given status
+func (r *Response) RequireStatus(status int) *Response {
+	r.t.Helper()
+	if r.StatusCode != status {
+		r.t.Fatalf("Expected status %d, got %d: %s", status, r.StatusCode, r.Body)
+	}
+	return r
+}
+
+// Decode decodes the JSON body into v
+func (r *Response) Decode(v interface{}) {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("Failed to decode response %s: %v", r.Body, err)
+	}
+}
+
+// JSON returns the JSON body as a map
+func (r *Response) JSON() map[string]interface{} {
+	r.t.Helper()
+	var body map[string]interface{}
+	r.Decode(&body)
+	return body
+}
+
+// UploadFile uploads a spreadsheet through POST /api/uploads and returns the upload ID
+func (h *Harness) UploadFile(path string) string {
+	h.t.Helper()
+	body, contentType := h.fileForm(path)
+
+	var result struct {
+		Upload models.Upload `json:"upload"`
+	}
+	h.Request(http.MethodPost, "/api/uploads", body, "Content-Type", contentType).
+		RequireStatus(http.StatusCreated).Decode(&result)
+	return result.Upload.ID
+}
+
+// ReplaceFile replaces the file of a failed upload through PUT /api/v2/uploads/:id/file, which
+// processes it again
+func (h *Harness) ReplaceFile(uploadID, path string) {
+	h.t.Helper()
+	body, contentType := h.fileForm(path)
+	h.Request(http.MethodPut, "/api/v2/uploads/"+uploadID+"/file", body, "Content-Type", contentType).
+		RequireStatus(http.StatusAccepted)
+}
+
+// fileForm builds a multipart body holding the file at path and returns it with its content type
+func (h *Harness) fileForm(path string) (*bytes.Buffer, string) {
+	h.t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		h.t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		h.t.Fatalf("Failed to create form file: %v", err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		h.t.Fatalf("Failed to copy %s: %v", path, err)
+	}
+	if err := writer.Close(); err != nil {
+		h.t.Fatalf("Failed to close multipart body: %v", err)
+	}
+	return &body, writer.FormDataContentType()
+}
+
+// ProcessUpload starts processing an upload with the given options, which may be nil
+func (h *Harness) ProcessUpload(uploadID string, options interface{}) {
+	h.t.Helper()
+	h.Request(http.MethodPost, "/api/uploads/"+uploadID+"/process", options).RequireStatus(http.StatusAccepted)
+}
+
+// WaitForProcessing polls the upload's status until processing has finished and returns it
+func (h *Harness) WaitForProcessing(uploadID string) services.ProcessingProgress {
+	h.t.Helper()
+	deadline := time.Now().Add(h.WaitTimeout)
+	for {
+		var result struct {
+			Status services.ProcessingProgress `json:"status"`
+		}
+		h.GetJSON("/api/uploads/"+uploadID+"/status", &result)
+		if models.IsUploadFinished(result.Status.Status) {
+			return result.Status
+		}
+		if time.Now().After(deadline) {
+			h.t.Fatalf("Upload %s still %s after %s", uploadID, result.Status.Status, h.WaitTimeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Ingest uploads a spreadsheet, processes it with default options and requires processing to
+// complete, with or without rejected rows. It returns the upload ID.
+func (h *Harness) Ingest(path string) string {
+	h.t.Helper()
+	uploadID := h.UploadFile(path)
+	h.ProcessUpload(uploadID, nil)
+	if progress := h.WaitForProcessing(uploadID); progress.Status == models.UploadStatusFailed {
+		h.t.Fatalf("Expected upload %s to complete, got %s: %v", uploadID, progress.Status, progress.Errors)
+	}
+	return uploadID
+}
+
+// FixtureIncident is one row of a fixture workbook. Dates are written as given, such as
+// "2024-01-15 09:00:00".
+type FixtureIncident struct {
+	IncidentID      string
+	ReportDate      string
+	ResolveDate     string
+	Application     string
+	Priority        string
+	Status          string
+	Description     string
+	ResolutionGroup string
+	Resolver        string
+}
+
+// fixtureHeader is the header row of fixture workbooks
+var fixtureHeader = []string{"Incident ID", "Report Date", "Resolve Date", "Application Name", "Priority",
+	"Status", "Brief Description", "Resolution Group", "Resolved Person"}
+
+// WriteFixture writes incidents to a new workbook in a temporary directory and returns its path
+func WriteFixture(t testing.TB, incidents []FixtureIncident) string {
+	t.Helper()
+	rows := [][]string{fixtureHeader}
+	for _, incident := range incidents {
+		rows = append(rows, []string{incident.IncidentID, incident.ReportDate, incident.ResolveDate,
+			incident.Application, incident.Priority, incident.Status, incident.Description,
+			incident.ResolutionGroup, incident.Resolver})
+	}
+	return WriteWorkbook(t, "incidents.xlsx", rows)
+}
+
+// WriteWorkbook writes rows, the first being the header, to Sheet1 of a new workbook in a
+// temporary directory and returns its path
+func WriteWorkbook(t testing.TB, filename string, rows [][]string) string {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			t.Fatalf("Failed to resolve cell name: %v", err)
+		}
+		if err := f.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatalf("Failed to write row %d: %v", i+1, err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), filename)
+	if err := f.SaveAs(path); err != nil {
+		t.Fatalf("Failed to save workbook: %v", err)
+	}
+	return path
+}
+
+// Incidents returns the number of incidents stored by an upload
+func (h *Harness) Incidents(uploadID string) int {
+	h.t.Helper()
+	var count int
+	if err := h.Server.DB.GetConnection().QueryRow("SELECT COUNT(*) FROM incidents WHERE upload_id = ?", uploadID).Scan(&count); err != nil {
+		h.t.Fatalf("Failed to count incidents: %v", err)
+	}
+	return count
+}
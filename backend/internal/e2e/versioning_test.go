@@ -0,0 +1,96 @@
+package e2e
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/handlers"
+)
+
+// updateRoutes rewrites the frozen v1 route list after an intended change to v1
+var updateRoutes = flag.Bool("update", false, "rewrite the frozen v1 route list from the current router")
+
+// TestAPIv1RoutesFrozen fails when a v1 route is added, removed or renamed. v1 keeps the shape
+// existing clients rely on; new and changed endpoints belong in a later version.
+func TestAPIv1RoutesFrozen(t *testing.T) {
+	h := New(t)
+
+	prefix := handlers.APIVersionPath(handlers.APIVersion1) + "/"
+	var routes []string
+	for _, route := range h.Server.Router.Routes() {
+		if strings.HasPrefix(route.Path, prefix) {
+			routes = append(routes, route.Method+" "+route.Path)
+		}
+	}
+	sort.Strings(routes)
+	current := strings.Join(routes, "\n") + "\n"
+
+	path := filepath.Join("testdata", "v1_routes.golden")
+	if *updateRoutes {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create testdata directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(current), 0644); err != nil {
+			t.Fatalf("Failed to write frozen routes: %v", err)
+		}
+	}
+	frozen, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read frozen routes (run with -update to create them): %v", err)
+	}
+	if string(frozen) != current {
+		t.Errorf("v1 routes changed; add new endpoints to v2 instead, or run with -update after an intended change.\nfrozen:\n%s\ncurrent:\n%s", frozen, current)
+	}
+}
+
+func TestAPIVersions(t *testing.T) {
+	h := New(t)
+
+	for _, path := range []string{"/api/v1/uploads", "/api/v2/uploads", "/api/uploads"} {
+		response := h.Request(http.MethodGet, path, nil).RequireStatus(http.StatusOK)
+		expected := handlers.APIVersion1
+		if strings.HasPrefix(path, "/api/v2/") {
+			expected = handlers.APIVersion2
+		}
+		if version := response.Header.Get(handlers.APIVersionHeader); version != expected {
+			t.Errorf("Expected %s to be served by %s, got %q", path, expected, version)
+		}
+	}
+
+	legacy := h.Request(http.MethodGet, "/api/uploads", nil)
+	if legacy.Header.Get("Deprecation") == "" {
+		t.Error("Expected unversioned routes to be flagged as deprecated")
+	}
+	if link := legacy.Header.Get("Link"); link != `</api/v1/uploads>; rel="successor-version"` {
+		t.Errorf("Expected a successor link to /api/v1/uploads, got %q", link)
+	}
+	if current := h.Request(http.MethodGet, "/api/v1/uploads", nil); current.Header.Get("Deprecation") != "" {
+		t.Error("Expected versioned routes not to be deprecated")
+	}
+
+	// Lists after v1 are enveloped with pagination metadata
+	body := h.Request(http.MethodGet, "/api/v2/uploads?per_page=10", nil).RequireStatus(http.StatusOK).JSON()
+	meta, _ := body["meta"].(map[string]interface{})
+	if data, ok := body["data"].([]interface{}); !ok || len(data) != 0 || meta["total"] != float64(0) || meta["per_page"] != float64(10) {
+		t.Errorf("Expected an empty page of uploads with its metadata, got %v", body)
+	}
+	legacyBody := h.Request(http.MethodGet, "/api/v1/uploads", nil).RequireStatus(http.StatusOK).JSON()
+	if _, ok := legacyBody["uploads"]; !ok {
+		t.Errorf("Expected v1 to keep the uploads key, got %v", legacyBody)
+	}
+
+	// Admin routes stay behind the admin token in every version
+	h.Request(http.MethodGet, "/api/v2/admin/config", nil).RequireStatus(http.StatusUnauthorized)
+	h.AdminRequest(http.MethodGet, "/api/v2/admin/config", nil).RequireStatus(http.StatusOK)
+
+	var versions []handlers.APIVersionInfo
+	h.GetData("/api/versions", &versions)
+	if len(versions) != 3 || versions[1].Version != handlers.APIVersion2 || versions[1].Status != "current" {
+		t.Errorf("Expected v1, v2 and the unversioned routes, got %+v", versions)
+	}
+}
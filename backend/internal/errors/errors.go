@@ -265,6 +265,7 @@ func ErrorHandler() gin.HandlerFunc {
 				WithMethod(c.Request.Method)
 			
 			// Send error response
+			c.Set(sentErrorKey, apiError)
 			c.JSON(apiError.GetHTTPStatus(), apiError)
 			return
 		}
@@ -283,10 +284,34 @@ func SendError(c *gin.Context, err *APIError) {
 	if err.Method == "" {
 		err.WithMethod(c.Request.Method)
 	}
-	
+	c.Set(sentErrorKey, err)
+
+	if c.GetBool(envelopeKey) {
+		c.JSON(err.GetHTTPStatus(), gin.H{"data": nil, "errors": []*APIError{err}})
+		return
+	}
 	c.JSON(err.GetHTTPStatus(), err)
 }
 
+// sentErrorKey holds the error a request was answered with
+const sentErrorKey = "sent_error"
+
+// SentError returns the error the request was answered with, or nil when it succeeded
+func SentError(c *gin.Context) *APIError {
+	err, _ := c.Get(sentErrorKey)
+	apiErr, _ := err.(*APIError)
+	return apiErr
+}
+
+// envelopeKey marks requests whose errors are sent in the response envelope
+const envelopeKey = "error_envelope"
+
+// UseEnvelope makes SendError send the errors of the request in the response envelope of
+// versioned APIs, {"data": null, "errors": [error]}, instead of as the whole body
+func UseEnvelope(c *gin.Context) {
+	c.Set(envelopeKey, true)
+}
+
 // AbortWithError aborts the request with an error
 func AbortWithError(c *gin.Context, err *APIError) {
 	SendError(c, err)
@@ -302,6 +327,7 @@ func RecoveryHandler() gin.HandlerFunc {
 			WithMethod(c.Request.Method).
 			WithDetails(fmt.Sprintf("Panic: %v", recovered))
 		
+		c.Set(sentErrorKey, err)
 		c.JSON(err.GetHTTPStatus(), err)
 		c.Abort()
 	})
@@ -1,9 +1,9 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
-	"strings"
 	"time"
 
 	"incident-management-system/internal/errors"
@@ -43,44 +43,52 @@ func NewAnalyticsHandler(db *sql.DB) *AnalyticsHandler {
 	}
 }
 
-// parseTimelineFilters parses query parameters into TimelineFilters
-func parseTimelineFilters(c *gin.Context) (*services.TimelineFilters, error) {
-	filters := &services.TimelineFilters{}
+// SetArchiveStore makes analytics read archived incidents when a date range spans them
+func (h *AnalyticsHandler) SetArchiveStore(archive *services.ArchiveStore) {
+	h.analyticsService.SetArchiveStore(archive)
+}
 
-	// Parse start_date
-	if startDateStr := c.Query("start_date"); startDateStr != "" {
-		startDate, err := time.Parse("2006-01-02", startDateStr)
-		if err != nil {
-			return nil, err
-		}
-		filters.StartDate = &startDate
-	}
+// SetFeatureFlags makes archive federation follow the flag of each request's tenant
+func (h *AnalyticsHandler) SetFeatureFlags(flags *services.FeatureFlagService) {
+	h.analyticsService.SetFeatureFlags(flags)
+}
 
-	// Parse end_date
-	if endDateStr := c.Query("end_date"); endDateStr != "" {
-		endDate, err := time.Parse("2006-01-02", endDateStr)
-		if err != nil {
-			return nil, err
-		}
-		filters.EndDate = &endDate
-	}
+// SetCacheTTL changes how long analytics results are cached
+func (h *AnalyticsHandler) SetCacheTTL(ttl time.Duration) {
+	h.analyticsService.SetTTL(ttl)
+}
 
-	// Parse priorities
-	if prioritiesStr := c.Query("priorities"); prioritiesStr != "" {
-		filters.Priorities = strings.Split(prioritiesStr, ",")
-	}
+// CacheWarmer returns the analytics cache, for warming it in the background
+func (h *AnalyticsHandler) CacheWarmer() services.CacheWarmer {
+	return h.analyticsService
+}
 
-	// Parse applications
-	if applicationsStr := c.Query("applications"); applicationsStr != "" {
-		filters.Applications = strings.Split(applicationsStr, ",")
+// parseTimelineFilters binds and validates the shared analytics query parameters.
+// On failure the validation response has already been sent.
+func parseTimelineFilters(c *gin.Context) (*services.TimelineFilters, bool) {
+	var query AnalyticsQuery
+	if !bindQuery(c, &query) {
+		return nil, false
 	}
+	return query.ToFilters(), true
+}
 
-	// Parse statuses
-	if statusesStr := c.Query("statuses"); statusesStr != "" {
-		filters.Statuses = strings.Split(statusesStr, ",")
+// parseRankedFilters is parseTimelineFilters for endpoints whose lists accept a limit
+func parseRankedFilters(c *gin.Context) (*services.TimelineFilters, bool) {
+	var query RankedAnalyticsQuery
+	if !bindQuery(c, &query) {
+		return nil, false
 	}
+	return query.ToFilters(), true
+}
 
-	return filters, nil
+// groupedFilters reports the filters of an analytics list in its response metadata together with
+// the period or organization level its entries are grouped by
+type groupedFilters struct {
+	Period string `json:"period,omitempty"`
+	Level  string `json:"level,omitempty"`
+	Unit   string `json:"unit,omitempty"`
+	*services.TimelineFilters
 }
 
 // sendError is a helper function to send error responses
@@ -96,12 +104,13 @@ func (h *AnalyticsHandler) GetDailyTimeline(c *gin.Context) {
 
 	logger.Info("Getting daily timeline")
 
-	filters, err := parseTimelineFilters(c)
-	if err != nil {
-		apiErr := errors.NewAPIError(errors.ErrInvalidDateFormat, "Invalid date format. Use YYYY-MM-DD").
-			WithDetails(err.Error()).
-			WithUserMessage("Please use the correct date format (YYYY-MM-DD)")
-		errors.SendError(c, apiErr)
+	var query TimelineQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+	if wantsNDJSON(c) {
+		h.streamTimeline(c, "get_daily_timeline", "daily", filters, h.analyticsService.StreamDailyTimeline)
 		return
 	}
 
@@ -120,7 +129,7 @@ func (h *AnalyticsHandler) GetDailyTimeline(c *gin.Context) {
 
 	monitoring.UpdatePerformance(time.Since(start))
 
-	c.JSON(http.StatusOK, gin.H{
+	sendList(c, timeline, filters, gin.H{
 		"data":    timeline,
 		"filters": filters,
 		"count":   len(timeline),
@@ -132,11 +141,13 @@ func (h *AnalyticsHandler) GetWeeklyTimeline(c *gin.Context) {
 	start := time.Now()
 	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_weekly_timeline")
 
-	filters, err := parseTimelineFilters(c)
-	if err != nil {
-		apiErr := errors.NewAPIError(errors.ErrInvalidDateFormat, "Invalid date format. Use YYYY-MM-DD").
-			WithDetails(err.Error())
-		errors.SendError(c, apiErr)
+	var query TimelineQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+	if wantsNDJSON(c) {
+		h.streamTimeline(c, "get_weekly_timeline", "weekly", filters, h.analyticsService.StreamWeeklyTimeline)
 		return
 	}
 
@@ -151,34 +162,93 @@ func (h *AnalyticsHandler) GetWeeklyTimeline(c *gin.Context) {
 	logger.LogDuration("get_weekly_timeline", start)
 	monitoring.UpdatePerformance(time.Since(start))
 
-	c.JSON(http.StatusOK, gin.H{
+	sendList(c, timeline, filters, gin.H{
 		"data":    timeline,
 		"filters": filters,
 		"count":   len(timeline),
 	})
 }
 
-// GetTrendAnalysis handles GET /api/analytics/trends
-func (h *AnalyticsHandler) GetTrendAnalysis(c *gin.Context) {
+// GetHourlyTimeline handles GET /api/v2/analytics/timeline/hourly
+func (h *AnalyticsHandler) GetHourlyTimeline(c *gin.Context) {
 	start := time.Now()
-	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_trend_analysis")
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_hourly_timeline")
 
-	period := c.DefaultQuery("period", "daily")
-	if period != "daily" && period != "weekly" {
-		apiErr := errors.NewAPIError(errors.ErrInvalidParameter, "Period must be 'daily' or 'weekly'").
-			WithUserMessage("Please specify a valid period: 'daily' or 'weekly'")
-		errors.SendError(c, apiErr)
+	var query HourlyTimelineQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+	if wantsNDJSON(c) {
+		h.streamTimeline(c, "get_hourly_timeline", "hourly", filters, h.analyticsService.StreamHourlyTimeline)
 		return
 	}
 
-	filters, err := parseTimelineFilters(c)
+	timeline, err := h.analyticsService.GetHourlyTimeline(c.Request.Context(), filters)
 	if err != nil {
-		apiErr := errors.NewAPIError(errors.ErrInvalidDateFormat, "Invalid date format. Use YYYY-MM-DD").
-			WithDetails(err.Error())
+		apiErr := errors.DatabaseError("retrieve hourly timeline", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_hourly_timeline")
 		errors.SendError(c, apiErr)
 		return
 	}
 
+	logger.LogDuration("get_hourly_timeline", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"count": len(timeline),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	sendList(c, timeline, filters, gin.H{
+		"data":    timeline,
+		"filters": filters,
+		"count":   len(timeline),
+	})
+}
+
+// streamTimeline sends a timeline as NDJSON, a period per line as it is read from the database,
+// so long ranges are neither cached nor held in memory
+func (h *AnalyticsHandler) streamTimeline(c *gin.Context, operation, name string, filters *services.TimelineFilters,
+	streamFn func(context.Context, *services.TimelineFilters, func(services.TimelineData) error) error) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation(operation)
+
+	stream := newNDJSONStream(c)
+	err := streamFn(c.Request.Context(), filters, func(data services.TimelineData) error {
+		return stream.Write(data)
+	})
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve "+name+" timeline", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", operation)
+		stream.Close(apiErr)
+		return
+	}
+	stream.Close(nil)
+
+	logger.LogDuration(operation, start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"count":    stream.lines,
+			"streamed": true,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+}
+
+// GetTrendAnalysis handles GET /api/analytics/trends
+func (h *AnalyticsHandler) GetTrendAnalysis(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_trend_analysis")
+
+	var query TrendQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	period := query.Period
+	if period == "" {
+		period = "daily"
+	}
+	filters := query.ToFilters()
+
 	trends, err := h.analyticsService.GetTrendAnalysis(c.Request.Context(), period, filters)
 	if err != nil {
 		apiErr := errors.DatabaseError("retrieve trend analysis", err)
@@ -195,7 +265,7 @@ func (h *AnalyticsHandler) GetTrendAnalysis(c *gin.Context) {
 
 	monitoring.UpdatePerformance(time.Since(start))
 
-	c.JSON(http.StatusOK, gin.H{
+	sendList(c, trends, groupedFilters{Period: period, TimelineFilters: filters}, gin.H{
 		"data":    trends,
 		"period":  period,
 		"filters": filters,
@@ -205,9 +275,8 @@ func (h *AnalyticsHandler) GetTrendAnalysis(c *gin.Context) {
 
 // GetTicketsPerDayMetrics handles GET /api/analytics/metrics/daily
 func (h *AnalyticsHandler) GetTicketsPerDayMetrics(c *gin.Context) {
-	filters, err := parseTimelineFilters(c)
-	if err != nil {
-		sendError(c, "INVALID_DATE_FORMAT", "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest, err.Error())
+	filters, ok := parseTimelineFilters(c)
+	if !ok {
 		return
 	}
 
@@ -225,9 +294,8 @@ func (h *AnalyticsHandler) GetTicketsPerDayMetrics(c *gin.Context) {
 
 // GetTicketsPerWeekMetrics handles GET /api/analytics/metrics/weekly
 func (h *AnalyticsHandler) GetTicketsPerWeekMetrics(c *gin.Context) {
-	filters, err := parseTimelineFilters(c)
-	if err != nil {
-		sendError(c, "INVALID_DATE_FORMAT", "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest, err.Error())
+	filters, ok := parseTimelineFilters(c)
+	if !ok {
 		return
 	}
 
@@ -245,9 +313,8 @@ func (h *AnalyticsHandler) GetTicketsPerWeekMetrics(c *gin.Context) {
 
 // GetTimelineOverview handles GET /api/analytics/timeline/overview
 func (h *AnalyticsHandler) GetTimelineOverview(c *gin.Context) {
-	filters, err := parseTimelineFilters(c)
-	if err != nil {
-		sendError(c, "INVALID_DATE_FORMAT", "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest, err.Error())
+	filters, ok := parseTimelineFilters(c)
+	if !ok {
 		return
 	}
 
@@ -307,9 +374,8 @@ func (h *AnalyticsHandler) GetTimelineOverview(c *gin.Context) {
 
 // GetPriorityAnalysis handles GET /api/analytics/priority
 func (h *AnalyticsHandler) GetPriorityAnalysis(c *gin.Context) {
-	filters, err := parseTimelineFilters(c)
-	if err != nil {
-		sendError(c, "INVALID_DATE_FORMAT", "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest, err.Error())
+	filters, ok := parseTimelineFilters(c)
+	if !ok {
 		return
 	}
 
@@ -319,7 +385,7 @@ func (h *AnalyticsHandler) GetPriorityAnalysis(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	sendList(c, analysis, filters, gin.H{
 		"data":    analysis,
 		"filters": filters,
 		"count":   len(analysis),
@@ -328,11 +394,11 @@ func (h *AnalyticsHandler) GetPriorityAnalysis(c *gin.Context) {
 
 // GetApplicationAnalysis handles GET /api/analytics/applications
 func (h *AnalyticsHandler) GetApplicationAnalysis(c *gin.Context) {
-	filters, err := parseTimelineFilters(c)
-	if err != nil {
-		sendError(c, "INVALID_DATE_FORMAT", "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest, err.Error())
+	var query ApplicationAnalyticsQuery
+	if !bindQuery(c, &query) {
 		return
 	}
+	filters := query.ToFilters()
 
 	analysis, err := h.analyticsService.GetApplicationAnalysis(c.Request.Context(), filters)
 	if err != nil {
@@ -340,22 +406,251 @@ func (h *AnalyticsHandler) GetApplicationAnalysis(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	sendList(c, analysis, filters, gin.H{
+		"data":    analysis,
+		"filters": filters,
+		"count":   len(analysis),
+	})
+}
+
+// GetRegionAnalysis handles GET /api/analytics/regions
+func (h *AnalyticsHandler) GetRegionAnalysis(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_region_analysis")
+
+	var query ApplicationAnalyticsQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+
+	analysis, err := h.analyticsService.GetRegionAnalysis(c.Request.Context(), filters)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve region analysis", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_region_analysis")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_region_analysis", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"count": len(analysis),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	sendList(c, analysis, filters, gin.H{
+		"data":    analysis,
+		"filters": filters,
+		"count":   len(analysis),
+	})
+}
+
+// GetGroupAnalysis handles GET /api/analytics/groups
+func (h *AnalyticsHandler) GetGroupAnalysis(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_group_analysis")
+
+	var query GroupAnalyticsQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	level := query.Level
+	if level == "" {
+		level = services.OrgLevelGroup
+	}
+	filters := query.ToFilters()
+
+	analysis, err := h.analyticsService.GetGroupAnalysis(c.Request.Context(), level, query.Unit, filters)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve group analysis", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_group_analysis")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_group_analysis", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"level": level,
+			"unit":  query.Unit,
+			"count": len(analysis),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	sendList(c, analysis, groupedFilters{Level: level, Unit: query.Unit, TimelineFilters: filters}, gin.H{
+		"data":    analysis,
+		"level":   level,
+		"filters": filters,
+		"count":   len(analysis),
+	})
+}
+
+// GetServiceAnalysis handles GET /api/analytics/services
+func (h *AnalyticsHandler) GetServiceAnalysis(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_service_analysis")
+
+	var query ServiceAnalyticsQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	level := query.Level
+	if level == "" {
+		level = services.ServiceLevelService
+	}
+	filters := query.ToFilters()
+
+	analysis, err := h.analyticsService.GetServiceAnalysis(c.Request.Context(), level, query.Unit, filters)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve service analysis", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_service_analysis")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_service_analysis", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"level": level,
+			"unit":  query.Unit,
+			"count": len(analysis),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	sendList(c, analysis, groupedFilters{Level: level, Unit: query.Unit, TimelineFilters: filters}, gin.H{
 		"data":    analysis,
+		"level":   level,
 		"filters": filters,
 		"count":   len(analysis),
 	})
 }
 
+// GetNoiseAnalysis handles GET /api/analytics/noise
+func (h *AnalyticsHandler) GetNoiseAnalysis(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_noise_analysis")
+
+	filters, ok := parseRankedFilters(c)
+	if !ok {
+		return
+	}
+
+	analysis, err := h.analyticsService.GetNoiseAnalysis(c.Request.Context(), filters)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve noise analysis", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_noise_analysis")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_noise_analysis", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"source_mapped": analysis.SourceMapped,
+			"source_count":  len(analysis.Sources),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    analysis,
+		"filters": filters,
+	})
+}
+
+// GetBenchmark handles GET /api/analytics/benchmark
+func (h *AnalyticsHandler) GetBenchmark(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_benchmark")
+
+	var query BenchmarkQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	dimension := query.Dimension
+	if dimension == "" {
+		dimension = services.BenchmarkByApplication
+	}
+	filters := query.ToFilters()
+	opts := services.BenchmarkOptions{
+		MinIncidents: query.MinIncidents,
+		ZThreshold:   query.ZThreshold,
+	}
+
+	report, err := h.analyticsService.GetBenchmark(c.Request.Context(), dimension, filters, opts)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve benchmark", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_benchmark")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_benchmark", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"dimension":     dimension,
+			"entity_count":  report.EntityCount,
+			"outlier_count": report.OutlierCount,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    report,
+		"filters": filters,
+	})
+}
+
+// GetCapacityPlan handles GET /api/analytics/capacity
+func (h *AnalyticsHandler) GetCapacityPlan(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_capacity_plan")
+
+	var query CapacityQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+	opts := services.CapacityOptions{
+		HistoryWeeks:    query.HistoryWeeks,
+		ForecastWeeks:   query.Weeks,
+		ProductiveHours: query.ProductiveHours,
+		HandlingHours:   query.HandlingHours,
+	}
+
+	plan, err := h.analyticsService.GetCapacityPlan(c.Request.Context(), filters, opts)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve capacity plan", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_capacity_plan")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_capacity_plan", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"group_count":    len(plan.Groups),
+			"forecast_weeks": plan.ForecastWeeks,
+			"peak_fte":       plan.PeakFTE,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    plan,
+		"filters": filters,
+	})
+}
+
 // GetResolutionAnalysis handles GET /api/analytics/resolution
 func (h *AnalyticsHandler) GetResolutionAnalysis(c *gin.Context) {
-	filters, err := parseTimelineFilters(c)
-	if err != nil {
-		sendError(c, "INVALID_DATE_FORMAT", "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest, err.Error())
+	var query ResolutionQuery
+	if !bindQuery(c, &query) {
 		return
 	}
+	filters := query.ToFilters()
+	opts := query.ToOptions()
+	opts.Exclude = query.ExcludeOutliers
 
-	metrics, err := h.analyticsService.GetResolutionAnalysis(c.Request.Context(), filters)
+	metrics, err := h.analyticsService.GetResolutionAnalysisWithOptions(c.Request.Context(), filters, opts)
 	if err != nil {
 		sendError(c, "DATABASE_ERROR", "Failed to retrieve resolution analysis", http.StatusInternalServerError, err.Error())
 		return
@@ -367,11 +662,183 @@ func (h *AnalyticsHandler) GetResolutionAnalysis(c *gin.Context) {
 	})
 }
 
+// GetResolutionOutliers handles GET /api/analytics/resolution/outliers
+func (h *AnalyticsHandler) GetResolutionOutliers(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_resolution_outliers")
+
+	var query ResolutionOutlierQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+
+	report, err := h.analyticsService.GetResolutionOutliers(c.Request.Context(), filters, query.ToOptions(), query.Limit)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve resolution outliers", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_resolution_outliers")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_resolution_outliers", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"outlier_count": report.Total,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    report,
+		"count":   len(report.Incidents),
+		"filters": filters,
+	})
+}
+
+// GetTextProfile handles GET /api/v2/analytics/text-profile
+func (h *AnalyticsHandler) GetTextProfile(c *gin.Context) {
+	var query TextProfileQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+
+	report, err := h.analyticsService.GetTextProfile(c.Request.Context(), filters, query.Limit)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve text profile", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_text_profile")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    report,
+		"filters": filters,
+	})
+}
+
+// ExplainChange handles GET /api/v2/analytics/explain
+func (h *AnalyticsHandler) ExplainChange(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("explain_change")
+
+	var query ExplainQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+	date := *parseDateParam(query.Date)
+	first, last := services.ExplainSpan(query.Period, date, query.BaselinePeriods)
+	if !holdKeyDates(c, first, last) {
+		return
+	}
+
+	explanation, err := h.analyticsService.ExplainChange(c.Request.Context(), filters, query.Period,
+		date, query.BaselinePeriods, query.Limit)
+	if err != nil {
+		apiErr := errors.DatabaseError("explain change", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "explain_change")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("explain_change", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"period":       explanation.Period,
+			"period_start": explanation.PeriodStart,
+			"change":       explanation.Change,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    explanation,
+		"filters": filters,
+	})
+}
+
+// GetApplicationProfile handles GET /api/v2/analytics/applications/:name
+func (h *AnalyticsHandler) GetApplicationProfile(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_application_profile")
+
+	var params ApplicationProfileParams
+	if !bindURI(c, &params) {
+		return
+	}
+	var query ApplicationProfileQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	period := query.Period
+	if period == "" {
+		period = "weekly"
+	}
+	filters := query.ToFilters()
+
+	profile, err := h.analyticsService.GetApplicationProfile(c.Request.Context(), params.Name, period, filters, time.Now())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Application"))
+			return
+		}
+		apiErr := errors.DatabaseError("retrieve application profile", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_application_profile")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_application_profile", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"application":    profile.Name,
+			"incident_count": profile.IncidentCount,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    profile,
+		"filters": filters,
+	})
+}
+
+// GetNotesQuality handles GET /api/analytics/notes-quality
+func (h *AnalyticsHandler) GetNotesQuality(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_notes_quality")
+
+	var query NotesQualityQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+
+	report, err := h.analyticsService.GetNotesQuality(c.Request.Context(), filters, query.Limit)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve notes quality", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_notes_quality")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_notes_quality", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"incident_count": report.Overall.Incidents,
+			"group_count":    len(report.Groups),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    report,
+		"filters": filters,
+	})
+}
+
 // GetPerformanceMetrics handles GET /api/analytics/performance
 func (h *AnalyticsHandler) GetPerformanceMetrics(c *gin.Context) {
-	filters, err := parseTimelineFilters(c)
-	if err != nil {
-		sendError(c, "INVALID_DATE_FORMAT", "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest, err.Error())
+	filters, ok := parseRankedFilters(c)
+	if !ok {
 		return
 	}
 
@@ -389,9 +856,8 @@ func (h *AnalyticsHandler) GetPerformanceMetrics(c *gin.Context) {
 
 // GetSentimentAnalysis handles GET /api/analytics/sentiment
 func (h *AnalyticsHandler) GetSentimentAnalysis(c *gin.Context) {
-	filters, err := parseTimelineFilters(c)
-	if err != nil {
-		sendError(c, "INVALID_DATE_FORMAT", "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest, err.Error())
+	filters, ok := parseTimelineFilters(c)
+	if !ok {
 		return
 	}
 
@@ -401,18 +867,77 @@ func (h *AnalyticsHandler) GetSentimentAnalysis(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	sendList(c, analysis, filters, gin.H{
 		"data":    analysis,
 		"filters": filters,
 		"count":   len(analysis),
 	})
 }
 
+// GetSentimentTimeline handles GET /api/analytics/sentiment/timeline
+func (h *AnalyticsHandler) GetSentimentTimeline(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_sentiment_timeline")
+
+	var query TrendQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	period := query.Period
+	if period == "" {
+		period = "daily"
+	}
+	filters := query.ToFilters()
+
+	timeline, err := h.analyticsService.GetSentimentTimeline(c.Request.Context(), period, filters)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve sentiment timeline", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_sentiment_timeline")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_sentiment_timeline", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"period": period,
+			"count":  len(timeline),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	sendList(c, timeline, groupedFilters{Period: period, TimelineFilters: filters}, gin.H{
+		"data":    timeline,
+		"period":  period,
+		"filters": filters,
+		"count":   len(timeline),
+	})
+}
+
+// GetSentimentCorrelation handles GET /api/analytics/sentiment/correlation
+func (h *AnalyticsHandler) GetSentimentCorrelation(c *gin.Context) {
+	filters, ok := parseTimelineFilters(c)
+	if !ok {
+		return
+	}
+
+	correlation, err := h.analyticsService.GetSentimentCorrelation(c.Request.Context(), filters)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve sentiment correlation", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_sentiment_correlation")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    correlation,
+		"filters": filters,
+	})
+}
+
 // GetAutomationAnalysis handles GET /api/analytics/automation
 func (h *AnalyticsHandler) GetAutomationAnalysis(c *gin.Context) {
-	filters, err := parseTimelineFilters(c)
-	if err != nil {
-		sendError(c, "INVALID_DATE_FORMAT", "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest, err.Error())
+	filters, ok := parseRankedFilters(c)
+	if !ok {
 		return
 	}
 
@@ -422,7 +947,7 @@ func (h *AnalyticsHandler) GetAutomationAnalysis(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	sendList(c, analysis, filters, gin.H{
 		"data":    analysis,
 		"filters": filters,
 		"count":   len(analysis),
@@ -431,9 +956,8 @@ func (h *AnalyticsHandler) GetAutomationAnalysis(c *gin.Context) {
 
 // GetITProcessAutomationReporting handles GET /api/analytics/automation/reporting
 func (h *AnalyticsHandler) GetITProcessAutomationReporting(c *gin.Context) {
-	filters, err := parseTimelineFilters(c)
-	if err != nil {
-		sendError(c, "INVALID_DATE_FORMAT", "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest, err.Error())
+	filters, ok := parseRankedFilters(c)
+	if !ok {
 		return
 	}
 
@@ -449,11 +973,88 @@ func (h *AnalyticsHandler) GetITProcessAutomationReporting(c *gin.Context) {
 	})
 }
 
+// GetAutomationDistribution handles GET /api/v2/analytics/automation/distribution
+func (h *AnalyticsHandler) GetAutomationDistribution(c *gin.Context) {
+	var query AutomationDistributionQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+
+	distribution, err := h.analyticsService.GetAutomationDistribution(c.Request.Context(), filters, query.ToBoundaries())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve automation score distribution", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_automation_distribution")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    distribution,
+		"filters": filters,
+	})
+}
+
+// GetAutomationCalibration handles GET /api/v2/analytics/automation/calibration
+func (h *AnalyticsHandler) GetAutomationCalibration(c *gin.Context) {
+	var query AutomationCalibrationQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+
+	calibration, err := h.analyticsService.GetAutomationCalibration(c.Request.Context(), filters, query.ToCandidates())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve automation threshold calibration", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_automation_calibration")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    calibration,
+		"filters": filters,
+	})
+}
+
+// GetAutomationScenario handles POST /api/analytics/automation/scenario
+func (h *AnalyticsHandler) GetAutomationScenario(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_automation_scenario")
+
+	var req AutomationScenarioRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	filters := req.ToFilters()
+
+	scenario, err := h.analyticsService.GetAutomationScenario(c.Request.Context(), filters, req.ToAssumptions())
+	if err != nil {
+		apiErr := errors.DatabaseError("project automation scenario", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_automation_scenario")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_automation_scenario", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"assumption_count":   len(scenario.Assumptions),
+			"incident_reduction": scenario.Reduction.Incidents,
+			"baseline_incidents": scenario.Baseline.Incidents,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    scenario,
+		"filters": filters,
+	})
+}
+
 // GetAnalyticsSummary handles GET /api/analytics/summary
 func (h *AnalyticsHandler) GetAnalyticsSummary(c *gin.Context) {
-	filters, err := parseTimelineFilters(c)
-	if err != nil {
-		sendError(c, "INVALID_DATE_FORMAT", "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest, err.Error())
+	filters, ok := parseRankedFilters(c)
+	if !ok {
 		return
 	}
 
@@ -468,3 +1069,52 @@ func (h *AnalyticsHandler) GetAnalyticsSummary(c *gin.Context) {
 		"filters": filters,
 	})
 }
+
+// GetMySummary handles GET /api/analytics/my-summary. It summarizes the incidents of the calling
+// user's data scope, so clients need not rebuild the user's filters on every request.
+func (h *AnalyticsHandler) GetMySummary(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_my_summary")
+
+	filters, ok := parseRankedFilters(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.analyticsService.GetMySummary(c.Request.Context(), requestUser(c), filters, time.Now())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve personal summary", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_handler", "get_my_summary")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_my_summary", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"scoped":          summary.Scoped,
+			"total_incidents": summary.TotalIncidents,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    summary,
+		"filters": filters,
+	})
+}
+
+// GetCacheStats handles GET /api/admin/cache, listing the analytics cache lookups of each
+// endpoint since the server started and the share answered from the cache
+func (h *AnalyticsHandler) GetCacheStats(c *gin.Context) {
+	stats := h.analyticsService.CacheStats()
+
+	h.logger.WithContext(c.Request.Context()).Debug("Analytics cache statistics requested",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"endpoints": len(stats),
+		}))
+
+	sendList(c, stats, nil, gin.H{
+		"data":  stats,
+		"count": len(stats),
+	})
+}
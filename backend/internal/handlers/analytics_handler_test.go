@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"incident-management-system/internal/database"
 	"incident-management-system/internal/models"
+	"incident-management-system/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -141,6 +143,70 @@ func TestAnalyticsHandler_GetDailyTimeline(t *testing.T) {
 	assert.Greater(t, len(data), 0, "Should return timeline data")
 }
 
+func TestAnalyticsHandler_GetHourlyTimeline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+	today := time.Now()
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+	}{
+		{name: "range", query: "start_date=" + today.AddDate(0, 0, -1).Format("2006-01-02") + "&end_date=" + today.Format("2006-01-02"), expectedStatus: http.StatusOK},
+		{name: "fourteen days", query: "start_date=2024-01-01&end_date=2024-01-14", expectedStatus: http.StatusOK},
+		{name: "range too long", query: "start_date=2024-01-01&end_date=2024-01-15", expectedStatus: http.StatusBadRequest},
+		{name: "missing range", query: "", expectedStatus: http.StatusBadRequest},
+		{name: "end before start", query: "start_date=2024-01-05&end_date=2024-01-01", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/analytics/timeline/hourly?"+tt.query, nil)
+
+			handler.GetHourlyTimeline(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetDailyTimelineBaselineWeeks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+	}{
+		{name: "baseline weeks", query: "baseline_weeks=4", expectedStatus: http.StatusOK},
+		{name: "most weeks", query: "baseline_weeks=52", expectedStatus: http.StatusOK},
+		{name: "too many weeks", query: "baseline_weeks=53", expectedStatus: http.StatusBadRequest},
+		{name: "not a number", query: "baseline_weeks=many", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/analytics/timeline/daily?"+tt.query, nil)
+
+			handler.GetDailyTimeline(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
 func TestAnalyticsHandler_GetWeeklyTimeline(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -327,6 +393,246 @@ func TestAnalyticsHandler_GetApplicationAnalysis(t *testing.T) {
 	// Application analysis might be empty with limited test data, but endpoint should not error
 }
 
+func TestAnalyticsHandler_RankedListLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+
+	tests := []struct {
+		name           string
+		path           string
+		serve          func(c *gin.Context)
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "applications", path: "/analytics/applications?limit=1", serve: handler.GetApplicationAnalysis, expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "groups", path: "/analytics/groups?limit=1", serve: handler.GetGroupAnalysis, expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "services", path: "/analytics/services?level=business_unit&limit=1", serve: handler.GetServiceAnalysis, expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "regions", path: "/analytics/regions?limit=1", serve: handler.GetRegionAnalysis, expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "automation", path: "/analytics/automation?limit=1", serve: handler.GetAutomationAnalysis, expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "negative limit", path: "/analytics/applications?limit=-1", serve: handler.GetApplicationAnalysis, expectedStatus: http.StatusBadRequest},
+		{name: "limit above maximum", path: "/analytics/automation?limit=101", serve: handler.GetAutomationAnalysis, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", tt.path, nil)
+
+			tt.serve(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.EqualValues(t, tt.expectedCount, response["count"])
+			assert.EqualValues(t, 1, response["filters"].(map[string]interface{})["limit"])
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetGroupAnalysis(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedLevel string
+		hasError      bool
+	}{
+		{
+			name:          "default group level",
+			query:         "",
+			expectedLevel: "group",
+		},
+		{
+			name:          "department level",
+			query:         "?level=department",
+			expectedLevel: "department",
+		},
+		{
+			name:          "single division",
+			query:         "?level=division&unit=Unassigned",
+			expectedLevel: "division",
+		},
+		{
+			name:     "invalid level",
+			query:    "?level=team",
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/analytics/groups"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			handler.GetGroupAnalysis(c)
+
+			if tt.hasError {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				return
+			}
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			assert.Equal(t, tt.expectedLevel, response["level"])
+			_, ok := response["data"].([]interface{})
+			assert.True(t, ok, "Data should be an array")
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetBenchmark(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+
+	tests := []struct {
+		name              string
+		query             string
+		expectedDimension string
+		hasError          bool
+	}{
+		{
+			name:              "default application dimension",
+			query:             "",
+			expectedDimension: "application",
+		},
+		{
+			name:              "group dimension with options",
+			query:             "?dimension=group&min_incidents=1&z_threshold=1.5",
+			expectedDimension: "group",
+		},
+		{
+			name:     "invalid dimension",
+			query:    "?dimension=priority",
+			hasError: true,
+		},
+		{
+			name:     "threshold out of range",
+			query:    "?z_threshold=10",
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/analytics/benchmark"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			handler.GetBenchmark(c)
+
+			if tt.hasError {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				return
+			}
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			data, ok := response["data"].(map[string]interface{})
+			require.True(t, ok, "Data should be an object")
+			assert.Equal(t, tt.expectedDimension, data["dimension"])
+			_, ok = data["entries"].([]interface{})
+			assert.True(t, ok, "Entries should be an array")
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetCapacityPlan(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+
+	tests := []struct {
+		name                  string
+		query                 string
+		expectedWeeks         int
+		expectedProductiveHrs float64
+		hasError              bool
+	}{
+		{
+			name:                  "defaults",
+			query:                 "",
+			expectedWeeks:         4,
+			expectedProductiveHrs: 30,
+		},
+		{
+			name:                  "custom options",
+			query:                 "?weeks=8&history_weeks=26&productive_hours=25&handling_hours=2",
+			expectedWeeks:         8,
+			expectedProductiveHrs: 25,
+		},
+		{
+			name:     "too few history weeks",
+			query:    "?history_weeks=1",
+			hasError: true,
+		},
+		{
+			name:     "invalid productive hours",
+			query:    "?productive_hours=200",
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/analytics/capacity"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			handler.GetCapacityPlan(c)
+
+			if tt.hasError {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				return
+			}
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			data, ok := response["data"].(map[string]interface{})
+			require.True(t, ok, "Data should be an object")
+			assert.Equal(t, float64(tt.expectedWeeks), data["forecast_weeks"])
+			assert.Equal(t, tt.expectedProductiveHrs, data["productive_hours_per_agent"])
+			weeks, ok := data["weeks"].([]interface{})
+			assert.True(t, ok, "Weeks should be an array")
+			assert.Len(t, weeks, tt.expectedWeeks)
+			_, ok = data["groups"].([]interface{})
+			assert.True(t, ok, "Groups should be an array")
+		})
+	}
+}
+
 func TestAnalyticsHandler_GetResolutionAnalysis(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -357,6 +663,195 @@ func TestAnalyticsHandler_GetResolutionAnalysis(t *testing.T) {
 	// Resolution analysis might be empty with limited test data, but endpoint should not error
 }
 
+func TestAnalyticsHandler_ResolutionPercentiles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+
+	tests := []struct {
+		name                string
+		path                string
+		serve               func(c *gin.Context)
+		expectedStatus      int
+		expectedPercentiles []interface{}
+	}{
+		{name: "resolution", path: "/analytics/resolution?percentiles=95,80,95", serve: handler.GetResolutionAnalysis, expectedStatus: http.StatusOK, expectedPercentiles: []interface{}{80.0, 95.0}},
+		{name: "applications", path: "/analytics/applications?percentiles=99.9", serve: handler.GetApplicationAnalysis, expectedStatus: http.StatusOK, expectedPercentiles: []interface{}{99.9}},
+		{name: "not a number", path: "/analytics/resolution?percentiles=p95", serve: handler.GetResolutionAnalysis, expectedStatus: http.StatusBadRequest},
+		{name: "out of range", path: "/analytics/applications?percentiles=50,100", serve: handler.GetApplicationAnalysis, expectedStatus: http.StatusBadRequest},
+		{name: "too many", path: "/analytics/resolution?percentiles=10,20,30,40,50,60,70,80,90,95,99", serve: handler.GetResolutionAnalysis, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", tt.path, nil)
+
+			tt.serve(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedPercentiles, response["filters"].(map[string]interface{})["percentiles"])
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetAutomationDistribution(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatus     int
+		expectedBoundaries []interface{}
+	}{
+		{name: "default buckets", path: "/analytics/automation/distribution", expectedStatus: http.StatusOK, expectedBoundaries: []interface{}{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9}},
+		{name: "bucket count", path: "/analytics/automation/distribution?buckets=4", expectedStatus: http.StatusOK, expectedBoundaries: []interface{}{0.25, 0.5, 0.75}},
+		{name: "boundaries", path: "/analytics/automation/distribution?boundaries=0.55,0.35,0.45,0.35&buckets=4", expectedStatus: http.StatusOK, expectedBoundaries: []interface{}{0.35, 0.45, 0.55}},
+		{name: "boundary out of range", path: "/analytics/automation/distribution?boundaries=0.5,1", expectedStatus: http.StatusBadRequest},
+		{name: "boundary too precise", path: "/analytics/automation/distribution?boundaries=0.4555", expectedStatus: http.StatusBadRequest},
+		{name: "too many buckets", path: "/analytics/automation/distribution?buckets=101", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", tt.path, nil)
+
+			handler.GetAutomationDistribution(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			data := response["data"].(map[string]interface{})
+			assert.Equal(t, tt.expectedBoundaries, data["boundaries"])
+			assert.Len(t, data["buckets"], len(tt.expectedBoundaries)+1)
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetAutomationCalibration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatus     int
+		expectedCandidates []interface{}
+	}{
+		{name: "default steps", path: "/analytics/automation/calibration", expectedStatus: http.StatusOK, expectedCandidates: []interface{}{0.05, 0.1, 0.15, 0.2, 0.25, 0.3, 0.35, 0.4, 0.45, 0.5, 0.55, 0.6, 0.65, 0.7, 0.75, 0.8, 0.85, 0.9, 0.95}},
+		{name: "steps", path: "/analytics/automation/calibration?steps=4", expectedStatus: http.StatusOK, expectedCandidates: []interface{}{0.25, 0.5, 0.75}},
+		{name: "thresholds", path: "/analytics/automation/calibration?thresholds=0.45,0.35&steps=4", expectedStatus: http.StatusOK, expectedCandidates: []interface{}{0.35, 0.45}},
+		{name: "threshold out of range", path: "/analytics/automation/calibration?thresholds=0", expectedStatus: http.StatusBadRequest},
+		{name: "too few steps", path: "/analytics/automation/calibration?steps=1", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", tt.path, nil)
+
+			handler.GetAutomationCalibration(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			data := response["data"].(map[string]interface{})
+			assert.Equal(t, tt.expectedCandidates, data["candidates"])
+			assert.NotNil(t, data["groups"])
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetResolutionOutliers(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+
+	tests := []struct {
+		name           string
+		path           string
+		handle         func(*gin.Context)
+		expectedStatus int
+	}{
+		{
+			name:           "resolution analysis excluding outliers",
+			path:           "/analytics/resolution?exclude_outliers=true&outlier_method=percentile&percentile=95",
+			handle:         handler.GetResolutionAnalysis,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid outlier method",
+			path:           "/analytics/resolution?exclude_outliers=true&outlier_method=zscore",
+			handle:         handler.GetResolutionAnalysis,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "list outliers",
+			path:           "/analytics/resolution/outliers?iqr_multiplier=3&limit=5",
+			handle:         handler.GetResolutionOutliers,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "percentile out of range",
+			path:           "/analytics/resolution/outliers?percentile=100",
+			handle:         handler.GetResolutionOutliers,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "limit too large",
+			path:           "/analytics/resolution/outliers?limit=5000",
+			handle:         handler.GetResolutionOutliers,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", tt.path, nil)
+
+			tt.handle(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if w.Code != http.StatusOK {
+				return
+			}
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			_, ok := response["data"].(map[string]interface{})
+			assert.True(t, ok, "Data should be an object")
+		})
+	}
+}
+
 func TestAnalyticsHandler_GetPerformanceMetrics(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -417,6 +912,95 @@ func TestAnalyticsHandler_GetSentimentAnalysis(t *testing.T) {
 	// Sentiment analysis might be empty with limited test data, but endpoint should not error
 }
 
+func TestAnalyticsHandler_GetSentimentTimeline(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+
+	tests := []struct {
+		name     string
+		query    string
+		period   string
+		hasError bool
+	}{
+		{
+			name:   "default daily timeline",
+			query:  "",
+			period: "daily",
+		},
+		{
+			name:   "weekly timeline with filters",
+			query:  "?period=weekly&priorities=P1,P2",
+			period: "weekly",
+		},
+		{
+			name:     "invalid period",
+			query:    "?period=monthly",
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/analytics/sentiment/timeline"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			handler.GetSentimentTimeline(c)
+
+			if tt.hasError {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				return
+			}
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			assert.Equal(t, tt.period, response["period"])
+			_, ok := response["data"].([]interface{})
+			assert.True(t, ok, "Data should be an array")
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetSentimentCorrelation(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+
+	// Create request
+	req := httptest.NewRequest("GET", "/analytics/sentiment/correlation", nil)
+	w := httptest.NewRecorder()
+
+	// Create gin context
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	// Execute handler
+	handler.GetSentimentCorrelation(c)
+
+	// Check response
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	data, ok := response["data"].(map[string]interface{})
+	require.True(t, ok, "Data should be an object")
+	assert.Contains(t, data, "by_label")
+	assert.Contains(t, data, "by_priority")
+	assert.Contains(t, data, "correlations")
+}
+
 func TestAnalyticsHandler_GetAutomationAnalysis(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -447,6 +1031,77 @@ func TestAnalyticsHandler_GetAutomationAnalysis(t *testing.T) {
 	// Automation analysis might be empty with limited test data, but endpoint should not error
 }
 
+func TestAnalyticsHandler_GetAutomationScenario(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+
+	tests := []struct {
+		name              string
+		body              string
+		expectedReduction float64
+		hasError          bool
+	}{
+		{
+			name:              "all infrastructure candidates",
+			body:              `{"assumptions":[{"it_process_group":"Infrastructure","min_score":0.7,"success_rate":0.8}]}`,
+			expectedReduction: 8,
+		},
+		{
+			name:              "score above every candidate",
+			body:              `{"assumptions":[{"min_score":0.9,"success_rate":1}]}`,
+			expectedReduction: 0,
+		},
+		{
+			name:     "no assumptions",
+			body:     `{"assumptions":[]}`,
+			hasError: true,
+		},
+		{
+			name:     "success rate above one",
+			body:     `{"assumptions":[{"success_rate":1.5}]}`,
+			hasError: true,
+		},
+		{
+			name:     "invalid priority",
+			body:     `{"priorities":["P9"],"assumptions":[{"success_rate":0.5}]}`,
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/analytics/automation/scenario", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+
+			handler.GetAutomationScenario(c)
+
+			if tt.hasError {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				return
+			}
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			data, ok := response["data"].(map[string]interface{})
+			require.True(t, ok, "Data should be an object")
+			baseline := data["baseline"].(map[string]interface{})
+			reduction := data["reduction"].(map[string]interface{})
+			assert.Equal(t, float64(10), baseline["incidents"])
+			assert.Equal(t, tt.expectedReduction, reduction["incidents"])
+		})
+	}
+}
+
 func TestAnalyticsHandler_GetAnalyticsSummary(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -476,3 +1131,190 @@ func TestAnalyticsHandler_GetAnalyticsSummary(t *testing.T) {
 	assert.True(t, ok, "Data should be an object")
 	// Summary should contain data even with limited test data
 }
+
+func TestAnalyticsHandler_GetNotesQuality(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{name: "notes quality", path: "/analytics/notes-quality?limit=5", expectedStatus: http.StatusOK},
+		{name: "limit too large", path: "/analytics/notes-quality?limit=1000", expectedStatus: http.StatusBadRequest},
+		{name: "invalid priority", path: "/analytics/notes-quality?priorities=P9", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", tt.path, nil)
+
+			handler.GetNotesQuality(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if w.Code != http.StatusOK {
+				return
+			}
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			data, ok := response["data"].(map[string]interface{})
+			require.True(t, ok, "Data should be an object")
+			assert.LessOrEqual(t, len(data["lowest"].([]interface{})), 5)
+			assert.NotNil(t, data["groups"])
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetTextProfile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{name: "text profile", path: "/analytics/text-profile?limit=1", expectedStatus: http.StatusOK},
+		{name: "limit too large", path: "/analytics/text-profile?limit=501", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", tt.path, nil)
+
+			handler.GetTextProfile(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if w.Code != http.StatusOK {
+				return
+			}
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			data := response["data"].(map[string]interface{})
+			assert.Equal(t, float64(10), data["overall"].(map[string]interface{})["incidents"])
+			assert.LessOrEqual(t, len(data["applications"].([]interface{})), 1)
+		})
+	}
+}
+
+func TestAnalyticsHandler_ExplainChange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 3)
+
+	handler := NewAnalyticsHandler(db)
+	today := time.Now().Format("2006-01-02")
+	march := &services.APIKey{ID: "key-1", StartDate: "2024-03-01", EndDate: "2024-03-31"}
+
+	tests := []struct {
+		name           string
+		path           string
+		key            *services.APIKey
+		expectedStatus int
+	}{
+		{name: "daily", path: "/analytics/explain?date=" + today, expectedStatus: http.StatusOK},
+		{name: "weekly", path: "/analytics/explain?period=weekly&baseline_periods=2&date=" + today, expectedStatus: http.StatusOK},
+		{name: "missing date", path: "/analytics/explain", expectedStatus: http.StatusBadRequest},
+		{name: "invalid period", path: "/analytics/explain?period=monthly&date=" + today, expectedStatus: http.StatusBadRequest},
+		{name: "too many baseline periods", path: "/analytics/explain?baseline_periods=13&date=" + today, expectedStatus: http.StatusBadRequest},
+		{name: "within the key's date range", path: "/analytics/explain?baseline_periods=2&date=2024-03-20", key: march, expectedStatus: http.StatusOK},
+		{name: "baseline before the key's date range", path: "/analytics/explain?date=2024-03-10", key: march, expectedStatus: http.StatusForbidden},
+		{name: "after the key's date range", path: "/analytics/explain?baseline_periods=1&date=2024-04-02", key: march, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", tt.path, nil)
+			if tt.key != nil {
+				c.Request = c.Request.WithContext(services.WithAPIKey(c.Request.Context(), tt.key))
+			}
+
+			handler.ExplainChange(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if w.Code != http.StatusOK {
+				return
+			}
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			data := response["data"].(map[string]interface{})
+			assert.Len(t, data["dimensions"].([]interface{}), 4)
+			assert.NotNil(t, data["top_contributors"])
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetApplicationProfile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 3)
+
+	handler := NewAnalyticsHandler(db)
+	router := gin.New()
+	router.GET("/analytics/applications/:name", handler.GetApplicationProfile)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{name: "weekly profile", path: "/analytics/applications/TestApp", expectedStatus: http.StatusOK},
+		{name: "daily profile", path: "/analytics/applications/TestApp?period=daily&limit=1", expectedStatus: http.StatusOK},
+		{name: "unknown application", path: "/analytics/applications/Unknown", expectedStatus: http.StatusNotFound},
+		{name: "invalid period", path: "/analytics/applications/TestApp?period=monthly", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if w.Code != http.StatusOK {
+				return
+			}
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			data := response["data"].(map[string]interface{})
+			assert.Equal(t, "TestApp", data["name"])
+			assert.Equal(t, float64(3), data["incident_count"])
+			for _, field := range []string{"timeline", "sentiment", "priorities", "resolution", "automation", "problems", "sla", "sla_by_priority"} {
+				assert.NotNil(t, data[field], field)
+			}
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetNoiseAnalysis(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 3)
+
+	handler := NewAnalyticsHandler(db)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/analytics/noise", nil)
+	handler.GetNoiseAnalysis(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, false, data["source_mapped"])
+	assert.EqualValues(t, 3, data["unsourced"])
+}
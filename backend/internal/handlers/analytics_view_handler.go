@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnalyticsViewHandler handles the analytics view consistency endpoints
+type AnalyticsViewHandler struct {
+	viewService *services.AnalyticsViewService
+	logger      *logging.Logger
+}
+
+// NewAnalyticsViewHandler creates a new analytics view handler
+func NewAnalyticsViewHandler(db *sql.DB) *AnalyticsViewHandler {
+	return &AnalyticsViewHandler{
+		viewService: services.NewAnalyticsViewService(db),
+		logger:      logging.GetGlobalLogger().WithComponent("analytics_view_handler"),
+	}
+}
+
+// CheckViews handles GET /api/admin/views
+func (h *AnalyticsViewHandler) CheckViews(c *gin.Context) {
+	start := time.Now()
+
+	report, err := h.viewService.CheckViews(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("check analytics views", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_view_handler", "check_views")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logView(c, "check_views", start, report)
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
+
+// RebuildViews handles POST /api/admin/views/rebuild
+func (h *AnalyticsViewHandler) RebuildViews(c *gin.Context) {
+	start := time.Now()
+
+	report, err := h.viewService.RebuildViews(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("rebuild analytics views", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "analytics_view_handler", "rebuild_views")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logView(c, "rebuild_views", start, report)
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
+
+// logView logs the duration of a view operation and the views left inconsistent
+func (h *AnalyticsViewHandler) logView(c *gin.Context, operation string, start time.Time, report *services.ViewConsistencyReport) {
+	var drifted []string
+	for _, view := range report.Views {
+		if !view.Consistent {
+			drifted = append(drifted, view.View)
+		}
+	}
+	h.logger.WithContext(c.Request.Context()).LogDuration(operation, start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"consistent": report.Consistent,
+			"drifted":    drifted,
+		}))
+	monitoring.UpdatePerformance(time.Since(start))
+}
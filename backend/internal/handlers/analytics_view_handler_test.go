@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsViewHandler_Views(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	createTestIncidents(t, db, 10)
+	handler := NewAnalyticsViewHandler(db)
+
+	// Check views
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/views", nil)
+	handler.CheckViews(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data struct {
+			Consistent bool `json:"consistent"`
+			Rebuilt    bool `json:"rebuilt"`
+			Views      []struct {
+				View   string `json:"view"`
+				Exists bool   `json:"exists"`
+			} `json:"views"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Data.Consistent)
+	assert.Len(t, response.Data.Views, 6)
+
+	// Rebuild views after one is dropped
+	_, err := db.Exec("DROP VIEW weekly_timeline")
+	require.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/admin/views/rebuild", nil)
+	handler.RebuildViews(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Data.Consistent)
+	assert.True(t, response.Data.Rebuilt)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ObserverAuth is middleware authenticating requests made with an observer API key, sent as
+// "Authorization: Bearer ims_obs_...", in place of a user's session. Such requests may only read
+// the analytics endpoints listed on the key, the routes below analyticsPrefix, and their report
+// dates are held within the key's range: missing bounds are filled in and dates outside it are
+// refused. Requests without an observer key pass through unchanged.
+func ObserverAuth(keys *services.APIKeyService, analyticsPrefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || !strings.HasPrefix(secret, services.ObserverKeyPrefix) {
+			c.Next()
+			return
+		}
+
+		key, err := keys.ResolveKey(c.Request.Context(), secret)
+		if err == sql.ErrNoRows {
+			errors.AbortWithError(c, errors.NewAPIError(errors.ErrUnauthorized, "Invalid API key").
+				WithUserMessage("The API key does not exist, was revoked or has expired"))
+			return
+		}
+		if err != nil {
+			apiErr := errors.DatabaseError("resolve API key", err)
+			monitoring.TrackError(c.Request.Context(), apiErr, "api_key_handler", "resolve_api_key")
+			errors.AbortWithError(c, apiErr)
+			return
+		}
+
+		endpoint, found := strings.CutPrefix(c.FullPath(), analyticsPrefix)
+		if !found || !isReadOnlyMethod(c.Request.Method) || !key.Allows(endpoint) {
+			errors.AbortWithError(c, errors.NewAPIError(errors.ErrForbidden, "API key not allowed for this endpoint").
+				WithUserMessage("This API key can only read the analytics endpoints it was created for"))
+			return
+		}
+		if !holdKeyDateRange(c, key) {
+			return
+		}
+
+		ctx := services.WithAPIKey(c.Request.Context(), key)
+		c.Request = c.Request.WithContext(logging.WithUserID(ctx, key.UserID()))
+		c.Next()
+	}
+}
+
+// holdKeyDateRange fills in the key's date bounds missing from the request's start_date and
+// end_date, and refuses with a 403 and returns false when the request asks for dates outside them
+func holdKeyDateRange(c *gin.Context, key *services.APIKey) bool {
+	query := c.Request.URL.Query()
+	bounds := []struct {
+		param, bound string
+		outside      func(value string) bool
+	}{
+		{"start_date", key.StartDate, func(value string) bool { return value < key.StartDate }},
+		{"end_date", key.EndDate, func(value string) bool { return value > key.EndDate }},
+	}
+	for _, b := range bounds {
+		if b.bound == "" {
+			continue
+		}
+		value := query.Get(b.param)
+		if value == "" {
+			query.Set(b.param, b.bound)
+			continue
+		}
+		if b.outside(value) {
+			errors.AbortWithError(c, keyDateRangeError(key))
+			return false
+		}
+	}
+	c.Request.URL.RawQuery = query.Encode()
+	return true
+}
+
+// holdKeyDates refuses with a 403 and returns false when the request is made with an API key
+// limited to a date range that does not hold every day from first to last. It is for endpoints
+// choosing their own dates rather than reading start_date and end_date.
+func holdKeyDates(c *gin.Context, first, last time.Time) bool {
+	key := services.APIKeyFromContext(c.Request.Context())
+	if key == nil {
+		return true
+	}
+	if (key.StartDate != "" && first.Format("2006-01-02") < key.StartDate) ||
+		(key.EndDate != "" && last.Format("2006-01-02") > key.EndDate) {
+		errors.SendError(c, keyDateRangeError(key))
+		return false
+	}
+	return true
+}
+
+// keyDateRangeError is the error refusing dates outside an API key's date range
+func keyDateRangeError(key *services.APIKey) *errors.APIError {
+	return errors.NewAPIError(errors.ErrForbidden, "Date range not allowed for this API key").
+		WithDetails(gin.H{"start_date": key.StartDate, "end_date": key.EndDate}).
+		WithUserMessage("This API key can only read incidents reported within its date range")
+}
+
+// APIKeyHandler handles the admin endpoints managing API keys
+type APIKeyHandler struct {
+	keys   *services.APIKeyService
+	logger *logging.Logger
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(keys *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		keys:   keys,
+		logger: logging.GetGlobalLogger().WithComponent("api_key_handler"),
+	}
+}
+
+// ListAPIKeys handles GET /api/v2/admin/api-keys
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.keys.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		h.sendError(c, err, "list_api_keys")
+		return
+	}
+
+	sendList(c, keys, nil, gin.H{
+		"data":  keys,
+		"count": len(keys),
+	})
+}
+
+// CreateAPIKey handles POST /api/v2/admin/api-keys. The key in the response is only shown once
+// and is sent back as "Authorization: Bearer <key>".
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req APIKeyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	actor := requestUser(c)
+	key, secret, err := h.keys.CreateObserverKey(c.Request.Context(), req.Name, req.Endpoints, req.StartDate,
+		req.EndDate, time.Duration(req.TTLDays)*24*time.Hour, actor)
+	if err != nil {
+		h.sendError(c, err, "create_api_key")
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Warn("API key created",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"api_key":    key.ID,
+			"role":       key.Role,
+			"endpoints":  len(key.Endpoints),
+			"created_by": actor,
+		}))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": key,
+		"key":  secret,
+	})
+}
+
+// RevokeAPIKey handles DELETE /api/v2/admin/api-keys/:id
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	var params APIKeyParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.keys.RevokeAPIKey(c.Request.Context(), params.ID); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("API key"))
+			return
+		}
+		h.sendError(c, err, "revoke_api_key")
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Warn("API key revoked",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"api_key":    params.ID,
+			"revoked_by": requestUser(c),
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked",
+	})
+}
+
+// sendError answers a failed API key operation, with 400 for keys that are malformed
+func (h *APIKeyHandler) sendError(c *gin.Context, err error, operation string) {
+	if stderrors.Is(err, services.ErrInvalidAPIKey) {
+		errors.SendError(c, errors.BadRequest(err.Error()))
+		return
+	}
+	apiErr := errors.DatabaseError("manage API keys", err)
+	monitoring.TrackError(c.Request.Context(), apiErr, "api_key_handler", operation)
+	errors.SendError(c, apiErr)
+}
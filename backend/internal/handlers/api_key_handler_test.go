@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserverAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	keys := services.NewAPIKeyService(db)
+	handler := NewAPIKeyHandler(keys)
+	analyticsHandler := NewAnalyticsHandler(db)
+
+	incidents := []models.Incident{
+		{ID: "i1", UploadID: "upload-1", IncidentID: "INC001", ReportDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			ApplicationName: "Portal", Priority: "P1", Status: "Open"},
+		{ID: "i2", UploadID: "upload-1", IncidentID: "INC002", ReportDate: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+			ApplicationName: "Billing", Priority: "P2", Status: "Open"},
+	}
+	_, err := services.NewIncidentService(db).BatchInsertIncidents(context.Background(), incidents, "upload-1")
+	require.NoError(t, err)
+
+	router := gin.New()
+	api := router.Group("/api/v2", ObserverAuth(keys, "/api/v2/analytics/"))
+	api.GET("/analytics/timeline/daily", analyticsHandler.GetDailyTimeline)
+	api.GET("/analytics/priority", analyticsHandler.GetPriorityAnalysis)
+	api.GET("/admin/api-keys", handler.ListAPIKeys)
+	api.POST("/admin/api-keys", handler.CreateAPIKey)
+	api.DELETE("/admin/api-keys/:id", handler.RevokeAPIKey)
+
+	send := func(method, path, body, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v2/admin/api-keys", `{"name":"Wiki","endpoints":["/timeline/daily"]}`, "").Code)
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v2/admin/api-keys",
+		`{"name":"Wiki","endpoints":["timeline/daily"],"start_date":"2024-06-01","end_date":"2024-01-01"}`, "").Code)
+
+	w := send("POST", "/api/v2/admin/api-keys",
+		`{"name":"Wiki","endpoints":["timeline/daily"],"start_date":"2024-01-01","end_date":"2024-01-31"}`, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Data services.APIKey `json:"data"`
+		Key  string          `json:"key"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.True(t, strings.HasPrefix(created.Key, services.ObserverKeyPrefix))
+	assert.Equal(t, services.APIKeyRoleObserver, created.Data.Role)
+
+	// Missing bounds are filled in from the key's range
+	w = send("GET", "/api/v2/analytics/timeline/daily", "", created.Key)
+	require.Equal(t, http.StatusOK, w.Code)
+	var timeline struct {
+		Data []services.TimelineData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &timeline))
+	require.Len(t, timeline.Data, 1)
+	assert.Equal(t, "2024-01-15", timeline.Data[0].Date)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		key            string
+		expectedStatus int
+	}{
+		{name: "dates within the range", method: "GET", path: "/api/v2/analytics/timeline/daily?start_date=2024-01-10", key: created.Key, expectedStatus: http.StatusOK},
+		{name: "dates outside the range", method: "GET", path: "/api/v2/analytics/timeline/daily?end_date=2024-03-31", key: created.Key, expectedStatus: http.StatusForbidden},
+		{name: "endpoint not on the key", method: "GET", path: "/api/v2/analytics/priority", key: created.Key, expectedStatus: http.StatusForbidden},
+		{name: "admin endpoints", method: "GET", path: "/api/v2/admin/api-keys", key: created.Key, expectedStatus: http.StatusForbidden},
+		{name: "unknown key", method: "GET", path: "/api/v2/analytics/timeline/daily", key: services.ObserverKeyPrefix + "bogus", expectedStatus: http.StatusUnauthorized},
+		{name: "other bearer tokens pass through", method: "GET", path: "/api/v2/analytics/priority", key: "admin-token", expectedStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedStatus, send(tt.method, tt.path, "", tt.key).Code)
+		})
+	}
+
+	w = send("GET", "/api/v2/admin/api-keys", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed struct {
+		Data []services.APIKey `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Data, 1)
+	assert.NotNil(t, listed.Data[0].LastUsedAt)
+	assert.NotContains(t, w.Body.String(), created.Key)
+
+	// Revoked keys stop working
+	assert.Equal(t, http.StatusOK, send("DELETE", "/api/v2/admin/api-keys/"+created.Data.ID, "", "").Code)
+	assert.Equal(t, http.StatusNotFound, send("DELETE", "/api/v2/admin/api-keys/"+created.Data.ID, "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/v2/analytics/timeline/daily", "", created.Key).Code)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApplicationHandler handles application name normalization endpoints
+type ApplicationHandler struct {
+	normalizer *services.ApplicationNormalizer
+	logger     *logging.Logger
+}
+
+// NewApplicationHandler creates a new application handler
+func NewApplicationHandler(db *sql.DB) *ApplicationHandler {
+	return &ApplicationHandler{
+		normalizer: services.NewApplicationNormalizer(db),
+		logger:     logging.GetGlobalLogger().WithComponent("application_handler"),
+	}
+}
+
+// ListAliases handles GET /api/applications/aliases
+func (h *ApplicationHandler) ListAliases(c *gin.Context) {
+	aliases, err := h.normalizer.ListAliases(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve application aliases", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "application_handler", "list_aliases")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, aliases, nil, gin.H{
+		"data":  aliases,
+		"count": len(aliases),
+	})
+}
+
+// CreateAlias handles POST /api/applications/aliases
+func (h *ApplicationHandler) CreateAlias(c *gin.Context) {
+	var req ApplicationAliasRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	alias, err := h.normalizer.SaveAlias(c.Request.Context(), req.Alias, req.CanonicalName)
+	if err != nil {
+		apiErr := errors.DatabaseError("save application alias", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "application_handler", "create_alias")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Application alias saved",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"alias":          alias.Alias,
+			"canonical_name": alias.CanonicalName,
+		}))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": alias,
+	})
+}
+
+// DeleteAlias handles DELETE /api/applications/aliases/:alias
+func (h *ApplicationHandler) DeleteAlias(c *gin.Context) {
+	alias := c.Param("alias")
+
+	if err := h.normalizer.DeleteAlias(c.Request.Context(), alias); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Application alias"))
+			return
+		}
+		apiErr := errors.DatabaseError("delete application alias", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "application_handler", "delete_alias")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Application alias deleted",
+	})
+}
+
+// MergeApplications handles POST /api/applications/merge
+func (h *ApplicationHandler) MergeApplications(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("merge_applications")
+
+	var req ApplicationMergeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if services.ApplicationAliasKey(req.From) == services.ApplicationAliasKey(req.To) {
+		apiErr := errors.NewAPIError(errors.ErrInvalidParameter, "Cannot merge an application into itself").
+			WithUserMessage("Choose two different application names to merge")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	result, err := h.normalizer.MergeApplications(c.Request.Context(), req.From, req.To)
+	if err != nil {
+		apiErr := errors.DatabaseError("merge applications", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "application_handler", "merge_applications")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("merge_applications", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"from":              result.From,
+			"to":                result.To,
+			"updated_incidents": result.UpdatedIncidents,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+	})
+}
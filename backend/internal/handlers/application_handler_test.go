@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplicationHandler_Aliases(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewApplicationHandler(db)
+
+	// Create alias
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/applications/aliases",
+		strings.NewReader(`{"alias":"SAP-PROD","canonical_name":"SAP"}`))
+	handler.CreateAlias(c)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// List aliases
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/applications/aliases", nil)
+	handler.ListAliases(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(1), response["count"])
+
+	// Missing canonical name is rejected
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/applications/aliases", strings.NewReader(`{"alias":"SAP"}`))
+	handler.CreateAlias(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Delete unknown alias
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/applications/aliases/unknown", nil)
+	c.Params = []gin.Param{{Key: "alias", Value: "unknown"}}
+	handler.DeleteAlias(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestApplicationHandler_MergeApplications(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewApplicationHandler(db)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "successful merge",
+			body:           `{"from":"sap prod","to":"SAP"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "merge into itself",
+			body:           `{"from":"SAP-PROD","to":"sap prod"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing target",
+			body:           `{"from":"sap prod"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/applications/merge", strings.NewReader(tt.body))
+
+			handler.MergeApplications(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ArchiveHandler handles endpoints for the incident archive
+type ArchiveHandler struct {
+	archive *services.ArchiveStore
+	logger  *logging.Logger
+}
+
+// NewArchiveHandler creates a new archive handler
+func NewArchiveHandler(archive *services.ArchiveStore) *ArchiveHandler {
+	return &ArchiveHandler{
+		archive: archive,
+		logger:  logging.GetGlobalLogger().WithComponent("archive_handler"),
+	}
+}
+
+// ListArchive handles GET /api/archive
+func (h *ArchiveHandler) ListArchive(c *gin.Context) {
+	files, err := h.archive.ListFiles(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve archive files", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "archive_handler", "list_archive")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, files, nil, gin.H{
+		"data":  files,
+		"count": len(files),
+	})
+}
+
+// ArchiveIncidents handles POST /api/archive
+func (h *ArchiveHandler) ArchiveIncidents(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("archive_incidents")
+
+	var req ArchiveRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.archive.ArchiveBefore(c.Request.Context(), *parseDateParam(req.Before))
+	if err != nil {
+		apiErr := errors.DatabaseError("archive incidents", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "archive_handler", "archive_incidents")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("archive_incidents", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"before":   result.Before,
+			"archived": result.Archived,
+			"years":    result.Years,
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+	})
+}
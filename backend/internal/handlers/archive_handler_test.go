@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveHandler_ArchiveIncidents(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 3)
+	archive, err := services.NewArchiveStore(db, t.TempDir())
+	require.NoError(t, err)
+	handler := NewArchiveHandler(archive)
+
+	tests := []struct {
+		name             string
+		body             string
+		expectedStatus   int
+		expectedArchived int
+	}{
+		{name: "missing date", body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid date", body: `{"before":"01/01/2100"}`, expectedStatus: http.StatusBadRequest},
+		{name: "nothing old enough", body: `{"before":"2000-01-01"}`, expectedStatus: http.StatusOK},
+		{name: "archive everything", body: `{"before":"2100-01-01"}`, expectedStatus: http.StatusOK, expectedArchived: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/archive", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.ArchiveIncidents(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Data services.ArchiveResult `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedArchived, response.Data.Archived)
+		})
+	}
+
+	var live int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM incidents").Scan(&live))
+	assert.Equal(t, 0, live)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/archive", nil)
+
+	handler.ListArchive(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []services.ArchiveFile `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	archived := 0
+	for _, file := range response.Data {
+		archived += file.Incidents
+	}
+	assert.Equal(t, 3, archived)
+}
@@ -0,0 +1,492 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AutomationHandler handles custom automation keyword and runbook management, automation
+// candidate and automation opportunity tracking endpoints
+type AutomationHandler struct {
+	keywordService  *services.AutomationKeywordService
+	runbookService  *services.RunbookService
+	ticketService   *services.AutomationTicketService
+	pipelineService *services.AutomationPipelineService
+	logger          *logging.Logger
+}
+
+// NewAutomationHandler creates a new automation handler
+func NewAutomationHandler(db *sql.DB) *AutomationHandler {
+	return &AutomationHandler{
+		keywordService:  services.NewAutomationKeywordService(db),
+		runbookService:  services.NewRunbookService(db),
+		ticketService:   services.NewAutomationTicketService(db, nil),
+		pipelineService: services.NewAutomationPipelineService(db),
+		logger:          logging.GetGlobalLogger().WithComponent("automation_handler"),
+	}
+}
+
+// SetJiraConfig sets the Jira site automation candidate tickets are created in
+func (h *AutomationHandler) SetJiraConfig(jira *services.JiraConfig) {
+	h.ticketService.SetJiraConfig(jira)
+}
+
+// ListKeywords handles GET /api/automation/keywords
+func (h *AutomationHandler) ListKeywords(c *gin.Context) {
+	keywords, err := h.keywordService.ListKeywords(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve automation keywords", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "automation_handler", "list_keywords")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, keywords, nil, gin.H{
+		"data":  keywords,
+		"count": len(keywords),
+	})
+}
+
+// SaveKeyword handles POST /api/automation/keywords
+func (h *AutomationHandler) SaveKeyword(c *gin.Context) {
+	var req AutomationKeywordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	keyword, err := h.keywordService.SaveKeyword(c.Request.Context(), req.Kind, req.Keyword, *req.Weight)
+	if err != nil {
+		if err == services.ErrInvalidAutomationKeyword {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("save automation keyword", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "automation_handler", "save_keyword")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Automation keyword saved",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"keyword": keyword.Keyword,
+			"kind":    keyword.Kind,
+			"weight":  keyword.Weight,
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": keyword,
+	})
+}
+
+// DeleteKeyword handles DELETE /api/automation/keywords/:kind/:keyword
+func (h *AutomationHandler) DeleteKeyword(c *gin.Context) {
+	var params AutomationKeywordParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.keywordService.DeleteKeyword(c.Request.Context(), params.Kind, params.Keyword); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Automation keyword"))
+			return
+		}
+		apiErr := errors.DatabaseError("delete automation keyword", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "automation_handler", "delete_keyword")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Automation keyword deleted",
+	})
+}
+
+// PreviewKeyword handles POST /api/automation/keywords/preview. It reports how a proposed
+// keyword would change historical feasibility without saving it.
+func (h *AutomationHandler) PreviewKeyword(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("preview_keyword")
+
+	var query RankedAnalyticsQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	var req AutomationKeywordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	filters := query.ToFilters()
+
+	preview, err := h.keywordService.PreviewKeyword(c.Request.Context(), req.Kind, req.Keyword, *req.Weight, filters)
+	if err != nil {
+		if err == services.ErrInvalidAutomationKeyword {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("preview automation keyword", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "automation_handler", "preview_keyword")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("preview_keyword", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"keyword":           preview.Keyword,
+			"matching":          preview.MatchingIncidents,
+			"became_feasible":   preview.BecameFeasible,
+			"became_infeasible": preview.BecameInfeasible,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    preview,
+		"filters": filters,
+	})
+}
+
+// ListRunbooks handles GET /api/automation/runbooks
+func (h *AutomationHandler) ListRunbooks(c *gin.Context) {
+	runbooks, err := h.runbookService.ListRunbooks(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve runbooks", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "automation_handler", "list_runbooks")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, runbooks, nil, gin.H{
+		"data":  runbooks,
+		"count": len(runbooks),
+	})
+}
+
+// GetRunbook handles GET /api/automation/runbooks/:name
+func (h *AutomationHandler) GetRunbook(c *gin.Context) {
+	var params RunbookParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	runbook, err := h.runbookService.GetRunbook(c.Request.Context(), params.Name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Runbook"))
+			return
+		}
+		apiErr := errors.DatabaseError("retrieve runbook", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "automation_handler", "get_runbook")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": runbook,
+	})
+}
+
+// SaveRunbook handles POST /api/automation/runbooks
+func (h *AutomationHandler) SaveRunbook(c *gin.Context) {
+	var req RunbookRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	runbook, err := h.runbookService.SaveRunbook(c.Request.Context(), req.ToRunbook())
+	if err != nil {
+		if err == services.ErrInvalidRunbook {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("save runbook", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "automation_handler", "save_runbook")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Runbook saved",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"runbook":          runbook.Name,
+			"keywords":         len(runbook.Keywords),
+			"it_process_group": runbook.ITProcessGroup,
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": runbook,
+	})
+}
+
+// DeleteRunbook handles DELETE /api/automation/runbooks/:name
+func (h *AutomationHandler) DeleteRunbook(c *gin.Context) {
+	var params RunbookParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.runbookService.DeleteRunbook(c.Request.Context(), params.Name); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Runbook"))
+			return
+		}
+		apiErr := errors.DatabaseError("delete runbook", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "automation_handler", "delete_runbook")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Runbook deleted",
+	})
+}
+
+// GetCandidate handles GET /api/analytics/automation/candidates/:id, where the ID is the
+// candidate's IT process group
+func (h *AutomationHandler) GetCandidate(c *gin.Context) {
+	candidate, ok := h.findCandidate(c, "get_candidate")
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": candidate,
+	})
+}
+
+// CreateTicket handles POST /api/analytics/automation/candidates/:id/ticket. It creates a Jira
+// issue describing the candidate and stores the issue key; a candidate that already has a
+// ticket returns it unchanged.
+func (h *AutomationHandler) CreateTicket(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("create_ticket")
+
+	var req AutomationTicketRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+	candidate, ok := h.findCandidate(c, "create_ticket")
+	if !ok {
+		return
+	}
+
+	ticket, created, err := h.ticketService.CreateTicket(c.Request.Context(), candidate, services.TicketOptions{
+		ProjectKey: req.ProjectKey,
+		IssueType:  req.IssueType,
+	})
+	if err != nil {
+		var apiErr *errors.APIError
+		switch {
+		case stderrors.Is(err, services.ErrMissingJiraProject):
+			apiErr = errors.BadRequest(err.Error())
+		case stderrors.Is(err, services.ErrJiraNotConfigured):
+			apiErr = errors.NewAPIError(errors.ErrServiceUnavailable, err.Error()).
+				WithUserMessage("Ticket creation is not set up on this server")
+		case stderrors.Is(err, services.ErrJiraRequestFailed):
+			apiErr = errors.NewAPIError(errors.ErrServiceUnavailable, err.Error()).
+				WithUserMessage("Jira could not create the ticket")
+		default:
+			apiErr = errors.DatabaseError("create automation ticket", err)
+		}
+		monitoring.TrackError(c.Request.Context(), apiErr, "automation_handler", "create_ticket")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("create_ticket", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"it_process_group": ticket.ITProcessGroup,
+			"issue_key":        ticket.IssueKey,
+			"created":          created,
+		}))
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{
+		"data":    ticket,
+		"created": created,
+	})
+}
+
+// allowPipeline refuses the automation opportunity pipeline to users with a data scope, sending
+// the error. Candidates are whole IT process groups, and their projected and realized savings
+// count the incidents of every application, so a scoped view would mix both.
+func allowPipeline(c *gin.Context) bool {
+	if services.DataScopeFromContext(c.Request.Context()) != nil {
+		errors.SendError(c, errors.NewAPIError(errors.ErrForbidden,
+			"Automation opportunities are not available to users with a data scope"))
+		return false
+	}
+	return true
+}
+
+// ListOpportunities handles GET /api/v2/automation/opportunities
+func (h *AutomationHandler) ListOpportunities(c *gin.Context) {
+	if !allowPipeline(c) {
+		return
+	}
+	var query AutomationOpportunityQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	opportunities, err := h.pipelineService.ListOpportunities(c.Request.Context(), query.Status, query.Assignee)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve automation opportunities", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "automation_handler", "list_opportunities")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, opportunities, nil, gin.H{
+		"data":  opportunities,
+		"count": len(opportunities),
+	})
+}
+
+// GetOpportunity handles GET /api/v2/automation/opportunities/:id, where the ID is the
+// candidate's IT process group
+func (h *AutomationHandler) GetOpportunity(c *gin.Context) {
+	if !allowPipeline(c) {
+		return
+	}
+	var params AutomationCandidateParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	opportunity, err := h.pipelineService.GetOpportunity(c.Request.Context(), params.ID)
+	if err != nil {
+		h.sendOpportunityError(c, err, "get_opportunity")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": opportunity,
+	})
+}
+
+// UpdateOpportunity handles PUT /api/v2/automation/opportunities/:id. It changes the status,
+// assignee or handling time of a candidate and records the update with its note; a candidate
+// that is not tracked yet starts as identified.
+func (h *AutomationHandler) UpdateOpportunity(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("update_opportunity")
+	if !allowPipeline(c) {
+		return
+	}
+
+	var params AutomationCandidateParams
+	if !bindURI(c, &params) {
+		return
+	}
+	var req AutomationOpportunityRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	opportunity, err := h.pipelineService.UpdateOpportunity(c.Request.Context(), params.ID, services.OpportunityUpdate{
+		Status:          req.Status,
+		Assignee:        req.Assignee,
+		Note:            req.Note,
+		HandlingMinutes: req.HandlingMinutes,
+	}, requestUser(c))
+	if err != nil {
+		h.sendOpportunityError(c, err, "update_opportunity")
+		return
+	}
+
+	logger.LogDuration("update_opportunity", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"it_process_group": opportunity.ITProcessGroup,
+			"status":           opportunity.Status,
+			"assignee":         opportunity.Assignee,
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": opportunity,
+	})
+}
+
+// GetPipeline handles GET /api/v2/analytics/automation/pipeline, the count of candidates in each
+// review status and the realized against projected savings of implemented ones
+func (h *AutomationHandler) GetPipeline(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_pipeline")
+	if !allowPipeline(c) {
+		return
+	}
+
+	pipeline, err := h.pipelineService.GetPipeline(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve automation pipeline", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "automation_handler", "get_pipeline")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_pipeline", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"untracked":   pipeline.Untracked,
+			"implemented": len(pipeline.Implemented),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": pipeline,
+	})
+}
+
+// sendOpportunityError answers a failed opportunity operation, with 400 for updates and status
+// changes that are not allowed and 404 for candidates that are not tracked or have no
+// automatable incidents
+func (h *AutomationHandler) sendOpportunityError(c *gin.Context, err error, operation string) {
+	switch {
+	case stderrors.Is(err, services.ErrInvalidOpportunityTransition):
+		errors.SendError(c, errors.NewAPIError(errors.ErrInvalidStatus, err.Error()))
+	case stderrors.Is(err, services.ErrInvalidOpportunityUpdate):
+		errors.SendError(c, errors.BadRequest(err.Error()))
+	case err == sql.ErrNoRows:
+		errors.SendError(c, errors.NotFound("Automation opportunity"))
+	default:
+		apiErr := errors.DatabaseError("manage automation opportunity", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "automation_handler", operation)
+		errors.SendError(c, apiErr)
+	}
+}
+
+// findCandidate loads the candidate named in the path with the query's filters, sending the
+// error response when it cannot
+func (h *AutomationHandler) findCandidate(c *gin.Context, operation string) (*services.AutomationCandidateDetail, bool) {
+	var params AutomationCandidateParams
+	if !bindURI(c, &params) {
+		return nil, false
+	}
+	var query AutomationCandidateQuery
+	if !bindQuery(c, &query) {
+		return nil, false
+	}
+
+	candidate, err := h.ticketService.GetCandidate(c.Request.Context(), params.ID, query.ToFilters(), query.HandlingMinutes)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Automation candidate"))
+			return nil, false
+		}
+		apiErr := errors.DatabaseError("retrieve automation candidate", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "automation_handler", operation)
+		errors.SendError(c, apiErr)
+		return nil, false
+	}
+	return candidate, true
+}
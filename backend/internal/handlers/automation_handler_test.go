@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutomationHandler_Keywords(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewAutomationHandler(db)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "automation keyword",
+			body:           `{"keyword":"hotfix","kind":"automation","weight":0.6}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "manual keyword",
+			body:           `{"keyword":"vendor","kind":"manual","weight":-0.7}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing weight",
+			body:           `{"keyword":"rollback","kind":"automation"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "weight out of range",
+			body:           `{"keyword":"rollback","kind":"automation","weight":2}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid kind",
+			body:           `{"keyword":"rollback","kind":"other","weight":0.5}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "multi-word keyword",
+			body:           `{"keyword":"roll back","kind":"automation","weight":0.5}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/automation/keywords", strings.NewReader(tt.body))
+			handler.SaveKeyword(c)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	// List keywords
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/automation/keywords", nil)
+	handler.ListKeywords(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(2), response["count"])
+
+	// Delete keyword, then it is gone
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/automation/keywords/manual/vendor", nil)
+	c.Params = []gin.Param{{Key: "kind", Value: "manual"}, {Key: "keyword", Value: "vendor"}}
+	handler.DeleteKeyword(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/automation/keywords/manual/vendor", nil)
+	c.Params = []gin.Param{{Key: "kind", Value: "manual"}, {Key: "keyword", Value: "vendor"}}
+	handler.DeleteKeyword(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAutomationHandler_Runbooks(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewAutomationHandler(db)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "keyword runbook",
+			body:           `{"name":"Password reset bot","keywords":["password reset","locked out"]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "process group runbook",
+			body:           `{"name":"Disk cleanup","description":"Nightly cleanup job","it_process_group":"Storage"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing name",
+			body:           `{"keywords":["vpn"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "no keywords or process group",
+			body:           `{"name":"Empty"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/automation/runbooks", strings.NewReader(tt.body))
+			handler.SaveRunbook(c)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	// List runbooks
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/automation/runbooks", nil)
+	handler.ListRunbooks(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(2), response["count"])
+
+	// Get, delete, then it is gone
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/automation/runbooks/Disk%20cleanup", nil)
+	c.Params = []gin.Param{{Key: "name", Value: "Disk cleanup"}}
+	handler.GetRunbook(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/automation/runbooks/Disk%20cleanup", nil)
+	c.Params = []gin.Param{{Key: "name", Value: "Disk cleanup"}}
+	handler.DeleteRunbook(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/automation/runbooks/Disk%20cleanup", nil)
+	c.Params = []gin.Param{{Key: "name", Value: "Disk cleanup"}}
+	handler.GetRunbook(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAutomationHandler_PreviewKeyword(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+	handler := NewAutomationHandler(db)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/automation/keywords/preview?priorities=P1,P2",
+		strings.NewReader(`{"keyword":"issue","kind":"automation","weight":1}`))
+	handler.PreviewKeyword(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "issue", data["keyword"])
+	assert.Contains(t, data, "became_feasible")
+	assert.NotNil(t, response["filters"])
+
+	// Preview leaves the keyword list untouched
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/automation/keywords", nil)
+	handler.ListKeywords(c)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(0), response["count"])
+
+	// Invalid priority filter is rejected
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/automation/keywords/preview?priorities=P9",
+		strings.NewReader(`{"keyword":"issue","kind":"automation","weight":1}`))
+	handler.PreviewKeyword(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAutomationHandler_CandidateTicket(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+	handler := NewAutomationHandler(db)
+
+	tests := []struct {
+		name           string
+		group          string
+		query          string
+		expectedStatus int
+	}{
+		{
+			name:           "candidate with filters",
+			group:          "Infrastructure",
+			query:          "?priorities=P3&handling_minutes=45",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "group without automatable incidents",
+			group:          "Networking",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "zero handling minutes uses the default",
+			group:          "Infrastructure",
+			query:          "?handling_minutes=0",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "handling minutes above a day",
+			group:          "Infrastructure",
+			query:          "?handling_minutes=2000",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/analytics/automation/candidates/"+tt.group+tt.query, nil)
+			c.Params = []gin.Param{{Key: "id", Value: tt.group}}
+			handler.GetCandidate(c)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	// Without a configured Jira site no ticket can be created
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/analytics/automation/candidates/Infrastructure/ticket",
+		strings.NewReader(`{"project_key":"AUTO"}`))
+	c.Params = []gin.Param{{Key: "id", Value: "Infrastructure"}}
+	handler.CreateTicket(c)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	// Unknown body fields are rejected
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/analytics/automation/candidates/Infrastructure/ticket",
+		strings.NewReader(`{"project":"AUTO"}`))
+	c.Params = []gin.Param{{Key: "id", Value: "Infrastructure"}}
+	handler.CreateTicket(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAutomationHandler_Opportunities(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+	handler := NewAutomationHandler(db)
+
+	tests := []struct {
+		name           string
+		group          string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "start review",
+			group:          "Infrastructure",
+			body:           `{"status":"under_review","assignee":"sam","note":"Looks repetitive"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "skip approval",
+			group:          "Infrastructure",
+			body:           `{"status":"implemented"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown status",
+			group:          "Infrastructure",
+			body:           `{"status":"done"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "group without automatable incidents",
+			group:          "Networking",
+			body:           `{"status":"under_review"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("PUT", "/automation/opportunities/"+tt.group, strings.NewReader(tt.body))
+			c.Params = []gin.Param{{Key: "id", Value: tt.group}}
+			handler.UpdateOpportunity(c)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	// List opportunities under review
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/automation/opportunities?status=under_review", nil)
+	handler.ListOpportunities(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(1), response["count"])
+
+	// Untracked candidates are not found
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/automation/opportunities/Networking", nil)
+	c.Params = []gin.Param{{Key: "id", Value: "Networking"}}
+	handler.GetOpportunity(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Pipeline dashboard
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/analytics/automation/pipeline", nil)
+	handler.GetPipeline(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Users with a data scope are refused the pipeline and the opportunities
+	scoped := func(method, target string) *http.Request {
+		req := httptest.NewRequest(method, target, strings.NewReader(`{"status":"identified"}`))
+		return req.WithContext(services.WithDataScope(req.Context(), &services.DataScope{
+			UserID: "analyst", Applications: []string{"Portal"},
+		}))
+	}
+	for name, call := range map[string]func(*gin.Context){
+		"list":     handler.ListOpportunities,
+		"get":      handler.GetOpportunity,
+		"update":   handler.UpdateOpportunity,
+		"pipeline": handler.GetPipeline,
+	} {
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request = scoped("PUT", "/automation/opportunities/Infrastructure")
+		c.Params = []gin.Param{{Key: "id", Value: "Infrastructure"}}
+		call(c)
+		assert.Equal(t, http.StatusForbidden, w.Code, name)
+	}
+}
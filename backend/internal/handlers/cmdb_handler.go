@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCMDBFileSize caps the CMDB CSV files accepted for import
+const maxCMDBFileSize = 20 << 20 // 20MB
+
+// CMDBHandler handles the CMDB enrichment endpoints
+type CMDBHandler struct {
+	cmdbService *services.CMDBService
+	logger      *logging.Logger
+}
+
+// NewCMDBHandler creates a new CMDB handler
+func NewCMDBHandler(db *sql.DB) *CMDBHandler {
+	return &CMDBHandler{
+		cmdbService: services.NewCMDBService(db),
+		logger:      logging.GetGlobalLogger().WithComponent("cmdb_handler"),
+	}
+}
+
+// SetServiceNowConfig sets the ServiceNow instance CMDB items are synced from
+func (h *CMDBHandler) SetServiceNowConfig(config *services.ServiceNowConfig) {
+	h.cmdbService.SetServiceNowConfig(config)
+}
+
+// ListItems handles GET /api/cmdb/items
+func (h *CMDBHandler) ListItems(c *gin.Context) {
+	var query CMDBItemsQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	items, err := h.cmdbService.ListItems(c.Request.Context(), query.Type)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve CMDB items", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "cmdb_handler", "list_items")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, items, query, gin.H{
+		"data":  items,
+		"count": len(items),
+	})
+}
+
+// ImportCSV handles POST /api/cmdb/import. It replaces the CSV-loaded CMDB items with those of
+// the uploaded file and re-enriches the stored incidents.
+func (h *CMDBHandler) ImportCSV(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("import_cmdb")
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		apiErr := errors.NewAPIError(errors.ErrMissingFile, "No file provided").
+			WithUserMessage("Please select a CMDB export to import")
+		monitoring.TrackError(c.Request.Context(), apiErr, "cmdb_handler", "import_csv")
+		errors.SendError(c, apiErr)
+		return
+	}
+	if file.Size > maxCMDBFileSize {
+		errors.SendError(c, errors.FileUploadError("file_too_large"))
+		return
+	}
+	reader, err := file.Open()
+	if err != nil {
+		apiErr := errors.InternalServer("Failed to read the uploaded file: " + err.Error())
+		monitoring.TrackError(c.Request.Context(), apiErr, "cmdb_handler", "import_csv")
+		errors.SendError(c, apiErr)
+		return
+	}
+	defer reader.Close()
+
+	result, err := h.cmdbService.ImportCSV(c.Request.Context(), reader)
+	if err != nil {
+		if stderrors.Is(err, services.ErrInvalidCMDBImport) {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("import CMDB items", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "cmdb_handler", "import_csv")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("import_cmdb", start, logRefresh(result))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+	})
+}
+
+// SyncServiceNow handles POST /api/cmdb/sync. It replaces the synced CMDB items with those of
+// the configured ServiceNow instance and re-enriches the stored incidents.
+func (h *CMDBHandler) SyncServiceNow(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("sync_cmdb")
+
+	result, err := h.cmdbService.SyncServiceNow(c.Request.Context())
+	if err != nil {
+		var apiErr *errors.APIError
+		switch {
+		case stderrors.Is(err, services.ErrServiceNowNotConfigured):
+			apiErr = errors.NewAPIError(errors.ErrServiceUnavailable, err.Error()).
+				WithUserMessage("ServiceNow CMDB sync is not set up on this server")
+		case stderrors.Is(err, services.ErrServiceNowRequestFailed):
+			apiErr = errors.NewAPIError(errors.ErrServiceUnavailable, err.Error()).
+				WithUserMessage("The ServiceNow CMDB could not be read")
+		default:
+			apiErr = errors.DatabaseError("sync CMDB items", err)
+		}
+		monitoring.TrackError(c.Request.Context(), apiErr, "cmdb_handler", "sync_servicenow")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("sync_cmdb", start, logRefresh(result))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+	})
+}
+
+// logRefresh returns the log metadata of a CMDB import or sync
+func logRefresh(result *services.CMDBRefreshResult) *logging.Logger {
+	return logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+		"source":            result.Source,
+		"items":             result.Items,
+		"skipped":           len(result.Skipped),
+		"updated_incidents": result.UpdatedIncidents,
+	})
+}
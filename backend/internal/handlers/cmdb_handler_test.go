@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCMDBHandler_Items(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewCMDBHandler(db)
+
+	importFile := func(content string) *httptest.ResponseRecorder {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "cmdb.csv")
+		require.NoError(t, err)
+		_, err = io.WriteString(part, content)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/cmdb/import", body)
+		c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+		handler.ImportCSV(c)
+		return w
+	}
+
+	// Import a CMDB export
+	w := importFile("type,name,owner,environment,criticality\napplication,Portal,Web Team,Production,High\nservice,Payments,Finance IT,Production,Critical\n")
+	require.Equal(t, http.StatusOK, w.Code)
+	var imported struct {
+		Data services.CMDBRefreshResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &imported))
+	assert.Equal(t, 2, imported.Data.Items)
+
+	// A file without a type column is rejected
+	w = importFile("name,owner\nPortal,Web Team\n")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// List application items
+	w = httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/cmdb/items?type=application", nil)
+	handler.ListItems(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(1), response["count"])
+
+	// An unknown type is rejected
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/cmdb/items?type=database", nil)
+	handler.ListItems(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Sync without a configured ServiceNow instance
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/cmdb/sync", nil)
+	handler.SyncServiceNow(c)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigHandler serves the runtime-tunable system settings to admins
+type ConfigHandler struct {
+	configService *services.SystemConfigService
+	logger        *logging.Logger
+}
+
+// NewConfigHandler creates a new config handler
+func NewConfigHandler(configService *services.SystemConfigService) *ConfigHandler {
+	return &ConfigHandler{
+		configService: configService,
+		logger:        logging.GetGlobalLogger().WithComponent("config_handler"),
+	}
+}
+
+// GetConfig handles GET /api/admin/config
+func (h *ConfigHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.configService.List(),
+	})
+}
+
+// UpdateConfig handles PUT /api/admin/config. Only the settings in the body change, and none
+// do if any value is invalid.
+func (h *ConfigHandler) UpdateConfig(c *gin.Context) {
+	var req SystemConfigRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	actor := requestUser(c)
+	changes, err := h.configService.Update(c.Request.Context(), req.Settings, actor)
+	if err != nil {
+		if stderrors.Is(err, services.ErrInvalidSetting) {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("update system config", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "config_handler", "update_config")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	for _, change := range changes {
+		h.logger.WithContext(c.Request.Context()).Warn("System setting changed",
+			logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+				"key":        change.Key,
+				"old_value":  change.OldValue,
+				"new_value":  change.NewValue,
+				"changed_by": actor,
+			}))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    h.configService.List(),
+		"changes": changes,
+	})
+}
+
+// GetConfigAudit handles GET /api/admin/config/audit
+func (h *ConfigHandler) GetConfigAudit(c *gin.Context) {
+	var query ConfigAuditQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 100
+	}
+
+	changes, err := h.configService.ListAudit(c.Request.Context(), query.Key, query.Limit)
+	if err != nil {
+		apiErr := errors.DatabaseError("list config audit", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "config_handler", "get_config_audit")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": changes,
+	})
+}
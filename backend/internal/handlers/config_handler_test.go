@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	configService, err := services.NewSystemConfigService(context.Background(), createTestDB(t))
+	require.NoError(t, err)
+
+	threshold := 0.0
+	configService.Register(services.Setting{
+		Key: "memory.delay_threshold_mb", Type: services.SettingFloat, Default: 512.0, Max: 4096,
+		Apply: func(value interface{}) { threshold = value.(float64) },
+	})
+	handler := NewConfigHandler(configService)
+
+	router := gin.New()
+	admin := router.Group("/api/admin", AdminAuth("s3cret"))
+	admin.GET("/config", handler.GetConfig)
+	admin.PUT("/config", handler.UpdateConfig)
+	admin.GET("/config/audit", handler.GetConfigAudit)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", "ops-admin")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "no settings", body: `{"settings":{}}`, expectedStatus: http.StatusBadRequest},
+		{name: "unknown setting", body: `{"settings":{"cache.ttl":5}}`, expectedStatus: http.StatusBadRequest},
+		{name: "out of range", body: `{"settings":{"memory.delay_threshold_mb":9000}}`, expectedStatus: http.StatusBadRequest},
+		{name: "valid", body: `{"settings":{"memory.delay_threshold_mb":640}}`, expectedStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedStatus, send("PUT", "/api/admin/config", tt.body).Code)
+		})
+	}
+	assert.Equal(t, 640.0, threshold)
+
+	var config struct {
+		Data []services.SettingValue `json:"data"`
+	}
+	w := send("GET", "/api/admin/config", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &config))
+	require.Len(t, config.Data, 1)
+	assert.Equal(t, 640.0, config.Data[0].Value)
+	assert.Equal(t, 512.0, config.Data[0].Default)
+	assert.Equal(t, "ops-admin", config.Data[0].UpdatedBy)
+
+	var audit struct {
+		Data []services.ConfigChange `json:"data"`
+	}
+	w = send("GET", "/api/admin/config/audit?key=memory.delay_threshold_mb", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &audit))
+	require.Len(t, audit.Data, 1)
+	assert.Equal(t, 512.0, audit.Data[0].OldValue)
+	assert.Equal(t, 640.0, audit.Data[0].NewValue)
+	assert.Equal(t, "ops-admin", audit.Data[0].ChangedBy)
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CostCenterHandler handles the cost center registry and chargeback reporting endpoints
+type CostCenterHandler struct {
+	costCenterService *services.CostCenterService
+	logger            *logging.Logger
+}
+
+// NewCostCenterHandler creates a new cost center handler
+func NewCostCenterHandler(db *sql.DB) *CostCenterHandler {
+	return &CostCenterHandler{
+		costCenterService: services.NewCostCenterService(db),
+		logger:            logging.GetGlobalLogger().WithComponent("cost_center_handler"),
+	}
+}
+
+// ListAssignments handles GET /api/cost-centers
+func (h *CostCenterHandler) ListAssignments(c *gin.Context) {
+	assignments, err := h.costCenterService.ListAssignments(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve cost center assignments", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "cost_center_handler", "list_assignments")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, assignments, nil, gin.H{
+		"data":  assignments,
+		"count": len(assignments),
+	})
+}
+
+// SaveAssignment handles POST /api/cost-centers
+func (h *CostCenterHandler) SaveAssignment(c *gin.Context) {
+	var req CostCenterAssignmentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	assignment, err := h.costCenterService.SaveAssignment(c.Request.Context(), req.EntityType, req.EntityName, req.CostCenter, req.HourlyRate)
+	if err != nil {
+		apiErr := errors.DatabaseError("save cost center assignment", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "cost_center_handler", "save_assignment")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Cost center assignment saved",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"entity_type": assignment.EntityType,
+			"entity_name": assignment.EntityName,
+			"cost_center": assignment.CostCenter,
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": assignment,
+	})
+}
+
+// DeleteAssignment handles DELETE /api/cost-centers/:type/:name
+func (h *CostCenterHandler) DeleteAssignment(c *gin.Context) {
+	var params CostCenterAssignmentParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.costCenterService.DeleteAssignment(c.Request.Context(), params.EntityType, params.EntityName); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Cost center assignment"))
+			return
+		}
+		apiErr := errors.DatabaseError("delete cost center assignment", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "cost_center_handler", "delete_assignment")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cost center assignment deleted",
+	})
+}
+
+// GetChargebackReport handles GET /api/analytics/chargeback
+func (h *CostCenterHandler) GetChargebackReport(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_chargeback_report")
+
+	var query ChargebackQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	basis := query.Basis
+	if basis == "" {
+		basis = services.CostCenterEntityApplication
+	}
+	filters := query.ToFilters()
+
+	report, err := h.costCenterService.GetChargebackReport(c.Request.Context(), basis, query.Rate, filters)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve chargeback report", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "cost_center_handler", "get_chargeback_report")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_chargeback_report", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"basis":  basis,
+			"format": query.Format,
+			"lines":  len(report.Lines),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	if query.Format == "csv" {
+		writeChargebackCSV(c, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    report,
+		"filters": filters,
+	})
+}
+
+// GetCostSummary handles GET /api/analytics/cost
+func (h *CostCenterHandler) GetCostSummary(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_cost_summary")
+
+	var query CostQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	basis := query.Basis
+	if basis == "" {
+		basis = services.CostCenterEntityApplication
+	}
+	filters := query.ToFilters()
+
+	summary, err := h.costCenterService.GetCostSummary(c.Request.Context(), basis, query.Rate, filters)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve cost summary", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "cost_center_handler", "get_cost_summary")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_cost_summary", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"basis":          basis,
+			"incident_count": summary.IncidentCount,
+			"derived_count":  summary.DerivedCount,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    summary,
+		"filters": filters,
+	})
+}
+
+// writeChargebackCSV streams the chargeback lines as a CSV attachment for finance
+func writeChargebackCSV(c *gin.Context, report *services.ChargebackReport) {
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=chargeback-%s.csv", report.Basis))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"month", "cost_center", "incident_count", "resolution_hours", "amount"})
+	for _, line := range report.Lines {
+		writer.Write([]string{
+			line.Month,
+			line.CostCenter,
+			strconv.Itoa(line.IncidentCount),
+			strconv.FormatFloat(line.ResolutionHours, 'f', -1, 64),
+			strconv.FormatFloat(line.Amount, 'f', 2, 64),
+		})
+	}
+	writer.Flush()
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostCenterHandler_Assignments(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewCostCenterHandler(db)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "application assignment",
+			body:           `{"entity_type":"application","entity_name":"Billing","cost_center":"CC-100","hourly_rate":90}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "group assignment without rate",
+			body:           `{"entity_type":"group","entity_name":"Web Team","cost_center":"CC-200"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid entity type",
+			body:           `{"entity_type":"person","entity_name":"Jane","cost_center":"CC-300"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "negative rate",
+			body:           `{"entity_type":"application","entity_name":"Portal","cost_center":"CC-300","hourly_rate":-5}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/cost-centers", strings.NewReader(tt.body))
+			handler.SaveAssignment(c)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	// List assignments
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/cost-centers", nil)
+	handler.ListAssignments(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(2), response["count"])
+
+	// Delete unknown assignment
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/cost-centers/application/unknown", nil)
+	c.Params = []gin.Param{{Key: "type", Value: "application"}, {Key: "name", Value: "unknown"}}
+	handler.DeleteAssignment(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCostCenterHandler_GetChargebackReport(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewCostCenterHandler(db)
+
+	// JSON report
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/analytics/chargeback?basis=group&rate=120", nil)
+	handler.GetChargebackReport(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data, ok := response["data"].(map[string]interface{})
+	require.True(t, ok, "Data should be an object")
+	assert.Equal(t, "group", data["basis"])
+	assert.Equal(t, float64(120), data["default_rate"])
+
+	// CSV export
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/analytics/chargeback?format=csv", nil)
+	handler.GetChargebackReport(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "month,cost_center,incident_count,resolution_hours,amount\n"))
+
+	// Invalid rate
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/analytics/chargeback?rate=-10", nil)
+	handler.GetChargebackReport(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCostCenterHandler_GetCostSummary(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewCostCenterHandler(db)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/analytics/cost?basis=group&rate=90", nil)
+	handler.GetCostSummary(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data, ok := response["data"].(map[string]interface{})
+	require.True(t, ok, "Data should be an object")
+	assert.Equal(t, "group", data["basis"])
+	assert.Equal(t, float64(90), data["default_rate"])
+	for _, breakdown := range []string{"by_priority", "by_application", "by_month", "by_cost_center"} {
+		_, ok := data[breakdown].([]interface{})
+		assert.True(t, ok, "%s should be an array", breakdown)
+	}
+
+	// Invalid basis
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/analytics/cost?basis=division", nil)
+	handler.GetCostSummary(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"database/sql"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DataQualityHandler handles the data-quality alert endpoints
+type DataQualityHandler struct {
+	dataQualityService *services.DataQualityService
+}
+
+// NewDataQualityHandler creates a new data quality handler
+func NewDataQualityHandler(db *sql.DB) *DataQualityHandler {
+	return &DataQualityHandler{
+		dataQualityService: services.NewDataQualityService(db),
+	}
+}
+
+// ListAlerts handles GET /api/data-quality/alerts. Users with a data scope are refused, because
+// alerts count the incidents of every application of an upload.
+func (h *DataQualityHandler) ListAlerts(c *gin.Context) {
+	var query DataQualityAlertQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	if services.DataScopeFromContext(c.Request.Context()) != nil {
+		errors.SendError(c, errors.NewAPIError(errors.ErrForbidden,
+			"Data-quality alerts are not available to users with a data scope"))
+		return
+	}
+
+	alerts, err := h.dataQualityService.ListAlerts(c.Request.Context(), query.UploadID, query.Limit)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve data quality alerts", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "data_quality_handler", "list_alerts")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, alerts, nil, gin.H{
+		"data":  alerts,
+		"count": len(alerts),
+	})
+}
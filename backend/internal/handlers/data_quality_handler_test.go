@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataQualityHandler_ListAlerts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewDataQualityHandler(db)
+
+	dataQuality := services.NewDataQualityService(db)
+	for _, uploadID := range []string{"upload-a", "upload-b", "upload-b"} {
+		require.NoError(t, dataQuality.CreateAlert(context.Background(), &services.DataQualityAlert{
+			Type:     services.AlertUploadContinuity,
+			Severity: services.AlertSeverityWarning,
+			UploadID: uploadID,
+			Message:  "Counts differ from the previous upload",
+		}, map[string]interface{}{"discrepancy_count": 1}))
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "all alerts", path: "/data-quality/alerts", expectedStatus: http.StatusOK, expectedCount: 3},
+		{name: "one upload", path: "/data-quality/alerts?upload_id=upload-b", expectedStatus: http.StatusOK, expectedCount: 2},
+		{name: "limited", path: "/data-quality/alerts?limit=1", expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "invalid limit", path: "/data-quality/alerts?limit=-1", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", tt.path, nil)
+
+			handler.ListAlerts(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			alerts := response["data"].([]interface{})
+			require.Len(t, alerts, tt.expectedCount)
+			alert := alerts[0].(map[string]interface{})
+			assert.Equal(t, services.AlertUploadContinuity, alert["type"])
+			assert.Equal(t, 1.0, alert["details"].(map[string]interface{})["discrepancy_count"])
+		})
+	}
+
+	t.Run("user with a data scope", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		scope := &services.DataScope{UserID: "analyst", Applications: []string{"Portal"}}
+		c.Request = httptest.NewRequest("GET", "/data-quality/alerts", nil).
+			WithContext(services.WithDataScope(context.Background(), scope))
+
+		handler.ListAlerts(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
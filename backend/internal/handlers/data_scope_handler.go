@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DataScope is middleware restricting the request to the data scope of the calling user, so
+// analytics and incident queries further down only see the applications and groups allowed
+func DataScope(scopes *services.DataScopeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, err := scopes.GetScope(c.Request.Context(), requestUser(c))
+		if err != nil {
+			apiErr := errors.DatabaseError("resolve data scope", err)
+			monitoring.TrackError(c.Request.Context(), apiErr, "data_scope_handler", "resolve_scope")
+			errors.AbortWithError(c, apiErr)
+			return
+		}
+		c.Request = c.Request.WithContext(services.WithDataScope(c.Request.Context(), scope))
+		c.Next()
+	}
+}
+
+// DataScopeHandler handles the admin endpoints managing users' data scopes
+type DataScopeHandler struct {
+	scopes *services.DataScopeService
+	logger *logging.Logger
+}
+
+// NewDataScopeHandler creates a new data scope handler
+func NewDataScopeHandler(scopes *services.DataScopeService) *DataScopeHandler {
+	return &DataScopeHandler{
+		scopes: scopes,
+		logger: logging.GetGlobalLogger().WithComponent("data_scope_handler"),
+	}
+}
+
+// ListScopes handles GET /api/admin/scopes
+func (h *DataScopeHandler) ListScopes(c *gin.Context) {
+	scopes, err := h.scopes.ListScopes(c.Request.Context())
+	if err != nil {
+		h.sendError(c, err, "list_scopes")
+		return
+	}
+
+	sendList(c, scopes, nil, gin.H{
+		"data":  scopes,
+		"count": len(scopes),
+	})
+}
+
+// GetScope handles GET /api/admin/scopes/:user
+func (h *DataScopeHandler) GetScope(c *gin.Context) {
+	var params DataScopeParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	scope, err := h.scopes.GetScope(c.Request.Context(), params.User)
+	if err != nil {
+		h.sendError(c, err, "get_scope")
+		return
+	}
+	if scope == nil {
+		errors.SendError(c, errors.NotFound("Data scope"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": scope,
+	})
+}
+
+// SetScope handles PUT /api/admin/scopes/:user
+func (h *DataScopeHandler) SetScope(c *gin.Context) {
+	var params DataScopeParams
+	if !bindURI(c, &params) {
+		return
+	}
+	var req DataScopeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	actor := requestUser(c)
+	scope, err := h.scopes.SetScope(c.Request.Context(), params.User, req.Applications, req.Groups, actor)
+	if err != nil {
+		h.sendError(c, err, "set_scope")
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Warn("Data scope changed",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"user":         params.User,
+			"applications": len(scope.Applications),
+			"groups":       len(scope.Groups),
+			"changed_by":   actor,
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": scope,
+	})
+}
+
+// DeleteScope handles DELETE /api/admin/scopes/:user
+func (h *DataScopeHandler) DeleteScope(c *gin.Context) {
+	var params DataScopeParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.scopes.DeleteScope(c.Request.Context(), params.User); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Data scope"))
+			return
+		}
+		h.sendError(c, err, "delete_scope")
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Warn("Data scope removed",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"user":       params.User,
+			"changed_by": requestUser(c),
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Data scope removed",
+	})
+}
+
+// sendError answers a failed scope operation, with 400 for scopes allowing nothing
+func (h *DataScopeHandler) sendError(c *gin.Context, err error, operation string) {
+	if stderrors.Is(err, services.ErrEmptyScope) {
+		errors.SendError(c, errors.BadRequest(err.Error()))
+		return
+	}
+	apiErr := errors.DatabaseError("manage data scope", err)
+	monitoring.TrackError(c.Request.Context(), apiErr, "data_scope_handler", operation)
+	errors.SendError(c, apiErr)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataScopeHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	scopes := services.NewDataScopeService(db)
+	handler := NewDataScopeHandler(scopes)
+	analyticsHandler := NewAnalyticsHandler(db)
+
+	incidents := []models.Incident{
+		{ID: "i1", UploadID: "upload-1", IncidentID: "INC001", ReportDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			ApplicationName: "Portal", ResolutionGroup: "Web Team", Priority: "P1", Status: "Open"},
+		{ID: "i2", UploadID: "upload-1", IncidentID: "INC002", ReportDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			ApplicationName: "Billing", ResolutionGroup: "Finance Apps", Priority: "P2", Status: "Open"},
+	}
+	_, err := services.NewIncidentService(db).BatchInsertIncidents(context.Background(), incidents, "upload-1")
+	require.NoError(t, err)
+
+	router := gin.New()
+	api := router.Group("/api", DataScope(scopes))
+	api.GET("/admin/scopes", handler.ListScopes)
+	api.GET("/admin/scopes/:user", handler.GetScope)
+	api.PUT("/admin/scopes/:user", handler.SetScope)
+	api.DELETE("/admin/scopes/:user", handler.DeleteScope)
+	api.GET("/analytics/priority", analyticsHandler.GetPriorityAnalysis)
+
+	send := func(method, path, body, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", user)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "empty scope", method: "PUT", path: "/api/admin/scopes/analyst", body: `{"applications":[],"groups":[]}`, expectedStatus: http.StatusBadRequest},
+		{name: "unknown field", method: "PUT", path: "/api/admin/scopes/analyst", body: `{"apps":["Billing"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "valid scope", method: "PUT", path: "/api/admin/scopes/analyst", body: `{"applications":["Billing"]}`, expectedStatus: http.StatusOK},
+		{name: "get scope", method: "GET", path: "/api/admin/scopes/analyst", expectedStatus: http.StatusOK},
+		{name: "get missing scope", method: "GET", path: "/api/admin/scopes/nobody", expectedStatus: http.StatusNotFound},
+		{name: "delete missing scope", method: "DELETE", path: "/api/admin/scopes/nobody", expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedStatus, send(tt.method, tt.path, tt.body, "admin").Code)
+		})
+	}
+
+	priorities := func(user string) []services.PriorityAnalysis {
+		var response struct {
+			Data []services.PriorityAnalysis `json:"data"`
+		}
+		w := send("GET", "/api/analytics/priority", "", user)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data
+	}
+	assert.Len(t, priorities("admin"), 2)
+	scoped := priorities("analyst")
+	require.Len(t, scoped, 1)
+	assert.Equal(t, "P2", scoped[0].Priority)
+
+	var listed struct {
+		Data  []services.DataScope `json:"data"`
+		Count int                  `json:"count"`
+	}
+	w := send("GET", "/api/admin/scopes", "", "admin")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Equal(t, 1, listed.Count)
+	assert.Equal(t, "admin", listed.Data[0].UpdatedBy)
+
+	assert.Equal(t, http.StatusOK, send("DELETE", "/api/admin/scopes/analyst", "", "admin").Code)
+	assert.Len(t, priorities("analyst"), 2)
+}
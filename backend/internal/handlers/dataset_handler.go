@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Dataset endpoints live on the upload handler: a dataset is a group of uploads, stored and
+// processed in the background the same way as single uploads.
+
+// CreateDataset handles POST /api/datasets
+func (h *UploadHandler) CreateDataset(c *gin.Context) {
+	var req DatasetRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	dataset, err := h.datasetService.CreateDataset(c.Request.Context(), req.Name, req.Description)
+	if err != nil {
+		apiErr := errors.DatabaseError("create dataset", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "upload_handler", "create_dataset")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Dataset created",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"dataset_id": dataset.ID,
+			"name":       dataset.Name,
+		}))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": dataset,
+	})
+}
+
+// ListDatasets handles GET /api/datasets
+func (h *UploadHandler) ListDatasets(c *gin.Context) {
+	datasets, err := h.datasetService.ListDatasets(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve datasets", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "upload_handler", "list_datasets")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	for i := range datasets {
+		versionDataset(c, &datasets[i])
+	}
+
+	sendList(c, datasets, nil, gin.H{
+		"data":  datasets,
+		"count": len(datasets),
+	})
+}
+
+// GetDataset handles GET /api/datasets/:id, returning the dataset with its uploads
+func (h *UploadHandler) GetDataset(c *gin.Context) {
+	dataset, ok := h.findDataset(c, "get_dataset")
+	if !ok {
+		return
+	}
+
+	uploads, err := h.datasetService.ListUploads(c.Request.Context(), dataset.ID)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve dataset uploads", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "upload_handler", "get_dataset")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	versionDataset(c, dataset)
+	versionUploads(c, uploads)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    dataset,
+		"uploads": uploads,
+	})
+}
+
+// UploadDatasetFiles handles POST /api/datasets/:id/uploads. Every file in the multipart "files"
+// field becomes an upload in the dataset; if any file is rejected none are kept.
+func (h *UploadHandler) UploadDatasetFiles(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("upload_dataset_files")
+
+	dataset, ok := h.findDataset(c, "upload_dataset_files")
+	if !ok {
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["files"]) == 0 {
+		apiErr := errors.NewAPIError(errors.ErrMissingFile, "No files provided").
+			WithUserMessage("Please select one or more files to upload")
+		monitoring.TrackError(c.Request.Context(), apiErr, "upload_handler", "upload_dataset_files")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	uploads := make([]*models.Upload, 0, len(form.File["files"]))
+	for _, file := range form.File["files"] {
+		upload, apiErr := h.storeUpload(c.Request.Context(), file, dataset.ID)
+		if apiErr != nil {
+			for _, stored := range uploads {
+				h.deleteUpload(c, stored)
+			}
+			apiErr = apiErr.WithDetails(file.Filename)
+			monitoring.TrackError(c.Request.Context(), apiErr, "upload_handler", "upload_dataset_files")
+			errors.SendError(c, apiErr)
+			return
+		}
+		uploads = append(uploads, upload)
+	}
+
+	logger.LogDuration("upload_dataset_files", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"dataset_id": dataset.ID,
+			"files":      len(uploads),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Files uploaded successfully",
+		"uploads": uploads,
+		"count":   len(uploads),
+	})
+}
+
+// ProcessDataset handles POST /api/datasets/:id/process. It processes the dataset's pending
+// uploads together in the background; the optional body takes the same options as a single upload.
+func (h *UploadHandler) ProcessDataset(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("process_dataset")
+
+	dataset, ok := h.findDataset(c, "process_dataset")
+	if !ok {
+		return
+	}
+
+	var req ProcessUploadRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+	options := req.ToOptions()
+
+	if !h.checkProcessingOptions(c, options, "process_dataset") {
+		return
+	}
+
+	// Claim the pending uploads before responding, so a concurrent request cannot process them too
+	queued, err := h.processingService.QueueDataset(c.Request.Context(), dataset.ID, options)
+	if err != nil {
+		if err == services.ErrNoPendingUploads {
+			apiErr := errors.NewAPIError(errors.ErrInvalidStatus, services.ErrNoPendingUploads.Error()).
+				WithUserMessage("All files in this dataset have already been processed or are being processed").
+				WithSuggestions([]string{
+					"Upload more files to the dataset",
+					"Check the status of the dataset's uploads",
+				})
+			errors.SendError(c, apiErr)
+			return
+		}
+		apiErr := errors.DatabaseError("queue dataset uploads", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "upload_handler", "process_dataset")
+		errors.SendError(c, apiErr)
+		return
+	}
+	pending := make([]string, 0, len(queued))
+	for _, upload := range queued {
+		pending = append(pending, upload.ID)
+	}
+
+	// Start processing in background
+	ctx, cancel := h.processingContext(c)
+	go func() {
+		defer cancel()
+		_, err := h.processingService.ProcessQueuedDataset(ctx, dataset.ID, queued, options)
+		if err != nil {
+			logger.Error("Processing failed for dataset", err,
+				logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+					"dataset_id": dataset.ID,
+				}))
+
+			apiErr := errors.ProcessingFailed(err.Error())
+			monitoring.TrackError(ctx, apiErr, "processing_service", "process_dataset")
+		} else {
+			logger.Info("Dataset processing completed",
+				logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+					"dataset_id": dataset.ID,
+				}))
+		}
+	}()
+
+	logger.LogDuration("process_dataset", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"dataset_id": dataset.ID,
+			"uploads":    len(pending),
+			"dry_run":    options.DryRun,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":            "Processing started",
+		"dataset_id":         dataset.ID,
+		"upload_ids":         pending,
+		"processing_options": options,
+	})
+}
+
+// findDataset loads the dataset named in the path, sending the error response when it cannot
+func (h *UploadHandler) findDataset(c *gin.Context, operation string) (*services.Dataset, bool) {
+	var params DatasetParams
+	if !bindURI(c, &params) {
+		return nil, false
+	}
+
+	dataset, err := h.datasetService.GetDataset(c.Request.Context(), params.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Dataset"))
+			return nil, false
+		}
+		apiErr := errors.DatabaseError("retrieve dataset", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "upload_handler", operation)
+		errors.SendError(c, apiErr)
+		return nil, false
+	}
+	return dataset, true
+}
+
+// deleteUpload removes an upload stored earlier in a request that is being rejected
+func (h *UploadHandler) deleteUpload(c *gin.Context, upload *models.Upload) {
+	if _, err := h.db.ExecContext(c.Request.Context(), "DELETE FROM uploads WHERE id = ?", upload.ID); err != nil {
+		h.logger.WithContext(c.Request.Context()).Error("Failed to remove rejected dataset upload", err,
+			logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+				"upload_id": upload.ID,
+			}))
+	}
+	h.fileStore.DeleteFile(upload.Filename)
+}
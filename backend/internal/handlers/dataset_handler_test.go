@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/services"
+	"incident-management-system/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createMultiFileForm creates a multipart form with several files in the "files" field
+func createMultiFileForm(t *testing.T, filenames ...string) (*bytes.Buffer, *multipart.Writer) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	for _, filename := range filenames {
+		part, err := writer.CreateFormFile("files", filename)
+		require.NoError(t, err, "Failed to create form file")
+		_, err = io.WriteString(part, "test content")
+		require.NoError(t, err, "Failed to write file content")
+	}
+
+	require.NoError(t, writer.Close(), "Failed to close writer")
+	return body, writer
+}
+
+func TestUploadHandler_Datasets(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	fileStore := storage.NewFileStore(t.TempDir())
+
+	received := make(chan string, 1)
+	mockService := &MockProcessingService{
+		ProcessDatasetFunc: func(ctx context.Context, datasetID string, options models.ProcessingOptions) ([]*services.ProcessingProgress, error) {
+			received <- datasetID
+			return nil, nil
+		},
+		QueueDatasetFunc: func(ctx context.Context, datasetID string, options models.ProcessingOptions) ([]models.Upload, error) {
+			uploads, err := services.NewDatasetService(db).ListUploads(ctx, datasetID)
+			if err != nil || len(uploads) == 0 {
+				return nil, services.ErrNoPendingUploads
+			}
+			return uploads, nil
+		},
+	}
+	handler := NewUploadHandler(db, fileStore, mockService)
+
+	// Create the dataset
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/datasets", strings.NewReader(`{"name":"2024-03 regional"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handler.CreateDataset(c)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created struct {
+		Data services.Dataset `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	datasetID := created.Data.ID
+	params := []gin.Param{{Key: "id", Value: datasetID}}
+
+	// Processing an empty dataset is rejected
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/datasets/"+datasetID+"/process", nil)
+	c.Params = params
+	handler.ProcessDataset(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	tests := []struct {
+		name           string
+		datasetID      string
+		filenames      []string
+		expectedStatus int
+	}{
+		{
+			name:           "multiple files",
+			datasetID:      datasetID,
+			filenames:      []string{"emea.xlsx", "apac.xlsx"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "one invalid file rejects all",
+			datasetID:      datasetID,
+			filenames:      []string{"amer.xlsx", "notes.txt"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "no files",
+			datasetID:      datasetID,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown dataset",
+			datasetID:      "missing",
+			filenames:      []string{"emea.xlsx"},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, writer := createMultiFileForm(t, tt.filenames...)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/datasets/"+tt.datasetID+"/uploads", body)
+			c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+			c.Params = []gin.Param{{Key: "id", Value: tt.datasetID}}
+
+			handler.UploadDatasetFiles(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	// Only the files from the accepted request belong to the dataset
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/datasets/"+datasetID, nil)
+	c.Params = params
+	handler.GetDataset(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var dataset struct {
+		Data    services.Dataset `json:"data"`
+		Uploads []models.Upload  `json:"uploads"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &dataset))
+	assert.Equal(t, 2, dataset.Data.UploadCount)
+	assert.Equal(t, map[string]int{"uploaded": 2}, dataset.Data.UploadStatus)
+	require.Len(t, dataset.Uploads, 2)
+	for _, upload := range dataset.Uploads {
+		assert.Equal(t, datasetID, upload.DatasetID)
+	}
+
+	// Process the dataset
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/datasets/"+datasetID+"/process", strings.NewReader(`{"dedup_strategy":"last"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	handler.ProcessDataset(c)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response["upload_ids"], 2)
+	assert.Equal(t, datasetID, <-received)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDebugRouter(t *testing.T) (*gin.Engine, string) {
+	gin.SetMode(gin.TestMode)
+	dumpDir := t.TempDir()
+	bodyLogger, err := logging.NewBodyLogger(logging.GetGlobalLogger(), logging.DefaultBodyLogConfig())
+	require.NoError(t, err)
+	handler := NewDebugHandler(dumpDir, bodyLogger)
+
+	router := gin.New()
+	debug := router.Group("/debug", AdminAuth("s3cret"))
+	debug.GET("/pprof/*profile", handler.Pprof)
+	debug.GET("/vars", handler.Vars)
+	debug.POST("/goroutines/dump", handler.DumpGoroutines)
+	debug.GET("/body-logging", handler.GetBodyLogging)
+	debug.PUT("/body-logging", handler.UpdateBodyLogging)
+	return router, dumpDir
+}
+
+func TestAdminAuth(t *testing.T) {
+	router, _ := setupDebugRouter(t)
+
+	tests := []struct {
+		name           string
+		header         string
+		expectedStatus int
+	}{
+		{name: "no token", expectedStatus: http.StatusUnauthorized},
+		{name: "wrong token", header: "Bearer guess", expectedStatus: http.StatusUnauthorized},
+		{name: "token without bearer scheme", header: "s3cret", expectedStatus: http.StatusUnauthorized},
+		{name: "admin token", header: "Bearer s3cret", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/debug/vars", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	t.Run("empty admin token rejects everyone", func(t *testing.T) {
+		open := gin.New()
+		open.GET("/debug/vars", AdminAuth(""), func(c *gin.Context) { c.Status(http.StatusOK) })
+		req := httptest.NewRequest("GET", "/debug/vars", nil)
+		req.Header.Set("Authorization", "Bearer ")
+		w := httptest.NewRecorder()
+		open.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestDebugHandler_Diagnostics(t *testing.T) {
+	router, dumpDir := setupDebugRouter(t)
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("pprof index", func(t *testing.T) {
+		w := get("/debug/pprof/")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "goroutine")
+	})
+
+	t.Run("named profile", func(t *testing.T) {
+		w := get("/debug/pprof/goroutine?debug=1")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "goroutine profile")
+	})
+
+	t.Run("expvar", func(t *testing.T) {
+		w := get("/debug/vars")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var vars map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &vars))
+		assert.Contains(t, vars, "memstats")
+	})
+
+	t.Run("goroutine dump", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/debug/goroutines/dump", nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var response struct {
+			Data GoroutineDump `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.True(t, strings.HasPrefix(response.Data.File, dumpDir))
+		assert.Greater(t, response.Data.Goroutines, 0)
+
+		content, err := os.ReadFile(response.Data.File)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "goroutine 1 [")
+		assert.Equal(t, int64(len(content)), response.Data.SizeBytes)
+	})
+}
+
+func TestDebugHandler_BodyLogging(t *testing.T) {
+	router, _ := setupDebugRouter(t)
+	send := func(method, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/debug/body-logging", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	config := func(w *httptest.ResponseRecorder) logging.BodyLogConfig {
+		var response struct {
+			Data logging.BodyLogConfig `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	w := send("GET", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, config(w).Enabled)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "missing enabled", body: `{"routes":["/api/uploads"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "enabled without routes", body: `{"enabled":true}`, expectedStatus: http.StatusBadRequest},
+		{name: "route without leading slash", body: `{"enabled":true,"routes":["api"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "cap too large", body: `{"enabled":true,"routes":["/api"],"max_body_bytes":2000000}`, expectedStatus: http.StatusBadRequest},
+		{name: "enable", body: `{"enabled":true,"routes":["/api/uploads"],"max_body_bytes":1024}`, expectedStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedStatus, send("PUT", tt.body).Code)
+		})
+	}
+
+	enabled := config(send("GET", ""))
+	assert.True(t, enabled.Enabled)
+	assert.Equal(t, []string{"/api/uploads"}, enabled.Routes)
+	assert.Equal(t, 1024, enabled.MaxBodyBytes)
+
+	// Switching off keeps the routes for the next time
+	w = send("PUT", `{"enabled":false}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, config(w).Enabled)
+	assert.Equal(t, []string{"/api/uploads"}, config(w).Routes)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/monitoring"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultErrorWindow is the window errors are aggregated over when none is asked for, and
+// defaultErrorGroups how many groups are listed
+const (
+	defaultErrorWindow = "24h"
+	defaultErrorGroups = 50
+)
+
+// ErrorAnalytics is middleware noting the route of each request answered with an error, so API
+// errors can be aggregated by route as well as by code and component
+func ErrorAnalytics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		apiErr := errors.SentError(c)
+		if apiErr == nil || c.FullPath() == "" {
+			// Unmatched paths would fill the aggregate with whatever clients probe for
+			return
+		}
+		monitoring.RecordRequestError(c.Request.Context(), apiErr, c.Request.Method+" "+c.FullPath())
+	}
+}
+
+// GetErrorAggregate handles GET /api/v2/admin/errors/aggregate
+func GetErrorAggregate(c *gin.Context) {
+	var query ErrorAggregateQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	if query.Window == "" {
+		query.Window = defaultErrorWindow
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultErrorGroups
+	}
+
+	aggregate := monitoring.AggregateErrors(monitoring.ErrorAggregateWindows[query.Window], time.Now(),
+		monitoring.ErrorAggregateFilter{
+			Code:      query.Code,
+			Component: query.Component,
+			Route:     query.Route,
+			Limit:     query.Limit,
+		})
+	if aggregate == nil {
+		errors.SendError(c, errors.NewAPIError(errors.ErrServiceUnavailable, "Error tracking not initialized"))
+		return
+	}
+	aggregate.Window = query.Window
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": aggregate,
+	})
+}
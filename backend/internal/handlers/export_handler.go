@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler handles incident data exports, which run on the job queue
+type ExportHandler struct {
+	jobQueue      *services.JobQueue
+	exportService *services.IncidentExportService
+	logger        *logging.Logger
+}
+
+// NewExportHandler creates a new export handler. The job queue must have exportService set.
+func NewExportHandler(jobQueue *services.JobQueue, exportService *services.IncidentExportService) *ExportHandler {
+	return &ExportHandler{
+		jobQueue:      jobQueue,
+		exportService: exportService,
+		logger:        logging.GetGlobalLogger().WithComponent("export_handler"),
+	}
+}
+
+// exportLinks returns the status and download URLs of an export job, in the API version serving
+// the request
+func exportLinks(c *gin.Context, jobID string) gin.H {
+	prefix := apiPathPrefix(c)
+	return gin.H{
+		"status_url":   fmt.Sprintf("%s/exports/%s", prefix, jobID),
+		"download_url": fmt.Sprintf("%s/exports/%s/download", prefix, jobID),
+	}
+}
+
+// RequestIncidentExport handles GET /api/incidents/export
+func (h *ExportHandler) RequestIncidentExport(c *gin.Context) {
+	var query IncidentExportQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+
+	job, err := h.jobQueue.SubmitJobContext(c.Request.Context(), services.JobTypeExportIncidents, "", map[string]interface{}{
+		"format":  query.Format,
+		"filters": filters,
+		"scope":   services.DataScopeFromContext(c.Request.Context()),
+	})
+	if err != nil {
+		apiErr := errors.NewAPIError(errors.ErrServiceUnavailable, err.Error()).
+			WithUserMessage("The export could not be queued, please try again shortly")
+		monitoring.TrackError(c.Request.Context(), apiErr, "export_handler", "request_incident_export")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Incident export queued",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"job_id": job.ID,
+			"format": query.Format,
+		}))
+
+	response := exportLinks(c, job.ID)
+	response["job_id"] = job.ID
+	response["status"] = services.JobStatusPending
+	response["filters"] = filters
+	c.JSON(http.StatusAccepted, response)
+}
+
+// GetExport handles GET /api/exports/:id
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	job, ok := h.findExportJob(c)
+	if !ok {
+		return
+	}
+
+	response := gin.H{"data": job}
+	if job.Status == services.JobStatusCompleted {
+		response["download_url"] = exportLinks(c, job.ID)["download_url"]
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadExport handles GET /api/exports/:id/download
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	job, ok := h.findExportJob(c)
+	if !ok {
+		return
+	}
+	if job.Status != services.JobStatusCompleted {
+		errors.SendError(c, errors.NewAPIError(errors.ErrInvalidStatus,
+			fmt.Sprintf("Export is not ready: job is %s", job.Status)))
+		return
+	}
+
+	export, _ := job.Result.(*services.IncidentExport)
+	path, err := h.exportService.FilePath(export)
+	if err != nil {
+		errors.SendError(c, errors.NotFound("Export file"))
+		return
+	}
+
+	filename := fmt.Sprintf("incidents-%s.%s", export.CreatedAt.Format("20060102-150405"), export.Format)
+	c.FileAttachment(path, filename)
+}
+
+// findExportJob looks up the export job named in the path, sending a 404 when there is none
+func (h *ExportHandler) findExportJob(c *gin.Context) (*services.Job, bool) {
+	var params ExportParams
+	if !bindURI(c, &params) {
+		return nil, false
+	}
+
+	job, err := h.jobQueue.JobSnapshot(params.ID)
+	if err != nil || job.Type != services.JobTypeExportIncidents {
+		errors.SendError(c, errors.NotFound("Export"))
+		return nil, false
+	}
+	return job, true
+}
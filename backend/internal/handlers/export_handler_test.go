@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportHandler_IncidentExport(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 3)
+
+	exportService, err := services.NewIncidentExportService(db, t.TempDir())
+	require.NoError(t, err)
+	jobQueue := services.NewJobQueue(services.JobQueueConfig{Workers: 1}, services.NewProcessingService(db, nil))
+	defer jobQueue.Shutdown()
+	jobQueue.SetExportService(exportService)
+	handler := NewExportHandler(jobQueue, exportService)
+
+	router := gin.New()
+	router.GET("/api/incidents/export", handler.RequestIncidentExport)
+	router.GET("/api/exports/:id", handler.GetExport)
+	router.GET("/api/exports/:id/download", handler.DownloadExport)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	t.Run("invalid requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("/api/incidents/export").Code)
+		assert.Equal(t, http.StatusBadRequest, get("/api/incidents/export?format=csv").Code)
+		assert.Equal(t, http.StatusBadRequest, get("/api/incidents/export?format=parquet&priorities=P9").Code)
+		assert.Equal(t, http.StatusNotFound, get("/api/exports/job_missing").Code)
+		assert.Equal(t, http.StatusNotFound, get("/api/exports/job_missing/download").Code)
+	})
+
+	t.Run("export and download", func(t *testing.T) {
+		w := get("/api/incidents/export?format=parquet&priorities=P3")
+		require.Equal(t, http.StatusAccepted, w.Code)
+
+		var queued struct {
+			JobID       string `json:"job_id"`
+			StatusURL   string `json:"status_url"`
+			DownloadURL string `json:"download_url"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &queued))
+		require.NotEmpty(t, queued.JobID)
+
+		var status struct {
+			Data struct {
+				Status string                  `json:"status"`
+				Result services.IncidentExport `json:"result"`
+			} `json:"data"`
+			DownloadURL string `json:"download_url"`
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for status.Data.Status != string(services.JobStatusCompleted) && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+			w = get(queued.StatusURL)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+		}
+		require.Equal(t, string(services.JobStatusCompleted), status.Data.Status)
+		assert.Equal(t, 3, status.Data.Result.Rows)
+		assert.Equal(t, queued.DownloadURL, status.DownloadURL)
+
+		w = get(queued.DownloadURL)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), ".parquet")
+		assert.Equal(t, "PAR1", w.Body.String()[:4])
+	})
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantContext is middleware putting the X-Tenant-ID header into the request context, so
+// feature flag checks further down see the caller's tenant overrides
+func TenantContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tenant := c.GetHeader("X-Tenant-ID"); tenant != "" {
+			c.Request = c.Request.WithContext(services.WithTenant(c.Request.Context(), tenant))
+		}
+		c.Next()
+	}
+}
+
+// RequireFeature is middleware rejecting requests with 403 FORBIDDEN while the flag is off
+// for the caller's tenant
+func RequireFeature(flags *services.FeatureFlagService, key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !flags.Enabled(c.Request.Context(), key) {
+			errors.AbortWithError(c, errors.NewAPIError(errors.ErrForbidden,
+				fmt.Sprintf("Feature %s is disabled", key)))
+			return
+		}
+		c.Next()
+	}
+}
+
+// FeatureFlagHandler lets admins switch feature flags, globally and per tenant
+type FeatureFlagHandler struct {
+	flags  *services.FeatureFlagService
+	logger *logging.Logger
+}
+
+// NewFeatureFlagHandler creates a new feature flag handler
+func NewFeatureFlagHandler(flags *services.FeatureFlagService) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		flags:  flags,
+		logger: logging.GetGlobalLogger().WithComponent("feature_flag_handler"),
+	}
+}
+
+// ListFlags handles GET /api/admin/flags
+func (h *FeatureFlagHandler) ListFlags(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.flags.List(c.Request.Context()),
+	})
+}
+
+// UpdateFlag handles PUT /api/admin/flags/:key
+func (h *FeatureFlagHandler) UpdateFlag(c *gin.Context) {
+	var params FeatureFlagParams
+	if !bindURI(c, &params) {
+		return
+	}
+	var req FeatureFlagRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	actor := requestUser(c)
+	if err := h.flags.SetEnabled(c.Request.Context(), params.Key, *req.Enabled, actor); err != nil {
+		h.sendError(c, err, "update_flag")
+		return
+	}
+	h.logChange(c, params.Key, "", *req.Enabled, actor)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.flag(c, params.Key),
+	})
+}
+
+// SetOverride handles PUT /api/admin/flags/:key/tenants/:tenant
+func (h *FeatureFlagHandler) SetOverride(c *gin.Context) {
+	var params FlagOverrideParams
+	if !bindURI(c, &params) {
+		return
+	}
+	var req FeatureFlagRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	actor := requestUser(c)
+	if err := h.flags.SetOverride(c.Request.Context(), params.Key, params.Tenant, *req.Enabled, actor); err != nil {
+		h.sendError(c, err, "set_flag_override")
+		return
+	}
+	h.logChange(c, params.Key, params.Tenant, *req.Enabled, actor)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.flag(c, params.Key),
+	})
+}
+
+// DeleteOverride handles DELETE /api/admin/flags/:key/tenants/:tenant
+func (h *FeatureFlagHandler) DeleteOverride(c *gin.Context) {
+	var params FlagOverrideParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.flags.DeleteOverride(c.Request.Context(), params.Key, params.Tenant); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Feature flag override"))
+			return
+		}
+		h.sendError(c, err, "delete_flag_override")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.flag(c, params.Key),
+	})
+}
+
+// flag returns the current state of one registered flag
+func (h *FeatureFlagHandler) flag(c *gin.Context, key string) services.FeatureFlag {
+	for _, flag := range h.flags.List(c.Request.Context()) {
+		if flag.Key == key {
+			return flag
+		}
+	}
+	return services.FeatureFlag{Key: key}
+}
+
+// sendError answers a failed flag change, with 404 for flags that are not registered
+func (h *FeatureFlagHandler) sendError(c *gin.Context, err error, operation string) {
+	if stderrors.Is(err, services.ErrUnknownFlag) {
+		errors.SendError(c, errors.NotFound("Feature flag"))
+		return
+	}
+	apiErr := errors.DatabaseError("save feature flag", err)
+	monitoring.TrackError(c.Request.Context(), apiErr, "feature_flag_handler", operation)
+	errors.SendError(c, apiErr)
+}
+
+// logChange records who switched a flag, and for which tenant
+func (h *FeatureFlagHandler) logChange(c *gin.Context, key, tenant string, enabled bool, actor string) {
+	h.logger.WithContext(c.Request.Context()).Warn("Feature flag changed",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"key":        key,
+			"tenant":     tenant,
+			"enabled":    enabled,
+			"changed_by": actor,
+		}))
+}
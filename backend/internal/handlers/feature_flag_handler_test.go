@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureFlagHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	flags, err := services.NewFeatureFlagService(context.Background(), createTestDB(t), time.Hour)
+	require.NoError(t, err)
+	flags.Register(services.FlagDefinition{Key: services.FlagJiraTickets, Default: true})
+	handler := NewFeatureFlagHandler(flags)
+
+	router := gin.New()
+	api := router.Group("/api", TenantContext())
+	api.POST("/tickets", RequireFeature(flags, services.FlagJiraTickets), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	admin := api.Group("/admin", AdminAuth("s3cret"))
+	admin.GET("/flags", handler.ListFlags)
+	admin.PUT("/flags/:key", handler.UpdateFlag)
+	admin.PUT("/flags/:key/tenants/:tenant", handler.SetOverride)
+	admin.DELETE("/flags/:key/tenants/:tenant", handler.DeleteOverride)
+
+	send := func(method, path, body, tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		req.Header.Set("Content-Type", "application/json")
+		if tenant != "" {
+			req.Header.Set("X-Tenant-ID", tenant)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusCreated, send("POST", "/api/tickets", "", "").Code)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "missing enabled", method: "PUT", path: "/api/admin/flags/automation.jira_tickets", body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "unknown flag", method: "PUT", path: "/api/admin/flags/unknown", body: `{"enabled":true}`, expectedStatus: http.StatusNotFound},
+		{name: "disable globally", method: "PUT", path: "/api/admin/flags/automation.jira_tickets", body: `{"enabled":false}`, expectedStatus: http.StatusOK},
+		{name: "enable for tenant", method: "PUT", path: "/api/admin/flags/automation.jira_tickets/tenants/acme", body: `{"enabled":true}`, expectedStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedStatus, send(tt.method, tt.path, tt.body, "").Code)
+		})
+	}
+
+	assert.Equal(t, http.StatusForbidden, send("POST", "/api/tickets", "", "").Code)
+	assert.Equal(t, http.StatusForbidden, send("POST", "/api/tickets", "", "globex").Code)
+	assert.Equal(t, http.StatusCreated, send("POST", "/api/tickets", "", "acme").Code)
+
+	var listed struct {
+		Data []services.FeatureFlag `json:"data"`
+	}
+	w := send("GET", "/api/admin/flags", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Data, 1)
+	assert.False(t, listed.Data[0].Enabled)
+	require.Len(t, listed.Data[0].Overrides, 1)
+	assert.Equal(t, "acme", listed.Data[0].Overrides[0].Tenant)
+
+	assert.Equal(t, http.StatusOK, send("DELETE", "/api/admin/flags/automation.jira_tickets/tenants/acme", "", "").Code)
+	assert.Equal(t, http.StatusNotFound, send("DELETE", "/api/admin/flags/automation.jira_tickets/tenants/acme", "", "").Code)
+	assert.Equal(t, http.StatusForbidden, send("POST", "/api/tickets", "", "acme").Code)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeedbackHandler handles analyzer feedback and accuracy reporting endpoints
+type FeedbackHandler struct {
+	feedbackService *services.FeedbackService
+	logger          *logging.Logger
+}
+
+// NewFeedbackHandler creates a new feedback handler
+func NewFeedbackHandler(db *sql.DB) *FeedbackHandler {
+	return &FeedbackHandler{
+		feedbackService: services.NewFeedbackService(db),
+		logger:          logging.GetGlobalLogger().WithComponent("feedback_handler"),
+	}
+}
+
+// RecordFeedback handles POST /api/incidents/:id/feedback
+func (h *FeedbackHandler) RecordFeedback(c *gin.Context) {
+	var params IncidentParams
+	if !bindURI(c, &params) {
+		return
+	}
+	var req AnalyzerFeedbackRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	feedback, err := h.feedbackService.RecordFeedback(c.Request.Context(), params.ID, req.Analyzer, *req.Correct, req.Expected, req.Comment)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			errors.SendError(c, errors.NotFound("Incident"))
+		case services.ErrNoAnalyzerOutput, services.ErrInvalidFeedback:
+			errors.SendError(c, errors.BadRequest(err.Error()))
+		default:
+			apiErr := errors.DatabaseError("record analyzer feedback", err)
+			monitoring.TrackError(c.Request.Context(), apiErr, "feedback_handler", "record_feedback")
+			errors.SendError(c, apiErr)
+		}
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Analyzer feedback recorded",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"incident_id":      feedback.IncidentID,
+			"analyzer":         feedback.Analyzer,
+			"analyzer_version": feedback.AnalyzerVersion,
+			"correct":          feedback.Correct,
+		}))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": feedback,
+	})
+}
+
+// ListFeedback handles GET /api/incidents/:id/feedback
+func (h *FeedbackHandler) ListFeedback(c *gin.Context) {
+	var params IncidentParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	feedback, err := h.feedbackService.ListFeedback(c.Request.Context(), params.ID)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve analyzer feedback", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "feedback_handler", "list_feedback")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, feedback, nil, gin.H{
+		"data":  feedback,
+		"count": len(feedback),
+	})
+}
+
+// GetAccuracyReport handles GET /api/analytics/feedback/accuracy
+func (h *FeedbackHandler) GetAccuracyReport(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_accuracy_report")
+
+	var query AccuracyQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+
+	report, err := h.feedbackService.GetAccuracyReport(c.Request.Context(), query.Analyzer, filters)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve analyzer accuracy", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "feedback_handler", "get_accuracy_report")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_accuracy_report", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"analyzer": query.Analyzer,
+			"versions": len(report),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	sendList(c, report, filters, gin.H{
+		"data":    report,
+		"filters": filters,
+		"count":   len(report),
+	})
+}
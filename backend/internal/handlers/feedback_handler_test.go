@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedbackHandler_RecordFeedback(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 3)
+	handler := NewFeedbackHandler(db)
+
+	var incidentID string
+	require.NoError(t, db.QueryRow("SELECT id FROM incidents LIMIT 1").Scan(&incidentID))
+
+	tests := []struct {
+		name           string
+		id             string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "correct sentiment",
+			id:             incidentID,
+			body:           `{"analyzer":"sentiment","correct":true}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "wrong process group",
+			id:             incidentID,
+			body:           `{"analyzer":"process_group","correct":false,"expected":"User Support","comment":"password reset"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "not actually automatable",
+			id:             incidentID,
+			body:           `{"analyzer":"automation","correct":false}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "missing verdict",
+			id:             incidentID,
+			body:           `{"analyzer":"sentiment"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown analyzer",
+			id:             incidentID,
+			body:           `{"analyzer":"priority","correct":true}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "contradictory expected value",
+			id:             incidentID,
+			body:           `{"analyzer":"sentiment","correct":false,"expected":"positive"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown incident",
+			id:             "missing",
+			body:           `{"analyzer":"sentiment","correct":true}`,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/incidents/"+tt.id+"/feedback", strings.NewReader(tt.body))
+			c.Params = []gin.Param{{Key: "id", Value: tt.id}}
+			handler.RecordFeedback(c)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	// List feedback for the incident
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/incidents/"+incidentID+"/feedback", nil)
+	c.Params = []gin.Param{{Key: "id", Value: incidentID}}
+	handler.ListFeedback(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(3), response["count"])
+
+	// Accuracy report per analyzer
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/analytics/feedback/accuracy?analyzer=process_group", nil)
+	handler.GetAccuracyReport(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].([]interface{})
+	require.Len(t, data, 1)
+	entry := data[0].(map[string]interface{})
+	assert.Equal(t, "process_group", entry["analyzer"])
+	assert.Equal(t, float64(0), entry["accuracy"])
+
+	// Invalid analyzer filter is rejected
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/analytics/feedback/accuracy?analyzer=priority", nil)
+	handler.GetAccuracyReport(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GoalHandler handles the quarterly KPI goal endpoints
+type GoalHandler struct {
+	goalService      *services.GoalService
+	analyticsService *services.AnalyticsService
+	logger           *logging.Logger
+}
+
+// NewGoalHandler creates a new goal handler
+func NewGoalHandler(db *sql.DB) *GoalHandler {
+	return &GoalHandler{
+		goalService:      services.NewGoalService(db),
+		analyticsService: services.NewAnalyticsService(db),
+		logger:           logging.GetGlobalLogger().WithComponent("goal_handler"),
+	}
+}
+
+// ListGoals handles GET /api/goals
+func (h *GoalHandler) ListGoals(c *gin.Context) {
+	var query GoalQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	goals, err := h.goalService.ListGoals(c.Request.Context(), query.Quarter, query.Team)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve goals", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "goal_handler", "list_goals")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, goals, nil, gin.H{
+		"data":  goals,
+		"count": len(goals),
+	})
+}
+
+// GetGoal handles GET /api/goals/:id
+func (h *GoalHandler) GetGoal(c *gin.Context) {
+	var params GoalParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	goal, err := h.goalService.GetGoal(c.Request.Context(), params.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Goal"))
+			return
+		}
+		apiErr := errors.DatabaseError("retrieve goal", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "goal_handler", "get_goal")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": goal,
+	})
+}
+
+// CreateGoal handles POST /api/goals
+func (h *GoalHandler) CreateGoal(c *gin.Context) {
+	start := time.Now()
+
+	var req GoalRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	goal := req.ToGoal()
+	goal.CreatedBy = requestUser(c)
+
+	created, err := h.goalService.CreateGoal(c.Request.Context(), goal)
+	if err != nil {
+		if stderrors.Is(err, services.ErrInvalidGoal) {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("create goal", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "goal_handler", "create_goal")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).LogDuration("create_goal", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"goal_id": created.ID,
+			"metric":  created.Metric,
+			"quarter": created.Quarter,
+			"team":    created.Team,
+		}))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": created,
+	})
+}
+
+// DeleteGoal handles DELETE /api/goals/:id
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	var params GoalParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.goalService.DeleteGoal(c.Request.Context(), params.ID); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Goal"))
+			return
+		}
+		apiErr := errors.DatabaseError("delete goal", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "goal_handler", "delete_goal")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Goal deleted",
+	})
+}
+
+// ListGoalProgress handles GET /api/analytics/goals
+func (h *GoalHandler) ListGoalProgress(c *gin.Context) {
+	start := time.Now()
+
+	var query GoalProgressQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	asOf := goalAsOf(query.AsOf)
+
+	goals, err := h.goalService.ListGoals(c.Request.Context(), query.Quarter, query.Team)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve goals", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "goal_handler", "list_goal_progress")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	progress := make([]services.GoalProgress, 0, len(goals))
+	for _, goal := range goals {
+		goalProgress, err := h.analyticsService.GetGoalProgress(c.Request.Context(), goal, asOf)
+		if err != nil {
+			apiErr := errors.DatabaseError("compute goal progress", err)
+			monitoring.TrackError(c.Request.Context(), apiErr, "goal_handler", "list_goal_progress")
+			errors.SendError(c, apiErr)
+			return
+		}
+		progress = append(progress, *goalProgress)
+	}
+
+	h.logger.WithContext(c.Request.Context()).LogDuration("goal_progress", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"quarter": query.Quarter,
+			"team":    query.Team,
+			"goals":   len(progress),
+		}))
+	monitoring.UpdatePerformance(time.Since(start))
+
+	sendList(c, progress, nil, gin.H{
+		"data":  progress,
+		"count": len(progress),
+	})
+}
+
+// GetGoalProgress handles GET /api/analytics/goals/:id
+func (h *GoalHandler) GetGoalProgress(c *gin.Context) {
+	var params GoalParams
+	if !bindURI(c, &params) {
+		return
+	}
+	var query GoalProgressQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	goal, err := h.goalService.GetGoal(c.Request.Context(), params.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Goal"))
+			return
+		}
+		apiErr := errors.DatabaseError("retrieve goal", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "goal_handler", "get_goal_progress")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	progress, err := h.analyticsService.GetGoalProgress(c.Request.Context(), *goal, goalAsOf(query.AsOf))
+	if err != nil {
+		apiErr := errors.DatabaseError("compute goal progress", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "goal_handler", "get_goal_progress")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": progress,
+	})
+}
+
+// goalAsOf returns the as_of date of a progress query, defaulting to today
+func goalAsOf(value string) time.Time {
+	if asOf := parseDateParam(value); asOf != nil {
+		return *asOf
+	}
+	return time.Now().UTC()
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoalHandler_Goals(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewGoalHandler(db)
+
+	// Create goal
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/goals",
+		strings.NewReader(`{"name":"Resolution rate","team":"Web","metric":"resolution_rate","comparison":"at_least","target":95,"quarter":"2024-Q1"}`))
+	handler.CreateGoal(c)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created struct {
+		Data struct {
+			ID         string `json:"id"`
+			TargetType string `json:"target_type"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "absolute", created.Data.TargetType)
+
+	// Unknown metric is rejected
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/goals",
+		strings.NewReader(`{"name":"Uptime","metric":"uptime","comparison":"at_least","target":99,"quarter":"2024-Q1"}`))
+	handler.CreateGoal(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Malformed quarter is rejected
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/goals",
+		strings.NewReader(`{"name":"P1s","metric":"p1_count","comparison":"at_most","target":5,"quarter":"2024-Q9"}`))
+	handler.CreateGoal(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Progress of every goal in the quarter
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/analytics/goals?quarter=2024-Q1&as_of=2024-02-01", nil)
+	handler.ListGoalProgress(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var progress map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &progress))
+	assert.Equal(t, float64(1), progress["count"])
+	assert.Equal(t, "no_data", progress["data"].([]interface{})[0].(map[string]interface{})["status"])
+
+	// Progress of an unknown goal
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/analytics/goals/unknown", nil)
+	c.Params = []gin.Param{{Key: "id", Value: "unknown"}}
+	handler.GetGoalProgress(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Delete goal
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/goals/"+created.Data.ID, nil)
+	c.Params = []gin.Param{{Key: "id", Value: created.Data.ID}}
+	handler.DeleteGoal(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
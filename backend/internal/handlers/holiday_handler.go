@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HolidayHandler handles the regional holiday calendar endpoints
+type HolidayHandler struct {
+	holidayService *services.HolidayService
+	logger         *logging.Logger
+}
+
+// NewHolidayHandler creates a new holiday handler
+func NewHolidayHandler(db *sql.DB) *HolidayHandler {
+	return &HolidayHandler{
+		holidayService: services.NewHolidayService(db),
+		logger:         logging.GetGlobalLogger().WithComponent("holiday_handler"),
+	}
+}
+
+// ListHolidays handles GET /api/holidays
+func (h *HolidayHandler) ListHolidays(c *gin.Context) {
+	var query HolidayQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	holidays, err := h.holidayService.ListHolidays(c.Request.Context(), query.Region, query.Year)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve holidays", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "holiday_handler", "list_holidays")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, holidays, nil, gin.H{
+		"data":  holidays,
+		"count": len(holidays),
+	})
+}
+
+// SaveHolidays handles POST /api/holidays
+func (h *HolidayHandler) SaveHolidays(c *gin.Context) {
+	var req HolidayRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	markers := make([]services.HolidayMarker, len(req.Holidays))
+	for i, holiday := range req.Holidays {
+		markers[i] = services.HolidayMarker{Date: holiday.Date, Name: holiday.Name}
+	}
+
+	holidays, err := h.holidayService.SaveHolidays(c.Request.Context(), req.Region, markers)
+	if err != nil {
+		apiErr := errors.DatabaseError("save holidays", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "holiday_handler", "save_holidays")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Holidays saved",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"region": services.NormalizeRegion(req.Region),
+			"count":  len(holidays),
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  holidays,
+		"count": len(holidays),
+	})
+}
+
+// DeleteHoliday handles DELETE /api/holidays/:region/:date
+func (h *HolidayHandler) DeleteHoliday(c *gin.Context) {
+	var params HolidayParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.holidayService.DeleteHoliday(c.Request.Context(), params.Region, *parseDateParam(params.Date)); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Holiday"))
+			return
+		}
+		apiErr := errors.DatabaseError("delete holiday", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "holiday_handler", "delete_holiday")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Holiday deleted",
+	})
+}
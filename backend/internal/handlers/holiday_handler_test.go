@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHolidayHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewHolidayHandler(db)
+	analyticsHandler := NewAnalyticsHandler(db)
+
+	router := gin.New()
+	router.GET("/api/holidays", handler.ListHolidays)
+	router.POST("/api/holidays", handler.SaveHolidays)
+	router.DELETE("/api/holidays/:region/:date", handler.DeleteHoliday)
+	router.GET("/api/analytics/trends", analyticsHandler.GetTrendAnalysis)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "no holidays", method: "POST", path: "/api/holidays", body: `{"region":"DE","holidays":[]}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid date", method: "POST", path: "/api/holidays", body: `{"region":"DE","holidays":[{"date":"25.12.2024","name":"Christmas"}]}`, expectedStatus: http.StatusBadRequest},
+		{name: "valid calendar", method: "POST", path: "/api/holidays", body: `{"region":"de","holidays":[{"date":"2024-12-25","name":"Christmas"},{"date":"2024-12-26","name":"Boxing Day"}]}`, expectedStatus: http.StatusOK},
+		{name: "adjust without region", method: "GET", path: "/api/analytics/trends?period=weekly&holidays=adjust", expectedStatus: http.StatusBadRequest},
+		{name: "adjust with region", method: "GET", path: "/api/analytics/trends?period=weekly&holidays=adjust&holiday_region=DE", expectedStatus: http.StatusOK},
+		{name: "delete invalid date", method: "DELETE", path: "/api/holidays/DE/christmas", expectedStatus: http.StatusBadRequest},
+		{name: "delete holiday", method: "DELETE", path: "/api/holidays/de/2024-12-26", expectedStatus: http.StatusOK},
+		{name: "delete missing holiday", method: "DELETE", path: "/api/holidays/DE/2024-12-26", expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedStatus, send(tt.method, tt.path, tt.body).Code)
+		})
+	}
+
+	var listed struct {
+		Data  []services.Holiday `json:"data"`
+		Count int                `json:"count"`
+	}
+	w := send("GET", "/api/holidays?region=DE&year=2024", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Equal(t, 1, listed.Count)
+	assert.Equal(t, "DE", listed.Data[0].Region)
+	assert.Equal(t, "2024-12-25", listed.Data[0].Date)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pushed incident batches live on the upload handler: each batch becomes an upload that is
+// processed in the background the same way as a file upload.
+
+// PushIncidents handles POST /api/incidents/batch. The body is a JSON array of incidents keyed
+// by incident field. The batch is stored as an upload during the request, skipping incidents
+// already imported; validation, deduplication and analysis then run in the background, and
+// their outcome is read from the upload's status.
+func (h *UploadHandler) PushIncidents(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("push_incidents")
+
+	var query IncidentBatchQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	var incidents []services.PushedIncident
+	if !bindJSON(c, &incidents) {
+		return
+	}
+	options := query.ToOptions()
+	if !h.checkProcessingOptions(c, options, "push_incidents") {
+		return
+	}
+
+	result, err := h.pushService.PushIncidents(c.Request.Context(), incidents, query.Source)
+	if err != nil {
+		if stderrors.Is(err, services.ErrInvalidPush) {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("store incident batch", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "upload_handler", "push_incidents")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	if result.Upload == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "All incidents were already imported",
+			"accepted": 0,
+			"skipped":  result.Skipped,
+		})
+		return
+	}
+
+	uploadID := result.Upload.ID
+	ctx, cancel := h.processingContext(c)
+	go func() {
+		defer cancel()
+		_, err := h.processingService.ProcessUploadWithOptions(ctx, uploadID, options)
+		if err != nil {
+			logger.Error("Processing failed for pushed incidents", err,
+				logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+					"source":    query.Source,
+					"upload_id": uploadID,
+				}))
+
+			apiErr := errors.ProcessingFailed(err.Error())
+			monitoring.TrackError(ctx, apiErr, "processing_service", "process_upload")
+		}
+	}()
+
+	logger.LogDuration("push_incidents", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"source":    query.Source,
+			"upload_id": uploadID,
+			"accepted":  result.Accepted,
+			"skipped":   len(result.Skipped),
+			"pushed_by": requestUser(c),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":            "Processing started",
+		"upload_id":          uploadID,
+		"accepted":           result.Accepted,
+		"skipped":            result.Skipped,
+		"processing_options": options,
+	})
+}
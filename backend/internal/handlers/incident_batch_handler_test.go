@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/services"
+	"incident-management-system/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadHandler_PushIncidents(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	processed := make(chan models.ProcessingOptions, 1)
+	mockService := &MockProcessingService{
+		ProcessUploadWithOptionsFunc: func(ctx context.Context, uploadID string, options models.ProcessingOptions) (*services.ProcessingProgress, error) {
+			processed <- options
+			return &services.ProcessingProgress{UploadID: uploadID}, nil
+		},
+	}
+	handler := NewUploadHandler(db, storage.NewFileStore(t.TempDir()), mockService)
+
+	router := gin.New()
+	router.POST("/api/incidents/batch", handler.PushIncidents)
+
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		expectedStatus int
+	}{
+		{name: "not an array", body: `{"incident_id":"INC1"}`, expectedStatus: http.StatusBadRequest},
+		{name: "empty batch", body: `[]`, expectedStatus: http.StatusBadRequest},
+		{name: "unknown field", body: `[{"incident_id":"INC1","colour":"red"}]`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid dedup strategy", query: "?dedup_strategy=newest", body: `[{"incident_id":"INC1"}]`, expectedStatus: http.StatusBadRequest},
+		{name: "unknown rule set", query: "?rule_set=strict", body: `[{"incident_id":"INC1"}]`, expectedStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/incidents/batch"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	req := httptest.NewRequest("POST", "/api/incidents/batch?source=monitor&dedup_strategy=last&run_sentiment=false",
+		strings.NewReader(`[{"incident_id":"INC1","report_date":"2024-03-01","priority":"P2"}]`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	var response struct {
+		UploadID string `json:"upload_id"`
+		Accepted int    `json:"accepted"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotEmpty(t, response.UploadID)
+	assert.Equal(t, 1, response.Accepted)
+
+	select {
+	case options := <-processed:
+		assert.Equal(t, models.DedupStrategyLast, options.DedupStrategy)
+		assert.False(t, options.RunSentiment)
+		assert.True(t, options.RunAutomation)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the pushed batch to be processed")
+	}
+}
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IncidentHandler handles endpoints for individual incident records
+type IncidentHandler struct {
+	incidentService   *services.IncidentService
+	eventService      *services.IncidentEventService
+	mergeService      *services.IncidentMergeService
+	processingService *services.ProcessingService
+	logger            *logging.Logger
+}
+
+// NewIncidentHandler creates a new incident handler
+func NewIncidentHandler(db *sql.DB) *IncidentHandler {
+	return &IncidentHandler{
+		incidentService:   services.NewIncidentService(db),
+		eventService:      services.NewIncidentEventService(db),
+		mergeService:      services.NewIncidentMergeService(db),
+		processingService: services.NewProcessingService(db, nil),
+		logger:            logging.GetGlobalLogger().WithComponent("incident_handler"),
+	}
+}
+
+// SetProcessingService makes manually entered incidents share the upload pipeline's processing
+// service, so they are analyzed with the same analyzers and rules
+func (h *IncidentHandler) SetProcessingService(processingService *services.ProcessingService) {
+	h.processingService = processingService
+}
+
+// CreateIncident handles POST /api/incidents. The incident is validated, analyzed within the
+// requested time budget and stored during the request, in the day's manual entry upload.
+func (h *IncidentHandler) CreateIncident(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("create_incident")
+
+	var query IncidentCreateQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	var req IncidentCreateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.processingService.CreateIncident(c.Request.Context(), req.ToIncident(), query.ToOptions())
+	if err != nil {
+		var violations models.ValidationErrors
+		switch {
+		case stderrors.As(err, &violations):
+			errors.SendError(c, incidentValidationError(violations))
+		case stderrors.Is(err, services.ErrDuplicateIncident):
+			errors.SendError(c, errors.NewAPIError(errors.ErrDuplicateIncidentID, err.Error()))
+		case stderrors.Is(err, services.ErrRuleSetNotFound):
+			errors.SendError(c, errors.BadRequest(err.Error()))
+		default:
+			apiErr := errors.DatabaseError("create incident", err)
+			monitoring.TrackError(c.Request.Context(), apiErr, "incident_handler", "create_incident")
+			errors.SendError(c, apiErr)
+		}
+		return
+	}
+
+	logger.LogDuration("create_incident", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"incident_id": result.Incident.IncidentID,
+			"upload_id":   result.UploadID,
+			"analysis":    result.Analysis,
+			"created_by":  requestUser(c),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": result,
+	})
+}
+
+// incidentValidationError converts the incident's field violations to an API error
+func incidentValidationError(violations models.ValidationErrors) *errors.APIError {
+	validations := make([]errors.ValidationError, 0, len(violations))
+	for _, violation := range violations {
+		validations = append(validations, errors.ValidationError{
+			Field:   violation.Field,
+			Value:   violation.Value,
+			Message: violation.Message,
+		})
+	}
+	return errors.WrapValidationErrors(validations)
+}
+
+// GetTimeline handles GET /api/incidents/:id/timeline
+func (h *IncidentHandler) GetTimeline(c *gin.Context) {
+	var params IncidentParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	events, err := h.eventService.GetTimeline(c.Request.Context(), params.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Incident"))
+			return
+		}
+		apiErr := errors.DatabaseError("retrieve incident timeline", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "incident_handler", "get_timeline")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, events, nil, gin.H{
+		"data":  events,
+		"count": len(events),
+	})
+}
+
+// GetRelatedIncidents handles GET /api/incidents/:id/related
+func (h *IncidentHandler) GetRelatedIncidents(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_related_incidents")
+
+	var params IncidentParams
+	if !bindURI(c, &params) {
+		return
+	}
+	var query RelatedIncidentsQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	related, err := h.incidentService.GetRelatedIncidents(c.Request.Context(), params.ID, query.ToOptions())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Incident"))
+			return
+		}
+		apiErr := errors.DatabaseError("retrieve related incidents", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "incident_handler", "get_related_incidents")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_related_incidents", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"incident_id": params.ID,
+			"related":     len(related),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	sendList(c, related, nil, gin.H{
+		"data":  related,
+		"count": len(related),
+	})
+}
+
+// GetSample handles GET /api/incidents/sample. It returns a stratified random sample of the
+// incidents matching the filters for QA review; passing back the returned seed repeats it.
+func (h *IncidentHandler) GetSample(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_incident_sample")
+
+	var query IncidentSampleQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+
+	sample, err := h.incidentService.SampleIncidents(c.Request.Context(), filters, query.ToOptions())
+	if err != nil {
+		apiErr := errors.DatabaseError("sample incidents", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "incident_handler", "get_incident_sample")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_incident_sample", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"seed":        sample.Seed,
+			"stratify_by": sample.StratifyBy,
+			"population":  sample.Population,
+			"size":        sample.Size,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    sample,
+		"filters": filters,
+	})
+}
+
+// MergeIncidents handles POST /api/v2/incident-merges. The duplicates are linked to the primary
+// incident and no longer counted in analytics, until the merge is undone.
+func (h *IncidentHandler) MergeIncidents(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("merge_incidents")
+
+	var req IncidentMergeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	merge, err := h.mergeService.Merge(c.Request.Context(), req.PrimaryID, req.DuplicateIDs, req.Reason, requestUser(c))
+	if err != nil {
+		h.sendMergeError(c, err, "merge_incidents")
+		return
+	}
+
+	logger.LogDuration("merge_incidents", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"merge_id":   merge.ID,
+			"primary_id": merge.PrimaryID,
+			"duplicates": len(merge.DuplicateIDs),
+			"merged_by":  merge.MergedBy,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": merge,
+	})
+}
+
+// GetMerge handles GET /api/v2/incident-merges/:id
+func (h *IncidentHandler) GetMerge(c *gin.Context) {
+	var params IncidentMergeParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	merge, err := h.mergeService.GetMerge(c.Request.Context(), params.ID)
+	if err != nil {
+		h.sendMergeError(c, err, "get_merge")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": merge,
+	})
+}
+
+// UndoMerge handles POST /api/v2/incident-merges/:id/undo. The duplicates are split from the
+// primary incident and counted again; the merge is kept, marked undone.
+func (h *IncidentHandler) UndoMerge(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("undo_merge")
+
+	var params IncidentMergeParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	merge, err := h.mergeService.Undo(c.Request.Context(), params.ID, requestUser(c))
+	if err != nil {
+		h.sendMergeError(c, err, "undo_merge")
+		return
+	}
+
+	logger.LogDuration("undo_merge", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"merge_id":  merge.ID,
+			"undone_by": merge.UndoneBy,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": merge,
+	})
+}
+
+// ListIncidentMerges handles GET /api/v2/incidents/:id/merges, the merges an incident took part
+// in as primary or duplicate
+func (h *IncidentHandler) ListIncidentMerges(c *gin.Context) {
+	var params IncidentParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	merges, err := h.mergeService.ListMerges(c.Request.Context(), params.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Incident"))
+			return
+		}
+		h.sendMergeError(c, err, "list_incident_merges")
+		return
+	}
+
+	sendList(c, merges, nil, gin.H{
+		"data":  merges,
+		"count": len(merges),
+	})
+}
+
+// sendMergeError answers a failed merge operation, with 400 for merges that are not allowed and
+// 404 for unknown merges and incidents
+func (h *IncidentHandler) sendMergeError(c *gin.Context, err error, operation string) {
+	switch {
+	case stderrors.Is(err, services.ErrInvalidMerge):
+		errors.SendError(c, errors.BadRequest(err.Error()))
+	case err == sql.ErrNoRows:
+		errors.SendError(c, errors.NotFound("Incident merge"))
+	case stderrors.Is(err, sql.ErrNoRows):
+		errors.SendError(c, errors.NotFound("Incident").WithDetails(err.Error()))
+	default:
+		apiErr := errors.DatabaseError("manage incident merge", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "incident_handler", operation)
+		errors.SendError(c, apiErr)
+	}
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncidentHandler_GetTimeline(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 1)
+	handler := NewIncidentHandler(db)
+
+	var incidentID string
+	require.NoError(t, db.QueryRow("SELECT id FROM incidents LIMIT 1").Scan(&incidentID))
+	_, err := services.NewFeedbackService(db).RecordFeedback(context.Background(), incidentID, services.FeedbackAnalyzerSentiment, true, "", "")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		id             string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "incident with feedback", id: incidentID, expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "unknown incident", id: "missing", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/incidents/"+tt.id+"/timeline", nil)
+			c.Params = []gin.Param{{Key: "id", Value: tt.id}}
+
+			handler.GetTimeline(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Data  []services.IncidentEvent `json:"data"`
+				Count int                      `json:"count"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCount, response.Count)
+			assert.Equal(t, services.EventFeedback, response.Data[0].EventType)
+			assert.Equal(t, true, response.Data[0].Details["correct"])
+		})
+	}
+}
+
+func TestIncidentHandler_GetRelatedIncidents(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 3)
+	handler := NewIncidentHandler(db)
+
+	var incidentID string
+	require.NoError(t, db.QueryRow("SELECT id FROM incidents LIMIT 1").Scan(&incidentID))
+
+	tests := []struct {
+		name           string
+		id             string
+		query          string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "default options", id: incidentID, expectedStatus: http.StatusOK, expectedCount: 2},
+		{name: "limited", id: incidentID, query: "?limit=1", expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "limit too large", id: incidentID, query: "?limit=500", expectedStatus: http.StatusBadRequest},
+		{name: "score out of range", id: incidentID, query: "?min_score=2", expectedStatus: http.StatusBadRequest},
+		{name: "unknown incident", id: "missing", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/incidents/"+tt.id+"/related"+tt.query, nil)
+			c.Params = []gin.Param{{Key: "id", Value: tt.id}}
+
+			handler.GetRelatedIncidents(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Data  []services.RelatedIncident `json:"data"`
+				Count int                        `json:"count"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCount, response.Count)
+			for _, related := range response.Data {
+				assert.NotEqual(t, incidentID, related.ID)
+				assert.Contains(t, related.Reasons, services.RelatedByCluster)
+			}
+		})
+	}
+}
+
+func TestIncidentHandler_GetSample(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+	handler := NewIncidentHandler(db)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedSize   int
+	}{
+		{name: "default size", query: "", expectedStatus: http.StatusOK, expectedSize: 10},
+		{name: "seeded by application", query: "?size=3&seed=42&stratify_by=application", expectedStatus: http.StatusOK, expectedSize: 3},
+		{name: "size too large", query: "?size=501", expectedStatus: http.StatusBadRequest},
+		{name: "unknown stratum", query: "?stratify_by=status", expectedStatus: http.StatusBadRequest},
+		{name: "negative seed", query: "?seed=-1", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/incidents/sample"+tt.query, nil)
+
+			handler.GetSample(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Data services.IncidentSample `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, 10, response.Data.Population)
+			assert.Equal(t, tt.expectedSize, response.Data.Size)
+			assert.Len(t, response.Data.Incidents, tt.expectedSize)
+		})
+	}
+}
+
+func TestIncidentHandler_CreateIncident(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	handler := NewIncidentHandler(db)
+
+	valid := `{"incident_id":"INC900","brief_description":"Checkout page fails to load","application_name":"Portal","resolution_group":"Web Team","priority":"P2","status":"Open"}`
+
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		expectedStatus int
+	}{
+		{name: "open incident", body: valid, expectedStatus: http.StatusCreated},
+		{name: "duplicate incident", body: valid, expectedStatus: http.StatusBadRequest},
+		{name: "missing fields", body: `{"incident_id":"INC901","priority":"P2"}`, expectedStatus: http.StatusBadRequest},
+		{name: "resolved without resolver", body: `{"incident_id":"INC902","brief_description":"Slow page","application_name":"Portal","resolution_group":"Web Team","priority":"P3","resolve_date":"2024-01-15T10:00:00Z"}`, expectedStatus: http.StatusBadRequest},
+		{name: "unknown rule set", query: "?rule_set=missing", body: `{"incident_id":"INC903","brief_description":"Slow page","application_name":"Portal","resolution_group":"Web Team","priority":"P3"}`, expectedStatus: http.StatusBadRequest},
+		{name: "budget out of range", query: "?budget_ms=60000", body: `{"incident_id":"INC904","brief_description":"Slow page","application_name":"Portal","resolution_group":"Web Team","priority":"P3"}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/incidents"+tt.query, strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.CreateIncident(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var response struct {
+				Data services.ManualEntryResult `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "INC900", response.Data.Incident.IncidentID)
+			assert.NotEmpty(t, response.Data.UploadID)
+			assert.Equal(t, services.ManualAnalysisCompleted, response.Data.Analysis)
+		})
+	}
+}
+
+func TestIncidentHandler_MergeIncidents(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 3)
+	handler := NewIncidentHandler(db)
+
+	router := gin.New()
+	v2 := router.Group("/api/v2", APIVersion(APIVersion2))
+	v2.GET("/incidents/:id/merges", handler.ListIncidentMerges)
+	v2.POST("/incident-merges", handler.MergeIncidents)
+	v2.GET("/incident-merges/:id", handler.GetMerge)
+	v2.POST("/incident-merges/:id/undo", handler.UndoMerge)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	var ids []string
+	rows, err := db.Query("SELECT id FROM incidents ORDER BY id")
+	require.NoError(t, err)
+	for rows.Next() {
+		var id string
+		require.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	require.NoError(t, rows.Close())
+	require.Len(t, ids, 3)
+
+	body := `{"primary_id":"` + ids[0] + `","duplicate_ids":["` + ids[1] + `","` + ids[2] + `"],"reason":"Same outage"}`
+	w := send("POST", "/api/v2/incident-merges", body)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Data services.IncidentMerge `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, ids[0], created.Data.PrimaryID)
+	assert.Len(t, created.Data.DuplicateIDs, 2)
+
+	t.Run("refused merges", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v2/incident-merges", body).Code)
+		assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v2/incident-merges", `{"primary_id":"`+ids[0]+`","duplicate_ids":[]}`).Code)
+		assert.Equal(t, http.StatusNotFound, send("POST", "/api/v2/incident-merges", `{"primary_id":"missing","duplicate_ids":["`+ids[1]+`"]}`).Code)
+	})
+
+	w = send("GET", "/api/v2/incidents/"+ids[2]+"/merges", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed struct {
+		Data []services.IncidentMerge `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Data, 1)
+	assert.Equal(t, created.Data.ID, listed.Data[0].ID)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/api/v2/incidents/missing/merges", "").Code)
+
+	w = send("POST", "/api/v2/incident-merges/"+created.Data.ID+"/undo", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var undone struct {
+		Data services.IncidentMerge `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &undone))
+	assert.NotNil(t, undone.Data.UndoneAt)
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v2/incident-merges/"+created.Data.ID+"/undo", "").Code)
+
+	assert.Equal(t, http.StatusOK, send("GET", "/api/v2/incident-merges/"+created.Data.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/api/v2/incident-merges/missing", "").Code)
+}
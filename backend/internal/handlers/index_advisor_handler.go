@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/monitoring"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IndexAdvisorHandler handles the slow query and index advisor endpoints
+type IndexAdvisorHandler struct {
+	db         *database.DB
+	migrations *database.MigrationManager
+}
+
+// NewIndexAdvisorHandler creates a new index advisor handler
+func NewIndexAdvisorHandler(db *database.DB) *IndexAdvisorHandler {
+	return &IndexAdvisorHandler{
+		db:         db,
+		migrations: database.NewMigrationManager(db),
+	}
+}
+
+// GetAdvice handles GET /api/v2/admin/indexes. It reports the slow statements recorded since
+// the server started and the indexes they suggest are missing.
+func (h *IndexAdvisorHandler) GetAdvice(c *gin.Context) {
+	suggestions, err := h.db.SuggestIndexes(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("suggest indexes", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "index_advisor_handler", "get_advice")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"threshold_ms": h.db.SlowQueries().Threshold().Milliseconds(),
+			"slow_queries": h.db.SlowQueries().Queries(),
+			"suggestions":  suggestions,
+		},
+	})
+}
+
+// CreateMigration handles POST /api/v2/admin/indexes/migration. It generates a migration
+// creating the chosen suggested indexes, and creates them now when asked to.
+func (h *IndexAdvisorHandler) CreateMigration(c *gin.Context) {
+	var req IndexMigrationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	suggestions, err := h.db.SuggestIndexes(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("suggest indexes", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "index_advisor_handler", "create_migration")
+		errors.SendError(c, apiErr)
+		return
+	}
+	if len(req.Indexes) > 0 {
+		byName := make(map[string]database.IndexSuggestion, len(suggestions))
+		for _, suggestion := range suggestions {
+			byName[suggestion.Name] = suggestion
+		}
+		suggestions = suggestions[:0]
+		for _, name := range req.Indexes {
+			suggestion, ok := byName[name]
+			if !ok {
+				errors.SendError(c, errors.BadRequest(fmt.Sprintf("%s is not a current index suggestion", name)))
+				return
+			}
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	if len(suggestions) == 0 {
+		errors.SendError(c, errors.BadRequest("There are no index suggestions to generate a migration for"))
+		return
+	}
+
+	migration := h.migrations.IndexMigration(suggestions)
+	if req.Apply {
+		if err := h.migrations.ApplyIndexMigration(c.Request.Context(), migration); err != nil {
+			apiErr := errors.DatabaseError("apply suggested indexes", err)
+			monitoring.TrackError(c.Request.Context(), apiErr, "index_advisor_handler", "apply_migration")
+			errors.SendError(c, apiErr)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"version":    migration.Version,
+			"name":       migration.Name,
+			"up_query":   migration.UpQuery,
+			"down_query": migration.DownQuery,
+			"source":     database.MigrationSource(migration),
+			"indexes":    suggestions,
+			"applied":    req.Apply,
+		},
+	})
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexAdvisorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := database.NewDB(&database.Config{DatabasePath: ":memory:", SlowQueryThreshold: time.Nanosecond})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.InitializeDatabase())
+	db.SlowQueries().Reset()
+
+	var count int
+	require.NoError(t, db.GetConnection().QueryRowContext(context.Background(),
+		"SELECT COUNT(*) FROM incidents WHERE application_name = $1 AND priority = $2", "Portal", "P1").Scan(&count))
+
+	handler := NewIndexAdvisorHandler(db)
+	router := gin.New()
+	router.GET("/api/v2/admin/indexes", handler.GetAdvice)
+	router.POST("/api/v2/admin/indexes/migration", handler.CreateMigration)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("GET", "/api/v2/admin/indexes", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var advice struct {
+		Data struct {
+			SlowQueries []database.SlowQuery       `json:"slow_queries"`
+			Suggestions []database.IndexSuggestion `json:"suggestions"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &advice))
+	require.Len(t, advice.Data.Suggestions, 1)
+	suggestion := advice.Data.Suggestions[0]
+	assert.Equal(t, "idx_incidents_application_name_priority", suggestion.Name)
+	assert.Equal(t, []string{"application_name", "priority"}, suggestion.Columns)
+	assert.NotEmpty(t, advice.Data.SlowQueries)
+
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v2/admin/indexes/migration", `{"indexes":["idx_unknown"]}`).Code)
+
+	w = send("POST", "/api/v2/admin/indexes/migration", `{"indexes":["idx_incidents_application_name_priority"],"apply":true}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var migration struct {
+		Data struct {
+			Name    string `json:"name"`
+			UpQuery string `json:"up_query"`
+			Source  string `json:"source"`
+			Applied bool   `json:"applied"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &migration))
+	assert.Equal(t, "add_idx_incidents_application_name_priority", migration.Data.Name)
+	assert.Contains(t, migration.Data.UpQuery, "ON incidents(application_name, priority)")
+	assert.Contains(t, migration.Data.Source, migration.Data.Name)
+	assert.True(t, migration.Data.Applied)
+
+	// The applied index leaves nothing to suggest
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v2/admin/indexes/migration", `{}`).Code)
+}
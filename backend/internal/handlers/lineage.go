@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DataLineage adds a meta block to analytics responses requested with meta=true, describing
+// the uploads, report dates and incident count the numbers came from, whether they were served
+// from the cache, and a version of the data. The lineage follows the filters the response echoes,
+// so it covers the same incidents as the response.
+func (h *AnalyticsHandler) DataLineage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.Query("meta")
+		if value == "" {
+			c.Next()
+			return
+		}
+		withMeta, err := strconv.ParseBool(value)
+		if err != nil {
+			errors.AbortWithError(c, errors.BadRequest("meta must be true or false"))
+			return
+		}
+		if !withMeta {
+			c.Next()
+			return
+		}
+
+		ctx, trace := services.WithCacheTrace(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		writer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = writer.ResponseWriter
+		body := writer.body.Bytes()
+		if writer.status == http.StatusOK && isJSONResponse(writer.Header().Get("Content-Type")) {
+			if annotated, err := h.addLineage(c, body, trace); err != nil {
+				h.logger.WithContext(ctx).Warn("Failed to add data lineage to response", "error", err.Error())
+			} else {
+				body = annotated
+			}
+		}
+
+		c.Writer.WriteHeader(writer.status)
+		if len(body) == 0 {
+			c.Writer.WriteHeaderNow()
+			return
+		}
+		c.Writer.Write(body)
+	}
+}
+
+// addLineage returns the JSON object body with a meta block for the filters it echoes
+func (h *AnalyticsHandler) addLineage(c *gin.Context, body []byte, trace *services.CacheTrace) ([]byte, error) {
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	var filters *services.TimelineFilters
+	if raw, ok := response["filters"]; ok && string(raw) != "null" {
+		filters = &services.TimelineFilters{}
+		if err := json.Unmarshal(raw, filters); err != nil {
+			return nil, err
+		}
+	}
+
+	lineage, err := h.analyticsService.GetDataLineage(c.Request.Context(), filters)
+	if err != nil {
+		return nil, err
+	}
+	lineage.Cache = trace.Status()
+
+	if response["meta"], err = json.Marshal(lineage); err != nil {
+		return nil, err
+	}
+	return json.Marshal(response)
+}
+
+// isJSONResponse reports whether a response content type is JSON
+func isJSONResponse(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/json"
+}
+
+// bufferedWriter holds back a response so middleware can change it after the handler ran
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(p []byte) (int, error) {
+	return w.body.Write(p)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
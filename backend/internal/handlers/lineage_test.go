@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsHandler_DataLineage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 10)
+
+	handler := NewAnalyticsHandler(db)
+	router := gin.New()
+	analytics := router.Group("/analytics", handler.DataLineage())
+	analytics.GET("/priority", handler.GetPriorityAnalysis)
+	analytics.GET("/resolution", handler.GetResolutionAnalysis)
+
+	serve := func(path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var response map[string]interface{}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w, response
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedCache  string
+	}{
+		{name: "computed", path: "/analytics/priority?meta=true", expectedStatus: http.StatusOK, expectedCache: services.LineageCacheMiss},
+		{name: "cached", path: "/analytics/priority?meta=true", expectedStatus: http.StatusOK, expectedCache: services.LineageCacheHit},
+		{name: "not cached", path: "/analytics/resolution?meta=1", expectedStatus: http.StatusOK, expectedCache: services.LineageCacheBypass},
+		{name: "not requested", path: "/analytics/priority", expectedStatus: http.StatusOK},
+		{name: "turned off", path: "/analytics/priority?meta=false", expectedStatus: http.StatusOK},
+		{name: "invalid", path: "/analytics/priority?meta=yes", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, response := serve(tt.path)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Contains(t, response, "data")
+			if tt.expectedCache == "" {
+				assert.NotContains(t, response, "meta")
+				return
+			}
+
+			meta := response["meta"].(map[string]interface{})
+			assert.Equal(t, tt.expectedCache, meta["cache"])
+			assert.Equal(t, 10.0, meta["incident_count"])
+			assert.Len(t, meta["uploads"], 1)
+			assert.NotEmpty(t, meta["data_version"])
+			assert.NotEmpty(t, meta["start_date"])
+			// Let the cache store results before the next request
+			time.Sleep(10 * time.Millisecond)
+		})
+	}
+
+	// The lineage follows the filters of the request
+	_, response := serve("/analytics/priority?meta=true&priorities=P1")
+	meta := response["meta"].(map[string]interface{})
+	assert.Equal(t, 0.0, meta["incident_count"])
+	assert.Empty(t, meta["uploads"])
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceHandler handles the maintenance window calendar endpoints
+type MaintenanceHandler struct {
+	maintenanceService *services.MaintenanceWindowService
+	logger             *logging.Logger
+}
+
+// NewMaintenanceHandler creates a new maintenance handler
+func NewMaintenanceHandler(db *sql.DB) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenanceService: services.NewMaintenanceWindowService(db),
+		logger:             logging.GetGlobalLogger().WithComponent("maintenance_handler"),
+	}
+}
+
+// ListWindows handles GET /api/maintenance-windows
+func (h *MaintenanceHandler) ListWindows(c *gin.Context) {
+	var query MaintenanceWindowQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	from := parseDateParam(query.From)
+	to := parseDateParam(query.To)
+	if to != nil {
+		// Include windows starting at any time on the last day
+		end := to.AddDate(0, 0, 1)
+		to = &end
+	}
+
+	windows, err := h.maintenanceService.ListWindows(c.Request.Context(), query.Application, from, to)
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve maintenance windows", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "maintenance_handler", "list_windows")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, windows, nil, gin.H{
+		"data":  windows,
+		"count": len(windows),
+	})
+}
+
+// GetWindow handles GET /api/maintenance-windows/:id
+func (h *MaintenanceHandler) GetWindow(c *gin.Context) {
+	var params MaintenanceWindowParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	window, err := h.maintenanceService.GetWindow(c.Request.Context(), params.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Maintenance window"))
+			return
+		}
+		apiErr := errors.DatabaseError("retrieve maintenance window", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "maintenance_handler", "get_window")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": window,
+	})
+}
+
+// CreateWindow handles POST /api/maintenance-windows
+func (h *MaintenanceHandler) CreateWindow(c *gin.Context) {
+	start := time.Now()
+
+	var req MaintenanceWindowRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	window := req.ToWindow()
+	window.CreatedBy = requestUser(c)
+
+	created, err := h.maintenanceService.CreateWindow(c.Request.Context(), window)
+	if err != nil {
+		if err == services.ErrInvalidMaintenanceWindow {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("create maintenance window", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "maintenance_handler", "create_window")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).LogDuration("create_maintenance_window", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"window_id":   created.ID,
+			"application": created.ApplicationName,
+			"starts_at":   created.StartsAt,
+			"ends_at":     created.EndsAt,
+		}))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": created,
+	})
+}
+
+// UpdateWindow handles PUT /api/maintenance-windows/:id
+func (h *MaintenanceHandler) UpdateWindow(c *gin.Context) {
+	var params MaintenanceWindowParams
+	if !bindURI(c, &params) {
+		return
+	}
+	var req MaintenanceWindowRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	updated, err := h.maintenanceService.UpdateWindow(c.Request.Context(), params.ID, req.ToWindow())
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			errors.SendError(c, errors.NotFound("Maintenance window"))
+		case services.ErrInvalidMaintenanceWindow:
+			errors.SendError(c, errors.BadRequest(err.Error()))
+		default:
+			apiErr := errors.DatabaseError("update maintenance window", err)
+			monitoring.TrackError(c.Request.Context(), apiErr, "maintenance_handler", "update_window")
+			errors.SendError(c, apiErr)
+		}
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Maintenance window updated",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"window_id":   updated.ID,
+			"application": updated.ApplicationName,
+			"updated_by":  requestUser(c),
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": updated,
+	})
+}
+
+// DeleteWindow handles DELETE /api/maintenance-windows/:id
+func (h *MaintenanceHandler) DeleteWindow(c *gin.Context) {
+	var params MaintenanceWindowParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.maintenanceService.DeleteWindow(c.Request.Context(), params.ID); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Maintenance window"))
+			return
+		}
+		apiErr := errors.DatabaseError("delete maintenance window", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "maintenance_handler", "delete_window")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Maintenance window deleted",
+	})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewMaintenanceHandler(createTestDB(t))
+
+	router := gin.New()
+	router.GET("/api/maintenance-windows", handler.ListWindows)
+	router.POST("/api/maintenance-windows", handler.CreateWindow)
+	router.GET("/api/maintenance-windows/:id", handler.GetWindow)
+	router.PUT("/api/maintenance-windows/:id", handler.UpdateWindow)
+	router.DELETE("/api/maintenance-windows/:id", handler.DeleteWindow)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", "ops-lead")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "missing title", body: `{"starts_at":"2024-03-01T22:00:00Z","ends_at":"2024-03-02T02:00:00Z"}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid timestamp", body: `{"title":"Upgrade","starts_at":"2024-03-01","ends_at":"2024-03-02T02:00:00Z"}`, expectedStatus: http.StatusBadRequest},
+		{name: "ends before start", body: `{"title":"Upgrade","starts_at":"2024-03-02T02:00:00Z","ends_at":"2024-03-01T22:00:00Z"}`, expectedStatus: http.StatusBadRequest},
+		{name: "valid window", body: `{"application_name":"Billing","title":"Upgrade","starts_at":"2024-03-01T22:00:00Z","ends_at":"2024-03-02T02:00:00Z"}`, expectedStatus: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedStatus, send("POST", "/api/maintenance-windows", tt.body).Code)
+		})
+	}
+
+	var listed struct {
+		Data  []services.MaintenanceWindow `json:"data"`
+		Count int                          `json:"count"`
+	}
+	w := send("GET", "/api/maintenance-windows?application=Billing&from=2024-03-01&to=2024-03-01", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Equal(t, 1, listed.Count)
+	window := listed.Data[0]
+	assert.Equal(t, "ops-lead", window.CreatedBy)
+
+	w = send("GET", "/api/maintenance-windows?application=Portal", "")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Equal(t, 0, listed.Count)
+	assert.Equal(t, http.StatusBadRequest, send("GET", "/api/maintenance-windows?from=March", "").Code)
+
+	w = send("PUT", "/api/maintenance-windows/"+window.ID, `{"title":"Network work","starts_at":"2024-03-05T01:00:00Z","ends_at":"2024-03-05T03:00:00Z"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var updated struct {
+		Data services.MaintenanceWindow `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Empty(t, updated.Data.ApplicationName)
+	assert.Equal(t, "Network work", updated.Data.Title)
+
+	assert.Equal(t, http.StatusOK, send("GET", "/api/maintenance-windows/"+window.ID, "").Code)
+	assert.Equal(t, http.StatusOK, send("DELETE", "/api/maintenance-windows/"+window.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/api/maintenance-windows/"+window.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, send("PUT", "/api/maintenance-windows/"+window.ID, `{"title":"x","starts_at":"2024-03-05T01:00:00Z","ends_at":"2024-03-05T03:00:00Z"}`).Code)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MappingProfileHandler handles spreadsheet column mapping profile endpoints
+type MappingProfileHandler struct {
+	profileService *services.MappingProfileService
+	logger         *logging.Logger
+}
+
+// NewMappingProfileHandler creates a new mapping profile handler
+func NewMappingProfileHandler(db *sql.DB) *MappingProfileHandler {
+	return &MappingProfileHandler{
+		profileService: services.NewMappingProfileService(db),
+		logger:         logging.GetGlobalLogger().WithComponent("mapping_profile_handler"),
+	}
+}
+
+// ListProfiles handles GET /api/mapping-profiles
+func (h *MappingProfileHandler) ListProfiles(c *gin.Context) {
+	profiles, err := h.profileService.ListProfiles(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve mapping profiles", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "mapping_profile_handler", "list_profiles")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, profiles, nil, gin.H{
+		"data":  profiles,
+		"count": len(profiles),
+	})
+}
+
+// GetProfile handles GET /api/mapping-profiles/:name
+func (h *MappingProfileHandler) GetProfile(c *gin.Context) {
+	var params MappingProfileParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	profile, err := h.profileService.GetProfile(c.Request.Context(), params.Name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Mapping profile"))
+			return
+		}
+		apiErr := errors.DatabaseError("retrieve mapping profile", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "mapping_profile_handler", "get_profile")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": profile,
+	})
+}
+
+// SaveProfile handles POST /api/mapping-profiles
+func (h *MappingProfileHandler) SaveProfile(c *gin.Context) {
+	var req MappingProfileRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	profile, err := h.profileService.SaveProfile(c.Request.Context(), req.Name, req.Description, req.Columns, req.DateFormats, req.PendingStatuses)
+	if err != nil {
+		if stderrors.Is(err, services.ErrInvalidMappingProfile) {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("save mapping profile", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "mapping_profile_handler", "save_profile")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Mapping profile saved",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"name":   profile.Name,
+			"fields": len(profile.Columns),
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": profile,
+	})
+}
+
+// DeleteProfile handles DELETE /api/mapping-profiles/:name
+func (h *MappingProfileHandler) DeleteProfile(c *gin.Context) {
+	var params MappingProfileParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.profileService.DeleteProfile(c.Request.Context(), params.Name); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Mapping profile"))
+			return
+		}
+		apiErr := errors.DatabaseError("delete mapping profile", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "mapping_profile_handler", "delete_profile")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Mapping profile deleted",
+	})
+}
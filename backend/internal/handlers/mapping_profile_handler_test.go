@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMappingProfileHandler_Profiles(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewMappingProfileHandler(db)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "valid profile",
+			body:           `{"name":"servicenow","description":"ServiceNow export","columns":{"incident_id":["Number"],"report_date":["Opened"]}}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "replace profile",
+			body:           `{"name":"servicenow","columns":{"incident_id":["Number","Ticket Number"]}}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing columns",
+			body:           `{"name":"jira"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown field",
+			body:           `{"name":"jira","columns":{"ticket_key":["Key"]}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "blank header names",
+			body:           `{"name":"jira","columns":{"incident_id":["  "]}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/mapping-profiles", strings.NewReader(tt.body))
+			handler.SaveProfile(c)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	// Get the replaced profile
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/mapping-profiles/servicenow", nil)
+	c.Params = []gin.Param{{Key: "name", Value: "servicenow"}}
+	handler.GetProfile(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data struct {
+			Columns map[string][]string `json:"columns"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, map[string][]string{"incident_id": {"Number", "Ticket Number"}}, response.Data.Columns)
+
+	// List profiles
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/mapping-profiles", nil)
+	handler.ListProfiles(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var list map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, float64(1), list["count"])
+
+	// Delete the profile, then it is gone
+	for _, expectedStatus := range []int{http.StatusOK, http.StatusNotFound} {
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("DELETE", "/mapping-profiles/servicenow", nil)
+		c.Params = []gin.Param{{Key: "name", Value: "servicenow"}}
+		handler.DeleteProfile(c)
+		assert.Equal(t, expectedStatus, w.Code)
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// observedValueLimit caps the stored values listed for a free-text field
+const observedValueLimit = 50
+
+// analyticsFilterFields maps each filter query parameter of AnalyticsQuery to the incident field
+// it filters on
+var analyticsFilterFields = map[string]string{
+	"start_date":    "report_date",
+	"end_date":      "report_date",
+	"priorities":    "priority",
+	"applications":  "application_name",
+	"statuses":      "status",
+	"regions":       "region",
+	"dataset_id":    "dataset_id",
+	"owners":        "cmdb_owner",
+	"environments":  "cmdb_environment",
+	"criticalities": "cmdb_criticality",
+}
+
+// analyticsFilterOptions describes the query parameters of AnalyticsQuery that change how
+// incidents are counted rather than filtering on a field
+var analyticsFilterOptions = map[string]string{
+	"maintenance":    "Include, exclude or keep only incidents reported during maintenance windows",
+	"holiday_region": "Holiday calendar timelines are annotated with",
+	"holidays":       "Include, exclude or adjust for the holidays of holiday_region",
+}
+
+// analyticsDimensions lists the analytics endpoints grouping incidents by each field
+var analyticsDimensions = map[string][]string{
+	"report_date":      {"/analytics/timeline/daily", "/analytics/timeline/weekly", "/analytics/trends"},
+	"priority":         {"/analytics/priority"},
+	"application_name": {"/analytics/applications", "/analytics/benchmark?dimension=application", "/analytics/text-profile"},
+	"upload_id":        {"/analytics/text-profile"},
+	"resolution_group": {"/analytics/groups", "/analytics/benchmark?dimension=group"},
+	"business_service": {"/analytics/services"},
+	"region":           {"/analytics/regions"},
+	"sentiment_label":  {"/analytics/sentiment"},
+	"it_process_group": {"/analytics/automation", "/analytics/automation/distribution", "/analytics/automation/calibration"},
+	"automation_score": {"/analytics/automation/distribution", "/analytics/automation/calibration"},
+}
+
+// SchemaField describes an incident field with the analytics filters and dimensions using it
+type SchemaField struct {
+	models.FieldSchema
+	Filters        []string                 `json:"filters,omitempty"`    // query parameters filtering on the field
+	Dimensions     []string                 `json:"dimensions,omitempty"` // endpoints grouping by the field
+	ObservedValues []services.ObservedValue `json:"observed_values,omitempty"`
+}
+
+// SchemaFilter describes a query parameter shared by the analytics endpoints
+type SchemaFilter struct {
+	Parameter   string `json:"parameter"`
+	Field       string `json:"field,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// DataDictionary describes the incident data model as the API exposes it
+type DataDictionary struct {
+	Fields  []SchemaField       `json:"fields"`
+	Filters []SchemaFilter      `json:"filters"`
+	Enums   map[string][]string `json:"enums"`
+}
+
+// MetaHandler handles the endpoints describing the API itself
+type MetaHandler struct {
+	analyticsService *services.AnalyticsService
+}
+
+// NewMetaHandler creates a new meta handler
+func NewMetaHandler(db *sql.DB) *MetaHandler {
+	return &MetaHandler{
+		analyticsService: services.NewAnalyticsService(db),
+	}
+}
+
+// GetSchema handles GET /api/v2/meta/schema. Fields, types and values are read from the models,
+// so the dictionary follows them as they change.
+func (h *MetaHandler) GetSchema(c *gin.Context) {
+	filters := analyticsFilterParameters()
+	fieldFilters := map[string][]string{}
+	for _, filter := range filters {
+		if filter.Field != "" {
+			fieldFilters[filter.Field] = append(fieldFilters[filter.Field], filter.Parameter)
+		}
+	}
+
+	dictionary := DataDictionary{
+		Filters: filters,
+		Enums: map[string][]string{
+			"priorities":       models.ValidPriorities,
+			"sentiments":       models.ValidSentiments,
+			"upload_statuses":  versionedUploadStatuses(c),
+			"dedup_strategies": models.ValidDedupStrategies,
+		},
+	}
+	for _, field := range models.DescribeIncident() {
+		schema := SchemaField{
+			FieldSchema: field,
+			Filters:     fieldFilters[field.Name],
+			Dimensions:  analyticsDimensions[field.Name],
+		}
+		if services.HasObservedValues(field.Name) {
+			values, err := h.analyticsService.GetObservedValues(c.Request.Context(), field.Name, observedValueLimit)
+			if err != nil {
+				apiErr := errors.DatabaseError("list field values", err)
+				monitoring.TrackError(c.Request.Context(), apiErr, "meta_handler", "get_schema")
+				errors.SendError(c, apiErr)
+				return
+			}
+			schema.ObservedValues = values
+		}
+		dictionary.Fields = append(dictionary.Fields, schema)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": dictionary,
+	})
+}
+
+// analyticsFilterParameters lists the query parameters of AnalyticsQuery in declaration order
+func analyticsFilterParameters() []SchemaFilter {
+	query := reflect.TypeOf(AnalyticsQuery{})
+	filters := make([]SchemaFilter, 0, query.NumField())
+	for i := 0; i < query.NumField(); i++ {
+		parameter, _, _ := strings.Cut(query.Field(i).Tag.Get("form"), ",")
+		if parameter == "" {
+			continue
+		}
+		filters = append(filters, SchemaFilter{
+			Parameter:   parameter,
+			Field:       analyticsFilterFields[parameter],
+			Description: analyticsFilterOptions[parameter],
+		})
+	}
+	return filters
+}
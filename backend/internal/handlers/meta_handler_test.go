@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsFiltersDescribed(t *testing.T) {
+	fields := map[string]bool{}
+	for _, field := range models.DescribeIncident() {
+		fields[field.Name] = true
+	}
+
+	// Every analytics filter names the field it filters on or describes its option
+	query := reflect.TypeOf(AnalyticsQuery{})
+	for i := 0; i < query.NumField(); i++ {
+		parameter, _, _ := strings.Cut(query.Field(i).Tag.Get("form"), ",")
+		field, mapped := analyticsFilterFields[parameter]
+		_, option := analyticsFilterOptions[parameter]
+		assert.True(t, mapped || option, "analytics filter %s is not described", parameter)
+		if mapped {
+			assert.True(t, fields[field], "analytics filter %s names unknown field %s", parameter, field)
+		}
+	}
+	for field := range analyticsDimensions {
+		assert.True(t, fields[field], "analytics dimension names unknown field %s", field)
+	}
+}
+
+func TestMetaHandler_GetSchema(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewMetaHandler(db)
+
+	batch := []models.Incident{}
+	for i, status := range []string{"Closed", "Closed", "Open"} {
+		batch = append(batch, models.Incident{ID: "inc-" + string(rune('a'+i)), UploadID: "upload-1", IncidentID: "INC-" + string(rune('a'+i)),
+			ReportDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), ApplicationName: "Portal", Priority: "P2", Status: status})
+	}
+	_, err := services.NewIncidentService(db).BatchInsertIncidents(context.Background(), batch, "upload-1")
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/api/v2/meta/schema", handler.GetSchema)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v2/meta/schema", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Data DataDictionary `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, models.ValidPriorities, response.Data.Enums["priorities"])
+	assert.Equal(t, models.ValidSentiments, response.Data.Enums["sentiments"])
+
+	fields := map[string]SchemaField{}
+	for _, field := range response.Data.Fields {
+		fields[field.Name] = field
+	}
+	assert.Len(t, fields, len(models.DescribeIncident()))
+
+	priority := fields["priority"]
+	assert.Equal(t, "string", priority.Type)
+	assert.Equal(t, models.ValidPriorities, priority.Values)
+	assert.Equal(t, []string{"priorities"}, priority.Filters)
+	assert.Equal(t, []string{"/analytics/priority"}, priority.Dimensions)
+	assert.Equal(t, []string{"start_date", "end_date"}, fields["report_date"].Filters)
+	assert.True(t, fields["sentiment_label"].Derived)
+
+	assert.Equal(t, []services.ObservedValue{{Value: "Closed", Count: 2}, {Value: "Open", Count: 1}}, fields["status"].ObservedValues)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultMetricsHistoryRange is how far back the metrics history reaches without a start
+const defaultMetricsHistoryRange = 7 * 24 * time.Hour
+
+// MetricsHistoryHandler handles the monitoring metrics history endpoint
+type MetricsHistoryHandler struct {
+	history *services.MetricsHistoryService
+}
+
+// NewMetricsHistoryHandler creates a new metrics history handler
+func NewMetricsHistoryHandler(history *services.MetricsHistoryService) *MetricsHistoryHandler {
+	return &MetricsHistoryHandler{
+		history: history,
+	}
+}
+
+// GetHistory handles GET /api/v2/monitoring/history
+func (h *MetricsHistoryHandler) GetHistory(c *gin.Context) {
+	var query MetricsHistoryQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	end := time.Now().UTC()
+	if query.End != "" {
+		end, _ = time.Parse(time.RFC3339, query.End)
+	}
+	start := end.Add(-defaultMetricsHistoryRange)
+	if query.Start != "" {
+		start, _ = time.Parse(time.RFC3339, query.Start)
+	}
+	if !start.Before(end) {
+		errors.SendError(c, errors.BadRequest("start must be before end"))
+		return
+	}
+	if query.Interval == "" {
+		query.Interval = services.MetricsIntervalHour
+	}
+
+	points, err := h.history.GetHistory(c.Request.Context(), start, end, query.Interval)
+	if err != nil {
+		apiErr := errors.DatabaseError("get metrics history", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "metrics_history_handler", "get_history")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, points, gin.H{"start": start, "end": end, "interval": query.Interval}, gin.H{
+		"data": points,
+	})
+}
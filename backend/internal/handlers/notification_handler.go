@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler handles the notification preferences of the calling user
+type NotificationHandler struct {
+	preferences *services.NotificationPreferenceService
+	logger      *logging.Logger
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(preferences *services.NotificationPreferenceService) *NotificationHandler {
+	return &NotificationHandler{
+		preferences: preferences,
+		logger:      logging.GetGlobalLogger().WithComponent("notification_handler"),
+	}
+}
+
+// GetPreferences handles GET /api/me/notifications
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	preferences, err := h.preferences.GetPreferences(c.Request.Context(), requestUser(c))
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve notification preferences", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "notification_handler", "get_preferences")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": preferences,
+	})
+}
+
+// UpdatePreferences handles PUT /api/me/notifications
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var req NotificationPreferencesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	updated, err := h.preferences.SavePreferences(c.Request.Context(), requestUser(c), req.ToPreferences())
+	if err != nil {
+		if stderrors.Is(err, services.ErrInvalidNotificationPreferences) {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("save notification preferences", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "notification_handler", "update_preferences")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Notification preferences updated",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"events":   updated.Events,
+			"channels": updated.Channels,
+			"delivery": updated.Delivery,
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": updated,
+	})
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationHandler_Preferences(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewNotificationHandler(services.NewNotificationPreferenceService(db, services.NewNotifier(nil)))
+
+	request := func(user, method, body string, handle gin.HandlerFunc) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, "/me/notifications", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("X-User-ID", user)
+		handle(c)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) services.NotificationPreferences {
+		var response struct {
+			Data services.NotificationPreferences `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	// Users who never saved preferences get the defaults
+	w := request("alice", "GET", "", handler.GetPreferences)
+	require.Equal(t, http.StatusOK, w.Code)
+	defaults := decode(w)
+	assert.Equal(t, services.DeliveryImmediate, defaults.Delivery)
+	assert.ElementsMatch(t, services.NotificationEvents, defaults.Events)
+	assert.Nil(t, defaults.UpdatedAt)
+
+	// Save a digest on Slack with quiet hours
+	w = request("alice", "PUT",
+		`{"events":["watchlist.volume"],"channels":["slack"],"delivery":"digest","digest_hour":9,"quiet_hours_start":"22:00","quiet_hours_end":"07:00","timezone":"Europe/Berlin"}`,
+		handler.UpdatePreferences)
+	require.Equal(t, http.StatusOK, w.Code)
+	saved := decode(w)
+	assert.Equal(t, []string{"slack"}, saved.Channels)
+	assert.Equal(t, 9, saved.DigestHour)
+	assert.NotNil(t, saved.UpdatedAt)
+
+	w = request("alice", "GET", "", handler.GetPreferences)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "22:00", decode(w).QuietHoursStart)
+
+	// Preferences belong to their user
+	w = request("bob", "GET", "", handler.GetPreferences)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, services.DeliveryImmediate, decode(w).Delivery)
+
+	// Unknown events, half-set or malformed quiet hours and unknown time zones are rejected
+	for _, body := range []string{
+		`{"events":["upload.completed"]}`,
+		`{"quiet_hours_start":"22:00"}`,
+		`{"quiet_hours_start":"10pm","quiet_hours_end":"7am"}`,
+		`{"timezone":"Mars/Olympus"}`,
+		`{"digest_hour":24}`,
+	} {
+		w = request("alice", "PUT", body, handler.UpdatePreferences)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
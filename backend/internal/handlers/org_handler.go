@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrgHandler handles org hierarchy management endpoints
+type OrgHandler struct {
+	orgService *services.OrgHierarchyService
+	logger     *logging.Logger
+}
+
+// NewOrgHandler creates a new org hierarchy handler
+func NewOrgHandler(db *sql.DB) *OrgHandler {
+	return &OrgHandler{
+		orgService: services.NewOrgHierarchyService(db),
+		logger:     logging.GetGlobalLogger().WithComponent("org_handler"),
+	}
+}
+
+// ListGroups handles GET /api/org/groups
+func (h *OrgHandler) ListGroups(c *gin.Context) {
+	groups, err := h.orgService.ListGroups(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve org hierarchy", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "org_handler", "list_groups")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, groups, nil, gin.H{
+		"data":  groups,
+		"count": len(groups),
+	})
+}
+
+// SaveGroup handles PUT /api/org/groups/:group
+func (h *OrgHandler) SaveGroup(c *gin.Context) {
+	resolutionGroup := strings.TrimSpace(c.Param("group"))
+	if resolutionGroup == "" {
+		errors.SendError(c, errors.NewAPIError(errors.ErrMissingParameter, "Resolution group is required"))
+		return
+	}
+
+	var req OrgGroupRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	group, err := h.orgService.SaveGroup(c.Request.Context(), resolutionGroup, req.Department, req.Division)
+	if err != nil {
+		apiErr := errors.DatabaseError("save org group", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "org_handler", "save_group")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Org group saved",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"resolution_group": group.ResolutionGroup,
+			"department":       group.Department,
+			"division":         group.Division,
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": group,
+	})
+}
+
+// DeleteGroup handles DELETE /api/org/groups/:group
+func (h *OrgHandler) DeleteGroup(c *gin.Context) {
+	if err := h.orgService.DeleteGroup(c.Request.Context(), c.Param("group")); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Org group"))
+			return
+		}
+		apiErr := errors.DatabaseError("delete org group", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "org_handler", "delete_group")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Org group deleted",
+	})
+}
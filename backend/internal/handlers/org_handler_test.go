@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgHandler_Groups(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewOrgHandler(db)
+
+	// Save group
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("PUT", "/org/groups/Web%20Team",
+		strings.NewReader(`{"department":"Digital","division":"Technology"}`))
+	c.Params = []gin.Param{{Key: "group", Value: "Web Team"}}
+	handler.SaveGroup(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Missing division is rejected
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("PUT", "/org/groups/Web%20Team", strings.NewReader(`{"department":"Digital"}`))
+	c.Params = []gin.Param{{Key: "group", Value: "Web Team"}}
+	handler.SaveGroup(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// List groups
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/org/groups", nil)
+	handler.ListGroups(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(1), response["count"])
+
+	// Delete unknown group
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/org/groups/unknown", nil)
+	c.Params = []gin.Param{{Key: "group", Value: "unknown"}}
+	handler.DeleteGroup(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
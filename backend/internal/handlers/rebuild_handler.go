@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RebuildHandler handles rebuilds of the data derived from the stored incidents, which run on
+// the job queue
+type RebuildHandler struct {
+	jobQueue *services.JobQueue
+	logger   *logging.Logger
+}
+
+// NewRebuildHandler creates a new rebuild handler. The job queue must have a rebuilder set.
+func NewRebuildHandler(jobQueue *services.JobQueue) *RebuildHandler {
+	return &RebuildHandler{
+		jobQueue: jobQueue,
+		logger:   logging.GetGlobalLogger().WithComponent("rebuild_handler"),
+	}
+}
+
+// StartRebuild handles POST /api/v2/admin/rebuild. It queues a job rebuilding the analytics
+// views, IT process groups and analytics cache from the stored incidents.
+func (h *RebuildHandler) StartRebuild(c *gin.Context) {
+	job, err := h.jobQueue.SubmitJobContext(c.Request.Context(), services.JobTypeRebuildArtifacts, "", map[string]interface{}{})
+	if err != nil {
+		apiErr := errors.NewAPIError(errors.ErrServiceUnavailable, err.Error()).
+			WithUserMessage("The rebuild could not be queued, please try again shortly")
+		monitoring.TrackError(c.Request.Context(), apiErr, "rebuild_handler", "start_rebuild")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Derived artifact rebuild queued",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"job_id": job.ID,
+		}))
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"job_id":     job.ID,
+		"status":     services.JobStatusPending,
+		"status_url": fmt.Sprintf("%s/admin/rebuild/%s", apiPathPrefix(c), job.ID),
+	})
+}
+
+// GetRebuild handles GET /api/v2/admin/rebuild/:id. A completed job's result reports each
+// rebuilt artifact.
+func (h *RebuildHandler) GetRebuild(c *gin.Context) {
+	var params RebuildParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	job, err := h.jobQueue.JobSnapshot(params.ID)
+	if err != nil || job.Type != services.JobTypeRebuildArtifacts {
+		errors.SendError(c, errors.NotFound("Rebuild"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": job})
+}
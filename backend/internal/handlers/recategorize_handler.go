@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecategorizeHandler handles IT process re-categorizations of stored incidents, which run on
+// the job queue
+type RecategorizeHandler struct {
+	jobQueue *services.JobQueue
+	logger   *logging.Logger
+}
+
+// NewRecategorizeHandler creates a new recategorize handler. The job queue must have a
+// recategorizer set.
+func NewRecategorizeHandler(jobQueue *services.JobQueue) *RecategorizeHandler {
+	return &RecategorizeHandler{
+		jobQueue: jobQueue,
+		logger:   logging.GetGlobalLogger().WithComponent("recategorize_handler"),
+	}
+}
+
+// StartRecategorization handles POST /api/v2/admin/recategorize. It queues a job assigning the
+// incidents matching the analytics filters the IT process group the current categorization gives
+// them.
+func (h *RecategorizeHandler) StartRecategorization(c *gin.Context) {
+	var query RecategorizeQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	filters := query.ToFilters()
+
+	job, err := h.jobQueue.SubmitJobContext(c.Request.Context(), services.JobTypeRecategorize, "", map[string]interface{}{
+		"filters": filters,
+		"dry_run": query.DryRun,
+	})
+	if err != nil {
+		apiErr := errors.NewAPIError(errors.ErrServiceUnavailable, err.Error()).
+			WithUserMessage("The re-categorization could not be queued, please try again shortly")
+		monitoring.TrackError(c.Request.Context(), apiErr, "recategorize_handler", "start_recategorization")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("IT process re-categorization queued",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"job_id":  job.ID,
+			"dry_run": query.DryRun,
+		}))
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"job_id":     job.ID,
+		"status":     services.JobStatusPending,
+		"status_url": fmt.Sprintf("%s/admin/recategorize/%s", apiPathPrefix(c), job.ID),
+		"dry_run":    query.DryRun,
+		"filters":    filters,
+	})
+}
+
+// GetRecategorization handles GET /api/v2/admin/recategorize/:id. A completed job's result
+// counts the incidents scanned and moved, by old and new group.
+func (h *RecategorizeHandler) GetRecategorization(c *gin.Context) {
+	var params RecategorizeParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	job, err := h.jobQueue.JobSnapshot(params.ID)
+	if err != nil || job.Type != services.JobTypeRecategorize {
+		errors.SendError(c, errors.NotFound("Re-categorization"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": job})
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecategorizeHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDBAnalytics(t)
+	createTestIncidents(t, db, 3)
+	_, err := db.Exec("UPDATE incidents SET it_process_group = 'Legacy Group'")
+	require.NoError(t, err)
+
+	jobQueue := services.NewJobQueue(services.JobQueueConfig{Workers: 1}, services.NewProcessingService(db, nil))
+	defer jobQueue.Shutdown()
+	jobQueue.SetRecategorizer(services.NewITProcessRecategorizer(db))
+	handler := NewRecategorizeHandler(jobQueue)
+
+	router := gin.New()
+	router.POST("/api/v2/admin/recategorize", handler.StartRecategorization)
+	router.GET("/api/v2/admin/recategorize/:id", handler.GetRecategorization)
+	send := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v2/admin/recategorize?priorities=P9").Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/api/v2/admin/recategorize/job_missing").Code)
+
+	w := send("POST", "/api/v2/admin/recategorize")
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var queued struct {
+		JobID     string `json:"job_id"`
+		StatusURL string `json:"status_url"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &queued))
+	require.NotEmpty(t, queued.JobID)
+
+	var status struct {
+		Data struct {
+			Status string                      `json:"status"`
+			Result services.RecategorizeResult `json:"result"`
+		} `json:"data"`
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for status.Data.Status != string(services.JobStatusCompleted) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		w = send("GET", "/api/v2/admin/recategorize/"+queued.JobID)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	}
+	require.Equal(t, string(services.JobStatusCompleted), status.Data.Status)
+	assert.Equal(t, 3, status.Data.Result.Scanned)
+	assert.Equal(t, 3, status.Data.Result.Changed)
+
+	var legacy int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM incidents WHERE it_process_group = 'Legacy Group'").Scan(&legacy))
+	assert.Zero(t, legacy)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler handles generated report endpoints
+type ReportHandler struct {
+	opsReviewService *services.OpsReviewService
+	handoverService  *services.HandoverService
+	logger           *logging.Logger
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(db *sql.DB) *ReportHandler {
+	return &ReportHandler{
+		opsReviewService: services.NewOpsReviewService(db),
+		handoverService:  services.NewHandoverService(db),
+		logger:           logging.GetGlobalLogger().WithComponent("report_handler"),
+	}
+}
+
+// GetOpsReview handles GET /api/reports/ops-review
+func (h *ReportHandler) GetOpsReview(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_ops_review")
+
+	var query OpsReviewQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	pack, err := h.opsReviewService.GenerateWeeklyPack(c.Request.Context(), parseDateParam(query.Week))
+	if err != nil {
+		apiErr := errors.DatabaseError("generate ops review", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "report_handler", "get_ops_review")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_ops_review", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"week_start":   pack.WeekStart,
+			"format":       query.Format,
+			"sla_breaches": len(pack.SLABreaches),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	if query.Format == "xlsx" {
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=ops-review-%s.xlsx", pack.WeekStart))
+		c.Status(http.StatusOK)
+		if err := services.WriteOpsReviewXLSX(pack, c.Writer); err != nil {
+			logger.Error("Failed to write ops review workbook", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": pack,
+	})
+}
+
+// GetHandover handles GET /api/reports/handover
+func (h *ReportHandler) GetHandover(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_handover")
+
+	var query HandoverQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	window := services.DefaultHandoverWindow
+	if query.Window != "" {
+		window, _ = time.ParseDuration(query.Window)
+	}
+	if window > services.MaxHandoverWindow {
+		errors.SendError(c, errors.BadRequest(fmt.Sprintf("window must be at most %s", services.MaxHandoverWindow)))
+		return
+	}
+	end := time.Now()
+	if query.End != "" {
+		end, _ = time.Parse(time.RFC3339, query.End)
+	}
+
+	report, err := h.handoverService.GenerateHandover(c.Request.Context(), end, window)
+	if err != nil {
+		apiErr := errors.DatabaseError("generate handover", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "report_handler", "get_handover")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_handover", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"window_hours":   report.WindowHours,
+			"format":         query.Format,
+			"new_p1_p2":      len(report.NewMajorIncidents),
+			"open_incidents": report.Summary.OpenIncidents,
+			"anomaly_count":  len(report.Anomalies),
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	if query.Format == "text" {
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+		c.Status(http.StatusOK)
+		if err := services.WriteHandoverText(report, c.Writer); err != nil {
+			logger.Error("Failed to write handover text", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportHandler_GetOpsReview(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewReportHandler(db)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedType   string
+	}{
+		{
+			name:           "json pack for a given week",
+			query:          "?week=2024-01-17",
+			expectedStatus: http.StatusOK,
+			expectedType:   "application/json; charset=utf-8",
+		},
+		{
+			name:           "xlsx pack",
+			query:          "?format=xlsx",
+			expectedStatus: http.StatusOK,
+			expectedType:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		},
+		{
+			name:           "unsupported format",
+			query:          "?format=pdf",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid week",
+			query:          "?week=last-week",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/reports/ops-review"+tt.query, nil)
+
+			handler.GetOpsReview(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedType != "" {
+				assert.Equal(t, tt.expectedType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+
+	// The requested week is normalized to its Monday
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/reports/ops-review?week=2024-01-17", nil)
+	handler.GetOpsReview(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "2024-01-15", data["week_start"])
+}
+
+func TestReportHandler_GetHandover(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewReportHandler(db)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedType   string
+	}{
+		{
+			name:           "default window",
+			query:          "",
+			expectedStatus: http.StatusOK,
+			expectedType:   "application/json; charset=utf-8",
+		},
+		{
+			name:           "rendered text",
+			query:          "?window=12h&format=text",
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/plain; charset=utf-8",
+		},
+		{
+			name:           "invalid window",
+			query:          "?window=8",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "window too long",
+			query:          "?window=168h",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid end",
+			query:          "?end=2024-01-15",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/reports/handover"+tt.query, nil)
+
+			handler.GetHandover(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedType != "" {
+				assert.Equal(t, tt.expectedType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+
+	// The window ends at the requested time
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/reports/handover?window=8h&end=2024-01-15T16:00:00Z", nil)
+	handler.GetHandover(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "2024-01-15T08:00:00Z", data["window_start"])
+	assert.Equal(t, float64(8), data["window_hours"])
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportSnapshotHandler handles report snapshots and their share links
+type ReportSnapshotHandler struct {
+	snapshots *services.ReportSnapshotService
+	logger    *logging.Logger
+}
+
+// NewReportSnapshotHandler creates a new report snapshot handler
+func NewReportSnapshotHandler(db *sql.DB) *ReportSnapshotHandler {
+	return &ReportSnapshotHandler{
+		snapshots: services.NewReportSnapshotService(db),
+		logger:    logging.GetGlobalLogger().WithComponent("report_snapshot_handler"),
+	}
+}
+
+// CreateSnapshot handles POST /api/v2/reports/snapshots. The view's filters are sent as query
+// parameters, as for the view itself. The token in the response is only shown here.
+func (h *ReportSnapshotHandler) CreateSnapshot(c *gin.Context) {
+	start := time.Now()
+
+	filters, ok := parseRankedFilters(c)
+	if !ok {
+		return
+	}
+	var req SnapshotRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	actor := requestUser(c)
+	snapshot, token, err := h.snapshots.CreateSnapshot(c.Request.Context(), req.Name, req.View, filters,
+		time.Duration(req.TTLDays)*24*time.Hour, actor)
+	if err != nil {
+		h.sendError(c, err, "create_snapshot")
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).LogDuration("create_snapshot", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"snapshot":   snapshot.ID,
+			"view":       snapshot.View,
+			"created_by": actor,
+			"expires_at": snapshot.ExpiresAt,
+		}))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":  snapshot,
+		"token": token,
+		"url":   apiPathPrefix(c) + "/shared/" + token,
+	})
+}
+
+// ListSnapshots handles GET /api/v2/reports/snapshots
+func (h *ReportSnapshotHandler) ListSnapshots(c *gin.Context) {
+	snapshots, err := h.snapshots.ListSnapshots(c.Request.Context())
+	if err != nil {
+		h.sendError(c, err, "list_snapshots")
+		return
+	}
+
+	sendList(c, snapshots, nil, gin.H{
+		"data":  snapshots,
+		"count": len(snapshots),
+	})
+}
+
+// RevokeSnapshot handles DELETE /api/v2/reports/snapshots/:id
+func (h *ReportSnapshotHandler) RevokeSnapshot(c *gin.Context) {
+	var params SnapshotParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.snapshots.RevokeSnapshot(c.Request.Context(), params.ID); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Snapshot"))
+			return
+		}
+		h.sendError(c, err, "revoke_snapshot")
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Snapshot revoked",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"snapshot":   params.ID,
+			"revoked_by": requestUser(c),
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Snapshot revoked",
+	})
+}
+
+// GetSharedSnapshot handles GET /api/v2/shared/:token, serving the frozen result without sign-in
+func (h *ReportSnapshotHandler) GetSharedSnapshot(c *gin.Context) {
+	var params SharedSnapshotParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	snapshot, err := h.snapshots.GetSharedSnapshot(c.Request.Context(), params.Token)
+	if err == sql.ErrNoRows {
+		errors.SendError(c, errors.NotFound("Snapshot").
+			WithUserMessage("This link does not exist, was revoked or has expired"))
+		return
+	}
+	if err != nil {
+		h.sendError(c, err, "get_shared_snapshot")
+		return
+	}
+
+	// Links are shared outside the tool, so the creator's name stays out of the response
+	snapshot.CreatedBy = ""
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"data": snapshot,
+	})
+}
+
+// sendError answers a failed snapshot operation, with 400 for views that cannot be snapshotted
+func (h *ReportSnapshotHandler) sendError(c *gin.Context, err error, operation string) {
+	if stderrors.Is(err, services.ErrInvalidSnapshot) {
+		errors.SendError(c, errors.BadRequest(err.Error()))
+		return
+	}
+	apiErr := errors.DatabaseError("manage report snapshots", err)
+	monitoring.TrackError(c.Request.Context(), apiErr, "report_snapshot_handler", operation)
+	errors.SendError(c, apiErr)
+}
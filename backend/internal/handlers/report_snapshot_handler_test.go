@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportSnapshotHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewReportSnapshotHandler(db)
+	incidents := services.NewIncidentService(db)
+
+	insert := func(uploadID string, priorities ...string) {
+		batch := make([]models.Incident, len(priorities))
+		for i, priority := range priorities {
+			batch[i] = models.Incident{ID: uploadID + "-" + priority, UploadID: uploadID, IncidentID: "INC-" + uploadID + priority,
+				ReportDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), ApplicationName: "Portal", Priority: priority, Status: "Open"}
+		}
+		_, err := incidents.BatchInsertIncidents(context.Background(), batch, uploadID)
+		require.NoError(t, err)
+	}
+	insert("upload-1", "P1", "P2")
+
+	router := gin.New()
+	api := router.Group("/api/v2", APIVersion(APIVersion2))
+	api.GET("/reports/snapshots", handler.ListSnapshots)
+	api.POST("/reports/snapshots", handler.CreateSnapshot)
+	api.DELETE("/reports/snapshots/:id", handler.RevokeSnapshot)
+	api.GET("/shared/:token", handler.GetSharedSnapshot)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", "analyst-7")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v2/reports/snapshots", `{"name":"January","view":"noise"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v2/reports/snapshots?start_date=2024-13-01", `{"name":"January","view":"priority"}`).Code)
+
+	w := send("POST", "/api/v2/reports/snapshots?start_date=2024-01-01&end_date=2024-01-31&priorities=P1",
+		`{"name":"January P1","view":"priority","ttl_days":30}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Data  services.ReportSnapshot `json:"data"`
+		Token string                  `json:"token"`
+		URL   string                  `json:"url"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "/api/v2/shared/"+created.Token, created.URL)
+	assert.Equal(t, []string{"P1"}, created.Data.Filters.Priorities)
+	assert.Equal(t, "analyst-7", created.Data.CreatedBy)
+	assert.WithinDuration(t, created.Data.CreatedAt.Add(30*24*time.Hour), created.Data.ExpiresAt, time.Second)
+
+	// Incidents arriving later leave the snapshot as it was
+	insert("upload-2", "P1", "P3")
+
+	w = send("GET", created.URL, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var shared struct {
+		Data struct {
+			services.ReportSnapshot
+			Data []services.PriorityAnalysis `json:"data"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &shared))
+	require.Len(t, shared.Data.Data, 1)
+	assert.Equal(t, "P1", shared.Data.Data[0].Priority)
+	assert.Equal(t, 1, shared.Data.Data[0].Count)
+	assert.Equal(t, 1, shared.Data.ViewCount)
+	assert.Empty(t, shared.Data.CreatedBy)
+
+	w = send("GET", "/api/v2/reports/snapshots", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed struct {
+		Data []services.ReportSnapshot `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Data, 1)
+	assert.Nil(t, listed.Data[0].Data)
+	assert.Equal(t, 1, listed.Data[0].ViewCount)
+
+	assert.Equal(t, http.StatusNotFound, send("GET", "/api/v2/shared/bogus", "").Code)
+
+	// Revoked links stop working
+	assert.Equal(t, http.StatusOK, send("DELETE", "/api/v2/reports/snapshots/"+created.Data.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, send("DELETE", "/api/v2/reports/snapshots/"+created.Data.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", created.URL, "").Code)
+}
@@ -0,0 +1,1184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/services"
+)
+
+// AnalyticsQuery holds the filter parameters shared by all analytics endpoints
+type AnalyticsQuery struct {
+	StartDate    string `form:"start_date" binding:"omitempty,date"`
+	EndDate      string `form:"end_date" binding:"omitempty,date"`
+	Priorities   string `form:"priorities" binding:"omitempty,csvoneof=P1 P2 P3 P4"`
+	Applications string `form:"applications"`
+	Statuses     string `form:"statuses"`
+	Regions      string `form:"regions"`
+	DatasetID    string `form:"dataset_id" binding:"omitempty,max=200"`
+	// Owners, Environments and Criticalities filter on the CMDB attributes of incidents
+	Owners        string `form:"owners"`
+	Environments  string `form:"environments"`
+	Criticalities string `form:"criticalities"`
+	Maintenance   string `form:"maintenance" binding:"omitempty,oneof=include exclude only"`
+	// HolidayRegion is required when holidays are excluded or adjusted
+	HolidayRegion string `form:"holiday_region" binding:"required_if=Holidays exclude,required_if=Holidays adjust,max=50"`
+	Holidays      string `form:"holidays" binding:"omitempty,oneof=include exclude adjust"`
+}
+
+// ToFilters converts the validated query into service-level timeline filters
+func (q AnalyticsQuery) ToFilters() *services.TimelineFilters {
+	return &services.TimelineFilters{
+		StartDate:     parseDateParam(q.StartDate),
+		EndDate:       parseDateParam(q.EndDate),
+		Priorities:    splitCSV(q.Priorities),
+		Applications:  splitCSV(q.Applications),
+		Statuses:      splitCSV(q.Statuses),
+		Regions:       splitCSV(q.Regions),
+		Owners:        splitCSV(q.Owners),
+		Environments:  splitCSV(q.Environments),
+		Criticalities: splitCSV(q.Criticalities),
+		DatasetID:     q.DatasetID,
+		Maintenance:   q.Maintenance,
+		HolidayRegion: q.HolidayRegion,
+		Holidays:      q.Holidays,
+	}
+}
+
+// RankedAnalyticsQuery holds the analytics parameters for endpoints returning ranked lists
+type RankedAnalyticsQuery struct {
+	AnalyticsQuery
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// ToFilters converts the validated query into timeline filters carrying the list limit
+func (q RankedAnalyticsQuery) ToFilters() *services.TimelineFilters {
+	filters := q.AnalyticsQuery.ToFilters()
+	filters.Limit = q.Limit
+	return filters
+}
+
+// TimelineQuery holds the parameters for the daily and weekly timelines
+type TimelineQuery struct {
+	AnalyticsQuery
+	BaselineWeeks int `form:"baseline_weeks" binding:"omitempty,min=1,max=52"`
+}
+
+// ToFilters converts the validated query into timeline filters carrying the baseline weeks
+func (q TimelineQuery) ToFilters() *services.TimelineFilters {
+	filters := q.AnalyticsQuery.ToFilters()
+	filters.BaselineWeeks = q.BaselineWeeks
+	return filters
+}
+
+// HourlyTimelineQuery holds the parameters for the hourly timeline, whose date range is required
+// and at most services.MaxHourlyTimelineDays days long
+type HourlyTimelineQuery struct {
+	TimelineQuery
+}
+
+// TrendQuery holds the parameters for trend analysis
+type TrendQuery struct {
+	AnalyticsQuery
+	Period string `form:"period" binding:"omitempty,oneof=daily weekly"`
+}
+
+// GroupAnalyticsQuery holds the parameters for org hierarchy group analysis
+type GroupAnalyticsQuery struct {
+	RankedAnalyticsQuery
+	Level string `form:"level" binding:"omitempty,oneof=group department division"`
+	Unit  string `form:"unit" binding:"omitempty,max=200"`
+}
+
+// ServiceAnalyticsQuery holds the parameters for business service analysis
+type ServiceAnalyticsQuery struct {
+	RankedAnalyticsQuery
+	Level string `form:"level" binding:"omitempty,oneof=service business_unit"`
+	Unit  string `form:"unit" binding:"omitempty,max=200"`
+}
+
+// BenchmarkQuery holds the parameters for benchmarking applications or groups against the portfolio
+type BenchmarkQuery struct {
+	AnalyticsQuery
+	Dimension    string  `form:"dimension" binding:"omitempty,oneof=application group"`
+	MinIncidents int     `form:"min_incidents" binding:"omitempty,min=1"`
+	ZThreshold   float64 `form:"z_threshold" binding:"omitempty,gte=0.5,lte=5"`
+}
+
+// AutomationDistributionQuery holds the parameters for the automation score distribution.
+// Boundaries, when given, replace the equal-width buckets.
+type AutomationDistributionQuery struct {
+	AnalyticsQuery
+	Buckets    int    `form:"buckets" binding:"omitempty,min=1,max=100"`
+	Boundaries string `form:"boundaries" binding:"omitempty,scoreboundaries"`
+}
+
+// ToBoundaries converts the validated parameters into sorted, distinct bucket boundaries
+func (q AutomationDistributionQuery) ToBoundaries() []float64 {
+	if q.Boundaries == "" {
+		return services.EqualScoreBoundaries(q.Buckets)
+	}
+	var boundaries []float64
+	for _, value := range splitCSV(q.Boundaries) {
+		if boundary, err := strconv.ParseFloat(value, 64); err == nil {
+			boundaries = append(boundaries, boundary)
+		}
+	}
+	slices.Sort(boundaries)
+	return slices.Compact(boundaries)
+}
+
+// AutomationCalibrationQuery holds the parameters for the automation threshold calibration
+// report. Thresholds, when given, replace the equal steps as candidate thresholds.
+type AutomationCalibrationQuery struct {
+	AnalyticsQuery
+	Steps      int    `form:"steps" binding:"omitempty,min=2,max=100"`
+	Thresholds string `form:"thresholds" binding:"omitempty,scoreboundaries"`
+}
+
+// ToCandidates converts the validated parameters into sorted, distinct candidate thresholds
+func (q AutomationCalibrationQuery) ToCandidates() []float64 {
+	if q.Thresholds == "" {
+		steps := q.Steps
+		if steps == 0 {
+			steps = services.DefaultCalibrationSteps
+		}
+		return services.EqualScoreBoundaries(steps)
+	}
+	return AutomationDistributionQuery{Boundaries: q.Thresholds}.ToBoundaries()
+}
+
+// OutlierParams holds the resolution time outlier detection parameters
+type OutlierParams struct {
+	OutlierMethod string  `form:"outlier_method" binding:"omitempty,oneof=iqr percentile"`
+	IQRMultiplier float64 `form:"iqr_multiplier" binding:"omitempty,gt=0,lte=10"`
+	Percentile    float64 `form:"percentile" binding:"omitempty,gte=50,lt=100"`
+}
+
+// ToOptions converts the validated parameters into service-level outlier options
+func (p OutlierParams) ToOptions() services.OutlierOptions {
+	return services.OutlierOptions{
+		Method:        p.OutlierMethod,
+		IQRMultiplier: p.IQRMultiplier,
+		Percentile:    p.Percentile,
+	}
+}
+
+// PercentileParams holds the resolution time percentiles reported alongside the median
+type PercentileParams struct {
+	Percentiles string `form:"percentiles" binding:"omitempty,percentiles"`
+}
+
+// ToPercentiles converts the validated list into sorted, distinct percentiles
+func (p PercentileParams) ToPercentiles() []float64 {
+	var percentiles []float64
+	for _, value := range splitCSV(p.Percentiles) {
+		if percentile, err := strconv.ParseFloat(value, 64); err == nil {
+			percentiles = append(percentiles, percentile)
+		}
+	}
+	slices.Sort(percentiles)
+	return slices.Compact(percentiles)
+}
+
+// ResolutionQuery holds the parameters for resolution analysis
+type ResolutionQuery struct {
+	AnalyticsQuery
+	OutlierParams
+	PercentileParams
+	ExcludeOutliers bool `form:"exclude_outliers"`
+}
+
+// ToFilters converts the validated query into timeline filters carrying the percentiles
+func (q ResolutionQuery) ToFilters() *services.TimelineFilters {
+	filters := q.AnalyticsQuery.ToFilters()
+	filters.Percentiles = q.ToPercentiles()
+	return filters
+}
+
+// ApplicationAnalyticsQuery holds the parameters for application and region analysis
+type ApplicationAnalyticsQuery struct {
+	RankedAnalyticsQuery
+	PercentileParams
+}
+
+// ToFilters converts the validated query into timeline filters carrying the limit and percentiles
+func (q ApplicationAnalyticsQuery) ToFilters() *services.TimelineFilters {
+	filters := q.RankedAnalyticsQuery.ToFilters()
+	filters.Percentiles = q.ToPercentiles()
+	return filters
+}
+
+// ResolutionOutlierQuery holds the parameters for listing resolution time outliers
+type ResolutionOutlierQuery struct {
+	AnalyticsQuery
+	OutlierParams
+	Limit int `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// NotesQualityQuery holds the parameters for the resolution notes quality report
+type NotesQualityQuery struct {
+	AnalyticsQuery
+	Limit int `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+// TextProfileQuery holds the parameters for the incident description text profile
+type TextProfileQuery struct {
+	AnalyticsQuery
+	Limit int `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+// ExplainQuery holds the parameters for explaining the incident volume of a day or week. The
+// compared periods replace start_date and end_date.
+type ExplainQuery struct {
+	AnalyticsQuery
+	Date            string `form:"date" binding:"required,date"`
+	Period          string `form:"period" binding:"omitempty,oneof=daily weekly"`
+	BaselinePeriods int    `form:"baseline_periods" binding:"omitempty,min=1,max=12"`
+	Limit           int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// ApplicationProfileParams holds the path parameter identifying an application to profile
+type ApplicationProfileParams struct {
+	Name string `uri:"name" binding:"required,max=200"`
+}
+
+// ApplicationProfileQuery holds the parameters for an application profile. The limit caps its
+// automation groups and problems.
+type ApplicationProfileQuery struct {
+	RankedAnalyticsQuery
+	Period string `form:"period" binding:"omitempty,oneof=daily weekly"`
+}
+
+// CapacityQuery holds the parameters for the capacity plan
+type CapacityQuery struct {
+	AnalyticsQuery
+	HistoryWeeks    int     `form:"history_weeks" binding:"omitempty,min=2,max=104"`
+	Weeks           int     `form:"weeks" binding:"omitempty,min=1,max=52"`
+	ProductiveHours float64 `form:"productive_hours" binding:"omitempty,gt=0,lte=168"`
+	HandlingHours   float64 `form:"handling_hours" binding:"omitempty,gt=0"`
+}
+
+// CostQuery holds the parameters for the incident cost summary
+type CostQuery struct {
+	AnalyticsQuery
+	Basis string  `form:"basis" binding:"omitempty,oneof=application group"`
+	Rate  float64 `form:"rate" binding:"omitempty,gt=0"`
+}
+
+// ChargebackQuery holds the parameters for the chargeback report
+type ChargebackQuery struct {
+	AnalyticsQuery
+	Basis  string  `form:"basis" binding:"omitempty,oneof=application group"`
+	Rate   float64 `form:"rate" binding:"omitempty,gt=0"`
+	Format string  `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+// OpsReviewQuery holds the parameters for the weekly ops review pack
+type OpsReviewQuery struct {
+	Week   string `form:"week" binding:"omitempty,date"`
+	Format string `form:"format" binding:"omitempty,oneof=json xlsx"`
+}
+
+// HandoverQuery holds the parameters for the shift handover report. Window is a duration such as
+// 8h; End is an RFC 3339 time and defaults to now.
+type HandoverQuery struct {
+	Window string `form:"window" binding:"omitempty,duration"`
+	End    string `form:"end" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Format string `form:"format" binding:"omitempty,oneof=json text"`
+}
+
+// PaginationQuery holds the shared page/page_size parameters for list endpoints
+type PaginationQuery struct {
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1,max=500"`
+}
+
+// ListQuery holds the pagination parameters of list endpoints in API versions after v1. A
+// cursor from a previous page takes precedence over page.
+type ListQuery struct {
+	Page    int    `form:"page" binding:"omitempty,min=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,min=1,max=500"`
+	Cursor  string `form:"cursor" binding:"omitempty,max=100"`
+}
+
+// IsSet reports whether the caller requested a specific page
+func (p PaginationQuery) IsSet() bool {
+	return p.Page > 0 || p.PageSize > 0
+}
+
+// Limit returns the effective page size
+func (p PaginationQuery) Limit() int {
+	if p.PageSize <= 0 {
+		return DefaultPageSize
+	}
+	return p.PageSize
+}
+
+// Offset returns the number of rows to skip for the requested page
+func (p PaginationQuery) Offset() int {
+	if p.Page <= 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.Limit()
+}
+
+// UploadDiffParams holds the path parameters for comparing two uploads
+type UploadDiffParams struct {
+	ID      string `uri:"id" binding:"required"`
+	OtherID string `uri:"otherId" binding:"required,nefield=ID"`
+}
+
+// UploadFileQuery holds the query parameters for retrieving an upload's original file. With
+// Link set a signed download link valid for ExpiresIn seconds is returned instead of the file.
+type UploadFileQuery struct {
+	Link      bool `form:"link"`
+	ExpiresIn int  `form:"expires_in" binding:"omitempty,min=1,max=86400"`
+}
+
+// SignedFileQuery holds the query parameters of a signed download link
+type SignedFileQuery struct {
+	Expires   string `form:"expires" binding:"required"`
+	Signature string `form:"signature" binding:"required"`
+}
+
+// ApplicationAliasRequest is the body for creating an application alias rule
+type ApplicationAliasRequest struct {
+	Alias         string `json:"alias" binding:"required,max=200"`
+	CanonicalName string `json:"canonical_name" binding:"required,max=200"`
+}
+
+// ApplicationMergeRequest is the body for merging one application name into another
+type ApplicationMergeRequest struct {
+	From string `json:"from" binding:"required,max=200"`
+	To   string `json:"to" binding:"required,max=200"`
+}
+
+// OrgGroupRequest is the body for placing a resolution group in the org hierarchy
+type OrgGroupRequest struct {
+	Department string `json:"department" binding:"required,max=200"`
+	Division   string `json:"division" binding:"required,max=200"`
+}
+
+// BusinessServiceRequest is the body for placing a business service in the service catalog
+type BusinessServiceRequest struct {
+	BusinessUnit string   `json:"business_unit" binding:"required,max=200"`
+	Applications []string `json:"applications" binding:"omitempty,max=100,dive,max=200"`
+	Aliases      []string `json:"aliases" binding:"omitempty,max=100,dive,max=200"`
+}
+
+// BusinessServiceParams holds the path parameter identifying a business service
+type BusinessServiceParams struct {
+	Name string `uri:"name" binding:"required,max=200"`
+}
+
+// CMDBItemsQuery holds the optional entity type filter for listing CMDB items
+type CMDBItemsQuery struct {
+	Type string `form:"type" binding:"omitempty,oneof=application service"`
+}
+
+// CostCenterAssignmentRequest is the body for attributing an application or group to a cost center
+type CostCenterAssignmentRequest struct {
+	EntityType string   `json:"entity_type" binding:"required,oneof=application group"`
+	EntityName string   `json:"entity_name" binding:"required,max=200"`
+	CostCenter string   `json:"cost_center" binding:"required,max=100"`
+	HourlyRate *float64 `json:"hourly_rate" binding:"omitempty,gt=0"`
+}
+
+// CostCenterAssignmentParams holds the path parameters identifying a cost center assignment
+type CostCenterAssignmentParams struct {
+	EntityType string `uri:"type" binding:"required,oneof=application group"`
+	EntityName string `uri:"name" binding:"required"`
+}
+
+// AutomationKeywordRequest is the body for saving or previewing a custom automation keyword
+type AutomationKeywordRequest struct {
+	Keyword string   `json:"keyword" binding:"required,max=100"`
+	Kind    string   `json:"kind" binding:"required,oneof=automation manual"`
+	Weight  *float64 `json:"weight" binding:"required,gte=-1,lte=1"`
+}
+
+// AutomationKeywordParams holds the path parameters identifying a custom automation keyword
+type AutomationKeywordParams struct {
+	Kind    string `uri:"kind" binding:"required,oneof=automation manual"`
+	Keyword string `uri:"keyword" binding:"required"`
+}
+
+// RunbookRequest is the body for creating or replacing a runbook. It needs keywords, a linked
+// IT process group or both.
+type RunbookRequest struct {
+	Name           string   `json:"name" binding:"required,max=200"`
+	Description    string   `json:"description" binding:"omitempty,max=2000"`
+	Keywords       []string `json:"keywords" binding:"omitempty,max=50,dive,max=100"`
+	ITProcessGroup string   `json:"it_process_group" binding:"omitempty,max=200"`
+}
+
+// ToRunbook converts the validated body into a service-level runbook
+func (r RunbookRequest) ToRunbook() services.Runbook {
+	return services.Runbook{
+		Name:           r.Name,
+		Description:    r.Description,
+		Keywords:       r.Keywords,
+		ITProcessGroup: r.ITProcessGroup,
+	}
+}
+
+// RunbookParams holds the path parameter identifying a runbook
+type RunbookParams struct {
+	Name string `uri:"name" binding:"required"`
+}
+
+// AutomationCandidateParams holds the path parameter identifying an automation candidate by its IT process group
+type AutomationCandidateParams struct {
+	ID string `uri:"id" binding:"required,max=200"`
+}
+
+// AutomationCandidateQuery holds the filters and savings assumption for an automation candidate
+type AutomationCandidateQuery struct {
+	AnalyticsQuery
+	HandlingMinutes int `form:"handling_minutes" binding:"omitempty,min=1,max=1440"`
+}
+
+// AutomationTicketRequest is the optional body for creating a Jira issue for an automation candidate
+type AutomationTicketRequest struct {
+	ProjectKey string `json:"project_key" binding:"omitempty,max=50"`
+	IssueType  string `json:"issue_type" binding:"omitempty,max=100"`
+}
+
+// AutomationOpportunityRequest is the body for updating the review status of an automation
+// candidate. Omitted fields keep their value; an empty assignee unassigns the candidate.
+type AutomationOpportunityRequest struct {
+	Status          string  `json:"status" binding:"omitempty,oneof=identified under_review approved implemented rejected"`
+	Assignee        *string `json:"assignee" binding:"omitempty,max=200"`
+	Note            string  `json:"note" binding:"omitempty,max=2000"`
+	HandlingMinutes int     `json:"handling_minutes" binding:"omitempty,min=1,max=1440"`
+}
+
+// AutomationOpportunityQuery holds the filters for listing tracked automation candidates
+type AutomationOpportunityQuery struct {
+	Status   string `form:"status" binding:"omitempty,oneof=identified under_review approved implemented rejected"`
+	Assignee string `form:"assignee" binding:"omitempty,max=200"`
+}
+
+// IncidentParams holds the path parameter identifying an incident record
+type IncidentParams struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// RelatedIncidentsQuery holds the parameters for finding incidents related to one incident
+type RelatedIncidentsQuery struct {
+	Limit      int     `form:"limit" binding:"omitempty,min=1,max=100"`
+	WindowDays int     `form:"window_days" binding:"omitempty,min=1,max=365"`
+	MinScore   float64 `form:"min_score" binding:"omitempty,gt=0,lte=1"`
+}
+
+// ToOptions converts the validated query into service-level related incident options
+func (q RelatedIncidentsQuery) ToOptions() services.RelatedOptions {
+	return services.RelatedOptions{
+		Limit:      q.Limit,
+		WindowDays: q.WindowDays,
+		MinScore:   q.MinScore,
+	}
+}
+
+// IncidentMergeRequest is the body for merging duplicate incidents into a primary incident
+type IncidentMergeRequest struct {
+	PrimaryID    string   `json:"primary_id" binding:"required"`
+	DuplicateIDs []string `json:"duplicate_ids" binding:"required,min=1,max=100"`
+	Reason       string   `json:"reason" binding:"omitempty,max=500"`
+}
+
+// IncidentMergeParams holds the path parameter identifying an incident merge
+type IncidentMergeParams struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// IncidentSampleQuery holds the parameters for drawing a sample of incidents for QA review
+type IncidentSampleQuery struct {
+	AnalyticsQuery
+	Size       int    `form:"size" binding:"omitempty,min=1,max=500"`
+	StratifyBy string `form:"stratify_by" binding:"omitempty,oneof=priority application"`
+	Seed       *int64 `form:"seed" binding:"omitempty,min=0"`
+}
+
+// ToOptions converts the validated query into service-level sample options
+func (q IncidentSampleQuery) ToOptions() services.SampleOptions {
+	return services.SampleOptions{
+		Size:       q.Size,
+		StratifyBy: q.StratifyBy,
+		Seed:       q.Seed,
+	}
+}
+
+// AnalyzerFeedbackRequest is the body for marking an analyzer output correct or incorrect
+type AnalyzerFeedbackRequest struct {
+	Analyzer string `json:"analyzer" binding:"required,oneof=sentiment automation process_group"`
+	Correct  *bool  `json:"correct" binding:"required"`
+	Expected string `json:"expected" binding:"omitempty,max=100"`
+	Comment  string `json:"comment" binding:"omitempty,max=1000"`
+}
+
+// AccuracyQuery holds the parameters for the analyzer accuracy report
+type AccuracyQuery struct {
+	AnalyticsQuery
+	Analyzer string `form:"analyzer" binding:"omitempty,oneof=sentiment automation process_group"`
+}
+
+// ShadowConfigRequest is the body for creating a shadow analyzer configuration
+type ShadowConfigRequest struct {
+	Name               string                     `json:"name" binding:"required,max=200"`
+	SentimentPhrases   map[string]float64         `json:"sentiment_phrases" binding:"omitempty,dive,keys,max=200,endkeys,gte=-1,lte=1"`
+	AutomationKeywords []AutomationKeywordRequest `json:"automation_keywords" binding:"omitempty,dive"`
+	Active             bool                       `json:"active"`
+}
+
+// ShadowConfigParams holds the path parameter identifying a shadow configuration
+type ShadowConfigParams struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// ProcessUploadRequest is the optional body for starting upload processing. Omitted fields
+// fall back to models.DefaultProcessingOptions.
+type ProcessUploadRequest struct {
+	MappingProfile string   `json:"mapping_profile" binding:"omitempty,max=200"`
+	RuleSet        string   `json:"rule_set" binding:"omitempty,max=200"`
+	DedupStrategy  string   `json:"dedup_strategy" binding:"omitempty,oneof=first last fail"`
+	ErrorThreshold *float64 `json:"error_threshold" binding:"omitempty,gte=0,lte=100"`
+	RunSentiment   *bool    `json:"run_sentiment"`
+	RunAutomation  *bool    `json:"run_automation"`
+	InferPriority  bool     `json:"infer_priority"`
+	Timezone       string   `json:"timezone" binding:"omitempty,timezone"`
+	Sheet          string   `json:"sheet" binding:"omitempty,max=31"`
+	DryRun         bool     `json:"dry_run"`
+	Urgent         bool     `json:"urgent"`
+}
+
+// ToOptions converts the validated request into processing options
+func (r ProcessUploadRequest) ToOptions() models.ProcessingOptions {
+	options := models.DefaultProcessingOptions()
+	options.MappingProfile = r.MappingProfile
+	options.RuleSet = r.RuleSet
+	options.Timezone = r.Timezone
+	options.Sheet = r.Sheet
+	options.DryRun = r.DryRun
+	options.Urgent = r.Urgent
+	options.InferPriority = r.InferPriority
+	if r.DedupStrategy != "" {
+		options.DedupStrategy = r.DedupStrategy
+	}
+	if r.ErrorThreshold != nil {
+		options.ErrorThreshold = *r.ErrorThreshold
+	}
+	if r.RunSentiment != nil {
+		options.RunSentiment = *r.RunSentiment
+	}
+	if r.RunAutomation != nil {
+		options.RunAutomation = *r.RunAutomation
+	}
+	return options
+}
+
+// MappingProfileRequest is the body for creating or replacing a column mapping profile
+type MappingProfileRequest struct {
+	Name            string              `json:"name" binding:"required,max=200"`
+	Description     string              `json:"description" binding:"omitempty,max=500"`
+	Columns         map[string][]string `json:"columns" binding:"required,min=1"`
+	DateFormats     map[string]string   `json:"date_formats"`
+	PendingStatuses []string            `json:"pending_statuses" binding:"omitempty,max=50,dive,max=100"`
+}
+
+// MappingProfileParams holds the path parameter identifying a mapping profile
+type MappingProfileParams struct {
+	Name string `uri:"name" binding:"required"`
+}
+
+// ValidationRuleSetRequest is the body for creating or replacing a validation rule set
+type ValidationRuleSetRequest struct {
+	Name        string                    `json:"name" binding:"required,max=200"`
+	Description string                    `json:"description" binding:"omitempty,max=500"`
+	Rules       []services.ValidationRule `json:"rules" binding:"required,min=1,max=200"`
+}
+
+// ValidationRuleSetParams holds the path parameter identifying a validation rule set
+type ValidationRuleSetParams struct {
+	Name string `uri:"name" binding:"required"`
+}
+
+// DatasetRequest is the body for creating a dataset
+type DatasetRequest struct {
+	Name        string `json:"name" binding:"required,max=200"`
+	Description string `json:"description" binding:"omitempty,max=500"`
+}
+
+// DatasetParams holds the path parameter identifying a dataset
+type DatasetParams struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// SheetSourceRequest is the body for connecting a Google Sheet. Credentials is the service
+// account key file as issued by Google; a sync interval of 0 imports only on demand.
+type SheetSourceRequest struct {
+	Name                string          `json:"name" binding:"required,max=200"`
+	URL                 string          `json:"url" binding:"required,url,max=500"`
+	SheetRange          string          `json:"sheet_range" binding:"omitempty,max=200"`
+	Credentials         json.RawMessage `json:"credentials" binding:"required"`
+	MappingProfile      string          `json:"mapping_profile" binding:"omitempty,max=200"`
+	SyncIntervalMinutes int             `json:"sync_interval_minutes" binding:"omitempty,min=5,max=10080"`
+}
+
+// ToInput converts the request into the service's sheet source input
+func (r SheetSourceRequest) ToInput() services.SheetSourceInput {
+	return services.SheetSourceInput{
+		Name:                r.Name,
+		URL:                 r.URL,
+		SheetRange:          r.SheetRange,
+		Credentials:         r.Credentials,
+		MappingProfile:      r.MappingProfile,
+		SyncIntervalMinutes: r.SyncIntervalMinutes,
+	}
+}
+
+// SheetSourceParams holds the path parameter identifying a sheet source
+type SheetSourceParams struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// ArchiveRequest is the body for moving old incidents into the archive
+type ArchiveRequest struct {
+	Before string `json:"before" binding:"required,date"`
+}
+
+// IncidentExportQuery holds the parameters for exporting incident data
+type IncidentExportQuery struct {
+	AnalyticsQuery
+	Format string `form:"format" binding:"required,oneof=parquet"`
+}
+
+// ExportParams holds the path parameter identifying an export job
+type ExportParams struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// RecategorizeQuery holds the incidents an IT process re-categorization covers. A dry run only
+// counts the incidents that would move.
+type RecategorizeQuery struct {
+	AnalyticsQuery
+	DryRun bool `form:"dry_run"`
+}
+
+// RecategorizeParams holds the path parameter identifying a re-categorization job
+type RecategorizeParams struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// RebuildParams holds the path parameter identifying a derived artifact rebuild job
+type RebuildParams struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// BodyLoggingRequest changes request and response body logging. Omitted fields keep their
+// current values.
+type BodyLoggingRequest struct {
+	Enabled      *bool    `json:"enabled" binding:"required"`
+	Routes       []string `json:"routes" binding:"omitempty,max=50,dive,required,startswith=/"`
+	MaxBodyBytes *int     `json:"max_body_bytes" binding:"omitempty,min=0,max=1048576"`
+}
+
+// UsageQuery holds the parameters for the API usage summary
+type UsageQuery struct {
+	Days  int `form:"days" binding:"omitempty,min=1,max=365"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// ErrorAggregateQuery holds the parameters for aggregating API errors. Route is a method and
+// route pattern, such as "GET /api/v2/analytics/priority".
+type ErrorAggregateQuery struct {
+	Window    string `form:"window" binding:"omitempty,oneof=1h 6h 24h 7d"`
+	Code      string `form:"code" binding:"omitempty,max=100"`
+	Component string `form:"component" binding:"omitempty,max=100"`
+	Route     string `form:"route" binding:"omitempty,max=500"`
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+// MetricsHistoryQuery holds the parameters for the monitoring metrics history. Start and End
+// are RFC 3339 times; the last 7 days are returned by hour without them.
+type MetricsHistoryQuery struct {
+	Start    string `form:"start" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	End      string `form:"end" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Interval string `form:"interval" binding:"omitempty,oneof=raw hour day"`
+}
+
+// IndexMigrationRequest is the body for generating a migration of suggested indexes, by name;
+// every current suggestion is included without names
+type IndexMigrationRequest struct {
+	Indexes []string `json:"indexes" binding:"omitempty,max=50,dive,required,max=200"`
+	Apply   bool     `json:"apply"`
+}
+
+// DataQualityAlertQuery holds the filters for listing data-quality alerts
+type DataQualityAlertQuery struct {
+	UploadID string `form:"upload_id"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+// SystemConfigRequest is the body for changing system settings, keyed by setting name
+type SystemConfigRequest struct {
+	Settings map[string]interface{} `json:"settings" binding:"required,min=1"`
+}
+
+// ConfigAuditQuery holds the parameters for listing setting changes
+type ConfigAuditQuery struct {
+	Key   string `form:"key"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+// FeatureFlagParams holds the path parameter identifying a feature flag
+type FeatureFlagParams struct {
+	Key string `uri:"key" binding:"required"`
+}
+
+// FlagOverrideParams holds the path parameters identifying a tenant's feature flag override
+type FlagOverrideParams struct {
+	Key    string `uri:"key" binding:"required"`
+	Tenant string `uri:"tenant" binding:"required,max=200"`
+}
+
+// FeatureFlagRequest is the body for switching a feature flag
+type FeatureFlagRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// IncidentBatchQuery holds the source and processing options of a pushed incident batch.
+// Omitted options fall back to models.DefaultProcessingOptions.
+type IncidentBatchQuery struct {
+	Source         string   `form:"source" binding:"omitempty,max=100"`
+	RuleSet        string   `form:"rule_set" binding:"omitempty,max=200"`
+	DedupStrategy  string   `form:"dedup_strategy" binding:"omitempty,oneof=first last fail"`
+	ErrorThreshold *float64 `form:"error_threshold" binding:"omitempty,gte=0,lte=100"`
+	RunSentiment   *bool    `form:"run_sentiment"`
+	RunAutomation  *bool    `form:"run_automation"`
+	InferPriority  bool     `form:"infer_priority"`
+	Timezone       string   `form:"timezone" binding:"omitempty,timezone"`
+}
+
+// ToOptions converts the validated query into processing options
+func (q IncidentBatchQuery) ToOptions() models.ProcessingOptions {
+	return ProcessUploadRequest{
+		RuleSet:        q.RuleSet,
+		DedupStrategy:  q.DedupStrategy,
+		ErrorThreshold: q.ErrorThreshold,
+		RunSentiment:   q.RunSentiment,
+		RunAutomation:  q.RunAutomation,
+		InferPriority:  q.InferPriority,
+		Timezone:       q.Timezone,
+	}.ToOptions()
+}
+
+// IncidentCreateRequest is the body for entering a single incident. Omitted incident IDs are
+// generated and an omitted report date is the time of entry.
+type IncidentCreateRequest struct {
+	IncidentID       string     `json:"incident_id" binding:"omitempty,max=100"`
+	ReportDate       *time.Time `json:"report_date"`
+	ResolveDate      *time.Time `json:"resolve_date"`
+	BriefDescription string     `json:"brief_description" binding:"required,max=500"`
+	Description      string     `json:"description" binding:"max=10000"`
+	ApplicationName  string     `json:"application_name" binding:"required,max=200"`
+	ResolutionGroup  string     `json:"resolution_group" binding:"required,max=200"`
+	ResolvedPerson   string     `json:"resolved_person" binding:"max=200"`
+	Priority         string     `json:"priority" binding:"required,oneof=P1 P2 P3 P4"`
+	Status           string     `json:"status" binding:"max=50"`
+	Category         string     `json:"category" binding:"max=200"`
+	Subcategory      string     `json:"subcategory" binding:"max=200"`
+	Impact           string     `json:"impact" binding:"max=50"`
+	Urgency          string     `json:"urgency" binding:"max=50"`
+	CustomerAffected string     `json:"customer_affected" binding:"max=50"`
+	BusinessService  string     `json:"business_service" binding:"max=200"`
+	RootCause        string     `json:"root_cause" binding:"max=2000"`
+	ResolutionNotes  string     `json:"resolution_notes" binding:"max=10000"`
+	Cost             *float64   `json:"cost" binding:"omitempty,gte=0"`
+	Source           string     `json:"source" binding:"max=200"`
+	ClosureCode      string     `json:"closure_code" binding:"max=200"`
+	Region           string     `json:"region" binding:"max=100"`
+}
+
+// ToIncident converts the validated request into an incident
+func (r IncidentCreateRequest) ToIncident() models.Incident {
+	incident := models.Incident{
+		IncidentID:       strings.TrimSpace(r.IncidentID),
+		BriefDescription: r.BriefDescription,
+		Description:      r.Description,
+		ApplicationName:  r.ApplicationName,
+		ResolutionGroup:  r.ResolutionGroup,
+		ResolvedPerson:   r.ResolvedPerson,
+		Priority:         r.Priority,
+		Status:           r.Status,
+		Category:         r.Category,
+		Subcategory:      r.Subcategory,
+		Impact:           r.Impact,
+		Urgency:          r.Urgency,
+		CustomerAffected: r.CustomerAffected,
+		BusinessService:  r.BusinessService,
+		RootCause:        r.RootCause,
+		ResolutionNotes:  r.ResolutionNotes,
+		Cost:             r.Cost,
+		Source:           strings.TrimSpace(r.Source),
+		ClosureCode:      strings.TrimSpace(r.ClosureCode),
+		Region:           strings.TrimSpace(r.Region),
+	}
+	if r.ReportDate != nil {
+		incident.ReportDate = r.ReportDate.UTC()
+	}
+	if r.ResolveDate != nil {
+		resolved := r.ResolveDate.UTC()
+		incident.ResolveDate = &resolved
+	}
+	return incident
+}
+
+// IncidentCreateQuery holds the validation and analysis options for entering a single incident
+type IncidentCreateQuery struct {
+	RuleSet       string `form:"rule_set" binding:"omitempty,max=200"`
+	BudgetMs      int    `form:"budget_ms" binding:"omitempty,min=1,max=30000"`
+	RunSentiment  *bool  `form:"run_sentiment"`
+	RunAutomation *bool  `form:"run_automation"`
+}
+
+// ToOptions converts the validated query into manual entry options
+func (q IncidentCreateQuery) ToOptions() services.ManualEntryOptions {
+	options := services.DefaultManualEntryOptions()
+	options.RuleSet = q.RuleSet
+	if q.BudgetMs > 0 {
+		options.Budget = time.Duration(q.BudgetMs) * time.Millisecond
+	}
+	if q.RunSentiment != nil {
+		options.RunSentiment = *q.RunSentiment
+	}
+	if q.RunAutomation != nil {
+		options.RunAutomation = *q.RunAutomation
+	}
+	return options
+}
+
+// MaintenanceWindowQuery holds the filters for listing maintenance windows
+type MaintenanceWindowQuery struct {
+	Application string `form:"application" binding:"omitempty,max=200"`
+	From        string `form:"from" binding:"omitempty,date"`
+	To          string `form:"to" binding:"omitempty,date"`
+}
+
+// MaintenanceWindowRequest is the body for creating or replacing a maintenance window. Times
+// are RFC 3339 timestamps; an empty application_name covers every application.
+type MaintenanceWindowRequest struct {
+	ApplicationName string    `json:"application_name" binding:"omitempty,max=200"`
+	Title           string    `json:"title" binding:"required,max=200"`
+	Description     string    `json:"description" binding:"omitempty,max=2000"`
+	StartsAt        time.Time `json:"starts_at" binding:"required"`
+	EndsAt          time.Time `json:"ends_at" binding:"required"`
+}
+
+// ToWindow converts the validated body into a service-level maintenance window
+func (r MaintenanceWindowRequest) ToWindow() services.MaintenanceWindow {
+	return services.MaintenanceWindow{
+		ApplicationName: r.ApplicationName,
+		Title:           r.Title,
+		Description:     r.Description,
+		StartsAt:        r.StartsAt,
+		EndsAt:          r.EndsAt,
+	}
+}
+
+// MaintenanceWindowParams holds the path parameter identifying a maintenance window
+type MaintenanceWindowParams struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// GoalQuery holds the filters for listing goals
+type GoalQuery struct {
+	Quarter string `form:"quarter" binding:"omitempty,len=7"`
+	Team    string `form:"team" binding:"omitempty,max=200"`
+}
+
+// GoalProgressQuery holds the filters for goal progress, computed as of a date (default today)
+type GoalProgressQuery struct {
+	GoalQuery
+	AsOf string `form:"as_of" binding:"omitempty,date"`
+}
+
+// GoalRequest is the body for creating a goal. A change target is the percent change against
+// the previous quarter, so {"comparison":"at_most","target_type":"change","target":-20} asks for
+// 20% fewer incidents than last quarter.
+type GoalRequest struct {
+	Name         string   `json:"name" binding:"required,max=200"`
+	Team         string   `json:"team" binding:"omitempty,max=200"`
+	Metric       string   `json:"metric" binding:"required,oneof=incident_count p1_count resolution_rate avg_resolution_time median_resolution_time"`
+	Comparison   string   `json:"comparison" binding:"required,oneof=at_least at_most"`
+	TargetType   string   `json:"target_type" binding:"omitempty,oneof=absolute change"`
+	Target       float64  `json:"target"`
+	Quarter      string   `json:"quarter" binding:"required,len=7"`
+	Applications []string `json:"applications" binding:"omitempty,max=100,dive,max=200"`
+}
+
+// ToGoal converts the validated body into a service-level goal
+func (r GoalRequest) ToGoal() services.Goal {
+	return services.Goal{
+		Name:         r.Name,
+		Team:         r.Team,
+		Metric:       r.Metric,
+		Comparison:   r.Comparison,
+		TargetType:   r.TargetType,
+		Target:       r.Target,
+		Quarter:      r.Quarter,
+		Applications: r.Applications,
+	}
+}
+
+// GoalParams holds the path parameter identifying a goal
+type GoalParams struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// WatchlistRequest is the body for creating or replacing a watchlist. Thresholds are the percent
+// increase over the baseline that raises an alert; at least one of them is required.
+type WatchlistRequest struct {
+	Name                  string   `json:"name" binding:"required,max=200"`
+	EntityType            string   `json:"entity_type" binding:"required,oneof=application group"`
+	Entities              []string `json:"entities" binding:"required,min=1,max=50,dive,max=200"`
+	VolumeIncreasePct     *float64 `json:"volume_increase_pct" binding:"omitempty,gt=0,max=10000"`
+	ResolutionIncreasePct *float64 `json:"resolution_increase_pct" binding:"omitempty,gt=0,max=10000"`
+	PeriodDays            int      `json:"period_days" binding:"omitempty,min=1,max=90"`
+	BaselinePeriods       int      `json:"baseline_periods" binding:"omitempty,min=1,max=12"`
+	MinIncidents          int      `json:"min_incidents" binding:"omitempty,min=0,max=100000"`
+	Email                 string   `json:"email" binding:"omitempty,email,max=320"`
+	SlackWebhookURL       string   `json:"slack_webhook_url" binding:"omitempty,url,max=500"`
+}
+
+// ToWatchlist converts the validated body into a service-level watchlist
+func (r WatchlistRequest) ToWatchlist() services.Watchlist {
+	return services.Watchlist{
+		Name:                  r.Name,
+		EntityType:            r.EntityType,
+		Entities:              r.Entities,
+		VolumeIncreasePct:     r.VolumeIncreasePct,
+		ResolutionIncreasePct: r.ResolutionIncreasePct,
+		PeriodDays:            r.PeriodDays,
+		BaselinePeriods:       r.BaselinePeriods,
+		MinIncidents:          r.MinIncidents,
+		Email:                 r.Email,
+		SlackWebhookURL:       r.SlackWebhookURL,
+	}
+}
+
+// WatchlistParams holds the path parameter identifying a watchlist
+type WatchlistParams struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// NotificationPreferencesRequest is the body for replacing the calling user's notification
+// preferences. Omitted events or channels mean all of them; an empty list turns them all off.
+// Quiet hours are set together, as times of day such as 22:00.
+type NotificationPreferencesRequest struct {
+	Events          []string `json:"events" binding:"omitempty,max=20,dive,oneof=watchlist.volume watchlist.resolution_time"`
+	Channels        []string `json:"channels" binding:"omitempty,max=10,dive,oneof=email slack"`
+	Delivery        string   `json:"delivery" binding:"omitempty,oneof=immediate digest"`
+	DigestHour      *int     `json:"digest_hour" binding:"omitempty,min=0,max=23"`
+	QuietHoursStart string   `json:"quiet_hours_start" binding:"omitempty,max=5"`
+	QuietHoursEnd   string   `json:"quiet_hours_end" binding:"omitempty,max=5"`
+	Timezone        string   `json:"timezone" binding:"omitempty,timezone"`
+}
+
+// ToPreferences converts the validated body into service-level notification preferences
+func (r NotificationPreferencesRequest) ToPreferences() services.NotificationPreferences {
+	preferences := *services.DefaultNotificationPreferences("")
+	if r.Events != nil {
+		preferences.Events = r.Events
+	}
+	if r.Channels != nil {
+		preferences.Channels = r.Channels
+	}
+	if r.Delivery != "" {
+		preferences.Delivery = r.Delivery
+	}
+	if r.DigestHour != nil {
+		preferences.DigestHour = *r.DigestHour
+	}
+	if r.Timezone != "" {
+		preferences.Timezone = r.Timezone
+	}
+	preferences.QuietHoursStart = r.QuietHoursStart
+	preferences.QuietHoursEnd = r.QuietHoursEnd
+	return preferences
+}
+
+// HolidayQuery holds the filters for listing holidays
+type HolidayQuery struct {
+	Region string `form:"region" binding:"omitempty,max=50"`
+	Year   int    `form:"year" binding:"omitempty,min=1900,max=2200"`
+}
+
+// HolidayEntry is one holiday in a HolidayRequest
+type HolidayEntry struct {
+	Date string `json:"date" binding:"required,date"`
+	Name string `json:"name" binding:"required,max=200"`
+}
+
+// HolidayRequest is the body for adding holidays to a region's calendar
+type HolidayRequest struct {
+	Region   string         `json:"region" binding:"required,max=50"`
+	Holidays []HolidayEntry `json:"holidays" binding:"required,min=1,max=366,dive"`
+}
+
+// HolidayParams holds the path parameters identifying a holiday
+type HolidayParams struct {
+	Region string `uri:"region" binding:"required,max=50"`
+	Date   string `uri:"date" binding:"required,date"`
+}
+
+// DataScopeParams holds the path parameter identifying a user's data scope
+type DataScopeParams struct {
+	User string `uri:"user" binding:"required,max=200"`
+}
+
+// DataScopeRequest is the body for replacing a user's data scope. The user sees the incidents of
+// the listed applications and of the listed resolution groups; at least one entry is required.
+type DataScopeRequest struct {
+	Applications []string `json:"applications" binding:"omitempty,max=500,dive,required,max=200"`
+	Groups       []string `json:"groups" binding:"omitempty,max=500,dive,required,max=200"`
+}
+
+// APIKeyParams holds the path parameter identifying an API key
+type APIKeyParams struct {
+	ID string `uri:"id" binding:"required,max=100"`
+}
+
+// APIKeyRequest is the body for creating an observer API key. Endpoints are analytics routes
+// below /analytics, such as timeline/daily. The key never expires without TTLDays.
+type APIKeyRequest struct {
+	Name      string   `json:"name" binding:"required,max=200"`
+	Endpoints []string `json:"endpoints" binding:"required,min=1,max=50,dive,required,max=200"`
+	StartDate string   `json:"start_date" binding:"omitempty,date"`
+	EndDate   string   `json:"end_date" binding:"omitempty,date"`
+	TTLDays   int      `json:"ttl_days" binding:"omitempty,min=1,max=3650"`
+}
+
+// SnapshotParams holds the path parameter identifying a report snapshot
+type SnapshotParams struct {
+	ID string `uri:"id" binding:"required,max=100"`
+}
+
+// SharedSnapshotParams holds the path parameter of a snapshot's share link
+type SharedSnapshotParams struct {
+	Token string `uri:"token" binding:"required,max=200"`
+}
+
+// SnapshotRequest is the body for snapshotting an analytics view, one of services.SnapshotViews;
+// the view's filters are sent as query parameters. The link expires after 7 days without TTLDays.
+type SnapshotRequest struct {
+	Name    string `json:"name" binding:"required,max=200"`
+	View    string `json:"view" binding:"required,max=100"`
+	TTLDays int    `json:"ttl_days" binding:"omitempty,min=1,max=90"`
+}
+
+// SSOLoginQuery holds where to send the user after signing in
+type SSOLoginQuery struct {
+	ReturnTo string `form:"return_to" binding:"omitempty,max=2000"`
+}
+
+// SSOCallbackQuery holds the parameters the identity provider calls back with. The provider
+// sends error instead of code when the user did not sign in.
+type SSOCallbackQuery struct {
+	State            string `form:"state" binding:"required,max=200"`
+	Code             string `form:"code" binding:"omitempty,max=4096"`
+	Error            string `form:"error" binding:"omitempty,max=200"`
+	ErrorDescription string `form:"error_description" binding:"omitempty,max=2000"`
+}
+
+// SandboxReclassification overrides a field of one incident, or of every incident with the
+// from value, inside a sandbox
+type SandboxReclassification struct {
+	Field      string `json:"field" binding:"required,oneof=priority application_name resolution_group category status"`
+	IncidentID string `json:"incident_id" binding:"omitempty,max=100"`
+	From       string `json:"from" binding:"omitempty,max=200"`
+	To         string `json:"to" binding:"required,max=200"`
+}
+
+// SandboxRequest is the body for creating or replacing an analysis sandbox. TTLMinutes defaults to
+// four hours and is capped at a day.
+type SandboxRequest struct {
+	Name                 string                    `json:"name" binding:"omitempty,max=200"`
+	TTLMinutes           int                       `json:"ttl_minutes" binding:"omitempty,min=1,max=1440"`
+	ExcludedApplications []string                  `json:"excluded_applications" binding:"omitempty,max=500,dive,required,max=200"`
+	ExcludedGroups       []string                  `json:"excluded_groups" binding:"omitempty,max=500,dive,required,max=200"`
+	ExcludedIncidents    []string                  `json:"excluded_incidents" binding:"omitempty,max=5000,dive,required,max=100"`
+	Reclassifications    []SandboxReclassification `json:"reclassifications" binding:"omitempty,max=500,dive"`
+}
+
+// ToOverlay converts the request to the overlay applied inside the sandbox
+func (r SandboxRequest) ToOverlay() services.SandboxOverlay {
+	overlay := services.SandboxOverlay{
+		ExcludedApplications: r.ExcludedApplications,
+		ExcludedGroups:       r.ExcludedGroups,
+		ExcludedIncidents:    r.ExcludedIncidents,
+	}
+	for _, rc := range r.Reclassifications {
+		overlay.Reclassifications = append(overlay.Reclassifications, services.Reclassification{
+			Field:      rc.Field,
+			IncidentID: rc.IncidentID,
+			From:       rc.From,
+			To:         rc.To,
+		})
+	}
+	return overlay
+}
+
+// AutomationAssumptionRequest selects the automation candidates a scenario automates and how
+// often their automation is expected to succeed
+type AutomationAssumptionRequest struct {
+	ITProcessGroup string   `json:"it_process_group" binding:"omitempty,max=200"`
+	Applications   []string `json:"applications" binding:"omitempty,max=500,dive,required,max=200"`
+	Priorities     []string `json:"priorities" binding:"omitempty,dive,oneof=P1 P2 P3 P4"`
+	MinScore       float64  `json:"min_score" binding:"gte=0,lte=1"`
+	SuccessRate    float64  `json:"success_rate" binding:"required,gt=0,lte=1"`
+}
+
+// AutomationScenarioRequest is the body for projecting an automation rollout over the incidents
+// matching the filters
+type AutomationScenarioRequest struct {
+	StartDate    string                        `json:"start_date" binding:"omitempty,date"`
+	EndDate      string                        `json:"end_date" binding:"omitempty,date"`
+	Applications []string                      `json:"applications" binding:"omitempty,max=500,dive,required,max=200"`
+	Priorities   []string                      `json:"priorities" binding:"omitempty,dive,oneof=P1 P2 P3 P4"`
+	Statuses     []string                      `json:"statuses" binding:"omitempty,max=50,dive,required,max=100"`
+	DatasetID    string                        `json:"dataset_id" binding:"omitempty,max=200"`
+	Assumptions  []AutomationAssumptionRequest `json:"assumptions" binding:"required,min=1,max=50,dive"`
+}
+
+// ToFilters converts the validated body into service-level timeline filters
+func (r AutomationScenarioRequest) ToFilters() *services.TimelineFilters {
+	return &services.TimelineFilters{
+		StartDate:    parseDateParam(r.StartDate),
+		EndDate:      parseDateParam(r.EndDate),
+		Priorities:   r.Priorities,
+		Applications: r.Applications,
+		Statuses:     r.Statuses,
+		DatasetID:    r.DatasetID,
+	}
+}
+
+// ToAssumptions converts the validated body into the scenario's assumptions
+func (r AutomationScenarioRequest) ToAssumptions() []services.AutomationAssumption {
+	assumptions := make([]services.AutomationAssumption, len(r.Assumptions))
+	for i, a := range r.Assumptions {
+		assumptions[i] = services.AutomationAssumption{
+			ITProcessGroup: a.ITProcessGroup,
+			Applications:   a.Applications,
+			Priorities:     a.Priorities,
+			MinScore:       a.MinScore,
+			SuccessRate:    a.SuccessRate,
+		}
+	}
+	return assumptions
+}
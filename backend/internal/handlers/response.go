@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"incident-management-system/internal/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cursorPrefix starts every decoded list cursor, so stray values are rejected
+const cursorPrefix = "offset:"
+
+// NDJSONContentType is the media type of newline-delimited JSON. List requests after v1
+// accepting it get their items one JSON object per line instead of the envelope.
+const NDJSONContentType = "application/x-ndjson"
+
+// ndjsonFlushEvery is the number of lines written between flushes of a stream to the client
+const ndjsonFlushEvery = 100
+
+// Envelope is the body of responses in API versions after v1: the result in data, list
+// metadata in meta and, when the request failed, the errors
+type Envelope struct {
+	Data   interface{}        `json:"data"`
+	Meta   *ResponseMeta      `json:"meta,omitempty"`
+	Errors []*errors.APIError `json:"errors,omitempty"`
+}
+
+// ResponseMeta describes a list response: which page of the items data holds and the filters
+// they were selected with. NextCursor is null on the last page.
+type ResponseMeta struct {
+	Total      int         `json:"total"`
+	Page       int         `json:"page"`
+	PerPage    int         `json:"per_page"`
+	NextCursor *string     `json:"next_cursor"`
+	Filters    interface{} `json:"filters,omitempty"`
+}
+
+// listWindow returns the offset and size of the page a list request asks for, with size 0 when
+// it asks for no page and gets every item. It sends a 400 and returns false on a bad cursor.
+func listWindow(c *gin.Context) (int, int, bool) {
+	var query ListQuery
+	if !bindQuery(c, &query) {
+		return 0, 0, false
+	}
+	if query.Page == 0 && query.PerPage == 0 && query.Cursor == "" {
+		return 0, 0, true
+	}
+
+	limit := query.PerPage
+	if limit == 0 {
+		limit = DefaultPageSize
+	}
+	if query.Cursor == "" {
+		return (max(query.Page, 1) - 1) * limit, limit, true
+	}
+
+	offset, ok := decodeCursor(query.Cursor)
+	if !ok {
+		errors.SendError(c, errors.BadRequest("Invalid cursor").
+			WithUserMessage("The page link has expired or is malformed; start again from the first page"))
+		return 0, 0, false
+	}
+	return offset, limit, true
+}
+
+// listMeta describes the count items at offset of a list of total items, paged by limit
+func listMeta(total, offset, limit, count int) *ResponseMeta {
+	meta := &ResponseMeta{Total: total, Page: 1, PerPage: count}
+	if limit > 0 {
+		meta.Page = offset/limit + 1
+		meta.PerPage = limit
+		if next := offset + count; count > 0 && next < total {
+			cursor := encodeCursor(next)
+			meta.NextCursor = &cursor
+		}
+	}
+	return meta
+}
+
+// sendList sends the items of a list endpoint. v1 requests get legacy, the body the endpoint had
+// before versioning. Later versions get the envelope, with the requested page of items and the
+// filters, which may be nil, in meta.
+func sendList(c *gin.Context, items interface{}, filters interface{}, legacy gin.H) {
+	if RequestAPIVersion(c) == APIVersion1 {
+		c.JSON(http.StatusOK, legacy)
+		return
+	}
+
+	offset, limit, ok := listWindow(c)
+	if !ok {
+		return
+	}
+
+	list := reflect.ValueOf(items)
+	if list.Kind() != reflect.Slice {
+		list = reflect.ValueOf([]interface{}{})
+	}
+	total := list.Len()
+	start, end := min(offset, total), total
+	if limit > 0 {
+		end = min(start+limit, total)
+	}
+	page := reflect.MakeSlice(list.Type(), 0, end-start)
+	page = reflect.AppendSlice(page, list.Slice(start, end))
+
+	if wantsNDJSON(c) {
+		stream := newNDJSONStream(c)
+		for i := 0; i < page.Len(); i++ {
+			if stream.Write(page.Index(i).Interface()) != nil {
+				return
+			}
+		}
+		stream.Close(nil)
+		return
+	}
+
+	meta := listMeta(total, start, limit, end-start)
+	meta.Filters = filters
+	c.JSON(http.StatusOK, Envelope{Data: page.Interface(), Meta: meta})
+}
+
+// wantsNDJSON reports whether a list request asks for newline-delimited JSON. v1 requests
+// always get their legacy body.
+func wantsNDJSON(c *gin.Context) bool {
+	return RequestAPIVersion(c) != APIVersion1 && strings.Contains(c.GetHeader("Accept"), NDJSONContentType)
+}
+
+// ndjsonStream writes the items of a list response one JSON object per line as they are
+// produced, so clients can render them progressively
+type ndjsonStream struct {
+	c       *gin.Context
+	encoder *json.Encoder
+	lines   int
+}
+
+// newNDJSONStream starts a stream of items. Nothing is sent before the first item, so a
+// failure before it still gets a normal error response.
+func newNDJSONStream(c *gin.Context) *ndjsonStream {
+	return &ndjsonStream{c: c, encoder: json.NewEncoder(c.Writer)}
+}
+
+// Write sends item as the next line, returning an error when the client is gone
+func (s *ndjsonStream) Write(item interface{}) error {
+	if s.lines == 0 {
+		s.c.Header("Content-Type", NDJSONContentType)
+		s.c.Status(http.StatusOK)
+	}
+	if err := s.encoder.Encode(item); err != nil {
+		return err
+	}
+	s.lines++
+	if s.lines%ndjsonFlushEvery == 0 {
+		s.c.Writer.Flush()
+	}
+	return nil
+}
+
+// Close ends the stream. A failure before the first item is sent as an error response; once
+// items were sent, it is sent as a last line holding the envelope's errors,
+// {"data": null, "errors": [error]}, so clients must check the last line.
+func (s *ndjsonStream) Close(apiErr *errors.APIError) {
+	if apiErr != nil && s.lines == 0 {
+		errors.SendError(s.c, apiErr)
+		return
+	}
+	if s.lines == 0 {
+		s.c.Header("Content-Type", NDJSONContentType)
+		s.c.Status(http.StatusOK)
+	}
+	if apiErr != nil {
+		apiErr.WithRequestID(s.c.GetString("request_id")).WithPath(s.c.Request.URL.Path).WithMethod(s.c.Request.Method)
+		s.encoder.Encode(Envelope{Errors: []*errors.APIError{apiErr}})
+	}
+	s.c.Writer.Flush()
+}
+
+// sendPage sends a page of a list endpoint that pages in its query, described by meta. v1
+// requests get legacy, the body the endpoint had before versioning, and need no meta.
+func sendPage(c *gin.Context, items interface{}, meta *ResponseMeta, legacy gin.H) {
+	if RequestAPIVersion(c) == APIVersion1 {
+		c.JSON(http.StatusOK, legacy)
+		return
+	}
+	if list := reflect.ValueOf(items); list.Kind() == reflect.Slice && list.IsNil() {
+		items = reflect.MakeSlice(list.Type(), 0, 0).Interface()
+	}
+	c.JSON(http.StatusOK, Envelope{Data: items, Meta: meta})
+}
+
+// encodeCursor returns the opaque cursor of the list item at offset
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset of a cursor from encodeCursor
+func decodeCursor(cursor string) (int, bool) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(decoded), cursorPrefix) {
+		return 0, false
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(decoded), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, false
+	}
+	return offset, true
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	items := []string{"a", "b", "c", "d", "e"}
+	list := func(c *gin.Context) {
+		sendList(c, items, gin.H{"priorities": []string{"P1"}}, gin.H{"data": items, "count": len(items)})
+	}
+	router := gin.New()
+	router.Group("/api/v1", APIVersion(APIVersion1)).GET("/items", list)
+	v2 := router.Group("/api/v2", APIVersion(APIVersion2))
+	v2.GET("/items", list)
+	v2.GET("/missing", func(c *gin.Context) { errors.SendError(c, errors.NotFound("Item")) })
+
+	type envelope struct {
+		Data []string `json:"data"`
+		Meta struct {
+			Total      int                    `json:"total"`
+			Page       int                    `json:"page"`
+			PerPage    int                    `json:"per_page"`
+			NextCursor *string                `json:"next_cursor"`
+			Filters    map[string]interface{} `json:"filters"`
+		} `json:"meta"`
+		Errors []errors.APIError `json:"errors"`
+	}
+	get := func(path string) (*httptest.ResponseRecorder, envelope) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var body envelope
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w, body
+	}
+
+	t.Run("v1 keeps its body", func(t *testing.T) {
+		w, _ := get("/api/v1/items?per_page=2")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":["a","b","c","d","e"],"count":5}`, w.Body.String())
+	})
+
+	t.Run("every item without a page", func(t *testing.T) {
+		w, body := get("/api/v2/items")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, items, body.Data)
+		assert.Equal(t, 5, body.Meta.Total)
+		assert.Equal(t, 1, body.Meta.Page)
+		assert.Equal(t, 5, body.Meta.PerPage)
+		assert.Nil(t, body.Meta.NextCursor)
+		assert.Equal(t, []interface{}{"P1"}, body.Meta.Filters["priorities"])
+	})
+
+	t.Run("pages follow cursors", func(t *testing.T) {
+		_, first := get("/api/v2/items?per_page=2")
+		assert.Equal(t, []string{"a", "b"}, first.Data)
+		require.NotNil(t, first.Meta.NextCursor)
+
+		_, second := get("/api/v2/items?per_page=2&cursor=" + *first.Meta.NextCursor)
+		assert.Equal(t, []string{"c", "d"}, second.Data)
+		assert.Equal(t, 2, second.Meta.Page)
+		require.NotNil(t, second.Meta.NextCursor)
+
+		_, last := get("/api/v2/items?per_page=2&cursor=" + *second.Meta.NextCursor)
+		assert.Equal(t, []string{"e"}, last.Data)
+		assert.Nil(t, last.Meta.NextCursor)
+
+		_, beyond := get("/api/v2/items?page=4&per_page=2")
+		assert.Empty(t, beyond.Data)
+		assert.NotNil(t, beyond.Data)
+		assert.Equal(t, 5, beyond.Meta.Total)
+	})
+
+	t.Run("errors in the envelope", func(t *testing.T) {
+		w, body := get("/api/v2/items?cursor=bogus")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		require.Len(t, body.Errors, 1)
+		assert.Equal(t, errors.ErrInvalidParameter, body.Errors[0].Code)
+
+		w, body = get("/api/v2/items?per_page=1000")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		require.Len(t, body.Errors, 1)
+		assert.Equal(t, errors.ErrValidationError, body.Errors[0].Code)
+
+		w, body = get("/api/v2/missing")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Nil(t, body.Data)
+		require.Len(t, body.Errors, 1)
+	})
+
+	t.Run("items as NDJSON", func(t *testing.T) {
+		stream := func(path string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Accept", NDJSONContentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		w := stream("/api/v2/items?per_page=2&page=2")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, NDJSONContentType, w.Header().Get("Content-Type"))
+		assert.Equal(t, "\"c\"\n\"d\"\n", w.Body.String())
+
+		w = stream("/api/v1/items")
+		assert.JSONEq(t, `{"data":["a","b","c","d","e"],"count":5}`, w.Body.String())
+	})
+}
+
+func TestNDJSONStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	failAfter := func(lines int) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			stream := newNDJSONStream(c)
+			for i := 0; i < lines; i++ {
+				require.NoError(t, stream.Write(gin.H{"line": i}))
+			}
+			stream.Close(errors.DatabaseError("read items", assert.AnError))
+		}
+	}
+	router := gin.New()
+	router.GET("/before", failAfter(0))
+	router.GET("/after", failAfter(2))
+
+	// Failures before the first line get a normal error response
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/before", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	// Later failures end the stream with the envelope's errors
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/after", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.JSONEq(t, `{"line":1}`, lines[1])
+	var last Envelope
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &last))
+	assert.Nil(t, last.Data)
+	require.Len(t, last.Errors, 1)
+	assert.Equal(t, "/after", last.Errors[0].Path)
+}
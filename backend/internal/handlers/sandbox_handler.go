@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sandboxTokenHeader carries the token of the sandbox a request is made in
+const sandboxTokenHeader = "X-Sandbox-Token"
+
+// Sandbox is middleware placing requests carrying a sandbox token in that sandbox, so analytics
+// further down see the incidents through its overlay. Unknown and expired tokens are rejected
+// rather than silently answered with the real figures.
+func Sandbox(sandboxes *services.SandboxService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(sandboxTokenHeader)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		sandbox, err := sandboxes.GetSandbox(c.Request.Context(), token)
+		if err == sql.ErrNoRows {
+			errors.AbortWithError(c, errors.NotFound("Sandbox").
+				WithUserMessage("The analysis sandbox has expired or does not exist"))
+			return
+		}
+		if err != nil {
+			apiErr := errors.DatabaseError("resolve sandbox", err)
+			monitoring.TrackError(c.Request.Context(), apiErr, "sandbox_handler", "resolve_sandbox")
+			errors.AbortWithError(c, apiErr)
+			return
+		}
+		c.Request = c.Request.WithContext(services.WithSandbox(c.Request.Context(), sandbox))
+		c.Next()
+	}
+}
+
+// SandboxHandler handles creating and changing temporary analysis sandboxes
+type SandboxHandler struct {
+	sandboxes *services.SandboxService
+	logger    *logging.Logger
+}
+
+// NewSandboxHandler creates a new sandbox handler
+func NewSandboxHandler(sandboxes *services.SandboxService) *SandboxHandler {
+	return &SandboxHandler{
+		sandboxes: sandboxes,
+		logger:    logging.GetGlobalLogger().WithComponent("sandbox_handler"),
+	}
+}
+
+// CreateSandbox handles POST /api/sandboxes. The token in the response is only shown once and
+// is sent back in the X-Sandbox-Token header.
+func (h *SandboxHandler) CreateSandbox(c *gin.Context) {
+	var req SandboxRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	sandbox, token, err := h.sandboxes.CreateSandbox(c.Request.Context(), req.Name, req.ToOverlay(),
+		time.Duration(req.TTLMinutes)*time.Minute, requestUser(c))
+	if err != nil {
+		h.sendError(c, err, "create_sandbox")
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Sandbox created",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"sandbox":    sandbox.ID,
+			"expires_at": sandbox.ExpiresAt,
+		}))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":  sandbox,
+		"token": token,
+	})
+}
+
+// GetSandbox handles GET /api/sandboxes/current
+func (h *SandboxHandler) GetSandbox(c *gin.Context) {
+	sandbox := services.SandboxFromContext(c.Request.Context())
+	if sandbox == nil {
+		errors.SendError(c, errors.BadRequest("The "+sandboxTokenHeader+" header is required"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": sandbox,
+	})
+}
+
+// UpdateSandbox handles PUT /api/sandboxes/current, replacing the overlay and restarting the
+// sandbox's lifetime
+func (h *SandboxHandler) UpdateSandbox(c *gin.Context) {
+	token := c.GetHeader(sandboxTokenHeader)
+	if token == "" {
+		errors.SendError(c, errors.BadRequest("The "+sandboxTokenHeader+" header is required"))
+		return
+	}
+	var req SandboxRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	sandbox, err := h.sandboxes.UpdateSandbox(c.Request.Context(), token, req.ToOverlay(),
+		time.Duration(req.TTLMinutes)*time.Minute)
+	if err != nil {
+		h.sendError(c, err, "update_sandbox")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": sandbox,
+	})
+}
+
+// DeleteSandbox handles DELETE /api/sandboxes/current
+func (h *SandboxHandler) DeleteSandbox(c *gin.Context) {
+	token := c.GetHeader(sandboxTokenHeader)
+	if token == "" {
+		errors.SendError(c, errors.BadRequest("The "+sandboxTokenHeader+" header is required"))
+		return
+	}
+
+	if err := h.sandboxes.DeleteSandbox(c.Request.Context(), token); err != nil {
+		h.sendError(c, err, "delete_sandbox")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sandbox discarded",
+	})
+}
+
+// sendError answers a failed sandbox operation, with 400 for invalid overlays and 404 for
+// sandboxes that expired
+func (h *SandboxHandler) sendError(c *gin.Context, err error, operation string) {
+	switch {
+	case stderrors.Is(err, services.ErrInvalidSandbox):
+		errors.SendError(c, errors.BadRequest(err.Error()))
+	case err == sql.ErrNoRows:
+		errors.SendError(c, errors.NotFound("Sandbox"))
+	default:
+		apiErr := errors.DatabaseError("manage sandbox", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "sandbox_handler", operation)
+		errors.SendError(c, apiErr)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSandboxHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	sandboxes := services.NewSandboxService(db)
+	handler := NewSandboxHandler(sandboxes)
+	analyticsHandler := NewAnalyticsHandler(db)
+
+	incidents := []models.Incident{
+		{ID: "i1", UploadID: "upload-1", IncidentID: "INC001", ReportDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			ApplicationName: "Portal", ResolutionGroup: "Web Team", Priority: "P1", Status: "Open"},
+		{ID: "i2", UploadID: "upload-1", IncidentID: "INC002", ReportDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			ApplicationName: "Billing", ResolutionGroup: "Finance Apps", Priority: "P2", Status: "Open"},
+	}
+	_, err := services.NewIncidentService(db).BatchInsertIncidents(context.Background(), incidents, "upload-1")
+	require.NoError(t, err)
+
+	router := gin.New()
+	api := router.Group("/api", Sandbox(sandboxes))
+	api.POST("/sandboxes", handler.CreateSandbox)
+	api.GET("/sandboxes/current", handler.GetSandbox)
+	api.PUT("/sandboxes/current", handler.UpdateSandbox)
+	api.DELETE("/sandboxes/current", handler.DeleteSandbox)
+	api.GET("/analytics/priority", analyticsHandler.GetPriorityAnalysis)
+
+	send := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set(sandboxTokenHeader, token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		token          string
+		expectedStatus int
+	}{
+		{name: "unknown field", method: "POST", path: "/api/sandboxes", body: `{"excluded_apps":["Billing"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "unknown reclassified field", method: "POST", path: "/api/sandboxes", body: `{"reclassifications":[{"field":"description","from":"a","to":"b"}]}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid priority", method: "POST", path: "/api/sandboxes", body: `{"reclassifications":[{"field":"priority","from":"P1","to":"urgent"}]}`, expectedStatus: http.StatusBadRequest},
+		{name: "lifetime too long", method: "POST", path: "/api/sandboxes", body: `{"ttl_minutes":5000}`, expectedStatus: http.StatusBadRequest},
+		{name: "no token", method: "GET", path: "/api/sandboxes/current", expectedStatus: http.StatusBadRequest},
+		{name: "unknown token", method: "GET", path: "/api/analytics/priority", token: "not-a-token", expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedStatus, send(tt.method, tt.path, tt.body, tt.token).Code)
+		})
+	}
+
+	var created struct {
+		Data  services.Sandbox `json:"data"`
+		Token string           `json:"token"`
+	}
+	w := send("POST", "/api/sandboxes", `{"name":"What if","ttl_minutes":30,"excluded_applications":["Billing"]}`, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.NotEmpty(t, created.Token)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), created.Data.ExpiresAt, time.Minute)
+
+	priorities := func(token string) []services.PriorityAnalysis {
+		var response struct {
+			Data []services.PriorityAnalysis `json:"data"`
+		}
+		w := send("GET", "/api/analytics/priority", "", token)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data
+	}
+	assert.Len(t, priorities(""), 2)
+	sandboxed := priorities(created.Token)
+	require.Len(t, sandboxed, 1)
+	assert.Equal(t, "P1", sandboxed[0].Priority)
+
+	w = send("PUT", "/api/sandboxes/current", `{"reclassifications":[{"field":"priority","from":"P1","to":"P4"}]}`, created.Token)
+	require.Equal(t, http.StatusOK, w.Code)
+	reclassified := priorities(created.Token)
+	require.Len(t, reclassified, 2)
+	for _, priority := range reclassified {
+		assert.NotEqual(t, "P1", priority.Priority)
+	}
+
+	assert.Equal(t, http.StatusOK, send("GET", "/api/sandboxes/current", "", created.Token).Code)
+	assert.Equal(t, http.StatusOK, send("DELETE", "/api/sandboxes/current", "", created.Token).Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/api/analytics/priority", "", created.Token).Code)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceCatalogHandler handles business service catalog endpoints
+type ServiceCatalogHandler struct {
+	catalog *services.ServiceCatalog
+	logger  *logging.Logger
+}
+
+// NewServiceCatalogHandler creates a new service catalog handler
+func NewServiceCatalogHandler(db *sql.DB) *ServiceCatalogHandler {
+	return &ServiceCatalogHandler{
+		catalog: services.NewServiceCatalog(db),
+		logger:  logging.GetGlobalLogger().WithComponent("service_catalog_handler"),
+	}
+}
+
+// ListServices handles GET /api/service-catalog
+func (h *ServiceCatalogHandler) ListServices(c *gin.Context) {
+	catalog, err := h.catalog.ListServices(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve service catalog", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "service_catalog_handler", "list_services")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, catalog, nil, gin.H{
+		"data":  catalog,
+		"count": len(catalog),
+	})
+}
+
+// SaveService handles PUT /api/service-catalog/:name
+func (h *ServiceCatalogHandler) SaveService(c *gin.Context) {
+	var params BusinessServiceParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var req BusinessServiceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	service, err := h.catalog.SaveService(c.Request.Context(), params.Name, req.BusinessUnit, req.Applications, req.Aliases)
+	if err != nil {
+		if stderrors.Is(err, services.ErrInvalidService) {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("save business service", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "service_catalog_handler", "save_service")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Business service saved",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"name":          service.Name,
+			"business_unit": service.BusinessUnit,
+			"applications":  len(service.Applications),
+			"aliases":       len(service.Aliases),
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": service,
+	})
+}
+
+// DeleteService handles DELETE /api/service-catalog/:name
+func (h *ServiceCatalogHandler) DeleteService(c *gin.Context) {
+	var params BusinessServiceParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.catalog.DeleteService(c.Request.Context(), params.Name); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Business service"))
+			return
+		}
+		apiErr := errors.DatabaseError("delete business service", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "service_catalog_handler", "delete_service")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Business service deleted",
+	})
+}
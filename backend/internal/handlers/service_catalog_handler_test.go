@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceCatalogHandler_Services(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewServiceCatalogHandler(db)
+
+	// Save service
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("PUT", "/service-catalog/Online%20Banking",
+		strings.NewReader(`{"business_unit":"Retail","applications":["Portal"],"aliases":["OLB"]}`))
+	c.Params = []gin.Param{{Key: "name", Value: "Online Banking"}}
+	handler.SaveService(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// An alias naming another service is rejected
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("PUT", "/service-catalog/Cards",
+		strings.NewReader(`{"business_unit":"Retail","aliases":["Online Banking"]}`))
+	c.Params = []gin.Param{{Key: "name", Value: "Cards"}}
+	handler.SaveService(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Missing business unit is rejected
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("PUT", "/service-catalog/Cards", strings.NewReader(`{}`))
+	c.Params = []gin.Param{{Key: "name", Value: "Cards"}}
+	handler.SaveService(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// List services
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/service-catalog", nil)
+	handler.ListServices(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(1), response["count"])
+
+	// Delete unknown service
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/service-catalog/unknown", nil)
+	c.Params = []gin.Param{{Key: "name", Value: "unknown"}}
+	handler.DeleteService(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
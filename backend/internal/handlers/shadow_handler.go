@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShadowHandler handles shadow analyzer configuration endpoints
+type ShadowHandler struct {
+	shadowService *services.ShadowService
+	logger        *logging.Logger
+}
+
+// NewShadowHandler creates a new shadow handler
+func NewShadowHandler(db *sql.DB) *ShadowHandler {
+	return &ShadowHandler{
+		shadowService: services.NewShadowService(db),
+		logger:        logging.GetGlobalLogger().WithComponent("shadow_handler"),
+	}
+}
+
+// ListConfigs handles GET /api/shadow-configs
+func (h *ShadowHandler) ListConfigs(c *gin.Context) {
+	configs, err := h.shadowService.ListConfigs(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve shadow configs", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "shadow_handler", "list_configs")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, configs, nil, gin.H{
+		"data":  configs,
+		"count": len(configs),
+	})
+}
+
+// CreateConfig handles POST /api/shadow-configs
+func (h *ShadowHandler) CreateConfig(c *gin.Context) {
+	var req ShadowConfigRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	keywords := make([]services.ShadowKeyword, len(req.AutomationKeywords))
+	for i, keyword := range req.AutomationKeywords {
+		keywords[i] = services.ShadowKeyword{Keyword: keyword.Keyword, Kind: keyword.Kind, Weight: *keyword.Weight}
+	}
+
+	config, err := h.shadowService.CreateConfig(c.Request.Context(), req.Name, req.SentimentPhrases, keywords, req.Active)
+	if err != nil {
+		if err == services.ErrInvalidShadowPhrase || err == services.ErrInvalidAutomationKeyword {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("create shadow config", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "shadow_handler", "create_config")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Shadow config created",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"config_id": config.ID,
+			"name":      config.Name,
+			"active":    config.Active,
+		}))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": config,
+	})
+}
+
+// ActivateConfig handles POST /api/shadow-configs/:id/activate
+func (h *ShadowHandler) ActivateConfig(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+// DeactivateConfig handles POST /api/shadow-configs/:id/deactivate
+func (h *ShadowHandler) DeactivateConfig(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+// setActive turns shadow mode on or off for the configuration in the path
+func (h *ShadowHandler) setActive(c *gin.Context, active bool) {
+	var params ShadowConfigParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.shadowService.SetActive(c.Request.Context(), params.ID, active); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Shadow config"))
+			return
+		}
+		apiErr := errors.DatabaseError("update shadow config", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "shadow_handler", "set_active")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Shadow config updated",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"config_id": params.ID,
+			"active":    active,
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Shadow config updated",
+		"active":  active,
+	})
+}
+
+// DeleteConfig handles DELETE /api/shadow-configs/:id
+func (h *ShadowHandler) DeleteConfig(c *gin.Context) {
+	var params ShadowConfigParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.shadowService.DeleteConfig(c.Request.Context(), params.ID); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Shadow config"))
+			return
+		}
+		apiErr := errors.DatabaseError("delete shadow config", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "shadow_handler", "delete_config")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Shadow config deleted",
+	})
+}
+
+// GetComparison handles GET /api/shadow-configs/:id/comparison
+func (h *ShadowHandler) GetComparison(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_shadow_comparison")
+
+	var params ShadowConfigParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	comparison, err := h.shadowService.CompareShadow(c.Request.Context(), params.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Shadow config"))
+			return
+		}
+		apiErr := errors.DatabaseError("compare shadow config", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "shadow_handler", "get_shadow_comparison")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_shadow_comparison", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"config_id":          params.ID,
+			"incidents_compared": comparison.IncidentsCompared,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": comparison,
+	})
+}
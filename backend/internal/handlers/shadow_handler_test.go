@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadowHandler_Configs(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewShadowHandler(db)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "phrase and keyword overrides",
+			body:           `{"name":"tuning","sentiment_phrases":{"false alarm":0.5},"automation_keywords":[{"keyword":"hotfix","kind":"automation","weight":0.8}],"active":true}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "missing name",
+			body:           `{"sentiment_phrases":{"false alarm":0.5}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "phrase weight out of range",
+			body:           `{"name":"bad","sentiment_phrases":{"false alarm":3}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid keyword kind",
+			body:           `{"name":"bad","automation_keywords":[{"keyword":"hotfix","kind":"other","weight":0.8}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "single word phrase",
+			body:           `{"name":"bad","sentiment_phrases":{"alarm":0.5}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	var configID string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/shadow-configs", strings.NewReader(tt.body))
+			handler.CreateConfig(c)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if w.Code == http.StatusCreated {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				configID = response["data"].(map[string]interface{})["id"].(string)
+			}
+		})
+	}
+	require.NotEmpty(t, configID)
+
+	// List configs
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/shadow-configs", nil)
+	handler.ListConfigs(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(1), response["count"])
+
+	// Compare with no shadow results yet
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/shadow-configs/"+configID+"/comparison", nil)
+	c.Params = []gin.Param{{Key: "id", Value: configID}}
+	handler.GetComparison(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(0), response["data"].(map[string]interface{})["incidents_compared"])
+
+	// Deactivate, then unknown config is not found
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/shadow-configs/"+configID+"/deactivate", nil)
+	c.Params = []gin.Param{{Key: "id", Value: configID}}
+	handler.DeactivateConfig(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/shadow-configs/missing/activate", nil)
+	c.Params = []gin.Param{{Key: "id", Value: "missing"}}
+	handler.ActivateConfig(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Delete config
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/shadow-configs/"+configID, nil)
+	c.Params = []gin.Param{{Key: "id", Value: configID}}
+	handler.DeleteConfig(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/shadow-configs/"+configID+"/comparison", nil)
+	c.Params = []gin.Param{{Key: "id", Value: configID}}
+	handler.GetComparison(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
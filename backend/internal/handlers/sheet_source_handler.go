@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Google Sheets sources live on the upload handler: each import becomes an upload that is
+// processed in the background the same way as a file upload.
+
+// ListSheetSources handles GET /api/sheet-sources
+func (h *UploadHandler) ListSheetSources(c *gin.Context) {
+	sources, err := h.sheetsService.ListSources(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve sheet sources", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "upload_handler", "list_sheet_sources")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, sources, nil, gin.H{
+		"data":  sources,
+		"count": len(sources),
+	})
+}
+
+// CreateSheetSource handles POST /api/sheet-sources
+func (h *UploadHandler) CreateSheetSource(c *gin.Context) {
+	var req SheetSourceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	source, err := h.sheetsService.CreateSource(c.Request.Context(), req.ToInput())
+	if err != nil {
+		if stderrors.Is(err, services.ErrInvalidSheetSource) {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("create sheet source", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "upload_handler", "create_sheet_source")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Sheet source created",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"source_id":      source.ID,
+			"spreadsheet_id": source.SpreadsheetID,
+			"client_email":   source.ClientEmail,
+		}))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": source,
+	})
+}
+
+// GetSheetSource handles GET /api/sheet-sources/:id
+func (h *UploadHandler) GetSheetSource(c *gin.Context) {
+	source, ok := h.findSheetSource(c, "get_sheet_source")
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": source,
+	})
+}
+
+// DeleteSheetSource handles DELETE /api/sheet-sources/:id
+func (h *UploadHandler) DeleteSheetSource(c *gin.Context) {
+	var params SheetSourceParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.sheetsService.DeleteSource(c.Request.Context(), params.ID); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Sheet source"))
+			return
+		}
+		apiErr := errors.DatabaseError("delete sheet source", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "upload_handler", "delete_sheet_source")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sheet source deleted",
+	})
+}
+
+// ImportSheetSource handles POST /api/sheet-sources/:id/import. The sheet is fetched and stored
+// as an upload during the request so access problems are reported immediately; processing
+// then runs in the background with the source's mapping profile.
+func (h *UploadHandler) ImportSheetSource(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("import_sheet_source")
+
+	source, ok := h.findSheetSource(c, "import_sheet_source")
+	if !ok {
+		return
+	}
+
+	upload, err := h.sheetsService.ImportSource(c.Request.Context(), source)
+	if err != nil {
+		var apiErr *errors.APIError
+		switch {
+		case stderrors.Is(err, services.ErrEmptySheet), stderrors.Is(err, services.ErrInvalidSheetSource):
+			apiErr = errors.BadRequest(err.Error())
+		case stderrors.Is(err, services.ErrSheetFetchFailed):
+			apiErr = errors.NewAPIError(errors.ErrServiceUnavailable, err.Error()).
+				WithUserMessage("The Google Sheet could not be read").
+				WithSuggestions([]string{
+					"Share the sheet with " + source.ClientEmail,
+					"Check that the Google Sheets API is enabled for the service account's project",
+				})
+		default:
+			apiErr = errors.DatabaseError("import sheet", err)
+		}
+		monitoring.TrackError(c.Request.Context(), apiErr, "upload_handler", "import_sheet_source")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	options := source.ProcessingOptions()
+
+	// Start processing in background
+	ctx, cancel := h.processingContext(c)
+	go func() {
+		defer cancel()
+		_, err := h.processingService.ProcessUploadWithOptions(ctx, upload.ID, options)
+		if err != nil {
+			logger.Error("Processing failed for sheet import", err,
+				logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+					"source_id": source.ID,
+					"upload_id": upload.ID,
+				}))
+
+			apiErr := errors.ProcessingFailed(err.Error())
+			monitoring.TrackError(ctx, apiErr, "processing_service", "process_upload")
+		}
+	}()
+
+	logger.LogDuration("import_sheet_source", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"source_id": source.ID,
+			"upload_id": upload.ID,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":            "Processing started",
+		"upload_id":          upload.ID,
+		"processing_options": options,
+	})
+}
+
+// findSheetSource loads the sheet source named in the path, sending the error response when it cannot
+func (h *UploadHandler) findSheetSource(c *gin.Context, operation string) (*services.SheetSource, bool) {
+	var params SheetSourceParams
+	if !bindURI(c, &params) {
+		return nil, false
+	}
+
+	source, err := h.sheetsService.GetSource(c.Request.Context(), params.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Sheet source"))
+			return nil, false
+		}
+		apiErr := errors.DatabaseError("retrieve sheet source", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "upload_handler", operation)
+		errors.SendError(c, apiErr)
+		return nil, false
+	}
+	return source, true
+}
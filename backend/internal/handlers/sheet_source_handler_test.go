@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadHandler_SheetSources(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewUploadHandler(db, storage.NewFileStore(t.TempDir()), new(MockProcessingService))
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	credentials, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "importer@project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	})
+	require.NoError(t, err)
+
+	sheetURL := "https://docs.google.com/spreadsheets/d/sheet-1/edit"
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "valid source",
+			body:           `{"name":"EMEA tracker","url":"` + sheetURL + `","credentials":` + string(credentials) + `,"sync_interval_minutes":60}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "not a sheets URL",
+			body:           `{"name":"EMEA tracker","url":"https://example.com/sheet","credentials":` + string(credentials) + `}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing credentials",
+			body:           `{"name":"EMEA tracker","url":"` + sheetURL + `"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "not a service account",
+			body:           `{"name":"EMEA tracker","url":"` + sheetURL + `","credentials":{"type":"authorized_user"}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "interval too short",
+			body:           `{"name":"EMEA tracker","url":"` + sheetURL + `","credentials":` + string(credentials) + `,"sync_interval_minutes":1}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown mapping profile",
+			body:           `{"name":"EMEA tracker","url":"` + sheetURL + `","credentials":` + string(credentials) + `,"mapping_profile":"jira"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	var sourceID string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/sheet-sources", strings.NewReader(tt.body))
+			handler.CreateSheetSource(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if w.Code == http.StatusCreated {
+				var response struct {
+					Data map[string]interface{} `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "sheet-1", response.Data["spreadsheet_id"])
+				assert.NotContains(t, w.Body.String(), "PRIVATE KEY")
+				sourceID = response.Data["id"].(string)
+			}
+		})
+	}
+	require.NotEmpty(t, sourceID)
+
+	// List sources
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/sheet-sources", nil)
+	handler.ListSheetSources(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var list map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, float64(1), list["count"])
+
+	// Delete the source, then it is gone
+	for _, expectedStatus := range []int{http.StatusOK, http.StatusNotFound} {
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("DELETE", "/sheet-sources/"+sourceID, nil)
+		c.Params = []gin.Param{{Key: "id", Value: sourceID}}
+		handler.DeleteSheetSource(c)
+		assert.Equal(t, expectedStatus, w.Code)
+	}
+
+	// Importing a deleted source
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/sheet-sources/"+sourceID+"/import", nil)
+	c.Params = []gin.Param{{Key: "id", Value: sourceID}}
+	handler.ImportSheetSource(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
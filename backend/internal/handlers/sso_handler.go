@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ssoSessionCookie holds the session token of a user signed in through single sign-on
+const ssoSessionCookie = "ims_session"
+
+// SSOAuth is middleware identifying users by their single sign-on session. The session's user
+// becomes the request's user, so data scopes and audit entries follow the signed-in identity,
+// and viewers are limited to reading. When required is set, requests without a session are
+// rejected with 401 unless their path starts with one of exempt. A nil service lets every
+// request through unchanged.
+func SSOAuth(sso *services.SSOService, required bool, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Requests made with an API key act as the key, not a user
+		if sso == nil || services.APIKeyFromContext(c.Request.Context()) != nil {
+			c.Next()
+			return
+		}
+
+		isExempt := false
+		for _, prefix := range exempt {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				isExempt = true
+				break
+			}
+		}
+
+		token, _ := c.Cookie(ssoSessionCookie)
+		session, err := sso.Session(c.Request.Context(), token)
+		if err != nil {
+			apiErr := errors.DatabaseError("resolve session", err)
+			monitoring.TrackError(c.Request.Context(), apiErr, "sso_handler", "resolve_session")
+			errors.AbortWithError(c, apiErr)
+			return
+		}
+		if session == nil {
+			if required && !isExempt {
+				errors.AbortWithError(c, errors.NewAPIError(errors.ErrUnauthorized, "Sign-in required").
+					WithUserMessage("Please sign in to continue"))
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if !isExempt && !services.RoleAtLeast(session.Role, services.RoleAnalyst) && !isReadOnlyMethod(c.Request.Method) {
+			errors.AbortWithError(c, errors.NewAPIError(errors.ErrForbidden, "Viewers cannot change data").
+				WithUserMessage("Your role only allows viewing data"))
+			return
+		}
+
+		ctx := services.WithSSOSession(c.Request.Context(), session)
+		c.Request = c.Request.WithContext(logging.WithUserID(ctx, session.UserID))
+		c.Next()
+	}
+}
+
+// isReadOnlyMethod reports whether an HTTP method only reads data
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// SSOHandler handles signing in and out through the OpenID Connect identity provider
+type SSOHandler struct {
+	sso    *services.SSOService
+	logger *logging.Logger
+}
+
+// NewSSOHandler creates a new single sign-on handler
+func NewSSOHandler(sso *services.SSOService) *SSOHandler {
+	return &SSOHandler{
+		sso:    sso,
+		logger: logging.GetGlobalLogger().WithComponent("sso_handler"),
+	}
+}
+
+// Login handles GET /api/auth/login, redirecting to the identity provider
+func (h *SSOHandler) Login(c *gin.Context) {
+	var query SSOLoginQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	authURL, err := h.sso.BeginLogin(c.Request.Context(), safeReturnPath(query.ReturnTo))
+	if err != nil {
+		apiErr := errors.NewAPIError(errors.ErrServiceUnavailable, err.Error()).
+			WithUserMessage("The sign-in service could not be reached, please try again shortly")
+		monitoring.TrackError(c.Request.Context(), apiErr, "sso_handler", "login")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback handles GET /api/auth/callback, starting a session for the signed-in user
+func (h *SSOHandler) Callback(c *gin.Context) {
+	var query SSOCallbackQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	if query.Error != "" || query.Code == "" {
+		message := query.ErrorDescription
+		if message == "" {
+			message = "The identity provider returned no authorization code"
+		}
+		errors.SendError(c, errors.NewAPIError(errors.ErrUnauthorized, message).
+			WithUserMessage("Sign-in was not completed"))
+		return
+	}
+
+	session, token, returnTo, err := h.sso.CompleteLogin(c.Request.Context(), query.State, query.Code)
+	if err != nil {
+		switch {
+		case stderrors.Is(err, services.ErrSSOAccessDenied):
+			errors.SendError(c, errors.NewAPIError(errors.ErrForbidden, err.Error()).
+				WithUserMessage("Your account has not been granted access to this application"))
+		case stderrors.Is(err, services.ErrSSOLoginFailed), stderrors.Is(err, services.ErrInvalidSSOConfig):
+			apiErr := errors.NewAPIError(errors.ErrUnauthorized, err.Error()).
+				WithUserMessage("Sign-in failed, please try again")
+			monitoring.TrackError(c.Request.Context(), apiErr, "sso_handler", "callback")
+			errors.SendError(c, apiErr)
+		default:
+			apiErr := errors.DatabaseError("start session", err)
+			monitoring.TrackError(c.Request.Context(), apiErr, "sso_handler", "callback")
+			errors.SendError(c, apiErr)
+		}
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("User signed in",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"user": session.UserID,
+			"role": session.Role,
+		}))
+
+	h.setSessionCookie(c, token, int(time.Until(session.ExpiresAt).Seconds()))
+	c.Redirect(http.StatusFound, returnTo)
+}
+
+// GetSession handles GET /api/auth/session
+func (h *SSOHandler) GetSession(c *gin.Context) {
+	session := services.SSOSessionFromContext(c.Request.Context())
+	if session == nil {
+		errors.SendError(c, errors.NewAPIError(errors.ErrUnauthorized, "Not signed in"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": session,
+	})
+}
+
+// Logout handles POST /api/auth/logout
+func (h *SSOHandler) Logout(c *gin.Context) {
+	if token, err := c.Cookie(ssoSessionCookie); err == nil && token != "" {
+		if err := h.sso.Logout(c.Request.Context(), token); err != nil {
+			apiErr := errors.DatabaseError("end session", err)
+			monitoring.TrackError(c.Request.Context(), apiErr, "sso_handler", "logout")
+			errors.SendError(c, apiErr)
+			return
+		}
+	}
+
+	h.setSessionCookie(c, "", -1)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Signed out",
+	})
+}
+
+// setSessionCookie sets the session cookie, or removes it when maxAge is negative. Lax
+// same-site mode lets the cookie be set on the provider's redirect back to the callback.
+func (h *SSOHandler) setSessionCookie(c *gin.Context, token string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     ssoSessionCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   h.sso.SecureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// safeReturnPath keeps only paths on this server, so the login cannot be used to redirect users
+// to another site
+func safeReturnPath(returnTo string) string {
+	if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") || strings.Contains(returnTo, "\\") {
+		return "/"
+	}
+	return returnTo
+}
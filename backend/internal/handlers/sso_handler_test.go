@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSOHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// An identity provider signing in whoever is named by the code, with the groups listed for them
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	groups := map[string][]string{"admin-code": {"ims-admins"}, "viewer-code": {"staff"}}
+	var nonce string
+	var idp *httptest.Server
+	idp = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer": idp.URL, "authorization_endpoint": idp.URL + "/authorize",
+				"token_endpoint": idp.URL + "/token", "jwks_uri": idp.URL + "/jwks",
+			})
+		case "/jwks":
+			json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+				"kty": "RSA", "kid": "k", "n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}}})
+		case "/token":
+			r.ParseForm()
+			code := r.PostForm.Get("code")
+			header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k"})
+			claims, _ := json.Marshal(map[string]interface{}{
+				"iss": idp.URL, "aud": "ims", "sub": code, "email": code + "@example.com",
+				"exp": time.Now().Add(time.Hour).Unix(), "nonce": nonce, "groups": groups[code],
+			})
+			input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+			digest := sha256.Sum256([]byte(input))
+			signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+			json.NewEncoder(w).Encode(map[string]string{"id_token": input + "." + base64.RawURLEncoding.EncodeToString(signature)})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer idp.Close()
+
+	sso, err := services.NewSSOService(createTestDB(t), services.SSOConfig{
+		IssuerURL:    idp.URL,
+		ClientID:     "ims",
+		RedirectURL:  "http://localhost:8080/api/auth/callback",
+		RoleMappings: map[string]string{"ims-admins": services.RoleAdmin},
+		DefaultRole:  services.RoleViewer,
+	})
+	require.NoError(t, err)
+	handler := NewSSOHandler(sso)
+
+	router := gin.New()
+	api := router.Group("/api", SSOAuth(sso, true, "/api/auth/", "/api/admin/"))
+	api.GET("/auth/login", handler.Login)
+	api.GET("/auth/callback", handler.Callback)
+	api.GET("/auth/session", handler.GetSession)
+	api.POST("/auth/logout", handler.Logout)
+	api.GET("/uploads", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"user": requestUser(c)}) })
+	api.POST("/uploads", func(c *gin.Context) { c.JSON(http.StatusCreated, gin.H{}) })
+	api.GET("/admin/usage", AdminAuth("secret"), func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+
+	send := func(method, path, cookie string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: ssoSessionCookie, Value: cookie})
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	signIn := func(code string) string {
+		w := send("GET", "/api/auth/login?return_to=//evil.example.com", "")
+		require.Equal(t, http.StatusFound, w.Code)
+		authURL, err := url.Parse(w.Header().Get("Location"))
+		require.NoError(t, err)
+		nonce = authURL.Query().Get("nonce")
+
+		w = send("GET", "/api/auth/callback?state="+authURL.Query().Get("state")+"&code="+code, "")
+		require.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "/", w.Header().Get("Location"))
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == ssoSessionCookie {
+				assert.True(t, cookie.HttpOnly)
+				return cookie.Value
+			}
+		}
+		t.Fatal("callback set no session cookie")
+		return ""
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/uploads", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/auth/session", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/auth/callback?state=unknown&code=admin-code", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/auth/callback?state=x&error=access_denied", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/admin/usage", "").Code)
+
+	viewer := signIn("viewer-code")
+	w := send("GET", "/api/uploads", viewer)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "viewer-code@example.com")
+	assert.Equal(t, http.StatusForbidden, send("POST", "/api/uploads", viewer).Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/admin/usage", viewer).Code)
+
+	admin := signIn("admin-code")
+	assert.Equal(t, http.StatusCreated, send("POST", "/api/uploads", admin).Code)
+	assert.Equal(t, http.StatusOK, send("GET", "/api/admin/usage", admin).Code)
+
+	var session struct {
+		Data services.SSOSession `json:"data"`
+	}
+	w = send("GET", "/api/auth/session", admin)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+	assert.Equal(t, services.RoleAdmin, session.Data.Role)
+
+	assert.Equal(t, http.StatusOK, send("POST", "/api/auth/logout", viewer).Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/uploads", viewer).Code)
+}
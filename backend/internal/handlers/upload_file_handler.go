@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+	"os"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+	"incident-management-system/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultFileLinkTTL is how long a signed download link for an uploaded file stays valid when
+// no expiry is requested
+const DefaultFileLinkTTL = 15 * time.Minute
+
+// SignedUploadFilePath is the path prefix of signed download links for uploaded files. It lives
+// outside /api so the links work without a session.
+const SignedUploadFilePath = "/files/uploads/"
+
+// GetUploadFile handles GET /api/uploads/:id/file. It sends the original uploaded file, exactly
+// as ingested, or with link=true a signed download link to it. Users with a data scope cannot
+// retrieve original files, which hold every incident of the upload.
+func (h *UploadHandler) GetUploadFile(c *gin.Context) {
+	uploadID := c.Param("id")
+	if uploadID == "" {
+		errors.SendError(c, errors.NewAPIError(errors.ErrMissingUploadID, "Upload ID is required"))
+		return
+	}
+
+	var query UploadFileQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	if services.DataScopeFromContext(c.Request.Context()) != nil {
+		errors.SendError(c, errors.NewAPIError(errors.ErrForbidden,
+			"Original files are not available to users with a data scope"))
+		return
+	}
+
+	upload, path, ok := h.uploadFile(c, uploadID, "get_upload_file")
+	if !ok {
+		return
+	}
+
+	if query.Link {
+		ttl := DefaultFileLinkTTL
+		if query.ExpiresIn > 0 {
+			ttl = time.Duration(query.ExpiresIn) * time.Second
+		}
+		url, expiresAt := h.urlSigner.SignedURL(SignedUploadFilePath+upload.ID, ttl)
+		c.JSON(http.StatusOK, gin.H{
+			"data": gin.H{
+				"url":        url,
+				"expires_at": expiresAt,
+			},
+		})
+		return
+	}
+
+	h.sendUploadFile(c, upload, path, "session")
+}
+
+// DownloadSignedUploadFile handles GET /files/uploads/:id, sending the original uploaded file to
+// holders of an unexpired signed link
+func (h *UploadHandler) DownloadSignedUploadFile(c *gin.Context) {
+	var query SignedFileQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	uploadID := c.Param("id")
+	if err := h.urlSigner.Verify(SignedUploadFilePath+uploadID, query.Expires, query.Signature); err != nil {
+		message := "Invalid download link"
+		if stderrors.Is(err, storage.ErrLinkExpired) {
+			message = "Download link has expired"
+		}
+		errors.SendError(c, errors.NewAPIError(errors.ErrForbidden, message))
+		return
+	}
+
+	upload, path, ok := h.uploadFile(c, uploadID, "download_signed_upload_file")
+	if !ok {
+		return
+	}
+	h.sendUploadFile(c, upload, path, "signed_link")
+}
+
+// uploadFile looks up an upload and the path of its stored file, sending a 404 when either is
+// missing. Uploads created by manual entry or pushed batches have no file.
+func (h *UploadHandler) uploadFile(c *gin.Context, uploadID, operation string) (*models.Upload, string, bool) {
+	upload, err := h.getUploadRecord(c.Request.Context(), uploadID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Upload"))
+			return nil, "", false
+		}
+		apiErr := errors.DatabaseError("retrieve upload", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "upload_handler", operation)
+		errors.SendError(c, apiErr)
+		return nil, "", false
+	}
+
+	path := h.fileStore.GetFilePath(upload.Filename)
+	if info, err := os.Stat(path); upload.Filename == "" || err != nil || info.IsDir() {
+		errors.SendError(c, errors.NotFound("Upload file"))
+		return nil, "", false
+	}
+	return upload, path, true
+}
+
+// sendUploadFile sends an upload's file under its original name and records who retrieved it
+func (h *UploadHandler) sendUploadFile(c *gin.Context, upload *models.Upload, path, via string) {
+	h.logger.WithContext(c.Request.Context()).Info("Original upload file retrieved",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"upload_id": upload.ID,
+			"user":      requestUser(c),
+			"via":       via,
+		}))
+	c.FileAttachment(path, upload.OriginalFilename)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/services"
+	"incident-management-system/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadHandler_GetUploadFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	dir := t.TempDir()
+
+	content := "original workbook bytes"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "20240101_abcd1234.xlsx"), []byte(content), 0644))
+	_, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES
+		('upload-1', '20240101_abcd1234.xlsx', 'January incidents.xlsx', 'completed'),
+		('manual', '', 'manual-entry-2024-01-01', 'completed')`)
+	require.NoError(t, err)
+
+	handler := NewUploadHandler(db, storage.NewFileStore(dir), &MockProcessingService{})
+	handler.SetURLSigner(storage.NewURLSigner([]byte("test-key")))
+
+	router := gin.New()
+	router.GET("/api/uploads/:id/file", handler.GetUploadFile)
+	router.GET("/api/scoped/uploads/:id/file", func(c *gin.Context) {
+		scope := &services.DataScope{UserID: "analyst", Applications: []string{"Portal"}}
+		c.Request = c.Request.WithContext(services.WithDataScope(c.Request.Context(), scope))
+		c.Next()
+	}, handler.GetUploadFile)
+	router.GET(SignedUploadFilePath+":id", handler.DownloadSignedUploadFile)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("streams the original file", func(t *testing.T) {
+		w := get("/api/uploads/upload-1/file")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, content, w.Body.String())
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "January incidents.xlsx")
+	})
+
+	t.Run("signed link downloads the file", func(t *testing.T) {
+		w := get("/api/uploads/upload-1/file?link=true&expires_in=60")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data struct {
+				URL       string `json:"url"`
+				ExpiresAt string `json:"expires_at"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.True(t, strings.HasPrefix(response.Data.URL, SignedUploadFilePath+"upload-1?"), response.Data.URL)
+		assert.NotEmpty(t, response.Data.ExpiresAt)
+
+		w = get(response.Data.URL)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, content, w.Body.String())
+
+		// The signature covers the upload and the expiry
+		tampered := strings.Replace(response.Data.URL, "upload-1", "upload-2", 1)
+		assert.Equal(t, http.StatusForbidden, get(tampered).Code)
+		assert.Equal(t, http.StatusForbidden, get(strings.Replace(response.Data.URL, "expires=", "expires=9", 1)).Code)
+		assert.Equal(t, http.StatusBadRequest, get(SignedUploadFilePath+"upload-1").Code)
+	})
+
+	t.Run("links from another key are rejected", func(t *testing.T) {
+		url, _ := storage.NewURLSigner([]byte("other-key")).SignedURL(SignedUploadFilePath+"upload-1", DefaultFileLinkTTL)
+		assert.Equal(t, http.StatusForbidden, get(url).Code)
+	})
+
+	t.Run("missing upload or file", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/api/uploads/unknown/file").Code)
+		assert.Equal(t, http.StatusNotFound, get("/api/uploads/manual/file").Code)
+	})
+
+	t.Run("invalid expiry", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("/api/uploads/upload-1/file?link=true&expires_in=90000").Code)
+	})
+
+	t.Run("users with a data scope are refused", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, get("/api/scoped/uploads/upload-1/file").Code)
+	})
+}
@@ -14,8 +14,11 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/services"
 	"incident-management-system/internal/storage"
 
 	"github.com/gin-gonic/gin"
@@ -25,18 +28,58 @@ import (
 
 // MockProcessingService is a mock implementation of the processing service
 type MockProcessingService struct {
-	ProcessUploadFunc       func(ctx context.Context, uploadID string) (interface{}, error)
-	GetProcessingStatusFunc func(ctx context.Context, uploadID string) (interface{}, error)
+	ProcessUploadFunc            func(ctx context.Context, uploadID string) (*services.ProcessingProgress, error)
+	ProcessUploadWithOptionsFunc func(ctx context.Context, uploadID string, options models.ProcessingOptions) (*services.ProcessingProgress, error)
+	ProcessDatasetFunc           func(ctx context.Context, datasetID string, options models.ProcessingOptions) ([]*services.ProcessingProgress, error)
+	QueueUploadFunc              func(ctx context.Context, uploadID string, options models.ProcessingOptions) error
+	QueueDatasetFunc             func(ctx context.Context, datasetID string, options models.ProcessingOptions) ([]models.Upload, error)
+	GetProcessingStatusFunc      func(ctx context.Context, uploadID string) (*services.ProcessingProgress, error)
 }
 
-func (m *MockProcessingService) ProcessUpload(ctx context.Context, uploadID string) (interface{}, error) {
+func (m *MockProcessingService) ProcessUpload(ctx context.Context, uploadID string) (*services.ProcessingProgress, error) {
 	if m.ProcessUploadFunc != nil {
 		return m.ProcessUploadFunc(ctx, uploadID)
 	}
 	return nil, nil
 }
 
-func (m *MockProcessingService) GetProcessingStatus(ctx context.Context, uploadID string) (interface{}, error) {
+func (m *MockProcessingService) ProcessUploadWithOptions(ctx context.Context, uploadID string, options models.ProcessingOptions) (*services.ProcessingProgress, error) {
+	if m.ProcessUploadWithOptionsFunc != nil {
+		return m.ProcessUploadWithOptionsFunc(ctx, uploadID, options)
+	}
+	return m.ProcessUpload(ctx, uploadID)
+}
+
+func (m *MockProcessingService) ProcessDataset(ctx context.Context, datasetID string, options models.ProcessingOptions) ([]*services.ProcessingProgress, error) {
+	if m.ProcessDatasetFunc != nil {
+		return m.ProcessDatasetFunc(ctx, datasetID, options)
+	}
+	return nil, nil
+}
+
+func (m *MockProcessingService) QueueUpload(ctx context.Context, uploadID string, options models.ProcessingOptions) error {
+	if m.QueueUploadFunc != nil {
+		return m.QueueUploadFunc(ctx, uploadID, options)
+	}
+	return nil
+}
+
+func (m *MockProcessingService) ProcessQueuedUpload(ctx context.Context, uploadID string, options models.ProcessingOptions) (*services.ProcessingProgress, error) {
+	return m.ProcessUploadWithOptions(ctx, uploadID, options)
+}
+
+func (m *MockProcessingService) QueueDataset(ctx context.Context, datasetID string, options models.ProcessingOptions) ([]models.Upload, error) {
+	if m.QueueDatasetFunc != nil {
+		return m.QueueDatasetFunc(ctx, datasetID, options)
+	}
+	return nil, services.ErrNoPendingUploads
+}
+
+func (m *MockProcessingService) ProcessQueuedDataset(ctx context.Context, datasetID string, queued []models.Upload, options models.ProcessingOptions) ([]*services.ProcessingProgress, error) {
+	return m.ProcessDataset(ctx, datasetID, options)
+}
+
+func (m *MockProcessingService) GetProcessingStatus(ctx context.Context, uploadID string) (*services.ProcessingProgress, error) {
 	if m.GetProcessingStatusFunc != nil {
 		return m.GetProcessingStatusFunc(ctx, uploadID)
 	}
@@ -352,6 +395,52 @@ func TestUploadHandler_GetUpload(t *testing.T) {
 	}
 }
 
+func TestUploadHandler_V1UploadStatuses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewUploadHandler(db, storage.NewFileStore(t.TempDir()), new(MockProcessingService))
+
+	_, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES
+		('upload-queued', 'a.xlsx', 'a.xlsx', 'queued'),
+		('upload-partial', 'b.xlsx', 'b.xlsx', 'completed_with_errors')`)
+	require.NoError(t, err)
+
+	router := gin.New()
+	for _, version := range []string{APIVersion1, APIVersion2} {
+		group := router.Group(APIVersionPath(version), APIVersion(version))
+		group.GET("/uploads", handler.GetUploads)
+		group.GET("/uploads/:id", handler.GetUpload)
+	}
+	statusOf := func(path string) string {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Upload models.Upload `json:"upload"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Upload.Status
+	}
+
+	// v1 reports the statuses it had before the upload lifecycle was added
+	assert.Equal(t, models.UploadStatusProcessing, statusOf("/api/v1/uploads/upload-queued"))
+	assert.Equal(t, models.UploadStatusCompleted, statusOf("/api/v1/uploads/upload-partial"))
+	assert.Equal(t, models.UploadStatusQueued, statusOf("/api/v2/uploads/upload-queued"))
+	assert.Equal(t, models.UploadStatusCompletedWithErrors, statusOf("/api/v2/uploads/upload-partial"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/uploads", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Uploads []models.Upload `json:"uploads"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Uploads, 2)
+	for _, upload := range list.Uploads {
+		assert.Contains(t, []string{models.UploadStatusProcessing, models.UploadStatusCompleted}, upload.Status)
+	}
+}
+
 func TestUploadHandler_ProcessUpload(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -400,7 +489,7 @@ func TestUploadHandler_ProcessUpload(t *testing.T) {
 			name:     "successful process upload",
 			uploadID: uploadID,
 			setupMock: func() {
-				mockService.ProcessUploadFunc = func(ctx context.Context, uploadID string) (interface{}, error) {
+				mockService.ProcessUploadFunc = func(ctx context.Context, uploadID string) (*services.ProcessingProgress, error) {
 					return nil, nil
 				}
 			},
@@ -466,3 +555,299 @@ func TestUploadHandler_ProcessUpload(t *testing.T) {
 		})
 	}
 }
+
+func TestUploadHandler_ProcessUploadOptions(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	fileStore := storage.NewFileStore(t.TempDir())
+
+	received := make(chan models.ProcessingOptions, 1)
+	mockService := &MockProcessingService{
+		ProcessUploadWithOptionsFunc: func(ctx context.Context, uploadID string, options models.ProcessingOptions) (*services.ProcessingProgress, error) {
+			received <- options
+			return nil, nil
+		},
+	}
+	handler := NewUploadHandler(db, fileStore, mockService)
+
+	_, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+		"upload-1", "upload-1.xlsx", "upload-1.xlsx", "uploaded")
+	require.NoError(t, err)
+	_, err = services.NewMappingProfileService(db).SaveProfile(context.Background(), "servicenow", "",
+		map[string][]string{"incident_id": {"Number"}}, nil, nil)
+	require.NoError(t, err)
+	_, err = services.NewValidationRuleSetService(db).SaveRuleSet(context.Background(), "vendor", "",
+		[]services.ValidationRule{{Type: services.RuleRequired, Field: "application_name"}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		body           string
+		role           string // signed-in role; empty for no session
+		expectedStatus int
+		expected       *models.ProcessingOptions
+	}{
+		{
+			name:           "no body uses defaults",
+			body:           "",
+			expectedStatus: http.StatusAccepted,
+			expected:       &models.ProcessingOptions{DedupStrategy: "first", RunSentiment: true, RunAutomation: true},
+		},
+		{
+			name:           "all options",
+			body:           `{"mapping_profile":"servicenow","rule_set":"vendor","dedup_strategy":"last","error_threshold":5,"run_sentiment":false,"timezone":"Europe/Berlin","dry_run":true}`,
+			expectedStatus: http.StatusAccepted,
+			expected: &models.ProcessingOptions{MappingProfile: "servicenow", RuleSet: "vendor", DedupStrategy: "last", ErrorThreshold: 5,
+				RunSentiment: false, RunAutomation: true, Timezone: "Europe/Berlin", DryRun: true},
+		},
+		{
+			name:           "urgent as analyst",
+			body:           `{"urgent":true}`,
+			role:           services.RoleAnalyst,
+			expectedStatus: http.StatusAccepted,
+			expected:       &models.ProcessingOptions{DedupStrategy: "first", RunSentiment: true, RunAutomation: true, Urgent: true},
+		},
+		{
+			name:           "urgent as viewer",
+			body:           `{"urgent":true}`,
+			role:           services.RoleViewer,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "unknown dedup strategy",
+			body:           `{"dedup_strategy":"newest"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "error threshold above 100",
+			body:           `{"error_threshold":150}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid timezone",
+			body:           `{"timezone":"Mars/Olympus"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown mapping profile",
+			body:           `{"mapping_profile":"jira"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown validation rule set",
+			body:           `{"rule_set":"strict"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown field",
+			body:           `{"dedupe":"first"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/uploads/upload-1/process", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			if tt.role != "" {
+				c.Request = c.Request.WithContext(services.WithSSOSession(c.Request.Context(),
+					&services.SSOSession{UserID: "user-1", Role: tt.role}))
+			}
+			c.Params = []gin.Param{{Key: "id", Value: "upload-1"}}
+
+			handler.ProcessUpload(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expected == nil {
+				return
+			}
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Contains(t, response, "processing_options")
+			assert.Equal(t, *tt.expected, <-received)
+		})
+	}
+}
+
+func TestUploadHandler_DiffUploads(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	fileStore := storage.NewFileStore(t.TempDir())
+	handler := NewUploadHandler(db, fileStore, new(MockProcessingService))
+
+	for _, id := range []string{"upload-a", "upload-b"} {
+		_, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+			id, id+".xlsx", id+".xlsx", "completed")
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name           string
+		uploadID       string
+		otherID        string
+		expectedStatus int
+	}{
+		{
+			name:           "successful diff",
+			uploadID:       "upload-a",
+			otherID:        "upload-b",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "same upload",
+			uploadID:       "upload-a",
+			otherID:        "upload-a",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "other upload not found",
+			uploadID:       "upload-a",
+			otherID:        "missing",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := fmt.Sprintf("/uploads/%s/diff/%s", tt.uploadID, tt.otherID)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", url, nil)
+			c.Params = []gin.Param{{Key: "id", Value: tt.uploadID}, {Key: "otherId", Value: tt.otherID}}
+
+			handler.DiffUploads(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				diff, ok := response["diff"].(map[string]interface{})
+				require.True(t, ok, "Diff should be an object")
+				assert.Equal(t, "upload-a", diff["base_upload_id"])
+				assert.Contains(t, diff, "summary")
+			}
+		})
+	}
+}
+
+func TestUploadHandler_GetUploadProfile(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewUploadHandler(db, storage.NewFileStore(t.TempDir()), new(MockProcessingService))
+
+	for _, id := range []string{"upload-a", "upload-b"} {
+		_, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+			id, id+".xlsx", id+".xlsx", "completed")
+		require.NoError(t, err)
+	}
+	require.NoError(t, services.NewUploadProfileService(db).SaveProfile(context.Background(), &services.UploadProfile{
+		UploadID: "upload-a", Status: "completed", TotalRows: 1000, StoredRows: 990,
+		ParseMs: 1200, ValidationMs: 40, AnalysisMs: 300, InsertMs: 450, TotalMs: 2000, RecordedAt: time.Now(),
+	}))
+
+	tests := []struct {
+		name           string
+		uploadID       string
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "profiled upload", uploadID: "upload-a", expectedStatus: http.StatusOK},
+		{name: "upload never profiled", uploadID: "upload-b", expectedStatus: http.StatusNotFound, expectedError: "Upload profile not found"},
+		{name: "upload not found", uploadID: "missing", expectedStatus: http.StatusNotFound, expectedError: "Upload not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", fmt.Sprintf("/uploads/%s/profile", tt.uploadID), nil)
+			c.Params = []gin.Param{{Key: "id", Value: tt.uploadID}}
+
+			handler.GetUploadProfile(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.expectedError != "" {
+				assert.Contains(t, response["message"], tt.expectedError)
+				return
+			}
+
+			profile, ok := response["data"].(map[string]interface{})
+			require.True(t, ok, "Profile should be an object")
+			assert.Equal(t, "parse", profile["slowest_stage"])
+			assert.Equal(t, 500.0, profile["rows_per_second"])
+			assert.Equal(t, 2200.0, profile["insert_rows_per_second"])
+		})
+	}
+}
+
+func TestUploadHandler_GetUploadContinuity(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewUploadHandler(db, storage.NewFileStore(t.TempDir()), new(MockProcessingService))
+
+	for _, id := range []string{"upload-a", "upload-b"} {
+		_, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+			id, id+".xlsx", id+".xlsx", "completed")
+		require.NoError(t, err)
+	}
+	_, err := services.NewUploadContinuityService(db).CheckUpload(context.Background(), "upload-a")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		uploadID       string
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "checked upload", uploadID: "upload-a", expectedStatus: http.StatusOK},
+		{name: "upload never checked", uploadID: "upload-b", expectedStatus: http.StatusNotFound, expectedError: "Upload continuity check not found"},
+		{name: "upload not found", uploadID: "missing", expectedStatus: http.StatusNotFound, expectedError: "Upload not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", fmt.Sprintf("/uploads/%s/continuity", tt.uploadID), nil)
+			c.Params = []gin.Param{{Key: "id", Value: tt.uploadID}}
+
+			handler.GetUploadContinuity(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.expectedError != "" {
+				assert.Contains(t, response["message"], tt.expectedError)
+				return
+			}
+
+			continuity, ok := response["data"].(map[string]interface{})
+			require.True(t, ok, "Continuity should be an object")
+			assert.Equal(t, services.ContinuityNoPrevious, continuity["status"])
+			assert.Equal(t, services.DefaultContinuityThreshold, continuity["threshold_pct"])
+			assert.Empty(t, continuity["discrepancies"])
+		})
+	}
+
+	t.Run("user with a data scope", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		scope := &services.DataScope{UserID: "analyst", Applications: []string{"Portal"}}
+		c.Request = httptest.NewRequest("GET", "/uploads/upload-a/continuity", nil).
+			WithContext(services.WithDataScope(context.Background(), scope))
+		c.Params = []gin.Param{{Key: "id", Value: "upload-a"}}
+
+		handler.GetUploadContinuity(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageTracking is middleware recording an anonymized usage event for each request to a known
+// route: the route pattern, the query parameter names, a hash of their values, the status and
+// latency, and hashes of the user and tenant. Users are identified by requestUser and tenants
+// by X-Tenant-ID.
+func UsageTracking(usage *services.UsageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !usage.Enabled() {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		endpoint := c.FullPath()
+		if endpoint == "" {
+			// Unmatched paths would fill the table with whatever clients probe for
+			return
+		}
+
+		filterKeys, filtersHash := usageFilters(usage, c.Request.URL.Query())
+		usage.Record(&services.UsageEvent{
+			RecordedAt:  start,
+			Method:      c.Request.Method,
+			Endpoint:    endpoint,
+			FilterKeys:  filterKeys,
+			FiltersHash: filtersHash,
+			Status:      c.Writer.Status(),
+			LatencyMs:   float64(time.Since(start).Microseconds()) / 1000,
+			UserHash:    usage.Anonymize(requestUser(c)),
+			TenantHash:  usage.Anonymize(c.GetHeader("X-Tenant-ID")),
+		})
+	}
+}
+
+// requestUser identifies the caller by the request's user ID, the X-User-ID header or, failing
+// both, the client address
+func requestUser(c *gin.Context) string {
+	if userID := logging.GetUserID(c.Request.Context()); userID != "" {
+		return userID
+	}
+	if userID := c.GetHeader("X-User-ID"); userID != "" {
+		return userID
+	}
+	return c.ClientIP()
+}
+
+// usageFilters returns the sorted query parameter names and a hash of the parameters with
+// their values, so filter combinations can be compared without storing what was searched for
+func usageFilters(usage *services.UsageService, query url.Values) ([]string, string) {
+	if len(query) == 0 {
+		return nil, ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	// Encode sorts by key, so the same filters always hash the same
+	return keys, usage.Anonymize(strings.ToLower(query.Encode()))
+}
+
+// UsageHandler serves the API usage analytics
+type UsageHandler struct {
+	usageService *services.UsageService
+	logger       *logging.Logger
+}
+
+// NewUsageHandler creates a new usage handler
+func NewUsageHandler(usageService *services.UsageService) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+		logger:       logging.GetGlobalLogger().WithComponent("usage_handler"),
+	}
+}
+
+// GetUsage handles GET /api/admin/usage
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	start := time.Now()
+	logger := h.logger.WithContext(c.Request.Context()).WithOperation("get_usage")
+
+	var query UsageQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	if query.Days == 0 {
+		query.Days = 30
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -query.Days)
+	summary, err := h.usageService.GetUsageSummary(c.Request.Context(), from, to, query.Limit)
+	if err != nil {
+		apiErr := errors.DatabaseError("get usage summary", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "usage_handler", "get_usage")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	logger.LogDuration("get_usage", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"days":     query.Days,
+			"requests": summary.TotalRequests,
+		}))
+
+	monitoring.UpdatePerformance(time.Since(start))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": summary,
+	})
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageTracking_GetUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	usage := services.NewUsageService(db, services.UsageConfig{Salt: "test", FlushInterval: time.Hour})
+	handler := NewUsageHandler(usage)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		usage.Run(ctx)
+		close(done)
+	}()
+
+	router := gin.New()
+	api := router.Group("/api", UsageTracking(usage))
+	api.GET("/analytics/trends", func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.GET("/incidents/:id/timeline", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+	router.GET("/api/admin/usage", AdminAuth("s3cret"), handler.GetUsage)
+
+	send := func(path, user string) {
+		req := httptest.NewRequest("GET", path, nil)
+		if user != "" {
+			req.Header.Set("X-User-ID", user)
+		}
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	send("/api/analytics/trends?priority=P1&application=Portal", "alice")
+	send("/api/analytics/trends?priority=P2", "alice")
+	send("/api/analytics/trends", "bob")
+	send("/api/incidents/INC-1/timeline", "bob")
+	send("/api/unknown", "carol")
+
+	// Stopping the writer flushes the queued events
+	cancel()
+	<-done
+
+	var rows int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM usage_events WHERE user_hash = 'alice'").Scan(&rows))
+	assert.Zero(t, rows, "user IDs must only be stored hashed")
+
+	t.Run("requires admin token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/usage", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("invalid days", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/admin/usage?days=400", nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/admin/usage?days=7", nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data services.UsageSummary `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		summary := response.Data
+
+		// The unmatched path and the admin request itself are not counted
+		assert.Equal(t, 4, summary.TotalRequests)
+		assert.Equal(t, 2, summary.Users)
+		require.Len(t, summary.Endpoints, 2)
+		assert.Equal(t, "/api/analytics/trends", summary.Endpoints[0].Endpoint)
+		assert.Equal(t, 3, summary.Endpoints[0].Requests)
+		assert.Equal(t, 2, summary.Endpoints[0].Users)
+		assert.Equal(t, "/api/incidents/:id/timeline", summary.Endpoints[1].Endpoint)
+		assert.Equal(t, 1.0, summary.Endpoints[1].ErrorRate)
+
+		filters := map[string]int{}
+		for _, filter := range summary.Filters {
+			filters[filter.Filter] = filter.Requests
+		}
+		assert.Equal(t, map[string]int{"priority": 2, "application": 1}, filters)
+
+		users := map[string]int{}
+		for _, user := range summary.TopUsers {
+			users[user.UserHash] = user.Endpoints
+		}
+		assert.Equal(t, map[string]int{usage.Anonymize("alice"): 1, usage.Anonymize("bob"): 2}, users)
+	})
+}
@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// dateLayout is the date format accepted by all query and body parameters
+const dateLayout = "2006-01-02"
+
+// DefaultPageSize is the page size used when a list endpoint is paginated without page_size
+const DefaultPageSize = 50
+
+var registerValidatorsOnce sync.Once
+
+// registerValidators installs the shared custom validators on gin's validator engine
+func registerValidators() {
+	registerValidatorsOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+
+		// Report fields by their wire name (form, uri or json tag) rather than the Go name
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"form", "uri", "json"} {
+				name := strings.Split(field.Tag.Get(tag), ",")[0]
+				if name != "" && name != "-" {
+					return name
+				}
+			}
+			return field.Name
+		})
+
+		v.RegisterValidation("date", validateDate)
+		v.RegisterValidation("duration", validateDuration)
+		v.RegisterValidation("csvoneof", validateCSVOneOf)
+		v.RegisterValidation("priority", validatePriority)
+		v.RegisterValidation("percentiles", validatePercentiles)
+		v.RegisterValidation("scoreboundaries", validateScoreBoundaries)
+		v.RegisterStructValidation(validateDateRange, AnalyticsQuery{})
+		v.RegisterStructValidation(validateHourlyRange, HourlyTimelineQuery{})
+	})
+}
+
+// validateDate checks that a string field is a YYYY-MM-DD date
+func validateDate(fl validator.FieldLevel) bool {
+	_, err := time.Parse(dateLayout, fl.Field().String())
+	return err == nil
+}
+
+// validateDuration checks that a string field is a positive Go duration such as 8h or 90m
+func validateDuration(fl validator.FieldLevel) bool {
+	duration, err := time.ParseDuration(fl.Field().String())
+	return err == nil && duration > 0
+}
+
+// validateCSVOneOf checks that every comma-separated value is in the space-separated parameter list
+func validateCSVOneOf(fl validator.FieldLevel) bool {
+	allowed := strings.Fields(fl.Param())
+	for _, value := range splitCSV(fl.Field().String()) {
+		if !containsString(allowed, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// validatePriority checks that a string field is a known incident priority
+func validatePriority(fl validator.FieldLevel) bool {
+	return containsString(models.ValidPriorities, fl.Field().String())
+}
+
+// validatePercentiles checks that a comma-separated list holds at most services.MaxPercentiles
+// numbers strictly between 0 and 100
+func validatePercentiles(fl validator.FieldLevel) bool {
+	values := splitCSV(fl.Field().String())
+	if len(values) > services.MaxPercentiles {
+		return false
+	}
+	for _, value := range values {
+		percentile, err := strconv.ParseFloat(value, 64)
+		if err != nil || percentile <= 0 || percentile >= 100 {
+			return false
+		}
+	}
+	return true
+}
+
+// validateScoreBoundaries checks that a string field is a comma-separated list of automation
+// score bucket boundaries: numbers between 0 and 1 with at most three decimal places
+func validateScoreBoundaries(fl validator.FieldLevel) bool {
+	values := splitCSV(fl.Field().String())
+	if len(values) > services.MaxScoreBoundaries {
+		return false
+	}
+	for _, value := range values {
+		boundary, err := strconv.ParseFloat(value, 64)
+		if err != nil || boundary <= 0 || boundary >= 1 || math.Abs(math.Round(boundary*1000)-boundary*1000) > 1e-6 {
+			return false
+		}
+	}
+	return true
+}
+
+// validateDateRange ensures end_date is not before start_date
+func validateDateRange(sl validator.StructLevel) {
+	query := sl.Current().Interface().(AnalyticsQuery)
+	if query.StartDate == "" || query.EndDate == "" {
+		return
+	}
+	startDate, startErr := time.Parse(dateLayout, query.StartDate)
+	endDate, endErr := time.Parse(dateLayout, query.EndDate)
+	if startErr != nil || endErr != nil {
+		return
+	}
+	if endDate.Before(startDate) {
+		sl.ReportError(query.EndDate, "end_date", "EndDate", "daterange", "")
+	}
+}
+
+// validateHourlyRange requires the date range of the hourly timeline and bounds its length
+func validateHourlyRange(sl validator.StructLevel) {
+	query := sl.Current().Interface().(HourlyTimelineQuery)
+	if query.StartDate == "" {
+		sl.ReportError(query.StartDate, "start_date", "StartDate", "required", "")
+	}
+	if query.EndDate == "" {
+		sl.ReportError(query.EndDate, "end_date", "EndDate", "required", "")
+	}
+	startDate, startErr := time.Parse(dateLayout, query.StartDate)
+	endDate, endErr := time.Parse(dateLayout, query.EndDate)
+	if startErr != nil || endErr != nil {
+		return
+	}
+	if endDate.Sub(startDate) >= services.MaxHourlyTimelineDays*24*time.Hour {
+		sl.ReportError(query.EndDate, "end_date", "EndDate", "hourlyrange", "")
+	}
+}
+
+// bindQuery binds and validates query parameters, sending a 400 listing every invalid field on failure
+func bindQuery(c *gin.Context, req interface{}) bool {
+	registerValidators()
+	if err := c.ShouldBindQuery(req); err != nil {
+		errors.SendError(c, validationAPIError(err))
+		return false
+	}
+	return true
+}
+
+// bindURI binds and validates path parameters, sending a 400 listing every invalid field on failure
+func bindURI(c *gin.Context, req interface{}) bool {
+	registerValidators()
+	if err := c.ShouldBindUri(req); err != nil {
+		errors.SendError(c, validationAPIError(err))
+		return false
+	}
+	return true
+}
+
+// bindJSON decodes a JSON body, rejecting unknown fields, and validates it against its struct tags
+func bindJSON(c *gin.Context, req interface{}) bool {
+	return decodeJSON(c, req, false)
+}
+
+// bindOptionalJSON is bindJSON for endpoints whose body may be omitted; an empty body is
+// validated as the zero value of req
+func bindOptionalJSON(c *gin.Context, req interface{}) bool {
+	return decodeJSON(c, req, true)
+}
+
+// decodeJSON implements bindJSON and bindOptionalJSON
+func decodeJSON(c *gin.Context, req interface{}, optional bool) bool {
+	registerValidators()
+
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(req); err != nil && !(optional && err == io.EOF) {
+		if err == io.EOF {
+			err = fmt.Errorf("request body is required")
+		}
+		errors.SendError(c, validationAPIError(err))
+		return false
+	}
+
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		errors.SendError(c, validationAPIError(err))
+		return false
+	}
+	return true
+}
+
+// validationAPIError converts binding and validator errors into a uniform validation response
+func validationAPIError(err error) *errors.APIError {
+	var validations []errors.ValidationError
+
+	var fieldErrors validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case stderrors.As(err, &fieldErrors):
+		for _, fe := range fieldErrors {
+			validations = append(validations, errors.ValidationError{
+				Field:   fe.Field(),
+				Value:   fe.Value(),
+				Message: validationMessage(fe),
+			})
+		}
+	case stderrors.As(err, &typeErr):
+		validations = append(validations, errors.ValidationError{
+			Field:   typeErr.Field,
+			Value:   typeErr.Value,
+			Message: fmt.Sprintf("must be of type %s", typeErr.Type.String()),
+		})
+	default:
+		validations = append(validations, errors.ValidationError{
+			Field:   "request",
+			Message: err.Error(),
+		})
+	}
+
+	return errors.WrapValidationErrors(validations)
+}
+
+// validationMessage renders a human-readable message for a single validator failure
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "date":
+		return "must be a date in YYYY-MM-DD format"
+	case "daterange":
+		return "must not be before start_date"
+	case "hourlyrange":
+		return fmt.Sprintf("must be within %d days of start_date", services.MaxHourlyTimelineDays)
+	case "duration":
+		return "must be a positive duration such as 8h or 90m"
+	case "datetime":
+		return "must be an RFC 3339 time such as 2024-01-15T08:00:00Z"
+	case "oneof", "csvoneof":
+		return fmt.Sprintf("must be one of: %s", strings.Join(strings.Fields(fe.Param()), ", "))
+	case "priority":
+		return fmt.Sprintf("must be one of: %s", strings.Join(models.ValidPriorities, ", "))
+	case "percentiles":
+		return fmt.Sprintf("must be a comma-separated list of at most %d numbers between 0 and 100", services.MaxPercentiles)
+	case "scoreboundaries":
+		return fmt.Sprintf("must be a comma-separated list of at most %d numbers between 0 and 1 with at most three decimal places", services.MaxScoreBoundaries)
+	case "required_if":
+		if params := strings.Fields(fe.Param()); len(params) == 2 {
+			return fmt.Sprintf("is required when %s is %s", strings.ToLower(params[0]), params[1])
+		}
+		return "is required"
+	case "nefield":
+		return fmt.Sprintf("must differ from %s", strings.ToLower(fe.Param()))
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
+	case "lte":
+		return fmt.Sprintf("must be less than or equal to %s", fe.Param())
+	case "timezone":
+		return "must be an IANA time zone name such as Europe/Berlin"
+	case "url":
+		return "must be a valid URL"
+	default:
+		return fmt.Sprintf("failed '%s' validation", fe.Tag())
+	}
+}
+
+// splitCSV splits a comma-separated parameter, trimming whitespace and dropping empty entries
+func splitCSV(value string) []string {
+	var result []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// containsString reports whether value is present in list
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDateParam parses a pre-validated YYYY-MM-DD parameter, returning nil when empty
+func parseDateParam(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	parsed, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValidationRuleSetHandler handles ingestion validation rule set endpoints
+type ValidationRuleSetHandler struct {
+	ruleSetService *services.ValidationRuleSetService
+	logger         *logging.Logger
+}
+
+// NewValidationRuleSetHandler creates a new validation rule set handler
+func NewValidationRuleSetHandler(db *sql.DB) *ValidationRuleSetHandler {
+	return &ValidationRuleSetHandler{
+		ruleSetService: services.NewValidationRuleSetService(db),
+		logger:         logging.GetGlobalLogger().WithComponent("validation_rule_set_handler"),
+	}
+}
+
+// ListRuleSets handles GET /api/validation-rule-sets
+func (h *ValidationRuleSetHandler) ListRuleSets(c *gin.Context) {
+	ruleSets, err := h.ruleSetService.ListRuleSets(c.Request.Context())
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve validation rule sets", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "validation_rule_set_handler", "list_rule_sets")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, ruleSets, nil, gin.H{
+		"data":  ruleSets,
+		"count": len(ruleSets),
+	})
+}
+
+// GetRuleSet handles GET /api/validation-rule-sets/:name
+func (h *ValidationRuleSetHandler) GetRuleSet(c *gin.Context) {
+	var params ValidationRuleSetParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	ruleSet, err := h.ruleSetService.GetRuleSet(c.Request.Context(), params.Name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Validation rule set"))
+			return
+		}
+		apiErr := errors.DatabaseError("retrieve validation rule set", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "validation_rule_set_handler", "get_rule_set")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": ruleSet,
+	})
+}
+
+// SaveRuleSet handles POST /api/validation-rule-sets
+func (h *ValidationRuleSetHandler) SaveRuleSet(c *gin.Context) {
+	var req ValidationRuleSetRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	ruleSet, err := h.ruleSetService.SaveRuleSet(c.Request.Context(), req.Name, req.Description, req.Rules)
+	if err != nil {
+		if stderrors.Is(err, services.ErrInvalidRuleSet) {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("save validation rule set", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "validation_rule_set_handler", "save_rule_set")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).Info("Validation rule set saved",
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"name":  ruleSet.Name,
+			"rules": len(ruleSet.Rules),
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": ruleSet,
+	})
+}
+
+// DeleteRuleSet handles DELETE /api/validation-rule-sets/:name
+func (h *ValidationRuleSetHandler) DeleteRuleSet(c *gin.Context) {
+	var params ValidationRuleSetParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.ruleSetService.DeleteRuleSet(c.Request.Context(), params.Name); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Validation rule set"))
+			return
+		}
+		apiErr := errors.DatabaseError("delete validation rule set", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "validation_rule_set_handler", "delete_rule_set")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Validation rule set deleted",
+	})
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationRuleSetHandler_RuleSets(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewValidationRuleSetHandler(db)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "valid rule set",
+			body:           `{"name":"vendor","description":"Vendor feed","rules":[{"type":"required","field":"application_name"}]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "replace rule set",
+			body:           `{"name":"vendor","rules":[{"type":"pattern","field":"incident_id","pattern":"^INC\\d+$"},{"type":"compare","field":"resolve_date","operator":"gte","other":"report_date"}]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing rules",
+			body:           `{"name":"legacy"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown field",
+			body:           `{"name":"legacy","rules":[{"type":"required","field":"ticket_key"}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid pattern",
+			body:           `{"name":"legacy","rules":[{"type":"pattern","field":"incident_id","pattern":"INC("}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/validation-rule-sets", strings.NewReader(tt.body))
+			handler.SaveRuleSet(c)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	// Get the replaced rule set
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/validation-rule-sets/vendor", nil)
+	c.Params = []gin.Param{{Key: "name", Value: "vendor"}}
+	handler.GetRuleSet(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data struct {
+			Rules []map[string]interface{} `json:"rules"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data.Rules, 2)
+	assert.Equal(t, "pattern", response.Data.Rules[0]["type"])
+
+	// List rule sets
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/validation-rule-sets", nil)
+	handler.ListRuleSets(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var list map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, float64(1), list["count"])
+
+	// Delete the rule set, then it is gone
+	for _, expectedStatus := range []int{http.StatusOK, http.StatusNotFound} {
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("DELETE", "/validation-rule-sets/vendor", nil)
+		c.Params = []gin.Param{{Key: "name", Value: "vendor"}}
+		handler.DeleteRuleSet(c)
+		assert.Equal(t, expectedStatus, w.Code)
+	}
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validationFields extracts the invalid field names from a validation error response
+func validationFields(t *testing.T, w *httptest.ResponseRecorder) []string {
+	var response struct {
+		Code        string `json:"code"`
+		Validations []struct {
+			Field   string `json:"field"`
+			Message string `json:"message"`
+		} `json:"validations"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "VALIDATION_ERROR", response.Code)
+
+	var fields []string
+	for _, v := range response.Validations {
+		fields = append(fields, v.Field)
+	}
+	return fields
+}
+
+func TestBindQuery_AnalyticsQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectValid    bool
+		expectedFields []string
+	}{
+		{
+			name:        "no parameters",
+			query:       "",
+			expectValid: true,
+		},
+		{
+			name:        "valid parameters",
+			query:       "start_date=2024-01-01&end_date=2024-01-31&priorities=P1,P2&applications=App1",
+			expectValid: true,
+		},
+		{
+			name:           "all invalid fields reported at once",
+			query:          "start_date=01/01/2024&end_date=2024-13-01&priorities=P1,P9",
+			expectedFields: []string{"start_date", "end_date", "priorities"},
+		},
+		{
+			name:           "end date before start date",
+			query:          "start_date=2024-02-01&end_date=2024-01-01",
+			expectedFields: []string{"end_date"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/analytics/summary?"+tt.query, nil)
+
+			var query AnalyticsQuery
+			ok := bindQuery(c, &query)
+
+			assert.Equal(t, tt.expectValid, ok)
+			if tt.expectValid {
+				filters := query.ToFilters()
+				if strings.Contains(tt.query, "priorities") {
+					assert.Equal(t, []string{"P1", "P2"}, filters.Priorities)
+				}
+				return
+			}
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.ElementsMatch(t, tt.expectedFields, validationFields(t, w))
+		})
+	}
+}
+
+func TestBindQuery_Pagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/uploads?page=-1&page_size=1000", nil)
+
+	var pagination PaginationQuery
+	assert.False(t, bindQuery(c, &pagination))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.ElementsMatch(t, []string{"page", "page_size"}, validationFields(t, w))
+
+	pagination = PaginationQuery{Page: 3, PageSize: 20}
+	assert.True(t, pagination.IsSet())
+	assert.Equal(t, 20, pagination.Limit())
+	assert.Equal(t, 40, pagination.Offset())
+	assert.Equal(t, DefaultPageSize, PaginationQuery{}.Limit())
+}
+
+func TestBindJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type request struct {
+		Name     string `json:"name" binding:"required"`
+		Priority string `json:"priority" binding:"required,priority"`
+		DueDate  string `json:"due_date" binding:"omitempty,date"`
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		expectValid    bool
+		expectedFields []string
+	}{
+		{
+			name:        "valid body",
+			body:        `{"name":"test","priority":"P2","due_date":"2024-01-01"}`,
+			expectValid: true,
+		},
+		{
+			name:           "multiple invalid fields",
+			body:           `{"priority":"P7","due_date":"tomorrow"}`,
+			expectedFields: []string{"name", "priority", "due_date"},
+		},
+		{
+			name:           "unknown field rejected",
+			body:           `{"name":"test","priority":"P1","extra":true}`,
+			expectedFields: []string{"request"},
+		},
+		{
+			name:           "empty body",
+			body:           ``,
+			expectedFields: []string{"request"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/test", strings.NewReader(tt.body))
+
+			var req request
+			ok := bindJSON(c, &req)
+
+			assert.Equal(t, tt.expectValid, ok)
+			if !tt.expectValid {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				assert.ElementsMatch(t, tt.expectedFields, validationFields(t, w))
+			}
+		})
+	}
+}
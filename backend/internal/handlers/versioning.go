@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API versions. Each is served under /api/<version>; the unversioned /api paths serve v1 for
+// clients written before versioning and are deprecated.
+const (
+	// APIVersion1 freezes the response shapes the API had when versioning was introduced
+	APIVersion1 = "v1"
+	// APIVersion2 is where endpoints with new or changed response shapes are added
+	APIVersion2 = "v2"
+)
+
+// APIBasePath is the path prefix of every API route, and of the unversioned routes
+const APIBasePath = "/api"
+
+// APIVersionHeader names the response header carrying the API version that served a request
+const APIVersionHeader = "API-Version"
+
+// apiVersionKey is the gin context key holding the version of the route being served
+const apiVersionKey = "api_version"
+
+// APIVersionInfo describes an API version for GET /api/versions
+type APIVersionInfo struct {
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	// Status is current for the latest version, supported for older ones and deprecated for
+	// ones that will be removed
+	Status string     `json:"status"`
+	Sunset *time.Time `json:"sunset,omitempty"`
+}
+
+// Deprecation describes deprecated routes for the Deprecation, Sunset and Link response headers
+// of RFC 9745 and RFC 8594
+type Deprecation struct {
+	// Since is when the routes were deprecated
+	Since time.Time
+	// Sunset is when the routes will be removed; zero when no date is set
+	Sunset time.Time
+	// Successor returns the path of the route replacing the requested one; nil when there is none
+	Successor func(c *gin.Context) string
+}
+
+// APIVersionPath returns the path prefix of an API version, such as /api/v1
+func APIVersionPath(version string) string {
+	return APIBasePath + "/" + version
+}
+
+// APIVersion is middleware for a route group serving an API version. It records the version for
+// handlers and reports it in the API-Version response header. Versions after v1 send errors in
+// the response envelope.
+func APIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiVersionKey, version)
+		c.Header(APIVersionHeader, version)
+		if version != APIVersion1 {
+			errors.UseEnvelope(c)
+		}
+		c.Next()
+	}
+}
+
+// RequestAPIVersion returns the API version serving the request, v1 outside a versioned group
+func RequestAPIVersion(c *gin.Context) string {
+	if version := c.GetString(apiVersionKey); version != "" {
+		return version
+	}
+	return APIVersion1
+}
+
+// apiPathPrefix returns the path prefix of links to other endpoints of the API version serving
+// the request, or the unversioned prefix outside a versioned group
+func apiPathPrefix(c *gin.Context) string {
+	if version := c.GetString(apiVersionKey); version != "" {
+		return APIVersionPath(version)
+	}
+	return APIBasePath
+}
+
+// Deprecated is middleware flagging routes as deprecated. Responses carry a Deprecation header
+// with the deprecation date, a Sunset header when a removal date is set and a successor-version
+// Link to the replacing route. Requests are served as before.
+func Deprecated(deprecation Deprecation) gin.HandlerFunc {
+	since := "@" + strconv.FormatInt(deprecation.Since.Unix(), 10)
+	sunset := ""
+	if !deprecation.Sunset.IsZero() {
+		sunset = deprecation.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", since)
+		if sunset != "" {
+			c.Header("Sunset", sunset)
+		}
+		if deprecation.Successor != nil {
+			if successor := deprecation.Successor(c); successor != "" {
+				c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+			}
+		}
+		c.Next()
+	}
+}
+
+// SuccessorPrefix returns a Deprecation.Successor replacing the path prefix from with to, for
+// routes that moved as a group
+func SuccessorPrefix(from, to string) func(c *gin.Context) string {
+	return func(c *gin.Context) string {
+		path := c.Request.URL.Path
+		if !strings.HasPrefix(path, from) {
+			return ""
+		}
+		successor := to + strings.TrimPrefix(path, from)
+		if c.Request.URL.RawQuery != "" {
+			successor += "?" + c.Request.URL.RawQuery
+		}
+		return successor
+	}
+}
+
+// ListAPIVersions returns a handler for GET /api/versions, listing the API versions clients
+// can use
+func ListAPIVersions(versions []APIVersionInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"data":  versions,
+			"count": len(versions),
+		})
+	}
+}
+
+// v1UploadStatuses maps the upload statuses added after v1 was frozen to the status v1 reported
+// for them: a queued upload was already processing, and an upload with rejected rows completed
+var v1UploadStatuses = map[string]string{
+	models.UploadStatusQueued:              models.UploadStatusProcessing,
+	models.UploadStatusCompletedWithErrors: models.UploadStatusCompleted,
+}
+
+// versionedUploadStatus returns an upload status as the API version serving the request reports it
+func versionedUploadStatus(c *gin.Context, status string) string {
+	if RequestAPIVersion(c) == APIVersion1 {
+		if legacy, ok := v1UploadStatuses[status]; ok {
+			return legacy
+		}
+	}
+	return status
+}
+
+// versionUploads reports the statuses of uploads as the API version serving the request does
+func versionUploads(c *gin.Context, uploads []models.Upload) {
+	for i := range uploads {
+		uploads[i].Status = versionedUploadStatus(c, uploads[i].Status)
+	}
+}
+
+// versionDataset reports the upload status counts of a dataset as the API version serving the
+// request does, adding up the counts of statuses v1 reports as one
+func versionDataset(c *gin.Context, dataset *services.Dataset) {
+	if RequestAPIVersion(c) != APIVersion1 || dataset.UploadStatus == nil {
+		return
+	}
+	counts := make(map[string]int, len(dataset.UploadStatus))
+	for status, count := range dataset.UploadStatus {
+		counts[versionedUploadStatus(c, status)] += count
+	}
+	dataset.UploadStatus = counts
+}
+
+// versionedUploadStatuses returns the upload statuses the API version serving the request reports
+func versionedUploadStatuses(c *gin.Context) []string {
+	if RequestAPIVersion(c) != APIVersion1 {
+		return models.ValidUploadStatuses
+	}
+	statuses := make([]string, 0, len(models.ValidUploadStatuses))
+	for _, status := range models.ValidUploadStatuses {
+		if _, added := v1UploadStatuses[status]; !added {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
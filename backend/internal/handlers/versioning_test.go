@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIVersioning(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	version := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"version": RequestAPIVersion(c), "prefix": apiPathPrefix(c)})
+	}
+
+	since := time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+	router.Group("/api/v2", APIVersion(APIVersion2)).GET("/uploads", version)
+	router.Group("/api", APIVersion(APIVersion1), Deprecated(Deprecation{
+		Since:     since,
+		Sunset:    sunset,
+		Successor: SuccessorPrefix("/api", "/api/v1"),
+	})).GET("/uploads", version)
+	router.GET("/plain", version)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		return w
+	}
+
+	t.Run("versioned route", func(t *testing.T) {
+		w := serve("/api/v2/uploads")
+		assert.Equal(t, APIVersion2, w.Header().Get(APIVersionHeader))
+		assert.JSONEq(t, `{"version":"v2","prefix":"/api/v2"}`, w.Body.String())
+		assert.Empty(t, w.Header().Get("Deprecation"))
+	})
+
+	t.Run("deprecated route", func(t *testing.T) {
+		w := serve("/api/uploads?page=2")
+		assert.Equal(t, APIVersion1, w.Header().Get(APIVersionHeader))
+		assert.Equal(t, "@1792108800", w.Header().Get("Deprecation"))
+		assert.Equal(t, "Thu, 01 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+		assert.Equal(t, `</api/v1/uploads?page=2>; rel="successor-version"`, w.Header().Get("Link"))
+	})
+
+	t.Run("route outside a versioned group", func(t *testing.T) {
+		w := serve("/plain")
+		assert.Empty(t, w.Header().Get(APIVersionHeader))
+		assert.JSONEq(t, `{"version":"v1","prefix":"/api"}`, w.Body.String())
+	})
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WatchlistHandler handles the endpoints of the calling user's watchlists
+type WatchlistHandler struct {
+	watchlistService *services.WatchlistService
+	logger           *logging.Logger
+}
+
+// NewWatchlistHandler creates a new watchlist handler. The service is shared with the scheduler
+// that evaluates watchlists in the background.
+func NewWatchlistHandler(watchlistService *services.WatchlistService) *WatchlistHandler {
+	return &WatchlistHandler{
+		watchlistService: watchlistService,
+		logger:           logging.GetGlobalLogger().WithComponent("watchlist_handler"),
+	}
+}
+
+// ListWatchlists handles GET /api/watchlists
+func (h *WatchlistHandler) ListWatchlists(c *gin.Context) {
+	watchlists, err := h.watchlistService.ListWatchlists(c.Request.Context(), requestUser(c))
+	if err != nil {
+		apiErr := errors.DatabaseError("retrieve watchlists", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "watchlist_handler", "list_watchlists")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, watchlists, nil, gin.H{
+		"data":  watchlists,
+		"count": len(watchlists),
+	})
+}
+
+// GetWatchlist handles GET /api/watchlists/:id
+func (h *WatchlistHandler) GetWatchlist(c *gin.Context) {
+	var params WatchlistParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	watchlist, err := h.watchlistService.GetWatchlist(c.Request.Context(), requestUser(c), params.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Watchlist"))
+			return
+		}
+		apiErr := errors.DatabaseError("retrieve watchlist", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "watchlist_handler", "get_watchlist")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": watchlist,
+	})
+}
+
+// CreateWatchlist handles POST /api/watchlists
+func (h *WatchlistHandler) CreateWatchlist(c *gin.Context) {
+	start := time.Now()
+
+	var req WatchlistRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	created, err := h.watchlistService.CreateWatchlist(c.Request.Context(), requestUser(c), req.ToWatchlist())
+	if err != nil {
+		if stderrors.Is(err, services.ErrInvalidWatchlist) {
+			errors.SendError(c, errors.BadRequest(err.Error()))
+			return
+		}
+		apiErr := errors.DatabaseError("create watchlist", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "watchlist_handler", "create_watchlist")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).LogDuration("create_watchlist", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"watchlist_id": created.ID,
+			"entity_type":  created.EntityType,
+			"entities":     len(created.Entities),
+		}))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": created,
+	})
+}
+
+// UpdateWatchlist handles PUT /api/watchlists/:id
+func (h *WatchlistHandler) UpdateWatchlist(c *gin.Context) {
+	var params WatchlistParams
+	if !bindURI(c, &params) {
+		return
+	}
+	var req WatchlistRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	updated, err := h.watchlistService.UpdateWatchlist(c.Request.Context(), requestUser(c), params.ID, req.ToWatchlist())
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			errors.SendError(c, errors.NotFound("Watchlist"))
+		case stderrors.Is(err, services.ErrInvalidWatchlist):
+			errors.SendError(c, errors.BadRequest(err.Error()))
+		default:
+			apiErr := errors.DatabaseError("update watchlist", err)
+			monitoring.TrackError(c.Request.Context(), apiErr, "watchlist_handler", "update_watchlist")
+			errors.SendError(c, apiErr)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": updated,
+	})
+}
+
+// DeleteWatchlist handles DELETE /api/watchlists/:id
+func (h *WatchlistHandler) DeleteWatchlist(c *gin.Context) {
+	var params WatchlistParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	if err := h.watchlistService.DeleteWatchlist(c.Request.Context(), requestUser(c), params.ID); err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Watchlist"))
+			return
+		}
+		apiErr := errors.DatabaseError("delete watchlist", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "watchlist_handler", "delete_watchlist")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Watchlist deleted",
+	})
+}
+
+// ListAlerts handles GET /api/watchlists/:id/alerts
+func (h *WatchlistHandler) ListAlerts(c *gin.Context) {
+	var params WatchlistParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	alerts, err := h.watchlistService.ListAlerts(c.Request.Context(), requestUser(c), params.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Watchlist"))
+			return
+		}
+		apiErr := errors.DatabaseError("retrieve watchlist alerts", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "watchlist_handler", "list_alerts")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	sendList(c, alerts, nil, gin.H{
+		"data":  alerts,
+		"count": len(alerts),
+	})
+}
+
+// EvaluateWatchlist handles POST /api/watchlists/:id/evaluate
+func (h *WatchlistHandler) EvaluateWatchlist(c *gin.Context) {
+	start := time.Now()
+
+	var params WatchlistParams
+	if !bindURI(c, &params) {
+		return
+	}
+
+	evaluation, err := h.watchlistService.EvaluateWatchlist(c.Request.Context(), requestUser(c), params.ID, time.Now())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errors.SendError(c, errors.NotFound("Watchlist"))
+			return
+		}
+		apiErr := errors.DatabaseError("evaluate watchlist", err)
+		monitoring.TrackError(c.Request.Context(), apiErr, "watchlist_handler", "evaluate_watchlist")
+		errors.SendError(c, apiErr)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).LogDuration("evaluate_watchlist", start,
+		logging.GetGlobalLogger().WithMetadata(map[string]interface{}{
+			"watchlist_id": evaluation.WatchlistID,
+			"alerts":       len(evaluation.Alerts),
+		}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": evaluation,
+	})
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"incident-management-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchlistHandler_CRUD(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	db := createTestDB(t)
+	handler := NewWatchlistHandler(services.NewWatchlistService(db, services.NewNotifier(nil)))
+
+	request := func(user, method, target, body string, params gin.Params, handle gin.HandlerFunc) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("X-User-ID", user)
+		c.Params = params
+		handle(c)
+		return w
+	}
+
+	// Create a watchlist
+	w := request("alice", "POST", "/watchlists",
+		`{"name":"Web apps","entity_type":"application","entities":["Portal"],"volume_increase_pct":50,"slack_webhook_url":"https://hooks.example.com/abc"}`,
+		nil, handler.CreateWatchlist)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	id := created.Data["id"].(string)
+	assert.Equal(t, true, created.Data["slack_configured"])
+	assert.NotContains(t, w.Body.String(), "hooks.example.com", "the webhook URL is not returned")
+
+	// Unknown entity types and watchlists without thresholds are rejected
+	w = request("alice", "POST", "/watchlists", `{"name":"Bad","entity_type":"team","entities":["Portal"],"volume_increase_pct":50}`,
+		nil, handler.CreateWatchlist)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request("alice", "POST", "/watchlists", `{"name":"Bad","entity_type":"group","entities":["Web Team"]}`,
+		nil, handler.CreateWatchlist)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Other users cannot see or change it
+	params := gin.Params{{Key: "id", Value: id}}
+	w = request("bob", "GET", "/watchlists/"+id, "", params, handler.GetWatchlist)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = request("bob", "DELETE", "/watchlists/"+id, "", params, handler.DeleteWatchlist)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Replace it
+	w = request("alice", "PUT", "/watchlists/"+id,
+		`{"name":"Web apps","entity_type":"application","entities":["Portal","Billing"],"resolution_increase_pct":30,"period_days":14}`,
+		params, handler.UpdateWatchlist)
+	require.Equal(t, http.StatusOK, w.Code)
+	var updated struct {
+		Data services.Watchlist `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, []string{"Billing", "Portal"}, updated.Data.Entities)
+	assert.Equal(t, 14, updated.Data.PeriodDays)
+	assert.Nil(t, updated.Data.VolumeIncreasePct)
+	assert.False(t, updated.Data.SlackConfigured)
+
+	// Evaluate it without incidents
+	w = request("alice", "POST", "/watchlists/"+id+"/evaluate", "", params, handler.EvaluateWatchlist)
+	require.Equal(t, http.StatusOK, w.Code)
+	var evaluated struct {
+		Data services.WatchlistEvaluation `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &evaluated))
+	assert.Len(t, evaluated.Data.Entities, 2)
+	assert.Empty(t, evaluated.Data.Alerts)
+
+	w = request("alice", "GET", "/watchlists", "", nil, handler.ListWatchlists)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":1`)
+
+	w = request("alice", "DELETE", "/watchlists/"+id, "", params, handler.DeleteWatchlist)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = request("alice", "GET", "/watchlists/"+id+"/alerts", "", params, handler.ListAlerts)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
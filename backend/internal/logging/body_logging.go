@@ -0,0 +1,320 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redacted replaces sensitive values in logged bodies
+const redacted = "[REDACTED]"
+
+// BodyLogConfig selects the requests whose bodies are logged while debugging client integrations
+type BodyLogConfig struct {
+	Enabled bool `json:"enabled"`
+	// Routes are path prefixes or route patterns such as /api/uploads/:id; only matching
+	// requests are logged
+	Routes []string `json:"routes"`
+	// MaxBodyBytes caps how much of each request and response body is logged
+	MaxBodyBytes int `json:"max_body_bytes"`
+}
+
+// DefaultBodyLogConfig returns body logging turned off with a 4KB body cap
+func DefaultBodyLogConfig() BodyLogConfig {
+	return BodyLogConfig{MaxBodyBytes: 4096}
+}
+
+// Validate checks that an enabled configuration names the routes to log
+func (c BodyLogConfig) Validate() error {
+	if c.Enabled && len(c.Routes) == 0 {
+		return fmt.Errorf("at least one route is required to enable body logging")
+	}
+	if c.MaxBodyBytes < 0 || c.MaxBodyBytes > 1<<20 {
+		return fmt.Errorf("max_body_bytes must be between 0 and 1048576")
+	}
+	return nil
+}
+
+// sensitiveKeys are field names, matched case-insensitively as substrings, whose values are
+// always redacted: credentials and personal data
+var sensitiveKeys = []string{
+	"password", "passwd", "secret", "token", "authorization", "api_key", "apikey", "cookie",
+	"session", "credential", "private_key", "email", "phone", "ssn", "webhook",
+}
+
+// isSensitiveKey reports whether values under key must be redacted
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// Patterns redacted from any text, whatever field they appear in
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	bearerPattern = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._~+/=-]+`)
+	jwtPattern    = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	// Observer API key secrets, and the tokens of report share links, which grant access alone
+	observerKeyPattern = regexp.MustCompile(`ims_obs_[A-Za-z0-9_-]+`)
+	shareLinkPattern   = regexp.MustCompile(`/shared/[^/?#\s"]+`)
+	// Sensitive fields of JSON that could not be parsed, such as a truncated body
+	jsonFieldPattern = regexp.MustCompile(`("([^"\\]*)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+)
+
+// BodyLogger logs the sanitized request and response bodies of selected routes. It is off
+// unless enabled, and its configuration can be changed while the server runs.
+type BodyLogger struct {
+	logger *Logger
+
+	mu     sync.RWMutex
+	config BodyLogConfig
+}
+
+// NewBodyLogger creates a body logger with an initial configuration
+func NewBodyLogger(logger *Logger, config BodyLogConfig) (*BodyLogger, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &BodyLogger{logger: logger.WithComponent("body_logging"), config: config}, nil
+}
+
+// Config returns the current configuration
+func (b *BodyLogger) Config() BodyLogConfig {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	config := b.config
+	config.Routes = append([]string(nil), b.config.Routes...)
+	return config
+}
+
+// Update replaces the configuration, taking effect for the next request
+func (b *BodyLogger) Update(config BodyLogConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.config = config
+	b.mu.Unlock()
+
+	b.logger.Warn("Body logging reconfigured",
+		slog.Bool("enabled", config.Enabled),
+		slog.Any("routes", config.Routes),
+		slog.Int("max_body_bytes", config.MaxBodyBytes))
+	return nil
+}
+
+// matches reports whether a request is on a logged route
+func (c BodyLogConfig) matches(routePattern, path string) bool {
+	for _, route := range c.Routes {
+		if route == routePattern || strings.HasPrefix(path, route) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware logs the bodies of requests on the configured routes. Bodies are captured while
+// the handler streams them, up to the size cap, so large uploads are never buffered.
+func (b *BodyLogger) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		config := b.Config()
+		if !config.Enabled || !config.matches(c.FullPath(), c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		request := &capture{limit: config.MaxBodyBytes}
+		if c.Request.Body != nil {
+			c.Request.Body = &captureReader{ReadCloser: c.Request.Body, capture: request}
+		}
+		response := &captureWriter{ResponseWriter: c.Writer, capture: capture{limit: config.MaxBodyBytes}}
+		c.Writer = response
+
+		c.Next()
+
+		b.logger.WithContext(c.Request.Context()).Info("Request and response bodies",
+			slog.String("method", c.Request.Method),
+			slog.String("path", redactText(c.Request.URL.Path)),
+			slog.String("query", redactQuery(c.Request.URL.RawQuery)),
+			slog.Int("status", c.Writer.Status()),
+			slog.Group("request",
+				slog.String("content_type", c.Request.Header.Get("Content-Type")),
+				slog.String("body", request.sanitized(c.Request.Header.Get("Content-Type"))),
+				slog.Int64("size", request.size),
+				slog.Bool("truncated", request.truncated())),
+			slog.Group("response",
+				slog.String("content_type", response.Header().Get("Content-Type")),
+				slog.String("body", response.capture.sanitized(response.Header().Get("Content-Type"))),
+				slog.Int64("size", response.capture.size),
+				slog.Bool("truncated", response.capture.truncated())),
+		)
+	}
+}
+
+// capture keeps the first limit bytes of a body and counts the rest
+type capture struct {
+	limit int
+	buf   bytes.Buffer
+	size  int64
+}
+
+func (c *capture) record(p []byte) {
+	c.size += int64(len(p))
+	if room := c.limit - c.buf.Len(); room > 0 {
+		if len(p) > room {
+			p = p[:room]
+		}
+		c.buf.Write(p)
+	}
+}
+
+func (c *capture) truncated() bool {
+	return c.size > int64(c.buf.Len())
+}
+
+// sanitized returns the captured body with sensitive values redacted. Bodies that are not
+// text, such as spreadsheet uploads, are summarized rather than logged.
+func (c *capture) sanitized(contentType string) string {
+	if c.size == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return redactJSON(c.buf.Bytes())
+	case mediaType == "application/x-www-form-urlencoded":
+		return redactQuery(c.buf.String())
+	case strings.HasPrefix(mediaType, "text/") || mediaType == "application/x-ndjson":
+		return redactText(c.buf.String())
+	default:
+		return fmt.Sprintf("[%d bytes of %s omitted]", c.size, contentType)
+	}
+}
+
+// captureReader records a request body as the handler reads it
+type captureReader struct {
+	io.ReadCloser
+	capture *capture
+}
+
+func (r *captureReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.capture.record(p[:n])
+	return n, err
+}
+
+// captureWriter records a response body as the handler writes it
+type captureWriter struct {
+	gin.ResponseWriter
+	capture capture
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	w.capture.record(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.capture.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// redactJSON redacts sensitive fields and values of a JSON body. A body cut off by the size cap
+// cannot be parsed and is redacted field by field instead.
+func redactJSON(body []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return redactText(jsonFieldPattern.ReplaceAllStringFunc(string(body), func(field string) string {
+			parts := jsonFieldPattern.FindStringSubmatch(field)
+			if !isSensitiveKey(parts[2]) {
+				return field
+			}
+			return parts[1] + `"` + redacted + `"`
+		}))
+	}
+
+	sanitized, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return redacted
+	}
+	return string(sanitized)
+}
+
+// redactValue redacts a decoded JSON value in place
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSensitiveKey(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+		return v
+	case string:
+		return redactText(v)
+	default:
+		return v
+	}
+}
+
+// redactQuery redacts sensitive parameters of a query string or form body
+func redactQuery(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return redactText(raw)
+	}
+
+	// Values are left unescaped so the log stays readable
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(values))
+	for _, key := range keys {
+		for _, value := range values[key] {
+			if isSensitiveKey(key) {
+				value = redacted
+			}
+			pairs = append(pairs, key+"="+redactText(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// redactText removes email addresses, bearer tokens, JWTs, observer API keys and share link
+// tokens from free text
+func redactText(text string) string {
+	text = bearerPattern.ReplaceAllString(text, "Bearer "+redacted)
+	text = jwtPattern.ReplaceAllString(text, redacted)
+	text = observerKeyPattern.ReplaceAllString(text, redacted)
+	text = shareLinkPattern.ReplaceAllString(text, "/shared/"+redacted)
+	return emailPattern.ReplaceAllString(text, redacted)
+}
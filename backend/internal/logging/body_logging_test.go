@@ -0,0 +1,149 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRedactJSON(t *testing.T) {
+	body := `{"user":{"email":"jane@example.com","Password":"hunter2"},"api_token":"abc","note":"contact bob@example.com","items":[{"session_id":"s1","count":2}]}`
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(redactJSON([]byte(body))), &got); err != nil {
+		t.Fatalf("redacted body is not JSON: %v", err)
+	}
+
+	user := got["user"].(map[string]interface{})
+	if user["email"] != redacted || user["Password"] != redacted || got["api_token"] != redacted {
+		t.Errorf("sensitive fields not redacted: %v", got)
+	}
+	if got["note"] != "contact "+redacted {
+		t.Errorf("email in free text not redacted: %v", got["note"])
+	}
+	item := got["items"].([]interface{})[0].(map[string]interface{})
+	if item["session_id"] != redacted || item["count"] != float64(2) {
+		t.Errorf("unexpected nested item %v", item)
+	}
+}
+
+func TestRedactJSON_Truncated(t *testing.T) {
+	got := redactJSON([]byte(`{"name":"Upload","password":"hunter2","token":"abc123`))
+	if strings.Contains(got, "hunter2") || strings.Contains(got, "abc123") {
+		t.Errorf("truncated body leaked a secret: %s", got)
+	}
+	if !strings.Contains(got, `"name":"Upload"`) {
+		t.Errorf("truncated body lost an ordinary field: %s", got)
+	}
+}
+
+func TestRedactQueryAndText(t *testing.T) {
+	if got := redactQuery("page=2&access_token=abc&q=ops@example.com"); got != "access_token=[REDACTED]&page=2&q=[REDACTED]" {
+		t.Errorf("redactQuery() = %q", got)
+	}
+	if got := redactText("Authorization: Bearer abc.def-123"); got != "Authorization: Bearer [REDACTED]" {
+		t.Errorf("redactText() = %q", got)
+	}
+}
+
+func TestRedactJSON_SharedSecrets(t *testing.T) {
+	body := `{"key":"ims_obs_Ab3-x_9","url":"/api/v2/shared/f00dcafe?format=json","slack_webhook_url":"https://hooks.slack.com/services/T0/B0/x"}`
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(redactJSON([]byte(body))), &got); err != nil {
+		t.Fatalf("redacted body is not JSON: %v", err)
+	}
+	if got["key"] != redacted || got["slack_webhook_url"] != redacted {
+		t.Errorf("observer key or webhook not redacted: %v", got)
+	}
+	if got["url"] != "/api/v2/shared/"+redacted+"?format=json" {
+		t.Errorf("share link token not redacted: %v", got["url"])
+	}
+}
+
+func TestBodyLogger_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := filepath.Join(t.TempDir(), "body.log")
+	logger, err := NewLogger(&Config{Level: LevelInfo, Format: "json", Output: path, TimeFormat: time.RFC3339})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	bodyLogger, err := NewBodyLogger(logger, BodyLogConfig{Enabled: true, Routes: []string{"/api/incidents"}, MaxBodyBytes: 64})
+	if err != nil {
+		t.Fatalf("NewBodyLogger() error = %v", err)
+	}
+
+	router := gin.New()
+	router.Use(bodyLogger.Middleware())
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "application/json", body)
+	}
+	router.POST("/api/incidents", echo)
+	router.POST("/api/uploads", echo)
+
+	send := func(path, body string) string {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	body := `{"title":"Login broken","reporter_email":"jane@example.com"}`
+	if got := send("/api/incidents", body); got != body {
+		t.Errorf("handler saw a different body: %q", got)
+	}
+	send("/api/uploads", `{"title":"not logged"}`)
+	send("/api/incidents", `{"title":"`+strings.Repeat("x", 100)+`"}`)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d body log lines, want 2: %s", len(lines), data)
+	}
+
+	var first struct {
+		Path    string `json:"path"`
+		Request struct {
+			Body string `json:"body"`
+			Size int    `json:"size"`
+		} `json:"request"`
+		Response struct {
+			Body string `json:"body"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("log line is not JSON: %v", err)
+	}
+	if first.Path != "/api/incidents" || first.Request.Size != len(body) {
+		t.Errorf("unexpected log line %s", lines[0])
+	}
+	if strings.Contains(lines[0], "jane@example.com") || !strings.Contains(first.Request.Body, "Login broken") {
+		t.Errorf("request body not redacted as expected: %q", first.Request.Body)
+	}
+	if first.Response.Body != first.Request.Body {
+		t.Errorf("response body %q, want %q", first.Response.Body, first.Request.Body)
+	}
+
+	if !bytes.Contains([]byte(lines[1]), []byte(`"truncated":true`)) {
+		t.Errorf("expected the oversized body to be marked truncated: %s", lines[1])
+	}
+
+	if err := bodyLogger.Update(BodyLogConfig{Enabled: true}); err == nil {
+		t.Errorf("expected enabling without routes to fail")
+	}
+}
@@ -28,6 +28,7 @@ const (
 type Logger struct {
 	*slog.Logger
 	level slog.Level
+	sinks []*asyncSink
 }
 
 // LogEntry represents a structured log entry
@@ -54,6 +55,9 @@ type Config struct {
 	Output     string   `json:"output"` // "stdout", "stderr", or file path
 	AddSource  bool     `json:"add_source"`
 	TimeFormat string   `json:"time_format"`
+	// Sinks are external destinations that receive records alongside Output, each with its own
+	// level and buffered delivery
+	Sinks []SinkConfig `json:"sinks"`
 }
 
 // DefaultConfig returns a default logger configuration
@@ -90,21 +94,7 @@ func NewLogger(config *Config) (*Logger, error) {
 	}
 
 	// Configure slog level
-	var level slog.Level
-	switch config.Level {
-	case LevelDebug:
-		level = slog.LevelDebug
-	case LevelInfo:
-		level = slog.LevelInfo
-	case LevelWarn:
-		level = slog.LevelWarn
-	case LevelError:
-		level = slog.LevelError
-	case LevelFatal:
-		level = slog.LevelError // slog doesn't have fatal, use error
-	default:
-		level = slog.LevelInfo
-	}
+	level := slogLevel(config.Level, slog.LevelInfo)
 
 	// Create handler options
 	opts := &slog.HandlerOptions{
@@ -130,13 +120,80 @@ func NewLogger(config *Config) (*Logger, error) {
 		handler = slog.NewTextHandler(writer, opts)
 	}
 
+	// Fan out to the external sinks, each filtering at its own level
+	var sinks []*asyncSink
+	if len(config.Sinks) > 0 {
+		handlers := []slog.Handler{handler}
+		for _, sinkConfig := range config.Sinks {
+			sinkConfig = sinkConfig.withDefaults()
+			sinkWriter, err := newSinkWriter(sinkConfig, config.TimeFormat)
+			if err != nil {
+				for _, sink := range sinks {
+					sink.Close()
+				}
+				return nil, fmt.Errorf("failed to open %s log sink: %w", sinkConfig.Type, err)
+			}
+			sink := newAsyncSink(sinkConfig, sinkWriter)
+			sinks = append(sinks, sink)
+
+			sinkOpts := *opts
+			sinkOpts.Level = slogLevel(sinkConfig.Level, level)
+			if sinkConfig.Type == SinkFile && sinkConfig.Format == "text" {
+				handlers = append(handlers, slog.NewTextHandler(sink, &sinkOpts))
+			} else {
+				handlers = append(handlers, slog.NewJSONHandler(sink, &sinkOpts))
+			}
+		}
+		handler = &multiHandler{handlers: handlers}
+	}
+
 	logger := slog.New(handler)
 	return &Logger{
 		Logger: logger,
 		level:  level,
+		sinks:  sinks,
 	}, nil
 }
 
+// slogLevel converts a log level to its slog level, using fallback when the level is not set
+func slogLevel(level LogLevel, fallback slog.Level) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	case LevelFatal:
+		return slog.LevelError // slog doesn't have fatal, use error
+	default:
+		return fallback
+	}
+}
+
+// SinkStats reports the delivery counters of the logger's external sinks
+func (l *Logger) SinkStats() []SinkStats {
+	stats := make([]SinkStats, 0, len(l.sinks))
+	for _, sink := range l.sinks {
+		stats = append(stats, sink.stats())
+	}
+	return stats
+}
+
+// Close delivers the records still buffered for the external sinks and closes them. Loggers
+// derived with the With methods share their sinks, so only the root logger needs closing.
+func (l *Logger) Close() error {
+	var firstErr error
+	for _, sink := range l.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
 // WithContext adds context information to the logger
 func (l *Logger) WithContext(ctx context.Context) *Logger {
 	attrs := []any{}
@@ -154,6 +211,7 @@ func (l *Logger) WithContext(ctx context.Context) *Logger {
 	return &Logger{
 		Logger: l.Logger.With(attrs...),
 		level:  l.level,
+		sinks:  l.sinks,
 	}
 }
 
@@ -162,6 +220,7 @@ func (l *Logger) WithComponent(component string) *Logger {
 	return &Logger{
 		Logger: l.Logger.With(slog.String("component", component)),
 		level:  l.level,
+		sinks:  l.sinks,
 	}
 }
 
@@ -170,6 +229,7 @@ func (l *Logger) WithOperation(operation string) *Logger {
 	return &Logger{
 		Logger: l.Logger.With(slog.String("operation", operation)),
 		level:  l.level,
+		sinks:  l.sinks,
 	}
 }
 
@@ -182,6 +242,7 @@ func (l *Logger) WithMetadata(metadata map[string]interface{}) *Logger {
 	return &Logger{
 		Logger: l.Logger.With(attrs...),
 		level:  l.level,
+		sinks:  l.sinks,
 	}
 }
 
@@ -229,6 +290,7 @@ func (l *Logger) ErrorWithStack(msg string, err error, args ...interface{}) {
 // Fatal logs a fatal message and exits
 func (l *Logger) Fatal(msg string, err error, args ...interface{}) {
 	l.ErrorWithStack(msg, err, args...)
+	l.Close()
 	os.Exit(1)
 }
 
@@ -303,8 +365,13 @@ func RequestIDMiddleware() gin.HandlerFunc {
 		// Add to Gin context
 		c.Set("request_id", requestID)
 
-		// Add to request context
+		// Add to request context. Callers identifying themselves with X-User-ID are recorded as
+		// the request's user until authentication sets one, so background work started by the
+		// request can be traced to both.
 		ctx := WithRequestID(c.Request.Context(), requestID)
+		if userID := c.GetHeader("X-User-ID"); userID != "" && GetUserID(ctx) == "" {
+			ctx = WithUserID(ctx, userID)
+		}
 		c.Request = c.Request.WithContext(ctx)
 
 		// Add to response header
@@ -0,0 +1,581 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sink types
+const (
+	SinkFile   = "file"
+	SinkSyslog = "syslog"
+	SinkHTTP   = "http"
+	SinkOTLP   = "otlp"
+)
+
+// serviceName identifies this application to syslog and OTLP collectors
+const serviceName = "incident-management-system"
+
+// SinkConfig configures one external log destination. Records are formatted as JSON lines
+// unless a file sink asks for text.
+type SinkConfig struct {
+	Type   string   `json:"type"`   // "file", "syslog", "http" or "otlp"
+	Level  LogLevel `json:"level"`  // Minimum level delivered; the logger level when empty
+	Format string   `json:"format"` // "json" or "text", file sinks only
+
+	// File sinks
+	Path       string `json:"path"`
+	MaxSizeMB  int    `json:"max_size_mb"` // Size at which the file is rotated, default 100
+	MaxBackups int    `json:"max_backups"` // Rotated files kept, default 5
+
+	// Syslog sinks
+	Network string `json:"network"` // "udp", "tcp" or "unix", default udp
+	Address string `json:"address"` // Default localhost:514
+
+	// HTTP sinks post newline-delimited JSON; OTLP sinks post OTLP/HTTP JSON to a collector's
+	// /v1/logs endpoint
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers"`
+	TimeoutMs int               `json:"timeout_ms"` // Default 5000
+
+	// Delivery
+	BufferSize      int `json:"buffer_size"`       // Records queued before new ones are dropped, default 1024
+	BatchSize       int `json:"batch_size"`        // Records sent per delivery, default 100
+	FlushIntervalMs int `json:"flush_interval_ms"` // Longest a record waits for a batch to fill, default 1000
+}
+
+// withDefaults fills unset sink options
+func (c SinkConfig) withDefaults() SinkConfig {
+	if c.MaxSizeMB <= 0 {
+		c.MaxSizeMB = 100
+	}
+	if c.MaxBackups <= 0 {
+		c.MaxBackups = 5
+	}
+	if c.Network == "" {
+		c.Network = "udp"
+	}
+	if c.Address == "" {
+		c.Address = "localhost:514"
+	}
+	if c.TimeoutMs <= 0 {
+		c.TimeoutMs = 5000
+	}
+	if c.BufferSize <= 0 {
+		c.BufferSize = 1024
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.FlushIntervalMs <= 0 {
+		c.FlushIntervalMs = 1000
+	}
+	return c
+}
+
+// SinkStats counts the records a sink delivered, dropped because its buffer was full, or lost
+// because the backend failed
+type SinkStats struct {
+	Type      string `json:"type"`
+	Delivered int64  `json:"delivered"`
+	Dropped   int64  `json:"dropped"`
+	Failed    int64  `json:"failed"`
+}
+
+// sinkWriter delivers batches of formatted log lines to a log backend
+type sinkWriter interface {
+	WriteBatch(lines [][]byte) error
+	Close() error
+}
+
+// newSinkWriter opens the backend a sink delivers to
+func newSinkWriter(config SinkConfig, timeFormat string) (sinkWriter, error) {
+	timeout := time.Duration(config.TimeoutMs) * time.Millisecond
+	switch config.Type {
+	case SinkFile:
+		if config.Path == "" {
+			return nil, fmt.Errorf("file sink requires a path")
+		}
+		return newRotatingFile(config.Path, int64(config.MaxSizeMB)*1024*1024, config.MaxBackups)
+	case SinkSyslog:
+		return newSyslogWriter(config.Network, config.Address, timeout)
+	case SinkHTTP:
+		if config.URL == "" {
+			return nil, fmt.Errorf("http sink requires a url")
+		}
+		return &httpWriter{url: config.URL, headers: config.Headers, client: &http.Client{Timeout: timeout}}, nil
+	case SinkOTLP:
+		if config.URL == "" {
+			return nil, fmt.Errorf("otlp sink requires a url")
+		}
+		return &otlpWriter{
+			httpWriter: httpWriter{url: config.URL, headers: config.Headers, client: &http.Client{Timeout: timeout}},
+			timeFormat: timeFormat,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown log sink type %q", config.Type)
+	}
+}
+
+// asyncSink queues formatted records and delivers them in batches from a background goroutine.
+// Writes never block: when the queue is full the record is dropped and counted, so a slow or
+// unreachable backend cannot stall the request that logged.
+type asyncSink struct {
+	kind          string
+	writer        sinkWriter
+	queue         chan []byte
+	batchSize     int
+	flushInterval time.Duration
+
+	delivered atomic.Int64
+	dropped   atomic.Int64
+	failed    atomic.Int64
+
+	mu        sync.RWMutex // Guards closed against records written while the sink closes
+	closed    bool
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// newAsyncSink starts delivering records queued on the sink to writer
+func newAsyncSink(config SinkConfig, writer sinkWriter) *asyncSink {
+	sink := &asyncSink{
+		kind:          config.Type,
+		writer:        writer,
+		queue:         make(chan []byte, config.BufferSize),
+		batchSize:     config.BatchSize,
+		flushInterval: time.Duration(config.FlushIntervalMs) * time.Millisecond,
+		done:          make(chan struct{}),
+	}
+	go sink.run()
+	return sink
+}
+
+// Write queues one formatted record. slog handlers write each record in a single call.
+// Records written after Close are dropped.
+func (s *asyncSink) Write(p []byte) (int, error) {
+	line := bytes.TrimRight(p, "\n")
+	record := make([]byte, len(line))
+	copy(record, line)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		s.dropped.Add(1)
+		return len(p), nil
+	}
+
+	select {
+	case s.queue <- record:
+	default:
+		s.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// run delivers queued records until the queue is closed
+func (s *asyncSink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, s.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.writer.WriteBatch(batch); err != nil {
+			// The logger cannot log its own failures; report them where operators still look
+			s.failed.Add(int64(len(batch)))
+			fmt.Fprintf(os.Stderr, "log sink %s: failed to deliver %d records: %v\n", s.kind, len(batch), err)
+		} else {
+			s.delivered.Add(int64(len(batch)))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case record, ok := <-s.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, record)
+			if len(batch) >= s.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Close delivers the records still queued and closes the backend
+func (s *asyncSink) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		close(s.queue)
+		s.mu.Unlock()
+
+		<-s.done
+		err = s.writer.Close()
+	})
+	return err
+}
+
+// stats returns the sink's delivery counters
+func (s *asyncSink) stats() SinkStats {
+	return SinkStats{
+		Type:      s.kind,
+		Delivered: s.delivered.Load(),
+		Dropped:   s.dropped.Load(),
+		Failed:    s.failed.Load(),
+	}
+}
+
+// multiHandler sends each record to every handler whose level it reaches
+type multiHandler struct {
+	handlers []slog.Handler
+}
+
+func (m *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range m.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *multiHandler) Handle(ctx context.Context, record slog.Record) error {
+	var firstErr error
+	for _, handler := range m.handlers {
+		if !handler.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, record.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(m.handlers))
+	for i, handler := range m.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+func (m *multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(m.handlers))
+	for i, handler := range m.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+// rotatingFile appends to a log file, renaming it to path.1 once it reaches maxBytes and
+// shifting older files up to path.<maxBackups>
+type rotatingFile struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+
+	file *os.File
+	size int64
+}
+
+// newRotatingFile opens path for appending, creating its directory if needed
+func newRotatingFile(path string, maxBytes int64, maxBackups int) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	r := &rotatingFile{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the current log file and records its size
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file %s: %w", r.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file %s: %w", r.path, err)
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+func (r *rotatingFile) WriteBatch(lines [][]byte) error {
+	for _, line := range lines {
+		if r.size > 0 && r.size+int64(len(line))+1 > r.maxBytes {
+			if err := r.rotate(); err != nil {
+				return err
+			}
+		}
+		n, err := r.file.Write(append(line, '\n'))
+		r.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("failed to write log file: %w", err)
+		}
+	}
+	return nil
+}
+
+// rotate shifts the backups up by one, dropping the oldest, and starts a new file
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	os.Remove(fmt.Sprintf("%s.%d", r.path, r.maxBackups))
+	for i := r.maxBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Close() error {
+	return r.file.Close()
+}
+
+// syslogWriter sends RFC 5424 messages to a syslog daemon. Stream connections use octet
+// counting to frame messages; they are redialed after a failed write.
+type syslogWriter struct {
+	network  string
+	address  string
+	timeout  time.Duration
+	hostname string
+
+	conn net.Conn
+}
+
+// newSyslogWriter connects to the syslog daemon at address
+func newSyslogWriter(network, address string, timeout time.Duration) (*syslogWriter, error) {
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	w := &syslogWriter{network: network, address: address, timeout: timeout, hostname: hostname}
+	if err := w.dial(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// dial opens the connection to the syslog daemon
+func (w *syslogWriter) dial() error {
+	conn, err := net.DialTimeout(w.network, w.address, w.timeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog at %s: %w", w.address, err)
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *syslogWriter) WriteBatch(lines [][]byte) error {
+	for _, line := range lines {
+		if w.conn == nil {
+			if err := w.dial(); err != nil {
+				return err
+			}
+		}
+		message := w.format(line)
+		if w.network != "udp" && w.network != "unixgram" {
+			message = append([]byte(strconv.Itoa(len(message))+" "), message...)
+		}
+		w.conn.SetWriteDeadline(time.Now().Add(w.timeout))
+		if _, err := w.conn.Write(message); err != nil {
+			w.conn.Close()
+			w.conn = nil
+			return fmt.Errorf("failed to write to syslog: %w", err)
+		}
+	}
+	return nil
+}
+
+// format wraps a JSON log line in an RFC 5424 header with the user facility and the severity
+// of the line's level
+func (w *syslogWriter) format(line []byte) []byte {
+	var record struct {
+		Level string `json:"level"`
+	}
+	json.Unmarshal(line, &record)
+
+	severity := 6 // informational
+	switch strings.ToUpper(record.Level) {
+	case "DEBUG":
+		severity = 7
+	case "WARN":
+		severity = 4
+	case "ERROR":
+		severity = 3
+	}
+
+	header := fmt.Sprintf("<%d>1 %s %s %s %d - - ", 1*8+severity,
+		time.Now().UTC().Format(time.RFC3339Nano), w.hostname, serviceName, os.Getpid())
+	return append([]byte(header), line...)
+}
+
+func (w *syslogWriter) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
+
+// httpWriter posts batches of JSON log lines as newline-delimited JSON
+type httpWriter struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (w *httpWriter) WriteBatch(lines [][]byte) error {
+	return w.post("application/x-ndjson", append(bytes.Join(lines, []byte("\n")), '\n'))
+}
+
+// post sends body to the sink URL, failing on any non-2xx response
+func (w *httpWriter) post(contentType string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range w.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post logs: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("log endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (w *httpWriter) Close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
+
+// otlpWriter posts batches to an OpenTelemetry collector using the OTLP/HTTP JSON encoding
+type otlpWriter struct {
+	httpWriter
+	timeFormat string
+}
+
+// OTLP severity numbers of the log levels
+var otlpSeverity = map[string]int{"DEBUG": 5, "INFO": 9, "WARN": 13, "ERROR": 17}
+
+func (w *otlpWriter) WriteBatch(lines [][]byte) error {
+	records := make([]map[string]interface{}, 0, len(lines))
+	for _, line := range lines {
+		records = append(records, w.logRecord(line))
+	}
+
+	payload := map[string]interface{}{
+		"resourceLogs": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": []interface{}{otlpAttribute("service.name", serviceName)},
+			},
+			"scopeLogs": []interface{}{map[string]interface{}{
+				"scope":      map[string]interface{}{"name": serviceName},
+				"logRecords": records,
+			}},
+		}},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode OTLP logs: %w", err)
+	}
+	return w.post("application/json", body)
+}
+
+// logRecord converts a JSON log line to an OTLP log record. The message becomes the body and
+// every other field an attribute.
+func (w *otlpWriter) logRecord(line []byte) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(line, &fields); err != nil {
+		fields = map[string]interface{}{slog.MessageKey: string(line)}
+	}
+
+	observed := time.Now()
+	timestamp := observed
+	if value, ok := fields[slog.TimeKey].(string); ok {
+		if parsed, err := time.Parse(w.timeFormat, value); err == nil {
+			timestamp = parsed
+		}
+	}
+	level, _ := fields[slog.LevelKey].(string)
+	message, _ := fields[slog.MessageKey].(string)
+	delete(fields, slog.TimeKey)
+	delete(fields, slog.LevelKey)
+	delete(fields, slog.MessageKey)
+
+	attributes := make([]interface{}, 0, len(fields))
+	for key, value := range fields {
+		attributes = append(attributes, otlpAttribute(key, value))
+	}
+
+	return map[string]interface{}{
+		"timeUnixNano":         strconv.FormatInt(timestamp.UnixNano(), 10),
+		"observedTimeUnixNano": strconv.FormatInt(observed.UnixNano(), 10),
+		"severityNumber":       otlpSeverity[strings.ToUpper(level)],
+		"severityText":         level,
+		"body":                 map[string]interface{}{"stringValue": message},
+		"attributes":           attributes,
+	}
+}
+
+// otlpAttribute encodes one attribute as an OTLP key/value pair. Nested values are sent as
+// their JSON text.
+func otlpAttribute(key string, value interface{}) map[string]interface{} {
+	var encoded map[string]interface{}
+	switch v := value.(type) {
+	case string:
+		encoded = map[string]interface{}{"stringValue": v}
+	case bool:
+		encoded = map[string]interface{}{"boolValue": v}
+	case float64:
+		encoded = map[string]interface{}{"doubleValue": v}
+	default:
+		text, _ := json.Marshal(v)
+		encoded = map[string]interface{}{"stringValue": string(text)}
+	}
+	return map[string]interface{}{"key": key, "value": encoded}
+}
@@ -0,0 +1,251 @@
+package logging
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewLogger_FileSinkFiltersByLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	logger, err := NewLogger(&Config{
+		Level:      LevelDebug,
+		Format:     "json",
+		Output:     "stderr",
+		TimeFormat: time.RFC3339,
+		Sinks:      []SinkConfig{{Type: SinkFile, Path: path, Level: LevelWarn}},
+	})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	logger.WithComponent("test").Info("routine message")
+	logger.WithComponent("test").Warn("something odd")
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("file sink got %d lines, want 1: %q", len(lines), data)
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("file sink line is not JSON: %v", err)
+	}
+	if record["msg"] != "something odd" || record["component"] != "test" {
+		t.Errorf("unexpected record %v", record)
+	}
+
+	stats := logger.SinkStats()
+	if len(stats) != 1 || stats[0].Delivered != 1 || stats[0].Dropped != 0 {
+		t.Errorf("SinkStats() = %+v, want one delivered record", stats)
+	}
+}
+
+func TestRotatingFile_KeepsBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	file, err := newRotatingFile(path, 20, 2)
+	if err != nil {
+		t.Fatalf("newRotatingFile() error = %v", err)
+	}
+
+	for _, line := range []string{"first line 1", "second line", "third line", "fourth line"} {
+		if err := file.WriteBatch([][]byte{[]byte(line)}); err != nil {
+			t.Fatalf("WriteBatch() error = %v", err)
+		}
+	}
+	file.Close()
+
+	want := map[string]string{
+		path:        "fourth line\n",
+		path + ".1": "third line\n",
+		path + ".2": "second line\n",
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", name, data, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups, found %s.3", path)
+	}
+}
+
+// blockingWriter stalls every delivery until released
+type blockingWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	lines   int
+}
+
+func (w *blockingWriter) WriteBatch(lines [][]byte) error {
+	<-w.release
+	w.mu.Lock()
+	w.lines += len(lines)
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *blockingWriter) Close() error { return nil }
+
+func TestAsyncSink_DropsWhenBackendStalls(t *testing.T) {
+	writer := &blockingWriter{release: make(chan struct{})}
+	sink := newAsyncSink(SinkConfig{Type: SinkHTTP, BufferSize: 4, BatchSize: 1, FlushIntervalMs: 10}, writer)
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		sink.Write([]byte("{\"msg\":\"hello\"}\n"))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("writes blocked on a stalled backend for %v", elapsed)
+	}
+
+	close(writer.release)
+	sink.Close()
+
+	stats := sink.stats()
+	if stats.Dropped == 0 {
+		t.Errorf("expected records to be dropped while the backend stalled")
+	}
+	if stats.Delivered+stats.Dropped != 100 {
+		t.Errorf("delivered %d + dropped %d, want 100", stats.Delivered, stats.Dropped)
+	}
+}
+
+func TestNewLogger_HTTPAndOTLPSinks(t *testing.T) {
+	var mu sync.Mutex
+	bodies := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies[r.URL.Path] += string(body)
+		mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	headers := map[string]string{"Authorization": "Bearer secret"}
+	logger, err := NewLogger(&Config{
+		Level:      LevelInfo,
+		Format:     "json",
+		Output:     "stderr",
+		TimeFormat: time.RFC3339Nano,
+		Sinks: []SinkConfig{
+			{Type: SinkHTTP, URL: server.URL + "/ingest", Headers: headers},
+			{Type: SinkOTLP, URL: server.URL + "/v1/logs", Headers: headers, Level: LevelError},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	logger.Info("upload processed", "rows", 42)
+	logger.Error("upload failed", io.ErrUnexpectedEOF)
+	logger.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	ndjson := strings.Split(strings.TrimSpace(bodies["/ingest"]), "\n")
+	if len(ndjson) != 2 {
+		t.Fatalf("http sink got %d lines, want 2: %q", len(ndjson), bodies["/ingest"])
+	}
+
+	var payload struct {
+		ResourceLogs []struct {
+			ScopeLogs []struct {
+				LogRecords []struct {
+					SeverityNumber int    `json:"severityNumber"`
+					SeverityText   string `json:"severityText"`
+					TimeUnixNano   string `json:"timeUnixNano"`
+					Body           struct {
+						StringValue string `json:"stringValue"`
+					} `json:"body"`
+					Attributes []struct {
+						Key string `json:"key"`
+					} `json:"attributes"`
+				} `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	if err := json.Unmarshal([]byte(bodies["/v1/logs"]), &payload); err != nil {
+		t.Fatalf("otlp sink body is not JSON: %v", err)
+	}
+	records := payload.ResourceLogs[0].ScopeLogs[0].LogRecords
+	if len(records) != 1 {
+		t.Fatalf("otlp sink got %d records, want only the error", len(records))
+	}
+	record := records[0]
+	if record.SeverityNumber != 17 || record.SeverityText != "ERROR" || record.Body.StringValue != "upload failed" {
+		t.Errorf("unexpected OTLP record %+v", record)
+	}
+	if record.TimeUnixNano == "" {
+		t.Errorf("expected a record timestamp")
+	}
+
+	hasError := false
+	for _, attribute := range record.Attributes {
+		hasError = hasError || attribute.Key == "error"
+	}
+	if !hasError {
+		t.Errorf("expected the error to be sent as an attribute, got %+v", record.Attributes)
+	}
+}
+
+func TestNewLogger_RejectsUnknownSink(t *testing.T) {
+	if _, err := NewLogger(&Config{Output: "stderr", Sinks: []SinkConfig{{Type: "kafka"}}}); err == nil {
+		t.Errorf("expected an error for an unknown sink type")
+	}
+}
+
+func TestSyslogWriter_FormatsRFC5424(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer conn.Close()
+
+	writer, err := newSyslogWriter("udp", conn.LocalAddr().String(), time.Second)
+	if err != nil {
+		t.Fatalf("newSyslogWriter() error = %v", err)
+	}
+	defer writer.Close()
+
+	if err := writer.WriteBatch([][]byte{[]byte(`{"level":"WARN","msg":"disk filling up"}`)}); err != nil {
+		t.Fatalf("WriteBatch() error = %v", err)
+	}
+
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("failed to read syslog message: %v", err)
+	}
+	message := string(buf[:n])
+	if !strings.HasPrefix(message, "<12>1 ") {
+		t.Errorf("expected user facility warning priority, got %q", message)
+	}
+	if !strings.Contains(message, serviceName) || !strings.HasSuffix(message, `"msg":"disk filling up"}`) {
+		t.Errorf("unexpected syslog message %q", message)
+	}
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FieldDoc describes a field of a model for the data dictionary
+type FieldDoc struct {
+	Description string
+	// Derived marks fields the system computes, rather than reads from the source data
+	Derived bool
+	// Values lists the values the field may hold, for fields restricted to a set
+	Values []string
+}
+
+// FieldSchema describes one field of a model as the API exposes it
+type FieldSchema struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"` // string, integer, number, boolean or date-time
+	Nullable    bool     `json:"nullable"`
+	Optional    bool     `json:"optional"` // left out of responses when empty
+	Derived     bool     `json:"derived"`
+	Description string   `json:"description"`
+	Values      []string `json:"values,omitempty"`
+}
+
+// IncidentFieldDocs describes each field of Incident, by its JSON name. Every field must have an
+// entry, so the data dictionary cannot drift from the model.
+var IncidentFieldDocs = map[string]FieldDoc{
+	"id":                        {Description: "Unique ID of the stored incident", Derived: true},
+	"upload_id":                 {Description: "Upload the incident was read from", Derived: true},
+	"dataset_id":                {Description: "Dataset the incident's upload belongs to", Derived: true},
+	"incident_id":               {Description: "Incident number as the source records it"},
+	"report_date":               {Description: "Day the incident was reported"},
+	"resolve_date":              {Description: "Day the incident was first resolved"},
+	"last_resolve_date":         {Description: "Day the incident was last resolved, after any reopening"},
+	"brief_description":         {Description: "Short summary of the incident"},
+	"description":               {Description: "Full description of the incident"},
+	"application_name":          {Description: "Affected application, after alias normalization"},
+	"application_name_raw":      {Description: "Affected application as the source recorded it, before normalization"},
+	"resolution_group":          {Description: "Team that resolved the incident"},
+	"resolved_person":           {Description: "Person who resolved the incident"},
+	"priority":                  {Description: "Incident priority", Values: ValidPriorities},
+	"priority_inferred":         {Description: "Whether the priority was missing and estimated from the incident's text", Derived: true},
+	"category":                  {Description: "Category as the source records it"},
+	"subcategory":               {Description: "Subcategory as the source records it"},
+	"impact":                    {Description: "Impact as the source records it"},
+	"urgency":                   {Description: "Urgency as the source records it"},
+	"status":                    {Description: "Status as the source records it; 'reopened' marks reopened incidents"},
+	"customer_affected":         {Description: "Whether customers were affected, as the source records it"},
+	"business_service":          {Description: "Business service, after service catalog matching"},
+	"business_service_raw":      {Description: "Business service as the source recorded it, before catalog matching"},
+	"root_cause":                {Description: "Root cause as the source records it"},
+	"resolution_notes":          {Description: "Notes on how the incident was resolved"},
+	"cost":                      {Description: "Cost of handling the incident, when the source records it"},
+	"pending_hours":             {Description: "Hours the resolution clock was paused waiting on the customer"},
+	"source":                    {Description: "Monitoring tool or channel the incident was raised through"},
+	"closure_code":              {Description: "Closure code as the source records it"},
+	"region":                    {Description: "Region or country of the affected users or support site"},
+	"cmdb_owner":                {Description: "Owner of the incident's application or business service in the CMDB", Derived: true},
+	"cmdb_environment":          {Description: "Environment of the incident's application or business service in the CMDB", Derived: true},
+	"cmdb_criticality":          {Description: "Criticality of the incident's application or business service in the CMDB", Derived: true},
+	"sentiment_score":           {Description: "Sentiment of the incident's text, from -1 to 1", Derived: true},
+	"sentiment_label":           {Description: "Sentiment of the incident's text", Derived: true, Values: ValidSentiments},
+	"resolution_time_hours":     {Description: "Hours from report to resolution", Derived: true},
+	"net_resolution_time_hours": {Description: "Hours from report to resolution without pending time", Derived: true},
+	"automation_score":          {Description: "How suited the incident's fix is to automation, from 0 to 1", Derived: true},
+	"automation_feasible":       {Description: "Whether the incident's fix could be automated", Derived: true},
+	"it_process_group":          {Description: "IT process the incident's fix belongs to", Derived: true},
+	"sentiment_version":         {Description: "Version of the sentiment analyzer that scored the incident", Derived: true},
+	"automation_version":        {Description: "Version of the automation analyzer that scored the incident", Derived: true},
+	"created_at":                {Description: "When the incident was stored", Derived: true},
+	"updated_at":                {Description: "When the stored incident last changed", Derived: true},
+}
+
+// DescribeIncident returns the fields of Incident as the API exposes them, in the model's order
+func DescribeIncident() []FieldSchema {
+	return DescribeFields(reflect.TypeOf(Incident{}), IncidentFieldDocs)
+}
+
+// DescribeFields returns the JSON fields of a struct type with their docs. Fields without a JSON
+// name are left out; fields without a doc are described as undocumented.
+func DescribeFields(model reflect.Type, docs map[string]FieldDoc) []FieldSchema {
+	fields := []FieldSchema{}
+	for i := 0; i < model.NumField(); i++ {
+		field := model.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		fieldType := field.Type
+		schema := FieldSchema{
+			Name:     name,
+			Nullable: fieldType.Kind() == reflect.Pointer,
+			Optional: strings.Contains(options, "omitempty"),
+		}
+		if schema.Nullable {
+			fieldType = fieldType.Elem()
+		}
+		schema.Type = jsonType(fieldType)
+
+		doc, ok := docs[name]
+		if !ok {
+			doc.Description = "Undocumented"
+		}
+		schema.Description, schema.Derived, schema.Values = doc.Description, doc.Derived, doc.Values
+		fields = append(fields, schema)
+	}
+	return fields
+}
+
+// jsonType names the JSON type a Go type is encoded as
+func jsonType(t reflect.Type) string {
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return "date-time"
+	case t.Kind() == reflect.Bool:
+		return "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return "integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return "number"
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return "array"
+	case t.Kind() == reflect.Struct || t.Kind() == reflect.Map:
+		return "object"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestDescribeIncident(t *testing.T) {
+	fields := DescribeIncident()
+
+	// Every field of the model is documented, and every doc names a field of the model
+	described := map[string]FieldSchema{}
+	for _, field := range fields {
+		if field.Description == "Undocumented" {
+			t.Errorf("field %s has no entry in IncidentFieldDocs", field.Name)
+		}
+		described[field.Name] = field
+	}
+	for name := range IncidentFieldDocs {
+		if _, ok := described[name]; !ok {
+			t.Errorf("IncidentFieldDocs documents %s, which Incident does not have", name)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		typ      string
+		nullable bool
+		derived  bool
+	}{
+		{"incident_id", "string", false, false},
+		{"report_date", "date-time", false, false},
+		{"resolve_date", "date-time", true, false},
+		{"cost", "number", true, false},
+		{"resolution_time_hours", "integer", true, true},
+		{"automation_feasible", "boolean", true, true},
+	}
+	for _, tt := range tests {
+		field := described[tt.name]
+		if field.Type != tt.typ || field.Nullable != tt.nullable || field.Derived != tt.derived {
+			t.Errorf("unexpected schema of %s: %+v", tt.name, field)
+		}
+	}
+	if values := described["priority"].Values; len(values) != len(ValidPriorities) {
+		t.Errorf("expected the valid priorities listed, got %v", values)
+	}
+}
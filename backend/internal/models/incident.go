@@ -10,6 +10,7 @@ import (
 type Incident struct {
 	ID                   string     `json:"id" db:"id"`
 	UploadID            string     `json:"upload_id" db:"upload_id"`
+	DatasetID           string     `json:"dataset_id,omitempty" db:"dataset_id"`
 	IncidentID          string     `json:"incident_id" db:"incident_id"`
 	ReportDate          time.Time  `json:"report_date" db:"report_date"`
 	ResolveDate         *time.Time `json:"resolve_date,omitempty" db:"resolve_date"`
@@ -17,9 +18,11 @@ type Incident struct {
 	BriefDescription    string     `json:"brief_description" db:"brief_description"`
 	Description         string     `json:"description" db:"description"`
 	ApplicationName     string     `json:"application_name" db:"application_name"`
+	ApplicationNameRaw  string     `json:"application_name_raw,omitempty" db:"application_name_raw"`
 	ResolutionGroup     string     `json:"resolution_group" db:"resolution_group"`
 	ResolvedPerson      string     `json:"resolved_person" db:"resolved_person"`
 	Priority            string     `json:"priority" db:"priority"`
+	PriorityInferred    bool       `json:"priority_inferred,omitempty" db:"priority_inferred"` // priority was missing and estimated from the text
 	
 	// Additional fields
 	Category            string     `json:"category,omitempty" db:"category"`
@@ -29,16 +32,31 @@ type Incident struct {
 	Status              string     `json:"status,omitempty" db:"status"`
 	CustomerAffected    string     `json:"customer_affected,omitempty" db:"customer_affected"`
 	BusinessService     string     `json:"business_service,omitempty" db:"business_service"`
+	BusinessServiceRaw  string     `json:"business_service_raw,omitempty" db:"business_service_raw"` // business service as the source recorded it, before catalog matching
 	RootCause           string     `json:"root_cause,omitempty" db:"root_cause"`
 	ResolutionNotes     string     `json:"resolution_notes,omitempty" db:"resolution_notes"`
+	Cost                *float64   `json:"cost,omitempty" db:"cost"` // cost of handling the incident, when the source records it
+	PendingHours        *float64   `json:"pending_hours,omitempty" db:"pending_hours"` // time the resolution clock was paused waiting on the customer
+	Source              string     `json:"source,omitempty" db:"source"` // monitoring tool or channel the incident was raised through
+	ClosureCode         string     `json:"closure_code,omitempty" db:"closure_code"`
+	Region              string     `json:"region,omitempty" db:"region"` // region or country of the affected users or support site
+	
+	// CMDB attributes of the incident's application, or of its business service when the
+	// application is not in the CMDB
+	CMDBOwner           string     `json:"cmdb_owner,omitempty" db:"cmdb_owner"`
+	CMDBEnvironment     string     `json:"cmdb_environment,omitempty" db:"cmdb_environment"`
+	CMDBCriticality     string     `json:"cmdb_criticality,omitempty" db:"cmdb_criticality"`
 	
 	// Derived fields
 	SentimentScore      *float64   `json:"sentiment_score,omitempty" db:"sentiment_score"`
 	SentimentLabel      string     `json:"sentiment_label,omitempty" db:"sentiment_label"`
 	ResolutionTimeHours *int       `json:"resolution_time_hours,omitempty" db:"resolution_time_hours"`
+	NetResolutionTimeHours *int    `json:"net_resolution_time_hours,omitempty" db:"net_resolution_time_hours"` // resolution time without pending time
 	AutomationScore     *float64   `json:"automation_score,omitempty" db:"automation_score"`
 	AutomationFeasible  *bool      `json:"automation_feasible,omitempty" db:"automation_feasible"`
 	ITProcessGroup      string     `json:"it_process_group,omitempty" db:"it_process_group"`
+	SentimentVersion    string     `json:"sentiment_version,omitempty" db:"sentiment_version"`
+	AutomationVersion   string     `json:"automation_version,omitempty" db:"automation_version"`
 	
 	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
@@ -56,15 +74,102 @@ type Upload struct {
 	Errors           []string  `json:"errors,omitempty" db:"errors"`
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 	ProcessedAt      *time.Time `json:"processed_at,omitempty" db:"processed_at"`
+	ProcessingOptions *ProcessingOptions `json:"processing_options,omitempty" db:"processing_options"`
+	DatasetID        string    `json:"dataset_id,omitempty" db:"dataset_id"`
+	IngestedSheet    *IngestedSheet `json:"ingested_sheet,omitempty" db:"ingested_sheet"`
+	Cleanup          *UploadCleanup `json:"cleanup,omitempty" db:"cleanup"`
+}
+
+// Upload cleanup reasons and statuses
+const (
+	CleanupReasonInsertFailed = "insert_failed"
+	CleanupReasonCancelled    = "cancelled"
+	CleanupReasonLeftoverRows = "leftover_rows"
+
+	CleanupStatusCompleted = "completed"
+	CleanupStatusFailed    = "failed"
+)
+
+// UploadCleanup records the incidents deleted from an upload whose run stopped after storing
+// part of its file, or that still held rows from such a run when it was processed again
+type UploadCleanup struct {
+	Reason      string    `json:"reason"`
+	Status      string    `json:"status"`
+	DeletedRows int       `json:"deleted_rows"`
+	Error       string    `json:"error,omitempty"`
+	CleanedAt   time.Time `json:"cleaned_at"`
+}
+
+// IngestedSheet records which part of a workbook an upload's incidents were read from
+type IngestedSheet struct {
+	Sheet     string `json:"sheet"`
+	HeaderRow int    `json:"header_row"` // 1-based row holding the column names
+	Range     string `json:"range"`      // cells read, such as A3:J120
+	// SkippedSheets lists the other sheets of the workbook, hidden ones included
+	SkippedSheets     []string `json:"skipped_sheets,omitempty"`
+	MergedRanges      int      `json:"merged_ranges"`      // merged cell ranges filled across the data
+	FormulasEvaluated int      `json:"formulas_evaluated"` // formula cells without a cached value that were calculated
+	// Columns maps each incident field read from the sheet to the header of its column
+	Columns map[string]string `json:"columns,omitempty"`
+	// DuplicateColumns lists the headers naming more than one column; the last of them is read
+	DuplicateColumns []string `json:"duplicate_columns,omitempty"`
+	// SkippedRows lists the rows below the header left out as not holding incidents
+	SkippedRows []SkippedRow `json:"skipped_rows,omitempty"`
+}
+
+// Reasons a sheet row is skipped rather than parsed
+const (
+	SkipRepeatedHeader = "repeated_header" // the header row again, as pasted exports repeat it
+	SkipDuplicateRow   = "duplicate_row"   // the same cells as an earlier row
+	SkipSummaryRow     = "summary_row"     // a total or other summary line below the data
+)
+
+// SkippedRow records a sheet row that was not parsed and why
+type SkippedRow struct {
+	Row    int    `json:"row"` // 1-based sheet row
+	Reason string `json:"reason"`
+	// DuplicateOf is the earlier row a duplicate row repeats
+	DuplicateOf int `json:"duplicate_of,omitempty"`
+}
+
+// ProcessingOptions controls how an upload is processed. The options are stored on the upload
+// record so every dataset can be traced back to how it was produced.
+type ProcessingOptions struct {
+	MappingProfile string  `json:"mapping_profile,omitempty"`
+	RuleSet        string  `json:"rule_set,omitempty"` // validation rule set rows are checked against
+	DedupStrategy  string  `json:"dedup_strategy"`
+	ErrorThreshold float64 `json:"error_threshold"` // percentage of rows allowed to fail parsing
+	RunSentiment   bool    `json:"run_sentiment"`
+	RunAutomation  bool    `json:"run_automation"`
+	Timezone       string  `json:"timezone,omitempty"` // IANA zone whose calendar day dates are stored as
+	Sheet          string  `json:"sheet,omitempty"`    // worksheet to read; empty detects the data sheet
+	InferPriority  bool    `json:"infer_priority,omitempty"` // estimate missing priorities from the incident text
+	DryRun         bool    `json:"dry_run"`
+	// Urgent runs start ahead of runs held back by memory pressure and pause background jobs,
+	// such as cache warming, until they finish
+	Urgent         bool    `json:"urgent,omitempty"`
+}
+
+// DefaultProcessingOptions returns the options used when processing is started without any:
+// built-in column mapping, first occurrence of a duplicate ID wins, no failed rows tolerated,
+// both analyzers enabled and dates kept in UTC
+func DefaultProcessingOptions() ProcessingOptions {
+	return ProcessingOptions{
+		DedupStrategy: DedupStrategyFirst,
+		RunSentiment:  true,
+		RunAutomation: true,
+	}
 }
 
 // Constants for validation
 const (
-	// Upload status values
-	UploadStatusUploaded   = "uploaded"
-	UploadStatusProcessing = "processing"
-	UploadStatusCompleted  = "completed"
-	UploadStatusFailed     = "failed"
+	// Upload status values. See uploadTransitions for the order an upload moves through them.
+	UploadStatusUploaded            = "uploaded"
+	UploadStatusQueued              = "queued"
+	UploadStatusProcessing          = "processing"
+	UploadStatusCompleted           = "completed"
+	UploadStatusCompletedWithErrors = "completed_with_errors"
+	UploadStatusFailed              = "failed"
 
 	// Priority values
 	PriorityP1 = "P1"
@@ -76,15 +181,49 @@ const (
 	SentimentPositive = "positive"
 	SentimentNegative = "negative"
 	SentimentNeutral  = "neutral"
+
+	// Dedup strategies for incident IDs repeated within one upload
+	DedupStrategyFirst = "first"
+	DedupStrategyLast  = "last"
+	DedupStrategyFail  = "fail"
 )
 
 // Valid values for validation
 var (
-	ValidUploadStatuses = []string{UploadStatusUploaded, UploadStatusProcessing, UploadStatusCompleted, UploadStatusFailed}
+	ValidUploadStatuses = []string{UploadStatusUploaded, UploadStatusQueued, UploadStatusProcessing, UploadStatusCompleted, UploadStatusCompletedWithErrors, UploadStatusFailed}
 	ValidPriorities     = []string{PriorityP1, PriorityP2, PriorityP3, PriorityP4}
 	ValidSentiments     = []string{SentimentPositive, SentimentNegative, SentimentNeutral}
+	ValidDedupStrategies = []string{DedupStrategyFirst, DedupStrategyLast, DedupStrategyFail}
 )
 
+// uploadTransitions lists the statuses an upload may move to from each status. A processing run
+// queues an uploaded file, starts it once the server has capacity and finishes it as completed,
+// completed with errors when some rows were rejected, or failed. A dry run returns the upload to
+// uploaded when it finishes, and rolling back a finished run returns it there too.
+var uploadTransitions = map[string][]string{
+	UploadStatusUploaded:            {UploadStatusQueued},
+	UploadStatusQueued:              {UploadStatusProcessing, UploadStatusFailed},
+	UploadStatusProcessing:          {UploadStatusCompleted, UploadStatusCompletedWithErrors, UploadStatusFailed, UploadStatusUploaded},
+	UploadStatusCompleted:           {UploadStatusUploaded},
+	UploadStatusCompletedWithErrors: {UploadStatusUploaded},
+	UploadStatusFailed:              {UploadStatusUploaded},
+}
+
+// CanTransitionUpload reports whether an upload may move from one status to another
+func CanTransitionUpload(from, to string) bool {
+	for _, next := range uploadTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUploadFinished reports whether status ends a processing run
+func IsUploadFinished(status string) bool {
+	return status == UploadStatusCompleted || status == UploadStatusCompletedWithErrors || status == UploadStatusFailed
+}
+
 // ValidationError represents a field validation error
 type ValidationError struct {
 	Field   string `json:"field"`
@@ -211,6 +350,24 @@ func (i *Incident) Validate() error {
 		})
 	}
 
+	// Cost validation
+	if i.Cost != nil && *i.Cost < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "cost",
+			Value:   fmt.Sprintf("%.2f", *i.Cost),
+			Message: "cost cannot be negative",
+		})
+	}
+
+	// Pending time validation
+	if i.PendingHours != nil && *i.PendingHours < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "pending_hours",
+			Value:   fmt.Sprintf("%.2f", *i.PendingHours),
+			Message: "pending hours cannot be negative",
+		})
+	}
+
 	if len(errors) > 0 {
 		return errors
 	}
@@ -342,15 +499,36 @@ func isValidSentiment(sentiment string) bool {
 	return false
 }
 
-// CalculateResolutionTime calculates resolution time in hours
+// CalculateResolutionTime calculates the gross resolution time in hours and the net resolution
+// time, which leaves out the time the clock was paused in pending states
 func (i *Incident) CalculateResolutionTime() {
 	if i.ResolveDate != nil {
 		duration := i.ResolveDate.Sub(i.ReportDate)
 		hours := int(duration.Hours())
 		i.ResolutionTimeHours = &hours
+
+		net := hours
+		if i.PendingHours != nil {
+			net = int((duration - time.Duration(*i.PendingHours*float64(time.Hour))).Hours())
+			if net < 0 {
+				net = 0
+			}
+		}
+		i.NetResolutionTimeHours = &net
 	}
 }
 
+// ReportTime returns the report date and time when the report date carries a time of day, and
+// nil for a date alone, read as midnight
+func (i *Incident) ReportTime() *time.Time {
+	hour, minute, second := i.ReportDate.Clock()
+	if i.ReportDate.IsZero() || hour+minute+second == 0 && i.ReportDate.Nanosecond() == 0 {
+		return nil
+	}
+	reportTime := i.ReportDate
+	return &reportTime
+}
+
 // SetDefaults sets default values for the incident
 func (i *Incident) SetDefaults() {
 	now := time.Now()
@@ -387,6 +565,11 @@ func (u *Upload) IsProcessing() bool {
 	return u.Status == UploadStatusProcessing
 }
 
+// IsQueued returns true if the upload is waiting for processing to start
+func (u *Upload) IsQueued() bool {
+	return u.Status == UploadStatusQueued
+}
+
 // AddError adds an error to the upload
 func (u *Upload) AddError(err string) {
 	if u.Errors == nil {
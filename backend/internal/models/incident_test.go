@@ -143,6 +143,43 @@ func TestIncidentCalculateResolutionTime(t *testing.T) {
 	}
 }
 
+func TestIncidentCalculateNetResolutionTime(t *testing.T) {
+	reportTime := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	resolveTime := time.Date(2023, 1, 1, 20, 0, 0, 0, time.UTC)
+	pending := 6.5
+
+	incident := &Incident{ReportDate: reportTime, ResolveDate: &resolveTime, PendingHours: &pending}
+	incident.CalculateResolutionTime()
+	if *incident.ResolutionTimeHours != 10 || *incident.NetResolutionTimeHours != 3 {
+		t.Errorf("Expected 10 gross and 3 net hours, got %d and %d",
+			*incident.ResolutionTimeHours, *incident.NetResolutionTimeHours)
+	}
+
+	pending = 12
+	incident.CalculateResolutionTime()
+	if *incident.NetResolutionTimeHours != 0 {
+		t.Errorf("Expected pending time beyond the resolution time to leave 0 net hours, got %d", *incident.NetResolutionTimeHours)
+	}
+
+	incident.PendingHours = nil
+	incident.CalculateResolutionTime()
+	if *incident.NetResolutionTimeHours != 10 {
+		t.Errorf("Expected net hours to equal gross hours without pending time, got %d", *incident.NetResolutionTimeHours)
+	}
+}
+
+func TestIncidentReportTime(t *testing.T) {
+	incident := &Incident{ReportDate: time.Date(2023, 1, 1, 10, 30, 0, 0, time.UTC)}
+	if reportTime := incident.ReportTime(); reportTime == nil || !reportTime.Equal(incident.ReportDate) {
+		t.Errorf("Expected the report time of a date with a time of day, got %v", reportTime)
+	}
+
+	incident.ReportDate = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	if reportTime := incident.ReportTime(); reportTime != nil {
+		t.Errorf("Expected no report time for a date alone, got %v", reportTime)
+	}
+}
+
 func TestUploadMethods(t *testing.T) {
 	upload := &Upload{
 		Status: UploadStatusCompleted,
@@ -184,6 +221,33 @@ func TestUploadMethods(t *testing.T) {
 	}
 }
 
+func TestUploadTransitions(t *testing.T) {
+	lifecycle := []string{UploadStatusUploaded, UploadStatusQueued, UploadStatusProcessing, UploadStatusCompletedWithErrors, UploadStatusUploaded}
+	for i := 1; i < len(lifecycle); i++ {
+		if !CanTransitionUpload(lifecycle[i-1], lifecycle[i]) {
+			t.Errorf("Expected %s -> %s to be allowed", lifecycle[i-1], lifecycle[i])
+		}
+	}
+
+	// Processing cannot be skipped, restarted or started twice
+	for _, invalid := range [][2]string{
+		{UploadStatusUploaded, UploadStatusProcessing},
+		{UploadStatusQueued, UploadStatusQueued},
+		{UploadStatusProcessing, UploadStatusProcessing},
+		{UploadStatusCompleted, UploadStatusProcessing},
+		{UploadStatusFailed, UploadStatusCompleted},
+		{"unknown", UploadStatusQueued},
+	} {
+		if CanTransitionUpload(invalid[0], invalid[1]) {
+			t.Errorf("Expected %s -> %s to be rejected", invalid[0], invalid[1])
+		}
+	}
+
+	if !IsUploadFinished(UploadStatusCompletedWithErrors) || IsUploadFinished(UploadStatusQueued) {
+		t.Error("Expected only completed, completed with errors and failed to finish a run")
+	}
+}
+
 func TestValidationErrorForRow(t *testing.T) {
 	invalidIncident := &Incident{
 		Priority: "INVALID",
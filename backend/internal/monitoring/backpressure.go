@@ -0,0 +1,248 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Memory pressure levels, from least to most severe
+const (
+	PressureNormal = "normal"
+	PressureDelay  = "delay"
+	PressureReject = "reject"
+)
+
+// BackpressureConfig holds the heap thresholds that hold back new work
+type BackpressureConfig struct {
+	DelayThresholdMB  float64       // Heap in use above which new processing waits; 0 disables
+	RejectThresholdMB float64       // Heap in use above which uploads are rejected; 0 disables
+	RetryAfter        time.Duration // Retry-After sent with rejected uploads
+	MaxDelay          time.Duration // Longest a processing run waits before starting anyway
+	PollInterval      time.Duration // How often a waiting run checks memory again
+}
+
+// DefaultBackpressureConfig returns default backpressure configuration
+func DefaultBackpressureConfig() *BackpressureConfig {
+	return &BackpressureConfig{
+		DelayThresholdMB:  512,
+		RejectThresholdMB: 768,
+		RetryAfter:        30 * time.Second,
+		MaxDelay:          5 * time.Minute,
+		PollInterval:      time.Second,
+	}
+}
+
+// BackpressureStatus reports the current memory pressure and how often it held work back
+type BackpressureStatus struct {
+	Level             string  `json:"level"`
+	HeapAllocMB       float64 `json:"heap_alloc_mb"`
+	DelayThresholdMB  float64 `json:"delay_threshold_mb"`
+	RejectThresholdMB float64 `json:"reject_threshold_mb"`
+	Waiting           int     `json:"waiting"`
+	Delayed           int64   `json:"delayed"`
+	Rejected          int64   `json:"rejected"`
+	Urgent            int64   `json:"urgent"` // urgent runs started through the priority lane
+}
+
+// Backpressure holds back new work while the heap is large: processing runs wait for memory to
+// be released, and above a higher threshold new uploads are turned away
+type Backpressure struct {
+	config *BackpressureConfig // Thresholds are guarded by mu
+	heapMB func() float64
+	logger *logging.Logger
+
+	mu       sync.Mutex
+	waiting  int
+	delayed  int64
+	rejected int64
+	urgent   int64
+}
+
+// NewBackpressure creates backpressure driven by the heap usage the memory monitor reports
+func NewBackpressure(monitor *MemoryMonitor, config *BackpressureConfig) *Backpressure {
+	if config == nil {
+		config = DefaultBackpressureConfig()
+	}
+
+	return &Backpressure{
+		config: config,
+		// Stats older than a poll interval are re-read, so waiting runs see memory being freed
+		heapMB: func() float64 { return monitor.HeapAllocMB(config.PollInterval) },
+		logger: monitor.logger.WithComponent("backpressure"),
+	}
+}
+
+// Level returns the current memory pressure level
+func (b *Backpressure) Level() string {
+	return b.levelAt(b.heapMB())
+}
+
+// SetDelayThreshold changes the delay threshold while the server runs; 0 disables delays
+func (b *Backpressure) SetDelayThreshold(mb float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.DelayThresholdMB = mb
+}
+
+// SetRejectThreshold changes the reject threshold while the server runs; 0 disables rejections
+func (b *Backpressure) SetRejectThreshold(mb float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config.RejectThresholdMB = mb
+}
+
+// thresholds returns the current delay and reject thresholds
+func (b *Backpressure) thresholds() (delayMB, rejectMB float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.config.DelayThresholdMB, b.config.RejectThresholdMB
+}
+
+// levelAt returns the pressure level for a heap size
+func (b *Backpressure) levelAt(heapMB float64) string {
+	delayMB, rejectMB := b.thresholds()
+	switch {
+	case rejectMB > 0 && heapMB > rejectMB:
+		return PressureReject
+	case delayMB > 0 && heapMB > delayMB:
+		return PressureDelay
+	default:
+		return PressureNormal
+	}
+}
+
+// Status reports the current memory pressure and the work held back so far
+func (b *Backpressure) Status() *BackpressureStatus {
+	heapMB := b.heapMB()
+	level := b.levelAt(heapMB)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return &BackpressureStatus{
+		Level:             level,
+		HeapAllocMB:       heapMB,
+		DelayThresholdMB:  b.config.DelayThresholdMB,
+		RejectThresholdMB: b.config.RejectThresholdMB,
+		Waiting:           b.waiting,
+		Delayed:           b.delayed,
+		Rejected:          b.rejected,
+		Urgent:            b.urgent,
+	}
+}
+
+// Wait blocks a new processing run while memory is above the delay threshold. It returns once
+// memory drops, or after MaxDelay so a stuck heap cannot stall processing for good, and returns
+// the context's error if it is cancelled first.
+func (b *Backpressure) Wait(ctx context.Context) error {
+	return b.wait(ctx, false)
+}
+
+// WaitUrgent is the priority lane for urgent processing runs: they start ahead of the runs Wait
+// holds back and only wait while memory is above the reject threshold, as long as Wait would
+func (b *Backpressure) WaitUrgent(ctx context.Context) error {
+	b.mu.Lock()
+	b.urgent++
+	b.mu.Unlock()
+	return b.wait(ctx, true)
+}
+
+// held reports whether a run waits at a pressure level; urgent runs only wait at the reject level
+func held(level string, urgent bool) bool {
+	if urgent {
+		return level == PressureReject
+	}
+	return level != PressureNormal
+}
+
+// wait blocks a new processing run while held says it waits at the current pressure level
+func (b *Backpressure) wait(ctx context.Context, urgent bool) error {
+	heapMB := b.heapMB()
+	if !held(b.levelAt(heapMB), urgent) {
+		return nil
+	}
+
+	start := time.Now()
+	b.mu.Lock()
+	b.waiting++
+	b.delayed++
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.waiting--
+		b.mu.Unlock()
+	}()
+
+	thresholdMB, rejectMB := b.thresholds()
+	if urgent {
+		thresholdMB = rejectMB
+	}
+	TrackError(ctx, errors.NewAPIError(errors.ErrResourceExhausted,
+		fmt.Sprintf("Processing delayed: heap in use %.0fMB is above %.0fMB", heapMB, thresholdMB)),
+		"backpressure", "delay_processing")
+
+	ticker := time.NewTicker(b.config.PollInterval)
+	defer ticker.Stop()
+
+	deadline := time.NewTimer(b.config.MaxDelay)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			b.logger.WithContext(ctx).Warn("Memory still high after the maximum delay; starting processing",
+				b.logger.WithMetadata(map[string]interface{}{
+					"heap_alloc_mb": b.heapMB(),
+					"waited":        time.Since(start).String(),
+				}))
+			return nil
+		case <-ticker.C:
+			if !held(b.Level(), urgent) {
+				b.logger.WithContext(ctx).Info("Memory pressure eased; starting processing",
+					b.logger.WithMetadata(map[string]interface{}{
+						"waited": time.Since(start).String(),
+					}))
+				return nil
+			}
+		}
+	}
+}
+
+// RejectUploads is middleware that turns requests away with 503 and Retry-After while memory is
+// above the reject threshold, so large uploads do not add to the pressure
+func (b *Backpressure) RejectUploads() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		heapMB := b.heapMB()
+		if b.levelAt(heapMB) != PressureReject {
+			c.Next()
+			return
+		}
+
+		b.mu.Lock()
+		b.rejected++
+		rejectMB := b.config.RejectThresholdMB
+		b.mu.Unlock()
+
+		retryAfter := int(b.config.RetryAfter.Seconds())
+		apiErr := errors.NewAPIError(errors.ErrServiceUnavailable,
+			fmt.Sprintf("Upload rejected: heap in use %.0fMB is above %.0fMB", heapMB, rejectMB)).
+			WithUserMessage("The server is low on memory and cannot accept uploads right now").
+			WithSuggestions([]string{
+				fmt.Sprintf("Retry in %d seconds", retryAfter),
+			})
+		TrackError(c.Request.Context(), apiErr, "backpressure", "reject_upload")
+
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		errors.AbortWithError(c, apiErr)
+	}
+}
@@ -0,0 +1,182 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestBackpressure returns backpressure reading the heap size from heap, in megabytes
+func newTestBackpressure(t *testing.T, heap *atomic.Int64, config *BackpressureConfig) *Backpressure {
+	logger, err := logging.NewLogger(&logging.Config{
+		Level:  "info",
+		Format: "json",
+		Output: "stdout",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	backpressure := NewBackpressure(NewMemoryMonitor(logger, nil), config)
+	backpressure.heapMB = func() float64 { return float64(heap.Load()) }
+	return backpressure
+}
+
+func TestBackpressure_Level(t *testing.T) {
+	var heap atomic.Int64
+	backpressure := newTestBackpressure(t, &heap, &BackpressureConfig{DelayThresholdMB: 100, RejectThresholdMB: 200})
+
+	tests := []struct {
+		heapMB   int64
+		expected string
+	}{
+		{heapMB: 50, expected: PressureNormal},
+		{heapMB: 100, expected: PressureNormal},
+		{heapMB: 150, expected: PressureDelay},
+		{heapMB: 250, expected: PressureReject},
+	}
+	for _, tt := range tests {
+		heap.Store(tt.heapMB)
+		if level := backpressure.Level(); level != tt.expected {
+			t.Errorf("Expected %s at %dMB, got %s", tt.expected, tt.heapMB, level)
+		}
+	}
+
+	disabled := newTestBackpressure(t, &heap, &BackpressureConfig{})
+	if level := disabled.Level(); level != PressureNormal {
+		t.Errorf("Expected zero thresholds to disable backpressure, got %s", level)
+	}
+}
+
+func TestBackpressure_Wait(t *testing.T) {
+	var heap atomic.Int64
+	config := &BackpressureConfig{
+		DelayThresholdMB: 100,
+		MaxDelay:         time.Minute,
+		PollInterval:     5 * time.Millisecond,
+	}
+
+	t.Run("no pressure", func(t *testing.T) {
+		backpressure := newTestBackpressure(t, &heap, config)
+		heap.Store(10)
+		if err := backpressure.Wait(context.Background()); err != nil {
+			t.Fatalf("Expected no wait, got %v", err)
+		}
+		if status := backpressure.Status(); status.Delayed != 0 {
+			t.Errorf("Expected nothing delayed, got %d", status.Delayed)
+		}
+	})
+
+	t.Run("waits until memory is released", func(t *testing.T) {
+		backpressure := newTestBackpressure(t, &heap, config)
+		heap.Store(150)
+		time.AfterFunc(30*time.Millisecond, func() { heap.Store(50) })
+
+		start := time.Now()
+		if err := backpressure.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+		if waited := time.Since(start); waited < 30*time.Millisecond {
+			t.Errorf("Expected to wait for memory to drop, waited %v", waited)
+		}
+		if status := backpressure.Status(); status.Delayed != 1 || status.Waiting != 0 {
+			t.Errorf("Expected one finished delay, got %+v", status)
+		}
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		backpressure := newTestBackpressure(t, &heap, config)
+		heap.Store(150)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err := backpressure.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the context error, got %v", err)
+		}
+	})
+
+	t.Run("gives up after the maximum delay", func(t *testing.T) {
+		short := *config
+		short.MaxDelay = 20 * time.Millisecond
+		backpressure := newTestBackpressure(t, &heap, &short)
+		heap.Store(150)
+		if err := backpressure.Wait(context.Background()); err != nil {
+			t.Errorf("Expected processing to start after the maximum delay, got %v", err)
+		}
+	})
+}
+
+func TestBackpressure_WaitUrgent(t *testing.T) {
+	var heap atomic.Int64
+	backpressure := newTestBackpressure(t, &heap, &BackpressureConfig{
+		DelayThresholdMB:  100,
+		RejectThresholdMB: 200,
+		MaxDelay:          time.Minute,
+		PollInterval:      5 * time.Millisecond,
+	})
+
+	heap.Store(150)
+	if err := backpressure.WaitUrgent(context.Background()); err != nil {
+		t.Fatalf("WaitUrgent failed: %v", err)
+	}
+	if status := backpressure.Status(); status.Delayed != 0 || status.Urgent != 1 {
+		t.Errorf("Expected an urgent run to skip the delay, got %+v", status)
+	}
+
+	heap.Store(250)
+	time.AfterFunc(30*time.Millisecond, func() { heap.Store(150) })
+	start := time.Now()
+	if err := backpressure.WaitUrgent(context.Background()); err != nil {
+		t.Fatalf("WaitUrgent failed: %v", err)
+	}
+	if waited := time.Since(start); waited < 30*time.Millisecond {
+		t.Errorf("Expected an urgent run to wait above the reject threshold, waited %v", waited)
+	}
+	if status := backpressure.Status(); status.Delayed != 1 || status.Urgent != 2 {
+		t.Errorf("Expected one delayed urgent run, got %+v", status)
+	}
+}
+
+func TestBackpressure_RejectUploads(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var heap atomic.Int64
+	backpressure := newTestBackpressure(t, &heap, &BackpressureConfig{
+		DelayThresholdMB:  100,
+		RejectThresholdMB: 200,
+		RetryAfter:        45 * time.Second,
+	})
+
+	router := gin.New()
+	router.POST("/uploads", backpressure.RejectUploads(), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	upload := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/uploads", nil))
+		return w
+	}
+
+	heap.Store(150)
+	if w := upload(); w.Code != http.StatusCreated {
+		t.Errorf("Expected uploads to be accepted below the reject threshold, got %d", w.Code)
+	}
+
+	heap.Store(250)
+	w := upload()
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 above the reject threshold, got %d", w.Code)
+	}
+	if retryAfter := w.Header().Get("Retry-After"); retryAfter != "45" {
+		t.Errorf("Expected Retry-After 45, got %q", retryAfter)
+	}
+	if status := backpressure.Status(); status.Rejected != 1 || status.Level != PressureReject {
+		t.Errorf("Expected one rejection at reject level, got %+v", status)
+	}
+}
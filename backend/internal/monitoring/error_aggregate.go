@@ -0,0 +1,262 @@
+package monitoring
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+)
+
+// ErrorAggregateWindows are the time windows errors can be aggregated over, by name
+var ErrorAggregateWindows = map[string]time.Duration{
+	"1h":  time.Hour,
+	"6h":  6 * time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+// UntrackedComponent is the component of errors answered without being tracked by a component,
+// such as requests failing validation
+const UntrackedComponent = "api"
+
+const (
+	// maxOccurrenceAge keeps twice the longest window, so it can be compared with the one before
+	maxOccurrenceAge = 14 * 24 * time.Hour
+	// maxOccurrences bounds the memory held by error occurrences
+	maxOccurrences = 100000
+	// errorAggregateBuckets is how many equal intervals each window's counts are split into
+	errorAggregateBuckets = 12
+	// errorTrendThreshold is how much a count must change against the previous window, as a
+	// fraction of it, to be rising or falling
+	errorTrendThreshold = 0.2
+	// routeLookback is how many recent occurrences are searched for the one a failed request tracked
+	routeLookback = 50
+)
+
+// Error trends against the previous window
+const (
+	ErrorTrendNew     = "new"
+	ErrorTrendRising  = "rising"
+	ErrorTrendFalling = "falling"
+	ErrorTrendSteady  = "steady"
+)
+
+// errorOccurrence is one error, kept for aggregating errors over time windows
+type errorOccurrence struct {
+	at        time.Time
+	code      errors.ErrorCode
+	component string
+	route     string // method and route pattern of the failed request; empty outside requests
+	requestID string
+}
+
+// ErrorAggregateFilter narrows an aggregate to errors with a code, component or route. Limit caps
+// the groups listed, 0 listing them all.
+type ErrorAggregateFilter struct {
+	Code      string
+	Component string
+	Route     string
+	Limit     int
+}
+
+// ErrorGroup counts the errors of one code, component and route in a window
+type ErrorGroup struct {
+	Code          errors.ErrorCode `json:"code"`
+	Component     string           `json:"component"`
+	Route         string           `json:"route,omitempty"`
+	Count         int              `json:"count"`
+	PreviousCount int              `json:"previous_count"` // in the window before
+	ChangePercent *float64         `json:"change_percent"` // null when the previous window had none
+	Trend         string           `json:"trend"`
+	Buckets       []int            `json:"buckets"` // counts of equal intervals, oldest first
+	LastSeen      time.Time        `json:"last_seen"`
+}
+
+// ErrorAggregate reports the errors of a window grouped by code, component and route, with how
+// each changed against the window before
+type ErrorAggregate struct {
+	Window        string                   `json:"window"`
+	Since         time.Time                `json:"since"`
+	Until         time.Time                `json:"until"`
+	BucketSize    string                   `json:"bucket_size"`
+	Total         int                      `json:"total"`
+	PreviousTotal int                      `json:"previous_total"`
+	ChangePercent *float64                 `json:"change_percent"`
+	Trend         string                   `json:"trend"`
+	ByCode        map[errors.ErrorCode]int `json:"by_code"`
+	ByComponent   map[string]int           `json:"by_component"`
+	ByRoute       map[string]int           `json:"by_route"`
+	GroupCount    int                      `json:"group_count"` // groups before the limit
+	Groups        []ErrorGroup             `json:"groups"`
+}
+
+// recordOccurrence keeps an error for aggregation, dropping those too old or too many. The
+// caller holds et.mu.
+func (et *ErrorTracker) recordOccurrence(occurrence errorOccurrence) {
+	et.occurrences = append(et.occurrences, occurrence)
+
+	start := 0
+	if over := len(et.occurrences) - maxOccurrences; over > 0 {
+		start = over
+	}
+	cutoff := occurrence.at.Add(-maxOccurrenceAge)
+	for start < len(et.occurrences) && et.occurrences[start].at.Before(cutoff) {
+		start++
+	}
+	et.occurrences = et.occurrences[start:]
+}
+
+// RecordRequestError notes the route of a request answered with err. When a component tracked
+// the error during the request, its occurrence gets the route; otherwise the error is recorded
+// under UntrackedComponent.
+func (et *ErrorTracker) RecordRequestError(ctx context.Context, err *errors.APIError, route string) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+
+	requestID := logging.GetRequestID(ctx)
+	if requestID != "" {
+		for i := len(et.occurrences) - 1; i >= 0 && i >= len(et.occurrences)-routeLookback; i-- {
+			occurrence := &et.occurrences[i]
+			if occurrence.requestID == requestID && occurrence.code == err.Code && occurrence.route == "" {
+				occurrence.route = route
+				return
+			}
+		}
+	}
+	et.recordOccurrence(errorOccurrence{
+		at:        time.Now(),
+		code:      err.Code,
+		component: UntrackedComponent,
+		route:     route,
+		requestID: requestID,
+	})
+}
+
+// AggregateErrors groups the errors of the window ending at now by code, component and route,
+// most frequent first, comparing each with the window before
+func (et *ErrorTracker) AggregateErrors(window time.Duration, now time.Time, filter ErrorAggregateFilter) *ErrorAggregate {
+	et.mu.RLock()
+	defer et.mu.RUnlock()
+
+	since := now.Add(-window)
+	previousSince := since.Add(-window)
+	bucketSize := window / errorAggregateBuckets
+	aggregate := &ErrorAggregate{
+		Since:       since,
+		Until:       now,
+		BucketSize:  bucketSize.String(),
+		ByCode:      map[errors.ErrorCode]int{},
+		ByComponent: map[string]int{},
+		ByRoute:     map[string]int{},
+		Groups:      []ErrorGroup{},
+	}
+
+	type groupKey struct {
+		code      errors.ErrorCode
+		component string
+		route     string
+	}
+	groups := map[groupKey]*ErrorGroup{}
+	for _, occurrence := range et.occurrences {
+		if occurrence.at.Before(previousSince) || occurrence.at.After(now) ||
+			(filter.Code != "" && string(occurrence.code) != filter.Code) ||
+			(filter.Component != "" && occurrence.component != filter.Component) ||
+			(filter.Route != "" && occurrence.route != filter.Route) {
+			continue
+		}
+
+		key := groupKey{occurrence.code, occurrence.component, occurrence.route}
+		group := groups[key]
+		if group == nil {
+			group = &ErrorGroup{Code: key.code, Component: key.component, Route: key.route, Buckets: make([]int, errorAggregateBuckets)}
+			groups[key] = group
+		}
+		if occurrence.at.Before(since) {
+			group.PreviousCount++
+			aggregate.PreviousTotal++
+			continue
+		}
+
+		group.Count++
+		bucket := int(occurrence.at.Sub(since) / bucketSize)
+		if bucket >= errorAggregateBuckets {
+			bucket = errorAggregateBuckets - 1
+		}
+		group.Buckets[bucket]++
+		if occurrence.at.After(group.LastSeen) {
+			group.LastSeen = occurrence.at
+		}
+		aggregate.Total++
+		aggregate.ByCode[occurrence.code]++
+		aggregate.ByComponent[occurrence.component]++
+		if occurrence.route != "" {
+			aggregate.ByRoute[occurrence.route]++
+		}
+	}
+
+	for _, group := range groups {
+		if group.Count == 0 {
+			continue
+		}
+		group.ChangePercent, group.Trend = errorTrend(group.Count, group.PreviousCount)
+		aggregate.Groups = append(aggregate.Groups, *group)
+	}
+	sort.Slice(aggregate.Groups, func(i, j int) bool {
+		a, b := aggregate.Groups[i], aggregate.Groups[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		return a.Route < b.Route
+	})
+	aggregate.GroupCount = len(aggregate.Groups)
+	if filter.Limit > 0 && len(aggregate.Groups) > filter.Limit {
+		aggregate.Groups = aggregate.Groups[:filter.Limit]
+	}
+	aggregate.ChangePercent, aggregate.Trend = errorTrend(aggregate.Total, aggregate.PreviousTotal)
+	return aggregate
+}
+
+// errorTrend compares a window's count with the previous window's
+func errorTrend(count, previous int) (*float64, string) {
+	if previous == 0 {
+		if count == 0 {
+			return nil, ErrorTrendSteady
+		}
+		return nil, ErrorTrendNew
+	}
+	change := float64(count-previous) / float64(previous)
+	percent := math.Round(change*10000) / 100
+	switch {
+	case change > errorTrendThreshold:
+		return &percent, ErrorTrendRising
+	case change < -errorTrendThreshold:
+		return &percent, ErrorTrendFalling
+	default:
+		return &percent, ErrorTrendSteady
+	}
+}
+
+// RecordRequestError notes the route of a failed request with the global error tracker
+func RecordRequestError(ctx context.Context, err *errors.APIError, route string) {
+	if globalErrorTracker != nil {
+		globalErrorTracker.RecordRequestError(ctx, err, route)
+	}
+}
+
+// AggregateErrors aggregates the errors of the global error tracker, or returns nil before
+// monitoring is initialized
+func AggregateErrors(window time.Duration, now time.Time, filter ErrorAggregateFilter) *ErrorAggregate {
+	if globalErrorTracker == nil {
+		return nil
+	}
+	return globalErrorTracker.AggregateErrors(window, now, filter)
+}
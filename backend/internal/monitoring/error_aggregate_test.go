@@ -0,0 +1,96 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/logging"
+)
+
+func TestErrorTracker_AggregateErrors(t *testing.T) {
+	logger, err := logging.NewLogger(&logging.Config{
+		Level:  "error",
+		Format: "json",
+		Output: "stdout",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	tracker := NewErrorTracker(logger, 100)
+
+	now := time.Date(2025, 9, 22, 12, 0, 0, 0, time.UTC)
+	record := func(ago time.Duration, code errors.ErrorCode, component, route string, count int) {
+		for i := 0; i < count; i++ {
+			tracker.recordOccurrence(errorOccurrence{at: now.Add(-ago), code: code, component: component, route: route})
+		}
+	}
+	record(30*time.Minute, errors.ErrValidationError, UntrackedComponent, "GET /api/v2/analytics/priority", 6)
+	record(90*time.Minute, errors.ErrValidationError, UntrackedComponent, "GET /api/v2/analytics/priority", 2)
+	record(10*time.Minute, errors.ErrDatabaseError, "analytics_handler", "GET /api/v2/analytics/summary", 2)
+	record(70*time.Minute, errors.ErrDatabaseError, "analytics_handler", "GET /api/v2/analytics/summary", 4)
+	record(5*time.Minute, errors.ErrProcessingFailed, "job_queue", "", 1)
+	record(3*time.Hour, errors.ErrQueryTimeout, "analytics_handler", "GET /api/v2/analytics/trends", 5)
+
+	aggregate := tracker.AggregateErrors(time.Hour, now, ErrorAggregateFilter{})
+	if aggregate.Total != 9 || aggregate.PreviousTotal != 6 || aggregate.Trend != ErrorTrendRising {
+		t.Fatalf("expected 9 errors against 6, rising, got %d against %d, %s", aggregate.Total, aggregate.PreviousTotal, aggregate.Trend)
+	}
+	if aggregate.BucketSize != "5m0s" || aggregate.ByRoute["GET /api/v2/analytics/priority"] != 6 || aggregate.ByComponent[UntrackedComponent] != 6 {
+		t.Errorf("unexpected breakdown: %+v", aggregate)
+	}
+	if len(aggregate.Groups) != 3 || aggregate.GroupCount != 3 {
+		t.Fatalf("expected the three groups seen in the last hour, got %+v", aggregate.Groups)
+	}
+
+	validation := aggregate.Groups[0]
+	if validation.Code != errors.ErrValidationError || validation.Count != 6 || validation.PreviousCount != 2 ||
+		validation.ChangePercent == nil || *validation.ChangePercent != 200 || validation.Trend != ErrorTrendRising {
+		t.Errorf("unexpected validation group: %+v", validation)
+	}
+	if validation.Buckets[6] != 6 || !validation.LastSeen.Equal(now.Add(-30*time.Minute)) {
+		t.Errorf("expected the validation errors in the seventh bucket, got %v", validation.Buckets)
+	}
+	database := aggregate.Groups[1]
+	if database.Code != errors.ErrDatabaseError || database.Trend != ErrorTrendFalling || *database.ChangePercent != -50 {
+		t.Errorf("unexpected database group: %+v", database)
+	}
+	background := aggregate.Groups[2]
+	if background.Route != "" || background.Trend != ErrorTrendNew || background.ChangePercent != nil {
+		t.Errorf("unexpected background group: %+v", background)
+	}
+
+	aggregate = tracker.AggregateErrors(time.Hour, now, ErrorAggregateFilter{Component: "analytics_handler", Limit: 1})
+	if aggregate.Total != 2 || len(aggregate.Groups) != 1 || aggregate.GroupCount != 1 {
+		t.Errorf("expected only the analytics handler's errors, got %+v", aggregate)
+	}
+}
+
+func TestErrorTracker_RecordRequestError(t *testing.T) {
+	logger, err := logging.NewLogger(&logging.Config{
+		Level:  "error",
+		Format: "json",
+		Output: "stdout",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	tracker := NewErrorTracker(logger, 100)
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+
+	// An error a component tracked during the request gets the request's route
+	tracked := errors.NewAPIError(errors.ErrDatabaseError, "Database operation failed: query summary")
+	tracker.TrackError(ctx, tracked, "analytics_handler", "get_summary")
+	tracker.RecordRequestError(ctx, tracked, "GET /api/v2/analytics/summary")
+	tracker.RecordRequestError(logging.WithRequestID(context.Background(), "req-2"),
+		errors.NewAPIError(errors.ErrValidationError, "Validation failed"), "GET /api/v2/analytics/summary")
+
+	aggregate := tracker.AggregateErrors(time.Hour, time.Now().Add(time.Second), ErrorAggregateFilter{})
+	if aggregate.Total != 2 || aggregate.ByRoute["GET /api/v2/analytics/summary"] != 2 {
+		t.Fatalf("expected both errors on the summary route, got %+v", aggregate)
+	}
+	if aggregate.ByComponent["analytics_handler"] != 1 || aggregate.ByComponent[UntrackedComponent] != 1 {
+		t.Errorf("expected the tracked error under its component, got %v", aggregate.ByComponent)
+	}
+}
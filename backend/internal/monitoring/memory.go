@@ -124,8 +124,24 @@ func (m *MemoryMonitor) collectStats() {
 	}
 }
 
-// updateStats updates the current memory statistics
+// updateStats updates the current memory statistics, logging when usage is high
 func (m *MemoryMonitor) updateStats() {
+	ms := m.refresh()
+
+	// Log if memory usage is high
+	if ms.Alloc > 100*1024*1024 { // 100MB
+		m.logger.Warn("High memory usage detected",
+			nil,
+			m.logger.WithMetadata(map[string]interface{}{
+				"alloc_mb":      float64(ms.Alloc) / 1024 / 1024,
+				"heap_alloc_mb": float64(ms.HeapAlloc) / 1024 / 1024,
+				"sys_mb":        float64(ms.Sys) / 1024 / 1024,
+			}))
+	}
+}
+
+// refresh reads and stores the current memory statistics
+func (m *MemoryMonitor) refresh() *runtime.MemStats {
 	var ms runtime.MemStats
 	runtime.ReadMemStats(&ms)
 
@@ -165,16 +181,7 @@ func (m *MemoryMonitor) updateStats() {
 	}
 	m.mu.Unlock()
 
-	// Log if memory usage is high
-	if ms.Alloc > 100*1024*1024 { // 100MB
-		m.logger.Warn("High memory usage detected",
-			nil,
-			m.logger.WithMetadata(map[string]interface{}{
-				"alloc_mb":      float64(ms.Alloc) / 1024 / 1024,
-				"heap_alloc_mb": float64(ms.HeapAlloc) / 1024 / 1024,
-				"sys_mb":        float64(ms.Sys) / 1024 / 1024,
-			}))
-	}
+	return &ms
 }
 
 // GetStats returns current memory statistics
@@ -211,6 +218,19 @@ func (m *MemoryMonitor) GetMemoryUsage() map[string]interface{} {
 	}
 }
 
+// HeapAllocMB returns the heap in use in megabytes, reading fresh statistics when the last
+// collection is older than maxAge
+func (m *MemoryMonitor) HeapAllocMB(maxAge time.Duration) float64 {
+	m.mu.RLock()
+	stats := m.stats
+	m.mu.RUnlock()
+
+	if time.Since(stats.Timestamp) > maxAge {
+		return float64(m.refresh().HeapAlloc) / 1024 / 1024
+	}
+	return float64(stats.HeapAlloc) / 1024 / 1024
+}
+
 // IsMemoryUsageHigh checks if memory usage is above threshold
 func (m *MemoryMonitor) IsMemoryUsageHigh(thresholdMB float64) bool {
 	m.mu.RLock()
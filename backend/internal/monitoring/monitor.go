@@ -20,6 +20,8 @@ type ErrorTracker struct {
 	logger       *logging.Logger
 	maxEvents    int
 	alertThresholds *AlertThresholds
+	// occurrences records every error, unlike errors, for aggregating them over time windows
+	occurrences []errorOccurrence
 }
 
 // ErrorEvent represents a tracked error event
@@ -165,6 +167,13 @@ func (et *ErrorTracker) TrackError(ctx context.Context, err *errors.APIError, co
 		}
 	}
 	
+	et.recordOccurrence(errorOccurrence{
+		at:        event.Timestamp,
+		code:      err.Code,
+		component: component,
+		requestID: event.RequestID,
+	})
+
 	// Update metrics
 	et.updateMetrics(err, event.Severity, component)
 	
@@ -0,0 +1,929 @@
+// Package server wires the services, handlers and routes of the API server
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/errors"
+	"incident-management-system/internal/handlers"
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/monitoring"
+	"incident-management-system/internal/services"
+	"incident-management-system/internal/storage"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Config holds the storage locations and credentials of a server. Other settings are read from
+// the environment.
+type Config struct {
+	DatabasePath string
+	UploadDir    string
+	ExportDir    string
+	ArchiveDir   string
+	DumpDir      string
+	// AdminToken guards the admin and debug endpoints; without it the debug endpoints are disabled
+	AdminToken string
+}
+
+// ConfigFromEnv returns the configuration of the production server
+func ConfigFromEnv() Config {
+	return Config{
+		DatabasePath: "incident_management.db",
+		UploadDir:    "uploads",
+		ExportDir:    envString("EXPORT_DIR", "exports"),
+		ArchiveDir:   envString("ARCHIVE_DIR", "archive"),
+		DumpDir:      envString("DEBUG_DUMP_DIR", "dumps"),
+		AdminToken:   os.Getenv("ADMIN_TOKEN"),
+	}
+}
+
+// legacyAPIDeprecated is when the unversioned /api routes were deprecated in favour of /api/v1
+var legacyAPIDeprecated = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+
+// Server is the fully wired API. Background work runs until Close.
+type Server struct {
+	Router        *gin.Engine
+	DB            *database.DB
+	MemoryMonitor *monitoring.MemoryMonitor
+
+	cancel  context.CancelFunc
+	closers []func()
+}
+
+// New initializes the database, services and handlers and registers all routes. Background work
+// stops when ctx is cancelled or the server is closed.
+func New(ctx context.Context, config Config) (_ *Server, err error) {
+	logger := logging.GetGlobalLogger()
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Server{cancel: cancel}
+	defer func() {
+		if err != nil {
+			s.Close()
+		}
+	}()
+
+	// Initialize memory monitoring
+	memConfig := &monitoring.MemoryConfig{
+		CollectionInterval: 30 * time.Second,
+	}
+	memMonitor := monitoring.NewMemoryMonitor(logger, memConfig)
+	s.MemoryMonitor = memMonitor
+	memMonitor.Start()
+	s.closers = append(s.closers, memMonitor.Stop)
+
+	// Hold back new processing and uploads while the heap is large
+	backpressureConfig := monitoring.DefaultBackpressureConfig()
+	backpressureConfig.DelayThresholdMB = envFloat("MEMORY_DELAY_THRESHOLD_MB", backpressureConfig.DelayThresholdMB)
+	backpressureConfig.RejectThresholdMB = envFloat("MEMORY_REJECT_THRESHOLD_MB", backpressureConfig.RejectThresholdMB)
+	backpressure := monitoring.NewBackpressure(memMonitor, backpressureConfig)
+
+	// Initialize database
+	dbConfig := &database.Config{
+		DatabasePath:       config.DatabasePath,
+		SlowQueryThreshold: time.Duration(envFloat("SLOW_QUERY_THRESHOLD_MS", float64(database.DefaultSlowQueryThreshold/time.Millisecond))) * time.Millisecond,
+	}
+	db, err := database.NewDB(dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	s.DB = db
+	s.closers = append(s.closers, func() { db.Close() })
+
+	// Initialize database schema
+	if err := db.InitializeDatabase(); err != nil {
+		return nil, fmt.Errorf("failed to initialize database schema: %w", err)
+	}
+
+	// Initialize file storage
+	fileStore := storage.NewFileStore(config.UploadDir)
+
+	// Initialize services
+	processingService := services.NewProcessingService(db.GetConnection(), fileStore)
+	processingService.SetGate(backpressure)
+
+	// Settings admins can change through /api/admin/config. Saved values override the defaults
+	// below, which come from the environment, and live settings are applied as they register.
+	configService, err := services.NewSystemConfigService(ctx, db.GetConnection())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load system settings: %w", err)
+	}
+	configService.Register(services.Setting{
+		Key:         "memory.delay_threshold_mb",
+		Type:        services.SettingFloat,
+		Description: "Heap in use above which new processing runs wait; 0 disables",
+		Default:     backpressureConfig.DelayThresholdMB,
+		Max:         1 << 20,
+		Apply:       func(value interface{}) { backpressure.SetDelayThreshold(value.(float64)) },
+	})
+	configService.Register(services.Setting{
+		Key:         "memory.reject_threshold_mb",
+		Type:        services.SettingFloat,
+		Description: "Heap in use above which uploads are rejected; 0 disables",
+		Default:     backpressureConfig.RejectThresholdMB,
+		Max:         1 << 20,
+		Apply:       func(value interface{}) { backpressure.SetRejectThreshold(value.(float64)) },
+	})
+	configService.Register(services.Setting{
+		Key:         "database.slow_query_threshold_ms",
+		Type:        services.SettingInt,
+		Description: "Milliseconds a database statement runs before the slow query log behind the index advisor records it; 0 disables",
+		Default:     int(db.SlowQueries().Threshold() / time.Millisecond),
+		Max:         10 * 60 * 1000,
+		Apply: func(value interface{}) {
+			db.SlowQueries().SetThreshold(time.Duration(value.(int)) * time.Millisecond)
+		},
+	})
+	configService.Register(services.Setting{
+		Key:         "processing.parser_workers",
+		Type:        services.SettingInt,
+		Description: "Spreadsheet rows parsed concurrently",
+		Default:     runtime.NumCPU(),
+		Min:         1,
+		Max:         256,
+		Apply:       func(value interface{}) { processingService.SetParserWorkers(value.(int)) },
+	})
+	configService.Register(services.Setting{
+		Key:         "processing.continuity_threshold_pct",
+		Type:        services.SettingFloat,
+		Description: "Difference from the previous upload in a day's count for an application that raises a data-quality alert",
+		Default:     services.DefaultContinuityThreshold,
+		Max:         1000,
+		Apply:       func(value interface{}) { processingService.SetContinuityThreshold(value.(float64)) },
+	})
+	configService.Register(services.Setting{
+		Key:         "processing.distribution_shift_factor",
+		Type:        services.SettingFloat,
+		Description: "How many times larger or smaller than in the prior period a priority or application share or the average resolution time may become before an upload's distribution check flags it",
+		Default:     services.DefaultDistributionShiftFactor,
+		Min:         1.1,
+		Max:         100,
+		Apply:       func(value interface{}) { processingService.SetDistributionShiftFactor(value.(float64)) },
+	})
+	configService.Register(services.Setting{
+		Key:         "jobs.workers",
+		Type:        services.SettingInt,
+		Description: "Background jobs run concurrently",
+		Default:     3,
+		Min:         1,
+		Max:         64,
+	})
+
+	// Feature flags guarding risky features; admins switch them at runtime, also per tenant
+	flags, err := services.NewFeatureFlagService(ctx, db.GetConnection(), 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feature flags: %w", err)
+	}
+	flags.Register(services.FlagDefinition{
+		Key:         services.FlagShadowAnalysis,
+		Description: "Run the active shadow analyzer configuration after each upload",
+		Default:     true,
+	})
+	flags.Register(services.FlagDefinition{
+		Key:         services.FlagArchiveFederation,
+		Description: "Include archived incidents in analytics whose date range spans them",
+		Default:     os.Getenv("ARCHIVE_FEDERATION") != "false",
+	})
+	flags.Register(services.FlagDefinition{
+		Key:         services.FlagJiraTickets,
+		Description: "File automation candidates as Jira tickets",
+		Default:     true,
+	})
+	processingService.SetFeatureFlags(flags)
+
+	// Background jobs: incident exports, IT process re-categorizations and derived artifact rebuilds
+	exportService, err := services.NewIncidentExportService(db.GetConnection(), config.ExportDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize export service: %w", err)
+	}
+	jobQueue := services.NewJobQueue(services.JobQueueConfig{Workers: configService.Int("jobs.workers")}, processingService)
+	jobQueue.SetExportService(exportService)
+	jobQueue.SetRecategorizer(services.NewITProcessRecategorizer(db.GetConnection()))
+	jobQueue.SetRebuilder(services.NewArtifactRebuilder(db.GetConnection()))
+	// Urgent upload processing holds back cache warming and backfill jobs until it finishes
+	processingService.SetPreemptor(jobQueue)
+	// Failure injection for exercising retries and alerting in test and staging environments only
+	jobQueue.SetChaos(services.ChaosConfig{
+		FailurePct: envFloat("JOB_CHAOS_FAILURE_PCT", 0),
+		DelayPct:   envFloat("JOB_CHAOS_DELAY_PCT", 0),
+		MaxDelay:   time.Duration(envFloat("JOB_CHAOS_MAX_DELAY_MS", 0) * float64(time.Millisecond)),
+		Seed:       int64(envFloat("JOB_CHAOS_SEED", 0)),
+	})
+	configService.Register(services.Setting{
+		Key:         "jobs.timeout_minutes",
+		Type:        services.SettingInt,
+		Description: "Deadline of a single background job attempt",
+		Default:     30,
+		Min:         1,
+		Max:         24 * 60,
+		Apply: func(value interface{}) {
+			jobQueue.SetJobTimeout(time.Duration(value.(int)) * time.Minute)
+		},
+	})
+	s.closers = append(s.closers, jobQueue.Shutdown)
+
+	// Import scheduled Google Sheets sources until shutdown
+	sheetsService := services.NewGoogleSheetsService(db.GetConnection(), fileStore)
+	go sheetsService.RunScheduler(ctx, processingService, time.Minute)
+
+	// Evaluate watchlists once a day, notifying their owners through SMTP_* and their Slack webhooks
+	notifier := services.NewNotifier(&services.SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	})
+	if !notifier.EmailConfigured() {
+		logger.Warn("SMTP_HOST or SMTP_FROM is not set; watchlist alerts are not emailed")
+	}
+	watchlistService := services.NewWatchlistService(db.GetConnection(), notifier)
+	go watchlistService.RunScheduler(ctx, time.Hour)
+
+	// Send the notifications held for users' digests or quiet hours as they fall due
+	notificationService := services.NewNotificationPreferenceService(db.GetConnection(), notifier)
+	go notificationService.RunScheduler(ctx, time.Minute)
+
+	// Anonymized API usage analytics; USAGE_TRACKING=false opts out of recording
+	usageSalt := os.Getenv("USAGE_HASH_SALT")
+	if usageSalt == "" {
+		usageSalt = uuid.New().String()
+		logger.Warn("USAGE_HASH_SALT is not set; usage analytics count users per server run")
+	}
+	usageService := services.NewUsageService(db.GetConnection(), services.UsageConfig{Salt: usageSalt})
+	// Stopped on Close, after the HTTP server drains, so the events of the last requests are written
+	usageCtx, stopUsage := context.WithCancel(context.Background())
+	usageDone := make(chan struct{})
+	go func() {
+		usageService.Run(usageCtx)
+		close(usageDone)
+	}()
+	s.closers = append(s.closers, func() {
+		stopUsage()
+		<-usageDone
+	})
+	configService.Register(services.Setting{
+		Key:         "usage.tracking_enabled",
+		Type:        services.SettingBool,
+		Description: "Record anonymized API usage events",
+		Default:     os.Getenv("USAGE_TRACKING") != "false",
+		Apply:       func(value interface{}) { usageService.SetEnabled(value.(bool)) },
+	})
+
+	// Sample the in-memory error and performance metrics every 5 minutes, so their history
+	// survives restarts
+	metricsHistory := services.NewMetricsHistoryService(db.GetConnection())
+	go metricsHistory.RunRecorder(ctx, 5*time.Minute, metricsSample)
+	configService.Register(services.Setting{
+		Key:         "monitoring.history_retention_days",
+		Type:        services.SettingInt,
+		Description: "Days metrics history samples are kept",
+		Default:     int(services.DefaultMetricsRetention / (24 * time.Hour)),
+		Min:         1,
+		Max:         3650,
+		Apply: func(value interface{}) {
+			metricsHistory.SetRetention(time.Duration(value.(int)) * 24 * time.Hour)
+		},
+	})
+
+	// Initialize handlers
+	uploadHandler := handlers.NewUploadHandler(db.GetConnection(), fileStore, processingService)
+	uploadHandler.SetBaseContext(ctx)
+	// Download links for uploaded files are signed with FILE_URL_SIGNING_KEY; set it so links
+	// survive restarts and work across instances
+	signingKey := os.Getenv("FILE_URL_SIGNING_KEY")
+	if signingKey == "" {
+		logger.Warn("FILE_URL_SIGNING_KEY is not set; signed download links stop working on restart")
+	}
+	uploadHandler.SetURLSigner(storage.NewURLSigner([]byte(signingKey)))
+	analyticsHandler := handlers.NewAnalyticsHandler(db.GetConnection())
+	configService.Register(services.Setting{
+		Key:         "analytics.cache_ttl_minutes",
+		Type:        services.SettingInt,
+		Description: "How long analytics results are cached",
+		Default:     5,
+		Min:         1,
+		Max:         24 * 60,
+		Apply: func(value interface{}) {
+			analyticsHandler.SetCacheTTL(time.Duration(value.(int)) * time.Minute)
+		},
+	})
+
+	// Archived incidents live in per-year Parquet files; analytics federate them while the
+	// analytics.archive_federation flag is on
+	archiveStore, err := services.NewArchiveStore(db.GetConnection(), config.ArchiveDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open incident archive: %w", err)
+	}
+	analyticsHandler.SetArchiveStore(archiveStore)
+	analyticsHandler.SetFeatureFlags(flags)
+
+	// Warm the analytics cache through the job queue once an upload adds incidents, so the first
+	// dashboard load after a big upload is not a cold miss on every query
+	jobQueue.SetCacheWarmer(analyticsHandler.CacheWarmer())
+	var cacheWarming atomic.Bool
+	configService.Register(services.Setting{
+		Key:         "analytics.cache_warming_enabled",
+		Type:        services.SettingBool,
+		Description: "Precompute common analytics after each completed upload",
+		Default:     os.Getenv("CACHE_WARMING") != "false",
+		Apply:       func(value interface{}) { cacheWarming.Store(value.(bool)) },
+	})
+	processingService.SetOnCompleted(func(ctx context.Context, uploadID string) {
+		if !cacheWarming.Load() {
+			return
+		}
+		if _, err := jobQueue.SubmitJobContext(ctx, services.JobTypeWarmCache, uploadID, nil); err != nil {
+			logger.WithContext(ctx).Warn("Analytics cache warm not queued", "upload_id", uploadID, "error", err.Error())
+		}
+	})
+	archiveHandler := handlers.NewArchiveHandler(archiveStore)
+	applicationHandler := handlers.NewApplicationHandler(db.GetConnection())
+	orgHandler := handlers.NewOrgHandler(db.GetConnection())
+	serviceCatalogHandler := handlers.NewServiceCatalogHandler(db.GetConnection())
+	costCenterHandler := handlers.NewCostCenterHandler(db.GetConnection())
+	cmdbHandler := handlers.NewCMDBHandler(db.GetConnection())
+	cmdbHandler.SetServiceNowConfig(&services.ServiceNowConfig{
+		BaseURL:          os.Getenv("SERVICENOW_BASE_URL"),
+		Username:         os.Getenv("SERVICENOW_USERNAME"),
+		Password:         os.Getenv("SERVICENOW_PASSWORD"),
+		ApplicationTable: os.Getenv("SERVICENOW_CMDB_APPLICATION_TABLE"),
+		ServiceTable:     os.Getenv("SERVICENOW_CMDB_SERVICE_TABLE"),
+	})
+	maintenanceHandler := handlers.NewMaintenanceHandler(db.GetConnection())
+	watchlistHandler := handlers.NewWatchlistHandler(watchlistService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	goalHandler := handlers.NewGoalHandler(db.GetConnection())
+	holidayHandler := handlers.NewHolidayHandler(db.GetConnection())
+	reportHandler := handlers.NewReportHandler(db.GetConnection())
+	reportSnapshotHandler := handlers.NewReportSnapshotHandler(db.GetConnection())
+	metricsHistoryHandler := handlers.NewMetricsHistoryHandler(metricsHistory)
+	metaHandler := handlers.NewMetaHandler(db.GetConnection())
+	indexAdvisorHandler := handlers.NewIndexAdvisorHandler(db)
+	recategorizeHandler := handlers.NewRecategorizeHandler(jobQueue)
+	rebuildHandler := handlers.NewRebuildHandler(jobQueue)
+	analyticsViewHandler := handlers.NewAnalyticsViewHandler(db.GetConnection())
+	automationHandler := handlers.NewAutomationHandler(db.GetConnection())
+	automationHandler.SetJiraConfig(&services.JiraConfig{
+		BaseURL:    os.Getenv("JIRA_BASE_URL"),
+		Email:      os.Getenv("JIRA_EMAIL"),
+		APIToken:   os.Getenv("JIRA_API_TOKEN"),
+		ProjectKey: os.Getenv("JIRA_PROJECT_KEY"),
+		IssueType:  os.Getenv("JIRA_ISSUE_TYPE"),
+	})
+	feedbackHandler := handlers.NewFeedbackHandler(db.GetConnection())
+	dataQualityHandler := handlers.NewDataQualityHandler(db.GetConnection())
+	incidentHandler := handlers.NewIncidentHandler(db.GetConnection())
+	incidentHandler.SetProcessingService(processingService)
+	exportHandler := handlers.NewExportHandler(jobQueue, exportService)
+	shadowHandler := handlers.NewShadowHandler(db.GetConnection())
+	mappingProfileHandler := handlers.NewMappingProfileHandler(db.GetConnection())
+	ruleSetHandler := handlers.NewValidationRuleSetHandler(db.GetConnection())
+	usageHandler := handlers.NewUsageHandler(usageService)
+	configHandler := handlers.NewConfigHandler(configService)
+	flagHandler := handlers.NewFeatureFlagHandler(flags)
+	scopeService := services.NewDataScopeService(db.GetConnection())
+	scopeHandler := handlers.NewDataScopeHandler(scopeService)
+	sandboxService := services.NewSandboxService(db.GetConnection())
+	sandboxHandler := handlers.NewSandboxHandler(sandboxService)
+	apiKeyService := services.NewAPIKeyService(db.GetConnection())
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+
+	// Single sign-on through an OpenID Connect provider, enabled by OIDC_ISSUER_URL. With
+	// SSO_REQUIRED=true every API request outside /api/auth needs a session; admin endpoints
+	// also accept ADMIN_TOKEN.
+	var ssoService *services.SSOService
+	ssoRequired := os.Getenv("SSO_REQUIRED") == "true"
+	if issuer := os.Getenv("OIDC_ISSUER_URL"); issuer != "" {
+		ssoConfig := services.SSOConfig{
+			IssuerURL:    issuer,
+			DiscoveryURL: os.Getenv("OIDC_DISCOVERY_URL"),
+			ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+			ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+			GroupsClaim:  os.Getenv("OIDC_GROUPS_CLAIM"),
+			DefaultRole:  os.Getenv("OIDC_DEFAULT_ROLE"),
+			SessionTTL:   time.Duration(envFloat("SSO_SESSION_HOURS", 8) * float64(time.Hour)),
+		}
+		if scopes := os.Getenv("OIDC_SCOPES"); scopes != "" {
+			ssoConfig.Scopes = strings.Fields(strings.ReplaceAll(scopes, ",", " "))
+		}
+		// OIDC_ROLE_MAPPINGS maps identity provider groups to roles, such as
+		// {"ims-admins":"admin","ims-analysts":"analyst"}
+		if mappings := os.Getenv("OIDC_ROLE_MAPPINGS"); mappings != "" {
+			if err := json.Unmarshal([]byte(mappings), &ssoConfig.RoleMappings); err != nil {
+				return nil, fmt.Errorf("invalid OIDC_ROLE_MAPPINGS: %w", err)
+			}
+		}
+		ssoService, err = services.NewSSOService(db.GetConnection(), ssoConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure single sign-on: %w", err)
+		}
+	} else if ssoRequired {
+		return nil, fmt.Errorf("SSO_REQUIRED is set but OIDC_ISSUER_URL is not")
+	}
+
+	// Runtime diagnostics are served only to callers holding the admin token
+	adminToken := config.AdminToken
+
+	// Request and response bodies of the DEBUG_BODY_LOG_ROUTES prefixes are logged, redacted,
+	// while DEBUG_BODY_LOGGING is set; admins can switch it at runtime
+	bodyLogConfig := logging.DefaultBodyLogConfig()
+	bodyLogConfig.Enabled = os.Getenv("DEBUG_BODY_LOGGING") == "true"
+	if routes := os.Getenv("DEBUG_BODY_LOG_ROUTES"); routes != "" {
+		bodyLogConfig.Routes = strings.Split(routes, ",")
+	}
+	bodyLogConfig.MaxBodyBytes = int(envFloat("DEBUG_BODY_LOG_MAX_BYTES", float64(bodyLogConfig.MaxBodyBytes)))
+	bodyLogger, err := logging.NewBodyLogger(logger, bodyLogConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body logging configuration: %w", err)
+	}
+	debugHandler := handlers.NewDebugHandler(config.DumpDir, bodyLogger)
+
+	// Initialize Gin router with custom mode
+	gin.SetMode(gin.ReleaseMode) // Disable Gin's default logging
+	r := gin.New()
+
+	// Add middleware
+	r.Use(logging.RequestIDMiddleware())
+	r.Use(logging.LoggingMiddleware(logger))
+	r.Use(handlers.ErrorAnalytics())
+	r.Use(bodyLogger.Middleware())
+	r.Use(errors.RecoveryHandler())
+	r.Use(errors.ErrorHandler())
+
+	// Configure CORS
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowOrigins = []string{"http://localhost:5173"} // Vite dev server
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID", "X-User-ID", "X-Tenant-ID", "X-Sandbox-Token"}
+	corsConfig.AllowCredentials = true // Single sign-on sessions are cookies
+	r.Use(cors.New(corsConfig))
+
+	// Health check endpoint
+	r.GET("/health", func(c *gin.Context) {
+		health := monitoring.GetHealthStatus()
+		c.JSON(http.StatusOK, health)
+	})
+
+	// Monitoring endpoints
+	r.GET("/metrics", func(c *gin.Context) {
+		metrics, err := monitoring.ExportMetrics()
+		if err != nil {
+			errors.SendError(c, errors.InternalServer("Failed to export metrics"))
+			return
+		}
+		c.Data(http.StatusOK, "application/json", metrics)
+	})
+
+	// Memory monitoring endpoints
+	r.GET("/memory", func(c *gin.Context) {
+		memUsage := memMonitor.GetMemoryUsage()
+		memUsage["backpressure"] = backpressure.Status()
+		c.JSON(http.StatusOK, memUsage)
+	})
+
+	r.POST("/memory/gc", func(c *gin.Context) {
+		memMonitor.ForceGC()
+		c.JSON(http.StatusOK, gin.H{"message": "Garbage collection forced"})
+	})
+
+	// Signed download links for uploaded files, usable without a session until they expire
+	r.GET(handlers.SignedUploadFilePath+":id", uploadHandler.DownloadSignedUploadFile)
+
+	// Admin-only runtime diagnostics
+	if adminToken != "" {
+		debug := r.Group("/debug", handlers.AdminAuth(adminToken))
+		{
+			debug.GET("/pprof/*profile", debugHandler.Pprof)
+			debug.POST("/pprof/symbol", debugHandler.Pprof)
+			debug.GET("/vars", debugHandler.Vars)
+			debug.POST("/goroutines/dump", debugHandler.DumpGoroutines)
+			debug.GET("/body-logging", debugHandler.GetBodyLogging)
+			debug.PUT("/body-logging", debugHandler.UpdateBodyLogging)
+		}
+	} else {
+		logger.Warn("ADMIN_TOKEN is not set; debug endpoints are disabled")
+	}
+
+	// API routes. Users with a data scope only see the incidents of their applications and groups,
+	// and requests with X-Sandbox-Token see them through that sandbox's overlay. Every version is
+	// served under /api/<version>. Handlers whose responses change in a later version check
+	// handlers.RequestAPIVersion, so older versions keep their shapes, and endpoints added after v1
+	// are only registered for later versions. From v2, observer API keys may read the analytics
+	// endpoints listed on them without a session.
+	registerAPI := func(prefix, version string, deprecation ...gin.HandlerFunc) {
+		middleware := append([]gin.HandlerFunc{handlers.APIVersion(version)}, deprecation...)
+		if version != handlers.APIVersion1 {
+			middleware = append(middleware, handlers.ObserverAuth(apiKeyService, prefix+"/analytics/"))
+		}
+		middleware = append(middleware,
+			handlers.SSOAuth(ssoService, ssoRequired, prefix+"/auth/", prefix+"/admin/", prefix+"/shared/"),
+			handlers.TenantContext(), handlers.DataScope(scopeService), handlers.Sandbox(sandboxService),
+			handlers.UsageTracking(usageService))
+		api := r.Group(prefix, middleware...)
+
+		// Single sign-on endpoints
+		if ssoService != nil {
+			ssoHandler := handlers.NewSSOHandler(ssoService)
+			api.GET("/auth/login", ssoHandler.Login)
+			api.GET("/auth/callback", ssoHandler.Callback)
+			api.GET("/auth/session", ssoHandler.GetSession)
+			api.POST("/auth/logout", ssoHandler.Logout)
+		}
+
+		// Analysis sandbox endpoints
+		api.POST("/sandboxes", sandboxHandler.CreateSandbox)
+		api.GET("/sandboxes/current", sandboxHandler.GetSandbox)
+		api.PUT("/sandboxes/current", sandboxHandler.UpdateSandbox)
+		api.DELETE("/sandboxes/current", sandboxHandler.DeleteSandbox)
+
+		// Upload endpoints
+		api.POST("/uploads", backpressure.RejectUploads(), uploadHandler.UploadFile)
+		api.GET("/uploads", uploadHandler.GetUploads)
+		api.GET("/uploads/:id", uploadHandler.GetUpload)
+		api.POST("/uploads/:id/process", uploadHandler.ProcessUpload)
+		api.GET("/uploads/:id/status", uploadHandler.GetProcessingStatus)
+		api.GET("/uploads/:id/file", uploadHandler.GetUploadFile)
+		api.GET("/uploads/:id/profile", uploadHandler.GetUploadProfile)
+		api.GET("/uploads/:id/quality", uploadHandler.GetUploadQuality)
+		api.GET("/uploads/:id/continuity", uploadHandler.GetUploadContinuity)
+		api.POST("/uploads/:id/diff/:otherId", uploadHandler.DiffUploads)
+		if version != handlers.APIVersion1 {
+			api.GET("/uploads/:id/events", uploadHandler.GetUploadEvents)
+			api.PUT("/uploads/:id/file", backpressure.RejectUploads(), uploadHandler.ReplaceUploadFile)
+			api.GET("/uploads/:id/stats", uploadHandler.GetUploadStats)
+		}
+
+		// Data-quality alerts raised by upload checks
+		api.GET("/data-quality/alerts", dataQualityHandler.ListAlerts)
+
+		// Archive endpoints
+		api.GET("/archive", archiveHandler.ListArchive)
+		api.POST("/archive", archiveHandler.ArchiveIncidents)
+
+		// Dataset (upload group) endpoints
+		api.GET("/datasets", uploadHandler.ListDatasets)
+		api.POST("/datasets", uploadHandler.CreateDataset)
+		api.GET("/datasets/:id", uploadHandler.GetDataset)
+		api.POST("/datasets/:id/uploads", backpressure.RejectUploads(), uploadHandler.UploadDatasetFiles)
+		api.POST("/datasets/:id/process", uploadHandler.ProcessDataset)
+
+		// Google Sheets source endpoints
+		api.GET("/sheet-sources", uploadHandler.ListSheetSources)
+		api.POST("/sheet-sources", uploadHandler.CreateSheetSource)
+		api.GET("/sheet-sources/:id", uploadHandler.GetSheetSource)
+		api.DELETE("/sheet-sources/:id", uploadHandler.DeleteSheetSource)
+		api.POST("/sheet-sources/:id/import", backpressure.RejectUploads(), uploadHandler.ImportSheetSource)
+
+		// Column mapping profile endpoints
+		api.GET("/mapping-profiles", mappingProfileHandler.ListProfiles)
+		api.POST("/mapping-profiles", mappingProfileHandler.SaveProfile)
+		api.GET("/mapping-profiles/:name", mappingProfileHandler.GetProfile)
+		api.DELETE("/mapping-profiles/:name", mappingProfileHandler.DeleteProfile)
+
+		// Validation rule set endpoints
+		api.GET("/validation-rule-sets", ruleSetHandler.ListRuleSets)
+		api.POST("/validation-rule-sets", ruleSetHandler.SaveRuleSet)
+		api.GET("/validation-rule-sets/:name", ruleSetHandler.GetRuleSet)
+		api.DELETE("/validation-rule-sets/:name", ruleSetHandler.DeleteRuleSet)
+
+		// Application name normalization endpoints
+		api.GET("/applications/aliases", applicationHandler.ListAliases)
+		api.POST("/applications/aliases", applicationHandler.CreateAlias)
+		api.DELETE("/applications/aliases/:alias", applicationHandler.DeleteAlias)
+		api.POST("/applications/merge", applicationHandler.MergeApplications)
+
+		// Org hierarchy endpoints
+		api.GET("/org/groups", orgHandler.ListGroups)
+		api.PUT("/org/groups/:group", orgHandler.SaveGroup)
+		api.DELETE("/org/groups/:group", orgHandler.DeleteGroup)
+
+		// Service catalog endpoints
+		if version != handlers.APIVersion1 {
+			api.GET("/service-catalog", serviceCatalogHandler.ListServices)
+			api.PUT("/service-catalog/:name", serviceCatalogHandler.SaveService)
+			api.DELETE("/service-catalog/:name", serviceCatalogHandler.DeleteService)
+		}
+
+		// CMDB enrichment endpoints
+		if version != handlers.APIVersion1 {
+			api.GET("/cmdb/items", cmdbHandler.ListItems)
+			api.POST("/cmdb/import", cmdbHandler.ImportCSV)
+			api.POST("/cmdb/sync", cmdbHandler.SyncServiceNow)
+		}
+
+		// Cost center registry endpoints
+		api.GET("/cost-centers", costCenterHandler.ListAssignments)
+		api.POST("/cost-centers", costCenterHandler.SaveAssignment)
+		api.DELETE("/cost-centers/:type/:name", costCenterHandler.DeleteAssignment)
+
+		// Maintenance window calendar endpoints
+		api.GET("/maintenance-windows", maintenanceHandler.ListWindows)
+		api.POST("/maintenance-windows", maintenanceHandler.CreateWindow)
+		api.GET("/maintenance-windows/:id", maintenanceHandler.GetWindow)
+		api.PUT("/maintenance-windows/:id", maintenanceHandler.UpdateWindow)
+		api.DELETE("/maintenance-windows/:id", maintenanceHandler.DeleteWindow)
+
+		// Watchlist endpoints of the calling user
+		if version != handlers.APIVersion1 {
+			api.GET("/watchlists", watchlistHandler.ListWatchlists)
+			api.POST("/watchlists", watchlistHandler.CreateWatchlist)
+			api.GET("/watchlists/:id", watchlistHandler.GetWatchlist)
+			api.PUT("/watchlists/:id", watchlistHandler.UpdateWatchlist)
+			api.DELETE("/watchlists/:id", watchlistHandler.DeleteWatchlist)
+			api.GET("/watchlists/:id/alerts", watchlistHandler.ListAlerts)
+			api.POST("/watchlists/:id/evaluate", watchlistHandler.EvaluateWatchlist)
+		}
+
+		// Notification preferences of the calling user
+		if version != handlers.APIVersion1 {
+			api.GET("/me/notifications", notificationHandler.GetPreferences)
+			api.PUT("/me/notifications", notificationHandler.UpdatePreferences)
+		}
+
+		// Quarterly KPI goal endpoints
+		if version != handlers.APIVersion1 {
+			api.GET("/goals", goalHandler.ListGoals)
+			api.POST("/goals", goalHandler.CreateGoal)
+			api.GET("/goals/:id", goalHandler.GetGoal)
+			api.DELETE("/goals/:id", goalHandler.DeleteGoal)
+		}
+
+		// Holiday calendar endpoints
+		api.GET("/holidays", holidayHandler.ListHolidays)
+		api.POST("/holidays", holidayHandler.SaveHolidays)
+		api.DELETE("/holidays/:region/:date", holidayHandler.DeleteHoliday)
+
+		// Automation keyword endpoints
+		api.GET("/automation/keywords", automationHandler.ListKeywords)
+		api.POST("/automation/keywords", automationHandler.SaveKeyword)
+		api.POST("/automation/keywords/preview", automationHandler.PreviewKeyword)
+		api.DELETE("/automation/keywords/:kind/:keyword", automationHandler.DeleteKeyword)
+		api.GET("/automation/runbooks", automationHandler.ListRunbooks)
+		api.POST("/automation/runbooks", automationHandler.SaveRunbook)
+		api.GET("/automation/runbooks/:name", automationHandler.GetRunbook)
+		api.DELETE("/automation/runbooks/:name", automationHandler.DeleteRunbook)
+		if version != handlers.APIVersion1 {
+			api.GET("/automation/opportunities", automationHandler.ListOpportunities)
+			api.GET("/automation/opportunities/:id", automationHandler.GetOpportunity)
+			api.PUT("/automation/opportunities/:id", automationHandler.UpdateOpportunity)
+		}
+
+		// Shadow analyzer configuration endpoints
+		api.GET("/shadow-configs", shadowHandler.ListConfigs)
+		api.POST("/shadow-configs", shadowHandler.CreateConfig)
+		api.DELETE("/shadow-configs/:id", shadowHandler.DeleteConfig)
+		api.POST("/shadow-configs/:id/activate", shadowHandler.ActivateConfig)
+		api.POST("/shadow-configs/:id/deactivate", shadowHandler.DeactivateConfig)
+		api.GET("/shadow-configs/:id/comparison", shadowHandler.GetComparison)
+
+		// Incident endpoints
+		api.POST("/incidents", incidentHandler.CreateIncident)
+		api.POST("/incidents/batch", backpressure.RejectUploads(), uploadHandler.PushIncidents)
+		api.GET("/incidents/export", exportHandler.RequestIncidentExport)
+		api.GET("/incidents/sample", incidentHandler.GetSample)
+		api.GET("/incidents/:id/timeline", incidentHandler.GetTimeline)
+		api.GET("/incidents/:id/related", incidentHandler.GetRelatedIncidents)
+		if version != handlers.APIVersion1 {
+			api.GET("/incidents/:id/merges", incidentHandler.ListIncidentMerges)
+			api.POST("/incident-merges", incidentHandler.MergeIncidents)
+			api.GET("/incident-merges/:id", incidentHandler.GetMerge)
+			api.POST("/incident-merges/:id/undo", incidentHandler.UndoMerge)
+		}
+
+		// Export job endpoints
+		api.GET("/exports/:id", exportHandler.GetExport)
+		api.GET("/exports/:id/download", exportHandler.DownloadExport)
+
+		// Analyzer feedback endpoints
+		api.GET("/incidents/:id/feedback", feedbackHandler.ListFeedback)
+		api.POST("/incidents/:id/feedback", feedbackHandler.RecordFeedback)
+
+		// Report endpoints
+		api.GET("/reports/ops-review", reportHandler.GetOpsReview)
+		if version != handlers.APIVersion1 {
+			api.GET("/reports/handover", reportHandler.GetHandover)
+			api.GET("/reports/snapshots", reportSnapshotHandler.ListSnapshots)
+			api.POST("/reports/snapshots", reportSnapshotHandler.CreateSnapshot)
+			api.DELETE("/reports/snapshots/:id", reportSnapshotHandler.RevokeSnapshot)
+
+			// Monitoring metrics recorded over time
+			api.GET("/monitoring/history", metricsHistoryHandler.GetHistory)
+
+			// Data dictionary of the incident model
+			api.GET("/meta/schema", metaHandler.GetSchema)
+
+			// Share links of report snapshots, served without sign-in
+			api.GET("/shared/:token", reportSnapshotHandler.GetSharedSnapshot)
+		}
+
+		// Admin endpoints, for holders of ADMIN_TOKEN only
+		admin := api.Group("/admin", handlers.AdminAuth(adminToken))
+		{
+			admin.GET("/usage", usageHandler.GetUsage)
+			admin.GET("/config", configHandler.GetConfig)
+			admin.PUT("/config", configHandler.UpdateConfig)
+			admin.GET("/config/audit", configHandler.GetConfigAudit)
+			admin.GET("/flags", flagHandler.ListFlags)
+			admin.PUT("/flags/:key", flagHandler.UpdateFlag)
+			admin.PUT("/flags/:key/tenants/:tenant", flagHandler.SetOverride)
+			admin.DELETE("/flags/:key/tenants/:tenant", flagHandler.DeleteOverride)
+			admin.GET("/scopes", scopeHandler.ListScopes)
+			admin.GET("/scopes/:user", scopeHandler.GetScope)
+			admin.PUT("/scopes/:user", scopeHandler.SetScope)
+			admin.DELETE("/scopes/:user", scopeHandler.DeleteScope)
+			if version != handlers.APIVersion1 {
+				admin.GET("/views", analyticsViewHandler.CheckViews)
+				admin.POST("/views/rebuild", analyticsViewHandler.RebuildViews)
+				admin.GET("/cache", analyticsHandler.GetCacheStats)
+				admin.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+				admin.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+				admin.DELETE("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+				admin.GET("/errors/aggregate", handlers.GetErrorAggregate)
+				admin.GET("/indexes", indexAdvisorHandler.GetAdvice)
+				admin.POST("/indexes/migration", indexAdvisorHandler.CreateMigration)
+				admin.POST("/recategorize", recategorizeHandler.StartRecategorization)
+				admin.GET("/recategorize/:id", recategorizeHandler.GetRecategorization)
+				admin.POST("/rebuild", rebuildHandler.StartRebuild)
+				admin.GET("/rebuild/:id", rebuildHandler.GetRebuild)
+			}
+		}
+
+		// Analytics endpoints
+		analytics := api.Group("/analytics", analyticsHandler.DataLineage())
+		{
+			// Timeline endpoints
+			analytics.GET("/timeline/daily", analyticsHandler.GetDailyTimeline)
+			analytics.GET("/timeline/weekly", analyticsHandler.GetWeeklyTimeline)
+			if version != handlers.APIVersion1 {
+				analytics.GET("/timeline/hourly", analyticsHandler.GetHourlyTimeline)
+			}
+			analytics.GET("/timeline/overview", analyticsHandler.GetTimelineOverview)
+
+			// Trend analysis endpoints
+			analytics.GET("/trends", analyticsHandler.GetTrendAnalysis)
+			if version != handlers.APIVersion1 {
+				analytics.GET("/explain", analyticsHandler.ExplainChange)
+			}
+
+			// Metrics endpoints
+			analytics.GET("/metrics/daily", analyticsHandler.GetTicketsPerDayMetrics)
+			analytics.GET("/metrics/weekly", analyticsHandler.GetTicketsPerWeekMetrics)
+
+			// Priority and Application Analysis endpoints
+			analytics.GET("/priority", analyticsHandler.GetPriorityAnalysis)
+			analytics.GET("/applications", analyticsHandler.GetApplicationAnalysis)
+			analytics.GET("/groups", analyticsHandler.GetGroupAnalysis)
+			if version != handlers.APIVersion1 {
+				analytics.GET("/applications/:name", analyticsHandler.GetApplicationProfile)
+				analytics.GET("/services", analyticsHandler.GetServiceAnalysis)
+				analytics.GET("/regions", analyticsHandler.GetRegionAnalysis)
+			}
+			analytics.GET("/benchmark", analyticsHandler.GetBenchmark)
+			analytics.GET("/chargeback", costCenterHandler.GetChargebackReport)
+			analytics.GET("/cost", costCenterHandler.GetCostSummary)
+			analytics.GET("/capacity", analyticsHandler.GetCapacityPlan)
+			analytics.GET("/resolution", analyticsHandler.GetResolutionAnalysis)
+			analytics.GET("/resolution/outliers", analyticsHandler.GetResolutionOutliers)
+			analytics.GET("/performance", analyticsHandler.GetPerformanceMetrics)
+			analytics.GET("/notes-quality", analyticsHandler.GetNotesQuality)
+			if version != handlers.APIVersion1 {
+				analytics.GET("/text-profile", analyticsHandler.GetTextProfile)
+			}
+
+			// Sentiment and Automation Analysis endpoints
+			analytics.GET("/sentiment", analyticsHandler.GetSentimentAnalysis)
+			analytics.GET("/sentiment/timeline", analyticsHandler.GetSentimentTimeline)
+			analytics.GET("/sentiment/correlation", analyticsHandler.GetSentimentCorrelation)
+			analytics.GET("/automation", analyticsHandler.GetAutomationAnalysis)
+			analytics.GET("/automation/reporting", analyticsHandler.GetITProcessAutomationReporting)
+			if version != handlers.APIVersion1 {
+				analytics.GET("/automation/distribution", analyticsHandler.GetAutomationDistribution)
+				analytics.GET("/automation/calibration", analyticsHandler.GetAutomationCalibration)
+				analytics.GET("/noise", analyticsHandler.GetNoiseAnalysis)
+			}
+			analytics.POST("/automation/scenario", analyticsHandler.GetAutomationScenario)
+			analytics.GET("/automation/candidates/:id", automationHandler.GetCandidate)
+			analytics.POST("/automation/candidates/:id/ticket", handlers.RequireFeature(flags, services.FlagJiraTickets), automationHandler.CreateTicket)
+			if version != handlers.APIVersion1 {
+				analytics.GET("/automation/pipeline", automationHandler.GetPipeline)
+			}
+			analytics.GET("/feedback/accuracy", feedbackHandler.GetAccuracyReport)
+			if version != handlers.APIVersion1 {
+				analytics.GET("/goals", goalHandler.ListGoalProgress)
+				analytics.GET("/goals/:id", goalHandler.GetGoalProgress)
+			}
+			analytics.GET("/summary", analyticsHandler.GetAnalyticsSummary)
+			if version != handlers.APIVersion1 {
+				analytics.GET("/my-summary", analyticsHandler.GetMySummary)
+			}
+		}
+	}
+
+	registerAPI(handlers.APIVersionPath(handlers.APIVersion1), handlers.APIVersion1)
+	registerAPI(handlers.APIVersionPath(handlers.APIVersion2), handlers.APIVersion2)
+
+	// The unversioned routes serve v1 for clients written before versioning, pointing them to
+	// their /api/v1 successors
+	legacySunset := envDate("API_LEGACY_SUNSET")
+	registerAPI(handlers.APIBasePath, handlers.APIVersion1, handlers.Deprecated(handlers.Deprecation{
+		Since:     legacyAPIDeprecated,
+		Sunset:    legacySunset,
+		Successor: handlers.SuccessorPrefix(handlers.APIBasePath, handlers.APIVersionPath(handlers.APIVersion1)),
+	}))
+
+	versions := []handlers.APIVersionInfo{
+		{Version: handlers.APIVersion1, Path: handlers.APIVersionPath(handlers.APIVersion1), Status: "supported"},
+		{Version: handlers.APIVersion2, Path: handlers.APIVersionPath(handlers.APIVersion2), Status: "current"},
+		{Version: "unversioned", Path: handlers.APIBasePath, Status: "deprecated"},
+	}
+	if !legacySunset.IsZero() {
+		versions[2].Sunset = &legacySunset
+	}
+	r.GET(handlers.APIBasePath+"/versions", handlers.ListAPIVersions(versions))
+
+	s.Router = r
+	return s, nil
+}
+
+// Close stops background work, waiting for running jobs and pending usage events, and closes the
+// database
+func (s *Server) Close() {
+	s.cancel()
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		s.closers[i]()
+	}
+	s.closers = nil
+}
+
+// metricsSample reads the in-memory monitoring metrics for the metrics history
+func metricsSample() services.MetricsSample {
+	health := monitoring.GetHealthStatus()
+	sample := services.MetricsSample{Status: health.Status}
+	if metrics := health.ErrorMetrics; metrics != nil {
+		sample.TotalErrors = metrics.TotalErrors
+		sample.LastHourErrors = metrics.LastHourErrors
+		sample.ErrorRate = metrics.ErrorRate
+		sample.CriticalErrors = metrics.ErrorsBySeverity["critical"]
+	}
+	if performance := health.Performance; performance != nil {
+		sample.RequestCount = performance.RequestCount
+		sample.AvgResponseMs = float64(performance.AvgResponseTime.Microseconds()) / 1000
+		sample.SlowRequests = performance.SlowRequests
+		sample.MemoryBytes = int64(performance.MemoryUsage)
+		sample.Goroutines = performance.GoroutineCount
+	}
+	return sample
+}
+
+// envFloat reads a number from the environment, falling back when it is unset or invalid
+func envFloat(name string, fallback float64) float64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Ignoring invalid %s=%q: %v", name, value, err)
+		return fallback
+	}
+	return parsed
+}
+
+// envDate reads a YYYY-MM-DD date from the environment, returning the zero time when it is unset
+// or invalid
+func envDate(name string) time.Time {
+	value := os.Getenv(name)
+	if value == "" {
+		return time.Time{}
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		log.Printf("Ignoring invalid %s=%q: %v", name, value, err)
+		return time.Time{}
+	}
+	return parsed
+}
+
+// envString reads a string from the environment, falling back when it is unset
+func envString(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -10,7 +10,9 @@ import (
 
 // AnalyticsService provides analytics and reporting functionality
 type AnalyticsService struct {
-	db *sql.DB
+	db      *sql.DB
+	archive *ArchiveStore
+	flags   *FeatureFlagService
 }
 
 // NewAnalyticsService creates a new analytics service
@@ -20,16 +22,16 @@ func NewAnalyticsService(db *sql.DB) *AnalyticsService {
 	}
 }
 
-// buildFilterConditions builds WHERE conditions and arguments for filters
-func buildFilterConditions(filters *TimelineFilters, startArgIndex int) (string, []interface{}, int) {
+// buildFilterConditions builds WHERE conditions and arguments for filters. The data scope of the
+// user making the request is always applied, even without filters, and incidents merged into
+// another are never counted.
+func buildFilterConditions(ctx context.Context, filters *TimelineFilters, startArgIndex int) (string, []interface{}, int) {
+	conditions, args, argIndex := scopeConditions(ctx, startArgIndex)
+	conditions = append(conditions, canonicalIncident)
 	if filters == nil {
-		return "", []interface{}{}, startArgIndex
+		return " AND " + strings.Join(conditions, " AND "), args, argIndex
 	}
 
-	var conditions []string
-	var args []interface{}
-	argIndex := startArgIndex
-
 	if filters.StartDate != nil {
 		conditions = append(conditions, fmt.Sprintf("report_date >= $%d", argIndex))
 		args = append(args, *filters.StartDate)
@@ -67,6 +69,45 @@ func buildFilterConditions(filters *TimelineFilters, startArgIndex int) (string,
 		}
 		conditions = append(conditions, fmt.Sprintf("status IN (%s)", strings.Join(placeholders, ",")))
 	}
+	if len(filters.Regions) > 0 {
+		placeholders := make([]string, len(filters.Regions))
+		for i, region := range filters.Regions {
+			placeholders[i] = fmt.Sprintf("$%d", argIndex)
+			args = append(args, region)
+			argIndex++
+		}
+		conditions = append(conditions, fmt.Sprintf("region IN (%s)", strings.Join(placeholders, ",")))
+	}
+	for _, attribute := range []struct {
+		column string
+		values []string
+	}{
+		{"cmdb_owner", filters.Owners},
+		{"cmdb_environment", filters.Environments},
+		{"cmdb_criticality", filters.Criticalities},
+	} {
+		if len(attribute.values) == 0 {
+			continue
+		}
+		placeholders := make([]string, len(attribute.values))
+		for i, value := range attribute.values {
+			placeholders[i] = fmt.Sprintf("$%d", argIndex)
+			args = append(args, value)
+			argIndex++
+		}
+		conditions = append(conditions, fmt.Sprintf("%s IN (%s)", attribute.column, strings.Join(placeholders, ",")))
+	}
+	if filters.DatasetID != "" {
+		conditions = append(conditions, fmt.Sprintf("dataset_id = $%d", argIndex))
+		args = append(args, filters.DatasetID)
+		argIndex++
+	}
+	switch filters.Maintenance {
+	case MaintenanceExclude:
+		conditions = append(conditions, "NOT "+maintenanceWindowMatch)
+	case MaintenanceOnly:
+		conditions = append(conditions, maintenanceWindowMatch)
+	}
 
 	whereClause := ""
 	if len(conditions) > 0 {
@@ -84,6 +125,10 @@ type TimelineData struct {
 	P2Count      int    `json:"p2_count"`
 	P3Count      int    `json:"p3_count"`
 	P4Count      int    `json:"p4_count"`
+	// Holidays lists the holidays of the selected region in the period
+	Holidays []HolidayMarker `json:"holidays,omitempty"`
+	// Baseline is the normal range of the period when baseline weeks are requested
+	Baseline *BaselineBand `json:"baseline,omitempty"`
 }
 
 // TrendAnalysis represents trend analysis data
@@ -92,6 +137,9 @@ type TrendAnalysis struct {
 	IncidentCount int     `json:"incident_count"`
 	GrowthRate   float64 `json:"growth_rate"`
 	Trend        string  `json:"trend"` // "increasing", "decreasing", "stable"
+	// AdjustedCount is the count the growth rate was computed from when holidays are adjusted
+	AdjustedCount float64         `json:"adjusted_count,omitempty"`
+	Holidays      []HolidayMarker `json:"holidays,omitempty"`
 }
 
 // PriorityAnalysis represents priority distribution analysis
@@ -109,6 +157,8 @@ type ApplicationAnalysis struct {
 	MedianResolutionTime float64 `json:"median_resolution_time"`
 	ResolvedIncidents   int     `json:"resolved_incidents"`
 	Trend               string  `json:"trend"`
+	// Percentiles holds the requested resolution time percentiles, keyed like p95
+	Percentiles map[string]float64 `json:"percentiles,omitempty"`
 }
 
 // ResolutionMetrics represents resolution analysis metrics
@@ -118,6 +168,11 @@ type ResolutionMetrics struct {
 	TotalIncidents       int     `json:"total_incidents"`
 	ResolvedIncidents    int     `json:"resolved_incidents"`
 	ResolutionRate       float64 `json:"resolution_rate"`
+	// Percentiles holds the requested resolution time percentiles, keyed like p95
+	Percentiles map[string]float64 `json:"percentiles,omitempty"`
+	// ExcludedOutliers and OutlierBounds are set when outliers were excluded
+	ExcludedOutliers int            `json:"excluded_outliers,omitempty"`
+	OutlierBounds    *OutlierBounds `json:"outlier_bounds,omitempty"`
 }
 
 // SentimentAnalysis represents sentiment analysis aggregation
@@ -156,91 +211,195 @@ type TimelineFilters struct {
 	Priorities   []string   `json:"priorities,omitempty"`
 	Applications []string   `json:"applications,omitempty"`
 	Statuses     []string   `json:"statuses,omitempty"`
+	Regions      []string   `json:"regions,omitempty"`
+	DatasetID    string     `json:"dataset_id,omitempty"`
+	Maintenance  string     `json:"maintenance,omitempty"` // Exclude, or keep only, incidents reported during maintenance windows
+	// HolidayRegion selects the holiday calendar timelines are annotated with, and Holidays how
+	// trends and forecasts treat its holidays
+	HolidayRegion string `json:"holiday_region,omitempty"`
+	Holidays      string `json:"holidays,omitempty"`
+	// Owners, Environments and Criticalities keep the incidents whose CMDB attributes match
+	Owners        []string `json:"owners,omitempty"`
+	Environments  []string `json:"environments,omitempty"`
+	Criticalities []string `json:"criticalities,omitempty"`
+	// Limit caps ranked lists such as the top applications; 0 keeps each list's default
+	Limit int `json:"limit,omitempty"`
+	// Percentiles lists the resolution time percentiles, from 0 to 100, reported alongside the median
+	Percentiles []float64 `json:"percentiles,omitempty"`
+	// BaselineWeeks adds to each timeline period the baseline band of the same period of that
+	// many weeks before; 0 adds none
+	BaselineWeeks int `json:"baseline_weeks,omitempty"`
 }
 
-// GetDailyTimeline returns daily incident timeline data with optional filters
-func (s *AnalyticsService) GetDailyTimeline(ctx context.Context, filters *TimelineFilters) ([]TimelineData, error) {
-	query := `
-		SELECT 
-			DATE_TRUNC('day', report_date) as date,
-			COUNT(*) as incident_count,
-			COUNT(CASE WHEN priority = 'P1' THEN 1 END) as p1_count,
-			COUNT(CASE WHEN priority = 'P2' THEN 1 END) as p2_count,
-			COUNT(CASE WHEN priority = 'P3' THEN 1 END) as p3_count,
-			COUNT(CASE WHEN priority = 'P4' THEN 1 END) as p4_count
-		FROM incidents 
-		WHERE 1=1`
+// MaxHourlyTimelineDays is the longest date range, in days, the hourly timeline may cover
+const MaxHourlyTimelineDays = 14
 
-	// Apply filters
-	whereClause, args, _ := buildFilterConditions(filters, 1)
-	query += whereClause
-	query += " GROUP BY DATE_TRUNC('day', report_date) ORDER BY date"
+// DefaultTopN is how many entries ranked lists return when no limit is given, and MaxTopN the
+// most a limit may ask for
+const (
+	DefaultTopN = 5
+	MaxTopN     = 100
+)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query daily timeline: %w", err)
+// topN returns the limit for a ranked list, or fallback when none is set
+func (f *TimelineFilters) topN(fallback int) int {
+	if f == nil || f.Limit <= 0 {
+		return fallback
 	}
-	defer rows.Close()
+	if f.Limit > MaxTopN {
+		return MaxTopN
+	}
+	return f.Limit
+}
 
-	var timeline []TimelineData
-	for rows.Next() {
-		var data TimelineData
-		var date time.Time
-		
-		err := rows.Scan(
-			&date,
-			&data.IncidentCount,
-			&data.P1Count,
-			&data.P2Count,
-			&data.P3Count,
-			&data.P4Count,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan timeline row: %w", err)
-		}
-		
-		data.Date = date.Format("2006-01-02")
-		timeline = append(timeline, data)
+// limitClause returns the LIMIT clause for a ranked query, or nothing when no limit is set
+func (f *TimelineFilters) limitClause() string {
+	if f == nil || f.Limit <= 0 {
+		return ""
 	}
+	return fmt.Sprintf(" LIMIT %d", f.topN(0))
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating timeline rows: %w", err)
+// percentiles returns the requested resolution time percentiles, at most MaxPercentiles of them
+func (f *TimelineFilters) percentiles() []float64 {
+	if f == nil {
+		return nil
+	}
+	if len(f.Percentiles) > MaxPercentiles {
+		return f.Percentiles[:MaxPercentiles]
+	}
+	return f.Percentiles
+}
+
+// unlimited returns the filters without a limit, for aggregates that need every row of a
+// ranked list
+func (f *TimelineFilters) unlimited() *TimelineFilters {
+	if f == nil || f.Limit == 0 {
+		return f
 	}
+	copied := *f
+	copied.Limit = 0
+	return &copied
+}
 
+// topEntries returns the first n entries of a ranked list
+func topEntries[T any](entries []T, n int) []T {
+	if len(entries) < n {
+		n = len(entries)
+	}
+	return entries[:n]
+}
+
+// GetDailyTimeline returns daily incident timeline data with optional filters
+func (s *AnalyticsService) GetDailyTimeline(ctx context.Context, filters *TimelineFilters) ([]TimelineData, error) {
+	var timeline []TimelineData
+	err := s.StreamDailyTimeline(ctx, filters, func(data TimelineData) error {
+		timeline = append(timeline, data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return timeline, nil
 }
 
 // GetWeeklyTimeline returns weekly incident timeline data with optional filters
 func (s *AnalyticsService) GetWeeklyTimeline(ctx context.Context, filters *TimelineFilters) ([]TimelineData, error) {
+	var timeline []TimelineData
+	err := s.StreamWeeklyTimeline(ctx, filters, func(data TimelineData) error {
+		timeline = append(timeline, data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return timeline, nil
+}
+
+// GetHourlyTimeline returns the incidents per hour of the incidents whose report time of day is
+// known; incidents reported with a date alone are left out
+func (s *AnalyticsService) GetHourlyTimeline(ctx context.Context, filters *TimelineFilters) ([]TimelineData, error) {
+	var timeline []TimelineData
+	err := s.StreamHourlyTimeline(ctx, filters, func(data TimelineData) error {
+		timeline = append(timeline, data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return timeline, nil
+}
+
+// StreamDailyTimeline passes the days of the daily timeline to fn in date order as they are
+// read, so long ranges are never held in memory. An error from fn stops the stream and is
+// returned.
+func (s *AnalyticsService) StreamDailyTimeline(ctx context.Context, filters *TimelineFilters, fn func(TimelineData) error) error {
+	return s.streamTimeline(ctx, filters, "daily", "day", 1, fn)
+}
+
+// StreamWeeklyTimeline passes the weeks of the weekly timeline to fn like StreamDailyTimeline
+func (s *AnalyticsService) StreamWeeklyTimeline(ctx context.Context, filters *TimelineFilters, fn func(TimelineData) error) error {
+	return s.streamTimeline(ctx, filters, "weekly", "week", 7, fn)
+}
+
+// StreamHourlyTimeline passes the hours of the hourly timeline to fn like StreamDailyTimeline.
+// Each hour is marked with the holidays of its day.
+func (s *AnalyticsService) StreamHourlyTimeline(ctx context.Context, filters *TimelineFilters, fn func(TimelineData) error) error {
+	return s.streamTimeline(ctx, filters, "hourly", "hour", 1, fn)
+}
+
+// streamTimeline counts incidents per unit, 'hour', 'day' or 'week', of days days and passes each
+// period to fn. name labels errors. Hours are read from the report time, so incidents without
+// one are left out. With baseline weeks, the counts of the prior weeks are read first and each
+// period carries its baseline band.
+func (s *AnalyticsService) streamTimeline(ctx context.Context, filters *TimelineFilters, name, unit string, days int, fn func(TimelineData) error) error {
+	column, layout := "report_date", "2006-01-02"
+	if unit == "hour" {
+		column, layout = "reported_at", time.RFC3339
+	}
 	query := `
-		SELECT 
-			DATE_TRUNC('week', report_date) as week,
+		SELECT
+			DATE_TRUNC('` + unit + `', ` + column + `) as period,
 			COUNT(*) as incident_count,
 			COUNT(CASE WHEN priority = 'P1' THEN 1 END) as p1_count,
 			COUNT(CASE WHEN priority = 'P2' THEN 1 END) as p2_count,
 			COUNT(CASE WHEN priority = 'P3' THEN 1 END) as p3_count,
 			COUNT(CASE WHEN priority = 'P4' THEN 1 END) as p4_count
-		FROM incidents 
-		WHERE 1=1`
+		FROM incidents
+		WHERE ` + column + ` IS NOT NULL`
 
 	// Apply filters
-	whereClause, args, _ := buildFilterConditions(filters, 1)
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
 	query += whereClause
-	query += " GROUP BY DATE_TRUNC('week', report_date) ORDER BY week"
+	query += " GROUP BY DATE_TRUNC('" + unit + "', " + column + ") ORDER BY period"
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query weekly timeline: %w", err)
+		return fmt.Errorf("failed to query %s timeline: %w", name, err)
 	}
 	defer rows.Close()
 
-	var timeline []TimelineData
+	calendar, err := s.holidayCalendar(ctx, filters)
+	if err != nil {
+		return err
+	}
+
+	var history map[int64]int
+	var firstPeriod time.Time
+	weeks := 0
+	if filters != nil && filters.BaselineWeeks > 0 {
+		weeks = min(filters.BaselineWeeks, MaxBaselineWeeks)
+		if history, firstPeriod, err = s.timelineHistory(ctx, filters, column, unit, weeks); err != nil {
+			return err
+		}
+	}
+
 	for rows.Next() {
 		var data TimelineData
-		var week time.Time
-		
+		var period time.Time
+
 		err := rows.Scan(
-			&week,
+			&period,
 			&data.IncidentCount,
 			&data.P1Count,
 			&data.P2Count,
@@ -248,18 +407,23 @@ func (s *AnalyticsService) GetWeeklyTimeline(ctx context.Context, filters *Timel
 			&data.P4Count,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan weekly timeline row: %w", err)
+			return fmt.Errorf("failed to scan %s timeline row: %w", name, err)
+		}
+
+		data.Date = period.Format(layout)
+		data.Holidays = holidayMarkers(calendar, period, days)
+		if weeks > 0 {
+			data.Baseline = baselineBand(history, firstPeriod, period, weeks)
+		}
+		if err := fn(data); err != nil {
+			return err
 		}
-		
-		data.Date = week.Format("2006-01-02")
-		timeline = append(timeline, data)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating weekly timeline rows: %w", err)
+		return fmt.Errorf("error iterating %s timeline rows: %w", name, err)
 	}
-
-	return timeline, nil
+	return nil
 }
 
 // GetTrendAnalysis calculates trend analysis for incident data
@@ -281,18 +445,36 @@ func (s *AnalyticsService) GetTrendAnalysis(ctx context.Context, period string,
 		return nil, fmt.Errorf("failed to get timeline data for trend analysis: %w", err)
 	}
 
-	if len(timelineData) < 2 {
+	// Holidays are left out of the series, or weekly counts scaled to a full working week
+	mode := ""
+	if filters != nil {
+		mode = filters.Holidays
+	}
+	type trendPoint struct {
+		data  TimelineData
+		value float64
+	}
+	var points []trendPoint
+	for _, data := range timelineData {
+		scale := holidayScale(mode, data.Holidays, period == "weekly")
+		if scale == 0 {
+			continue
+		}
+		points = append(points, trendPoint{data: data, value: float64(data.IncidentCount) * scale})
+	}
+
+	if len(points) < 2 {
 		return []TrendAnalysis{}, nil
 	}
 
 	var trends []TrendAnalysis
-	for i := 1; i < len(timelineData); i++ {
-		current := timelineData[i]
-		previous := timelineData[i-1]
+	for i := 1; i < len(points); i++ {
+		current := points[i]
+		previous := points[i-1]
 		
 		var growthRate float64
-		if previous.IncidentCount > 0 {
-			growthRate = float64(current.IncidentCount-previous.IncidentCount) / float64(previous.IncidentCount) * 100
+		if previous.value > 0 {
+			growthRate = (current.value - previous.value) / previous.value * 100
 		}
 
 		trend := "stable"
@@ -302,26 +484,31 @@ func (s *AnalyticsService) GetTrendAnalysis(ctx context.Context, period string,
 			trend = "decreasing"
 		}
 
-		trends = append(trends, TrendAnalysis{
-			Period:        current.Date,
-			IncidentCount: current.IncidentCount,
+		analysis := TrendAnalysis{
+			Period:        current.data.Date,
+			IncidentCount: current.data.IncidentCount,
 			GrowthRate:    growthRate,
 			Trend:         trend,
-		})
+			Holidays:      current.data.Holidays,
+		}
+		if current.value != float64(current.data.IncidentCount) {
+			analysis.AdjustedCount = roundTo(current.value, 2)
+		}
+		trends = append(trends, analysis)
 	}
 
 	return trends, nil
 }
 
-// GetTicketsPerDayMetrics returns metrics for tickets per day
+// GetTicketsPerDayMetrics returns metrics for tickets per day, all zero when no incidents match
 func (s *AnalyticsService) GetTicketsPerDayMetrics(ctx context.Context, filters *TimelineFilters) (map[string]interface{}, error) {
 	query := `
 		SELECT 
-			SUM(daily_count) as total_incidents,
-			AVG(daily_count) as avg_per_day,
-			MAX(daily_count) as max_per_day,
-			MIN(daily_count) as min_per_day,
-			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY daily_count) as median_per_day
+			COALESCE(SUM(daily_count), 0) as total_incidents,
+			COALESCE(AVG(daily_count), 0) as avg_per_day,
+			COALESCE(MAX(daily_count), 0) as max_per_day,
+			COALESCE(MIN(daily_count), 0) as min_per_day,
+			COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY daily_count), 0) as median_per_day
 		FROM (
 			SELECT 
 				DATE_TRUNC('day', report_date) as date,
@@ -330,14 +517,14 @@ func (s *AnalyticsService) GetTicketsPerDayMetrics(ctx context.Context, filters
 			WHERE 1=1`
 
 	// Apply filters
-	whereClause, args, _ := buildFilterConditions(filters, 1)
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
 	query += whereClause
 	query += " GROUP BY DATE_TRUNC('day', report_date)) daily_stats"
 
 	var totalIncidents int
 	var avgPerDay, maxPerDay, minPerDay, medianPerDay float64
 
-	err := s.db.QueryRowContext(ctx, query, args...).Scan(
+	err := s.db.QueryRowContext(ctx, s.federate(ctx, query, filters), args...).Scan(
 		&totalIncidents,
 		&avgPerDay,
 		&maxPerDay,
@@ -357,15 +544,15 @@ func (s *AnalyticsService) GetTicketsPerDayMetrics(ctx context.Context, filters
 	}, nil
 }
 
-// GetTicketsPerWeekMetrics returns metrics for tickets per week
+// GetTicketsPerWeekMetrics returns metrics for tickets per week, all zero when no incidents match
 func (s *AnalyticsService) GetTicketsPerWeekMetrics(ctx context.Context, filters *TimelineFilters) (map[string]interface{}, error) {
 	query := `
 		SELECT 
-			SUM(weekly_count) as total_incidents,
-			AVG(weekly_count) as avg_per_week,
-			MAX(weekly_count) as max_per_week,
-			MIN(weekly_count) as min_per_week,
-			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY weekly_count) as median_per_week
+			COALESCE(SUM(weekly_count), 0) as total_incidents,
+			COALESCE(AVG(weekly_count), 0) as avg_per_week,
+			COALESCE(MAX(weekly_count), 0) as max_per_week,
+			COALESCE(MIN(weekly_count), 0) as min_per_week,
+			COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY weekly_count), 0) as median_per_week
 		FROM (
 			SELECT 
 				DATE_TRUNC('week', report_date) as week,
@@ -374,14 +561,14 @@ func (s *AnalyticsService) GetTicketsPerWeekMetrics(ctx context.Context, filters
 			WHERE 1=1`
 
 	// Apply filters
-	whereClause, args, _ := buildFilterConditions(filters, 1)
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
 	query += whereClause
 	query += " GROUP BY DATE_TRUNC('week', report_date)) weekly_stats"
 
 	var totalIncidents int
 	var avgPerWeek, maxPerWeek, minPerWeek, medianPerWeek float64
 
-	err := s.db.QueryRowContext(ctx, query, args...).Scan(
+	err := s.db.QueryRowContext(ctx, s.federate(ctx, query, filters), args...).Scan(
 		&totalIncidents,
 		&avgPerWeek,
 		&maxPerWeek,
@@ -412,11 +599,11 @@ func (s *AnalyticsService) GetPriorityAnalysis(ctx context.Context, filters *Tim
 		WHERE 1=1`
 
 	// Apply filters
-	whereClause, args, _ := buildFilterConditions(filters, 1)
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
 	query += whereClause
 	query += " GROUP BY priority ORDER BY priority"
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query priority analysis: %w", err)
 	}
@@ -447,22 +634,24 @@ func (s *AnalyticsService) GetPriorityAnalysis(ctx context.Context, filters *Tim
 
 // GetApplicationAnalysis returns application-wise incident breakdown with optional filters
 func (s *AnalyticsService) GetApplicationAnalysis(ctx context.Context, filters *TimelineFilters) ([]ApplicationAnalysis, error) {
-	query := `
+	percentiles := filters.percentiles()
+	query := fmt.Sprintf(`
 		SELECT 
 			application_name,
 			COUNT(*) as incident_count,
-			AVG(resolution_time_hours) as avg_resolution_time,
-			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY resolution_time_hours) as median_resolution_time,
-			COUNT(CASE WHEN resolve_date IS NOT NULL THEN 1 END) as resolved_incidents
+			AVG(%[1]s) as avg_resolution_time,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY %[1]s) as median_resolution_time,
+			COUNT(CASE WHEN resolve_date IS NOT NULL THEN 1 END) as resolved_incidents%[2]s
 		FROM incidents 
-		WHERE 1=1`
+		WHERE 1=1`, resolutionHoursExpr, percentileColumns(resolutionHoursExpr, percentiles, ""))
 
 	// Apply filters
-	whereClause, args, _ := buildFilterConditions(filters, 1)
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
 	query += whereClause
-	query += " GROUP BY application_name ORDER BY incident_count DESC"
+	query += " GROUP BY application_name ORDER BY incident_count DESC, application_name"
+	query += filters.limitClause()
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query application analysis: %w", err)
 	}
@@ -472,14 +661,16 @@ func (s *AnalyticsService) GetApplicationAnalysis(ctx context.Context, filters *
 	for rows.Next() {
 		var data ApplicationAnalysis
 		var avgResolutionTime, medianResolutionTime sql.NullFloat64
+		values := newPercentileValues(percentiles)
 		
-		err := rows.Scan(
+		dest := []interface{}{
 			&data.ApplicationName,
 			&data.IncidentCount,
 			&avgResolutionTime,
 			&medianResolutionTime,
 			&data.ResolvedIncidents,
-		)
+		}
+		err := rows.Scan(append(dest, values.dest()...)...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan application analysis row: %w", err)
 		}
@@ -490,6 +681,7 @@ func (s *AnalyticsService) GetApplicationAnalysis(ctx context.Context, filters *
 		if medianResolutionTime.Valid {
 			data.MedianResolutionTime = medianResolutionTime.Float64
 		}
+		data.Percentiles = values.result()
 		
 		// Calculate trend (simplified - could be enhanced with historical data)
 		data.Trend = "stable"
@@ -511,28 +703,31 @@ func (s *AnalyticsService) GetApplicationAnalysis(ctx context.Context, filters *
 
 // GetResolutionAnalysis returns resolution analysis with average times and metrics
 func (s *AnalyticsService) GetResolutionAnalysis(ctx context.Context, filters *TimelineFilters) (*ResolutionMetrics, error) {
-	query := `
+	percentiles := filters.percentiles()
+	query := fmt.Sprintf(`
 		SELECT 
 			COUNT(*) as total_incidents,
 			COUNT(CASE WHEN resolve_date IS NOT NULL THEN 1 END) as resolved_incidents,
-			AVG(resolution_time_hours) as avg_resolution_time,
-			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY resolution_time_hours) as median_resolution_time
+			AVG(%[1]s) as avg_resolution_time,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY %[1]s) as median_resolution_time%[2]s
 		FROM incidents 
-		WHERE 1=1`
+		WHERE 1=1`, resolutionHoursExpr, percentileColumns(resolutionHoursExpr, percentiles, ""))
 
 	// Apply filters
-	whereClause, args, _ := buildFilterConditions(filters, 1)
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
 	query += whereClause
 
 	var metrics ResolutionMetrics
 	var avgResolutionTime, medianResolutionTime sql.NullFloat64
+	values := newPercentileValues(percentiles)
 
-	err := s.db.QueryRowContext(ctx, query, args...).Scan(
+	dest := []interface{}{
 		&metrics.TotalIncidents,
 		&metrics.ResolvedIncidents,
 		&avgResolutionTime,
 		&medianResolutionTime,
-	)
+	}
+	err := s.db.QueryRowContext(ctx, s.federate(ctx, query, filters), args...).Scan(append(dest, values.dest()...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query resolution analysis: %w", err)
 	}
@@ -543,6 +738,7 @@ func (s *AnalyticsService) GetResolutionAnalysis(ctx context.Context, filters *T
 	if medianResolutionTime.Valid {
 		metrics.MedianResolutionTime = medianResolutionTime.Float64
 	}
+	metrics.Percentiles = values.result()
 
 	// Calculate resolution rate
 	if metrics.TotalIncidents > 0 {
@@ -567,7 +763,7 @@ func (s *AnalyticsService) GetPerformanceMetrics(ctx context.Context, filters *T
 	}
 
 	// Get application analysis
-	applicationAnalysis, err := s.GetApplicationAnalysis(ctx, filters)
+	applicationAnalysis, err := s.GetApplicationAnalysis(ctx, filters.unlimited())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get application analysis: %w", err)
 	}
@@ -590,11 +786,7 @@ func (s *AnalyticsService) GetPerformanceMetrics(ctx context.Context, filters *T
 	// Calculate top applications by incident count
 	topApplications := make([]ApplicationAnalysis, 0)
 	if len(applicationAnalysis) > 0 {
-		limit := 5
-		if len(applicationAnalysis) < limit {
-			limit = len(applicationAnalysis)
-		}
-		topApplications = applicationAnalysis[:limit]
+		topApplications = topEntries(applicationAnalysis, filters.topN(DefaultTopN))
 	}
 
 	return map[string]interface{}{
@@ -624,11 +816,11 @@ func (s *AnalyticsService) GetSentimentAnalysis(ctx context.Context, filters *Ti
 		WHERE sentiment_label IS NOT NULL`
 
 	// Apply filters
-	whereClause, args, _ := buildFilterConditions(filters, 1)
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
 	query += whereClause
-	query += " GROUP BY sentiment_label ORDER BY count DESC"
+	query += " GROUP BY sentiment_label ORDER BY count DESC, sentiment_label"
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query sentiment analysis: %w", err)
 	}
@@ -676,11 +868,12 @@ func (s *AnalyticsService) GetAutomationAnalysis(ctx context.Context, filters *T
 		WHERE it_process_group IS NOT NULL`
 
 	// Apply filters
-	whereClause, args, _ := buildFilterConditions(filters, 1)
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
 	query += whereClause
-	query += " GROUP BY it_process_group ORDER BY automation_percentage DESC"
+	query += " GROUP BY it_process_group ORDER BY automation_percentage DESC, incident_count DESC, it_process_group"
+	query += filters.limitClause()
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query automation analysis: %w", err)
 	}
@@ -719,7 +912,7 @@ func (s *AnalyticsService) GetAutomationAnalysis(ctx context.Context, filters *T
 // GetITProcessAutomationReporting returns IT process automation reporting utilities
 func (s *AnalyticsService) GetITProcessAutomationReporting(ctx context.Context, filters *TimelineFilters) (map[string]interface{}, error) {
 	// Get automation analysis
-	automationAnalysis, err := s.GetAutomationAnalysis(ctx, filters)
+	automationAnalysis, err := s.GetAutomationAnalysis(ctx, filters.unlimited())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get automation analysis: %w", err)
 	}
@@ -749,11 +942,7 @@ func (s *AnalyticsService) GetITProcessAutomationReporting(ctx context.Context,
 	// Get top automation opportunities
 	topOpportunities := make([]AutomationAnalysis, 0)
 	if len(automationAnalysis) > 0 {
-		limit := 5
-		if len(automationAnalysis) < limit {
-			limit = len(automationAnalysis)
-		}
-		topOpportunities = automationAnalysis[:limit]
+		topOpportunities = topEntries(automationAnalysis, filters.topN(DefaultTopN))
 	}
 
 	return map[string]interface{}{
@@ -791,25 +980,21 @@ func (s *AnalyticsService) GetAnalyticsSummary(ctx context.Context, filters *Tim
 	}
 
 	// Get automation analysis
-	automationAnalysis, err := s.GetAutomationAnalysis(ctx, filters)
+	automationAnalysis, err := s.GetAutomationAnalysis(ctx, filters.unlimited())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get automation analysis: %w", err)
 	}
 
 	// Get top applications
-	applicationAnalysis, err := s.GetApplicationAnalysis(ctx, filters)
+	applicationAnalysis, err := s.GetApplicationAnalysis(ctx, filters.unlimited())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get application analysis: %w", err)
 	}
 
-	// Get top 5 applications
+	// Get the top applications, 5 unless a limit is given
 	topApplications := make([]ApplicationAnalysis, 0)
 	if len(applicationAnalysis) > 0 {
-		limit := 5
-		if len(applicationAnalysis) < limit {
-			limit = len(applicationAnalysis)
-		}
-		topApplications = applicationAnalysis[:limit]
+		topApplications = topEntries(applicationAnalysis, filters.topN(DefaultTopN))
 	}
 
 	summary := &AnalyticsSummary{
@@ -382,6 +382,37 @@ func TestAnalyticsService_GetTicketsPerDayMetrics(t *testing.T) {
 	assert.Equal(t, 1.5, metrics["median_per_day"]) // Median of [1, 2]
 }
 
+func TestAnalyticsService_TicketMetricsWithoutIncidents(t *testing.T) {
+	dbConfig := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	db, err := database.NewDB(dbConfig)
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.InitializeDatabase()
+	require.NoError(t, err)
+
+	analyticsService := NewAnalyticsService(db.GetConnection())
+
+	// An empty dataset has zero metrics rather than failing on NULL aggregates
+	daily, err := analyticsService.GetTicketsPerDayMetrics(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, daily["total_incidents"])
+	assert.Equal(t, 0.0, daily["avg_per_day"])
+	assert.Equal(t, 0.0, daily["max_per_day"])
+	assert.Equal(t, 0.0, daily["min_per_day"])
+	assert.Equal(t, 0.0, daily["median_per_day"])
+
+	weekly, err := analyticsService.GetTicketsPerWeekMetrics(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, weekly["total_incidents"])
+	assert.Equal(t, 0.0, weekly["avg_per_week"])
+	assert.Equal(t, 0.0, weekly["max_per_week"])
+	assert.Equal(t, 0.0, weekly["min_per_week"])
+	assert.Equal(t, 0.0, weekly["median_per_week"])
+}
+
 func TestAnalyticsService_GetPriorityAnalysis(t *testing.T) {
 	// Setup test database
 	dbConfig := &database.Config{
@@ -973,4 +1004,64 @@ func TestAnalyticsService_GetAnalyticsSummary(t *testing.T) {
 
 	assert.Len(t, summary.TopApplications, 1)
 	assert.Equal(t, "App1", summary.TopApplications[0].ApplicationName)
-}
\ No newline at end of file
+}
+func TestAnalyticsService_RankedListLimits(t *testing.T) {
+	db, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.InitializeDatabase())
+
+	ctx := context.Background()
+	// Billing and Payroll tie on incident count, so only the tie-breaker orders them
+	var incidents []models.Incident
+	for i, app := range []string{"Payroll", "Billing", "Payroll", "Billing", "Portal"} {
+		incident := diffTestIncident(uuid.New().String(), "upload-1", uuid.New().String(), "P3", "Open")
+		incident.ApplicationName = app
+		incident.ITProcessGroup = []string{"Network", "Access", "Network", "Access", "Storage"}[i]
+		incidents = append(incidents, incident)
+	}
+	_, err = NewIncidentService(db.GetConnection()).BatchInsertIncidents(ctx, incidents, "upload-1")
+	require.NoError(t, err)
+
+	service := NewAnalyticsService(db.GetConnection())
+	names := func(analysis []ApplicationAnalysis) []string {
+		var names []string
+		for _, app := range analysis {
+			names = append(names, app.ApplicationName)
+		}
+		return names
+	}
+
+	analysis, err := service.GetApplicationAnalysis(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Billing", "Payroll", "Portal"}, names(analysis))
+
+	analysis, err = service.GetApplicationAnalysis(ctx, &TimelineFilters{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Billing", "Payroll"}, names(analysis))
+
+	automation, err := service.GetAutomationAnalysis(ctx, &TimelineFilters{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, automation, 1)
+	assert.Equal(t, "Access", automation[0].ITProcessGroup)
+
+	// Composite reports keep their totals and only shorten the ranked list
+	metrics, err := service.GetPerformanceMetrics(ctx, &TimelineFilters{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 3, metrics["total_applications"])
+	assert.Equal(t, []string{"Billing"}, names(metrics["top_applications"].([]ApplicationAnalysis)))
+
+	summary, err := service.GetAnalyticsSummary(ctx, &TimelineFilters{Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, summary.TopApplications, 2)
+	assert.Len(t, summary.AutomationSummary, 3)
+
+	// Limits are part of the cache key
+	cached, err := NewCachedAnalyticsService(service, DefaultCacheConfig())
+	require.NoError(t, err)
+	_, err = cached.GetApplicationAnalysis(ctx, &TimelineFilters{Limit: 1})
+	require.NoError(t, err)
+	analysis, err = cached.GetApplicationAnalysis(ctx, &TimelineFilters{Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, analysis, 2)
+}
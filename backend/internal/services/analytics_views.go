@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/database"
+)
+
+// viewDriftSampleLimit caps the differing values listed for each view
+const viewDriftSampleLimit = 10
+
+// analyticsViewCheck pairs an analytics view with the live query the analytics services run for
+// the same figures. Keys identify a row; Columns are compared as numbers.
+type analyticsViewCheck struct {
+	view    string
+	keys    []string
+	columns []string
+	live    string
+}
+
+// timelineViewColumns are the counts of the daily and weekly timeline views
+var timelineViewColumns = []string{"incident_count", "p1_count", "p2_count", "p3_count", "p4_count"}
+
+// analyticsViewChecks holds the live equivalent of each analytics view created with the schema
+var analyticsViewChecks = []analyticsViewCheck{
+	{
+		view:    "incident_timeline",
+		keys:    []string{"date"},
+		columns: timelineViewColumns,
+		live: `
+			SELECT
+				DATE_TRUNC('day', report_date) as date,
+				COUNT(*) as incident_count,
+				COUNT(CASE WHEN priority = 'P1' THEN 1 END) as p1_count,
+				COUNT(CASE WHEN priority = 'P2' THEN 1 END) as p2_count,
+				COUNT(CASE WHEN priority = 'P3' THEN 1 END) as p3_count,
+				COUNT(CASE WHEN priority = 'P4' THEN 1 END) as p4_count
+			FROM incidents
+			GROUP BY DATE_TRUNC('day', report_date)`,
+	},
+	{
+		view:    "weekly_timeline",
+		keys:    []string{"week"},
+		columns: timelineViewColumns,
+		live: `
+			SELECT
+				DATE_TRUNC('week', report_date) as week,
+				COUNT(*) as incident_count,
+				COUNT(CASE WHEN priority = 'P1' THEN 1 END) as p1_count,
+				COUNT(CASE WHEN priority = 'P2' THEN 1 END) as p2_count,
+				COUNT(CASE WHEN priority = 'P3' THEN 1 END) as p3_count,
+				COUNT(CASE WHEN priority = 'P4' THEN 1 END) as p4_count
+			FROM incidents
+			GROUP BY DATE_TRUNC('week', report_date)`,
+	},
+	{
+		view:    "resolution_metrics",
+		keys:    []string{"application_name", "priority"},
+		columns: []string{"avg_resolution_time", "median_resolution_time", "total_incidents", "resolved_incidents"},
+		live: fmt.Sprintf(`
+			SELECT
+				application_name,
+				priority,
+				AVG(%[1]s) as avg_resolution_time,
+				PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY %[1]s) as median_resolution_time,
+				COUNT(*) as total_incidents,
+				COUNT(CASE WHEN resolve_date IS NOT NULL THEN 1 END) as resolved_incidents
+			FROM incidents
+			WHERE resolution_time_hours IS NOT NULL
+			GROUP BY application_name, priority`, resolutionHoursExpr),
+	},
+	{
+		view:    "priority_analysis",
+		keys:    []string{"priority"},
+		columns: []string{"count", "percentage"},
+		live: `
+			SELECT
+				priority,
+				COUNT(*) as count,
+				ROUND(COUNT(*) * 100.0 / SUM(COUNT(*)) OVER (), 2) as percentage
+			FROM incidents
+			GROUP BY priority`,
+	},
+	{
+		view:    "sentiment_summary",
+		keys:    []string{"sentiment_label"},
+		columns: []string{"count", "avg_score"},
+		live: `
+			SELECT
+				sentiment_label,
+				COUNT(*) as count,
+				ROUND(AVG(sentiment_score), 3) as avg_score
+			FROM incidents
+			WHERE sentiment_label IS NOT NULL
+			GROUP BY sentiment_label`,
+	},
+	{
+		view:    "automation_opportunities",
+		keys:    []string{"it_process_group"},
+		columns: []string{"incident_count", "avg_automation_score", "automatable_count", "automation_percentage"},
+		live: `
+			SELECT
+				it_process_group,
+				COUNT(*) as incident_count,
+				AVG(automation_score) as avg_automation_score,
+				COUNT(CASE WHEN automation_feasible = true THEN 1 END) as automatable_count,
+				ROUND(COUNT(CASE WHEN automation_feasible = true THEN 1 END) * 100.0 / COUNT(*), 2) as automation_percentage
+			FROM incidents
+			WHERE it_process_group IS NOT NULL
+			GROUP BY it_process_group`,
+	},
+}
+
+// ViewDrift is a value that differs between an analytics view and its live query. A nil value
+// means the row is missing on that side.
+type ViewDrift struct {
+	Key       string   `json:"key"`
+	Column    string   `json:"column"`
+	ViewValue *float64 `json:"view_value"`
+	LiveValue *float64 `json:"live_value"`
+}
+
+// ViewConsistency compares one analytics view with the live query the services run
+type ViewConsistency struct {
+	View           string `json:"view"`
+	Exists         bool   `json:"exists"`
+	Consistent     bool   `json:"consistent"`
+	ViewRows       int    `json:"view_rows"`
+	LiveRows       int    `json:"live_rows"`
+	MissingRows    int    `json:"missing_rows"`
+	ExtraRows      int    `json:"extra_rows"`
+	MismatchedRows int    `json:"mismatched_rows"`
+	// Drift lists the first differing values
+	Drift []ViewDrift `json:"drift"`
+	Error string      `json:"error,omitempty"`
+}
+
+// ViewConsistencyReport is the result of checking every analytics view
+type ViewConsistencyReport struct {
+	CheckedAt  time.Time         `json:"checked_at"`
+	Consistent bool              `json:"consistent"`
+	Rebuilt    bool              `json:"rebuilt"`
+	Views      []ViewConsistency `json:"views"`
+}
+
+// AnalyticsViewService checks the analytics views of the schema against the live analytics
+// queries, and rebuilds them when they drift
+type AnalyticsViewService struct {
+	db *sql.DB
+}
+
+// NewAnalyticsViewService creates a new AnalyticsViewService instance
+func NewAnalyticsViewService(db *sql.DB) *AnalyticsViewService {
+	return &AnalyticsViewService{db: db}
+}
+
+// CheckViews compares every analytics view with its live query. A view that is missing or
+// fails to query is reported as inconsistent rather than failing the check.
+func (s *AnalyticsViewService) CheckViews(ctx context.Context) (*ViewConsistencyReport, error) {
+	existing, err := s.existingViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ViewConsistencyReport{CheckedAt: time.Now(), Consistent: true}
+	for _, check := range analyticsViewChecks {
+		result := ViewConsistency{View: check.view, Exists: existing[check.view], Drift: []ViewDrift{}}
+		if !result.Exists {
+			result.Error = "view does not exist"
+		} else if err := s.compare(ctx, check, &result); err != nil {
+			result.Error = err.Error()
+		}
+		result.Consistent = result.Error == "" && result.MissingRows == 0 && result.ExtraRows == 0 && result.MismatchedRows == 0
+		report.Consistent = report.Consistent && result.Consistent
+		report.Views = append(report.Views, result)
+	}
+	return report, nil
+}
+
+// RebuildViews recreates the analytics views from their current definitions and checks them again
+func (s *AnalyticsViewService) RebuildViews(ctx context.Context) (*ViewConsistencyReport, error) {
+	if err := database.RebuildAnalyticsViews(ctx, s.db); err != nil {
+		return nil, fmt.Errorf("failed to rebuild analytics views: %w", err)
+	}
+	report, err := s.CheckViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Rebuilt = true
+	return report, nil
+}
+
+// existingViews returns the analytics views present in the database
+func (s *AnalyticsViewService) existingViews(ctx context.Context) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT table_name FROM information_schema.tables WHERE table_type = 'VIEW'")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query views: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan view: %w", err)
+		}
+		existing[name] = true
+	}
+	return existing, rows.Err()
+}
+
+// compare loads the view and its live query and records how they differ
+func (s *AnalyticsViewService) compare(ctx context.Context, check analyticsViewCheck, result *ViewConsistency) error {
+	viewRows, err := s.loadRows(ctx, check, check.view)
+	if err != nil {
+		return err
+	}
+	liveRows, err := s.loadRows(ctx, check, "("+check.live+")")
+	if err != nil {
+		return err
+	}
+	result.ViewRows, result.LiveRows = len(viewRows), len(liveRows)
+
+	addDrift := func(drift ViewDrift) {
+		if len(result.Drift) < viewDriftSampleLimit {
+			result.Drift = append(result.Drift, drift)
+		}
+	}
+	for key, live := range liveRows {
+		view, ok := viewRows[key]
+		if !ok {
+			result.MissingRows++
+			addDrift(ViewDrift{Key: key, Column: check.columns[0], LiveValue: live[0]})
+			continue
+		}
+		mismatched := false
+		for i, column := range check.columns {
+			if !viewValuesEqual(view[i], live[i]) {
+				mismatched = true
+				addDrift(ViewDrift{Key: key, Column: column, ViewValue: view[i], LiveValue: live[i]})
+			}
+		}
+		if mismatched {
+			result.MismatchedRows++
+		}
+	}
+	for key, view := range viewRows {
+		if _, ok := liveRows[key]; !ok {
+			result.ExtraRows++
+			addDrift(ViewDrift{Key: key, Column: check.columns[0], ViewValue: view[0]})
+		}
+	}
+	return nil
+}
+
+// loadRows returns the compared columns of source keyed by its key columns joined with "|"
+func (s *AnalyticsViewService) loadRows(ctx context.Context, check analyticsViewCheck, source string) (map[string][]*float64, error) {
+	keys := make([]string, len(check.keys))
+	for i, key := range check.keys {
+		keys[i] = fmt.Sprintf("COALESCE(CAST(%s AS VARCHAR), '')", key)
+	}
+	columns := make([]string, len(check.columns))
+	for i, column := range check.columns {
+		columns[i] = fmt.Sprintf("CAST(%s AS DOUBLE)", column)
+	}
+	query := fmt.Sprintf("SELECT %s, %s FROM %s", strings.Join(keys, " || '|' || "), strings.Join(columns, ", "), source)
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", check.view, err)
+	}
+	defer rows.Close()
+
+	result := make(map[string][]*float64)
+	for rows.Next() {
+		var key string
+		values := make([]sql.NullFloat64, len(check.columns))
+		dest := []interface{}{&key}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", check.view, err)
+		}
+
+		row := make([]*float64, len(values))
+		for i, value := range values {
+			if value.Valid {
+				v := value.Float64
+				row[i] = &v
+			}
+		}
+		result[key] = row
+	}
+	return result, rows.Err()
+}
+
+// viewValuesEqual reports whether two compared values match, allowing for floating point error
+func viewValuesEqual(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return math.Abs(*a-*b) <= 1e-6*math.Max(1, math.Abs(*b))
+}
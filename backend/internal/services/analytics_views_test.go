@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestAnalyticsViewService_CheckViews(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+	service := NewAnalyticsViewService(db)
+
+	hours := func(h int) *int { return &h }
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P1", "Closed"),
+		diffTestIncident("i2", "upload-1", "INC002", "P3", "Closed"),
+	}
+	incidents[0].ResolutionTimeHours = hours(10)
+	incidents[0].NetResolutionTimeHours = hours(6)
+	incidents[1].ResolutionTimeHours = hours(20)
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	report, err := service.CheckViews(ctx)
+	if err != nil {
+		t.Fatalf("Failed to check views: %v", err)
+	}
+	if !report.Consistent || len(report.Views) != len(database.AnalyticsViewNames()) {
+		t.Errorf("Expected every view to match its live query, got %+v", report)
+	}
+
+	// A view left behind by an older release measures gross resolution time
+	if _, err := db.ExecContext(ctx, `
+		CREATE OR REPLACE VIEW resolution_metrics AS
+		SELECT application_name, priority,
+			AVG(resolution_time_hours) as avg_resolution_time,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY resolution_time_hours) as median_resolution_time,
+			COUNT(*) as total_incidents,
+			COUNT(CASE WHEN resolve_date IS NOT NULL THEN 1 END) as resolved_incidents
+		FROM incidents WHERE resolution_time_hours IS NOT NULL
+		GROUP BY application_name, priority`); err != nil {
+		t.Fatalf("Failed to replace view: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "DROP VIEW sentiment_summary"); err != nil {
+		t.Fatalf("Failed to drop view: %v", err)
+	}
+
+	report, err = service.CheckViews(ctx)
+	if err != nil {
+		t.Fatalf("Failed to check views: %v", err)
+	}
+	if report.Consistent {
+		t.Error("Expected the drifted views to be reported")
+	}
+	for _, view := range report.Views {
+		switch view.View {
+		case "resolution_metrics":
+			if view.Consistent || view.MismatchedRows != 1 || len(view.Drift) != 2 || *view.Drift[0].LiveValue != 6 {
+				t.Errorf("Expected the P1 row to drift in avg and median, got %+v", view)
+			}
+		case "sentiment_summary":
+			if view.Exists || view.Consistent {
+				t.Errorf("Expected the dropped view to be reported missing, got %+v", view)
+			}
+		default:
+			if !view.Consistent {
+				t.Errorf("Expected %s to stay consistent, got %+v", view.View, view)
+			}
+		}
+	}
+
+	report, err = service.RebuildViews(ctx)
+	if err != nil {
+		t.Fatalf("Failed to rebuild views: %v", err)
+	}
+	if !report.Consistent || !report.Rebuilt {
+		t.Errorf("Expected the rebuilt views to be consistent, got %+v", report)
+	}
+}
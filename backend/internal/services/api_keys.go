@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// API key roles. Observers may only read the analytics endpoints listed on their key.
+const (
+	APIKeyRoleObserver = "observer"
+)
+
+// ObserverKeyPrefix starts every observer key, so they are told apart from other bearer tokens
+// without a lookup
+const ObserverKeyPrefix = "ims_obs_"
+
+// apiKeyUsageInterval is how often a key's last use is recorded, so each embedded chart load
+// does not write to the database
+const apiKeyUsageInterval = time.Minute
+
+// apiKeyEndpointPattern matches an analytics route below /analytics, such as timeline/daily or
+// applications/:name
+var apiKeyEndpointPattern = regexp.MustCompile(`^[a-z0-9_-]+(/:?[a-z0-9_-]+)*$`)
+
+// ErrInvalidAPIKey is returned when an API key lists a malformed endpoint or an empty date range
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKey is a narrowly scoped credential for server-side callers, such as a wiki page embedding
+// charts. An observer key reads only the analytics endpoints it lists, for report dates within
+// its range, without a user's session. Only a hash of the key itself is stored.
+type APIKey struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Role      string   `json:"role"`
+	Endpoints []string `json:"endpoints"` // analytics routes below /analytics
+	// StartDate and EndDate bound the report dates the key may ask for; either may be empty
+	StartDate  string     `json:"start_date,omitempty"`
+	EndDate    string     `json:"end_date,omitempty"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// UserID is the user requests made with the key act as, such as for data scopes and usage
+func (k *APIKey) UserID() string {
+	return "apikey:" + k.ID
+}
+
+// Allows reports whether the key may read an analytics route below /analytics
+func (k *APIKey) Allows(endpoint string) bool {
+	return containsString(k.Endpoints, endpoint)
+}
+
+// apiKeyContextKey is the context key of the API key a request is made with
+type apiKeyContextKey struct{}
+
+// WithAPIKey returns a context of a request made with key. A nil key leaves the context unchanged.
+func WithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	if key == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext returns the API key a request is made with, or nil for other requests
+func APIKeyFromContext(ctx context.Context) *APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*APIKey)
+	return key
+}
+
+// APIKeyService manages API keys
+type APIKeyService struct {
+	db *sql.DB
+}
+
+// NewAPIKeyService creates a new APIKeyService instance
+func NewAPIKeyService(db *sql.DB) *APIKeyService {
+	return &APIKeyService{db: db}
+}
+
+// CreateObserverKey stores a new observer key reading endpoints within the report date range,
+// living for ttl or forever when ttl is 0, and returns it with the key itself, which is only
+// shown here
+func (s *APIKeyService) CreateObserverKey(ctx context.Context, name string, endpoints []string, startDate, endDate string, ttl time.Duration, createdBy string) (*APIKey, string, error) {
+	key := &APIKey{
+		ID:        hashSessionToken(randomToken())[:16],
+		Name:      strings.TrimSpace(name),
+		Role:      APIKeyRoleObserver,
+		Endpoints: uniqueSorted(endpoints),
+		StartDate: startDate,
+		EndDate:   endDate,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now().UTC(),
+	}
+	if len(key.Endpoints) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one endpoint is required", ErrInvalidAPIKey)
+	}
+	for _, endpoint := range key.Endpoints {
+		if !apiKeyEndpointPattern.MatchString(endpoint) {
+			return nil, "", fmt.Errorf("%w: endpoint %q is not an analytics route such as timeline/daily", ErrInvalidAPIKey, endpoint)
+		}
+	}
+	if startDate != "" && endDate != "" && startDate > endDate {
+		return nil, "", fmt.Errorf("%w: start_date is after end_date", ErrInvalidAPIKey)
+	}
+	if ttl > 0 {
+		expires := key.CreatedAt.Add(ttl)
+		key.ExpiresAt = &expires
+	}
+	data, err := json.Marshal(key.Endpoints)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret := ObserverKeyPrefix + randomToken()
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO api_keys (id, key_hash, name, role, endpoints, start_date, end_date, created_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, key.ID, hashSessionToken(secret), key.Name, key.Role, string(data), nullIfEmpty(startDate), nullIfEmpty(endDate),
+		nullIfEmpty(createdBy), key.CreatedAt, key.ExpiresAt); err != nil {
+		return nil, "", fmt.Errorf("failed to save API key: %w", err)
+	}
+	return key, secret, nil
+}
+
+// apiKeyColumns are the columns scanned by scanAPIKey
+const apiKeyColumns = `id, name, role, endpoints, COALESCE(CAST(start_date AS VARCHAR), ''),
+	COALESCE(CAST(end_date AS VARCHAR), ''), COALESCE(created_by, ''), created_at, expires_at, last_used_at, revoked_at`
+
+// scanAPIKey reads a key selected with apiKeyColumns
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*APIKey, error) {
+	var key APIKey
+	var endpoints string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	if err := row.Scan(&key.ID, &key.Name, &key.Role, &endpoints, &key.StartDate, &key.EndDate, &key.CreatedBy,
+		&key.CreatedAt, &expiresAt, &lastUsedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(endpoints), &key.Endpoints); err != nil {
+		return nil, fmt.Errorf("failed to decode API key endpoints: %w", err)
+	}
+	for _, t := range []struct {
+		src sql.NullTime
+		dst **time.Time
+	}{{expiresAt, &key.ExpiresAt}, {lastUsedAt, &key.LastUsedAt}, {revokedAt, &key.RevokedAt}} {
+		if t.src.Valid {
+			value := t.src.Time
+			*t.dst = &value
+		}
+	}
+	return &key, nil
+}
+
+// ListAPIKeys returns every key, including revoked and expired ones, newest first
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys ORDER BY created_at DESC, id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+// ResolveKey returns the live key with the given secret and records its use, returning
+// sql.ErrNoRows when it does not exist, was revoked or expired
+func (s *APIKeyService) ResolveKey(ctx context.Context, secret string) (*APIKey, error) {
+	now := time.Now().UTC()
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+`
+		FROM api_keys
+		WHERE key_hash = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)
+	`, hashSessionToken(secret), now))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API key: %w", err)
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyUsageInterval {
+		if _, err := s.db.ExecContext(ctx, "UPDATE api_keys SET last_used_at = ? WHERE id = ?", now, key.ID); err != nil {
+			return nil, fmt.Errorf("failed to record API key use: %w", err)
+		}
+		key.LastUsedAt = &now
+	}
+	return key, nil
+}
+
+// RevokeAPIKey stops a key working, returning sql.ErrNoRows when it does not exist or was
+// revoked already
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL",
+		time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
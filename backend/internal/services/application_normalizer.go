@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"incident-management-system/internal/models"
+)
+
+// ApplicationAlias maps a raw application name onto its canonical name
+type ApplicationAlias struct {
+	AliasKey      string    `json:"alias_key"`
+	Alias         string    `json:"alias"`
+	CanonicalName string    `json:"canonical_name"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ApplicationMergeResult reports the outcome of merging one application name into another
+type ApplicationMergeResult struct {
+	From             string `json:"from"`
+	To               string `json:"to"`
+	UpdatedIncidents int64  `json:"updated_incidents"`
+}
+
+// ApplicationNormalizer resolves application name variants ("SAP-PROD", "sap prod") to canonical names
+type ApplicationNormalizer struct {
+	db      *sql.DB
+	mu      sync.RWMutex
+	aliases map[string]string // alias key -> canonical name
+}
+
+// NewApplicationNormalizer creates a new ApplicationNormalizer instance
+func NewApplicationNormalizer(db *sql.DB) *ApplicationNormalizer {
+	return &ApplicationNormalizer{
+		db:      db,
+		aliases: make(map[string]string),
+	}
+}
+
+// ApplicationAliasKey reduces an application name to its matching key: lowercase
+// alphanumeric words separated by single spaces, so punctuation and case are ignored
+func ApplicationAliasKey(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// LoadAliases refreshes the in-memory alias rules from the database
+func (n *ApplicationNormalizer) LoadAliases(ctx context.Context) error {
+	aliases, err := n.ListAliases(ctx)
+	if err != nil {
+		return err
+	}
+
+	rules := make(map[string]string, len(aliases))
+	for _, alias := range aliases {
+		rules[alias.AliasKey] = alias.CanonicalName
+	}
+
+	n.mu.Lock()
+	n.aliases = rules
+	n.mu.Unlock()
+
+	return nil
+}
+
+// Normalize returns the canonical name for a raw application name, or the trimmed raw name when no rule matches
+func (n *ApplicationNormalizer) Normalize(raw string) string {
+	trimmed := strings.TrimSpace(raw)
+
+	n.mu.RLock()
+	canonical, ok := n.aliases[ApplicationAliasKey(trimmed)]
+	n.mu.RUnlock()
+
+	if ok {
+		return canonical
+	}
+	return trimmed
+}
+
+// NormalizeIncidents applies the alias rules to incidents, keeping the original value in ApplicationNameRaw
+func (n *ApplicationNormalizer) NormalizeIncidents(incidents []models.Incident) {
+	for i := range incidents {
+		if incidents[i].ApplicationNameRaw == "" {
+			incidents[i].ApplicationNameRaw = incidents[i].ApplicationName
+		}
+		incidents[i].ApplicationName = n.Normalize(incidents[i].ApplicationNameRaw)
+	}
+}
+
+// ListAliases returns all alias rules ordered by canonical name
+func (n *ApplicationNormalizer) ListAliases(ctx context.Context) ([]ApplicationAlias, error) {
+	query := `
+		SELECT alias_key, alias, canonical_name, created_at
+		FROM application_aliases
+		ORDER BY canonical_name, alias_key
+	`
+
+	rows, err := n.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query application aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := []ApplicationAlias{}
+	for rows.Next() {
+		var alias ApplicationAlias
+		if err := rows.Scan(&alias.AliasKey, &alias.Alias, &alias.CanonicalName, &alias.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan application alias: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, rows.Err()
+}
+
+// SaveAlias creates or replaces the rule mapping alias onto canonicalName
+func (n *ApplicationNormalizer) SaveAlias(ctx context.Context, alias, canonicalName string) (*ApplicationAlias, error) {
+	key := ApplicationAliasKey(alias)
+	if key == "" {
+		return nil, fmt.Errorf("alias must contain letters or digits")
+	}
+	canonicalName = strings.TrimSpace(canonicalName)
+	if canonicalName == "" {
+		return nil, fmt.Errorf("canonical name is required")
+	}
+
+	record := &ApplicationAlias{
+		AliasKey:      key,
+		Alias:         strings.TrimSpace(alias),
+		CanonicalName: canonicalName,
+		CreatedAt:     time.Now(),
+	}
+
+	query := `
+		INSERT OR REPLACE INTO application_aliases (alias_key, alias, canonical_name, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+	if _, err := n.db.ExecContext(ctx, query, record.AliasKey, record.Alias, record.CanonicalName, record.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save application alias: %w", err)
+	}
+
+	n.mu.Lock()
+	n.aliases[key] = canonicalName
+	n.mu.Unlock()
+
+	return record, nil
+}
+
+// DeleteAlias removes an alias rule, returning sql.ErrNoRows when it does not exist
+func (n *ApplicationNormalizer) DeleteAlias(ctx context.Context, alias string) error {
+	key := ApplicationAliasKey(alias)
+
+	result, err := n.db.ExecContext(ctx, "DELETE FROM application_aliases WHERE alias_key = ?", key)
+	if err != nil {
+		return fmt.Errorf("failed to delete application alias: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	n.mu.Lock()
+	delete(n.aliases, key)
+	n.mu.Unlock()
+
+	return nil
+}
+
+// MergeApplications records from as an alias of to and re-maps historical incidents whose
+// application name matches from. The original value is preserved in application_name_raw.
+func (n *ApplicationNormalizer) MergeApplications(ctx context.Context, from, to string) (*ApplicationMergeResult, error) {
+	if _, err := n.SaveAlias(ctx, from, to); err != nil {
+		return nil, err
+	}
+	to = strings.TrimSpace(to)
+	fromKey := ApplicationAliasKey(from)
+
+	// Match on the alias key in Go so variants like "SAP-PROD" and "sap prod" are both re-mapped
+	rows, err := n.db.QueryContext(ctx, "SELECT DISTINCT application_name FROM incidents WHERE application_name <> ?", to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query application names: %w", err)
+	}
+	var matching []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan application name: %w", err)
+		}
+		if ApplicationAliasKey(name) == fromKey {
+			matching = append(matching, name)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read application names: %w", err)
+	}
+
+	result := &ApplicationMergeResult{From: strings.TrimSpace(from), To: to}
+	if len(matching) == 0 {
+		return result, nil
+	}
+
+	tx, err := n.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE incidents
+		SET application_name_raw = COALESCE(NULLIF(application_name_raw, ''), application_name),
+			application_name = ?,
+			updated_at = ?
+		WHERE application_name = ?
+	`
+	for _, name := range matching {
+		res, err := tx.ExecContext(ctx, query, to, time.Now(), name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to re-map application %q: %w", name, err)
+		}
+		updated, _ := res.RowsAffected()
+		result.UpdatedIncidents += updated
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit application merge: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestApplicationAliasKey(t *testing.T) {
+	tests := map[string]string{
+		"SAP":         "sap",
+		"sap prod":    "sap prod",
+		"SAP-PROD":    "sap prod",
+		"  Sap_Prod ": "sap prod",
+		"---":         "",
+	}
+
+	for input, expected := range tests {
+		if got := ApplicationAliasKey(input); got != expected {
+			t.Errorf("ApplicationAliasKey(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestApplicationNormalizer_MergeApplications(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	normalizer := NewApplicationNormalizer(db)
+	incidentService := NewIncidentService(db)
+	ctx := context.Background()
+
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P1", "Open"),
+		diffTestIncident("i2", "upload-1", "INC002", "P2", "Open"),
+		diffTestIncident("i3", "upload-1", "INC003", "P3", "Open"),
+	}
+	incidents[0].ApplicationName = "SAP-PROD"
+	incidents[1].ApplicationName = "sap prod"
+	incidents[2].ApplicationName = "Portal"
+
+	// Without rules only whitespace is normalized, and the raw value is kept
+	normalizer.NormalizeIncidents(incidents)
+	if incidents[0].ApplicationName != "SAP-PROD" || incidents[0].ApplicationNameRaw != "SAP-PROD" {
+		t.Errorf("Unexpected normalization without rules: %+v", incidents[0])
+	}
+
+	if _, err := incidentService.BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	result, err := normalizer.MergeApplications(ctx, "sap prod", "SAP")
+	if err != nil {
+		t.Fatalf("Failed to merge applications: %v", err)
+	}
+
+	if result.UpdatedIncidents != 2 {
+		t.Errorf("Expected 2 re-mapped incidents, got %d", result.UpdatedIncidents)
+	}
+
+	stored, err := incidentService.GetIncidentsByUpload(ctx, "upload-1")
+	if err != nil {
+		t.Fatalf("Failed to load incidents: %v", err)
+	}
+	for _, incident := range stored {
+		switch incident.IncidentID {
+		case "INC001", "INC002":
+			if incident.ApplicationName != "SAP" {
+				t.Errorf("Expected %s re-mapped to SAP, got %s", incident.IncidentID, incident.ApplicationName)
+			}
+		case "INC003":
+			if incident.ApplicationName != "Portal" {
+				t.Errorf("Expected INC003 untouched, got %s", incident.ApplicationName)
+			}
+		}
+	}
+
+	// The merge leaves an alias rule behind for future ingestion
+	fresh := NewApplicationNormalizer(db)
+	if err := fresh.LoadAliases(ctx); err != nil {
+		t.Fatalf("Failed to load aliases: %v", err)
+	}
+	if got := fresh.Normalize(" Sap Prod "); got != "SAP" {
+		t.Errorf("Expected alias to resolve to SAP, got %s", got)
+	}
+
+	if err := fresh.DeleteAlias(ctx, "SAP-PROD"); err != nil {
+		t.Errorf("Failed to delete alias: %v", err)
+	}
+	if got := fresh.Normalize("sap prod"); got != "sap prod" {
+		t.Errorf("Expected deleted alias to no longer apply, got %s", got)
+	}
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// applicationProfilePercentiles are the resolution time percentiles of an application profile
+var applicationProfilePercentiles = []float64{25, 75, 90, 95}
+
+// ApplicationProblem is a root cause recorded on incidents of an application: the underlying
+// problem linking them
+type ApplicationProblem struct {
+	RootCause     string `json:"root_cause"`
+	IncidentCount int    `json:"incident_count"`
+	OpenIncidents int    `json:"open_incidents"`
+	FirstSeen     string `json:"first_seen"`
+	LastSeen      string `json:"last_seen"`
+}
+
+// ApplicationProfile gathers the analytics of one application for a review with its owner
+type ApplicationProfile struct {
+	Name              string  `json:"name"`
+	Period            string  `json:"period"`
+	IncidentCount     int     `json:"incident_count"`
+	OpenIncidents     int     `json:"open_incidents"`
+	AvgResolutionTime float64 `json:"avg_resolution_time"`
+	// Timeline and Sentiment are the incident volume and sentiment per day or week
+	Timeline   []TimelineData           `json:"timeline"`
+	Sentiment  []SentimentTimelinePoint `json:"sentiment"`
+	Priorities []PriorityAnalysis       `json:"priorities"`
+	Resolution *ResolutionMetrics       `json:"resolution"`
+	// Automation lists the IT process groups of the application's incidents, the most
+	// automatable first
+	Automation []AutomationAnalysis `json:"automation"`
+	// Problems lists the recorded root causes, the most recurring first
+	Problems []ApplicationProblem `json:"problems"`
+	SLA      SLAStatus            `json:"sla"`
+	// SLAByPriority breaks the SLA status down by priority
+	SLAByPriority []SummaryUnit `json:"sla_by_priority"`
+	GeneratedAt   time.Time     `json:"generated_at"`
+}
+
+// GetApplicationProfile returns the profile of the named application within the filters, whose
+// applications it replaces, returning sql.ErrNoRows when the application has no incidents in
+// them. The timeline and sentiment trend are daily or weekly per period; automation groups and
+// problems are cut to the filters' limit. Open incidents are measured against their SLA target
+// at now.
+func (s *AnalyticsService) GetApplicationProfile(ctx context.Context, name, period string, filters *TimelineFilters, now time.Time) (*ApplicationProfile, error) {
+	scoped := TimelineFilters{}
+	if filters != nil {
+		scoped = *filters
+	}
+	scoped.Applications = []string{name}
+	scoped.Percentiles = applicationProfilePercentiles
+	limit := scoped.topN(DefaultTopN)
+	scoped.Limit = limit
+
+	units, err := s.summaryUnits(ctx, "COALESCE(application_name, '')", nil, &scoped, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(units) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	profile := &ApplicationProfile{
+		Name:              units[0].Name,
+		Period:            period,
+		IncidentCount:     units[0].IncidentCount,
+		OpenIncidents:     units[0].OpenIncidents,
+		AvgResolutionTime: units[0].AvgResolutionTime,
+		SLA:               units[0].SLA,
+		GeneratedAt:       now,
+	}
+
+	if period == "weekly" {
+		profile.Timeline, err = s.GetWeeklyTimeline(ctx, &scoped)
+	} else {
+		profile.Timeline, err = s.GetDailyTimeline(ctx, &scoped)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if profile.Sentiment, err = s.GetSentimentTimeline(ctx, period, &scoped); err != nil {
+		return nil, err
+	}
+	if profile.Priorities, err = s.GetPriorityAnalysis(ctx, &scoped); err != nil {
+		return nil, err
+	}
+	if profile.Resolution, err = s.GetResolutionAnalysis(ctx, &scoped); err != nil {
+		return nil, err
+	}
+	if profile.Automation, err = s.GetAutomationAnalysis(ctx, &scoped); err != nil {
+		return nil, err
+	}
+	if profile.Automation == nil {
+		profile.Automation = []AutomationAnalysis{}
+	}
+	if profile.Problems, err = s.applicationProblems(ctx, &scoped, limit); err != nil {
+		return nil, err
+	}
+	if profile.SLAByPriority, err = s.summaryUnits(ctx, "priority", nil, &scoped, now); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// applicationProblems returns the root causes recorded on the incidents matching the filters,
+// compared ignoring case and surrounding space, the most recurring first
+func (s *AnalyticsService) applicationProblems(ctx context.Context, filters *TimelineFilters, limit int) ([]ApplicationProblem, error) {
+	query := `
+		SELECT
+			MIN(TRIM(root_cause)) AS root_cause,
+			COUNT(*) AS incident_count,
+			COUNT(CASE WHEN resolve_date IS NULL THEN 1 END) AS open_incidents,
+			MIN(report_date) AS first_seen,
+			MAX(report_date) AS last_seen
+		FROM incidents
+		WHERE TRIM(COALESCE(root_cause, '')) <> ''`
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query += whereClause
+	query += fmt.Sprintf(" GROUP BY LOWER(TRIM(root_cause)) ORDER BY incident_count DESC, root_cause LIMIT %d", limit)
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query application problems: %w", err)
+	}
+	defer rows.Close()
+
+	problems := []ApplicationProblem{}
+	for rows.Next() {
+		var problem ApplicationProblem
+		var firstSeen, lastSeen time.Time
+		if err := rows.Scan(&problem.RootCause, &problem.IncidentCount, &problem.OpenIncidents, &firstSeen, &lastSeen); err != nil {
+			return nil, fmt.Errorf("failed to scan application problem: %w", err)
+		}
+		problem.FirstSeen = firstSeen.Format("2006-01-02")
+		problem.LastSeen = lastSeen.Format("2006-01-02")
+		problems = append(problems, problem)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating application problems: %w", err)
+	}
+	return problems, nil
+}
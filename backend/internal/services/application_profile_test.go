@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/models"
+)
+
+func TestAnalyticsService_GetApplicationProfile(t *testing.T) {
+	resolved := func(id, priority, rootCause string, hours int) models.Incident {
+		incident := diffTestIncident(id, "upload-1", "INC-"+id, priority, "Closed")
+		resolveDate := incident.ReportDate.Add(time.Duration(hours) * time.Hour)
+		incident.ResolveDate = &resolveDate
+		incident.ResolutionTimeHours = &hours
+		incident.RootCause = rootCause
+		return incident
+	}
+	open := diffTestIncident("open", "upload-1", "INC-open", "P3", "Open")
+	open.RootCause = "Disk full"
+	other := resolved("other", "P1", "Expired certificate", 1)
+	other.ApplicationName = "Billing"
+
+	dbWrapper, db := newSentimentTestDB(t, []models.Incident{
+		resolved("met", "P1", "Expired certificate", 2),
+		resolved("breached", "P1", " expired certificate ", 10),
+		open,
+		other,
+	})
+	defer dbWrapper.Close()
+	service := NewAnalyticsService(db)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	profile, err := service.GetApplicationProfile(ctx, "Portal", "weekly", nil, now)
+	if err != nil {
+		t.Fatalf("GetApplicationProfile() error = %v", err)
+	}
+	if profile.Name != "Portal" || profile.IncidentCount != 3 || profile.OpenIncidents != 1 {
+		t.Errorf("unexpected profile totals: %+v", profile)
+	}
+	if len(profile.Timeline) != 1 || profile.Timeline[0].IncidentCount != 3 || len(profile.Sentiment) != 1 {
+		t.Errorf("expected one week of timeline and sentiment, got %+v and %+v", profile.Timeline, profile.Sentiment)
+	}
+	if len(profile.Priorities) != 2 || profile.Priorities[0].Priority != "P1" || profile.Priorities[0].Count != 2 {
+		t.Errorf("unexpected priority mix: %+v", profile.Priorities)
+	}
+	if profile.Resolution.ResolvedIncidents != 2 || profile.Resolution.Percentiles["p90"] == 0 {
+		t.Errorf("unexpected resolution metrics: %+v", profile.Resolution)
+	}
+
+	// The open P3 incident is 120 hours old, past its 24 hour target
+	if profile.SLA.Met != 1 || profile.SLA.Breached != 1 || profile.SLA.OpenBreached != 1 || profile.SLA.ComplianceRate != 33.3 {
+		t.Errorf("unexpected SLA status: %+v", profile.SLA)
+	}
+	if len(profile.SLAByPriority) != 2 || profile.SLAByPriority[0].Name != "P1" || profile.SLAByPriority[0].SLA.ComplianceRate != 50 {
+		t.Errorf("unexpected SLA by priority: %+v", profile.SLAByPriority)
+	}
+
+	if len(profile.Problems) != 2 {
+		t.Fatalf("expected two problems, got %+v", profile.Problems)
+	}
+	if problem := profile.Problems[0]; problem.RootCause != "Expired certificate" || problem.IncidentCount != 2 ||
+		problem.OpenIncidents != 0 || problem.FirstSeen != "2024-01-15" {
+		t.Errorf("expected root causes compared ignoring case and space, got %+v", problem)
+	}
+	if problem := profile.Problems[1]; problem.RootCause != "Disk full" || problem.OpenIncidents != 1 {
+		t.Errorf("unexpected second problem: %+v", problem)
+	}
+
+	if _, err := service.GetApplicationProfile(ctx, "Unknown", "daily", nil, now); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for an application without incidents, got %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// archiveFilePattern matches the per-year archive file names
+var archiveFilePattern = regexp.MustCompile(`^incidents_(\d{4})\.parquet$`)
+
+// ArchiveFile describes the archived incidents of one report year
+type ArchiveFile struct {
+	Year      int    `json:"year"`
+	File      string `json:"file"`
+	Incidents int    `json:"incidents"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// ArchiveResult summarizes one archive run
+type ArchiveResult struct {
+	Before   string `json:"before"`
+	Archived int    `json:"archived"`
+	Years    []int  `json:"years"`
+}
+
+// ArchiveStore keeps incidents moved out of the live database in one Parquet file per report
+// year. DuckDB reads the files directly, so analytics can federate them with the live table.
+type ArchiveStore struct {
+	db  *sql.DB
+	dir string
+
+	mu    sync.RWMutex
+	years []int
+}
+
+// NewArchiveStore opens the archive in dir, creating the directory if needed
+func NewArchiveStore(db *sql.DB, dir string) (*ArchiveStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve archive directory: %w", err)
+	}
+
+	store := &ArchiveStore{db: db, dir: absDir}
+	if err := store.scan(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// scan refreshes the archived years from the files on disk
+func (a *ArchiveStore) scan() error {
+	entries, err := os.ReadDir(a.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read archive directory: %w", err)
+	}
+
+	var years []int
+	for _, entry := range entries {
+		if match := archiveFilePattern.FindStringSubmatch(entry.Name()); match != nil && !entry.IsDir() {
+			year, _ := strconv.Atoi(match[1])
+			years = append(years, year)
+		}
+	}
+	sort.Ints(years)
+
+	a.mu.Lock()
+	a.years = years
+	a.mu.Unlock()
+	return nil
+}
+
+// path returns the archive file of a report year
+func (a *ArchiveStore) path(year int) string {
+	return filepath.Join(a.dir, fmt.Sprintf("incidents_%d.parquet", year))
+}
+
+// Years returns the archived report years, oldest first
+func (a *ArchiveStore) Years() []int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return append([]int(nil), a.years...)
+}
+
+// ListFiles describes each archive file
+func (a *ArchiveStore) ListFiles(ctx context.Context) ([]ArchiveFile, error) {
+	files := []ArchiveFile{}
+	for _, year := range a.Years() {
+		path := a.path(year)
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat archive file: %w", err)
+		}
+
+		file := ArchiveFile{Year: year, File: filepath.Base(path), SizeBytes: info.Size()}
+		if err := a.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM read_parquet("+sqlString(path)+")").Scan(&file.Incidents); err != nil {
+			return nil, fmt.Errorf("failed to count archived incidents for %d: %w", year, err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// ArchiveBefore moves incidents reported before the given date from the live database into the
+// archive file of their report year, merging with incidents archived earlier. Each year is
+// written to a temporary file first, so a failed run leaves the live table and archive intact.
+func (a *ArchiveStore) ArchiveBefore(ctx context.Context, before time.Time) (*ArchiveResult, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	result := &ArchiveResult{Before: before.Format("2006-01-02"), Years: []int{}}
+
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT CAST(YEAR(report_date) AS INTEGER), COUNT(*)
+		FROM incidents
+		WHERE report_date < ?
+		GROUP BY 1
+		ORDER BY 1
+	`, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incidents to archive: %w", err)
+	}
+	counts := map[int]int{}
+	var years []int
+	for rows.Next() {
+		var year, count int
+		if err := rows.Scan(&year, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan archive year: %w", err)
+		}
+		years = append(years, year)
+		counts[year] = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating archive years: %w", err)
+	}
+
+	for _, year := range years {
+		if err := a.archiveYear(ctx, year, before); err != nil {
+			return result, err
+		}
+		result.Archived += counts[year]
+		result.Years = append(result.Years, year)
+
+		if !containsInt(a.years, year) {
+			a.years = append(a.years, year)
+			sort.Ints(a.years)
+		}
+	}
+
+	return result, nil
+}
+
+// archiveYear writes the year's archive file and then removes the archived incidents from the
+// live table. Incidents already in the archive file are not written twice.
+func (a *ArchiveStore) archiveYear(ctx context.Context, year int, before time.Time) error {
+	final := a.path(year)
+	temp := final + ".tmp"
+
+	selection := fmt.Sprintf("SELECT * FROM main.incidents WHERE report_date < %s AND YEAR(report_date) = %d",
+		sqlString(before.Format("2006-01-02")), year)
+	source := selection
+	if _, err := os.Stat(final); err == nil {
+		source = fmt.Sprintf(`SELECT * FROM read_parquet(%s)
+			UNION ALL BY NAME
+			%s AND id NOT IN (SELECT id FROM read_parquet(%s))`, sqlString(final), selection, sqlString(final))
+	}
+
+	if _, err := a.db.ExecContext(ctx, fmt.Sprintf("COPY (%s) TO %s (FORMAT PARQUET)", source, sqlString(temp))); err != nil {
+		os.Remove(temp)
+		return fmt.Errorf("failed to write archive for %d: %w", year, err)
+	}
+	if err := os.Rename(temp, final); err != nil {
+		os.Remove(temp)
+		return fmt.Errorf("failed to replace archive for %d: %w", year, err)
+	}
+
+	if _, err := a.db.ExecContext(ctx, "DELETE FROM incidents WHERE report_date < ? AND YEAR(report_date) = ?", before, year); err != nil {
+		return fmt.Errorf("failed to remove archived incidents for %d: %w", year, err)
+	}
+	return nil
+}
+
+// federatedSource returns a query combining the live table with the archive files of every
+// year the date range overlaps, or "" when the range touches no archived year
+func (a *ArchiveStore) federatedSource(filters *TimelineFilters) string {
+	var paths []string
+	for _, year := range a.Years() {
+		if filters != nil && filters.StartDate != nil && year < filters.StartDate.Year() {
+			continue
+		}
+		if filters != nil && filters.EndDate != nil && year > filters.EndDate.Year() {
+			continue
+		}
+		paths = append(paths, sqlString(a.path(year)))
+	}
+	if len(paths) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(`
+			SELECT * FROM main.incidents
+			UNION ALL BY NAME
+			SELECT * FROM read_parquet([%s], union_by_name = true)`, strings.Join(paths, ", "))
+}
+
+// SetArchiveStore makes analytics federate queries across the live table and the archive
+// whenever the requested date range spans archived years. A nil store turns federation off.
+func (s *AnalyticsService) SetArchiveStore(archive *ArchiveStore) {
+	s.archive = archive
+}
+
+// SetFeatureFlags makes federation depend on the FlagArchiveFederation flag of each request's
+// tenant
+func (s *AnalyticsService) SetFeatureFlags(flags *FeatureFlagService) {
+	s.flags = flags
+}
+
+// federates reports whether queries made with ctx read the archive
+func (s *AnalyticsService) federates(ctx context.Context) bool {
+	if s.archive == nil {
+		return false
+	}
+	return s.flags == nil || s.flags.Enabled(ctx, FlagArchiveFederation)
+}
+
+// federate rewrites an analytics query so every reference to incidents also reads the archived
+// incidents in the filters' date range, and sees them through the request's sandbox. The live
+// table is shadowed by a common table expression of the same name, so queries need no other
+// changes.
+func (s *AnalyticsService) federate(ctx context.Context, query string, filters *TimelineFilters) string {
+	var source string
+	if s.federates(ctx) {
+		source = s.archive.federatedSource(filters)
+	}
+	source = SandboxFromContext(ctx).overlay(source)
+	if source == "" {
+		return query
+	}
+	source = "incidents AS (" + source + "\n\t\t)"
+
+	trimmed := strings.TrimSpace(query)
+	if len(trimmed) > 5 && strings.EqualFold(trimmed[:5], "WITH ") {
+		return "WITH " + source + ",\n" + trimmed[5:]
+	}
+	return "WITH " + source + "\n" + query
+}
+
+// sqlString quotes value as a SQL string literal. DuckDB cannot bind file paths as parameters
+// in COPY and read_parquet, and sandbox overlays are inlined into already numbered queries.
+func sqlString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// containsInt reports whether values contains value
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
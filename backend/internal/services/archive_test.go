@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestArchiveStore_Federation(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+
+	insert := func(uploadID string, reported ...time.Time) {
+		t.Helper()
+		var incidents []models.Incident
+		for i, date := range reported {
+			incident := diffTestIncident("", uploadID, fmt.Sprintf("INC-%s-%d", uploadID, i), "P2", "Closed")
+			incident.ReportDate = date
+			incidents = append(incidents, incident)
+		}
+		if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, uploadID); err != nil {
+			t.Fatalf("Failed to insert incidents: %v", err)
+		}
+	}
+	insert("upload-1",
+		time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	)
+
+	archive, err := NewArchiveStore(db, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	result, err := archive.ArchiveBefore(ctx, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to archive incidents: %v", err)
+	}
+	if result.Archived != 3 || len(result.Years) != 2 || result.Years[0] != 2022 || result.Years[1] != 2023 {
+		t.Fatalf("Expected 3 incidents archived across 2022 and 2023, got %+v", result)
+	}
+
+	total := func(service *AnalyticsService, filters *TimelineFilters) int {
+		t.Helper()
+		breakdown, err := service.GetPriorityAnalysis(ctx, filters)
+		if err != nil {
+			t.Fatalf("Failed to get priority analysis: %v", err)
+		}
+		count := 0
+		for _, priority := range breakdown {
+			count += priority.Count
+		}
+		return count
+	}
+
+	live := NewAnalyticsService(db)
+	if got := total(live, nil); got != 1 {
+		t.Errorf("Expected only the 2024 incident to stay live, got %d", got)
+	}
+
+	federated := NewAnalyticsService(db)
+	federated.SetArchiveStore(archive)
+	start2023 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	start2024 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		filters  *TimelineFilters
+		expected int
+	}{
+		{"all history", nil, 4},
+		{"range spanning live and archive", &TimelineFilters{StartDate: &start2023}, 3},
+		{"live range only", &TimelineFilters{StartDate: &start2024}, 1},
+		{"archive range only", &TimelineFilters{EndDate: &start2023}, 1},
+	}
+	for _, tt := range tests {
+		if got := total(federated, tt.filters); got != tt.expected {
+			t.Errorf("%s: expected %d incidents, got %d", tt.name, tt.expected, got)
+		}
+	}
+
+	// A second run merges into the existing year without duplicating what is already archived
+	insert("upload-2", time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC))
+	if _, err := archive.ArchiveBefore(ctx, start2024); err != nil {
+		t.Fatalf("Failed to archive incidents: %v", err)
+	}
+	files, err := archive.ListFiles(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list archive files: %v", err)
+	}
+	if len(files) != 2 || files[1].Year != 2023 || files[1].Incidents != 3 || files[1].File != "incidents_2023.parquet" {
+		t.Errorf("Expected 3 incidents archived for 2023, got %+v", files)
+	}
+	if got := total(federated, nil); got != 5 {
+		t.Errorf("Expected 5 incidents in total, got %d", got)
+	}
+
+	// The archive is picked up again when reopened
+	reopened, err := NewArchiveStore(db, archive.dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen archive: %v", err)
+	}
+	if years := reopened.Years(); len(years) != 2 {
+		t.Errorf("Expected 2 archived years after reopening, got %v", years)
+	}
+}
+
+func TestAnalyticsService_Federate(t *testing.T) {
+	service := NewAnalyticsService(nil)
+	query := "SELECT COUNT(*) FROM incidents"
+	if got := service.federate(context.Background(), query, nil); got != query {
+		t.Errorf("Expected queries to be left alone without an archive, got %q", got)
+	}
+
+	service.SetArchiveStore(&ArchiveStore{dir: "/data/archive", years: []int{2022}})
+	federated := service.federate(context.Background(), "\n\t\tWITH recent AS (SELECT * FROM incidents) SELECT COUNT(*) FROM recent", nil)
+	if !strings.HasPrefix(federated, "WITH incidents AS (") || !strings.Contains(federated, "'/data/archive/incidents_2022.parquet'") ||
+		!strings.Contains(federated, "),\nrecent AS (") {
+		t.Errorf("Expected the archive to be merged into the existing WITH clause, got %q", federated)
+	}
+
+	// The federation flag switches the archive off per tenant
+	flags := &FeatureFlagService{ttl: time.Hour, loadedAt: time.Now(), byKey: map[string]*FlagDefinition{
+		FlagArchiveFederation: {Key: FlagArchiveFederation, Default: true},
+	}, overrides: map[string]map[string]*storedFlag{
+		FlagArchiveFederation: {"acme": {enabled: false}},
+	}}
+	service.SetFeatureFlags(flags)
+	if got := service.federate(WithTenant(context.Background(), "acme"), query, nil); got != query {
+		t.Errorf("Expected no federation for a tenant with the flag off, got %q", got)
+	}
+	if got := service.federate(context.Background(), query, nil); got == query {
+		t.Errorf("Expected federation while the flag is on")
+	}
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ArtifactRebuildResult reports a rebuild of the data derived from the stored incidents
+type ArtifactRebuildResult struct {
+	// Views is the consistency check of the analytics views after they were recreated
+	Views *ViewConsistencyReport `json:"views"`
+	// Recategorization counts the incidents moved to another IT process group
+	Recategorization *RecategorizeResult `json:"recategorization"`
+	// Cache reports the analytics cache warm; nil when the server has no analytics cache
+	Cache    *CacheWarmResult `json:"cache,omitempty"`
+	Duration string           `json:"duration"`
+}
+
+// ArtifactRebuilder rebuilds everything derived from the stored incidents: the analytics views,
+// the IT process groups and the analytics cache. It is the recovery tool for derived data that
+// no longer matches the incidents, such as after rows were edited in the database directly.
+type ArtifactRebuilder struct {
+	views         *AnalyticsViewService
+	recategorizer *ITProcessRecategorizer
+}
+
+// NewArtifactRebuilder creates a new artifact rebuilder
+func NewArtifactRebuilder(db *sql.DB) *ArtifactRebuilder {
+	return &ArtifactRebuilder{
+		views:         NewAnalyticsViewService(db),
+		recategorizer: NewITProcessRecategorizer(db),
+	}
+}
+
+// Rebuild recreates the analytics views, re-runs the IT process categorization over every
+// incident, then clears cache and warms it again, so no cached result outlives the rebuild.
+// Each step starts once the one before succeeded; the steps are safe to run again, so a failed
+// rebuild is retried from the start. progress, when set, is called with the percentage done and
+// the current step.
+func (r *ArtifactRebuilder) Rebuild(ctx context.Context, cache CacheWarmer, progress func(percent int, message string)) (*ArtifactRebuildResult, error) {
+	start := time.Now()
+	if progress == nil {
+		progress = func(int, string) {}
+	}
+	result := &ArtifactRebuildResult{}
+
+	progress(5, "Rebuilding analytics views")
+	views, err := r.views.RebuildViews(ctx)
+	if err != nil {
+		return result, err
+	}
+	result.Views = views
+
+	progress(15, "Recategorizing incidents")
+	recategorization, err := r.recategorizer.Recategorize(ctx, nil, false, func(scanned, total int) {
+		percent := 15
+		if total > 0 {
+			percent += scanned * 70 / total
+		}
+		progress(percent, fmt.Sprintf("Recategorized %d/%d incidents", scanned, total))
+	})
+	if recategorization != nil {
+		result.Recategorization = recategorization
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to recategorize incidents: %w", err)
+	}
+
+	if cache != nil {
+		progress(85, "Clearing and warming analytics cache")
+		if clearer, ok := cache.(cacheClearer); ok {
+			clearer.ClearCache()
+		}
+		warm, err := cache.WarmCache(ctx, time.Now())
+		result.Cache = warm
+		if err != nil {
+			return result, fmt.Errorf("failed to warm analytics cache: %w", err)
+		}
+	}
+
+	result.Duration = time.Since(start).String()
+	return result, nil
+}
@@ -1,13 +1,19 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 
 	"incident-management-system/internal/models"
 )
 
+// SimpleAutomationAnalyzerVersion identifies the current rule set; bump it when scoring changes
+const SimpleAutomationAnalyzerVersion = "simple-v2"
+
 // SimpleAutomationAnalyzer implements basic automation analysis
 type SimpleAutomationAnalyzer struct {
 	automationKeywords    map[string]float64
@@ -15,6 +21,9 @@ type SimpleAutomationAnalyzer struct {
 	itProcessGroups       map[string][]string
 	automationThresholds  map[string]float64
 	resolutionTimeWeights map[string]float64
+
+	// mu guards the keyword maps, which are reloaded from the database between uploads
+	mu sync.RWMutex
 }
 
 // NewSimpleAutomationAnalyzer creates a new automation analyzer
@@ -205,19 +214,32 @@ func (a *SimpleAutomationAnalyzer) initializeITProcessGroups() {
 	a.itProcessGroups = itProcessGroups
 }
 
+// defaultAutomationThresholds are the automation feasibility thresholds by IT process group: an
+// incident is feasible to automate when its score reaches its group's threshold
+var defaultAutomationThresholds = map[string]float64{
+	"Infrastructure":      0.5, // High automation potential
+	"Monitoring":          0.4, // Very high automation potential
+	"Backup & Recovery":   0.5, // High automation potential
+	"Change Management":   0.5, // Medium automation potential
+	"Network Operations":  0.5, // Medium-high automation potential
+	"Application Support": 0.4, // Lower automation potential
+	"Security":            0.6, // Medium automation potential
+	"User Support":        0.3, // Lower automation potential (more human interaction)
+}
+
+// AutomationThresholds returns the automation feasibility threshold of each IT process group
+func AutomationThresholds() map[string]float64 {
+	thresholds := make(map[string]float64, len(defaultAutomationThresholds))
+	for group, threshold := range defaultAutomationThresholds {
+		thresholds[group] = threshold
+	}
+	return thresholds
+}
+
 // initializeThresholds sets up automation thresholds and weights
 func (a *SimpleAutomationAnalyzer) initializeThresholds() {
 	// Automation feasibility thresholds by IT process group
-	automationThresholds := map[string]float64{
-		"Infrastructure":     0.5, // High automation potential
-		"Monitoring":         0.4, // Very high automation potential
-		"Backup & Recovery":  0.5, // High automation potential
-		"Change Management":  0.5, // Medium automation potential
-		"Network Operations": 0.5, // Medium-high automation potential
-		"Application Support": 0.4, // Lower automation potential
-		"Security":           0.6, // Medium automation potential
-		"User Support":       0.3, // Lower automation potential (more human interaction)
-	}
+	automationThresholds := AutomationThresholds()
 
 	// Resolution time weights (shorter times suggest more automation potential)
 	resolutionTimeWeights := map[string]float64{
@@ -319,6 +341,8 @@ func (a *SimpleAutomationAnalyzer) analyzeTextContent(incident *models.Incident)
 	var matchedKeywords int
 
 	// Score automation keywords
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	for _, token := range tokens {
 		if score, exists := a.automationKeywords[token]; exists {
 			totalScore += score
@@ -552,11 +576,14 @@ func (a *SimpleAutomationAnalyzer) generateReasons(textScore float64, itProcessG
 	return reasons
 }
 
+// tokenPunctuation matches the characters tokenizeText strips. It is compiled once since text
+// is tokenized for every incident analyzed or compared.
+var tokenPunctuation = regexp.MustCompile(`[^\p{L}\p{N}\s-]`)
+
 // tokenizeText tokenizes text for keyword analysis
 func (a *SimpleAutomationAnalyzer) tokenizeText(text string) []string {
 	// Remove punctuation and split into words
-	reg := regexp.MustCompile(`[^\p{L}\p{N}\s-]`)
-	cleanText := reg.ReplaceAllString(text, " ")
+	cleanText := tokenPunctuation.ReplaceAllString(text, " ")
 	
 	// Split into words and filter
 	words := strings.Fields(cleanText)
@@ -572,14 +599,22 @@ func (a *SimpleAutomationAnalyzer) tokenizeText(text string) []string {
 	return tokens
 }
 
+// Version returns the version of the automation rules
+func (a *SimpleAutomationAnalyzer) Version() string {
+	return SimpleAutomationAnalyzerVersion
+}
+
 // GetAutomationStats returns statistics about the automation analyzer
 func (a *SimpleAutomationAnalyzer) GetAutomationStats() map[string]interface{} {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	return map[string]interface{}{
 		"automation_keywords_count": len(a.automationKeywords),
 		"manual_keywords_count":     len(a.manualKeywords),
 		"it_process_groups_count":   len(a.itProcessGroups),
 		"it_process_groups":         a.getITProcessGroupNames(),
 		"analyzer_type":             "simple_rule_based",
+		"analyzer_version":          SimpleAutomationAnalyzerVersion,
 	}
 }
 
@@ -594,6 +629,9 @@ func (a *SimpleAutomationAnalyzer) getITProcessGroupNames() []string {
 
 // AddCustomKeywords allows adding custom automation keywords
 func (a *SimpleAutomationAnalyzer) AddCustomKeywords(automation, manual map[string]float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	for word, score := range automation {
 		a.automationKeywords[strings.ToLower(word)] = score
 	}
@@ -603,6 +641,59 @@ func (a *SimpleAutomationAnalyzer) AddCustomKeywords(automation, manual map[stri
 	}
 }
 
+// LoadKeywords resets the keyword lists to the built-in defaults and applies the custom keywords
+// stored in the automation_keywords table, so deleted keywords stop affecting scores
+func (a *SimpleAutomationAnalyzer) LoadKeywords(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, "SELECT keyword, kind, weight FROM automation_keywords")
+	if err != nil {
+		return fmt.Errorf("failed to query automation keywords: %w", err)
+	}
+	defer rows.Close()
+
+	automation := make(map[string]float64)
+	manual := make(map[string]float64)
+	for rows.Next() {
+		var keyword, kind string
+		var weight float64
+		if err := rows.Scan(&keyword, &kind, &weight); err != nil {
+			return fmt.Errorf("failed to scan automation keyword: %w", err)
+		}
+		if kind == AutomationKeywordKindManual {
+			manual[keyword] = weight
+		} else {
+			automation[keyword] = weight
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating automation keywords: %w", err)
+	}
+
+	a.mu.Lock()
+	a.initializeKeywords()
+	a.mu.Unlock()
+
+	a.AddCustomKeywords(automation, manual)
+	return nil
+}
+
+// clone returns an independent copy of the analyzer, used to score proposed keyword changes
+func (a *SimpleAutomationAnalyzer) clone() *SimpleAutomationAnalyzer {
+	clone := NewSimpleAutomationAnalyzer()
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	clone.automationKeywords = make(map[string]float64, len(a.automationKeywords))
+	for word, score := range a.automationKeywords {
+		clone.automationKeywords[word] = score
+	}
+	clone.manualKeywords = make(map[string]float64, len(a.manualKeywords))
+	for word, score := range a.manualKeywords {
+		clone.manualKeywords[word] = score
+	}
+
+	return clone
+}
+
 // ValidateAutomationScore ensures automation scores are within valid range
 func ValidateAutomationScore(score float64) error {
 	if score < 0.0 || score > 1.0 {
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// DefaultCalibrationSteps is how many equal steps the candidate thresholds of the calibration
+	// report split scores into without explicit candidates: every 0.05
+	DefaultCalibrationSteps = 20
+
+	// MinCalibrationLabels is how many incidents of a group need automation feedback saying
+	// whether they are feasible before the calibration report suggests a threshold for it
+	MinCalibrationLabels = 10
+)
+
+// ThresholdPoint is one point of a group's sensitivity curve: what a feasibility threshold would
+// flag as feasible
+type ThresholdPoint struct {
+	Threshold   float64 `json:"threshold"`
+	Current     bool    `json:"current,omitempty"` // the group's threshold today
+	Feasible    int     `json:"feasible"`
+	FeasiblePct float64 `json:"feasible_pct"`
+	// Flipped counts the incidents whose feasibility differs from what the current threshold gives
+	Flipped int `json:"flipped"`
+	// Agreement is the percentage of feedback-labeled incidents the threshold classifies as their
+	// feedback says; nil for groups without labels
+	Agreement *float64 `json:"agreement"`
+}
+
+// ThresholdCalibration is the sensitivity of one IT process group's feasibility to its threshold
+type ThresholdCalibration struct {
+	ITProcessGroup   string           `json:"it_process_group"`
+	CurrentThreshold *float64         `json:"current_threshold"` // nil for groups without a threshold
+	Count            int              `json:"count"`
+	Labeled          int              `json:"labeled"`
+	Curve            []ThresholdPoint `json:"curve"`
+	// SuggestedThreshold is the candidate agreeing best with the feedback, the one closest to the
+	// current threshold on ties; nil with fewer than MinCalibrationLabels labeled incidents
+	SuggestedThreshold *float64 `json:"suggested_threshold"`
+}
+
+// AutomationCalibration is the threshold calibration report of every IT process group
+type AutomationCalibration struct {
+	Candidates []float64              `json:"candidates"`
+	MinLabels  int                    `json:"min_labels"`
+	Groups     []ThresholdCalibration `json:"groups"`
+}
+
+// calibrationScore counts the incidents of a group with one automation score, scaled by
+// scoreScale, and their feedback labels
+type calibrationScore struct {
+	score, count, labeledFeasible, labeledNotFeasible int
+}
+
+// GetAutomationCalibration reports, for each IT process group, how many incidents each candidate
+// threshold would flag as feasible and how many of them would flip compared to the group's current
+// threshold, which is added to its curve. Where analysts recorded automation feedback saying
+// whether incidents are feasible, each candidate's agreement with it is reported and the best
+// agreeing candidate suggested, so thresholds can be set from data. Only the latest verdict per
+// incident counts.
+func (s *AnalyticsService) GetAutomationCalibration(ctx context.Context, filters *TimelineFilters, candidates []float64) (*AutomationCalibration, error) {
+	query := fmt.Sprintf(`
+		WITH labels AS (
+			SELECT incident_id AS labeled_id, actual AS label
+			FROM analyzer_feedback
+			WHERE analyzer = '%s'
+			QUALIFY ROW_NUMBER() OVER (PARTITION BY incident_id ORDER BY created_at DESC) = 1
+		)
+		SELECT
+			it_process_group,
+			CAST(ROUND(automation_score * %d) AS INTEGER) AS score,
+			COUNT(*) AS incident_count,
+			COUNT(CASE WHEN label = 'true' THEN 1 END) AS labeled_feasible,
+			COUNT(CASE WHEN label = 'false' THEN 1 END) AS labeled_not_feasible
+		FROM incidents
+		LEFT JOIN labels ON labels.labeled_id = incidents.id
+		WHERE automation_score IS NOT NULL AND it_process_group IS NOT NULL`, FeedbackAnalyzerAutomation, scoreScale)
+
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query += whereClause
+	query += " GROUP BY it_process_group, score ORDER BY it_process_group, score"
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation calibration: %w", err)
+	}
+	defer rows.Close()
+
+	scores := map[string][]calibrationScore{}
+	for rows.Next() {
+		var group string
+		var entry calibrationScore
+		if err := rows.Scan(&group, &entry.score, &entry.count, &entry.labeledFeasible, &entry.labeledNotFeasible); err != nil {
+			return nil, fmt.Errorf("failed to scan automation calibration row: %w", err)
+		}
+		scores[group] = append(scores[group], entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating automation calibration rows: %w", err)
+	}
+
+	thresholds := AutomationThresholds()
+	calibration := &AutomationCalibration{
+		Candidates: candidates,
+		MinLabels:  MinCalibrationLabels,
+		Groups:     make([]ThresholdCalibration, 0, len(scores)),
+	}
+	for group, entries := range scores {
+		var current *float64
+		if threshold, ok := thresholds[group]; ok {
+			current = &threshold
+		}
+		calibration.Groups = append(calibration.Groups, calibrateGroup(group, current, entries, candidates))
+	}
+	sort.Slice(calibration.Groups, func(i, j int) bool {
+		if calibration.Groups[i].Count != calibration.Groups[j].Count {
+			return calibration.Groups[i].Count > calibration.Groups[j].Count
+		}
+		return calibration.Groups[i].ITProcessGroup < calibration.Groups[j].ITProcessGroup
+	})
+	return calibration, nil
+}
+
+// calibrateGroup computes the sensitivity curve of one group over the candidates and its current
+// threshold
+func calibrateGroup(group string, current *float64, entries []calibrationScore, candidates []float64) ThresholdCalibration {
+	calibration := ThresholdCalibration{ITProcessGroup: group, CurrentThreshold: current, Curve: []ThresholdPoint{}}
+	for _, entry := range entries {
+		calibration.Count += entry.count
+		calibration.Labeled += entry.labeledFeasible + entry.labeledNotFeasible
+	}
+
+	points := append([]float64{}, candidates...)
+	currentScaled := -1
+	if current != nil {
+		currentScaled = int(math.Round(*current * scoreScale))
+		points = append(points, *current)
+	}
+	sort.Float64s(points)
+
+	seen := map[int]bool{}
+	best := -1
+	for _, threshold := range points {
+		scaled := int(math.Round(threshold * scoreScale))
+		if seen[scaled] {
+			continue
+		}
+		seen[scaled] = true
+
+		point := ThresholdPoint{Threshold: threshold, Current: scaled == currentScaled}
+		agreeing := 0
+		for _, entry := range entries {
+			feasible := entry.score >= scaled
+			if feasible {
+				point.Feasible += entry.count
+				agreeing += entry.labeledFeasible
+			} else {
+				agreeing += entry.labeledNotFeasible
+			}
+			if currentScaled >= 0 && feasible != (entry.score >= currentScaled) {
+				point.Flipped += entry.count
+			}
+		}
+		if calibration.Count > 0 {
+			point.FeasiblePct = float64(point.Feasible) * 100.0 / float64(calibration.Count)
+		}
+		if calibration.Labeled > 0 {
+			agreement := float64(agreeing) * 100.0 / float64(calibration.Labeled)
+			point.Agreement = &agreement
+		}
+		if point.Agreement != nil && (best < 0 || betterCalibration(point, calibration.Curve[best], current)) {
+			best = len(calibration.Curve)
+		}
+		calibration.Curve = append(calibration.Curve, point)
+	}
+
+	if best >= 0 && calibration.Labeled >= MinCalibrationLabels {
+		suggested := calibration.Curve[best].Threshold
+		calibration.SuggestedThreshold = &suggested
+	}
+	return calibration
+}
+
+// betterCalibration reports whether point agrees better with the feedback than best, or as well
+// and closer to the current threshold
+func betterCalibration(point, best ThresholdPoint, current *float64) bool {
+	if *point.Agreement != *best.Agreement {
+		return *point.Agreement > *best.Agreement
+	}
+	return current != nil && math.Abs(point.Threshold-*current) < math.Abs(best.Threshold-*current)
+}
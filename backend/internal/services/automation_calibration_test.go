@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"incident-management-system/internal/models"
+)
+
+func TestAnalyticsService_GetAutomationCalibration(t *testing.T) {
+	// Monitoring has a threshold of 0.4; analysts marked the 0.45 incidents not feasible and
+	// confirmed the 0.2 and 0.9 ones
+	var incidents []models.Incident
+	for i, score := range []float64{0.2, 0.3, 0.45, 0.45, 0.45, 0.45, 0.45, 0.45, 0.45, 0.45, 0.45, 0.45, 0.7, 0.9} {
+		incidents = append(incidents, automationTestIncident(fmt.Sprint(i), "Monitoring", score, score >= 0.4))
+	}
+	incidents = append(incidents, automationTestIncident("custom", "Custom Group", 0.5, true))
+
+	dbWrapper, db := newSentimentTestDB(t, incidents)
+	defer dbWrapper.Close()
+	ctx := context.Background()
+	feedback := NewFeedbackService(db)
+	for i := 2; i < 12; i++ {
+		if _, err := feedback.RecordFeedback(ctx, fmt.Sprint(i), FeedbackAnalyzerAutomation, false, "", ""); err != nil {
+			t.Fatalf("RecordFeedback() error = %v", err)
+		}
+	}
+	for _, id := range []string{"0", "13"} {
+		if _, err := feedback.RecordFeedback(ctx, id, FeedbackAnalyzerAutomation, true, "", ""); err != nil {
+			t.Fatalf("RecordFeedback() error = %v", err)
+		}
+	}
+
+	calibration, err := NewAnalyticsService(db).GetAutomationCalibration(ctx, nil, []float64{0.25, 0.5, 0.75})
+	if err != nil {
+		t.Fatalf("GetAutomationCalibration() error = %v", err)
+	}
+	if len(calibration.Groups) != 2 || calibration.Groups[0].ITProcessGroup != "Monitoring" {
+		t.Fatalf("expected groups by size, got %+v", calibration.Groups)
+	}
+
+	monitoring := calibration.Groups[0]
+	if monitoring.Count != 14 || monitoring.Labeled != 12 {
+		t.Errorf("unexpected Monitoring counts: %+v", monitoring)
+	}
+	if len(monitoring.Curve) != 4 || !monitoring.Curve[1].Current || monitoring.Curve[1].Threshold != 0.4 {
+		t.Fatalf("expected the current threshold added to the curve, got %+v", monitoring.Curve)
+	}
+	want := []struct{ feasible, flipped int }{{13, 1}, {12, 0}, {2, 10}, {1, 11}}
+	for i, point := range monitoring.Curve {
+		if point.Feasible != want[i].feasible || point.Flipped != want[i].flipped {
+			t.Errorf("point %v: feasible %d flipped %d, want %d and %d", point.Threshold, point.Feasible, point.Flipped, want[i].feasible, want[i].flipped)
+		}
+	}
+	// The current threshold wrongly flags the ten 0.45 incidents; 0.5 and 0.75 agree with every
+	// label and 0.5 is closer to the current threshold
+	if agreement := monitoring.Curve[1].Agreement; agreement == nil || *agreement != float64(2)*100/12 {
+		t.Errorf("unexpected agreement at 0.4: %v", agreement)
+	}
+	if agreement := monitoring.Curve[3].Agreement; agreement == nil || *agreement != 100 {
+		t.Errorf("unexpected agreement at 0.75: %v", agreement)
+	}
+	if monitoring.SuggestedThreshold == nil || *monitoring.SuggestedThreshold != 0.5 {
+		t.Errorf("expected 0.5 suggested, got %v", monitoring.SuggestedThreshold)
+	}
+
+	custom := calibration.Groups[1]
+	if custom.CurrentThreshold != nil || custom.SuggestedThreshold != nil || len(custom.Curve) != 3 || custom.Curve[0].Agreement != nil {
+		t.Errorf("unexpected calibration of a group without threshold or labels: %+v", custom)
+	}
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// DefaultAutomationBuckets is how many equal-width buckets the automation score distribution
+	// splits scores into without explicit boundaries
+	DefaultAutomationBuckets = 10
+
+	// MaxScoreBoundaries caps the bucket boundaries of the automation score distribution
+	MaxScoreBoundaries = 99
+
+	// NearThresholdMargin is how close to its group's feasibility threshold a score counts as
+	// near it
+	NearThresholdMargin = 0.05
+
+	// scoreScale is the precision scores are compared at: three decimal places
+	scoreScale = 1000
+)
+
+// ScoreBucket counts the incidents whose automation score falls in [Min, Max); the last bucket
+// includes a score of 1
+type ScoreBucket struct {
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+	Count       int     `json:"count"`
+	Feasible    int     `json:"feasible"`
+	NotFeasible int     `json:"not_feasible"`
+}
+
+// AutomationGroupDistribution is the automation score distribution of one IT process group
+type AutomationGroupDistribution struct {
+	ITProcessGroup string        `json:"it_process_group"`
+	Threshold      *float64      `json:"threshold"` // nil for groups without a threshold
+	Count          int           `json:"count"`
+	Feasible       int           `json:"feasible"`
+	NearThreshold  int           `json:"near_threshold"` // scores within NearThresholdMargin of the threshold
+	BelowThreshold int           `json:"below_threshold"`
+	Buckets        []ScoreBucket `json:"buckets"`
+}
+
+// AutomationDistribution is the distribution of automation scores across incidents, overall and
+// by IT process group, split by the feasibility flag
+type AutomationDistribution struct {
+	Boundaries          []float64                     `json:"boundaries"`
+	NearThresholdMargin float64                       `json:"near_threshold_margin"`
+	Total               int                           `json:"total"`
+	Feasible            int                           `json:"feasible"`
+	Buckets             []ScoreBucket                 `json:"buckets"`
+	Groups              []AutomationGroupDistribution `json:"groups"`
+}
+
+// EqualScoreBoundaries returns the boundaries splitting scores from 0 to 1 into equal-width
+// buckets, rounded to three decimal places
+func EqualScoreBoundaries(buckets int) []float64 {
+	if buckets < 1 {
+		buckets = DefaultAutomationBuckets
+	}
+	boundaries := make([]float64, 0, buckets-1)
+	for i := 1; i < buckets; i++ {
+		boundary := math.Round(float64(i)/float64(buckets)*scoreScale) / scoreScale
+		if len(boundaries) == 0 || boundary > boundaries[len(boundaries)-1] {
+			boundaries = append(boundaries, boundary)
+		}
+	}
+	return boundaries
+}
+
+// GetAutomationDistribution returns how automation scores spread across the buckets between
+// boundaries, ascending scores strictly between 0 and 1, so teams can see whether scores
+// cluster near the feasibility thresholds. Scores are compared at three decimal places.
+func (s *AnalyticsService) GetAutomationDistribution(ctx context.Context, filters *TimelineFilters, boundaries []float64) (*AutomationDistribution, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			it_process_group,
+			CAST(ROUND(automation_score * %d) AS INTEGER) AS score,
+			COUNT(*) AS incident_count,
+			COUNT(CASE WHEN automation_feasible = true THEN 1 END) AS feasible_count
+		FROM incidents
+		WHERE automation_score IS NOT NULL AND it_process_group IS NOT NULL`, scoreScale)
+
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query += whereClause
+	query += " GROUP BY it_process_group, score ORDER BY it_process_group, score"
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation score distribution: %w", err)
+	}
+	defer rows.Close()
+
+	thresholds := AutomationThresholds()
+	distribution := &AutomationDistribution{
+		Boundaries:          boundaries,
+		NearThresholdMargin: NearThresholdMargin,
+		Buckets:             scoreBuckets(boundaries),
+		Groups:              []AutomationGroupDistribution{},
+	}
+	groups := map[string]*AutomationGroupDistribution{}
+	for rows.Next() {
+		var group string
+		var score, count, feasible int
+		if err := rows.Scan(&group, &score, &count, &feasible); err != nil {
+			return nil, fmt.Errorf("failed to scan automation score distribution row: %w", err)
+		}
+
+		entry, ok := groups[group]
+		if !ok {
+			entry = &AutomationGroupDistribution{ITProcessGroup: group, Buckets: scoreBuckets(boundaries)}
+			if threshold, ok := thresholds[group]; ok {
+				entry.Threshold = &threshold
+			}
+			groups[group] = entry
+		}
+		entry.Count += count
+		entry.Feasible += feasible
+		if entry.Threshold != nil {
+			threshold := int(math.Round(*entry.Threshold * scoreScale))
+			if abs(score-threshold) <= int(math.Round(NearThresholdMargin*scoreScale)) {
+				entry.NearThreshold += count
+			}
+			if score < threshold {
+				entry.BelowThreshold += count
+			}
+		}
+
+		bucket := scoreBucket(boundaries, score)
+		for _, buckets := range [][]ScoreBucket{entry.Buckets, distribution.Buckets} {
+			buckets[bucket].Count += count
+			buckets[bucket].Feasible += feasible
+			buckets[bucket].NotFeasible += count - feasible
+		}
+		distribution.Total += count
+		distribution.Feasible += feasible
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating automation score distribution rows: %w", err)
+	}
+
+	for _, entry := range groups {
+		distribution.Groups = append(distribution.Groups, *entry)
+	}
+	sort.Slice(distribution.Groups, func(i, j int) bool {
+		if distribution.Groups[i].Count != distribution.Groups[j].Count {
+			return distribution.Groups[i].Count > distribution.Groups[j].Count
+		}
+		return distribution.Groups[i].ITProcessGroup < distribution.Groups[j].ITProcessGroup
+	})
+	return distribution, nil
+}
+
+// scoreBuckets returns the empty buckets between boundaries, from 0 to 1
+func scoreBuckets(boundaries []float64) []ScoreBucket {
+	edges := append(append([]float64{0}, boundaries...), 1)
+	buckets := make([]ScoreBucket, len(edges)-1)
+	for i := range buckets {
+		buckets[i] = ScoreBucket{Min: edges[i], Max: edges[i+1]}
+	}
+	return buckets
+}
+
+// scoreBucket returns the index of the bucket holding a score scaled by scoreScale
+func scoreBucket(boundaries []float64, score int) int {
+	return sort.Search(len(boundaries), func(i int) bool {
+		return int(math.Round(boundaries[i]*scoreScale)) > score
+	})
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
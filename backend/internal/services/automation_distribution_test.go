@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"incident-management-system/internal/models"
+)
+
+// automationTestIncident builds an incident with the given automation score and feasibility
+func automationTestIncident(id, group string, score float64, feasible bool) models.Incident {
+	incident := diffTestIncident(id, "upload-1", "INC-"+id, "P3", "Closed")
+	incident.ITProcessGroup = group
+	incident.AutomationScore = &score
+	incident.AutomationFeasible = &feasible
+	return incident
+}
+
+func TestEqualScoreBoundaries(t *testing.T) {
+	if got := EqualScoreBoundaries(4); !reflect.DeepEqual(got, []float64{0.25, 0.5, 0.75}) {
+		t.Errorf("EqualScoreBoundaries(4) = %v", got)
+	}
+	if got := EqualScoreBoundaries(3); !reflect.DeepEqual(got, []float64{0.333, 0.667}) {
+		t.Errorf("EqualScoreBoundaries(3) = %v", got)
+	}
+	if got := EqualScoreBoundaries(1); len(got) != 0 {
+		t.Errorf("expected a single bucket without boundaries, got %v", got)
+	}
+}
+
+func TestAnalyticsService_GetAutomationDistribution(t *testing.T) {
+	dbWrapper, db := newSentimentTestDB(t, []models.Incident{
+		automationTestIncident("1", "Monitoring", 0.38, false),
+		automationTestIncident("2", "Monitoring", 0.42, true),
+		automationTestIncident("3", "Monitoring", 0.9, true),
+		automationTestIncident("4", "User Support", 0.1, false),
+		automationTestIncident("5", "User Support", 0.5, true),
+		automationTestIncident("6", "Custom Group", 1, true),
+	})
+	defer dbWrapper.Close()
+
+	distribution, err := NewAnalyticsService(db).GetAutomationDistribution(context.Background(), nil, []float64{0.4, 0.5})
+	if err != nil {
+		t.Fatalf("GetAutomationDistribution() error = %v", err)
+	}
+
+	if distribution.Total != 6 || distribution.Feasible != 4 {
+		t.Errorf("unexpected totals: %+v", distribution)
+	}
+	want := []ScoreBucket{
+		{Min: 0, Max: 0.4, Count: 2, Feasible: 0, NotFeasible: 2},
+		{Min: 0.4, Max: 0.5, Count: 1, Feasible: 1, NotFeasible: 0},
+		{Min: 0.5, Max: 1, Count: 3, Feasible: 3, NotFeasible: 0},
+	}
+	if !reflect.DeepEqual(distribution.Buckets, want) {
+		t.Errorf("Buckets = %+v, want %+v", distribution.Buckets, want)
+	}
+
+	if len(distribution.Groups) != 3 || distribution.Groups[0].ITProcessGroup != "Monitoring" {
+		t.Fatalf("expected groups by size, got %+v", distribution.Groups)
+	}
+	monitoring := distribution.Groups[0]
+	if monitoring.Threshold == nil || *monitoring.Threshold != 0.4 || monitoring.NearThreshold != 2 || monitoring.BelowThreshold != 1 {
+		t.Errorf("unexpected Monitoring distribution: %+v", monitoring)
+	}
+	if counts := []int{monitoring.Buckets[0].Count, monitoring.Buckets[1].Count, monitoring.Buckets[2].Count}; !reflect.DeepEqual(counts, []int{1, 1, 1}) {
+		t.Errorf("unexpected Monitoring buckets: %v", counts)
+	}
+	for _, group := range distribution.Groups {
+		if group.ITProcessGroup == "Custom Group" && group.Threshold != nil {
+			t.Errorf("expected no threshold for a group the analyzer does not know, got %v", *group.Threshold)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/models"
+)
+
+// Automation keyword kinds. Automation keywords usually carry positive weights and manual
+// keywords negative ones, but both are summed the same way when scoring text.
+const (
+	AutomationKeywordKindAutomation = "automation"
+	AutomationKeywordKindManual     = "manual"
+)
+
+// maxKeywordPreviewSamples caps the incidents listed in a keyword impact preview when no limit is given
+const maxKeywordPreviewSamples = 20
+
+// ErrInvalidAutomationKeyword is returned when a keyword is not a single token the analyzer can match
+var ErrInvalidAutomationKeyword = errors.New("keyword must be a single word of at least two characters")
+
+// AutomationKeyword is a custom keyword weight persisted for automation analysis
+type AutomationKeyword struct {
+	Keyword   string    `json:"keyword"`
+	Kind      string    `json:"kind"`
+	Weight    float64   `json:"weight"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// KeywordImpactSample is one incident whose feasibility would change with a proposed keyword
+type KeywordImpactSample struct {
+	IncidentID       string  `json:"incident_id"`
+	ApplicationName  string  `json:"application_name"`
+	BriefDescription string  `json:"brief_description"`
+	CurrentScore     float64 `json:"current_score"`
+	ProposedScore    float64 `json:"proposed_score"`
+	ProposedFeasible bool    `json:"proposed_feasible"`
+}
+
+// KeywordImpactPreview summarizes how a proposed keyword would change automation feasibility
+// across historical incidents, without saving the keyword. Samples are the most recent changes.
+type KeywordImpactPreview struct {
+	Keyword           string                `json:"keyword"`
+	Kind              string                `json:"kind"`
+	Weight            float64               `json:"weight"`
+	CurrentWeight     *float64              `json:"current_weight,omitempty"`
+	IncidentsAnalyzed int                   `json:"incidents_analyzed"`
+	MatchingIncidents int                   `json:"matching_incidents"`
+	BecameFeasible    int                   `json:"became_feasible"`
+	BecameInfeasible  int                   `json:"became_infeasible"`
+	ScoreChanged      int                   `json:"score_changed"`
+	Samples           []KeywordImpactSample `json:"samples"`
+}
+
+// IsValidAutomationKeywordKind reports whether kind is a known keyword kind
+func IsValidAutomationKeywordKind(kind string) bool {
+	return kind == AutomationKeywordKindAutomation || kind == AutomationKeywordKindManual
+}
+
+// AutomationKeywordService manages custom automation keywords and previews their impact
+type AutomationKeywordService struct {
+	db *sql.DB
+}
+
+// NewAutomationKeywordService creates a new AutomationKeywordService instance
+func NewAutomationKeywordService(db *sql.DB) *AutomationKeywordService {
+	return &AutomationKeywordService{db: db}
+}
+
+// normalizeAutomationKeyword lowercases a keyword and checks it tokenizes to itself,
+// since the analyzer only ever matches single tokens
+func normalizeAutomationKeyword(keyword string) (string, error) {
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	tokens := (&SimpleAutomationAnalyzer{}).tokenizeText(keyword)
+	if len(tokens) != 1 || tokens[0] != keyword {
+		return "", ErrInvalidAutomationKeyword
+	}
+	return keyword, nil
+}
+
+// ListKeywords returns all custom automation keywords ordered by kind and keyword
+func (s *AutomationKeywordService) ListKeywords(ctx context.Context) ([]AutomationKeyword, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT keyword, kind, weight, updated_at
+		FROM automation_keywords
+		ORDER BY kind, keyword
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation keywords: %w", err)
+	}
+	defer rows.Close()
+
+	keywords := []AutomationKeyword{}
+	for rows.Next() {
+		var keyword AutomationKeyword
+		if err := rows.Scan(&keyword.Keyword, &keyword.Kind, &keyword.Weight, &keyword.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan automation keyword: %w", err)
+		}
+		keywords = append(keywords, keyword)
+	}
+
+	return keywords, rows.Err()
+}
+
+// SaveKeyword creates or replaces a custom automation keyword. The keyword takes effect
+// from the next processed upload.
+func (s *AutomationKeywordService) SaveKeyword(ctx context.Context, kind, keyword string, weight float64) (*AutomationKeyword, error) {
+	if !IsValidAutomationKeywordKind(kind) {
+		return nil, fmt.Errorf("invalid automation keyword kind: %s", kind)
+	}
+	keyword, err := normalizeAutomationKeyword(keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := &AutomationKeyword{
+		Keyword:   keyword,
+		Kind:      kind,
+		Weight:    weight,
+		UpdatedAt: time.Now(),
+	}
+
+	query := `
+		INSERT OR REPLACE INTO automation_keywords (keyword, kind, weight, updated_at)
+		VALUES (?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, saved.Keyword, saved.Kind, saved.Weight, saved.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save automation keyword: %w", err)
+	}
+
+	return saved, nil
+}
+
+// DeleteKeyword removes a custom automation keyword, returning sql.ErrNoRows when it does not exist.
+// A deleted keyword that overrode a built-in one falls back to the built-in weight.
+func (s *AutomationKeywordService) DeleteKeyword(ctx context.Context, kind, keyword string) error {
+	result, err := s.db.ExecContext(ctx,
+		"DELETE FROM automation_keywords WHERE kind = ? AND keyword = ?",
+		kind, strings.ToLower(strings.TrimSpace(keyword)))
+	if err != nil {
+		return fmt.Errorf("failed to delete automation keyword: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// PreviewKeyword re-scores historical incidents with and without a proposed keyword weight and
+// reports how many would change automation feasibility. Only incidents containing the keyword
+// can change, so the rest are counted as analyzed but not re-scored.
+func (s *AutomationKeywordService) PreviewKeyword(ctx context.Context, kind, keyword string, weight float64, filters *TimelineFilters) (*KeywordImpactPreview, error) {
+	if !IsValidAutomationKeywordKind(kind) {
+		return nil, fmt.Errorf("invalid automation keyword kind: %s", kind)
+	}
+	keyword, err := normalizeAutomationKeyword(keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	current := NewSimpleAutomationAnalyzer()
+	if err := current.LoadKeywords(ctx, s.db); err != nil {
+		return nil, err
+	}
+	proposed := current.clone()
+	if kind == AutomationKeywordKindManual {
+		proposed.AddCustomKeywords(nil, map[string]float64{keyword: weight})
+	} else {
+		proposed.AddCustomKeywords(map[string]float64{keyword: weight}, nil)
+	}
+
+	preview := &KeywordImpactPreview{
+		Keyword: keyword,
+		Kind:    kind,
+		Weight:  weight,
+		Samples: []KeywordImpactSample{},
+	}
+	currentKeywords := current.automationKeywords
+	if kind == AutomationKeywordKindManual {
+		currentKeywords = current.manualKeywords
+	}
+	if existing, ok := currentKeywords[keyword]; ok {
+		preview.CurrentWeight = &existing
+	}
+
+	query := `
+		SELECT incident_id, COALESCE(application_name, ''), COALESCE(brief_description, ''),
+			COALESCE(description, ''), COALESCE(resolution_notes, ''), COALESCE(root_cause, ''),
+			COALESCE(resolution_group, ''), COALESCE(category, ''), COALESCE(subcategory, ''),
+			COALESCE(priority, ''), resolution_time_hours
+		FROM incidents
+		WHERE 1=1`
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query += whereClause
+	query += " ORDER BY report_date DESC, incident_id"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incidents for keyword preview: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var incident models.Incident
+		var resolutionTime sql.NullInt64
+		if err := rows.Scan(&incident.IncidentID, &incident.ApplicationName, &incident.BriefDescription,
+			&incident.Description, &incident.ResolutionNotes, &incident.RootCause,
+			&incident.ResolutionGroup, &incident.Category, &incident.Subcategory, &incident.Priority, &resolutionTime); err != nil {
+			return nil, fmt.Errorf("failed to scan incident for keyword preview: %w", err)
+		}
+		if resolutionTime.Valid {
+			hours := int(resolutionTime.Int64)
+			incident.ResolutionTimeHours = &hours
+		}
+		preview.IncidentsAnalyzed++
+
+		if !incidentHasKeyword(current, &incident, keyword) {
+			continue
+		}
+		preview.MatchingIncidents++
+
+		before, err := current.AnalyzeAutomation(&incident)
+		if err != nil {
+			return nil, err
+		}
+		after, err := proposed.AnalyzeAutomation(&incident)
+		if err != nil {
+			return nil, err
+		}
+
+		if after.Score != before.Score {
+			preview.ScoreChanged++
+		}
+		if after.Feasible == before.Feasible {
+			continue
+		}
+		if after.Feasible {
+			preview.BecameFeasible++
+		} else {
+			preview.BecameInfeasible++
+		}
+		if len(preview.Samples) < filters.topN(maxKeywordPreviewSamples) {
+			preview.Samples = append(preview.Samples, KeywordImpactSample{
+				IncidentID:       incident.IncidentID,
+				ApplicationName:  incident.ApplicationName,
+				BriefDescription: incident.BriefDescription,
+				CurrentScore:     before.Score,
+				ProposedScore:    after.Score,
+				ProposedFeasible: after.Feasible,
+			})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating incidents for keyword preview: %w", err)
+	}
+
+	return preview, nil
+}
+
+// incidentHasKeyword reports whether the text the analyzer scores contains keyword as a token
+func incidentHasKeyword(analyzer *SimpleAutomationAnalyzer, incident *models.Incident, keyword string) bool {
+	text := strings.ToLower(strings.Join([]string{
+		incident.BriefDescription,
+		incident.Description,
+		incident.ResolutionNotes,
+		incident.RootCause,
+	}, " "))
+	for _, token := range analyzer.tokenizeText(text) {
+		if token == keyword {
+			return true
+		}
+	}
+	return false
+}
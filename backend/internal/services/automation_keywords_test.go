@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestAutomationKeywordService_PreviewAndSave(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	service := NewAutomationKeywordService(db)
+	ctx := context.Background()
+
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P3", "Closed"),
+		diffTestIncident("i2", "upload-1", "INC002", "P3", "Closed"),
+		diffTestIncident("i3", "upload-1", "INC003", "P3", "Closed"),
+	}
+	incidents[0].Description = "Applied hotfix to login service"
+	incidents[1].ResolutionNotes = "Hotfix deployed"
+
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	preview, err := service.PreviewKeyword(ctx, AutomationKeywordKindAutomation, "Hotfix", 1.0, nil)
+	if err != nil {
+		t.Fatalf("Failed to preview keyword: %v", err)
+	}
+	if preview.Keyword != "hotfix" || preview.CurrentWeight != nil {
+		t.Errorf("Unexpected keyword in preview: %+v", preview)
+	}
+	if preview.IncidentsAnalyzed != 3 || preview.MatchingIncidents != 2 {
+		t.Errorf("Expected 3 analyzed and 2 matching incidents, got %d and %d", preview.IncidentsAnalyzed, preview.MatchingIncidents)
+	}
+	if preview.BecameFeasible != 2 || preview.BecameInfeasible != 0 || len(preview.Samples) != 2 {
+		t.Errorf("Expected 2 incidents to become feasible, got %+v", preview)
+	}
+
+	// Preview must not persist the keyword
+	keywords, err := service.ListKeywords(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list keywords: %v", err)
+	}
+	if len(keywords) != 0 {
+		t.Fatalf("Expected preview to leave keywords untouched, got %+v", keywords)
+	}
+
+	if _, err := service.SaveKeyword(ctx, AutomationKeywordKindAutomation, "two words", 0.5); err != ErrInvalidAutomationKeyword {
+		t.Errorf("Expected ErrInvalidAutomationKeyword, got %v", err)
+	}
+	if _, err := service.SaveKeyword(ctx, AutomationKeywordKindAutomation, " Hotfix ", 1.0); err != nil {
+		t.Fatalf("Failed to save keyword: %v", err)
+	}
+
+	// The persisted keyword is picked up by a freshly loaded analyzer
+	analyzer := NewSimpleAutomationAnalyzer()
+	if err := analyzer.LoadKeywords(ctx, db); err != nil {
+		t.Fatalf("Failed to load keywords: %v", err)
+	}
+	result, err := analyzer.AnalyzeAutomation(&incidents[0])
+	if err != nil {
+		t.Fatalf("Failed to analyze incident: %v", err)
+	}
+	if !result.Feasible {
+		t.Errorf("Expected incident with saved keyword to be feasible, got score %.3f", result.Score)
+	}
+
+	// Previewing the saved weight again changes nothing
+	preview, err = service.PreviewKeyword(ctx, AutomationKeywordKindAutomation, "hotfix", 1.0, nil)
+	if err != nil {
+		t.Fatalf("Failed to preview keyword: %v", err)
+	}
+	if preview.CurrentWeight == nil || *preview.CurrentWeight != 1.0 {
+		t.Errorf("Expected current weight 1.0, got %v", preview.CurrentWeight)
+	}
+	if preview.BecameFeasible != 0 || preview.ScoreChanged != 0 {
+		t.Errorf("Expected no changes for the saved weight, got %+v", preview)
+	}
+
+	// Deleting restores the built-in keyword set on the next load
+	if err := service.DeleteKeyword(ctx, AutomationKeywordKindAutomation, "hotfix"); err != nil {
+		t.Fatalf("Failed to delete keyword: %v", err)
+	}
+	if err := service.DeleteKeyword(ctx, AutomationKeywordKindAutomation, "hotfix"); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows deleting a missing keyword, got %v", err)
+	}
+	if err := analyzer.LoadKeywords(ctx, db); err != nil {
+		t.Fatalf("Failed to reload keywords: %v", err)
+	}
+	result, _ = analyzer.AnalyzeAutomation(&incidents[0])
+	if result.Feasible {
+		t.Errorf("Expected incident to be infeasible after deleting the keyword, got score %.3f", result.Score)
+	}
+}
@@ -0,0 +1,445 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Automation opportunity statuses. See opportunityTransitions for the order a candidate moves
+// through them.
+const (
+	OpportunityStatusIdentified  = "identified"
+	OpportunityStatusUnderReview = "under_review"
+	OpportunityStatusApproved    = "approved"
+	OpportunityStatusImplemented = "implemented"
+	OpportunityStatusRejected    = "rejected"
+)
+
+// OpportunityStatuses lists the automation opportunity statuses in pipeline order
+var OpportunityStatuses = []string{
+	OpportunityStatusIdentified,
+	OpportunityStatusUnderReview,
+	OpportunityStatusApproved,
+	OpportunityStatusImplemented,
+	OpportunityStatusRejected,
+}
+
+// opportunityTransitions lists the statuses an opportunity may move to from each status. A
+// candidate is reviewed, then approved and implemented, or rejected at any point before it is
+// implemented. Review can send a candidate back a step, and a rejected candidate can be
+// identified again. Implemented is final.
+var opportunityTransitions = map[string][]string{
+	OpportunityStatusIdentified:  {OpportunityStatusUnderReview, OpportunityStatusRejected},
+	OpportunityStatusUnderReview: {OpportunityStatusIdentified, OpportunityStatusApproved, OpportunityStatusRejected},
+	OpportunityStatusApproved:    {OpportunityStatusUnderReview, OpportunityStatusImplemented, OpportunityStatusRejected},
+	OpportunityStatusImplemented: {},
+	OpportunityStatusRejected:    {OpportunityStatusIdentified},
+}
+
+// savingsPeriodDays is the length of the period monthly savings are expressed over
+const savingsPeriodDays = 30.0
+
+var (
+	// ErrInvalidOpportunityTransition is returned when an opportunity cannot move to a status
+	ErrInvalidOpportunityTransition = errors.New("automation opportunity status transition not allowed")
+	// ErrInvalidOpportunityUpdate is returned when an opportunity update changes nothing
+	ErrInvalidOpportunityUpdate = errors.New("invalid automation opportunity update")
+)
+
+// CanTransitionOpportunity reports whether an opportunity may move from one status to another
+func CanTransitionOpportunity(from, to string) bool {
+	return containsString(opportunityTransitions[from], to)
+}
+
+// AutomationOpportunity tracks an automation candidate, identified by its IT process group,
+// through review and implementation. ProjectedMonthlyHours is recorded when the candidate is
+// implemented: the handling hours of its automatable incidents per 30 days before then.
+type AutomationOpportunity struct {
+	ITProcessGroup        string            `json:"it_process_group"`
+	Status                string            `json:"status"`
+	Assignee              string            `json:"assignee,omitempty"`
+	HandlingMinutes       int               `json:"handling_minutes"`
+	ProjectedMonthlyHours *float64          `json:"projected_monthly_hours,omitempty"`
+	ImplementedAt         *time.Time        `json:"implemented_at,omitempty"`
+	CreatedAt             time.Time         `json:"created_at"`
+	UpdatedAt             time.Time         `json:"updated_at"`
+	Notes                 []OpportunityNote `json:"notes,omitempty"`
+}
+
+// OpportunityNote records one update of an opportunity: a note, a status change or both
+type OpportunityNote struct {
+	ID         string    `json:"id"`
+	Author     string    `json:"author,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	FromStatus string    `json:"from_status,omitempty"`
+	ToStatus   string    `json:"to_status,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// OpportunityUpdate changes an opportunity. Empty fields and a nil Assignee keep their value;
+// an empty Assignee unassigns the opportunity.
+type OpportunityUpdate struct {
+	Status          string
+	Assignee        *string
+	Note            string
+	HandlingMinutes int
+}
+
+// PipelineStage counts the opportunities in one status
+type PipelineStage struct {
+	Status string `json:"status"`
+	Count  int    `json:"count"`
+}
+
+// ImplementedSavings compares the hours an implemented opportunity was projected to save since
+// its implementation with the handling hours of the automatable incidents reported since then
+type ImplementedSavings struct {
+	ITProcessGroup      string    `json:"it_process_group"`
+	ImplementedAt       time.Time `json:"implemented_at"`
+	DaysImplemented     int       `json:"days_implemented"`
+	ProjectedHoursSaved float64   `json:"projected_hours_saved"`
+	RealizedHoursSaved  float64   `json:"realized_hours_saved"`
+	RealizationRate     *float64  `json:"realization_rate"`
+}
+
+// AutomationPipeline is the automation opportunity dashboard: how many candidates are in each
+// status and what the implemented ones saved against their projection
+type AutomationPipeline struct {
+	Stages              []PipelineStage      `json:"stages"`
+	Untracked           int                  `json:"untracked"`
+	Implemented         []ImplementedSavings `json:"implemented"`
+	ProjectedHoursSaved float64              `json:"projected_hours_saved"`
+	RealizedHoursSaved  float64              `json:"realized_hours_saved"`
+	RealizationRate     *float64             `json:"realization_rate"`
+	GeneratedAt         time.Time            `json:"generated_at"`
+}
+
+// AutomationPipelineService tracks automation candidates from identification to implementation
+type AutomationPipelineService struct {
+	db *sql.DB
+}
+
+// NewAutomationPipelineService creates a new AutomationPipelineService instance
+func NewAutomationPipelineService(db *sql.DB) *AutomationPipelineService {
+	return &AutomationPipelineService{db: db}
+}
+
+// UpdateOpportunity applies an update to the opportunity of an IT process group, recording it as
+// a note. A candidate that is not tracked yet starts as identified; it must have automatable
+// incidents, returning sql.ErrNoRows otherwise. Marking an opportunity implemented records its
+// projected monthly savings.
+func (s *AutomationPipelineService) UpdateOpportunity(ctx context.Context, processGroup string, update OpportunityUpdate, author string) (*AutomationOpportunity, error) {
+	update.Note = strings.TrimSpace(update.Note)
+	if update.Status == "" && update.Assignee == nil && update.Note == "" && update.HandlingMinutes == 0 {
+		return nil, fmt.Errorf("%w: set a status, assignee, note or handling minutes", ErrInvalidOpportunityUpdate)
+	}
+	if update.HandlingMinutes < 0 {
+		return nil, fmt.Errorf("%w: handling minutes must be positive", ErrInvalidOpportunityUpdate)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	opportunity, err := getOpportunity(ctx, tx, processGroup)
+	tracked := err == nil
+	if err == sql.ErrNoRows {
+		var automatable int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM incidents WHERE it_process_group = ? AND automation_feasible = true AND "+canonicalIncident,
+			processGroup).Scan(&automatable); err != nil {
+			return nil, fmt.Errorf("failed to query automation candidate: %w", err)
+		}
+		if automatable == 0 {
+			return nil, sql.ErrNoRows
+		}
+		opportunity = &AutomationOpportunity{
+			ITProcessGroup:  processGroup,
+			Status:          OpportunityStatusIdentified,
+			HandlingMinutes: DefaultHandlingMinutes,
+			CreatedAt:       now,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	note := OpportunityNote{ID: uuid.New().String(), Author: author, Note: update.Note, CreatedAt: now}
+	if update.Status != "" && update.Status != opportunity.Status {
+		if !CanTransitionOpportunity(opportunity.Status, update.Status) {
+			return nil, fmt.Errorf("%w: %s to %s", ErrInvalidOpportunityTransition, opportunity.Status, update.Status)
+		}
+		note.FromStatus, note.ToStatus = opportunity.Status, update.Status
+		opportunity.Status = update.Status
+	}
+	if update.Assignee != nil {
+		opportunity.Assignee = strings.TrimSpace(*update.Assignee)
+	}
+	if update.HandlingMinutes > 0 {
+		opportunity.HandlingMinutes = update.HandlingMinutes
+	}
+	if note.ToStatus == OpportunityStatusImplemented {
+		projected, err := projectedMonthlyHours(ctx, tx, processGroup, opportunity.HandlingMinutes, now)
+		if err != nil {
+			return nil, err
+		}
+		opportunity.ProjectedMonthlyHours = &projected
+		opportunity.ImplementedAt = &now
+	}
+	opportunity.UpdatedAt = now
+
+	var implementedAt interface{}
+	if opportunity.ImplementedAt != nil {
+		implementedAt = *opportunity.ImplementedAt
+	}
+	var projected interface{}
+	if opportunity.ProjectedMonthlyHours != nil {
+		projected = *opportunity.ProjectedMonthlyHours
+	}
+	if tracked {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE automation_opportunity_tracking
+			SET status = ?, assignee = ?, handling_minutes = ?, projected_monthly_hours = ?, implemented_at = ?, updated_at = ?
+			WHERE it_process_group = ?
+		`, opportunity.Status, nullIfEmpty(opportunity.Assignee), opportunity.HandlingMinutes, projected, implementedAt,
+			opportunity.UpdatedAt, processGroup)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO automation_opportunity_tracking (it_process_group, status, assignee, handling_minutes,
+				projected_monthly_hours, implemented_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, processGroup, opportunity.Status, nullIfEmpty(opportunity.Assignee), opportunity.HandlingMinutes, projected,
+			implementedAt, opportunity.CreatedAt, opportunity.UpdatedAt)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save automation opportunity: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO automation_opportunity_notes (id, it_process_group, author, note, from_status, to_status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, note.ID, processGroup, nullIfEmpty(note.Author), nullIfEmpty(note.Note), nullIfEmpty(note.FromStatus),
+		nullIfEmpty(note.ToStatus), note.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save automation opportunity note: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit automation opportunity: %w", err)
+	}
+	return s.GetOpportunity(ctx, processGroup)
+}
+
+// GetOpportunity returns the tracked opportunity of an IT process group with its notes, newest
+// first, or sql.ErrNoRows when the candidate is not tracked
+func (s *AutomationPipelineService) GetOpportunity(ctx context.Context, processGroup string) (*AutomationOpportunity, error) {
+	opportunity, err := getOpportunity(ctx, s.db, processGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, COALESCE(author, ''), COALESCE(note, ''), COALESCE(from_status, ''), COALESCE(to_status, ''), created_at
+		FROM automation_opportunity_notes
+		WHERE it_process_group = ?
+		ORDER BY created_at DESC, id
+	`, processGroup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation opportunity notes: %w", err)
+	}
+	defer rows.Close()
+
+	opportunity.Notes = []OpportunityNote{}
+	for rows.Next() {
+		var note OpportunityNote
+		if err := rows.Scan(&note.ID, &note.Author, &note.Note, &note.FromStatus, &note.ToStatus, &note.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan automation opportunity note: %w", err)
+		}
+		opportunity.Notes = append(opportunity.Notes, note)
+	}
+	return opportunity, rows.Err()
+}
+
+// ListOpportunities returns the tracked opportunities, optionally only those in a status or
+// assigned to someone, most recently updated first
+func (s *AutomationPipelineService) ListOpportunities(ctx context.Context, status, assignee string) ([]AutomationOpportunity, error) {
+	query := "SELECT " + opportunityColumns + " FROM automation_opportunity_tracking WHERE 1=1"
+	var args []interface{}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	if assignee != "" {
+		query += " AND assignee = ?"
+		args = append(args, assignee)
+	}
+	query += " ORDER BY updated_at DESC, it_process_group"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation opportunities: %w", err)
+	}
+	defer rows.Close()
+
+	opportunities := []AutomationOpportunity{}
+	for rows.Next() {
+		opportunity, err := scanOpportunity(rows)
+		if err != nil {
+			return nil, err
+		}
+		opportunities = append(opportunities, *opportunity)
+	}
+	return opportunities, rows.Err()
+}
+
+// GetPipeline counts the opportunities in each status and compares the projected and realized
+// savings of implemented ones. Candidates with automatable incidents that are not tracked yet
+// count as identified, and are also reported as untracked. Realized savings are the handling
+// hours of the automatable incidents of the group reported since it was implemented, which the
+// automation now handles.
+func (s *AutomationPipelineService) GetPipeline(ctx context.Context) (*AutomationPipeline, error) {
+	now := time.Now()
+	pipeline := &AutomationPipeline{Implemented: []ImplementedSavings{}, GeneratedAt: now}
+
+	counts := make(map[string]int)
+	rows, err := s.db.QueryContext(ctx, "SELECT status, COUNT(*) FROM automation_opportunity_tracking GROUP BY status")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation pipeline: %w", err)
+	}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan automation pipeline stage: %w", err)
+		}
+		counts[status] = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating automation pipeline stages: %w", err)
+	}
+
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT it_process_group)
+		FROM incidents
+		WHERE automation_feasible = true AND it_process_group IS NOT NULL AND it_process_group != '' AND `+canonicalIncident+`
+			AND it_process_group NOT IN (SELECT it_process_group FROM automation_opportunity_tracking)
+	`).Scan(&pipeline.Untracked); err != nil {
+		return nil, fmt.Errorf("failed to count untracked automation candidates: %w", err)
+	}
+	counts[OpportunityStatusIdentified] += pipeline.Untracked
+
+	for _, status := range OpportunityStatuses {
+		pipeline.Stages = append(pipeline.Stages, PipelineStage{Status: status, Count: counts[status]})
+	}
+
+	implemented, err := s.ListOpportunities(ctx, OpportunityStatusImplemented, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, opportunity := range implemented {
+		if opportunity.ImplementedAt == nil {
+			continue
+		}
+		var realized int
+		if err := s.db.QueryRowContext(ctx, `
+			SELECT COUNT(*)
+			FROM incidents
+			WHERE it_process_group = ? AND automation_feasible = true AND report_date >= ? AND `+canonicalIncident,
+			opportunity.ITProcessGroup, *opportunity.ImplementedAt).Scan(&realized); err != nil {
+			return nil, fmt.Errorf("failed to query realized savings of %s: %w", opportunity.ITProcessGroup, err)
+		}
+
+		days := now.Sub(*opportunity.ImplementedAt).Hours() / 24
+		savings := ImplementedSavings{
+			ITProcessGroup:     opportunity.ITProcessGroup,
+			ImplementedAt:      *opportunity.ImplementedAt,
+			DaysImplemented:    int(days),
+			RealizedHoursSaved: float64(realized*opportunity.HandlingMinutes) / 60,
+		}
+		if opportunity.ProjectedMonthlyHours != nil {
+			savings.ProjectedHoursSaved = *opportunity.ProjectedMonthlyHours * days / savingsPeriodDays
+		}
+		savings.RealizationRate = realizationRate(savings.RealizedHoursSaved, savings.ProjectedHoursSaved)
+
+		pipeline.Implemented = append(pipeline.Implemented, savings)
+		pipeline.ProjectedHoursSaved += savings.ProjectedHoursSaved
+		pipeline.RealizedHoursSaved += savings.RealizedHoursSaved
+	}
+	pipeline.RealizationRate = realizationRate(pipeline.RealizedHoursSaved, pipeline.ProjectedHoursSaved)
+
+	return pipeline, nil
+}
+
+// realizationRate is the realized share of projected savings in percent, or nil before anything
+// was projected
+func realizationRate(realized, projected float64) *float64 {
+	if projected <= 0 {
+		return nil
+	}
+	rate := realized / projected * 100
+	return &rate
+}
+
+// projectedMonthlyHours is the handling time of a group's automatable incidents reported before
+// implementation, in hours per 30 days of the period they span
+func projectedMonthlyHours(ctx context.Context, tx *sql.Tx, processGroup string, handlingMinutes int, before time.Time) (float64, error) {
+	var count int
+	var first, last sql.NullTime
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*), MIN(report_date), MAX(report_date)
+		FROM incidents
+		WHERE it_process_group = ? AND automation_feasible = true AND report_date < ? AND `+canonicalIncident,
+		processGroup, before).Scan(&count, &first, &last); err != nil {
+		return 0, fmt.Errorf("failed to query projected savings of %s: %w", processGroup, err)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	// A single day of incidents still spans one day
+	days := last.Time.Sub(first.Time).Hours()/24 + 1
+	if days < savingsPeriodDays {
+		days = savingsPeriodDays
+	}
+	return float64(count*handlingMinutes) / 60 * savingsPeriodDays / days, nil
+}
+
+// opportunityColumns are the columns scanOpportunity reads
+const opportunityColumns = `it_process_group, status, COALESCE(assignee, ''), handling_minutes, projected_monthly_hours,
+	implemented_at, created_at, updated_at`
+
+// getOpportunity reads one tracked opportunity without its notes, returning sql.ErrNoRows when
+// the candidate is not tracked
+func getOpportunity(ctx context.Context, db mergeQuerier, processGroup string) (*AutomationOpportunity, error) {
+	return scanOpportunity(db.QueryRowContext(ctx,
+		"SELECT "+opportunityColumns+" FROM automation_opportunity_tracking WHERE it_process_group = ?", processGroup))
+}
+
+// scanOpportunity reads an opportunity selected with opportunityColumns
+func scanOpportunity(row interface{ Scan(...interface{}) error }) (*AutomationOpportunity, error) {
+	var opportunity AutomationOpportunity
+	var projected sql.NullFloat64
+	var implementedAt sql.NullTime
+	if err := row.Scan(&opportunity.ITProcessGroup, &opportunity.Status, &opportunity.Assignee, &opportunity.HandlingMinutes,
+		&projected, &implementedAt, &opportunity.CreatedAt, &opportunity.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan automation opportunity: %w", err)
+	}
+	if projected.Valid {
+		opportunity.ProjectedMonthlyHours = &projected.Float64
+	}
+	if implementedAt.Valid {
+		opportunity.ImplementedAt = &implementedAt.Time
+	}
+	return &opportunity, nil
+}
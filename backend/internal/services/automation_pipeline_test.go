@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestAutomationPipelineService_Lifecycle(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	service := NewAutomationPipelineService(db)
+	ctx := context.Background()
+
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P3", "Closed"),
+		diffTestIncident("i2", "upload-1", "INC002", "P3", "Closed"),
+		diffTestIncident("i3", "upload-1", "INC003", "P3", "Closed"),
+		diffTestIncident("i4", "upload-1", "INC004", "P3", "Closed"),
+	}
+	for i := range incidents {
+		incidents[i].ITProcessGroup = "Access Management"
+		feasible := true
+		incidents[i].AutomationFeasible = &feasible
+	}
+	incidents[3].ITProcessGroup = "Backup"
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	if _, err := service.UpdateOpportunity(ctx, "Unknown", OpportunityUpdate{Status: OpportunityStatusUnderReview}, "jane"); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for a group without automatable incidents, got %v", err)
+	}
+	if _, err := service.UpdateOpportunity(ctx, "Access Management", OpportunityUpdate{}, "jane"); !errors.Is(err, ErrInvalidOpportunityUpdate) {
+		t.Errorf("Expected ErrInvalidOpportunityUpdate for an empty update, got %v", err)
+	}
+	// A candidate cannot skip review
+	if _, err := service.UpdateOpportunity(ctx, "Access Management", OpportunityUpdate{Status: OpportunityStatusImplemented}, "jane"); !errors.Is(err, ErrInvalidOpportunityTransition) {
+		t.Errorf("Expected ErrInvalidOpportunityTransition, got %v", err)
+	}
+
+	assignee := "sam"
+	for _, update := range []OpportunityUpdate{
+		{Status: OpportunityStatusUnderReview, Assignee: &assignee, Note: "Worth a look"},
+		{Status: OpportunityStatusApproved},
+		{Note: "Script drafted", HandlingMinutes: 20},
+		{Status: OpportunityStatusImplemented},
+	} {
+		if _, err := service.UpdateOpportunity(ctx, "Access Management", update, "jane"); err != nil {
+			t.Fatalf("Failed to update opportunity with %+v: %v", update, err)
+		}
+	}
+
+	opportunity, err := service.GetOpportunity(ctx, "Access Management")
+	if err != nil {
+		t.Fatalf("Failed to get opportunity: %v", err)
+	}
+	if opportunity.Status != OpportunityStatusImplemented || opportunity.Assignee != "sam" || opportunity.HandlingMinutes != 20 {
+		t.Errorf("Unexpected opportunity: %+v", opportunity)
+	}
+	if len(opportunity.Notes) != 4 || opportunity.Notes[0].ToStatus != OpportunityStatusImplemented || opportunity.Notes[3].Note != "Worth a look" {
+		t.Errorf("Expected 4 notes, newest first, got %+v", opportunity.Notes)
+	}
+	// Three automatable incidents on one day, at 20 minutes each, projected over a 30 day month
+	if opportunity.ImplementedAt == nil || opportunity.ProjectedMonthlyHours == nil || *opportunity.ProjectedMonthlyHours != 1 {
+		t.Errorf("Expected 1 projected hour per month, got %+v", opportunity)
+	}
+	if _, err := service.UpdateOpportunity(ctx, "Access Management", OpportunityUpdate{Status: OpportunityStatusRejected}, "jane"); !errors.Is(err, ErrInvalidOpportunityTransition) {
+		t.Errorf("Expected implemented to be final, got %v", err)
+	}
+
+	// An incident reported after implementation counts as realized savings
+	later := diffTestIncident("i5", "upload-2", "INC005", "P3", "Closed")
+	later.ReportDate = time.Now().Add(time.Hour)
+	later.ITProcessGroup = "Access Management"
+	feasible := true
+	later.AutomationFeasible = &feasible
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, []models.Incident{later}, "upload-2"); err != nil {
+		t.Fatalf("Failed to insert incident: %v", err)
+	}
+
+	pipeline, err := service.GetPipeline(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get pipeline: %v", err)
+	}
+	if pipeline.Untracked != 1 || len(pipeline.Stages) != len(OpportunityStatuses) {
+		t.Errorf("Unexpected pipeline: %+v", pipeline)
+	}
+	for _, stage := range pipeline.Stages {
+		expected := 0
+		if stage.Status == OpportunityStatusIdentified || stage.Status == OpportunityStatusImplemented {
+			expected = 1
+		}
+		if stage.Count != expected {
+			t.Errorf("Expected %d opportunities %s, got %d", expected, stage.Status, stage.Count)
+		}
+	}
+	if len(pipeline.Implemented) != 1 || pipeline.RealizedHoursSaved < 0.33 || pipeline.RealizedHoursSaved > 0.34 {
+		t.Errorf("Expected 20 realized minutes, got %+v", pipeline)
+	}
+
+	listed, err := service.ListOpportunities(ctx, "", "sam")
+	if err != nil {
+		t.Fatalf("Failed to list opportunities: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Notes != nil {
+		t.Errorf("Expected the assigned opportunity without notes, got %+v", listed)
+	}
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AutomationAssumption describes one slice of the automation candidates a scenario automates:
+// the feasible incidents with at least MinScore that match every criterion given. SuccessRate
+// is the share of those incidents the automation is expected to resolve without an engineer.
+type AutomationAssumption struct {
+	ITProcessGroup string   `json:"it_process_group,omitempty"`
+	Applications   []string `json:"applications,omitempty"`
+	Priorities     []string `json:"priorities,omitempty"`
+	MinScore       float64  `json:"min_score"`
+	SuccessRate    float64  `json:"success_rate"`
+}
+
+// condition returns the SQL condition selecting the assumption's candidates, numbering its
+// parameters from argIndex
+func (a AutomationAssumption) condition(argIndex int) (string, []interface{}, int) {
+	conditions := []string{
+		"automation_feasible = true",
+		fmt.Sprintf("automation_score >= $%d", argIndex),
+	}
+	args := []interface{}{a.MinScore}
+	argIndex++
+
+	if a.ITProcessGroup != "" {
+		conditions = append(conditions, fmt.Sprintf("it_process_group = $%d", argIndex))
+		args = append(args, a.ITProcessGroup)
+		argIndex++
+	}
+	for _, list := range []struct {
+		column string
+		values []string
+	}{
+		{"application_name", a.Applications},
+		{"priority", a.Priorities},
+	} {
+		if len(list.values) == 0 {
+			continue
+		}
+		placeholders := make([]string, len(list.values))
+		for i, value := range list.values {
+			placeholders[i] = fmt.Sprintf("$%d", argIndex)
+			args = append(args, value)
+			argIndex++
+		}
+		conditions = append(conditions, fmt.Sprintf("%s IN (%s)", list.column, strings.Join(placeholders, ", ")))
+	}
+	return strings.Join(conditions, " AND "), args, argIndex
+}
+
+// ScenarioTotals holds incident volume, resolution hours and SLA breaches. Projections are
+// expected values, so they need not be whole numbers.
+type ScenarioTotals struct {
+	Incidents       float64 `json:"incidents"`
+	ResolutionHours float64 `json:"resolution_hours"`
+	SLABreaches     float64 `json:"sla_breaches"`
+}
+
+// add returns the sum of two totals
+func (t ScenarioTotals) add(other ScenarioTotals) ScenarioTotals {
+	return ScenarioTotals{
+		Incidents:       t.Incidents + other.Incidents,
+		ResolutionHours: t.ResolutionHours + other.ResolutionHours,
+		SLABreaches:     t.SLABreaches + other.SLABreaches,
+	}
+}
+
+// scale returns the totals multiplied by factor
+func (t ScenarioTotals) scale(factor float64) ScenarioTotals {
+	return ScenarioTotals{
+		Incidents:       t.Incidents * factor,
+		ResolutionHours: t.ResolutionHours * factor,
+		SLABreaches:     t.SLABreaches * factor,
+	}
+}
+
+// rounded returns the totals rounded to two decimal places
+func (t ScenarioTotals) rounded() ScenarioTotals {
+	return ScenarioTotals{
+		Incidents:       roundTo(t.Incidents, 2),
+		ResolutionHours: roundTo(t.ResolutionHours, 2),
+		SLABreaches:     roundTo(t.SLABreaches, 2),
+	}
+}
+
+// ScenarioAssumptionResult is what one assumption of a scenario contributes
+type ScenarioAssumptionResult struct {
+	Assumption AutomationAssumption `json:"assumption"`
+	// Candidates holds the history of the incidents the assumption automates. Incidents matched
+	// by an earlier assumption are counted there only.
+	Candidates ScenarioTotals `json:"candidates"`
+	Reduction  ScenarioTotals `json:"reduction"`
+}
+
+// AutomationScenario projects what automating the assumptions' candidates would have saved over
+// the historical period
+type AutomationScenario struct {
+	PeriodStart string `json:"period_start,omitempty"`
+	PeriodEnd   string `json:"period_end,omitempty"`
+	PeriodDays  int    `json:"period_days"`
+
+	Baseline         ScenarioTotals `json:"baseline"`
+	Projected        ScenarioTotals `json:"projected"`
+	Reduction        ScenarioTotals `json:"reduction"`
+	ReductionPercent ScenarioTotals `json:"reduction_percent"`
+	// AnnualReduction extrapolates the reduction over the period to a year
+	AnnualReduction ScenarioTotals `json:"annual_reduction"`
+
+	Assumptions []ScenarioAssumptionResult `json:"assumptions"`
+}
+
+// GetAutomationScenario replays the incident history matching the filters as if the candidates
+// of each assumption had been automated. Automated incidents no longer reach an engineer, so
+// each one resolved by automation removes its volume, its resolution hours and, when it was
+// resolved past its priority's SLA target, its breach. Assumptions are applied in order and an
+// incident is only counted for the first one it matches.
+func (s *AnalyticsService) GetAutomationScenario(ctx context.Context, filters *TimelineFilters, assumptions []AutomationAssumption) (*AutomationScenario, error) {
+	whereClause, args, argIndex := buildFilterConditions(ctx, filters, 1)
+
+	var cases []string
+	for i, assumption := range assumptions {
+		condition, conditionArgs, next := assumption.condition(argIndex)
+		cases = append(cases, fmt.Sprintf("WHEN %s THEN %d", condition, i))
+		args = append(args, conditionArgs...)
+		argIndex = next
+	}
+	assumptionIndex := "-1"
+	if len(cases) > 0 {
+		assumptionIndex = "CASE " + strings.Join(cases, " ") + " ELSE -1 END"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			%s as assumption_index,
+			COUNT(*) as incident_count,
+			CAST(COALESCE(SUM(resolution_time_hours), 0) AS DOUBLE) as resolution_hours,
+			COUNT(CASE WHEN %s > %s THEN 1 END) as sla_breaches,
+			MIN(report_date) as period_start,
+			MAX(report_date) as period_end
+		FROM incidents
+		WHERE 1=1`, assumptionIndex, resolutionHoursExpr, slaTargetExpression("priority"))
+	query += whereClause
+	query += " GROUP BY assumption_index"
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation scenario: %w", err)
+	}
+	defer rows.Close()
+
+	scenario := &AutomationScenario{Assumptions: make([]ScenarioAssumptionResult, len(assumptions))}
+	for i, assumption := range assumptions {
+		scenario.Assumptions[i].Assumption = assumption
+	}
+	var periodStart, periodEnd time.Time
+	for rows.Next() {
+		var index, count, breaches int
+		var hours float64
+		var first, last sql.NullTime
+		if err := rows.Scan(&index, &count, &hours, &breaches, &first, &last); err != nil {
+			return nil, fmt.Errorf("failed to scan automation scenario row: %w", err)
+		}
+
+		totals := ScenarioTotals{Incidents: float64(count), ResolutionHours: hours, SLABreaches: float64(breaches)}
+		scenario.Baseline = scenario.Baseline.add(totals)
+		if index >= 0 && index < len(assumptions) {
+			scenario.Assumptions[index].Candidates = totals
+			scenario.Assumptions[index].Reduction = totals.scale(assumptions[index].SuccessRate).rounded()
+			scenario.Reduction = scenario.Reduction.add(totals.scale(assumptions[index].SuccessRate))
+		}
+		if first.Valid && (periodStart.IsZero() || first.Time.Before(periodStart)) {
+			periodStart = first.Time
+		}
+		if last.Valid && last.Time.After(periodEnd) {
+			periodEnd = last.Time
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating automation scenario rows: %w", err)
+	}
+
+	scenario.Projected = scenario.Baseline.add(scenario.Reduction.scale(-1)).rounded()
+	if !periodStart.IsZero() {
+		scenario.PeriodStart = periodStart.Format("2006-01-02")
+		scenario.PeriodEnd = periodEnd.Format("2006-01-02")
+		scenario.PeriodDays = int(periodEnd.Sub(periodStart).Hours()/24) + 1
+		scenario.AnnualReduction = scenario.Reduction.scale(365 / float64(scenario.PeriodDays)).rounded()
+	}
+	scenario.ReductionPercent = ScenarioTotals{
+		Incidents:       percentOf(scenario.Reduction.Incidents, scenario.Baseline.Incidents),
+		ResolutionHours: percentOf(scenario.Reduction.ResolutionHours, scenario.Baseline.ResolutionHours),
+		SLABreaches:     percentOf(scenario.Reduction.SLABreaches, scenario.Baseline.SLABreaches),
+	}
+	scenario.Baseline = scenario.Baseline.rounded()
+	scenario.Reduction = scenario.Reduction.rounded()
+
+	return scenario, nil
+}
+
+// percentOf returns part as a percentage of whole, rounded to two decimal places, or 0 when
+// whole is 0
+func percentOf(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return roundTo(part/whole*100, 2)
+}
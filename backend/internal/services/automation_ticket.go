@@ -0,0 +1,345 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// automationCandidateExampleLimit caps the example incidents included with a candidate
+	automationCandidateExampleLimit = 5
+	// DefaultHandlingMinutes is the manual effort assumed per automatable incident when estimating savings
+	DefaultHandlingMinutes = 30
+	// defaultJiraIssueType is used when neither the request nor the configuration names an issue type
+	defaultJiraIssueType = "Task"
+)
+
+var (
+	// ErrJiraNotConfigured is returned when a ticket is requested without a configured Jira site
+	ErrJiraNotConfigured = errors.New("jira integration is not configured")
+	// ErrMissingJiraProject is returned when neither the request nor the configuration names a project
+	ErrMissingJiraProject = errors.New("no jira project key given or configured")
+	// ErrJiraRequestFailed is returned when Jira rejects the issue or cannot be reached
+	ErrJiraRequestFailed = errors.New("failed to create jira issue")
+)
+
+// JiraConfig holds the Jira Cloud site and API credentials used to create tracking issues
+type JiraConfig struct {
+	BaseURL    string
+	Email      string
+	APIToken   string
+	ProjectKey string
+	IssueType  string
+}
+
+// Configured reports whether the site and credentials are set
+func (c *JiraConfig) Configured() bool {
+	return c != nil && c.BaseURL != "" && c.Email != "" && c.APIToken != ""
+}
+
+// CandidateIncident is an example incident of an automation candidate
+type CandidateIncident struct {
+	IncidentID       string  `json:"incident_id"`
+	ReportDate       string  `json:"report_date"`
+	ApplicationName  string  `json:"application_name"`
+	BriefDescription string  `json:"brief_description"`
+	AutomationScore  float64 `json:"automation_score"`
+}
+
+// AutomationCandidateDetail is an automation candidate with example incidents, a savings
+// estimate, the runbooks already covering it, and the tracking ticket created for it and its
+// review status, if any. Candidates are identified by their IT process group.
+type AutomationCandidateDetail struct {
+	AutomationCandidate
+	Examples            []CandidateIncident    `json:"examples"`
+	HandlingMinutes     int                    `json:"handling_minutes"`
+	EstimatedHoursSaved float64                `json:"estimated_hours_saved"`
+	Ticket              *AutomationTicket      `json:"ticket,omitempty"`
+	Tracking            *AutomationOpportunity `json:"tracking,omitempty"`
+}
+
+// AutomationTicket is the Jira issue tracking the automation of a candidate
+type AutomationTicket struct {
+	ITProcessGroup string    `json:"it_process_group"`
+	IssueKey       string    `json:"issue_key"`
+	IssueURL       string    `json:"issue_url"`
+	ProjectKey     string    `json:"project_key"`
+	IssueType      string    `json:"issue_type"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TicketOptions selects where a ticket is created; empty fields fall back to the Jira configuration
+type TicketOptions struct {
+	ProjectKey string
+	IssueType  string
+}
+
+// AutomationTicketService turns automation candidates into Jira tracking issues
+type AutomationTicketService struct {
+	db         *sql.DB
+	jira       *JiraConfig
+	httpClient *http.Client
+}
+
+// NewAutomationTicketService creates a new AutomationTicketService instance. jira may be nil,
+// in which case candidates can be inspected but no tickets created.
+func NewAutomationTicketService(db *sql.DB, jira *JiraConfig) *AutomationTicketService {
+	return &AutomationTicketService{
+		db:         db,
+		jira:       jira,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// SetJiraConfig replaces the Jira site and credentials tickets are created with
+func (s *AutomationTicketService) SetJiraConfig(jira *JiraConfig) {
+	s.jira = jira
+}
+
+// GetCandidate returns the automation candidate for an IT process group within the filters,
+// or sql.ErrNoRows when the group has no automatable incidents
+func (s *AutomationTicketService) GetCandidate(ctx context.Context, processGroup string, filters *TimelineFilters, handlingMinutes int) (*AutomationCandidateDetail, error) {
+	if handlingMinutes <= 0 {
+		handlingMinutes = DefaultHandlingMinutes
+	}
+
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 2)
+	args = append([]interface{}{processGroup}, args...)
+
+	candidate := &AutomationCandidateDetail{
+		AutomationCandidate: AutomationCandidate{ITProcessGroup: processGroup},
+		Examples:            []CandidateIncident{},
+		HandlingMinutes:     handlingMinutes,
+	}
+	var avgScore sql.NullFloat64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) as incident_count,
+			COUNT(CASE WHEN automation_feasible = true THEN 1 END) as automatable_count,
+			AVG(automation_score) as avg_automation_score
+		FROM incidents
+		WHERE it_process_group = $1`+whereClause, args...).
+		Scan(&candidate.IncidentCount, &candidate.AutomatableCount, &avgScore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation candidate: %w", err)
+	}
+	if candidate.AutomatableCount == 0 {
+		return nil, sql.ErrNoRows
+	}
+	if avgScore.Valid {
+		candidate.AvgAutomationScore = avgScore.Float64
+	}
+	candidate.EstimatedHoursSaved = float64(candidate.AutomatableCount*handlingMinutes) / 60
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT incident_id, report_date, COALESCE(application_name, ''),
+			COALESCE(brief_description, ''), COALESCE(automation_score, 0)
+		FROM incidents
+		WHERE it_process_group = $1 AND automation_feasible = true`+whereClause+`
+		ORDER BY automation_score DESC, report_date DESC, incident_id
+		LIMIT `+fmt.Sprint(automationCandidateExampleLimit), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation candidate examples: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var example CandidateIncident
+		var reportDate time.Time
+		if err := rows.Scan(&example.IncidentID, &reportDate, &example.ApplicationName,
+			&example.BriefDescription, &example.AutomationScore); err != nil {
+			return nil, fmt.Errorf("failed to scan automation candidate example: %w", err)
+		}
+		example.ReportDate = reportDate.Format("2006-01-02")
+		candidate.Examples = append(candidate.Examples, example)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating automation candidate examples: %w", err)
+	}
+
+	runbooks, err := NewRunbookService(s.db).ListRunbooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	texts, err := queryDescriptions(ctx, s.db, `
+		SELECT COALESCE(brief_description, '') || ' ' || COALESCE(description, '')
+		FROM incidents
+		WHERE it_process_group = $1 AND automation_feasible = true`+whereClause, args...)
+	if err != nil {
+		return nil, err
+	}
+	candidate.flagRunbooks(runbooks, texts)
+
+	candidate.Ticket, err = s.GetTicket(ctx, processGroup)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	candidate.Tracking, err = getOpportunity(ctx, s.db, processGroup)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return candidate, nil
+}
+
+// GetTicket returns the ticket created for an IT process group, or sql.ErrNoRows
+func (s *AutomationTicketService) GetTicket(ctx context.Context, processGroup string) (*AutomationTicket, error) {
+	var ticket AutomationTicket
+	err := s.db.QueryRowContext(ctx, `
+		SELECT it_process_group, issue_key, issue_url, project_key, issue_type, created_at
+		FROM automation_tickets
+		WHERE it_process_group = ?
+	`, processGroup).Scan(&ticket.ITProcessGroup, &ticket.IssueKey, &ticket.IssueURL,
+		&ticket.ProjectKey, &ticket.IssueType, &ticket.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to query automation ticket: %w", err)
+	}
+	return &ticket, nil
+}
+
+// CreateTicket creates a Jira issue for the candidate and records its key. A candidate gets
+// one ticket: if one exists it is returned with created false and Jira is not called.
+func (s *AutomationTicketService) CreateTicket(ctx context.Context, candidate *AutomationCandidateDetail, options TicketOptions) (*AutomationTicket, bool, error) {
+	if candidate.Ticket != nil {
+		return candidate.Ticket, false, nil
+	}
+	if !s.jira.Configured() {
+		return nil, false, ErrJiraNotConfigured
+	}
+
+	ticket := &AutomationTicket{
+		ITProcessGroup: candidate.ITProcessGroup,
+		ProjectKey:     firstNonEmpty(options.ProjectKey, s.jira.ProjectKey),
+		IssueType:      firstNonEmpty(options.IssueType, s.jira.IssueType, defaultJiraIssueType),
+		CreatedAt:      time.Now(),
+	}
+	if ticket.ProjectKey == "" {
+		return nil, false, ErrMissingJiraProject
+	}
+
+	key, err := s.createJiraIssue(ctx, ticket, candidate)
+	if err != nil {
+		return nil, false, err
+	}
+	ticket.IssueKey = key
+	ticket.IssueURL = strings.TrimRight(s.jira.BaseURL, "/") + "/browse/" + key
+
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO automation_tickets (it_process_group, issue_key, issue_url, project_key, issue_type, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, ticket.ITProcessGroup, ticket.IssueKey, ticket.IssueURL, ticket.ProjectKey, ticket.IssueType,
+		ticket.CreatedAt); err != nil {
+		return nil, false, fmt.Errorf("failed to record jira issue %s: %w", key, err)
+	}
+
+	return ticket, true, nil
+}
+
+// createJiraIssue creates the issue through the Jira REST API and returns its key
+func (s *AutomationTicketService) createJiraIssue(ctx context.Context, ticket *AutomationTicket, candidate *AutomationCandidateDetail) (string, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": ticket.ProjectKey},
+			"issuetype":   map[string]string{"name": ticket.IssueType},
+			"summary":     fmt.Sprintf("Automate %s incidents", candidate.ITProcessGroup),
+			"description": candidateDescription(candidate),
+			"labels":      []string{"automation-candidate"},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode jira issue: %w", err)
+	}
+
+	endpoint := strings.TrimRight(s.jira.BaseURL, "/") + "/rest/api/2/issue"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrJiraRequestFailed, err)
+	}
+	req.SetBasicAuth(s.jira.Email, s.jira.APIToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrJiraRequestFailed, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrJiraRequestFailed, err)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		var jiraError struct {
+			ErrorMessages []string          `json:"errorMessages"`
+			Errors        map[string]string `json:"errors"`
+		}
+		messages := []string{resp.Status}
+		if json.Unmarshal(body, &jiraError) == nil {
+			messages = append(messages, jiraError.ErrorMessages...)
+			for field, message := range jiraError.Errors {
+				messages = append(messages, field+": "+message)
+			}
+		}
+		return "", fmt.Errorf("%w: %s", ErrJiraRequestFailed, strings.Join(messages, "; "))
+	}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal(body, &created); err != nil || created.Key == "" {
+		return "", fmt.Errorf("%w: response did not include an issue key", ErrJiraRequestFailed)
+	}
+	return created.Key, nil
+}
+
+// candidateDescription renders the issue description in Jira wiki markup
+func candidateDescription(candidate *AutomationCandidateDetail) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "h3. Summary\n")
+	fmt.Fprintf(&b, "* IT process group: %s\n", candidate.ITProcessGroup)
+	fmt.Fprintf(&b, "* Incidents: %d, of which automatable: %d\n", candidate.IncidentCount, candidate.AutomatableCount)
+	fmt.Fprintf(&b, "* Average automation score: %.2f\n", candidate.AvgAutomationScore)
+	fmt.Fprintf(&b, "* Estimated savings: %.1f hours (%d automatable incidents at %d minutes each)\n",
+		candidate.EstimatedHoursSaved, candidate.AutomatableCount, candidate.HandlingMinutes)
+
+	if len(candidate.Examples) > 0 {
+		fmt.Fprintf(&b, "\nh3. Example incidents\n")
+		fmt.Fprintf(&b, "||Incident||Reported||Application||Description||Score||\n")
+		for _, example := range candidate.Examples {
+			fmt.Fprintf(&b, "|%s|%s|%s|%s|%.2f|\n", example.IncidentID, example.ReportDate,
+				jiraCell(example.ApplicationName), jiraCell(example.BriefDescription), example.AutomationScore)
+		}
+	}
+	return b.String()
+}
+
+// jiraCell makes a value safe to place in a wiki markup table cell
+func jiraCell(value string) string {
+	value = strings.NewReplacer("|", "/", "\n", " ", "\r", " ").Replace(value)
+	if value == "" {
+		return " "
+	}
+	return value
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestAutomationTicketService_CreateTicket(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P3", "Closed"),
+		diffTestIncident("i2", "upload-1", "INC002", "P3", "Closed"),
+		diffTestIncident("i3", "upload-1", "INC003", "P3", "Closed"),
+		diffTestIncident("i4", "upload-1", "INC004", "P3", "Closed"),
+	}
+	for i := range incidents {
+		incidents[i].ITProcessGroup = "Access Management"
+		feasible, score := i < 3, 0.5+float64(i)/10
+		incidents[i].AutomationFeasible = &feasible
+		incidents[i].AutomationScore = &score
+	}
+	incidents[1].BriefDescription = "Password reset | locked out"
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	var requests []map[string]map[string]interface{}
+	jira := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, ok := r.BasicAuth(); !ok || user != "bot@example.com" || token != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body map[string]map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, body)
+
+		if body["fields"]["project"].(map[string]interface{})["key"] == "NOPE" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errorMessages":[],"errors":{"project":"valid project is required"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"10001","key":"AUTO-7","self":"` + r.Host + `/rest/api/2/issue/10001"}`))
+	}))
+	defer jira.Close()
+
+	service := NewAutomationTicketService(db, nil)
+
+	candidate, err := service.GetCandidate(ctx, "Access Management", nil, 20)
+	if err != nil {
+		t.Fatalf("Failed to get candidate: %v", err)
+	}
+	if candidate.IncidentCount != 4 || candidate.AutomatableCount != 3 || candidate.EstimatedHoursSaved != 1 {
+		t.Errorf("Expected 4 incidents, 3 automatable and 1 hour saved, got %+v", candidate)
+	}
+	if len(candidate.Examples) != 3 || candidate.Examples[0].IncidentID != "INC003" {
+		t.Errorf("Expected the 3 automatable incidents by score, got %+v", candidate.Examples)
+	}
+
+	if _, err := service.GetCandidate(ctx, "Networking", nil, 0); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for a group without automatable incidents, got %v", err)
+	}
+
+	if _, _, err := service.CreateTicket(ctx, candidate, TicketOptions{}); !errors.Is(err, ErrJiraNotConfigured) {
+		t.Fatalf("Expected ErrJiraNotConfigured, got %v", err)
+	}
+
+	service.SetJiraConfig(&JiraConfig{BaseURL: jira.URL, Email: "bot@example.com", APIToken: "secret"})
+	if _, _, err := service.CreateTicket(ctx, candidate, TicketOptions{}); !errors.Is(err, ErrMissingJiraProject) {
+		t.Fatalf("Expected ErrMissingJiraProject, got %v", err)
+	}
+
+	_, _, err = service.CreateTicket(ctx, candidate, TicketOptions{ProjectKey: "NOPE"})
+	if !errors.Is(err, ErrJiraRequestFailed) || !strings.Contains(err.Error(), "valid project is required") {
+		t.Fatalf("Expected the Jira error to be reported, got %v", err)
+	}
+
+	ticket, created, err := service.CreateTicket(ctx, candidate, TicketOptions{ProjectKey: "AUTO", IssueType: "Story"})
+	if err != nil {
+		t.Fatalf("Failed to create ticket: %v", err)
+	}
+	if !created || ticket.IssueKey != "AUTO-7" || ticket.IssueURL != jira.URL+"/browse/AUTO-7" || ticket.IssueType != "Story" {
+		t.Errorf("Unexpected ticket: %+v (created %v)", ticket, created)
+	}
+
+	fields := requests[len(requests)-1]["fields"]
+	description := fields["description"].(string)
+	if fields["summary"] != "Automate Access Management incidents" ||
+		!strings.Contains(description, "Estimated savings: 1.0 hours") ||
+		!strings.Contains(description, "|INC002|") ||
+		!strings.Contains(description, "Password reset / locked out") {
+		t.Errorf("Unexpected issue fields: %v", fields)
+	}
+
+	// The stored key is returned without creating another issue
+	candidate, err = service.GetCandidate(ctx, "Access Management", nil, 0)
+	if err != nil {
+		t.Fatalf("Failed to get candidate: %v", err)
+	}
+	if candidate.Ticket == nil || candidate.Ticket.IssueKey != "AUTO-7" {
+		t.Fatalf("Expected the candidate to reference AUTO-7, got %+v", candidate.Ticket)
+	}
+	calls := len(requests)
+	if _, created, err := service.CreateTicket(ctx, candidate, TicketOptions{ProjectKey: "AUTO"}); err != nil || created {
+		t.Errorf("Expected the existing ticket to be returned, got created=%v err=%v", created, err)
+	}
+	if len(requests) != calls {
+		t.Error("Expected no further Jira requests for an existing ticket")
+	}
+}
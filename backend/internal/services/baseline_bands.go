@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// MaxBaselineWeeks is the most prior weeks a timeline's baseline bands may be computed from
+const MaxBaselineWeeks = 52
+
+// BaselineBand is the normal range of a timeline period: the mean and spread of the incidents in
+// the same period of the weeks before, clipped at zero
+type BaselineBand struct {
+	Weeks  int     `json:"weeks"` // prior weeks the band was computed from
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+	// Lower1 and Upper1 lie one standard deviation from the mean, Lower2 and Upper2 two
+	Lower1 float64 `json:"lower_1sd"`
+	Upper1 float64 `json:"upper_1sd"`
+	Lower2 float64 `json:"lower_2sd"`
+	Upper2 float64 `json:"upper_2sd"`
+}
+
+// timelineHistory counts the incidents matching the filters per unit of column, starting weeks
+// weeks before the filters' start date, so every period of the timeline has its prior weeks.
+// History never reaches before the start date of the API key making the request, whose incidents
+// the key may not read. It also returns the first period with incidents, before which there is
+// no history.
+func (s *AnalyticsService) timelineHistory(ctx context.Context, filters *TimelineFilters, column, unit string, weeks int) (map[int64]int, time.Time, error) {
+	scoped := *filters
+	if filters.StartDate != nil {
+		historyStart := filters.StartDate.AddDate(0, 0, -7*weeks)
+		if key := APIKeyFromContext(ctx); key != nil && key.StartDate != "" {
+			keyStart, err := time.Parse("2006-01-02", key.StartDate)
+			if err != nil {
+				return nil, time.Time{}, fmt.Errorf("invalid start date of API key %s: %w", key.ID, err)
+			}
+			if historyStart.Before(keyStart) {
+				historyStart = keyStart
+			}
+		}
+		scoped.StartDate = &historyStart
+	}
+
+	query := `
+		SELECT DATE_TRUNC('` + unit + `', ` + column + `) as period, COUNT(*) as incident_count
+		FROM incidents
+		WHERE ` + column + ` IS NOT NULL`
+	whereClause, args, _ := buildFilterConditions(ctx, &scoped, 1)
+	query += whereClause
+	query += " GROUP BY DATE_TRUNC('" + unit + "', " + column + ")"
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, &scoped), args...)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to query timeline history: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[int64]int{}
+	var first time.Time
+	for rows.Next() {
+		var period time.Time
+		var count int
+		if err := rows.Scan(&period, &count); err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to scan timeline history row: %w", err)
+		}
+		counts[period.Unix()] = count
+		if first.IsZero() || period.Before(first) {
+			first = period
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("error iterating timeline history rows: %w", err)
+	}
+	return counts, first, nil
+}
+
+// baselineBand computes the band of the period from the counts of the same period in up to weeks
+// weeks before it, periods without incidents counting as zero. Weeks before first, the earliest
+// recorded period, are not counted; nil is returned when none is left.
+func baselineBand(counts map[int64]int, first, period time.Time, weeks int) *BaselineBand {
+	var values []float64
+	for week := 1; week <= weeks; week++ {
+		prior := period.AddDate(0, 0, -7*week)
+		if prior.Before(first) {
+			break
+		}
+		values = append(values, float64(counts[prior.Unix()]))
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+	var squares float64
+	for _, value := range values {
+		squares += (value - mean) * (value - mean)
+	}
+	stdDev := math.Sqrt(squares / float64(len(values)))
+
+	return &BaselineBand{
+		Weeks:  len(values),
+		Mean:   roundTo(mean, 2),
+		StdDev: roundTo(stdDev, 2),
+		Lower1: roundTo(math.Max(mean-stdDev, 0), 2),
+		Upper1: roundTo(mean+stdDev, 2),
+		Lower2: roundTo(math.Max(mean-2*stdDev, 0), 2),
+		Upper2: roundTo(mean+2*stdDev, 2),
+	}
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/models"
+)
+
+func TestAnalyticsService_TimelineBaselineBands(t *testing.T) {
+	// Mondays 2024-01-01, 01-08 and 01-15 have 2, 4 and 3 incidents; Tuesday 01-16 has one
+	var incidents []models.Incident
+	for day, count := range map[int]int{1: 2, 8: 4, 15: 3, 16: 1} {
+		for i := 0; i < count; i++ {
+			incident := diffTestIncident(fmt.Sprint(day, "-", i), "upload-1", fmt.Sprint("INC-", day, "-", i), "P3", "Closed")
+			incident.ReportDate = time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)
+			incidents = append(incidents, incident)
+		}
+	}
+	dbWrapper, db := newSentimentTestDB(t, incidents)
+	defer dbWrapper.Close()
+	service := NewAnalyticsService(db)
+	ctx := context.Background()
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	timeline, err := service.GetDailyTimeline(ctx, &TimelineFilters{StartDate: &start, BaselineWeeks: 4})
+	if err != nil {
+		t.Fatalf("GetDailyTimeline() error = %v", err)
+	}
+	if len(timeline) != 2 {
+		t.Fatalf("expected the days from the start date only, got %+v", timeline)
+	}
+	// Weeks before the first recorded incident are not counted
+	expected := BaselineBand{Weeks: 2, Mean: 3, StdDev: 1, Lower1: 2, Upper1: 4, Lower2: 1, Upper2: 5}
+	if band := timeline[0].Baseline; band == nil || *band != expected {
+		t.Errorf("expected the Monday band %+v, got %+v", expected, band)
+	}
+	// Tuesdays without incidents count as zero
+	if band := timeline[1].Baseline; band == nil || band.Weeks != 2 || band.Mean != 0 || band.Upper2 != 0 {
+		t.Errorf("unexpected Tuesday band: %+v", band)
+	}
+
+	weekly, err := service.GetWeeklyTimeline(ctx, &TimelineFilters{StartDate: &start, BaselineWeeks: 1})
+	if err != nil {
+		t.Fatalf("GetWeeklyTimeline() error = %v", err)
+	}
+	if len(weekly) != 1 || weekly[0].Baseline == nil || weekly[0].Baseline.Weeks != 1 || weekly[0].Baseline.Mean != 4 {
+		t.Errorf("expected the week compared with the week before, got %+v", weekly)
+	}
+
+	// An API key limited from 2024-01-08 gets no history from before then
+	keyed := WithAPIKey(ctx, &APIKey{ID: "key-1", StartDate: "2024-01-08"})
+	timeline, err = service.GetDailyTimeline(keyed, &TimelineFilters{StartDate: &start, BaselineWeeks: 4})
+	if err != nil {
+		t.Fatalf("GetDailyTimeline() error = %v", err)
+	}
+	if band := timeline[0].Baseline; band == nil || band.Weeks != 1 || band.Mean != 4 {
+		t.Errorf("expected the Monday band from the key's start date only, got %+v", band)
+	}
+
+	plain, err := service.GetDailyTimeline(ctx, &TimelineFilters{StartDate: &start})
+	if err != nil {
+		t.Fatalf("GetDailyTimeline() error = %v", err)
+	}
+	if plain[0].Baseline != nil {
+		t.Errorf("expected no band without baseline weeks, got %+v", plain[0].Baseline)
+	}
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Benchmark dimensions
+const (
+	BenchmarkByApplication = "application"
+	BenchmarkByGroup       = "group"
+)
+
+// Benchmark defaults
+const (
+	DefaultBenchmarkMinIncidents = 5
+	DefaultBenchmarkZThreshold   = 2.0
+)
+
+// Benchmarked metric names, used in BenchmarkEntry.Flags
+const (
+	BenchmarkMetricResolutionTime = "resolution_time"
+	BenchmarkMetricP1Rate         = "p1_rate"
+	BenchmarkMetricReopenRate     = "reopen_rate"
+)
+
+// reopenedCondition matches incidents marked reopened or resolved again after their first resolution
+const reopenedCondition = `(LOWER(status) = 'reopened'
+	OR (last_resolve_date IS NOT NULL AND resolve_date IS NOT NULL AND last_resolve_date > resolve_date))`
+
+// benchmarkDimensionColumns maps each benchmark dimension to the incidents column it groups by
+var benchmarkDimensionColumns = map[string]string{
+	BenchmarkByApplication: "application_name",
+	BenchmarkByGroup:       "resolution_group",
+}
+
+// BenchmarkOptions controls which entities are benchmarked and when they are flagged
+type BenchmarkOptions struct {
+	// MinIncidents excludes entities with too few incidents to compare meaningfully
+	MinIncidents int
+	// ZThreshold is the number of standard deviations above the portfolio average
+	// at which a metric is flagged as an outlier
+	ZThreshold float64
+}
+
+// BenchmarkStats holds the raw per-entity metrics a benchmark is computed from
+type BenchmarkStats struct {
+	Name              string
+	IncidentCount     int
+	AvgResolutionTime *float64
+	P1Rate            float64
+	ReopenRate        float64
+}
+
+// BenchmarkMetric compares one metric of an entity against the portfolio
+type BenchmarkMetric struct {
+	Value            float64 `json:"value"`
+	PortfolioAverage float64 `json:"portfolio_average"`
+	PercentileRank   float64 `json:"percentile_rank"`
+	ZScore           float64 `json:"z_score"`
+	Outlier          bool    `json:"outlier"`
+}
+
+// BenchmarkEntry is the benchmark of one application or resolution group
+type BenchmarkEntry struct {
+	Name           string           `json:"name"`
+	IncidentCount  int              `json:"incident_count"`
+	ResolutionTime *BenchmarkMetric `json:"resolution_time,omitempty"`
+	P1Rate         BenchmarkMetric  `json:"p1_rate"`
+	ReopenRate     BenchmarkMetric  `json:"reopen_rate"`
+	Flags          []string         `json:"flags"`
+}
+
+// BenchmarkReport compares every entity of a dimension against the portfolio.
+// Entries are ordered with flagged entities first, then by incident count.
+type BenchmarkReport struct {
+	Dimension     string           `json:"dimension"`
+	MinIncidents  int              `json:"min_incidents"`
+	ZThreshold    float64          `json:"z_threshold"`
+	EntityCount   int              `json:"entity_count"`
+	OutlierCount  int              `json:"outlier_count"`
+	ExcludedCount int              `json:"excluded_count"`
+	Entries       []BenchmarkEntry `json:"entries"`
+}
+
+// IsValidBenchmarkDimension reports whether dimension can be benchmarked
+func IsValidBenchmarkDimension(dimension string) bool {
+	_, ok := benchmarkDimensionColumns[dimension]
+	return ok
+}
+
+// GetBenchmark compares each application or resolution group against the portfolio average
+// for resolution time, P1 rate and reopen rate. An incident counts as reopened when its
+// status is "Reopened" or it was resolved again after its first resolution.
+func (s *AnalyticsService) GetBenchmark(ctx context.Context, dimension string, filters *TimelineFilters, opts BenchmarkOptions) (*BenchmarkReport, error) {
+	column, ok := benchmarkDimensionColumns[dimension]
+	if !ok {
+		return nil, fmt.Errorf("invalid benchmark dimension: %s", dimension)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			%s as name,
+			COUNT(*) as incident_count,
+			AVG(%s) as avg_resolution_time,
+			COUNT(CASE WHEN priority = 'P1' THEN 1 END) * 100.0 / COUNT(*) as p1_rate,
+			COUNT(CASE WHEN %s THEN 1 END) * 100.0 / COUNT(*) as reopen_rate
+		FROM incidents
+		WHERE 1=1`, column, resolutionHoursExpr, reopenedCondition)
+
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query += whereClause
+	query += fmt.Sprintf(" GROUP BY %s", column)
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query benchmark stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []BenchmarkStats
+	for rows.Next() {
+		var data BenchmarkStats
+		var avgResolutionTime sql.NullFloat64
+
+		if err := rows.Scan(&data.Name, &data.IncidentCount, &avgResolutionTime, &data.P1Rate, &data.ReopenRate); err != nil {
+			return nil, fmt.Errorf("failed to scan benchmark row: %w", err)
+		}
+		if avgResolutionTime.Valid {
+			value := avgResolutionTime.Float64
+			data.AvgResolutionTime = &value
+		}
+		stats = append(stats, data)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating benchmark rows: %w", err)
+	}
+
+	return BuildBenchmarkReport(dimension, stats, opts), nil
+}
+
+// BuildBenchmarkReport ranks per-entity stats against the portfolio. The portfolio average is the
+// mean across eligible entities, so large applications do not dominate the baseline. For all three
+// metrics higher is worse, so only values above the average are flagged.
+func BuildBenchmarkReport(dimension string, stats []BenchmarkStats, opts BenchmarkOptions) *BenchmarkReport {
+	if opts.MinIncidents <= 0 {
+		opts.MinIncidents = DefaultBenchmarkMinIncidents
+	}
+	if opts.ZThreshold <= 0 {
+		opts.ZThreshold = DefaultBenchmarkZThreshold
+	}
+
+	report := &BenchmarkReport{
+		Dimension:    dimension,
+		MinIncidents: opts.MinIncidents,
+		ZThreshold:   opts.ZThreshold,
+		Entries:      []BenchmarkEntry{},
+	}
+
+	var eligible []BenchmarkStats
+	for _, stat := range stats {
+		if stat.IncidentCount < opts.MinIncidents {
+			report.ExcludedCount++
+			continue
+		}
+		eligible = append(eligible, stat)
+	}
+
+	var resolutionTimes, p1Rates, reopenRates []float64
+	for _, stat := range eligible {
+		if stat.AvgResolutionTime != nil {
+			resolutionTimes = append(resolutionTimes, *stat.AvgResolutionTime)
+		}
+		p1Rates = append(p1Rates, stat.P1Rate)
+		reopenRates = append(reopenRates, stat.ReopenRate)
+	}
+
+	for _, stat := range eligible {
+		entry := BenchmarkEntry{
+			Name:          stat.Name,
+			IncidentCount: stat.IncidentCount,
+			P1Rate:        benchmarkMetric(stat.P1Rate, p1Rates, opts.ZThreshold),
+			ReopenRate:    benchmarkMetric(stat.ReopenRate, reopenRates, opts.ZThreshold),
+			Flags:         []string{},
+		}
+		if stat.AvgResolutionTime != nil {
+			metric := benchmarkMetric(*stat.AvgResolutionTime, resolutionTimes, opts.ZThreshold)
+			entry.ResolutionTime = &metric
+			if metric.Outlier {
+				entry.Flags = append(entry.Flags, BenchmarkMetricResolutionTime)
+			}
+		}
+		if entry.P1Rate.Outlier {
+			entry.Flags = append(entry.Flags, BenchmarkMetricP1Rate)
+		}
+		if entry.ReopenRate.Outlier {
+			entry.Flags = append(entry.Flags, BenchmarkMetricReopenRate)
+		}
+		if len(entry.Flags) > 0 {
+			report.OutlierCount++
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+
+	sort.SliceStable(report.Entries, func(i, j int) bool {
+		a, b := report.Entries[i], report.Entries[j]
+		if len(a.Flags) != len(b.Flags) {
+			return len(a.Flags) > len(b.Flags)
+		}
+		if a.IncidentCount != b.IncidentCount {
+			return a.IncidentCount > b.IncidentCount
+		}
+		return a.Name < b.Name
+	})
+
+	report.EntityCount = len(report.Entries)
+	return report
+}
+
+// benchmarkMetric scores value against the population of values for all eligible entities
+func benchmarkMetric(value float64, population []float64, zThreshold float64) BenchmarkMetric {
+	metric := BenchmarkMetric{Value: value}
+	if len(population) == 0 {
+		return metric
+	}
+
+	var sum float64
+	for _, v := range population {
+		sum += v
+	}
+	mean := sum / float64(len(population))
+
+	var squares float64
+	var below, equal int
+	for _, v := range population {
+		squares += (v - mean) * (v - mean)
+		switch {
+		case v < value:
+			below++
+		case v == value:
+			equal++
+		}
+	}
+	stdDev := math.Sqrt(squares / float64(len(population)))
+
+	metric.PortfolioAverage = mean
+	// Ties count half, so identical entities share the middle rank
+	metric.PercentileRank = (float64(below) + 0.5*float64(equal)) / float64(len(population)) * 100
+	if stdDev > 0 {
+		metric.ZScore = (value - mean) / stdDev
+		metric.Outlier = metric.ZScore >= zThreshold
+	}
+
+	return metric
+}
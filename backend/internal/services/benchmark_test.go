@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestBuildBenchmarkReport(t *testing.T) {
+	var stats []BenchmarkStats
+	for i := 0; i < 9; i++ {
+		stats = append(stats, BenchmarkStats{
+			Name:              fmt.Sprintf("App %d", i),
+			IncidentCount:     10,
+			AvgResolutionTime: floatPtr(10),
+			P1Rate:            10,
+			ReopenRate:        5,
+		})
+	}
+	stats = append(stats,
+		BenchmarkStats{Name: "Slow App", IncidentCount: 12, AvgResolutionTime: floatPtr(80), P1Rate: 10, ReopenRate: 5},
+		BenchmarkStats{Name: "Tiny App", IncidentCount: 2, AvgResolutionTime: floatPtr(500), P1Rate: 100, ReopenRate: 100},
+	)
+
+	report := BuildBenchmarkReport(BenchmarkByApplication, stats, BenchmarkOptions{})
+
+	if report.MinIncidents != DefaultBenchmarkMinIncidents || report.ZThreshold != DefaultBenchmarkZThreshold {
+		t.Errorf("Expected default options, got min=%d z=%v", report.MinIncidents, report.ZThreshold)
+	}
+	if report.EntityCount != 10 || report.ExcludedCount != 1 {
+		t.Errorf("Expected 10 entities and 1 excluded, got %d and %d", report.EntityCount, report.ExcludedCount)
+	}
+	if report.OutlierCount != 1 {
+		t.Fatalf("Expected 1 outlier, got %d", report.OutlierCount)
+	}
+
+	slow := report.Entries[0]
+	if slow.Name != "Slow App" {
+		t.Fatalf("Expected flagged entity first, got %s", slow.Name)
+	}
+	if len(slow.Flags) != 1 || slow.Flags[0] != BenchmarkMetricResolutionTime {
+		t.Errorf("Expected resolution_time flag, got %v", slow.Flags)
+	}
+	if slow.ResolutionTime.PortfolioAverage != 17 {
+		t.Errorf("Expected portfolio average 17, got %v", slow.ResolutionTime.PortfolioAverage)
+	}
+	if slow.ResolutionTime.PercentileRank != 95 {
+		t.Errorf("Expected percentile rank 95, got %v", slow.ResolutionTime.PercentileRank)
+	}
+
+	// Identical values have no spread, so nothing is flagged and ranks sit in the middle
+	if report.Entries[1].P1Rate.Outlier || report.Entries[1].P1Rate.PercentileRank != 50 {
+		t.Errorf("Unexpected P1 rate benchmark: %+v", report.Entries[1].P1Rate)
+	}
+}
+
+func TestAnalyticsService_GetBenchmark(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+
+	resolved := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+	reresolved := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	var incidents []models.Incident
+	for i := 0; i < 4; i++ {
+		incident := diffTestIncident(fmt.Sprintf("a%d", i), "upload-1", fmt.Sprintf("INCA%d", i), "P1", "Closed")
+		incident.ApplicationName = "Billing"
+		incident.ResolveDate = &resolved
+		if i == 0 {
+			incident.LastResolveDate = &reresolved
+		}
+		incidents = append(incidents, incident)
+	}
+	for i := 0; i < 2; i++ {
+		incident := diffTestIncident(fmt.Sprintf("b%d", i), "upload-1", fmt.Sprintf("INCB%d", i), "P3", "Open")
+		incident.ApplicationName = "Portal"
+		incidents = append(incidents, incident)
+	}
+
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	report, err := NewAnalyticsService(db).GetBenchmark(ctx, BenchmarkByApplication, nil, BenchmarkOptions{MinIncidents: 1})
+	if err != nil {
+		t.Fatalf("Failed to get benchmark: %v", err)
+	}
+	if report.EntityCount != 2 {
+		t.Fatalf("Expected 2 entities, got %+v", report.Entries)
+	}
+
+	billing := report.Entries[0]
+	if billing.Name != "Billing" || billing.P1Rate.Value != 100 || billing.ReopenRate.Value != 25 {
+		t.Errorf("Unexpected Billing benchmark: %+v", billing)
+	}
+	if billing.P1Rate.PortfolioAverage != 50 {
+		t.Errorf("Expected P1 portfolio average 50, got %v", billing.P1Rate.PortfolioAverage)
+	}
+
+	if _, err := NewAnalyticsService(db).GetBenchmark(ctx, "team", nil, BenchmarkOptions{}); err == nil {
+		t.Error("Expected error for invalid benchmark dimension")
+	}
+}
@@ -2,9 +2,15 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
@@ -92,6 +98,35 @@ func (c *CacheService) Close() {
 type CachedAnalyticsService struct {
 	*AnalyticsService
 	cache *CacheService
+	ttl   atomic.Int64 // Lifetime of new entries in nanoseconds; 0 means the default config's TTL
+
+	// flights holds the queries running for missed keys, so identical lookups arriving while
+	// one runs wait for its result instead of querying again
+	flightMu sync.Mutex
+	flights  map[string]*cacheFlight
+
+	statsMu sync.Mutex
+	stats   map[string]*EndpointCacheStats
+
+	warmMu sync.Mutex // Runs one cache warm at a time
+}
+
+// cacheFlight is a query running for a missed cache key
+type cacheFlight struct {
+	done chan struct{}
+	data interface{}
+	err  error
+}
+
+// EndpointCacheStats counts the cached lookups of one analytics endpoint
+type EndpointCacheStats struct {
+	Endpoint string `json:"endpoint"`
+	Hits     int64  `json:"hits"`
+	Misses   int64  `json:"misses"`
+	// Shared counts misses answered by an identical query that was already running
+	Shared int64 `json:"shared"`
+	// HitRate is the percentage of lookups answered without a query of their own
+	HitRate float64 `json:"hit_rate"`
 }
 
 // NewCachedAnalyticsService creates a new cached analytics service
@@ -101,60 +136,242 @@ func NewCachedAnalyticsService(analyticsService *AnalyticsService, cacheConfig *
 		return nil, fmt.Errorf("failed to create cache service: %w", err)
 	}
 
-	return &CachedAnalyticsService{
+	service := &CachedAnalyticsService{
 		AnalyticsService: analyticsService,
 		cache:           cache,
-	}, nil
+	}
+	if cacheConfig != nil {
+		service.ttl.Store(int64(cacheConfig.TTL))
+	}
+	return service, nil
 }
 
-// buildCacheKey creates a cache key from filters
-func buildCacheKey(prefix string, filters *TimelineFilters) string {
-	if filters == nil {
-		return prefix
+// SetTTL changes how long analytics results stay cached. Entries cached under the old TTL are
+// dropped so the new one applies at once.
+func (s *CachedAnalyticsService) SetTTL(ttl time.Duration) {
+	s.ttl.Store(int64(ttl))
+	if s.cache != nil {
+		s.cache.Clear()
 	}
+}
 
-	key := prefix
-	if filters.StartDate != nil {
-		key += fmt.Sprintf("_start:%s", filters.StartDate.Format("2006-01-02"))
+// cacheTTL returns the lifetime of new cache entries
+func (s *CachedAnalyticsService) cacheTTL() time.Duration {
+	if ttl := time.Duration(s.ttl.Load()); ttl > 0 {
+		return ttl
 	}
-	if filters.EndDate != nil {
-		key += fmt.Sprintf("_end:%s", filters.EndDate.Format("2006-01-02"))
+	return DefaultCacheConfig().TTL
+}
+
+// federatedKeySuffix marks the cache keys of results that include archived incidents
+const federatedKeySuffix = "_federated"
+
+// normalizeFilters returns a copy of filters in canonical form, so filters asking for the same
+// incidents are cached under one key: list values are trimmed, deduplicated and sorted, empty
+// values dropped, the holiday region normalized and including maintenance windows, the
+// default, left unset
+func normalizeFilters(filters *TimelineFilters) *TimelineFilters {
+	if filters == nil {
+		return nil
+	}
+	normalized := *filters
+	normalized.Priorities = canonicalValues(filters.Priorities)
+	normalized.Applications = canonicalValues(filters.Applications)
+	normalized.Statuses = canonicalValues(filters.Statuses)
+	normalized.Regions = canonicalValues(filters.Regions)
+	normalized.Owners = canonicalValues(filters.Owners)
+	normalized.Environments = canonicalValues(filters.Environments)
+	normalized.Criticalities = canonicalValues(filters.Criticalities)
+	normalized.DatasetID = strings.TrimSpace(filters.DatasetID)
+	normalized.Maintenance = strings.TrimSpace(filters.Maintenance)
+	if normalized.Maintenance == MaintenanceInclude {
+		normalized.Maintenance = ""
 	}
-	if len(filters.Priorities) > 0 {
-		key += fmt.Sprintf("_prios:%v", filters.Priorities)
+	normalized.HolidayRegion = NormalizeRegion(filters.HolidayRegion)
+	normalized.Holidays = strings.TrimSpace(filters.Holidays)
+	if len(filters.Percentiles) > 0 {
+		percentiles := append([]float64(nil), filters.Percentiles...)
+		sort.Float64s(percentiles)
+		normalized.Percentiles = percentiles[:0]
+		for i, percentile := range percentiles {
+			if i == 0 || percentile != percentiles[i-1] {
+				normalized.Percentiles = append(normalized.Percentiles, percentile)
+			}
+		}
 	}
-	if len(filters.Applications) > 0 {
-		key += fmt.Sprintf("_apps:%v", filters.Applications)
+	return &normalized
+}
+
+// canonicalValues trims, deduplicates and sorts filter values, dropping empty ones
+func canonicalValues(values []string) []string {
+	trimmed := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			trimmed = append(trimmed, value)
+		}
 	}
-	if len(filters.Statuses) > 0 {
-		key += fmt.Sprintf("_statuses:%v", filters.Statuses)
+	if len(trimmed) == 0 {
+		return nil
 	}
+	return uniqueSorted(trimmed)
+}
 
-	return key
+// buildCacheKey creates the cache key of an endpoint's result: the endpoint name followed by a
+// hash of its normalized filters and any other parameters it takes
+func buildCacheKey(endpoint string, filters *TimelineFilters, params ...interface{}) string {
+	normalized := normalizeFilters(filters)
+	if normalized == nil {
+		normalized = &TimelineFilters{}
+	}
+	canonical, _ := json.Marshal(struct {
+		Filters *TimelineFilters `json:"filters"`
+		Params  []interface{}    `json:"params,omitempty"`
+	}{normalized, params})
+	sum := sha256.Sum256(canonical)
+	return endpoint + ":" + hex.EncodeToString(sum[:16])
 }
 
-// getCachedOrFetch retrieves data from cache or fetches it
+// cacheEndpoint returns the endpoint a cache key was built for
+func cacheEndpoint(key string) string {
+	endpoint, _, _ := strings.Cut(key, ":")
+	return endpoint
+}
+
+// Lookup outcomes counted per endpoint
+const (
+	cacheLookupHit = iota
+	cacheLookupMiss
+	cacheLookupShared
+)
+
+// getCachedOrFetch retrieves data from cache or fetches it. Concurrent misses of the same key
+// share one fetch.
 func (s *CachedAnalyticsService) getCachedOrFetch(ctx context.Context, key string, fetchFunc func() (interface{}, error)) (interface{}, error) {
+	endpoint := cacheEndpoint(key)
+	key = s.contextKey(ctx, key)
+
 	// Try to get from cache first
-	if cached, found := s.cache.Get(key); found {
+	cached, found := s.cache.Get(key)
+	cacheTraceFromContext(ctx).record(found)
+	if found {
+		s.recordLookup(endpoint, cacheLookupHit)
 		return cached, nil
 	}
 
-	// Fetch from source
-	data, err := fetchFunc()
-	if err != nil {
-		return nil, err
+	data, shared, err := s.fetchOnce(ctx, key, fetchFunc)
+	if shared {
+		s.recordLookup(endpoint, cacheLookupShared)
+	} else {
+		s.recordLookup(endpoint, cacheLookupMiss)
 	}
+	return data, err
+}
+
+// contextKey qualifies a cache key with what the request's results depend on besides its filters
+func (s *CachedAnalyticsService) contextKey(ctx context.Context, key string) string {
+	// Results with and without archived incidents differ, and federation can be switched per tenant
+	if s.federates(ctx) {
+		key += federatedKeySuffix
+	}
+	// Restricted users see only part of the data, so each scope caches its own results
+	key += DataScopeFromContext(ctx).cacheKeySuffix()
+	// Sandboxes see the incidents through their overlay
+	key += SandboxFromContext(ctx).cacheKeySuffix()
+	return key
+}
+
+// fetchOnce runs fetchFunc for a missed key and caches its result, unless the same key is
+// already being fetched; then it waits for that fetch and shared reports that it did. A shared
+// fetch cancelled with the request that started it is run again for this one.
+func (s *CachedAnalyticsService) fetchOnce(ctx context.Context, key string, fetchFunc func() (interface{}, error)) (data interface{}, shared bool, err error) {
+	s.flightMu.Lock()
+	if flight, running := s.flights[key]; running {
+		s.flightMu.Unlock()
+		select {
+		case <-flight.done:
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+		if flight.err == nil || ctx.Err() != nil ||
+			!(errors.Is(flight.err, context.Canceled) || errors.Is(flight.err, context.DeadlineExceeded)) {
+			return flight.data, true, flight.err
+		}
+		data, err := fetchFunc()
+		return data, false, err
+	}
+	if s.flights == nil {
+		s.flights = make(map[string]*cacheFlight)
+	}
+	flight := &cacheFlight{done: make(chan struct{})}
+	s.flights[key] = flight
+	s.flightMu.Unlock()
+
+	defer func() {
+		s.flightMu.Lock()
+		delete(s.flights, key)
+		s.flightMu.Unlock()
+		close(flight.done)
+	}()
+
+	flight.data, flight.err = fetchFunc()
+	if flight.err != nil {
+		return nil, false, flight.err
+	}
+
+	s.store(key, flight.data)
+	return flight.data, false, nil
+}
 
-	// Store in cache
+// store caches a result under its full key, costed by its JSON size
+func (s *CachedAnalyticsService) store(key string, data interface{}) {
 	jsonData, _ := json.Marshal(data)
-	s.cache.Set(key, data, int64(len(jsonData)), 5*time.Minute)
+	s.cache.Set(key, data, int64(len(jsonData)), s.cacheTTL())
+}
+
+// recordLookup counts a cached lookup of an endpoint
+func (s *CachedAnalyticsService) recordLookup(endpoint string, outcome int) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	if s.stats == nil {
+		s.stats = make(map[string]*EndpointCacheStats)
+	}
+	stats, found := s.stats[endpoint]
+	if !found {
+		stats = &EndpointCacheStats{Endpoint: endpoint}
+		s.stats[endpoint] = stats
+	}
+	switch outcome {
+	case cacheLookupHit:
+		stats.Hits++
+	case cacheLookupShared:
+		stats.Shared++
+	default:
+		stats.Misses++
+	}
+}
 
-	return data, nil
+// CacheStats returns the lookups counted for each endpoint since the service started, ordered
+// by endpoint
+func (s *CachedAnalyticsService) CacheStats() []EndpointCacheStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	stats := make([]EndpointCacheStats, 0, len(s.stats))
+	for _, endpoint := range s.stats {
+		entry := *endpoint
+		if lookups := entry.Hits + entry.Misses + entry.Shared; lookups > 0 {
+			entry.HitRate = roundTo(float64(entry.Hits+entry.Shared)*100/float64(lookups), 2)
+		}
+		stats = append(stats, entry)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Endpoint < stats[j].Endpoint })
+	return stats
 }
 
 // GetDailyTimeline returns cached daily incident timeline data
 func (s *CachedAnalyticsService) GetDailyTimeline(ctx context.Context, filters *TimelineFilters) ([]TimelineData, error) {
+	filters = normalizeFilters(filters)
 	key := buildCacheKey("daily_timeline", filters)
 	
 	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
@@ -169,6 +386,7 @@ func (s *CachedAnalyticsService) GetDailyTimeline(ctx context.Context, filters *
 
 // GetWeeklyTimeline returns cached weekly incident timeline data
 func (s *CachedAnalyticsService) GetWeeklyTimeline(ctx context.Context, filters *TimelineFilters) ([]TimelineData, error) {
+	filters = normalizeFilters(filters)
 	key := buildCacheKey("weekly_timeline", filters)
 	
 	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
@@ -181,9 +399,25 @@ func (s *CachedAnalyticsService) GetWeeklyTimeline(ctx context.Context, filters
 	return result.([]TimelineData), nil
 }
 
+// GetHourlyTimeline returns cached hourly incident timeline data
+func (s *CachedAnalyticsService) GetHourlyTimeline(ctx context.Context, filters *TimelineFilters) ([]TimelineData, error) {
+	filters = normalizeFilters(filters)
+	key := buildCacheKey("hourly_timeline", filters)
+
+	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
+		return s.AnalyticsService.GetHourlyTimeline(ctx, filters)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]TimelineData), nil
+}
+
 // GetTrendAnalysis returns cached trend analysis data
 func (s *CachedAnalyticsService) GetTrendAnalysis(ctx context.Context, period string, filters *TimelineFilters) ([]TrendAnalysis, error) {
-	key := buildCacheKey(fmt.Sprintf("trend_analysis_%s", period), filters)
+	filters = normalizeFilters(filters)
+	key := buildCacheKey("trend_analysis", filters, period)
 	
 	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
 		return s.AnalyticsService.GetTrendAnalysis(ctx, period, filters)
@@ -197,6 +431,7 @@ func (s *CachedAnalyticsService) GetTrendAnalysis(ctx context.Context, period st
 
 // GetPriorityAnalysis returns cached priority analysis data
 func (s *CachedAnalyticsService) GetPriorityAnalysis(ctx context.Context, filters *TimelineFilters) ([]PriorityAnalysis, error) {
+	filters = normalizeFilters(filters)
 	key := buildCacheKey("priority_analysis", filters)
 	
 	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
@@ -211,6 +446,7 @@ func (s *CachedAnalyticsService) GetPriorityAnalysis(ctx context.Context, filter
 
 // GetApplicationAnalysis returns cached application analysis data
 func (s *CachedAnalyticsService) GetApplicationAnalysis(ctx context.Context, filters *TimelineFilters) ([]ApplicationAnalysis, error) {
+	filters = normalizeFilters(filters)
 	key := buildCacheKey("application_analysis", filters)
 	
 	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
@@ -223,8 +459,69 @@ func (s *CachedAnalyticsService) GetApplicationAnalysis(ctx context.Context, fil
 	return result.([]ApplicationAnalysis), nil
 }
 
+// GetGroupAnalysis returns cached org hierarchy group analysis data
+func (s *CachedAnalyticsService) GetGroupAnalysis(ctx context.Context, level, unit string, filters *TimelineFilters) ([]GroupAnalysis, error) {
+	filters = normalizeFilters(filters)
+	key := buildCacheKey("group_analysis", filters, level, unit)
+	
+	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
+		return s.AnalyticsService.GetGroupAnalysis(ctx, level, unit, filters)
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	return result.([]GroupAnalysis), nil
+}
+
+// GetBenchmark returns a cached benchmark report
+func (s *CachedAnalyticsService) GetBenchmark(ctx context.Context, dimension string, filters *TimelineFilters, opts BenchmarkOptions) (*BenchmarkReport, error) {
+	filters = normalizeFilters(filters)
+	key := buildCacheKey("benchmark", filters, dimension, opts.MinIncidents, opts.ZThreshold)
+	
+	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
+		return s.AnalyticsService.GetBenchmark(ctx, dimension, filters, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	return result.(*BenchmarkReport), nil
+}
+
+// GetCapacityPlan returns a cached capacity plan
+func (s *CachedAnalyticsService) GetCapacityPlan(ctx context.Context, filters *TimelineFilters, opts CapacityOptions) (*CapacityPlan, error) {
+	filters = normalizeFilters(filters)
+	key := buildCacheKey("capacity", filters, opts.HistoryWeeks, opts.ForecastWeeks, opts.ProductiveHours, opts.HandlingHours)
+	
+	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
+		return s.AnalyticsService.GetCapacityPlan(ctx, filters, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	return result.(*CapacityPlan), nil
+}
+
+// GetNotesQuality returns a cached resolution notes quality report
+func (s *CachedAnalyticsService) GetNotesQuality(ctx context.Context, filters *TimelineFilters, limit int) (*NotesQualityReport, error) {
+	filters = normalizeFilters(filters)
+	key := buildCacheKey("notes_quality", filters, limit)
+	
+	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
+		return s.AnalyticsService.GetNotesQuality(ctx, filters, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	return result.(*NotesQualityReport), nil
+}
+
 // GetSentimentAnalysis returns cached sentiment analysis data
 func (s *CachedAnalyticsService) GetSentimentAnalysis(ctx context.Context, filters *TimelineFilters) ([]SentimentAnalysis, error) {
+	filters = normalizeFilters(filters)
 	key := buildCacheKey("sentiment_analysis", filters)
 	
 	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
@@ -237,8 +534,39 @@ func (s *CachedAnalyticsService) GetSentimentAnalysis(ctx context.Context, filte
 	return result.([]SentimentAnalysis), nil
 }
 
+// GetSentimentTimeline returns cached sentiment timeline data
+func (s *CachedAnalyticsService) GetSentimentTimeline(ctx context.Context, period string, filters *TimelineFilters) ([]SentimentTimelinePoint, error) {
+	filters = normalizeFilters(filters)
+	key := buildCacheKey("sentiment_timeline", filters, period)
+	
+	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
+		return s.AnalyticsService.GetSentimentTimeline(ctx, period, filters)
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	return result.([]SentimentTimelinePoint), nil
+}
+
+// GetSentimentCorrelation returns cached sentiment correlation data
+func (s *CachedAnalyticsService) GetSentimentCorrelation(ctx context.Context, filters *TimelineFilters) (*SentimentCorrelation, error) {
+	filters = normalizeFilters(filters)
+	key := buildCacheKey("sentiment_correlation", filters)
+	
+	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
+		return s.AnalyticsService.GetSentimentCorrelation(ctx, filters)
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	return result.(*SentimentCorrelation), nil
+}
+
 // GetAutomationAnalysis returns cached automation analysis data
 func (s *CachedAnalyticsService) GetAutomationAnalysis(ctx context.Context, filters *TimelineFilters) ([]AutomationAnalysis, error) {
+	filters = normalizeFilters(filters)
 	key := buildCacheKey("automation_analysis", filters)
 	
 	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
@@ -253,6 +581,7 @@ func (s *CachedAnalyticsService) GetAutomationAnalysis(ctx context.Context, filt
 
 // GetAnalyticsSummary returns cached analytics summary
 func (s *CachedAnalyticsService) GetAnalyticsSummary(ctx context.Context, filters *TimelineFilters) (*AnalyticsSummary, error) {
+	filters = normalizeFilters(filters)
 	key := buildCacheKey("analytics_summary", filters)
 	
 	result, err := s.getCachedOrFetch(ctx, key, func() (interface{}, error) {
@@ -271,17 +600,22 @@ func (s *CachedAnalyticsService) InvalidateCache(filters *TimelineFilters) {
 	keys := []string{
 		buildCacheKey("daily_timeline", filters),
 		buildCacheKey("weekly_timeline", filters),
-		buildCacheKey("trend_analysis_daily", filters),
-		buildCacheKey("trend_analysis_weekly", filters),
+		buildCacheKey("hourly_timeline", filters),
+		buildCacheKey("trend_analysis", filters, "daily"),
+		buildCacheKey("trend_analysis", filters, "weekly"),
 		buildCacheKey("priority_analysis", filters),
 		buildCacheKey("application_analysis", filters),
 		buildCacheKey("sentiment_analysis", filters),
+		buildCacheKey("sentiment_timeline", filters, "daily"),
+		buildCacheKey("sentiment_timeline", filters, "weekly"),
+		buildCacheKey("sentiment_correlation", filters),
 		buildCacheKey("automation_analysis", filters),
 		buildCacheKey("analytics_summary", filters),
 	}
 	
 	for _, key := range keys {
 		s.cache.Delete(key)
+		s.cache.Delete(key + federatedKeySuffix)
 	}
 }
 
@@ -1,6 +1,11 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -136,73 +141,127 @@ func TestCachedAnalyticsService(t *testing.T) {
 	cachedService, err = NewCachedAnalyticsService(mockService, config)
 	require.NoError(t, err)
 	assert.NotNil(t, cachedService)
+	assert.Equal(t, 2*time.Minute, cachedService.cacheTTL())
+}
+
+func TestCachedAnalyticsService_SetTTL(t *testing.T) {
+	cachedService, err := NewCachedAnalyticsService(&AnalyticsService{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultCacheConfig().TTL, cachedService.cacheTTL())
+
+	cachedService.cache.Set("daily_timeline", []TimelineData{}, 1, time.Hour)
+	time.Sleep(10 * time.Millisecond)
+
+	// A new TTL drops the entries cached under the old one
+	cachedService.SetTTL(time.Minute)
+	assert.Equal(t, time.Minute, cachedService.cacheTTL())
+	_, found := cachedService.cache.Get("daily_timeline")
+	assert.False(t, found)
 }
 
 func TestBuildCacheKey(t *testing.T) {
-	// Test with nil filters
+	// Nil and empty filters ask for the same incidents
 	key := buildCacheKey("test_prefix", nil)
-	assert.Equal(t, "test_prefix", key)
-
-	// Test with empty filters
-	filters := &TimelineFilters{}
-	key = buildCacheKey("test_prefix", filters)
-	assert.Equal(t, "test_prefix", key)
+	assert.True(t, strings.HasPrefix(key, "test_prefix:"))
+	assert.Equal(t, key, buildCacheKey("test_prefix", &TimelineFilters{}))
+	assert.Equal(t, "test_prefix", cacheEndpoint(key))
 
-	// Test with date filters
 	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
-
-	filters = &TimelineFilters{
-		StartDate: &startDate,
-		EndDate:   &endDate,
+	filters := &TimelineFilters{
+		StartDate:    &startDate,
+		EndDate:      &endDate,
+		Priorities:   []string{"P2", "P1"},
+		Applications: []string{"App1 ", "App2"},
+		Statuses:     []string{"Open", "Closed", "Open"},
+		Maintenance:  MaintenanceInclude,
 	}
-
 	key = buildCacheKey("test_prefix", filters)
-	assert.Contains(t, key, "test_prefix")
-	assert.Contains(t, key, "start:2024-01-01")
-	assert.Contains(t, key, "end:2024-01-31")
 
-	// Test with priority filters
-	filters = &TimelineFilters{
-		Priorities: []string{"P1", "P2"},
+	// Equivalent filters expressed differently share the key
+	equivalent := &TimelineFilters{
+		StartDate:    &startDate,
+		EndDate:      &endDate,
+		Priorities:   []string{"P1", "P2"},
+		Applications: []string{"App2", "App1", ""},
+		Statuses:     []string{"Closed", "Open"},
 	}
+	assert.Equal(t, key, buildCacheKey("test_prefix", equivalent))
+	assert.Equal(t, []string{"App1", "App2"}, normalizeFilters(filters).Applications)
+	assert.Equal(t, []string{"App1 ", "App2"}, filters.Applications, "the caller's filters are left alone")
+
+	// Different filters, endpoints or parameters do not
+	otherDate := endDate.AddDate(0, 0, 1)
+	assert.NotEqual(t, key, buildCacheKey("test_prefix", &TimelineFilters{StartDate: &startDate, EndDate: &otherDate}))
+	assert.NotEqual(t, key, buildCacheKey("test_prefix", &TimelineFilters{Regions: []string{"EMEA"}}))
+	assert.NotEqual(t, key, buildCacheKey("other_prefix", filters))
+	assert.NotEqual(t, buildCacheKey("trend_analysis", filters, "daily"), buildCacheKey("trend_analysis", filters, "weekly"))
+
+	// Percentiles are compared as a set
+	assert.Equal(t, buildCacheKey("p", &TimelineFilters{Percentiles: []float64{90, 50, 90}}),
+		buildCacheKey("p", &TimelineFilters{Percentiles: []float64{50, 90}}))
+}
 
-	key = buildCacheKey("test_prefix", filters)
-	assert.Contains(t, key, "test_prefix")
-	assert.Contains(t, key, "[P1 P2]")
-
-	// Test with application filters
-	filters = &TimelineFilters{
-		Applications: []string{"App1", "App2"},
+func TestCachedAnalyticsService_SharedFetch(t *testing.T) {
+	cachedService, err := NewCachedAnalyticsService(&AnalyticsService{}, nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+	key := buildCacheKey("daily_timeline", nil)
+
+	// Identical lookups arriving while a query runs wait for it
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	fetch := func() (interface{}, error) {
+		fetches.Add(1)
+		<-release
+		return []TimelineData{{IncidentCount: 3}}, nil
 	}
-
-	key = buildCacheKey("test_prefix", filters)
-	assert.Contains(t, key, "test_prefix")
-	assert.Contains(t, key, "[App1 App2]")
-
-	// Test with status filters
-	filters = &TimelineFilters{
-		Statuses: []string{"Open", "Closed"},
+	var wg sync.WaitGroup
+	results := make(chan interface{}, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := cachedService.getCachedOrFetch(ctx, key, fetch)
+			assert.NoError(t, err)
+			results <- data
+		}()
 	}
-
-	key = buildCacheKey("test_prefix", filters)
-	assert.Contains(t, key, "test_prefix")
-	assert.Contains(t, key, "[Open Closed]")
-
-	// Test with all filters
-	filters = &TimelineFilters{
-		StartDate:    &startDate,
-		EndDate:      &endDate,
-		Priorities:   []string{"P1", "P2"},
-		Applications: []string{"App1", "App2"},
-		Statuses:     []string{"Open", "Closed"},
+	require.Eventually(t, func() bool {
+		cachedService.flightMu.Lock()
+		defer cachedService.flightMu.Unlock()
+		return len(cachedService.flights) == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	assert.Equal(t, int32(1), fetches.Load())
+	for data := range results {
+		assert.Equal(t, []TimelineData{{IncidentCount: 3}}, data)
 	}
 
-	key = buildCacheKey("test_prefix", filters)
-	assert.Contains(t, key, "test_prefix")
-	assert.Contains(t, key, "start:2024-01-01")
-	assert.Contains(t, key, "end:2024-01-31")
-	assert.Contains(t, key, "[P1 P2]")
-	assert.Contains(t, key, "[App1 App2]")
-	assert.Contains(t, key, "[Open Closed]")
+	// Later lookups are answered from the cache
+	time.Sleep(10 * time.Millisecond)
+	_, err = cachedService.getCachedOrFetch(ctx, key, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	stats := cachedService.CacheStats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, "daily_timeline", stats[0].Endpoint)
+	assert.Equal(t, int64(1), stats[0].Misses)
+	assert.Equal(t, int64(4), stats[0].Shared)
+	assert.Equal(t, int64(1), stats[0].Hits)
+	assert.InDelta(t, 83.33, stats[0].HitRate, 0.01)
+
+	// Failed queries are not cached
+	failing := func() (interface{}, error) { return nil, errors.New("query failed") }
+	errKey := buildCacheKey("priority_analysis", nil)
+	_, err = cachedService.getCachedOrFetch(ctx, errKey, failing)
+	assert.Error(t, err)
+	time.Sleep(10 * time.Millisecond)
+	_, found := cachedService.cache.Get(errKey)
+	assert.False(t, found)
 }
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CacheWarmer precomputes cached analytics, such as after an upload adds incidents
+type CacheWarmer interface {
+	WarmCache(ctx context.Context, now time.Time) (*CacheWarmResult, error)
+}
+
+// cacheClearer is implemented by caches that can drop every cached result, such as before they
+// are warmed again after stored incidents changed
+type cacheClearer interface {
+	ClearCache()
+}
+
+// CacheWarmResult reports a cache warm
+type CacheWarmResult struct {
+	Entries  int      `json:"entries"` // results computed and cached
+	Failed   int      `json:"failed"`
+	Errors   []string `json:"errors,omitempty"`
+	Duration string   `json:"duration"`
+}
+
+// cacheWarmEndpoint computes the result of one cached endpoint for a filter set
+type cacheWarmEndpoint struct {
+	endpoint string
+	fetch    func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error)
+}
+
+// cacheWarmEndpoints are the endpoints a dashboard loads first
+var cacheWarmEndpoints = []cacheWarmEndpoint{
+	{"analytics_summary", func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error) {
+		return s.GetAnalyticsSummary(ctx, filters)
+	}},
+	{"daily_timeline", func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error) {
+		return s.GetDailyTimeline(ctx, filters)
+	}},
+	{"weekly_timeline", func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error) {
+		return s.GetWeeklyTimeline(ctx, filters)
+	}},
+	{"priority_analysis", func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error) {
+		return s.GetPriorityAnalysis(ctx, filters)
+	}},
+	{"application_analysis", func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error) {
+		return s.GetApplicationAnalysis(ctx, filters)
+	}},
+}
+
+// warmedFilters returns the filter combinations dashboards ask for most: no filters, and the
+// date range presets of the dashboard's date picker, which end on the current UTC day
+func warmedFilters(now time.Time) []*TimelineFilters {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	starts := []time.Time{
+		today.AddDate(0, 0, -7),
+		today.AddDate(0, 0, -30),
+		today.AddDate(0, -3, 0),
+		today.AddDate(0, -6, 0),
+		time.Date(today.Year(), 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	filters := []*TimelineFilters{{}}
+	for i := range starts {
+		end := today
+		filters = append(filters, &TimelineFilters{StartDate: &starts[i], EndDate: &end})
+	}
+	return filters
+}
+
+// WarmCache computes the summary, timelines and priority and application analyses for the
+// common filter combinations and caches them, replacing results cached before new incidents
+// arrived. Results are cached for ctx's data scope and sandbox, normally those of unrestricted
+// users. Warms run one at a time; a failing query is reported and the others still run.
+func (s *CachedAnalyticsService) WarmCache(ctx context.Context, now time.Time) (*CacheWarmResult, error) {
+	s.warmMu.Lock()
+	defer s.warmMu.Unlock()
+
+	start := time.Now()
+	result := &CacheWarmResult{}
+	for _, filters := range warmedFilters(now) {
+		for _, warm := range cacheWarmEndpoints {
+			if err := ctx.Err(); err != nil {
+				result.Duration = time.Since(start).String()
+				return result, fmt.Errorf("cache warm cancelled after %d entries: %w", result.Entries, err)
+			}
+			data, err := warm.fetch(s.AnalyticsService, ctx, filters)
+			if err != nil {
+				result.Failed++
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", warm.endpoint, err))
+				continue
+			}
+			s.store(s.contextKey(ctx, buildCacheKey(warm.endpoint, filters)), data)
+			result.Entries++
+		}
+	}
+	result.Duration = time.Since(start).String()
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmedFilters(t *testing.T) {
+	now := time.Date(2024, 5, 20, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60))
+	filters := warmedFilters(now)
+	require.Len(t, filters, 6)
+	assert.Nil(t, filters[0].StartDate)
+
+	// Ranges end on the current UTC day, like the date picker's presets
+	assert.Equal(t, "2024-05-21", filters[1].EndDate.Format("2006-01-02"))
+	assert.Equal(t, "2024-05-14", filters[1].StartDate.Format("2006-01-02"))
+	assert.Equal(t, "2024-01-01", filters[5].StartDate.Format("2006-01-02"))
+}
+
+func TestCachedAnalyticsService_WarmCache(t *testing.T) {
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	require.NoError(t, err)
+	defer dbWrapper.Close()
+	require.NoError(t, dbWrapper.InitializeDatabase())
+
+	cachedService, err := NewCachedAnalyticsService(NewAnalyticsService(dbWrapper.GetConnection()), nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	result, err := cachedService.WarmCache(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, len(warmedFilters(time.Now()))*len(cacheWarmEndpoints), result.Entries)
+	assert.Zero(t, result.Failed)
+
+	// A dashboard loading after the warm is answered from the cache
+	time.Sleep(10 * time.Millisecond)
+	_, err = cachedService.GetAnalyticsSummary(ctx, &TimelineFilters{})
+	require.NoError(t, err)
+	stats := cachedService.CacheStats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, "analytics_summary", stats[0].Endpoint)
+	assert.Equal(t, int64(1), stats[0].Hits)
+
+	// A cancelled warm stops and reports it
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = cachedService.WarmCache(cancelled, time.Now())
+	assert.ErrorIs(t, err, context.Canceled)
+}
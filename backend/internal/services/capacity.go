@@ -0,0 +1,379 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Capacity planning defaults
+const (
+	DefaultCapacityHistoryWeeks  = 12
+	DefaultCapacityForecastWeeks = 4
+	// DefaultProductiveHoursPerAgent is the weekly time an agent spends working incidents,
+	// after meetings, leave and other duties
+	DefaultProductiveHoursPerAgent = 30.0
+)
+
+// CapacityOptions controls the capacity forecast
+type CapacityOptions struct {
+	// HistoryWeeks is the number of weeks, ending with the latest week, the trend is fitted to
+	HistoryWeeks int
+	// ForecastWeeks is the number of weeks projected after the latest week
+	ForecastWeeks int
+	// ProductiveHours is the weekly handling time available per agent
+	ProductiveHours float64
+	// HandlingHours overrides the observed average handling time of every group when positive
+	HandlingHours float64
+	// WeekScale, when set, holds a factor per history week that its counts are multiplied by
+	// before the trend is fitted; weeks with a factor of 0 are left out of the fit
+	WeekScale []float64
+}
+
+// CapacityGroupStats holds the history a group's capacity forecast is computed from
+type CapacityGroupStats struct {
+	Name string
+	// WeeklyCounts holds one incident count per history week, oldest first
+	WeeklyCounts []int
+	// AvgHandlingHours is the mean resolution time of the group's resolved incidents
+	AvgHandlingHours *float64
+}
+
+// CapacityWeek is the projected workload for one week
+type CapacityWeek struct {
+	WeekStart          string  `json:"week_start"`
+	ProjectedIncidents float64 `json:"projected_incidents"`
+	HandlingHours      float64 `json:"handling_hours"`
+	FTE                float64 `json:"fte"`
+}
+
+// CapacityGroup is the capacity forecast of one resolution group
+type CapacityGroup struct {
+	Name                string         `json:"name"`
+	HistoricalIncidents int            `json:"historical_incidents"`
+	AvgIncidentsPerWeek float64        `json:"avg_incidents_per_week"`
+	TrendPerWeek        float64        `json:"trend_per_week"`
+	AvgHandlingHours    float64        `json:"avg_handling_hours"`
+	HandlingHoursSource string         `json:"handling_hours_source"`
+	ProjectedIncidents  float64        `json:"projected_incidents"`
+	ProjectedHours      float64        `json:"projected_hours"`
+	AvgFTE              float64        `json:"avg_fte"`
+	PeakFTE             float64        `json:"peak_fte"`
+	Weeks               []CapacityWeek `json:"weeks"`
+}
+
+// Sources of a group's handling time, used in CapacityGroup.HandlingHoursSource
+const (
+	HandlingHoursObserved  = "observed"
+	HandlingHoursPortfolio = "portfolio"
+	HandlingHoursOverride  = "override"
+)
+
+// CapacityPlan projects incident volume, handling hours and staffing for the weeks after the
+// history window. Groups are ordered by projected hours, largest first.
+type CapacityPlan struct {
+	HistoryStart    string          `json:"history_start"`
+	HistoryEnd      string          `json:"history_end"`
+	HistoryWeeks    int             `json:"history_weeks"`
+	ForecastWeeks   int             `json:"forecast_weeks"`
+	ProductiveHours float64         `json:"productive_hours_per_agent"`
+	Weeks           []CapacityWeek  `json:"weeks"`
+	Groups          []CapacityGroup `json:"groups"`
+	TotalHours      float64         `json:"total_hours"`
+	PeakFTE         float64         `json:"peak_fte"`
+	// HolidayWeeks lists the history weeks whose counts were excluded or adjusted for holidays
+	HolidayWeeks []string `json:"holiday_weeks,omitempty"`
+}
+
+// GetCapacityPlan forecasts weekly incident volume per resolution group from a linear trend over
+// the history window, and converts it into handling hours and FTE using each group's average
+// resolution time. The history window ends with the week of the end_date filter, or of the most
+// recent matching incident when no end date is given.
+func (s *AnalyticsService) GetCapacityPlan(ctx context.Context, filters *TimelineFilters, opts CapacityOptions) (*CapacityPlan, error) {
+	opts = opts.withDefaults()
+
+	lastWeek, err := s.capacityAnchorWeek(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	historyStart := lastWeek.AddDate(0, 0, -7*(opts.HistoryWeeks-1))
+	if opts.WeekScale, err = s.capacityHolidayScale(ctx, filters, historyStart, opts.HistoryWeeks); err != nil {
+		return nil, err
+	}
+
+	whereClause, args, nextIdx := buildFilterConditions(ctx, filters, 1)
+	query := fmt.Sprintf(`
+		SELECT
+			COALESCE(resolution_group, 'Unassigned') as group_name,
+			DATE_TRUNC('week', report_date) as week,
+			COUNT(*) as incident_count,
+			CAST(SUM(resolution_time_hours) AS DOUBLE) as handling_hours,
+			COUNT(resolution_time_hours) as resolved_count
+		FROM incidents
+		WHERE report_date >= $%d AND report_date < $%d`, nextIdx, nextIdx+1)
+	query += whereClause
+	query += " GROUP BY group_name, week"
+	args = append(args, historyStart, lastWeek.AddDate(0, 0, 7))
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query capacity history: %w", err)
+	}
+	defer rows.Close()
+
+	type handling struct {
+		hours    float64
+		resolved int
+	}
+	statsByGroup := make(map[string]*CapacityGroupStats)
+	handlingByGroup := make(map[string]*handling)
+	for rows.Next() {
+		var name string
+		var week time.Time
+		var count, resolved int
+		var hours sql.NullFloat64
+		if err := rows.Scan(&name, &week, &count, &hours, &resolved); err != nil {
+			return nil, fmt.Errorf("failed to scan capacity row: %w", err)
+		}
+
+		stats, ok := statsByGroup[name]
+		if !ok {
+			stats = &CapacityGroupStats{Name: name, WeeklyCounts: make([]int, opts.HistoryWeeks)}
+			statsByGroup[name] = stats
+			handlingByGroup[name] = &handling{}
+		}
+		index := int(StartOfWeek(week).Sub(historyStart).Hours() / (24 * 7))
+		if index >= 0 && index < opts.HistoryWeeks {
+			stats.WeeklyCounts[index] += count
+		}
+		handlingByGroup[name].hours += hours.Float64
+		handlingByGroup[name].resolved += resolved
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating capacity rows: %w", err)
+	}
+
+	stats := make([]CapacityGroupStats, 0, len(statsByGroup))
+	for name, group := range statsByGroup {
+		if h := handlingByGroup[name]; h.resolved > 0 {
+			avg := h.hours / float64(h.resolved)
+			group.AvgHandlingHours = &avg
+		}
+		stats = append(stats, *group)
+	}
+
+	return BuildCapacityPlan(lastWeek, stats, opts), nil
+}
+
+// capacityHolidayScale returns the factor each history week is scaled by under the filters'
+// holiday mode, or nil when holidays are not excluded or adjusted
+func (s *AnalyticsService) capacityHolidayScale(ctx context.Context, filters *TimelineFilters, historyStart time.Time, weeks int) ([]float64, error) {
+	if filters == nil || (filters.Holidays != HolidaysExclude && filters.Holidays != HolidaysAdjust) {
+		return nil, nil
+	}
+	calendar, err := s.holidayCalendar(ctx, filters)
+	if err != nil || len(calendar) == 0 {
+		return nil, err
+	}
+
+	scale := make([]float64, weeks)
+	for i := range scale {
+		scale[i] = holidayScale(filters.Holidays, holidayMarkers(calendar, historyStart.AddDate(0, 0, 7*i), 7), true)
+	}
+	return scale, nil
+}
+
+// capacityAnchorWeek returns the start of the last week of the capacity history window
+func (s *AnalyticsService) capacityAnchorWeek(ctx context.Context, filters *TimelineFilters) (time.Time, error) {
+	if filters != nil && filters.EndDate != nil {
+		return StartOfWeek(*filters.EndDate), nil
+	}
+
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	var latest sql.NullTime
+	if err := s.db.QueryRowContext(ctx, s.federate(ctx, "SELECT MAX(report_date) FROM incidents WHERE 1=1"+whereClause, filters), args...).Scan(&latest); err != nil {
+		return time.Time{}, fmt.Errorf("failed to query latest report date: %w", err)
+	}
+	if !latest.Valid {
+		return StartOfWeek(time.Now()), nil
+	}
+	return StartOfWeek(latest.Time), nil
+}
+
+// BuildCapacityPlan projects each group's weekly counts forward from lastWeek. Groups without
+// resolved incidents use the portfolio average handling time, weighted by incident volume.
+func BuildCapacityPlan(lastWeek time.Time, stats []CapacityGroupStats, opts CapacityOptions) *CapacityPlan {
+	opts = opts.withDefaults()
+
+	plan := &CapacityPlan{
+		HistoryStart:    lastWeek.AddDate(0, 0, -7*(opts.HistoryWeeks-1)).Format("2006-01-02"),
+		HistoryEnd:      lastWeek.AddDate(0, 0, 6).Format("2006-01-02"),
+		HistoryWeeks:    opts.HistoryWeeks,
+		ForecastWeeks:   opts.ForecastWeeks,
+		ProductiveHours: opts.ProductiveHours,
+		Weeks:           make([]CapacityWeek, opts.ForecastWeeks),
+		Groups:          []CapacityGroup{},
+	}
+	for i := range plan.Weeks {
+		plan.Weeks[i].WeekStart = lastWeek.AddDate(0, 0, 7*(i+1)).Format("2006-01-02")
+	}
+	for i, factor := range opts.WeekScale {
+		if factor != 1 {
+			plan.HolidayWeeks = append(plan.HolidayWeeks, lastWeek.AddDate(0, 0, -7*(opts.HistoryWeeks-1-i)).Format("2006-01-02"))
+		}
+	}
+
+	var portfolioHours, portfolioWeight float64
+	for _, stat := range stats {
+		if stat.AvgHandlingHours != nil {
+			weight := float64(sumCounts(stat.WeeklyCounts))
+			portfolioHours += *stat.AvgHandlingHours * weight
+			portfolioWeight += weight
+		}
+	}
+
+	for _, stat := range stats {
+		total := sumCounts(stat.WeeklyCounts)
+		if total == 0 {
+			continue
+		}
+
+		group := CapacityGroup{
+			Name:                stat.Name,
+			HistoricalIncidents: total,
+			AvgIncidentsPerWeek: roundTo(float64(total)/float64(len(stat.WeeklyCounts)), 2),
+			Weeks:               make([]CapacityWeek, opts.ForecastWeeks),
+		}
+		switch {
+		case opts.HandlingHours > 0:
+			group.AvgHandlingHours = opts.HandlingHours
+			group.HandlingHoursSource = HandlingHoursOverride
+		case stat.AvgHandlingHours != nil:
+			group.AvgHandlingHours = *stat.AvgHandlingHours
+			group.HandlingHoursSource = HandlingHoursObserved
+		case portfolioWeight > 0:
+			group.AvgHandlingHours = portfolioHours / portfolioWeight
+			group.HandlingHoursSource = HandlingHoursPortfolio
+		default:
+			group.HandlingHoursSource = HandlingHoursPortfolio
+		}
+
+		intercept, slope := linearTrend(stat.WeeklyCounts, opts.WeekScale)
+		group.TrendPerWeek = roundTo(slope, 2)
+		for i := range group.Weeks {
+			projected := math.Max(0, intercept+slope*float64(len(stat.WeeklyCounts)+i))
+			hours := projected * group.AvgHandlingHours
+			group.Weeks[i] = CapacityWeek{
+				WeekStart:          plan.Weeks[i].WeekStart,
+				ProjectedIncidents: roundTo(projected, 1),
+				HandlingHours:      roundTo(hours, 1),
+				FTE:                roundTo(hours/opts.ProductiveHours, 2),
+			}
+			group.ProjectedIncidents += projected
+			group.ProjectedHours += hours
+			group.PeakFTE = math.Max(group.PeakFTE, group.Weeks[i].FTE)
+
+			plan.Weeks[i].ProjectedIncidents += projected
+			plan.Weeks[i].HandlingHours += hours
+		}
+		if opts.ForecastWeeks > 0 {
+			group.AvgFTE = roundTo(group.ProjectedHours/float64(opts.ForecastWeeks)/opts.ProductiveHours, 2)
+		}
+		plan.TotalHours += group.ProjectedHours
+		group.AvgHandlingHours = roundTo(group.AvgHandlingHours, 2)
+		group.ProjectedIncidents = roundTo(group.ProjectedIncidents, 1)
+		group.ProjectedHours = roundTo(group.ProjectedHours, 1)
+		plan.Groups = append(plan.Groups, group)
+	}
+
+	for i := range plan.Weeks {
+		week := &plan.Weeks[i]
+		week.FTE = roundTo(week.HandlingHours/opts.ProductiveHours, 2)
+		week.ProjectedIncidents = roundTo(week.ProjectedIncidents, 1)
+		week.HandlingHours = roundTo(week.HandlingHours, 1)
+		plan.PeakFTE = math.Max(plan.PeakFTE, week.FTE)
+	}
+	plan.TotalHours = roundTo(plan.TotalHours, 1)
+
+	sort.SliceStable(plan.Groups, func(i, j int) bool {
+		a, b := plan.Groups[i], plan.Groups[j]
+		if a.ProjectedHours != b.ProjectedHours {
+			return a.ProjectedHours > b.ProjectedHours
+		}
+		return a.Name < b.Name
+	})
+
+	return plan
+}
+
+// withDefaults fills unset capacity options
+func (o CapacityOptions) withDefaults() CapacityOptions {
+	if o.HistoryWeeks <= 0 {
+		o.HistoryWeeks = DefaultCapacityHistoryWeeks
+	}
+	if o.ForecastWeeks <= 0 {
+		o.ForecastWeeks = DefaultCapacityForecastWeeks
+	}
+	if o.ProductiveHours <= 0 {
+		o.ProductiveHours = DefaultProductiveHoursPerAgent
+	}
+	return o
+}
+
+// linearTrend fits counts[x] = intercept + slope*x by least squares. When scale is set, each
+// count is multiplied by its factor first and counts with a factor of 0 are left out.
+func linearTrend(counts []int, scale []float64) (intercept, slope float64) {
+	xs := make([]float64, 0, len(counts))
+	ys := make([]float64, 0, len(counts))
+	for x, count := range counts {
+		y := float64(count)
+		if x < len(scale) {
+			if scale[x] == 0 {
+				continue
+			}
+			y *= scale[x]
+		}
+		xs = append(xs, float64(x))
+		ys = append(ys, y)
+	}
+	return fitLine(xs, ys)
+}
+
+// fitLine fits ys = intercept + slope*xs by least squares
+func fitLine(xs, ys []float64) (intercept, slope float64) {
+	var sumX, sumY, sumXY, sumXX float64
+	n := float64(len(xs))
+	for i, x := range xs {
+		sumX += x
+		sumY += ys[i]
+		sumXY += x * ys[i]
+		sumXX += x * x
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	if denominator := n*sumXX - sumX*sumX; denominator != 0 {
+		slope = (n*sumXY - sumX*sumY) / denominator
+	}
+	intercept = (sumY - slope*sumX) / n
+	return intercept, slope
+}
+
+// sumCounts returns the total of counts
+func sumCounts(counts []int) int {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
+
+// roundTo rounds value to the given number of decimal places
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
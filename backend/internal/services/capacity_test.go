@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestBuildCapacityPlan(t *testing.T) {
+	lastWeek := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	stats := []CapacityGroupStats{
+		{Name: "Declining", WeeklyCounts: []int{6, 4, 2, 0}, AvgHandlingHours: floatPtr(2)},
+		{Name: "Quiet", WeeklyCounts: []int{0, 0, 0, 0}},
+	}
+
+	plan := BuildCapacityPlan(lastWeek, stats, CapacityOptions{ForecastWeeks: 2, HandlingHours: 5})
+
+	if plan.ProductiveHours != DefaultProductiveHoursPerAgent || plan.HistoryWeeks != DefaultCapacityHistoryWeeks {
+		t.Errorf("Expected default options, got %+v", plan)
+	}
+	if len(plan.Groups) != 1 {
+		t.Fatalf("Expected groups without history to be skipped, got %+v", plan.Groups)
+	}
+
+	group := plan.Groups[0]
+	if group.HandlingHoursSource != HandlingHoursOverride || group.AvgHandlingHours != 5 {
+		t.Errorf("Expected the handling time override, got %+v", group)
+	}
+	if group.TrendPerWeek != -2 || group.ProjectedIncidents != 0 || group.PeakFTE != 0 {
+		t.Errorf("Expected a declining trend floored at zero, got %+v", group)
+	}
+	if plan.Weeks[0].WeekStart != "2024-03-11" || plan.Weeks[1].WeekStart != "2024-03-18" {
+		t.Errorf("Expected forecast weeks after the history, got %+v", plan.Weeks)
+	}
+}
+
+func TestAnalyticsService_GetCapacityPlan(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+
+	// Web Team: 1, 2, 3 and 4 incidents in the weeks of Jan 1 to Jan 22, 3 hours each
+	var incidents []models.Incident
+	for week := 0; week < 4; week++ {
+		for i := 0; i <= week; i++ {
+			incident := diffTestIncident(fmt.Sprintf("w%d-%d", week, i), "upload-1", fmt.Sprintf("INCW%d%d", week, i), "P3", "Closed")
+			incident.ReportDate = time.Date(2024, 1, 2+7*week, 0, 0, 0, 0, time.UTC)
+			hours := 3
+			incident.ResolutionTimeHours = &hours
+			incidents = append(incidents, incident)
+		}
+	}
+	// Service Desk: 2 unresolved incidents in the latest week
+	for i := 0; i < 2; i++ {
+		incident := diffTestIncident(fmt.Sprintf("s%d", i), "upload-1", fmt.Sprintf("INCS%d", i), "P3", "Open")
+		incident.ReportDate = time.Date(2024, 1, 24, 0, 0, 0, 0, time.UTC)
+		incident.ResolutionGroup = "Service Desk"
+		incidents = append(incidents, incident)
+	}
+
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	plan, err := NewAnalyticsService(db).GetCapacityPlan(ctx, nil, CapacityOptions{HistoryWeeks: 4, ForecastWeeks: 2})
+	if err != nil {
+		t.Fatalf("Failed to get capacity plan: %v", err)
+	}
+	if plan.HistoryStart != "2024-01-01" || plan.HistoryEnd != "2024-01-28" {
+		t.Errorf("Expected history from 2024-01-01 to 2024-01-28, got %s to %s", plan.HistoryStart, plan.HistoryEnd)
+	}
+	if len(plan.Groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %+v", plan.Groups)
+	}
+
+	web := plan.Groups[0]
+	if web.Name != "Web Team" || web.HistoricalIncidents != 10 || web.TrendPerWeek != 1 {
+		t.Errorf("Unexpected Web Team history: %+v", web)
+	}
+	if web.Weeks[0].ProjectedIncidents != 5 || web.Weeks[1].ProjectedIncidents != 6 {
+		t.Errorf("Expected 5 and 6 projected incidents, got %+v", web.Weeks)
+	}
+	if web.Weeks[0].HandlingHours != 15 || web.Weeks[1].FTE != 0.6 || web.PeakFTE != 0.6 {
+		t.Errorf("Expected 15 hours then 0.6 FTE, got %+v", web.Weeks)
+	}
+
+	desk := plan.Groups[1]
+	if desk.HandlingHoursSource != HandlingHoursPortfolio || desk.AvgHandlingHours != 3 {
+		t.Errorf("Expected the portfolio handling time for an unresolved group, got %+v", desk)
+	}
+	if desk.Weeks[1].ProjectedIncidents != 2.6 || desk.Weeks[1].HandlingHours != 7.8 {
+		t.Errorf("Unexpected Service Desk forecast: %+v", desk.Weeks)
+	}
+
+	if plan.Weeks[0].WeekStart != "2024-01-29" || plan.Weeks[0].HandlingHours != 21 || plan.Weeks[0].FTE != 0.7 {
+		t.Errorf("Unexpected first forecast week: %+v", plan.Weeks[0])
+	}
+	if plan.TotalHours != 46.8 || plan.PeakFTE != 0.86 {
+		t.Errorf("Expected 46.8 total hours and 0.86 peak FTE, got %v and %v", plan.TotalHours, plan.PeakFTE)
+	}
+
+	// An end date moves the history window back
+	endDate := time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)
+	plan, err = NewAnalyticsService(db).GetCapacityPlan(ctx, &TimelineFilters{EndDate: &endDate}, CapacityOptions{HistoryWeeks: 2})
+	if err != nil {
+		t.Fatalf("Failed to get capacity plan: %v", err)
+	}
+	if len(plan.Groups) != 1 || plan.Groups[0].HistoricalIncidents != 3 {
+		t.Errorf("Expected only the Web Team history up to Jan 14, got %+v", plan.Groups)
+	}
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Periods a change can be explained for
+const (
+	ExplainDaily  = "daily"
+	ExplainWeekly = "weekly"
+)
+
+// Dimensions a change is broken down by
+const (
+	ExplainByApplication  = "application"
+	ExplainByPriority     = "priority"
+	ExplainByGroup        = "group"
+	ExplainByProcessGroup = "process_group"
+)
+
+// DefaultExplainBaselinePeriods is how many earlier periods are averaged into the baseline of a
+// change explanation unless another number is asked for
+const DefaultExplainBaselinePeriods = 4
+
+// explainDimensions lists the dimensions a change is broken down by, in report order and in the
+// order of the columns of the change explanation query
+var explainDimensions = []string{ExplainByApplication, ExplainByPriority, ExplainByGroup, ExplainByProcessGroup}
+
+// ChangeContributor is how much one value of a dimension contributed to a change
+type ChangeContributor struct {
+	Dimension string  `json:"dimension"`
+	Value     string  `json:"value"`
+	Current   int     `json:"current"`
+	Baseline  float64 `json:"baseline"`
+	Change    float64 `json:"change"`
+	// ChangePct is nil when the value had no incidents in the baseline
+	ChangePct *float64 `json:"change_pct"`
+	// SharePct is the percentage of the total increase the value accounts for; nil when the total
+	// did not increase
+	SharePct *float64 `json:"share_pct"`
+}
+
+// ChangeBreakdown ranks the values of one dimension by their contribution to a change
+type ChangeBreakdown struct {
+	Dimension    string              `json:"dimension"`
+	Contributors []ChangeContributor `json:"contributors"`
+}
+
+// ChangeExplanation decomposes the incident volume of a day or week against its baseline
+type ChangeExplanation struct {
+	Period          string   `json:"period"`
+	PeriodStart     string   `json:"period_start"`
+	PeriodEnd       string   `json:"period_end"` // exclusive
+	BaselinePeriods int      `json:"baseline_periods"`
+	Current         int      `json:"current"`
+	Baseline        float64  `json:"baseline"`
+	Change          float64  `json:"change"`
+	ChangePct       *float64 `json:"change_pct"` // nil when the baseline has no incidents
+	// TopContributors are the values of every dimension that added the most incidents
+	TopContributors []ChangeContributor `json:"top_contributors"`
+	Dimensions      []ChangeBreakdown   `json:"dimensions"`
+}
+
+// ExplainSpan returns the first and last report day ExplainChange reads to explain the period
+// holding date: from the first baseline period to the end of the explained one
+func ExplainSpan(period string, date time.Time, baselinePeriods int) (time.Time, time.Time) {
+	if baselinePeriods <= 0 {
+		baselinePeriods = DefaultExplainBaselinePeriods
+	}
+	_, _, periodEnd, spanStart := explainPeriods(period, date, baselinePeriods)
+	return spanStart, periodEnd.AddDate(0, 0, -1)
+}
+
+// explainPeriods returns the period explained, daily unless weekly, the start and exclusive end
+// of the one holding date, and the start of the first of the baseline periods before it
+func explainPeriods(period string, date time.Time, baselinePeriods int) (string, time.Time, time.Time, time.Time) {
+	periodStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	periodDays := 1
+	if period == ExplainWeekly {
+		periodStart = periodStart.AddDate(0, 0, -(int(periodStart.Weekday())+6)%7)
+		periodDays = 7
+	} else {
+		period = ExplainDaily
+	}
+	// Periods repeat every week: whole weeks, or the same weekday
+	spanStart := periodStart.AddDate(0, 0, -7*baselinePeriods)
+	return period, periodStart, periodStart.AddDate(0, 0, periodDays), spanStart
+}
+
+// ExplainChange explains the incident volume of the day, or the Monday-to-Sunday week, holding
+// date: each application, priority, resolution group and IT process group, the cluster the
+// automation analyzer assigns, is compared with its average over the baselinePeriods periods
+// before. A day is compared with the same weekday of the weeks before, so weekly patterns do not
+// read as spikes. Contributors are ranked by the incidents they added, and each dimension, like
+// the top contributors, is cut to limit entries. The filters' own date range is ignored.
+func (s *AnalyticsService) ExplainChange(ctx context.Context, filters *TimelineFilters, period string, date time.Time, baselinePeriods, limit int) (*ChangeExplanation, error) {
+	if baselinePeriods <= 0 {
+		baselinePeriods = DefaultExplainBaselinePeriods
+	}
+	if limit <= 0 {
+		limit = DefaultTopN
+	}
+
+	period, periodStart, periodEnd, spanStart := explainPeriods(period, date, baselinePeriods)
+	periodDays := int(periodEnd.Sub(periodStart).Hours() / 24)
+
+	scoped := TimelineFilters{}
+	if filters != nil {
+		scoped = *filters
+	}
+	lastDay := periodEnd.AddDate(0, 0, -1)
+	scoped.StartDate, scoped.EndDate = &spanStart, &lastDay
+
+	query := `
+		SELECT
+			application_name,
+			priority,
+			COALESCE(NULLIF(resolution_group, ''), 'Unassigned') AS group_name,
+			COALESCE(NULLIF(it_process_group, ''), 'Uncategorized') AS process_group,
+			DATE_DIFF('day', CAST($1 AS DATE), report_date) AS day_offset,
+			COUNT(*) AS incident_count
+		FROM incidents
+		WHERE 1=1`
+	whereClause, args, _ := buildFilterConditions(ctx, &scoped, 2)
+	query += whereClause
+	query += " GROUP BY application_name, priority, group_name, process_group, day_offset"
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, &scoped), append([]interface{}{spanStart}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query change explanation: %w", err)
+	}
+	defer rows.Close()
+
+	// counts[dimension][value] holds the current and summed baseline incidents
+	counts := map[string]map[string]*[2]int{}
+	for _, dimension := range explainDimensions {
+		counts[dimension] = map[string]*[2]int{}
+	}
+	var current, baseline int
+	for rows.Next() {
+		var application, priority, group, processGroup string
+		var offset, count int
+		if err := rows.Scan(&application, &priority, &group, &processGroup, &offset, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan change explanation row: %w", err)
+		}
+		if offset < 0 || offset%7 >= periodDays {
+			continue
+		}
+		slot := 1
+		if offset/7 == baselinePeriods {
+			slot = 0
+			current += count
+		} else {
+			baseline += count
+		}
+		for i, value := range []string{application, priority, group, processGroup} {
+			dimension := explainDimensions[i]
+			entry, ok := counts[dimension][value]
+			if !ok {
+				entry = &[2]int{}
+				counts[dimension][value] = entry
+			}
+			entry[slot] += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating change explanation rows: %w", err)
+	}
+
+	average := float64(baseline) / float64(baselinePeriods)
+	increase := float64(current) - average
+	explanation := &ChangeExplanation{
+		Period:          period,
+		PeriodStart:     periodStart.Format("2006-01-02"),
+		PeriodEnd:       periodEnd.Format("2006-01-02"),
+		BaselinePeriods: baselinePeriods,
+		Current:         current,
+		Baseline:        roundTo(average, 2),
+		Change:          roundTo(increase, 2),
+		ChangePct:       changePct(float64(current), average),
+		TopContributors: []ChangeContributor{},
+		Dimensions:      make([]ChangeBreakdown, 0, len(explainDimensions)),
+	}
+
+	var all []ChangeContributor
+	for _, dimension := range explainDimensions {
+		contributors := make([]ChangeContributor, 0, len(counts[dimension]))
+		for value, entry := range counts[dimension] {
+			average := float64(entry[1]) / float64(baselinePeriods)
+			contributor := ChangeContributor{
+				Dimension: dimension,
+				Value:     value,
+				Current:   entry[0],
+				Baseline:  roundTo(average, 2),
+				Change:    roundTo(float64(entry[0])-average, 2),
+				ChangePct: changePct(float64(entry[0]), average),
+			}
+			if increase > 0 {
+				share := roundTo((float64(entry[0])-average)*100.0/increase, 2)
+				contributor.SharePct = &share
+			}
+			contributors = append(contributors, contributor)
+		}
+		sortContributors(contributors)
+		all = append(all, contributors...)
+		if len(contributors) > limit {
+			contributors = contributors[:limit]
+		}
+		explanation.Dimensions = append(explanation.Dimensions, ChangeBreakdown{Dimension: dimension, Contributors: contributors})
+	}
+
+	sortContributors(all)
+	for _, contributor := range all {
+		if len(explanation.TopContributors) == limit || contributor.Change <= 0 {
+			break
+		}
+		explanation.TopContributors = append(explanation.TopContributors, contributor)
+	}
+	return explanation, nil
+}
+
+// changePct returns the percentage change of current over baseline, or nil without a baseline
+func changePct(current, baseline float64) *float64 {
+	if baseline == 0 {
+		return nil
+	}
+	pct := roundTo((current-baseline)*100.0/baseline, 2)
+	return &pct
+}
+
+// sortContributors orders contributors by the incidents they added, then by dimension and value
+func sortContributors(contributors []ChangeContributor) {
+	sort.Slice(contributors, func(i, j int) bool {
+		if contributors[i].Change != contributors[j].Change {
+			return contributors[i].Change > contributors[j].Change
+		}
+		if contributors[i].Dimension != contributors[j].Dimension {
+			return contributors[i].Dimension < contributors[j].Dimension
+		}
+		return contributors[i].Value < contributors[j].Value
+	})
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/models"
+)
+
+func TestAnalyticsService_ExplainChange(t *testing.T) {
+	// Tuesday 2024-01-16 has five Billing P1 incidents on top of the usual Portal one; Portal also
+	// had incidents on the two Tuesdays before and on Wednesday 2024-01-10
+	day := func(id string, date time.Time) models.Incident {
+		incident := diffTestIncident(id, "upload-1", "INC-"+id, "P3", "Closed")
+		incident.ReportDate = date
+		return incident
+	}
+	incidents := []models.Incident{
+		day("portal-1", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)),
+		day("portal-2", time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)),
+		day("portal-3", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+		day("portal-4", time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)),
+	}
+	for i := 0; i < 5; i++ {
+		incident := day(fmt.Sprint("billing-", i), time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC))
+		incident.ApplicationName = "Billing"
+		incident.ResolutionGroup = "Billing Team"
+		incident.Priority = "P1"
+		incidents = append(incidents, incident)
+	}
+
+	dbWrapper, db := newSentimentTestDB(t, incidents)
+	defer dbWrapper.Close()
+	service := NewAnalyticsService(db)
+	ctx := context.Background()
+	tuesday := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+
+	explanation, err := service.ExplainChange(ctx, nil, ExplainDaily, tuesday, 0, 2)
+	if err != nil {
+		t.Fatalf("ExplainChange() error = %v", err)
+	}
+	if explanation.PeriodStart != "2024-01-16" || explanation.PeriodEnd != "2024-01-17" ||
+		explanation.BaselinePeriods != DefaultExplainBaselinePeriods {
+		t.Errorf("unexpected period: %+v", explanation)
+	}
+	// The Wednesday incident is not in the baseline of a Tuesday
+	if explanation.Current != 6 || explanation.Baseline != 0.5 || explanation.Change != 5.5 || *explanation.ChangePct != 1100 {
+		t.Errorf("unexpected totals: current %d, baseline %v, change %v", explanation.Current, explanation.Baseline, explanation.Change)
+	}
+	if len(explanation.Dimensions) != len(explainDimensions) {
+		t.Fatalf("expected every dimension, got %+v", explanation.Dimensions)
+	}
+
+	applications := explanation.Dimensions[0]
+	if applications.Dimension != ExplainByApplication || len(applications.Contributors) != 2 {
+		t.Fatalf("unexpected application breakdown: %+v", applications)
+	}
+	billing, portal := applications.Contributors[0], applications.Contributors[1]
+	if billing.Value != "Billing" || billing.Change != 5 || billing.ChangePct != nil || *billing.SharePct != 90.91 {
+		t.Errorf("unexpected Billing contribution: %+v", billing)
+	}
+	if portal.Value != "Portal" || portal.Baseline != 0.5 || *portal.ChangePct != 100 {
+		t.Errorf("unexpected Portal contribution: %+v", portal)
+	}
+
+	// Uncategorized covers every incident, then the Billing application, group and P1 tie
+	if len(explanation.TopContributors) != 2 {
+		t.Fatalf("expected the top contributors cut to the limit, got %+v", explanation.TopContributors)
+	}
+	if top := explanation.TopContributors[0]; top.Dimension != ExplainByProcessGroup || top.Value != "Uncategorized" {
+		t.Errorf("unexpected top contributor: %+v", top)
+	}
+	if next := explanation.TopContributors[1]; next.Dimension != ExplainByApplication || next.Value != "Billing" {
+		t.Errorf("unexpected second contributor: %+v", next)
+	}
+
+	weekly, err := service.ExplainChange(ctx, &TimelineFilters{Applications: []string{"Portal"}}, ExplainWeekly, tuesday, 2, 0)
+	if err != nil {
+		t.Fatalf("ExplainChange() error = %v", err)
+	}
+	if weekly.PeriodStart != "2024-01-15" || weekly.PeriodEnd != "2024-01-22" {
+		t.Errorf("expected the Monday-to-Sunday week, got %s to %s", weekly.PeriodStart, weekly.PeriodEnd)
+	}
+	if weekly.Current != 1 || weekly.Baseline != 1.5 || weekly.ChangePct == nil || *weekly.ChangePct != -33.33 {
+		t.Errorf("unexpected weekly totals: %+v", weekly)
+	}
+	if len(weekly.TopContributors) != 0 || weekly.Dimensions[0].Contributors[0].SharePct != nil {
+		t.Errorf("expected no contributors to a decrease, got %+v", weekly.TopContributors)
+	}
+}
@@ -0,0 +1,474 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"incident-management-system/internal/models"
+)
+
+// CMDB entity types: incidents are matched on their application first, then on their business
+// service
+const (
+	CMDBEntityApplication = "application"
+	CMDBEntityService     = "service"
+)
+
+// CMDB item sources
+const (
+	CMDBSourceCSV        = "csv"
+	CMDBSourceServiceNow = "servicenow"
+)
+
+const (
+	// defaultServiceNowPageSize is how many records are requested from the ServiceNow table API at once
+	defaultServiceNowPageSize = 500
+	// maxCMDBImportRows caps the rows read from a CMDB CSV file
+	maxCMDBImportRows = 100000
+)
+
+var (
+	// ErrInvalidCMDBImport is returned when a CMDB CSV file cannot be imported
+	ErrInvalidCMDBImport = errors.New("invalid CMDB import")
+	// ErrServiceNowNotConfigured is returned when a sync is requested without a configured ServiceNow instance
+	ErrServiceNowNotConfigured = errors.New("servicenow CMDB integration is not configured")
+	// ErrServiceNowRequestFailed is returned when ServiceNow rejects the request or cannot be reached
+	ErrServiceNowRequestFailed = errors.New("failed to read servicenow CMDB")
+)
+
+// ServiceNowConfig holds the ServiceNow instance and credentials CMDB items are read from.
+// ApplicationTable and ServiceTable default to cmdb_ci_appl and cmdb_ci_service.
+type ServiceNowConfig struct {
+	BaseURL          string
+	Username         string
+	Password         string
+	ApplicationTable string
+	ServiceTable     string
+}
+
+// Configured reports whether the instance and credentials are set
+func (c *ServiceNowConfig) Configured() bool {
+	return c != nil && c.BaseURL != "" && c.Username != "" && c.Password != ""
+}
+
+// CMDBItem holds the CMDB attributes of an application or business service
+type CMDBItem struct {
+	EntityType  string    `json:"entity_type"`
+	EntityName  string    `json:"entity_name"`
+	Owner       string    `json:"owner"`
+	Environment string    `json:"environment"`
+	Criticality string    `json:"criticality"`
+	Source      string    `json:"source"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+}
+
+// CMDBRefreshResult reports a CMDB import or sync and the incidents whose attributes changed
+type CMDBRefreshResult struct {
+	Source           string                   `json:"source"`
+	Items            int                      `json:"items"`
+	Applications     int                      `json:"applications"`
+	Services         int                      `json:"services"`
+	Skipped          []models.ValidationError `json:"skipped"`
+	UpdatedIncidents int64                    `json:"updated_incidents"`
+	RefreshedAt      time.Time                `json:"refreshed_at"`
+}
+
+// CMDBService keeps a local copy of the owner, environment and criticality the CMDB records for
+// applications and business services, loaded from a CSV export or synced from ServiceNow, and
+// attaches them to incidents
+type CMDBService struct {
+	db         *sql.DB
+	serviceNow *ServiceNowConfig
+	httpClient *http.Client
+
+	mu    sync.RWMutex
+	items map[string]CMDBItem // entity type + key -> item
+}
+
+// NewCMDBService creates a new CMDBService instance
+func NewCMDBService(db *sql.DB) *CMDBService {
+	return &CMDBService{
+		db:         db,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		items:      make(map[string]CMDBItem),
+	}
+}
+
+// SetServiceNowConfig replaces the ServiceNow instance CMDB items are synced from
+func (s *CMDBService) SetServiceNowConfig(config *ServiceNowConfig) {
+	s.serviceNow = config
+}
+
+// cmdbItemKey identifies an item in the in-memory cache
+func cmdbItemKey(entityType, name string) string {
+	return entityType + "|" + ApplicationAliasKey(name)
+}
+
+// LoadItems refreshes the in-memory cache from the local copy
+func (s *CMDBService) LoadItems(ctx context.Context) error {
+	items, err := s.ListItems(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	cache := make(map[string]CMDBItem, len(items))
+	for _, item := range items {
+		cache[cmdbItemKey(item.EntityType, item.EntityName)] = item
+	}
+
+	s.mu.Lock()
+	s.items = cache
+	s.mu.Unlock()
+	return nil
+}
+
+// Lookup returns the CMDB item of an application or, when the application is not in the CMDB,
+// of the business service
+func (s *CMDBService) Lookup(application, service string) (CMDBItem, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if item, ok := s.items[cmdbItemKey(CMDBEntityApplication, application)]; ok && strings.TrimSpace(application) != "" {
+		return item, true
+	}
+	if item, ok := s.items[cmdbItemKey(CMDBEntityService, service)]; ok && strings.TrimSpace(service) != "" {
+		return item, true
+	}
+	return CMDBItem{}, false
+}
+
+// EnrichIncidents attaches the CMDB attributes to incidents. It runs after application and
+// business service names are normalized, so they are matched on their canonical names.
+func (s *CMDBService) EnrichIncidents(incidents []models.Incident) {
+	for i := range incidents {
+		item, _ := s.Lookup(incidents[i].ApplicationName, incidents[i].BusinessService)
+		incidents[i].CMDBOwner = item.Owner
+		incidents[i].CMDBEnvironment = item.Environment
+		incidents[i].CMDBCriticality = item.Criticality
+	}
+}
+
+// ListItems returns the local copy ordered by type and name, only of entityType when set
+func (s *CMDBService) ListItems(ctx context.Context, entityType string) ([]CMDBItem, error) {
+	query := `
+		SELECT entity_type, entity_name, COALESCE(owner, ''), COALESCE(environment, ''),
+			COALESCE(criticality, ''), source, refreshed_at
+		FROM cmdb_items
+		WHERE ? = '' OR entity_type = ?
+		ORDER BY entity_type, entity_name
+	`
+	rows, err := s.db.QueryContext(ctx, query, entityType, entityType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query CMDB items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []CMDBItem{}
+	for rows.Next() {
+		var item CMDBItem
+		if err := rows.Scan(&item.EntityType, &item.EntityName, &item.Owner, &item.Environment,
+			&item.Criticality, &item.Source, &item.RefreshedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan CMDB item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// ImportCSV replaces the CSV-loaded items with those of a CMDB export. The file needs a header
+// naming the type (application or service), name, owner, environment and criticality columns;
+// rows that cannot be read are skipped and reported. Items synced from ServiceNow are kept, but
+// an imported row replaces the synced item of the same name.
+func (s *CMDBService) ImportCSV(ctx context.Context, r io.Reader) (*CMDBRefreshResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read header: %v", ErrInvalidCMDBImport, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"type", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing %s column", ErrInvalidCMDBImport, required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	result := &CMDBRefreshResult{Source: CMDBSourceCSV, Skipped: []models.ValidationError{}, RefreshedAt: time.Now()}
+	var items []CMDBItem
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			result.Skipped = append(result.Skipped, models.ValidationError{Row: row, Message: err.Error()})
+			continue
+		}
+		if len(items) >= maxCMDBImportRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrInvalidCMDBImport, maxCMDBImportRows)
+		}
+
+		item := CMDBItem{
+			EntityType:  strings.ToLower(field(record, "type")),
+			EntityName:  field(record, "name"),
+			Owner:       field(record, "owner"),
+			Environment: field(record, "environment"),
+			Criticality: field(record, "criticality"),
+			Source:      CMDBSourceCSV,
+			RefreshedAt: result.RefreshedAt,
+		}
+		switch {
+		case item.EntityType != CMDBEntityApplication && item.EntityType != CMDBEntityService:
+			result.Skipped = append(result.Skipped, models.ValidationError{Row: row, Field: "type", Value: item.EntityType,
+				Message: "type must be application or service"})
+		case ApplicationAliasKey(item.EntityName) == "":
+			result.Skipped = append(result.Skipped, models.ValidationError{Row: row, Field: "name", Value: item.EntityName,
+				Message: "name must contain letters or digits"})
+		default:
+			items = append(items, item)
+		}
+	}
+
+	if err := s.replaceItems(ctx, CMDBSourceCSV, items, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SyncServiceNow replaces the synced items with the applications and business services of the
+// configured ServiceNow instance
+func (s *CMDBService) SyncServiceNow(ctx context.Context) (*CMDBRefreshResult, error) {
+	if !s.serviceNow.Configured() {
+		return nil, ErrServiceNowNotConfigured
+	}
+
+	result := &CMDBRefreshResult{Source: CMDBSourceServiceNow, Skipped: []models.ValidationError{}, RefreshedAt: time.Now()}
+	var items []CMDBItem
+	for _, table := range []struct{ entityType, name string }{
+		{CMDBEntityApplication, firstNonEmpty(s.serviceNow.ApplicationTable, "cmdb_ci_appl")},
+		{CMDBEntityService, firstNonEmpty(s.serviceNow.ServiceTable, "cmdb_ci_service")},
+	} {
+		records, err := s.fetchServiceNowTable(ctx, table.name)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			item := CMDBItem{
+				EntityType:  table.entityType,
+				EntityName:  strings.TrimSpace(record.Name),
+				Owner:       strings.TrimSpace(record.OwnedBy),
+				Environment: strings.TrimSpace(firstNonEmpty(record.Environment, record.UsedFor)),
+				Criticality: strings.TrimSpace(firstNonEmpty(record.BusinessCriticality, record.BusinesCriticality)),
+				Source:      CMDBSourceServiceNow,
+				RefreshedAt: result.RefreshedAt,
+			}
+			if ApplicationAliasKey(item.EntityName) != "" {
+				items = append(items, item)
+			}
+		}
+	}
+
+	if err := s.replaceItems(ctx, CMDBSourceServiceNow, items, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// serviceNowRecord holds the fields read from a ServiceNow CMDB table, as display values.
+// Business services record their environment in used_for and, on most instances, their
+// criticality in the misspelled busines_criticality field.
+type serviceNowRecord struct {
+	Name                string `json:"name"`
+	OwnedBy             string `json:"owned_by"`
+	Environment         string `json:"environment"`
+	UsedFor             string `json:"used_for"`
+	BusinessCriticality string `json:"business_criticality"`
+	BusinesCriticality  string `json:"busines_criticality"`
+}
+
+// fetchServiceNowTable reads every active record of a CMDB table through the ServiceNow table API
+func (s *CMDBService) fetchServiceNowTable(ctx context.Context, table string) ([]serviceNowRecord, error) {
+	var records []serviceNowRecord
+	for offset := 0; ; offset += defaultServiceNowPageSize {
+		params := url.Values{}
+		params.Set("sysparm_fields", "name,owned_by,environment,used_for,business_criticality,busines_criticality")
+		params.Set("sysparm_display_value", "true")
+		params.Set("sysparm_exclude_reference_link", "true")
+		params.Set("sysparm_query", "operational_status=1^ORoperational_statusISEMPTY")
+		params.Set("sysparm_limit", strconv.Itoa(defaultServiceNowPageSize))
+		params.Set("sysparm_offset", strconv.Itoa(offset))
+		endpoint := strings.TrimRight(s.serviceNow.BaseURL, "/") + "/api/now/table/" + url.PathEscape(table) + "?" + params.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrServiceNowRequestFailed, err)
+		}
+		req.SetBasicAuth(s.serviceNow.Username, s.serviceNow.Password)
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrServiceNowRequestFailed, err)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrServiceNowRequestFailed, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			var snError struct {
+				Error struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			message := resp.Status
+			if json.Unmarshal(body, &snError) == nil && snError.Error.Message != "" {
+				message += "; " + snError.Error.Message
+			}
+			return nil, fmt.Errorf("%w: %s: %s", ErrServiceNowRequestFailed, table, message)
+		}
+
+		var page struct {
+			Result []serviceNowRecord `json:"result"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrServiceNowRequestFailed, table, err)
+		}
+		records = append(records, page.Result...)
+		if len(page.Result) < defaultServiceNowPageSize {
+			return records, nil
+		}
+	}
+}
+
+// replaceItems swaps the items of a source for items, reloads the cache and re-applies it to the
+// stored incidents. Later rows win when a name appears more than once.
+func (s *CMDBService) replaceItems(ctx context.Context, source string, items []CMDBItem, result *CMDBRefreshResult) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM cmdb_items WHERE source = ?", source); err != nil {
+		return fmt.Errorf("failed to clear %s CMDB items: %w", source, err)
+	}
+
+	unique := make(map[string]CMDBItem, len(items))
+	var order []string
+	for _, item := range items {
+		key := cmdbItemKey(item.EntityType, item.EntityName)
+		if _, ok := unique[key]; !ok {
+			order = append(order, key)
+		}
+		unique[key] = item
+	}
+
+	query := `
+		INSERT OR REPLACE INTO cmdb_items (entity_type, entity_key, entity_name, owner, environment, criticality, source, refreshed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	for _, key := range order {
+		item := unique[key]
+		if _, err := tx.ExecContext(ctx, query, item.EntityType, ApplicationAliasKey(item.EntityName), item.EntityName,
+			nullIfEmpty(item.Owner), nullIfEmpty(item.Environment), nullIfEmpty(item.Criticality),
+			item.Source, item.RefreshedAt); err != nil {
+			return fmt.Errorf("failed to save CMDB item %s: %w", item.EntityName, err)
+		}
+		if item.EntityType == CMDBEntityApplication {
+			result.Applications++
+		} else {
+			result.Services++
+		}
+	}
+	result.Items = len(order)
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit CMDB items: %w", err)
+	}
+
+	if err := s.LoadItems(ctx); err != nil {
+		return err
+	}
+	result.UpdatedIncidents, err = s.ApplyToIncidents(ctx)
+	return err
+}
+
+// ApplyToIncidents re-attaches the cached CMDB attributes to the stored incidents, returning how
+// many changed
+func (s *CMDBService) ApplyToIncidents(ctx context.Context) (int64, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT DISTINCT COALESCE(application_name, ''), COALESCE(business_service, '')
+		FROM incidents
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query incident applications: %w", err)
+	}
+	type entity struct{ application, service string }
+	var entities []entity
+	for rows.Next() {
+		var e entity
+		if err := rows.Scan(&e.application, &e.service); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan incident application: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read incident applications: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE incidents
+		SET cmdb_owner = ?, cmdb_environment = ?, cmdb_criticality = ?
+		WHERE COALESCE(application_name, '') = ? AND COALESCE(business_service, '') = ?
+			AND (cmdb_owner IS DISTINCT FROM ? OR cmdb_environment IS DISTINCT FROM ?
+				OR cmdb_criticality IS DISTINCT FROM ?)
+	`
+	var updated int64
+	for _, e := range entities {
+		item, _ := s.Lookup(e.application, e.service)
+		owner, environment, criticality := nullIfEmpty(item.Owner), nullIfEmpty(item.Environment), nullIfEmpty(item.Criticality)
+		res, err := tx.ExecContext(ctx, query, owner, environment, criticality, e.application, e.service,
+			owner, environment, criticality)
+		if err != nil {
+			return 0, fmt.Errorf("failed to enrich incidents of %q: %w", e.application, err)
+		}
+		n, _ := res.RowsAffected()
+		updated += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit CMDB enrichment: %w", err)
+	}
+	return updated, nil
+}
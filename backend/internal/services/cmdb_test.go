@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestCMDBService_ImportAndEnrich(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	cmdb := NewCMDBService(db)
+	ctx := context.Background()
+
+	portal := diffTestIncident("i1", "upload-1", "INC001", "P1", "Open")
+	billing := diffTestIncident("i2", "upload-1", "INC002", "P2", "Open")
+	billing.ApplicationName = "Billing"
+	billing.BusinessService = "Payments"
+	unknown := diffTestIncident("i3", "upload-1", "INC003", "P3", "Open")
+	unknown.ApplicationName = "Legacy"
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, []models.Incident{portal, billing, unknown}, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	csvFile := "\ufeffType,Name,Owner,Environment,Criticality\n" +
+		"application,portal,Web Team,Production,High\n" +
+		"service,Payments,Finance IT,Production,Critical\n" +
+		"database,Orders DB,DBA,Production,High\n" +
+		"application,,Nobody,,\n"
+	result, err := cmdb.ImportCSV(ctx, strings.NewReader(csvFile))
+	if err != nil {
+		t.Fatalf("Failed to import CMDB file: %v", err)
+	}
+	if result.Items != 2 || result.Applications != 1 || result.Services != 1 || len(result.Skipped) != 2 {
+		t.Fatalf("Unexpected import result: %+v", result)
+	}
+	if result.UpdatedIncidents != 2 {
+		t.Errorf("Expected 2 enriched incidents, got %d", result.UpdatedIncidents)
+	}
+
+	// Applications are matched ignoring case and punctuation, then incidents fall back to their service
+	incidents, err := NewIncidentService(db).GetIncidentsByUpload(ctx, "upload-1")
+	if err != nil {
+		t.Fatalf("Failed to read incidents: %v", err)
+	}
+	owners := make(map[string]string)
+	for _, incident := range incidents {
+		owners[incident.IncidentID] = incident.CMDBOwner + "/" + incident.CMDBCriticality
+	}
+	if owners["INC001"] != "Web Team/High" || owners["INC002"] != "Finance IT/Critical" || owners["INC003"] != "/" {
+		t.Errorf("Unexpected CMDB attributes: %v", owners)
+	}
+
+	// Re-applying an unchanged CMDB leaves incidents alone
+	if updated, err := cmdb.ApplyToIncidents(ctx); err != nil || updated != 0 {
+		t.Errorf("Expected no changes, got %d (%v)", updated, err)
+	}
+
+	// CMDB attributes filter analytics
+	metrics, err := NewAnalyticsService(db).GetResolutionAnalysis(ctx, &TimelineFilters{Criticalities: []string{"Critical"}})
+	if err != nil {
+		t.Fatalf("Failed to get filtered analytics: %v", err)
+	}
+	if metrics.TotalIncidents != 1 {
+		t.Errorf("Expected 1 critical incident, got %d", metrics.TotalIncidents)
+	}
+
+	// New incidents are enriched from the cache as they are processed
+	fresh := []models.Incident{{ApplicationName: "Portal"}, {ApplicationName: "Other", BusinessService: "payments"}}
+	cmdb.EnrichIncidents(fresh)
+	if fresh[0].CMDBEnvironment != "Production" || fresh[1].CMDBOwner != "Finance IT" {
+		t.Errorf("Unexpected enrichment: %+v", fresh)
+	}
+
+	// A file without the required columns is rejected
+	if _, err := cmdb.ImportCSV(ctx, strings.NewReader("name,owner\nPortal,Web Team\n")); err == nil {
+		t.Error("Expected an import without a type column to fail")
+	}
+}
+
+func TestCMDBService_SyncServiceNow(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	cmdb := NewCMDBService(db)
+	ctx := context.Background()
+
+	if _, err := cmdb.SyncServiceNow(ctx); err != ErrServiceNowNotConfigured {
+		t.Fatalf("Expected ErrServiceNowNotConfigured, got %v", err)
+	}
+
+	var tables []string
+	serviceNow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "sync" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		table := strings.TrimPrefix(r.URL.Path, "/api/now/table/")
+		tables = append(tables, table)
+		records := []map[string]string{}
+		switch table {
+		case "cmdb_ci_appl":
+			records = append(records, map[string]string{"name": "Portal", "owned_by": "Jane Doe", "environment": "Production", "business_criticality": "1 - most critical"})
+		case "cmdb_ci_service":
+			records = append(records, map[string]string{"name": "Payments", "owned_by": "John Roe", "used_for": "Production", "busines_criticality": "2 - somewhat critical"})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": records})
+	}))
+	defer serviceNow.Close()
+
+	cmdb.SetServiceNowConfig(&ServiceNowConfig{BaseURL: serviceNow.URL, Username: "sync", Password: "secret"})
+	result, err := cmdb.SyncServiceNow(ctx)
+	if err != nil {
+		t.Fatalf("Failed to sync ServiceNow: %v", err)
+	}
+	if result.Applications != 1 || result.Services != 1 || len(tables) != 2 {
+		t.Fatalf("Unexpected sync result: %+v, tables %v", result, tables)
+	}
+
+	items, err := cmdb.ListItems(ctx, CMDBEntityService)
+	if err != nil {
+		t.Fatalf("Failed to list CMDB items: %v", err)
+	}
+	if len(items) != 1 || items[0].Environment != "Production" || items[0].Criticality != "2 - somewhat critical" ||
+		items[0].Source != CMDBSourceServiceNow {
+		t.Errorf("Unexpected synced services: %+v", items)
+	}
+
+	// A CSV import keeps the synced items
+	if _, err := cmdb.ImportCSV(ctx, strings.NewReader("type,name,owner\napplication,Billing,Finance IT\n")); err != nil {
+		t.Fatalf("Failed to import CMDB file: %v", err)
+	}
+	if items, _ := cmdb.ListItems(ctx, ""); len(items) != 3 {
+		t.Errorf("Expected 3 items after import, got %+v", items)
+	}
+
+	cmdb.SetServiceNowConfig(&ServiceNowConfig{BaseURL: serviceNow.URL, Username: "sync", Password: "wrong"})
+	if _, err := cmdb.SyncServiceNow(ctx); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected a rejected sync, got %v", err)
+	}
+}
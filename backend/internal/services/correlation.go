@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"incident-management-system/internal/logging"
+)
+
+// correlationPrefix returns the prefix tying a log line to the request, and the user, that
+// started the work it describes, or "" when neither is known
+func correlationPrefix(requestID, userID string) string {
+	switch {
+	case requestID != "" && userID != "":
+		return fmt.Sprintf("[request %s, user %s] ", requestID, userID)
+	case requestID != "":
+		return fmt.Sprintf("[request %s] ", requestID)
+	case userID != "":
+		return fmt.Sprintf("[user %s] ", userID)
+	}
+	return ""
+}
+
+// logf logs like log.Printf, prefixed with the request and user carried by ctx so the lines of
+// background work can be traced to the request that started it
+func logf(ctx context.Context, format string, args ...interface{}) {
+	log.Print(correlationPrefix(logging.GetRequestID(ctx), logging.GetUserID(ctx)) + fmt.Sprintf(format, args...))
+}
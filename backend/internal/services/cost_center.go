@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cost center assignment entity types
+const (
+	CostCenterEntityApplication = "application"
+	CostCenterEntityGroup       = "group"
+)
+
+// DefaultChargebackHourlyRate is the handling rate used when an assignment has no rate of its own
+const DefaultChargebackHourlyRate = 75.0
+
+// UnallocatedCostCenter is reported for incidents whose application or group has no cost center
+const UnallocatedCostCenter = "Unallocated"
+
+// costCenterEntityColumns maps each assignment entity type to the incidents column it matches
+var costCenterEntityColumns = map[string]string{
+	CostCenterEntityApplication: "application_name",
+	CostCenterEntityGroup:       "resolution_group",
+}
+
+// CostCenterAssignment attributes an application or resolution group to a cost center
+type CostCenterAssignment struct {
+	EntityType string    `json:"entity_type"`
+	EntityName string    `json:"entity_name"`
+	CostCenter string    `json:"cost_center"`
+	HourlyRate *float64  `json:"hourly_rate,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ChargebackLine is the handling effort allocated to one cost center in one month
+type ChargebackLine struct {
+	Month           string  `json:"month"`
+	CostCenter      string  `json:"cost_center"`
+	IncidentCount   int     `json:"incident_count"`
+	ResolutionHours float64 `json:"resolution_hours"`
+	Amount          float64 `json:"amount"`
+}
+
+// ChargebackReport allocates incident handling effort to cost centers per month
+type ChargebackReport struct {
+	Basis       string           `json:"basis"`
+	DefaultRate float64          `json:"default_rate"`
+	TotalHours  float64          `json:"total_hours"`
+	TotalAmount float64          `json:"total_amount"`
+	Lines       []ChargebackLine `json:"lines"`
+}
+
+// IsValidCostCenterEntity reports whether entityType can be assigned to a cost center
+func IsValidCostCenterEntity(entityType string) bool {
+	_, ok := costCenterEntityColumns[entityType]
+	return ok
+}
+
+// CostCenterService manages the cost center registry and chargeback reporting
+type CostCenterService struct {
+	db *sql.DB
+}
+
+// NewCostCenterService creates a new CostCenterService instance
+func NewCostCenterService(db *sql.DB) *CostCenterService {
+	return &CostCenterService{db: db}
+}
+
+// ListAssignments returns all cost center assignments ordered by cost center
+func (s *CostCenterService) ListAssignments(ctx context.Context) ([]CostCenterAssignment, error) {
+	query := `
+		SELECT entity_type, entity_name, cost_center, hourly_rate, updated_at
+		FROM cost_center_assignments
+		ORDER BY cost_center, entity_type, entity_name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cost center assignments: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := []CostCenterAssignment{}
+	for rows.Next() {
+		var assignment CostCenterAssignment
+		var hourlyRate sql.NullFloat64
+		if err := rows.Scan(&assignment.EntityType, &assignment.EntityName, &assignment.CostCenter, &hourlyRate, &assignment.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan cost center assignment: %w", err)
+		}
+		if hourlyRate.Valid {
+			assignment.HourlyRate = &hourlyRate.Float64
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	return assignments, rows.Err()
+}
+
+// SaveAssignment creates or replaces the cost center assignment of an application or group
+func (s *CostCenterService) SaveAssignment(ctx context.Context, entityType, entityName, costCenter string, hourlyRate *float64) (*CostCenterAssignment, error) {
+	if !IsValidCostCenterEntity(entityType) {
+		return nil, fmt.Errorf("invalid cost center entity type: %s", entityType)
+	}
+
+	assignment := &CostCenterAssignment{
+		EntityType: entityType,
+		EntityName: strings.TrimSpace(entityName),
+		CostCenter: strings.TrimSpace(costCenter),
+		HourlyRate: hourlyRate,
+		UpdatedAt:  time.Now(),
+	}
+	if assignment.EntityName == "" || assignment.CostCenter == "" {
+		return nil, fmt.Errorf("entity name and cost center are required")
+	}
+
+	query := `
+		INSERT OR REPLACE INTO cost_center_assignments (entity_type, entity_name, cost_center, hourly_rate, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, assignment.EntityType, assignment.EntityName, assignment.CostCenter, hourlyRate, assignment.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save cost center assignment: %w", err)
+	}
+
+	return assignment, nil
+}
+
+// DeleteAssignment removes a cost center assignment, returning sql.ErrNoRows when it does not exist
+func (s *CostCenterService) DeleteAssignment(ctx context.Context, entityType, entityName string) error {
+	result, err := s.db.ExecContext(ctx,
+		"DELETE FROM cost_center_assignments WHERE entity_type = ? AND entity_name = ?",
+		entityType, strings.TrimSpace(entityName))
+	if err != nil {
+		return fmt.Errorf("failed to delete cost center assignment: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetChargebackReport allocates resolution hours × hourly rate to cost centers per month of the
+// report date, or the recorded cost of incidents that have one. basis selects whether incidents
+// are attributed through their application or their resolution group; assignments without a
+// rate of their own use defaultRate.
+func (s *CostCenterService) GetChargebackReport(ctx context.Context, basis string, defaultRate float64, filters *TimelineFilters) (*ChargebackReport, error) {
+	column, ok := costCenterEntityColumns[basis]
+	if !ok {
+		return nil, fmt.Errorf("invalid chargeback basis: %s", basis)
+	}
+	if defaultRate <= 0 {
+		defaultRate = DefaultChargebackHourlyRate
+	}
+
+	whereClause, args, nextIdx := buildFilterConditions(ctx, filters, 1)
+	query := fmt.Sprintf(`
+		SELECT
+			strftime(i.report_date, '%%Y-%%m') as month,
+			COALESCE(a.cost_center, '%s') as cost_center,
+			COUNT(*) as incident_count,
+			SUM(COALESCE(i.resolution_time_hours, 0)) as resolution_hours,
+			SUM(%s) as amount
+		FROM incidents i
+		LEFT JOIN cost_center_assignments a ON a.entity_type = $%d AND a.entity_name = i.%s
+		WHERE 1=1`, UnallocatedCostCenter, incidentCostExpression(nextIdx), nextIdx+1, column)
+	query += whereClause
+	query += " GROUP BY month, cost_center ORDER BY month, cost_center"
+	args = append(args, defaultRate, basis)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chargeback report: %w", err)
+	}
+	defer rows.Close()
+
+	report := &ChargebackReport{
+		Basis:       basis,
+		DefaultRate: defaultRate,
+		Lines:       []ChargebackLine{},
+	}
+	for rows.Next() {
+		var line ChargebackLine
+		if err := rows.Scan(&line.Month, &line.CostCenter, &line.IncidentCount, &line.ResolutionHours, &line.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan chargeback row: %w", err)
+		}
+		line.Amount = roundCurrency(line.Amount)
+		report.TotalHours += line.ResolutionHours
+		report.TotalAmount += line.Amount
+		report.Lines = append(report.Lines, line)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating chargeback rows: %w", err)
+	}
+
+	report.TotalAmount = roundCurrency(report.TotalAmount)
+	return report, nil
+}
+
+// CostBreakdownLine is the incident cost of one priority, application, month or cost center
+type CostBreakdownLine struct {
+	Key           string  `json:"key"`
+	IncidentCount int     `json:"incident_count"`
+	TotalCost     float64 `json:"total_cost"`
+	AvgCost       float64 `json:"avg_cost"`
+}
+
+// CostSummary totals the cost of incidents and breaks it down by priority, application, month of
+// the report date and cost center. Incidents with a recorded cost use it; the cost of the others
+// is derived from their resolution hours × the hourly rate of their cost center.
+type CostSummary struct {
+	Basis         string              `json:"basis"`
+	DefaultRate   float64             `json:"default_rate"`
+	IncidentCount int                 `json:"incident_count"`
+	RecordedCount int                 `json:"recorded_count"`
+	DerivedCount  int                 `json:"derived_count"`
+	TotalCost     float64             `json:"total_cost"`
+	AvgCost       float64             `json:"avg_cost"`
+	ByPriority    []CostBreakdownLine `json:"by_priority"`
+	ByApplication []CostBreakdownLine `json:"by_application"`
+	ByMonth       []CostBreakdownLine `json:"by_month"`
+	ByCostCenter  []CostBreakdownLine `json:"by_cost_center"`
+}
+
+// GetCostSummary summarizes incident cost. basis selects whether incidents are attributed to
+// cost centers, and priced, through their application or their resolution group; assignments
+// without a rate of their own use defaultRate.
+func (s *CostCenterService) GetCostSummary(ctx context.Context, basis string, defaultRate float64, filters *TimelineFilters) (*CostSummary, error) {
+	column, ok := costCenterEntityColumns[basis]
+	if !ok {
+		return nil, fmt.Errorf("invalid cost basis: %s", basis)
+	}
+	if defaultRate <= 0 {
+		defaultRate = DefaultChargebackHourlyRate
+	}
+
+	whereClause, args, nextIdx := buildFilterConditions(ctx, filters, 1)
+	query := fmt.Sprintf(`
+		WITH costed AS (
+			SELECT
+				i.priority,
+				i.application_name,
+				strftime(i.report_date, '%%Y-%%m') as month,
+				COALESCE(a.cost_center, '%s') as cost_center,
+				i.cost IS NOT NULL as recorded,
+				%s as cost
+			FROM incidents i
+			LEFT JOIN cost_center_assignments a ON a.entity_type = $%d AND a.entity_name = i.%s
+			WHERE 1=1%s
+		)
+		SELECT
+			GROUPING(priority), GROUPING(application_name), GROUPING(month), GROUPING(cost_center),
+			COALESCE(priority, ''), COALESCE(application_name, ''), COALESCE(month, ''), COALESCE(cost_center, ''),
+			COUNT(*), COUNT(CASE WHEN recorded THEN 1 END), COALESCE(SUM(cost), 0)
+		FROM costed
+		GROUP BY GROUPING SETS ((priority), (application_name), (month), (cost_center), ())
+		ORDER BY 11 DESC, 5, 6, 7, 8`,
+		UnallocatedCostCenter, incidentCostExpression(nextIdx+1), nextIdx, column, whereClause)
+	args = append(args, basis, defaultRate)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cost summary: %w", err)
+	}
+	defer rows.Close()
+
+	summary := &CostSummary{
+		Basis:         basis,
+		DefaultRate:   defaultRate,
+		ByPriority:    []CostBreakdownLine{},
+		ByApplication: []CostBreakdownLine{},
+		ByMonth:       []CostBreakdownLine{},
+		ByCostCenter:  []CostBreakdownLine{},
+	}
+	for rows.Next() {
+		var byPriority, byApplication, byMonth, byCostCenter int
+		var priority, application, month, costCenter string
+		var count, recorded int
+		var total float64
+		if err := rows.Scan(&byPriority, &byApplication, &byMonth, &byCostCenter, &priority, &application, &month, &costCenter,
+			&count, &recorded, &total); err != nil {
+			return nil, fmt.Errorf("failed to scan cost summary row: %w", err)
+		}
+
+		line := CostBreakdownLine{IncidentCount: count, TotalCost: roundCurrency(total)}
+		if count > 0 {
+			line.AvgCost = roundCurrency(total / float64(count))
+		}
+		// GROUPING is 0 for the columns a row is grouped by
+		switch {
+		case byPriority == 0:
+			line.Key = priority
+			summary.ByPriority = append(summary.ByPriority, line)
+		case byApplication == 0:
+			line.Key = application
+			summary.ByApplication = append(summary.ByApplication, line)
+		case byMonth == 0:
+			line.Key = month
+			summary.ByMonth = append(summary.ByMonth, line)
+		case byCostCenter == 0:
+			line.Key = costCenter
+			summary.ByCostCenter = append(summary.ByCostCenter, line)
+		default:
+			summary.IncidentCount = count
+			summary.RecordedCount = recorded
+			summary.DerivedCount = count - recorded
+			summary.TotalCost = line.TotalCost
+			summary.AvgCost = line.AvgCost
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cost summary rows: %w", err)
+	}
+
+	sort.Slice(summary.ByMonth, func(i, j int) bool { return summary.ByMonth[i].Key < summary.ByMonth[j].Key })
+	return summary, nil
+}
+
+// incidentCostExpression returns the SQL expression giving the cost of incident i joined with its
+// cost center assignment a: the recorded cost, or else its resolution hours × the assignment's
+// rate, falling back to the default rate bound to parameter rateIdx
+func incidentCostExpression(rateIdx int) string {
+	return fmt.Sprintf("COALESCE(i.cost, COALESCE(i.resolution_time_hours, 0) * COALESCE(a.hourly_rate, $%d))", rateIdx)
+}
+
+// roundCurrency rounds an amount to cents
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
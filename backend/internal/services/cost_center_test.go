@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestCostCenterService_GetChargebackReport(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	service := NewCostCenterService(db)
+	ctx := context.Background()
+
+	hours := func(h int) *int { return &h }
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P1", "Closed"),
+		diffTestIncident("i2", "upload-1", "INC002", "P2", "Closed"),
+		diffTestIncident("i3", "upload-1", "INC003", "P3", "Closed"),
+		diffTestIncident("i4", "upload-1", "INC004", "P3", "Open"),
+	}
+	incidents[0].ApplicationName = "Billing"
+	incidents[0].ResolutionTimeHours = hours(4)
+	incidents[1].ApplicationName = "Billing"
+	incidents[1].ResolutionTimeHours = hours(2)
+	incidents[1].ReportDate = time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)
+	incidents[2].ApplicationName = "Portal"
+	incidents[2].ResolutionTimeHours = hours(10)
+	incidents[3].ApplicationName = "Legacy"
+
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	rate := 100.0
+	if _, err := service.SaveAssignment(ctx, CostCenterEntityApplication, "Billing", "CC-100", &rate); err != nil {
+		t.Fatalf("Failed to save assignment: %v", err)
+	}
+	if _, err := service.SaveAssignment(ctx, CostCenterEntityApplication, "Portal", "CC-200", nil); err != nil {
+		t.Fatalf("Failed to save assignment: %v", err)
+	}
+
+	report, err := service.GetChargebackReport(ctx, CostCenterEntityApplication, 50, nil)
+	if err != nil {
+		t.Fatalf("Failed to get chargeback report: %v", err)
+	}
+
+	expected := []ChargebackLine{
+		{Month: "2024-01", CostCenter: "CC-100", IncidentCount: 1, ResolutionHours: 4, Amount: 400},
+		{Month: "2024-01", CostCenter: "CC-200", IncidentCount: 1, ResolutionHours: 10, Amount: 500},
+		{Month: "2024-01", CostCenter: UnallocatedCostCenter, IncidentCount: 1, ResolutionHours: 0, Amount: 0},
+		{Month: "2024-02", CostCenter: "CC-100", IncidentCount: 1, ResolutionHours: 2, Amount: 200},
+	}
+	if len(report.Lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %+v", len(expected), report.Lines)
+	}
+	for i, line := range expected {
+		if report.Lines[i] != line {
+			t.Errorf("Line %d: expected %+v, got %+v", i, line, report.Lines[i])
+		}
+	}
+	if report.TotalAmount != 1100 || report.TotalHours != 16 {
+		t.Errorf("Unexpected totals: hours=%v amount=%v", report.TotalHours, report.TotalAmount)
+	}
+
+	// Attributing by group ignores application assignments
+	byGroup, err := service.GetChargebackReport(ctx, CostCenterEntityGroup, 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to get group chargeback report: %v", err)
+	}
+	if byGroup.DefaultRate != DefaultChargebackHourlyRate {
+		t.Errorf("Expected default rate %v, got %v", DefaultChargebackHourlyRate, byGroup.DefaultRate)
+	}
+	for _, line := range byGroup.Lines {
+		if line.CostCenter != UnallocatedCostCenter {
+			t.Errorf("Expected only unallocated lines, got %+v", line)
+		}
+	}
+
+	if err := service.DeleteAssignment(ctx, CostCenterEntityApplication, "Portal"); err != nil {
+		t.Fatalf("Failed to delete assignment: %v", err)
+	}
+	if err := service.DeleteAssignment(ctx, CostCenterEntityApplication, "Portal"); err == nil {
+		t.Error("Expected error deleting missing assignment")
+	}
+}
+
+func TestCostCenterService_GetCostSummary(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	service := NewCostCenterService(db)
+	ctx := context.Background()
+
+	hours := func(h int) *int { return &h }
+	recorded := 150.0
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P1", "Closed"),
+		diffTestIncident("i2", "upload-1", "INC002", "P2", "Closed"),
+		diffTestIncident("i3", "upload-1", "INC003", "P3", "Closed"),
+		diffTestIncident("i4", "upload-1", "INC004", "P3", "Open"),
+	}
+	incidents[0].ApplicationName = "Billing"
+	incidents[0].ResolutionTimeHours = hours(4)
+	incidents[1].ApplicationName = "Billing"
+	incidents[1].ResolutionTimeHours = hours(2)
+	incidents[1].Cost = &recorded
+	incidents[1].ReportDate = time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)
+	incidents[2].ApplicationName = "Portal"
+	incidents[2].ResolutionTimeHours = hours(10)
+	incidents[3].ApplicationName = "Legacy"
+
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	rate := 100.0
+	if _, err := service.SaveAssignment(ctx, CostCenterEntityApplication, "Billing", "CC-100", &rate); err != nil {
+		t.Fatalf("Failed to save assignment: %v", err)
+	}
+	if _, err := service.SaveAssignment(ctx, CostCenterEntityApplication, "Portal", "CC-200", nil); err != nil {
+		t.Fatalf("Failed to save assignment: %v", err)
+	}
+
+	// The recorded cost of INC002 wins over its derived 2h × 100
+	summary, err := service.GetCostSummary(ctx, CostCenterEntityApplication, 50, nil)
+	if err != nil {
+		t.Fatalf("Failed to get cost summary: %v", err)
+	}
+	if summary.IncidentCount != 4 || summary.RecordedCount != 1 || summary.DerivedCount != 3 ||
+		summary.TotalCost != 1050 || summary.AvgCost != 262.5 {
+		t.Errorf("Unexpected totals: %+v", summary)
+	}
+
+	expected := map[string][]CostBreakdownLine{
+		"priority": {
+			{Key: "P3", IncidentCount: 2, TotalCost: 500, AvgCost: 250},
+			{Key: "P1", IncidentCount: 1, TotalCost: 400, AvgCost: 400},
+			{Key: "P2", IncidentCount: 1, TotalCost: 150, AvgCost: 150},
+		},
+		"application": {
+			{Key: "Billing", IncidentCount: 2, TotalCost: 550, AvgCost: 275},
+			{Key: "Portal", IncidentCount: 1, TotalCost: 500, AvgCost: 500},
+			{Key: "Legacy", IncidentCount: 1, TotalCost: 0, AvgCost: 0},
+		},
+		"month": {
+			{Key: "2024-01", IncidentCount: 3, TotalCost: 900, AvgCost: 300},
+			{Key: "2024-02", IncidentCount: 1, TotalCost: 150, AvgCost: 150},
+		},
+		"cost center": {
+			{Key: "CC-100", IncidentCount: 2, TotalCost: 550, AvgCost: 275},
+			{Key: "CC-200", IncidentCount: 1, TotalCost: 500, AvgCost: 500},
+			{Key: UnallocatedCostCenter, IncidentCount: 1, TotalCost: 0, AvgCost: 0},
+		},
+	}
+	actual := map[string][]CostBreakdownLine{
+		"priority":    summary.ByPriority,
+		"application": summary.ByApplication,
+		"month":       summary.ByMonth,
+		"cost center": summary.ByCostCenter,
+	}
+	for dimension, lines := range expected {
+		if len(actual[dimension]) != len(lines) {
+			t.Errorf("Expected %d lines by %s, got %+v", len(lines), dimension, actual[dimension])
+			continue
+		}
+		for i, line := range lines {
+			if actual[dimension][i] != line {
+				t.Errorf("Line %d by %s: expected %+v, got %+v", i, dimension, line, actual[dimension][i])
+			}
+		}
+	}
+
+	// The chargeback report charges the recorded cost too
+	report, err := service.GetChargebackReport(ctx, CostCenterEntityApplication, 50, nil)
+	if err != nil {
+		t.Fatalf("Failed to get chargeback report: %v", err)
+	}
+	if report.TotalAmount != 1050 {
+		t.Errorf("Expected the chargeback to total 1050, got %v", report.TotalAmount)
+	}
+
+	if _, err := service.GetCostSummary(ctx, "department", 0, nil); err == nil {
+		t.Error("Expected error for an invalid basis")
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"fmt"
+)
+
+// observedValueColumns are the free-text incident fields whose stored values the data dictionary
+// lists, as the source data does not restrict them to a set
+var observedValueColumns = map[string]string{
+	"status": "status",
+}
+
+// ObservedValue counts the incidents holding one value of a field
+type ObservedValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// HasObservedValues reports whether the stored values of an incident field can be listed
+func HasObservedValues(field string) bool {
+	_, ok := observedValueColumns[field]
+	return ok
+}
+
+// GetObservedValues returns the most common stored values of an incident field, within the
+// caller's data scope, most common first
+func (s *AnalyticsService) GetObservedValues(ctx context.Context, field string, limit int) ([]ObservedValue, error) {
+	column, ok := observedValueColumns[field]
+	if !ok {
+		return nil, fmt.Errorf("values of field %s are not listed", field)
+	}
+
+	whereClause, args, argIndex := buildFilterConditions(ctx, nil, 1)
+	query := fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*) AS count
+		FROM incidents
+		WHERE NULLIF(TRIM(%[1]s), '') IS NOT NULL%[2]s
+		GROUP BY %[1]s
+		ORDER BY count DESC, %[1]s
+		LIMIT $%[3]d`, column, whereClause, argIndex)
+	rows, err := s.db.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s values: %w", field, err)
+	}
+	defer rows.Close()
+
+	values := []ObservedValue{}
+	for rows.Next() {
+		var value ObservedValue
+		if err := rows.Scan(&value.Value, &value.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan %s value: %w", field, err)
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Data-quality alert types
+const (
+	AlertUploadContinuity  = "upload_continuity"
+	AlertDistributionShift = "distribution_shift"
+)
+
+// Data-quality alert severities
+const (
+	AlertSeverityWarning = "warning"
+	AlertSeverityHigh    = "high"
+)
+
+// DefaultAlertLimit is how many alerts are listed when no limit is given
+const DefaultAlertLimit = 50
+
+// DataQualityAlert flags a problem found in the data of an upload, such as counts that do not
+// match the upload before it
+type DataQualityAlert struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Severity  string          `json:"severity"`
+	UploadID  string          `json:"upload_id,omitempty"`
+	Message   string          `json:"message"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// DataQualityService stores and lists data-quality alerts
+type DataQualityService struct {
+	db *sql.DB
+}
+
+// NewDataQualityService creates a new DataQualityService instance
+func NewDataQualityService(db *sql.DB) *DataQualityService {
+	return &DataQualityService{db: db}
+}
+
+// CreateAlert stores an alert, filling in its ID and creation time. details is stored as JSON.
+func (s *DataQualityService) CreateAlert(ctx context.Context, alert *DataQualityAlert, details interface{}) error {
+	if details != nil {
+		data, err := json.Marshal(details)
+		if err != nil {
+			return fmt.Errorf("failed to encode alert details: %w", err)
+		}
+		alert.Details = data
+	}
+	alert.ID = uuid.New().String()
+	alert.CreatedAt = time.Now().UTC()
+
+	query := `
+		INSERT INTO data_quality_alerts (id, type, severity, upload_id, message, details, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, alert.ID, alert.Type, alert.Severity, nullIfEmpty(alert.UploadID),
+		alert.Message, nullIfEmpty(string(alert.Details)), alert.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save data quality alert: %w", err)
+	}
+	return nil
+}
+
+// ListAlerts returns the most recent alerts first, only those of uploadID when it is set
+func (s *DataQualityService) ListAlerts(ctx context.Context, uploadID string, limit int) ([]DataQualityAlert, error) {
+	if limit <= 0 {
+		limit = DefaultAlertLimit
+	}
+	query := `
+		SELECT id, type, severity, COALESCE(upload_id, ''), message, COALESCE(details, ''), created_at
+		FROM data_quality_alerts
+		WHERE ? = '' OR upload_id = ?
+		ORDER BY created_at DESC, id
+		LIMIT ?
+	`
+	rows, err := s.db.QueryContext(ctx, query, uploadID, uploadID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data quality alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []DataQualityAlert{}
+	for rows.Next() {
+		var alert DataQualityAlert
+		var details string
+		if err := rows.Scan(&alert.ID, &alert.Type, &alert.Severity, &alert.UploadID, &alert.Message,
+			&details, &alert.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan data quality alert: %w", err)
+		}
+		if details != "" {
+			alert.Details = json.RawMessage(details)
+		}
+		alerts = append(alerts, alert)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating data quality alerts: %w", err)
+	}
+	return alerts, nil
+}
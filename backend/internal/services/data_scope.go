@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Scope types name the incident column a scope entry restricts
+const (
+	ScopeTypeApplication = "application"
+	ScopeTypeGroup       = "group"
+)
+
+// ErrEmptyScope is returned when a scope would allow no applications and no groups
+var ErrEmptyScope = errors.New("a scope needs at least one application or group")
+
+// DataScope restricts a user to the incidents of certain applications or resolution groups. An
+// incident is visible when either its application or its resolution group is allowed.
+type DataScope struct {
+	UserID       string    `json:"user_id"`
+	Applications []string  `json:"applications"`
+	Groups       []string  `json:"groups"`
+	UpdatedBy    string    `json:"updated_by,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// dataScopeKey is the context key of the data scope of the user making a request
+type dataScopeKey struct{}
+
+// WithDataScope returns a context restricted to the incidents scope allows. A nil scope leaves
+// the context unrestricted.
+func WithDataScope(ctx context.Context, scope *DataScope) context.Context {
+	if scope == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, dataScopeKey{}, scope)
+}
+
+// DataScopeFromContext returns the data scope of the user making a request, or nil when the
+// user is unrestricted
+func DataScopeFromContext(ctx context.Context) *DataScope {
+	scope, _ := ctx.Value(dataScopeKey{}).(*DataScope)
+	return scope
+}
+
+// cacheKeySuffix identifies the scope in analytics cache keys, so restricted users never read
+// results cached for someone who may see more
+func (d *DataScope) cacheKeySuffix() string {
+	if d == nil {
+		return ""
+	}
+	return fmt.Sprintf("_scope:%s|%s", strings.Join(d.Applications, ","), strings.Join(d.Groups, ","))
+}
+
+// condition returns the SQL condition matching the incidents the scope allows, taking each
+// placeholder from next
+func (d *DataScope) condition(next func() string) (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+	add := func(column string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		placeholders := make([]string, len(values))
+		for i, value := range values {
+			placeholders[i] = next()
+			args = append(args, value)
+		}
+		parts = append(parts, fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ",")))
+	}
+	add("application_name", d.Applications)
+	add("resolution_group", d.Groups)
+
+	if len(parts) == 0 {
+		return "1=0", nil
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args
+}
+
+// scopeConditions returns the conditions restricting a query to the data scope in ctx, with $N
+// placeholders numbered from startArgIndex
+func scopeConditions(ctx context.Context, startArgIndex int) ([]string, []interface{}, int) {
+	scope := DataScopeFromContext(ctx)
+	if scope == nil {
+		return nil, nil, startArgIndex
+	}
+
+	argIndex := startArgIndex
+	condition, args := scope.condition(func() string {
+		placeholder := fmt.Sprintf("$%d", argIndex)
+		argIndex++
+		return placeholder
+	})
+	return []string{condition}, args, argIndex
+}
+
+// scopeClause returns an " AND ..." clause restricting a query with ? placeholders to the data
+// scope in ctx, or "" for unrestricted users
+func scopeClause(ctx context.Context) (string, []interface{}) {
+	scope := DataScopeFromContext(ctx)
+	if scope == nil {
+		return "", nil
+	}
+	condition, args := scope.condition(func() string { return "?" })
+	return " AND " + condition, args
+}
+
+// DataScopeService manages the data scopes of restricted users
+type DataScopeService struct {
+	db *sql.DB
+}
+
+// NewDataScopeService creates a new DataScopeService instance
+func NewDataScopeService(db *sql.DB) *DataScopeService {
+	return &DataScopeService{db: db}
+}
+
+// ListScopes returns the scopes of every restricted user ordered by user
+func (s *DataScopeService) ListScopes(ctx context.Context) ([]DataScope, error) {
+	return s.queryScopes(ctx, "")
+}
+
+// GetScope returns a user's data scope, or nil when the user is unrestricted
+func (s *DataScopeService) GetScope(ctx context.Context, userID string) (*DataScope, error) {
+	scopes, err := s.queryScopes(ctx, userID)
+	if err != nil || len(scopes) == 0 {
+		return nil, err
+	}
+	return &scopes[0], nil
+}
+
+// SetScope replaces a user's data scope with the given applications and groups, returning
+// ErrEmptyScope when both are empty
+func (s *DataScopeService) SetScope(ctx context.Context, userID string, applications, groups []string, updatedBy string) (*DataScope, error) {
+	scope := &DataScope{
+		UserID:       userID,
+		Applications: uniqueSorted(applications),
+		Groups:       uniqueSorted(groups),
+		UpdatedBy:    updatedBy,
+		UpdatedAt:    time.Now(),
+	}
+	if len(scope.Applications) == 0 && len(scope.Groups) == 0 {
+		return nil, ErrEmptyScope
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// DuckDB cannot delete and re-insert a key in one transaction, so only the entries that are
+	// no longer allowed are deleted and the rest are replaced in place
+	query := "DELETE FROM user_scopes WHERE user_id = ?"
+	args := []interface{}{userID}
+	for _, entry := range []struct {
+		scopeType string
+		values    []string
+	}{{ScopeTypeApplication, scope.Applications}, {ScopeTypeGroup, scope.Groups}} {
+		for _, value := range entry.values {
+			query += " AND NOT (scope_type = ? AND value = ?)"
+			args = append(args, entry.scopeType, value)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to clear scope: %w", err)
+	}
+	insert := func(scopeType string, values []string) error {
+		for _, value := range values {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT OR REPLACE INTO user_scopes (user_id, scope_type, value, updated_by, updated_at)
+				VALUES (?, ?, ?, ?, ?)
+			`, userID, scopeType, value, nullIfEmpty(updatedBy), scope.UpdatedAt); err != nil {
+				return fmt.Errorf("failed to save scope: %w", err)
+			}
+		}
+		return nil
+	}
+	if err := insert(ScopeTypeApplication, scope.Applications); err != nil {
+		return nil, err
+	}
+	if err := insert(ScopeTypeGroup, scope.Groups); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit scope: %w", err)
+	}
+	return scope, nil
+}
+
+// DeleteScope lifts a user's restriction, returning sql.ErrNoRows when the user has no scope
+func (s *DataScopeService) DeleteScope(ctx context.Context, userID string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM user_scopes WHERE user_id = ?", userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete scope: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// queryScopes loads the scopes of one user, or of every user when userID is empty
+func (s *DataScopeService) queryScopes(ctx context.Context, userID string) ([]DataScope, error) {
+	query := "SELECT user_id, scope_type, value, COALESCE(updated_by, ''), updated_at FROM user_scopes"
+	var args []interface{}
+	if userID != "" {
+		query += " WHERE user_id = ?"
+		args = append(args, userID)
+	}
+	query += " ORDER BY user_id, scope_type, value"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scopes: %w", err)
+	}
+	defer rows.Close()
+
+	scopes := []DataScope{}
+	for rows.Next() {
+		var user, scopeType, value, updatedBy string
+		var updatedAt time.Time
+		if err := rows.Scan(&user, &scopeType, &value, &updatedBy, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan scope: %w", err)
+		}
+		if len(scopes) == 0 || scopes[len(scopes)-1].UserID != user {
+			scopes = append(scopes, DataScope{UserID: user, Applications: []string{}, Groups: []string{}})
+		}
+		scope := &scopes[len(scopes)-1]
+		if scopeType == ScopeTypeApplication {
+			scope.Applications = append(scope.Applications, value)
+		} else {
+			scope.Groups = append(scope.Groups, value)
+		}
+		scope.UpdatedBy = updatedBy
+		scope.UpdatedAt = updatedAt
+	}
+
+	return scopes, rows.Err()
+}
+
+// uniqueSorted trims values and returns the distinct non-empty ones in order
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := []string{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	sort.Strings(unique)
+	return unique
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestDataScopes(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	scopes := NewDataScopeService(db)
+	ctx := context.Background()
+
+	portal := diffTestIncident("i1", "upload-1", "INC001", "P1", "Open")
+	billing := diffTestIncident("i2", "upload-1", "INC002", "P2", "Open")
+	billing.ApplicationName = "Billing"
+	billing.ResolutionGroup = "Finance Apps"
+	payroll := diffTestIncident("i3", "upload-1", "INC003", "P3", "Open")
+	payroll.ApplicationName = "Payroll"
+	payroll.ResolutionGroup = "Finance Apps"
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, []models.Incident{portal, billing, payroll}, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	if _, err := scopes.SetScope(ctx, "alice", []string{" ", ""}, nil, "admin"); err != ErrEmptyScope {
+		t.Errorf("expected ErrEmptyScope, got %v", err)
+	}
+	if _, err := scopes.SetScope(ctx, "alice", []string{"Portal", "Portal"}, []string{"Finance Apps"}, "admin"); err != nil {
+		t.Fatalf("SetScope() error = %v", err)
+	}
+	// Setting a scope again replaces it
+	if _, err := scopes.SetScope(ctx, "bob", []string{"Portal", "Billing"}, nil, "admin"); err != nil {
+		t.Fatalf("SetScope() error = %v", err)
+	}
+	if _, err := scopes.SetScope(ctx, "bob", []string{"Billing"}, nil, "admin"); err != nil {
+		t.Fatalf("SetScope() error = %v", err)
+	}
+
+	listed, err := scopes.ListScopes(ctx)
+	if err != nil {
+		t.Fatalf("ListScopes() error = %v", err)
+	}
+	if len(listed) != 2 || len(listed[0].Applications) != 1 || len(listed[0].Groups) != 1 ||
+		len(listed[1].Applications) != 1 || listed[1].Applications[0] != "Billing" || listed[1].UpdatedBy != "admin" {
+		t.Fatalf("unexpected scopes %+v", listed)
+	}
+	if scope, err := scopes.GetScope(ctx, "carol"); err != nil || scope != nil {
+		t.Fatalf("expected carol to be unrestricted, got %+v, %v", scope, err)
+	}
+
+	service := NewAnalyticsService(db)
+	count := func(ctx context.Context, filters *TimelineFilters) int {
+		t.Helper()
+		priorities, err := service.GetPriorityAnalysis(ctx, filters)
+		if err != nil {
+			t.Fatalf("GetPriorityAnalysis() error = %v", err)
+		}
+		total := 0
+		for _, priority := range priorities {
+			total += priority.Count
+		}
+		return total
+	}
+
+	alice, _ := scopes.GetScope(ctx, "alice")
+	bob, _ := scopes.GetScope(ctx, "bob")
+	aliceCtx := WithDataScope(ctx, alice)
+	bobCtx := WithDataScope(ctx, bob)
+
+	if got := count(ctx, nil); got != 3 {
+		t.Errorf("expected unrestricted users to see 3 incidents, got %d", got)
+	}
+	// Applications and groups add up: Portal by application, Billing and Payroll by group
+	if got := count(aliceCtx, nil); got != 3 {
+		t.Errorf("expected alice to see 3 incidents, got %d", got)
+	}
+	if got := count(bobCtx, nil); got != 1 {
+		t.Errorf("expected bob to see 1 incident, got %d", got)
+	}
+	if got := count(bobCtx, &TimelineFilters{Applications: []string{"Portal"}}); got != 0 {
+		t.Errorf("expected filters to narrow bob's scope, got %d", got)
+	}
+
+	groups, err := service.GetGroupAnalysis(bobCtx, OrgLevelGroup, "", nil)
+	if err != nil {
+		t.Fatalf("GetGroupAnalysis() error = %v", err)
+	}
+	if len(groups) != 1 || groups[0].IncidentCount != 1 {
+		t.Errorf("expected the group roll-up to be scoped, got %+v", groups)
+	}
+
+	// Cached results are kept apart per scope
+	cached, err := NewCachedAnalyticsService(service, DefaultCacheConfig())
+	if err != nil {
+		t.Fatalf("NewCachedAnalyticsService() error = %v", err)
+	}
+	if _, err := cached.GetPriorityAnalysis(ctx, nil); err != nil {
+		t.Fatalf("GetPriorityAnalysis() error = %v", err)
+	}
+	priorities, err := cached.GetPriorityAnalysis(bobCtx, nil)
+	if err != nil {
+		t.Fatalf("GetPriorityAnalysis() error = %v", err)
+	}
+	if len(priorities) != 1 || priorities[0].Priority != "P2" {
+		t.Errorf("expected bob's cached analysis to be scoped, got %+v", priorities)
+	}
+
+	// Single incident lookups treat out-of-scope incidents as missing
+	if _, err := NewIncidentEventService(db).GetTimeline(bobCtx, "i1"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for an out-of-scope timeline, got %v", err)
+	}
+	if _, err := NewIncidentService(db).GetRelatedIncidents(bobCtx, "i1", RelatedOptions{}); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for out-of-scope related incidents, got %v", err)
+	}
+	incidents, err := NewIncidentService(db).GetIncidentsByUpload(bobCtx, "upload-1")
+	if err != nil {
+		t.Fatalf("GetIncidentsByUpload() error = %v", err)
+	}
+	if len(incidents) != 1 || incidents[0].ID != "i2" {
+		t.Errorf("expected bob to see only the Billing incident, got %d incidents", len(incidents))
+	}
+
+	if err := scopes.DeleteScope(ctx, "bob"); err != nil {
+		t.Fatalf("DeleteScope() error = %v", err)
+	}
+	if err := scopes.DeleteScope(ctx, "bob"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows deleting a missing scope, got %v", err)
+	}
+}
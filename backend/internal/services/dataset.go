@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Dataset is a named group of uploads that belong together, such as one month's regional
+// exports. Its uploads are processed together and their incidents carry the dataset ID.
+type Dataset struct {
+	ID            string         `json:"id"`
+	Name          string         `json:"name"`
+	Description   string         `json:"description,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UploadCount   int            `json:"upload_count"`
+	IncidentCount int            `json:"incident_count"`
+	UploadStatus  map[string]int `json:"upload_status"`
+}
+
+// DatasetService manages datasets and the uploads assigned to them
+type DatasetService struct {
+	db *sql.DB
+}
+
+// NewDatasetService creates a new DatasetService instance
+func NewDatasetService(db *sql.DB) *DatasetService {
+	return &DatasetService{db: db}
+}
+
+// CreateDataset creates an empty dataset
+func (s *DatasetService) CreateDataset(ctx context.Context, name, description string) (*Dataset, error) {
+	dataset := &Dataset{
+		ID:           uuid.New().String(),
+		Name:         strings.TrimSpace(name),
+		Description:  strings.TrimSpace(description),
+		CreatedAt:    time.Now(),
+		UploadStatus: map[string]int{},
+	}
+
+	query := `
+		INSERT INTO datasets (id, name, description, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, dataset.ID, dataset.Name, nullIfEmpty(dataset.Description), dataset.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to create dataset: %w", err)
+	}
+
+	return dataset, nil
+}
+
+// ListDatasets returns all datasets, newest first, with their upload and incident counts
+func (s *DatasetService) ListDatasets(ctx context.Context) ([]Dataset, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, COALESCE(description, ''), created_at
+		FROM datasets
+		ORDER BY created_at DESC, name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query datasets: %w", err)
+	}
+	defer rows.Close()
+
+	datasets := []Dataset{}
+	for rows.Next() {
+		var dataset Dataset
+		if err := rows.Scan(&dataset.ID, &dataset.Name, &dataset.Description, &dataset.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan dataset: %w", err)
+		}
+		datasets = append(datasets, dataset)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating datasets: %w", err)
+	}
+	rows.Close()
+
+	for i := range datasets {
+		if err := s.loadCounts(ctx, &datasets[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return datasets, nil
+}
+
+// GetDataset returns a dataset with its counts, or sql.ErrNoRows when it does not exist
+func (s *DatasetService) GetDataset(ctx context.Context, id string) (*Dataset, error) {
+	var dataset Dataset
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, name, COALESCE(description, ''), created_at
+		FROM datasets
+		WHERE id = ?
+	`, id).Scan(&dataset.ID, &dataset.Name, &dataset.Description, &dataset.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to query dataset: %w", err)
+	}
+
+	if err := s.loadCounts(ctx, &dataset); err != nil {
+		return nil, err
+	}
+	return &dataset, nil
+}
+
+// ListUploads returns the uploads assigned to a dataset in the order they were added,
+// which is also the order cross-file dedup treats as first to last
+func (s *DatasetService) ListUploads(ctx context.Context, datasetID string) ([]models.Upload, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, filename, original_filename, status, record_count,
+			   processed_count, error_count, created_at, processed_at,
+			   COALESCE(processing_options, ''), COALESCE(dataset_id, ''), COALESCE(ingested_sheet, ''),
+			   COALESCE(cleanup, '')
+		FROM uploads
+		WHERE dataset_id = ?
+		ORDER BY created_at, original_filename
+	`, datasetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dataset uploads: %w", err)
+	}
+	defer rows.Close()
+
+	uploads := []models.Upload{}
+	for rows.Next() {
+		var upload models.Upload
+		var optionsJSON, sheetJSON, cleanupJSON string
+		if err := rows.Scan(&upload.ID, &upload.Filename, &upload.OriginalFilename, &upload.Status,
+			&upload.RecordCount, &upload.ProcessedCount, &upload.ErrorCount, &upload.CreatedAt,
+			&upload.ProcessedAt, &optionsJSON, &upload.DatasetID, &sheetJSON, &cleanupJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan dataset upload: %w", err)
+		}
+		upload.Errors = []string{}
+		upload.ProcessingOptions = DecodeProcessingOptions(optionsJSON)
+		upload.IngestedSheet = DecodeIngestedSheet(sheetJSON)
+		upload.Cleanup = DecodeUploadCleanup(cleanupJSON)
+		uploads = append(uploads, upload)
+	}
+
+	return uploads, rows.Err()
+}
+
+// loadCounts fills in the upload and incident counts of a dataset
+func (s *DatasetService) loadCounts(ctx context.Context, dataset *Dataset) error {
+	dataset.UploadStatus = map[string]int{}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT status, COUNT(*)
+		FROM uploads
+		WHERE dataset_id = ?
+		GROUP BY status
+	`, dataset.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count dataset uploads: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return fmt.Errorf("failed to scan dataset upload count: %w", err)
+		}
+		dataset.UploadStatus[status] = count
+		dataset.UploadCount += count
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating dataset upload counts: %w", err)
+	}
+
+	err = s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM incidents WHERE dataset_id = ?", dataset.ID).
+		Scan(&dataset.IncidentCount)
+	if err != nil {
+		return fmt.Errorf("failed to count dataset incidents: %w", err)
+	}
+	return nil
+}
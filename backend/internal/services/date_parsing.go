@@ -0,0 +1,371 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Orders of day and month in numeric dates such as 02/03/2024
+const (
+	DateOrderDayFirst   = "day_first"
+	DateOrderMonthFirst = "month_first"
+)
+
+// dateFields are the incident fields read as dates, the only fields a date format can be set for
+var dateFields = []string{"report_date", "resolve_date"}
+
+// ErrInvalidDateFormat is returned for a date format override that cannot read a full date
+var ErrInvalidDateFormat = errors.New("date format must contain YYYY or YY, MM or MMM, and DD")
+
+// excelEpoch is day zero of Excel serial dates in the 1900 date system
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// Bounds of the numbers read as Excel serial dates: 1927-05-18 up to 9999-12-31. Smaller numbers
+// are more likely years or IDs than dates.
+const (
+	minExcelSerial = 10000
+	maxExcelSerial = 2958465
+)
+
+// maxRejectedSamples is how many values that matched no date format are kept as examples
+const maxRejectedSamples = 5
+
+// timePattern matches an optional time of day after a numeric date
+const timePattern = `(?:[ T](\d{1,2}):(\d{2})(?::(\d{2})(?:\.\d+)?)?\s*([AaPp][Mm])?)?`
+
+var (
+	dayMonthPattern  = regexp.MustCompile(`^(\d{1,2})([/.-])(\d{1,2})([/.-])(\d{4}|\d{2})` + timePattern + `$`)
+	yearFirstPattern = regexp.MustCompile(`^(\d{4})([/.-])(\d{1,2})([/.-])(\d{1,2})` + timePattern + `$`)
+	excelSerialRegex = regexp.MustCompile(`^\d+(\.\d+)?$`)
+)
+
+// namedDateLayouts are the textual date formats tried after the numeric ones, by format name
+var namedDateLayouts = []struct {
+	name   string
+	layout string
+}{
+	{"RFC3339", time.RFC3339},
+	{"RFC822", time.RFC822},
+	{"YYYYMMDD", "20060102"},
+	{"DD-MMM-YYYY", "2-Jan-2006"},
+	{"DD-MMM-YYYY HH:mm", "2-Jan-2006 15:04"},
+	{"DD MMM YYYY", "2 Jan 2006"},
+	{"DD MMM YYYY HH:mm", "2 Jan 2006 15:04"},
+	{"DD MMMM YYYY", "2 January 2006"},
+	{"MMM DD, YYYY", "Jan 2, 2006"},
+	{"MMMM DD, YYYY", "January 2, 2006"},
+}
+
+// dateFormatTokens converts the tokens of a date format override to a Go time layout. Longer
+// tokens come first so MMM is not read as MM. Days, months and hours also accept one digit.
+var dateFormatTokens = strings.NewReplacer(
+	"YYYY", "2006", "YY", "06",
+	"MMM", "Jan", "MM", "1",
+	"DD", "2",
+	"HH", "15", "mm", "04", "ss", "05",
+)
+
+// DateFormatLayout converts a date format override such as DD/MM/YYYY HH:mm to a Go time layout
+func DateFormatLayout(format string) (string, error) {
+	format = strings.TrimSpace(format)
+	for _, token := range []string{"YY", "MM", "DD"} {
+		if !strings.Contains(format, token) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidDateFormat, format)
+		}
+	}
+	return dateFormatTokens.Replace(format), nil
+}
+
+// IsDateField reports whether field is an incident field read as a date
+func IsDateField(field string) bool {
+	for _, dateField := range dateFields {
+		if field == dateField {
+			return true
+		}
+	}
+	return false
+}
+
+// DateColumnStats reports how the values of one date column were read. Values that match no
+// date format are rejected and leave the field empty.
+type DateColumnStats struct {
+	// Format is the format override of the mapping profile; empty when formats were detected
+	Format string `json:"format,omitempty"`
+	// Order is how numeric day-month dates were read when detected
+	Order     string `json:"order,omitempty"`
+	Parsed    int    `json:"parsed"`
+	Rejected  int    `json:"rejected"`
+	Ambiguous int    `json:"ambiguous"` // values valid as both DD/MM and MM/DD
+	// Formats counts the parsed values per format, such as DD/MM/YYYY or excel_serial
+	Formats         map[string]int `json:"formats"`
+	RejectedSamples []string       `json:"rejected_samples,omitempty"`
+	Warnings        []string       `json:"warnings,omitempty"`
+}
+
+// dateColumn reads the values of one date column consistently and counts how they were read.
+// It is shared by the parse workers.
+type dateColumn struct {
+	field    string
+	location *time.Location
+	layout   string // Go layout of the format override; empty detects formats
+	dayFirst bool
+	detected bool // some value could only be read one way, deciding the order
+	mixed    bool // the column holds both unambiguous DD/MM and MM/DD dates
+
+	mu    sync.Mutex
+	stats DateColumnStats
+}
+
+// newDateColumn creates the reader of a date column. format is a mapping profile override; when
+// empty the day-month order is detected from values, defaulting to month first.
+func newDateColumn(field, format string, location *time.Location, values []string) (*dateColumn, error) {
+	if location == nil {
+		location = time.UTC
+	}
+	column := &dateColumn{
+		field:    field,
+		location: location,
+		stats:    DateColumnStats{Formats: map[string]int{}},
+	}
+
+	if format != "" {
+		layout, err := DateFormatLayout(format)
+		if err != nil {
+			return nil, err
+		}
+		column.layout = layout
+		column.stats.Format = format
+		return column, nil
+	}
+
+	dayFirst, monthFirst := 0, 0
+	for _, value := range values {
+		match := dayMonthPattern.FindStringSubmatch(strings.TrimSpace(value))
+		if match == nil {
+			continue
+		}
+		first, _ := strconv.Atoi(match[1])
+		second, _ := strconv.Atoi(match[3])
+		switch {
+		case first > 12 && second <= 12:
+			dayFirst++
+		case second > 12 && first <= 12:
+			monthFirst++
+		}
+	}
+	column.dayFirst = dayFirst > monthFirst
+	column.detected = dayFirst > 0 || monthFirst > 0
+	column.mixed = dayFirst > 0 && monthFirst > 0
+	column.stats.Order = DateOrderMonthFirst
+	if column.dayFirst {
+		column.stats.Order = DateOrderDayFirst
+	}
+	return column, nil
+}
+
+// parse reads a date value as its wall-clock time in the column's location, expressed as UTC
+// like every other stored timestamp. Empty values are skipped without being counted.
+func (c *dateColumn) parse(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	parsed, format, ambiguous, ok := c.read(value)
+
+	c.mu.Lock()
+	if ok {
+		c.stats.Parsed++
+		c.stats.Formats[format]++
+		if ambiguous {
+			c.stats.Ambiguous++
+		}
+	} else {
+		c.stats.Rejected++
+		if len(c.stats.RejectedSamples) < maxRejectedSamples {
+			c.stats.RejectedSamples = append(c.stats.RejectedSamples, value)
+		}
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		return time.Time{}, false
+	}
+	return inLocation(parsed, c.location), true
+}
+
+// read parses a trimmed value, returning the name of the format it matched and whether it could
+// also have been read with day and month swapped
+func (c *dateColumn) read(value string) (time.Time, string, bool, bool) {
+	if c.layout != "" {
+		if parsed, err := time.Parse(c.layout, value); err == nil {
+			return parsed, c.stats.Format, false, true
+		}
+		// Cells formatted as numbers keep their serial date whatever the expected format
+		if parsed, ok := parseExcelSerial(value); ok {
+			return parsed, "excel_serial", false, true
+		}
+		return time.Time{}, "", false, false
+	}
+
+	if parsed, ok := parseExcelSerial(value); ok {
+		return parsed, "excel_serial", false, true
+	}
+
+	if match := yearFirstPattern.FindStringSubmatch(value); match != nil && match[2] == match[4] {
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[3])
+		day, _ := strconv.Atoi(match[5])
+		if parsed, ok := numericDate(year, month, day, match[6:]); ok {
+			return parsed, numericFormatName([]string{"YYYY", "MM", "DD"}, match[2], match[6:]), false, true
+		}
+		return time.Time{}, "", false, false
+	}
+
+	if match := dayMonthPattern.FindStringSubmatch(value); match != nil && match[2] == match[4] {
+		first, _ := strconv.Atoi(match[1])
+		second, _ := strconv.Atoi(match[3])
+		year, _ := strconv.Atoi(match[5])
+		yearToken := "YYYY"
+		if len(match[5]) == 2 {
+			yearToken = "YY"
+			year += 2000
+			if year > 2068 {
+				year -= 100
+			}
+		}
+
+		day, month := second, first
+		if c.dayFirst {
+			day, month = first, second
+		}
+		// A value only valid the other way round is read that way, as it cannot be meant otherwise
+		if parsed, ok := numericDate(year, month, day, match[6:]); ok {
+			return parsed, dayMonthFormatName(c.dayFirst, yearToken, match[2], match[6:]),
+				first != second && first <= 12 && second <= 12, true
+		}
+		if parsed, ok := numericDate(year, day, month, match[6:]); ok {
+			return parsed, dayMonthFormatName(!c.dayFirst, yearToken, match[2], match[6:]), false, true
+		}
+		return time.Time{}, "", false, false
+	}
+
+	for _, named := range namedDateLayouts {
+		if parsed, err := time.Parse(named.layout, value); err == nil {
+			return parsed, named.name, false, true
+		}
+	}
+	return time.Time{}, "", false, false
+}
+
+// finish returns the column's statistics with warnings about ambiguous and rejected values
+func (c *dateColumn) finish() *DateColumnStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+
+	readAs := "MM/DD"
+	if c.dayFirst {
+		readAs = "DD/MM"
+	}
+	if c.mixed {
+		stats.Warnings = append(stats.Warnings, fmt.Sprintf(
+			"%s mixes DD/MM and MM/DD dates; values valid both ways were read as %s", c.field, readAs))
+	}
+	if stats.Ambiguous > 0 && !c.detected {
+		stats.Warnings = append(stats.Warnings, fmt.Sprintf(
+			"%d %s values could be DD/MM or MM/DD and nothing in the column tells them apart; they were read as %s. "+
+				"Set a date format for %s in the mapping profile if that is wrong", stats.Ambiguous, c.field, readAs, c.field))
+	}
+	if stats.Rejected > 0 {
+		stats.Warnings = append(stats.Warnings, fmt.Sprintf(
+			"%d %s values matched no date format and were left empty", stats.Rejected, c.field))
+	}
+	return &stats
+}
+
+// numericDate builds a date and optional time from regex groups, rejecting out of range values
+// such as 31 February rather than letting them roll over
+func numericDate(year, month, day int, clock []string) (time.Time, bool) {
+	hour, minute, second := 0, 0, 0
+	if clock[0] != "" {
+		hour, _ = strconv.Atoi(clock[0])
+		minute, _ = strconv.Atoi(clock[1])
+		second, _ = strconv.Atoi(clock[2])
+		switch strings.ToLower(clock[3]) {
+		case "am":
+			if hour == 12 {
+				hour = 0
+			} else if hour > 12 {
+				return time.Time{}, false
+			}
+		case "pm":
+			if hour < 12 {
+				hour += 12
+			} else if hour > 12 {
+				return time.Time{}, false
+			}
+		}
+	}
+	if month < 1 || month > 12 || hour > 23 || minute > 59 || second > 59 {
+		return time.Time{}, false
+	}
+	parsed := time.Date(year, time.Month(month), day, hour, minute, second, 0, time.UTC)
+	if parsed.Day() != day || parsed.Month() != time.Month(month) {
+		return time.Time{}, false
+	}
+	return parsed, true
+}
+
+// parseExcelSerial reads an Excel serial date, the days since excelEpoch with the time of day as
+// the fraction
+func parseExcelSerial(value string) (time.Time, bool) {
+	if !excelSerialRegex.MatchString(value) {
+		return time.Time{}, false
+	}
+	serial, err := strconv.ParseFloat(value, 64)
+	if err != nil || serial < minExcelSerial || serial > maxExcelSerial {
+		return time.Time{}, false
+	}
+	return excelEpoch.Add(time.Duration(serial * float64(24*time.Hour))).Round(time.Second), true
+}
+
+// dayMonthFormatName names a numeric day-month format, such as DD/MM/YYYY HH:mm
+func dayMonthFormatName(dayFirst bool, yearToken, separator string, clock []string) string {
+	if dayFirst {
+		return numericFormatName([]string{"DD", "MM", yearToken}, separator, clock)
+	}
+	return numericFormatName([]string{"MM", "DD", yearToken}, separator, clock)
+}
+
+// numericFormatName joins date tokens with their separator and names the time of day, if any
+func numericFormatName(tokens []string, separator string, clock []string) string {
+	name := strings.Join(tokens, separator)
+	switch {
+	case clock[0] == "":
+	case clock[2] == "":
+		name += " HH:mm"
+	default:
+		name += " HH:mm:ss"
+	}
+	if clock[0] != "" && clock[3] != "" {
+		name += " AM/PM"
+	}
+	return name
+}
+
+// inLocation returns the wall-clock time of t in location, expressed as UTC, so the stored day
+// is the day in that time zone
+func inLocation(t time.Time, location *time.Location) time.Time {
+	if location == time.UTC {
+		return t
+	}
+	local := t.In(location)
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(),
+		local.Second(), local.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDateColumn_DetectsFormats(t *testing.T) {
+	testCases := []struct {
+		name      string
+		format    string
+		values    []string
+		value     string
+		expected  time.Time
+		formatKey string
+	}{
+		{"day first decided by another value", "", []string{"02/03/2024", "25/03/2024"}, "02/03/2024",
+			time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), "DD/MM/YYYY"},
+		{"month first decided by another value", "", []string{"02/03/2024", "03/25/2024"}, "02/03/2024",
+			time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC), "MM/DD/YYYY"},
+		{"ISO date with minutes", "", nil, "2024-03-02 14:05",
+			time.Date(2024, 3, 2, 14, 5, 0, 0, time.UTC), "YYYY-MM-DD HH:mm"},
+		{"Excel serial with time", "", nil, "45353.5",
+			time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC), "excel_serial"},
+		{"two-digit year with afternoon time", "", nil, "3-2-24 2:05 PM",
+			time.Date(2024, 3, 2, 14, 5, 0, 0, time.UTC), "MM-DD-YY HH:mm AM/PM"},
+		{"month name", "", nil, "2 Mar 2024",
+			time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), "DD MMM YYYY"},
+		{"profile format", "DD.MM.YYYY HH:mm", nil, "2.3.2024 14:05",
+			time.Date(2024, 3, 2, 14, 5, 0, 0, time.UTC), "DD.MM.YYYY HH:mm"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			column, err := newDateColumn("report_date", tc.format, nil, tc.values)
+			if err != nil {
+				t.Fatalf("Failed to create date column: %v", err)
+			}
+			parsed, ok := column.parse(tc.value)
+			if !ok {
+				t.Fatalf("Expected %q to parse", tc.value)
+			}
+			if !parsed.Equal(tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, parsed)
+			}
+			if stats := column.finish(); stats.Formats[tc.formatKey] != 1 {
+				t.Errorf("Expected the value counted as %s, got %v", tc.formatKey, stats.Formats)
+			}
+		})
+	}
+}
+
+func TestDateColumn_ReportsAmbiguousAndRejectedValues(t *testing.T) {
+	values := []string{"02/03/2024", "04/05/2024", "31/02/2024", "soon", ""}
+	column, err := newDateColumn("resolve_date", "", nil, values)
+	if err != nil {
+		t.Fatalf("Failed to create date column: %v", err)
+	}
+	for _, value := range values {
+		column.parse(value)
+	}
+
+	stats := column.finish()
+	// 31/02 is only plausible day first, which makes the column day first, but it is no real date
+	if stats.Order != DateOrderDayFirst || stats.Parsed != 2 || stats.Ambiguous != 2 || stats.Rejected != 2 {
+		t.Errorf("Expected day first with 2 parsed, 2 ambiguous and 2 rejected values, got %+v", stats)
+	}
+	if len(stats.RejectedSamples) != 2 || stats.RejectedSamples[1] != "soon" {
+		t.Errorf("Expected the rejected values as samples, got %v", stats.RejectedSamples)
+	}
+	if len(stats.Warnings) != 1 || !strings.Contains(stats.Warnings[0], "2 resolve_date values matched no date format") {
+		t.Errorf("Expected only a rejection warning once the order is decided, got %v", stats.Warnings)
+	}
+
+	undecided, err := newDateColumn("report_date", "", nil, []string{"02/03/2024"})
+	if err != nil {
+		t.Fatalf("Failed to create date column: %v", err)
+	}
+	undecided.parse("02/03/2024")
+	if warnings := undecided.finish().Warnings; len(warnings) != 1 || !strings.Contains(warnings[0], "could be DD/MM or MM/DD") {
+		t.Errorf("Expected an ambiguity warning, got %v", warnings)
+	}
+}
+
+func TestDateFormatLayout(t *testing.T) {
+	if layout, err := DateFormatLayout("DD-MMM-YYYY HH:mm:ss"); err != nil || layout != "2-Jan-2006 15:04:05" {
+		t.Errorf("Expected layout 2-Jan-2006 15:04:05, got %q (%v)", layout, err)
+	}
+	if _, err := DateFormatLayout("MM/YYYY"); !errors.Is(err, ErrInvalidDateFormat) {
+		t.Errorf("Expected a format without a day to be rejected, got %v", err)
+	}
+}
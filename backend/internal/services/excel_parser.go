@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"incident-management-system/internal/models"
@@ -16,6 +17,7 @@ import (
 
 // ExcelParser handles parsing of Excel files with concurrent processing
 type ExcelParser struct {
+	mu         sync.RWMutex // Guards maxWorkers, which can change while files are parsed
 	maxWorkers int
 	batchSize  int
 }
@@ -54,8 +56,97 @@ func NewExcelParser(config *ExcelParserConfig) *ExcelParser {
 	}
 }
 
-// ParseFile parses an Excel file and returns incidents with concurrent processing
+// SetMaxWorkers changes the number of concurrent row workers for files parsed from now on
+func (p *ExcelParser) SetMaxWorkers(workers int) {
+	if workers <= 0 {
+		workers = 1
+	}
+	p.mu.Lock()
+	p.maxWorkers = workers
+	p.mu.Unlock()
+}
+
+// ParseOptions adjusts how a spreadsheet is read
+type ParseOptions struct {
+	// Columns maps incident fields to extra header names, matched before the built-in names
+	Columns map[string][]string
+	// Location is the time zone dates are converted to before they are stored. Dates without
+	// an explicit offset are read as UTC; nil leaves them unchanged.
+	Location *time.Location
+	// Rules rejects parsed rows that break a validation rule set; nil applies none
+	Rules *RuleEngine
+	// Sheet is the worksheet to read. Empty picks the visible sheet whose header maps to the
+	// most incident fields.
+	Sheet string
+	// DateFormats sets the format of date fields, such as DD/MM/YYYY, instead of detecting it
+	DateFormats map[string]string
+	// PendingStatuses are the statuses whose time a status_history column excludes from the
+	// resolution clock; empty uses DefaultPendingStatuses
+	PendingStatuses []string
+}
+
+// ParseResult holds the incidents parsed from a spreadsheet and the rows that could not be parsed
+type ParseResult struct {
+	Incidents []models.Incident
+	TotalRows int
+	Errors    []models.ValidationError
+	// FailedRows counts the rows behind Errors; one row can break several validation rules
+	FailedRows int
+	// ValidationTime is the time spent checking rows against the rule set, summed across the
+	// parse workers
+	ValidationTime time.Duration
+	// Sheet records the sheet and cell range the incidents were read from
+	Sheet *models.IngestedSheet
+	// Dates reports how each mapped date column was read
+	Dates map[string]*DateColumnStats
+}
+
+// defaultColumnMappings lists the normalized header names recognized for each incident field
+var defaultColumnMappings = map[string][]string{
+	"incident_id":         {"incidentid", "incidentid", "id", "ticketid", "ticketid"},
+	"application_name":    {"applicationname", "applicationname", "app", "application"},
+	"report_date":         {"reportdate", "reportdate", "date", "createddate", "createddate"},
+	"priority":            {"priority", "prio", "severity"},
+	"status":              {"status", "state"},
+	"resolved_person":     {"resolvedperson", "resolver", "resolvedby", "resolvedby"},
+	"resolve_date":        {"resolvedate", "resolvedate", "resolveddate", "resolveddate"},
+	"brief_description":   {"briefdescription", "description", "desc", "summary"},
+	"resolution_group":    {"resolutiongroup", "assignee", "assignedto", "assignedto"},
+	"it_process_group":    {"itprocessgroup", "itprocessgroup", "processgroup", "processgroup"},
+	"automation_feasible": {"automationfeasible", "automationfeasible", "automatable"},
+	"automation_score":    {"automationscore", "automationscore"},
+	"sentiment_label":     {"sentimentlabel", "sentimentlabel", "sentiment"},
+	"sentiment_score":     {"sentimentscore", "sentimentscore"},
+	"closure_code":        {"closurecode", "closurecode", "closecode", "closecode"},
+	"cost":                {"cost", "incidentcost", "costperincident", "ticketcost", "handlingcost"},
+	"pending_hours":       {"pendinghours", "pendingtime", "pendingduration", "onholdhours", "pausedhours"},
+	"status_history":      {"statushistory", "statuslog", "statechanges"},
+	"source":              {"source", "monitoringsource", "alertsource", "eventsource", "contacttype", "reportedvia", "channel"},
+	"region":              {"region", "country", "geography", "geo", "location", "site"},
+}
+
+// IsMappableField reports whether field is an incident field that spreadsheet columns can map to
+func IsMappableField(field string) bool {
+	_, ok := defaultColumnMappings[field]
+	return ok
+}
+
+// ParseFile parses an Excel file and returns incidents with concurrent processing.
+// Any row that cannot be parsed fails the whole file.
 func (p *ExcelParser) ParseFile(ctx context.Context, filePath string) ([]models.Incident, error) {
+	result, err := p.ParseFileWithOptions(ctx, filePath, ParseOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("failed to process rows: %w", result.Errors[0])
+	}
+	return result.Incidents, nil
+}
+
+// ParseFileWithOptions parses an Excel file, collecting the rows that cannot be parsed
+// instead of failing, so the caller can decide how many failures to tolerate
+func (p *ExcelParser) ParseFileWithOptions(ctx context.Context, filePath string, options ParseOptions) (*ParseResult, error) {
 	// Open Excel file
 	f, err := excelize.OpenFile(filePath)
 	if err != nil {
@@ -63,60 +154,106 @@ func (p *ExcelParser) ParseFile(ctx context.Context, filePath string) ([]models.
 	}
 	defer f.Close()
 
-	// Get all rows from the first sheet
-	rows, err := f.GetRows("Sheet1")
+	// Find the data sheet and its header row, skipping title rows and hidden sheets
+	sheet, err := p.selectWorksheet(f, options)
 	if err != nil {
-		// Try to get rows from the first available sheet
-		sheets := f.GetSheetList()
-		if len(sheets) == 0 {
-			return nil, fmt.Errorf("no sheets found in Excel file")
-		}
-		rows, err = f.GetRows(sheets[0])
-		if err != nil {
-			return nil, fmt.Errorf("failed to read rows from sheet: %w", err)
-		}
+		return nil, err
 	}
 
 	// Check if we have data
-	if len(rows) <= 1 {
-		return []models.Incident{}, nil
+	if len(sheet.rows) <= sheet.dataStart {
+		return &ParseResult{Incidents: []models.Incident{}, Sheet: sheet.ingested(f.GetSheetList())}, nil
 	}
 
 	// Parse header row to get column indices
-	header := rows[0]
-	columnIndices := p.parseHeader(header)
+	header := sheet.rows[sheet.header]
+	columnIndices := p.parseHeaderWithColumns(header, options.Columns)
+	formulas := sheet.evaluateFormulas(f, columnIndices)
+	ingested := sheet.ingested(f.GetSheetList())
+	ingested.FormulasEvaluated = formulas
+	ingested.Columns = make(map[string]string, len(columnIndices))
+	for field, index := range columnIndices {
+		ingested.Columns[field] = strings.TrimSpace(header[index])
+	}
+	ingested.DuplicateColumns = duplicateColumns(header, columnIndices)
+
+	// Repeated headers, duplicated rows and trailing summaries are recorded rather than parsed
+	kept, skipped := sheet.incidentRows(columnIndices)
+	ingested.SkippedRows = skipped
+	dataRows := make([][]string, len(kept))
+	rowNumbers := make([]int, len(kept))
+	for i, index := range kept {
+		dataRows[i] = sheet.rows[index]
+		rowNumbers[i] = index + 1
+	}
+
+	// Date columns detect their format from all their values before any row is parsed
+	dates := make(map[string]*dateColumn)
+	for _, field := range dateFields {
+		index, mapped := columnIndices[field]
+		if !mapped {
+			continue
+		}
+		values := make([]string, 0, len(dataRows))
+		for _, row := range dataRows {
+			if index < len(row) {
+				values = append(values, row[index])
+			}
+		}
+		column, err := newDateColumn(field, options.DateFormats[field], options.Location, values)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date format for %s: %w", field, err)
+		}
+		dates[field] = column
+	}
+	var history *statusHistory
+	if _, mapped := columnIndices["status_history"]; mapped {
+		history = newStatusHistory(options.PendingStatuses, dates["report_date"], options.Location)
+	}
 
 	// Process data rows concurrently
-	dataRows := rows[1:]
-	incidents, err := p.processRowsConcurrently(ctx, dataRows, columnIndices)
-	if err != nil {
-		return nil, fmt.Errorf("failed to process rows: %w", err)
+	var validationNanos int64
+	incidents, rowErrors, failedRows := p.processRowsConcurrently(ctx, dataRows, rowNumbers, columnIndices, dates, history, options.Rules, &validationNanos)
+
+	dateStats := make(map[string]*DateColumnStats, len(dates))
+	for field, column := range dates {
+		dateStats[field] = column.finish()
 	}
 
-	return incidents, nil
+	return &ParseResult{
+		Incidents:      incidents,
+		TotalRows:      len(dataRows),
+		Errors:         rowErrors,
+		FailedRows:     failedRows,
+		ValidationTime: time.Duration(atomic.LoadInt64(&validationNanos)),
+		Sheet:          ingested,
+		Dates:          dateStats,
+	}, nil
 }
 
 // parseHeader maps column names to indices
 func (p *ExcelParser) parseHeader(header []string) map[string]int {
+	return p.parseHeaderWithColumns(header, nil)
+}
+
+// parseHeaderWithColumns maps column names to indices, giving the extra header names in
+// columns precedence over the built-in ones
+func (p *ExcelParser) parseHeaderWithColumns(header []string, columns map[string][]string) map[string]int {
 	indices := make(map[string]int)
+	claimed := make(map[string]bool)
 
-	// Define expected column names and their mappings
-	columnMappings := map[string][]string{
-		"incident_id":         {"incidentid", "incidentid", "id", "ticketid", "ticketid"},
-		"application_name":    {"applicationname", "applicationname", "app", "application"},
-		"report_date":         {"reportdate", "reportdate", "date", "createddate", "createddate"},
-		"priority":            {"priority", "prio", "severity"},
-		"status":              {"status", "state"},
-		"resolved_person":     {"resolvedperson", "resolver", "resolvedby", "resolvedby"},
-		"resolve_date":        {"resolvedate", "resolvedate", "resolveddate", "resolveddate"},
-		"brief_description":   {"briefdescription", "description", "desc", "summary"},
-		"resolution_group":    {"resolutiongroup", "assignee", "assignedto", "assignedto"},
-		"it_process_group":    {"itprocessgroup", "itprocessgroup", "processgroup", "processgroup"},
-		"automation_feasible": {"automationfeasible", "automationfeasible", "automatable"},
-		"automation_score":    {"automationscore", "automationscore"},
-		"sentiment_label":     {"sentimentlabel", "sentimentlabel", "sentiment"},
-		"sentiment_score":     {"sentimentscore", "sentimentscore"},
-		"closure_code":        {"closurecode", "closurecode", "closecode", "closecode"},
+	// Extra names are matched first so a profile can repoint a field the built-in names also match
+	for i, columnName := range header {
+		normalized := normalizeColumnName(columnName)
+		for field, possibleNames := range columns {
+			for _, possibleName := range possibleNames {
+				if normalized == normalizeColumnName(possibleName) {
+					indices[field] = i
+					claimed[field] = true
+					break
+				}
+			}
+		}
 	}
 
 	// Map header columns to expected fields
@@ -125,7 +262,10 @@ func (p *ExcelParser) parseHeader(header []string) map[string]int {
 		normalized := normalizeColumnName(columnName)
 
 		// Find matching field
-		for field, possibleNames := range columnMappings {
+		for field, possibleNames := range defaultColumnMappings {
+			if claimed[field] {
+				continue
+			}
 			for _, possibleName := range possibleNames {
 				if normalized == possibleName {
 					indices[field] = i
@@ -150,8 +290,11 @@ func normalizeColumnName(name string) string {
 	return strings.ToLower(result)
 }
 
-// processRowsConcurrently processes rows using concurrent workers
-func (p *ExcelParser) processRowsConcurrently(ctx context.Context, rows [][]string, columnIndices map[string]int) ([]models.Incident, error) {
+// processRowsConcurrently processes rows using concurrent workers, returning the parsed incidents
+// in sheet order, an error for every row that could not be parsed or broke one of rules, and the
+// number of rows that failed. rowNumbers holds the 1-based sheet row of each row, used to number
+// row errors. Time spent on rule checks is added to validationNanos when set.
+func (p *ExcelParser) processRowsConcurrently(ctx context.Context, rows [][]string, rowNumbers []int, columnIndices map[string]int, dates map[string]*dateColumn, history *statusHistory, rules *RuleEngine, validationNanos *int64) ([]models.Incident, []models.ValidationError, int) {
 	// Create channels for work distribution and results collection
 	type workItem struct {
 		index int
@@ -167,7 +310,9 @@ func (p *ExcelParser) processRowsConcurrently(ctx context.Context, rows [][]stri
 
 	// Create worker pool
 	var wg sync.WaitGroup
+	p.mu.RLock()
 	workerCount := p.maxWorkers
+	p.mu.RUnlock()
 	if workerCount > len(rows) {
 		workerCount = len(rows)
 	}
@@ -185,7 +330,14 @@ func (p *ExcelParser) processRowsConcurrently(ctx context.Context, rows [][]stri
 					}
 
 					// Process the row
-					incident, err := p.parseRow(work.row, columnIndices)
+					incident, err := p.parseRow(work.row, columnIndices, dates, history)
+					if err == nil && rules != nil {
+						checkStart := time.Now()
+						err = rules.Check(&incident)
+						if validationNanos != nil {
+							atomic.AddInt64(validationNanos, int64(time.Since(checkStart)))
+						}
+					}
 					resultsChan <- struct {
 						index    int
 						incident models.Incident
@@ -219,35 +371,48 @@ func (p *ExcelParser) processRowsConcurrently(ctx context.Context, rows [][]stri
 
 	// Collect results
 	incidents := make([]models.Incident, len(rows))
-	var firstError error
+	failed := make(map[int]error)
 
 	for result := range resultsChan {
 		if result.err != nil {
-			if firstError == nil {
-				firstError = result.err
-			}
+			failed[result.index] = result.err
 			continue
 		}
 		incidents[result.index] = result.incident
 	}
 
-	// Filter out zero-value incidents (failed parses)
+	// Filter out zero-value incidents (failed parses) and report failures in row order
 	filtered := make([]models.Incident, 0, len(incidents))
-	for _, incident := range incidents {
+	var rowErrors []models.ValidationError
+	for i, incident := range incidents {
+		if err, ok := failed[i]; ok {
+			if violations, isRuleViolation := err.(models.ValidationErrors); isRuleViolation {
+				for _, rowError := range violations {
+					rowError.Row = rowNumbers[i]
+					rowErrors = append(rowErrors, rowError)
+				}
+				continue
+			}
+			rowError, isValidation := err.(models.ValidationError)
+			if !isValidation {
+				rowError = models.ValidationError{Message: err.Error()}
+			}
+			rowError.Row = rowNumbers[i]
+			rowErrors = append(rowErrors, rowError)
+			continue
+		}
 		if incident.IncidentID != "" {
 			filtered = append(filtered, incident)
 		}
 	}
 
-	if firstError != nil {
-		return filtered, firstError
-	}
-
-	return filtered, nil
+	return filtered, rowErrors, len(failed)
 }
 
-// parseRow parses a single row into an Incident model
-func (p *ExcelParser) parseRow(row []string, columnIndices map[string]int) (models.Incident, error) {
+// parseRow parses a single row into an Incident model, reading date fields with their columns'
+// detected or configured formats. Pending time comes from a pending_hours column, or else is
+// totalled from the status history when history is set.
+func (p *ExcelParser) parseRow(row []string, columnIndices map[string]int, dates map[string]*dateColumn, history *statusHistory) (models.Incident, error) {
 	incident := models.Incident{}
 	incident.SetDefaults()
 
@@ -262,7 +427,7 @@ func (p *ExcelParser) parseRow(row []string, columnIndices map[string]int) (mode
 	// Parse required fields
 	incident.IncidentID = getCellValue("incident_id")
 	if incident.IncidentID == "" {
-		return models.Incident{}, fmt.Errorf("missing required field: incident_id")
+		return models.Incident{}, models.ValidationError{Field: "incident_id", Message: "missing required field"}
 	}
 
 	incident.ApplicationName = getCellValue("application_name")
@@ -273,16 +438,19 @@ func (p *ExcelParser) parseRow(row []string, columnIndices map[string]int) (mode
 	incident.Status = getCellValue("status")
 	incident.ITProcessGroup = getCellValue("it_process_group")
 	incident.SentimentLabel = getCellValue("sentiment_label")
+	incident.Source = strings.TrimSpace(getCellValue("source"))
+	incident.ClosureCode = strings.TrimSpace(getCellValue("closure_code"))
+	incident.Region = strings.TrimSpace(getCellValue("region"))
 
 	// Parse date fields
-	if dateStr := getCellValue("report_date"); dateStr != "" {
-		if parsedDate, err := parseDate(dateStr); err == nil {
+	if column := dates["report_date"]; column != nil {
+		if parsedDate, ok := column.parse(getCellValue("report_date")); ok {
 			incident.ReportDate = parsedDate
 		}
 	}
 
-	if dateStr := getCellValue("resolve_date"); dateStr != "" {
-		if parsedDate, err := parseDate(dateStr); err == nil {
+	if column := dates["resolve_date"]; column != nil {
+		if parsedDate, ok := column.parse(getCellValue("resolve_date")); ok {
 			incident.ResolveDate = &parsedDate
 		}
 	}
@@ -294,6 +462,22 @@ func (p *ExcelParser) parseRow(row []string, columnIndices map[string]int) (mode
 		}
 	}
 
+	if costStr := getCellValue("cost"); costStr != "" {
+		if cost, ok := parseAmount(costStr); ok {
+			incident.Cost = &cost
+		}
+	}
+
+	if pendingStr := getCellValue("pending_hours"); pendingStr != "" {
+		if pending, ok := parseAmount(pendingStr); ok {
+			incident.PendingHours = &pending
+		}
+	} else if historyStr := getCellValue("status_history"); history != nil && historyStr != "" && !incident.ReportDate.IsZero() {
+		if pending, ok := history.pendingHours(historyStr, incident.ReportDate, incident.ResolveDate); ok {
+			incident.PendingHours = &pending
+		}
+	}
+
 	if scoreStr := getCellValue("sentiment_score"); scoreStr != "" {
 		if score, err := strconv.ParseFloat(scoreStr, 64); err == nil {
 			incident.SentimentScore = &score
@@ -309,27 +493,38 @@ func (p *ExcelParser) parseRow(row []string, columnIndices map[string]int) (mode
 	return incident, nil
 }
 
-// parseDate attempts to parse a date string in various formats
+// parseDate parses a single date string, detecting its format
 func parseDate(dateStr string) (time.Time, error) {
-	// Try common date formats
-	formats := []string{
-		"2006-01-02",
-		"01/02/2006",
-		"02/01/2006",
-		"2006/01/02",
-		"01-02-2006",
-		"02-01-2006",
-		"2006-01-02 15:04:05",
-		"01/02/2006 15:04:05",
-		time.RFC3339,
-		time.RFC822,
-	}
-
-	for _, format := range formats {
-		if parsedDate, err := time.Parse(format, dateStr); err == nil {
-			return parsedDate, nil
-		}
-	}
+	return parseDateIn(dateStr, time.UTC)
+}
 
+// parseDateIn parses a single date string, detecting its format, and returns its wall-clock time
+// in location expressed as UTC
+func parseDateIn(dateStr string, location *time.Location) (time.Time, error) {
+	column, err := newDateColumn("date", "", location, []string{dateStr})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if parsed, ok := column.parse(dateStr); ok {
+		return parsed, nil
+	}
 	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
 }
+
+// parseAmount parses a money amount as spreadsheets format it, ignoring currency symbols,
+// thousands separators and surrounding spaces
+func parseAmount(value string) (float64, bool) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return -1
+		}
+	}, value)
+	if cleaned == "" {
+		return 0, false
+	}
+	amount, err := strconv.ParseFloat(cleaned, 64)
+	return amount, err == nil
+}
@@ -2,10 +2,14 @@ package services
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"incident-management-system/internal/models"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
 )
 
 func TestExcelParser_NewExcelParser(t *testing.T) {
@@ -81,6 +85,15 @@ func TestExcelParser_ParseHeader(t *testing.T) {
 				"report_date":      2,
 			},
 		},
+		{
+			name:   "Source and closure code headers",
+			header: []string{"Incident ID", "Alert Source", "Close Code"},
+			expected: map[string]int{
+				"incident_id":  0,
+				"source":       1,
+				"closure_code": 2,
+			},
+		},
 		{
 			name:   "Headers with spaces and underscores",
 			header: []string{"incident id", "application_name", "report-date"},
@@ -104,6 +117,22 @@ func TestExcelParser_ParseHeader(t *testing.T) {
 	}
 }
 
+func TestExcelParser_ParseHeaderWithColumns(t *testing.T) {
+	parser := NewExcelParser(nil)
+
+	// "Summary" is a built-in brief_description name, but the profile repoints the field
+	header := []string{"Ref", "Summary", "Short Description", "Priority"}
+	columns := map[string][]string{
+		"incident_id":       {"ref"},
+		"brief_description": {"Short Description"},
+	}
+
+	indices := parser.parseHeaderWithColumns(header, columns)
+	assert.Equal(t, 0, indices["incident_id"])
+	assert.Equal(t, 2, indices["brief_description"])
+	assert.Equal(t, 3, indices["priority"])
+}
+
 func TestExcelParser_NormalizeColumnName(t *testing.T) {
 	testCases := []struct {
 		input    string
@@ -124,6 +153,29 @@ func TestExcelParser_NormalizeColumnName(t *testing.T) {
 	}
 }
 
+func TestExcelParser_ParseAmount(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected float64
+		ok       bool
+	}{
+		{"125.50", 125.5, true},
+		{"$1,250.00", 1250, true},
+		{" 80 EUR ", 80, true},
+		{"-15", -15, true},
+		{"n/a", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			amount, ok := parseAmount(tc.input)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, amount)
+		})
+	}
+}
+
 func TestExcelParser_ParseDate(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -170,3 +222,215 @@ func TestExcelParser_ParseDate(t *testing.T) {
 		})
 	}
 }
+
+func TestExcelParser_ParseDateIn(t *testing.T) {
+	location, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+
+	// 20:00 UTC is already the next day in Tokyo
+	result, err := parseDateIn("2024-01-15 20:00:00", location)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 16, 5, 0, 0, 0, time.UTC), result)
+
+	// An explicit offset is honoured before converting
+	result, err = parseDateIn("2024-01-15T20:00:00+09:00", location)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 15, 20, 0, 0, 0, time.UTC), result)
+}
+
+// writeLayoutWorkbook saves a workbook after build has laid out its sheets, starting from an
+// empty Sheet1
+func writeLayoutWorkbook(t *testing.T, build func(f *excelize.File) error) string {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := build(f); err != nil {
+		t.Fatalf("Failed to build workbook: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "layout.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatalf("Failed to save workbook: %v", err)
+	}
+	return path
+}
+
+// setRows writes rows to a sheet starting at its first row
+func setRows(f *excelize.File, sheet string, rows [][]interface{}) error {
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestExcelParser_ParseWorkbookLayouts(t *testing.T) {
+	parser := NewExcelParser(nil)
+	header := []interface{}{"Incident ID", "Application Name", "Priority", "Status", "Brief Description"}
+
+	t.Run("title rows and hidden sheets are skipped", func(t *testing.T) {
+		path := writeLayoutWorkbook(t, func(f *excelize.File) error {
+			if err := setRows(f, "Sheet1", [][]interface{}{{"Notes"}, {"Exported from the ticketing tool"}}); err != nil {
+				return err
+			}
+			if _, err := f.NewSheet("Lookup"); err != nil {
+				return err
+			}
+			if err := setRows(f, "Lookup", [][]interface{}{header, {"OLD1", "Legacy", "P1", "Closed", "Old"}}); err != nil {
+				return err
+			}
+			if err := f.SetSheetVisible("Lookup", false); err != nil {
+				return err
+			}
+			if _, err := f.NewSheet("Incidents"); err != nil {
+				return err
+			}
+			return setRows(f, "Incidents", [][]interface{}{
+				{"Weekly incident export"},
+				{},
+				header,
+				{"INC1", "Portal", "P1", "Closed", "Portal down"},
+				{"", "Portal", "P2", "Open", "Missing ID"},
+				{"INC3", "Payroll", "P3", "Open", "Slow"},
+			})
+		})
+
+		result, err := parser.ParseFileWithOptions(context.Background(), path, ParseOptions{})
+		if err != nil {
+			t.Fatalf("Failed to parse workbook: %v", err)
+		}
+		assert.Len(t, result.Incidents, 2)
+		assert.Equal(t, 3, result.TotalRows)
+		if assert.Len(t, result.Errors, 1) {
+			assert.Equal(t, 5, result.Errors[0].Row)
+		}
+		assert.Equal(t, "Incidents", result.Sheet.Sheet)
+		assert.Equal(t, 3, result.Sheet.HeaderRow)
+		assert.Equal(t, "A3:E6", result.Sheet.Range)
+		assert.ElementsMatch(t, []string{"Sheet1", "Lookup"}, result.Sheet.SkippedSheets)
+	})
+
+	t.Run("repeated headers, duplicate rows and summaries are skipped", func(t *testing.T) {
+		path := writeLayoutWorkbook(t, func(f *excelize.File) error {
+			return setRows(f, "Sheet1", [][]interface{}{
+				{"Incident ID", "Application Name", "Priority", "Status", "Brief Description", "Status"},
+				{"INC1", "Portal", "P1", "Closed", "Portal down"},
+				{"INC2", "Payroll", "P2", "Open", "Slow"},
+				{"incident id", "Application Name", "Priority", "Status", "Brief Description", "Status"},
+				{"INC1", "Portal", "P1", "Closed", "Portal down"},
+				{"", "Portal", "P2", "Open", "Missing ID"},
+				{"INC3", "Portal", "P3", "Open", "Login fails"},
+				{"Total", "", "", "", "", "3"},
+				{"Grand total: 3"},
+			})
+		})
+
+		result, err := parser.ParseFileWithOptions(context.Background(), path, ParseOptions{})
+		if err != nil {
+			t.Fatalf("Failed to parse workbook: %v", err)
+		}
+		assert.Len(t, result.Incidents, 3)
+		assert.Equal(t, 4, result.TotalRows)
+		if assert.Len(t, result.Errors, 1) {
+			assert.Equal(t, 6, result.Errors[0].Row, "errors keep their sheet row numbers")
+		}
+		assert.Equal(t, []models.SkippedRow{
+			{Row: 4, Reason: models.SkipRepeatedHeader},
+			{Row: 5, Reason: models.SkipDuplicateRow, DuplicateOf: 2},
+			{Row: 8, Reason: models.SkipSummaryRow},
+			{Row: 9, Reason: models.SkipSummaryRow},
+		}, result.Sheet.SkippedRows)
+		assert.Equal(t, []string{"Status"}, result.Sheet.DuplicateColumns)
+		assert.Equal(t, []string{
+			`Header "Status" names more than one column; the last of them was read`,
+			"Skipped rows repeating the header row: 4",
+			"Skipped rows repeating an earlier row cell for cell, so they are not counted twice: 5 (row 2)",
+			"Skipped summary rows below the data: 8, 9",
+		}, sheetWarnings(result.Sheet))
+	})
+
+	t.Run("configured sheet is read even when hidden", func(t *testing.T) {
+		path := writeLayoutWorkbook(t, func(f *excelize.File) error {
+			if err := setRows(f, "Sheet1", [][]interface{}{header, {"INC1", "Portal", "P1", "Closed", "Down"}}); err != nil {
+				return err
+			}
+			if _, err := f.NewSheet("Archive"); err != nil {
+				return err
+			}
+			if err := setRows(f, "Archive", [][]interface{}{header, {"OLD1", "Legacy", "P2", "Closed", "Old"}}); err != nil {
+				return err
+			}
+			return f.SetSheetVisible("Archive", false)
+		})
+
+		result, err := parser.ParseFileWithOptions(context.Background(), path, ParseOptions{Sheet: "archive"})
+		if err != nil {
+			t.Fatalf("Failed to parse workbook: %v", err)
+		}
+		if assert.Len(t, result.Incidents, 1) {
+			assert.Equal(t, "OLD1", result.Incidents[0].IncidentID)
+		}
+		assert.Equal(t, "Archive", result.Sheet.Sheet)
+
+		_, err = parser.ParseFileWithOptions(context.Background(), path, ParseOptions{Sheet: "Missing"})
+		assert.ErrorContains(t, err, `sheet "Missing" not found`)
+	})
+
+	t.Run("merged headers and cells are filled", func(t *testing.T) {
+		path := writeLayoutWorkbook(t, func(f *excelize.File) error {
+			err := setRows(f, "Sheet1", [][]interface{}{
+				{"Incident ID", "Details", "", "Application Name"},
+				{"", "Priority", "Status", ""},
+				{"INC1", "P1", "Closed", "Portal"},
+				{"INC2", "P2", "Open", ""},
+			})
+			if err != nil {
+				return err
+			}
+			for _, merge := range [][2]string{{"A1", "A2"}, {"B1", "C1"}, {"D1", "D2"}, {"D3", "D4"}} {
+				if err := f.MergeCell("Sheet1", merge[0], merge[1]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+		result, err := parser.ParseFileWithOptions(context.Background(), path, ParseOptions{})
+		if err != nil {
+			t.Fatalf("Failed to parse workbook: %v", err)
+		}
+		assert.Empty(t, result.Errors)
+		if assert.Len(t, result.Incidents, 2) {
+			assert.Equal(t, "P1", result.Incidents[0].Priority)
+			assert.Equal(t, "Portal", result.Incidents[1].ApplicationName)
+			assert.Equal(t, "Open", result.Incidents[1].Status)
+		}
+		assert.Equal(t, 2, result.Sheet.HeaderRow)
+		assert.Equal(t, 4, result.Sheet.MergedRanges)
+	})
+
+	t.Run("formulas without cached values are evaluated", func(t *testing.T) {
+		path := writeLayoutWorkbook(t, func(f *excelize.File) error {
+			if err := setRows(f, "Sheet1", [][]interface{}{{"Incident ID", "Application Name", "Priority", "Number"}}); err != nil {
+				return err
+			}
+			if err := f.SetSheetRow("Sheet1", "B2", &[]interface{}{"Portal", "P1", 1}); err != nil {
+				return err
+			}
+			return f.SetCellFormula("Sheet1", "A2", `"INC"&D2`)
+		})
+
+		result, err := parser.ParseFileWithOptions(context.Background(), path, ParseOptions{})
+		if err != nil {
+			t.Fatalf("Failed to parse workbook: %v", err)
+		}
+		if assert.Len(t, result.Incidents, 1) {
+			assert.Equal(t, "INC1", result.Incidents[0].IncidentID)
+		}
+		assert.Equal(t, 1, result.Sheet.FormulasEvaluated)
+	})
+}
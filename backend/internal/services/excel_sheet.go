@@ -0,0 +1,333 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"incident-management-system/internal/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// headerScanRows is how many rows at the top of a sheet are searched for the header row, so
+// title and note rows above the column names are skipped
+const headerScanRows = 10
+
+// worksheet is a sheet read for parsing, with its merged cells filled in and its header located
+type worksheet struct {
+	name      string
+	rows      [][]string
+	header    int // index of the header row in rows
+	dataStart int // index of the first data row in rows
+	matched   int // incident fields the header row maps to
+	merged    int // merged ranges filled in
+}
+
+// mergedSpan is the 1-based rows and columns a merged cell range covers
+type mergedSpan struct {
+	firstRow, lastRow int
+	firstCol, lastCol int
+}
+
+// selectWorksheet reads the sheet named in options, or else the visible sheet whose header
+// maps to the most incident fields. Ties go to the earlier sheet. When no visible sheet has a
+// recognizable header, the first visible sheet is read with its first row as the header.
+func (p *ExcelParser) selectWorksheet(f *excelize.File, options ParseOptions) (*worksheet, error) {
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("no sheets found in Excel file")
+	}
+
+	if options.Sheet != "" {
+		for _, name := range sheets {
+			if strings.EqualFold(name, options.Sheet) {
+				return p.readWorksheet(f, name, options.Columns)
+			}
+		}
+		return nil, fmt.Errorf("sheet %q not found in Excel file", options.Sheet)
+	}
+
+	var best, first *worksheet
+	for _, name := range sheets {
+		if visible, err := f.GetSheetVisible(name); err == nil && !visible {
+			continue
+		}
+		sheet, err := p.readWorksheet(f, name, options.Columns)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			first = sheet
+		}
+		if sheet.matched > 0 && (best == nil || sheet.matched > best.matched) {
+			best = sheet
+		}
+	}
+
+	switch {
+	case best != nil:
+		return best, nil
+	case first != nil:
+		return first, nil
+	default:
+		return nil, fmt.Errorf("no visible sheets found in Excel file")
+	}
+}
+
+// readWorksheet reads a sheet, copies the value of every merged range into all of its cells and
+// finds the header row among the first headerScanRows rows
+func (p *ExcelParser) readWorksheet(f *excelize.File, name string, columns map[string][]string) (*worksheet, error) {
+	rows, err := f.GetRows(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rows from sheet %s: %w", name, err)
+	}
+	merges, err := f.GetMergeCells(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read merged cells of sheet %s: %w", name, err)
+	}
+
+	sheet := &worksheet{name: name, rows: rows}
+	spans := sheet.fillMergedCells(merges)
+
+	for i := 0; i < len(sheet.rows) && i < headerScanRows; i++ {
+		if matched := len(p.parseHeaderWithColumns(sheet.rows[i], columns)); matched > sheet.matched {
+			sheet.matched = matched
+			sheet.header = i
+		}
+	}
+
+	// A header merged down over several rows, such as a column label beside a grouped one,
+	// ends where its merge does
+	sheet.dataStart = sheet.header + 1
+	headerRow := sheet.header + 1
+	for _, span := range spans {
+		if span.firstRow <= headerRow && headerRow <= span.lastRow && span.lastRow > sheet.dataStart {
+			sheet.dataStart = span.lastRow
+		}
+	}
+
+	return sheet, nil
+}
+
+// fillMergedCells copies the value of each merged range into every cell it covers, so merged
+// headers name each of their columns and merged data cells apply to each of their rows. Ranges
+// are clipped to the rows and columns that hold data.
+func (s *worksheet) fillMergedCells(merges []excelize.MergeCell) []mergedSpan {
+	width := 0
+	for _, row := range s.rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	spans := make([]mergedSpan, 0, len(merges))
+	for _, merge := range merges {
+		firstCol, firstRow, err := excelize.CellNameToCoordinates(merge.GetStartAxis())
+		if err != nil {
+			continue
+		}
+		lastCol, lastRow, err := excelize.CellNameToCoordinates(merge.GetEndAxis())
+		if err != nil {
+			continue
+		}
+		spans = append(spans, mergedSpan{firstRow: firstRow, lastRow: lastRow, firstCol: firstCol, lastCol: lastCol})
+
+		value := merge.GetCellValue()
+		if value == "" {
+			continue
+		}
+		for r := firstRow; r <= lastRow && r <= len(s.rows); r++ {
+			for c := firstCol; c <= lastCol && c <= width; c++ {
+				for len(s.rows[r-1]) < c {
+					s.rows[r-1] = append(s.rows[r-1], "")
+				}
+				s.rows[r-1][c-1] = value
+			}
+		}
+		s.merged++
+	}
+	return spans
+}
+
+// evaluateFormulas calculates the formula cells of the mapped data columns that were saved
+// without a cached value, as workbooks written by scripts often are, and returns how many were
+// calculated. Cells with a cached value already hold the value Excel last calculated.
+func (s *worksheet) evaluateFormulas(f *excelize.File, columnIndices map[string]int) int {
+	evaluated := 0
+	for i := s.dataStart; i < len(s.rows); i++ {
+		for _, col := range columnIndices {
+			if col < len(s.rows[i]) && s.rows[i][col] != "" {
+				continue
+			}
+			cell, err := excelize.CoordinatesToCellName(col+1, i+1)
+			if err != nil {
+				continue
+			}
+			if formula, err := f.GetCellFormula(s.name, cell); err != nil || formula == "" {
+				continue
+			}
+			value, err := f.CalcCellValue(s.name, cell)
+			if err != nil || value == "" {
+				continue
+			}
+			for len(s.rows[i]) <= col {
+				s.rows[i] = append(s.rows[i], "")
+			}
+			s.rows[i][col] = value
+			evaluated++
+		}
+	}
+	return evaluated
+}
+
+// summaryLabels are the words the first cell of a summary row closing a sheet starts with, such
+// as "Total" or "Grand total: 120"
+var summaryLabels = []string{"grand total", "subtotal", "total", "sum", "count", "average", "avg"}
+
+// incidentRows picks out the data rows holding incidents, returning their indices in rows and
+// the rows skipped: copies of the header row, as exports pasted together repeat it, rows
+// repeating an earlier row cell for cell, and the summary rows closing the sheet. Blank rows are
+// kept, so they are reported as before.
+func (s *worksheet) incidentRows(columnIndices map[string]int) ([]int, []models.SkippedRow) {
+	summaryStart := len(s.rows)
+	for i := len(s.rows) - 1; i >= s.dataStart; i-- {
+		label := firstCell(s.rows[i])
+		if label == "" {
+			continue
+		}
+		if !isSummaryLabel(label) {
+			break
+		}
+		summaryStart = i
+	}
+
+	header := s.rows[s.header]
+	seen := make(map[string]int)
+	var kept []int
+	var skipped []models.SkippedRow
+	for i := s.dataStart; i < len(s.rows); i++ {
+		row := s.rows[i]
+		if i >= summaryStart && isSummaryLabel(firstCell(row)) {
+			skipped = append(skipped, models.SkippedRow{Row: i + 1, Reason: models.SkipSummaryRow})
+			continue
+		}
+		if repeatsHeader(row, header, columnIndices) {
+			skipped = append(skipped, models.SkippedRow{Row: i + 1, Reason: models.SkipRepeatedHeader})
+			continue
+		}
+		if key := rowKey(row); key != "" {
+			if first, duplicate := seen[key]; duplicate {
+				skipped = append(skipped, models.SkippedRow{Row: i + 1, Reason: models.SkipDuplicateRow, DuplicateOf: first + 1})
+				continue
+			}
+			seen[key] = i
+		}
+		kept = append(kept, i)
+	}
+	return kept, skipped
+}
+
+// firstCell returns the first non-empty cell of a row, trimmed
+func firstCell(row []string) string {
+	for _, cell := range row {
+		if cell = strings.TrimSpace(cell); cell != "" {
+			return cell
+		}
+	}
+	return ""
+}
+
+// isSummaryLabel reports whether a cell names a summary, a label of summaryLabels followed by
+// nothing or by a number or punctuation rather than more words
+func isSummaryLabel(cell string) bool {
+	cell = strings.ToLower(strings.TrimSpace(cell))
+	for _, label := range summaryLabels {
+		rest, found := strings.CutPrefix(cell, label)
+		if !found {
+			continue
+		}
+		rest = strings.TrimLeft(rest, " :=-")
+		if rest == "" || !strings.ContainsFunc(rest, func(r rune) bool { return r >= 'a' && r <= 'z' }) {
+			return true
+		}
+	}
+	return false
+}
+
+// repeatsHeader reports whether every mapped column of a row holds the header's column name
+func repeatsHeader(row, header []string, columnIndices map[string]int) bool {
+	if len(columnIndices) == 0 {
+		return false
+	}
+	for _, index := range columnIndices {
+		if index >= len(row) || normalizeColumnName(strings.TrimSpace(row[index])) != normalizeColumnName(strings.TrimSpace(header[index])) {
+			return false
+		}
+	}
+	return true
+}
+
+// rowKey joins the trimmed cells of a row, without its trailing empty cells, for finding
+// duplicate rows. Blank rows have no key.
+func rowKey(row []string) string {
+	cells := make([]string, len(row))
+	last := -1
+	for i, cell := range row {
+		cells[i] = strings.TrimSpace(cell)
+		if cells[i] != "" {
+			last = i
+		}
+	}
+	if last < 0 {
+		return ""
+	}
+	return strings.Join(cells[:last+1], "\x1f")
+}
+
+// duplicateColumns returns the headers of mapped columns that name more than one column
+func duplicateColumns(header []string, columnIndices map[string]int) []string {
+	counts := make(map[string]int, len(header))
+	for _, name := range header {
+		if normalized := normalizeColumnName(strings.TrimSpace(name)); normalized != "" {
+			counts[normalized]++
+		}
+	}
+	var duplicates []string
+	for _, index := range columnIndices {
+		if counts[normalizeColumnName(strings.TrimSpace(header[index]))] > 1 {
+			duplicates = append(duplicates, strings.TrimSpace(header[index]))
+		}
+	}
+	sort.Strings(duplicates)
+	return duplicates
+}
+
+// ingested describes the part of the workbook read from this sheet
+func (s *worksheet) ingested(sheets []string) *models.IngestedSheet {
+	width := 1
+	for _, row := range s.rows[s.header:] {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	lastRow := len(s.rows)
+	if lastRow < s.header+1 {
+		lastRow = s.header + 1
+	}
+	start, _ := excelize.CoordinatesToCellName(1, s.header+1)
+	end, _ := excelize.CoordinatesToCellName(width, lastRow)
+
+	ingested := &models.IngestedSheet{
+		Sheet:        s.name,
+		HeaderRow:    s.header + 1,
+		Range:        start + ":" + end,
+		MergedRanges: s.merged,
+	}
+	for _, name := range sheets {
+		if name != s.name {
+			ingested.SkippedSheets = append(ingested.SkippedSheets, name)
+		}
+	}
+	return ingested
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"incident-management-system/internal/logging"
+)
+
+// ErrUnknownFlag is returned for a feature flag that has not been registered
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// Feature flags guarding features that are new or expensive enough to need an off switch
+const (
+	// FlagShadowAnalysis runs the active shadow analyzer configuration after each upload
+	FlagShadowAnalysis = "analysis.shadow_runs"
+	// FlagArchiveFederation makes analytics read archived incidents along with live ones
+	FlagArchiveFederation = "analytics.archive_federation"
+	// FlagJiraTickets lets automation candidates be filed as Jira tickets
+	FlagJiraTickets = "automation.jira_tickets"
+)
+
+// tenantKey is the context key of the tenant a request is made for
+type tenantKey struct{}
+
+// WithTenant returns a context carrying the tenant a request is made for
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant a request is made for, or "" outside a tenant's request
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// FlagDefinition describes a feature flag known to the server
+type FlagDefinition struct {
+	Key         string
+	Description string
+	Default     bool // State until an admin sets the flag
+}
+
+// FlagOverride is the state of a flag for one tenant
+type FlagOverride struct {
+	Tenant    string    `json:"tenant"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FeatureFlag is the current state of a feature flag
+type FeatureFlag struct {
+	Key         string         `json:"key"`
+	Description string         `json:"description"`
+	Enabled     bool           `json:"enabled"`
+	Default     bool           `json:"default"`
+	Overrides   []FlagOverride `json:"overrides"`
+	UpdatedBy   string         `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time     `json:"updated_at,omitempty"`
+}
+
+// storedFlag is a flag state saved by an admin
+type storedFlag struct {
+	enabled   bool
+	updatedBy string
+	updatedAt time.Time
+}
+
+// FeatureFlagService decides whether features are on. Flags are registered in code with a
+// default; admins can switch a flag for everyone and override it per tenant. The saved states
+// are cached and reloaded once the cache is older than its TTL, so checks rarely touch the
+// database.
+type FeatureFlagService struct {
+	db     *sql.DB
+	ttl    time.Duration
+	logger *logging.Logger
+
+	mu          sync.RWMutex
+	definitions []*FlagDefinition
+	byKey       map[string]*FlagDefinition
+	flags       map[string]*storedFlag
+	overrides   map[string]map[string]*storedFlag // flag key -> tenant -> state
+	loadedAt    time.Time
+}
+
+// NewFeatureFlagService creates a feature flag service caching the saved flag states for ttl,
+// default 30s
+func NewFeatureFlagService(ctx context.Context, db *sql.DB, ttl time.Duration) (*FeatureFlagService, error) {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	s := &FeatureFlagService{
+		db:     db,
+		ttl:    ttl,
+		logger: logging.GetGlobalLogger().WithComponent("feature_flags"),
+		byKey:  make(map[string]*FlagDefinition),
+	}
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Register adds a flag. Checks of flags that are not registered always report them off.
+func (s *FeatureFlagService) Register(definition FlagDefinition) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	registered := definition
+	s.definitions = append(s.definitions, &registered)
+	s.byKey[definition.Key] = &registered
+}
+
+// Refresh reloads the saved flag states into the cache
+func (s *FeatureFlagService) Refresh(ctx context.Context) error {
+	flags := make(map[string]*storedFlag)
+	rows, err := s.db.QueryContext(ctx, `SELECT key, enabled, COALESCE(updated_by, ''), updated_at FROM feature_flags`)
+	if err != nil {
+		return fmt.Errorf("failed to query feature flags: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		flag := &storedFlag{}
+		if err := rows.Scan(&key, &flag.enabled, &flag.updatedBy, &flag.updatedAt); err != nil {
+			return fmt.Errorf("failed to scan feature flag: %w", err)
+		}
+		flags[key] = flag
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read feature flags: %w", err)
+	}
+
+	overrides := make(map[string]map[string]*storedFlag)
+	overrideRows, err := s.db.QueryContext(ctx, `
+		SELECT flag_key, tenant_id, enabled, COALESCE(updated_by, ''), updated_at FROM feature_flag_overrides
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query feature flag overrides: %w", err)
+	}
+	defer overrideRows.Close()
+	for overrideRows.Next() {
+		var key, tenant string
+		flag := &storedFlag{}
+		if err := overrideRows.Scan(&key, &tenant, &flag.enabled, &flag.updatedBy, &flag.updatedAt); err != nil {
+			return fmt.Errorf("failed to scan feature flag override: %w", err)
+		}
+		if overrides[key] == nil {
+			overrides[key] = make(map[string]*storedFlag)
+		}
+		overrides[key][tenant] = flag
+	}
+	if err := overrideRows.Err(); err != nil {
+		return fmt.Errorf("failed to read feature flag overrides: %w", err)
+	}
+
+	s.mu.Lock()
+	s.flags = flags
+	s.overrides = overrides
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// refreshIfStale reloads the cache once it is older than the TTL. A failed reload keeps the
+// cached states, so a database problem cannot flip features on or off.
+func (s *FeatureFlagService) refreshIfStale(ctx context.Context) {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) > s.ttl
+	s.mu.RUnlock()
+	if !stale {
+		return
+	}
+	if err := s.Refresh(ctx); err != nil {
+		s.logger.Error("Failed to reload feature flags", err)
+		// Wait a full TTL before trying again rather than querying on every check
+		s.mu.Lock()
+		s.loadedAt = time.Now()
+		s.mu.Unlock()
+	}
+}
+
+// Enabled reports whether a flag is on for the tenant of ctx. A tenant override wins over the
+// flag's global state, which wins over its default.
+func (s *FeatureFlagService) Enabled(ctx context.Context, key string) bool {
+	s.refreshIfStale(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	definition, ok := s.byKey[key]
+	if !ok {
+		return false
+	}
+	if tenant := TenantFromContext(ctx); tenant != "" {
+		if override, ok := s.overrides[key][tenant]; ok {
+			return override.enabled
+		}
+	}
+	if flag, ok := s.flags[key]; ok {
+		return flag.enabled
+	}
+	return definition.Default
+}
+
+// List returns every registered flag in registration order, with its tenant overrides
+func (s *FeatureFlagService) List(ctx context.Context) []FeatureFlag {
+	s.refreshIfStale(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	flags := make([]FeatureFlag, 0, len(s.definitions))
+	for _, definition := range s.definitions {
+		flag := FeatureFlag{
+			Key:         definition.Key,
+			Description: definition.Description,
+			Enabled:     definition.Default,
+			Default:     definition.Default,
+			Overrides:   []FlagOverride{},
+		}
+		if stored, ok := s.flags[definition.Key]; ok {
+			updatedAt := stored.updatedAt
+			flag.Enabled = stored.enabled
+			flag.UpdatedBy = stored.updatedBy
+			flag.UpdatedAt = &updatedAt
+		}
+		for tenant, override := range s.overrides[definition.Key] {
+			flag.Overrides = append(flag.Overrides, FlagOverride{
+				Tenant:    tenant,
+				Enabled:   override.enabled,
+				UpdatedBy: override.updatedBy,
+				UpdatedAt: override.updatedAt,
+			})
+		}
+		sort.Slice(flag.Overrides, func(i, j int) bool { return flag.Overrides[i].Tenant < flag.Overrides[j].Tenant })
+		flags = append(flags, flag)
+	}
+	return flags
+}
+
+// checkFlag returns ErrUnknownFlag unless key is registered
+func (s *FeatureFlagService) checkFlag(key string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.byKey[key]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownFlag, key)
+	}
+	return nil
+}
+
+// SetEnabled switches a flag on or off for every tenant without an override
+func (s *FeatureFlagService) SetEnabled(ctx context.Context, key string, enabled bool, actor string) error {
+	if err := s.checkFlag(key); err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO feature_flags (key, enabled, updated_by, updated_at) VALUES (?, ?, ?, ?)
+	`, key, enabled, actor, time.Now()); err != nil {
+		return fmt.Errorf("failed to save feature flag %s: %w", key, err)
+	}
+	return s.Refresh(ctx)
+}
+
+// SetOverride switches a flag on or off for one tenant
+func (s *FeatureFlagService) SetOverride(ctx context.Context, key, tenant string, enabled bool, actor string) error {
+	if err := s.checkFlag(key); err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO feature_flag_overrides (flag_key, tenant_id, enabled, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, key, tenant, enabled, actor, time.Now()); err != nil {
+		return fmt.Errorf("failed to save override of feature flag %s: %w", key, err)
+	}
+	return s.Refresh(ctx)
+}
+
+// DeleteOverride removes a tenant's override, so the tenant follows the flag's global state
+// again. It returns sql.ErrNoRows if the tenant has no override.
+func (s *FeatureFlagService) DeleteOverride(ctx context.Context, key, tenant string) error {
+	if err := s.checkFlag(key); err != nil {
+		return err
+	}
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM feature_flag_overrides WHERE flag_key = ? AND tenant_id = ?
+	`, key, tenant)
+	if err != nil {
+		return fmt.Errorf("failed to delete override of feature flag %s: %w", key, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return s.Refresh(ctx)
+}
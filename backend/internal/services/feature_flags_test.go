@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+)
+
+func TestFeatureFlagService_GlobalStateAndTenantOverrides(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+	acme := WithTenant(ctx, "acme")
+	globex := WithTenant(ctx, "globex")
+
+	flags, err := NewFeatureFlagService(ctx, db, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFeatureFlagService() error = %v", err)
+	}
+	flags.Register(FlagDefinition{Key: FlagArchiveFederation, Default: true})
+	flags.Register(FlagDefinition{Key: FlagJiraTickets, Default: false})
+
+	if !flags.Enabled(ctx, FlagArchiveFederation) || flags.Enabled(ctx, FlagJiraTickets) {
+		t.Errorf("expected flags to start at their defaults")
+	}
+	if flags.Enabled(ctx, "unregistered") {
+		t.Errorf("expected an unregistered flag to be off")
+	}
+	if err := flags.SetEnabled(ctx, "unregistered", true, "admin"); !errors.Is(err, ErrUnknownFlag) {
+		t.Errorf("SetEnabled() of an unregistered flag error = %v, want ErrUnknownFlag", err)
+	}
+
+	if err := flags.SetEnabled(ctx, FlagArchiveFederation, false, "admin"); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+	if err := flags.SetOverride(ctx, FlagArchiveFederation, "acme", true, "admin"); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+	if flags.Enabled(ctx, FlagArchiveFederation) || flags.Enabled(globex, FlagArchiveFederation) {
+		t.Errorf("expected the flag to be off globally and for tenants without an override")
+	}
+	if !flags.Enabled(acme, FlagArchiveFederation) {
+		t.Errorf("expected the tenant override to switch the flag on for acme")
+	}
+
+	listed := flags.List(ctx)
+	if len(listed) != 2 || listed[0].Key != FlagArchiveFederation || listed[0].Enabled || !listed[0].Default ||
+		listed[0].UpdatedBy != "admin" || len(listed[0].Overrides) != 1 || listed[0].Overrides[0].Tenant != "acme" {
+		t.Errorf("unexpected flags listed: %+v", listed)
+	}
+
+	// Another server sees saved changes once its cache expires
+	other, err := NewFeatureFlagService(ctx, db, time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewFeatureFlagService() error = %v", err)
+	}
+	other.Register(FlagDefinition{Key: FlagJiraTickets, Default: false})
+	if err := flags.SetEnabled(ctx, FlagJiraTickets, true, "admin"); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if !other.Enabled(ctx, FlagJiraTickets) {
+		t.Errorf("expected a stale cache to be reloaded")
+	}
+
+	if err := flags.DeleteOverride(ctx, FlagArchiveFederation, "acme"); err != nil {
+		t.Fatalf("DeleteOverride() error = %v", err)
+	}
+	if flags.Enabled(acme, FlagArchiveFederation) {
+		t.Errorf("expected acme to follow the global state once its override is removed")
+	}
+	if err := flags.DeleteOverride(ctx, FlagArchiveFederation, "acme"); err != sql.ErrNoRows {
+		t.Errorf("DeleteOverride() of a missing override error = %v, want sql.ErrNoRows", err)
+	}
+}
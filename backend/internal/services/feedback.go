@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Analyzer outputs that can receive feedback
+const (
+	FeedbackAnalyzerSentiment    = "sentiment"
+	FeedbackAnalyzerAutomation   = "automation"
+	FeedbackAnalyzerProcessGroup = "process_group"
+)
+
+// UnversionedAnalyzer is reported for incidents analyzed before analyzer versions were recorded
+const UnversionedAnalyzer = "unversioned"
+
+// UnknownFeedbackValue is the actual value of incorrect feedback that did not say what was right
+const UnknownFeedbackValue = "unknown"
+
+var (
+	// ErrNoAnalyzerOutput is returned when feedback targets an analyzer that produced no output for the incident
+	ErrNoAnalyzerOutput = errors.New("incident has no output from this analyzer")
+	// ErrInvalidFeedback is returned when the expected value contradicts the verdict or is not a valid output
+	ErrInvalidFeedback = errors.New("expected value is not consistent with the verdict")
+)
+
+// AnalyzerFeedback is a user verdict on one analyzer output for one incident
+type AnalyzerFeedback struct {
+	ID              string    `json:"id"`
+	IncidentID      string    `json:"incident_id"`
+	Analyzer        string    `json:"analyzer"`
+	AnalyzerVersion string    `json:"analyzer_version"`
+	Predicted       string    `json:"predicted"`
+	Actual          string    `json:"actual"`
+	Correct         bool      `json:"correct"`
+	Comment         string    `json:"comment,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// ConfusionCell counts feedback with one predicted and one actual value
+type ConfusionCell struct {
+	Predicted string `json:"predicted"`
+	Actual    string `json:"actual"`
+	Count     int    `json:"count"`
+}
+
+// AnalyzerAccuracy summarizes feedback for one analyzer version. Only the latest verdict
+// per incident counts, so users can correct their own feedback.
+type AnalyzerAccuracy struct {
+	Analyzer        string          `json:"analyzer"`
+	AnalyzerVersion string          `json:"analyzer_version"`
+	FeedbackCount   int             `json:"feedback_count"`
+	CorrectCount    int             `json:"correct_count"`
+	IncorrectCount  int             `json:"incorrect_count"`
+	Accuracy        float64         `json:"accuracy"`
+	ConfusionMatrix []ConfusionCell `json:"confusion_matrix"`
+}
+
+// IsValidFeedbackAnalyzer reports whether analyzer is an output that accepts feedback
+func IsValidFeedbackAnalyzer(analyzer string) bool {
+	switch analyzer {
+	case FeedbackAnalyzerSentiment, FeedbackAnalyzerAutomation, FeedbackAnalyzerProcessGroup:
+		return true
+	}
+	return false
+}
+
+// FeedbackService records user verdicts on analyzer outputs and reports analyzer accuracy
+type FeedbackService struct {
+	db *sql.DB
+}
+
+// NewFeedbackService creates a new FeedbackService instance
+func NewFeedbackService(db *sql.DB) *FeedbackService {
+	return &FeedbackService{db: db}
+}
+
+// RecordFeedback stores a verdict on an incident's analyzer output, returning sql.ErrNoRows when
+// the incident does not exist. The predicted value and analyzer version are taken from the
+// incident, so the verdict stays attributable after the incident is re-analyzed.
+func (s *FeedbackService) RecordFeedback(ctx context.Context, incidentID, analyzer string, correct bool, expected, comment string) (*AnalyzerFeedback, error) {
+	if !IsValidFeedbackAnalyzer(analyzer) {
+		return nil, fmt.Errorf("invalid feedback analyzer: %s", analyzer)
+	}
+
+	var sentimentLabel, itProcessGroup, sentimentVersion, automationVersion string
+	var automationFeasible sql.NullBool
+	scope, scopeArgs := scopeClause(ctx)
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(sentiment_label, ''), automation_feasible, COALESCE(it_process_group, ''),
+			COALESCE(sentiment_version, ''), COALESCE(automation_version, '')
+		FROM incidents
+		WHERE id = ?`+scope, append([]interface{}{incidentID}, scopeArgs...)...).Scan(&sentimentLabel, &automationFeasible, &itProcessGroup, &sentimentVersion, &automationVersion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to query incident for feedback: %w", err)
+	}
+
+	feedback := &AnalyzerFeedback{
+		ID:              uuid.New().String(),
+		IncidentID:      incidentID,
+		Analyzer:        analyzer,
+		AnalyzerVersion: automationVersion,
+		Correct:         correct,
+		Comment:         strings.TrimSpace(comment),
+		CreatedAt:       time.Now(),
+	}
+
+	expected = strings.TrimSpace(expected)
+	switch analyzer {
+	case FeedbackAnalyzerSentiment:
+		feedback.Predicted = sentimentLabel
+		feedback.AnalyzerVersion = sentimentVersion
+		expected = strings.ToLower(expected)
+		if expected != "" && expected != models.SentimentPositive && expected != models.SentimentNegative && expected != models.SentimentNeutral {
+			return nil, ErrInvalidFeedback
+		}
+	case FeedbackAnalyzerAutomation:
+		if automationFeasible.Valid {
+			feedback.Predicted = strconv.FormatBool(automationFeasible.Bool)
+			// Feasibility is binary, so an incorrect verdict implies the other value
+			if expected == "" && !correct {
+				expected = strconv.FormatBool(!automationFeasible.Bool)
+			}
+		}
+		if expected != "" {
+			value, err := strconv.ParseBool(expected)
+			if err != nil {
+				return nil, ErrInvalidFeedback
+			}
+			expected = strconv.FormatBool(value)
+		}
+	case FeedbackAnalyzerProcessGroup:
+		feedback.Predicted = itProcessGroup
+	}
+
+	if feedback.Predicted == "" {
+		return nil, ErrNoAnalyzerOutput
+	}
+	if feedback.AnalyzerVersion == "" {
+		feedback.AnalyzerVersion = UnversionedAnalyzer
+	}
+
+	switch {
+	case correct && expected != "" && !strings.EqualFold(expected, feedback.Predicted):
+		return nil, ErrInvalidFeedback
+	case !correct && strings.EqualFold(expected, feedback.Predicted):
+		return nil, ErrInvalidFeedback
+	case correct:
+		feedback.Actual = feedback.Predicted
+	case expected == "":
+		feedback.Actual = UnknownFeedbackValue
+	default:
+		feedback.Actual = expected
+	}
+
+	query := `
+		INSERT INTO analyzer_feedback (id, incident_id, analyzer, analyzer_version, predicted, actual, correct, comment, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, feedback.ID, feedback.IncidentID, feedback.Analyzer, feedback.AnalyzerVersion,
+		feedback.Predicted, feedback.Actual, feedback.Correct, nullIfEmpty(feedback.Comment), feedback.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save analyzer feedback: %w", err)
+	}
+
+	verdict := "correct"
+	if !feedback.Correct {
+		verdict = "incorrect"
+	}
+	event := IncidentEvent{
+		IncidentID: incidentID,
+		EventType:  EventFeedback,
+		OccurredAt: feedback.CreatedAt,
+		Source:     EventSourceFeedback,
+		Summary:    fmt.Sprintf("%s output marked %s", strings.ReplaceAll(analyzer, "_", " "), verdict),
+		Details: withEntries(map[string]interface{}{"feedback_id": feedback.ID, "correct": feedback.Correct},
+			"analyzer", feedback.Analyzer, "predicted", feedback.Predicted, "actual", feedback.Actual, "comment", feedback.Comment),
+	}
+	if err := insertIncidentEvents(ctx, s.db, []IncidentEvent{event}); err != nil {
+		return nil, err
+	}
+
+	return feedback, nil
+}
+
+// ListFeedback returns all feedback recorded for an incident, newest first
+func (s *FeedbackService) ListFeedback(ctx context.Context, incidentID string) ([]AnalyzerFeedback, error) {
+	scope, scopeArgs := scopeClause(ctx)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, incident_id, analyzer, analyzer_version, predicted, COALESCE(actual, ''), correct,
+			COALESCE(comment, ''), created_at
+		FROM analyzer_feedback
+		WHERE incident_id = ?
+			AND incident_id IN (SELECT id FROM incidents WHERE 1=1`+scope+`)
+		ORDER BY created_at DESC
+	`, append([]interface{}{incidentID}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query analyzer feedback: %w", err)
+	}
+	defer rows.Close()
+
+	feedback := []AnalyzerFeedback{}
+	for rows.Next() {
+		var item AnalyzerFeedback
+		if err := rows.Scan(&item.ID, &item.IncidentID, &item.Analyzer, &item.AnalyzerVersion, &item.Predicted,
+			&item.Actual, &item.Correct, &item.Comment, &item.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan analyzer feedback: %w", err)
+		}
+		feedback = append(feedback, item)
+	}
+
+	return feedback, rows.Err()
+}
+
+// GetAccuracyReport returns accuracy and a confusion matrix per analyzer and analyzer version,
+// counting only the latest verdict per incident and analyzer. An empty analyzer reports all three.
+func (s *FeedbackService) GetAccuracyReport(ctx context.Context, analyzer string, filters *TimelineFilters) ([]AnalyzerAccuracy, error) {
+	query := `
+		WITH latest AS (
+			SELECT f.analyzer, f.analyzer_version, f.predicted, COALESCE(f.actual, '') as actual, f.correct
+			FROM analyzer_feedback f
+			JOIN incidents ON incidents.id = f.incident_id
+			WHERE 1=1`
+
+	var args []interface{}
+	argIndex := 1
+	if analyzer != "" {
+		query += fmt.Sprintf(" AND f.analyzer = $%d", argIndex)
+		args = append(args, analyzer)
+		argIndex++
+	}
+	whereClause, filterArgs, _ := buildFilterConditions(ctx, filters, argIndex)
+	query += whereClause
+	args = append(args, filterArgs...)
+
+	query += `
+			QUALIFY ROW_NUMBER() OVER (PARTITION BY f.incident_id, f.analyzer ORDER BY f.created_at DESC) = 1
+		)
+		SELECT analyzer, analyzer_version, predicted, actual, correct, COUNT(*) as count
+		FROM latest
+		GROUP BY analyzer, analyzer_version, predicted, actual, correct
+		ORDER BY analyzer, analyzer_version, predicted, actual`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query analyzer accuracy: %w", err)
+	}
+	defer rows.Close()
+
+	byVersion := make(map[string]*AnalyzerAccuracy)
+	var keys []string
+	for rows.Next() {
+		var name, version string
+		var cell ConfusionCell
+		var correct bool
+		if err := rows.Scan(&name, &version, &cell.Predicted, &cell.Actual, &correct, &cell.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan analyzer accuracy row: %w", err)
+		}
+
+		key := name + "\x00" + version
+		accuracy, ok := byVersion[key]
+		if !ok {
+			accuracy = &AnalyzerAccuracy{Analyzer: name, AnalyzerVersion: version, ConfusionMatrix: []ConfusionCell{}}
+			byVersion[key] = accuracy
+			keys = append(keys, key)
+		}
+		accuracy.FeedbackCount += cell.Count
+		if correct {
+			accuracy.CorrectCount += cell.Count
+		} else {
+			accuracy.IncorrectCount += cell.Count
+		}
+		accuracy.ConfusionMatrix = append(accuracy.ConfusionMatrix, cell)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating analyzer accuracy rows: %w", err)
+	}
+
+	sort.Strings(keys)
+	report := make([]AnalyzerAccuracy, 0, len(keys))
+	for _, key := range keys {
+		accuracy := byVersion[key]
+		if accuracy.FeedbackCount > 0 {
+			accuracy.Accuracy = float64(accuracy.CorrectCount) * 100.0 / float64(accuracy.FeedbackCount)
+		}
+		report = append(report, *accuracy)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestFeedbackService_RecordAndReport(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	service := NewFeedbackService(db)
+	ctx := context.Background()
+
+	feasible := true
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P1", "Closed"),
+		diffTestIncident("i2", "upload-1", "INC002", "P2", "Closed"),
+		diffTestIncident("i3", "upload-1", "INC003", "P3", "Closed"),
+	}
+	for i := range incidents {
+		incidents[i].SentimentLabel = models.SentimentNegative
+		incidents[i].SentimentVersion = SimpleSentimentAnalyzerVersion
+		incidents[i].AutomationFeasible = &feasible
+		incidents[i].ITProcessGroup = "Infrastructure"
+		incidents[i].AutomationVersion = SimpleAutomationAnalyzerVersion
+	}
+	// Analyzed before versions were recorded
+	incidents[2].SentimentVersion = ""
+
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	stored, err := NewIncidentService(db).GetIncidentsByUpload(ctx, "upload-1")
+	if err != nil {
+		t.Fatalf("Failed to read incidents: %v", err)
+	}
+	if stored[0].SentimentVersion != SimpleSentimentAnalyzerVersion || stored[0].AutomationVersion != SimpleAutomationAnalyzerVersion {
+		t.Errorf("Expected analyzer versions to round-trip, got %q and %q", stored[0].SentimentVersion, stored[0].AutomationVersion)
+	}
+
+	record := func(incidentID, analyzer string, correct bool, expected string) (*AnalyzerFeedback, error) {
+		return service.RecordFeedback(ctx, incidentID, analyzer, correct, expected, "")
+	}
+
+	if _, err := record("missing", FeedbackAnalyzerSentiment, true, ""); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for unknown incident, got %v", err)
+	}
+	if _, err := record("i1", FeedbackAnalyzerSentiment, true, "positive"); err != ErrInvalidFeedback {
+		t.Errorf("Expected ErrInvalidFeedback for correct verdict with a different value, got %v", err)
+	}
+	if _, err := record("i1", FeedbackAnalyzerSentiment, false, "negative"); err != ErrInvalidFeedback {
+		t.Errorf("Expected ErrInvalidFeedback for incorrect verdict with the predicted value, got %v", err)
+	}
+	if _, err := record("i1", FeedbackAnalyzerSentiment, false, "angry"); err != ErrInvalidFeedback {
+		t.Errorf("Expected ErrInvalidFeedback for unknown sentiment label, got %v", err)
+	}
+
+	// Sentiment: i1 first marked correct, then corrected to positive; i2 correct; i3 unversioned
+	if _, err := record("i1", FeedbackAnalyzerSentiment, true, ""); err != nil {
+		t.Fatalf("Failed to record feedback: %v", err)
+	}
+	feedback, err := record("i1", FeedbackAnalyzerSentiment, false, "Positive")
+	if err != nil {
+		t.Fatalf("Failed to record feedback: %v", err)
+	}
+	if feedback.Predicted != models.SentimentNegative || feedback.Actual != models.SentimentPositive {
+		t.Errorf("Unexpected feedback values: %+v", feedback)
+	}
+	if _, err := record("i2", FeedbackAnalyzerSentiment, true, ""); err != nil {
+		t.Fatalf("Failed to record feedback: %v", err)
+	}
+	feedback, err = record("i3", FeedbackAnalyzerSentiment, false, "")
+	if err != nil {
+		t.Fatalf("Failed to record feedback: %v", err)
+	}
+	if feedback.AnalyzerVersion != UnversionedAnalyzer || feedback.Actual != UnknownFeedbackValue {
+		t.Errorf("Unexpected unversioned feedback: %+v", feedback)
+	}
+
+	// Automation: incorrect without an expected value implies the opposite feasibility
+	feedback, err = record("i1", FeedbackAnalyzerAutomation, false, "")
+	if err != nil {
+		t.Fatalf("Failed to record feedback: %v", err)
+	}
+	if feedback.Predicted != "true" || feedback.Actual != "false" {
+		t.Errorf("Unexpected automation feedback: %+v", feedback)
+	}
+
+	list, err := service.ListFeedback(ctx, "i1")
+	if err != nil {
+		t.Fatalf("Failed to list feedback: %v", err)
+	}
+	if len(list) != 3 {
+		t.Errorf("Expected 3 feedback entries for i1, got %d", len(list))
+	}
+
+	report, err := service.GetAccuracyReport(ctx, FeedbackAnalyzerSentiment, nil)
+	if err != nil {
+		t.Fatalf("Failed to get accuracy report: %v", err)
+	}
+	if len(report) != 2 {
+		t.Fatalf("Expected 2 sentiment versions, got %+v", report)
+	}
+
+	current := report[0]
+	if current.AnalyzerVersion != SimpleSentimentAnalyzerVersion {
+		t.Fatalf("Expected %s first, got %s", SimpleSentimentAnalyzerVersion, current.AnalyzerVersion)
+	}
+	// Only the latest verdict on i1 counts
+	if current.FeedbackCount != 2 || current.CorrectCount != 1 || current.Accuracy != 50 {
+		t.Errorf("Unexpected accuracy: %+v", current)
+	}
+	expected := []ConfusionCell{
+		{Predicted: models.SentimentNegative, Actual: models.SentimentNegative, Count: 1},
+		{Predicted: models.SentimentNegative, Actual: models.SentimentPositive, Count: 1},
+	}
+	if len(current.ConfusionMatrix) != len(expected) {
+		t.Fatalf("Expected %d confusion cells, got %+v", len(expected), current.ConfusionMatrix)
+	}
+	for i, cell := range expected {
+		if current.ConfusionMatrix[i] != cell {
+			t.Errorf("Cell %d: expected %+v, got %+v", i, cell, current.ConfusionMatrix[i])
+		}
+	}
+
+	if report[1].AnalyzerVersion != UnversionedAnalyzer || report[1].Accuracy != 0 {
+		t.Errorf("Unexpected unversioned accuracy: %+v", report[1])
+	}
+
+	// Filters apply to the incidents the feedback is about
+	report, err = service.GetAccuracyReport(ctx, "", &TimelineFilters{Priorities: []string{"P1"}})
+	if err != nil {
+		t.Fatalf("Failed to get filtered accuracy report: %v", err)
+	}
+	if len(report) != 2 || report[0].Analyzer != FeedbackAnalyzerAutomation || report[1].Analyzer != FeedbackAnalyzerSentiment {
+		t.Errorf("Expected automation and sentiment entries for P1, got %+v", report)
+	}
+}
@@ -0,0 +1,503 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Goal metrics are the KPIs a goal can target
+const (
+	GoalMetricIncidentCount        = "incident_count"
+	GoalMetricP1Count              = "p1_count"
+	GoalMetricResolutionRate       = "resolution_rate"
+	GoalMetricAvgResolutionTime    = "avg_resolution_time"
+	GoalMetricMedianResolutionTime = "median_resolution_time"
+)
+
+// Goal comparisons state whether the metric must end the quarter above or below the target
+const (
+	GoalAtLeast = "at_least"
+	GoalAtMost  = "at_most"
+)
+
+// Goal target types: an absolute value, or a percent change against the previous quarter
+const (
+	GoalTargetAbsolute = "absolute"
+	GoalTargetChange   = "change"
+)
+
+// Goal statuses summarise progress toward a goal
+const (
+	GoalStatusUpcoming = "upcoming"
+	GoalStatusOnTrack  = "on_track"
+	GoalStatusAtRisk   = "at_risk"
+	GoalStatusAchieved = "achieved"
+	GoalStatusMissed   = "missed"
+	GoalStatusNoData   = "no_data"
+)
+
+// Trend directions of a goal metric
+const (
+	GoalTrendToward = "toward_goal"
+	GoalTrendAway   = "away_from_goal"
+	GoalTrendFlat   = "flat"
+)
+
+// ErrInvalidGoal is returned when a goal has an unknown quarter or an impossible target
+var ErrInvalidGoal = errors.New("invalid goal")
+
+// Goal is a team's quarterly target for a KPI. For a change target, Target is the percent change
+// against the previous quarter, so -20 with at_most means at least 20% fewer than last quarter.
+type Goal struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Team         string    `json:"team,omitempty"`
+	Metric       string    `json:"metric"`
+	Comparison   string    `json:"comparison"`
+	TargetType   string    `json:"target_type"`
+	Target       float64   `json:"target"`
+	Quarter      string    `json:"quarter"`
+	Applications []string  `json:"applications,omitempty"`
+	CreatedBy    string    `json:"created_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// GoalWeek is the value of a goal metric over one week of the quarter
+type GoalWeek struct {
+	WeekStart time.Time `json:"week_start"`
+	Incidents int       `json:"incidents"`
+	Value     *float64  `json:"value"`
+}
+
+// GoalTrend is the fitted weekly change of a goal metric
+type GoalTrend struct {
+	Direction    string  `json:"direction"`
+	SlopePerWeek float64 `json:"slope_per_week"`
+}
+
+// GoalProgress is the attainment of a goal as of a date. CurrentValue is the quarter-to-date
+// value; TargetToDate pro-rates count targets by the elapsed share of the quarter.
+type GoalProgress struct {
+	Goal           Goal       `json:"goal"`
+	AsOf           string     `json:"as_of"`
+	Status         string     `json:"status"`
+	ElapsedPercent float64    `json:"elapsed_percent"`
+	BaselineValue  *float64   `json:"baseline_value,omitempty"`
+	TargetValue    *float64   `json:"target_value"`
+	CurrentValue   *float64   `json:"current_value"`
+	TargetToDate   *float64   `json:"target_to_date"`
+	AttainmentPct  *float64   `json:"attainment_percent"`
+	ProjectedValue *float64   `json:"projected_value"`
+	Trend          GoalTrend  `json:"trend"`
+	Weeks          []GoalWeek `json:"weeks"`
+}
+
+// GoalService manages quarterly KPI goals
+type GoalService struct {
+	db *sql.DB
+}
+
+// NewGoalService creates a new GoalService instance
+func NewGoalService(db *sql.DB) *GoalService {
+	return &GoalService{db: db}
+}
+
+// goalColumns lists the columns scanned by scanGoal
+const goalColumns = "id, name, team, metric, comparison, target_type, target, quarter, applications, created_by, created_at"
+
+// ListGoals returns the goals of a quarter and team, or all of them when either is empty,
+// ordered by quarter and name
+func (s *GoalService) ListGoals(ctx context.Context, quarter, team string) ([]Goal, error) {
+	query := "SELECT " + goalColumns + " FROM kpi_goals WHERE 1=1"
+	var args []interface{}
+	if quarter != "" {
+		query += " AND quarter = ?"
+		args = append(args, quarter)
+	}
+	if team != "" {
+		query += " AND team = ?"
+		args = append(args, team)
+	}
+	query += " ORDER BY quarter, name, id"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goals: %w", err)
+	}
+	defer rows.Close()
+
+	goals := []Goal{}
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, *goal)
+	}
+	return goals, rows.Err()
+}
+
+// GetGoal returns a goal, or sql.ErrNoRows when it does not exist
+func (s *GoalService) GetGoal(ctx context.Context, id string) (*Goal, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+goalColumns+" FROM kpi_goals WHERE id = ?", id)
+	return scanGoal(row)
+}
+
+// CreateGoal stores a new goal
+func (s *GoalService) CreateGoal(ctx context.Context, goal Goal) (*Goal, error) {
+	if err := normalizeGoal(&goal); err != nil {
+		return nil, err
+	}
+	goal.ID = uuid.New().String()
+	goal.CreatedAt = time.Now()
+
+	var applications interface{}
+	if len(goal.Applications) > 0 {
+		encoded, err := json.Marshal(goal.Applications)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode goal applications: %w", err)
+		}
+		applications = string(encoded)
+	}
+
+	query := `
+		INSERT INTO kpi_goals (id, name, team, metric, comparison, target_type, target, quarter, applications, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, goal.ID, goal.Name, nullIfEmpty(goal.Team), goal.Metric, goal.Comparison,
+		goal.TargetType, goal.Target, goal.Quarter, applications, nullIfEmpty(goal.CreatedBy), goal.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save goal: %w", err)
+	}
+	return &goal, nil
+}
+
+// DeleteGoal removes a goal, returning sql.ErrNoRows when it does not exist
+func (s *GoalService) DeleteGoal(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM kpi_goals WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete goal: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// normalizeGoal trims the text fields of a goal and checks its quarter and target
+func normalizeGoal(goal *Goal) error {
+	goal.Name = strings.TrimSpace(goal.Name)
+	goal.Team = strings.TrimSpace(goal.Team)
+	goal.Quarter = strings.ToUpper(strings.TrimSpace(goal.Quarter))
+	if goal.TargetType == "" {
+		goal.TargetType = GoalTargetAbsolute
+	}
+	goal.Applications = uniqueSorted(goal.Applications)
+
+	if goal.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidGoal)
+	}
+	if _, _, err := ParseQuarter(goal.Quarter); err != nil {
+		return err
+	}
+	switch {
+	case goal.TargetType == GoalTargetChange && goal.Target <= -100:
+		return fmt.Errorf("%w: a change target must be above -100%%", ErrInvalidGoal)
+	case goal.TargetType == GoalTargetAbsolute && goal.Target < 0:
+		return fmt.Errorf("%w: an absolute target cannot be negative", ErrInvalidGoal)
+	case goal.TargetType == GoalTargetAbsolute && goal.Metric == GoalMetricResolutionRate && goal.Target > 100:
+		return fmt.Errorf("%w: a resolution rate target cannot exceed 100", ErrInvalidGoal)
+	}
+	return nil
+}
+
+// ParseQuarter returns the first day of a quarter written as YYYY-Qn and the first day of the
+// quarter after it
+func ParseQuarter(quarter string) (start, end time.Time, err error) {
+	year, number, ok := strings.Cut(quarter, "-Q")
+	if !ok {
+		return start, end, fmt.Errorf("%w: quarter %q must be formatted as YYYY-Qn", ErrInvalidGoal, quarter)
+	}
+	y, yearErr := strconv.Atoi(year)
+	n, numberErr := strconv.Atoi(number)
+	if yearErr != nil || numberErr != nil || len(year) != 4 || n < 1 || n > 4 {
+		return start, end, fmt.Errorf("%w: quarter %q must be formatted as YYYY-Qn", ErrInvalidGoal, quarter)
+	}
+	start = time.Date(y, time.Month(3*(n-1)+1), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 3, 0), nil
+}
+
+// scanGoal scans a kpi_goals row selected with goalColumns
+func scanGoal(row interface{ Scan(...interface{}) error }) (*Goal, error) {
+	var goal Goal
+	var team, applications, createdBy sql.NullString
+	if err := row.Scan(&goal.ID, &goal.Name, &team, &goal.Metric, &goal.Comparison, &goal.TargetType,
+		&goal.Target, &goal.Quarter, &applications, &createdBy, &goal.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan goal: %w", err)
+	}
+	goal.Team = team.String
+	goal.CreatedBy = createdBy.String
+	if applications.Valid && applications.String != "" {
+		if err := json.Unmarshal([]byte(applications.String), &goal.Applications); err != nil {
+			return nil, fmt.Errorf("failed to decode goal applications: %w", err)
+		}
+	}
+	return &goal, nil
+}
+
+// isCount reports whether the goal metric accumulates over the quarter rather than averaging
+func (g Goal) isCount() bool {
+	return g.Metric == GoalMetricIncidentCount || g.Metric == GoalMetricP1Count
+}
+
+// meets reports whether value satisfies the goal's comparison against target
+func (g Goal) meets(value, target float64) bool {
+	if g.Comparison == GoalAtMost {
+		return value <= target
+	}
+	return value >= target
+}
+
+// filters returns the timeline filters selecting the goal's incidents reported between start
+// and end inclusive
+func (g Goal) filters(start, end time.Time) *TimelineFilters {
+	filters := &TimelineFilters{StartDate: &start, EndDate: &end, Applications: g.Applications}
+	if g.Metric == GoalMetricP1Count {
+		filters.Priorities = []string{"P1"}
+	}
+	return filters
+}
+
+// value extracts the goal metric from resolution metrics. Rates and times have no value for a
+// period without incidents.
+func (g Goal) value(metrics *ResolutionMetrics) *float64 {
+	var value float64
+	switch g.Metric {
+	case GoalMetricIncidentCount, GoalMetricP1Count:
+		value = float64(metrics.TotalIncidents)
+	case GoalMetricResolutionRate:
+		value = metrics.ResolutionRate
+	case GoalMetricAvgResolutionTime:
+		value = metrics.AvgResolutionTime
+	case GoalMetricMedianResolutionTime:
+		value = metrics.MedianResolutionTime
+	}
+	if !g.isCount() && metrics.TotalIncidents == 0 {
+		return nil
+	}
+	value = roundTo(value, 2)
+	return &value
+}
+
+// GetGoalProgress computes the attainment of a goal as of a date. The quarter-to-date value and
+// the previous quarter's baseline come from the resolution analysis; the trend is a least squares
+// line through the weekly values, and the projected end-of-quarter value extends that line over
+// the rest of the quarter.
+func (s *AnalyticsService) GetGoalProgress(ctx context.Context, goal Goal, asOf time.Time) (*GoalProgress, error) {
+	quarterStart, quarterEnd, err := ParseQuarter(goal.Quarter)
+	if err != nil {
+		return nil, err
+	}
+	asOf = time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	progress := &GoalProgress{
+		Goal:  goal,
+		AsOf:  asOf.Format("2006-01-02"),
+		Trend: GoalTrend{Direction: GoalTrendFlat},
+		Weeks: []GoalWeek{},
+	}
+
+	target := goal.Target
+	if goal.TargetType == GoalTargetChange {
+		baselineStart := quarterStart.AddDate(0, -3, 0)
+		baseline, err := s.GetResolutionAnalysis(ctx, goal.filters(baselineStart, quarterStart.AddDate(0, 0, -1)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get goal baseline: %w", err)
+		}
+		progress.BaselineValue = goal.value(baseline)
+		if progress.BaselineValue == nil || (goal.isCount() && *progress.BaselineValue == 0) {
+			progress.Status = GoalStatusNoData
+			return progress, nil
+		}
+		target = roundTo(*progress.BaselineValue*(1+goal.Target/100), 2)
+	}
+	progress.TargetValue = &target
+
+	if asOf.Before(quarterStart) {
+		progress.Status = GoalStatusUpcoming
+		return progress, nil
+	}
+
+	// The last day counted is the as-of date, or the quarter's last day once it is over
+	lastDay := asOf
+	if !lastDay.Before(quarterEnd) {
+		lastDay = quarterEnd.AddDate(0, 0, -1)
+	}
+	totalDays := quarterEnd.Sub(quarterStart).Hours() / 24
+	elapsedDays := lastDay.Sub(quarterStart).Hours()/24 + 1
+	progress.ElapsedPercent = roundTo(elapsedDays/totalDays*100, 1)
+
+	current, err := s.GetResolutionAnalysis(ctx, goal.filters(quarterStart, lastDay))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goal attainment: %w", err)
+	}
+	progress.CurrentValue = goal.value(current)
+	if progress.CurrentValue == nil {
+		progress.Status = GoalStatusNoData
+		return progress, nil
+	}
+
+	targetToDate := target
+	if goal.isCount() {
+		targetToDate = roundTo(target*elapsedDays/totalDays, 2)
+	}
+	progress.TargetToDate = &targetToDate
+	progress.AttainmentPct = goalAttainment(goal, *progress.CurrentValue, targetToDate)
+
+	if err := s.fillGoalTrend(ctx, progress, quarterStart, quarterEnd, lastDay); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !lastDay.Before(quarterEnd.AddDate(0, 0, -1)) && goal.meets(*progress.CurrentValue, target):
+		progress.Status = GoalStatusAchieved
+	case !lastDay.Before(quarterEnd.AddDate(0, 0, -1)):
+		progress.Status = GoalStatusMissed
+	case goal.meets(*progress.ProjectedValue, target):
+		progress.Status = GoalStatusOnTrack
+	default:
+		progress.Status = GoalStatusAtRisk
+	}
+	return progress, nil
+}
+
+// goalAttainment returns the current value as a percentage of the target to date, inverted for
+// at_most goals so that 100 or more always means the goal is being met
+func goalAttainment(goal Goal, current, targetToDate float64) *float64 {
+	var attainment float64
+	switch {
+	case goal.Comparison == GoalAtMost && current == 0:
+		attainment = 100
+	case goal.Comparison == GoalAtMost:
+		attainment = targetToDate / current * 100
+	case targetToDate == 0:
+		attainment = 100
+	default:
+		attainment = current / targetToDate * 100
+	}
+	attainment = roundTo(attainment, 1)
+	return &attainment
+}
+
+// fillGoalTrend loads the weekly values of the goal metric from the start of the quarter to
+// lastDay, fits a trend through them and projects the end-of-quarter value. Counts of the
+// partial first and last weeks are scaled up to a full week before fitting.
+func (s *AnalyticsService) fillGoalTrend(ctx context.Context, progress *GoalProgress, quarterStart, quarterEnd, lastDay time.Time) error {
+	goal := progress.Goal
+	whereClause, args, _ := buildFilterConditions(ctx, goal.filters(quarterStart, lastDay), 1)
+	query := fmt.Sprintf(`
+		SELECT
+			DATE_TRUNC('week', report_date) as week_start,
+			COUNT(*) as incidents,
+			COUNT(CASE WHEN resolve_date IS NOT NULL THEN 1 END) as resolved,
+			AVG(%[1]s) as avg_resolution_time,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY %[1]s) as median_resolution_time
+		FROM incidents
+		WHERE 1=1%[2]s
+		GROUP BY 1
+		ORDER BY 1`, resolutionHoursExpr, whereClause)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query goal trend: %w", err)
+	}
+	defer rows.Close()
+
+	weekly := map[string]*ResolutionMetrics{}
+	for rows.Next() {
+		var weekStart time.Time
+		var metrics ResolutionMetrics
+		var avg, median sql.NullFloat64
+		if err := rows.Scan(&weekStart, &metrics.TotalIncidents, &metrics.ResolvedIncidents, &avg, &median); err != nil {
+			return fmt.Errorf("failed to scan goal trend: %w", err)
+		}
+		metrics.AvgResolutionTime = avg.Float64
+		metrics.MedianResolutionTime = median.Float64
+		if metrics.TotalIncidents > 0 {
+			metrics.ResolutionRate = float64(metrics.ResolvedIncidents) / float64(metrics.TotalIncidents) * 100
+		}
+		weekly[weekStart.Format("2006-01-02")] = &metrics
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read goal trend: %w", err)
+	}
+
+	firstWeek := StartOfWeek(quarterStart)
+	end := lastDay.AddDate(0, 0, 1)
+	var xs, ys []float64
+	for week, x := firstWeek, 0; week.Before(end); week, x = week.AddDate(0, 0, 7), x+1 {
+		metrics, ok := weekly[week.Format("2006-01-02")]
+		if !ok {
+			metrics = &ResolutionMetrics{}
+		}
+		value := goal.value(metrics)
+		progress.Weeks = append(progress.Weeks, GoalWeek{WeekStart: week, Incidents: metrics.TotalIncidents, Value: value})
+		if value == nil {
+			continue
+		}
+		y := *value
+		if goal.isCount() {
+			// Days of the week inside the quarter and up to lastDay
+			from, to := week, week.AddDate(0, 0, 7)
+			if from.Before(quarterStart) {
+				from = quarterStart
+			}
+			if to.After(end) {
+				to = end
+			}
+			y *= 7 / (to.Sub(from).Hours() / 24)
+		}
+		xs = append(xs, float64(x))
+		ys = append(ys, y)
+	}
+
+	intercept, slope := fitLine(xs, ys)
+	progress.Trend.SlopePerWeek = roundTo(slope, 2)
+	if len(xs) > 1 && progress.Trend.SlopePerWeek != 0 {
+		if (slope < 0) == (goal.Comparison == GoalAtMost) {
+			progress.Trend.Direction = GoalTrendToward
+		} else {
+			progress.Trend.Direction = GoalTrendAway
+		}
+	}
+
+	// The remaining days are valued at the fitted line's midpoint over them
+	current := *progress.CurrentValue
+	projected := current
+	if remainingDays := quarterEnd.Sub(end).Hours() / 24; remainingDays > 0 && len(xs) > 0 {
+		midpoint := end.Sub(firstWeek).Hours()/24 + remainingDays/2
+		predicted := math.Max(0, intercept+slope*(midpoint/7-0.5))
+		if goal.isCount() {
+			projected = current + predicted*remainingDays/7
+		} else {
+			elapsedDays := end.Sub(quarterStart).Hours() / 24
+			projected = (current*elapsedDays + predicted*remainingDays) / (elapsedDays + remainingDays)
+			if goal.Metric == GoalMetricResolutionRate {
+				projected = math.Min(projected, 100)
+			}
+		}
+	}
+	projected = roundTo(projected, 2)
+	progress.ProjectedValue = &projected
+	return nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestParseQuarter(t *testing.T) {
+	start, end, err := ParseQuarter("2024-Q4")
+	if err != nil {
+		t.Fatalf("Failed to parse quarter: %v", err)
+	}
+	if !start.Equal(time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected quarter bounds %v - %v", start, end)
+	}
+
+	for _, quarter := range []string{"2024-Q5", "2024-Q0", "24-Q1", "2024Q1", ""} {
+		if _, _, err := ParseQuarter(quarter); !errors.Is(err, ErrInvalidGoal) {
+			t.Errorf("Expected ErrInvalidGoal for %q, got %v", quarter, err)
+		}
+	}
+}
+
+func TestAnalyticsService_GetGoalProgress(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	goalService := NewGoalService(db)
+	analyticsService := NewAnalyticsService(db)
+	ctx := context.Background()
+
+	// Ten P1s in Q4 2023, then one a week through the first four weeks of Q1 2024, which starts
+	// on a Monday. Every other Q1 incident is resolved.
+	var incidents []models.Incident
+	for i := 0; i < 10; i++ {
+		incident := diffTestIncident(fmt.Sprintf("b%d", i), "upload-1", fmt.Sprintf("INC0%02d", i), "P1", "Closed")
+		incident.ReportDate = time.Date(2023, 10, 2+7*i, 0, 0, 0, 0, time.UTC)
+		incidents = append(incidents, incident)
+	}
+	for i := 0; i < 4; i++ {
+		incident := diffTestIncident(fmt.Sprintf("q%d", i), "upload-1", fmt.Sprintf("INC1%02d", i), "P1", "Open")
+		incident.ReportDate = time.Date(2024, 1, 1+7*i, 0, 0, 0, 0, time.UTC)
+		if i%2 == 0 {
+			resolved := incident.ReportDate.Add(4 * time.Hour)
+			incident.ResolveDate = &resolved
+			incident.Status = "Closed"
+		}
+		incidents = append(incidents, incident)
+	}
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	if _, err := goalService.CreateGoal(ctx, Goal{Name: "Bad", Metric: GoalMetricP1Count, Comparison: GoalAtMost, Quarter: "2024-Q5"}); !errors.Is(err, ErrInvalidGoal) {
+		t.Errorf("Expected ErrInvalidGoal for an unknown quarter, got %v", err)
+	}
+	if _, err := goalService.CreateGoal(ctx, Goal{Name: "Bad", Metric: GoalMetricResolutionRate, Comparison: GoalAtLeast, Quarter: "2024-Q1", Target: 120}); !errors.Is(err, ErrInvalidGoal) {
+		t.Errorf("Expected ErrInvalidGoal for a rate above 100, got %v", err)
+	}
+
+	reduceP1, err := goalService.CreateGoal(ctx, Goal{
+		Name: "Reduce P1 volume", Team: "Web", Metric: GoalMetricP1Count, Comparison: GoalAtMost,
+		TargetType: GoalTargetChange, Target: -20, Quarter: "2024-q1",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create goal: %v", err)
+	}
+	if reduceP1.Quarter != "2024-Q1" {
+		t.Errorf("Expected the quarter to be normalized, got %q", reduceP1.Quarter)
+	}
+
+	progress, err := analyticsService.GetGoalProgress(ctx, *reduceP1, time.Date(2024, 1, 28, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to get goal progress: %v", err)
+	}
+	if *progress.BaselineValue != 10 || *progress.TargetValue != 8 || *progress.CurrentValue != 4 {
+		t.Errorf("Unexpected baseline, target or current value: %+v", progress)
+	}
+	// One P1 a week continues for the remaining 63 days of the quarter
+	if *progress.ProjectedValue != 13 || progress.Status != GoalStatusAtRisk || progress.Trend.Direction != GoalTrendFlat {
+		t.Errorf("Expected a flat trend projecting 13 P1s, got %+v", progress)
+	}
+	if len(progress.Weeks) != 4 || *progress.TargetToDate != 2.46 {
+		t.Errorf("Expected 4 weeks and a pro-rated target of 2.46, got %+v", progress)
+	}
+
+	ended, err := analyticsService.GetGoalProgress(ctx, *reduceP1, time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to get goal progress: %v", err)
+	}
+	if ended.Status != GoalStatusAchieved || ended.ElapsedPercent != 100 {
+		t.Errorf("Expected the goal to be achieved once the quarter ended, got %+v", ended)
+	}
+
+	upcoming, err := analyticsService.GetGoalProgress(ctx, *reduceP1, time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to get goal progress: %v", err)
+	}
+	if upcoming.Status != GoalStatusUpcoming || upcoming.CurrentValue != nil {
+		t.Errorf("Expected an upcoming goal without a value, got %+v", upcoming)
+	}
+
+	resolution, err := goalService.CreateGoal(ctx, Goal{
+		Name: "Resolution rate", Metric: GoalMetricResolutionRate, Comparison: GoalAtLeast,
+		Target: 95, Quarter: "2024-Q1", Applications: []string{"Portal", " Portal "},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create goal: %v", err)
+	}
+	if len(resolution.Applications) != 1 {
+		t.Errorf("Expected duplicate applications to be merged, got %v", resolution.Applications)
+	}
+	progress, err = analyticsService.GetGoalProgress(ctx, *resolution, time.Date(2024, 1, 28, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to get goal progress: %v", err)
+	}
+	if *progress.CurrentValue != 50 || *progress.AttainmentPct != 52.6 || progress.Status != GoalStatusAtRisk {
+		t.Errorf("Expected a 50%% resolution rate at risk, got %+v", progress)
+	}
+
+	goals, err := goalService.ListGoals(ctx, "2024-Q1", "Web")
+	if err != nil {
+		t.Fatalf("Failed to list goals: %v", err)
+	}
+	if len(goals) != 1 || goals[0].ID != reduceP1.ID {
+		t.Errorf("Expected the Web team goal, got %+v", goals)
+	}
+	if err := goalService.DeleteGoal(ctx, reduceP1.ID); err != nil {
+		t.Fatalf("Failed to delete goal: %v", err)
+	}
+	if err := goalService.DeleteGoal(ctx, reduceP1.ID); err == nil {
+		t.Error("Expected error deleting missing goal")
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+// Golden tests run the analytics against canonical fixture datasets and compare their output to
+// the expected results in testdata/golden/<fixture>/<case>.json, on every supported database
+// dialect. Run with -update to rewrite the golden files after an intended change in output.
+var updateGolden = flag.Bool("update", false, "rewrite the analytics golden files from the current output")
+
+// goldenDir holds one directory per fixture dataset, each with its incidents.json
+const goldenDir = "testdata/golden"
+
+// goldenDialect opens an empty database of one supported SQL dialect with the schema initialized
+type goldenDialect struct {
+	name string
+	open func(t *testing.T) *sql.DB
+}
+
+// goldenDialects lists the database dialects the golden tests run against. All dialects share
+// the golden files, so a dialect-specific difference in output fails its test.
+var goldenDialects = []goldenDialect{
+	{name: "duckdb", open: openGoldenDuckDB},
+}
+
+// goldenCases lists the analytics compared against golden files, named after their file
+var goldenCases = []struct {
+	name string
+	run  func(ctx context.Context, s *AnalyticsService) (interface{}, error)
+}{
+	{"timeline_daily", func(ctx context.Context, s *AnalyticsService) (interface{}, error) {
+		return s.GetDailyTimeline(ctx, nil)
+	}},
+	{"timeline_weekly", func(ctx context.Context, s *AnalyticsService) (interface{}, error) {
+		return s.GetWeeklyTimeline(ctx, nil)
+	}},
+	{"tickets_per_day", func(ctx context.Context, s *AnalyticsService) (interface{}, error) {
+		return s.GetTicketsPerDayMetrics(ctx, nil)
+	}},
+	{"tickets_per_week", func(ctx context.Context, s *AnalyticsService) (interface{}, error) {
+		return s.GetTicketsPerWeekMetrics(ctx, nil)
+	}},
+	{"priority", func(ctx context.Context, s *AnalyticsService) (interface{}, error) {
+		return s.GetPriorityAnalysis(ctx, nil)
+	}},
+	{"applications", func(ctx context.Context, s *AnalyticsService) (interface{}, error) {
+		return s.GetApplicationAnalysis(ctx, &TimelineFilters{Percentiles: []float64{90}})
+	}},
+	{"resolution", func(ctx context.Context, s *AnalyticsService) (interface{}, error) {
+		return s.GetResolutionAnalysis(ctx, &TimelineFilters{Percentiles: []float64{75, 95}})
+	}},
+	{"resolution_without_outliers", func(ctx context.Context, s *AnalyticsService) (interface{}, error) {
+		return s.GetResolutionAnalysisWithOptions(ctx, nil, OutlierOptions{Exclude: true})
+	}},
+	{"performance", func(ctx context.Context, s *AnalyticsService) (interface{}, error) {
+		return s.GetPerformanceMetrics(ctx, nil)
+	}},
+	{"sentiment", func(ctx context.Context, s *AnalyticsService) (interface{}, error) {
+		return s.GetSentimentAnalysis(ctx, nil)
+	}},
+	{"sentiment_timeline_weekly", func(ctx context.Context, s *AnalyticsService) (interface{}, error) {
+		return s.GetSentimentTimeline(ctx, "weekly", nil)
+	}},
+	{"automation", func(ctx context.Context, s *AnalyticsService) (interface{}, error) {
+		return s.GetAutomationAnalysis(ctx, nil)
+	}},
+	{"automation_reporting", func(ctx context.Context, s *AnalyticsService) (interface{}, error) {
+		return s.GetITProcessAutomationReporting(ctx, nil)
+	}},
+}
+
+func TestAnalyticsGolden(t *testing.T) {
+	fixtures, err := os.ReadDir(goldenDir)
+	if err != nil {
+		t.Fatalf("Failed to list golden fixtures: %v", err)
+	}
+
+	for _, dialect := range goldenDialects {
+		for _, fixture := range fixtures {
+			if !fixture.IsDir() {
+				continue
+			}
+			t.Run(dialect.name+"/"+fixture.Name(), func(t *testing.T) {
+				dir := filepath.Join(goldenDir, fixture.Name())
+				db := dialect.open(t)
+				loadGoldenFixture(t, db, filepath.Join(dir, "incidents.json"))
+				service := NewAnalyticsService(db)
+
+				for _, tc := range goldenCases {
+					t.Run(tc.name, func(t *testing.T) {
+						result, err := tc.run(context.Background(), service)
+						if err != nil {
+							t.Fatalf("%s failed: %v", tc.name, err)
+						}
+						compareGolden(t, filepath.Join(dir, tc.name+".json"), result)
+					})
+				}
+			})
+		}
+	}
+}
+
+// openGoldenDuckDB opens an in-memory DuckDB database
+func openGoldenDuckDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { dbWrapper.Close() })
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+	return dbWrapper.GetConnection()
+}
+
+// loadGoldenFixture inserts the incidents of a fixture file
+func loadGoldenFixture(t *testing.T, db *sql.DB, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	var incidents []models.Incident
+	if err := json.Unmarshal(data, &incidents); err != nil {
+		t.Fatalf("Failed to decode fixture %s: %v", path, err)
+	}
+	if len(incidents) == 0 {
+		return
+	}
+	if _, err := NewIncidentService(db).BatchInsertIncidents(context.Background(), incidents, incidents[0].UploadID); err != nil {
+		t.Fatalf("Failed to insert fixture incidents: %v", err)
+	}
+}
+
+// goldenPrecision is the number of decimals numbers are rounded to before comparison, so
+// dialects may differ in floating-point noise but not in results
+const goldenPrecision = 6
+
+// compareGolden compares the JSON encoding of result to a golden file, or rewrites the file with
+// -update
+func compareGolden(t *testing.T, path string, result interface{}) {
+	t.Helper()
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Failed to encode result: %v", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	got, err := json.MarshalIndent(roundGoldenFloats(decoded), "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to encode result: %v", err)
+	}
+	got = append(got, '\n')
+
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("Failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Output differs from %s\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// roundGoldenFloats rounds the numbers of a decoded JSON value to goldenPrecision decimals
+func roundGoldenFloats(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		scale := math.Pow(10, goldenPrecision)
+		return math.Round(v*scale) / scale
+	case []interface{}:
+		for i := range v {
+			v[i] = roundGoldenFloats(v[i])
+		}
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = roundGoldenFloats(v[key])
+		}
+	}
+	return value
+}
@@ -0,0 +1,603 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// sheetsReadonlyScope is the only OAuth scope requested for service accounts
+	sheetsReadonlyScope = "https://www.googleapis.com/auth/spreadsheets.readonly"
+	// defaultGoogleTokenURL is used when credentials do not name a token endpoint
+	defaultGoogleTokenURL = "https://oauth2.googleapis.com/token"
+	// defaultSheetsAPIURL is the Sheets API v4 spreadsheets collection
+	defaultSheetsAPIURL = "https://sheets.googleapis.com/v4/spreadsheets"
+	// defaultSheetRange reads every column in use on the first sheet
+	defaultSheetRange = "A:ZZ"
+)
+
+var (
+	// ErrInvalidSheetSource is returned when a sheet source has an unusable URL, credentials or mapping profile
+	ErrInvalidSheetSource = errors.New("invalid sheet source")
+	// ErrSheetFetchFailed is returned when the Sheets API or token endpoint rejects a request or cannot be reached
+	ErrSheetFetchFailed = errors.New("failed to fetch sheet")
+	// ErrEmptySheet is returned when a sheet has a header row but no incident rows
+	ErrEmptySheet = errors.New("sheet has no data rows")
+
+	spreadsheetURLPattern = regexp.MustCompile(`/spreadsheets/d/([a-zA-Z0-9_-]+)`)
+)
+
+// ServiceAccountCredentials is the subset of a Google service account key file used to
+// authenticate. The sheet must be shared with ClientEmail.
+type ServiceAccountCredentials struct {
+	Type        string `json:"type"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// SheetSource is a Google Sheet imported as uploads, on demand or every SyncIntervalMinutes.
+// The service account key is stored with the source but never returned.
+type SheetSource struct {
+	ID                  string     `json:"id"`
+	Name                string     `json:"name"`
+	SpreadsheetID       string     `json:"spreadsheet_id"`
+	SheetRange          string     `json:"sheet_range,omitempty"`
+	ClientEmail         string     `json:"client_email"`
+	MappingProfile      string     `json:"mapping_profile,omitempty"`
+	SyncIntervalMinutes int        `json:"sync_interval_minutes"`
+	LastSyncedAt        *time.Time `json:"last_synced_at,omitempty"`
+	LastUploadID        string     `json:"last_upload_id,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+
+	credentials string
+}
+
+// ProcessingOptions returns the options uploads imported from the source are processed with
+func (s *SheetSource) ProcessingOptions() models.ProcessingOptions {
+	options := models.DefaultProcessingOptions()
+	options.MappingProfile = s.MappingProfile
+	return options
+}
+
+// SheetSourceInput describes a sheet source to create
+type SheetSourceInput struct {
+	Name                string
+	URL                 string
+	SheetRange          string
+	Credentials         []byte
+	MappingProfile      string
+	SyncIntervalMinutes int
+}
+
+// UploadProcessor processes stored uploads; it is satisfied by ProcessingService
+type UploadProcessor interface {
+	ProcessUploadWithOptions(ctx context.Context, uploadID string, options models.ProcessingOptions) (*ProcessingProgress, error)
+}
+
+// GoogleSheetsService imports Google Sheets as uploads. Each import snapshots the sheet into an
+// Excel file so it is processed, stored and audited exactly like a file upload.
+type GoogleSheetsService struct {
+	db         *sql.DB
+	fileStore  *storage.FileStore
+	httpClient *http.Client
+	apiURL     string
+
+	tokenMu sync.Mutex
+	tokens  map[string]cachedToken
+}
+
+// cachedToken is an access token kept until shortly before it expires
+type cachedToken struct {
+	value   string
+	expires time.Time
+}
+
+// NewGoogleSheetsService creates a new GoogleSheetsService instance
+func NewGoogleSheetsService(db *sql.DB, fileStore *storage.FileStore) *GoogleSheetsService {
+	return &GoogleSheetsService{
+		db:         db,
+		fileStore:  fileStore,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		apiURL:     defaultSheetsAPIURL,
+		tokens:     make(map[string]cachedToken),
+	}
+}
+
+// ParseSpreadsheetURL extracts the spreadsheet ID from a Google Sheets URL
+func ParseSpreadsheetURL(sheetURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(sheetURL))
+	if err != nil || parsed.Host != "docs.google.com" {
+		return "", fmt.Errorf("%w: not a Google Sheets URL", ErrInvalidSheetSource)
+	}
+	match := spreadsheetURLPattern.FindStringSubmatch(parsed.Path)
+	if match == nil {
+		return "", fmt.Errorf("%w: URL does not contain a spreadsheet ID", ErrInvalidSheetSource)
+	}
+	return match[1], nil
+}
+
+// parseServiceAccountCredentials decodes a service account key file and its private key
+func parseServiceAccountCredentials(data []byte) (*ServiceAccountCredentials, *rsa.PrivateKey, error) {
+	var creds ServiceAccountCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, nil, fmt.Errorf("%w: credentials are not a service account key file", ErrInvalidSheetSource)
+	}
+	if creds.Type != "service_account" || creds.ClientEmail == "" {
+		return nil, nil, fmt.Errorf("%w: credentials are not a service account key file", ErrInvalidSheetSource)
+	}
+	if creds.TokenURI == "" {
+		creds.TokenURI = defaultGoogleTokenURL
+	}
+	if tokenURL, err := url.Parse(creds.TokenURI); err != nil || tokenURL.Scheme != "https" {
+		return nil, nil, fmt.Errorf("%w: token_uri must be an https URL", ErrInvalidSheetSource)
+	}
+
+	block, _ := pem.Decode([]byte(creds.PrivateKey))
+	if block == nil {
+		return nil, nil, fmt.Errorf("%w: private_key is not PEM encoded", ErrInvalidSheetSource)
+	}
+	var key interface{}
+	var err error
+	if key, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if err != nil || !ok {
+		return nil, nil, fmt.Errorf("%w: private_key is not an RSA key", ErrInvalidSheetSource)
+	}
+
+	return &creds, rsaKey, nil
+}
+
+// CreateSource validates and stores a sheet source
+func (s *GoogleSheetsService) CreateSource(ctx context.Context, input SheetSourceInput) (*SheetSource, error) {
+	spreadsheetID, err := ParseSpreadsheetURL(input.URL)
+	if err != nil {
+		return nil, err
+	}
+	creds, _, err := parseServiceAccountCredentials(input.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	if input.MappingProfile != "" {
+		if _, err := NewMappingProfileService(s.db).GetProfile(ctx, input.MappingProfile); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("%w: mapping profile %q does not exist", ErrInvalidSheetSource, input.MappingProfile)
+			}
+			return nil, err
+		}
+	}
+
+	source := &SheetSource{
+		ID:                  uuid.New().String(),
+		Name:                strings.TrimSpace(input.Name),
+		SpreadsheetID:       spreadsheetID,
+		SheetRange:          strings.TrimSpace(input.SheetRange),
+		ClientEmail:         creds.ClientEmail,
+		MappingProfile:      input.MappingProfile,
+		SyncIntervalMinutes: input.SyncIntervalMinutes,
+		CreatedAt:           time.Now(),
+		credentials:         string(input.Credentials),
+	}
+
+	query := `
+		INSERT INTO sheet_sources (id, name, spreadsheet_id, sheet_range, credentials,
+			mapping_profile, sync_interval_minutes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, source.ID, source.Name, source.SpreadsheetID,
+		nullIfEmpty(source.SheetRange), source.credentials, nullIfEmpty(source.MappingProfile),
+		source.SyncIntervalMinutes, source.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to create sheet source: %w", err)
+	}
+
+	return source, nil
+}
+
+// sheetSourceColumns is the column list read by scanSheetSource
+const sheetSourceColumns = `id, name, spreadsheet_id, COALESCE(sheet_range, ''), credentials,
+	COALESCE(mapping_profile, ''), sync_interval_minutes, last_synced_at,
+	COALESCE(last_upload_id, ''), COALESCE(last_error, ''), created_at`
+
+// ListSources returns all sheet sources ordered by name
+func (s *GoogleSheetsService) ListSources(ctx context.Context) ([]SheetSource, error) {
+	return s.querySources(ctx, "SELECT "+sheetSourceColumns+" FROM sheet_sources ORDER BY name, created_at")
+}
+
+// GetSource returns a sheet source, or sql.ErrNoRows when it does not exist
+func (s *GoogleSheetsService) GetSource(ctx context.Context, id string) (*SheetSource, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+sheetSourceColumns+" FROM sheet_sources WHERE id = ?", id)
+	return scanSheetSource(row)
+}
+
+// DeleteSource removes a sheet source, returning sql.ErrNoRows when it does not exist.
+// Uploads already imported from it are kept.
+func (s *GoogleSheetsService) DeleteSource(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM sheet_sources WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete sheet source: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ImportSource fetches the sheet and stores it as a new upload, ready to be processed with the
+// source's processing options. The attempt and its outcome are recorded on the source.
+func (s *GoogleSheetsService) ImportSource(ctx context.Context, source *SheetSource) (*models.Upload, error) {
+	upload, err := s.importSource(ctx, source)
+
+	attemptedAt := time.Now()
+	var uploadID, lastError interface{}
+	if err != nil {
+		lastError = err.Error()
+	} else {
+		uploadID = upload.ID
+	}
+	if _, updateErr := s.db.ExecContext(ctx, `
+		UPDATE sheet_sources
+		SET last_synced_at = ?, last_upload_id = COALESCE(?, last_upload_id), last_error = ?
+		WHERE id = ?
+	`, attemptedAt, uploadID, lastError, source.ID); updateErr != nil {
+		log.Printf("Warning: Failed to record import of sheet source %s: %v", source.ID, updateErr)
+	}
+	source.LastSyncedAt = &attemptedAt
+	if err != nil {
+		source.LastError = err.Error()
+		return nil, err
+	}
+	source.LastUploadID = upload.ID
+	source.LastError = ""
+
+	return upload, nil
+}
+
+// importSource fetches the sheet, writes it to an Excel file and creates the upload record
+func (s *GoogleSheetsService) importSource(ctx context.Context, source *SheetSource) (*models.Upload, error) {
+	rows, err := s.fetchValues(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) < 2 {
+		return nil, ErrEmptySheet
+	}
+
+	originalFilename := sheetFilename(source.Name)
+	filename, err := s.fileStore.SaveGeneratedFile(originalFilename, func(w io.Writer) error {
+		return writeSheetWorkbook(w, rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &models.Upload{
+		ID:               uuid.New().String(),
+		Filename:         filename,
+		OriginalFilename: originalFilename,
+		Status:           models.UploadStatusUploaded,
+		Errors:           []string{},
+		CreatedAt:        time.Now(),
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO uploads (id, filename, original_filename, status, record_count,
+			processed_count, error_count, errors, created_at)
+		VALUES (?, ?, ?, ?, 0, 0, 0, '[]', ?)
+	`, upload.ID, upload.Filename, upload.OriginalFilename, upload.Status, upload.CreatedAt); err != nil {
+		s.fileStore.DeleteFile(filename)
+		return nil, fmt.Errorf("failed to create upload record: %w", err)
+	}
+
+	return upload, nil
+}
+
+// SyncDueSources imports and processes every scheduled source whose interval has elapsed since
+// its last attempt. Failures are recorded on the source and do not stop the other sources.
+func (s *GoogleSheetsService) SyncDueSources(ctx context.Context, processor UploadProcessor, now time.Time) int {
+	sources, err := s.ListSources(ctx)
+	if err != nil {
+		log.Printf("Warning: Failed to list sheet sources: %v", err)
+		return 0
+	}
+
+	synced := 0
+	for i := range sources {
+		source := &sources[i]
+		if source.SyncIntervalMinutes <= 0 {
+			continue
+		}
+		interval := time.Duration(source.SyncIntervalMinutes) * time.Minute
+		if source.LastSyncedAt != nil && now.Sub(*source.LastSyncedAt) < interval {
+			continue
+		}
+
+		upload, err := s.ImportSource(ctx, source)
+		if err != nil {
+			log.Printf("Warning: Scheduled import of sheet source %s failed: %v", source.ID, err)
+			continue
+		}
+		if _, err := processor.ProcessUploadWithOptions(ctx, upload.ID, source.ProcessingOptions()); err != nil {
+			log.Printf("Warning: Processing of sheet source %s upload %s failed: %v", source.ID, upload.ID, err)
+		}
+		synced++
+	}
+	return synced
+}
+
+// RunScheduler checks for due sheet sources every interval until ctx is cancelled
+func (s *GoogleSheetsService) RunScheduler(ctx context.Context, processor UploadProcessor, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.SyncDueSources(ctx, processor, now)
+		}
+	}
+}
+
+// fetchValues reads the source's range as formatted cell values, one slice per row
+func (s *GoogleSheetsService) fetchValues(ctx context.Context, source *SheetSource) ([][]string, error) {
+	creds, key, err := parseServiceAccountCredentials([]byte(source.credentials))
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.accessToken(ctx, creds, key)
+	if err != nil {
+		return nil, err
+	}
+
+	sheetRange := source.SheetRange
+	if sheetRange == "" {
+		sheetRange = defaultSheetRange
+	}
+	endpoint := fmt.Sprintf("%s/%s/values/%s?majorDimension=ROWS&valueRenderOption=FORMATTED_VALUE",
+		s.apiURL, url.PathEscape(source.SpreadsheetID), url.PathEscape(sheetRange))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSheetFetchFailed, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var response struct {
+		Values [][]interface{} `json:"values"`
+	}
+	if err := s.doJSON(req, &response); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(response.Values))
+	for _, values := range response.Values {
+		row := make([]string, len(values))
+		for i, value := range values {
+			if value != nil {
+				row[i] = fmt.Sprint(value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// accessToken exchanges a signed service account assertion for an access token, reusing
+// tokens until a minute before they expire
+func (s *GoogleSheetsService) accessToken(ctx context.Context, creds *ServiceAccountCredentials, key *rsa.PrivateKey) (string, error) {
+	cacheKey := creds.ClientEmail + " " + creds.TokenURI
+
+	s.tokenMu.Lock()
+	cached, ok := s.tokens[cacheKey]
+	s.tokenMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	assertion, err := signServiceAccountAssertion(creds, key, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, creds.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrSheetFetchFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var response struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := s.doJSON(req, &response); err != nil {
+		return "", err
+	}
+	if response.AccessToken == "" {
+		return "", fmt.Errorf("%w: token endpoint returned no access token", ErrSheetFetchFailed)
+	}
+
+	s.tokenMu.Lock()
+	s.tokens[cacheKey] = cachedToken{
+		value:   response.AccessToken,
+		expires: time.Now().Add(time.Duration(response.ExpiresIn)*time.Second - time.Minute),
+	}
+	s.tokenMu.Unlock()
+
+	return response.AccessToken, nil
+}
+
+// doJSON sends a request and decodes a successful JSON response, turning Google error
+// responses into ErrSheetFetchFailed with their message
+func (s *GoogleSheetsService) doJSON(req *http.Request, out interface{}) error {
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSheetFetchFailed, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSheetFetchFailed, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// Sheets API errors are objects; token endpoint errors use OAuth's flat form
+		var apiError struct {
+			Error json.RawMessage `json:"error"`
+		}
+		var detail struct {
+			Message string `json:"message"`
+		}
+		var oauthError struct {
+			Description string `json:"error_description"`
+		}
+		message := resp.Status
+		if json.Unmarshal(body, &apiError) == nil && json.Unmarshal(apiError.Error, &detail) == nil && detail.Message != "" {
+			message = detail.Message
+		} else if json.Unmarshal(body, &oauthError) == nil && oauthError.Description != "" {
+			message = oauthError.Description
+		}
+		return fmt.Errorf("%w: %s", ErrSheetFetchFailed, message)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("%w: unexpected response: %v", ErrSheetFetchFailed, err)
+	}
+	return nil
+}
+
+// signServiceAccountAssertion builds the RS256-signed JWT a service account presents to the token endpoint
+func signServiceAccountAssertion(creds *ServiceAccountCredentials, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   creds.ClientEmail,
+		"scope": sheetsReadonlyScope,
+		"aud":   creds.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign service account assertion: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// writeSheetWorkbook writes sheet rows to a single-sheet Excel workbook
+func writeSheetWorkbook(w io.Writer, rows [][]string) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		values := make([]interface{}, len(row))
+		for j, value := range row {
+			values[j] = value
+		}
+		if err := f.SetSheetRow("Sheet1", cell, &values); err != nil {
+			return err
+		}
+	}
+
+	return f.Write(w)
+}
+
+// sheetFilename derives the upload's original filename from the source name
+func sheetFilename(name string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if cleaned == "" {
+		cleaned = "google-sheet"
+	}
+	return cleaned + ".xlsx"
+}
+
+// querySources runs a sheet source query and scans every row
+func (s *GoogleSheetsService) querySources(ctx context.Context, query string, args ...interface{}) ([]SheetSource, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sheet sources: %w", err)
+	}
+	defer rows.Close()
+
+	sources := []SheetSource{}
+	for rows.Next() {
+		source, err := scanSheetSource(rows)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, *source)
+	}
+	return sources, rows.Err()
+}
+
+// scanSheetSource scans one sheet source row and derives its client email from the stored key
+func scanSheetSource(scanner interface{ Scan(...interface{}) error }) (*SheetSource, error) {
+	var source SheetSource
+	var lastSyncedAt sql.NullTime
+	if err := scanner.Scan(&source.ID, &source.Name, &source.SpreadsheetID, &source.SheetRange,
+		&source.credentials, &source.MappingProfile, &source.SyncIntervalMinutes, &lastSyncedAt,
+		&source.LastUploadID, &source.LastError, &source.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan sheet source: %w", err)
+	}
+	if lastSyncedAt.Valid {
+		source.LastSyncedAt = &lastSyncedAt.Time
+	}
+
+	var creds ServiceAccountCredentials
+	if json.Unmarshal([]byte(source.credentials), &creds) == nil {
+		source.ClientEmail = creds.ClientEmail
+	}
+	return &source, nil
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/storage"
+)
+
+// testServiceAccountKey returns a service account key file whose token endpoint is tokenURI
+func testServiceAccountKey(t *testing.T, tokenURI string) ([]byte, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+	creds, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "importer@project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    tokenURI,
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode credentials: %v", err)
+	}
+	return creds, key
+}
+
+func TestParseSpreadsheetURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+		wantErr  bool
+	}{
+		{url: "https://docs.google.com/spreadsheets/d/1AbC-d_9/edit#gid=0", expected: "1AbC-d_9"},
+		{url: "https://docs.google.com/spreadsheets/d/1AbC-d_9", expected: "1AbC-d_9"},
+		{url: "https://docs.google.com/document/d/1AbC/edit", wantErr: true},
+		{url: "https://example.com/spreadsheets/d/1AbC/edit", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		id, err := ParseSpreadsheetURL(tt.url)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidSheetSource) {
+				t.Errorf("Expected ErrInvalidSheetSource for %s, got %v", tt.url, err)
+			}
+			continue
+		}
+		if err != nil || id != tt.expected {
+			t.Errorf("Expected %s for %s, got %s (%v)", tt.expected, tt.url, id, err)
+		}
+	}
+}
+
+func TestGoogleSheetsService_ImportSource(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	dir := t.TempDir()
+	fileStore := storage.NewFileStore(dir)
+	ctx := context.Background()
+
+	var key *rsa.PrivateKey
+	var tokenRequests atomic.Int32
+	rows := [][]interface{}{
+		{"Number", "Opened", "Priority", "Status", "Short description"},
+		{"INC100", "2024-03-01", "P2", "Closed", "VPN drops"},
+		{"INC101", "2024-03-02", "P3", "Open", "Printer offline"},
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			tokenRequests.Add(1)
+			// Verify the assertion was signed with the service account key
+			parts := strings.Split(r.FormValue("assertion"), ".")
+			signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			if rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature) != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"invalid_grant","error_description":"Invalid JWT Signature."}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"token-1","expires_in":3600,"token_type":"Bearer"}`))
+		case r.URL.Path == "/v4/spreadsheets/sheet-1/values/Incidents!A:F":
+			if r.Header.Get("Authorization") != "Bearer token-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"values": rows})
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":{"code":403,"message":"The caller does not have permission","status":"PERMISSION_DENIED"}}`))
+		}
+	}))
+	defer server.Close()
+
+	service := NewGoogleSheetsService(db, fileStore)
+	service.httpClient = server.Client()
+	service.apiURL = server.URL + "/v4/spreadsheets"
+
+	credentials, key := testServiceAccountKey(t, server.URL+"/token")
+
+	if _, err := NewMappingProfileService(db).SaveProfile(ctx, "servicenow", "", map[string][]string{
+		"incident_id":       {"Number"},
+		"report_date":       {"Opened"},
+		"brief_description": {"Short description"},
+	}, nil, nil); err != nil {
+		t.Fatalf("Failed to save mapping profile: %v", err)
+	}
+
+	t.Run("invalid sources are rejected", func(t *testing.T) {
+		inputs := []SheetSourceInput{
+			{Name: "bad url", URL: "https://example.com/sheet", Credentials: credentials},
+			{Name: "bad credentials", URL: "https://docs.google.com/spreadsheets/d/sheet-1/edit", Credentials: []byte(`{"type":"user"}`)},
+			{Name: "unknown profile", URL: "https://docs.google.com/spreadsheets/d/sheet-1/edit", Credentials: credentials, MappingProfile: "jira"},
+		}
+		for _, input := range inputs {
+			if _, err := service.CreateSource(ctx, input); !errors.Is(err, ErrInvalidSheetSource) {
+				t.Errorf("Expected ErrInvalidSheetSource for %s, got %v", input.Name, err)
+			}
+		}
+	})
+
+	source, err := service.CreateSource(ctx, SheetSourceInput{
+		Name:                "EMEA tracker",
+		URL:                 "https://docs.google.com/spreadsheets/d/sheet-1/edit#gid=0",
+		SheetRange:          "Incidents!A:F",
+		Credentials:         credentials,
+		MappingProfile:      "servicenow",
+		SyncIntervalMinutes: 60,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create sheet source: %v", err)
+	}
+	if source.ClientEmail != "importer@project.iam.gserviceaccount.com" {
+		t.Errorf("Expected client email from credentials, got %q", source.ClientEmail)
+	}
+
+	t.Run("credentials are not serialized", func(t *testing.T) {
+		stored, err := service.GetSource(ctx, source.ID)
+		if err != nil {
+			t.Fatalf("Failed to read sheet source: %v", err)
+		}
+		encoded, _ := json.Marshal(stored)
+		if strings.Contains(string(encoded), "PRIVATE KEY") {
+			t.Error("Expected the private key to be omitted from JSON")
+		}
+	})
+
+	t.Run("import stores a processable upload", func(t *testing.T) {
+		upload, err := service.ImportSource(ctx, source)
+		if err != nil {
+			t.Fatalf("Import failed: %v", err)
+		}
+
+		processing := NewProcessingService(db, fileStore)
+		progress, err := processing.ProcessUploadWithOptions(ctx, upload.ID, source.ProcessingOptions())
+		if err != nil {
+			t.Fatalf("Processing failed: %v", err)
+		}
+		if progress.ProcessedRows != 2 {
+			t.Errorf("Expected 2 processed rows, got %+v", progress)
+		}
+
+		stored, err := service.GetSource(ctx, source.ID)
+		if err != nil {
+			t.Fatalf("Failed to read sheet source: %v", err)
+		}
+		if stored.LastUploadID != upload.ID || stored.LastSyncedAt == nil || stored.LastError != "" {
+			t.Errorf("Expected the import to be recorded, got %+v", stored)
+		}
+	})
+
+	t.Run("scheduled sync waits for the interval", func(t *testing.T) {
+		processor := NewProcessingService(db, fileStore)
+		if synced := service.SyncDueSources(ctx, processor, time.Now()); synced != 0 {
+			t.Errorf("Expected no sync within the interval, got %d", synced)
+		}
+		if synced := service.SyncDueSources(ctx, processor, time.Now().Add(61*time.Minute)); synced != 1 {
+			t.Errorf("Expected one sync after the interval, got %d", synced)
+		}
+		if tokenRequests.Load() != 1 {
+			t.Errorf("Expected the access token to be reused, got %d token requests", tokenRequests.Load())
+		}
+	})
+
+	t.Run("fetch failures are recorded", func(t *testing.T) {
+		denied, err := service.CreateSource(ctx, SheetSourceInput{
+			Name:        "Private tracker",
+			URL:         "https://docs.google.com/spreadsheets/d/sheet-2/edit",
+			Credentials: credentials,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create sheet source: %v", err)
+		}
+
+		if _, err := service.ImportSource(ctx, denied); !errors.Is(err, ErrSheetFetchFailed) {
+			t.Fatalf("Expected ErrSheetFetchFailed, got %v", err)
+		}
+
+		stored, err := service.GetSource(ctx, denied.ID)
+		if err != nil {
+			t.Fatalf("Failed to read sheet source: %v", err)
+		}
+		if !strings.Contains(stored.LastError, "does not have permission") {
+			t.Errorf("Expected the API error to be recorded, got %q", stored.LastError)
+		}
+	})
+
+	t.Run("empty sheet", func(t *testing.T) {
+		rows = rows[:1]
+		if _, err := service.ImportSource(ctx, source); !errors.Is(err, ErrEmptySheet) {
+			t.Errorf("Expected ErrEmptySheet, got %v", err)
+		}
+
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM uploads WHERE status = ?", models.UploadStatusUploaded).Scan(&count); err != nil {
+			t.Fatalf("Failed to count uploads: %v", err)
+		}
+		if count != 0 {
+			t.Errorf("Expected no pending uploads from an empty sheet, got %d", count)
+		}
+	})
+}
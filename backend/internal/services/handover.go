@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Handover window bounds and section settings
+const (
+	DefaultHandoverWindow = 8 * time.Hour
+	MaxHandoverWindow     = 72 * time.Hour
+
+	// handoverAgingLimit caps the aging incidents listed in a handover
+	handoverAgingLimit = 20
+	// handoverBaselineDays is the number of days before the handover window that anomalies are
+	// measured against
+	handoverBaselineDays = 14
+	// handoverAnomalyMinIncidents and handoverAnomalyRatio are how many incidents, and how many
+	// times its usual volume, an application needs in the window to be flagged
+	handoverAnomalyMinIncidents = 3
+	handoverAnomalyRatio        = 2.0
+)
+
+// UnassignedResolutionGroup groups the open incidents that record no resolution group
+const UnassignedResolutionGroup = "Unassigned"
+
+// HandoverIncident is an incident called out in a shift handover
+type HandoverIncident struct {
+	IncidentID       string    `json:"incident_id"`
+	Priority         string    `json:"priority"`
+	ApplicationName  string    `json:"application_name"`
+	ResolutionGroup  string    `json:"resolution_group"`
+	BriefDescription string    `json:"brief_description"`
+	Status           string    `json:"status"`
+	ReportDate       time.Time `json:"report_date"`
+	Open             bool      `json:"open"`
+	// AgeHours is how long the incident had been open at the end of the window
+	AgeHours    int `json:"age_hours"`
+	TargetHours int `json:"target_hours,omitempty"`
+}
+
+// HandoverGroup is the unresolved work of one resolution group at the end of the window
+type HandoverGroup struct {
+	ResolutionGroup string `json:"resolution_group"`
+	OpenCount       int    `json:"open_count"`
+	P1Count         int    `json:"p1_count"`
+	P2Count         int    `json:"p2_count"`
+	OldestAgeHours  int    `json:"oldest_age_hours"`
+}
+
+// HandoverAnomaly is an application with far more incidents in the window than it usually has
+type HandoverAnomaly struct {
+	ApplicationName string `json:"application_name"`
+	IncidentCount   int    `json:"incident_count"`
+	// BaselineAverage is the application's usual incident count for as many days as the window spans
+	BaselineAverage float64 `json:"baseline_average"`
+	Message         string  `json:"message"`
+}
+
+// HandoverSummary holds the headline counts of a handover
+type HandoverSummary struct {
+	NewIncidents  int `json:"new_incidents"`
+	NewP1Count    int `json:"new_p1_count"`
+	NewP2Count    int `json:"new_p2_count"`
+	OpenIncidents int `json:"open_incidents"`
+	AgingCount    int `json:"aging_count"`
+}
+
+// HandoverReport summarizes a shift for the handover notes: the P1/P2 incidents raised in the
+// window, the work left open at its end, the open incidents past their SLA target and the
+// applications with unusual volume
+type HandoverReport struct {
+	WindowStart       time.Time          `json:"window_start"`
+	WindowEnd         time.Time          `json:"window_end"`
+	WindowHours       float64            `json:"window_hours"`
+	GeneratedAt       time.Time          `json:"generated_at"`
+	Summary           HandoverSummary    `json:"summary"`
+	NewMajorIncidents []HandoverIncident `json:"new_major_incidents"`
+	UnresolvedGroups  []HandoverGroup    `json:"unresolved_groups"`
+	AgingIncidents    []HandoverIncident `json:"aging_incidents"`
+	Anomalies         []HandoverAnomaly  `json:"anomalies"`
+}
+
+// HandoverService assembles shift handover reports
+type HandoverService struct {
+	db *sql.DB
+}
+
+// NewHandoverService creates a new HandoverService instance
+func NewHandoverService(db *sql.DB) *HandoverService {
+	return &HandoverService{db: db}
+}
+
+// GenerateHandover assembles the handover for the window ending at end. Incidents are dated by
+// day, so an incident is in the window when its report day overlaps it, and is open at the end of
+// the window when it was not resolved by the window's last day. Ages are measured from the start
+// of the report day.
+func (s *HandoverService) GenerateHandover(ctx context.Context, end time.Time, window time.Duration) (*HandoverReport, error) {
+	end = end.UTC()
+	start := end.Add(-window)
+	firstDay, lastDay := handoverDays(start, end)
+	report := &HandoverReport{
+		WindowStart: start,
+		WindowEnd:   end,
+		WindowHours: roundTo(window.Hours(), 2),
+		GeneratedAt: time.Now(),
+	}
+
+	scope, scopeArgs := scopeClause(ctx)
+	newQuery := `
+		SELECT
+			COUNT(*),
+			COUNT(CASE WHEN priority = 'P1' THEN 1 END),
+			COUNT(CASE WHEN priority = 'P2' THEN 1 END)
+		FROM incidents
+		WHERE report_date >= ? AND report_date <= ?` + scope
+	args := append([]interface{}{firstDay, lastDay}, scopeArgs...)
+	summary := &report.Summary
+	if err := s.db.QueryRowContext(ctx, newQuery, args...).Scan(&summary.NewIncidents, &summary.NewP1Count, &summary.NewP2Count); err != nil {
+		return nil, fmt.Errorf("failed to count handover incidents: %w", err)
+	}
+
+	newIncidents, err := s.loadIncidents(ctx, lastDay, end,
+		`report_date >= ? AND report_date <= ? AND priority IN ('P1', 'P2')`, firstDay, lastDay)
+	if err != nil {
+		return nil, err
+	}
+	report.NewMajorIncidents = newIncidents
+
+	openIncidents, err := s.loadIncidents(ctx, lastDay, end,
+		`report_date <= ? AND (resolve_date IS NULL OR resolve_date > ?)`, lastDay, lastDay)
+	if err != nil {
+		return nil, err
+	}
+	summary.OpenIncidents = len(openIncidents)
+	report.UnresolvedGroups = groupOpenIncidents(openIncidents)
+	aging := agingIncidents(openIncidents)
+	summary.AgingCount = len(aging)
+	if len(aging) > handoverAgingLimit {
+		aging = aging[:handoverAgingLimit]
+	}
+	report.AgingIncidents = aging
+
+	report.Anomalies, err = s.volumeAnomalies(ctx, firstDay, lastDay)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// handoverDays returns the first and last report days overlapping [start, end)
+func handoverDays(start, end time.Time) (time.Time, time.Time) {
+	firstDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	lastDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	if lastDay.Equal(end) && lastDay.After(firstDay) {
+		lastDay = lastDay.AddDate(0, 0, -1)
+	}
+	return firstDay, lastDay
+}
+
+// loadIncidents returns the incidents matching condition, oldest first, with whether they were
+// still open after lastDay and their age at end
+func (s *HandoverService) loadIncidents(ctx context.Context, lastDay, end time.Time, condition string, args ...interface{}) ([]HandoverIncident, error) {
+	scope, scopeArgs := scopeClause(ctx)
+	query := `
+		SELECT incident_id, priority, COALESCE(application_name, ''), COALESCE(resolution_group, ''),
+			COALESCE(brief_description, ''), COALESCE(status, ''), report_date, resolve_date
+		FROM incidents
+		WHERE ` + condition + scope + `
+		ORDER BY report_date, incident_id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, append(args, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query handover incidents: %w", err)
+	}
+	defer rows.Close()
+
+	incidents := []HandoverIncident{}
+	for rows.Next() {
+		var incident HandoverIncident
+		var resolveDate sql.NullTime
+		if err := rows.Scan(&incident.IncidentID, &incident.Priority, &incident.ApplicationName,
+			&incident.ResolutionGroup, &incident.BriefDescription, &incident.Status,
+			&incident.ReportDate, &resolveDate); err != nil {
+			return nil, fmt.Errorf("failed to scan handover incident: %w", err)
+		}
+		incident.Open = !resolveDate.Valid || resolveDate.Time.After(lastDay)
+		if incident.Open {
+			incident.AgeHours = int(end.Sub(incident.ReportDate).Hours())
+		}
+		incidents = append(incidents, incident)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating handover incidents: %w", err)
+	}
+	return incidents, nil
+}
+
+// volumeAnomalies returns the applications whose incident count on the report days from
+// firstDay to lastDay is well above their usual count for as many days, taken from the
+// handoverBaselineDays days before firstDay, largest first
+func (s *HandoverService) volumeAnomalies(ctx context.Context, firstDay, lastDay time.Time) ([]HandoverAnomaly, error) {
+	windowDays := int(lastDay.Sub(firstDay).Hours()/24) + 1
+	baselineStart := firstDay.AddDate(0, 0, -handoverBaselineDays)
+	scope, scopeArgs := scopeClause(ctx)
+	query := `
+		SELECT
+			COALESCE(application_name, ''),
+			COUNT(CASE WHEN report_date >= ? THEN 1 END) as window_count,
+			COUNT(CASE WHEN report_date < ? THEN 1 END) as baseline_count
+		FROM incidents
+		WHERE report_date >= ? AND report_date <= ?` + scope + `
+		GROUP BY 1
+		HAVING COUNT(CASE WHEN report_date >= ? THEN 1 END) >= ?
+	`
+
+	args := append([]interface{}{firstDay, firstDay, baselineStart, lastDay}, scopeArgs...)
+	rows, err := s.db.QueryContext(ctx, query, append(args, firstDay, handoverAnomalyMinIncidents)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query handover anomalies: %w", err)
+	}
+	defer rows.Close()
+
+	anomalies := []HandoverAnomaly{}
+	for rows.Next() {
+		var anomaly HandoverAnomaly
+		var baselineCount int
+		if err := rows.Scan(&anomaly.ApplicationName, &anomaly.IncidentCount, &baselineCount); err != nil {
+			return nil, fmt.Errorf("failed to scan handover anomaly: %w", err)
+		}
+		anomaly.BaselineAverage = roundTo(float64(baselineCount)/handoverBaselineDays*float64(windowDays), 2)
+		if float64(anomaly.IncidentCount) < handoverAnomalyRatio*anomaly.BaselineAverage {
+			continue
+		}
+		anomaly.Message = fmt.Sprintf("%d incidents against a usual %.1f", anomaly.IncidentCount, anomaly.BaselineAverage)
+		anomalies = append(anomalies, anomaly)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating handover anomalies: %w", err)
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		if anomalies[i].IncidentCount != anomalies[j].IncidentCount {
+			return anomalies[i].IncidentCount > anomalies[j].IncidentCount
+		}
+		return anomalies[i].ApplicationName < anomalies[j].ApplicationName
+	})
+	return anomalies, nil
+}
+
+// groupOpenIncidents totals open incidents by resolution group, largest backlog first
+func groupOpenIncidents(incidents []HandoverIncident) []HandoverGroup {
+	byGroup := make(map[string]*HandoverGroup)
+	for _, incident := range incidents {
+		name := strings.TrimSpace(incident.ResolutionGroup)
+		if name == "" {
+			name = UnassignedResolutionGroup
+		}
+		group, ok := byGroup[name]
+		if !ok {
+			group = &HandoverGroup{ResolutionGroup: name}
+			byGroup[name] = group
+		}
+		group.OpenCount++
+		switch incident.Priority {
+		case "P1":
+			group.P1Count++
+		case "P2":
+			group.P2Count++
+		}
+		if incident.AgeHours > group.OldestAgeHours {
+			group.OldestAgeHours = incident.AgeHours
+		}
+	}
+
+	groups := make([]HandoverGroup, 0, len(byGroup))
+	for _, group := range byGroup {
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].OpenCount != groups[j].OpenCount {
+			return groups[i].OpenCount > groups[j].OpenCount
+		}
+		return groups[i].ResolutionGroup < groups[j].ResolutionGroup
+	})
+	return groups
+}
+
+// agingIncidents returns the open incidents older than their priority's SLA target, longest
+// overrun first
+func agingIncidents(incidents []HandoverIncident) []HandoverIncident {
+	aging := []HandoverIncident{}
+	for _, incident := range incidents {
+		target, ok := SLATargetHours(incident.Priority)
+		if !ok || incident.AgeHours <= target {
+			continue
+		}
+		incident.TargetHours = target
+		aging = append(aging, incident)
+	}
+	sort.SliceStable(aging, func(i, j int) bool {
+		return aging[i].AgeHours-aging[i].TargetHours > aging[j].AgeHours-aging[j].TargetHours
+	})
+	return aging
+}
+
+// WriteHandoverText renders a handover report as plain text for pasting into handover notes
+func WriteHandoverText(report *HandoverReport, w io.Writer) error {
+	const timeLayout = "2006-01-02 15:04"
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Shift handover: %s to %s UTC (%gh)\n", report.WindowStart.Format(timeLayout),
+		report.WindowEnd.Format(timeLayout), report.WindowHours)
+	fmt.Fprintf(&b, "%d new incident(s), %d P1, %d P2. %d open, %d past SLA.\n",
+		report.Summary.NewIncidents, report.Summary.NewP1Count, report.Summary.NewP2Count,
+		report.Summary.OpenIncidents, report.Summary.AgingCount)
+
+	section := func(title string, count int, lines func()) {
+		fmt.Fprintf(&b, "\n%s (%d)\n", title, count)
+		if count == 0 {
+			b.WriteString("- None\n")
+			return
+		}
+		lines()
+	}
+
+	section("New P1/P2 incidents", len(report.NewMajorIncidents), func() {
+		for _, incident := range report.NewMajorIncidents {
+			state := "resolved"
+			if incident.Open {
+				state = "open"
+			}
+			fmt.Fprintf(&b, "- %s [%s] %s: %s (%s)\n", incident.IncidentID, incident.Priority,
+				incident.ApplicationName, incident.BriefDescription, state)
+		}
+	})
+	section("Unresolved by resolution group", len(report.UnresolvedGroups), func() {
+		for _, group := range report.UnresolvedGroups {
+			fmt.Fprintf(&b, "- %s: %d open (%d P1, %d P2), oldest %dh\n", group.ResolutionGroup,
+				group.OpenCount, group.P1Count, group.P2Count, group.OldestAgeHours)
+		}
+	})
+	section("Aging past SLA", report.Summary.AgingCount, func() {
+		for _, incident := range report.AgingIncidents {
+			fmt.Fprintf(&b, "- %s [%s] %s: open %dh, target %dh\n", incident.IncidentID,
+				incident.Priority, incident.ApplicationName, incident.AgeHours, incident.TargetHours)
+		}
+		if hidden := report.Summary.AgingCount - len(report.AgingIncidents); hidden > 0 {
+			fmt.Fprintf(&b, "- and %d more\n", hidden)
+		}
+	})
+	section("Anomalies", len(report.Anomalies), func() {
+		for _, anomaly := range report.Anomalies {
+			fmt.Fprintf(&b, "- %s: %s\n", anomaly.ApplicationName, anomaly.Message)
+		}
+	})
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
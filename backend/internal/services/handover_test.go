@@ -0,0 +1,103 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestHandoverService_GenerateHandover(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+	end := time.Date(2024, 1, 15, 16, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	incidents := []models.Incident{
+		// Reported on the window's day: an open P1 and a P2 resolved the same day
+		diffTestIncident("i1", "upload-1", "INC001", "P1", "Open"),
+		diffTestIncident("i2", "upload-1", "INC002", "P2", "Closed"),
+		// Reported two days earlier, still open and past its 24 hour P3 target
+		diffTestIncident("i3", "upload-1", "INC003", "P3", "Open"),
+		// Resolved the day after the window, so still open at its end
+		diffTestIncident("i4", "upload-1", "INC004", "P4", "Closed"),
+	}
+	resolvedSameDay, resolvedLater := day(15), day(16)
+	incidents[1].ResolveDate = &resolvedSameDay
+	incidents[2].ReportDate = day(13)
+	incidents[3].ReportDate = day(14)
+	incidents[3].ResolutionGroup = ""
+	incidents[3].ResolveDate = &resolvedLater
+	// A burst of Billing incidents on the window's day
+	for i := 0; i < 4; i++ {
+		incident := diffTestIncident(fmt.Sprintf("b%d", i), "upload-1", fmt.Sprintf("INC1%02d", i), "P4", "Closed")
+		incident.ApplicationName = "Billing"
+		incident.ResolveDate = &resolvedSameDay
+		incidents = append(incidents, incident)
+	}
+
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	report, err := NewHandoverService(db).GenerateHandover(ctx, end, DefaultHandoverWindow)
+	if err != nil {
+		t.Fatalf("Failed to generate handover: %v", err)
+	}
+
+	summary := report.Summary
+	if summary.NewIncidents != 6 || summary.NewP1Count != 1 || summary.NewP2Count != 1 {
+		t.Errorf("Unexpected new incident counts: %+v", summary)
+	}
+	if len(report.NewMajorIncidents) != 2 || !report.NewMajorIncidents[0].Open || report.NewMajorIncidents[1].Open {
+		t.Errorf("Expected the open P1 then the resolved P2, got %+v", report.NewMajorIncidents)
+	}
+	if summary.OpenIncidents != 3 {
+		t.Errorf("Expected 3 incidents open at the end of the window, got %d", summary.OpenIncidents)
+	}
+	if len(report.UnresolvedGroups) != 2 || report.UnresolvedGroups[0].ResolutionGroup != "Web Team" ||
+		report.UnresolvedGroups[0].OpenCount != 2 || report.UnresolvedGroups[0].OldestAgeHours != 64 ||
+		report.UnresolvedGroups[1].ResolutionGroup != UnassignedResolutionGroup {
+		t.Errorf("Unexpected unresolved groups: %+v", report.UnresolvedGroups)
+	}
+	if len(report.AgingIncidents) != 2 || report.AgingIncidents[0].IncidentID != "INC003" || report.AgingIncidents[0].TargetHours != 24 {
+		t.Errorf("Expected INC003 then INC001 to be aging past SLA, got %+v", report.AgingIncidents)
+	}
+	if len(report.Anomalies) != 1 || report.Anomalies[0].ApplicationName != "Billing" || report.Anomalies[0].IncidentCount != 4 {
+		t.Errorf("Expected a Billing anomaly, got %+v", report.Anomalies)
+	}
+
+	// A window ending at midnight covers only the day before
+	firstDay, lastDay := handoverDays(end.Add(-DefaultHandoverWindow), day(16))
+	if !firstDay.Equal(day(15)) || !lastDay.Equal(day(15)) {
+		t.Errorf("Expected the window to cover 2024-01-15 only, got %v - %v", firstDay, lastDay)
+	}
+
+	var text bytes.Buffer
+	if err := WriteHandoverText(report, &text); err != nil {
+		t.Fatalf("Failed to render handover: %v", err)
+	}
+	for _, want := range []string{"2024-01-15 08:00 to 2024-01-15 16:00 UTC (8h)", "INC001 [P1]", "Web Team: 2 open", "INC003 [P3]", "Billing: 4 incidents"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Expected handover text to contain %q, got:\n%s", want, text.String())
+		}
+	}
+}
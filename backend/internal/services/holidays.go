@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Holiday modes select how trend and forecast calculations treat public holidays
+const (
+	HolidaysInclude = "include"
+	HolidaysExclude = "exclude"
+	HolidaysAdjust  = "adjust"
+)
+
+// workdaysPerWeek is the number of weekdays a weekly count is scaled back to in adjust mode
+const workdaysPerWeek = 5
+
+// Holiday is a public holiday in a region's calendar
+type Holiday struct {
+	Region    string    `json:"region"`
+	Date      string    `json:"date"`
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HolidayMarker marks a holiday falling in a timeline or trend period
+type HolidayMarker struct {
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+// HolidayService manages the per-region public holiday calendars
+type HolidayService struct {
+	db *sql.DB
+}
+
+// NewHolidayService creates a new HolidayService instance
+func NewHolidayService(db *sql.DB) *HolidayService {
+	return &HolidayService{db: db}
+}
+
+// NormalizeRegion returns the stored form of a region code, so lookups ignore case
+func NormalizeRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
+
+// ListHolidays returns the holidays ordered by region and date. An empty region lists every
+// calendar and a zero year every year.
+func (s *HolidayService) ListHolidays(ctx context.Context, region string, year int) ([]Holiday, error) {
+	query := "SELECT region, holiday_date, name, updated_at FROM holidays WHERE 1=1"
+	var args []interface{}
+	if region != "" {
+		query += " AND region = ?"
+		args = append(args, NormalizeRegion(region))
+	}
+	if year != 0 {
+		query += " AND year(holiday_date) = ?"
+		args = append(args, year)
+	}
+	query += " ORDER BY region, holiday_date"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query holidays: %w", err)
+	}
+	defer rows.Close()
+
+	holidays := []Holiday{}
+	for rows.Next() {
+		var holiday Holiday
+		var date time.Time
+		if err := rows.Scan(&holiday.Region, &date, &holiday.Name, &holiday.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan holiday: %w", err)
+		}
+		holiday.Date = date.Format("2006-01-02")
+		holidays = append(holidays, holiday)
+	}
+
+	return holidays, rows.Err()
+}
+
+// SaveHolidays adds holidays to a region's calendar, replacing the name of any already on the
+// same date. Marker dates must be YYYY-MM-DD.
+func (s *HolidayService) SaveHolidays(ctx context.Context, region string, holidays []HolidayMarker) ([]Holiday, error) {
+	region = NormalizeRegion(region)
+	now := time.Now()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	saved := make([]Holiday, 0, len(holidays))
+	for _, marker := range holidays {
+		date, err := time.Parse("2006-01-02", marker.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid holiday date %q: %w", marker.Date, err)
+		}
+		holiday := Holiday{Region: region, Date: marker.Date, Name: strings.TrimSpace(marker.Name), UpdatedAt: now}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR REPLACE INTO holidays (region, holiday_date, name, updated_at)
+			VALUES (?, ?, ?, ?)
+		`, holiday.Region, date, holiday.Name, holiday.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to save holiday: %w", err)
+		}
+		saved = append(saved, holiday)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit holidays: %w", err)
+	}
+	return saved, nil
+}
+
+// DeleteHoliday removes a holiday from a region's calendar, returning sql.ErrNoRows when it does not exist
+func (s *HolidayService) DeleteHoliday(ctx context.Context, region string, date time.Time) error {
+	result, err := s.db.ExecContext(ctx,
+		"DELETE FROM holidays WHERE region = ? AND holiday_date = ?", NormalizeRegion(region), date)
+	if err != nil {
+		return fmt.Errorf("failed to delete holiday: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// holidayCalendar returns the holiday names of the filters' region keyed by YYYY-MM-DD date,
+// or nil when no region is selected
+func (s *AnalyticsService) holidayCalendar(ctx context.Context, filters *TimelineFilters) (map[string]string, error) {
+	if filters == nil || filters.HolidayRegion == "" {
+		return nil, nil
+	}
+
+	holidays, err := NewHolidayService(s.db).ListHolidays(ctx, filters.HolidayRegion, 0)
+	if err != nil {
+		return nil, err
+	}
+	calendar := make(map[string]string, len(holidays))
+	for _, holiday := range holidays {
+		calendar[holiday.Date] = holiday.Name
+	}
+	return calendar, nil
+}
+
+// holidayMarkers returns the holidays of calendar in the days days starting at start
+func holidayMarkers(calendar map[string]string, start time.Time, days int) []HolidayMarker {
+	var markers []HolidayMarker
+	for i := 0; i < days; i++ {
+		date := start.AddDate(0, 0, i).Format("2006-01-02")
+		if name, ok := calendar[date]; ok {
+			markers = append(markers, HolidayMarker{Date: date, Name: name})
+		}
+	}
+	return markers
+}
+
+// weekdayHolidays counts the markers falling on Monday to Friday
+func weekdayHolidays(markers []HolidayMarker) int {
+	count := 0
+	for _, marker := range markers {
+		date, err := time.Parse("2006-01-02", marker.Date)
+		if err != nil {
+			continue
+		}
+		if weekday := date.Weekday(); weekday != time.Saturday && weekday != time.Sunday {
+			count++
+		}
+	}
+	return count
+}
+
+// holidayScale returns the factor a period's incident count is multiplied by in a trend or
+// forecast under the holiday mode. A factor of 0 leaves the period out. Daily periods are left
+// out when they are holidays in both modes. Weeks that lose weekdays to holidays are left out in
+// exclude mode, and in adjust mode scaled up to a full working week.
+func holidayScale(mode string, markers []HolidayMarker, weekly bool) float64 {
+	if len(markers) == 0 || (mode != HolidaysExclude && mode != HolidaysAdjust) {
+		return 1
+	}
+	if !weekly {
+		return 0
+	}
+
+	lost := weekdayHolidays(markers)
+	switch {
+	case lost == 0:
+		return 1
+	case mode == HolidaysExclude || lost >= workdaysPerWeek:
+		return 0
+	default:
+		return float64(workdaysPerWeek) / float64(workdaysPerWeek-lost)
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestHolidayAwareTrends(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	holidays := NewHolidayService(db)
+	ctx := context.Background()
+
+	// Ten incidents in each of two normal weeks, six in the Christmas week
+	perDay := map[string]int{"2024-12-10": 10, "2024-12-17": 10, "2024-12-24": 2, "2024-12-25": 1, "2024-12-27": 3}
+	var incidents []models.Incident
+	for day, count := range perDay {
+		date, _ := time.Parse("2006-01-02", day)
+		for i := 0; i < count; i++ {
+			id := fmt.Sprintf("%s-%d", day, i)
+			incident := diffTestIncident(id, "upload-1", "INC-"+id, "P3", "Open")
+			incident.ReportDate = date
+			incidents = append(incidents, incident)
+		}
+	}
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	if _, err := holidays.SaveHolidays(ctx, " de ", []HolidayMarker{
+		{Date: "2024-12-25", Name: "Christmas"},
+		{Date: "2024-12-26", Name: "Boxing Day"},
+	}); err != nil {
+		t.Fatalf("SaveHolidays() error = %v", err)
+	}
+	// Saving a date again renames the holiday
+	if _, err := holidays.SaveHolidays(ctx, "DE", []HolidayMarker{{Date: "2024-12-25", Name: "Christmas Day"}}); err != nil {
+		t.Fatalf("SaveHolidays() error = %v", err)
+	}
+	listed, err := holidays.ListHolidays(ctx, "de", 2024)
+	if err != nil {
+		t.Fatalf("ListHolidays() error = %v", err)
+	}
+	if len(listed) != 2 || listed[0].Region != "DE" || listed[0].Name != "Christmas Day" {
+		t.Fatalf("unexpected holidays %+v", listed)
+	}
+
+	service := NewAnalyticsService(db)
+	daily, err := service.GetDailyTimeline(ctx, &TimelineFilters{HolidayRegion: "de"})
+	if err != nil {
+		t.Fatalf("GetDailyTimeline() error = %v", err)
+	}
+	for _, point := range daily {
+		marked := len(point.Holidays) == 1 && point.Holidays[0].Name == "Christmas Day"
+		if marked != (point.Date == "2024-12-25") {
+			t.Errorf("unexpected holiday markers on %s: %+v", point.Date, point.Holidays)
+		}
+	}
+	weekly, err := service.GetWeeklyTimeline(ctx, &TimelineFilters{HolidayRegion: "DE"})
+	if err != nil {
+		t.Fatalf("GetWeeklyTimeline() error = %v", err)
+	}
+	if len(weekly) != 3 || len(weekly[2].Holidays) != 2 || len(weekly[1].Holidays) != 0 {
+		t.Errorf("expected the Christmas week to carry both holidays, got %+v", weekly)
+	}
+
+	// Excluded holidays drop out of the daily series
+	trends, err := service.GetTrendAnalysis(ctx, "daily", &TimelineFilters{HolidayRegion: "DE", Holidays: HolidaysExclude})
+	if err != nil {
+		t.Fatalf("GetTrendAnalysis() error = %v", err)
+	}
+	if len(trends) != 3 || trends[2].Period != "2024-12-27" || trends[2].GrowthRate != 50 {
+		t.Errorf("expected Dec 27 to be compared with Dec 24, got %+v", trends)
+	}
+
+	// Adjusting scales the Christmas week's six incidents over three working days to ten
+	trends, err = service.GetTrendAnalysis(ctx, "weekly", &TimelineFilters{HolidayRegion: "DE", Holidays: HolidaysAdjust})
+	if err != nil {
+		t.Fatalf("GetTrendAnalysis() error = %v", err)
+	}
+	if len(trends) != 2 || trends[1].AdjustedCount != 10 || trends[1].IncidentCount != 6 || trends[1].Trend != "stable" {
+		t.Errorf("expected a stable adjusted Christmas week, got %+v", trends)
+	}
+	trends, err = service.GetTrendAnalysis(ctx, "weekly", &TimelineFilters{HolidayRegion: "DE", Holidays: HolidaysExclude})
+	if err != nil {
+		t.Fatalf("GetTrendAnalysis() error = %v", err)
+	}
+	if len(trends) != 1 || trends[0].Period != "2024-12-16" {
+		t.Errorf("expected the Christmas week to be excluded, got %+v", trends)
+	}
+
+	// The capacity forecast fits the adjusted history, so the holiday dip is not projected forward
+	endDate := time.Date(2024, 12, 27, 0, 0, 0, 0, time.UTC)
+	plan, err := service.GetCapacityPlan(ctx, &TimelineFilters{EndDate: &endDate, HolidayRegion: "DE", Holidays: HolidaysAdjust},
+		CapacityOptions{HistoryWeeks: 3, ForecastWeeks: 1})
+	if err != nil {
+		t.Fatalf("GetCapacityPlan() error = %v", err)
+	}
+	if len(plan.Groups) != 1 || plan.Groups[0].TrendPerWeek != 0 || plan.Weeks[0].ProjectedIncidents != 10 {
+		t.Errorf("expected a flat adjusted forecast, got %+v", plan)
+	}
+	if len(plan.HolidayWeeks) != 1 || plan.HolidayWeeks[0] != "2024-12-23" {
+		t.Errorf("expected the Christmas week to be reported, got %v", plan.HolidayWeeks)
+	}
+	plan, err = service.GetCapacityPlan(ctx, &TimelineFilters{EndDate: &endDate}, CapacityOptions{HistoryWeeks: 3, ForecastWeeks: 1})
+	if err != nil {
+		t.Fatalf("GetCapacityPlan() error = %v", err)
+	}
+	if plan.Groups[0].TrendPerWeek != -2 {
+		t.Errorf("expected the unadjusted forecast to follow the holiday dip, got %v", plan.Groups[0].TrendPerWeek)
+	}
+
+	if err := holidays.DeleteHoliday(ctx, "de", time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("DeleteHoliday() error = %v", err)
+	}
+	if err := holidays.DeleteHoliday(ctx, "DE", time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC)); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows deleting a missing holiday, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/models"
+)
+
+func TestAnalyticsService_GetHourlyTimeline(t *testing.T) {
+	at := func(id string, reportDate time.Time, priority string) models.Incident {
+		incident := diffTestIncident(id, "upload-1", "INC-"+id, priority, "Closed")
+		incident.ReportDate = reportDate
+		return incident
+	}
+	dbWrapper, db := newSentimentTestDB(t, []models.Incident{
+		at("1", time.Date(2024, 1, 15, 9, 5, 0, 0, time.UTC), "P1"),
+		at("2", time.Date(2024, 1, 15, 9, 55, 0, 0, time.UTC), "P3"),
+		at("3", time.Date(2024, 1, 15, 14, 0, 0, 0, time.UTC), "P2"),
+		at("4", time.Date(2024, 1, 16, 0, 30, 0, 0, time.UTC), "P3"),
+		// Reported with a date alone: no hour to count it in
+		at("5", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), "P4"),
+	})
+	defer dbWrapper.Close()
+	service := NewAnalyticsService(db)
+
+	timeline, err := service.GetHourlyTimeline(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetHourlyTimeline() error = %v", err)
+	}
+	if len(timeline) != 3 {
+		t.Fatalf("expected three hours with incidents, got %+v", timeline)
+	}
+	if timeline[0].Date != "2024-01-15T09:00:00Z" || timeline[0].IncidentCount != 2 || timeline[0].P1Count != 1 || timeline[0].P3Count != 1 {
+		t.Errorf("unexpected first hour: %+v", timeline[0])
+	}
+	if timeline[2].Date != "2024-01-16T00:00:00Z" || timeline[2].IncidentCount != 1 {
+		t.Errorf("unexpected last hour: %+v", timeline[2])
+	}
+
+	// The date range filters on the report date
+	day := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+	timeline, err = service.GetHourlyTimeline(context.Background(), &TimelineFilters{StartDate: &day, EndDate: &day})
+	if err != nil {
+		t.Fatalf("GetHourlyTimeline() error = %v", err)
+	}
+	if len(timeline) != 1 || timeline[0].Date != "2024-01-16T00:00:00Z" {
+		t.Errorf("expected the hours of 2024-01-16 only, got %+v", timeline)
+	}
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Incident lifecycle event types
+const (
+	EventReported = "reported"
+	EventResolved = "resolved"
+	EventReopened = "reopened"
+	EventComment  = "comment"
+	EventAnalyzed = "analyzed"
+	EventFeedback = "feedback"
+	EventEdited   = "edited"
+)
+
+// Sources that record incident events
+const (
+	EventSourceIngestion        = "ingestion"
+	EventSourceAnalysisJob      = "analysis_job"
+	EventSourceFeedback         = "feedback"
+	EventSourceRecategorization = "recategorization"
+)
+
+// eventOrder breaks ties between events that occurred at the same time, so a timeline built
+// from day-precision dates still reads in lifecycle order
+const eventOrder = `CASE event_type
+	WHEN 'reported' THEN 0 WHEN 'resolved' THEN 1 WHEN 'reopened' THEN 2 WHEN 'comment' THEN 3
+	WHEN 'analyzed' THEN 4 WHEN 'feedback' THEN 5 ELSE 6 END`
+
+// IncidentEvent is one entry in an incident's lifecycle history
+type IncidentEvent struct {
+	ID         string                 `json:"id"`
+	IncidentID string                 `json:"incident_id"`
+	EventType  string                 `json:"event_type"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Source     string                 `json:"source"`
+	Summary    string                 `json:"summary"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// eventExecer is satisfied by both *sql.DB and *sql.Tx, so events can be recorded inside the
+// transaction that stores the change they describe
+type eventExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// IngestionEvents derives the lifecycle events of a freshly ingested incident from its fields.
+// A reopened incident gets a reopened event at its first resolution and a second resolved event
+// at its last resolution. analyzedAt is when the analyzers ran during processing.
+func IngestionEvents(incident models.Incident, analyzedAt time.Time) []IncidentEvent {
+	details := map[string]interface{}{"upload_id": incident.UploadID}
+	if incident.DatasetID != "" {
+		details["dataset_id"] = incident.DatasetID
+	}
+
+	events := []IncidentEvent{{
+		EventType:  EventReported,
+		OccurredAt: incident.ReportDate,
+		Summary:    fmt.Sprintf("%s incident reported for %s", incident.Priority, applicationOrUnknown(incident.ApplicationName)),
+		Details:    withEntries(details, "priority", incident.Priority, "status", incident.Status, "resolution_group", incident.ResolutionGroup),
+	}}
+
+	lastActivity := incident.ReportDate
+	if incident.ResolveDate != nil {
+		events = append(events, resolvedEvent(incident, *incident.ResolveDate, details))
+		lastActivity = *incident.ResolveDate
+	}
+
+	resolvedAgain := incident.ResolveDate != nil && incident.LastResolveDate != nil && incident.LastResolveDate.After(*incident.ResolveDate)
+	if resolvedAgain || strings.EqualFold(incident.Status, "Reopened") {
+		events = append(events, IncidentEvent{
+			EventType:  EventReopened,
+			OccurredAt: lastActivity,
+			Summary:    "Incident reopened",
+			Details:    withEntries(details, "status", incident.Status),
+		})
+	}
+	if resolvedAgain {
+		events = append(events, resolvedEvent(incident, *incident.LastResolveDate, details))
+		lastActivity = *incident.LastResolveDate
+	}
+
+	if notes := strings.TrimSpace(incident.ResolutionNotes); notes != "" {
+		events = append(events, IncidentEvent{
+			EventType:  EventComment,
+			OccurredAt: lastActivity,
+			Summary:    "Resolution notes added",
+			Details:    withEntries(details, "field", "resolution_notes", "text", notes),
+		})
+	}
+
+	if analyzed := AnalysisEvent(incident, EventSourceIngestion, analyzedAt); analyzed != nil {
+		analyzed.Details["upload_id"] = incident.UploadID
+		events = append(events, *analyzed)
+	}
+
+	for i := range events {
+		events[i].IncidentID = incident.ID
+		events[i].Source = EventSourceIngestion
+	}
+	return events
+}
+
+// AnalysisEvent describes the outputs of the named analyzers stored on an incident, or of every
+// analyzer when none are named. It returns nil when none of them produced output.
+func AnalysisEvent(incident models.Incident, source string, at time.Time, analyzers ...string) *IncidentEvent {
+	include := func(analyzer string) bool {
+		if len(analyzers) == 0 {
+			return true
+		}
+		for _, name := range analyzers {
+			if name == analyzer {
+				return true
+			}
+		}
+		return false
+	}
+
+	details := map[string]interface{}{}
+	var ran []string
+	if include(FeedbackAnalyzerSentiment) && incident.SentimentLabel != "" {
+		ran = append(ran, FeedbackAnalyzerSentiment)
+		details["sentiment_label"] = incident.SentimentLabel
+		if incident.SentimentScore != nil {
+			details["sentiment_score"] = *incident.SentimentScore
+		}
+		if incident.SentimentVersion != "" {
+			details["sentiment_version"] = incident.SentimentVersion
+		}
+	}
+	if include(FeedbackAnalyzerAutomation) && incident.AutomationFeasible != nil {
+		ran = append(ran, FeedbackAnalyzerAutomation)
+		details["automation_feasible"] = *incident.AutomationFeasible
+		if incident.AutomationScore != nil {
+			details["automation_score"] = *incident.AutomationScore
+		}
+		if incident.ITProcessGroup != "" {
+			details["it_process_group"] = incident.ITProcessGroup
+		}
+		if incident.AutomationVersion != "" {
+			details["automation_version"] = incident.AutomationVersion
+		}
+	}
+	if len(ran) == 0 {
+		return nil
+	}
+
+	return &IncidentEvent{
+		IncidentID: incident.ID,
+		EventType:  EventAnalyzed,
+		OccurredAt: at,
+		Source:     source,
+		Summary:    "Analyzed for " + strings.Join(ran, " and "),
+		Details:    details,
+	}
+}
+
+// resolvedEvent describes one resolution of an incident
+func resolvedEvent(incident models.Incident, at time.Time, base map[string]interface{}) IncidentEvent {
+	summary := "Incident resolved"
+	if incident.ResolvedPerson != "" {
+		summary += " by " + incident.ResolvedPerson
+	}
+	details := withEntries(base, "resolved_person", incident.ResolvedPerson)
+	if incident.ResolutionTimeHours != nil {
+		details["resolution_time_hours"] = *incident.ResolutionTimeHours
+	}
+	return IncidentEvent{EventType: EventResolved, OccurredAt: at, Summary: summary, Details: details}
+}
+
+// withEntries copies base and adds the non-empty key/value pairs
+func withEntries(base map[string]interface{}, pairs ...string) map[string]interface{} {
+	details := make(map[string]interface{}, len(base)+len(pairs)/2)
+	for key, value := range base {
+		details[key] = value
+	}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			details[pairs[i]] = pairs[i+1]
+		}
+	}
+	return details
+}
+
+// applicationOrUnknown returns the application name, or "unknown application" when it is empty
+func applicationOrUnknown(value string) string {
+	if value == "" {
+		return "unknown application"
+	}
+	return value
+}
+
+// insertIncidentEvents stores events, filling in their IDs and creation times
+func insertIncidentEvents(ctx context.Context, db eventExecer, events []IncidentEvent) error {
+	query := `
+		INSERT INTO incident_events (id, incident_id, event_type, occurred_at, source, summary, details, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	now := time.Now()
+	for i := range events {
+		event := &events[i]
+		if event.ID == "" {
+			event.ID = uuid.New().String()
+		}
+		if event.CreatedAt.IsZero() {
+			event.CreatedAt = now
+		}
+
+		var details interface{}
+		if len(event.Details) > 0 {
+			encoded, err := json.Marshal(event.Details)
+			if err != nil {
+				return fmt.Errorf("failed to encode event details: %w", err)
+			}
+			details = string(encoded)
+		}
+
+		if _, err := db.ExecContext(ctx, query, event.ID, event.IncidentID, event.EventType, event.OccurredAt,
+			event.Source, event.Summary, details, event.CreatedAt); err != nil {
+			return fmt.Errorf("failed to save %s event for incident %s: %w", event.EventType, event.IncidentID, err)
+		}
+	}
+	return nil
+}
+
+// IncidentEventService records incident lifecycle events and serves incident timelines
+type IncidentEventService struct {
+	db *sql.DB
+}
+
+// NewIncidentEventService creates a new IncidentEventService instance
+func NewIncidentEventService(db *sql.DB) *IncidentEventService {
+	return &IncidentEventService{db: db}
+}
+
+// RecordEvent stores a single event, such as an edit made through the API
+func (s *IncidentEventService) RecordEvent(ctx context.Context, event IncidentEvent) (*IncidentEvent, error) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	events := []IncidentEvent{event}
+	if err := insertIncidentEvents(ctx, s.db, events); err != nil {
+		return nil, err
+	}
+	return &events[0], nil
+}
+
+// GetTimeline returns the events of an incident in the order they happened, returning
+// sql.ErrNoRows when the incident does not exist
+func (s *IncidentEventService) GetTimeline(ctx context.Context, incidentID string) ([]IncidentEvent, error) {
+	var exists bool
+	scope, scopeArgs := scopeClause(ctx)
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM incidents WHERE id = ?"+scope,
+		append([]interface{}{incidentID}, scopeArgs...)...).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to query incident: %w", err)
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, incident_id, event_type, occurred_at, COALESCE(source, ''), COALESCE(summary, ''),
+			COALESCE(details, ''), created_at
+		FROM incident_events
+		WHERE incident_id = ?
+		ORDER BY occurred_at, `+eventOrder+`, created_at, id
+	`, incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incident events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []IncidentEvent{}
+	for rows.Next() {
+		var event IncidentEvent
+		var details string
+		if err := rows.Scan(&event.ID, &event.IncidentID, &event.EventType, &event.OccurredAt, &event.Source,
+			&event.Summary, &details, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan incident event: %w", err)
+		}
+		if details != "" {
+			if err := json.Unmarshal([]byte(details), &event.Details); err != nil {
+				return nil, fmt.Errorf("failed to decode details of event %s: %w", event.ID, err)
+			}
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
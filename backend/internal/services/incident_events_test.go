@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestIngestionEvents(t *testing.T) {
+	resolved := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+	lastResolved := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+	analyzedAt := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+
+	incident := diffTestIncident("r1", "upload-1", "INC001", "P2", "Closed")
+	incident.ResolveDate = &resolved
+	incident.LastResolveDate = &lastResolved
+	incident.ResolutionNotes = " Restarted the app pool "
+
+	events := IngestionEvents(incident, analyzedAt)
+	expected := []struct {
+		eventType  string
+		occurredAt time.Time
+	}{
+		{EventReported, incident.ReportDate},
+		{EventResolved, resolved},
+		{EventReopened, resolved},
+		{EventResolved, lastResolved},
+		{EventComment, lastResolved},
+		{EventAnalyzed, analyzedAt},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %+v", len(expected), events)
+	}
+	for i, want := range expected {
+		if events[i].EventType != want.eventType || !events[i].OccurredAt.Equal(want.occurredAt) {
+			t.Errorf("Event %d: expected %s at %v, got %s at %v", i, want.eventType, want.occurredAt, events[i].EventType, events[i].OccurredAt)
+		}
+		if events[i].IncidentID != "r1" || events[i].Source != EventSourceIngestion || events[i].Details["upload_id"] != "upload-1" {
+			t.Errorf("Event %d is not attributed to the ingested incident: %+v", i, events[i])
+		}
+	}
+	if events[1].Summary != "Incident resolved by Jane" || events[4].Details["text"] != "Restarted the app pool" {
+		t.Errorf("Unexpected event contents: %+v and %+v", events[1], events[4])
+	}
+	if events[5].Details["sentiment_label"] != models.SentimentNeutral || events[5].Details["automation_feasible"] != nil {
+		t.Errorf("Expected only sentiment output on the analyzed event, got %+v", events[5].Details)
+	}
+
+	open := diffTestIncident("o1", "upload-1", "INC002", "P3", "Open")
+	open.SentimentLabel = ""
+	if events := IngestionEvents(open, analyzedAt); len(events) != 1 || events[0].EventType != EventReported {
+		t.Errorf("Expected only a reported event for an open unanalyzed incident, got %+v", events)
+	}
+}
+
+func TestIncidentEventService_GetTimeline(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+
+	resolved := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	incident := diffTestIncident("r1", "upload-1", "INC001", "P1", "Closed")
+	incident.ResolveDate = &resolved
+	incident.CreatedAt = time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+
+	incidentService := NewIncidentService(db)
+	if _, err := incidentService.BatchInsertIncidents(ctx, []models.Incident{incident}, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+	if _, err := NewFeedbackService(db).RecordFeedback(ctx, "r1", FeedbackAnalyzerSentiment, false, "negative", "angry customer"); err != nil {
+		t.Fatalf("Failed to record feedback: %v", err)
+	}
+
+	service := NewIncidentEventService(db)
+	if _, err := service.RecordEvent(ctx, IncidentEvent{
+		IncidentID: "r1",
+		EventType:  EventEdited,
+		OccurredAt: time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC),
+		Source:     "api",
+		Summary:    "Priority changed",
+		Details:    map[string]interface{}{"priority": "P2"},
+	}); err != nil {
+		t.Fatalf("Failed to record event: %v", err)
+	}
+
+	events, err := service.GetTimeline(ctx, "r1")
+	if err != nil {
+		t.Fatalf("Failed to get timeline: %v", err)
+	}
+	// Reported and resolved share a day, so the lifecycle order breaks the tie
+	expected := []string{EventReported, EventResolved, EventEdited, EventAnalyzed, EventFeedback}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %+v", len(expected), events)
+	}
+	for i, eventType := range expected {
+		if events[i].EventType != eventType {
+			t.Errorf("Event %d: expected %s, got %s", i, eventType, events[i].EventType)
+		}
+	}
+	feedback := events[4]
+	if feedback.Summary != "sentiment output marked incorrect" || feedback.Details["actual"] != "negative" || feedback.Details["comment"] != "angry customer" {
+		t.Errorf("Unexpected feedback event: %+v", feedback)
+	}
+	if events[2].Details["priority"] != "P2" {
+		t.Errorf("Expected edit details to round-trip, got %+v", events[2].Details)
+	}
+
+	if _, err := service.GetTimeline(ctx, "missing"); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for an unknown incident, got %v", err)
+	}
+
+	if err := incidentService.DeleteIncidentsByUpload(ctx, "upload-1"); err != nil {
+		t.Fatalf("Failed to delete incidents: %v", err)
+	}
+	var remaining int
+	if err := db.QueryRow("SELECT COUNT(*) FROM incident_events").Scan(&remaining); err != nil {
+		t.Fatalf("Failed to count events: %v", err)
+	}
+	if remaining != 0 {
+		t.Errorf("Expected events to be deleted with their incidents, got %d", remaining)
+	}
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Incident export formats
+const (
+	ExportFormatParquet = "parquet"
+)
+
+// exportColumns are the incident columns written to exports. Scores are widened from FLOAT to
+// DOUBLE, which data-science tools read without precision surprises.
+const exportColumns = `
+	id, incident_id, upload_id, dataset_id, report_date, resolve_date, last_resolve_date,
+	brief_description, description, application_name, application_name_raw, resolution_group,
+	resolved_person, priority, category, subcategory, impact, urgency, status, customer_affected,
+	business_service, business_service_raw, root_cause, resolution_notes, cost,
+	CAST(sentiment_score AS DOUBLE) AS sentiment_score, sentiment_label, sentiment_version,
+	resolution_time_hours, pending_hours, net_resolution_time_hours, CAST(automation_score AS DOUBLE) AS automation_score,
+	automation_feasible, it_process_group, automation_version, source, closure_code, region,
+	cmdb_owner, cmdb_environment, cmdb_criticality, created_at, updated_at`
+
+// IncidentExport describes a finished export file
+type IncidentExport struct {
+	ID        string           `json:"id"`
+	Format    string           `json:"format"`
+	Rows      int              `json:"rows"`
+	SizeBytes int64            `json:"size_bytes"`
+	Filters   *TimelineFilters `json:"filters,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// IncidentExportService writes incident data to files for download
+type IncidentExportService struct {
+	db  *sql.DB
+	dir string
+}
+
+// NewIncidentExportService creates an export service writing files to dir, creating it if needed
+func NewIncidentExportService(db *sql.DB, dir string) (*IncidentExportService, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve export directory: %w", err)
+	}
+	return &IncidentExportService{db: db, dir: absDir}, nil
+}
+
+// IsValidExportFormat reports whether format is a supported export format
+func IsValidExportFormat(format string) bool {
+	return format == ExportFormatParquet
+}
+
+// Export writes the incidents matching filters to a new export file. DuckDB streams the query
+// result straight into the file, so large exports are never held in memory.
+func (s *IncidentExportService) Export(ctx context.Context, filters *TimelineFilters, format string) (*IncidentExport, error) {
+	if !IsValidExportFormat(format) {
+		return nil, fmt.Errorf("invalid export format: %s", format)
+	}
+
+	export := &IncidentExport{ID: uuid.New().String(), Format: format, Filters: filters}
+	path := s.path(export.ID, format)
+	temp := path + ".tmp"
+
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query := fmt.Sprintf("COPY (SELECT %s FROM incidents WHERE 1=1%s ORDER BY report_date, incident_id) TO %s (FORMAT PARQUET, COMPRESSION ZSTD)",
+		exportColumns, whereClause, sqlString(temp))
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		os.Remove(temp)
+		return nil, fmt.Errorf("failed to write export: %w", err)
+	}
+	if err := os.Rename(temp, path); err != nil {
+		os.Remove(temp)
+		return nil, fmt.Errorf("failed to finish export: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat export: %w", err)
+	}
+	export.SizeBytes = info.Size()
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM read_parquet("+sqlString(path)+")").Scan(&export.Rows); err != nil {
+		return nil, fmt.Errorf("failed to count exported rows: %w", err)
+	}
+	export.CreatedAt = time.Now()
+
+	return export, nil
+}
+
+// FilePath returns the file of a finished export, or os.ErrNotExist when there is none
+func (s *IncidentExportService) FilePath(export *IncidentExport) (string, error) {
+	if export == nil {
+		return "", os.ErrNotExist
+	}
+	path := s.path(export.ID, export.Format)
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// path returns the file an export is written to
+func (s *IncidentExportService) path(id, format string) string {
+	return filepath.Join(s.dir, id+"."+format)
+}
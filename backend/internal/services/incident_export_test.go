@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestIncidentExportService_ExportParquet(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+
+	score := 0.75
+	var incidents []models.Incident
+	for i, priority := range []string{"P1", "P2", "P2"} {
+		incident := diffTestIncident(fmt.Sprintf("e%d", i), "upload-1", fmt.Sprintf("INC%03d", i), priority, "Closed")
+		incident.SentimentScore = &score
+		incidents = append(incidents, incident)
+	}
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	service, err := NewIncidentExportService(db, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create export service: %v", err)
+	}
+
+	if _, err := service.Export(ctx, nil, "xlsx"); err == nil {
+		t.Error("Expected an unsupported format to be rejected")
+	}
+
+	export, err := service.Export(ctx, &TimelineFilters{Priorities: []string{"P2"}}, ExportFormatParquet)
+	if err != nil {
+		t.Fatalf("Failed to export incidents: %v", err)
+	}
+	if export.Rows != 2 || export.SizeBytes == 0 {
+		t.Errorf("Expected 2 exported P2 incidents, got %+v", export)
+	}
+
+	path, err := service.FilePath(export)
+	if err != nil {
+		t.Fatalf("Expected the export file to exist: %v", err)
+	}
+	var firstID, reportType, scoreType string
+	if err := db.QueryRow(`SELECT incident_id, typeof(report_date), typeof(sentiment_score) FROM read_parquet(`+sqlString(path)+`) LIMIT 1`).
+		Scan(&firstID, &reportType, &scoreType); err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if firstID != "INC001" || reportType != "DATE" || scoreType != "DOUBLE" {
+		t.Errorf("Expected typed, ordered columns, got %s, %s and %s", firstID, reportType, scoreType)
+	}
+
+	t.Run("through the job queue", func(t *testing.T) {
+		jobQueue := NewJobQueue(JobQueueConfig{Workers: 1}, NewProcessingService(db, nil))
+		defer jobQueue.Shutdown()
+		jobQueue.SetExportService(service)
+
+		submitted, err := jobQueue.SubmitJob(JobTypeExportIncidents, "", map[string]interface{}{"format": ExportFormatParquet})
+		if err != nil {
+			t.Fatalf("Failed to submit export job: %v", err)
+		}
+		job, _ := jobQueue.JobSnapshot(submitted.ID)
+
+		deadline := time.Now().Add(5 * time.Second)
+		for job.Status != JobStatusCompleted && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+			job, _ = jobQueue.JobSnapshot(job.ID)
+		}
+		if job.Status != JobStatusCompleted {
+			t.Fatalf("Expected the export job to complete, got %s: %s", job.Status, job.Error)
+		}
+		if result, ok := job.Result.(*IncidentExport); !ok || result.Rows != 3 {
+			t.Errorf("Expected all 3 incidents exported, got %+v", job.Result)
+		}
+	})
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// canonicalIncident matches the incidents analytics count: those not merged into another.
+// Incidents archived before merging existed have no flag and are canonical. The column is
+// unqualified so the condition works whatever alias the surrounding query gives the incidents table.
+const canonicalIncident = "COALESCE(is_canonical, TRUE)"
+
+// EventMerged is the incident event type recorded when duplicates are merged into an incident or
+// a merge is undone
+const EventMerged = "merged"
+
+// EventSourceMerge records incident events written by merging incidents
+const EventSourceMerge = "merge"
+
+// ErrInvalidMerge is returned when incidents cannot be merged or a merge cannot be undone
+var ErrInvalidMerge = errors.New("invalid incident merge")
+
+// IncidentMerge records duplicates of one outage merged into a primary incident. The duplicates
+// keep their data but are excluded from analytics until the merge is undone.
+type IncidentMerge struct {
+	ID           string     `json:"id"`
+	PrimaryID    string     `json:"primary_id"`
+	DuplicateIDs []string   `json:"duplicate_ids"`
+	Reason       string     `json:"reason,omitempty"`
+	MergedBy     string     `json:"merged_by,omitempty"`
+	MergedAt     time.Time  `json:"merged_at"`
+	UndoneBy     string     `json:"undone_by,omitempty"`
+	UndoneAt     *time.Time `json:"undone_at,omitempty"`
+}
+
+// Active reports whether the merge has not been undone
+func (m *IncidentMerge) Active() bool {
+	return m.UndoneAt == nil
+}
+
+// IncidentMergeService merges duplicate incidents into a primary incident and undoes merges
+type IncidentMergeService struct {
+	db *sql.DB
+}
+
+// NewIncidentMergeService creates a new IncidentMergeService instance
+func NewIncidentMergeService(db *sql.DB) *IncidentMergeService {
+	return &IncidentMergeService{db: db}
+}
+
+// Merge marks the duplicates as merged into the primary incident, so only the primary is
+// counted. Every incident must exist in the caller's data scope, returning an error wrapping
+// sql.ErrNoRows otherwise, and must not already be merged; a duplicate cannot be the primary of
+// an active merge either, so merges never chain.
+func (s *IncidentMergeService) Merge(ctx context.Context, primaryID string, duplicateIDs []string, reason, mergedBy string) (*IncidentMerge, error) {
+	duplicates := uniqueSorted(duplicateIDs)
+	if len(duplicates) == 0 {
+		return nil, fmt.Errorf("%w: at least one duplicate is required", ErrInvalidMerge)
+	}
+	if containsString(duplicates, primaryID) {
+		return nil, fmt.Errorf("%w: incident %s cannot be merged into itself", ErrInvalidMerge, primaryID)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range append([]string{primaryID}, duplicates...) {
+		mergedInto, err := mergedIncident(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if mergedInto != "" {
+			return nil, fmt.Errorf("%w: incident %s is already merged into %s", ErrInvalidMerge, id, mergedInto)
+		}
+	}
+	for _, id := range duplicates {
+		var primaryOf int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM incident_merges WHERE primary_id = ? AND undone_at IS NULL",
+			id).Scan(&primaryOf); err != nil {
+			return nil, fmt.Errorf("failed to check merges of incident %s: %w", id, err)
+		}
+		if primaryOf > 0 {
+			return nil, fmt.Errorf("%w: incident %s has duplicates merged into it; undo that merge first", ErrInvalidMerge, id)
+		}
+	}
+
+	merge := &IncidentMerge{
+		ID:           uuid.New().String(),
+		PrimaryID:    primaryID,
+		DuplicateIDs: duplicates,
+		Reason:       strings.TrimSpace(reason),
+		MergedBy:     mergedBy,
+		MergedAt:     time.Now(),
+	}
+	encoded, err := json.Marshal(merge.DuplicateIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode duplicates: %w", err)
+	}
+
+	placeholders, args := mergeArgs(duplicates)
+	if _, err := tx.ExecContext(ctx, `UPDATE incidents SET is_canonical = FALSE, merged_into = ? WHERE id IN (`+placeholders+`)`,
+		append([]interface{}{primaryID}, args...)...); err != nil {
+		return nil, fmt.Errorf("failed to mark duplicates: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO incident_merges (id, primary_id, duplicate_ids, reason, merged_by, merged_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, merge.ID, merge.PrimaryID, string(encoded), nullIfEmpty(merge.Reason), nullIfEmpty(merge.MergedBy), merge.MergedAt); err != nil {
+		return nil, fmt.Errorf("failed to save incident merge: %w", err)
+	}
+
+	events := []IncidentEvent{{
+		IncidentID: primaryID,
+		Summary:    fmt.Sprintf("%d duplicate incident(s) merged into this incident", len(duplicates)),
+		Details:    map[string]interface{}{"merge_id": merge.ID, "duplicate_ids": duplicates},
+	}}
+	for _, id := range duplicates {
+		events = append(events, IncidentEvent{
+			IncidentID: id,
+			Summary:    "Merged as a duplicate of another incident",
+			Details:    map[string]interface{}{"merge_id": merge.ID, "primary_id": primaryID},
+		})
+	}
+	if err := insertIncidentEvents(ctx, tx, mergeEvents(events, merge.MergedAt, mergedBy, merge.Reason)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit incident merge: %w", err)
+	}
+	return merge, nil
+}
+
+// Undo returns the duplicates of a merge to the counted incidents. The merge is kept, marked
+// undone. It returns sql.ErrNoRows when the merge does not exist and ErrInvalidMerge when it was
+// undone already.
+func (s *IncidentMergeService) Undo(ctx context.Context, mergeID, undoneBy string) (*IncidentMerge, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	merge, err := getIncidentMerge(ctx, tx, mergeID)
+	if err != nil {
+		return nil, err
+	}
+	if !merge.Active() {
+		return nil, fmt.Errorf("%w: merge %s was undone at %s", ErrInvalidMerge, mergeID, merge.UndoneAt.Format(time.RFC3339))
+	}
+
+	now := time.Now()
+	placeholders, args := mergeArgs(merge.DuplicateIDs)
+	if _, err := tx.ExecContext(ctx, `UPDATE incidents SET is_canonical = TRUE, merged_into = NULL WHERE merged_into = ? AND id IN (`+placeholders+`)`,
+		append([]interface{}{merge.PrimaryID}, args...)...); err != nil {
+		return nil, fmt.Errorf("failed to restore duplicates: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE incident_merges SET undone_by = ?, undone_at = ? WHERE id = ?",
+		nullIfEmpty(undoneBy), now, mergeID); err != nil {
+		return nil, fmt.Errorf("failed to mark merge undone: %w", err)
+	}
+
+	events := []IncidentEvent{{
+		IncidentID: merge.PrimaryID,
+		Summary:    "Merge of duplicate incidents undone",
+		Details:    map[string]interface{}{"merge_id": merge.ID, "duplicate_ids": merge.DuplicateIDs},
+	}}
+	for _, id := range merge.DuplicateIDs {
+		events = append(events, IncidentEvent{
+			IncidentID: id,
+			Summary:    "Split from the incident it was merged into",
+			Details:    map[string]interface{}{"merge_id": merge.ID, "primary_id": merge.PrimaryID},
+		})
+	}
+	if err := insertIncidentEvents(ctx, tx, mergeEvents(events, now, undoneBy, "")); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit undoing merge: %w", err)
+	}
+	merge.UndoneBy = undoneBy
+	merge.UndoneAt = &now
+	return merge, nil
+}
+
+// GetMerge returns a merge, or sql.ErrNoRows when it does not exist
+func (s *IncidentMergeService) GetMerge(ctx context.Context, mergeID string) (*IncidentMerge, error) {
+	return getIncidentMerge(ctx, s.db, mergeID)
+}
+
+// ListMerges returns the merges an incident in the caller's data scope took part in, as primary
+// or duplicate, newest first. It returns sql.ErrNoRows when the incident does not exist.
+func (s *IncidentMergeService) ListMerges(ctx context.Context, incidentID string) ([]IncidentMerge, error) {
+	scope, scopeArgs := scopeClause(ctx)
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM incidents WHERE id = ?"+scope,
+		append([]interface{}{incidentID}, scopeArgs...)...).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to query incident: %w", err)
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	// Duplicates are stored as a JSON list, in which each ID appears quoted
+	quoted, _ := json.Marshal(incidentID)
+	rows, err := s.db.QueryContext(ctx, "SELECT "+incidentMergeColumns+` FROM incident_merges
+		WHERE primary_id = ? OR contains(duplicate_ids, ?)
+		ORDER BY merged_at DESC, id`, incidentID, string(quoted))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incident merges: %w", err)
+	}
+	defer rows.Close()
+
+	merges := []IncidentMerge{}
+	for rows.Next() {
+		merge, err := scanIncidentMerge(rows)
+		if err != nil {
+			return nil, err
+		}
+		merges = append(merges, *merge)
+	}
+	return merges, rows.Err()
+}
+
+// incidentMergeColumns are the columns scanIncidentMerge reads
+const incidentMergeColumns = `id, primary_id, duplicate_ids, COALESCE(reason, ''), COALESCE(merged_by, ''), merged_at,
+	COALESCE(undone_by, ''), undone_at`
+
+// mergeQuerier is satisfied by both *sql.DB and *sql.Tx
+type mergeQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// getIncidentMerge reads one merge, returning sql.ErrNoRows when it does not exist
+func getIncidentMerge(ctx context.Context, db mergeQuerier, mergeID string) (*IncidentMerge, error) {
+	return scanIncidentMerge(db.QueryRowContext(ctx, "SELECT "+incidentMergeColumns+" FROM incident_merges WHERE id = ?", mergeID))
+}
+
+// scanIncidentMerge reads a merge selected with incidentMergeColumns
+func scanIncidentMerge(row interface{ Scan(...interface{}) error }) (*IncidentMerge, error) {
+	var merge IncidentMerge
+	var duplicates string
+	var undoneAt sql.NullTime
+	if err := row.Scan(&merge.ID, &merge.PrimaryID, &duplicates, &merge.Reason, &merge.MergedBy, &merge.MergedAt,
+		&merge.UndoneBy, &undoneAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan incident merge: %w", err)
+	}
+	if err := json.Unmarshal([]byte(duplicates), &merge.DuplicateIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode duplicates of merge %s: %w", merge.ID, err)
+	}
+	if undoneAt.Valid {
+		merge.UndoneAt = &undoneAt.Time
+	}
+	return &merge, nil
+}
+
+// mergedIncident returns the incident an incident in the caller's data scope is merged into, or
+// "" when it is canonical, with an error wrapping sql.ErrNoRows when it does not exist
+func mergedIncident(ctx context.Context, tx *sql.Tx, incidentID string) (string, error) {
+	scope, scopeArgs := scopeClause(ctx)
+	var mergedInto string
+	err := tx.QueryRowContext(ctx, "SELECT COALESCE(merged_into, '') FROM incidents WHERE id = ?"+scope,
+		append([]interface{}{incidentID}, scopeArgs...)...).Scan(&mergedInto)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("incident not found: %s: %w", incidentID, err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query incident %s: %w", incidentID, err)
+	}
+	return mergedInto, nil
+}
+
+// mergeArgs returns the placeholders and arguments of an IN list of incident IDs
+func mergeArgs(ids []string) (string, []interface{}) {
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	return strings.Join(placeholders, ", "), args
+}
+
+// mergeEvents completes the timeline events of a merge or its undoing
+func mergeEvents(events []IncidentEvent, at time.Time, user, reason string) []IncidentEvent {
+	for i := range events {
+		events[i].EventType = EventMerged
+		events[i].OccurredAt = at
+		events[i].Source = EventSourceMerge
+		if user != "" {
+			events[i].Details["user"] = user
+		}
+		if reason != "" {
+			events[i].Details["reason"] = reason
+		}
+	}
+	return events
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestIncidentMergeService(t *testing.T) {
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	service := NewIncidentMergeService(db)
+	analytics := NewAnalyticsService(db)
+	ctx := context.Background()
+
+	// One P1 outage was ticketed three times
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P1", "Closed"),
+		diffTestIncident("i2", "upload-1", "INC002", "P1", "Closed"),
+		diffTestIncident("i3", "upload-1", "INC003", "P1", "Closed"),
+		diffTestIncident("i4", "upload-1", "INC004", "P3", "Open"),
+	}
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+	p1Count := func() int {
+		t.Helper()
+		priorities, err := analytics.GetPriorityAnalysis(ctx, nil)
+		if err != nil {
+			t.Fatalf("GetPriorityAnalysis() error = %v", err)
+		}
+		for _, priority := range priorities {
+			if priority.Priority == "P1" {
+				return priority.Count
+			}
+		}
+		return 0
+	}
+
+	merge, err := service.Merge(ctx, "i1", []string{"i3", "i2", "i2"}, " Same outage ", "alice")
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if len(merge.DuplicateIDs) != 2 || merge.DuplicateIDs[0] != "i2" || merge.Reason != "Same outage" || !merge.Active() {
+		t.Errorf("expected a merge of i2 and i3 with a trimmed reason, got %+v", merge)
+	}
+	if count := p1Count(); count != 1 {
+		t.Errorf("expected merged duplicates not to be counted, got %d P1 incidents", count)
+	}
+
+	t.Run("invalid merges", func(t *testing.T) {
+		cases := map[string]struct {
+			primary    string
+			duplicates []string
+		}{
+			"no duplicates":          {"i4", nil},
+			"into itself":            {"i4", []string{"i4"}},
+			"duplicate merged":       {"i4", []string{"i2"}},
+			"primary merged":         {"i2", []string{"i4"}},
+			"primary of other merge": {"i4", []string{"i1"}},
+		}
+		for name, tc := range cases {
+			if _, err := service.Merge(ctx, tc.primary, tc.duplicates, "", ""); !errors.Is(err, ErrInvalidMerge) {
+				t.Errorf("%s: expected ErrInvalidMerge, got %v", name, err)
+			}
+		}
+		if _, err := service.Merge(ctx, "i4", []string{"missing"}, "", ""); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected a missing incident error, got %v", err)
+		}
+		scoped := WithDataScope(ctx, &DataScope{Applications: []string{"Elsewhere"}})
+		if _, err := service.Merge(scoped, "i4", []string{"i1"}, "", ""); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected incidents outside the scope to be missing, got %v", err)
+		}
+	})
+
+	merges, err := service.ListMerges(ctx, "i3")
+	if err != nil {
+		t.Fatalf("ListMerges() error = %v", err)
+	}
+	if len(merges) != 1 || merges[0].ID != merge.ID {
+		t.Errorf("expected the merge listed for its duplicate, got %+v", merges)
+	}
+	if _, err := service.ListMerges(ctx, "missing"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for a missing incident, got %v", err)
+	}
+
+	timeline, err := NewIncidentEventService(db).GetTimeline(ctx, "i2")
+	if err != nil {
+		t.Fatalf("GetTimeline() error = %v", err)
+	}
+	if last := timeline[len(timeline)-1]; last.EventType != EventMerged || last.Details["primary_id"] != "i1" || last.Details["user"] != "alice" {
+		t.Errorf("expected the merge on the duplicate's timeline, got %+v", last)
+	}
+
+	undone, err := service.Undo(ctx, merge.ID, "bob")
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if undone.Active() || undone.UndoneBy != "bob" {
+		t.Errorf("expected the merge to be undone by bob, got %+v", undone)
+	}
+	if count := p1Count(); count != 3 {
+		t.Errorf("expected the duplicates to be counted again, got %d P1 incidents", count)
+	}
+	if _, err := service.Undo(ctx, merge.ID, "bob"); !errors.Is(err, ErrInvalidMerge) {
+		t.Errorf("expected undoing twice to fail, got %v", err)
+	}
+	if _, err := service.Undo(ctx, "missing", "bob"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for a missing merge, got %v", err)
+	}
+
+	// Deleting the primary with its upload returns its duplicates to the counts
+	other := diffTestIncident("o1", "upload-2", "INC100", "P1", "Closed")
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, []models.Incident{other}, "upload-2"); err != nil {
+		t.Fatalf("Failed to insert incident: %v", err)
+	}
+	again, err := service.Merge(ctx, "o1", []string{"i1"}, "", "")
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if err := NewIncidentService(db).DeleteIncidentsByUpload(ctx, "upload-2"); err != nil {
+		t.Fatalf("DeleteIncidentsByUpload() error = %v", err)
+	}
+	if count := p1Count(); count != 3 {
+		t.Errorf("expected the duplicate of a deleted incident to be counted, got %d P1 incidents", count)
+	}
+	if stored, err := service.GetMerge(ctx, again.ID); err != nil || stored.Active() {
+		t.Errorf("expected the merge into the deleted incident to be undone, got %+v (%v)", stored, err)
+	}
+}
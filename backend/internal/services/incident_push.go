@@ -0,0 +1,228 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// MaxPushedIncidents caps the incidents accepted in one pushed batch
+const MaxPushedIncidents = 10000
+
+// pushExistingChunk is the number of incident IDs looked up per duplicate query
+const pushExistingChunk = 500
+
+// ErrInvalidPush is returned when a pushed batch is empty, too large or has a malformed incident
+var ErrInvalidPush = errors.New("invalid incident batch")
+
+// PushedIncident is one incident sent to the push API, keyed by incident field as in a
+// spreadsheet header. Values are JSON strings, numbers, booleans or null.
+type PushedIncident map[string]json.RawMessage
+
+// PushSkip is a pushed incident left out of the batch's upload
+type PushSkip struct {
+	Index      int    `json:"index"` // Position in the pushed array, from 0
+	IncidentID string `json:"incident_id"`
+	Reason     string `json:"reason"`
+}
+
+// PushResult describes what was accepted from a pushed batch
+type PushResult struct {
+	Upload   *models.Upload `json:"upload,omitempty"` // Nil when every incident was skipped
+	Accepted int            `json:"accepted"`
+	Skipped  []PushSkip     `json:"skipped"`
+}
+
+// IncidentPushService accepts incidents pushed by other systems. Each batch is written to an
+// Excel snapshot and stored as an upload, so it is validated, deduplicated, analyzed and audited
+// exactly like a file upload.
+type IncidentPushService struct {
+	db        *sql.DB
+	fileStore *storage.FileStore
+}
+
+// NewIncidentPushService creates a new IncidentPushService instance
+func NewIncidentPushService(db *sql.DB, fileStore *storage.FileStore) *IncidentPushService {
+	return &IncidentPushService{
+		db:        db,
+		fileStore: fileStore,
+	}
+}
+
+// PushIncidents stores a batch as a new upload ready to be processed. Incidents whose ID is
+// already stored are skipped, so a sender can safely retry a batch. source names the sending
+// system in the upload's filename.
+func (s *IncidentPushService) PushIncidents(ctx context.Context, incidents []PushedIncident, source string) (*PushResult, error) {
+	if len(incidents) == 0 {
+		return nil, fmt.Errorf("%w: no incidents", ErrInvalidPush)
+	}
+	if len(incidents) > MaxPushedIncidents {
+		return nil, fmt.Errorf("%w: %d incidents, at most %d are accepted per batch", ErrInvalidPush, len(incidents), MaxPushedIncidents)
+	}
+
+	records := make([]map[string]string, len(incidents))
+	fieldSet := make(map[string]bool)
+	for i, incident := range incidents {
+		record, err := pushedRecord(incident)
+		if err != nil {
+			return nil, fmt.Errorf("%w: incident %d: %v", ErrInvalidPush, i, err)
+		}
+		records[i] = record
+		for field := range record {
+			fieldSet[field] = true
+		}
+	}
+
+	existing, err := s.existingIncidentIDs(ctx, records)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PushResult{Skipped: []PushSkip{}}
+	kept := make([]map[string]string, 0, len(records))
+	for i, record := range records {
+		if id := record["incident_id"]; existing[id] {
+			result.Skipped = append(result.Skipped, PushSkip{Index: i, IncidentID: id, Reason: "incident ID already imported"})
+			continue
+		}
+		kept = append(kept, record)
+	}
+	if len(kept) == 0 {
+		return result, nil
+	}
+
+	// The header holds the canonical field names, which the parser maps to themselves
+	fields := make([]string, 0, len(fieldSet))
+	for field := range fieldSet {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	rows := make([][]string, 0, len(kept)+1)
+	rows = append(rows, fields)
+	for _, record := range kept {
+		row := make([]string, len(fields))
+		for j, field := range fields {
+			row[j] = record[field]
+		}
+		rows = append(rows, row)
+	}
+
+	now := time.Now()
+	originalFilename := pushFilename(source, now)
+	filename, err := s.fileStore.SaveGeneratedFile(originalFilename, func(w io.Writer) error {
+		return writeSheetWorkbook(w, rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &models.Upload{
+		ID:               uuid.New().String(),
+		Filename:         filename,
+		OriginalFilename: originalFilename,
+		Status:           models.UploadStatusUploaded,
+		Errors:           []string{},
+		CreatedAt:        now,
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO uploads (id, filename, original_filename, status, record_count,
+			processed_count, error_count, errors, created_at)
+		VALUES (?, ?, ?, ?, 0, 0, 0, '[]', ?)
+	`, upload.ID, upload.Filename, upload.OriginalFilename, upload.Status, upload.CreatedAt); err != nil {
+		s.fileStore.DeleteFile(filename)
+		return nil, fmt.Errorf("failed to create upload record: %w", err)
+	}
+
+	result.Upload = upload
+	result.Accepted = len(kept)
+	return result, nil
+}
+
+// pushedRecord converts a pushed incident to the cell text a spreadsheet row would hold.
+// Numbers keep their exact digits, so numeric incident IDs are not rounded.
+func pushedRecord(incident PushedIncident) (map[string]string, error) {
+	record := make(map[string]string, len(incident))
+	for field, raw := range incident {
+		if !IsMappableField(field) {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		value := bytes.TrimSpace(raw)
+		switch {
+		case len(value) == 0 || string(value) == "null":
+			record[field] = ""
+		case value[0] == '"':
+			var text string
+			if err := json.Unmarshal(value, &text); err != nil {
+				return nil, fmt.Errorf("field %s: %v", field, err)
+			}
+			record[field] = strings.TrimSpace(text)
+		case value[0] == '{' || value[0] == '[':
+			return nil, fmt.Errorf("field %s must be a string, number or boolean", field)
+		default:
+			// Numbers and booleans
+			record[field] = string(value)
+		}
+	}
+	return record, nil
+}
+
+// existingIncidentIDs returns the IDs among records that are already stored
+func (s *IncidentPushService) existingIncidentIDs(ctx context.Context, records []map[string]string) (map[string]bool, error) {
+	ids := make([]interface{}, 0, len(records))
+	seen := make(map[string]bool)
+	for _, record := range records {
+		if id := record["incident_id"]; id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	existing := make(map[string]bool)
+	for start := 0; start < len(ids); start += pushExistingChunk {
+		end := start + pushExistingChunk
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunk := ids[start:end]
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(chunk)), ", ")
+		rows, err := s.db.QueryContext(ctx,
+			"SELECT DISTINCT incident_id FROM incidents WHERE incident_id IN ("+placeholders+")", chunk...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up existing incidents: %w", err)
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan existing incident: %w", err)
+			}
+			existing[id] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read existing incidents: %w", err)
+		}
+	}
+	return existing, nil
+}
+
+// pushFilename names the snapshot of a pushed batch after its source and arrival time
+func pushFilename(source string, at time.Time) string {
+	if strings.TrimSpace(source) == "" {
+		source = "api"
+	}
+	return strings.TrimSuffix(sheetFilename(source), ".xlsx") + "-push-" + at.UTC().Format("20060102-150405") + ".xlsx"
+}
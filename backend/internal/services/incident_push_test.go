@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/storage"
+)
+
+func TestIncidentPushService_PushIncidents(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	fileStore := storage.NewFileStore(t.TempDir())
+	service := NewIncidentPushService(db, fileStore)
+	ctx := context.Background()
+
+	decode := func(body string) []PushedIncident {
+		var incidents []PushedIncident
+		if err := json.Unmarshal([]byte(body), &incidents); err != nil {
+			t.Fatalf("Failed to decode incidents: %v", err)
+		}
+		return incidents
+	}
+
+	invalid := []string{
+		`[]`,
+		`[{"incident_id": "INC1", "colour": "red"}]`,
+		`[{"incident_id": "INC1", "priority": {"level": 1}}]`,
+	}
+	for _, body := range invalid {
+		if _, err := service.PushIncidents(ctx, decode(body), "monitor"); !errors.Is(err, ErrInvalidPush) {
+			t.Errorf("PushIncidents(%s) error = %v, want ErrInvalidPush", body, err)
+		}
+	}
+
+	batch := `[
+		{"incident_id": 12345678901234567, "report_date": "2024-03-01", "priority": "P2", "status": "Closed",
+		 "brief_description": "Database is down again, users are frustrated", "application_name": "Billing"},
+		{"incident_id": "INC200", "report_date": "2024-03-02T10:00:00Z", "priority": "P3", "status": "Open",
+		 "brief_description": null, "resolve_date": "2024-03-03"}
+	]`
+	result, err := service.PushIncidents(ctx, decode(batch), "monitor")
+	if err != nil {
+		t.Fatalf("PushIncidents() error = %v", err)
+	}
+	if result.Upload == nil || result.Accepted != 2 || len(result.Skipped) != 0 {
+		t.Fatalf("unexpected push result %+v", result)
+	}
+	if !strings.HasPrefix(result.Upload.OriginalFilename, "monitor-push-") {
+		t.Errorf("expected the upload to be named after its source, got %s", result.Upload.OriginalFilename)
+	}
+
+	// The snapshot is processed like any upload
+	progress, err := NewProcessingService(db, fileStore).ProcessUpload(ctx, result.Upload.ID)
+	if err != nil {
+		t.Fatalf("ProcessUpload() error = %v", err)
+	}
+	if progress.Status != models.UploadStatusCompleted || progress.ProcessedRows != 2 {
+		t.Fatalf("unexpected processing result %+v", progress)
+	}
+
+	var priority string
+	var sentimentLabel *string
+	if err := db.QueryRowContext(ctx, `
+		SELECT priority, sentiment_label FROM incidents WHERE incident_id = '12345678901234567'
+	`).Scan(&priority, &sentimentLabel); err != nil {
+		t.Fatalf("Expected the numeric incident ID to be stored exactly: %v", err)
+	}
+	if priority != "P2" || sentimentLabel == nil || *sentimentLabel == "" {
+		t.Errorf("expected the pushed incident to be analyzed, got priority %s, sentiment %v", priority, sentimentLabel)
+	}
+
+	// Resending the batch with one new incident only imports the new one
+	resent := strings.Replace(batch, `"INC200"`, `"INC201"`, 1)
+	result, err = service.PushIncidents(ctx, decode(resent), "monitor")
+	if err != nil {
+		t.Fatalf("PushIncidents() error = %v", err)
+	}
+	if result.Upload == nil || result.Accepted != 1 || len(result.Skipped) != 1 ||
+		result.Skipped[0].Index != 0 || result.Skipped[0].IncidentID != "12345678901234567" {
+		t.Errorf("unexpected result of a resent batch %+v", result)
+	}
+
+	// A batch of already imported incidents creates no upload
+	result, err = service.PushIncidents(ctx, decode(`[{"incident_id": "INC200"}]`), "")
+	if err != nil {
+		t.Fatalf("PushIncidents() error = %v", err)
+	}
+	if result.Upload != nil || result.Accepted != 0 || len(result.Skipped) != 1 {
+		t.Errorf("expected every incident to be skipped, got %+v", result)
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"incident-management-system/internal/models"
+)
+
+// Sample strata
+const (
+	SampleByPriority    = "priority"
+	SampleByApplication = "application"
+)
+
+// Incident sample defaults
+const (
+	DefaultSampleSize = 50
+	MaxSampleSize     = 500
+)
+
+// sampleStrataColumns maps each stratum kind to the incident column it groups by
+var sampleStrataColumns = map[string]string{
+	SampleByPriority:    "priority",
+	SampleByApplication: "COALESCE(application_name, '')",
+}
+
+// SampleOptions controls how incidents are sampled for review
+type SampleOptions struct {
+	// Size is how many incidents to sample
+	Size int
+	// StratifyBy is SampleByPriority (default) or SampleByApplication
+	StratifyBy string
+	// Seed makes the sample reproducible; nil picks a random seed
+	Seed *int64
+}
+
+// SampleStratum is how many incidents of one priority or application matched and were sampled
+type SampleStratum struct {
+	Value      string `json:"value"`
+	Population int    `json:"population"`
+	Sampled    int    `json:"sampled"`
+}
+
+// IncidentSample is a stratified random sample of the incidents matching the filters. Requesting
+// the same seed again returns the same incidents as long as the data is unchanged.
+type IncidentSample struct {
+	Seed       int64             `json:"seed"`
+	StratifyBy string            `json:"stratify_by"`
+	Population int               `json:"population"`
+	Size       int               `json:"size"`
+	Strata     []SampleStratum   `json:"strata"`
+	Incidents  []models.Incident `json:"incidents"`
+}
+
+// withDefaults fills unset sample options
+func (o SampleOptions) withDefaults() SampleOptions {
+	if o.Size <= 0 {
+		o.Size = DefaultSampleSize
+	}
+	if o.Size > MaxSampleSize {
+		o.Size = MaxSampleSize
+	}
+	if o.StratifyBy == "" {
+		o.StratifyBy = SampleByPriority
+	}
+	if o.Seed == nil {
+		seed := rand.Int63()
+		o.Seed = &seed
+	}
+	return o
+}
+
+// SampleIncidents draws a random sample of the incidents matching the filters, split across
+// priorities or applications in proportion to their volume so QA can audit a manageable subset.
+// Every stratum is represented when the sample is large enough. Within a stratum incidents are
+// ordered by a hash of their ID and the seed, so a seed always picks the same incidents.
+func (s *IncidentService) SampleIncidents(ctx context.Context, filters *TimelineFilters, opts SampleOptions) (*IncidentSample, error) {
+	opts = opts.withDefaults()
+	stratum, ok := sampleStrataColumns[opts.StratifyBy]
+	if !ok {
+		return nil, fmt.Errorf("invalid sample stratum: %s", opts.StratifyBy)
+	}
+
+	whereClause, args, nextIdx := buildFilterConditions(ctx, filters, 1)
+	countQuery := fmt.Sprintf(`
+		SELECT %s as stratum, COUNT(*)
+		FROM incidents
+		WHERE 1=1%s
+		GROUP BY stratum
+		ORDER BY stratum`, stratum, whereClause)
+
+	rows, err := s.db.QueryContext(ctx, countQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count incidents for sample: %w", err)
+	}
+	defer rows.Close()
+
+	sample := &IncidentSample{
+		Seed:       *opts.Seed,
+		StratifyBy: opts.StratifyBy,
+		Strata:     []SampleStratum{},
+		Incidents:  []models.Incident{},
+	}
+	for rows.Next() {
+		var entry SampleStratum
+		if err := rows.Scan(&entry.Value, &entry.Population); err != nil {
+			return nil, fmt.Errorf("failed to scan sample stratum: %w", err)
+		}
+		sample.Population += entry.Population
+		sample.Strata = append(sample.Strata, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sample strata: %w", err)
+	}
+
+	allocateSample(sample.Strata, opts.Size)
+
+	var quotas []string
+	for _, entry := range sample.Strata {
+		if entry.Sampled == 0 {
+			continue
+		}
+		quotas = append(quotas, fmt.Sprintf("WHEN $%d THEN %d", nextIdx, entry.Sampled))
+		args = append(args, entry.Value)
+		nextIdx++
+		sample.Size += entry.Sampled
+	}
+	if len(quotas) == 0 {
+		return sample, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM (
+			SELECT *, %s as sample_stratum,
+				ROW_NUMBER() OVER (PARTITION BY %s ORDER BY hash(id, $%d::BIGINT), id) as sample_rank
+			FROM incidents
+			WHERE 1=1%s
+		) incidents
+		WHERE sample_rank <= CASE sample_stratum %s ELSE 0 END
+		ORDER BY sample_stratum, sample_rank`,
+		incidentColumns, stratum, stratum, nextIdx, whereClause, strings.Join(quotas, " "))
+	args = append(args, *opts.Seed)
+
+	rows, err = s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample incidents: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+		sample.Incidents = append(sample.Incidents, incident)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sampled incidents: %w", err)
+	}
+
+	return sample, nil
+}
+
+// allocateSample splits size across the strata in proportion to their population, largest
+// remainders first. When size allows, every stratum gets at least one incident so rare priorities
+// or applications are not missed; otherwise the largest strata get one each.
+func allocateSample(strata []SampleStratum, size int) {
+	population := 0
+	for _, entry := range strata {
+		population += entry.Population
+	}
+	if size >= population {
+		for i := range strata {
+			strata[i].Sampled = strata[i].Population
+		}
+		return
+	}
+
+	// Largest strata first, ties by value, decide who gets the units left over
+	order := make([]int, len(strata))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return strata[order[a]].Population > strata[order[b]].Population
+	})
+
+	if size < len(strata) {
+		for _, i := range order[:size] {
+			strata[i].Sampled = 1
+		}
+		return
+	}
+
+	// One each, then the rest in proportion to what is left of each stratum
+	remaining := size - len(strata)
+	remainingPopulation := population - len(strata)
+	remainders := make([]float64, len(strata))
+	for i := range strata {
+		share := float64(remaining) * float64(strata[i].Population-1) / float64(remainingPopulation)
+		strata[i].Sampled = 1 + int(share)
+		remainders[i] = share - float64(int(share))
+		size -= strata[i].Sampled
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for _, i := range order[:size] {
+		strata[i].Sampled++
+	}
+}
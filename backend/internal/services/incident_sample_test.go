@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestAllocateSample(t *testing.T) {
+	tests := []struct {
+		name        string
+		populations []int
+		size        int
+		want        []int
+	}{
+		{name: "proportional", populations: []int{80, 15, 5}, size: 20, want: []int{15, 3, 2}},
+		{name: "rare stratum kept", populations: []int{98, 1, 1}, size: 10, want: []int{8, 1, 1}},
+		{name: "fewer than strata", populations: []int{5, 40, 20}, size: 2, want: []int{0, 1, 1}},
+		{name: "everything", populations: []int{3, 2}, size: 10, want: []int{3, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strata := make([]SampleStratum, len(tt.populations))
+			for i, population := range tt.populations {
+				strata[i] = SampleStratum{Value: fmt.Sprintf("s%d", i), Population: population}
+			}
+			allocateSample(strata, tt.size)
+			got := make([]int, len(strata))
+			for i, entry := range strata {
+				got[i] = entry.Sampled
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocateSample() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncidentService_SampleIncidents(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+
+	// 30 P3 incidents, 8 P2 and 2 P1
+	var incidents []models.Incident
+	for i := 0; i < 40; i++ {
+		priority := "P3"
+		if i < 2 {
+			priority = "P1"
+		} else if i < 10 {
+			priority = "P2"
+		}
+		incidents = append(incidents, diffTestIncident(fmt.Sprintf("s%02d", i), "upload-1", fmt.Sprintf("INC%03d", i), priority, "Closed"))
+	}
+	service := NewIncidentService(db)
+	if _, err := service.BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	seed := int64(7)
+	sample, err := service.SampleIncidents(ctx, nil, SampleOptions{Size: 10, Seed: &seed})
+	if err != nil {
+		t.Fatalf("SampleIncidents() error = %v", err)
+	}
+	if sample.Population != 40 || sample.Size != 10 || len(sample.Incidents) != 10 || sample.StratifyBy != SampleByPriority {
+		t.Fatalf("unexpected sample %+v", sample)
+	}
+	counts := map[string]int{}
+	for _, incident := range sample.Incidents {
+		counts[incident.Priority]++
+	}
+	if counts["P1"] != 1 || counts["P2"] != 2 || counts["P3"] != 7 {
+		t.Errorf("expected 1 P1, 2 P2 and 7 P3 incidents, got %v", counts)
+	}
+
+	ids := func(sample *IncidentSample) []string {
+		var ids []string
+		for _, incident := range sample.Incidents {
+			ids = append(ids, incident.IncidentID)
+		}
+		return ids
+	}
+	again, err := service.SampleIncidents(ctx, nil, SampleOptions{Size: 10, Seed: &seed})
+	if err != nil {
+		t.Fatalf("SampleIncidents() error = %v", err)
+	}
+	if !reflect.DeepEqual(ids(sample), ids(again)) {
+		t.Errorf("expected the same seed to repeat the sample, got %v and %v", ids(sample), ids(again))
+	}
+
+	other := int64(8)
+	different, err := service.SampleIncidents(ctx, nil, SampleOptions{Size: 10, Seed: &other})
+	if err != nil {
+		t.Fatalf("SampleIncidents() error = %v", err)
+	}
+	if reflect.DeepEqual(ids(sample), ids(different)) {
+		t.Errorf("expected another seed to draw another sample, got %v", ids(different))
+	}
+
+	// Filters narrow the population and a random seed is reported
+	filtered, err := service.SampleIncidents(ctx, &TimelineFilters{Priorities: []string{"P2"}}, SampleOptions{Size: 3})
+	if err != nil {
+		t.Fatalf("SampleIncidents() error = %v", err)
+	}
+	if filtered.Population != 8 || len(filtered.Incidents) != 3 || filtered.Incidents[0].Priority != "P2" {
+		t.Errorf("unexpected filtered sample %+v", filtered)
+	}
+	repeated, err := service.SampleIncidents(ctx, &TimelineFilters{Priorities: []string{"P2"}}, SampleOptions{Size: 3, Seed: &filtered.Seed})
+	if err != nil {
+		t.Fatalf("SampleIncidents() error = %v", err)
+	}
+	if !reflect.DeepEqual(ids(filtered), ids(repeated)) {
+		t.Errorf("expected the reported seed to repeat the sample, got %v and %v", ids(filtered), ids(repeated))
+	}
+}
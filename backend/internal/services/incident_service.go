@@ -3,25 +3,41 @@ package services
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"incident-management-system/internal/models"
+
+	"github.com/google/uuid"
 )
 
+// incidentInsertChunkSize is the number of incidents BatchInsertIncidents commits per transaction
+const incidentInsertChunkSize = 500
+
 // IncidentService handles incident data operations
 type IncidentService struct {
-	db *sql.DB
+	db        *sql.DB
+	chunkSize int
+	// onChunk, when set, is told after each committed chunk how many incidents of the batch are stored
+	onChunk func(uploadID string, inserted, total int)
 }
 
 // NewIncidentService creates a new IncidentService instance
 func NewIncidentService(db *sql.DB) *IncidentService {
 	return &IncidentService{
-		db: db,
+		db:        db,
+		chunkSize: incidentInsertChunkSize,
 	}
 }
 
+// SetInsertProgress registers fn to be called after each chunk BatchInsertIncidents commits,
+// with the number of the batch's incidents stored so far
+func (s *IncidentService) SetInsertProgress(fn func(uploadID string, inserted, total int)) {
+	s.onChunk = fn
+}
+
 // BatchInsertResult represents the result of a batch insert operation
 type BatchInsertResult struct {
 	InsertedCount int                      `json:"inserted_count"`
@@ -29,20 +45,58 @@ type BatchInsertResult struct {
 	Success       bool                     `json:"success"`
 }
 
-// BatchInsertIncidents inserts multiple incidents in a single transaction
+// BatchInsertIncidents inserts incidents in transactions of up to incidentInsertChunkSize
+// incidents each, so a large upload never holds one long transaction. Rows rejected by
+// validation are reported in the result. When a chunk fails, the chunks before it stay
+// committed: the result returned with the error counts them, and the caller is expected to
+// delete the upload's incidents with CleanupUploadIncidents.
 func (s *IncidentService) BatchInsertIncidents(ctx context.Context, incidents []models.Incident, uploadID string) (*BatchInsertResult, error) {
-	if len(incidents) == 0 {
-		return &BatchInsertResult{
-			InsertedCount: 0,
-			Errors:        []models.ValidationError{},
-			Success:       true,
-		}, nil
+	result := &BatchInsertResult{
+		InsertedCount: 0,
+		Errors:        make([]models.ValidationError, 0),
+		Success:       true, // Default to true, only set to false on critical errors
+	}
+
+	// Check for duplicate incident IDs within the upload, across chunks
+	duplicateMap := make(map[string]bool)
+
+	for start := 0; start < len(incidents); start += s.chunkSize {
+		end := start + s.chunkSize
+		if end > len(incidents) {
+			end = len(incidents)
+		}
+		if err := s.insertChunk(ctx, incidents, start, end, uploadID, duplicateMap, result); err != nil {
+			result.Success = false
+			return result, err
+		}
+		if s.onChunk != nil {
+			s.onChunk(uploadID, result.InsertedCount, len(incidents))
+		}
 	}
 
+	return result, nil
+}
+
+// insertChunk inserts incidents[start:end] in one transaction, adding to result. DuckDB aborts
+// a transaction on the first failed statement, so a row the database rejects is recorded and the
+// chunk is retried without it rather than committing a transaction that has already been lost.
+func (s *IncidentService) insertChunk(ctx context.Context, incidents []models.Incident, start, end int, uploadID string, duplicateMap map[string]bool, result *BatchInsertResult) error {
+	rejected := make(map[int]models.ValidationError)
+	for {
+		retry, err := s.attemptChunk(ctx, incidents, start, end, uploadID, duplicateMap, rejected, result)
+		if err != nil || !retry {
+			return err
+		}
+	}
+}
+
+// attemptChunk makes one attempt at inserting a chunk, skipping the rows already rejected. It
+// returns retry when the database rejected another row, after rolling the attempt back.
+func (s *IncidentService) attemptChunk(ctx context.Context, incidents []models.Incident, start, end int, uploadID string, duplicateMap map[string]bool, rejected map[int]models.ValidationError, result *BatchInsertResult) (retry bool, err error) {
 	// Start transaction
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
 	// Ensure rollback on error
@@ -52,11 +106,10 @@ func (s *IncidentService) BatchInsertIncidents(ctx context.Context, incidents []
 		}
 	}()
 
-	result := &BatchInsertResult{
-		InsertedCount: 0,
-		Errors:        make([]models.ValidationError, 0),
-		Success:       true, // Default to true, only set to false on critical errors
-	}
+	// Counts and seen incident IDs are only added to result once the chunk commits
+	inserted := 0
+	var rowErrors []models.ValidationError
+	chunkSeen := make(map[string]bool)
 
 	// Prepare insert statement
 	insertQuery := `
@@ -66,27 +119,33 @@ func (s *IncidentService) BatchInsertIncidents(ctx context.Context, incidents []
 			resolved_person, priority, category, subcategory, impact, urgency, 
 			status, customer_affected, business_service, root_cause, resolution_notes,
 			sentiment_score, sentiment_label, resolution_time_hours, automation_score,
-			automation_feasible, it_process_group, created_at, updated_at
+			automation_feasible, it_process_group, created_at, updated_at, application_name_raw,
+			sentiment_version, automation_version, dataset_id, cost, pending_hours,
+			net_resolution_time_hours, business_service_raw, source, closure_code, region,
+			cmdb_owner, cmdb_environment, cmdb_criticality, reported_at, priority_inferred
 		) VALUES (
 			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 
-			?, ?, ?, ?, ?, ?, ?, ?
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 		)
 	`
 
 	stmt, err := tx.PrepareContext(ctx, insertQuery)
 	if err != nil {
-		return nil, fmt.Errorf("failed to prepare insert statement: %w", err)
+		return false, fmt.Errorf("failed to prepare insert statement: %w", err)
 	}
 	defer stmt.Close()
 
-	// Check for duplicate incident IDs within the upload
-	duplicateMap := make(map[string]bool)
-
 	// Insert incidents one by one to handle individual errors
-	for i, incident := range incidents {
+	for i := start; i < end; i++ {
+		incident := incidents[i]
+		// Stop as soon as the caller gives up; the deferred rollback discards the partial chunk
+		if err = ctx.Err(); err != nil {
+			return false, fmt.Errorf("batch insert cancelled after %d of %d incidents: %w", i, len(incidents), err)
+		}
+
 		// Check for duplicates within this batch
-		if duplicateMap[incident.IncidentID] {
-			result.Errors = append(result.Errors, models.ValidationError{
+		if duplicateMap[incident.IncidentID] || chunkSeen[incident.IncidentID] {
+			rowErrors = append(rowErrors, models.ValidationError{
 				Field:   "incident_id",
 				Value:   incident.IncidentID,
 				Message: "duplicate incident ID within upload",
@@ -94,22 +153,29 @@ func (s *IncidentService) BatchInsertIncidents(ctx context.Context, incidents []
 			})
 			continue
 		}
-		duplicateMap[incident.IncidentID] = true
+		chunkSeen[incident.IncidentID] = true
+
+		// Rows the database rejected in an earlier attempt keep their error
+		if rowError, ok := rejected[i]; ok {
+			rowErrors = append(rowErrors, rowError)
+			continue
+		}
 
 		// Check for existing incident ID in database
-		exists, err := s.checkIncidentExists(ctx, tx, incident.IncidentID, uploadID)
-		if err != nil {
-			result.Errors = append(result.Errors, models.ValidationError{
+		exists, checkErr := s.checkIncidentExists(ctx, tx, incident.IncidentID, uploadID)
+		if checkErr != nil {
+			rejected[i] = models.ValidationError{
 				Field:   "incident_id",
 				Value:   incident.IncidentID,
-				Message: fmt.Sprintf("database error checking duplicate: %v", err),
+				Message: fmt.Sprintf("database error checking duplicate: %v", checkErr),
 				Row:     i + 2,
-			})
-			continue
+			}
+			tx.Rollback()
+			return true, nil
 		}
 
 		if exists {
-			result.Errors = append(result.Errors, models.ValidationError{
+			rowErrors = append(rowErrors, models.ValidationError{
 				Field:   "incident_id",
 				Value:   incident.IncidentID,
 				Message: "incident ID already exists in this upload",
@@ -118,6 +184,16 @@ func (s *IncidentService) BatchInsertIncidents(ctx context.Context, incidents []
 			continue
 		}
 
+		// Parsed rows carry neither a record ID nor their upload; fill both in on the caller's
+		// slice so later steps see the stored values
+		if incidents[i].ID == "" {
+			incidents[i].ID = uuid.New().String()
+		}
+		if incidents[i].UploadID == "" {
+			incidents[i].UploadID = uploadID
+		}
+		incident = incidents[i]
+
 		// Execute insert
 		// Convert empty strings to nil for optional fields
 		var sentimentLabel interface{}
@@ -127,7 +203,7 @@ func (s *IncidentService) BatchInsertIncidents(ctx context.Context, incidents []
 			sentimentLabel = incident.SentimentLabel
 		}
 
-		_, err = stmt.ExecContext(ctx,
+		_, execErr := stmt.ExecContext(ctx,
 			incident.ID,
 			incident.UploadID,
 			incident.IncidentID,
@@ -157,52 +233,74 @@ func (s *IncidentService) BatchInsertIncidents(ctx context.Context, incidents []
 			incident.ITProcessGroup,
 			incident.CreatedAt,
 			incident.UpdatedAt,
+			incident.ApplicationNameRaw,
+			nullIfEmpty(incident.SentimentVersion),
+			nullIfEmpty(incident.AutomationVersion),
+			nullIfEmpty(incident.DatasetID),
+			incident.Cost,
+			incident.PendingHours,
+			incident.NetResolutionTimeHours,
+			nullIfEmpty(incident.BusinessServiceRaw),
+			nullIfEmpty(incident.Source),
+			nullIfEmpty(incident.ClosureCode),
+			nullIfEmpty(incident.Region),
+			nullIfEmpty(incident.CMDBOwner),
+			nullIfEmpty(incident.CMDBEnvironment),
+			nullIfEmpty(incident.CMDBCriticality),
+			incident.ReportTime(),
+			incident.PriorityInferred,
 		)
 
-		if err != nil {
+		if execErr != nil {
 			// Handle constraint violations and other database errors
-			errorMsg := err.Error()
+			errorMsg := execErr.Error()
 			if strings.Contains(errorMsg, "UNIQUE constraint failed") {
-				result.Errors = append(result.Errors, models.ValidationError{
+				rejected[i] = models.ValidationError{
 					Field:   "incident_id",
 					Value:   incident.IncidentID,
 					Message: "incident ID already exists",
 					Row:     i + 2,
-				})
+				}
 			} else if strings.Contains(errorMsg, "CHECK constraint failed") {
-				result.Errors = append(result.Errors, models.ValidationError{
+				rejected[i] = models.ValidationError{
 					Field:   "general",
 					Value:   "",
 					Message: "data validation failed: " + errorMsg,
 					Row:     i + 2,
-				})
+				}
 			} else {
-				result.Errors = append(result.Errors, models.ValidationError{
+				rejected[i] = models.ValidationError{
 					Field:   "general",
 					Value:   "",
 					Message: "database error: " + errorMsg,
 					Row:     i + 2,
-				})
+				}
 			}
-			continue
+			tx.Rollback()
+			return true, nil
+		}
+
+		analyzedAt := incident.CreatedAt
+		if analyzedAt.IsZero() {
+			analyzedAt = time.Now()
+		}
+		if err = insertIncidentEvents(ctx, tx, IngestionEvents(incident, analyzedAt)); err != nil {
+			return false, err
 		}
 
-		result.InsertedCount++
+		inserted++
 	}
 
-	// Commit transaction if we have any successful inserts or if there were only validation errors
-	if result.InsertedCount > 0 || len(result.Errors) > 0 {
-		if err = tx.Commit(); err != nil {
-			return nil, fmt.Errorf("failed to commit transaction: %w", err)
-		}
-		// Success remains true for validation errors, only set to false on critical database errors
-	} else {
-		// Rollback if no successful inserts and no validation errors (critical error)
-		tx.Rollback()
-		result.Success = false
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit incidents %d-%d: %w", start+1, end, err)
 	}
 
-	return result, nil
+	for incidentID := range chunkSeen {
+		duplicateMap[incidentID] = true
+	}
+	result.InsertedCount += inserted
+	result.Errors = append(result.Errors, rowErrors...)
+	return false, nil
 }
 
 // checkIncidentExists checks if an incident ID already exists for the given upload
@@ -218,71 +316,147 @@ func (s *IncidentService) checkIncidentExists(ctx context.Context, tx *sql.Tx, i
 	return count > 0, nil
 }
 
-// UpdateUploadStatus updates the status and statistics of an upload
-func (s *IncidentService) UpdateUploadStatus(ctx context.Context, uploadID string, status string, recordCount, processedCount, errorCount int, errors []string) error {
-	// Convert errors to JSON string (simplified for now)
-	errorsJSON := "[]"
-	if len(errors) > 0 {
-		// In production, use proper JSON marshaling
-		errorsJSON = fmt.Sprintf(`["%s"]`, strings.Join(errors, `", "`))
+// SetUploadProcessingOptions records the options an upload is processed with
+func (s *IncidentService) SetUploadProcessingOptions(ctx context.Context, uploadID string, options models.ProcessingOptions) error {
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("failed to encode processing options: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, "UPDATE uploads SET processing_options = ? WHERE id = ?", string(optionsJSON), uploadID)
+	if err != nil {
+		return fmt.Errorf("failed to save processing options: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("upload record not found: %s", uploadID)
+	}
+	return nil
+}
+
+// DecodeProcessingOptions decodes processing options stored on an upload record, returning
+// nil for uploads that were never processed or predate recorded options
+func DecodeProcessingOptions(optionsJSON string) *models.ProcessingOptions {
+	if optionsJSON == "" {
+		return nil
+	}
+	var options models.ProcessingOptions
+	if err := json.Unmarshal([]byte(optionsJSON), &options); err != nil {
+		return nil
 	}
+	return &options
+}
 
-	// Debug: Check if record exists
-	var existingCount int
-	checkQuery := "SELECT COUNT(*) FROM uploads WHERE id = ?"
-	err := s.db.QueryRowContext(ctx, checkQuery, uploadID).Scan(&existingCount)
+// SetUploadParseReport records which sheet and cell range of its workbook an upload was read
+// from and how its date columns were parsed
+func (s *IncidentService) SetUploadParseReport(ctx context.Context, uploadID string, sheet *models.IngestedSheet, dates map[string]*DateColumnStats) error {
+	sheetJSON, err := json.Marshal(sheet)
+	if err != nil {
+		return fmt.Errorf("failed to encode ingested sheet: %w", err)
+	}
+	datesJSON, err := json.Marshal(dates)
 	if err != nil {
-		return fmt.Errorf("failed to check existing upload: %w", err)
+		return fmt.Errorf("failed to encode date quality: %w", err)
 	}
 
-	if existingCount == 0 {
+	result, err := s.db.ExecContext(ctx, "UPDATE uploads SET ingested_sheet = ?, date_quality = ? WHERE id = ?",
+		string(sheetJSON), string(datesJSON), uploadID)
+	if err != nil {
+		return fmt.Errorf("failed to save parse report: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
 		return fmt.Errorf("upload record not found: %s", uploadID)
 	}
+	return nil
+}
 
-	// Update without processed_at first
-	query := `
-		UPDATE uploads 
-		SET status = ?, record_count = ?, processed_count = ?, error_count = ?, errors = ?
-		WHERE id = ?
-	`
+// DecodeIngestedSheet decodes the ingested sheet stored on an upload record, returning nil for
+// uploads that were never parsed or predate recorded sheets
+func DecodeIngestedSheet(sheetJSON string) *models.IngestedSheet {
+	if sheetJSON == "" || sheetJSON == "null" {
+		return nil
+	}
+	var sheet models.IngestedSheet
+	if err := json.Unmarshal([]byte(sheetJSON), &sheet); err != nil {
+		return nil
+	}
+	return &sheet
+}
 
-	result, err := s.db.ExecContext(ctx, query, status, recordCount, processedCount, errorCount, errorsJSON, uploadID)
+// CleanupUploadIncidents deletes every incident an upload stored, with their events, in one
+// transaction and returns how many incidents were deleted. It is the compensating step for a run
+// that failed after some of its chunks had committed.
+func (s *IncidentService) CleanupUploadIncidents(ctx context.Context, uploadID string) (deleted int, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to update upload status (uploadID=%s, status=%s): %w", uploadID, status, err)
+		return 0, fmt.Errorf("failed to begin cleanup transaction: %w", err)
 	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("no rows updated for upload %s", uploadID)
+	if err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM incidents WHERE upload_id = ?", uploadID).Scan(&deleted); err != nil {
+		return 0, fmt.Errorf("failed to count incidents of upload %s: %w", uploadID, err)
 	}
+	if deleted == 0 {
+		return 0, tx.Commit()
+	}
+	if err = deleteUploadIncidents(ctx, tx, uploadID); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit cleanup of upload %s: %w", uploadID, err)
+	}
+	return deleted, nil
+}
 
-	// Update processed_at separately if not processing
-	if status != models.UploadStatusProcessing {
-		processedAtQuery := "UPDATE uploads SET processed_at = ? WHERE id = ?"
-		_, err = s.db.ExecContext(ctx, processedAtQuery, time.Now(), uploadID)
+// SetUploadCleanup records the outcome of the last cleanup of an upload's partial incidents. A
+// nil cleanup clears it.
+func (s *IncidentService) SetUploadCleanup(ctx context.Context, uploadID string, cleanup *models.UploadCleanup) error {
+	var cleanupJSON interface{}
+	if cleanup != nil {
+		encoded, err := json.Marshal(cleanup)
 		if err != nil {
-			return fmt.Errorf("failed to update processed_at: %w", err)
+			return fmt.Errorf("failed to encode upload cleanup: %w", err)
 		}
+		cleanupJSON = string(encoded)
 	}
 
+	result, err := s.db.ExecContext(ctx, "UPDATE uploads SET cleanup = ? WHERE id = ?", cleanupJSON, uploadID)
+	if err != nil {
+		return fmt.Errorf("failed to save upload cleanup: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("upload record not found: %s", uploadID)
+	}
 	return nil
 }
 
+// DecodeUploadCleanup decodes the cleanup stored on an upload record, returning nil for uploads
+// that never needed one
+func DecodeUploadCleanup(cleanupJSON string) *models.UploadCleanup {
+	if cleanupJSON == "" || cleanupJSON == "null" {
+		return nil
+	}
+	var cleanup models.UploadCleanup
+	if err := json.Unmarshal([]byte(cleanupJSON), &cleanup); err != nil {
+		return nil
+	}
+	return &cleanup
+}
+
 // GetIncidentsByUpload retrieves all incidents for a specific upload
 func (s *IncidentService) GetIncidentsByUpload(ctx context.Context, uploadID string) ([]models.Incident, error) {
+	scope, scopeArgs := scopeClause(ctx)
 	query := `
-		SELECT id, upload_id, incident_id, report_date, resolve_date, last_resolve_date,
-			   brief_description, description, application_name, resolution_group,
-			   resolved_person, priority, category, subcategory, impact, urgency,
-			   status, customer_affected, business_service, root_cause, resolution_notes,
-			   sentiment_score, sentiment_label, resolution_time_hours, automation_score,
-			   automation_feasible, it_process_group, created_at, updated_at
+		SELECT ` + incidentColumns + `
 		FROM incidents 
-		WHERE upload_id = ?
+		WHERE upload_id = ?` + scope + `
 		ORDER BY created_at ASC
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, uploadID)
+	rows, err := s.db.QueryContext(ctx, query, append([]interface{}{uploadID}, scopeArgs...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query incidents: %w", err)
 	}
@@ -290,42 +464,9 @@ func (s *IncidentService) GetIncidentsByUpload(ctx context.Context, uploadID str
 
 	var incidents []models.Incident
 	for rows.Next() {
-		var incident models.Incident
-
-		err := rows.Scan(
-			&incident.ID,
-			&incident.UploadID,
-			&incident.IncidentID,
-			&incident.ReportDate,
-			&incident.ResolveDate,
-			&incident.LastResolveDate,
-			&incident.BriefDescription,
-			&incident.Description,
-			&incident.ApplicationName,
-			&incident.ResolutionGroup,
-			&incident.ResolvedPerson,
-			&incident.Priority,
-			&incident.Category,
-			&incident.Subcategory,
-			&incident.Impact,
-			&incident.Urgency,
-			&incident.Status,
-			&incident.CustomerAffected,
-			&incident.BusinessService,
-			&incident.RootCause,
-			&incident.ResolutionNotes,
-			&incident.SentimentScore,
-			&incident.SentimentLabel,
-			&incident.ResolutionTimeHours,
-			&incident.AutomationScore,
-			&incident.AutomationFeasible,
-			&incident.ITProcessGroup,
-			&incident.CreatedAt,
-			&incident.UpdatedAt,
-		)
-
+		incident, err := scanIncident(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan incident: %w", err)
+			return nil, err
 		}
 
 		incidents = append(incidents, incident)
@@ -338,11 +479,109 @@ func (s *IncidentService) GetIncidentsByUpload(ctx context.Context, uploadID str
 	return incidents, nil
 }
 
+// incidentColumns selects every incident field in the order scanIncident reads them
+const incidentColumns = `id, upload_id, incident_id, report_date, resolve_date, last_resolve_date,
+			   brief_description, description, application_name, resolution_group,
+			   resolved_person, priority, category, subcategory, impact, urgency,
+			   status, customer_affected, business_service, root_cause, resolution_notes,
+			   sentiment_score, COALESCE(sentiment_label, ''), resolution_time_hours, automation_score,
+			   automation_feasible, COALESCE(it_process_group, ''), created_at, updated_at,
+			   COALESCE(application_name_raw, ''), COALESCE(sentiment_version, ''),
+			   COALESCE(automation_version, ''), COALESCE(dataset_id, ''), cost, pending_hours,
+			   net_resolution_time_hours, COALESCE(business_service_raw, ''), COALESCE(source, ''),
+			   COALESCE(closure_code, ''), COALESCE(region, ''), COALESCE(cmdb_owner, ''),
+			   COALESCE(cmdb_environment, ''), COALESCE(cmdb_criticality, ''),
+			   COALESCE(priority_inferred, FALSE)`
+
+// scanIncident reads an incident selected with incidentColumns
+func scanIncident(rows *sql.Rows) (models.Incident, error) {
+	var incident models.Incident
+	err := rows.Scan(
+		&incident.ID,
+		&incident.UploadID,
+		&incident.IncidentID,
+		&incident.ReportDate,
+		&incident.ResolveDate,
+		&incident.LastResolveDate,
+		&incident.BriefDescription,
+		&incident.Description,
+		&incident.ApplicationName,
+		&incident.ResolutionGroup,
+		&incident.ResolvedPerson,
+		&incident.Priority,
+		&incident.Category,
+		&incident.Subcategory,
+		&incident.Impact,
+		&incident.Urgency,
+		&incident.Status,
+		&incident.CustomerAffected,
+		&incident.BusinessService,
+		&incident.RootCause,
+		&incident.ResolutionNotes,
+		&incident.SentimentScore,
+		&incident.SentimentLabel,
+		&incident.ResolutionTimeHours,
+		&incident.AutomationScore,
+		&incident.AutomationFeasible,
+		&incident.ITProcessGroup,
+		&incident.CreatedAt,
+		&incident.UpdatedAt,
+		&incident.ApplicationNameRaw,
+		&incident.SentimentVersion,
+		&incident.AutomationVersion,
+		&incident.DatasetID,
+		&incident.Cost,
+		&incident.PendingHours,
+		&incident.NetResolutionTimeHours,
+		&incident.BusinessServiceRaw,
+		&incident.Source,
+		&incident.ClosureCode,
+		&incident.Region,
+		&incident.CMDBOwner,
+		&incident.CMDBEnvironment,
+		&incident.CMDBCriticality,
+		&incident.PriorityInferred,
+	)
+	if err != nil {
+		return incident, fmt.Errorf("failed to scan incident: %w", err)
+	}
+	return incident, nil
+}
+
 // DeleteIncidentsByUpload deletes all incidents for a specific upload (for rollback)
 func (s *IncidentService) DeleteIncidentsByUpload(ctx context.Context, uploadID string) error {
+	return deleteUploadIncidents(ctx, s.db, uploadID)
+}
+
+// uploadExecer is satisfied by both *sql.DB and *sql.Tx
+type uploadExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// deleteUploadIncidents deletes the incidents of an upload and their events. Merges into the
+// deleted incidents are undone, so their duplicates are counted again.
+func deleteUploadIncidents(ctx context.Context, db uploadExecer, uploadID string) error {
+	if _, err := db.ExecContext(ctx, `
+		UPDATE incident_merges SET undone_at = CURRENT_TIMESTAMP
+		WHERE undone_at IS NULL AND primary_id IN (SELECT id FROM incidents WHERE upload_id = ?)
+	`, uploadID); err != nil {
+		return fmt.Errorf("failed to undo merges into incidents of upload %s: %w", uploadID, err)
+	}
+	if _, err := db.ExecContext(ctx, `
+		UPDATE incidents SET is_canonical = TRUE, merged_into = NULL
+		WHERE merged_into IN (SELECT id FROM incidents WHERE upload_id = ?)
+	`, uploadID); err != nil {
+		return fmt.Errorf("failed to restore duplicates of incidents of upload %s: %w", uploadID, err)
+	}
+
+	eventsQuery := "DELETE FROM incident_events WHERE incident_id IN (SELECT id FROM incidents WHERE upload_id = ?)"
+	if _, err := db.ExecContext(ctx, eventsQuery, uploadID); err != nil {
+		return fmt.Errorf("failed to delete incident events for upload %s: %w", uploadID, err)
+	}
+
 	query := "DELETE FROM incidents WHERE upload_id = ?"
 
-	_, err := s.db.ExecContext(ctx, query, uploadID)
+	_, err := db.ExecContext(ctx, query, uploadID)
 	if err != nil {
 		return fmt.Errorf("failed to delete incidents for upload %s: %w", uploadID, err)
 	}
@@ -362,3 +601,11 @@ func (s *IncidentService) GetIncidentCount(ctx context.Context, uploadID string)
 
 	return count, nil
 }
+
+// nullIfEmpty stores empty optional strings as NULL
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -131,6 +131,94 @@ func TestIncidentService_BatchInsertIncidents(t *testing.T) {
 	}
 }
 
+func TestIncidentService_BatchInsertChunks(t *testing.T) {
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	service := NewIncidentService(db)
+	service.chunkSize = 2
+	ctx := context.Background()
+
+	reported := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	newIncident := func(incidentID string) models.Incident {
+		return models.Incident{
+			IncidentID:       incidentID,
+			ReportDate:       reported,
+			BriefDescription: "Chunked incident",
+			ApplicationName:  "Portal",
+			ResolutionGroup:  "Web Team",
+			ResolvedPerson:   "Alice",
+			Priority:         "P3",
+		}
+	}
+
+	// Duplicates are detected across chunks, and progress is reported after each chunk
+	var progress []int
+	service.SetInsertProgress(func(uploadID string, inserted, total int) {
+		if uploadID != "upload-ok" || total != 3 {
+			t.Errorf("unexpected progress for %s: %d of %d", uploadID, inserted, total)
+		}
+		progress = append(progress, inserted)
+	})
+	incidents := []models.Incident{newIncident("INC001"), newIncident("INC002"), newIncident("INC001")}
+	result, err := service.BatchInsertIncidents(ctx, incidents, "upload-ok")
+	if err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+	if result.InsertedCount != 2 || len(result.Errors) != 1 || result.Errors[0].Row != 4 {
+		t.Errorf("Expected 2 incidents inserted and row 4 rejected as a duplicate, got %+v", result)
+	}
+	if len(progress) != 2 || progress[0] != 2 || progress[1] != 2 {
+		t.Errorf("Expected progress after each of the 2 chunks, got %v", progress)
+	}
+	service.SetInsertProgress(nil)
+
+	// A resolve date before the report date aborts the transaction of its chunk; the chunk is
+	// retried without that row, so the rows around it are still stored
+	invalid := newIncident("INC013")
+	resolved := reported.AddDate(0, 0, -2)
+	invalid.ResolveDate = &resolved
+	incidents = []models.Incident{newIncident("INC011"), newIncident("INC012"), newIncident("INC015"), invalid, newIncident("INC014")}
+	result, err = service.BatchInsertIncidents(ctx, incidents, "upload-check")
+	if err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+	if result.InsertedCount != 4 || len(result.Errors) != 1 || result.Errors[0].Row != 5 {
+		t.Errorf("Expected 4 incidents inserted and row 5 rejected, got %+v", result)
+	}
+	if count, _ := service.GetIncidentCount(ctx, "upload-check"); count != 4 {
+		t.Errorf("Expected the stored count to match the inserted count, got %d", count)
+	}
+
+	// Cleanup deletes every incident of the upload, whichever chunk stored it
+	deleted, err := service.CleanupUploadIncidents(ctx, "upload-check")
+	if err != nil {
+		t.Fatalf("Failed to clean up upload: %v", err)
+	}
+	if deleted != 4 {
+		t.Errorf("Expected 4 incidents cleaned up, got %d", deleted)
+	}
+	if count, _ := service.GetIncidentCount(ctx, "upload-check"); count != 0 {
+		t.Errorf("Expected no incidents left after cleanup, got %d", count)
+	}
+	if count, _ := service.GetIncidentCount(ctx, "upload-ok"); count != 2 {
+		t.Errorf("Expected other uploads to keep their incidents, got %d", count)
+	}
+
+	// Cleaning up an empty upload deletes nothing
+	if deleted, err := service.CleanupUploadIncidents(ctx, "upload-check"); err != nil || deleted != 0 {
+		t.Errorf("Expected nothing to clean up, got %d, %v", deleted, err)
+	}
+}
+
 func TestIncidentService_GetIncidentsByUpload(t *testing.T) {
 	// Create a mock database for testing
 	config := &database.Config{
@@ -290,7 +378,7 @@ func TestIncidentService_GetIncidentCount(t *testing.T) {
 	}
 }
 
-func TestIncidentService_UpdateUploadStatus(t *testing.T) {
+func TestIncidentService_TransitionUploadOutcome(t *testing.T) {
 	// Create a mock database for testing
 	config := &database.Config{
 		DatabasePath: ":memory:",
@@ -313,16 +401,38 @@ func TestIncidentService_UpdateUploadStatus(t *testing.T) {
 
 	// Test updating status for non-existent upload (should error)
 	ctx := context.Background()
-	err = service.UpdateUploadStatus(ctx, "non-existent-upload", "completed", 10, 5, 2, []string{"error1", "error2"})
+	outcome := UploadOutcome{RecordCount: 10, ProcessedCount: 5, ErrorCount: 2, Errors: []string{"error1", "error2"}}
+	err = service.TransitionUpload(ctx, "non-existent-upload", models.UploadStatusCompleted, outcome, models.UploadStatusProcessing)
 	if err == nil {
 		t.Error("Expected error when updating non-existent upload")
 	}
 
-	// Test with valid parameters (will fail due to missing schema)
-	err = service.UpdateUploadStatus(ctx, "upload-123", "completed", 10, 5, 2, []string{"error1", "error2"})
+	// Test with valid parameters
+	if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+		"upload-123", "test.xlsx", "test.xlsx", models.UploadStatusProcessing); err != nil {
+		t.Fatalf("Failed to create upload: %v", err)
+	}
+	err = service.TransitionUpload(ctx, "upload-123", models.UploadStatusCompleted, outcome, models.UploadStatusProcessing)
 	if err != nil {
-		// Expect an error due to missing schema
-		t.Logf("Expected error due to missing schema: %v", err)
+		t.Fatalf("Failed to update upload status: %v", err)
+	}
+
+	var status, errorsJSON string
+	var recordCount, processedCount, errorCount int
+	var processedAt *time.Time
+	if err := db.QueryRow(`SELECT status, record_count, processed_count, error_count, errors, processed_at FROM uploads WHERE id = ?`,
+		"upload-123").Scan(&status, &recordCount, &processedCount, &errorCount, &errorsJSON, &processedAt); err != nil {
+		t.Fatalf("Failed to read upload: %v", err)
+	}
+	if status != models.UploadStatusCompleted || recordCount != 10 || processedCount != 5 || errorCount != 2 {
+		t.Errorf("Expected completed with 10 records, 5 processed and 2 errors, got %s with %d, %d and %d",
+			status, recordCount, processedCount, errorCount)
+	}
+	if errorsJSON != `["error1", "error2"]` {
+		t.Errorf("Expected both errors stored, got %s", errorsJSON)
+	}
+	if processedAt == nil {
+		t.Error("Expected processed_at to be set once processing finished")
 	}
 }
 
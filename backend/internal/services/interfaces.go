@@ -29,6 +29,12 @@ type AutomationAnalyzer interface {
 	AnalyzeBatch(incidents []*models.Incident) ([]*AutomationResult, error)
 }
 
+// VersionedAnalyzer is implemented by analyzers that report which version of their rules
+// produced a result, so analyzer feedback can be broken down by version
+type VersionedAnalyzer interface {
+	Version() string
+}
+
 // ProcessingEngine interface for coordinating data processing
 type ProcessingEngine interface {
 	ProcessUpload(uploadID string) error
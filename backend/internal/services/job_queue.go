@@ -2,11 +2,15 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"incident-management-system/internal/logging"
 	"incident-management-system/internal/models"
 )
 
@@ -17,6 +21,10 @@ const (
 	JobTypeProcessUpload      JobType = "process_upload"
 	JobTypeSentimentAnalysis  JobType = "sentiment_analysis"
 	JobTypeAutomationAnalysis JobType = "automation_analysis"
+	JobTypeExportIncidents    JobType = "export_incidents"
+	JobTypeWarmCache          JobType = "warm_cache"
+	JobTypeRecategorize       JobType = "recategorize_incidents"
+	JobTypeRebuildArtifacts   JobType = "rebuild_artifacts"
 )
 
 // JobStatus represents the current status of a job
@@ -28,8 +36,30 @@ const (
 	JobStatusCompleted JobStatus = "completed"
 	JobStatusFailed    JobStatus = "failed"
 	JobStatusRetrying  JobStatus = "retrying"
+	JobStatusCancelled JobStatus = "cancelled"
 )
 
+// defaultJobTimeout bounds a single job attempt when no timeout is configured
+const defaultJobTimeout = 30 * time.Minute
+
+// Retry backoff used when none is configured: the delay before the first retry, doubled for
+// each later retry up to the maximum
+const (
+	defaultRetryBaseDelay = 2 * time.Second
+	defaultRetryMaxDelay  = 5 * time.Minute
+)
+
+// JobAttempt records a failed attempt of a job and the retry scheduled after it
+type JobAttempt struct {
+	Attempt   int        `json:"attempt"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	FailedAt  time.Time  `json:"failed_at"`
+	Error     string     `json:"error"`
+	// RetryDelay and RetryAt are absent when the attempt was not retried
+	RetryDelay string     `json:"retry_delay,omitempty"`
+	RetryAt    *time.Time `json:"retry_at,omitempty"`
+}
+
 // Job represents a processing job in the queue
 type Job struct {
 	ID          string                 `json:"id"`
@@ -46,12 +76,43 @@ type Job struct {
 	StartedAt   *time.Time             `json:"started_at,omitempty"`
 	CompletedAt *time.Time             `json:"completed_at,omitempty"`
 	Result      interface{}            `json:"result,omitempty"`
+	// NextRetryAt is when a retrying job is resubmitted
+	NextRetryAt *time.Time `json:"next_retry_at,omitempty"`
+	// RetryHistory lists the failed attempts, oldest first
+	RetryHistory []JobAttempt `json:"retry_history,omitempty"`
+	// RequestID and UserID identify the request that submitted the job; every log line of the
+	// job and the records its processing writes carry them
+	RequestID string `json:"request_id,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+}
+
+// context returns parent carrying the job's request and user, so the services processing the
+// job log and record them
+func (job *Job) context(parent context.Context) context.Context {
+	ctx := parent
+	if job.RequestID != "" {
+		ctx = logging.WithRequestID(ctx, job.RequestID)
+	}
+	if job.UserID != "" {
+		ctx = logging.WithUserID(ctx, job.UserID)
+	}
+	return ctx
+}
+
+// logf logs a line about the job, prefixed with the request and user that submitted it
+func (job *Job) logf(format string, args ...interface{}) {
+	log.Print(correlationPrefix(job.RequestID, job.UserID) + fmt.Sprintf(format, args...))
 }
 
 // JobQueue manages asynchronous job processing
 type JobQueue struct {
 	jobs        chan *Job
 	workers     int
+	jobTimeout  atomic.Int64             // Nanoseconds; adjustable while jobs run
+	chaos       atomic.Pointer[jobChaos] // Failure injection for testing; nil when off
+	preemption  jobPreemption            // Urgent processing holding back background jobs
+	retryBase   time.Duration
+	retryMax    time.Duration
 	jobStore    map[string]*Job
 	jobStoreMux sync.RWMutex
 	ctx         context.Context
@@ -62,12 +123,21 @@ type JobQueue struct {
 	processingService *ProcessingService
 	sentimentService  SentimentAnalyzer
 	automationService AutomationAnalyzer
+	exportService     *IncidentExportService
+	cacheWarmer       CacheWarmer
+	recategorizer     *ITProcessRecategorizer
+	rebuilder         *ArtifactRebuilder
 }
 
 // JobQueueConfig holds configuration for the job queue
 type JobQueueConfig struct {
 	Workers    int
 	BufferSize int
+	JobTimeout time.Duration // Deadline for a single job attempt
+	// RetryBaseDelay is the backoff before the first retry, doubled for each later retry
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay caps the backoff between retries
+	RetryMaxDelay time.Duration
 }
 
 // NewJobQueue creates a new job queue instance
@@ -80,16 +150,32 @@ func NewJobQueue(config JobQueueConfig, processingService *ProcessingService) *J
 	if config.BufferSize <= 0 {
 		config.BufferSize = 100 // Default buffer size
 	}
+	if config.JobTimeout <= 0 {
+		config.JobTimeout = defaultJobTimeout
+	}
+	if config.RetryBaseDelay <= 0 {
+		config.RetryBaseDelay = defaultRetryBaseDelay
+	}
+	if config.RetryMaxDelay < config.RetryBaseDelay {
+		config.RetryMaxDelay = defaultRetryMaxDelay
+		if config.RetryMaxDelay < config.RetryBaseDelay {
+			config.RetryMaxDelay = config.RetryBaseDelay
+		}
+	}
 
 	jq := &JobQueue{
 		jobs:              make(chan *Job, config.BufferSize),
 		workers:           config.Workers,
+		retryBase:         config.RetryBaseDelay,
+		retryMax:          config.RetryMaxDelay,
 		jobStore:          make(map[string]*Job),
 		ctx:               ctx,
 		cancel:            cancel,
 		processingService: processingService,
 	}
 
+	jq.jobTimeout.Store(int64(config.JobTimeout))
+
 	// Start workers
 	jq.startWorkers()
 
@@ -106,8 +192,43 @@ func (jq *JobQueue) SetAutomationService(service AutomationAnalyzer) {
 	jq.automationService = service
 }
 
+// SetExportService sets the service that writes incident exports
+func (jq *JobQueue) SetExportService(service *IncidentExportService) {
+	jq.exportService = service
+}
+
+// SetCacheWarmer sets the analytics cache warmed by cache warm jobs
+func (jq *JobQueue) SetCacheWarmer(warmer CacheWarmer) {
+	jq.cacheWarmer = warmer
+}
+
+// SetRecategorizer sets the recategorizer run by IT process re-categorization jobs
+func (jq *JobQueue) SetRecategorizer(recategorizer *ITProcessRecategorizer) {
+	jq.recategorizer = recategorizer
+}
+
+// SetRebuilder sets the rebuilder run by derived artifact rebuild jobs
+func (jq *JobQueue) SetRebuilder(rebuilder *ArtifactRebuilder) {
+	jq.rebuilder = rebuilder
+}
+
+// SetJobTimeout changes the deadline of job attempts started from now on
+func (jq *JobQueue) SetJobTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultJobTimeout
+	}
+	jq.jobTimeout.Store(int64(timeout))
+}
+
 // SubmitJob submits a new job to the queue
 func (jq *JobQueue) SubmitJob(jobType JobType, uploadID string, payload map[string]interface{}) (*Job, error) {
+	return jq.SubmitJobContext(context.Background(), jobType, uploadID, payload)
+}
+
+// SubmitJobContext submits a new job on behalf of the request carried by ctx. The request ID and
+// user are added to the payload as "request_id" and "user_id" and propagated into the job's
+// processing.
+func (jq *JobQueue) SubmitJobContext(ctx context.Context, jobType JobType, uploadID string, payload map[string]interface{}) (*Job, error) {
 	job := &Job{
 		ID:         generateJobID(),
 		Type:       jobType,
@@ -117,6 +238,20 @@ func (jq *JobQueue) SubmitJob(jobType JobType, uploadID string, payload map[stri
 		Progress:   0,
 		MaxRetries: 3, // Default max retries
 		CreatedAt:  time.Now(),
+		RequestID:  logging.GetRequestID(ctx),
+		UserID:     logging.GetUserID(ctx),
+	}
+	if job.RequestID != "" || job.UserID != "" {
+		if job.Payload == nil {
+			job.Payload = make(map[string]interface{})
+		}
+		job.Payload["request_id"] = job.RequestID
+		job.Payload["user_id"] = job.UserID
+	}
+
+	// Reject jobs once shutdown has begun
+	if jq.ctx.Err() != nil {
+		return nil, fmt.Errorf("job queue is shutting down")
 	}
 
 	// Store job
@@ -127,7 +262,7 @@ func (jq *JobQueue) SubmitJob(jobType JobType, uploadID string, payload map[stri
 	// Submit to queue
 	select {
 	case jq.jobs <- job:
-		log.Printf("Job %s (%s) submitted for upload %s", job.ID, job.Type, uploadID)
+		job.logf("Job %s (%s) submitted for upload %s", job.ID, job.Type, uploadID)
 		return job, nil
 	case <-jq.ctx.Done():
 		return nil, fmt.Errorf("job queue is shutting down")
@@ -149,6 +284,22 @@ func (jq *JobQueue) GetJob(jobID string) (*Job, error) {
 	return job, nil
 }
 
+// JobSnapshot returns a copy of a job taken under the job store lock, safe to read and
+// serialize while a worker keeps updating the job
+func (jq *JobQueue) JobSnapshot(jobID string) (*Job, error) {
+	jq.jobStoreMux.RLock()
+	defer jq.jobStoreMux.RUnlock()
+
+	job, exists := jq.jobStore[jobID]
+	if !exists {
+		return nil, fmt.Errorf("job not found: %s", jobID)
+	}
+
+	snapshot := *job
+	snapshot.RetryHistory = append([]JobAttempt(nil), job.RetryHistory...)
+	return &snapshot, nil
+}
+
 // GetJobsByUpload retrieves all jobs for a specific upload
 func (jq *JobQueue) GetJobsByUpload(uploadID string) []*Job {
 	jq.jobStoreMux.RLock()
@@ -202,46 +353,100 @@ func (jq *JobQueue) processJob(workerID int, job *Job) {
 		return
 	}
 
-	log.Printf("Worker %d processing job %s (%s) for upload %s",
+	// Background jobs give way to urgent upload processing: they wait while it runs and are
+	// cancelled and queued again when it starts
+	parent, preempt := context.WithCancelCause(job.context(jq.ctx))
+	defer preempt(nil)
+	if preemptibleJobs[job.Type] && !jq.admitPreemptible(job, preempt) {
+		job.logf("Worker %d holding job %s (%s) for urgent upload processing", workerID, job.ID, job.Type)
+		return
+	}
+
+	job.logf("Worker %d processing job %s (%s) for upload %s",
 		workerID, job.ID, job.Type, job.UploadID)
 
 	// Update job status to running
 	jq.updateJobStatus(job, JobStatusRunning, 0, "Processing started")
 
 	startTime := time.Now()
+	jq.jobStoreMux.Lock()
 	job.StartedAt = &startTime
+	jq.jobStoreMux.Unlock()
+
+	// Each attempt is bounded by the job timeout and cancelled on queue shutdown
+	ctx, cancel := context.WithTimeout(parent, time.Duration(jq.jobTimeout.Load()))
+	defer cancel()
 
-	var err error
+	// In chaos mode an attempt may be delayed or failed before it runs
+	err := jq.injectChaos(ctx, job)
 
 	// Process based on job type
-	switch job.Type {
-	case JobTypeProcessUpload:
+	switch {
+	case err != nil:
+	case job.Type == JobTypeProcessUpload:
 		// Check if processing service is available
 		if jq.processingService == nil {
 			err = fmt.Errorf("processing service not available")
 			break
 		}
-		err = jq.processUploadJob(job)
-	case JobTypeSentimentAnalysis:
+		err = jq.processUploadJob(ctx, job)
+	case job.Type == JobTypeSentimentAnalysis:
 		// Check if sentiment service is available
 		if jq.sentimentService == nil {
 			err = fmt.Errorf("sentiment analysis service not available")
 			break
 		}
-		err = jq.processSentimentAnalysisJob(job)
-	case JobTypeAutomationAnalysis:
+		err = jq.processSentimentAnalysisJob(ctx, job)
+	case job.Type == JobTypeAutomationAnalysis:
 		// Check if automation service is available
 		if jq.automationService == nil {
 			err = fmt.Errorf("automation analysis service not available")
 			break
 		}
-		err = jq.processAutomationAnalysisJob(job)
+		err = jq.processAutomationAnalysisJob(ctx, job)
+	case job.Type == JobTypeExportIncidents:
+		// Check if export service is available
+		if jq.exportService == nil {
+			err = fmt.Errorf("export service not available")
+			break
+		}
+		err = jq.processExportJob(ctx, job)
+	case job.Type == JobTypeWarmCache:
+		// Check if the analytics cache is available
+		if jq.cacheWarmer == nil {
+			err = fmt.Errorf("analytics cache not available")
+			break
+		}
+		err = jq.processCacheWarmJob(ctx, job)
+	case job.Type == JobTypeRecategorize:
+		// Check if the recategorizer is available
+		if jq.recategorizer == nil {
+			err = fmt.Errorf("recategorizer not available")
+			break
+		}
+		err = jq.processRecategorizeJob(ctx, job)
+	case job.Type == JobTypeRebuildArtifacts:
+		// Check if the rebuilder is available
+		if jq.rebuilder == nil {
+			err = fmt.Errorf("artifact rebuilder not available")
+			break
+		}
+		err = jq.processRebuildJob(ctx, job)
 	default:
 		err = fmt.Errorf("unknown job type: %s", job.Type)
 	}
 
-	// Handle job completion or failure
-	if err != nil {
+	preempted := err != nil && errors.Is(context.Cause(ctx), errPreempted)
+	if preemptibleJobs[job.Type] {
+		jq.finishPreemptible(job, preempted)
+	}
+
+	// Handle job completion or failure; a preempted job is held for another run
+	if preempted {
+		return
+	} else if err != nil && ctx.Err() != nil {
+		jq.cancelJob(job, err)
+	} else if err != nil {
 		jq.handleJobError(job, err)
 	} else {
 		jq.completeJob(job)
@@ -249,7 +454,7 @@ func (jq *JobQueue) processJob(workerID int, job *Job) {
 }
 
 // processUploadJob processes an upload job
-func (jq *JobQueue) processUploadJob(job *Job) error {
+func (jq *JobQueue) processUploadJob(ctx context.Context, job *Job) error {
 	if jq.processingService == nil {
 		return fmt.Errorf("processing service not available")
 	}
@@ -258,8 +463,12 @@ func (jq *JobQueue) processUploadJob(job *Job) error {
 	jq.updateJobStatus(job, JobStatusRunning, 10, "Starting file processing")
 
 	// Process the upload
-	result, err := jq.processingService.ProcessUpload(jq.ctx, job.UploadID)
+	result, err := jq.processingService.ProcessUpload(ctx, job.UploadID)
 	if err != nil {
+		// Keep the partial progress recorded on cancellation
+		if result != nil {
+			jq.setJobResult(job, result)
+		}
 		return fmt.Errorf("failed to process upload: %w", err)
 	}
 
@@ -267,13 +476,13 @@ func (jq *JobQueue) processUploadJob(job *Job) error {
 	jq.updateJobStatus(job, JobStatusRunning, 90, "File processing completed")
 
 	// Store result
-	job.Result = result
+	jq.setJobResult(job, result)
 
 	return nil
 }
 
 // processSentimentAnalysisJob processes sentiment analysis for incidents
-func (jq *JobQueue) processSentimentAnalysisJob(job *Job) error {
+func (jq *JobQueue) processSentimentAnalysisJob(ctx context.Context, job *Job) error {
 	if jq.sentimentService == nil {
 		return fmt.Errorf("sentiment analysis service not available")
 	}
@@ -282,7 +491,7 @@ func (jq *JobQueue) processSentimentAnalysisJob(job *Job) error {
 	jq.updateJobStatus(job, JobStatusRunning, 10, "Starting sentiment analysis")
 
 	// Get incidents for the upload
-	incidents, err := jq.processingService.incidentService.GetIncidentsByUpload(jq.ctx, job.UploadID)
+	incidents, err := jq.processingService.incidentService.GetIncidentsByUpload(ctx, job.UploadID)
 	if err != nil {
 		return fmt.Errorf("failed to get incidents: %w", err)
 	}
@@ -298,6 +507,14 @@ func (jq *JobQueue) processSentimentAnalysisJob(job *Job) error {
 	processedCount := 0
 
 	for i := 0; i < len(incidents); i += batchSize {
+		if err := ctx.Err(); err != nil {
+			jq.setJobResult(job, map[string]interface{}{
+				"processed_incidents": processedCount,
+				"total_incidents":     totalIncidents,
+			})
+			return fmt.Errorf("sentiment analysis cancelled after %d/%d incidents: %w", processedCount, totalIncidents, err)
+		}
+
 		end := i + batchSize
 		if end > len(incidents) {
 			end = len(incidents)
@@ -309,7 +526,7 @@ func (jq *JobQueue) processSentimentAnalysisJob(job *Job) error {
 		for j := range batch {
 			result, err := jq.sentimentService.AnalyzeSentiment(batch[j].Description)
 			if err != nil {
-				log.Printf("Warning: Failed to analyze sentiment for incident %s: %v",
+				job.logf("Warning: Failed to analyze sentiment for incident %s: %v",
 					batch[j].IncidentID, err)
 				continue
 			}
@@ -320,7 +537,7 @@ func (jq *JobQueue) processSentimentAnalysisJob(job *Job) error {
 		}
 
 		// Update incidents in database
-		err = jq.updateIncidentsSentiment(batch)
+		err = jq.updateIncidentsSentiment(ctx, batch)
 		if err != nil {
 			return fmt.Errorf("failed to update sentiment data: %w", err)
 		}
@@ -331,16 +548,16 @@ func (jq *JobQueue) processSentimentAnalysisJob(job *Job) error {
 			fmt.Sprintf("Processed sentiment for %d/%d incidents", processedCount, totalIncidents))
 	}
 
-	job.Result = map[string]interface{}{
+	jq.setJobResult(job, map[string]interface{}{
 		"processed_incidents": processedCount,
 		"total_incidents":     totalIncidents,
-	}
+	})
 
 	return nil
 }
 
 // processAutomationAnalysisJob processes automation analysis for incidents
-func (jq *JobQueue) processAutomationAnalysisJob(job *Job) error {
+func (jq *JobQueue) processAutomationAnalysisJob(ctx context.Context, job *Job) error {
 	if jq.automationService == nil {
 		return fmt.Errorf("automation analysis service not available")
 	}
@@ -349,7 +566,7 @@ func (jq *JobQueue) processAutomationAnalysisJob(job *Job) error {
 	jq.updateJobStatus(job, JobStatusRunning, 10, "Starting automation analysis")
 
 	// Get incidents for the upload
-	incidents, err := jq.processingService.incidentService.GetIncidentsByUpload(jq.ctx, job.UploadID)
+	incidents, err := jq.processingService.incidentService.GetIncidentsByUpload(ctx, job.UploadID)
 	if err != nil {
 		return fmt.Errorf("failed to get incidents: %w", err)
 	}
@@ -365,6 +582,14 @@ func (jq *JobQueue) processAutomationAnalysisJob(job *Job) error {
 	processedCount := 0
 
 	for i := 0; i < len(incidents); i += batchSize {
+		if err := ctx.Err(); err != nil {
+			jq.setJobResult(job, map[string]interface{}{
+				"processed_incidents": processedCount,
+				"total_incidents":     totalIncidents,
+			})
+			return fmt.Errorf("automation analysis cancelled after %d/%d incidents: %w", processedCount, totalIncidents, err)
+		}
+
 		end := i + batchSize
 		if end > len(incidents) {
 			end = len(incidents)
@@ -376,7 +601,7 @@ func (jq *JobQueue) processAutomationAnalysisJob(job *Job) error {
 		for j := range batch {
 			result, err := jq.automationService.AnalyzeAutomation(&batch[j])
 			if err != nil {
-				log.Printf("Warning: Failed to analyze automation for incident %s: %v",
+				job.logf("Warning: Failed to analyze automation for incident %s: %v",
 					batch[j].IncidentID, err)
 				continue
 			}
@@ -388,7 +613,7 @@ func (jq *JobQueue) processAutomationAnalysisJob(job *Job) error {
 		}
 
 		// Update incidents in database
-		err = jq.updateIncidentsAutomation(batch)
+		err = jq.updateIncidentsAutomation(ctx, batch)
 		if err != nil {
 			return fmt.Errorf("failed to update automation data: %w", err)
 		}
@@ -399,14 +624,122 @@ func (jq *JobQueue) processAutomationAnalysisJob(job *Job) error {
 			fmt.Sprintf("Processed automation analysis for %d/%d incidents", processedCount, totalIncidents))
 	}
 
-	job.Result = map[string]interface{}{
+	jq.setJobResult(job, map[string]interface{}{
 		"processed_incidents": processedCount,
 		"total_incidents":     totalIncidents,
+	})
+
+	return nil
+}
+
+// processExportJob writes the incidents matching the job's filters to an export file. The
+// payload carries the "format", optional "filters" (*TimelineFilters) and the requesting user's
+// "scope" (*DataScope).
+func (jq *JobQueue) processExportJob(ctx context.Context, job *Job) error {
+	if jq.exportService == nil {
+		return fmt.Errorf("export service not available")
+	}
+
+	format, _ := job.Payload["format"].(string)
+	filters, _ := job.Payload["filters"].(*TimelineFilters)
+	scope, _ := job.Payload["scope"].(*DataScope)
+	ctx = WithDataScope(ctx, scope)
+
+	jq.updateJobStatus(job, JobStatusRunning, 10, fmt.Sprintf("Exporting incidents as %s", format))
+
+	export, err := jq.exportService.Export(ctx, filters, format)
+	if err != nil {
+		return fmt.Errorf("failed to export incidents: %w", err)
 	}
 
+	jq.updateJobStatus(job, JobStatusRunning, 90, fmt.Sprintf("Exported %d incidents", export.Rows))
+
+	jq.setJobResult(job, export)
+
 	return nil
 }
 
+// processCacheWarmJob precomputes the analytics dashboards load first, after the job's upload
+// added incidents
+func (jq *JobQueue) processCacheWarmJob(ctx context.Context, job *Job) error {
+	jq.updateJobStatus(job, JobStatusRunning, 10, "Warming analytics cache")
+
+	result, err := jq.cacheWarmer.WarmCache(ctx, time.Now())
+	if result != nil {
+		jq.setJobResult(job, result)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to warm analytics cache: %w", err)
+	}
+
+	jq.updateJobStatus(job, JobStatusRunning, 90,
+		fmt.Sprintf("Cached %d analytics results, %d failed", result.Entries, result.Failed))
+
+	return nil
+}
+
+// processRecategorizeJob re-runs the IT process categorization over the incidents matching the
+// job's "filters" (*TimelineFilters). Unless the payload asks for a "dry_run", the analytics
+// cache is cleared and warmed again once groups changed, so no cached result keeps the old
+// groups; the analytics views are computed from the incidents and need no refresh.
+func (jq *JobQueue) processRecategorizeJob(ctx context.Context, job *Job) error {
+	filters, _ := job.Payload["filters"].(*TimelineFilters)
+	dryRun, _ := job.Payload["dry_run"].(bool)
+
+	jq.updateJobStatus(job, JobStatusRunning, 5, "Recategorizing incidents")
+
+	result, err := jq.recategorizer.Recategorize(ctx, filters, dryRun, func(scanned, total int) {
+		progress := 5
+		if total > 0 {
+			progress += scanned * 85 / total
+		}
+		jq.updateJobStatus(job, JobStatusRunning, progress,
+			fmt.Sprintf("Recategorized %d/%d incidents", scanned, total))
+	})
+	if result != nil {
+		jq.setJobResult(job, result)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to recategorize incidents: %w", err)
+	}
+
+	if !dryRun && result.Changed > 0 && jq.cacheWarmer != nil {
+		if clearer, ok := jq.cacheWarmer.(cacheClearer); ok {
+			clearer.ClearCache()
+		}
+		jq.updateJobStatus(job, JobStatusRunning, 90,
+			fmt.Sprintf("Moved %d incidents to another group, warming analytics cache", result.Changed))
+		if _, err := jq.cacheWarmer.WarmCache(ctx, time.Now()); err != nil {
+			job.logf("Warning: Failed to warm analytics cache after recategorization: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// processRebuildJob rebuilds the analytics views, IT process groups and analytics cache from the
+// stored incidents
+func (jq *JobQueue) processRebuildJob(ctx context.Context, job *Job) error {
+	result, err := jq.rebuilder.Rebuild(ctx, jq.cacheWarmer, func(progress int, message string) {
+		jq.updateJobStatus(job, JobStatusRunning, progress, message)
+	})
+	if result != nil {
+		jq.setJobResult(job, result)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to rebuild derived artifacts: %w", err)
+	}
+	return nil
+}
+
+// setJobResult records a job's result under the job store lock
+func (jq *JobQueue) setJobResult(job *Job, result interface{}) {
+	jq.jobStoreMux.Lock()
+	defer jq.jobStoreMux.Unlock()
+
+	job.Result = result
+}
+
 // updateJobStatus updates the status and progress of a job
 func (jq *JobQueue) updateJobStatus(job *Job, status JobStatus, progress int, message string) {
 	jq.jobStoreMux.Lock()
@@ -416,72 +749,155 @@ func (jq *JobQueue) updateJobStatus(job *Job, status JobStatus, progress int, me
 	job.Progress = progress
 	job.Message = message
 
-	log.Printf("Job %s status updated: %s (%d%%) - %s", job.ID, status, progress, message)
+	job.logf("Job %s status updated: %s (%d%%) - %s", job.ID, status, progress, message)
 }
 
 // completeJob marks a job as completed
 func (jq *JobQueue) completeJob(job *Job) {
 	completedAt := time.Now()
+	jq.jobStoreMux.Lock()
 	job.CompletedAt = &completedAt
+	jq.jobStoreMux.Unlock()
 
 	jq.updateJobStatus(job, JobStatusCompleted, 100, "Job completed successfully")
 
-	log.Printf("Job %s completed successfully for upload %s", job.ID, job.UploadID)
+	job.logf("Job %s completed successfully for upload %s", job.ID, job.UploadID)
+}
+
+// cancelJob marks a job whose context was cancelled or timed out, keeping its partial progress.
+// Cancelled jobs are not retried.
+func (jq *JobQueue) cancelJob(job *Job, err error) {
+	completedAt := time.Now()
+	jq.jobStoreMux.Lock()
+	job.CompletedAt = &completedAt
+	job.Error = err.Error()
+	jq.jobStoreMux.Unlock()
+
+	jq.updateJobStatus(job, JobStatusCancelled, job.Progress,
+		fmt.Sprintf("Job cancelled at %d%%: %v", job.Progress, err))
 }
 
-// handleJobError handles job errors and implements retry logic
+// handleJobError handles job errors and implements retry logic. Every failed attempt is added to
+// the job's retry history.
 func (jq *JobQueue) handleJobError(job *Job, err error) {
+	jq.jobStoreMux.Lock()
 	job.Error = err.Error()
+	jq.jobStoreMux.Unlock()
+	attempt := JobAttempt{Attempt: job.RetryCount + 1, FailedAt: time.Now(), Error: err.Error()}
+	if job.StartedAt != nil {
+		startedAt := *job.StartedAt
+		attempt.StartedAt = &startedAt
+	}
 
-	log.Printf("Job %s failed: %v (retry %d/%d)", job.ID, err, job.RetryCount, job.MaxRetries)
+	job.logf("Job %s failed: %v (retry %d/%d)", job.ID, err, job.RetryCount, job.MaxRetries)
 
 	// Check if we should retry
 	if job.RetryCount < job.MaxRetries {
+		jq.jobStoreMux.Lock()
 		job.RetryCount++
+		jq.jobStoreMux.Unlock()
+		delay := jq.retryDelay(job.RetryCount)
+		retryAt := attempt.FailedAt.Add(delay)
+		attempt.RetryDelay = delay.String()
+		attempt.RetryAt = &retryAt
+		jq.recordAttempt(job, attempt, &retryAt)
+
 		jq.updateJobStatus(job, JobStatusRetrying, job.Progress,
-			fmt.Sprintf("Retrying job (attempt %d/%d): %v", job.RetryCount, job.MaxRetries, err))
-
-		// Exponential backoff for retry
-		retryDelay := time.Duration(job.RetryCount*job.RetryCount) * time.Second
-
-		go func() {
-			time.Sleep(retryDelay)
-
-			// Reset job for retry
-			job.Status = JobStatusPending
-			job.Error = ""
-
-			// Resubmit to queue
-			select {
-			case jq.jobs <- job:
-				log.Printf("Job %s resubmitted for retry %d", job.ID, job.RetryCount)
-			case <-jq.ctx.Done():
-				log.Printf("Cannot retry job %s: queue shutting down", job.ID)
-			default:
-				log.Printf("Cannot retry job %s: queue is full", job.ID)
-				jq.updateJobStatus(job, JobStatusFailed, job.Progress, "Failed to retry: queue full")
-			}
-		}()
+			fmt.Sprintf("Retrying job in %s (attempt %d/%d): %v", delay.Round(time.Millisecond), job.RetryCount, job.MaxRetries, err))
+		jq.scheduleRetry(job, delay)
 	} else {
 		// Max retries exceeded
+		jq.recordAttempt(job, attempt, nil)
 		completedAt := time.Now()
+		jq.jobStoreMux.Lock()
 		job.CompletedAt = &completedAt
+		jq.jobStoreMux.Unlock()
 		jq.updateJobStatus(job, JobStatusFailed, job.Progress,
 			fmt.Sprintf("Job failed after %d retries: %v", job.MaxRetries, err))
 	}
 }
 
+// retryDelay returns how long to wait before a job's retry-th retry: an exponential backoff from
+// the base delay, capped at the maximum, of which a random half is jitter so retries of jobs that
+// failed together spread out
+func (jq *JobQueue) retryDelay(retry int) time.Duration {
+	backoff := jq.retryBase
+	for i := 1; i < retry && backoff < jq.retryMax; i++ {
+		backoff *= 2
+	}
+	if backoff > jq.retryMax {
+		backoff = jq.retryMax
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// recordAttempt adds a failed attempt to a job's retry history and sets when it is retried
+func (jq *JobQueue) recordAttempt(job *Job, attempt JobAttempt, retryAt *time.Time) {
+	jq.jobStoreMux.Lock()
+	defer jq.jobStoreMux.Unlock()
+
+	job.RetryHistory = append(job.RetryHistory, attempt)
+	job.NextRetryAt = retryAt
+}
+
+// scheduleRetry resubmits a job once its retry delay has passed. Resubmission waits for room in
+// the queue rather than dropping the retry; a retry still waiting when the queue shuts down
+// cancels the job.
+func (jq *JobQueue) scheduleRetry(job *Job, delay time.Duration) {
+	if jq.ctx.Err() != nil {
+		jq.abandonRetry(job)
+		return
+	}
+
+	jq.wg.Add(1)
+	go func() {
+		defer jq.wg.Done()
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-jq.ctx.Done():
+			jq.abandonRetry(job)
+			return
+		}
+
+		// Reset job for retry
+		jq.jobStoreMux.Lock()
+		job.Status = JobStatusPending
+		job.Error = ""
+		job.NextRetryAt = nil
+		jq.jobStoreMux.Unlock()
+
+		select {
+		case jq.jobs <- job:
+			job.logf("Job %s resubmitted for retry %d", job.ID, job.RetryCount)
+		case <-jq.ctx.Done():
+			jq.abandonRetry(job)
+		}
+	}()
+}
+
+// abandonRetry cancels a job whose retry could not be resubmitted before shutdown
+func (jq *JobQueue) abandonRetry(job *Job) {
+	job.logf("Cannot retry job %s: queue shutting down", job.ID)
+	jq.jobStoreMux.Lock()
+	job.NextRetryAt = nil
+	jq.jobStoreMux.Unlock()
+	jq.cancelJob(job, fmt.Errorf("retry %d abandoned: job queue is shutting down", job.RetryCount))
+}
+
 // Shutdown gracefully shuts down the job queue
 func (jq *JobQueue) Shutdown() {
 	log.Println("Shutting down job queue...")
 
+	// Cancelling the queue context stops the workers and any in-flight job attempts.
+	// The jobs channel is left open so late submissions and retries cannot panic.
 	jq.cancel()
 
-	// Close the jobs channel to signal workers to stop
-	close(jq.jobs)
-
-	// Wait for all workers to finish
+	// Wait for all workers and scheduled retries to finish
 	jq.wg.Wait()
+	jq.cancelHeld()
 
 	log.Println("Job queue shutdown complete")
 }
@@ -494,7 +910,7 @@ func generateJobID() string {
 }
 
 // updateIncidentsSentiment updates sentiment data for incidents in the database
-func (jq *JobQueue) updateIncidentsSentiment(incidents []models.Incident) error {
+func (jq *JobQueue) updateIncidentsSentiment(ctx context.Context, incidents []models.Incident) error {
 	// This would typically be implemented in the incident service
 	// For now, we'll implement a simple batch update
 
@@ -505,18 +921,23 @@ func (jq *JobQueue) updateIncidentsSentiment(incidents []models.Incident) error
 			WHERE id = ?
 		`
 
-		_, err := jq.processingService.db.ExecContext(jq.ctx, query,
+		_, err := jq.processingService.db.ExecContext(ctx, query,
 			incident.SentimentScore, incident.SentimentLabel, time.Now(), incident.ID)
 		if err != nil {
 			return fmt.Errorf("failed to update sentiment for incident %s: %w", incident.ID, err)
 		}
+		if event := AnalysisEvent(incident, EventSourceAnalysisJob, time.Now(), FeedbackAnalyzerSentiment); event != nil {
+			if err := insertIncidentEvents(ctx, jq.processingService.db, []IncidentEvent{*event}); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
 // updateIncidentsAutomation updates automation data for incidents in the database
-func (jq *JobQueue) updateIncidentsAutomation(incidents []models.Incident) error {
+func (jq *JobQueue) updateIncidentsAutomation(ctx context.Context, incidents []models.Incident) error {
 	// This would typically be implemented in the incident service
 	// For now, we'll implement a simple batch update
 
@@ -527,12 +948,17 @@ func (jq *JobQueue) updateIncidentsAutomation(incidents []models.Incident) error
 			WHERE id = ?
 		`
 
-		_, err := jq.processingService.db.ExecContext(jq.ctx, query,
+		_, err := jq.processingService.db.ExecContext(ctx, query,
 			incident.AutomationScore, incident.AutomationFeasible, incident.ITProcessGroup,
 			time.Now(), incident.ID)
 		if err != nil {
 			return fmt.Errorf("failed to update automation data for incident %s: %w", incident.ID, err)
 		}
+		if event := AnalysisEvent(incident, EventSourceAnalysisJob, time.Now(), FeedbackAnalyzerAutomation); event != nil {
+			if err := insertIncidentEvents(ctx, jq.processingService.db, []IncidentEvent{*event}); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
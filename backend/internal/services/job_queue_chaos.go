@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrChaosInjected is the error of a job attempt failed on purpose by the chaos mode
+var ErrChaosInjected = errors.New("chaos: injected job failure")
+
+// defaultChaosMaxDelay bounds injected delays when no maximum is configured
+const defaultChaosMaxDelay = 5 * time.Second
+
+// ChaosConfig injects failures and delays into job attempts so retries, failed jobs and alerting
+// can be exercised in test and staging environments. It must not be enabled in production.
+type ChaosConfig struct {
+	// FailurePct is the share of job attempts, 0 to 100, that fail with ErrChaosInjected
+	FailurePct float64
+	// DelayPct is the share of job attempts, 0 to 100, delayed before they run
+	DelayPct float64
+	// MaxDelay bounds the injected delays, which are drawn uniformly up to it
+	MaxDelay time.Duration
+	// Seed makes the injected failures and delays reproducible; 0 picks a random seed
+	Seed int64
+}
+
+// Enabled reports whether the configuration injects anything
+func (c ChaosConfig) Enabled() bool {
+	return c.FailurePct > 0 || c.DelayPct > 0
+}
+
+// ChaosStats counts the job attempts the chaos mode has seen and changed
+type ChaosStats struct {
+	Enabled  bool  `json:"enabled"`
+	Seed     int64 `json:"seed,omitempty"`
+	Attempts int   `json:"attempts"`
+	Failures int   `json:"failures"`
+	Delays   int   `json:"delays"`
+}
+
+// jobChaos draws the failures and delays of job attempts. Draws are taken in the order attempts
+// start, so a seeded run is reproducible when jobs start in the same order, such as with one
+// worker.
+type jobChaos struct {
+	mu     sync.Mutex
+	config ChaosConfig
+	rng    *rand.Rand
+	stats  ChaosStats
+}
+
+// SetChaos turns the failure-injection mode on for job attempts started from now on, or off when
+// the configuration injects nothing
+func (jq *JobQueue) SetChaos(config ChaosConfig) {
+	if !config.Enabled() {
+		jq.chaos.Store(nil)
+		return
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = defaultChaosMaxDelay
+	}
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+	log.Printf("WARNING: job queue chaos mode enabled (failures %.1f%%, delays %.1f%% up to %s, seed %d)",
+		config.FailurePct, config.DelayPct, config.MaxDelay, config.Seed)
+
+	jq.chaos.Store(&jobChaos{
+		config: config,
+		rng:    rand.New(rand.NewSource(config.Seed)),
+		stats:  ChaosStats{Enabled: true, Seed: config.Seed},
+	})
+}
+
+// ChaosStats reports what the chaos mode has injected since it was last set
+func (jq *JobQueue) ChaosStats() ChaosStats {
+	chaos := jq.chaos.Load()
+	if chaos == nil {
+		return ChaosStats{}
+	}
+	chaos.mu.Lock()
+	defer chaos.mu.Unlock()
+	return chaos.stats
+}
+
+// injectChaos delays or fails a job attempt as drawn by the chaos mode. A delay ends early when
+// the attempt's context is done.
+func (jq *JobQueue) injectChaos(ctx context.Context, job *Job) error {
+	chaos := jq.chaos.Load()
+	if chaos == nil {
+		return nil
+	}
+	fail, delay := chaos.draw()
+
+	if delay > 0 {
+		job.logf("Chaos: delaying job %s (%s) by %s", job.ID, job.Type, delay)
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if fail {
+		job.logf("Chaos: failing job %s (%s) attempt %d", job.ID, job.Type, job.RetryCount+1)
+		return fmt.Errorf("%w (attempt %d)", ErrChaosInjected, job.RetryCount+1)
+	}
+	return nil
+}
+
+// draw decides whether the next attempt fails and how long it is delayed
+func (c *jobChaos) draw() (bool, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.Attempts++
+	fail := c.rng.Float64()*100 < c.config.FailurePct
+	var delay time.Duration
+	if c.rng.Float64()*100 < c.config.DelayPct {
+		delay = time.Duration(c.rng.Int63n(int64(c.config.MaxDelay)) + 1)
+	}
+	if fail {
+		c.stats.Failures++
+	}
+	if delay > 0 {
+		c.stats.Delays++
+	}
+	return fail, delay
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// errPreempted is the cancellation cause of a background job giving way to urgent processing
+var errPreempted = errors.New("preempted by urgent upload processing")
+
+// preemptibleJobs are the background job types urgent upload processing holds back: cache
+// warming, the re-categorization backfill and derived artifact rebuilds, which all give the same
+// result when run later
+var preemptibleJobs = map[JobType]bool{
+	JobTypeWarmCache:        true,
+	JobTypeRecategorize:     true,
+	JobTypeRebuildArtifacts: true,
+}
+
+// jobPreemption tracks the urgent processing runs holding back background jobs
+type jobPreemption struct {
+	mu      sync.Mutex
+	urgent  int                                // urgent runs in progress
+	running map[string]context.CancelCauseFunc // preemptible jobs running, by ID
+	held    []*Job                             // preemptible jobs waiting for the urgent runs to end
+}
+
+// Preempt holds back background jobs until the returned release function is called: running
+// cache warming and backfill jobs are cancelled and queued again, and those starting meanwhile
+// wait. While several urgent runs hold the queue, the jobs resume once the last is released.
+func (jq *JobQueue) Preempt() (release func()) {
+	p := &jq.preemption
+	p.mu.Lock()
+	p.urgent++
+	for _, cancel := range p.running {
+		cancel(errPreempted)
+	}
+	p.mu.Unlock()
+
+	var once sync.Once
+	return func() { once.Do(jq.endPreemption) }
+}
+
+// endPreemption ends one urgent run's hold, queuing the held jobs again after the last
+func (jq *JobQueue) endPreemption() {
+	p := &jq.preemption
+	p.mu.Lock()
+	p.urgent--
+	var held []*Job
+	if p.urgent == 0 {
+		held, p.held = p.held, nil
+	}
+	p.mu.Unlock()
+
+	for _, job := range held {
+		jq.resubmitHeld(job)
+	}
+}
+
+// admitPreemptible lets a preemptible job run, registering cancel so the next urgent run can
+// preempt it, unless urgent processing holds the queue, in which case the job is held until it
+// ends and false is returned
+func (jq *JobQueue) admitPreemptible(job *Job, cancel context.CancelCauseFunc) bool {
+	p := &jq.preemption
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.urgent > 0 {
+		jq.holdJob(job)
+		return false
+	}
+	if p.running == nil {
+		p.running = make(map[string]context.CancelCauseFunc)
+	}
+	p.running[job.ID] = cancel
+	return true
+}
+
+// finishPreemptible forgets a preemptible job that stopped running and, when it was preempted,
+// holds it for another run once urgent processing ends
+func (jq *JobQueue) finishPreemptible(job *Job, preempted bool) {
+	p := &jq.preemption
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.running, job.ID)
+	if !preempted {
+		return
+	}
+	if p.urgent > 0 {
+		jq.holdJob(job)
+		return
+	}
+	// The urgent run already ended
+	jq.resubmitHeld(job)
+}
+
+// holdJob parks a job until the urgent runs end; the preemption lock must be held
+func (jq *JobQueue) holdJob(job *Job) {
+	jq.preemption.held = append(jq.preemption.held, job)
+	jq.updateJobStatus(job, JobStatusPending, 0, "Held back by urgent upload processing")
+}
+
+// resubmitHeld queues a held job again, waiting for room in the queue rather than dropping it; a
+// job still waiting when the queue shuts down is cancelled
+func (jq *JobQueue) resubmitHeld(job *Job) {
+	if jq.ctx.Err() != nil {
+		jq.cancelJob(job, fmt.Errorf("job queue is shutting down"))
+		return
+	}
+
+	jq.wg.Add(1)
+	go func() {
+		defer jq.wg.Done()
+		select {
+		case jq.jobs <- job:
+			job.logf("Job %s resubmitted after urgent upload processing", job.ID)
+		case <-jq.ctx.Done():
+			jq.cancelJob(job, fmt.Errorf("job queue is shutting down"))
+		}
+	}()
+}
+
+// cancelHeld cancels the jobs still held when the queue shuts down
+func (jq *JobQueue) cancelHeld() {
+	p := &jq.preemption
+	p.mu.Lock()
+	held := p.held
+	p.held = nil
+	p.mu.Unlock()
+
+	for _, job := range held {
+		jq.cancelJob(job, fmt.Errorf("job queue is shutting down"))
+	}
+}
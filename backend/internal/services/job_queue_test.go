@@ -1,10 +1,15 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"incident-management-system/internal/database"
+	"incident-management-system/internal/logging"
 	"incident-management-system/internal/models"
 	"incident-management-system/internal/storage"
 
@@ -129,6 +134,77 @@ func TestJobQueue_SubmitJob(t *testing.T) {
 	}
 }
 
+func TestJobQueue_SubmitJobContext(t *testing.T) {
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+	db := dbWrapper.GetConnection()
+	dir := t.TempDir()
+	processingService := NewProcessingService(db, storage.NewFileStore(dir))
+	jobQueue := NewJobQueue(JobQueueConfig{Workers: 1, BufferSize: 10}, processingService)
+	defer jobQueue.Shutdown()
+
+	writeTestWorkbook(t, dir, "traced.xlsx", [][]string{
+		{"Incident ID", "Report Date", "Application Name", "Priority", "Resolved Person", "Brief Description", "Resolution Group"},
+		{"INC001", "2024-01-15", "Portal", "P2", "Jane", "Login failure", "Web Team"},
+	})
+	if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+		"upload-traced", "traced.xlsx", "traced.xlsx", models.UploadStatusUploaded); err != nil {
+		t.Fatalf("Failed to create upload: %v", err)
+	}
+
+	ctx := logging.WithUserID(logging.WithRequestID(context.Background(), "req-42"), "alice")
+	job, err := jobQueue.SubmitJobContext(ctx, JobTypeProcessUpload, "upload-traced", nil)
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	if job.RequestID != "req-42" || job.UserID != "alice" || job.Payload["request_id"] != "req-42" || job.Payload["user_id"] != "alice" {
+		t.Errorf("Expected the request and user on the job and its payload, got %+v", job)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		jobQueue.jobStoreMux.RLock()
+		status := job.Status
+		jobQueue.jobStoreMux.RUnlock()
+		if status == JobStatusCompleted || status == JobStatusFailed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Job did not finish, status %s", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The upload's status transitions are recorded against the submitting request
+	events, err := processingService.incidentService.ListUploadEvents(context.Background(), "upload-traced")
+	if err != nil {
+		t.Fatalf("Failed to list upload events: %v", err)
+	}
+	if len(events) == 0 {
+		t.Fatal("Expected the job to move the upload")
+	}
+	for _, event := range events {
+		if event.RequestID != "req-42" || event.UserID != "alice" {
+			t.Errorf("Expected the event traced to req-42 by alice, got %+v", event)
+		}
+	}
+
+	// Jobs submitted outside a request carry neither
+	job, err = jobQueue.SubmitJob(JobTypeExportIncidents, "", map[string]interface{}{"format": "csv"})
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	if _, found := job.Payload["request_id"]; found || job.RequestID != "" {
+		t.Errorf("Expected no request on a job submitted without one, got %+v", job)
+	}
+}
+
 func TestJobQueue_GetJob(t *testing.T) {
 	// Create a mock database for testing
 	config := &database.Config{
@@ -189,6 +265,15 @@ func TestJobQueue_GetJob(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error when getting non-existent job")
 	}
+
+	// A snapshot is a copy, unaffected by later updates of the job
+	snapshot, err := jobQueue.JobSnapshot(submittedJob.ID)
+	if err != nil || snapshot == submittedJob || snapshot.ID != submittedJob.ID {
+		t.Fatalf("Expected a copy of the job, got %p of %p: %v", snapshot, submittedJob, err)
+	}
+	if _, err := jobQueue.JobSnapshot("non-existent-job"); err == nil {
+		t.Error("Expected error when taking a snapshot of a non-existent job")
+	}
 }
 
 func TestJobQueue_GetJobsByUpload(t *testing.T) {
@@ -501,3 +586,318 @@ func TestJobQueue_HandleJobError(t *testing.T) {
 	// Shutdown the queue
 	jobQueue.Shutdown()
 }
+
+func TestJobQueue_CancelledJobNotRetried(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	processingService := NewProcessingService(dbWrapper.GetConnection(), storage.NewFileStore("/tmp"))
+	jobQueue := NewJobQueue(JobQueueConfig{Workers: 1, BufferSize: 10}, processingService)
+
+	// Shut down first so the job attempt runs with an already-cancelled context
+	jobQueue.Shutdown()
+
+	job := &Job{
+		ID:         "test-job-cancel",
+		Type:       JobTypeSentimentAnalysis,
+		Status:     JobStatusPending,
+		UploadID:   "upload-123",
+		Payload:    map[string]interface{}{},
+		MaxRetries: 3,
+		CreatedAt:  time.Now(),
+	}
+	jobQueue.SetSentimentService(NewSimpleSentimentAnalyzer())
+	jobQueue.processJob(0, job)
+
+	if job.Status != JobStatusCancelled {
+		t.Errorf("Expected job status cancelled, got %s", job.Status)
+	}
+
+	if job.RetryCount != 0 {
+		t.Errorf("Expected no retries for a cancelled job, got %d", job.RetryCount)
+	}
+
+	if job.CompletedAt == nil {
+		t.Error("Expected completed time to be set")
+	}
+
+	// Submitting after shutdown must fail without panicking
+	if _, err := jobQueue.SubmitJob(JobTypeProcessUpload, "upload-456", nil); err == nil {
+		t.Error("Expected error when submitting job after shutdown")
+	}
+}
+
+func TestJobQueue_Chaos(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	processingService := NewProcessingService(dbWrapper.GetConnection(), storage.NewFileStore("/tmp"))
+	jobQueue := NewJobQueue(JobQueueConfig{Workers: 1, BufferSize: 10}, processingService)
+	defer jobQueue.Shutdown()
+	jobQueue.SetSentimentService(NewSimpleSentimentAnalyzer())
+
+	// Every attempt fails, so a job without retries ends up failed with the injected error
+	jobQueue.SetChaos(ChaosConfig{FailurePct: 100, Seed: 42})
+	job := &Job{
+		ID:         "test-job-chaos",
+		Type:       JobTypeSentimentAnalysis,
+		Status:     JobStatusPending,
+		UploadID:   "upload-123",
+		Payload:    map[string]interface{}{},
+		MaxRetries: 0,
+		CreatedAt:  time.Now(),
+	}
+	jobQueue.processJob(0, job)
+
+	if job.Status != JobStatusFailed {
+		t.Errorf("Expected job status failed, got %s", job.Status)
+	}
+	if !strings.Contains(job.Error, ErrChaosInjected.Error()) {
+		t.Errorf("Expected the injected error, got %q", job.Error)
+	}
+	if stats := jobQueue.ChaosStats(); !stats.Enabled || stats.Seed != 42 || stats.Attempts != 1 || stats.Failures != 1 {
+		t.Errorf("Expected one injected failure, got %+v", stats)
+	}
+
+	// Nothing to inject turns the mode off
+	jobQueue.SetChaos(ChaosConfig{})
+	if stats := jobQueue.ChaosStats(); stats.Enabled {
+		t.Errorf("Expected chaos mode to be off, got %+v", stats)
+	}
+}
+
+func TestJobChaos_SeededDrawsAreReproducible(t *testing.T) {
+	draws := func() ([]bool, []time.Duration, ChaosStats) {
+		jobQueue := &JobQueue{}
+		jobQueue.SetChaos(ChaosConfig{FailurePct: 30, DelayPct: 20, MaxDelay: time.Second, Seed: 7})
+		chaos := jobQueue.chaos.Load()
+
+		var fails []bool
+		var delays []time.Duration
+		for i := 0; i < 1000; i++ {
+			fail, delay := chaos.draw()
+			fails = append(fails, fail)
+			delays = append(delays, delay)
+		}
+		return fails, delays, jobQueue.ChaosStats()
+	}
+
+	fails, delays, stats := draws()
+	againFails, againDelays, _ := draws()
+	for i := range fails {
+		if fails[i] != againFails[i] || delays[i] != againDelays[i] {
+			t.Fatalf("Expected the same draws for the same seed, draw %d differs", i)
+		}
+	}
+
+	if stats.Attempts != 1000 || stats.Failures < 250 || stats.Failures > 350 || stats.Delays < 150 || stats.Delays > 250 {
+		t.Errorf("Expected about 30%% failures and 20%% delays, got %+v", stats)
+	}
+	for _, delay := range delays {
+		if delay < 0 || delay > time.Second {
+			t.Fatalf("Expected delays within the maximum, got %s", delay)
+		}
+	}
+}
+
+func TestJobQueue_RetryDelay(t *testing.T) {
+	jobQueue := &JobQueue{retryBase: 100 * time.Millisecond, retryMax: time.Second}
+
+	// Each retry doubles the backoff up to the cap, and half of it is jitter
+	backoffs := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, backoff := range backoffs {
+		backoff *= time.Millisecond
+		seen := map[time.Duration]bool{}
+		for draw := 0; draw < 200; draw++ {
+			delay := jobQueue.retryDelay(i + 1)
+			if delay < backoff/2 || delay > backoff {
+				t.Fatalf("Expected retry %d to wait between %s and %s, got %s", i+1, backoff/2, backoff, delay)
+			}
+			seen[delay] = true
+		}
+		if len(seen) < 2 {
+			t.Errorf("Expected jittered delays for retry %d, got %v", i+1, seen)
+		}
+	}
+}
+
+func TestJobQueue_RetryHistory(t *testing.T) {
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	processingService := NewProcessingService(dbWrapper.GetConnection(), storage.NewFileStore("/tmp"))
+	jobQueue := NewJobQueue(JobQueueConfig{
+		Workers:        1,
+		BufferSize:     1,
+		RetryBaseDelay: time.Millisecond,
+		RetryMaxDelay:  4 * time.Millisecond,
+	}, processingService)
+	defer jobQueue.Shutdown()
+	jobQueue.SetSentimentService(NewSimpleSentimentAnalyzer())
+	jobQueue.SetChaos(ChaosConfig{FailurePct: 100, Seed: 1})
+
+	job, err := jobQueue.SubmitJob(JobTypeSentimentAnalysis, "upload-123", nil)
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		jobQueue.jobStoreMux.RLock()
+		status := job.Status
+		jobQueue.jobStoreMux.RUnlock()
+		if status == JobStatusFailed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the job to fail after its retries, still %s", status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	jobQueue.jobStoreMux.RLock()
+	defer jobQueue.jobStoreMux.RUnlock()
+	if len(job.RetryHistory) != job.MaxRetries+1 {
+		t.Fatalf("Expected %d attempts in the retry history, got %+v", job.MaxRetries+1, job.RetryHistory)
+	}
+	for i, attempt := range job.RetryHistory {
+		if attempt.Attempt != i+1 || !strings.Contains(attempt.Error, ErrChaosInjected.Error()) || attempt.StartedAt == nil {
+			t.Errorf("Expected attempt %d to record its injected failure, got %+v", i+1, attempt)
+		}
+		retried := i < job.MaxRetries
+		if (attempt.RetryAt != nil) != retried || (attempt.RetryDelay != "") != retried {
+			t.Errorf("Expected only retried attempts to record a retry, got %+v", attempt)
+		}
+	}
+	if job.NextRetryAt != nil {
+		t.Errorf("Expected no retry scheduled for a failed job, got %v", job.NextRetryAt)
+	}
+}
+
+func TestJobQueue_RetryAbandonedOnShutdown(t *testing.T) {
+	jobQueue := NewJobQueue(JobQueueConfig{Workers: 1, RetryBaseDelay: time.Hour}, nil)
+
+	job := &Job{ID: "test-job-retry", Type: JobTypeSentimentAnalysis, Status: JobStatusRunning, MaxRetries: 1, CreatedAt: time.Now()}
+	jobQueue.handleJobError(job, errors.New("temporary failure"))
+	if job.Status != JobStatusRetrying || job.NextRetryAt == nil {
+		t.Fatalf("Expected a scheduled retry, got %s at %v", job.Status, job.NextRetryAt)
+	}
+
+	// Shutdown does not wait out the backoff; the pending retry cancels the job
+	done := make(chan struct{})
+	go func() {
+		jobQueue.Shutdown()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected shutdown to stop the pending retry")
+	}
+
+	if job.Status != JobStatusCancelled || job.NextRetryAt != nil || job.CompletedAt == nil {
+		t.Errorf("Expected the job to be cancelled, got %s (next retry %v)", job.Status, job.NextRetryAt)
+	}
+	if !strings.Contains(job.Error, "abandoned") {
+		t.Errorf("Expected the abandoned retry to be reported, got %q", job.Error)
+	}
+}
+
+// blockingWarmer is a cache warmer whose first warm runs until its context is cancelled
+type blockingWarmer struct {
+	started chan struct{}
+	calls   atomic.Int32
+}
+
+func (w *blockingWarmer) WarmCache(ctx context.Context, now time.Time) (*CacheWarmResult, error) {
+	if w.calls.Add(1) > 1 {
+		return &CacheWarmResult{}, nil
+	}
+	close(w.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestJobQueue_Preempt(t *testing.T) {
+	jobQueue := NewJobQueue(JobQueueConfig{Workers: 1, BufferSize: 10}, nil)
+	defer jobQueue.Shutdown()
+	warmer := &blockingWarmer{started: make(chan struct{})}
+	jobQueue.SetCacheWarmer(warmer)
+
+	job, err := jobQueue.SubmitJob(JobTypeWarmCache, "upload-1", nil)
+	if err != nil {
+		t.Fatalf("SubmitJob failed: %v", err)
+	}
+	<-warmer.started
+
+	release := jobQueue.Preempt()
+	waitForJobStatus(t, jobQueue, job.ID, JobStatusPending)
+
+	// A job started while urgent processing runs is held too
+	held, err := jobQueue.SubmitJob(JobTypeWarmCache, "upload-2", nil)
+	if err != nil {
+		t.Fatalf("SubmitJob failed: %v", err)
+	}
+	waitForJobStatus(t, jobQueue, held.ID, JobStatusPending)
+	time.Sleep(20 * time.Millisecond)
+	if calls := warmer.calls.Load(); calls != 1 {
+		t.Errorf("Expected no warm while preempted, got %d warms", calls)
+	}
+
+	release()
+	release() // releasing twice must not end another run's hold
+	waitForJobStatus(t, jobQueue, job.ID, JobStatusCompleted)
+	waitForJobStatus(t, jobQueue, held.ID, JobStatusCompleted)
+	if job.RetryCount != 0 {
+		t.Errorf("Expected a preempted job not to use up retries, got %d", job.RetryCount)
+	}
+}
+
+// waitForJobStatus waits up to a second for a job to reach status
+func waitForJobStatus(t *testing.T, jobQueue *JobQueue, jobID string, status JobStatus) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		job, err := jobQueue.GetJob(jobID)
+		if err != nil {
+			t.Fatalf("GetJob failed: %v", err)
+		}
+		jobQueue.jobStoreMux.RLock()
+		current, message := job.Status, job.Message
+		jobQueue.jobStoreMux.RUnlock()
+		if current == status {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected job %s to be %s, got %s (%s)", jobID, status, current, message)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
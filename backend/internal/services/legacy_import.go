@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrUnsupportedExport is returned for a local export that is neither a workbook nor a CSV file
+var ErrUnsupportedExport = errors.New("unsupported export format: only .xlsx, .xls and .csv files are supported")
+
+// ImportLocalFile copies an export from the local file system into the file store and creates
+// its upload record, ready to be processed. CSV files are converted to a workbook as they are
+// copied; delimiter separates their fields. name is recorded as the upload's original filename.
+func (s *ProcessingService) ImportLocalFile(ctx context.Context, path, name string, delimiter rune) (*models.Upload, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	defer src.Close()
+
+	ext := strings.ToLower(filepath.Ext(path))
+	var filename string
+	switch ext {
+	case ".xlsx", ".xls":
+		filename, err = s.fileStore.SaveGeneratedFile(filepath.Base(path), func(w io.Writer) error {
+			_, err := io.Copy(w, src)
+			return err
+		})
+	case ".csv":
+		workbookName := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) + ".xlsx"
+		filename, err = s.fileStore.SaveGeneratedFile(workbookName, func(w io.Writer) error {
+			return writeCSVWorkbook(w, src, delimiter)
+		})
+	default:
+		return nil, ErrUnsupportedExport
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &models.Upload{
+		ID:               uuid.New().String(),
+		Filename:         filename,
+		OriginalFilename: name,
+		Status:           models.UploadStatusUploaded,
+		Errors:           []string{},
+		CreatedAt:        time.Now(),
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO uploads (id, filename, original_filename, status, record_count,
+			processed_count, error_count, errors, created_at)
+		VALUES (?, ?, ?, ?, 0, 0, 0, '[]', ?)
+	`, upload.ID, upload.Filename, upload.OriginalFilename, upload.Status, upload.CreatedAt); err != nil {
+		s.fileStore.DeleteFile(filename)
+		return nil, fmt.Errorf("failed to create upload record: %w", err)
+	}
+	return upload, nil
+}
+
+// FindImportedUpload returns the ID of the latest upload of the original filename that was
+// processed successfully, or "" when there is none
+func (s *ProcessingService) FindImportedUpload(ctx context.Context, name string) (string, error) {
+	var uploadID string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id FROM uploads
+		WHERE original_filename = ? AND status IN (?, ?)
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, name, models.UploadStatusCompleted, models.UploadStatusCompletedWithErrors).Scan(&uploadID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query imported uploads: %w", err)
+	}
+	return uploadID, nil
+}
+
+// writeCSVWorkbook streams the records of a CSV file into the first sheet of a workbook, so
+// large exports are never held in memory as cells
+func writeCSVWorkbook(w io.Writer, r io.Reader, delimiter rune) error {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	f := excelize.NewFile()
+	defer f.Close()
+	stream, err := f.NewStreamWriter("Sheet1")
+	if err != nil {
+		return err
+	}
+
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if row == 1 && len(record) > 0 {
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+		}
+		cell, err := excelize.CoordinatesToCellName(1, row)
+		if err != nil {
+			return err
+		}
+		values := make([]interface{}, len(record))
+		for i, value := range record {
+			values[i] = value
+		}
+		if err := stream.SetRow(cell, values); err != nil {
+			return err
+		}
+	}
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+	return f.Write(w)
+}
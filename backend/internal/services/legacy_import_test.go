@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/storage"
+)
+
+func TestProcessingService_ImportLocalFile(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	dir := t.TempDir()
+	service := NewProcessingService(db, storage.NewFileStore(filepath.Join(dir, "uploads")))
+	ctx := context.Background()
+
+	// A semicolon-separated export with a byte order mark is converted to a workbook
+	export := filepath.Join(dir, "2019.csv")
+	content := "\ufeffIncident ID;Report Date;Application Name;Priority;Status;Resolved Person;Brief Description;Resolution Group\n" +
+		"L1;2019-01-15;Portal;P2;Open;Jane;\"Login; failure\";Web Team\n"
+	if err := os.WriteFile(export, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write export: %v", err)
+	}
+
+	upload, err := service.ImportLocalFile(ctx, export, "legacy/2019.csv", ';')
+	if err != nil {
+		t.Fatalf("ImportLocalFile() error = %v", err)
+	}
+	if upload.OriginalFilename != "legacy/2019.csv" || filepath.Ext(upload.Filename) != ".xlsx" {
+		t.Errorf("expected a workbook recorded under the export's path, got %+v", upload)
+	}
+
+	// Not imported until it is processed
+	if uploadID, err := service.FindImportedUpload(ctx, "legacy/2019.csv"); err != nil || uploadID != "" {
+		t.Errorf("expected no imported upload yet, got %q, %v", uploadID, err)
+	}
+	options := models.DefaultProcessingOptions()
+	options.RunSentiment, options.RunAutomation = false, false
+	progress, err := service.ProcessUploadWithOptions(ctx, upload.ID, options)
+	if err != nil {
+		t.Fatalf("ProcessUploadWithOptions() error = %v", err)
+	}
+	if progress.ProcessedRows != 1 {
+		t.Errorf("expected the exported incident stored, got %+v", progress)
+	}
+	incidents, err := NewIncidentService(db).GetIncidentsByUpload(ctx, upload.ID)
+	if err != nil || len(incidents) != 1 || incidents[0].BriefDescription != "Login; failure" {
+		t.Errorf("expected the quoted description kept whole, got %+v, %v", incidents, err)
+	}
+	if uploadID, err := service.FindImportedUpload(ctx, "legacy/2019.csv"); err != nil || uploadID != upload.ID {
+		t.Errorf("expected the processed upload found, got %q, %v", uploadID, err)
+	}
+
+	if _, err := service.ImportLocalFile(ctx, filepath.Join(dir, "notes.txt"), "notes.txt", ','); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := service.ImportLocalFile(ctx, filepath.Join(dir, "notes.txt"), "notes.txt", ','); err != ErrUnsupportedExport {
+		t.Errorf("expected ErrUnsupportedExport, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Cache statuses reported in data lineage
+const (
+	LineageCacheHit     = "hit"
+	LineageCacheMiss    = "miss"
+	LineageCachePartial = "partial" // some results were cached and others computed
+	LineageCacheBypass  = "bypass"  // the endpoint does not cache its results
+)
+
+// LineageUpload is an upload the incidents behind a response came from
+type LineageUpload struct {
+	UploadID      string `json:"upload_id"`
+	Filename      string `json:"filename,omitempty"`
+	IncidentCount int    `json:"incident_count"`
+}
+
+// DataLineage describes the data behind an analytics response so consumers can verify what a
+// chart is based on. DataVersion is a fingerprint of the matching incidents and their last
+// update, so it changes whenever one of them is added, removed or updated.
+type DataLineage struct {
+	Uploads       []LineageUpload `json:"uploads"`
+	StartDate     *time.Time      `json:"start_date,omitempty"`
+	EndDate       *time.Time      `json:"end_date,omitempty"`
+	IncidentCount int             `json:"incident_count"`
+	DataVersion   string          `json:"data_version"`
+	Cache         string          `json:"cache"`
+	GeneratedAt   time.Time       `json:"generated_at"`
+}
+
+// CacheTrace records whether the analytics a request ran were served from the cache
+type CacheTrace struct {
+	mu     sync.Mutex
+	hits   int
+	misses int
+}
+
+type cacheTraceKey struct{}
+
+// WithCacheTrace returns a context whose cached analytics lookups are recorded in the trace
+func WithCacheTrace(ctx context.Context) (context.Context, *CacheTrace) {
+	trace := &CacheTrace{}
+	return context.WithValue(ctx, cacheTraceKey{}, trace), trace
+}
+
+// cacheTraceFromContext returns the request's cache trace, or nil when it is not traced
+func cacheTraceFromContext(ctx context.Context) *CacheTrace {
+	trace, _ := ctx.Value(cacheTraceKey{}).(*CacheTrace)
+	return trace
+}
+
+// record counts one cached lookup. A nil trace records nothing.
+func (t *CacheTrace) record(hit bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if hit {
+		t.hits++
+	} else {
+		t.misses++
+	}
+}
+
+// Status summarizes the recorded lookups as one of the LineageCache statuses
+func (t *CacheTrace) Status() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case t.hits == 0 && t.misses == 0:
+		return LineageCacheBypass
+	case t.misses == 0:
+		return LineageCacheHit
+	case t.hits == 0:
+		return LineageCacheMiss
+	default:
+		return LineageCachePartial
+	}
+}
+
+// GetDataLineage reports which uploads and report dates the incidents matching the filters come
+// from, including archived incidents when the analytics would read them. The cache status is left
+// for the caller to fill in.
+func (s *AnalyticsService) GetDataLineage(ctx context.Context, filters *TimelineFilters) (*DataLineage, error) {
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query := `
+		SELECT l.upload_id, COALESCE(u.original_filename, ''), l.incident_count, l.first_report, l.last_report,
+			l.fingerprint
+		FROM (
+			SELECT
+				upload_id,
+				COUNT(*) as incident_count,
+				MIN(report_date) as first_report,
+				MAX(report_date) as last_report,
+				BIT_XOR(hash(id, COALESCE(updated_at, created_at))) as fingerprint
+			FROM incidents
+			WHERE 1=1` + whereClause + `
+			GROUP BY upload_id
+		) l
+		LEFT JOIN uploads u ON u.id = l.upload_id
+		ORDER BY l.upload_id`
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data lineage: %w", err)
+	}
+	defer rows.Close()
+
+	lineage := &DataLineage{Uploads: []LineageUpload{}, GeneratedAt: time.Now().UTC()}
+	version := sha256.New()
+	for rows.Next() {
+		var upload LineageUpload
+		var first, last sql.NullTime
+		var fingerprint uint64
+		if err := rows.Scan(&upload.UploadID, &upload.Filename, &upload.IncidentCount, &first, &last, &fingerprint); err != nil {
+			return nil, fmt.Errorf("failed to scan data lineage row: %w", err)
+		}
+		lineage.Uploads = append(lineage.Uploads, upload)
+		lineage.IncidentCount += upload.IncidentCount
+		if first.Valid && (lineage.StartDate == nil || first.Time.Before(*lineage.StartDate)) {
+			start := first.Time
+			lineage.StartDate = &start
+		}
+		if last.Valid && (lineage.EndDate == nil || last.Time.After(*lineage.EndDate)) {
+			end := last.Time
+			lineage.EndDate = &end
+		}
+		fmt.Fprintf(version, "%s|%d|%x\n", upload.UploadID, upload.IncidentCount, fingerprint)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating data lineage rows: %w", err)
+	}
+	lineage.DataVersion = hex.EncodeToString(version.Sum(nil))[:16]
+
+	return lineage, nil
+}
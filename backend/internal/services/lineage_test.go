@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestAnalyticsService_GetDataLineage(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+	incidentService := NewIncidentService(db)
+
+	if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status)
+		VALUES ('upload-1', 'stored.xlsx', 'january.xlsx', 'completed')`); err != nil {
+		t.Fatalf("Failed to insert upload: %v", err)
+	}
+	first := diffTestIncident("a1", "upload-1", "INC001", "P1", "Open")
+	first.ReportDate = first.ReportDate.AddDate(0, 0, -5)
+	uploads := map[string][]models.Incident{
+		"upload-1": {first, diffTestIncident("a2", "upload-1", "INC002", "P2", "Closed")},
+		"upload-2": {diffTestIncident("b1", "upload-2", "INC003", "P3", "Closed")},
+	}
+	for uploadID, incidents := range uploads {
+		if _, err := incidentService.BatchInsertIncidents(ctx, incidents, uploadID); err != nil {
+			t.Fatalf("Failed to insert incidents: %v", err)
+		}
+	}
+
+	service := NewAnalyticsService(db)
+	lineage, err := service.GetDataLineage(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get data lineage: %v", err)
+	}
+	if lineage.IncidentCount != 3 || len(lineage.Uploads) != 2 {
+		t.Fatalf("Expected 3 incidents from 2 uploads, got %+v", lineage)
+	}
+	if lineage.Uploads[0] != (LineageUpload{UploadID: "upload-1", Filename: "january.xlsx", IncidentCount: 2}) {
+		t.Errorf("Unexpected first upload: %+v", lineage.Uploads[0])
+	}
+	if lineage.Uploads[1].Filename != "" || lineage.Uploads[1].IncidentCount != 1 {
+		t.Errorf("Unexpected second upload: %+v", lineage.Uploads[1])
+	}
+	if lineage.StartDate == nil || lineage.EndDate == nil || !lineage.StartDate.Equal(first.ReportDate) ||
+		!lineage.EndDate.Equal(first.ReportDate.AddDate(0, 0, 5)) {
+		t.Errorf("Unexpected date range %v to %v", lineage.StartDate, lineage.EndDate)
+	}
+
+	// Filters narrow the lineage to the incidents they match
+	filtered, err := service.GetDataLineage(ctx, &TimelineFilters{Priorities: []string{"P3"}})
+	if err != nil {
+		t.Fatalf("Failed to get data lineage: %v", err)
+	}
+	if filtered.IncidentCount != 1 || len(filtered.Uploads) != 1 || filtered.Uploads[0].UploadID != "upload-2" {
+		t.Errorf("Expected only upload-2 for P3, got %+v", filtered)
+	}
+
+	// The data version is stable until the data changes
+	again, err := service.GetDataLineage(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get data lineage: %v", err)
+	}
+	if again.DataVersion != lineage.DataVersion {
+		t.Errorf("Expected a stable data version, got %s and %s", lineage.DataVersion, again.DataVersion)
+	}
+	if _, err := incidentService.BatchInsertIncidents(ctx, []models.Incident{diffTestIncident("b2", "upload-2", "INC004", "P3", "Open")}, "upload-2"); err != nil {
+		t.Fatalf("Failed to insert incident: %v", err)
+	}
+	changed, err := service.GetDataLineage(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get data lineage: %v", err)
+	}
+	if changed.DataVersion == lineage.DataVersion || changed.IncidentCount != 4 {
+		t.Errorf("Expected a new data version after an insert, got %+v", changed)
+	}
+
+	// Cache traces report whether cached analytics were computed or reused
+	cached, err := NewCachedAnalyticsService(service, DefaultCacheConfig())
+	if err != nil {
+		t.Fatalf("Failed to create cached analytics service: %v", err)
+	}
+	traced, trace := WithCacheTrace(ctx)
+	if status := trace.Status(); status != LineageCacheBypass {
+		t.Errorf("Expected bypass before any lookup, got %s", status)
+	}
+	if _, err := cached.GetPriorityAnalysis(traced, nil); err != nil {
+		t.Fatalf("Failed to get priority analysis: %v", err)
+	}
+	if status := trace.Status(); status != LineageCacheMiss {
+		t.Errorf("Expected miss, got %s", status)
+	}
+	// Wait for the cache to store the result
+	time.Sleep(10 * time.Millisecond)
+
+	traced, trace = WithCacheTrace(ctx)
+	if _, err := cached.GetPriorityAnalysis(traced, nil); err != nil {
+		t.Fatalf("Failed to get priority analysis: %v", err)
+	}
+	if status := trace.Status(); status != LineageCacheHit {
+		t.Errorf("Expected hit, got %s", status)
+	}
+	if _, err := cached.GetSentimentAnalysis(traced, nil); err != nil {
+		t.Fatalf("Failed to get sentiment analysis: %v", err)
+	}
+	if status := trace.Status(); status != LineageCachePartial {
+		t.Errorf("Expected partial, got %s", status)
+	}
+}
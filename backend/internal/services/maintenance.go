@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Maintenance modes select how analytics treat incidents reported during a maintenance window
+const (
+	MaintenanceInclude = "include"
+	MaintenanceExclude = "exclude"
+	MaintenanceOnly    = "only"
+)
+
+// maintenanceWindowMatch is true for an incident reported on a day a maintenance window of its
+// application, or of every application, was open. Incidents carry only a report date, so a
+// window covers each calendar day (UTC) it overlaps. The incident columns are unqualified so
+// the condition works whatever alias the surrounding query gives the incidents table.
+const maintenanceWindowMatch = `EXISTS (
+	SELECT 1 FROM maintenance_windows mw
+	WHERE report_date >= CAST(mw.starts_at AS DATE) AND report_date < mw.ends_at
+		AND (mw.application IS NULL OR mw.application = application_name))`
+
+// ErrInvalidMaintenanceWindow is returned when a maintenance window does not end after it starts
+var ErrInvalidMaintenanceWindow = errors.New("maintenance window must end after it starts")
+
+// MaintenanceWindow is a planned outage period. An empty ApplicationName covers every application.
+type MaintenanceWindow struct {
+	ID              string    `json:"id"`
+	ApplicationName string    `json:"application_name,omitempty"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description,omitempty"`
+	StartsAt        time.Time `json:"starts_at"`
+	EndsAt          time.Time `json:"ends_at"`
+	CreatedBy       string    `json:"created_by,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// MaintenanceWindowService manages the calendar of planned outages
+type MaintenanceWindowService struct {
+	db *sql.DB
+}
+
+// NewMaintenanceWindowService creates a new MaintenanceWindowService instance
+func NewMaintenanceWindowService(db *sql.DB) *MaintenanceWindowService {
+	return &MaintenanceWindowService{db: db}
+}
+
+// maintenanceWindowColumns lists the columns scanned by scanMaintenanceWindow
+const maintenanceWindowColumns = "id, application, title, description, starts_at, ends_at, created_by, created_at"
+
+// ListWindows returns the windows overlapping the optional from and to bounds, ordered by start.
+// When application is set, only its windows and those covering every application are returned.
+func (s *MaintenanceWindowService) ListWindows(ctx context.Context, application string, from, to *time.Time) ([]MaintenanceWindow, error) {
+	query := "SELECT " + maintenanceWindowColumns + " FROM maintenance_windows WHERE 1=1"
+	var args []interface{}
+	if application != "" {
+		query += " AND (application IS NULL OR application = ?)"
+		args = append(args, application)
+	}
+	if from != nil {
+		query += " AND ends_at > ?"
+		args = append(args, from.UTC())
+	}
+	if to != nil {
+		query += " AND starts_at < ?"
+		args = append(args, to.UTC())
+	}
+	query += " ORDER BY starts_at, id"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query maintenance windows: %w", err)
+	}
+	defer rows.Close()
+
+	windows := []MaintenanceWindow{}
+	for rows.Next() {
+		window, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, *window)
+	}
+
+	return windows, rows.Err()
+}
+
+// GetWindow returns a maintenance window, or sql.ErrNoRows when it does not exist
+func (s *MaintenanceWindowService) GetWindow(ctx context.Context, id string) (*MaintenanceWindow, error) {
+	row := s.db.QueryRowContext(ctx,
+		"SELECT "+maintenanceWindowColumns+" FROM maintenance_windows WHERE id = ?", id)
+	return scanMaintenanceWindow(row)
+}
+
+// CreateWindow stores a new maintenance window. Its times are stored in UTC.
+func (s *MaintenanceWindowService) CreateWindow(ctx context.Context, window MaintenanceWindow) (*MaintenanceWindow, error) {
+	if err := normalizeMaintenanceWindow(&window); err != nil {
+		return nil, err
+	}
+	window.ID = uuid.New().String()
+	window.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO maintenance_windows (id, application, title, description, starts_at, ends_at, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, window.ID, nullIfEmpty(window.ApplicationName), window.Title,
+		nullIfEmpty(window.Description), window.StartsAt, window.EndsAt, nullIfEmpty(window.CreatedBy), window.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save maintenance window: %w", err)
+	}
+	return &window, nil
+}
+
+// UpdateWindow replaces the application, title, description and period of a maintenance
+// window, returning sql.ErrNoRows when it does not exist
+func (s *MaintenanceWindowService) UpdateWindow(ctx context.Context, id string, window MaintenanceWindow) (*MaintenanceWindow, error) {
+	if err := normalizeMaintenanceWindow(&window); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE maintenance_windows
+		SET application = ?, title = ?, description = ?, starts_at = ?, ends_at = ?
+		WHERE id = ?
+	`
+	result, err := s.db.ExecContext(ctx, query, nullIfEmpty(window.ApplicationName), window.Title,
+		nullIfEmpty(window.Description), window.StartsAt, window.EndsAt, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update maintenance window: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return s.GetWindow(ctx, id)
+}
+
+// DeleteWindow removes a maintenance window, returning sql.ErrNoRows when it does not exist
+func (s *MaintenanceWindowService) DeleteWindow(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM maintenance_windows WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete maintenance window: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// normalizeMaintenanceWindow trims the text fields of a window, converts its times to UTC and
+// checks that it ends after it starts
+func normalizeMaintenanceWindow(window *MaintenanceWindow) error {
+	window.ApplicationName = strings.TrimSpace(window.ApplicationName)
+	window.Title = strings.TrimSpace(window.Title)
+	window.Description = strings.TrimSpace(window.Description)
+	window.StartsAt = window.StartsAt.UTC()
+	window.EndsAt = window.EndsAt.UTC()
+	if !window.EndsAt.After(window.StartsAt) {
+		return ErrInvalidMaintenanceWindow
+	}
+	return nil
+}
+
+// scanMaintenanceWindow scans a maintenance_windows row selected with maintenanceWindowColumns
+func scanMaintenanceWindow(row interface{ Scan(...interface{}) error }) (*MaintenanceWindow, error) {
+	var window MaintenanceWindow
+	var application, description, createdBy sql.NullString
+	if err := row.Scan(&window.ID, &application, &window.Title, &description,
+		&window.StartsAt, &window.EndsAt, &createdBy, &window.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan maintenance window: %w", err)
+	}
+	window.ApplicationName = application.String
+	window.Description = description.String
+	window.CreatedBy = createdBy.String
+	return &window, nil
+}
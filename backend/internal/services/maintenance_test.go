@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestMaintenanceWindowService(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	service := NewMaintenanceWindowService(db)
+	ctx := context.Background()
+
+	// Billing: Jan 15 is inside its window, Jan 16 is not. Portal: Jan 20 is inside the global window.
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P1", "Closed"),
+		diffTestIncident("i2", "upload-1", "INC002", "P2", "Closed"),
+		diffTestIncident("i3", "upload-1", "INC003", "P3", "Closed"),
+		diffTestIncident("i4", "upload-1", "INC004", "P3", "Open"),
+	}
+	incidents[0].ApplicationName = "Billing"
+	incidents[1].ApplicationName = "Billing"
+	incidents[1].ReportDate = time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+	incidents[3].ReportDate = time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	// A window ending at midnight does not cover the following day
+	berlin := time.FixedZone("CET", 3600)
+	billing, err := service.CreateWindow(ctx, MaintenanceWindow{
+		ApplicationName: " Billing ",
+		Title:           "Database upgrade",
+		StartsAt:        time.Date(2024, 1, 15, 23, 0, 0, 0, berlin),
+		EndsAt:          time.Date(2024, 1, 16, 1, 0, 0, 0, berlin),
+	})
+	if err != nil {
+		t.Fatalf("CreateWindow() error = %v", err)
+	}
+	if billing.ApplicationName != "Billing" || !billing.StartsAt.Equal(time.Date(2024, 1, 15, 22, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected a trimmed window stored in UTC, got %+v", billing)
+	}
+	global, err := service.CreateWindow(ctx, MaintenanceWindow{
+		Title:    "Network maintenance",
+		StartsAt: time.Date(2024, 1, 20, 2, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2024, 1, 20, 4, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("CreateWindow() error = %v", err)
+	}
+
+	if _, err := service.CreateWindow(ctx, MaintenanceWindow{
+		Title:    "Backwards",
+		StartsAt: time.Date(2024, 1, 20, 4, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2024, 1, 20, 2, 0, 0, 0, time.UTC),
+	}); err != ErrInvalidMaintenanceWindow {
+		t.Errorf("expected ErrInvalidMaintenanceWindow, got %v", err)
+	}
+
+	from := time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC)
+	windows, err := service.ListWindows(ctx, "Portal", &from, nil)
+	if err != nil {
+		t.Fatalf("ListWindows() error = %v", err)
+	}
+	if len(windows) != 1 || windows[0].ID != global.ID {
+		t.Errorf("expected only the global window, got %+v", windows)
+	}
+
+	analytics := NewAnalyticsService(db)
+	counts := map[string]int{MaintenanceInclude: 4, MaintenanceExclude: 2, MaintenanceOnly: 2}
+	for mode, expected := range counts {
+		summary, err := analytics.GetAnalyticsSummary(ctx, &TimelineFilters{Maintenance: mode})
+		if err != nil {
+			t.Fatalf("GetAnalyticsSummary(%s) error = %v", mode, err)
+		}
+		if summary.TotalIncidents != expected {
+			t.Errorf("maintenance=%s: expected %d incidents, got %d", mode, expected, summary.TotalIncidents)
+		}
+	}
+
+	// The filter also applies to queries that alias the incidents table
+	report, err := NewCostCenterService(db).GetChargebackReport(ctx, CostCenterEntityApplication, 0,
+		&TimelineFilters{Maintenance: MaintenanceOnly})
+	if err != nil {
+		t.Fatalf("GetChargebackReport() error = %v", err)
+	}
+	if len(report.Lines) != 1 || report.Lines[0].IncidentCount != 2 {
+		t.Errorf("expected the two maintenance incidents, got %+v", report.Lines)
+	}
+
+	// Moving the Billing window to Jan 16 tags the other Billing incident instead
+	updated, err := service.UpdateWindow(ctx, billing.ID, MaintenanceWindow{
+		ApplicationName: "Billing",
+		Title:           "Database upgrade (moved)",
+		StartsAt:        time.Date(2024, 1, 16, 10, 0, 0, 0, time.UTC),
+		EndsAt:          time.Date(2024, 1, 16, 11, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("UpdateWindow() error = %v", err)
+	}
+	if updated.Title != "Database upgrade (moved)" || updated.CreatedAt.IsZero() {
+		t.Errorf("unexpected updated window %+v", updated)
+	}
+	var remaining string
+	if err := db.QueryRowContext(ctx, "SELECT incident_id FROM incidents WHERE application_name = 'Billing' AND NOT "+maintenanceWindowMatch).Scan(&remaining); err != nil || remaining != "INC001" {
+		t.Errorf("expected INC001 to fall outside the moved window, got %s (%v)", remaining, err)
+	}
+
+	if err := service.DeleteWindow(ctx, global.ID); err != nil {
+		t.Fatalf("DeleteWindow() error = %v", err)
+	}
+	if err := service.DeleteWindow(ctx, global.ID); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows deleting a missing window, got %v", err)
+	}
+	if _, err := service.UpdateWindow(ctx, global.ID, *updated); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows updating a missing window, got %v", err)
+	}
+}
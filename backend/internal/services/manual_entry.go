@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ManualEntryPrefix starts the filename of the uploads that collect the incidents entered through
+// the API, one per day
+const ManualEntryPrefix = "manual-entry-"
+
+// Analysis time budget of a manually entered incident
+const (
+	DefaultManualEntryBudget = 2 * time.Second
+	MaxManualEntryBudget     = 30 * time.Second
+)
+
+// Analysis outcomes of a manually entered incident
+const (
+	ManualAnalysisCompleted = "completed"
+	ManualAnalysisSkipped   = "skipped"   // analyzers were turned off
+	ManualAnalysisTimedOut  = "timed_out" // stored without analysis once the budget ran out
+)
+
+// Errors returned for manually entered incidents
+var (
+	ErrDuplicateIncident = errors.New("incident ID already exists")
+	ErrRuleSetNotFound   = errors.New("validation rule set not found")
+)
+
+// ManualEntryOptions controls how a single incident entered through the API is checked
+type ManualEntryOptions struct {
+	// RuleSet is a validation rule set the incident must pass; empty applies none
+	RuleSet string
+	// Budget bounds the time spent in the analyzers; 0 uses DefaultManualEntryBudget
+	Budget        time.Duration
+	RunSentiment  bool
+	RunAutomation bool
+}
+
+// DefaultManualEntryOptions runs both analyzers within the default budget
+func DefaultManualEntryOptions() ManualEntryOptions {
+	return ManualEntryOptions{
+		Budget:        DefaultManualEntryBudget,
+		RunSentiment:  true,
+		RunAutomation: true,
+	}
+}
+
+// ManualEntryResult is a stored manually entered incident and how it was analyzed
+type ManualEntryResult struct {
+	Incident   models.Incident `json:"incident"`
+	UploadID   string          `json:"upload_id"`
+	Analysis   string          `json:"analysis"`
+	AnalysisMs float64         `json:"analysis_ms"`
+}
+
+// CreateIncident validates, analyzes and stores a single incident right away, for teams that
+// record incidents as they happen instead of uploading spreadsheets. The analyzers get at most the
+// option's budget; when it runs out the incident is stored without their results. Incidents
+// entered on the same day are grouped into one completed upload named after the day.
+func (s *ProcessingService) CreateIncident(ctx context.Context, incident models.Incident, options ManualEntryOptions) (*ManualEntryResult, error) {
+	now := time.Now().UTC()
+	if incident.IncidentID == "" {
+		incident.IncidentID = "MAN-" + now.Format("20060102") + "-" + strings.ToUpper(uuid.New().String()[:8])
+	}
+	if incident.ReportDate.IsZero() {
+		incident.ReportDate = now
+	}
+	incident.SetDefaults()
+
+	if err := validateManualEntry(&incident); err != nil {
+		return nil, err
+	}
+	if options.RuleSet != "" {
+		engine, err := NewValidationRuleSetService(s.db).LoadEngine(ctx, options.RuleSet)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("%w: %s", ErrRuleSetNotFound, options.RuleSet)
+			}
+			return nil, fmt.Errorf("failed to load validation rule set: %w", err)
+		}
+		if err := engine.Check(&incident); err != nil {
+			return nil, err
+		}
+	}
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) > 0 FROM incidents WHERE incident_id = ?`,
+		incident.IncidentID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up incident ID: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateIncident, incident.IncidentID)
+	}
+
+	analysisStart := time.Now()
+	analysis := s.analyzeWithinBudget(ctx, &incident, options)
+	result := &ManualEntryResult{Analysis: analysis, AnalysisMs: milliseconds(time.Since(analysisStart))}
+
+	// Days are created and counted one entry at a time, so concurrent entries share the day's upload
+	s.manualMu.Lock()
+	defer s.manualMu.Unlock()
+
+	uploadID, err := s.manualEntryUpload(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	stored := []models.Incident{incident}
+	insertResult, err := s.incidentService.BatchInsertIncidents(ctx, stored, uploadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert incident: %w", err)
+	}
+	if len(insertResult.Errors) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateIncident, insertResult.Errors[0].Message)
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		UPDATE uploads
+		SET record_count = record_count + 1, processed_count = processed_count + 1, processed_at = ?
+		WHERE id = ?
+	`, now, uploadID); err != nil {
+		return nil, fmt.Errorf("failed to update manual entry upload: %w", err)
+	}
+
+	result.Incident = stored[0]
+	result.UploadID = uploadID
+	return result, nil
+}
+
+// validateManualEntry checks the incident's fields. An incident entered while still open has
+// no resolver yet, so the resolver is only required once it has a resolve date.
+func validateManualEntry(incident *models.Incident) error {
+	err := incident.Validate()
+	violations, ok := err.(models.ValidationErrors)
+	if !ok || incident.ResolveDate != nil {
+		return err
+	}
+
+	kept := violations[:0]
+	for _, violation := range violations {
+		if violation.Field != "resolved_person" {
+			kept = append(kept, violation)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+// analyzeWithinBudget normalizes the application and business service names and runs the enabled
+// analyzers on the incident, giving up on the analyzers once the budget runs out
+func (s *ProcessingService) analyzeWithinBudget(ctx context.Context, incident *models.Incident, options ManualEntryOptions) string {
+	incidents := []models.Incident{*incident}
+	s.normalizeIncidents(ctx, incidents)
+	incidents[0].CalculateResolutionTime()
+	*incident = incidents[0]
+
+	if !options.RunSentiment && !options.RunAutomation {
+		return ManualAnalysisSkipped
+	}
+	budget := options.Budget
+	if budget <= 0 {
+		budget = DefaultManualEntryBudget
+	}
+	budgetCtx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+
+	// The analyzers work on a copy, so a run that overruns the budget cannot change the stored incident
+	done := make(chan error, 1)
+	go func() {
+		s.loadAnalyzerRules(budgetCtx, options.RunSentiment, options.RunAutomation)
+		done <- s.analyzeIncidents(budgetCtx, incidents, options.RunSentiment, options.RunAutomation)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return ManualAnalysisTimedOut
+		}
+		*incident = incidents[0]
+		return ManualAnalysisCompleted
+	case <-budgetCtx.Done():
+		return ManualAnalysisTimedOut
+	}
+}
+
+// manualEntryUpload returns the upload collecting the incidents entered on day, creating it as
+// a completed upload without a file on the first entry of the day
+func (s *ProcessingService) manualEntryUpload(ctx context.Context, day time.Time) (string, error) {
+	name := ManualEntryPrefix + day.Format("2006-01-02")
+
+	var uploadID string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id FROM uploads WHERE original_filename = ? ORDER BY created_at LIMIT 1
+	`, name).Scan(&uploadID)
+	if err == nil {
+		return uploadID, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to find manual entry upload: %w", err)
+	}
+
+	uploadID = uuid.New().String()
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO uploads (id, filename, original_filename, status, record_count,
+			processed_count, error_count, errors, created_at, processed_at)
+		VALUES (?, ?, ?, ?, 0, 0, 0, '[]', ?, ?)
+	`, uploadID, name, name, models.UploadStatusCompleted, day, day); err != nil {
+		return "", fmt.Errorf("failed to create manual entry upload: %w", err)
+	}
+	return uploadID, nil
+}
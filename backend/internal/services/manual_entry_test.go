@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func manualEntryTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { dbWrapper.Close() })
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+	return dbWrapper.GetConnection()
+}
+
+func manualEntryIncident(incidentID string) models.Incident {
+	return models.Incident{
+		IncidentID:       incidentID,
+		BriefDescription: "Checkout page fails to load",
+		ApplicationName:  "Portal",
+		ResolutionGroup:  "Web Team",
+		Priority:         "P2",
+		Status:           "Open",
+	}
+}
+
+func TestProcessingService_CreateIncident(t *testing.T) {
+	db := manualEntryTestDB(t)
+	service := NewProcessingService(db, nil)
+	ctx := context.Background()
+
+	first, err := service.CreateIncident(ctx, manualEntryIncident("INC100"), DefaultManualEntryOptions())
+	if err != nil {
+		t.Fatalf("CreateIncident failed: %v", err)
+	}
+	if first.Analysis != ManualAnalysisCompleted {
+		t.Errorf("Expected completed analysis, got %s", first.Analysis)
+	}
+	if first.Incident.SentimentLabel == "" {
+		t.Error("Expected the incident to carry a sentiment label")
+	}
+
+	// An open incident has no resolver yet, and an omitted ID is generated
+	second, err := service.CreateIncident(ctx, manualEntryIncident(""), DefaultManualEntryOptions())
+	if err != nil {
+		t.Fatalf("CreateIncident without an ID failed: %v", err)
+	}
+	if !strings.HasPrefix(second.Incident.IncidentID, "MAN-") {
+		t.Errorf("Expected a generated incident ID, got %q", second.Incident.IncidentID)
+	}
+	if second.UploadID != first.UploadID {
+		t.Errorf("Expected entries of the same day to share an upload, got %s and %s", first.UploadID, second.UploadID)
+	}
+
+	var filename, status string
+	var records, processed int
+	if err := db.QueryRow(`
+		SELECT original_filename, status, record_count, processed_count FROM uploads WHERE id = ?
+	`, first.UploadID).Scan(&filename, &status, &records, &processed); err != nil {
+		t.Fatalf("Failed to read manual entry upload: %v", err)
+	}
+	if want := ManualEntryPrefix + time.Now().UTC().Format("2006-01-02"); filename != want {
+		t.Errorf("Expected upload %s, got %s", want, filename)
+	}
+	if status != models.UploadStatusCompleted || records != 2 || processed != 2 {
+		t.Errorf("Expected a completed upload of 2 incidents, got %s with %d/%d", status, processed, records)
+	}
+
+	var stored int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM incidents WHERE upload_id = ?`, first.UploadID).Scan(&stored); err != nil {
+		t.Fatalf("Failed to count incidents: %v", err)
+	}
+	if stored != 2 {
+		t.Errorf("Expected 2 stored incidents, got %d", stored)
+	}
+}
+
+func TestProcessingService_CreateIncidentRejects(t *testing.T) {
+	db := manualEntryTestDB(t)
+	service := NewProcessingService(db, nil)
+	ctx := context.Background()
+
+	if _, err := service.CreateIncident(ctx, manualEntryIncident("INC100"), DefaultManualEntryOptions()); err != nil {
+		t.Fatalf("CreateIncident failed: %v", err)
+	}
+
+	_, err := service.CreateIncident(ctx, manualEntryIncident("INC100"), DefaultManualEntryOptions())
+	if !errors.Is(err, ErrDuplicateIncident) {
+		t.Errorf("Expected a duplicate incident error, got %v", err)
+	}
+
+	invalid := manualEntryIncident("INC101")
+	invalid.Priority = "P9"
+	_, err = service.CreateIncident(ctx, invalid, DefaultManualEntryOptions())
+	var violations models.ValidationErrors
+	if !errors.As(err, &violations) {
+		t.Errorf("Expected validation errors, got %v", err)
+	}
+
+	// A resolved incident needs its resolver
+	resolved := manualEntryIncident("INC102")
+	resolveDate := time.Now().UTC()
+	resolved.ResolveDate = &resolveDate
+	if _, err := service.CreateIncident(ctx, resolved, DefaultManualEntryOptions()); !errors.As(err, &violations) {
+		t.Errorf("Expected a missing resolver to be rejected, got %v", err)
+	}
+
+	options := DefaultManualEntryOptions()
+	options.RuleSet = "missing"
+	if _, err := service.CreateIncident(ctx, manualEntryIncident("INC103"), options); !errors.Is(err, ErrRuleSetNotFound) {
+		t.Errorf("Expected an unknown rule set error, got %v", err)
+	}
+}
+
+func TestProcessingService_CreateIncidentAnalysisBudget(t *testing.T) {
+	db := manualEntryTestDB(t)
+	service := NewProcessingService(db, nil)
+	ctx := context.Background()
+
+	options := DefaultManualEntryOptions()
+	options.Budget = time.Nanosecond
+	result, err := service.CreateIncident(ctx, manualEntryIncident("INC200"), options)
+	if err != nil {
+		t.Fatalf("CreateIncident failed: %v", err)
+	}
+	if result.Analysis != ManualAnalysisTimedOut {
+		t.Errorf("Expected the analysis to time out, got %s", result.Analysis)
+	}
+	if result.Incident.SentimentScore != nil {
+		t.Error("Expected a timed out incident to be stored without sentiment")
+	}
+
+	options = ManualEntryOptions{}
+	result, err = service.CreateIncident(ctx, manualEntryIncident("INC201"), options)
+	if err != nil {
+		t.Fatalf("CreateIncident failed: %v", err)
+	}
+	if result.Analysis != ManualAnalysisSkipped {
+		t.Errorf("Expected the analysis to be skipped, got %s", result.Analysis)
+	}
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidMappingProfile is returned when a profile maps an unknown field or has no usable header names
+var ErrInvalidMappingProfile = errors.New("mapping profile must map known incident fields to at least one header name")
+
+// MappingProfile is a named set of spreadsheet header names per incident field, used when a
+// source's column headers differ from the ones the parser recognizes by default
+type MappingProfile struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Columns     map[string][]string `json:"columns"`
+	// DateFormats sets the format of date fields, such as DD/MM/YYYY, instead of detecting it
+	DateFormats map[string]string `json:"date_formats,omitempty"`
+	// PendingStatuses are the statuses whose time a status history column excludes from the
+	// resolution clock, such as "Pending Customer"; empty uses DefaultPendingStatuses
+	PendingStatuses []string  `json:"pending_statuses,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// MappingProfileService manages persisted column mapping profiles
+type MappingProfileService struct {
+	db *sql.DB
+}
+
+// NewMappingProfileService creates a new MappingProfileService instance
+func NewMappingProfileService(db *sql.DB) *MappingProfileService {
+	return &MappingProfileService{db: db}
+}
+
+// ListProfiles returns all mapping profiles ordered by name
+func (s *MappingProfileService) ListProfiles(ctx context.Context) ([]MappingProfile, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, COALESCE(description, ''), columns, COALESCE(date_formats, ''), COALESCE(pending_statuses, ''), updated_at
+		FROM mapping_profiles
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mapping profiles: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := []MappingProfile{}
+	for rows.Next() {
+		profile, err := scanMappingProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+// GetProfile returns the named mapping profile, or sql.ErrNoRows when it does not exist
+func (s *MappingProfileService) GetProfile(ctx context.Context, name string) (*MappingProfile, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT name, COALESCE(description, ''), columns, COALESCE(date_formats, ''), COALESCE(pending_statuses, ''), updated_at
+		FROM mapping_profiles
+		WHERE name = ?
+	`, name)
+
+	return scanMappingProfile(row)
+}
+
+// SaveProfile creates or replaces a mapping profile. Header names are trimmed and deduplicated;
+// fields must be ones the parser can populate. Date formats, which may be nil, can only be set
+// for date fields. Pending statuses are trimmed and deduplicated like header names.
+func (s *MappingProfileService) SaveProfile(ctx context.Context, name, description string, columns map[string][]string, dateFormats map[string]string, pendingStatuses []string) (*MappingProfile, error) {
+	cleaned, err := cleanMappingColumns(columns)
+	if err != nil {
+		return nil, err
+	}
+	formats, err := cleanDateFormats(dateFormats)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &MappingProfile{
+		Name:            strings.TrimSpace(name),
+		Description:     strings.TrimSpace(description),
+		Columns:         cleaned,
+		DateFormats:     formats,
+		PendingStatuses: cleanPendingStatuses(pendingStatuses),
+		UpdatedAt:       time.Now(),
+	}
+
+	columnsJSON, err := json.Marshal(profile.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode mapping profile columns: %w", err)
+	}
+
+	var formatsJSON []byte
+	if len(profile.DateFormats) > 0 {
+		if formatsJSON, err = json.Marshal(profile.DateFormats); err != nil {
+			return nil, fmt.Errorf("failed to encode mapping profile date formats: %w", err)
+		}
+	}
+
+	var statusesJSON []byte
+	if len(profile.PendingStatuses) > 0 {
+		if statusesJSON, err = json.Marshal(profile.PendingStatuses); err != nil {
+			return nil, fmt.Errorf("failed to encode mapping profile pending statuses: %w", err)
+		}
+	}
+
+	query := `
+		INSERT OR REPLACE INTO mapping_profiles (name, description, columns, date_formats, pending_statuses, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, profile.Name, nullIfEmpty(profile.Description),
+		string(columnsJSON), nullIfEmpty(string(formatsJSON)), nullIfEmpty(string(statusesJSON)), profile.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save mapping profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// DeleteProfile removes a mapping profile, returning sql.ErrNoRows when it does not exist.
+// Uploads processed with the profile keep its name in their recorded processing options.
+func (s *MappingProfileService) DeleteProfile(ctx context.Context, name string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM mapping_profiles WHERE name = ?", name)
+	if err != nil {
+		return fmt.Errorf("failed to delete mapping profile: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// cleanMappingColumns validates profile fields and normalizes their header name lists
+func cleanMappingColumns(columns map[string][]string) (map[string][]string, error) {
+	if len(columns) == 0 {
+		return nil, ErrInvalidMappingProfile
+	}
+
+	cleaned := make(map[string][]string, len(columns))
+	for field, headers := range columns {
+		if !IsMappableField(field) {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidMappingProfile, field)
+		}
+
+		seen := make(map[string]bool)
+		var names []string
+		for _, header := range headers {
+			header = strings.TrimSpace(header)
+			key := normalizeColumnName(header)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			names = append(names, header)
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("%w: no header names for field %q", ErrInvalidMappingProfile, field)
+		}
+		sort.Strings(names)
+		cleaned[field] = names
+	}
+
+	return cleaned, nil
+}
+
+// cleanDateFormats trims date formats, dropping empty ones, and checks each is set for a date
+// field and reads a full date
+func cleanDateFormats(formats map[string]string) (map[string]string, error) {
+	cleaned := make(map[string]string, len(formats))
+	for field, format := range formats {
+		format = strings.TrimSpace(format)
+		if format == "" {
+			continue
+		}
+		if !IsDateField(field) {
+			return nil, fmt.Errorf("%w: %q is not a date field", ErrInvalidMappingProfile, field)
+		}
+		if _, err := DateFormatLayout(format); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidMappingProfile, err)
+		}
+		cleaned[field] = format
+	}
+	if len(cleaned) == 0 {
+		return nil, nil
+	}
+	return cleaned, nil
+}
+
+// cleanPendingStatuses trims pending statuses, dropping empty ones and ones that differ only in
+// case or separators
+func cleanPendingStatuses(statuses []string) []string {
+	seen := make(map[string]bool)
+	var cleaned []string
+	for _, status := range statuses {
+		status = strings.TrimSpace(status)
+		key := normalizeColumnName(status)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		cleaned = append(cleaned, status)
+	}
+	return cleaned
+}
+
+// scanMappingProfile scans one mapping profile row and decodes its columns, date formats and
+// pending statuses
+func scanMappingProfile(scanner interface{ Scan(...interface{}) error }) (*MappingProfile, error) {
+	var profile MappingProfile
+	var columnsJSON, formatsJSON, statusesJSON string
+	if err := scanner.Scan(&profile.Name, &profile.Description, &columnsJSON, &formatsJSON, &statusesJSON, &profile.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan mapping profile: %w", err)
+	}
+	if err := json.Unmarshal([]byte(columnsJSON), &profile.Columns); err != nil {
+		return nil, fmt.Errorf("failed to decode mapping profile %s: %w", profile.Name, err)
+	}
+	if formatsJSON != "" {
+		if err := json.Unmarshal([]byte(formatsJSON), &profile.DateFormats); err != nil {
+			return nil, fmt.Errorf("failed to decode date formats of mapping profile %s: %w", profile.Name, err)
+		}
+	}
+	if statusesJSON != "" {
+		if err := json.Unmarshal([]byte(statusesJSON), &profile.PendingStatuses); err != nil {
+			return nil, fmt.Errorf("failed to decode pending statuses of mapping profile %s: %w", profile.Name, err)
+		}
+	}
+	return &profile, nil
+}
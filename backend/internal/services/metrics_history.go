@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"incident-management-system/internal/logging"
+)
+
+// DefaultMetricsRetention is how long metrics samples are kept unless configured otherwise
+const DefaultMetricsRetention = 90 * 24 * time.Hour
+
+// Intervals metrics history can be grouped by
+const (
+	MetricsIntervalRaw  = "raw"
+	MetricsIntervalHour = "hour"
+	MetricsIntervalDay  = "day"
+)
+
+// MetricsSample is a reading of the in-memory error and performance metrics. Totals count since
+// the server started, so they reset on restart; the interval counts do not.
+type MetricsSample struct {
+	RecordedAt       time.Time `json:"recorded_at"`
+	Status           string    `json:"status"`
+	TotalErrors      int64     `json:"total_errors"`
+	IntervalErrors   int64     `json:"interval_errors"` // since the previous sample
+	LastHourErrors   int       `json:"last_hour_errors"`
+	ErrorRate        float64   `json:"error_rate"` // errors per minute over the last hour
+	CriticalErrors   int       `json:"critical_errors"`
+	RequestCount     int64     `json:"request_count"`
+	IntervalRequests int64     `json:"interval_requests"` // since the previous sample
+	AvgResponseMs    float64   `json:"avg_response_ms"`
+	SlowRequests     int       `json:"slow_requests"`
+	MemoryBytes      int64     `json:"memory_bytes"`
+	Goroutines       int       `json:"goroutines"`
+}
+
+// MetricsHistoryPoint summarizes the samples of one interval for plotting trends
+type MetricsHistoryPoint struct {
+	Time           time.Time `json:"time"` // start of the interval
+	Samples        int       `json:"samples"`
+	Errors         int64     `json:"errors"`
+	Requests       int64     `json:"requests"`
+	ErrorRate      float64   `json:"error_rate"`      // average, errors per minute
+	AvgResponseMs  float64   `json:"avg_response_ms"` // average of the samples
+	MaxMemoryBytes int64     `json:"max_memory_bytes"`
+	MaxGoroutines  int       `json:"max_goroutines"`
+	// Unhealthy counts the samples taken while the server reported itself unhealthy or degraded
+	Unhealthy int `json:"unhealthy"`
+}
+
+// MetricsHistoryService keeps periodic samples of the in-memory monitoring metrics, which are
+// otherwise lost on restart
+type MetricsHistoryService struct {
+	db     *sql.DB
+	logger *logging.Logger
+
+	mu        sync.Mutex
+	retention time.Duration
+	last      *MetricsSample
+}
+
+// NewMetricsHistoryService creates a new MetricsHistoryService instance
+func NewMetricsHistoryService(db *sql.DB) *MetricsHistoryService {
+	return &MetricsHistoryService{
+		db:        db,
+		logger:    logging.GetGlobalLogger().WithComponent("metrics_history"),
+		retention: DefaultMetricsRetention,
+	}
+}
+
+// SetRetention sets how long samples are kept; older ones are deleted as new ones are recorded
+func (s *MetricsHistoryService) SetRetention(retention time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retention = retention
+}
+
+// Record stores a sample, counting the errors and requests since the previous one, and deletes
+// samples older than the retention
+func (s *MetricsHistoryService) Record(ctx context.Context, sample MetricsSample) error {
+	s.mu.Lock()
+	sample.IntervalErrors, sample.IntervalRequests = sample.TotalErrors, sample.RequestCount
+	if s.last != nil {
+		if delta := sample.TotalErrors - s.last.TotalErrors; delta >= 0 {
+			sample.IntervalErrors = delta
+		}
+		if delta := sample.RequestCount - s.last.RequestCount; delta >= 0 {
+			sample.IntervalRequests = delta
+		}
+	}
+	retention := s.retention
+	s.mu.Unlock()
+
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO metrics_history (recorded_at, status, total_errors, interval_errors, last_hour_errors, error_rate,
+			critical_errors, request_count, interval_requests, avg_response_ms, slow_requests, memory_bytes, goroutines)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (recorded_at) DO NOTHING
+	`, sample.RecordedAt, sample.Status, sample.TotalErrors, sample.IntervalErrors, sample.LastHourErrors, sample.ErrorRate,
+		sample.CriticalErrors, sample.RequestCount, sample.IntervalRequests, sample.AvgResponseMs, sample.SlowRequests,
+		sample.MemoryBytes, sample.Goroutines); err != nil {
+		return fmt.Errorf("failed to save metrics sample: %w", err)
+	}
+
+	s.mu.Lock()
+	s.last = &sample
+	s.mu.Unlock()
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM metrics_history WHERE recorded_at < ?",
+		sample.RecordedAt.Add(-retention)); err != nil {
+		return fmt.Errorf("failed to delete old metrics samples: %w", err)
+	}
+	return nil
+}
+
+// RunRecorder records a sample read from source every interval until ctx is cancelled
+func (s *MetricsHistoryService) RunRecorder(ctx context.Context, interval time.Duration, source func() MetricsSample) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			sample := source()
+			sample.RecordedAt = now.UTC().Truncate(time.Second)
+			if err := s.Record(ctx, sample); err != nil {
+				s.logger.Error("Failed to record metrics history", err)
+			}
+		}
+	}
+}
+
+// GetHistory summarizes the samples recorded from start up to end by interval, one of
+// MetricsIntervalRaw, MetricsIntervalHour or MetricsIntervalDay, oldest first
+func (s *MetricsHistoryService) GetHistory(ctx context.Context, start, end time.Time, interval string) ([]MetricsHistoryPoint, error) {
+	bucket := "recorded_at"
+	switch interval {
+	case MetricsIntervalHour, MetricsIntervalDay:
+		bucket = fmt.Sprintf("date_trunc('%s', recorded_at)", interval)
+	case MetricsIntervalRaw, "":
+	default:
+		return nil, fmt.Errorf("unknown metrics interval %q", interval)
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %[1]s AS bucket,
+			COUNT(*),
+			COALESCE(SUM(interval_errors), 0),
+			COALESCE(SUM(interval_requests), 0),
+			COALESCE(AVG(error_rate), 0),
+			COALESCE(AVG(avg_response_ms), 0),
+			COALESCE(MAX(memory_bytes), 0),
+			COALESCE(MAX(goroutines), 0),
+			COUNT(*) FILTER (WHERE status IN ('unhealthy', 'degraded'))
+		FROM metrics_history
+		WHERE recorded_at >= ? AND recorded_at < ?
+		GROUP BY bucket
+		ORDER BY bucket
+	`, bucket), start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics history: %w", err)
+	}
+	defer rows.Close()
+
+	points := []MetricsHistoryPoint{}
+	for rows.Next() {
+		var point MetricsHistoryPoint
+		if err := rows.Scan(&point.Time, &point.Samples, &point.Errors, &point.Requests, &point.ErrorRate,
+			&point.AvgResponseMs, &point.MaxMemoryBytes, &point.MaxGoroutines, &point.Unhealthy); err != nil {
+			return nil, fmt.Errorf("failed to scan metrics history: %w", err)
+		}
+		point.ErrorRate = roundTo(point.ErrorRate, 2)
+		point.AvgResponseMs = roundTo(point.AvgResponseMs, 2)
+		points = append(points, point)
+	}
+	return points, rows.Err()
+}
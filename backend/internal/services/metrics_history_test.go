@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+)
+
+func TestMetricsHistoryService(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	service := NewMetricsHistoryService(dbWrapper.GetConnection())
+	ctx := context.Background()
+	base := time.Date(2025, 9, 22, 10, 0, 0, 0, time.UTC)
+
+	samples := []MetricsSample{
+		{RecordedAt: base.Add(-100 * 24 * time.Hour), Status: "healthy", TotalErrors: 1, RequestCount: 10},
+		{RecordedAt: base, Status: "healthy", TotalErrors: 4, RequestCount: 100, ErrorRate: 1, AvgResponseMs: 20, MemoryBytes: 100, Goroutines: 10},
+		{RecordedAt: base.Add(30 * time.Minute), Status: "degraded", TotalErrors: 10, RequestCount: 250, ErrorRate: 3, AvgResponseMs: 40, MemoryBytes: 300, Goroutines: 12},
+		// The server restarted, resetting its totals
+		{RecordedAt: base.Add(90 * time.Minute), Status: "healthy", TotalErrors: 2, RequestCount: 40, ErrorRate: 0.5, AvgResponseMs: 10, MemoryBytes: 200, Goroutines: 8},
+	}
+	for _, sample := range samples {
+		if err := service.Record(ctx, sample); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	raw, err := service.GetHistory(ctx, base.Add(-200*24*time.Hour), base.Add(time.Hour*2), MetricsIntervalRaw)
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if len(raw) != 3 {
+		t.Fatalf("expected the sample past the retention deleted, got %+v", raw)
+	}
+	if raw[1].Errors != 6 || raw[1].Requests != 150 || raw[2].Errors != 2 || raw[2].Requests != 40 {
+		t.Errorf("expected errors and requests counted since the previous sample, got %+v", raw)
+	}
+
+	hourly, err := service.GetHistory(ctx, base, base.Add(2*time.Hour), MetricsIntervalHour)
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if len(hourly) != 2 {
+		t.Fatalf("expected two hours, got %+v", hourly)
+	}
+	first := hourly[0]
+	if !first.Time.Equal(base) || first.Samples != 2 || first.ErrorRate != 2 || first.AvgResponseMs != 30 ||
+		first.MaxMemoryBytes != 300 || first.MaxGoroutines != 12 || first.Unhealthy != 1 {
+		t.Errorf("unexpected first hour: %+v", first)
+	}
+
+	service.SetRetention(time.Hour)
+	if err := service.Record(ctx, MetricsSample{RecordedAt: base.Add(2 * time.Hour), Status: "healthy"}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	raw, err = service.GetHistory(ctx, base.Add(-time.Hour), base.Add(3*time.Hour), MetricsIntervalRaw)
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if len(raw) != 2 {
+		t.Errorf("expected samples older than an hour deleted, got %+v", raw)
+	}
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// mySummaryBreachLimit caps the open SLA breaches listed in a personal summary
+const mySummaryBreachLimit = 10
+
+// SLAStatus counts the incidents measured against their priority's resolution target. Open
+// incidents count once they are past their target; ComplianceRate is the share measured that met it.
+type SLAStatus struct {
+	Measured       int     `json:"measured"`
+	Met            int     `json:"met"`
+	Breached       int     `json:"breached"`
+	OpenBreached   int     `json:"open_breached"`
+	ComplianceRate float64 `json:"compliance_rate"`
+}
+
+// SummaryUnit is the volume, backlog and SLA status of one resolution group or application
+type SummaryUnit struct {
+	Name              string    `json:"name"`
+	IncidentCount     int       `json:"incident_count"`
+	OpenIncidents     int       `json:"open_incidents"`
+	P1Count           int       `json:"p1_count"`
+	P2Count           int       `json:"p2_count"`
+	AvgResolutionTime float64   `json:"avg_resolution_time"`
+	SLA               SLAStatus `json:"sla"`
+}
+
+// MySummary is the analytics summary of the calling user's team: the incidents of the resolution
+// groups and applications of their data scope. Users without a scope see every incident, and
+// their groups and applications are the busiest ones.
+type MySummary struct {
+	UserID            string        `json:"user_id"`
+	Scoped            bool          `json:"scoped"`
+	Scope             *DataScope    `json:"scope,omitempty"`
+	TotalIncidents    int           `json:"total_incidents"`
+	OpenIncidents     int           `json:"open_incidents"`
+	ResolvedIncidents int           `json:"resolved_incidents"`
+	ResolutionRate    float64       `json:"resolution_rate"`
+	AvgResolutionTime float64       `json:"avg_resolution_time"`
+	SLA               SLAStatus     `json:"sla"`
+	Groups            []SummaryUnit `json:"groups"`
+	Applications      []SummaryUnit `json:"applications"`
+	// OpenBreaches lists the open incidents furthest past their target
+	OpenBreaches []SLABreach `json:"open_breaches"`
+	GeneratedAt  time.Time   `json:"generated_at"`
+}
+
+// GetMySummary returns the summary of the incidents the data scope in ctx allows, within the
+// filters. Open incidents are measured against their target at now.
+func (s *AnalyticsService) GetMySummary(ctx context.Context, userID string, filters *TimelineFilters, now time.Time) (*MySummary, error) {
+	scope := DataScopeFromContext(ctx)
+	summary := &MySummary{
+		UserID:       userID,
+		Scoped:       scope != nil,
+		Scope:        scope,
+		Groups:       []SummaryUnit{},
+		Applications: []SummaryUnit{},
+		GeneratedAt:  now,
+	}
+
+	totals, err := s.summaryUnits(ctx, "'all'", nil, filters, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(totals) > 0 {
+		total := totals[0]
+		summary.TotalIncidents = total.IncidentCount
+		summary.OpenIncidents = total.OpenIncidents
+		summary.ResolvedIncidents = total.IncidentCount - total.OpenIncidents
+		summary.AvgResolutionTime = total.AvgResolutionTime
+		summary.SLA = total.SLA
+		if total.IncidentCount > 0 {
+			summary.ResolutionRate = roundTo(float64(summary.ResolvedIncidents)/float64(total.IncidentCount)*100, 1)
+		}
+	}
+
+	var groups, applications []string
+	if scope != nil {
+		groups, applications = scope.Groups, scope.Applications
+	}
+	if summary.Groups, err = s.scopeUnits(ctx, "COALESCE(resolution_group, '')", groups, scope != nil, filters, now); err != nil {
+		return nil, err
+	}
+	if summary.Applications, err = s.scopeUnits(ctx, "COALESCE(application_name, '')", applications, scope != nil, filters, now); err != nil {
+		return nil, err
+	}
+
+	if summary.OpenBreaches, err = s.openBreaches(ctx, filters, now); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// scopeUnits returns the summary of each named unit, busiest first. A scoped user gets every unit
+// of their scope, even those without incidents; an unrestricted one the busiest units.
+func (s *AnalyticsService) scopeUnits(ctx context.Context, unitExpr string, names []string, scoped bool, filters *TimelineFilters, now time.Time) ([]SummaryUnit, error) {
+	if scoped && len(names) == 0 {
+		return []SummaryUnit{}, nil
+	}
+	units, err := s.summaryUnits(ctx, unitExpr, names, filters, now)
+	if err != nil {
+		return nil, err
+	}
+	if !scoped {
+		if limit := filters.topN(DefaultTopN); len(units) > limit {
+			units = units[:limit]
+		}
+		return units, nil
+	}
+
+	found := make(map[string]bool, len(units))
+	for _, unit := range units {
+		found[unit.Name] = true
+	}
+	for _, name := range names {
+		if !found[name] {
+			units = append(units, SummaryUnit{Name: name})
+		}
+	}
+	return units, nil
+}
+
+// summaryUnits totals the incidents matching the filters by unitExpr, only for names when set,
+// busiest first
+func (s *AnalyticsService) summaryUnits(ctx context.Context, unitExpr string, names []string, filters *TimelineFilters, now time.Time) ([]SummaryUnit, error) {
+	whereClause, args, nextIdx := buildFilterConditions(ctx, filters, 2)
+	args = append([]interface{}{now}, args...)
+	if len(names) > 0 {
+		placeholders := make([]string, len(names))
+		for i, name := range names {
+			placeholders[i] = fmt.Sprintf("$%d", nextIdx)
+			args = append(args, name)
+			nextIdx++
+		}
+		whereClause += fmt.Sprintf(" AND %s IN (%s)", unitExpr, strings.Join(placeholders, ","))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			%[1]s as unit,
+			COUNT(*) as incident_count,
+			COUNT(CASE WHEN resolve_date IS NULL THEN 1 END) as open_incidents,
+			COUNT(CASE WHEN priority = 'P1' THEN 1 END) as p1_count,
+			COUNT(CASE WHEN priority = 'P2' THEN 1 END) as p2_count,
+			AVG(%[2]s) as avg_resolution_time,
+			COUNT(CASE WHEN resolve_date IS NOT NULL AND %[2]s <= %[3]s THEN 1 END) as sla_met,
+			COUNT(CASE WHEN resolve_date IS NOT NULL AND %[2]s > %[3]s THEN 1 END) as sla_breached,
+			COUNT(CASE WHEN resolve_date IS NULL AND date_diff('hour', report_date, $1::TIMESTAMP) > %[3]s THEN 1 END) as sla_open_breached
+		FROM incidents
+		WHERE 1=1%[4]s
+		GROUP BY 1
+		ORDER BY incident_count DESC, unit`,
+		unitExpr, resolutionHoursExpr, slaTargetExpression("priority"), whereClause)
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query summary units: %w", err)
+	}
+	defer rows.Close()
+
+	units := []SummaryUnit{}
+	for rows.Next() {
+		var unit SummaryUnit
+		var avgResolutionTime sql.NullFloat64
+		if err := rows.Scan(&unit.Name, &unit.IncidentCount, &unit.OpenIncidents, &unit.P1Count, &unit.P2Count,
+			&avgResolutionTime, &unit.SLA.Met, &unit.SLA.Breached, &unit.SLA.OpenBreached); err != nil {
+			return nil, fmt.Errorf("failed to scan summary unit: %w", err)
+		}
+		unit.AvgResolutionTime = roundTo(avgResolutionTime.Float64, 1)
+		unit.SLA.finish()
+		units = append(units, unit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating summary units: %w", err)
+	}
+	return units, nil
+}
+
+// openBreaches returns the open incidents past their target at now, furthest past first
+func (s *AnalyticsService) openBreaches(ctx context.Context, filters *TimelineFilters, now time.Time) ([]SLABreach, error) {
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 2)
+	args = append([]interface{}{now}, args...)
+	query := fmt.Sprintf(`
+		SELECT incident_id, priority, COALESCE(application_name, ''), target_hours, elapsed_hours
+		FROM (
+			SELECT incident_id, priority, application_name, %[1]s as target_hours,
+				date_diff('hour', report_date, $1::TIMESTAMP) as elapsed_hours
+			FROM incidents
+			WHERE resolve_date IS NULL%[2]s
+		) open_incidents
+		WHERE elapsed_hours > target_hours
+		ORDER BY elapsed_hours - target_hours DESC, incident_id
+		LIMIT %[3]d`, slaTargetExpression("priority"), whereClause, mySummaryBreachLimit)
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open SLA breaches: %w", err)
+	}
+	defer rows.Close()
+
+	breaches := []SLABreach{}
+	for rows.Next() {
+		breach := SLABreach{Open: true}
+		if err := rows.Scan(&breach.IncidentID, &breach.Priority, &breach.ApplicationName,
+			&breach.TargetHours, &breach.ElapsedHours); err != nil {
+			return nil, fmt.Errorf("failed to scan open SLA breach: %w", err)
+		}
+		breaches = append(breaches, breach)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating open SLA breaches: %w", err)
+	}
+	return breaches, nil
+}
+
+// finish computes the measured count and compliance rate from the counts
+func (s *SLAStatus) finish() {
+	s.Measured = s.Met + s.Breached + s.OpenBreached
+	if s.Measured > 0 {
+		s.ComplianceRate = roundTo(float64(s.Met)/float64(s.Measured)*100, 1)
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestAnalyticsService_GetMySummary(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	analyticsService := NewAnalyticsService(db)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	incidents := []struct {
+		group, application, priority string
+		resolvedAfter                time.Duration // 0 leaves the incident open
+	}{
+		{"Web Team", "Portal", "P1", 2 * time.Hour},  // within the 4h target
+		{"Web Team", "Portal", "P1", 10 * time.Hour}, // breached
+		{"Web Team", "Portal", "P2", 0},              // open for 5 days, past the 8h target
+		{"Web Team", "Portal", "P4", 0},              // open for 5 days, past the 72h target
+		{"DB Team", "Orders", "P3", 4 * time.Hour},
+		{"DB Team", "Orders", "P3", 0},
+	}
+	var batch []models.Incident
+	for i, spec := range incidents {
+		incident := diffTestIncident(fmt.Sprintf("i%d", i), "upload-1", fmt.Sprintf("INC%03d", i), spec.priority, "Open")
+		incident.ResolutionGroup = spec.group
+		incident.ApplicationName = spec.application
+		if spec.resolvedAfter > 0 {
+			resolved := incident.ReportDate.Add(spec.resolvedAfter)
+			incident.ResolveDate = &resolved
+			incident.Status = "Closed"
+			incident.CalculateResolutionTime()
+		}
+		batch = append(batch, incident)
+	}
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, batch, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	// A user scoped to the web team sees its incidents, and every unit of the scope
+	scoped := WithDataScope(ctx, &DataScope{UserID: "alice", Groups: []string{"Web Team", "Mobile Team"}})
+	summary, err := analyticsService.GetMySummary(scoped, "alice", nil, now)
+	if err != nil {
+		t.Fatalf("Failed to get summary: %v", err)
+	}
+	if !summary.Scoped || summary.TotalIncidents != 4 || summary.OpenIncidents != 2 || summary.ResolutionRate != 50 {
+		t.Fatalf("Unexpected scoped summary: %+v", summary)
+	}
+	if summary.SLA.Met != 1 || summary.SLA.Breached != 1 || summary.SLA.OpenBreached != 2 || summary.SLA.ComplianceRate != 25 {
+		t.Errorf("Unexpected SLA status: %+v", summary.SLA)
+	}
+	if len(summary.Groups) != 2 || summary.Groups[0].Name != "Web Team" || summary.Groups[1].Name != "Mobile Team" ||
+		summary.Groups[1].IncidentCount != 0 {
+		t.Errorf("Unexpected groups: %+v", summary.Groups)
+	}
+	if len(summary.Applications) != 0 {
+		t.Errorf("Expected no applications for a group-only scope, got %+v", summary.Applications)
+	}
+	if len(summary.OpenBreaches) != 2 || summary.OpenBreaches[0].IncidentID != "INC002" || !summary.OpenBreaches[0].Open {
+		t.Errorf("Unexpected open breaches: %+v", summary.OpenBreaches)
+	}
+
+	// An unrestricted user sees every incident, with the busiest units
+	summary, err = analyticsService.GetMySummary(ctx, "admin", &TimelineFilters{Limit: 1}, now)
+	if err != nil {
+		t.Fatalf("Failed to get summary: %v", err)
+	}
+	if summary.Scoped || summary.TotalIncidents != 6 || len(summary.Groups) != 1 || summary.Groups[0].Name != "Web Team" {
+		t.Errorf("Unexpected unrestricted summary: %+v", summary)
+	}
+	if len(summary.Applications) != 1 || summary.Applications[0].Name != "Portal" {
+		t.Errorf("Unexpected applications: %+v", summary.Applications)
+	}
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Incident origins, derived from the recorded source
+const (
+	OriginMonitoring = "monitoring"
+	OriginHuman      = "human"
+)
+
+// monitoringSourceKeywords mark a source as a monitoring tool rather than a person reporting
+var monitoringSourceKeywords = []string{
+	"monitor", "alert", "event", "auto", "system", "nagios", "zabbix", "datadog", "prometheus",
+	"grafana", "splunk", "dynatrace", "appdynamics", "new relic", "newrelic", "pagerduty",
+	"solarwinds", "scom", "opsgenie", "cloudwatch",
+}
+
+// autoResolvedClosureKeywords mark a closure code as the incident clearing without a person
+var autoResolvedClosureKeywords = []string{"auto", "self-healed", "self healed", "cleared", "recovered"}
+
+// noActionClosureKeywords mark a closure code as the incident needing no action
+var noActionClosureKeywords = []string{
+	"no action", "no fault", "no issue", "false positive", "false alarm", "duplicate",
+	"not reproducible", "cancel", "informational", "withdrawn",
+}
+
+// autoResolverNames are resolver names recorded when an incident closed itself
+var autoResolverNames = []string{"system", "auto", "automation", "monitoring", "autoclose"}
+
+// keywordMatch returns a condition true when the lower-cased column contains any of keywords.
+// The keywords are fixed in this file, so they are inlined rather than bound.
+func keywordMatch(column string, keywords []string) string {
+	matches := make([]string, len(keywords))
+	for i, keyword := range keywords {
+		matches[i] = fmt.Sprintf("LOWER(%s) LIKE '%%%s%%'", column, keyword)
+	}
+	return "(" + strings.Join(matches, " OR ") + ")"
+}
+
+// autoResolvedExpr is true for an incident that cleared without anyone acting on it
+var autoResolvedExpr = fmt.Sprintf("(%s OR LOWER(TRIM(resolved_person)) IN ('%s'))",
+	keywordMatch("COALESCE(closure_code, '')", autoResolvedClosureKeywords), strings.Join(autoResolverNames, "', '"))
+
+// noActionExpr is true for an incident closed as needing no action
+var noActionExpr = fmt.Sprintf("(%s OR LOWER(COALESCE(status, '')) IN ('cancelled', 'canceled'))",
+	keywordMatch("COALESCE(closure_code, '')", noActionClosureKeywords))
+
+// NoiseRates counts the incidents of a source or origin that were auto-resolved or needed no
+// action. NoiseRate is the share that was either.
+type NoiseRates struct {
+	IncidentCount     int     `json:"incident_count"`
+	AutoResolvedCount int     `json:"auto_resolved_count"`
+	AutoResolvedRate  float64 `json:"auto_resolved_rate"`
+	NoActionCount     int     `json:"no_action_count"`
+	NoActionRate      float64 `json:"no_action_rate"`
+	NoiseCount        int     `json:"noise_count"`
+	NoiseRate         float64 `json:"noise_rate"`
+}
+
+// NoiseSource is the noise of one recorded source
+type NoiseSource struct {
+	Source string `json:"source"`
+	Origin string `json:"origin"`
+	NoiseRates
+}
+
+// NoiseAnalysis quantifies alert noise by comparing monitoring-generated and human-reported
+// incidents. SourceMapped is false when no incident in scope records its source, in which case
+// the analysis is empty.
+type NoiseAnalysis struct {
+	SourceMapped bool          `json:"source_mapped"`
+	Unsourced    int           `json:"unsourced"`
+	Monitoring   NoiseRates    `json:"monitoring"`
+	Human        NoiseRates    `json:"human"`
+	Sources      []NoiseSource `json:"sources"`
+	// AlertsPerHumanIncident is the number of monitoring incidents for every human-reported one
+	AlertsPerHumanIncident *float64 `json:"alerts_per_human_incident"`
+}
+
+// GetNoiseAnalysis returns the auto-resolved and no-action rates of each incident source,
+// noisiest first, and the totals of monitoring-generated and human-reported incidents
+func (s *AnalyticsService) GetNoiseAnalysis(ctx context.Context, filters *TimelineFilters) (*NoiseAnalysis, error) {
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query := fmt.Sprintf(`
+		SELECT
+			COALESCE(TRIM(source), '') as source,
+			COUNT(*) as incident_count,
+			COUNT(CASE WHEN %s THEN 1 END) as auto_resolved,
+			COUNT(CASE WHEN %s THEN 1 END) as no_action,
+			COUNT(CASE WHEN %[1]s OR %[2]s THEN 1 END) as noise
+		FROM incidents
+		WHERE 1=1%[3]s
+		GROUP BY 1`, autoResolvedExpr, noActionExpr, whereClause)
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query noise analysis: %w", err)
+	}
+	defer rows.Close()
+
+	analysis := &NoiseAnalysis{Sources: []NoiseSource{}}
+	for rows.Next() {
+		var source NoiseSource
+		if err := rows.Scan(&source.Source, &source.IncidentCount, &source.AutoResolvedCount,
+			&source.NoActionCount, &source.NoiseCount); err != nil {
+			return nil, fmt.Errorf("failed to scan noise analysis: %w", err)
+		}
+		if source.Source == "" {
+			analysis.Unsourced += source.IncidentCount
+			continue
+		}
+		source.Origin = classifyIncidentSource(source.Source)
+		source.NoiseRates.finish()
+		analysis.Sources = append(analysis.Sources, source)
+
+		totals := &analysis.Human
+		if source.Origin == OriginMonitoring {
+			totals = &analysis.Monitoring
+		}
+		totals.IncidentCount += source.IncidentCount
+		totals.AutoResolvedCount += source.AutoResolvedCount
+		totals.NoActionCount += source.NoActionCount
+		totals.NoiseCount += source.NoiseCount
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read noise analysis: %w", err)
+	}
+
+	analysis.SourceMapped = len(analysis.Sources) > 0
+	analysis.Monitoring.finish()
+	analysis.Human.finish()
+	if analysis.Human.IncidentCount > 0 {
+		ratio := roundTo(float64(analysis.Monitoring.IncidentCount)/float64(analysis.Human.IncidentCount), 2)
+		analysis.AlertsPerHumanIncident = &ratio
+	}
+
+	sortNoiseSources(analysis.Sources)
+	if limit := filters.topN(0); limit > 0 && len(analysis.Sources) > limit {
+		analysis.Sources = analysis.Sources[:limit]
+	}
+	return analysis, nil
+}
+
+// classifyIncidentSource returns whether a recorded source is a monitoring tool or a person
+func classifyIncidentSource(source string) string {
+	lower := strings.ToLower(source)
+	for _, keyword := range monitoringSourceKeywords {
+		if strings.Contains(lower, keyword) {
+			return OriginMonitoring
+		}
+	}
+	return OriginHuman
+}
+
+// finish computes the rates from the counts
+func (r *NoiseRates) finish() {
+	if r.IncidentCount == 0 {
+		return
+	}
+	total := float64(r.IncidentCount)
+	r.AutoResolvedRate = roundTo(float64(r.AutoResolvedCount)/total*100, 1)
+	r.NoActionRate = roundTo(float64(r.NoActionCount)/total*100, 1)
+	r.NoiseRate = roundTo(float64(r.NoiseCount)/total*100, 1)
+}
+
+// sortNoiseSources orders sources by noise count, then incident count, then name
+func sortNoiseSources(sources []NoiseSource) {
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].NoiseCount != sources[j].NoiseCount {
+			return sources[i].NoiseCount > sources[j].NoiseCount
+		}
+		if sources[i].IncidentCount != sources[j].IncidentCount {
+			return sources[i].IncidentCount > sources[j].IncidentCount
+		}
+		return sources[i].Source < sources[j].Source
+	})
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestAnalyticsService_GetNoiseAnalysis(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	analyticsService := NewAnalyticsService(db)
+	ctx := context.Background()
+
+	// Without a source column nothing can be told apart
+	unsourced := diffTestIncident("i0", "upload-1", "INC000", "P3", "Closed")
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, []models.Incident{unsourced}, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+	analysis, err := analyticsService.GetNoiseAnalysis(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get noise analysis: %v", err)
+	}
+	if analysis.SourceMapped || analysis.Unsourced != 1 || len(analysis.Sources) != 0 {
+		t.Errorf("Expected an unmapped analysis, got %+v", analysis)
+	}
+
+	sources := []struct {
+		source, closureCode, resolver, status string
+	}{
+		{"Datadog Alert", "Auto Resolved", "Jane", "Closed"},
+		{"Datadog Alert", "", "system", "Closed"},
+		{"Datadog Alert", "False Positive", "Jane", "Closed"},
+		{"Datadog Alert", "Fixed", "Jane", "Closed"},
+		{"Phone", "Fixed", "Jane", "Closed"},
+		{"Phone", "", "Jane", "Cancelled"},
+	}
+	var incidents []models.Incident
+	for i, s := range sources {
+		incident := diffTestIncident(fmt.Sprintf("i%d", i+1), "upload-2", fmt.Sprintf("INC%03d", i+1), "P3", s.status)
+		incident.Source = s.source
+		incident.ClosureCode = s.closureCode
+		incident.ResolvedPerson = s.resolver
+		incidents = append(incidents, incident)
+	}
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-2"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	analysis, err = analyticsService.GetNoiseAnalysis(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get noise analysis: %v", err)
+	}
+	if !analysis.SourceMapped || len(analysis.Sources) != 2 {
+		t.Fatalf("Expected two sources, got %+v", analysis)
+	}
+	datadog := analysis.Sources[0]
+	if datadog.Source != "Datadog Alert" || datadog.Origin != OriginMonitoring || datadog.AutoResolvedCount != 2 ||
+		datadog.NoActionCount != 1 || datadog.NoiseRate != 75 {
+		t.Errorf("Unexpected Datadog noise: %+v", datadog)
+	}
+	if phone := analysis.Sources[1]; phone.Origin != OriginHuman || phone.NoActionRate != 50 || phone.AutoResolvedCount != 0 {
+		t.Errorf("Unexpected phone noise: %+v", phone)
+	}
+	if analysis.Monitoring.IncidentCount != 4 || analysis.Human.IncidentCount != 2 || *analysis.AlertsPerHumanIncident != 2 {
+		t.Errorf("Unexpected origin totals: %+v", analysis)
+	}
+
+	limited, err := analyticsService.GetNoiseAnalysis(ctx, &TimelineFilters{Limit: 1})
+	if err != nil {
+		t.Fatalf("Failed to get noise analysis: %v", err)
+	}
+	if len(limited.Sources) != 1 || limited.Human.IncidentCount != 2 {
+		t.Errorf("Expected the limit to keep totals over every source, got %+v", limited)
+	}
+}
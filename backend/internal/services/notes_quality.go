@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Points each part of the resolution notes contributes to the quality score, out of 100
+const (
+	notesLengthPoints    = 40
+	notesRootCausePoints = 25
+	notesStepsPoints     = 20
+	notesReferencePoints = 15
+)
+
+// NotesFullLengthWords is the number of words at which resolution notes get all length points
+const NotesFullLengthWords = 30
+
+// Notes quality bands, by score
+const (
+	NotesQualityPoor = "poor" // below NotesQualityFairScore
+	NotesQualityFair = "fair"
+	NotesQualityGood = "good" // NotesQualityGoodScore and above
+
+	NotesQualityFairScore = 40
+	NotesQualityGoodScore = 70
+)
+
+// DefaultNotesQualityLimit is how many of the lowest scored incidents are listed by default
+const DefaultNotesQualityLimit = 20
+
+var (
+	// notesRootCausePattern matches notes that explain why the incident happened
+	notesRootCausePattern = regexp.MustCompile(`(?i)\b(root[ -]?cause|caused by|due to|because|rca)\b`)
+	// notesStepsPattern matches notes that describe the fix as a sequence of steps, either as a
+	// numbered or bulleted list or in sequencing words
+	notesStepsPattern = regexp.MustCompile(`(?im)(^\s*(\d+[.)]|[-*•])\s+\S|\b(step \d+|first|then|next|afterwards|finally)\b)`)
+	// notesReferencePattern matches links, knowledge base articles and ticket numbers
+	notesReferencePattern = regexp.MustCompile(`(https?://\S+|\b(?i:kb|inc|chg|prb|rfc|ritm|req)\d{3,}\b|\b[A-Z][A-Z0-9]+-\d+\b)`)
+)
+
+// NotesQualityScore rates how well the resolution of one incident is documented
+type NotesQualityScore struct {
+	Score         int    `json:"score"`
+	Band          string `json:"band"`
+	Words         int    `json:"words"`
+	HasRootCause  bool   `json:"has_root_cause"`
+	HasSteps      bool   `json:"has_steps"`
+	HasReferences bool   `json:"has_references"`
+}
+
+// NotesQualityIncident is the notes quality of one incident
+type NotesQualityIncident struct {
+	ID              string `json:"id"`
+	IncidentID      string `json:"incident_id"`
+	ApplicationName string `json:"application_name"`
+	ResolutionGroup string `json:"resolution_group"`
+	Priority        string `json:"priority"`
+	NotesQualityScore
+}
+
+// NotesQualityGroup aggregates the notes quality of a resolution group's incidents. The
+// percentages are shares of its incidents.
+type NotesQualityGroup struct {
+	Name          string  `json:"name"`
+	Incidents     int     `json:"incidents"`
+	WithNotes     int     `json:"with_notes"`
+	AvgScore      float64 `json:"avg_score"`
+	RootCausePct  float64 `json:"root_cause_pct"`
+	StepsPct      float64 `json:"steps_pct"`
+	ReferencesPct float64 `json:"references_pct"`
+	Poor          int     `json:"poor"`
+	Fair          int     `json:"fair"`
+	Good          int     `json:"good"`
+
+	scoreSum, rootCause, steps, references int
+}
+
+// NotesQualityReport scores the resolution notes of the resolved incidents. Groups are ordered
+// by average score, lowest first, and Lowest lists the worst documented incidents.
+type NotesQualityReport struct {
+	Overall NotesQualityGroup      `json:"overall"`
+	Groups  []NotesQualityGroup    `json:"groups"`
+	Lowest  []NotesQualityIncident `json:"lowest"`
+}
+
+// ScoreResolutionNotes rates resolution notes on their length and on whether they name a root
+// cause, describe the steps taken and reference articles or tickets. A root cause recorded in
+// its own field counts as well.
+func ScoreResolutionNotes(notes, rootCause string) NotesQualityScore {
+	score := NotesQualityScore{
+		Words:         len(strings.Fields(notes)),
+		HasRootCause:  strings.TrimSpace(rootCause) != "" || notesRootCausePattern.MatchString(notes),
+		HasSteps:      notesStepsPattern.MatchString(notes),
+		HasReferences: notesReferencePattern.MatchString(notes),
+	}
+
+	points := float64(notesLengthPoints) * math.Min(float64(score.Words)/NotesFullLengthWords, 1)
+	if score.HasRootCause {
+		points += notesRootCausePoints
+	}
+	if score.HasSteps {
+		points += notesStepsPoints
+	}
+	if score.HasReferences {
+		points += notesReferencePoints
+	}
+	score.Score = int(math.Round(points))
+	score.Band = notesQualityBand(score.Score)
+	return score
+}
+
+// notesQualityBand returns the quality band of a score
+func notesQualityBand(score int) string {
+	switch {
+	case score >= NotesQualityGoodScore:
+		return NotesQualityGood
+	case score >= NotesQualityFairScore:
+		return NotesQualityFair
+	default:
+		return NotesQualityPoor
+	}
+}
+
+// add counts an incident's score in the group
+func (g *NotesQualityGroup) add(score NotesQualityScore) {
+	g.Incidents++
+	if score.Words > 0 {
+		g.WithNotes++
+	}
+	g.scoreSum += score.Score
+	if score.HasRootCause {
+		g.rootCause++
+	}
+	if score.HasSteps {
+		g.steps++
+	}
+	if score.HasReferences {
+		g.references++
+	}
+	switch score.Band {
+	case NotesQualityGood:
+		g.Good++
+	case NotesQualityFair:
+		g.Fair++
+	default:
+		g.Poor++
+	}
+}
+
+// finish computes the group's averages and percentages
+func (g *NotesQualityGroup) finish() {
+	if g.Incidents == 0 {
+		return
+	}
+	total := float64(g.Incidents)
+	g.AvgScore = math.Round(float64(g.scoreSum)/total*10) / 10
+	g.RootCausePct = math.Round(float64(g.rootCause)/total*1000) / 10
+	g.StepsPct = math.Round(float64(g.steps)/total*1000) / 10
+	g.ReferencesPct = math.Round(float64(g.references)/total*1000) / 10
+}
+
+// GetNotesQuality scores the resolution notes of the resolved incidents matching the filters and
+// aggregates the scores by resolution group. Up to limit of the lowest scored incidents are listed.
+func (s *AnalyticsService) GetNotesQuality(ctx context.Context, filters *TimelineFilters, limit int) (*NotesQualityReport, error) {
+	if limit <= 0 {
+		limit = DefaultNotesQualityLimit
+	}
+
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query := `
+		SELECT id, incident_id, COALESCE(application_name, ''), COALESCE(resolution_group, ''), priority,
+			COALESCE(resolution_notes, ''), COALESCE(root_cause, '')
+		FROM incidents
+		WHERE resolve_date IS NOT NULL` + whereClause
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query resolution notes: %w", err)
+	}
+	defer rows.Close()
+
+	report := &NotesQualityReport{
+		Overall: NotesQualityGroup{Name: "all"},
+		Groups:  []NotesQualityGroup{},
+		Lowest:  []NotesQualityIncident{},
+	}
+	groups := map[string]*NotesQualityGroup{}
+	var scored []NotesQualityIncident
+	for rows.Next() {
+		var incident NotesQualityIncident
+		var notes, rootCause string
+		if err := rows.Scan(&incident.ID, &incident.IncidentID, &incident.ApplicationName, &incident.ResolutionGroup,
+			&incident.Priority, &notes, &rootCause); err != nil {
+			return nil, fmt.Errorf("failed to scan resolution notes: %w", err)
+		}
+		incident.NotesQualityScore = ScoreResolutionNotes(notes, rootCause)
+
+		group, ok := groups[incident.ResolutionGroup]
+		if !ok {
+			group = &NotesQualityGroup{Name: incident.ResolutionGroup}
+			groups[incident.ResolutionGroup] = group
+		}
+		group.add(incident.NotesQualityScore)
+		report.Overall.add(incident.NotesQualityScore)
+		scored = append(scored, incident)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating resolution notes: %w", err)
+	}
+
+	report.Overall.finish()
+	for _, group := range groups {
+		group.finish()
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if report.Groups[i].AvgScore != report.Groups[j].AvgScore {
+			return report.Groups[i].AvgScore < report.Groups[j].AvgScore
+		}
+		return report.Groups[i].Name < report.Groups[j].Name
+	})
+
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].Score != scored[j].Score {
+			return scored[i].Score < scored[j].Score
+		}
+		return scored[i].IncidentID < scored[j].IncidentID
+	})
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+	report.Lowest = append(report.Lowest, scored...)
+
+	return report, nil
+}
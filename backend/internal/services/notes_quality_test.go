@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestScoreResolutionNotes(t *testing.T) {
+	longNotes := "Root cause was an expired certificate on the load balancer. " +
+		"1. Renewed the certificate\n2. Reloaded the load balancer\n3. Verified the login page. See KB00123 and CHG004567 for details of the renewal procedure."
+
+	tests := []struct {
+		name      string
+		notes     string
+		rootCause string
+		want      NotesQualityScore
+	}{
+		{
+			name: "empty",
+			want: NotesQualityScore{Score: 0, Band: NotesQualityPoor},
+		},
+		{
+			name:  "short without structure",
+			notes: "Fixed.",
+			want:  NotesQualityScore{Score: 1, Band: NotesQualityPoor, Words: 1},
+		},
+		{
+			name:      "root cause field counts",
+			notes:     "Restarted the service",
+			rootCause: "Memory leak",
+			want:      NotesQualityScore{Score: 29, Band: NotesQualityPoor, Words: 3, HasRootCause: true},
+		},
+		{
+			name:  "complete notes",
+			notes: longNotes,
+			want: NotesQualityScore{Score: 100, Band: NotesQualityGood, Words: len(strings.Fields(longNotes)),
+				HasRootCause: true, HasSteps: true, HasReferences: true},
+		},
+		{
+			name:  "steps in words and a ticket key",
+			notes: "First cleared the cache, then redeployed per OPS-42",
+			want:  NotesQualityScore{Score: 46, Band: NotesQualityFair, Words: 8, HasSteps: true, HasReferences: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScoreResolutionNotes(tt.notes, tt.rootCause); got != tt.want {
+				t.Errorf("ScoreResolutionNotes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyticsService_GetNotesQuality(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+
+	resolved := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+	notes := []struct {
+		group string
+		notes string
+	}{
+		{"Web Team", "Fixed."},
+		{"Web Team", ""},
+		{"Database Team", "Root cause was a full disk. First removed old logs, then extended the volume as described in KB00777."},
+		{"Database Team", "Extended the volume because the disk was full"},
+	}
+	var incidents []models.Incident
+	for i, n := range notes {
+		incident := diffTestIncident(fmt.Sprintf("n%d", i), "upload-1", fmt.Sprintf("INC%03d", i), "P3", "Closed")
+		incident.ResolutionGroup = n.group
+		incident.ResolutionNotes = n.notes
+		incident.ResolveDate = &resolved
+		incidents = append(incidents, incident)
+	}
+	// Open incidents have no resolution to document yet
+	incidents = append(incidents, diffTestIncident("open", "upload-1", "INC100", "P3", "Open"))
+
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	report, err := NewAnalyticsService(db).GetNotesQuality(ctx, nil, 2)
+	if err != nil {
+		t.Fatalf("GetNotesQuality() error = %v", err)
+	}
+
+	if report.Overall.Incidents != 4 || report.Overall.WithNotes != 3 {
+		t.Errorf("Expected 4 resolved incidents, 3 with notes, got %+v", report.Overall)
+	}
+	if len(report.Groups) != 2 || report.Groups[0].Name != "Web Team" || report.Groups[1].Name != "Database Team" {
+		t.Fatalf("Expected groups ordered by lowest score, got %+v", report.Groups)
+	}
+	database := report.Groups[1]
+	if database.RootCausePct != 100 || database.StepsPct != 50 || database.ReferencesPct != 50 {
+		t.Errorf("Expected Database Team percentages 100/50/50, got %+v", database)
+	}
+	if report.Groups[0].Poor != 2 || report.Groups[0].AvgScore >= database.AvgScore {
+		t.Errorf("Expected Web Team to score poorly, got %+v", report.Groups[0])
+	}
+	if len(report.Lowest) != 2 || report.Lowest[0].IncidentID != "INC001" || report.Lowest[1].IncidentID != "INC000" {
+		t.Errorf("Expected the two Web Team incidents as lowest, got %+v", report.Lowest)
+	}
+}
@@ -0,0 +1,417 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification events a user can subscribe to
+const (
+	NotifyWatchlistVolume         = "watchlist.volume"
+	NotifyWatchlistResolutionTime = "watchlist.resolution_time"
+)
+
+// NotificationEvents lists every notification event
+var NotificationEvents = []string{NotifyWatchlistVolume, NotifyWatchlistResolutionTime}
+
+// Notification channels
+const (
+	ChannelEmail = "email"
+	ChannelSlack = "slack"
+)
+
+// NotificationChannels lists every notification channel
+var NotificationChannels = []string{ChannelEmail, ChannelSlack}
+
+// Delivery modes: notifications are sent as they are raised, or collected into a daily digest
+const (
+	DeliveryImmediate = "immediate"
+	DeliveryDigest    = "digest"
+)
+
+const (
+	// DefaultDigestHour is the local hour digests are sent at unless a user picks another
+	DefaultDigestHour = 8
+
+	// notificationOutboxAttempts is how often sending a held notification is tried before it is
+	// dropped
+	notificationOutboxAttempts = 3
+
+	// clockLayout is the format of quiet hours: a time of day such as 22:00
+	clockLayout = "15:04"
+)
+
+// ErrInvalidNotificationPreferences is returned when preferences name unknown events, channels
+// or delivery modes, or have an invalid digest hour, quiet hours or time zone
+var ErrInvalidNotificationPreferences = errors.New("invalid notification preferences")
+
+// NotificationPreferences are the events a user is notified of, the channels used and when
+// notifications are sent. Digest delivery holds notifications until DigestHour; during quiet
+// hours, which may span midnight, notifications are held until they end. Hours are read in
+// Timezone.
+type NotificationPreferences struct {
+	UserID          string     `json:"user_id"`
+	Events          []string   `json:"events"`
+	Channels        []string   `json:"channels"`
+	Delivery        string     `json:"delivery"`
+	DigestHour      int        `json:"digest_hour"`
+	QuietHoursStart string     `json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd   string     `json:"quiet_hours_end,omitempty"`
+	Timezone        string     `json:"timezone"`
+	UpdatedAt       *time.Time `json:"updated_at"` // nil for a user who never saved preferences
+}
+
+// DefaultNotificationPreferences returns the preferences of a user who has not saved any: every
+// event on every channel, sent immediately at any hour
+func DefaultNotificationPreferences(userID string) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:     userID,
+		Events:     append([]string{}, NotificationEvents...),
+		Channels:   append([]string{}, NotificationChannels...),
+		Delivery:   DeliveryImmediate,
+		DigestHour: DefaultDigestHour,
+		Timezone:   "UTC",
+	}
+}
+
+// Wants reports whether the user is notified of event
+func (p *NotificationPreferences) Wants(event string) bool {
+	return containsString(p.Events, event)
+}
+
+// deliverAt returns when a notification raised at now is sent: now, the next digest, or the end
+// of the quiet hours the send time falls in
+func (p *NotificationPreferences) deliverAt(now time.Time) time.Time {
+	location, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	at := now.In(location)
+	if p.Delivery == DeliveryDigest {
+		at = nextClockTime(at, p.DigestHour*60)
+	}
+	if p.QuietHoursStart != "" && p.QuietHoursEnd != "" {
+		start, end := clockMinutes(p.QuietHoursStart), clockMinutes(p.QuietHoursEnd)
+		if inQuietHours(at, start, end) {
+			at = nextClockTime(at, end)
+		}
+	}
+	return at
+}
+
+// Notification is a message to one user about one event, with the addresses of the channels it
+// can be sent to; a channel without an address is not used
+type Notification struct {
+	UserID          string
+	Event           string
+	Subject         string
+	Body            string
+	Email           string
+	SlackWebhookURL string
+}
+
+// NotificationResult reports what became of a notification on each channel. Failures read
+// "<channel>: <error>".
+type NotificationResult struct {
+	Sent     []string
+	Held     []string
+	Failures []string
+}
+
+// NotificationPreferenceService stores users' notification preferences and delivers
+// notifications the way they ask for, holding back those due later in an outbox
+type NotificationPreferenceService struct {
+	db       *sql.DB
+	notifier *Notifier
+}
+
+// NewNotificationPreferenceService creates a new NotificationPreferenceService instance
+func NewNotificationPreferenceService(db *sql.DB, notifier *Notifier) *NotificationPreferenceService {
+	return &NotificationPreferenceService{db: db, notifier: notifier}
+}
+
+// GetPreferences returns a user's notification preferences, or the defaults when the user has
+// not saved any
+func (s *NotificationPreferenceService) GetPreferences(ctx context.Context, userID string) (*NotificationPreferences, error) {
+	var preferences NotificationPreferences
+	var events, channels string
+	var quietStart, quietEnd sql.NullString
+	var updatedAt time.Time
+	err := s.db.QueryRowContext(ctx, `
+		SELECT user_id, events, channels, delivery, digest_hour, quiet_hours_start, quiet_hours_end, timezone, updated_at
+		FROM notification_preferences
+		WHERE user_id = ?
+	`, userID).Scan(&preferences.UserID, &events, &channels, &preferences.Delivery, &preferences.DigestHour,
+		&quietStart, &quietEnd, &preferences.Timezone, &updatedAt)
+	if err == sql.ErrNoRows {
+		return DefaultNotificationPreferences(userID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification preferences: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(events), &preferences.Events); err != nil {
+		return nil, fmt.Errorf("failed to decode notification events: %w", err)
+	}
+	if err := json.Unmarshal([]byte(channels), &preferences.Channels); err != nil {
+		return nil, fmt.Errorf("failed to decode notification channels: %w", err)
+	}
+	preferences.QuietHoursStart = quietStart.String
+	preferences.QuietHoursEnd = quietEnd.String
+	preferences.UpdatedAt = &updatedAt
+	return &preferences, nil
+}
+
+// SavePreferences replaces a user's notification preferences
+func (s *NotificationPreferenceService) SavePreferences(ctx context.Context, userID string, preferences NotificationPreferences) (*NotificationPreferences, error) {
+	if err := normalizeNotificationPreferences(&preferences); err != nil {
+		return nil, err
+	}
+	events, err := json.Marshal(preferences.Events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notification events: %w", err)
+	}
+	channels, err := json.Marshal(preferences.Channels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notification channels: %w", err)
+	}
+	preferences.UserID = userID
+	now := time.Now()
+	preferences.UpdatedAt = &now
+
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO notification_preferences (user_id, events, channels, delivery, digest_hour,
+			quiet_hours_start, quiet_hours_end, timezone, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, userID, string(events), string(channels), preferences.Delivery, preferences.DigestHour,
+		nullIfEmpty(preferences.QuietHoursStart), nullIfEmpty(preferences.QuietHoursEnd), preferences.Timezone,
+		now); err != nil {
+		return nil, fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return &preferences, nil
+}
+
+// Deliver sends a notification at now on each channel the user's preferences allow, or holds it
+// in the outbox for the user's digest or until their quiet hours end. Callers leave out what the
+// user is not subscribed to, checking the events with preferences.Wants.
+func (s *NotificationPreferenceService) Deliver(ctx context.Context, preferences *NotificationPreferences, notification Notification, now time.Time) (*NotificationResult, error) {
+	result := &NotificationResult{}
+	deliverAt := preferences.deliverAt(now)
+	destinations := map[string]string{ChannelEmail: notification.Email, ChannelSlack: notification.SlackWebhookURL}
+	for _, channel := range NotificationChannels {
+		destination := destinations[channel]
+		if destination == "" || !containsString(preferences.Channels, channel) {
+			continue
+		}
+		if deliverAt.After(now) {
+			if err := s.hold(ctx, notification, channel, destination, deliverAt); err != nil {
+				return result, err
+			}
+			result.Held = append(result.Held, channel)
+			continue
+		}
+		if err := s.send(ctx, channel, destination, notification.Subject, notification.Body); err != nil {
+			result.Failures = append(result.Failures, channel+": "+err.Error())
+		} else {
+			result.Sent = append(result.Sent, channel)
+		}
+	}
+	return result, nil
+}
+
+// hold stores a notification in the outbox until deliverAt
+func (s *NotificationPreferenceService) hold(ctx context.Context, notification Notification, channel, destination string, deliverAt time.Time) error {
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO notification_outbox (id, user_id, event, channel, destination, subject, body, deliver_after, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, uuid.New().String(), notification.UserID, notification.Event, channel, destination, notification.Subject,
+		notification.Body, deliverAt.UTC(), time.Now()); err != nil {
+		return fmt.Errorf("failed to hold notification: %w", err)
+	}
+	return nil
+}
+
+// send delivers one message on a channel
+func (s *NotificationPreferenceService) send(ctx context.Context, channel, destination, subject, body string) error {
+	if s.notifier == nil {
+		return ErrNotificationFailed
+	}
+	if channel == ChannelSlack {
+		return s.notifier.SendSlack(ctx, destination, "*"+subject+"*\n"+body)
+	}
+	return s.notifier.SendEmail(destination, subject, body)
+}
+
+// outboxEntry is a notification held in the outbox
+type outboxEntry struct {
+	id, userID, channel, destination, subject, body string
+	attempts                                        int
+}
+
+// FlushOutbox sends the held notifications due at now, combining those for the same user and
+// destination into one message, and returns the number of messages sent. A message that fails
+// is tried again on the next flush, up to notificationOutboxAttempts times.
+func (s *NotificationPreferenceService) FlushOutbox(ctx context.Context, now time.Time) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, channel, destination, subject, body, attempts
+		FROM notification_outbox
+		WHERE deliver_after <= ?
+		ORDER BY user_id, channel, destination, created_at, id
+	`, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to query notification outbox: %w", err)
+	}
+
+	var groups [][]outboxEntry
+	for rows.Next() {
+		var entry outboxEntry
+		if err := rows.Scan(&entry.id, &entry.userID, &entry.channel, &entry.destination, &entry.subject,
+			&entry.body, &entry.attempts); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan held notification: %w", err)
+		}
+		if n := len(groups); n > 0 && groups[n-1][0].userID == entry.userID &&
+			groups[n-1][0].channel == entry.channel && groups[n-1][0].destination == entry.destination {
+			groups[n-1] = append(groups[n-1], entry)
+		} else {
+			groups = append(groups, []outboxEntry{entry})
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return 0, fmt.Errorf("error iterating notification outbox: %w", err)
+	}
+
+	sent := 0
+	for _, group := range groups {
+		subject, body := digestMessage(group)
+		sendErr := s.send(ctx, group[0].channel, group[0].destination, subject, body)
+		if sendErr != nil {
+			log.Printf("Warning: Failed to send %d held %s notification(s) to user %s: %v",
+				len(group), group[0].channel, group[0].userID, sendErr)
+		} else {
+			sent++
+		}
+		for _, entry := range group {
+			query := "DELETE FROM notification_outbox WHERE id = ?"
+			if sendErr != nil && entry.attempts+1 < notificationOutboxAttempts {
+				query = "UPDATE notification_outbox SET attempts = attempts + 1 WHERE id = ?"
+			}
+			if _, err := s.db.ExecContext(ctx, query, entry.id); err != nil {
+				return sent, fmt.Errorf("failed to update notification outbox: %w", err)
+			}
+		}
+	}
+	return sent, nil
+}
+
+// RunScheduler sends the held notifications that are due every interval until ctx is cancelled
+func (s *NotificationPreferenceService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := s.FlushOutbox(ctx, now); err != nil {
+				log.Printf("Warning: Failed to flush notification outbox: %v", err)
+			}
+		}
+	}
+}
+
+// digestMessage returns the subject and body of the message combining held notifications; a
+// single notification is sent as it is
+func digestMessage(entries []outboxEntry) (string, string) {
+	if len(entries) == 1 {
+		return entries[0].subject, entries[0].body
+	}
+	var body strings.Builder
+	for i, entry := range entries {
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		fmt.Fprintf(&body, "%s\n%s\n", entry.subject, strings.TrimRight(entry.body, "\n"))
+	}
+	return fmt.Sprintf("Digest: %d notifications", len(entries)), body.String()
+}
+
+// normalizeNotificationPreferences removes duplicate events and channels, applies the defaults
+// and checks the events, channels, delivery mode, hours and time zone
+func normalizeNotificationPreferences(preferences *NotificationPreferences) error {
+	preferences.Events = uniqueSorted(preferences.Events)
+	preferences.Channels = uniqueSorted(preferences.Channels)
+	preferences.QuietHoursStart = strings.TrimSpace(preferences.QuietHoursStart)
+	preferences.QuietHoursEnd = strings.TrimSpace(preferences.QuietHoursEnd)
+	if preferences.Delivery == "" {
+		preferences.Delivery = DeliveryImmediate
+	}
+	if preferences.Timezone == "" {
+		preferences.Timezone = "UTC"
+	}
+
+	for _, event := range preferences.Events {
+		if !containsString(NotificationEvents, event) {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidNotificationPreferences, event)
+		}
+	}
+	for _, channel := range preferences.Channels {
+		if !containsString(NotificationChannels, channel) {
+			return fmt.Errorf("%w: unknown channel %q", ErrInvalidNotificationPreferences, channel)
+		}
+	}
+	switch {
+	case preferences.Delivery != DeliveryImmediate && preferences.Delivery != DeliveryDigest:
+		return fmt.Errorf("%w: delivery must be %q or %q", ErrInvalidNotificationPreferences, DeliveryImmediate, DeliveryDigest)
+	case preferences.DigestHour < 0 || preferences.DigestHour > 23:
+		return fmt.Errorf("%w: digest_hour must be between 0 and 23", ErrInvalidNotificationPreferences)
+	case (preferences.QuietHoursStart == "") != (preferences.QuietHoursEnd == ""):
+		return fmt.Errorf("%w: quiet_hours_start and quiet_hours_end must be set together", ErrInvalidNotificationPreferences)
+	}
+	for _, clock := range []string{preferences.QuietHoursStart, preferences.QuietHoursEnd} {
+		if _, err := time.Parse(clockLayout, clock); clock != "" && err != nil {
+			return fmt.Errorf("%w: quiet hours must be times of day such as 22:00, got %q", ErrInvalidNotificationPreferences, clock)
+		}
+	}
+	if _, err := time.LoadLocation(preferences.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown time zone %q", ErrInvalidNotificationPreferences, preferences.Timezone)
+	}
+	return nil
+}
+
+// clockMinutes returns the minutes since midnight of a validated time of day
+func clockMinutes(clock string) int {
+	parsed, _ := time.Parse(clockLayout, clock)
+	return parsed.Hour()*60 + parsed.Minute()
+}
+
+// nextClockTime returns the first time at or after t whose time of day, in t's location, is
+// minute minutes past midnight
+func nextClockTime(t time.Time, minute int) time.Time {
+	next := time.Date(t.Year(), t.Month(), t.Day(), minute/60, minute%60, 0, 0, t.Location())
+	if next.Before(t) {
+		next = time.Date(t.Year(), t.Month(), t.Day()+1, minute/60, minute%60, 0, 0, t.Location())
+	}
+	return next
+}
+
+// inQuietHours reports whether t falls in the quiet hours from start to end, in minutes past
+// midnight; quiet hours ending before they start span midnight
+func inQuietHours(t time.Time, start, end int) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
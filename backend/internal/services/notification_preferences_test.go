@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+)
+
+func TestNotificationPreferences_DeliverAt(t *testing.T) {
+	now := time.Date(2024, 3, 4, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		preferences NotificationPreferences
+		expected    time.Time
+	}{
+		{
+			name:        "immediate",
+			preferences: NotificationPreferences{Delivery: DeliveryImmediate, Timezone: "UTC"},
+			expected:    now,
+		},
+		{
+			name:        "quiet hours across midnight",
+			preferences: NotificationPreferences{Delivery: DeliveryImmediate, QuietHoursStart: "22:00", QuietHoursEnd: "07:00", Timezone: "UTC"},
+			expected:    time.Date(2024, 3, 5, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "outside quiet hours",
+			preferences: NotificationPreferences{Delivery: DeliveryImmediate, QuietHoursStart: "12:00", QuietHoursEnd: "13:00", Timezone: "UTC"},
+			expected:    now,
+		},
+		{
+			name:        "next digest",
+			preferences: NotificationPreferences{Delivery: DeliveryDigest, DigestHour: 8, Timezone: "UTC"},
+			expected:    time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "digest in the user's time zone",
+			preferences: NotificationPreferences{Delivery: DeliveryDigest, DigestHour: 8, Timezone: "Europe/Berlin"},
+			expected:    time.Date(2024, 3, 5, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "digest during quiet hours",
+			preferences: NotificationPreferences{Delivery: DeliveryDigest, DigestHour: 6, QuietHoursStart: "22:00",
+				QuietHoursEnd: "07:30", Timezone: "UTC"},
+			expected: time.Date(2024, 3, 5, 7, 30, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.preferences.deliverAt(now); !got.Equal(tt.expected) {
+				t.Errorf("deliverAt() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNotificationPreferenceService(t *testing.T) {
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	var slackMessages []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode slack message: %v", err)
+		}
+		slackMessages = append(slackMessages, payload["text"])
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(nil)
+	notifier.httpClient = server.Client()
+	service := NewNotificationPreferenceService(dbWrapper.GetConnection(), notifier)
+	ctx := context.Background()
+
+	defaults, err := service.GetPreferences(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetPreferences() error = %v", err)
+	}
+	if defaults.UpdatedAt != nil || defaults.Delivery != DeliveryImmediate || len(defaults.Events) != len(NotificationEvents) {
+		t.Errorf("expected the defaults for a user without preferences, got %+v", defaults)
+	}
+
+	if _, err := service.SavePreferences(ctx, "alice", NotificationPreferences{QuietHoursStart: "22:00"}); !errors.Is(err, ErrInvalidNotificationPreferences) {
+		t.Errorf("expected ErrInvalidNotificationPreferences for quiet hours without an end, got %v", err)
+	}
+	if _, err := service.SavePreferences(ctx, "alice", NotificationPreferences{Channels: []string{"pager"}}); !errors.Is(err, ErrInvalidNotificationPreferences) {
+		t.Errorf("expected ErrInvalidNotificationPreferences for an unknown channel, got %v", err)
+	}
+
+	if _, err := service.SavePreferences(ctx, "alice", NotificationPreferences{
+		Events:     []string{NotifyWatchlistVolume},
+		Channels:   []string{ChannelSlack, ChannelSlack},
+		Delivery:   DeliveryDigest,
+		DigestHour: 8,
+	}); err != nil {
+		t.Fatalf("SavePreferences() error = %v", err)
+	}
+	preferences, err := service.GetPreferences(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetPreferences() error = %v", err)
+	}
+	if preferences.UpdatedAt == nil || len(preferences.Channels) != 1 || preferences.Timezone != "UTC" ||
+		preferences.Wants(NotifyWatchlistResolutionTime) {
+		t.Errorf("expected the saved preferences, got %+v", preferences)
+	}
+
+	// Digests hold notifications until the digest hour; email is not a chosen channel
+	now := time.Date(2024, 3, 4, 15, 0, 0, 0, time.UTC)
+	for _, subject := range []string{"First", "Second"} {
+		result, err := service.Deliver(ctx, preferences, Notification{UserID: "alice", Event: NotifyWatchlistVolume,
+			Subject: subject, Body: subject + " body", Email: "alice@example.com", SlackWebhookURL: server.URL}, now)
+		if err != nil {
+			t.Fatalf("Deliver() error = %v", err)
+		}
+		if len(result.Sent) != 0 || len(result.Held) != 1 || result.Held[0] != ChannelSlack {
+			t.Errorf("expected the notification held for slack only, got %+v", result)
+		}
+	}
+
+	if sent, err := service.FlushOutbox(ctx, now.Add(time.Hour)); err != nil || sent != 0 {
+		t.Errorf("expected nothing due before the digest hour, got %d, %v", sent, err)
+	}
+	sent, err := service.FlushOutbox(ctx, time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("FlushOutbox() error = %v", err)
+	}
+	if sent != 1 || len(slackMessages) != 1 {
+		t.Fatalf("expected one digest message, sent %d: %v", sent, slackMessages)
+	}
+	if slackMessages[0] != "*Digest: 2 notifications*\nFirst\nFirst body\n\nSecond\nSecond body\n" {
+		t.Errorf("unexpected digest: %q", slackMessages[0])
+	}
+	if sent, _ := service.FlushOutbox(ctx, time.Date(2024, 3, 6, 8, 0, 0, 0, time.UTC)); sent != 0 {
+		t.Errorf("expected the outbox to be empty after the digest, sent %d", sent)
+	}
+}
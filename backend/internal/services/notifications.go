@@ -0,0 +1,119 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrEmailNotConfigured is returned when an email is sent without an SMTP relay configured
+var ErrEmailNotConfigured = errors.New("email notifications are not configured")
+
+// ErrNotificationFailed is returned when an email or Slack message could not be delivered
+var ErrNotificationFailed = errors.New("notification delivery failed")
+
+// SMTPConfig holds the relay outgoing notification emails are sent through
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Configured reports whether the relay and sender are set
+func (c *SMTPConfig) Configured() bool {
+	return c != nil && c.Host != "" && c.From != ""
+}
+
+// Notifier delivers notifications by email and to Slack incoming webhooks
+type Notifier struct {
+	smtp       *SMTPConfig
+	httpClient *http.Client
+	sendMail   func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewNotifier creates a new Notifier. smtpConfig may be nil, in which case only Slack messages
+// can be sent.
+func NewNotifier(smtpConfig *SMTPConfig) *Notifier {
+	return &Notifier{
+		smtp:       smtpConfig,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		sendMail:   smtp.SendMail,
+	}
+}
+
+// SetSMTPConfig replaces the relay emails are sent through
+func (n *Notifier) SetSMTPConfig(smtpConfig *SMTPConfig) {
+	n.smtp = smtpConfig
+}
+
+// EmailConfigured reports whether emails can be sent
+func (n *Notifier) EmailConfigured() bool {
+	return n.smtp.Configured()
+}
+
+// SendEmail sends a plain text email to one recipient
+func (n *Notifier) SendEmail(to, subject, body string) error {
+	if !n.smtp.Configured() {
+		return ErrEmailNotConfigured
+	}
+	port := firstNonEmpty(n.smtp.Port, "587")
+	var auth smtp.Auth
+	if n.smtp.Username != "" {
+		auth = smtp.PlainAuth("", n.smtp.Username, n.smtp.Password, n.smtp.Host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.smtp.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", strings.NewReplacer("\r", " ", "\n", " ").Replace(subject))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	if err := n.sendMail(net.JoinHostPort(n.smtp.Host, port), auth, n.smtp.From, []string{to}, msg.Bytes()); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotificationFailed, err)
+	}
+	return nil
+}
+
+// SendSlack posts a message to a Slack incoming webhook
+func (n *Notifier) SendSlack(ctx context.Context, webhookURL, text string) error {
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotificationFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotificationFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("%w: slack returned %s: %s", ErrNotificationFailed, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// validSlackWebhook reports whether value is an https URL messages can be posted to
+func validSlackWebhook(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && parsed.Scheme == "https" && parsed.Host != ""
+}
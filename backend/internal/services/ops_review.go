@@ -0,0 +1,519 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ops review pack section sizes
+const (
+	opsReviewNoisyApplicationLimit    = 5
+	opsReviewAutomationCandidateLimit = 5
+)
+
+// OpsWeekSummary holds the headline incident metrics for one week
+type OpsWeekSummary struct {
+	WeekStart         string  `json:"week_start"`
+	IncidentCount     int     `json:"incident_count"`
+	P1Count           int     `json:"p1_count"`
+	P2Count           int     `json:"p2_count"`
+	ResolvedCount     int     `json:"resolved_count"`
+	AvgResolutionTime float64 `json:"avg_resolution_time"`
+}
+
+// OpsWeekTrend compares the review week with the week before it
+type OpsWeekTrend struct {
+	Current           OpsWeekSummary `json:"current"`
+	Previous          OpsWeekSummary `json:"previous"`
+	IncidentChangePct *float64       `json:"incident_change_pct"`
+	P1ChangePct       *float64       `json:"p1_change_pct"`
+}
+
+// NoisyApplication is an application ranked by incident volume in the review week
+type NoisyApplication struct {
+	ApplicationName string `json:"application_name"`
+	IncidentCount   int    `json:"incident_count"`
+	PreviousCount   int    `json:"previous_count"`
+	Change          int    `json:"change"`
+}
+
+// SLABreach is an incident that exceeded, or is still open past, its priority's resolution target
+type SLABreach struct {
+	IncidentID      string `json:"incident_id"`
+	Priority        string `json:"priority"`
+	ApplicationName string `json:"application_name"`
+	TargetHours     int    `json:"target_hours"`
+	ElapsedHours    int    `json:"elapsed_hours"`
+	Open            bool   `json:"open"`
+}
+
+// MajorIncident is a P1 incident reported in the review week
+type MajorIncident struct {
+	IncidentID          string `json:"incident_id"`
+	ReportDate          string `json:"report_date"`
+	ApplicationName     string `json:"application_name"`
+	BriefDescription    string `json:"brief_description"`
+	Status              string `json:"status"`
+	ResolutionTimeHours *int   `json:"resolution_time_hours,omitempty"`
+}
+
+// AutomationCandidate is an IT process group with automatable incidents in the review week.
+// Opportunity tells whether a registered runbook already covers it, listed in Runbooks.
+type AutomationCandidate struct {
+	ITProcessGroup     string         `json:"it_process_group"`
+	IncidentCount      int            `json:"incident_count"`
+	AutomatableCount   int            `json:"automatable_count"`
+	AvgAutomationScore float64        `json:"avg_automation_score"`
+	Opportunity        string         `json:"opportunity"`
+	Runbooks           []RunbookMatch `json:"runbooks"`
+}
+
+// DataQualityNote flags a data problem found in the review week's incidents or uploads
+type DataQualityNote struct {
+	Check   string `json:"check"`
+	Count   int    `json:"count"`
+	Message string `json:"message"`
+}
+
+// OpsReviewPack is the weekly operations review assembled from a week of incidents
+type OpsReviewPack struct {
+	WeekStart            string                `json:"week_start"`
+	WeekEnd              string                `json:"week_end"`
+	GeneratedAt          time.Time             `json:"generated_at"`
+	Trend                OpsWeekTrend          `json:"trend"`
+	NoisyApplications    []NoisyApplication    `json:"noisy_applications"`
+	SLABreaches          []SLABreach           `json:"sla_breaches"`
+	MajorIncidents       []MajorIncident       `json:"major_incidents"`
+	AutomationCandidates []AutomationCandidate `json:"automation_candidates"`
+	DataQualityNotes     []DataQualityNote     `json:"data_quality_notes"`
+}
+
+// opsReviewIncident holds the incident fields the review pack is built from
+type opsReviewIncident struct {
+	IncidentID          string
+	ReportDate          time.Time
+	ResolveDate         *time.Time
+	ApplicationName     string
+	ResolutionGroup     string
+	BriefDescription    string
+	Priority            string
+	Status              string
+	ResolutionTimeHours *int
+}
+
+// OpsReviewService assembles weekly ops review packs
+type OpsReviewService struct {
+	db *sql.DB
+}
+
+// NewOpsReviewService creates a new OpsReviewService instance
+func NewOpsReviewService(db *sql.DB) *OpsReviewService {
+	return &OpsReviewService{db: db}
+}
+
+// StartOfWeek returns the Monday of the week containing t, at midnight UTC
+func StartOfWeek(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// GenerateWeeklyPack assembles the ops review for the week containing weekOf. When weekOf is nil
+// the week of the most recent incident is used, falling back to the current week.
+func (s *OpsReviewService) GenerateWeeklyPack(ctx context.Context, weekOf *time.Time) (*OpsReviewPack, error) {
+	var weekStart time.Time
+	if weekOf != nil {
+		weekStart = StartOfWeek(*weekOf)
+	} else {
+		latest, err := s.latestReportDate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		weekStart = StartOfWeek(latest)
+	}
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	previousStart := weekStart.AddDate(0, 0, -7)
+
+	incidents, err := s.loadWeekIncidents(ctx, weekStart, weekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	previousIncidents, err := s.loadWeekIncidents(ctx, previousStart, weekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	noisy, err := s.noisyApplications(ctx, weekStart, weekEnd, previousStart)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.automationCandidates(ctx, weekStart, weekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := dataQualityNotes(incidents)
+	uploadNote, err := s.uploadQualityNote(ctx, weekStart, weekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if uploadNote != nil {
+		notes = append(notes, *uploadNote)
+	}
+
+	current := summarizeWeek(weekStart, incidents)
+	previous := summarizeWeek(previousStart, previousIncidents)
+	pack := &OpsReviewPack{
+		WeekStart:   weekStart.Format("2006-01-02"),
+		WeekEnd:     weekEnd.AddDate(0, 0, -1).Format("2006-01-02"),
+		GeneratedAt: time.Now(),
+		Trend: OpsWeekTrend{
+			Current:           current,
+			Previous:          previous,
+			IncidentChangePct: percentChange(previous.IncidentCount, current.IncidentCount),
+			P1ChangePct:       percentChange(previous.P1Count, current.P1Count),
+		},
+		NoisyApplications:    noisy,
+		SLABreaches:          findSLABreaches(incidents, weekEnd),
+		MajorIncidents:       majorIncidents(incidents),
+		AutomationCandidates: candidates,
+		DataQualityNotes:     notes,
+	}
+
+	return pack, nil
+}
+
+// latestReportDate returns the most recent incident report date, or now when there are no incidents
+func (s *OpsReviewService) latestReportDate(ctx context.Context) (time.Time, error) {
+	var latest sql.NullTime
+	scope, scopeArgs := scopeClause(ctx)
+	if err := s.db.QueryRowContext(ctx, "SELECT MAX(report_date) FROM incidents WHERE 1=1"+scope, scopeArgs...).Scan(&latest); err != nil {
+		return time.Time{}, fmt.Errorf("failed to query latest report date: %w", err)
+	}
+	if !latest.Valid {
+		return time.Now(), nil
+	}
+	return latest.Time, nil
+}
+
+// loadWeekIncidents returns the incidents reported in [start, end)
+func (s *OpsReviewService) loadWeekIncidents(ctx context.Context, start, end time.Time) ([]opsReviewIncident, error) {
+	scope, scopeArgs := scopeClause(ctx)
+	query := `
+		SELECT incident_id, report_date, resolve_date, application_name, resolution_group,
+			brief_description, priority, COALESCE(status, ''), ` + resolutionHoursExpr + `
+		FROM incidents
+		WHERE report_date >= ? AND report_date < ?` + scope + `
+		ORDER BY report_date, incident_id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, append([]interface{}{start, end}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query week incidents: %w", err)
+	}
+	defer rows.Close()
+
+	var incidents []opsReviewIncident
+	for rows.Next() {
+		var incident opsReviewIncident
+		var resolveDate sql.NullTime
+		var resolutionTime sql.NullInt64
+
+		err := rows.Scan(
+			&incident.IncidentID,
+			&incident.ReportDate,
+			&resolveDate,
+			&incident.ApplicationName,
+			&incident.ResolutionGroup,
+			&incident.BriefDescription,
+			&incident.Priority,
+			&incident.Status,
+			&resolutionTime,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan week incident: %w", err)
+		}
+		if resolveDate.Valid {
+			incident.ResolveDate = &resolveDate.Time
+		}
+		if resolutionTime.Valid {
+			hours := int(resolutionTime.Int64)
+			incident.ResolutionTimeHours = &hours
+		}
+		incidents = append(incidents, incident)
+	}
+
+	return incidents, rows.Err()
+}
+
+// noisyApplications returns the applications with the most incidents in [start, end), with their
+// count for the week starting at previousStart for comparison
+func (s *OpsReviewService) noisyApplications(ctx context.Context, start, end, previousStart time.Time) ([]NoisyApplication, error) {
+	scope, scopeArgs := scopeClause(ctx)
+	query := `
+		SELECT
+			application_name,
+			COUNT(CASE WHEN report_date >= ? THEN 1 END) as incident_count,
+			COUNT(CASE WHEN report_date < ? THEN 1 END) as previous_count
+		FROM incidents
+		WHERE report_date >= ? AND report_date < ?` + scope + `
+		GROUP BY application_name
+		HAVING COUNT(CASE WHEN report_date >= ? THEN 1 END) > 0
+		ORDER BY incident_count DESC, application_name
+		LIMIT ?
+	`
+
+	args := append([]interface{}{start, start, previousStart, end}, scopeArgs...)
+	rows, err := s.db.QueryContext(ctx, query, append(args, start, opsReviewNoisyApplicationLimit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query noisy applications: %w", err)
+	}
+	defer rows.Close()
+
+	applications := []NoisyApplication{}
+	for rows.Next() {
+		var app NoisyApplication
+		if err := rows.Scan(&app.ApplicationName, &app.IncidentCount, &app.PreviousCount); err != nil {
+			return nil, fmt.Errorf("failed to scan noisy application: %w", err)
+		}
+		app.Change = app.IncidentCount - app.PreviousCount
+		applications = append(applications, app)
+	}
+
+	return applications, rows.Err()
+}
+
+// automationCandidates returns the IT process groups with the most automatable incidents in [start, end)
+func (s *OpsReviewService) automationCandidates(ctx context.Context, start, end time.Time) ([]AutomationCandidate, error) {
+	scope, scopeArgs := scopeClause(ctx)
+	query := `
+		SELECT
+			it_process_group,
+			COUNT(*) as incident_count,
+			COUNT(CASE WHEN automation_feasible = true THEN 1 END) as automatable_count,
+			AVG(automation_score) as avg_automation_score
+		FROM incidents
+		WHERE report_date >= ? AND report_date < ?
+			AND it_process_group IS NOT NULL AND it_process_group <> ''` + scope + `
+		GROUP BY it_process_group
+		HAVING COUNT(CASE WHEN automation_feasible = true THEN 1 END) > 0
+		ORDER BY automatable_count DESC, avg_automation_score DESC
+		LIMIT ?
+	`
+
+	args := append([]interface{}{start, end}, scopeArgs...)
+	rows, err := s.db.QueryContext(ctx, query, append(args, opsReviewAutomationCandidateLimit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query automation candidates: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := []AutomationCandidate{}
+	for rows.Next() {
+		var candidate AutomationCandidate
+		var avgScore sql.NullFloat64
+		if err := rows.Scan(&candidate.ITProcessGroup, &candidate.IncidentCount, &candidate.AutomatableCount, &avgScore); err != nil {
+			return nil, fmt.Errorf("failed to scan automation candidate: %w", err)
+		}
+		if avgScore.Valid {
+			candidate.AvgAutomationScore = avgScore.Float64
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating automation candidates: %w", err)
+	}
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	runbooks, err := NewRunbookService(s.db).ListRunbooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range candidates {
+		texts, err := s.candidateDescriptions(ctx, candidates[i].ITProcessGroup, start, end)
+		if err != nil {
+			return nil, err
+		}
+		candidates[i].flagRunbooks(runbooks, texts)
+	}
+
+	return candidates, nil
+}
+
+// candidateDescriptions returns the descriptions of a process group's automatable incidents in
+// [start, end), which runbook keywords are matched against
+func (s *OpsReviewService) candidateDescriptions(ctx context.Context, processGroup string, start, end time.Time) ([]string, error) {
+	scope, scopeArgs := scopeClause(ctx)
+	query := `
+		SELECT COALESCE(brief_description, '') || ' ' || COALESCE(description, '')
+		FROM incidents
+		WHERE report_date >= ? AND report_date < ? AND it_process_group = ? AND automation_feasible = true` + scope
+
+	args := append([]interface{}{start, end, processGroup}, scopeArgs...)
+	return queryDescriptions(ctx, s.db, query, args...)
+}
+
+// uploadQualityNote reports uploads created in [start, end) that failed or had row errors
+func (s *OpsReviewService) uploadQualityNote(ctx context.Context, start, end time.Time) (*DataQualityNote, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM uploads
+		WHERE created_at >= ? AND created_at < ? AND (status = 'failed' OR error_count > 0)
+	`
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, query, start, end).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to query upload quality: %w", err)
+	}
+	if count == 0 {
+		return nil, nil
+	}
+
+	return &DataQualityNote{
+		Check:   "upload_errors",
+		Count:   count,
+		Message: fmt.Sprintf("%d upload(s) this week failed or had rows rejected", count),
+	}, nil
+}
+
+// summarizeWeek computes the headline metrics from a week of incidents
+func summarizeWeek(weekStart time.Time, incidents []opsReviewIncident) OpsWeekSummary {
+	summary := OpsWeekSummary{WeekStart: weekStart.Format("2006-01-02")}
+	var totalHours, timedCount int
+
+	for _, incident := range incidents {
+		summary.IncidentCount++
+		switch incident.Priority {
+		case "P1":
+			summary.P1Count++
+		case "P2":
+			summary.P2Count++
+		}
+		if incident.ResolveDate != nil {
+			summary.ResolvedCount++
+		}
+		if incident.ResolutionTimeHours != nil {
+			totalHours += *incident.ResolutionTimeHours
+			timedCount++
+		}
+	}
+	if timedCount > 0 {
+		summary.AvgResolutionTime = float64(totalHours) / float64(timedCount)
+	}
+
+	return summary
+}
+
+// findSLABreaches returns incidents resolved slower than their target, and unresolved incidents
+// whose age at the end of the week already exceeds it. Longest overruns come first.
+func findSLABreaches(incidents []opsReviewIncident, weekEnd time.Time) []SLABreach {
+	breaches := []SLABreach{}
+	for _, incident := range incidents {
+		target, ok := SLATargetHours(incident.Priority)
+		if !ok {
+			continue
+		}
+
+		breach := SLABreach{
+			IncidentID:      incident.IncidentID,
+			Priority:        incident.Priority,
+			ApplicationName: incident.ApplicationName,
+			TargetHours:     target,
+		}
+		switch {
+		case incident.ResolutionTimeHours != nil:
+			breach.ElapsedHours = *incident.ResolutionTimeHours
+		case incident.ResolveDate == nil:
+			breach.ElapsedHours = int(weekEnd.Sub(incident.ReportDate).Hours())
+			breach.Open = true
+		default:
+			continue
+		}
+
+		if breach.ElapsedHours > target {
+			breaches = append(breaches, breach)
+		}
+	}
+
+	sort.SliceStable(breaches, func(i, j int) bool {
+		return breaches[i].ElapsedHours-breaches[i].TargetHours > breaches[j].ElapsedHours-breaches[j].TargetHours
+	})
+	return breaches
+}
+
+// majorIncidents returns the P1 incidents of the week in report order
+func majorIncidents(incidents []opsReviewIncident) []MajorIncident {
+	major := []MajorIncident{}
+	for _, incident := range incidents {
+		if incident.Priority != "P1" {
+			continue
+		}
+		major = append(major, MajorIncident{
+			IncidentID:          incident.IncidentID,
+			ReportDate:          incident.ReportDate.Format("2006-01-02"),
+			ApplicationName:     incident.ApplicationName,
+			BriefDescription:    incident.BriefDescription,
+			Status:              incident.Status,
+			ResolutionTimeHours: incident.ResolutionTimeHours,
+		})
+	}
+	return major
+}
+
+// dataQualityNotes checks a week of incidents for missing or inconsistent fields
+func dataQualityNotes(incidents []opsReviewIncident) []DataQualityNote {
+	checks := []struct {
+		name    string
+		message string
+		failed  func(opsReviewIncident) bool
+	}{
+		{"missing_application", "incident(s) have no application name", func(i opsReviewIncident) bool {
+			return strings.TrimSpace(i.ApplicationName) == ""
+		}},
+		{"missing_resolution_group", "incident(s) have no resolution group", func(i opsReviewIncident) bool {
+			return strings.TrimSpace(i.ResolutionGroup) == ""
+		}},
+		{"closed_without_resolve_date", "closed or resolved incident(s) have no resolve date", func(i opsReviewIncident) bool {
+			status := strings.ToLower(i.Status)
+			return (status == "closed" || status == "resolved") && i.ResolveDate == nil
+		}},
+		{"resolve_before_report", "incident(s) were resolved before they were reported", func(i opsReviewIncident) bool {
+			return i.ResolveDate != nil && i.ResolveDate.Before(i.ReportDate)
+		}},
+	}
+
+	notes := []DataQualityNote{}
+	for _, check := range checks {
+		count := 0
+		for _, incident := range incidents {
+			if check.failed(incident) {
+				count++
+			}
+		}
+		if count > 0 {
+			notes = append(notes, DataQualityNote{
+				Check:   check.name,
+				Count:   count,
+				Message: fmt.Sprintf("%d %s", count, check.message),
+			})
+		}
+	}
+	return notes
+}
+
+// percentChange returns the change from previous to current as a percentage, or nil when previous is zero
+func percentChange(previous, current int) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := float64(current-previous) / float64(previous) * 100
+	return &change
+}
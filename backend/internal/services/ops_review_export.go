@@ -0,0 +1,110 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// WriteOpsReviewXLSX renders an ops review pack as a workbook with one sheet per section
+func WriteOpsReviewXLSX(pack *OpsReviewPack, w io.Writer) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	changeValue := func(pct *float64) interface{} {
+		if pct == nil {
+			return "n/a"
+		}
+		return fmt.Sprintf("%.1f%%", *pct)
+	}
+
+	current, previous := pack.Trend.Current, pack.Trend.Previous
+	sheets := []struct {
+		name string
+		rows [][]interface{}
+	}{
+		{"Summary", [][]interface{}{
+			{"Week", pack.WeekStart + " to " + pack.WeekEnd},
+			{},
+			{"Metric", "This week", "Previous week", "Change"},
+			{"Incidents", current.IncidentCount, previous.IncidentCount, changeValue(pack.Trend.IncidentChangePct)},
+			{"P1 incidents", current.P1Count, previous.P1Count, changeValue(pack.Trend.P1ChangePct)},
+			{"P2 incidents", current.P2Count, previous.P2Count},
+			{"Resolved", current.ResolvedCount, previous.ResolvedCount},
+			{"Avg resolution (hours)", current.AvgResolutionTime, previous.AvgResolutionTime},
+			{"SLA breaches", len(pack.SLABreaches)},
+		}},
+		{"Noisy Applications", opsReviewRows(
+			[]interface{}{"Application", "Incidents", "Previous week", "Change"},
+			len(pack.NoisyApplications), func(i int) []interface{} {
+				app := pack.NoisyApplications[i]
+				return []interface{}{app.ApplicationName, app.IncidentCount, app.PreviousCount, app.Change}
+			})},
+		{"SLA Breaches", opsReviewRows(
+			[]interface{}{"Incident", "Priority", "Application", "Target (hours)", "Elapsed (hours)", "Still open"},
+			len(pack.SLABreaches), func(i int) []interface{} {
+				b := pack.SLABreaches[i]
+				return []interface{}{b.IncidentID, b.Priority, b.ApplicationName, b.TargetHours, b.ElapsedHours, b.Open}
+			})},
+		{"Major Incidents", opsReviewRows(
+			[]interface{}{"Incident", "Reported", "Application", "Description", "Status", "Resolution (hours)"},
+			len(pack.MajorIncidents), func(i int) []interface{} {
+				m := pack.MajorIncidents[i]
+				var hours interface{}
+				if m.ResolutionTimeHours != nil {
+					hours = *m.ResolutionTimeHours
+				}
+				return []interface{}{m.IncidentID, m.ReportDate, m.ApplicationName, m.BriefDescription, m.Status, hours}
+			})},
+		{"Automation Candidates", opsReviewRows(
+			[]interface{}{"IT process group", "Incidents", "Automatable", "Avg automation score", "Opportunity", "Runbooks"},
+			len(pack.AutomationCandidates), func(i int) []interface{} {
+				a := pack.AutomationCandidates[i]
+				runbooks := make([]string, len(a.Runbooks))
+				for j, match := range a.Runbooks {
+					runbooks[j] = match.Name
+				}
+				return []interface{}{a.ITProcessGroup, a.IncidentCount, a.AutomatableCount, a.AvgAutomationScore,
+					a.Opportunity, strings.Join(runbooks, ", ")}
+			})},
+		{"Data Quality", opsReviewRows(
+			[]interface{}{"Check", "Count", "Note"},
+			len(pack.DataQualityNotes), func(i int) []interface{} {
+				n := pack.DataQualityNotes[i]
+				return []interface{}{n.Check, n.Count, n.Message}
+			})},
+	}
+
+	for i, sheet := range sheets {
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", sheet.name); err != nil {
+				return fmt.Errorf("failed to name sheet %s: %w", sheet.name, err)
+			}
+		} else if _, err := f.NewSheet(sheet.name); err != nil {
+			return fmt.Errorf("failed to create sheet %s: %w", sheet.name, err)
+		}
+
+		for r, row := range sheet.rows {
+			cell, _ := excelize.CoordinatesToCellName(1, r+1)
+			if err := f.SetSheetRow(sheet.name, cell, &row); err != nil {
+				return fmt.Errorf("failed to write sheet %s: %w", sheet.name, err)
+			}
+		}
+	}
+
+	if err := f.Write(w); err != nil {
+		return fmt.Errorf("failed to write ops review workbook: %w", err)
+	}
+	return nil
+}
+
+// opsReviewRows builds a sheet's rows from a header and a row function
+func opsReviewRows(header []interface{}, count int, row func(int) []interface{}) [][]interface{} {
+	rows := [][]interface{}{header}
+	for i := 0; i < count; i++ {
+		rows = append(rows, row(i))
+	}
+	return rows
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestStartOfWeek(t *testing.T) {
+	tests := map[string]string{
+		"2024-01-15": "2024-01-15", // Monday
+		"2024-01-17": "2024-01-15",
+		"2024-01-21": "2024-01-15", // Sunday
+		"2024-01-22": "2024-01-22",
+	}
+
+	for input, expected := range tests {
+		day, _ := time.Parse("2006-01-02", input)
+		if got := StartOfWeek(day).Format("2006-01-02"); got != expected {
+			t.Errorf("StartOfWeek(%s) = %s, expected %s", input, got, expected)
+		}
+	}
+}
+
+func TestOpsReviewService_GenerateWeeklyPack(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+
+	hours := func(h int) *int { return &h }
+	resolved := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+	previousWeek := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P1", "Closed"),
+		diffTestIncident("i2", "upload-1", "INC002", "P1", "Open"),
+		diffTestIncident("i3", "upload-1", "INC003", "P3", "Closed"),
+		diffTestIncident("i4", "upload-1", "INC004", "P3", "Closed"),
+		diffTestIncident("i5", "upload-1", "INC005", "P2", "Closed"),
+	}
+	// Resolved over its 4 hour P1 target
+	incidents[0].ResolveDate = &resolved
+	incidents[0].ResolutionTimeHours = hours(6)
+	// Within its 24 hour P3 target
+	incidents[2].ApplicationName = "Billing"
+	incidents[2].ResolveDate = &resolved
+	incidents[2].ResolutionTimeHours = hours(20)
+	// Closed without a resolve date
+	incidents[3].ApplicationName = "Billing"
+	// Previous week
+	incidents[4].ReportDate = previousWeek
+	incidents[4].ResolveDate = &previousWeek
+	incidents[4].ResolutionTimeHours = hours(2)
+
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	service := NewOpsReviewService(db)
+	pack, err := service.GenerateWeeklyPack(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate ops review: %v", err)
+	}
+
+	if pack.WeekStart != "2024-01-15" || pack.WeekEnd != "2024-01-21" {
+		t.Errorf("Expected week of the latest incident, got %s to %s", pack.WeekStart, pack.WeekEnd)
+	}
+
+	if pack.Trend.Current.IncidentCount != 4 || pack.Trend.Previous.IncidentCount != 1 {
+		t.Errorf("Unexpected trend: %+v", pack.Trend)
+	}
+	if pack.Trend.IncidentChangePct == nil || *pack.Trend.IncidentChangePct != 300 {
+		t.Errorf("Expected +300%% incident change, got %v", pack.Trend.IncidentChangePct)
+	}
+	if pack.Trend.P1ChangePct != nil {
+		t.Errorf("Expected no P1 change without a previous P1, got %v", *pack.Trend.P1ChangePct)
+	}
+
+	if len(pack.NoisyApplications) != 2 || pack.NoisyApplications[0].IncidentCount != 2 {
+		t.Errorf("Unexpected noisy applications: %+v", pack.NoisyApplications)
+	}
+	if portal := pack.NoisyApplications[1]; portal.ApplicationName != "Portal" || portal.PreviousCount != 1 {
+		t.Errorf("Unexpected Portal comparison: %+v", portal)
+	}
+
+	// INC002 is still open at the end of the week, INC001 overran its target
+	if len(pack.SLABreaches) != 3 {
+		t.Fatalf("Expected 3 SLA breaches, got %+v", pack.SLABreaches)
+	}
+	if pack.SLABreaches[0].IncidentID != "INC002" || !pack.SLABreaches[0].Open {
+		t.Errorf("Expected open INC002 breach first, got %+v", pack.SLABreaches[0])
+	}
+
+	if len(pack.MajorIncidents) != 2 {
+		t.Errorf("Expected 2 major incidents, got %+v", pack.MajorIncidents)
+	}
+
+	var closedNote *DataQualityNote
+	for i := range pack.DataQualityNotes {
+		if pack.DataQualityNotes[i].Check == "closed_without_resolve_date" {
+			closedNote = &pack.DataQualityNotes[i]
+		}
+	}
+	if closedNote == nil || closedNote.Count != 1 {
+		t.Errorf("Expected closed_without_resolve_date note, got %+v", pack.DataQualityNotes)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteOpsReviewXLSX(pack, &buf); err != nil {
+		t.Fatalf("Failed to write workbook: %v", err)
+	}
+	workbook, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Failed to read workbook: %v", err)
+	}
+	defer workbook.Close()
+
+	if sheets := workbook.GetSheetList(); len(sheets) != 6 || sheets[0] != "Summary" {
+		t.Errorf("Unexpected sheets: %v", sheets)
+	}
+	rows, err := workbook.GetRows("SLA Breaches")
+	if err != nil || len(rows) != 4 {
+		t.Errorf("Expected header and 3 breach rows, got %v (%v)", rows, err)
+	}
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Org hierarchy levels, from most to least specific
+const (
+	OrgLevelGroup      = "group"
+	OrgLevelDepartment = "department"
+	OrgLevelDivision   = "division"
+)
+
+// UnassignedOrgUnit is reported for resolution groups that have no hierarchy mapping
+const UnassignedOrgUnit = "Unassigned"
+
+// OrgGroup places a resolution group within its department and division
+type OrgGroup struct {
+	ResolutionGroup string    `json:"resolution_group"`
+	Department      string    `json:"department"`
+	Division        string    `json:"division"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// GroupAnalysis represents incident metrics rolled up to one org hierarchy unit
+type GroupAnalysis struct {
+	Level                string  `json:"level"`
+	Name                 string  `json:"name"`
+	Parent               string  `json:"parent,omitempty"`
+	GroupCount           int     `json:"group_count"`
+	IncidentCount        int     `json:"incident_count"`
+	ResolvedIncidents    int     `json:"resolved_incidents"`
+	ResolutionRate       float64 `json:"resolution_rate"`
+	AvgResolutionTime    float64 `json:"avg_resolution_time"`
+	MedianResolutionTime float64 `json:"median_resolution_time"`
+	P1Count              int     `json:"p1_count"`
+	P2Count              int     `json:"p2_count"`
+}
+
+// orgLevelColumns maps each hierarchy level to its unit and parent expressions
+var orgLevelColumns = map[string]struct{ unit, parent string }{
+	OrgLevelGroup:      {"i.resolution_group", "COALESCE(MIN(h.department), '" + UnassignedOrgUnit + "')"},
+	OrgLevelDepartment: {"COALESCE(h.department, '" + UnassignedOrgUnit + "')", "COALESCE(MIN(h.division), '" + UnassignedOrgUnit + "')"},
+	OrgLevelDivision:   {"COALESCE(h.division, '" + UnassignedOrgUnit + "')", "''"},
+}
+
+// IsValidOrgLevel reports whether level is a known hierarchy level
+func IsValidOrgLevel(level string) bool {
+	_, ok := orgLevelColumns[level]
+	return ok
+}
+
+// OrgHierarchyService manages the group → department → division hierarchy
+type OrgHierarchyService struct {
+	db *sql.DB
+}
+
+// NewOrgHierarchyService creates a new OrgHierarchyService instance
+func NewOrgHierarchyService(db *sql.DB) *OrgHierarchyService {
+	return &OrgHierarchyService{db: db}
+}
+
+// ListGroups returns all hierarchy mappings ordered by division, department and group
+func (s *OrgHierarchyService) ListGroups(ctx context.Context) ([]OrgGroup, error) {
+	query := `
+		SELECT resolution_group, department, division, updated_at
+		FROM org_hierarchy
+		ORDER BY division, department, resolution_group
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query org hierarchy: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []OrgGroup{}
+	for rows.Next() {
+		var group OrgGroup
+		if err := rows.Scan(&group.ResolutionGroup, &group.Department, &group.Division, &group.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan org group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, rows.Err()
+}
+
+// SaveGroup creates or replaces the hierarchy mapping for a resolution group
+func (s *OrgHierarchyService) SaveGroup(ctx context.Context, resolutionGroup, department, division string) (*OrgGroup, error) {
+	group := &OrgGroup{
+		ResolutionGroup: strings.TrimSpace(resolutionGroup),
+		Department:      strings.TrimSpace(department),
+		Division:        strings.TrimSpace(division),
+		UpdatedAt:       time.Now(),
+	}
+	if group.ResolutionGroup == "" || group.Department == "" || group.Division == "" {
+		return nil, fmt.Errorf("resolution group, department and division are required")
+	}
+
+	query := `
+		INSERT OR REPLACE INTO org_hierarchy (resolution_group, department, division, updated_at)
+		VALUES (?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, group.ResolutionGroup, group.Department, group.Division, group.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save org group: %w", err)
+	}
+
+	return group, nil
+}
+
+// DeleteGroup removes the hierarchy mapping for a resolution group, returning sql.ErrNoRows when it does not exist
+func (s *OrgHierarchyService) DeleteGroup(ctx context.Context, resolutionGroup string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM org_hierarchy WHERE resolution_group = ?", strings.TrimSpace(resolutionGroup))
+	if err != nil {
+		return fmt.Errorf("failed to delete org group: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetGroupAnalysis rolls incident metrics up to the given hierarchy level. Resolution groups
+// without a mapping are reported under "Unassigned". When unit is set only that unit is returned.
+func (s *AnalyticsService) GetGroupAnalysis(ctx context.Context, level, unit string, filters *TimelineFilters) ([]GroupAnalysis, error) {
+	columns, ok := orgLevelColumns[level]
+	if !ok {
+		return nil, fmt.Errorf("invalid org level: %s", level)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			%s as unit,
+			%s as parent,
+			COUNT(DISTINCT i.resolution_group) as group_count,
+			COUNT(*) as incident_count,
+			COUNT(CASE WHEN i.resolve_date IS NOT NULL THEN 1 END) as resolved_incidents,
+			AVG(COALESCE(i.net_resolution_time_hours, i.resolution_time_hours)) as avg_resolution_time,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY COALESCE(i.net_resolution_time_hours, i.resolution_time_hours)) as median_resolution_time,
+			COUNT(CASE WHEN i.priority = 'P1' THEN 1 END) as p1_count,
+			COUNT(CASE WHEN i.priority = 'P2' THEN 1 END) as p2_count
+		FROM incidents i
+		LEFT JOIN org_hierarchy h USING (resolution_group)
+		WHERE 1=1`, columns.unit, columns.parent)
+
+	whereClause, args, nextIdx := buildFilterConditions(ctx, filters, 1)
+	query += whereClause
+	if unit != "" {
+		query += fmt.Sprintf(" AND %s = $%d", columns.unit, nextIdx)
+		args = append(args, unit)
+	}
+	query += " GROUP BY unit ORDER BY incident_count DESC, unit"
+	query += filters.limitClause()
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query group analysis: %w", err)
+	}
+	defer rows.Close()
+
+	analysis := []GroupAnalysis{}
+	for rows.Next() {
+		data := GroupAnalysis{Level: level}
+		var avgResolutionTime, medianResolutionTime sql.NullFloat64
+
+		err := rows.Scan(
+			&data.Name,
+			&data.Parent,
+			&data.GroupCount,
+			&data.IncidentCount,
+			&data.ResolvedIncidents,
+			&avgResolutionTime,
+			&medianResolutionTime,
+			&data.P1Count,
+			&data.P2Count,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group analysis row: %w", err)
+		}
+
+		if avgResolutionTime.Valid {
+			data.AvgResolutionTime = avgResolutionTime.Float64
+		}
+		if medianResolutionTime.Valid {
+			data.MedianResolutionTime = medianResolutionTime.Float64
+		}
+		if data.IncidentCount > 0 {
+			data.ResolutionRate = float64(data.ResolvedIncidents) / float64(data.IncidentCount) * 100
+		}
+
+		analysis = append(analysis, data)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating group analysis rows: %w", err)
+	}
+
+	return analysis, nil
+}
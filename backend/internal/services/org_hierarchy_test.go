@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestAnalyticsService_GetGroupAnalysis(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	orgService := NewOrgHierarchyService(db)
+	analyticsService := NewAnalyticsService(db)
+	ctx := context.Background()
+
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P1", "Open"),
+		diffTestIncident("i2", "upload-1", "INC002", "P2", "Open"),
+		diffTestIncident("i3", "upload-1", "INC003", "P3", "Open"),
+		diffTestIncident("i4", "upload-1", "INC004", "P3", "Open"),
+	}
+	incidents[0].ResolutionGroup = "Web Team"
+	incidents[1].ResolutionGroup = "Mobile Team"
+	incidents[2].ResolutionGroup = "Network Team"
+	incidents[3].ResolutionGroup = "Service Desk"
+
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	for _, group := range []OrgGroup{
+		{ResolutionGroup: "Web Team", Department: "Digital", Division: "Technology"},
+		{ResolutionGroup: "Mobile Team", Department: "Digital", Division: "Technology"},
+		{ResolutionGroup: "Network Team", Department: "Infrastructure", Division: "Technology"},
+	} {
+		if _, err := orgService.SaveGroup(ctx, group.ResolutionGroup, group.Department, group.Division); err != nil {
+			t.Fatalf("Failed to save org group: %v", err)
+		}
+	}
+
+	departments, err := analyticsService.GetGroupAnalysis(ctx, OrgLevelDepartment, "", nil)
+	if err != nil {
+		t.Fatalf("Failed to get department analysis: %v", err)
+	}
+	if len(departments) != 3 {
+		t.Fatalf("Expected 3 departments, got %+v", departments)
+	}
+	if departments[0].Name != "Digital" || departments[0].IncidentCount != 2 || departments[0].GroupCount != 2 {
+		t.Errorf("Unexpected Digital rollup: %+v", departments[0])
+	}
+	if departments[0].Parent != "Technology" || departments[0].P1Count != 1 || departments[0].P2Count != 1 {
+		t.Errorf("Unexpected Digital rollup details: %+v", departments[0])
+	}
+
+	var unassigned *GroupAnalysis
+	for i := range departments {
+		if departments[i].Name == UnassignedOrgUnit {
+			unassigned = &departments[i]
+		}
+	}
+	if unassigned == nil || unassigned.IncidentCount != 1 {
+		t.Errorf("Expected unmapped group under %q, got %+v", UnassignedOrgUnit, departments)
+	}
+
+	divisions, err := analyticsService.GetGroupAnalysis(ctx, OrgLevelDivision, "Technology", nil)
+	if err != nil {
+		t.Fatalf("Failed to get division analysis: %v", err)
+	}
+	if len(divisions) != 1 || divisions[0].IncidentCount != 3 || divisions[0].GroupCount != 3 {
+		t.Errorf("Unexpected Technology rollup: %+v", divisions)
+	}
+
+	groups, err := analyticsService.GetGroupAnalysis(ctx, OrgLevelGroup, "Web Team", nil)
+	if err != nil {
+		t.Fatalf("Failed to get group analysis: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Parent != "Digital" || groups[0].IncidentCount != 1 {
+		t.Errorf("Unexpected Web Team analysis: %+v", groups)
+	}
+
+	if _, err := analyticsService.GetGroupAnalysis(ctx, "team", "", nil); err == nil {
+		t.Error("Expected error for invalid org level")
+	}
+}
+
+func TestOrgHierarchyService_SaveAndDeleteGroup(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	service := NewOrgHierarchyService(dbWrapper.GetConnection())
+	ctx := context.Background()
+
+	if _, err := service.SaveGroup(ctx, "Web Team", "Digital", "Technology"); err != nil {
+		t.Fatalf("Failed to save org group: %v", err)
+	}
+	// Saving again moves the group rather than duplicating it
+	if _, err := service.SaveGroup(ctx, "Web Team", "Platform", "Technology"); err != nil {
+		t.Fatalf("Failed to update org group: %v", err)
+	}
+
+	groups, err := service.ListGroups(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list org groups: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Department != "Platform" {
+		t.Errorf("Unexpected org groups: %+v", groups)
+	}
+
+	if _, err := service.SaveGroup(ctx, "Web Team", " ", "Technology"); err == nil {
+		t.Error("Expected error for empty department")
+	}
+
+	if err := service.DeleteGroup(ctx, "Web Team"); err != nil {
+		t.Fatalf("Failed to delete org group: %v", err)
+	}
+	if err := service.DeleteGroup(ctx, "Web Team"); err == nil {
+		t.Error("Expected error deleting missing org group")
+	}
+}
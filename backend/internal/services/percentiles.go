@@ -0,0 +1,67 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxPercentiles caps how many resolution time percentiles one request may ask for
+const MaxPercentiles = 10
+
+// PercentileKey names a percentile in responses, such as p80 or p99.9
+func PercentileKey(percentile float64) string {
+	return "p" + strconv.FormatFloat(percentile, 'f', -1, 64)
+}
+
+// percentileColumns returns a PERCENTILE_CONT aggregate of column for each requested percentile,
+// each preceded by a comma so they can follow the other select columns. When filter is set the
+// aggregates only see the rows matching it. The percentiles are validated numbers, so they are
+// written into the query rather than bound.
+func percentileColumns(column string, percentiles []float64, filter string) string {
+	var columns strings.Builder
+	for _, percentile := range percentiles {
+		fraction := strconv.FormatFloat(percentile/100, 'f', -1, 64)
+		fmt.Fprintf(&columns, ",\n\t\t\tPERCENTILE_CONT(%s) WITHIN GROUP (ORDER BY %s)", fraction, column)
+		if filter != "" {
+			fmt.Fprintf(&columns, " FILTER (WHERE %s)", filter)
+		}
+	}
+	return columns.String()
+}
+
+// percentileValues holds the scanned results of percentileColumns
+type percentileValues struct {
+	percentiles []float64
+	values      []sql.NullFloat64
+}
+
+// newPercentileValues prepares to scan the aggregates of percentileColumns
+func newPercentileValues(percentiles []float64) *percentileValues {
+	return &percentileValues{percentiles: percentiles, values: make([]sql.NullFloat64, len(percentiles))}
+}
+
+// dest returns the scan destinations for the percentile columns
+func (p *percentileValues) dest() []interface{} {
+	dest := make([]interface{}, len(p.values))
+	for i := range p.values {
+		dest[i] = &p.values[i]
+	}
+	return dest
+}
+
+// result returns the scanned percentiles keyed by PercentileKey, leaving out percentiles with no
+// resolved incidents, or nil when none were requested
+func (p *percentileValues) result() map[string]float64 {
+	if len(p.percentiles) == 0 {
+		return nil
+	}
+	result := make(map[string]float64, len(p.percentiles))
+	for i, value := range p.values {
+		if value.Valid {
+			result[PercentileKey(p.percentiles[i])] = value.Float64
+		}
+	}
+	return result
+}
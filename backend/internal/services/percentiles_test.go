@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestAnalyticsService_ResolutionPercentiles(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+
+	// Portal resolves in 1 to 10 hours plus one 1000 hour outlier; Billing has no resolved incidents
+	resolved := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+	var incidents []models.Incident
+	for i, hours := range []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1000} {
+		incident := diffTestIncident(fmt.Sprintf("r%d", i), "upload-1", fmt.Sprintf("INC%03d", i), "P3", "Closed")
+		incident.ResolveDate = &resolved
+		incident.ResolutionTimeHours = &hours
+		incidents = append(incidents, incident)
+	}
+	open := diffTestIncident("open", "upload-1", "INC100", "P3", "Open")
+	open.ApplicationName = "Billing"
+	incidents = append(incidents, open)
+
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	service := NewAnalyticsService(db)
+	filters := &TimelineFilters{Percentiles: []float64{80, 95}}
+
+	metrics, err := service.GetResolutionAnalysis(ctx, filters)
+	if err != nil {
+		t.Fatalf("Failed to get resolution analysis: %v", err)
+	}
+	if len(metrics.Percentiles) != 2 || metrics.Percentiles["p80"] != 9 || metrics.Percentiles["p95"] != 505 {
+		t.Errorf("Expected p80 9 and p95 505, got %v", metrics.Percentiles)
+	}
+
+	// Excluding outliers applies to the percentiles too
+	metrics, err = service.GetResolutionAnalysisWithOptions(ctx, filters, OutlierOptions{Exclude: true})
+	if err != nil {
+		t.Fatalf("Failed to get resolution analysis: %v", err)
+	}
+	if metrics.ExcludedOutliers != 1 || metrics.Percentiles["p80"] != 8.2 {
+		t.Errorf("Expected p80 8.2 without the outlier, got %v (%d excluded)", metrics.Percentiles, metrics.ExcludedOutliers)
+	}
+
+	analysis, err := service.GetApplicationAnalysis(ctx, filters)
+	if err != nil {
+		t.Fatalf("Failed to get application analysis: %v", err)
+	}
+	if len(analysis) != 2 {
+		t.Fatalf("Expected 2 applications, got %d", len(analysis))
+	}
+	if analysis[0].ApplicationName != "Portal" || analysis[0].Percentiles["p80"] != 9 {
+		t.Errorf("Expected Portal p80 9, got %+v", analysis[0])
+	}
+	if analysis[1].ApplicationName != "Billing" || len(analysis[1].Percentiles) != 0 {
+		t.Errorf("Expected no percentiles for Billing without resolved incidents, got %+v", analysis[1])
+	}
+
+	if key := PercentileKey(99.9); key != "p99.9" {
+		t.Errorf("Expected key p99.9, got %s", key)
+	}
+
+	// Without percentiles requested none are reported
+	metrics, err = service.GetResolutionAnalysis(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get resolution analysis: %v", err)
+	}
+	if metrics.Percentiles != nil {
+		t.Errorf("Expected no percentiles, got %v", metrics.Percentiles)
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -83,7 +84,7 @@ func TestProcessingService_ProcessIncidentsWithAnalysis(t *testing.T) {
 	}
 
 	// Process incidents with analysis
-	err = service.processIncidentsWithAnalysis(incidents)
+	err = service.processIncidentsWithAnalysis(context.Background(), incidents)
 	if err != nil {
 		t.Fatalf("Failed to process incidents with analysis: %v", err)
 	}
@@ -198,7 +199,7 @@ func TestProcessingService_ProcessIncidentsWithAnalysis_ErrorHandling(t *testing
 	}
 
 	// Process incidents with analysis - should not fail even with minimal data
-	err = service.processIncidentsWithAnalysis(incidents)
+	err = service.processIncidentsWithAnalysis(context.Background(), incidents)
 	if err != nil {
 		t.Fatalf("Processing should not fail with minimal data: %v", err)
 	}
@@ -270,7 +271,7 @@ func TestProcessingService_ProcessIncidentsWithAnalysis_NilAnalyzers(t *testing.
 	}
 
 	// Process incidents with nil analyzers - should not fail
-	err = service.processIncidentsWithAnalysis(incidents)
+	err = service.processIncidentsWithAnalysis(context.Background(), incidents)
 	if err != nil {
 		t.Fatalf("Processing should not fail with nil analyzers: %v", err)
 	}
@@ -3,8 +3,9 @@ package services
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"sync"
 	"time"
 
 	"incident-management-system/internal/models"
@@ -17,8 +18,21 @@ type ProcessingService struct {
 	fileStore          *storage.FileStore
 	excelParser        *ExcelParser
 	incidentService    *IncidentService
+	appNormalizer      *ApplicationNormalizer
+	serviceCatalog     *ServiceCatalog
+	cmdb               *CMDBService
 	sentimentAnalyzer  SentimentAnalyzer
 	automationAnalyzer AutomationAnalyzer
+	severityEstimator  *SeverityEstimator
+	shadowService      *ShadowService
+	profileService     *UploadProfileService
+	continuityService  *UploadContinuityService
+	distributionCheck  *DistributionCheckService
+	gate               processingGate
+	preemptor          processingPreemptor
+	flags              *FeatureFlagService
+	manualMu           sync.Mutex // Serializes manual entries into the day's upload
+	onCompleted        func(ctx context.Context, uploadID string)
 }
 
 // NewProcessingService creates a new ProcessingService instance
@@ -28,11 +42,103 @@ func NewProcessingService(db *sql.DB, fileStore *storage.FileStore) *ProcessingS
 		fileStore:          fileStore,
 		excelParser:        NewExcelParser(DefaultExcelParserConfig()),
 		incidentService:    NewIncidentService(db),
+		appNormalizer:      NewApplicationNormalizer(db),
+		serviceCatalog:     NewServiceCatalog(db),
+		cmdb:               NewCMDBService(db),
 		sentimentAnalyzer:  NewSimpleSentimentAnalyzer(),
 		automationAnalyzer: NewSimpleAutomationAnalyzer(),
+		severityEstimator:  NewSeverityEstimator(),
+		shadowService:      NewShadowService(db),
+		profileService:     NewUploadProfileService(db),
+		continuityService:  NewUploadContinuityService(db),
+		distributionCheck:  NewDistributionCheckService(db),
 	}
 }
 
+// processingGate holds back new processing runs, such as while the server is short of memory
+type processingGate interface {
+	Wait(ctx context.Context) error
+}
+
+// SetGate makes every processing run wait for gate before it starts reading its file
+func (s *ProcessingService) SetGate(gate processingGate) {
+	s.gate = gate
+}
+
+// urgentGate is implemented by gates with a priority lane urgent runs start through
+type urgentGate interface {
+	WaitUrgent(ctx context.Context) error
+}
+
+// processingPreemptor holds back background work, such as cache warming, until the release
+// function it returns is called
+type processingPreemptor interface {
+	Preempt() (release func())
+}
+
+// SetPreemptor makes urgent processing runs hold back preemptor's background work while they run
+func (s *ProcessingService) SetPreemptor(preemptor processingPreemptor) {
+	s.preemptor = preemptor
+}
+
+// SetFeatureFlags makes optional processing steps, such as shadow analysis, depend on their
+// feature flags
+func (s *ProcessingService) SetFeatureFlags(flags *FeatureFlagService) {
+	s.flags = flags
+}
+
+// SetParserWorkers changes how many rows of a spreadsheet are parsed concurrently, taking
+// effect for the next file read
+func (s *ProcessingService) SetParserWorkers(workers int) {
+	s.excelParser.SetMaxWorkers(workers)
+}
+
+// SetInsertProgress registers fn to be told, as each chunk of an upload's incidents is stored,
+// how many of its incidents are stored so far
+func (s *ProcessingService) SetInsertProgress(fn func(uploadID string, inserted, total int)) {
+	s.incidentService.SetInsertProgress(fn)
+}
+
+// SetOnCompleted registers fn to be called when an upload finishes processing with incidents
+// stored, completed or completed with errors, such as to warm caches with its incidents
+func (s *ProcessingService) SetOnCompleted(fn func(ctx context.Context, uploadID string)) {
+	s.onCompleted = fn
+}
+
+// SetContinuityThreshold changes the percentage by which a day's count for an application may
+// differ from the previous upload before the continuity check reports it
+func (s *ProcessingService) SetContinuityThreshold(pct float64) {
+	s.continuityService.SetThreshold(pct)
+}
+
+// SetDistributionShiftFactor changes how many times larger or smaller than in the prior period a
+// share or average may become before the distribution check flags it
+func (s *ProcessingService) SetDistributionShiftFactor(factor float64) {
+	s.distributionCheck.SetShiftFactor(factor)
+}
+
+// waitForGate blocks until the gate lets a new run start, through its priority lane for urgent
+// runs, returning the context's error if it is cancelled while waiting
+func (s *ProcessingService) waitForGate(ctx context.Context, urgent bool) error {
+	if s.gate == nil {
+		return nil
+	}
+	if priority, ok := s.gate.(urgentGate); ok && urgent {
+		return priority.WaitUrgent(ctx)
+	}
+	return s.gate.Wait(ctx)
+}
+
+// sentimentPhraseLoader is implemented by sentiment analyzers with a configurable phrase lexicon
+type sentimentPhraseLoader interface {
+	LoadPhrases(ctx context.Context, db *sql.DB) error
+}
+
+// automationKeywordLoader is implemented by automation analyzers with persisted custom keywords
+type automationKeywordLoader interface {
+	LoadKeywords(ctx context.Context, db *sql.DB) error
+}
+
 // ProcessingProgress represents the progress of file processing
 type ProcessingProgress struct {
 	UploadID      string     `json:"upload_id"`
@@ -45,60 +151,121 @@ type ProcessingProgress struct {
 	StartTime     time.Time  `json:"start_time"`
 	EndTime       *time.Time `json:"end_time,omitempty"`
 	Duration      string     `json:"duration,omitempty"`
+	Cancelled     bool       `json:"cancelled,omitempty"`
+	DryRun        bool       `json:"dry_run,omitempty"`
+	// IngestedSheet is the sheet and cell range of the workbook the incidents were read from
+	IngestedSheet *models.IngestedSheet `json:"ingested_sheet,omitempty"`
+	// Cleanup is the outcome of deleting incidents a failed run had already stored
+	Cleanup *models.UploadCleanup `json:"cleanup,omitempty"`
+
+	timings processingTimings
 }
 
-// ProcessUpload processes an uploaded Excel file
+// ErrNoPendingUploads is returned when a dataset has no uploads waiting to be processed
+var ErrNoPendingUploads = errors.New("dataset has no uploads waiting to be processed")
+
+// statusUpdateTimeout bounds the final status write made after the processing context is cancelled
+const statusUpdateTimeout = 10 * time.Second
+
+// ProcessUpload processes an uploaded Excel file with the default processing options
 func (s *ProcessingService) ProcessUpload(ctx context.Context, uploadID string) (*ProcessingProgress, error) {
+	return s.ProcessUploadWithOptions(ctx, uploadID, models.DefaultProcessingOptions())
+}
+
+// ProcessUploadWithOptions queues an uploaded Excel file and processes it. A dry run parses,
+// deduplicates and analyzes the file without storing incidents and returns the upload to the
+// uploaded status so it can then be processed for real.
+func (s *ProcessingService) ProcessUploadWithOptions(ctx context.Context, uploadID string, options models.ProcessingOptions) (*ProcessingProgress, error) {
+	if err := s.QueueUpload(ctx, uploadID, options); err != nil {
+		return nil, err
+	}
+	return s.ProcessQueuedUpload(ctx, uploadID, options)
+}
+
+// QueueUpload claims an uploaded file for processing, moving it to the queued status and
+// recording the options it will be processed with. Only one caller can queue an upload; the
+// others get ErrInvalidUploadTransition, so an upload is never processed twice at once.
+func (s *ProcessingService) QueueUpload(ctx context.Context, uploadID string, options models.ProcessingOptions) error {
+	if err := s.incidentService.TransitionUpload(ctx, uploadID, models.UploadStatusQueued, UploadOutcome{},
+		models.UploadStatusUploaded); err != nil {
+		return fmt.Errorf("failed to queue upload: %w", err)
+	}
+
+	// Record how the upload is processed before anything can fail
+	if err := s.incidentService.SetUploadProcessingOptions(ctx, uploadID, options); err != nil {
+		queued := &ProcessingProgress{UploadID: uploadID, Status: models.UploadStatusQueued}
+		s.markProcessingFailed(ctx, queued, []string{fmt.Sprintf("Failed to record processing options: %v", err)})
+		return err
+	}
+	return nil
+}
+
+// ProcessQueuedUpload processes an upload queued by QueueUpload, once the gate lets a new run
+// start. Urgent runs hold back the preemptor's background work until they finish.
+func (s *ProcessingService) ProcessQueuedUpload(ctx context.Context, uploadID string, options models.ProcessingOptions) (*ProcessingProgress, error) {
 	progress := &ProcessingProgress{
 		UploadID:  uploadID,
-		Status:    models.UploadStatusProcessing,
+		Status:    models.UploadStatusQueued,
 		StartTime: time.Now(),
 		Errors:    make([]string, 0),
+		DryRun:    options.DryRun,
 	}
 
-	// Update upload status to processing
-	if err := s.incidentService.UpdateUploadStatus(ctx, uploadID, models.UploadStatusProcessing, 0, 0, 0, nil); err != nil {
-		return nil, fmt.Errorf("failed to update upload status to processing: %w", err)
+	// Preempting first frees the memory background work holds before the gate looks at it
+	if options.Urgent && s.preemptor != nil {
+		release := s.preemptor.Preempt()
+		defer release()
+	}
+
+	// The upload stays queued while it waits, so it cannot be queued again
+	if err := s.waitForGate(ctx, options.Urgent); err != nil {
+		return s.markProcessingCancelled(ctx, progress, "waiting to start")
+	}
+	if err := s.startProcessing(ctx, progress); err != nil {
+		return nil, err
 	}
 
 	// Get upload record to find the file
 	upload, err := s.getUploadRecord(ctx, uploadID)
+	if ctx.Err() != nil {
+		return s.markProcessingCancelled(ctx, progress, "loading upload")
+	}
 	if err != nil {
-		s.markProcessingFailed(ctx, uploadID, []string{fmt.Sprintf("Failed to get upload record: %v", err)})
+		s.markProcessingFailed(ctx, progress, []string{fmt.Sprintf("Failed to get upload record: %v", err)})
 		return nil, fmt.Errorf("failed to get upload record: %w", err)
 	}
 
+	parseOptions, err := s.parseOptions(ctx, options)
+	if err != nil {
+		s.markProcessingFailed(ctx, progress, []string{err.Error()})
+		return nil, err
+	}
+
 	// Get file path
 	filePath := s.fileStore.GetFilePath(upload.Filename)
 
 	// Parse Excel file
-	log.Printf("Starting to parse Excel file: %s", filePath)
-	incidents, err := s.excelParser.ParseFile(ctx, filePath)
+	logf(ctx, "Starting to parse Excel file: %s", filePath)
+	parseStart := time.Now()
+	parseResult, err := s.excelParser.ParseFileWithOptions(ctx, filePath, parseOptions)
+	progress.timings.parse = time.Since(parseStart)
+	if ctx.Err() != nil {
+		return s.markProcessingCancelled(ctx, progress, "parsing")
+	}
 	if err != nil {
 		errorMsg := fmt.Sprintf("Failed to parse Excel file: %v", err)
-		s.markProcessingFailed(ctx, uploadID, []string{errorMsg})
+		s.markProcessingFailed(ctx, progress, []string{errorMsg})
 		return nil, fmt.Errorf("failed to parse Excel file: %w", err)
 	}
 
-	// Create a mock ParseResult for compatibility
-	parseResult := &struct {
-		Incidents []models.Incident
-		TotalRows int
-		ValidRows int
-		Errors    []models.ValidationError
-	}{
-		Incidents: incidents,
-		TotalRows: len(incidents),
-		ValidRows: len(incidents),
-		Errors:    []models.ValidationError{},
-	}
-
+	s.recordParseReport(ctx, progress, parseResult)
 	progress.TotalRows = parseResult.TotalRows
-	progress.ValidRows = parseResult.ValidRows
+	progress.ValidRows = len(parseResult.Incidents)
 	progress.ErrorCount = len(parseResult.Errors)
+	progress.timings.validation = parseResult.ValidationTime
 
-	log.Printf("Parsed Excel file: %d total rows, %d valid rows, %d errors",
-		parseResult.TotalRows, parseResult.ValidRows, len(parseResult.Errors))
+	logf(ctx, "Parsed Excel file: %d total rows, %d valid rows, %d errors",
+		parseResult.TotalRows, len(parseResult.Incidents), len(parseResult.Errors))
 
 	// Collect error messages
 	errorMessages := make([]string, 0)
@@ -107,23 +274,66 @@ func (s *ProcessingService) ProcessUpload(ctx context.Context, uploadID string)
 	}
 	progress.Errors = errorMessages
 
+	// Fail the upload when more rows failed to parse than the error threshold allows
+	if exceedsErrorThreshold(parseResult.FailedRows, parseResult.TotalRows, options.ErrorThreshold) {
+		errorMsg := fmt.Sprintf("%d of %d rows failed to parse, above the %.1f%% error threshold",
+			parseResult.FailedRows, parseResult.TotalRows, options.ErrorThreshold)
+		s.markProcessingFailed(ctx, progress, append(errorMessages, errorMsg))
+		return nil, fmt.Errorf("failed to parse Excel file: %s", errorMsg)
+	}
+
+	// Uploads added to a dataset tag their incidents with it even when processed on their own
+	for i := range parseResult.Incidents {
+		parseResult.Incidents[i].DatasetID = upload.DatasetID
+	}
+
+	// Resolve incident IDs repeated within the file before analysis
+	dedupStart := time.Now()
+	incidents, dropped, err := dedupIncidents(parseResult.Incidents, options.DedupStrategy)
+	progress.timings.validation += time.Since(dedupStart)
+	if err != nil {
+		s.markProcessingFailed(ctx, progress, append(errorMessages, err.Error()))
+		return nil, err
+	}
+	for _, duplicate := range dropped {
+		errorMessages = append(errorMessages, duplicateError(duplicate, options.DedupStrategy).Error())
+	}
+	progress.Errors = errorMessages
+	progress.ErrorCount = len(errorMessages)
+	progress.ValidRows = len(incidents)
+
 	// If we have valid incidents, process them with analysis and then insert
 	var insertResult *BatchInsertResult
-	if len(parseResult.Incidents) > 0 {
-		log.Printf("Processing %d incidents with analysis", len(parseResult.Incidents))
-
-		// Process incidents with sentiment and automation analysis
-		err = s.processIncidentsWithAnalysis(parseResult.Incidents)
+	if len(incidents) > 0 {
+		analysisStart := time.Now()
+		err = s.normalizeAndAnalyze(ctx, incidents, options)
+		progress.timings.analysis = time.Since(analysisStart)
+		if ctx.Err() != nil {
+			return s.markProcessingCancelled(ctx, progress, "analysis")
+		}
 		if err != nil {
-			log.Printf("Warning: Analysis processing failed: %v", err)
+			logf(ctx, "Warning: Analysis processing failed: %v", err)
 			// Continue with insertion even if analysis fails
 		}
 
-		log.Printf("Inserting %d incidents into database", len(parseResult.Incidents))
-		insertResult, err = s.incidentService.BatchInsertIncidents(ctx, parseResult.Incidents, uploadID)
+		if options.DryRun {
+			return s.completeDryRun(ctx, progress)
+		}
+
+		logf(ctx, "Inserting %d incidents into database", len(incidents))
+		insertStart := time.Now()
+		insertResult, err = s.incidentService.BatchInsertIncidents(ctx, incidents, uploadID)
+		progress.timings.insert = time.Since(insertStart)
+		if err != nil {
+			// Earlier chunks may have committed; the upload must not keep them once it fails
+			s.cleanupPartialInsert(ctx, progress, insertResult, err)
+		}
+		if ctx.Err() != nil {
+			return s.markProcessingCancelled(ctx, progress, "insertion")
+		}
 		if err != nil {
 			errorMsg := fmt.Sprintf("Failed to insert incidents: %v", err)
-			s.markProcessingFailed(ctx, uploadID, append(errorMessages, errorMsg))
+			s.markProcessingFailed(ctx, progress, append(errorMessages, errorMsg))
 			return nil, fmt.Errorf("failed to insert incidents: %w", err)
 		}
 
@@ -136,20 +346,103 @@ func (s *ProcessingService) ProcessUpload(ctx context.Context, uploadID string)
 		progress.Errors = errorMessages
 		progress.ErrorCount = len(errorMessages)
 
-		log.Printf("Inserted %d incidents successfully", insertResult.InsertedCount)
+		logf(ctx, "Inserted %d incidents successfully", insertResult.InsertedCount)
+
+		// Evaluate the active shadow configuration, if any, without touching production fields
+		shadowStart := time.Now()
+		s.runShadowAnalysis(ctx, uploadID, incidents)
+		progress.timings.analysis += time.Since(shadowStart)
+	} else if options.DryRun {
+		return s.completeDryRun(ctx, progress)
 	}
 
+	return s.finishProcessing(ctx, progress), nil
+}
+
+// startProcessing moves a queued upload to processing when its run starts
+func (s *ProcessingService) startProcessing(ctx context.Context, progress *ProcessingProgress) error {
+	if err := s.incidentService.TransitionUpload(ctx, progress.UploadID, models.UploadStatusProcessing, UploadOutcome{},
+		models.UploadStatusQueued); err != nil {
+		return fmt.Errorf("failed to update upload status to processing: %w", err)
+	}
+	progress.Status = models.UploadStatusProcessing
+	progress.StartTime = time.Now()
+	s.sweepLeftoverIncidents(ctx, progress)
+	return nil
+}
+
+// sweepLeftoverIncidents deletes incidents an earlier run of the upload left behind, such as when
+// the server stopped mid-insert, so a retry starts from an empty upload. The upload's cleanup
+// record is replaced by the sweep's outcome, or cleared when there was nothing to delete.
+func (s *ProcessingService) sweepLeftoverIncidents(ctx context.Context, progress *ProcessingProgress) {
+	count, err := s.incidentService.GetIncidentCount(ctx, progress.UploadID)
+	if err != nil {
+		logf(ctx, "Warning: Failed to check upload %s for leftover incidents: %v", progress.UploadID, err)
+		return
+	}
+	if count == 0 {
+		if err := s.incidentService.SetUploadCleanup(ctx, progress.UploadID, nil); err != nil {
+			logf(ctx, "Warning: Failed to clear cleanup of upload %s: %v", progress.UploadID, err)
+		}
+		return
+	}
+	s.cleanupUpload(ctx, progress, models.CleanupReasonLeftoverRows)
+}
+
+// cleanupPartialInsert is the compensating step for an insert that failed or was cancelled after
+// some of its chunks committed: it deletes the upload's incidents so the failed upload holds no
+// partial rows
+func (s *ProcessingService) cleanupPartialInsert(ctx context.Context, progress *ProcessingProgress, result *BatchInsertResult, insertErr error) {
+	reason := models.CleanupReasonInsertFailed
+	if ctx.Err() != nil || errors.Is(insertErr, context.Canceled) || errors.Is(insertErr, context.DeadlineExceeded) {
+		reason = models.CleanupReasonCancelled
+	}
+	if result != nil {
+		progress.ProcessedRows = result.InsertedCount
+	}
+	s.cleanupUpload(ctx, progress, reason)
+}
+
+// cleanupUpload deletes the incidents stored for an upload and records the outcome on the upload
+// and the progress. It uses a detached context so it still runs after cancellation.
+func (s *ProcessingService) cleanupUpload(ctx context.Context, progress *ProcessingProgress, reason string) {
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusUpdateTimeout)
+	defer cancel()
+
+	cleanup := &models.UploadCleanup{Reason: reason, Status: models.CleanupStatusCompleted}
+	deleted, err := s.incidentService.CleanupUploadIncidents(cleanupCtx, progress.UploadID)
+	cleanup.CleanedAt = time.Now()
+	if err != nil {
+		cleanup.Status = models.CleanupStatusFailed
+		cleanup.Error = err.Error()
+		logf(ctx, "Warning: Failed to clean up incidents of upload %s: %v", progress.UploadID, err)
+	} else {
+		cleanup.DeletedRows = deleted
+		progress.ProcessedRows = 0
+		logf(ctx, "Cleaned up %d incidents of upload %s (%s)", deleted, progress.UploadID, reason)
+	}
+	progress.Cleanup = cleanup
+
+	if err := s.incidentService.SetUploadCleanup(cleanupCtx, progress.UploadID, cleanup); err != nil {
+		logf(ctx, "Warning: Failed to record cleanup of upload %s: %v", progress.UploadID, err)
+	}
+}
+
+// finishProcessing records the final status of a processed upload: completed, completed with
+// errors when some rows were rejected, or failed when nothing was stored and there were errors
+func (s *ProcessingService) finishProcessing(ctx context.Context, progress *ProcessingProgress) *ProcessingProgress {
 	// Determine final status
 	finalStatus := models.UploadStatusCompleted
 	if progress.ProcessedRows == 0 && progress.ErrorCount > 0 {
 		finalStatus = models.UploadStatusFailed
+	} else if progress.ErrorCount > 0 {
+		finalStatus = models.UploadStatusCompletedWithErrors
 	}
 
 	// Update final upload status
-	err = s.incidentService.UpdateUploadStatus(ctx, uploadID, finalStatus,
-		progress.TotalRows, progress.ProcessedRows, progress.ErrorCount, errorMessages)
+	err := s.incidentService.TransitionUpload(ctx, progress.UploadID, finalStatus, progress.outcome(), progress.Status)
 	if err != nil {
-		log.Printf("Warning: Failed to update final upload status: %v", err)
+		logf(ctx, "Warning: Failed to update final upload status: %v", err)
 	}
 
 	// Set completion time and duration
@@ -158,28 +451,424 @@ func (s *ProcessingService) ProcessUpload(ctx context.Context, uploadID string)
 	progress.Status = finalStatus
 	progress.Duration = endTime.Sub(progress.StartTime).String()
 
-	log.Printf("Processing completed for upload %s: status=%s, processed=%d, errors=%d",
-		uploadID, finalStatus, progress.ProcessedRows, progress.ErrorCount)
+	logf(ctx, "Processing completed for upload %s: status=%s, processed=%d, errors=%d",
+		progress.UploadID, finalStatus, progress.ProcessedRows, progress.ErrorCount)
+
+	if finalStatus != models.UploadStatusFailed && progress.ProcessedRows > 0 {
+		s.checkContinuity(ctx, progress.UploadID)
+		s.checkDistribution(ctx, progress.UploadID)
+		if s.onCompleted != nil {
+			s.onCompleted(ctx, progress.UploadID)
+		}
+	}
+	s.recordProfile(ctx, progress)
+	return progress
+}
+
+// ProcessDataset processes every upload of a dataset still in the uploaded status as one batch.
+// Each file is parsed and checked against the error threshold on its own; incident IDs repeated
+// across files are then resolved with the dedup strategy, treating files in the order they were
+// added. Incidents are stored under their own upload and tagged with the dataset ID.
+func (s *ProcessingService) ProcessDataset(ctx context.Context, datasetID string, options models.ProcessingOptions) ([]*ProcessingProgress, error) {
+	queued, err := s.QueueDataset(ctx, datasetID, options)
+	if err != nil {
+		return nil, err
+	}
+	return s.ProcessQueuedDataset(ctx, datasetID, queued, options)
+}
+
+// QueueDataset queues every upload of a dataset still in the uploaded status, returning them in
+// the order they were added, or ErrNoPendingUploads when there are none. Uploads another run
+// queues first are left to that run.
+func (s *ProcessingService) QueueDataset(ctx context.Context, datasetID string, options models.ProcessingOptions) ([]models.Upload, error) {
+	uploads, err := NewDatasetService(s.db).ListUploads(ctx, datasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var queued []models.Upload
+	for _, upload := range uploads {
+		if upload.Status != models.UploadStatusUploaded {
+			continue
+		}
+		if err := s.QueueUpload(ctx, upload.ID, options); err != nil {
+			logf(ctx, "Warning: Upload %s of dataset %s not queued: %v", upload.ID, datasetID, err)
+			continue
+		}
+		queued = append(queued, upload)
+	}
+	if len(queued) == 0 {
+		return nil, ErrNoPendingUploads
+	}
+	return queued, nil
+}
+
+// ProcessQueuedDataset processes the uploads of a dataset queued by QueueDataset as one batch,
+// once the gate lets a new run start
+func (s *ProcessingService) ProcessQueuedDataset(ctx context.Context, datasetID string, queued []models.Upload, options models.ProcessingOptions) ([]*ProcessingProgress, error) {
+	all := make([]*ProcessingProgress, 0, len(queued))
+	for _, upload := range queued {
+		all = append(all, &ProcessingProgress{
+			UploadID:  upload.ID,
+			Status:    models.UploadStatusQueued,
+			StartTime: time.Now(),
+			Errors:    make([]string, 0),
+			DryRun:    options.DryRun,
+		})
+	}
+	if err := s.waitForGate(ctx, false); err != nil {
+		return s.cancelDataset(ctx, all, "waiting to start")
+	}
+
+	parseOptions, parseOptionsErr := s.parseOptions(ctx, options)
+
+	var active []*ProcessingProgress
+	var combined []models.Incident
+
+	for i, upload := range queued {
+		progress := all[i]
+		if err := s.startProcessing(ctx, progress); err != nil {
+			s.failProgress(ctx, progress, err.Error())
+			continue
+		}
+		if parseOptionsErr != nil {
+			s.failProgress(ctx, progress, parseOptionsErr.Error())
+			continue
+		}
+
+		filePath := s.fileStore.GetFilePath(upload.Filename)
+		logf(ctx, "Starting to parse Excel file: %s", filePath)
+		parseStart := time.Now()
+		parseResult, err := s.excelParser.ParseFileWithOptions(ctx, filePath, parseOptions)
+		progress.timings.parse = time.Since(parseStart)
+		if ctx.Err() != nil {
+			return s.cancelDataset(ctx, all, "parsing")
+		}
+		if err != nil {
+			s.failProgress(ctx, progress, fmt.Sprintf("Failed to parse Excel file: %v", err))
+			continue
+		}
+
+		s.recordParseReport(ctx, progress, parseResult)
+		progress.TotalRows = parseResult.TotalRows
+		progress.timings.validation = parseResult.ValidationTime
+		for _, validationError := range parseResult.Errors {
+			progress.Errors = append(progress.Errors, validationError.Error())
+		}
+		if exceedsErrorThreshold(parseResult.FailedRows, parseResult.TotalRows, options.ErrorThreshold) {
+			s.failProgress(ctx, progress, fmt.Sprintf("%d of %d rows failed to parse, above the %.1f%% error threshold",
+				parseResult.FailedRows, parseResult.TotalRows, options.ErrorThreshold))
+			continue
+		}
+
+		for i := range parseResult.Incidents {
+			parseResult.Incidents[i].UploadID = upload.ID
+			parseResult.Incidents[i].DatasetID = datasetID
+		}
+		combined = append(combined, parseResult.Incidents...)
+		active = append(active, progress)
+	}
+
+	// Resolve incident IDs repeated anywhere in the dataset
+	dedupStart := time.Now()
+	incidents, dropped, err := dedupIncidents(combined, options.DedupStrategy)
+	dedupTime := time.Since(dedupStart)
+	if err != nil {
+		for _, progress := range active {
+			s.failProgress(ctx, progress, err.Error())
+		}
+		return all, err
+	}
+
+	byUpload := make(map[string]*ProcessingProgress, len(active))
+	for _, progress := range active {
+		byUpload[progress.UploadID] = progress
+	}
+	for _, duplicate := range dropped {
+		progress := byUpload[duplicate.UploadID]
+		progress.Errors = append(progress.Errors, duplicateError(duplicate, options.DedupStrategy).Error())
+	}
+
+	var analysisTime time.Duration
+	if len(incidents) > 0 {
+		analysisStart := time.Now()
+		err = s.normalizeAndAnalyze(ctx, incidents, options)
+		analysisTime = time.Since(analysisStart)
+		if ctx.Err() != nil {
+			return s.cancelDataset(ctx, all, "analysis")
+		}
+		if err != nil {
+			logf(ctx, "Warning: Analysis processing failed: %v", err)
+		}
+	}
+
+	grouped := make(map[string][]models.Incident, len(active))
+	for _, incident := range incidents {
+		grouped[incident.UploadID] = append(grouped[incident.UploadID], incident)
+	}
+
+	for _, progress := range active {
+		uploadIncidents := grouped[progress.UploadID]
+		progress.ValidRows = len(uploadIncidents)
+		progress.ErrorCount = len(progress.Errors)
+
+		// Deduplication and analysis ran once over the whole dataset; each upload is profiled
+		// with its share by incident count
+		progress.timings.validation += stageShare(dedupTime, len(uploadIncidents), len(incidents))
+		progress.timings.analysis = stageShare(analysisTime, len(uploadIncidents), len(incidents))
+
+		if options.DryRun {
+			s.completeDryRun(ctx, progress)
+			continue
+		}
+
+		if len(uploadIncidents) > 0 {
+			insertStart := time.Now()
+			insertResult, err := s.incidentService.BatchInsertIncidents(ctx, uploadIncidents, progress.UploadID)
+			progress.timings.insert = time.Since(insertStart)
+			if err != nil {
+				s.cleanupPartialInsert(ctx, progress, insertResult, err)
+			}
+			if ctx.Err() != nil {
+				return s.cancelDataset(ctx, all, "insertion")
+			}
+			if err != nil {
+				s.failProgress(ctx, progress, fmt.Sprintf("Failed to insert incidents: %v", err))
+				continue
+			}
+
+			progress.ProcessedRows = insertResult.InsertedCount
+			for _, insertError := range insertResult.Errors {
+				progress.Errors = append(progress.Errors, insertError.Error())
+			}
+			progress.ErrorCount = len(progress.Errors)
+
+			shadowStart := time.Now()
+			s.runShadowAnalysis(ctx, progress.UploadID, uploadIncidents)
+			progress.timings.analysis += time.Since(shadowStart)
+		}
+
+		s.finishProcessing(ctx, progress)
+	}
+
+	logf(ctx, "Processing completed for dataset %s: %d uploads", datasetID, len(all))
+	return all, nil
+}
+
+// failProgress marks one upload of a batch as failed with a final error message
+func (s *ProcessingService) failProgress(ctx context.Context, progress *ProcessingProgress, message string) {
+	progress.Errors = append(progress.Errors, message)
+	progress.ErrorCount = len(progress.Errors)
+	s.markProcessingFailed(ctx, progress, progress.Errors)
+
+	endTime := time.Now()
+	progress.EndTime = &endTime
+	progress.Status = models.UploadStatusFailed
+	progress.Duration = endTime.Sub(progress.StartTime).String()
+
+	s.recordProfile(ctx, progress)
+}
+
+// cancelDataset records the cancellation on every upload of a batch that had not finished
+func (s *ProcessingService) cancelDataset(ctx context.Context, all []*ProcessingProgress, stage string) ([]*ProcessingProgress, error) {
+	var err error
+	for _, progress := range all {
+		if progress.EndTime != nil {
+			continue
+		}
+		_, err = s.markProcessingCancelled(ctx, progress, stage)
+	}
+	return all, err
+}
+
+// parseOptions resolves the mapping profile, validation rule set and time zone named in the
+// processing options
+func (s *ProcessingService) parseOptions(ctx context.Context, options models.ProcessingOptions) (ParseOptions, error) {
+	parseOptions := ParseOptions{Sheet: options.Sheet}
+
+	if options.MappingProfile != "" {
+		profile, err := NewMappingProfileService(s.db).GetProfile(ctx, options.MappingProfile)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return parseOptions, fmt.Errorf("mapping profile not found: %s", options.MappingProfile)
+			}
+			return parseOptions, fmt.Errorf("failed to load mapping profile: %w", err)
+		}
+		parseOptions.Columns = profile.Columns
+		parseOptions.DateFormats = profile.DateFormats
+		parseOptions.PendingStatuses = profile.PendingStatuses
+	}
+
+	if options.RuleSet != "" {
+		engine, err := NewValidationRuleSetService(s.db).LoadEngine(ctx, options.RuleSet)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return parseOptions, fmt.Errorf("validation rule set not found: %s", options.RuleSet)
+			}
+			return parseOptions, fmt.Errorf("failed to load validation rule set: %w", err)
+		}
+		parseOptions.Rules = engine
+	}
+
+	if options.Timezone != "" {
+		location, err := time.LoadLocation(options.Timezone)
+		if err != nil {
+			return parseOptions, fmt.Errorf("invalid timezone %q: %w", options.Timezone, err)
+		}
+		parseOptions.Location = location
+	}
+
+	return parseOptions, nil
+}
+
+// normalizeAndAnalyze canonicalizes application and business service names, estimates missing
+// priorities when options ask for it and runs the analyzers enabled in options, reloading their
+// persisted phrases and keywords first. Priorities are estimated first, as automation scores
+// weigh them.
+func (s *ProcessingService) normalizeAndAnalyze(ctx context.Context, incidents []models.Incident, options models.ProcessingOptions) error {
+	s.normalizeIncidents(ctx, incidents)
+
+	if options.InferPriority {
+		if inferred := s.severityEstimator.InferPriorities(incidents); inferred > 0 {
+			logf(ctx, "Inferred the priority of %d incidents without one", inferred)
+		}
+	}
+
+	s.loadAnalyzerRules(ctx, options.RunSentiment, options.RunAutomation)
+
+	logf(ctx, "Processing %d incidents with analysis", len(incidents))
+
+	// Process incidents with the enabled sentiment and automation analysis
+	return s.analyzeIncidents(ctx, incidents, options.RunSentiment, options.RunAutomation)
+}
+
+// normalizeIncidents maps application name variants to canonical names and business services to
+// the service catalog, keeping the raw values, and attaches the CMDB attributes of the result
+func (s *ProcessingService) normalizeIncidents(ctx context.Context, incidents []models.Incident) {
+	if err := s.appNormalizer.LoadAliases(ctx); err != nil {
+		logf(ctx, "Warning: Failed to load application aliases: %v", err)
+	}
+	s.appNormalizer.NormalizeIncidents(incidents)
+
+	if err := s.serviceCatalog.LoadServices(ctx); err != nil {
+		logf(ctx, "Warning: Failed to load service catalog: %v", err)
+	}
+	s.serviceCatalog.NormalizeIncidents(incidents)
+
+	if err := s.cmdb.LoadItems(ctx); err != nil {
+		logf(ctx, "Warning: Failed to load CMDB items: %v", err)
+	}
+	s.cmdb.EnrichIncidents(incidents)
+}
+
+// loadAnalyzerRules picks up phrases and keywords added to the enabled analyzers since the last run
+func (s *ProcessingService) loadAnalyzerRules(ctx context.Context, runSentiment, runAutomation bool) {
+	if loader, ok := s.sentimentAnalyzer.(sentimentPhraseLoader); ok && runSentiment {
+		if err := loader.LoadPhrases(ctx, s.db); err != nil {
+			logf(ctx, "Warning: Failed to load sentiment phrases: %v", err)
+		}
+	}
+	if loader, ok := s.automationAnalyzer.(automationKeywordLoader); ok && runAutomation {
+		if err := loader.LoadKeywords(ctx, s.db); err != nil {
+			logf(ctx, "Warning: Failed to load automation keywords: %v", err)
+		}
+	}
+}
+
+// exceedsErrorThreshold reports whether the share of failed rows is above threshold percent
+func exceedsErrorThreshold(failedRows, totalRows int, threshold float64) bool {
+	if failedRows == 0 || totalRows == 0 {
+		return false
+	}
+	return float64(failedRows)*100.0/float64(totalRows) > threshold
+}
+
+// dedupIncidents resolves incident IDs repeated within one batch of rows. The first and last
+// strategies keep one occurrence and return the others as dropped; the fail strategy rejects
+// the batch.
+func dedupIncidents(incidents []models.Incident, strategy string) (kept, dropped []models.Incident, err error) {
+	kept = make([]models.Incident, 0, len(incidents))
+	positions := make(map[string]int, len(incidents))
+
+	for _, incident := range incidents {
+		position, seen := positions[incident.IncidentID]
+		if !seen {
+			positions[incident.IncidentID] = len(kept)
+			kept = append(kept, incident)
+			continue
+		}
+
+		switch strategy {
+		case models.DedupStrategyFail:
+			return nil, nil, fmt.Errorf("duplicate incident ID within upload: %s", incident.IncidentID)
+		case models.DedupStrategyLast:
+			dropped = append(dropped, kept[position])
+			kept[position] = incident
+		default:
+			dropped = append(dropped, incident)
+		}
+	}
+
+	return kept, dropped, nil
+}
+
+// duplicateError describes a row dropped by dedup
+func duplicateError(incident models.Incident, strategy string) models.ValidationError {
+	message := "duplicate incident ID within upload"
+	if strategy == models.DedupStrategyLast {
+		message += ", kept last occurrence"
+	}
+	return models.ValidationError{Field: "incident_id", Value: incident.IncidentID, Message: message}
+}
+
+// completeDryRun returns a dry-run upload to the uploaded status with the counts and errors a
+// real run would have produced
+func (s *ProcessingService) completeDryRun(ctx context.Context, progress *ProcessingProgress) (*ProcessingProgress, error) {
+	outcome := progress.outcome()
+	outcome.ProcessedCount = 0
+	err := s.incidentService.TransitionUpload(ctx, progress.UploadID, models.UploadStatusUploaded, outcome, progress.Status)
+	if err != nil {
+		logf(ctx, "Warning: Failed to update upload status after dry run: %v", err)
+	}
+
+	endTime := time.Now()
+	progress.EndTime = &endTime
+	progress.Status = models.UploadStatusUploaded
+	progress.Duration = endTime.Sub(progress.StartTime).String()
+
+	logf(ctx, "Dry run completed for upload %s: valid=%d, errors=%d",
+		progress.UploadID, progress.ValidRows, progress.ErrorCount)
+
+	s.recordProfile(ctx, progress)
 
 	return progress, nil
 }
 
-// RollbackProcessing rolls back a failed processing operation
+// RollbackProcessing rolls back a finished processing run, deleting its incidents and returning
+// the upload to the uploaded status. Runs that have not finished cannot be rolled back.
 func (s *ProcessingService) RollbackProcessing(ctx context.Context, uploadID string) error {
-	log.Printf("Rolling back processing for upload %s", uploadID)
+	logf(ctx, "Rolling back processing for upload %s", uploadID)
+
+	status, err := uploadStatus(ctx, s.db, uploadID)
+	if err != nil {
+		return fmt.Errorf("failed to reset upload status during rollback: %w", err)
+	}
+	if !models.IsUploadFinished(status) {
+		return fmt.Errorf("%w: upload %s is %s and has not finished processing", ErrInvalidUploadTransition, uploadID, status)
+	}
 
 	// Delete any inserted incidents
 	if err := s.incidentService.DeleteIncidentsByUpload(ctx, uploadID); err != nil {
-		log.Printf("Warning: Failed to delete incidents during rollback: %v", err)
+		logf(ctx, "Warning: Failed to delete incidents during rollback: %v", err)
 	}
 
 	// Reset upload status
-	err := s.incidentService.UpdateUploadStatus(ctx, uploadID, models.UploadStatusUploaded, 0, 0, 0, nil)
+	err = s.incidentService.TransitionUpload(ctx, uploadID, models.UploadStatusUploaded, UploadOutcome{}, status)
 	if err != nil {
 		return fmt.Errorf("failed to reset upload status during rollback: %w", err)
 	}
 
-	log.Printf("Rollback completed for upload %s", uploadID)
+	logf(ctx, "Rollback completed for upload %s", uploadID)
 	return nil
 }
 
@@ -197,6 +886,8 @@ func (s *ProcessingService) GetProcessingStatus(ctx context.Context, uploadID st
 		ProcessedRows: upload.ProcessedCount,
 		ErrorCount:    upload.ErrorCount,
 		Errors:        upload.Errors,
+		IngestedSheet: upload.IngestedSheet,
+		Cleanup:       upload.Cleanup,
 	}
 
 	// Calculate duration if processing is complete
@@ -209,11 +900,118 @@ func (s *ProcessingService) GetProcessingStatus(ctx context.Context, uploadID st
 	return progress, nil
 }
 
-// markProcessingFailed marks an upload as failed with error messages
-func (s *ProcessingService) markProcessingFailed(ctx context.Context, uploadID string, errors []string) {
-	err := s.incidentService.UpdateUploadStatus(ctx, uploadID, models.UploadStatusFailed, 0, 0, len(errors), errors)
+// recordParseReport notes on the progress and the upload which sheet and range were parsed and
+// how the date columns were read. Failing to store it is logged rather than failing the upload.
+func (s *ProcessingService) recordParseReport(ctx context.Context, progress *ProcessingProgress, result *ParseResult) {
+	if result.Sheet == nil {
+		return
+	}
+	sheet := result.Sheet
+	progress.IngestedSheet = sheet
+	if err := s.incidentService.SetUploadParseReport(ctx, progress.UploadID, sheet, result.Dates); err != nil {
+		logf(ctx, "Warning: Failed to record parse report of upload %s: %v", progress.UploadID, err)
+	}
+	logf(ctx, "Upload %s read from sheet %q, range %s (header row %d, %d merged ranges, %d formulas evaluated)",
+		progress.UploadID, sheet.Sheet, sheet.Range, sheet.HeaderRow, sheet.MergedRanges, sheet.FormulasEvaluated)
+	for _, dates := range result.Dates {
+		for _, warning := range dates.Warnings {
+			logf(ctx, "Warning: Upload %s: %s", progress.UploadID, warning)
+		}
+	}
+}
+
+// markProcessingFailed marks the upload of a run as failed with error messages
+func (s *ProcessingService) markProcessingFailed(ctx context.Context, progress *ProcessingProgress, errors []string) {
+	err := s.incidentService.TransitionUpload(ctx, progress.UploadID, models.UploadStatusFailed,
+		UploadOutcome{ErrorCount: len(errors), Errors: errors}, progress.Status)
 	if err != nil {
-		log.Printf("Failed to mark upload %s as failed: %v", uploadID, err)
+		logf(ctx, "Failed to mark upload %s as failed: %v", progress.UploadID, err)
+	}
+}
+
+// outcome returns the counts and errors of a run, to record with the upload's status
+func (p *ProcessingProgress) outcome() UploadOutcome {
+	return UploadOutcome{
+		RecordCount:    p.TotalRows,
+		ProcessedCount: p.ProcessedRows,
+		ErrorCount:     p.ErrorCount,
+		Errors:         p.Errors,
+	}
+}
+
+// markProcessingCancelled records the partial progress of an upload whose context was cancelled
+// or timed out. The status write uses a detached context so it succeeds after cancellation.
+func (s *ProcessingService) markProcessingCancelled(ctx context.Context, progress *ProcessingProgress, stage string) (*ProcessingProgress, error) {
+	cause := ctx.Err()
+	message := fmt.Sprintf("Processing cancelled during %s after %d of %d rows: %v",
+		stage, progress.ProcessedRows, progress.TotalRows, cause)
+	progress.Errors = append(progress.Errors, message)
+	progress.ErrorCount = len(progress.Errors)
+
+	statusCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusUpdateTimeout)
+	defer cancel()
+
+	err := s.incidentService.TransitionUpload(statusCtx, progress.UploadID, models.UploadStatusFailed,
+		progress.outcome(), progress.Status)
+	if err != nil {
+		logf(ctx, "Failed to record cancellation for upload %s: %v", progress.UploadID, err)
+	}
+
+	endTime := time.Now()
+	progress.EndTime = &endTime
+	progress.Status = models.UploadStatusFailed
+	progress.Duration = endTime.Sub(progress.StartTime).String()
+	progress.Cancelled = true
+
+	logf(ctx, "Processing cancelled for upload %s during %s: %v", progress.UploadID, stage, cause)
+
+	s.recordProfile(statusCtx, progress)
+
+	return progress, fmt.Errorf("processing cancelled during %s: %w", stage, cause)
+}
+
+// recordProfile stores the stage timings of a finished run. Profiling failures are logged and
+// never fail the upload.
+func (s *ProcessingService) recordProfile(ctx context.Context, progress *ProcessingProgress) {
+	if s.profileService == nil {
+		return
+	}
+	if err := s.profileService.SaveProfile(ctx, NewUploadProfile(progress)); err != nil {
+		logf(ctx, "Warning: Failed to record processing profile for upload %s: %v", progress.UploadID, err)
+	}
+}
+
+// checkContinuity compares a newly stored upload with the upload before it. Failures are logged
+// and do not fail the upload.
+func (s *ProcessingService) checkContinuity(ctx context.Context, uploadID string) {
+	if s.continuityService == nil {
+		return
+	}
+	continuity, err := s.continuityService.CheckUpload(ctx, uploadID)
+	if err != nil {
+		logf(ctx, "Warning: Failed to check continuity of upload %s: %v", uploadID, err)
+		return
+	}
+	if continuity.Status == ContinuityDiscrepancies {
+		logf(ctx, "Warning: Upload %s disagrees with upload %s on %d day and application counts (alert %s)",
+			uploadID, continuity.PreviousUploadID, len(continuity.Discrepancies), continuity.AlertID)
+	}
+}
+
+// checkDistribution compares a processed upload's distribution with the prior period. Like the
+// continuity check, a failure only logs a warning: the upload's incidents are stored either way.
+func (s *ProcessingService) checkDistribution(ctx context.Context, uploadID string) {
+	if s.distributionCheck == nil {
+		return
+	}
+	check, err := s.distributionCheck.CheckUpload(ctx, uploadID)
+	if err != nil {
+		logf(ctx, "Warning: Failed to check distribution of upload %s: %v", uploadID, err)
+		return
+	}
+	if check.Status == DistributionShifted {
+		logf(ctx, "Warning: Upload %s shifted %d distribution metrics against %s to %s (alert %s)",
+			uploadID, check.FlaggedCount, check.PriorStart, check.PriorEnd, check.AlertID)
 	}
 }
 
@@ -221,13 +1019,15 @@ func (s *ProcessingService) markProcessingFailed(ctx context.Context, uploadID s
 func (s *ProcessingService) getUploadRecord(ctx context.Context, uploadID string) (*models.Upload, error) {
 	query := `
 		SELECT id, filename, original_filename, status, record_count, 
-			   processed_count, error_count, errors, created_at, processed_at
+			   processed_count, error_count, COALESCE(errors, '[]'), created_at, processed_at,
+			   COALESCE(processing_options, ''), COALESCE(dataset_id, ''), COALESCE(ingested_sheet, ''),
+			   COALESCE(cleanup, '')
 		FROM uploads 
 		WHERE id = ?
 	`
 
 	var upload models.Upload
-	var errorsJSON string
+	var errorsJSON, optionsJSON, sheetJSON, cleanupJSON string
 
 	err := s.db.QueryRowContext(ctx, query, uploadID).Scan(
 		&upload.ID,
@@ -240,6 +1040,10 @@ func (s *ProcessingService) getUploadRecord(ctx context.Context, uploadID string
 		&errorsJSON,
 		&upload.CreatedAt,
 		&upload.ProcessedAt,
+		&optionsJSON,
+		&upload.DatasetID,
+		&sheetJSON,
+		&cleanupJSON,
 	)
 
 	if err != nil {
@@ -248,45 +1052,96 @@ func (s *ProcessingService) getUploadRecord(ctx context.Context, uploadID string
 
 	// For now, initialize empty errors slice - in production, parse JSON
 	upload.Errors = []string{}
+	upload.ProcessingOptions = DecodeProcessingOptions(optionsJSON)
+	upload.IngestedSheet = DecodeIngestedSheet(sheetJSON)
+	upload.Cleanup = DecodeUploadCleanup(cleanupJSON)
 
 	return &upload, nil
 }
 
 // processIncidentsWithAnalysis processes incidents with sentiment and automation analysis
-func (s *ProcessingService) processIncidentsWithAnalysis(incidents []models.Incident) error {
-	log.Printf("Starting analysis processing for %d incidents", len(incidents))
+func (s *ProcessingService) processIncidentsWithAnalysis(ctx context.Context, incidents []models.Incident) error {
+	return s.analyzeIncidents(ctx, incidents, true, true)
+}
+
+// analyzeIncidents calculates resolution times and runs the enabled analyzers. Values read from
+// the spreadsheet are kept for a disabled analyzer.
+func (s *ProcessingService) analyzeIncidents(ctx context.Context, incidents []models.Incident, runSentiment, runAutomation bool) error {
+	logf(ctx, "Starting analysis processing for %d incidents", len(incidents))
+
+	sentimentVersion := analyzerVersion(s.sentimentAnalyzer)
+	automationVersion := analyzerVersion(s.automationAnalyzer)
 
 	for i := range incidents {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("analysis cancelled after %d of %d incidents: %w", i, len(incidents), err)
+		}
+
 		// Calculate resolution time if not already calculated
 		incidents[i].CalculateResolutionTime()
 
 		// Perform sentiment analysis
-		if s.sentimentAnalyzer != nil {
+		if s.sentimentAnalyzer != nil && runSentiment {
 			sentimentResult, err := s.sentimentAnalyzer.AnalyzeSentiment(
 				incidents[i].BriefDescription + " " + incidents[i].Description)
 			if err != nil {
-				log.Printf("Warning: Sentiment analysis failed for incident %s: %v",
+				logf(ctx, "Warning: Sentiment analysis failed for incident %s: %v",
 					incidents[i].IncidentID, err)
 			} else {
 				incidents[i].SentimentScore = &sentimentResult.Score
 				incidents[i].SentimentLabel = sentimentResult.Label
+				incidents[i].SentimentVersion = sentimentVersion
 			}
 		}
 
 		// Perform automation analysis
-		if s.automationAnalyzer != nil {
+		if s.automationAnalyzer != nil && runAutomation {
 			automationResult, err := s.automationAnalyzer.AnalyzeAutomation(&incidents[i])
 			if err != nil {
-				log.Printf("Warning: Automation analysis failed for incident %s: %v",
+				logf(ctx, "Warning: Automation analysis failed for incident %s: %v",
 					incidents[i].IncidentID, err)
 			} else {
 				incidents[i].AutomationScore = &automationResult.Score
 				incidents[i].AutomationFeasible = &automationResult.Feasible
 				incidents[i].ITProcessGroup = automationResult.ITProcessGroup
+				incidents[i].AutomationVersion = automationVersion
 			}
 		}
 	}
 
-	log.Printf("Completed analysis processing for %d incidents", len(incidents))
+	logf(ctx, "Completed analysis processing for %d incidents", len(incidents))
 	return nil
 }
+
+// analyzerVersion returns the rule version of an analyzer, or "" when it does not report one
+func analyzerVersion(analyzer interface{}) string {
+	if versioned, ok := analyzer.(VersionedAnalyzer); ok {
+		return versioned.Version()
+	}
+	return ""
+}
+
+// runShadowAnalysis runs the active shadow configuration over the processed incidents. Shadow
+// failures are logged and never fail the upload.
+func (s *ProcessingService) runShadowAnalysis(ctx context.Context, uploadID string, incidents []models.Incident) {
+	if s.shadowService == nil {
+		return
+	}
+	if s.flags != nil && !s.flags.Enabled(ctx, FlagShadowAnalysis) {
+		return
+	}
+
+	config, err := s.shadowService.ActiveConfig(ctx)
+	if err != nil {
+		logf(ctx, "Warning: Failed to load shadow config: %v", err)
+		return
+	}
+	if config == nil {
+		return
+	}
+
+	logf(ctx, "Running shadow config %q over %d incidents", config.Name, len(incidents))
+	if err := s.shadowService.RunShadow(ctx, config, uploadID, incidents); err != nil {
+		logf(ctx, "Warning: Shadow analysis failed for config %q: %v", config.Name, err)
+	}
+}
@@ -2,6 +2,10 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +14,7 @@ import (
 	"incident-management-system/internal/storage"
 
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/xuri/excelize/v2"
 )
 
 func TestProcessingService_NewProcessingService(t *testing.T) {
@@ -157,7 +162,8 @@ func TestProcessingService_markProcessingFailed(t *testing.T) {
 
 	// Test marking processing as failed (will fail due to missing schema)
 	errors := []string{"test error 1", "test error 2"}
-	service.markProcessingFailed(context.Background(), "upload-123", errors)
+	progress := &ProcessingProgress{UploadID: "upload-123", Status: models.UploadStatusProcessing}
+	service.markProcessingFailed(context.Background(), progress, errors)
 
 	// Should not panic - just log the error
 	t.Log("markProcessingFailed completed without panic")
@@ -250,7 +256,7 @@ func TestProcessingService_processIncidentsWithAnalysis(t *testing.T) {
 	}
 
 	// Test processing incidents with analysis
-	err = service.processIncidentsWithAnalysis(incidents)
+	err = service.processIncidentsWithAnalysis(context.Background(), incidents)
 	if err != nil {
 		t.Fatalf("Failed to process incidents with analysis: %v", err)
 	}
@@ -285,3 +291,753 @@ func TestProcessingService_processIncidentsWithAnalysis(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessingService_markProcessingCancelled(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	service := NewProcessingService(db, storage.NewFileStore("/tmp"))
+
+	_, err = db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+		"upload-123", "test.xlsx", "test.xlsx", models.UploadStatusProcessing)
+	if err != nil {
+		t.Fatalf("Failed to insert upload: %v", err)
+	}
+
+	// Simulate a client disconnect or shutdown midway through processing
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	progress := &ProcessingProgress{
+		UploadID:  "upload-123",
+		Status:    models.UploadStatusProcessing,
+		TotalRows: 10,
+		StartTime: time.Now(),
+		Errors:    []string{},
+	}
+
+	result, err := service.markProcessingCancelled(ctx, progress, "insertion")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	if !result.Cancelled || result.Status != models.UploadStatusFailed {
+		t.Errorf("Expected cancelled failed progress, got cancelled=%v status=%s", result.Cancelled, result.Status)
+	}
+
+	if result.EndTime == nil || result.ErrorCount != 1 {
+		t.Errorf("Expected end time and one error, got end=%v errors=%d", result.EndTime, result.ErrorCount)
+	}
+
+	// The status write must succeed even though the processing context is cancelled
+	var status string
+	var recordCount int
+	err = db.QueryRow("SELECT status, record_count FROM uploads WHERE id = ?", "upload-123").Scan(&status, &recordCount)
+	if err != nil {
+		t.Fatalf("Failed to read upload: %v", err)
+	}
+
+	if status != models.UploadStatusFailed || recordCount != 10 {
+		t.Errorf("Expected failed status with 10 rows, got %s with %d", status, recordCount)
+	}
+}
+
+func TestProcessingService_ProcessUploadCancelled(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	service := NewProcessingService(dbWrapper.GetConnection(), storage.NewFileStore("/tmp"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	incidents := []models.Incident{{ID: "incident-1", IncidentID: "INC001", Priority: "P1"}}
+
+	if err := service.processIncidentsWithAnalysis(ctx, incidents); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected analysis to stop with context.Canceled, got %v", err)
+	}
+
+	if _, err := service.incidentService.BatchInsertIncidents(ctx, incidents, "upload-123"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected batch insert to stop with context.Canceled, got %v", err)
+	}
+}
+
+// writeTestWorkbook writes rows to Sheet1 of a new workbook in dir
+func writeTestWorkbook(t *testing.T, dir, filename string, rows [][]string) {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			t.Fatalf("Failed to resolve cell name: %v", err)
+		}
+		if err := f.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatalf("Failed to write row %d: %v", i+1, err)
+		}
+	}
+	if err := f.SaveAs(filepath.Join(dir, filename)); err != nil {
+		t.Fatalf("Failed to save workbook: %v", err)
+	}
+}
+
+func TestProcessingService_ProcessUploadWithOptions(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	dir := t.TempDir()
+	service := NewProcessingService(db, storage.NewFileStore(dir))
+	ctx := context.Background()
+
+	if _, err := NewMappingProfileService(db).SaveProfile(ctx, "legacy", "", map[string][]string{
+		"incident_id": {"Ref"},
+		"report_date": {"Logged At"},
+	}, nil, nil); err != nil {
+		t.Fatalf("Failed to save mapping profile: %v", err)
+	}
+
+	writeTestWorkbook(t, dir, "legacy.xlsx", [][]string{
+		{"Ref", "Logged At", "Priority", "Status", "Summary", "Sentiment"},
+		{"INC001", "2024-03-01 09:00:00", "P2", "Closed", "Password reset failed again", "positive"},
+		{"INC002", "2024-03-02 10:30:00", "P3", "Closed", "Printer offline", "neutral"},
+		{"INC001", "2024-03-03 02:00:00", "P1", "Closed", "Password reset escalated", "negative"},
+		{"", "2024-03-04 12:00:00", "P4", "Open", "Missing reference", "neutral"},
+	})
+
+	createUpload := func(id string) {
+		t.Helper()
+		if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+			id, "legacy.xlsx", "legacy.xlsx", models.UploadStatusUploaded); err != nil {
+			t.Fatalf("Failed to create upload: %v", err)
+		}
+	}
+
+	options := models.ProcessingOptions{
+		MappingProfile: "legacy",
+		DedupStrategy:  models.DedupStrategyLast,
+		ErrorThreshold: 25,
+		RunSentiment:   false,
+		RunAutomation:  true,
+		Timezone:       "America/New_York",
+	}
+
+	t.Run("dry run stores nothing", func(t *testing.T) {
+		createUpload("upload-dry")
+		dryRun := options
+		dryRun.DryRun = true
+
+		progress, err := service.ProcessUploadWithOptions(ctx, "upload-dry", dryRun)
+		if err != nil {
+			t.Fatalf("Dry run failed: %v", err)
+		}
+		if progress.Status != models.UploadStatusUploaded || progress.ValidRows != 2 || progress.ProcessedRows != 0 {
+			t.Errorf("Expected uploaded status with 2 valid rows and none processed, got %+v", progress)
+		}
+
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM incidents WHERE upload_id = ?", "upload-dry").Scan(&count); err != nil {
+			t.Fatalf("Failed to count incidents: %v", err)
+		}
+		if count != 0 {
+			t.Errorf("Expected a dry run to store no incidents, got %d", count)
+		}
+
+		upload, err := service.getUploadRecord(ctx, "upload-dry")
+		if err != nil {
+			t.Fatalf("Failed to read upload: %v", err)
+		}
+		if upload.ProcessingOptions == nil || *upload.ProcessingOptions != dryRun {
+			t.Errorf("Expected recorded options %+v, got %+v", dryRun, upload.ProcessingOptions)
+		}
+	})
+
+	t.Run("profile, dedup, timezone and analyzer toggles", func(t *testing.T) {
+		createUpload("upload-real")
+
+		progress, err := service.ProcessUploadWithOptions(ctx, "upload-real", options)
+		if err != nil {
+			t.Fatalf("Processing failed: %v", err)
+		}
+		// One row without a reference and one duplicate are reported
+		if progress.Status != models.UploadStatusCompletedWithErrors || progress.ProcessedRows != 2 || progress.ErrorCount != 2 {
+			t.Errorf("Expected 2 processed rows and 2 errors, got %+v", progress)
+		}
+
+		incidents, err := service.incidentService.GetIncidentsByUpload(ctx, "upload-real")
+		if err != nil {
+			t.Fatalf("Failed to read incidents: %v", err)
+		}
+		byID := make(map[string]models.Incident)
+		for _, incident := range incidents {
+			byID[incident.IncidentID] = incident
+		}
+
+		duplicate := byID["INC001"]
+		if duplicate.Priority != "P1" {
+			t.Errorf("Expected the last INC001 row to be kept, got priority %s", duplicate.Priority)
+		}
+		// 02:00 UTC on 3 March is still 2 March in New York
+		if expected := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC); !duplicate.ReportDate.Equal(expected) {
+			t.Errorf("Expected report date %v, got %v", expected, duplicate.ReportDate)
+		}
+		if duplicate.SentimentLabel != models.SentimentNegative || duplicate.SentimentVersion != "" {
+			t.Errorf("Expected the spreadsheet sentiment to be kept, got %s (version %q)",
+				duplicate.SentimentLabel, duplicate.SentimentVersion)
+		}
+		if duplicate.AutomationVersion == "" {
+			t.Error("Expected automation analysis to run")
+		}
+	})
+
+	t.Run("missing priorities inferred", func(t *testing.T) {
+		writeTestWorkbook(t, dir, "unprioritized.xlsx", [][]string{
+			{"Ref", "Logged At", "Priority", "Status", "Summary"},
+			{"INC010", "2024-03-05 09:00:00", "", "Closed", "Payroll outage for all users"},
+			{"INC011", "2024-03-05 10:00:00", "P4", "Closed", "Payroll outage for all users"},
+		})
+		if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+			"upload-inferred", "unprioritized.xlsx", "unprioritized.xlsx", models.UploadStatusUploaded); err != nil {
+			t.Fatalf("Failed to create upload: %v", err)
+		}
+		inferring := options
+		inferring.InferPriority = true
+
+		if _, err := service.ProcessUploadWithOptions(ctx, "upload-inferred", inferring); err != nil {
+			t.Fatalf("Processing failed: %v", err)
+		}
+		incidents, err := service.incidentService.GetIncidentsByUpload(ctx, "upload-inferred")
+		if err != nil {
+			t.Fatalf("Failed to read incidents: %v", err)
+		}
+		for _, incident := range incidents {
+			switch incident.IncidentID {
+			case "INC010":
+				if incident.Priority != models.PriorityP1 || !incident.PriorityInferred {
+					t.Errorf("Expected an inferred P1, got %s (inferred %v)", incident.Priority, incident.PriorityInferred)
+				}
+			case "INC011":
+				if incident.Priority != models.PriorityP4 || incident.PriorityInferred {
+					t.Errorf("Expected the recorded P4 to be kept, got %s (inferred %v)", incident.Priority, incident.PriorityInferred)
+				}
+			}
+		}
+	})
+
+	t.Run("error threshold exceeded", func(t *testing.T) {
+		createUpload("upload-strict")
+		strict := options
+		strict.ErrorThreshold = 10
+
+		if _, err := service.ProcessUploadWithOptions(ctx, "upload-strict", strict); err == nil {
+			t.Fatal("Expected processing to fail above the error threshold")
+		}
+
+		upload, err := service.getUploadRecord(ctx, "upload-strict")
+		if err != nil {
+			t.Fatalf("Failed to read upload: %v", err)
+		}
+		if upload.Status != models.UploadStatusFailed {
+			t.Errorf("Expected failed status, got %s", upload.Status)
+		}
+	})
+
+	t.Run("duplicate rejected by fail strategy", func(t *testing.T) {
+		createUpload("upload-fail")
+		failOnDuplicate := options
+		failOnDuplicate.DedupStrategy = models.DedupStrategyFail
+
+		if _, err := service.ProcessUploadWithOptions(ctx, "upload-fail", failOnDuplicate); err == nil {
+			t.Fatal("Expected processing to fail on a duplicate incident ID")
+		}
+	})
+}
+
+func TestProcessingService_ProcessDataset(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	dir := t.TempDir()
+	service := NewProcessingService(db, storage.NewFileStore(dir))
+	datasets := NewDatasetService(db)
+	ctx := context.Background()
+
+	header := []string{"Incident ID", "Report Date", "Priority", "Status", "Summary"}
+	writeTestWorkbook(t, dir, "emea.xlsx", [][]string{
+		header,
+		{"INC001", "2024-03-01", "P2", "Closed", "VPN drops"},
+		{"INC002", "2024-03-02", "P3", "Closed", "Printer offline"},
+	})
+	writeTestWorkbook(t, dir, "apac.xlsx", [][]string{
+		header,
+		{"INC001", "2024-03-01", "P1", "Closed", "VPN drops, escalated"},
+		{"INC003", "2024-03-03", "P3", "Open", "Mailbox full"},
+	})
+
+	createDataset := func(name string) string {
+		t.Helper()
+		dataset, err := datasets.CreateDataset(ctx, name, "")
+		if err != nil {
+			t.Fatalf("Failed to create dataset: %v", err)
+		}
+		for i, filename := range []string{"emea.xlsx", "apac.xlsx"} {
+			if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status, created_at, dataset_id)
+				VALUES (?, ?, ?, ?, ?, ?)`, name+"-"+filename, filename, filename, models.UploadStatusUploaded,
+				time.Date(2024, 3, 5, 9, i, 0, 0, time.UTC), dataset.ID); err != nil {
+				t.Fatalf("Failed to create upload: %v", err)
+			}
+		}
+		return dataset.ID
+	}
+
+	options := models.DefaultProcessingOptions()
+
+	t.Run("dry run stores nothing", func(t *testing.T) {
+		datasetID := createDataset("dry")
+		dryRun := options
+		dryRun.DryRun = true
+
+		results, err := service.ProcessDataset(ctx, datasetID, dryRun)
+		if err != nil {
+			t.Fatalf("Dry run failed: %v", err)
+		}
+		if len(results) != 2 || results[0].ValidRows != 2 || results[1].ValidRows != 1 {
+			t.Fatalf("Expected 2 and 1 valid rows across the dataset, got %+v", results)
+		}
+
+		dataset, err := datasets.GetDataset(ctx, datasetID)
+		if err != nil {
+			t.Fatalf("Failed to read dataset: %v", err)
+		}
+		if dataset.IncidentCount != 0 || dataset.UploadStatus[models.UploadStatusUploaded] != 2 {
+			t.Errorf("Expected no incidents and both uploads still pending, got %+v", dataset)
+		}
+	})
+
+	t.Run("cross-file dedup and dataset tagging", func(t *testing.T) {
+		datasetID := createDataset("real")
+
+		results, err := service.ProcessDataset(ctx, datasetID, options)
+		if err != nil {
+			t.Fatalf("Processing failed: %v", err)
+		}
+		// The second file's INC001 repeats the first file's and is reported against the second upload
+		if len(results) != 2 || results[0].ProcessedRows != 2 || results[1].ProcessedRows != 1 || results[1].ErrorCount != 1 {
+			t.Fatalf("Expected 2 and 1 processed rows with the duplicate on the second upload, got %+v", results)
+		}
+
+		if _, err := service.ProcessDataset(ctx, datasetID, options); err != ErrNoPendingUploads {
+			t.Errorf("Expected ErrNoPendingUploads on reprocessing, got %v", err)
+		}
+
+		dataset, err := datasets.GetDataset(ctx, datasetID)
+		if err != nil {
+			t.Fatalf("Failed to read dataset: %v", err)
+		}
+		if dataset.IncidentCount != 3 || dataset.UploadStatus[models.UploadStatusCompleted] != 1 ||
+			dataset.UploadStatus[models.UploadStatusCompletedWithErrors] != 1 {
+			t.Errorf("Expected 3 incidents, one completed upload and one with the rejected duplicate, got %+v", dataset)
+		}
+
+		analysis, err := NewAnalyticsService(db).GetPriorityAnalysis(ctx, &TimelineFilters{DatasetID: datasetID})
+		if err != nil {
+			t.Fatalf("Failed to query analytics: %v", err)
+		}
+		counts := make(map[string]int)
+		for _, priority := range analysis {
+			counts[priority.Priority] = priority.Count
+		}
+		if counts["P2"] != 1 || counts["P3"] != 2 || counts["P1"] != 0 {
+			t.Errorf("Expected the first INC001 row to be kept, got %v", counts)
+		}
+	})
+}
+
+// blockingGate holds processing runs until their context ends
+type blockingGate struct{}
+
+func (blockingGate) Wait(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestProcessingService_GateCancelled(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	service := NewProcessingService(db, storage.NewFileStore(t.TempDir()))
+	service.SetGate(blockingGate{})
+
+	_, err = db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+		"upload-123", "test.xlsx", "test.xlsx", models.UploadStatusUploaded)
+	if err != nil {
+		t.Fatalf("Failed to insert upload: %v", err)
+	}
+
+	// A run held back by the gate past its deadline is recorded as cancelled before parsing
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	progress, err := service.ProcessUpload(ctx, "upload-123")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the deadline to end the wait, got %v", err)
+	}
+	if !progress.Cancelled || !strings.Contains(progress.Errors[0], "waiting to start") {
+		t.Errorf("Expected a cancellation while waiting to start, got %+v", progress)
+	}
+
+	var status string
+	if err := db.QueryRow("SELECT status FROM uploads WHERE id = ?", "upload-123").Scan(&status); err != nil {
+		t.Fatalf("Failed to read upload: %v", err)
+	}
+	if status != models.UploadStatusFailed {
+		t.Errorf("Expected failed status, got %s", status)
+	}
+}
+
+func TestProcessingService_DateQuality(t *testing.T) {
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	dir := t.TempDir()
+	service := NewProcessingService(db, storage.NewFileStore(dir))
+	ctx := context.Background()
+
+	if _, err := NewMappingProfileService(db).SaveProfile(ctx, "eu", "", map[string][]string{
+		"incident_id": {"Ref"},
+	}, map[string]string{"report_date": "DD/MM/YYYY"}, nil); err != nil {
+		t.Fatalf("Failed to save mapping profile: %v", err)
+	}
+	if _, err := NewMappingProfileService(db).SaveProfile(ctx, "bad", "", map[string][]string{
+		"incident_id": {"Ref"},
+	}, map[string]string{"priority": "DD/MM/YYYY"}, nil); !errors.Is(err, ErrInvalidMappingProfile) {
+		t.Errorf("Expected a date format for a non-date field to be rejected, got %v", err)
+	}
+
+	writeTestWorkbook(t, dir, "eu.xlsx", [][]string{
+		{"Ref", "Report Date", "Resolve Date", "Priority"},
+		{"INC001", "02/03/2024", "04/03/2024", "P2"},
+		{"INC002", "05/03/2024", "not resolved", "P3"},
+	})
+	if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+		"upload-eu", "eu.xlsx", "eu.xlsx", models.UploadStatusUploaded); err != nil {
+		t.Fatalf("Failed to create upload: %v", err)
+	}
+
+	options := models.DefaultProcessingOptions()
+	options.MappingProfile = "eu"
+	if _, err := service.ProcessUploadWithOptions(ctx, "upload-eu", options); err != nil {
+		t.Fatalf("Processing failed: %v", err)
+	}
+
+	incidents, err := service.incidentService.GetIncidentsByUpload(ctx, "upload-eu")
+	if err != nil {
+		t.Fatalf("Failed to read incidents: %v", err)
+	}
+	for _, incident := range incidents {
+		if incident.IncidentID == "INC001" && !incident.ReportDate.Equal(time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected the profile format to read 02/03/2024 as 2 March, got %v", incident.ReportDate)
+		}
+	}
+
+	report, err := NewUploadQualityService(db).GetReport(ctx, "upload-eu")
+	if err != nil {
+		t.Fatalf("Failed to get quality report: %v", err)
+	}
+	if report.IngestedSheet == nil || report.IngestedSheet.Range != "A1:D3" {
+		t.Errorf("Expected the ingested range A1:D3, got %+v", report.IngestedSheet)
+	}
+	reportDates, resolveDates := report.Dates["report_date"], report.Dates["resolve_date"]
+	if reportDates == nil || reportDates.Format != "DD/MM/YYYY" || reportDates.Parsed != 2 {
+		t.Errorf("Expected 2 report dates read with the profile format, got %+v", reportDates)
+	}
+	if resolveDates == nil || resolveDates.Ambiguous != 1 || resolveDates.Rejected != 1 {
+		t.Errorf("Expected 1 ambiguous and 1 rejected resolve date, got %+v", resolveDates)
+	}
+	if len(report.Warnings) != 2 {
+		t.Errorf("Expected ambiguity and rejection warnings, got %v", report.Warnings)
+	}
+
+	if _, err := NewUploadQualityService(db).GetReport(ctx, "missing"); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for a missing upload, got %v", err)
+	}
+}
+
+func TestProcessingService_PendingTime(t *testing.T) {
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	dir := t.TempDir()
+	service := NewProcessingService(db, storage.NewFileStore(dir))
+	ctx := context.Background()
+
+	if _, err := NewMappingProfileService(db).SaveProfile(ctx, "held", "", map[string][]string{
+		"pending_hours":  {"Customer Wait (h)"},
+		"status_history": {"Audit Trail"},
+	}, nil, []string{" On Hold ", "on-hold"}); err != nil {
+		t.Fatalf("Failed to save mapping profile: %v", err)
+	}
+	profile, err := NewMappingProfileService(db).GetProfile(ctx, "held")
+	if err != nil {
+		t.Fatalf("Failed to read mapping profile: %v", err)
+	}
+	if len(profile.PendingStatuses) != 1 || profile.PendingStatuses[0] != "On Hold" {
+		t.Errorf("Expected the pending statuses to be trimmed and deduplicated, got %v", profile.PendingStatuses)
+	}
+
+	writeTestWorkbook(t, dir, "held.xlsx", [][]string{
+		{"Incident ID", "Report Date", "Resolve Date", "Priority", "Customer Wait (h)", "Audit Trail"},
+		{"INC001", "2024-03-01 09:00", "2024-03-01 21:00", "P2", "5.5", ""},
+		{"INC002", "2024-03-01 09:00", "2024-03-01 19:00", "P2", "",
+			"2024-03-01 10:00 Pending Customer; 2024-03-01 11:00 On Hold; 2024-03-01 15:00 In Progress"},
+		{"INC003", "2024-03-01 09:00", "2024-03-01 12:00", "P2", "", ""},
+	})
+	if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+		"upload-held", "held.xlsx", "held.xlsx", models.UploadStatusUploaded); err != nil {
+		t.Fatalf("Failed to create upload: %v", err)
+	}
+
+	options := models.DefaultProcessingOptions()
+	options.MappingProfile = "held"
+	if _, err := service.ProcessUploadWithOptions(ctx, "upload-held", options); err != nil {
+		t.Fatalf("Processing failed: %v", err)
+	}
+
+	incidents, err := service.incidentService.GetIncidentsByUpload(ctx, "upload-held")
+	if err != nil {
+		t.Fatalf("Failed to read incidents: %v", err)
+	}
+	// Pending Customer is not pending for this profile, so INC002 pauses only while On Hold
+	expected := map[string][2]int{"INC001": {12, 6}, "INC002": {10, 6}, "INC003": {3, 3}}
+	for _, incident := range incidents {
+		hours, ok := expected[incident.IncidentID]
+		if !ok {
+			continue
+		}
+		if incident.ResolutionTimeHours == nil || incident.NetResolutionTimeHours == nil {
+			t.Errorf("Expected gross and net resolution hours for %s", incident.IncidentID)
+			continue
+		}
+		if *incident.ResolutionTimeHours != hours[0] || *incident.NetResolutionTimeHours != hours[1] {
+			t.Errorf("Expected %s to take %d gross and %d net hours, got %d and %d", incident.IncidentID,
+				hours[0], hours[1], *incident.ResolutionTimeHours, *incident.NetResolutionTimeHours)
+		}
+	}
+
+	metrics, err := NewAnalyticsService(db).GetResolutionAnalysis(ctx, &TimelineFilters{})
+	if err != nil {
+		t.Fatalf("Failed to get resolution analysis: %v", err)
+	}
+	if metrics.AvgResolutionTime != 5 {
+		t.Errorf("Expected the average resolution time to use net hours, got %.2f", metrics.AvgResolutionTime)
+	}
+}
+
+func TestProcessingService_UploadCleanup(t *testing.T) {
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	dir := t.TempDir()
+	service := NewProcessingService(db, storage.NewFileStore(dir))
+	ctx := context.Background()
+
+	writeTestWorkbook(t, dir, "retry.xlsx", [][]string{
+		{"Incident ID", "Report Date", "Resolve Date", "Priority"},
+		{"INC001", "2024-03-01 09:00", "2024-03-01 12:00", "P2"},
+		{"INC002", "2024-03-02 09:00", "", "P3"},
+	})
+	createUpload := func(id string) {
+		t.Helper()
+		if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+			id, "retry.xlsx", "retry.xlsx", models.UploadStatusUploaded); err != nil {
+			t.Fatalf("Failed to create upload: %v", err)
+		}
+	}
+	storeLeftovers := func(uploadID string, incidentIDs ...string) {
+		t.Helper()
+		var incidents []models.Incident
+		for _, incidentID := range incidentIDs {
+			incidents = append(incidents, models.Incident{
+				IncidentID:       incidentID,
+				ReportDate:       time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+				BriefDescription: "Left behind",
+				ApplicationName:  "Portal",
+				ResolutionGroup:  "Web Team",
+				ResolvedPerson:   "Alice",
+				Priority:         "P4",
+			})
+		}
+		if _, err := service.incidentService.BatchInsertIncidents(ctx, incidents, uploadID); err != nil {
+			t.Fatalf("Failed to store leftover incidents: %v", err)
+		}
+	}
+
+	t.Run("retry sweeps leftover rows", func(t *testing.T) {
+		createUpload("upload-retry")
+		storeLeftovers("upload-retry", "INC900", "INC901")
+
+		progress, err := service.ProcessUpload(ctx, "upload-retry")
+		if err != nil {
+			t.Fatalf("Processing failed: %v", err)
+		}
+		if progress.Cleanup == nil || progress.Cleanup.Reason != models.CleanupReasonLeftoverRows ||
+			progress.Cleanup.Status != models.CleanupStatusCompleted || progress.Cleanup.DeletedRows != 2 {
+			t.Errorf("Expected 2 leftover rows swept, got %+v", progress.Cleanup)
+		}
+		if progress.ProcessedRows != 2 {
+			t.Errorf("Expected the retry to store 2 incidents, got %d", progress.ProcessedRows)
+		}
+		if count, _ := service.incidentService.GetIncidentCount(ctx, "upload-retry"); count != 2 {
+			t.Errorf("Expected only the retried incidents to remain, got %d", count)
+		}
+
+		status, err := service.GetProcessingStatus(ctx, "upload-retry")
+		if err != nil {
+			t.Fatalf("Failed to get processing status: %v", err)
+		}
+		if status.Cleanup == nil || status.Cleanup.DeletedRows != 2 {
+			t.Errorf("Expected the status to report the sweep, got %+v", status.Cleanup)
+		}
+	})
+
+	t.Run("clean run clears stale cleanup", func(t *testing.T) {
+		createUpload("upload-clean")
+		stale := &models.UploadCleanup{Reason: models.CleanupReasonInsertFailed, Status: models.CleanupStatusCompleted, DeletedRows: 5}
+		if err := service.incidentService.SetUploadCleanup(ctx, "upload-clean", stale); err != nil {
+			t.Fatalf("Failed to record cleanup: %v", err)
+		}
+
+		if _, err := service.ProcessUpload(ctx, "upload-clean"); err != nil {
+			t.Fatalf("Processing failed: %v", err)
+		}
+		status, err := service.GetProcessingStatus(ctx, "upload-clean")
+		if err != nil {
+			t.Fatalf("Failed to get processing status: %v", err)
+		}
+		if status.Cleanup != nil {
+			t.Errorf("Expected no cleanup once nothing was left behind, got %+v", status.Cleanup)
+		}
+	})
+
+	t.Run("failed insert is compensated", func(t *testing.T) {
+		createUpload("upload-failed")
+		storeLeftovers("upload-failed", "INC100", "INC101", "INC102")
+		progress := &ProcessingProgress{UploadID: "upload-failed", Status: models.UploadStatusProcessing}
+
+		service.cleanupPartialInsert(ctx, progress, &BatchInsertResult{InsertedCount: 3}, errors.New("disk full"))
+		if progress.Cleanup == nil || progress.Cleanup.Reason != models.CleanupReasonInsertFailed || progress.Cleanup.DeletedRows != 3 {
+			t.Errorf("Expected 3 partial rows cleaned up after the failed insert, got %+v", progress.Cleanup)
+		}
+		if progress.ProcessedRows != 0 {
+			t.Errorf("Expected no rows processed once cleaned up, got %d", progress.ProcessedRows)
+		}
+		if count, _ := service.incidentService.GetIncidentCount(ctx, "upload-failed"); count != 0 {
+			t.Errorf("Expected no partial rows to remain, got %d", count)
+		}
+	})
+
+	t.Run("cancelled insert is compensated", func(t *testing.T) {
+		createUpload("upload-cancelled")
+		storeLeftovers("upload-cancelled", "INC200")
+		if _, err := db.Exec("UPDATE uploads SET status = ? WHERE id = ?", models.UploadStatusProcessing, "upload-cancelled"); err != nil {
+			t.Fatalf("Failed to start upload: %v", err)
+		}
+		progress := &ProcessingProgress{UploadID: "upload-cancelled", Status: models.UploadStatusProcessing}
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		service.cleanupPartialInsert(cancelled, progress, &BatchInsertResult{InsertedCount: 1}, cancelled.Err())
+		if _, err := service.markProcessingCancelled(cancelled, progress, "insertion"); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the run to end cancelled, got %v", err)
+		}
+
+		status, err := service.GetProcessingStatus(ctx, "upload-cancelled")
+		if err != nil {
+			t.Fatalf("Failed to get processing status: %v", err)
+		}
+		if status.Status != models.UploadStatusFailed || status.Cleanup == nil ||
+			status.Cleanup.Reason != models.CleanupReasonCancelled || status.Cleanup.DeletedRows != 1 {
+			t.Errorf("Expected a failed upload reporting the cancelled cleanup, got %+v", status)
+		}
+		if count, _ := service.incidentService.GetIncidentCount(ctx, "upload-cancelled"); count != 0 {
+			t.Errorf("Expected no partial rows to remain, got %d", count)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"incident-management-system/internal/models"
+)
+
+// RecategorizeBatchSize is how many incidents a re-categorization reads and updates at a time
+const RecategorizeBatchSize = 500
+
+// ITProcessGroupChange counts the incidents moved from one IT process group to another
+type ITProcessGroupChange struct {
+	From  string `json:"from"` // empty for incidents that had no group
+	To    string `json:"to"`
+	Count int    `json:"count"`
+}
+
+// RecategorizeResult reports a re-categorization of historical incidents
+type RecategorizeResult struct {
+	DryRun   bool                   `json:"dry_run"`
+	Scanned  int                    `json:"scanned"`
+	Changed  int                    `json:"changed"`
+	Changes  []ITProcessGroupChange `json:"changes"`
+	Duration string                 `json:"duration"`
+}
+
+// ITProcessRecategorizer re-runs the IT process categorization over stored incidents, so
+// incidents analyzed under an older configuration are grouped the way new uploads are
+type ITProcessRecategorizer struct {
+	db *sql.DB
+}
+
+// NewITProcessRecategorizer creates a new IT process recategorizer
+func NewITProcessRecategorizer(db *sql.DB) *ITProcessRecategorizer {
+	return &ITProcessRecategorizer{db: db}
+}
+
+// Recategorize assigns the incidents matching filters the IT process group the current automation
+// analyzer gives them, in batches of RecategorizeBatchSize. Each batch is stored in
+// one transaction with an analyzed event for every moved incident, so a cancelled run keeps the
+// batches already done. A dry run only counts the changes. progress, when set, is called after
+// each batch with the incidents scanned so far and the total.
+func (r *ITProcessRecategorizer) Recategorize(ctx context.Context, filters *TimelineFilters, dryRun bool, progress func(scanned, total int)) (*RecategorizeResult, error) {
+	start := time.Now()
+	analyzer := NewSimpleAutomationAnalyzer()
+
+	whereClause, args, argIndex := buildFilterConditions(ctx, filters, 1)
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM incidents WHERE 1=1"+whereClause, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count incidents to recategorize: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, brief_description, COALESCE(description, ''), application_name, resolution_group,
+			COALESCE(category, ''), COALESCE(subcategory, ''), COALESCE(it_process_group, '')
+		FROM incidents
+		WHERE id > $%d%s
+		ORDER BY id
+		LIMIT %d`, argIndex, whereClause, RecategorizeBatchSize)
+
+	result := &RecategorizeResult{DryRun: dryRun, Changes: []ITProcessGroupChange{}}
+	changes := map[[2]string]int{}
+	lastID := ""
+	for {
+		batch, err := r.loadBatch(ctx, query, append(args, lastID))
+		if err != nil {
+			return result, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		lastID = batch[len(batch)-1].ID
+
+		var moved []recategorizedIncident
+		for i := range batch {
+			group := analyzer.categorizeITProcess(&batch[i])
+			if group != batch[i].ITProcessGroup {
+				moved = append(moved, recategorizedIncident{id: batch[i].ID, from: batch[i].ITProcessGroup, to: group})
+			}
+		}
+		if !dryRun && len(moved) > 0 {
+			if err := r.store(ctx, moved); err != nil {
+				return result, err
+			}
+		}
+
+		result.Scanned += len(batch)
+		result.Changed += len(moved)
+		for _, change := range moved {
+			changes[[2]string{change.from, change.to}]++
+		}
+		if progress != nil {
+			progress(result.Scanned, total)
+		}
+		if len(batch) < RecategorizeBatchSize {
+			break
+		}
+	}
+
+	for key, count := range changes {
+		result.Changes = append(result.Changes, ITProcessGroupChange{From: key[0], To: key[1], Count: count})
+	}
+	sort.Slice(result.Changes, func(i, j int) bool {
+		a, b := result.Changes[i], result.Changes[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	result.Duration = time.Since(start).String()
+	return result, nil
+}
+
+// recategorizedIncident is an incident whose IT process group changed
+type recategorizedIncident struct {
+	id, from, to string
+}
+
+// loadBatch reads the next batch of incidents to recategorize, with only the fields the
+// categorization reads and their current group
+func (r *ITProcessRecategorizer) loadBatch(ctx context.Context, query string, args []interface{}) ([]models.Incident, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incidents to recategorize: %w", err)
+	}
+	defer rows.Close()
+
+	var batch []models.Incident
+	for rows.Next() {
+		var incident models.Incident
+		if err := rows.Scan(&incident.ID, &incident.BriefDescription, &incident.Description, &incident.ApplicationName,
+			&incident.ResolutionGroup, &incident.Category, &incident.Subcategory, &incident.ITProcessGroup); err != nil {
+			return nil, fmt.Errorf("failed to scan incident to recategorize: %w", err)
+		}
+		batch = append(batch, incident)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating incidents to recategorize: %w", err)
+	}
+	return batch, nil
+}
+
+// store saves the new groups of a batch and records an analyzed event for each moved incident
+func (r *ITProcessRecategorizer) store(ctx context.Context, moved []recategorizedIncident) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	events := make([]IncidentEvent, 0, len(moved))
+	for _, change := range moved {
+		if _, err := tx.ExecContext(ctx, "UPDATE incidents SET it_process_group = ?, updated_at = ? WHERE id = ?",
+			change.to, now, change.id); err != nil {
+			return fmt.Errorf("failed to recategorize incident %s: %w", change.id, err)
+		}
+
+		from := change.from
+		if from == "" {
+			from = "no group"
+		}
+		events = append(events, IncidentEvent{
+			IncidentID: change.id,
+			EventType:  EventAnalyzed,
+			OccurredAt: now,
+			Source:     EventSourceRecategorization,
+			Summary:    fmt.Sprintf("Re-categorized from %s to %s", from, change.to),
+			Details: map[string]interface{}{
+				"it_process_group":          change.to,
+				"previous_it_process_group": change.from,
+			},
+		})
+	}
+	if err := insertIncidentEvents(ctx, tx, events); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit recategorized incidents: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"incident-management-system/internal/models"
+)
+
+func TestITProcessRecategorizer_Recategorize(t *testing.T) {
+	current := diffTestIncident("1", "upload-1", "INC-1", "P3", "Closed")
+	current.ITProcessGroup = NewSimpleAutomationAnalyzer().categorizeITProcess(&current)
+	stale := diffTestIncident("2", "upload-1", "INC-2", "P3", "Closed")
+	stale.ITProcessGroup = "Legacy Group"
+	ungrouped := diffTestIncident("3", "upload-1", "INC-3", "P3", "Closed")
+	filteredOut := diffTestIncident("4", "upload-1", "INC-4", "P1", "Closed")
+	filteredOut.ITProcessGroup = "Legacy Group"
+
+	dbWrapper, db := newSentimentTestDB(t, []models.Incident{current, stale, ungrouped, filteredOut})
+	defer dbWrapper.Close()
+	ctx := context.Background()
+	filters := &TimelineFilters{Priorities: []string{"P3"}}
+	recategorizer := NewITProcessRecategorizer(db)
+
+	groupOf := func(id string) string {
+		var group string
+		if err := db.QueryRowContext(ctx, "SELECT COALESCE(it_process_group, '') FROM incidents WHERE id = ?", id).Scan(&group); err != nil {
+			t.Fatalf("failed to read group of %s: %v", id, err)
+		}
+		return group
+	}
+
+	dryRun, err := recategorizer.Recategorize(ctx, filters, true, nil)
+	if err != nil {
+		t.Fatalf("Recategorize() dry run error = %v", err)
+	}
+	if dryRun.Scanned != 3 || dryRun.Changed != 2 || groupOf("2") != "Legacy Group" {
+		t.Fatalf("unexpected dry run: %+v", dryRun)
+	}
+
+	var calls int
+	result, err := recategorizer.Recategorize(ctx, filters, false, func(scanned, total int) {
+		calls++
+		if scanned != 3 || total != 3 {
+			t.Errorf("progress(%d, %d), want (3, 3)", scanned, total)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Recategorize() error = %v", err)
+	}
+	if result.Changed != 2 || calls != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	want := map[ITProcessGroupChange]bool{
+		{From: "Legacy Group", To: current.ITProcessGroup, Count: 1}: true,
+		{From: "", To: current.ITProcessGroup, Count: 1}:             true,
+	}
+	for _, change := range result.Changes {
+		if !want[change] {
+			t.Errorf("unexpected change %+v", change)
+		}
+	}
+	if groupOf("2") != current.ITProcessGroup || groupOf("3") != current.ITProcessGroup {
+		t.Errorf("expected the stale and ungrouped incidents moved to %s", current.ITProcessGroup)
+	}
+	if groupOf("4") != "Legacy Group" {
+		t.Errorf("expected the incident outside the filters left alone")
+	}
+
+	var events int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM incident_events WHERE source = ?", EventSourceRecategorization).Scan(&events); err != nil {
+		t.Fatalf("failed to count events: %v", err)
+	}
+	if events != 2 {
+		t.Errorf("expected an event per moved incident, got %d", events)
+	}
+
+	again, err := recategorizer.Recategorize(ctx, filters, false, nil)
+	if err != nil {
+		t.Fatalf("Recategorize() error = %v", err)
+	}
+	if again.Changed != 0 || len(again.Changes) != 0 {
+		t.Errorf("expected nothing left to move, got %+v", again)
+	}
+}
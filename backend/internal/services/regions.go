@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// UnspecifiedRegion groups the incidents that record no region
+const UnspecifiedRegion = "Unspecified"
+
+// RegionAnalysis is the volume, priority mix and resolution performance of one region
+type RegionAnalysis struct {
+	Region        string  `json:"region"`
+	IncidentCount int     `json:"incident_count"`
+	SharePercent  float64 `json:"share_percent"`
+	// PriorityMix counts the region's incidents of each priority
+	PriorityMix          map[string]int `json:"priority_mix"`
+	ResolvedIncidents    int            `json:"resolved_incidents"`
+	ResolutionRate       float64        `json:"resolution_rate"`
+	AvgResolutionTime    float64        `json:"avg_resolution_time"`
+	MedianResolutionTime float64        `json:"median_resolution_time"`
+	// Percentiles holds the requested resolution time percentiles, keyed like p95
+	Percentiles map[string]float64 `json:"percentiles,omitempty"`
+}
+
+// GetRegionAnalysis returns the incidents of each region, busiest first. Incidents without a
+// region are grouped under UnspecifiedRegion; SharePercent is taken of every incident matching
+// the filters, limited or not.
+func (s *AnalyticsService) GetRegionAnalysis(ctx context.Context, filters *TimelineFilters) ([]RegionAnalysis, error) {
+	percentiles := filters.percentiles()
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query := fmt.Sprintf(`
+		SELECT
+			COALESCE(NULLIF(TRIM(region), ''), '%[3]s') as region_name,
+			COUNT(*) as incident_count,
+			SUM(COUNT(*)) OVER () as total_incidents,
+			COUNT(CASE WHEN priority = 'P1' THEN 1 END) as p1_count,
+			COUNT(CASE WHEN priority = 'P2' THEN 1 END) as p2_count,
+			COUNT(CASE WHEN priority = 'P3' THEN 1 END) as p3_count,
+			COUNT(CASE WHEN priority = 'P4' THEN 1 END) as p4_count,
+			COUNT(CASE WHEN resolve_date IS NOT NULL THEN 1 END) as resolved_incidents,
+			AVG(%[1]s) as avg_resolution_time,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY %[1]s) as median_resolution_time%[2]s
+		FROM incidents
+		WHERE 1=1%[4]s
+		GROUP BY 1
+		ORDER BY incident_count DESC, region_name`,
+		resolutionHoursExpr, percentileColumns(resolutionHoursExpr, percentiles, ""), UnspecifiedRegion, whereClause)
+	query += filters.limitClause()
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query region analysis: %w", err)
+	}
+	defer rows.Close()
+
+	regions := []RegionAnalysis{}
+	for rows.Next() {
+		var region RegionAnalysis
+		var total float64
+		var p1, p2, p3, p4 int
+		var avgResolutionTime, medianResolutionTime sql.NullFloat64
+		values := newPercentileValues(percentiles)
+
+		dest := []interface{}{
+			&region.Region, &region.IncidentCount, &total, &p1, &p2, &p3, &p4,
+			&region.ResolvedIncidents, &avgResolutionTime, &medianResolutionTime,
+		}
+		if err := rows.Scan(append(dest, values.dest()...)...); err != nil {
+			return nil, fmt.Errorf("failed to scan region analysis row: %w", err)
+		}
+
+		region.PriorityMix = map[string]int{"P1": p1, "P2": p2, "P3": p3, "P4": p4}
+		if total > 0 {
+			region.SharePercent = roundTo(float64(region.IncidentCount)/total*100, 1)
+		}
+		if region.IncidentCount > 0 {
+			region.ResolutionRate = roundTo(float64(region.ResolvedIncidents)/float64(region.IncidentCount)*100, 1)
+		}
+		region.AvgResolutionTime = avgResolutionTime.Float64
+		region.MedianResolutionTime = medianResolutionTime.Float64
+		region.Percentiles = values.result()
+		regions = append(regions, region)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating region analysis rows: %w", err)
+	}
+	return regions, nil
+}
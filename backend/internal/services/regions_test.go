@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestAnalyticsService_GetRegionAnalysis(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	analyticsService := NewAnalyticsService(db)
+	ctx := context.Background()
+
+	regions := []struct {
+		region, priority string
+		resolved         bool
+	}{
+		{"EMEA", "P1", true},
+		{"EMEA", "P3", true},
+		{"EMEA", "P3", false},
+		{"APAC", "P2", true},
+		{"", "P4", false},
+	}
+	var incidents []models.Incident
+	for i, r := range regions {
+		incident := diffTestIncident(fmt.Sprintf("i%d", i), "upload-1", fmt.Sprintf("INC%03d", i), r.priority, "Open")
+		incident.Region = r.region
+		if r.resolved {
+			resolved := incident.ReportDate.Add(4 * time.Hour)
+			incident.ResolveDate = &resolved
+			incident.Status = "Closed"
+			incident.CalculateResolutionTime()
+		}
+		incidents = append(incidents, incident)
+	}
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	analysis, err := analyticsService.GetRegionAnalysis(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get region analysis: %v", err)
+	}
+	if len(analysis) != 3 {
+		t.Fatalf("Expected 3 regions, got %+v", analysis)
+	}
+	emea := analysis[0]
+	if emea.Region != "EMEA" || emea.IncidentCount != 3 || emea.SharePercent != 60 || emea.ResolutionRate != 66.7 {
+		t.Errorf("Unexpected EMEA analysis: %+v", emea)
+	}
+	if emea.PriorityMix["P1"] != 1 || emea.PriorityMix["P3"] != 2 || emea.AvgResolutionTime != 4 {
+		t.Errorf("Unexpected EMEA priority mix or resolution time: %+v", emea)
+	}
+	if analysis[2].Region != UnspecifiedRegion || analysis[2].IncidentCount != 1 {
+		t.Errorf("Expected incidents without a region to be unspecified, got %+v", analysis[2])
+	}
+
+	// Limited lists keep the share of every matching incident
+	limited, err := analyticsService.GetRegionAnalysis(ctx, &TimelineFilters{Limit: 1})
+	if err != nil {
+		t.Fatalf("Failed to get region analysis: %v", err)
+	}
+	if len(limited) != 1 || limited[0].SharePercent != 60 {
+		t.Errorf("Unexpected limited region analysis: %+v", limited)
+	}
+
+	filtered, err := analyticsService.GetResolutionAnalysis(ctx, &TimelineFilters{Regions: []string{"APAC"}})
+	if err != nil {
+		t.Fatalf("Failed to get resolution analysis: %v", err)
+	}
+	if filtered.TotalIncidents != 1 {
+		t.Errorf("Expected the region filter to keep 1 incident, got %d", filtered.TotalIncidents)
+	}
+}
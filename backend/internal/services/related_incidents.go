@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Related incident defaults
+const (
+	DefaultRelatedLimit      = 10
+	DefaultRelatedWindowDays = 7
+	DefaultRelatedMinScore   = 0.1
+)
+
+// Relatedness signals and their weights in the combined score. Text similarity dominates since
+// it is the strongest hint that two tickets share a cause.
+const (
+	RelatedByDescription = "similar_description"
+	RelatedByApplication = "same_application"
+	RelatedByCluster     = "same_cluster"
+
+	relatedDescriptionWeight = 0.5
+	relatedApplicationWeight = 0.3
+	relatedClusterWeight     = 0.2
+
+	// minDescriptionSimilarity is the token overlap below which descriptions are not similar
+	minDescriptionSimilarity = 0.2
+)
+
+// relatedStopWords are common words left out of description similarity
+var relatedStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "has": true, "in": true, "is": true, "it": true, "not": true, "of": true,
+	"on": true, "or": true, "the": true, "to": true, "was": true, "with": true,
+}
+
+// RelatedOptions controls which incidents count as related
+type RelatedOptions struct {
+	// Limit caps the number of related incidents returned
+	Limit int
+	// WindowDays is how many days apart incidents on the same application may be reported
+	WindowDays int
+	// MinScore drops related incidents with a lower combined score
+	MinScore float64
+}
+
+// RelatedIncident is an incident related to the one under review, with the signals that matched
+type RelatedIncident struct {
+	ID                    string    `json:"id"`
+	IncidentID            string    `json:"incident_id"`
+	BriefDescription      string    `json:"brief_description"`
+	ApplicationName       string    `json:"application_name"`
+	ITProcessGroup        string    `json:"it_process_group,omitempty"`
+	Priority              string    `json:"priority"`
+	Status                string    `json:"status"`
+	ReportDate            time.Time `json:"report_date"`
+	DescriptionSimilarity float64   `json:"description_similarity"`
+	DaysApart             int       `json:"days_apart"`
+	Score                 float64   `json:"score"`
+	Reasons               []string  `json:"reasons"`
+}
+
+// relatedCandidate is an incident considered for relatedness
+type relatedCandidate struct {
+	RelatedIncident
+	tokens map[string]bool
+}
+
+// withDefaults fills unset related incident options
+func (o RelatedOptions) withDefaults() RelatedOptions {
+	if o.Limit <= 0 {
+		o.Limit = DefaultRelatedLimit
+	}
+	if o.WindowDays <= 0 {
+		o.WindowDays = DefaultRelatedWindowDays
+	}
+	if o.MinScore <= 0 {
+		o.MinScore = DefaultRelatedMinScore
+	}
+	return o
+}
+
+// GetRelatedIncidents ranks other incidents by how related they are to the incident with the
+// given record ID, returning sql.ErrNoRows when it does not exist. Incidents are related by
+// similar description text, by being reported on the same application within the time window,
+// and by sharing an IT process group, the cluster the automation analyzer assigns.
+func (s *IncidentService) GetRelatedIncidents(ctx context.Context, id string, opts RelatedOptions) ([]RelatedIncident, error) {
+	opts = opts.withDefaults()
+
+	scope, scopeArgs := scopeClause(ctx)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, incident_id, COALESCE(brief_description, ''), COALESCE(description, ''),
+			COALESCE(application_name, ''), COALESCE(it_process_group, ''), priority,
+			COALESCE(status, ''), report_date
+		FROM incidents
+		WHERE 1=1`+scope, scopeArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incidents: %w", err)
+	}
+	defer rows.Close()
+
+	var target *relatedCandidate
+	var candidates []relatedCandidate
+	for rows.Next() {
+		var candidate relatedCandidate
+		var description string
+		if err := rows.Scan(&candidate.ID, &candidate.IncidentID, &candidate.BriefDescription, &description,
+			&candidate.ApplicationName, &candidate.ITProcessGroup, &candidate.Priority, &candidate.Status,
+			&candidate.ReportDate); err != nil {
+			return nil, fmt.Errorf("failed to scan incident: %w", err)
+		}
+		candidate.tokens = descriptionTokens(candidate.BriefDescription + " " + description)
+		if candidate.ID == id {
+			target = &candidate
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating incidents: %w", err)
+	}
+	if target == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	related := []RelatedIncident{}
+	for _, candidate := range candidates {
+		if scoreRelated(target, &candidate, opts.WindowDays) && candidate.Score >= opts.MinScore {
+			related = append(related, candidate.RelatedIncident)
+		}
+	}
+
+	sort.SliceStable(related, func(i, j int) bool {
+		if related[i].Score != related[j].Score {
+			return related[i].Score > related[j].Score
+		}
+		if related[i].DaysApart != related[j].DaysApart {
+			return related[i].DaysApart < related[j].DaysApart
+		}
+		return related[i].IncidentID < related[j].IncidentID
+	})
+	if len(related) > opts.Limit {
+		related = related[:opts.Limit]
+	}
+
+	return related, nil
+}
+
+// scoreRelated fills in the signals and combined score of candidate against target, reporting
+// whether any signal matched
+func scoreRelated(target, candidate *relatedCandidate, windowDays int) bool {
+	candidate.DaysApart = int(math.Abs(candidate.ReportDate.Sub(target.ReportDate).Hours()) / 24)
+	candidate.DescriptionSimilarity = roundTo(jaccard(target.tokens, candidate.tokens), 3)
+	candidate.Reasons = []string{}
+
+	score := 0.0
+	if candidate.DescriptionSimilarity >= minDescriptionSimilarity {
+		candidate.Reasons = append(candidate.Reasons, RelatedByDescription)
+		score += relatedDescriptionWeight * candidate.DescriptionSimilarity
+	}
+	if target.ApplicationName != "" && strings.EqualFold(candidate.ApplicationName, target.ApplicationName) &&
+		candidate.DaysApart <= windowDays {
+		// Closer in time is more likely the same underlying problem
+		candidate.Reasons = append(candidate.Reasons, RelatedByApplication)
+		score += relatedApplicationWeight * (1 - float64(candidate.DaysApart)/float64(windowDays+1))
+	}
+	if target.ITProcessGroup != "" && candidate.ITProcessGroup == target.ITProcessGroup {
+		candidate.Reasons = append(candidate.Reasons, RelatedByCluster)
+		score += relatedClusterWeight
+	}
+
+	candidate.Score = roundTo(score, 3)
+	return len(candidate.Reasons) > 0
+}
+
+// descriptionTokens returns the distinct words of text used for similarity, without stop words
+func descriptionTokens(text string) map[string]bool {
+	tokens := map[string]bool{}
+	for _, token := range (&SimpleAutomationAnalyzer{}).tokenizeText(text) {
+		if !relatedStopWords[token] {
+			tokens[token] = true
+		}
+	}
+	return tokens
+}
+
+// jaccard returns the share of tokens two sets have in common
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for token := range a {
+		if b[token] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
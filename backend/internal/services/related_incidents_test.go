@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestIncidentService_GetRelatedIncidents(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+
+	incident := func(id, brief, application, group string, reported time.Time) models.Incident {
+		incident := diffTestIncident(id, "upload-1", "INC-"+id, "P3", "Open")
+		incident.BriefDescription = brief
+		incident.ApplicationName = application
+		incident.ITProcessGroup = group
+		incident.ReportDate = reported
+		return incident
+	}
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+
+	service := NewIncidentService(db)
+	if _, err := service.BatchInsertIncidents(ctx, []models.Incident{
+		incident("r0", "Payment gateway timeout on checkout", "Billing", "Payments", day(1, 15)),
+		incident("r1", "Checkout: payment gateway timeout", "Store", "", day(3, 1)),
+		incident("r2", "Printer offline", "billing", "", day(1, 17)),
+		incident("r3", "Disk full", "Reporting", "Payments", day(6, 1)),
+		incident("r4", "VPN down", "Billing", "", day(2, 20)),
+	}, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	related, err := service.GetRelatedIncidents(ctx, "r0", RelatedOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get related incidents: %v", err)
+	}
+	expected := []struct {
+		id     string
+		score  float64
+		reason string
+	}{
+		{"r1", 0.5, RelatedByDescription},
+		{"r2", 0.225, RelatedByApplication},
+		{"r3", 0.2, RelatedByCluster},
+	}
+	if len(related) != len(expected) {
+		t.Fatalf("Expected %d related incidents, got %+v", len(expected), related)
+	}
+	for i, want := range expected {
+		got := related[i]
+		if got.ID != want.id || got.Score != want.score || len(got.Reasons) != 1 || got.Reasons[0] != want.reason {
+			t.Errorf("Rank %d: expected %s scoring %v by %s, got %+v", i, want.id, want.score, want.reason, got)
+		}
+	}
+	if related[0].DescriptionSimilarity != 1 || related[1].DaysApart != 2 {
+		t.Errorf("Unexpected signals: %+v", related[:2])
+	}
+
+	related, err = service.GetRelatedIncidents(ctx, "r0", RelatedOptions{Limit: 1, WindowDays: 60})
+	if err != nil {
+		t.Fatalf("Failed to get related incidents: %v", err)
+	}
+	if len(related) != 1 || related[0].ID != "r1" {
+		t.Errorf("Expected only the best match, got %+v", related)
+	}
+
+	related, err = service.GetRelatedIncidents(ctx, "r0", RelatedOptions{MinScore: 0.21})
+	if err != nil {
+		t.Fatalf("Failed to get related incidents: %v", err)
+	}
+	if len(related) != 2 {
+		t.Errorf("Expected the cluster-only match to fall below the minimum score, got %+v", related)
+	}
+
+	if _, err := service.GetRelatedIncidents(ctx, "missing", RelatedOptions{}); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for an unknown incident, got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultSnapshotTTL is how long a snapshot's share link works when no lifetime is given, and
+// MaxSnapshotTTL the longest it may be asked to work
+const (
+	DefaultSnapshotTTL = 7 * 24 * time.Hour
+	MaxSnapshotTTL     = 90 * 24 * time.Hour
+)
+
+// ErrInvalidSnapshot is returned when a snapshot asks for a view that cannot be snapshotted
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// snapshotViews computes each analytics view that can be snapshotted, by its route below /analytics
+var snapshotViews = map[string]func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error){
+	"summary": func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error) {
+		return s.GetAnalyticsSummary(ctx, filters)
+	},
+	"timeline/daily": func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error) {
+		return s.GetDailyTimeline(ctx, filters)
+	},
+	"timeline/weekly": func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error) {
+		return s.GetWeeklyTimeline(ctx, filters)
+	},
+	"priority": func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error) {
+		return s.GetPriorityAnalysis(ctx, filters)
+	},
+	"applications": func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error) {
+		return s.GetApplicationAnalysis(ctx, filters)
+	},
+	"resolution": func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error) {
+		return s.GetResolutionAnalysis(ctx, filters)
+	},
+	"sentiment": func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error) {
+		return s.GetSentimentAnalysis(ctx, filters)
+	},
+	"automation": func(s *AnalyticsService, ctx context.Context, filters *TimelineFilters) (interface{}, error) {
+		return s.GetAutomationAnalysis(ctx, filters)
+	},
+}
+
+// SnapshotViews returns the analytics views that can be snapshotted, sorted
+func SnapshotViews() []string {
+	views := make([]string, 0, len(snapshotViews))
+	for view := range snapshotViews {
+		views = append(views, view)
+	}
+	sort.Strings(views)
+	return views
+}
+
+// ReportSnapshot is an analytics view's result frozen with the filters it was computed for, served
+// read-only through an expiring link that needs no sign-in. The result is what its creator saw,
+// within their data scope. Only a hash of the link's token is stored.
+type ReportSnapshot struct {
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	View      string           `json:"view"` // analytics route below /analytics
+	Filters   *TimelineFilters `json:"filters"`
+	Data      json.RawMessage  `json:"data,omitempty"` // left out of listings
+	CreatedBy string           `json:"created_by,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+	ExpiresAt time.Time        `json:"expires_at"`
+	ViewCount int              `json:"view_count"`
+	RevokedAt *time.Time       `json:"revoked_at,omitempty"`
+}
+
+// ReportSnapshotService freezes analytics views and serves them through share links
+type ReportSnapshotService struct {
+	db        *sql.DB
+	analytics *AnalyticsService
+}
+
+// NewReportSnapshotService creates a new ReportSnapshotService instance
+func NewReportSnapshotService(db *sql.DB) *ReportSnapshotService {
+	return &ReportSnapshotService{
+		db:        db,
+		analytics: NewAnalyticsService(db),
+	}
+}
+
+// CreateSnapshot computes view for filters with live queries, within ctx's data scope, and stores
+// the result with a share link living for ttl, or DefaultSnapshotTTL when ttl is 0. It returns the
+// snapshot with the link's token, which is only shown here.
+func (s *ReportSnapshotService) CreateSnapshot(ctx context.Context, name, view string, filters *TimelineFilters, ttl time.Duration, createdBy string) (*ReportSnapshot, string, error) {
+	fetch, ok := snapshotViews[view]
+	if !ok {
+		return nil, "", fmt.Errorf("%w: view %q is not one of %s", ErrInvalidSnapshot, view, strings.Join(SnapshotViews(), ", "))
+	}
+	if ttl <= 0 {
+		ttl = DefaultSnapshotTTL
+	}
+	if ttl > MaxSnapshotTTL {
+		ttl = MaxSnapshotTTL
+	}
+	if filters == nil {
+		filters = &TimelineFilters{}
+	}
+
+	result, err := fetch(s.analytics, ctx, filters)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to compute %s: %w", view, err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	filterData, err := json.Marshal(filters)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode snapshot filters: %w", err)
+	}
+
+	now := time.Now().UTC()
+	snapshot := &ReportSnapshot{
+		ID:        hashSessionToken(randomToken())[:16],
+		Name:      strings.TrimSpace(name),
+		View:      view,
+		Filters:   filters,
+		Data:      data,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	token := randomToken()
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO report_snapshots (id, token_hash, name, view, filters, data, created_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, snapshot.ID, hashSessionToken(token), snapshot.Name, view, string(filterData), string(data),
+		nullIfEmpty(createdBy), snapshot.CreatedAt, snapshot.ExpiresAt); err != nil {
+		return nil, "", fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	logf(ctx, "Snapshot %s of %s created by %s, expiring %s", snapshot.ID, view, createdBy, snapshot.ExpiresAt.Format(time.RFC3339))
+	return snapshot, token, nil
+}
+
+// scanReportSnapshot reads a snapshot selected with reportSnapshotColumns, and its data when
+// withData is set
+func scanReportSnapshot(row interface{ Scan(...interface{}) error }, withData bool) (*ReportSnapshot, error) {
+	var snapshot ReportSnapshot
+	var filters, data string
+	var revokedAt sql.NullTime
+	if err := row.Scan(&snapshot.ID, &snapshot.Name, &snapshot.View, &filters, &data, &snapshot.CreatedBy,
+		&snapshot.CreatedAt, &snapshot.ExpiresAt, &snapshot.ViewCount, &revokedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(filters), &snapshot.Filters); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot filters: %w", err)
+	}
+	if withData {
+		snapshot.Data = json.RawMessage(data)
+	}
+	if revokedAt.Valid {
+		snapshot.RevokedAt = &revokedAt.Time
+	}
+	return &snapshot, nil
+}
+
+// reportSnapshotColumns are the columns scanned by scanReportSnapshot
+const reportSnapshotColumns = `id, name, view, filters, data, COALESCE(created_by, ''), created_at, expires_at,
+	COALESCE(view_count, 0), revoked_at`
+
+// ListSnapshots returns every snapshot without its data, including revoked and expired ones,
+// newest first
+func (s *ReportSnapshotService) ListSnapshots(ctx context.Context) ([]ReportSnapshot, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+reportSnapshotColumns+" FROM report_snapshots ORDER BY created_at DESC, id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []ReportSnapshot{}
+	for rows.Next() {
+		snapshot, err := scanReportSnapshot(rows, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot: %w", err)
+		}
+		snapshots = append(snapshots, *snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+// GetSharedSnapshot returns the live snapshot shared with token and counts the view, returning
+// sql.ErrNoRows when it does not exist, was revoked or expired
+func (s *ReportSnapshotService) GetSharedSnapshot(ctx context.Context, token string) (*ReportSnapshot, error) {
+	snapshot, err := scanReportSnapshot(s.db.QueryRowContext(ctx, "SELECT "+reportSnapshotColumns+`
+		FROM report_snapshots
+		WHERE token_hash = ? AND revoked_at IS NULL AND expires_at > ?
+	`, hashSessionToken(token), time.Now().UTC()), true)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snapshot: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, "UPDATE report_snapshots SET view_count = COALESCE(view_count, 0) + 1 WHERE id = ?",
+		snapshot.ID); err != nil {
+		return nil, fmt.Errorf("failed to count snapshot view: %w", err)
+	}
+	snapshot.ViewCount++
+	return snapshot, nil
+}
+
+// RevokeSnapshot stops a snapshot's link working, returning sql.ErrNoRows when it does not exist
+// or was revoked already
+func (s *ReportSnapshotService) RevokeSnapshot(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "UPDATE report_snapshots SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL",
+		time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke snapshot: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Outlier detection methods
+const (
+	OutlierMethodIQR        = "iqr"
+	OutlierMethodPercentile = "percentile"
+)
+
+// Outlier detection defaults
+const (
+	DefaultOutlierIQRMultiplier = 1.5
+	DefaultOutlierPercentile    = 99.0
+	DefaultOutlierLimit         = 100
+)
+
+// OutlierOptions controls how resolution time outliers are detected
+type OutlierOptions struct {
+	// Method is OutlierMethodIQR (default) or OutlierMethodPercentile
+	Method string
+	// IQRMultiplier sets the fences at Q1 - k×IQR and Q3 + k×IQR for the IQR method
+	IQRMultiplier float64
+	// Percentile caps resolution times above this percentile for the percentile method
+	Percentile float64
+	// Exclude removes outliers from the resolution metrics
+	Exclude bool
+}
+
+// OutlierBounds are the resolution times, in hours, outside of which an incident is an outlier.
+// Lower is nil when the method only caps long resolution times.
+type OutlierBounds struct {
+	Method string   `json:"method"`
+	Lower  *float64 `json:"lower,omitempty"`
+	Upper  float64  `json:"upper"`
+}
+
+// ResolutionOutlier is an incident whose resolution time falls outside the outlier bounds
+type ResolutionOutlier struct {
+	ID                  string     `json:"id"`
+	IncidentID          string     `json:"incident_id"`
+	ApplicationName     string     `json:"application_name"`
+	ResolutionGroup     string     `json:"resolution_group"`
+	Priority            string     `json:"priority"`
+	Status              string     `json:"status"`
+	ReportDate          time.Time  `json:"report_date"`
+	ResolveDate         *time.Time `json:"resolve_date,omitempty"`
+	ResolutionTimeHours int        `json:"resolution_time_hours"`
+}
+
+// ResolutionOutlierReport lists the outliers of the filtered incidents, longest first
+type ResolutionOutlierReport struct {
+	Bounds    *OutlierBounds      `json:"bounds"`
+	Total     int                 `json:"total"`
+	Incidents []ResolutionOutlier `json:"incidents"`
+}
+
+// withDefaults fills unset outlier options
+func (o OutlierOptions) withDefaults() OutlierOptions {
+	if o.Method == "" {
+		o.Method = OutlierMethodIQR
+	}
+	if o.IQRMultiplier <= 0 {
+		o.IQRMultiplier = DefaultOutlierIQRMultiplier
+	}
+	if o.Percentile <= 0 {
+		o.Percentile = DefaultOutlierPercentile
+	}
+	return o
+}
+
+// IsValidOutlierMethod reports whether method is a supported outlier detection method
+func IsValidOutlierMethod(method string) bool {
+	return method == OutlierMethodIQR || method == OutlierMethodPercentile
+}
+
+// GetResolutionOutlierBounds computes the outlier bounds over the resolved incidents matching
+// filters. It returns nil when no incident has a resolution time.
+func (s *AnalyticsService) GetResolutionOutlierBounds(ctx context.Context, filters *TimelineFilters, opts OutlierOptions) (*OutlierBounds, error) {
+	opts = opts.withDefaults()
+	if !IsValidOutlierMethod(opts.Method) {
+		return nil, fmt.Errorf("invalid outlier method: %s", opts.Method)
+	}
+
+	whereClause, args, nextIdx := buildFilterConditions(ctx, filters, 1)
+	query := fmt.Sprintf(`
+		SELECT
+			QUANTILE_CONT(%[1]s, 0.25),
+			QUANTILE_CONT(%[1]s, 0.75),
+			QUANTILE_CONT(%[1]s, $%[2]d)
+		FROM incidents
+		WHERE %[1]s IS NOT NULL`, resolutionHoursExpr, nextIdx)
+	query += whereClause
+	args = append(args, opts.Percentile/100)
+
+	var q1, q3, cap sql.NullFloat64
+	if err := s.db.QueryRowContext(ctx, s.federate(ctx, query, filters), args...).Scan(&q1, &q3, &cap); err != nil {
+		return nil, fmt.Errorf("failed to query resolution quantiles: %w", err)
+	}
+	if !q1.Valid {
+		return nil, nil
+	}
+
+	if opts.Method == OutlierMethodPercentile {
+		return &OutlierBounds{Method: opts.Method, Upper: cap.Float64}, nil
+	}
+	iqr := q3.Float64 - q1.Float64
+	lower := q1.Float64 - opts.IQRMultiplier*iqr
+	return &OutlierBounds{
+		Method: opts.Method,
+		Lower:  &lower,
+		Upper:  q3.Float64 + opts.IQRMultiplier*iqr,
+	}, nil
+}
+
+// GetResolutionAnalysisWithOptions returns resolution analysis, optionally excluding resolution
+// time outliers. Excluded incidents are left out of every metric, including the totals.
+func (s *AnalyticsService) GetResolutionAnalysisWithOptions(ctx context.Context, filters *TimelineFilters, opts OutlierOptions) (*ResolutionMetrics, error) {
+	if !opts.Exclude {
+		return s.GetResolutionAnalysis(ctx, filters)
+	}
+
+	bounds, err := s.GetResolutionOutlierBounds(ctx, filters, opts)
+	if err != nil {
+		return nil, err
+	}
+	if bounds == nil {
+		return s.GetResolutionAnalysis(ctx, filters)
+	}
+
+	whereClause, args, nextIdx := buildFilterConditions(ctx, filters, 1)
+	outlierCondition, outlierArgs := bounds.condition(nextIdx)
+	percentiles := filters.percentiles()
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*) FILTER (WHERE NOT (%s)) as total_incidents,
+			COUNT(CASE WHEN resolve_date IS NOT NULL AND NOT (%s) THEN 1 END) as resolved_incidents,
+			AVG(%s) FILTER (WHERE NOT (%s)) as avg_resolution_time,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY %s) FILTER (WHERE NOT (%s)) as median_resolution_time,
+			COUNT(*) FILTER (WHERE %s) as excluded_outliers%s
+		FROM incidents
+		WHERE 1=1`, outlierCondition, outlierCondition, resolutionHoursExpr, outlierCondition, resolutionHoursExpr, outlierCondition, outlierCondition,
+		percentileColumns(resolutionHoursExpr, percentiles, "NOT ("+outlierCondition+")"))
+	query += whereClause
+	args = append(args, outlierArgs...)
+
+	metrics := ResolutionMetrics{OutlierBounds: bounds}
+	var avgResolutionTime, medianResolutionTime sql.NullFloat64
+	values := newPercentileValues(percentiles)
+	dest := []interface{}{
+		&metrics.TotalIncidents,
+		&metrics.ResolvedIncidents,
+		&avgResolutionTime,
+		&medianResolutionTime,
+		&metrics.ExcludedOutliers,
+	}
+	err = s.db.QueryRowContext(ctx, s.federate(ctx, query, filters), args...).Scan(append(dest, values.dest()...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query resolution analysis: %w", err)
+	}
+
+	if avgResolutionTime.Valid {
+		metrics.AvgResolutionTime = avgResolutionTime.Float64
+	}
+	if medianResolutionTime.Valid {
+		metrics.MedianResolutionTime = medianResolutionTime.Float64
+	}
+	metrics.Percentiles = values.result()
+	if metrics.TotalIncidents > 0 {
+		metrics.ResolutionRate = float64(metrics.ResolvedIncidents) / float64(metrics.TotalIncidents) * 100
+	}
+
+	return &metrics, nil
+}
+
+// GetResolutionOutliers lists up to limit incidents whose resolution time falls outside the
+// outlier bounds, longest first, so they can be reviewed for data entry mistakes.
+func (s *AnalyticsService) GetResolutionOutliers(ctx context.Context, filters *TimelineFilters, opts OutlierOptions, limit int) (*ResolutionOutlierReport, error) {
+	if limit <= 0 {
+		limit = DefaultOutlierLimit
+	}
+
+	bounds, err := s.GetResolutionOutlierBounds(ctx, filters, opts)
+	if err != nil {
+		return nil, err
+	}
+	report := &ResolutionOutlierReport{Bounds: bounds, Incidents: []ResolutionOutlier{}}
+	if bounds == nil {
+		return report, nil
+	}
+
+	whereClause, args, nextIdx := buildFilterConditions(ctx, filters, 1)
+	outlierCondition, outlierArgs := bounds.condition(nextIdx)
+	args = append(args, outlierArgs...)
+
+	countQuery := "SELECT COUNT(*) FROM incidents WHERE " + outlierCondition + whereClause
+	if err := s.db.QueryRowContext(ctx, s.federate(ctx, countQuery, filters), args...).Scan(&report.Total); err != nil {
+		return nil, fmt.Errorf("failed to count resolution outliers: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, incident_id, COALESCE(application_name, ''), COALESCE(resolution_group, ''),
+			priority, COALESCE(status, ''), report_date, resolve_date, %[1]s AS resolution_hours
+		FROM incidents
+		WHERE %[2]s%[3]s
+		ORDER BY resolution_hours DESC, incident_id
+		LIMIT %[4]d`, resolutionHoursExpr, outlierCondition, whereClause, limit)
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query resolution outliers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var outlier ResolutionOutlier
+		var resolveDate sql.NullTime
+		if err := rows.Scan(&outlier.ID, &outlier.IncidentID, &outlier.ApplicationName, &outlier.ResolutionGroup,
+			&outlier.Priority, &outlier.Status, &outlier.ReportDate, &resolveDate, &outlier.ResolutionTimeHours); err != nil {
+			return nil, fmt.Errorf("failed to scan resolution outlier: %w", err)
+		}
+		if resolveDate.Valid {
+			outlier.ResolveDate = &resolveDate.Time
+		}
+		report.Incidents = append(report.Incidents, outlier)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating resolution outliers: %w", err)
+	}
+
+	return report, nil
+}
+
+// condition returns a SQL condition matching incidents outside the bounds, with placeholders
+// starting at argIndex
+func (b *OutlierBounds) condition(argIndex int) (string, []interface{}) {
+	if b.Lower == nil {
+		return fmt.Sprintf("(%[1]s IS NOT NULL AND %[1]s > $%[2]d)", resolutionHoursExpr, argIndex),
+			[]interface{}{b.Upper}
+	}
+	return fmt.Sprintf("(%[1]s IS NOT NULL AND (%[1]s > $%[2]d OR %[1]s < $%[3]d))", resolutionHoursExpr, argIndex, argIndex+1),
+		[]interface{}{b.Upper, *b.Lower}
+}
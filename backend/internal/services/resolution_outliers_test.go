@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestAnalyticsService_ResolutionOutliers(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+
+	resolved := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+	var incidents []models.Incident
+	for i, hours := range []int{4, 4, 5, 5, 6, 6, 7, 7, 8, 2000} {
+		incident := diffTestIncident(fmt.Sprintf("r%d", i), "upload-1", fmt.Sprintf("INC%03d", i), "P3", "Closed")
+		incident.ResolveDate = &resolved
+		incident.ResolutionTimeHours = &hours
+		incidents = append(incidents, incident)
+	}
+	incidents = append(incidents, diffTestIncident("open", "upload-1", "INC100", "P3", "Open"))
+
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	service := NewAnalyticsService(db)
+
+	t.Run("IQR bounds", func(t *testing.T) {
+		bounds, err := service.GetResolutionOutlierBounds(ctx, nil, OutlierOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get outlier bounds: %v", err)
+		}
+		if bounds.Method != OutlierMethodIQR || bounds.Lower == nil || *bounds.Lower != 2 || bounds.Upper != 10 {
+			t.Errorf("Expected IQR bounds 2 to 10, got %+v", bounds)
+		}
+	})
+
+	t.Run("metrics exclude outliers", func(t *testing.T) {
+		metrics, err := service.GetResolutionAnalysisWithOptions(ctx, nil, OutlierOptions{Exclude: true})
+		if err != nil {
+			t.Fatalf("Failed to get resolution analysis: %v", err)
+		}
+		if metrics.ExcludedOutliers != 1 || metrics.TotalIncidents != 10 || metrics.ResolvedIncidents != 9 {
+			t.Errorf("Expected 1 excluded, 10 total and 9 resolved, got %+v", metrics)
+		}
+		if math.Abs(metrics.AvgResolutionTime-52.0/9) > 1e-9 || metrics.MedianResolutionTime != 6 {
+			t.Errorf("Expected average 5.78 and median 6, got %v and %v", metrics.AvgResolutionTime, metrics.MedianResolutionTime)
+		}
+
+		included, err := service.GetResolutionAnalysisWithOptions(ctx, nil, OutlierOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get resolution analysis: %v", err)
+		}
+		if included.AvgResolutionTime != 205.2 || included.OutlierBounds != nil {
+			t.Errorf("Expected the outlier to be included by default, got %+v", included)
+		}
+	})
+
+	t.Run("outlier listing", func(t *testing.T) {
+		report, err := service.GetResolutionOutliers(ctx, nil, OutlierOptions{Method: OutlierMethodPercentile, Percentile: 90}, 0)
+		if err != nil {
+			t.Fatalf("Failed to get resolution outliers: %v", err)
+		}
+		if report.Bounds.Lower != nil || math.Abs(report.Bounds.Upper-207.2) > 1e-9 {
+			t.Errorf("Expected an upper cap of 207.2, got %+v", report.Bounds)
+		}
+		if report.Total != 1 || len(report.Incidents) != 1 || report.Incidents[0].IncidentID != "INC009" {
+			t.Fatalf("Expected INC009 as the only outlier, got %+v", report.Incidents)
+		}
+		if report.Incidents[0].ResolutionTimeHours != 2000 || report.Incidents[0].ResolveDate == nil {
+			t.Errorf("Unexpected outlier: %+v", report.Incidents[0])
+		}
+	})
+
+	t.Run("no resolved incidents", func(t *testing.T) {
+		filters := &TimelineFilters{Statuses: []string{"Open"}}
+		report, err := service.GetResolutionOutliers(ctx, filters, OutlierOptions{}, 10)
+		if err != nil {
+			t.Fatalf("Failed to get resolution outliers: %v", err)
+		}
+		if report.Bounds != nil || len(report.Incidents) != 0 {
+			t.Errorf("Expected no bounds or outliers, got %+v", report)
+		}
+	})
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRunbook is returned when a runbook has neither keywords nor a linked process group
+var ErrInvalidRunbook = errors.New("runbook must have keywords or a linked IT process group")
+
+// Opportunity kinds of an automation candidate
+const (
+	OpportunityExistingRunbook = "existing_runbook" // a registered runbook already covers it
+	OpportunityNew             = "new_opportunity"
+)
+
+// How a runbook was matched to an automation candidate
+const (
+	RunbookMatchProcessGroup = "process_group"
+	RunbookMatchKeywords     = "keywords"
+)
+
+// RunbookKeywordCoverage is the share, in percent, of a candidate's automatable incidents that
+// must mention a runbook keyword for the runbook to cover the candidate
+const RunbookKeywordCoverage = 50.0
+
+// Runbook is an existing automation or documented procedure. It covers the automation
+// candidate of its linked IT process group, and candidates whose incidents mention its keywords.
+type Runbook struct {
+	Name           string    `json:"name"`
+	Description    string    `json:"description,omitempty"`
+	Keywords       []string  `json:"keywords"`
+	ITProcessGroup string    `json:"it_process_group,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// RunbookMatch is a runbook found to cover an automation candidate. CoveragePct is the share of
+// the candidate's automatable incidents mentioning the matched keywords; a runbook linked to the
+// candidate's process group covers all of them.
+type RunbookMatch struct {
+	Name             string   `json:"name"`
+	MatchedBy        string   `json:"matched_by"`
+	MatchedKeywords  []string `json:"matched_keywords,omitempty"`
+	MatchedIncidents int      `json:"matched_incidents"`
+	CoveragePct      float64  `json:"coverage_pct"`
+}
+
+// RunbookService manages the registry of existing runbooks
+type RunbookService struct {
+	db *sql.DB
+}
+
+// NewRunbookService creates a new RunbookService instance
+func NewRunbookService(db *sql.DB) *RunbookService {
+	return &RunbookService{db: db}
+}
+
+// runbookColumns lists the columns scanned by scanRunbook
+const runbookColumns = "name, description, keywords, it_process_group, updated_at"
+
+// ListRunbooks returns all runbooks ordered by name
+func (s *RunbookService) ListRunbooks(ctx context.Context) ([]Runbook, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+runbookColumns+" FROM runbooks ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query runbooks: %w", err)
+	}
+	defer rows.Close()
+
+	runbooks := []Runbook{}
+	for rows.Next() {
+		runbook, err := scanRunbook(rows)
+		if err != nil {
+			return nil, err
+		}
+		runbooks = append(runbooks, *runbook)
+	}
+
+	return runbooks, rows.Err()
+}
+
+// GetRunbook returns the named runbook, or sql.ErrNoRows when it does not exist
+func (s *RunbookService) GetRunbook(ctx context.Context, name string) (*Runbook, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+runbookColumns+" FROM runbooks WHERE name = ?", name)
+	return scanRunbook(row)
+}
+
+// SaveRunbook creates or replaces a runbook. Keywords are trimmed, lowercased and deduplicated.
+func (s *RunbookService) SaveRunbook(ctx context.Context, runbook Runbook) (*Runbook, error) {
+	runbook.Name = strings.TrimSpace(runbook.Name)
+	runbook.Description = strings.TrimSpace(runbook.Description)
+	runbook.ITProcessGroup = strings.TrimSpace(runbook.ITProcessGroup)
+	runbook.Keywords = cleanRunbookKeywords(runbook.Keywords)
+	if len(runbook.Keywords) == 0 && runbook.ITProcessGroup == "" {
+		return nil, ErrInvalidRunbook
+	}
+	runbook.UpdatedAt = time.Now()
+
+	keywordsJSON, err := json.Marshal(runbook.Keywords)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode runbook keywords: %w", err)
+	}
+
+	query := `
+		INSERT OR REPLACE INTO runbooks (name, description, keywords, it_process_group, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, runbook.Name, nullIfEmpty(runbook.Description), string(keywordsJSON),
+		nullIfEmpty(runbook.ITProcessGroup), runbook.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save runbook: %w", err)
+	}
+
+	return &runbook, nil
+}
+
+// DeleteRunbook removes a runbook, returning sql.ErrNoRows when it does not exist
+func (s *RunbookService) DeleteRunbook(ctx context.Context, name string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM runbooks WHERE name = ?", name)
+	if err != nil {
+		return fmt.Errorf("failed to delete runbook: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// cleanRunbookKeywords trims, lowercases and deduplicates keywords, dropping empty ones
+func cleanRunbookKeywords(keywords []string) []string {
+	cleaned := []string{}
+	seen := map[string]bool{}
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword == "" || seen[keyword] {
+			continue
+		}
+		seen[keyword] = true
+		cleaned = append(cleaned, keyword)
+	}
+	sort.Strings(cleaned)
+	return cleaned
+}
+
+// scanRunbook scans a runbooks row selected with runbookColumns
+func scanRunbook(row interface{ Scan(...interface{}) error }) (*Runbook, error) {
+	var runbook Runbook
+	var description, processGroup sql.NullString
+	var keywordsJSON string
+	if err := row.Scan(&runbook.Name, &description, &keywordsJSON, &processGroup, &runbook.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan runbook: %w", err)
+	}
+	if err := json.Unmarshal([]byte(keywordsJSON), &runbook.Keywords); err != nil {
+		return nil, fmt.Errorf("failed to decode runbook keywords: %w", err)
+	}
+	runbook.Description = description.String
+	runbook.ITProcessGroup = processGroup.String
+	return &runbook, nil
+}
+
+// matchRunbooks returns the runbooks covering the automation candidate of processGroup, whose
+// automatable incidents have the given descriptions, best coverage first
+func matchRunbooks(runbooks []Runbook, processGroup string, texts []string) []RunbookMatch {
+	lowered := make([]string, len(texts))
+	for i, text := range texts {
+		lowered[i] = strings.ToLower(text)
+	}
+
+	matches := []RunbookMatch{}
+	for _, runbook := range runbooks {
+		if runbook.ITProcessGroup != "" && strings.EqualFold(runbook.ITProcessGroup, processGroup) {
+			matches = append(matches, RunbookMatch{
+				Name:             runbook.Name,
+				MatchedBy:        RunbookMatchProcessGroup,
+				MatchedIncidents: len(texts),
+				CoveragePct:      100,
+			})
+			continue
+		}
+		if len(runbook.Keywords) == 0 || len(texts) == 0 {
+			continue
+		}
+
+		matched := 0
+		found := map[string]bool{}
+		for _, text := range lowered {
+			hit := false
+			for _, keyword := range runbook.Keywords {
+				if strings.Contains(text, keyword) {
+					found[keyword] = true
+					hit = true
+				}
+			}
+			if hit {
+				matched++
+			}
+		}
+		coverage := float64(matched) / float64(len(texts)) * 100
+		if coverage < RunbookKeywordCoverage {
+			continue
+		}
+
+		match := RunbookMatch{
+			Name:             runbook.Name,
+			MatchedBy:        RunbookMatchKeywords,
+			MatchedIncidents: matched,
+			CoveragePct:      math.Round(coverage*10) / 10,
+		}
+		for _, keyword := range runbook.Keywords {
+			if found[keyword] {
+				match.MatchedKeywords = append(match.MatchedKeywords, keyword)
+			}
+		}
+		matches = append(matches, match)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].CoveragePct > matches[j].CoveragePct
+	})
+	return matches
+}
+
+// queryDescriptions runs a query selecting one description per row and returns them
+func queryDescriptions(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query candidate descriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var texts []string
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			return nil, fmt.Errorf("failed to scan candidate description: %w", err)
+		}
+		texts = append(texts, text)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating candidate descriptions: %w", err)
+	}
+	return texts, nil
+}
+
+// flagRunbooks records on the candidate which runbooks already cover it, marking it as an
+// existing runbook or a new opportunity
+func (c *AutomationCandidate) flagRunbooks(runbooks []Runbook, texts []string) {
+	c.Runbooks = matchRunbooks(runbooks, c.ITProcessGroup, texts)
+	if len(c.Runbooks) > 0 {
+		c.Opportunity = OpportunityExistingRunbook
+	} else {
+		c.Opportunity = OpportunityNew
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestMatchRunbooks(t *testing.T) {
+	runbooks := []Runbook{
+		{Name: "Access self-service", ITProcessGroup: "access management"},
+		{Name: "Password reset bot", Keywords: []string{"locked out", "password reset"}},
+		{Name: "Disk cleanup", Keywords: []string{"disk full"}},
+	}
+	texts := []string{
+		"Password reset for new starter",
+		"User locked out after holiday",
+		"VPN token expired",
+	}
+
+	matches := matchRunbooks(runbooks, "Access Management", texts)
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 matching runbooks, got %+v", matches)
+	}
+	if matches[0].Name != "Access self-service" || matches[0].MatchedBy != RunbookMatchProcessGroup || matches[0].CoveragePct != 100 {
+		t.Errorf("Expected the linked runbook first, got %+v", matches[0])
+	}
+	keyword := matches[1]
+	if keyword.Name != "Password reset bot" || keyword.MatchedBy != RunbookMatchKeywords ||
+		keyword.MatchedIncidents != 2 || keyword.CoveragePct != 66.7 || len(keyword.MatchedKeywords) != 2 {
+		t.Errorf("Expected the keyword runbook to cover 2 of 3 incidents, got %+v", keyword)
+	}
+
+	// A keyword found in a minority of the incidents does not cover the candidate
+	if matches := matchRunbooks(runbooks[2:], "Storage", []string{"disk full on db01", "backup failed", "slow query"}); len(matches) != 0 {
+		t.Errorf("Expected no match below the coverage threshold, got %+v", matches)
+	}
+}
+
+func TestRunbookService(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+	service := NewRunbookService(db)
+
+	if _, err := service.SaveRunbook(ctx, Runbook{Name: "Empty", Keywords: []string{" "}}); !errors.Is(err, ErrInvalidRunbook) {
+		t.Errorf("Expected a runbook without keywords or process group to be rejected, got %v", err)
+	}
+
+	saved, err := service.SaveRunbook(ctx, Runbook{Name: " Password reset bot ", Keywords: []string{"Password Reset", "locked out", "password reset"}})
+	if err != nil {
+		t.Fatalf("SaveRunbook() error = %v", err)
+	}
+	if saved.Name != "Password reset bot" || len(saved.Keywords) != 2 || saved.Keywords[0] != "locked out" {
+		t.Errorf("Expected a trimmed name and cleaned keywords, got %+v", saved)
+	}
+
+	runbook, err := service.GetRunbook(ctx, "Password reset bot")
+	if err != nil {
+		t.Fatalf("GetRunbook() error = %v", err)
+	}
+	if len(runbook.Keywords) != 2 || runbook.Keywords[1] != "password reset" {
+		t.Errorf("Expected the stored keywords, got %+v", runbook.Keywords)
+	}
+
+	// Candidates covered by the runbook are flagged, others are new opportunities
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P3", "Closed"),
+		diffTestIncident("i2", "upload-1", "INC002", "P3", "Closed"),
+		diffTestIncident("i3", "upload-1", "INC003", "P3", "Closed"),
+	}
+	incidents[0].BriefDescription = "Password reset request"
+	incidents[1].BriefDescription = "Locked out of email"
+	incidents[2].BriefDescription = "Printer jammed"
+	incidents[2].ITProcessGroup = "Printing"
+	for i := range incidents {
+		if incidents[i].ITProcessGroup == "" {
+			incidents[i].ITProcessGroup = "Access Management"
+		}
+		feasible, score := true, 0.8
+		incidents[i].AutomationFeasible = &feasible
+		incidents[i].AutomationScore = &score
+	}
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	tickets := NewAutomationTicketService(db, nil)
+	access, err := tickets.GetCandidate(ctx, "Access Management", nil, 0)
+	if err != nil {
+		t.Fatalf("GetCandidate() error = %v", err)
+	}
+	if access.Opportunity != OpportunityExistingRunbook || len(access.Runbooks) != 1 || access.Runbooks[0].Name != "Password reset bot" {
+		t.Errorf("Expected the candidate to be covered by the runbook, got %s %+v", access.Opportunity, access.Runbooks)
+	}
+	printing, err := tickets.GetCandidate(ctx, "Printing", nil, 0)
+	if err != nil {
+		t.Fatalf("GetCandidate() error = %v", err)
+	}
+	if printing.Opportunity != OpportunityNew || len(printing.Runbooks) != 0 {
+		t.Errorf("Expected a new opportunity, got %s %+v", printing.Opportunity, printing.Runbooks)
+	}
+
+	if err := service.DeleteRunbook(ctx, "Password reset bot"); err != nil {
+		t.Fatalf("DeleteRunbook() error = %v", err)
+	}
+	if err := service.DeleteRunbook(ctx, "Password reset bot"); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows deleting a missing runbook, got %v", err)
+	}
+}
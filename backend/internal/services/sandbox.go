@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/models"
+)
+
+const (
+	// DefaultSandboxTTL is how long a sandbox lives without an explicit lifetime
+	DefaultSandboxTTL = 4 * time.Hour
+	// MaxSandboxTTL caps the lifetime of a sandbox
+	MaxSandboxTTL = 24 * time.Hour
+)
+
+// ReclassifiableFields are the incident fields a sandbox can reclassify
+var ReclassifiableFields = []string{"priority", "application_name", "resolution_group", "category", "status"}
+
+// ErrInvalidSandbox is returned when a sandbox overlay reclassifies an unknown field or sets a
+// value the field cannot hold
+var ErrInvalidSandbox = errors.New("invalid sandbox overlay")
+
+// Reclassification overrides a field of the incidents matching it in a sandbox. It matches one
+// incident by incident number, or else every incident whose field has the From value.
+type Reclassification struct {
+	Field      string `json:"field"`
+	IncidentID string `json:"incident_id,omitempty"`
+	From       string `json:"from,omitempty"`
+	To         string `json:"to"`
+}
+
+// SandboxOverlay is applied on top of the incidents analytics read inside a sandbox. Exclusions
+// match the stored values, before reclassification.
+type SandboxOverlay struct {
+	ExcludedApplications []string           `json:"excluded_applications"`
+	ExcludedGroups       []string           `json:"excluded_groups"`
+	ExcludedIncidents    []string           `json:"excluded_incidents"`
+	Reclassifications    []Reclassification `json:"reclassifications"`
+}
+
+// validate checks the reclassifications and fills nil lists, so overlays serialize alike
+func (o *SandboxOverlay) validate() error {
+	for _, list := range []*[]string{&o.ExcludedApplications, &o.ExcludedGroups, &o.ExcludedIncidents} {
+		*list = uniqueSorted(*list)
+	}
+	if o.Reclassifications == nil {
+		o.Reclassifications = []Reclassification{}
+	}
+	for _, r := range o.Reclassifications {
+		if !containsString(ReclassifiableFields, r.Field) {
+			return fmt.Errorf("%w: field %q cannot be reclassified", ErrInvalidSandbox, r.Field)
+		}
+		if (r.IncidentID == "") == (r.From == "") {
+			return fmt.Errorf("%w: a reclassification needs either incident_id or from", ErrInvalidSandbox)
+		}
+		if r.Field == "priority" && !containsString(models.ValidPriorities, r.To) {
+			return fmt.Errorf("%w: priority must be one of %s", ErrInvalidSandbox, strings.Join(models.ValidPriorities, ", "))
+		}
+	}
+	return nil
+}
+
+// Sandbox is a temporary analysis session. Analytics requests made with its token see the
+// incidents through its overlay, without anything stored being changed.
+type Sandbox struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name,omitempty"`
+	Overlay   SandboxOverlay `json:"overlay"`
+	CreatedBy string         `json:"created_by,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	ExpiresAt time.Time      `json:"expires_at"`
+}
+
+// sandboxKey is the context key of the sandbox a request is made in
+type sandboxKey struct{}
+
+// WithSandbox returns a context whose analytics see the incidents through the sandbox. A nil
+// sandbox leaves the context unchanged.
+func WithSandbox(ctx context.Context, sandbox *Sandbox) context.Context {
+	if sandbox == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, sandboxKey{}, sandbox)
+}
+
+// SandboxFromContext returns the sandbox a request is made in, or nil outside a sandbox
+func SandboxFromContext(ctx context.Context) *Sandbox {
+	sandbox, _ := ctx.Value(sandboxKey{}).(*Sandbox)
+	return sandbox
+}
+
+// cacheKeySuffix identifies the overlay in analytics cache keys. Sandboxes with the same
+// overlay share cached results, and changing an overlay stops its old results being read.
+func (s *Sandbox) cacheKeySuffix() string {
+	if s == nil {
+		return ""
+	}
+	data, _ := json.Marshal(s.Overlay)
+	sum := sha256.Sum256(data)
+	return "_sandbox:" + hex.EncodeToString(sum[:8])
+}
+
+// overlay returns a query reading the incidents of source, or of the live table when source is
+// empty, with the sandbox's exclusions and reclassifications applied. Outside a sandbox source
+// is returned unchanged. Values are inlined as literals, as the query becomes a common table
+// expression of queries whose parameters are numbered already.
+func (s *Sandbox) overlay(source string) string {
+	if s == nil {
+		return source
+	}
+	o := s.Overlay
+	if len(o.ExcludedApplications) == 0 && len(o.ExcludedGroups) == 0 && len(o.ExcludedIncidents) == 0 && len(o.Reclassifications) == 0 {
+		return source
+	}
+
+	from := "main.incidents"
+	if source != "" {
+		from = "(" + source + "\n\t\t)"
+	}
+
+	var replacements []string
+	for _, field := range ReclassifiableFields {
+		var cases []string
+		for _, r := range o.Reclassifications {
+			if r.Field != field {
+				continue
+			}
+			if r.IncidentID != "" {
+				cases = append(cases, fmt.Sprintf("WHEN incident_id = %s THEN %s", sqlString(r.IncidentID), sqlString(r.To)))
+			} else {
+				cases = append(cases, fmt.Sprintf("WHEN %s = %s THEN %s", field, sqlString(r.From), sqlString(r.To)))
+			}
+		}
+		if len(cases) > 0 {
+			replacements = append(replacements, fmt.Sprintf("CASE %s ELSE %s END AS %s", strings.Join(cases, " "), field, field))
+		}
+	}
+
+	query := "\n\t\t\tSELECT *"
+	if len(replacements) > 0 {
+		query += " REPLACE (" + strings.Join(replacements, ", ") + ")"
+	}
+	query += " FROM " + from + " WHERE 1=1"
+	for _, exclusion := range []struct {
+		column string
+		values []string
+	}{
+		{"application_name", o.ExcludedApplications},
+		{"resolution_group", o.ExcludedGroups},
+		{"incident_id", o.ExcludedIncidents},
+	} {
+		if len(exclusion.values) == 0 {
+			continue
+		}
+		literals := make([]string, len(exclusion.values))
+		for i, value := range exclusion.values {
+			literals[i] = sqlString(value)
+		}
+		query += fmt.Sprintf(" AND %s NOT IN (%s)", exclusion.column, strings.Join(literals, ", "))
+	}
+	return query
+}
+
+// SandboxService stores sandboxes until they expire
+type SandboxService struct {
+	db *sql.DB
+}
+
+// NewSandboxService creates a new SandboxService instance
+func NewSandboxService(db *sql.DB) *SandboxService {
+	return &SandboxService{db: db}
+}
+
+// CreateSandbox stores a new sandbox living for ttl, capped at MaxSandboxTTL, and returns it with
+// the token analytics requests are made in it with. Expired sandboxes are removed on the way.
+func (s *SandboxService) CreateSandbox(ctx context.Context, name string, overlay SandboxOverlay, ttl time.Duration, createdBy string) (*Sandbox, string, error) {
+	if err := overlay.validate(); err != nil {
+		return nil, "", err
+	}
+	now := time.Now().UTC()
+	sandbox := &Sandbox{
+		ID:        hashSessionToken(randomToken())[:16],
+		Name:      strings.TrimSpace(name),
+		Overlay:   overlay,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: now.Add(sandboxTTL(ttl)),
+	}
+	data, err := json.Marshal(sandbox.Overlay)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM analysis_sandboxes WHERE expires_at <= ?", now); err != nil {
+		return nil, "", fmt.Errorf("failed to remove expired sandboxes: %w", err)
+	}
+	token := randomToken()
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO analysis_sandboxes (token_hash, id, name, overlay, created_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, hashSessionToken(token), sandbox.ID, nullIfEmpty(sandbox.Name), string(data), nullIfEmpty(createdBy),
+		sandbox.CreatedAt, sandbox.ExpiresAt); err != nil {
+		return nil, "", fmt.Errorf("failed to save sandbox: %w", err)
+	}
+	return sandbox, token, nil
+}
+
+// GetSandbox returns the live sandbox with the given token, returning sql.ErrNoRows when it does
+// not exist or expired
+func (s *SandboxService) GetSandbox(ctx context.Context, token string) (*Sandbox, error) {
+	var sandbox Sandbox
+	var name, createdBy sql.NullString
+	var overlay string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, name, overlay, created_by, created_at, expires_at
+		FROM analysis_sandboxes
+		WHERE token_hash = ? AND expires_at > ?
+	`, hashSessionToken(token), time.Now().UTC()).Scan(&sandbox.ID, &name, &overlay, &createdBy, &sandbox.CreatedAt, &sandbox.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sandbox: %w", err)
+	}
+
+	sandbox.Name = name.String
+	sandbox.CreatedBy = createdBy.String
+	if err := json.Unmarshal([]byte(overlay), &sandbox.Overlay); err != nil {
+		return nil, fmt.Errorf("failed to decode sandbox overlay: %w", err)
+	}
+	return &sandbox, nil
+}
+
+// UpdateSandbox replaces the overlay of the sandbox with the given token and restarts its
+// lifetime, returning sql.ErrNoRows when it does not exist or expired
+func (s *SandboxService) UpdateSandbox(ctx context.Context, token string, overlay SandboxOverlay, ttl time.Duration) (*Sandbox, error) {
+	if err := overlay.validate(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(overlay)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE analysis_sandboxes SET overlay = ?, expires_at = ?
+		WHERE token_hash = ? AND expires_at > ?
+	`, string(data), now.Add(sandboxTTL(ttl)), hashSessionToken(token), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update sandbox: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return s.GetSandbox(ctx, token)
+}
+
+// DeleteSandbox discards the sandbox with the given token, returning sql.ErrNoRows when it does
+// not exist
+func (s *SandboxService) DeleteSandbox(ctx context.Context, token string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM analysis_sandboxes WHERE token_hash = ?", hashSessionToken(token))
+	if err != nil {
+		return fmt.Errorf("failed to delete sandbox: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// sandboxTTL applies the default and the cap to a requested sandbox lifetime
+func sandboxTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return DefaultSandboxTTL
+	}
+	if ttl > MaxSandboxTTL {
+		return MaxSandboxTTL
+	}
+	return ttl
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestSandboxes(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	sandboxes := NewSandboxService(db)
+	ctx := context.Background()
+
+	portal := diffTestIncident("i1", "upload-1", "INC001", "P1", "Open")
+	billing := diffTestIncident("i2", "upload-1", "INC002", "P2", "Open")
+	billing.ApplicationName = "Billing"
+	payroll := diffTestIncident("i3", "upload-1", "INC003", "P3", "Open")
+	payroll.ApplicationName = "Payroll"
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, []models.Incident{portal, billing, payroll}, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	invalid := []SandboxOverlay{
+		{Reclassifications: []Reclassification{{Field: "description", From: "x", To: "y"}}},
+		{Reclassifications: []Reclassification{{Field: "priority", From: "P1", To: "P9"}}},
+		{Reclassifications: []Reclassification{{Field: "priority", To: "P2"}}},
+	}
+	for _, overlay := range invalid {
+		if _, _, err := sandboxes.CreateSandbox(ctx, "", overlay, 0, "alice"); !errors.Is(err, ErrInvalidSandbox) {
+			t.Errorf("expected ErrInvalidSandbox for %+v, got %v", overlay, err)
+		}
+	}
+
+	created, token, err := sandboxes.CreateSandbox(ctx, "Without billing", SandboxOverlay{
+		ExcludedApplications: []string{"Billing"},
+		Reclassifications: []Reclassification{
+			{Field: "priority", From: "P3", To: "P1"},
+			{Field: "application_name", IncidentID: "INC001", To: "O'Brien Portal"},
+		},
+	}, 0, "alice")
+	if err != nil {
+		t.Fatalf("CreateSandbox() error = %v", err)
+	}
+	if token == "" || created.ExpiresAt.Sub(created.CreatedAt) != DefaultSandboxTTL {
+		t.Errorf("unexpected sandbox %+v", created)
+	}
+	sandbox, err := sandboxes.GetSandbox(ctx, token)
+	if err != nil {
+		t.Fatalf("GetSandbox() error = %v", err)
+	}
+	if sandbox.Name != "Without billing" || sandbox.CreatedBy != "alice" || len(sandbox.Overlay.Reclassifications) != 2 {
+		t.Errorf("unexpected stored sandbox %+v", sandbox)
+	}
+	if _, err := sandboxes.GetSandbox(ctx, "not-a-token"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for an unknown token, got %v", err)
+	}
+
+	service := NewAnalyticsService(db)
+	priorities := func(ctx context.Context) map[string]int {
+		t.Helper()
+		analysis, err := service.GetPriorityAnalysis(ctx, nil)
+		if err != nil {
+			t.Fatalf("GetPriorityAnalysis() error = %v", err)
+		}
+		counts := map[string]int{}
+		for _, priority := range analysis {
+			counts[priority.Priority] = priority.Count
+		}
+		return counts
+	}
+
+	sandboxCtx := WithSandbox(ctx, sandbox)
+	if got := priorities(sandboxCtx); len(got) != 1 || got["P1"] != 2 {
+		t.Errorf("expected Billing excluded and P3 reclassified as P1, got %v", got)
+	}
+	if got := priorities(ctx); len(got) != 3 {
+		t.Errorf("expected requests outside the sandbox to see the stored data, got %v", got)
+	}
+
+	apps, err := service.GetApplicationAnalysis(sandboxCtx, nil)
+	if err != nil {
+		t.Fatalf("GetApplicationAnalysis() error = %v", err)
+	}
+	names := map[string]bool{}
+	for _, app := range apps {
+		names[app.ApplicationName] = true
+	}
+	if len(names) != 2 || !names["O'Brien Portal"] || !names["Payroll"] {
+		t.Errorf("expected the single incident reclassification to apply, got %v", names)
+	}
+
+	// Cached results are kept apart per overlay
+	cached, err := NewCachedAnalyticsService(service, DefaultCacheConfig())
+	if err != nil {
+		t.Fatalf("NewCachedAnalyticsService() error = %v", err)
+	}
+	if _, err := cached.GetPriorityAnalysis(ctx, nil); err != nil {
+		t.Fatalf("GetPriorityAnalysis() error = %v", err)
+	}
+	analysis, err := cached.GetPriorityAnalysis(sandboxCtx, nil)
+	if err != nil {
+		t.Fatalf("GetPriorityAnalysis() error = %v", err)
+	}
+	if len(analysis) != 1 {
+		t.Errorf("expected the cached analysis to be sandboxed, got %+v", analysis)
+	}
+
+	// Updating replaces the overlay and restarts the lifetime
+	updated, err := sandboxes.UpdateSandbox(ctx, token, SandboxOverlay{ExcludedIncidents: []string{"INC002"}}, 2*MaxSandboxTTL)
+	if err != nil {
+		t.Fatalf("UpdateSandbox() error = %v", err)
+	}
+	if len(updated.Overlay.Reclassifications) != 0 || time.Until(updated.ExpiresAt) > MaxSandboxTTL {
+		t.Errorf("unexpected updated sandbox %+v", updated)
+	}
+	if got := priorities(WithSandbox(ctx, updated)); len(got) != 2 || got["P2"] != 0 {
+		t.Errorf("expected INC002 excluded, got %v", got)
+	}
+
+	// Expired sandboxes are gone
+	if _, err := db.Exec("UPDATE analysis_sandboxes SET expires_at = ?", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("Failed to expire sandbox: %v", err)
+	}
+	if _, err := sandboxes.GetSandbox(ctx, token); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for an expired sandbox, got %v", err)
+	}
+	if _, err := sandboxes.UpdateSandbox(ctx, token, SandboxOverlay{}, 0); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows updating an expired sandbox, got %v", err)
+	}
+
+	_, other, err := sandboxes.CreateSandbox(ctx, "", SandboxOverlay{}, time.Hour, "bob")
+	if err != nil {
+		t.Fatalf("CreateSandbox() error = %v", err)
+	}
+	if err := sandboxes.DeleteSandbox(ctx, token); err != sql.ErrNoRows {
+		t.Errorf("expected creating a sandbox to remove expired ones, got %v", err)
+	}
+	if err := sandboxes.DeleteSandbox(ctx, other); err != nil {
+		t.Fatalf("DeleteSandbox() error = %v", err)
+	}
+}
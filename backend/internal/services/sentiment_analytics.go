@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+// SentimentTimelinePoint holds the sentiment of incidents reported in one day or week
+type SentimentTimelinePoint struct {
+	Date          string  `json:"date"`
+	IncidentCount int     `json:"incident_count"`
+	ScoredCount   int     `json:"scored_count"`
+	AvgScore      float64 `json:"avg_score"`
+	PositiveCount int     `json:"positive_count"`
+	NeutralCount  int     `json:"neutral_count"`
+	NegativeCount int     `json:"negative_count"`
+	PositivePct   float64 `json:"positive_pct"`
+	NeutralPct    float64 `json:"neutral_pct"`
+	NegativePct   float64 `json:"negative_pct"`
+}
+
+// sentimentPeriodUnits maps a timeline period to its DATE_TRUNC unit
+var sentimentPeriodUnits = map[string]string{
+	"daily":  "day",
+	"weekly": "week",
+}
+
+// GetSentimentTimeline returns the average sentiment score and label distribution per day or week.
+// Percentages are shares of the incidents that have a sentiment label.
+func (s *AnalyticsService) GetSentimentTimeline(ctx context.Context, period string, filters *TimelineFilters) ([]SentimentTimelinePoint, error) {
+	unit, ok := sentimentPeriodUnits[period]
+	if !ok {
+		return nil, fmt.Errorf("invalid period: %s", period)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT 
+			DATE_TRUNC('%s', report_date) as period_start,
+			COUNT(*) as incident_count,
+			COUNT(sentiment_score) as scored_count,
+			AVG(sentiment_score) as avg_score,
+			COUNT(CASE WHEN sentiment_label = 'positive' THEN 1 END) as positive_count,
+			COUNT(CASE WHEN sentiment_label = 'neutral' THEN 1 END) as neutral_count,
+			COUNT(CASE WHEN sentiment_label = 'negative' THEN 1 END) as negative_count
+		FROM incidents 
+		WHERE 1=1`, unit)
+
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query += whereClause
+	query += " GROUP BY period_start ORDER BY period_start"
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sentiment timeline: %w", err)
+	}
+	defer rows.Close()
+
+	timeline := []SentimentTimelinePoint{}
+	for rows.Next() {
+		var data SentimentTimelinePoint
+		var periodStart time.Time
+		var avgScore sql.NullFloat64
+
+		err := rows.Scan(
+			&periodStart,
+			&data.IncidentCount,
+			&data.ScoredCount,
+			&avgScore,
+			&data.PositiveCount,
+			&data.NeutralCount,
+			&data.NegativeCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sentiment timeline row: %w", err)
+		}
+
+		data.Date = periodStart.Format("2006-01-02")
+		if avgScore.Valid {
+			data.AvgScore = avgScore.Float64
+		}
+		if labelled := data.PositiveCount + data.NeutralCount + data.NegativeCount; labelled > 0 {
+			data.PositivePct = float64(data.PositiveCount) * 100 / float64(labelled)
+			data.NeutralPct = float64(data.NeutralCount) * 100 / float64(labelled)
+			data.NegativePct = float64(data.NegativeCount) * 100 / float64(labelled)
+		}
+
+		timeline = append(timeline, data)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sentiment timeline rows: %w", err)
+	}
+
+	return timeline, nil
+}
+
+// SentimentLabelStats holds the resolution performance of incidents with one sentiment label
+type SentimentLabelStats struct {
+	SentimentLabel       string  `json:"sentiment_label"`
+	IncidentCount        int     `json:"incident_count"`
+	AvgScore             float64 `json:"avg_score"`
+	AvgResolutionTime    float64 `json:"avg_resolution_time"`
+	MedianResolutionTime float64 `json:"median_resolution_time"`
+	ReopenRate           float64 `json:"reopen_rate"`
+	P1Pct                float64 `json:"p1_pct"`
+	P2Pct                float64 `json:"p2_pct"`
+}
+
+// SentimentPriorityStats holds the sentiment of incidents with one priority
+type SentimentPriorityStats struct {
+	Priority      string  `json:"priority"`
+	IncidentCount int     `json:"incident_count"`
+	AvgScore      float64 `json:"avg_score"`
+	NegativePct   float64 `json:"negative_pct"`
+}
+
+// CorrelationCoefficient is a Pearson correlation between sentiment score and one measure.
+// Coefficient is nil when there are too few samples or one side has no variance.
+type CorrelationCoefficient struct {
+	Coefficient *float64 `json:"coefficient"`
+	SampleSize  int      `json:"sample_size"`
+	Strength    string   `json:"strength"`
+}
+
+// SentimentCorrelations relates sentiment score to resolution time, reopening and priority severity
+type SentimentCorrelations struct {
+	ResolutionTime CorrelationCoefficient `json:"resolution_time"`
+	Reopened       CorrelationCoefficient `json:"reopened"`
+	Severity       CorrelationCoefficient `json:"severity"`
+}
+
+// SentimentCorrelation reports how sentiment relates to resolution performance
+type SentimentCorrelation struct {
+	ByLabel      []SentimentLabelStats    `json:"by_label"`
+	ByPriority   []SentimentPriorityStats `json:"by_priority"`
+	Correlations SentimentCorrelations    `json:"correlations"`
+}
+
+// minCorrelationSamples is the fewest paired samples a coefficient is reported for
+const minCorrelationSamples = 3
+
+// severityExpression maps priority to a numeric severity, P1 = 4 down to P4 = 1
+const severityExpression = "CASE priority WHEN 'P1' THEN 4 WHEN 'P2' THEN 3 WHEN 'P3' THEN 2 WHEN 'P4' THEN 1 END"
+
+// GetSentimentCorrelation groups resolution performance by sentiment label and priority, and computes
+// Pearson coefficients between sentiment score and resolution time, reopening (0/1) and severity.
+// Since lower scores are more negative, a negative resolution time coefficient means negative-toned
+// tickets take longer.
+func (s *AnalyticsService) GetSentimentCorrelation(ctx context.Context, filters *TimelineFilters) (*SentimentCorrelation, error) {
+	byLabel, err := s.sentimentLabelStats(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	byPriority, err := s.sentimentPriorityStats(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	correlations, err := s.sentimentCorrelations(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SentimentCorrelation{
+		ByLabel:      byLabel,
+		ByPriority:   byPriority,
+		Correlations: *correlations,
+	}, nil
+}
+
+// sentimentLabelStats returns resolution performance per sentiment label
+func (s *AnalyticsService) sentimentLabelStats(ctx context.Context, filters *TimelineFilters) ([]SentimentLabelStats, error) {
+	query := fmt.Sprintf(`
+		SELECT 
+			sentiment_label,
+			COUNT(*) as incident_count,
+			AVG(sentiment_score) as avg_score,
+			AVG(resolution_time_hours) as avg_resolution_time,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY resolution_time_hours) as median_resolution_time,
+			COUNT(CASE WHEN %s THEN 1 END) * 100.0 / COUNT(*) as reopen_rate,
+			COUNT(CASE WHEN priority = 'P1' THEN 1 END) * 100.0 / COUNT(*) as p1_pct,
+			COUNT(CASE WHEN priority = 'P2' THEN 1 END) * 100.0 / COUNT(*) as p2_pct
+		FROM incidents 
+		WHERE sentiment_label IS NOT NULL AND sentiment_label <> ''`, reopenedCondition)
+
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query += whereClause
+	query += " GROUP BY sentiment_label ORDER BY avg_score"
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sentiment label stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []SentimentLabelStats{}
+	for rows.Next() {
+		var data SentimentLabelStats
+		var avgScore, avgResolutionTime, medianResolutionTime sql.NullFloat64
+
+		err := rows.Scan(
+			&data.SentimentLabel,
+			&data.IncidentCount,
+			&avgScore,
+			&avgResolutionTime,
+			&medianResolutionTime,
+			&data.ReopenRate,
+			&data.P1Pct,
+			&data.P2Pct,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sentiment label stats row: %w", err)
+		}
+
+		if avgScore.Valid {
+			data.AvgScore = avgScore.Float64
+		}
+		if avgResolutionTime.Valid {
+			data.AvgResolutionTime = avgResolutionTime.Float64
+		}
+		if medianResolutionTime.Valid {
+			data.MedianResolutionTime = medianResolutionTime.Float64
+		}
+
+		stats = append(stats, data)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sentiment label stats rows: %w", err)
+	}
+
+	return stats, nil
+}
+
+// sentimentPriorityStats returns sentiment per priority
+func (s *AnalyticsService) sentimentPriorityStats(ctx context.Context, filters *TimelineFilters) ([]SentimentPriorityStats, error) {
+	query := `
+		SELECT 
+			priority,
+			COUNT(*) as incident_count,
+			AVG(sentiment_score) as avg_score,
+			COUNT(CASE WHEN sentiment_label = 'negative' THEN 1 END) * 100.0 / COUNT(*) as negative_pct
+		FROM incidents 
+		WHERE 1=1`
+
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query += whereClause
+	query += " GROUP BY priority ORDER BY priority"
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sentiment priority stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []SentimentPriorityStats{}
+	for rows.Next() {
+		var data SentimentPriorityStats
+		var avgScore sql.NullFloat64
+
+		if err := rows.Scan(&data.Priority, &data.IncidentCount, &avgScore, &data.NegativePct); err != nil {
+			return nil, fmt.Errorf("failed to scan sentiment priority stats row: %w", err)
+		}
+		if avgScore.Valid {
+			data.AvgScore = avgScore.Float64
+		}
+
+		stats = append(stats, data)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sentiment priority stats rows: %w", err)
+	}
+
+	return stats, nil
+}
+
+// sentimentCorrelations computes the Pearson coefficients between sentiment score and each measure
+func (s *AnalyticsService) sentimentCorrelations(ctx context.Context, filters *TimelineFilters) (*SentimentCorrelations, error) {
+	query := fmt.Sprintf(`
+		SELECT 
+			CORR(sentiment_score, resolution_time_hours),
+			COUNT(CASE WHEN sentiment_score IS NOT NULL AND resolution_time_hours IS NOT NULL THEN 1 END),
+			CORR(sentiment_score, CASE WHEN %s THEN 1.0 ELSE 0.0 END),
+			COUNT(sentiment_score),
+			CORR(sentiment_score, %s),
+			COUNT(CASE WHEN sentiment_score IS NOT NULL AND priority IS NOT NULL THEN 1 END)
+		FROM incidents 
+		WHERE 1=1`, reopenedCondition, severityExpression)
+
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query += whereClause
+
+	var resolutionCorr, reopenedCorr, severityCorr sql.NullFloat64
+	var resolutionSamples, reopenedSamples, severitySamples int
+	err := s.db.QueryRowContext(ctx, s.federate(ctx, query, filters), args...).Scan(
+		&resolutionCorr, &resolutionSamples,
+		&reopenedCorr, &reopenedSamples,
+		&severityCorr, &severitySamples,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sentiment correlations: %w", err)
+	}
+
+	return &SentimentCorrelations{
+		ResolutionTime: newCorrelationCoefficient(resolutionCorr, resolutionSamples),
+		Reopened:       newCorrelationCoefficient(reopenedCorr, reopenedSamples),
+		Severity:       newCorrelationCoefficient(severityCorr, severitySamples),
+	}, nil
+}
+
+// newCorrelationCoefficient wraps a coefficient, dropping it when it is undefined or under-sampled
+func newCorrelationCoefficient(value sql.NullFloat64, samples int) CorrelationCoefficient {
+	result := CorrelationCoefficient{SampleSize: samples, Strength: "insufficient_data"}
+	if !value.Valid || samples < minCorrelationSamples || math.IsNaN(value.Float64) {
+		return result
+	}
+
+	coefficient := value.Float64
+	result.Coefficient = &coefficient
+	result.Strength = CorrelationStrength(coefficient)
+	return result
+}
+
+// CorrelationStrength describes the magnitude of a correlation coefficient
+func CorrelationStrength(coefficient float64) string {
+	switch magnitude := math.Abs(coefficient); {
+	case magnitude < 0.1:
+		return "negligible"
+	case magnitude < 0.3:
+		return "weak"
+	case magnitude < 0.5:
+		return "moderate"
+	default:
+		return "strong"
+	}
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+// newSentimentTestDB creates an in-memory database seeded with scored incidents
+func newSentimentTestDB(t *testing.T, incidents []models.Incident) (*database.DB, *sql.DB) {
+	t.Helper()
+
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		dbWrapper.Close()
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	if _, err := NewIncidentService(db).BatchInsertIncidents(context.Background(), incidents, "upload-1"); err != nil {
+		dbWrapper.Close()
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	return dbWrapper, db
+}
+
+// sentimentTestIncident builds an incident with the given sentiment on day offset days after 2024-01-15
+func sentimentTestIncident(id string, offset int, label string, score float64) models.Incident {
+	incident := diffTestIncident(id, "upload-1", "INC-"+id, "P3", "Closed")
+	incident.ReportDate = time.Date(2024, 1, 15+offset, 0, 0, 0, 0, time.UTC)
+	incident.SentimentLabel = label
+	incident.SentimentScore = &score
+	return incident
+}
+
+func TestAnalyticsService_GetSentimentTimeline(t *testing.T) {
+	dbWrapper, db := newSentimentTestDB(t, []models.Incident{
+		sentimentTestIncident("1", 0, models.SentimentNegative, -0.6),
+		sentimentTestIncident("2", 0, models.SentimentNeutral, 0),
+		sentimentTestIncident("3", 1, models.SentimentPositive, 0.4),
+		sentimentTestIncident("4", 7, models.SentimentPositive, 0.8),
+	})
+	defer dbWrapper.Close()
+
+	service := NewAnalyticsService(db)
+	ctx := context.Background()
+
+	daily, err := service.GetSentimentTimeline(ctx, "daily", nil)
+	if err != nil {
+		t.Fatalf("Failed to get daily sentiment timeline: %v", err)
+	}
+	if len(daily) != 3 {
+		t.Fatalf("Expected 3 days, got %+v", daily)
+	}
+	first := daily[0]
+	if first.Date != "2024-01-15" || first.IncidentCount != 2 || first.NegativeCount != 1 || first.NegativePct != 50 {
+		t.Errorf("Unexpected first day: %+v", first)
+	}
+	// sentiment_score is stored as a 32-bit FLOAT
+	if math.Abs(first.AvgScore+0.3) > 1e-6 {
+		t.Errorf("Expected average score -0.3, got %v", first.AvgScore)
+	}
+
+	weekly, err := service.GetSentimentTimeline(ctx, "weekly", nil)
+	if err != nil {
+		t.Fatalf("Failed to get weekly sentiment timeline: %v", err)
+	}
+	if len(weekly) != 2 || weekly[0].IncidentCount != 3 || weekly[1].PositivePct != 100 {
+		t.Errorf("Unexpected weekly timeline: %+v", weekly)
+	}
+
+	if _, err := service.GetSentimentTimeline(ctx, "monthly", nil); err == nil {
+		t.Error("Expected error for invalid period")
+	}
+}
+
+func TestAnalyticsService_GetSentimentCorrelation(t *testing.T) {
+	hours := func(h int) *int { return &h }
+	reresolved := time.Date(2024, 1, 25, 0, 0, 0, 0, time.UTC)
+
+	incidents := []models.Incident{
+		sentimentTestIncident("1", 0, models.SentimentNegative, -0.8),
+		sentimentTestIncident("2", 0, models.SentimentNegative, -0.4),
+		sentimentTestIncident("3", 1, models.SentimentNeutral, 0),
+		sentimentTestIncident("4", 1, models.SentimentPositive, 0.5),
+	}
+	// Resolution time falls as sentiment improves
+	for i, h := range []int{40, 30, 10, 5} {
+		incidents[i].ResolutionTimeHours = hours(h)
+		resolved := incidents[i].ReportDate.AddDate(0, 0, 1)
+		incidents[i].ResolveDate = &resolved
+	}
+	incidents[0].Priority = "P1"
+	incidents[0].LastResolveDate = &reresolved
+
+	dbWrapper, db := newSentimentTestDB(t, incidents)
+	defer dbWrapper.Close()
+
+	correlation, err := NewAnalyticsService(db).GetSentimentCorrelation(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to get sentiment correlation: %v", err)
+	}
+
+	if len(correlation.ByLabel) != 3 {
+		t.Fatalf("Expected 3 sentiment labels, got %+v", correlation.ByLabel)
+	}
+	negative := correlation.ByLabel[0]
+	if negative.SentimentLabel != models.SentimentNegative || negative.AvgResolutionTime != 35 {
+		t.Errorf("Unexpected negative label stats: %+v", negative)
+	}
+	if negative.ReopenRate != 50 || negative.P1Pct != 50 {
+		t.Errorf("Unexpected negative reopen/P1 rates: %+v", negative)
+	}
+
+	if len(correlation.ByPriority) != 2 || correlation.ByPriority[0].Priority != "P1" || correlation.ByPriority[0].NegativePct != 100 {
+		t.Errorf("Unexpected priority stats: %+v", correlation.ByPriority)
+	}
+
+	resolution := correlation.Correlations.ResolutionTime
+	if resolution.Coefficient == nil || *resolution.Coefficient > -0.9 || resolution.Strength != "strong" {
+		t.Errorf("Expected strong negative resolution time correlation, got %+v", resolution)
+	}
+	if resolution.SampleSize != 4 {
+		t.Errorf("Expected 4 samples, got %d", resolution.SampleSize)
+	}
+}
+
+func TestCorrelationStrength(t *testing.T) {
+	tests := map[float64]string{
+		0.05:  "negligible",
+		-0.2:  "weak",
+		0.45:  "moderate",
+		-0.75: "strong",
+	}
+
+	for coefficient, expected := range tests {
+		if got := CorrelationStrength(coefficient); got != expected {
+			t.Errorf("CorrelationStrength(%v) = %s, expected %s", coefficient, got, expected)
+		}
+	}
+
+	if c := newCorrelationCoefficient(sql.NullFloat64{Float64: 0.9, Valid: true}, 2); c.Coefficient != nil || c.Strength != "insufficient_data" {
+		t.Errorf("Expected under-sampled coefficient to be dropped, got %+v", c)
+	}
+}
@@ -1,19 +1,31 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 
 	"incident-management-system/internal/models"
 )
 
+// SimpleSentimentAnalyzerVersion identifies the current rule set; bump it when scoring changes
+const SimpleSentimentAnalyzerVersion = "simple-v2"
+
 // SimpleSentimentAnalyzer implements basic sentiment analysis
 type SimpleSentimentAnalyzer struct {
 	positiveWords map[string]float64
 	negativeWords map[string]float64
 	intensifiers  map[string]float64
 	negators      map[string]bool
+	emoji         map[string]float64
+
+	// phrases are keyed by their space-joined tokens and matched before single words
+	mu           sync.RWMutex
+	phrases      map[string]float64
+	maxPhraseLen int
 }
 
 // NewSimpleSentimentAnalyzer creates a new simple sentiment analyzer
@@ -23,6 +35,8 @@ func NewSimpleSentimentAnalyzer() *SimpleSentimentAnalyzer {
 		negativeWords: make(map[string]float64),
 		intensifiers:  make(map[string]float64),
 		negators:      make(map[string]bool),
+		emoji:         make(map[string]float64),
+		phrases:       make(map[string]float64),
 	}
 
 	analyzer.initializeWordLists()
@@ -155,10 +169,70 @@ func (s *SimpleSentimentAnalyzer) initializeWordLists() {
 		"hadn't":  true,
 	}
 
+	// Multi-word and domain phrases. Reports that nothing is wrong are status updates rather
+	// than praise, so they are neutral instead of flipping a negative word into a positive one.
+	phrases := map[string]float64{
+		"not working":        -0.7,
+		"still not working":  -0.8,
+		"not resolved":       -0.7,
+		"not fixed":          -0.7,
+		"not responding":     -0.7,
+		"still failing":      -0.8,
+		"keeps crashing":     -0.9,
+		"data loss":          -0.9,
+		"security breach":    -0.9,
+		"no issue":           0.0,
+		"no issues":          0.0,
+		"no problem":         0.0,
+		"no problems":        0.0,
+		"no errors":          0.0,
+		"false alarm":        0.3,
+		"false positive":     0.3,
+		"working as expected": 0.7,
+		"works as expected":  0.7,
+		"back to normal":     0.7,
+		"up and running":     0.7,
+		"service restored":   0.8,
+	}
+
+	// Emoji, matched in the raw text since tokenization strips them
+	emoji := map[string]float64{
+		"👍": 0.6,
+		"👌": 0.6,
+		"✅": 0.6,
+		"✔": 0.5,
+		"🎉": 0.7,
+		"😀": 0.6,
+		"😃": 0.6,
+		"😊": 0.6,
+		"🙂": 0.4,
+		"😍": 0.7,
+		"❤": 0.6,
+		"🙏": 0.4,
+		"👎": -0.6,
+		"❌": -0.5,
+		"🔥": -0.5,
+		"💥": -0.7,
+		"🚨": -0.7,
+		"⚠": -0.4,
+		"🐛": -0.5,
+		"🙁": -0.4,
+		"☹": -0.5,
+		"😞": -0.6,
+		"😢": -0.6,
+		"😭": -0.7,
+		"😤": -0.6,
+		"😠": -0.7,
+		"😡": -0.8,
+		"🤬": -0.9,
+	}
+
 	s.positiveWords = positiveWords
 	s.negativeWords = negativeWords
 	s.intensifiers = intensifiers
 	s.negators = negators
+	s.emoji = emoji
+	s.AddPhrases(phrases)
 }
 
 // AnalyzeSentiment analyzes the sentiment of a given text
@@ -172,7 +246,8 @@ func (s *SimpleSentimentAnalyzer) AnalyzeSentiment(text string) (*SentimentResul
 
 	// Normalize and tokenize text
 	tokens := s.tokenize(text)
-	if len(tokens) == 0 {
+	emojiScore, emojiCount := s.calculateEmojiScore(text)
+	if len(tokens) == 0 && emojiCount == 0 {
 		return &SentimentResult{
 			Score: 0.0,
 			Label: models.SentimentNeutral,
@@ -180,10 +255,10 @@ func (s *SimpleSentimentAnalyzer) AnalyzeSentiment(text string) (*SentimentResul
 	}
 
 	// Calculate sentiment score
-	score := s.calculateSentimentScore(tokens)
+	score := s.calculateSentimentScore(tokens) + emojiScore
 
 	// Normalize score to [-1, 1] range
-	normalizedScore := s.normalizeScore(score, len(tokens))
+	normalizedScore := s.normalizeScore(score, len(tokens)+emojiCount)
 
 	// Determine sentiment label
 	label := s.scoreToLabel(normalizedScore)
@@ -239,7 +314,26 @@ func (s *SimpleSentimentAnalyzer) calculateSentimentScore(tokens []string) float
 	var intensifier float64 = 1.0
 	var negated bool = false
 	
-	for i, token := range tokens {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		// Check for phrases first, so "not working" is scored as a whole rather than negator + word
+		if phraseScore, length, isPhrase := s.matchPhrase(tokens, i); isPhrase {
+			phraseScore *= intensifier
+			if negated {
+				phraseScore *= -1
+			}
+			totalScore += phraseScore
+
+			intensifier = 1.0
+			negated = false
+			i += length - 1
+			continue
+		}
+
 		// Check for intensifiers
 		if intensity, isIntensifier := s.intensifiers[token]; isIntensifier {
 			intensifier = intensity
@@ -290,6 +384,33 @@ func (s *SimpleSentimentAnalyzer) calculateSentimentScore(tokens []string) float
 	return totalScore
 }
 
+// matchPhrase returns the weight and token length of the longest phrase starting at tokens[start].
+// Callers must hold s.mu.
+func (s *SimpleSentimentAnalyzer) matchPhrase(tokens []string, start int) (float64, int, bool) {
+	for length := s.maxPhraseLen; length >= 2; length-- {
+		if start+length > len(tokens) {
+			continue
+		}
+		if weight, ok := s.phrases[strings.Join(tokens[start:start+length], " ")]; ok {
+			return weight, length, true
+		}
+	}
+	return 0, 0, false
+}
+
+// calculateEmojiScore sums the weights of known emoji in the raw text and returns how many were found
+func (s *SimpleSentimentAnalyzer) calculateEmojiScore(text string) (float64, int) {
+	var score float64
+	var count int
+	for symbol, weight := range s.emoji {
+		if n := strings.Count(text, symbol); n > 0 {
+			score += weight * float64(n)
+			count += n
+		}
+	}
+	return score, count
+}
+
 // normalizeScore normalizes the sentiment score to [-1, 1] range
 func (s *SimpleSentimentAnalyzer) normalizeScore(score float64, tokenCount int) float64 {
 	if tokenCount == 0 {
@@ -322,6 +443,11 @@ func (s *SimpleSentimentAnalyzer) scoreToLabel(score float64) string {
 	}
 }
 
+// Version returns the version of the sentiment rules
+func (s *SimpleSentimentAnalyzer) Version() string {
+	return SimpleSentimentAnalyzerVersion
+}
+
 // GetSentimentStats returns statistics about the sentiment analysis
 func (s *SimpleSentimentAnalyzer) GetSentimentStats() map[string]interface{} {
 	return map[string]interface{}{
@@ -329,7 +455,10 @@ func (s *SimpleSentimentAnalyzer) GetSentimentStats() map[string]interface{} {
 		"negative_words_count": len(s.negativeWords),
 		"intensifiers_count":   len(s.intensifiers),
 		"negators_count":       len(s.negators),
+		"phrases_count":        s.phraseCount(),
+		"emoji_count":          len(s.emoji),
 		"analyzer_type":        "simple_rule_based",
+		"analyzer_version":     SimpleSentimentAnalyzerVersion,
 	}
 }
 
@@ -344,6 +473,56 @@ func (s *SimpleSentimentAnalyzer) AddCustomWords(positive, negative map[string]f
 	}
 }
 
+// AddPhrases adds or overrides multi-word phrases. Phrases are tokenized the same way as the
+// analyzed text, so punctuation and single-character words are ignored when matching.
+func (s *SimpleSentimentAnalyzer) AddPhrases(phrases map[string]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for phrase, weight := range phrases {
+		tokens := s.tokenize(phrase)
+		if len(tokens) < 2 {
+			continue
+		}
+		s.phrases[strings.Join(tokens, " ")] = weight
+		if len(tokens) > s.maxPhraseLen {
+			s.maxPhraseLen = len(tokens)
+		}
+	}
+}
+
+// LoadPhrases merges the configured phrase lexicon from the sentiment_phrases table
+func (s *SimpleSentimentAnalyzer) LoadPhrases(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, "SELECT phrase, weight FROM sentiment_phrases")
+	if err != nil {
+		return fmt.Errorf("failed to query sentiment phrases: %w", err)
+	}
+	defer rows.Close()
+
+	phrases := make(map[string]float64)
+	for rows.Next() {
+		var phrase string
+		var weight float64
+		if err := rows.Scan(&phrase, &weight); err != nil {
+			return fmt.Errorf("failed to scan sentiment phrase: %w", err)
+		}
+		phrases[phrase] = weight
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating sentiment phrases: %w", err)
+	}
+
+	s.AddPhrases(phrases)
+	return nil
+}
+
+// phraseCount returns the number of phrases in the lexicon
+func (s *SimpleSentimentAnalyzer) phraseCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.phrases)
+}
+
 // ValidateScore ensures sentiment scores are within valid range
 func ValidateSentimentScore(score float64) error {
 	if score < -1.0 || score > 1.0 {
@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"testing"
 
+	"incident-management-system/internal/database"
 	"incident-management-system/internal/models"
 )
 
@@ -273,6 +275,134 @@ func TestSimpleSentimentAnalyzer_AddCustomWords(t *testing.T) {
 	}
 }
 
+func TestSimpleSentimentAnalyzer_PhrasesAndEmoji(t *testing.T) {
+	analyzer := NewSimpleSentimentAnalyzer()
+
+	tests := []struct {
+		name          string
+		text          string
+		expectedLabel string
+	}{
+		{
+			name:          "negated positive phrase",
+			text:          "VPN is not working for remote users",
+			expectedLabel: models.SentimentNegative,
+		},
+		{
+			name:          "persistent failure phrase",
+			text:          "Batch job still not working after restart",
+			expectedLabel: models.SentimentNegative,
+		},
+		{
+			name:          "no issues is neutral rather than praise",
+			text:          "Checked the server, no issues found",
+			expectedLabel: models.SentimentNeutral,
+		},
+		{
+			name:          "singular no issue is neutral",
+			text:          "Monitoring shows no issue",
+			expectedLabel: models.SentimentNeutral,
+		},
+		{
+			name:          "domain phrase",
+			text:          "Alert turned out to be a false alarm",
+			expectedLabel: models.SentimentPositive,
+		},
+		{
+			name:          "negated domain phrase",
+			text:          "This was not a false alarm",
+			expectedLabel: models.SentimentNegative,
+		},
+		{
+			name:          "negation with intensifier",
+			text:          "Response is not very good",
+			expectedLabel: models.SentimentNegative,
+		},
+		{
+			name:          "negated negative word",
+			text:          "Nightly sync never failed after the patch",
+			expectedLabel: models.SentimentPositive,
+		},
+		{
+			name:          "emoji only",
+			text:          "👍",
+			expectedLabel: models.SentimentPositive,
+		},
+		{
+			name:          "negative emoji",
+			text:          "Login page again 😡😡",
+			expectedLabel: models.SentimentNegative,
+		},
+		{
+			name:          "emoji with variation selector",
+			text:          "Disk usage ⚠️ on db01",
+			expectedLabel: models.SentimentNegative,
+		},
+		{
+			name:          "phrase with punctuation",
+			text:          "Payments are up-and-running again",
+			expectedLabel: models.SentimentPositive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := analyzer.AnalyzeSentiment(tt.text)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Label != tt.expectedLabel {
+				t.Errorf("expected label %s, got %s (score: %.3f)",
+					tt.expectedLabel, result.Label, result.Score)
+			}
+
+			if err := ValidateSentimentScore(result.Score); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestSimpleSentimentAnalyzer_LoadPhrases(t *testing.T) {
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+	db := dbWrapper.GetConnection()
+
+	if _, err := db.Exec("INSERT INTO sentiment_phrases (phrase, weight) VALUES ('change freeze', -0.6), ('false alarm', -0.2)"); err != nil {
+		t.Fatalf("Failed to insert phrases: %v", err)
+	}
+
+	analyzer := NewSimpleSentimentAnalyzer()
+	before := analyzer.GetSentimentStats()["phrases_count"].(int)
+
+	if err := analyzer.LoadPhrases(context.Background(), db); err != nil {
+		t.Fatalf("Failed to load phrases: %v", err)
+	}
+
+	// One new phrase, one override of a default
+	if after := analyzer.GetSentimentStats()["phrases_count"].(int); after != before+1 {
+		t.Errorf("expected %d phrases after loading, got %d", before+1, after)
+	}
+
+	result, _ := analyzer.AnalyzeSentiment("Deployment blocked by change freeze")
+	if result.Label != models.SentimentNegative {
+		t.Errorf("expected configured phrase to score negative, got %s (score: %.3f)", result.Label, result.Score)
+	}
+
+	result, _ = analyzer.AnalyzeSentiment("Alert was a false alarm")
+	if result.Label != models.SentimentNegative {
+		t.Errorf("expected configured weight to override default, got %s (score: %.3f)", result.Label, result.Score)
+	}
+}
+
 func TestBatchProcessIncidents(t *testing.T) {
 	analyzer := NewSimpleSentimentAnalyzer()
 
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"incident-management-system/internal/models"
+)
+
+// Service catalog rollup levels, from most to least specific
+const (
+	ServiceLevelService      = "service"
+	ServiceLevelBusinessUnit = "business_unit"
+)
+
+// ErrInvalidService is returned when a business service cannot be saved to the catalog
+var ErrInvalidService = errors.New("invalid business service")
+
+// BusinessService is a service catalog entry: a business service, the applications that own it
+// and the business unit it belongs to. Incidents naming the service or one of its aliases are
+// recorded under its name.
+type BusinessService struct {
+	ServiceKey   string    `json:"service_key"`
+	Name         string    `json:"name"`
+	BusinessUnit string    `json:"business_unit"`
+	Applications []string  `json:"applications"`
+	Aliases      []string  `json:"aliases"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ServiceAnalysis represents incident metrics rolled up to one business service or business unit
+type ServiceAnalysis struct {
+	Level                string   `json:"level"`
+	Name                 string   `json:"name"`
+	BusinessUnit         string   `json:"business_unit,omitempty"`
+	Cataloged            bool     `json:"cataloged"`
+	Applications         []string `json:"applications,omitempty"`
+	ServiceCount         int      `json:"service_count"`
+	IncidentCount        int      `json:"incident_count"`
+	ResolvedIncidents    int      `json:"resolved_incidents"`
+	ResolutionRate       float64  `json:"resolution_rate"`
+	AvgResolutionTime    float64  `json:"avg_resolution_time"`
+	MedianResolutionTime float64  `json:"median_resolution_time"`
+	P1Count              int      `json:"p1_count"`
+	P2Count              int      `json:"p2_count"`
+}
+
+// serviceLevelColumns maps each rollup level to its unit, business unit and cataloged expressions.
+// Incidents without a business service are reported under "Unassigned", as are the services
+// outside the catalog at the business unit level.
+var serviceLevelColumns = map[string]struct{ unit, businessUnit, cataloged string }{
+	ServiceLevelService: {
+		"COALESCE(NULLIF(i.business_service, ''), '" + UnassignedOrgUnit + "')",
+		"COALESCE(MIN(c.business_unit), '" + UnassignedOrgUnit + "')",
+		"COUNT(c.service_key) > 0",
+	},
+	ServiceLevelBusinessUnit: {
+		"COALESCE(c.business_unit, '" + UnassignedOrgUnit + "')",
+		"''",
+		"COUNT(c.service_key) > 0",
+	},
+}
+
+// IsValidServiceLevel reports whether level is a known service catalog rollup level
+func IsValidServiceLevel(level string) bool {
+	_, ok := serviceLevelColumns[level]
+	return ok
+}
+
+// ServiceCatalog manages the service → application → business unit registry and resolves the
+// free-text business service of incidents to catalog names
+type ServiceCatalog struct {
+	db       *sql.DB
+	mu       sync.RWMutex
+	services map[string]string   // service or alias key -> service name
+	owners   map[string][]string // application key -> names of the services it owns
+}
+
+// NewServiceCatalog creates a new ServiceCatalog instance
+func NewServiceCatalog(db *sql.DB) *ServiceCatalog {
+	return &ServiceCatalog{
+		db:       db,
+		services: make(map[string]string),
+		owners:   make(map[string][]string),
+	}
+}
+
+// LoadServices refreshes the in-memory catalog rules from the database
+func (c *ServiceCatalog) LoadServices(ctx context.Context) error {
+	services, err := c.ListServices(ctx)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]string)
+	owners := make(map[string][]string)
+	for _, service := range services {
+		names[service.ServiceKey] = service.Name
+		for _, alias := range service.Aliases {
+			names[ApplicationAliasKey(alias)] = service.Name
+		}
+		for _, application := range service.Applications {
+			key := ApplicationAliasKey(application)
+			owners[key] = append(owners[key], service.Name)
+		}
+	}
+
+	c.mu.Lock()
+	c.services = names
+	c.owners = owners
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Normalize returns the catalog name for a raw business service, matched on its name or one of
+// its aliases. An incident without a business service is attributed to the service its
+// application owns, when exactly one does. Otherwise the trimmed raw value is returned.
+func (c *ServiceCatalog) Normalize(raw, application string) string {
+	trimmed := strings.TrimSpace(raw)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if trimmed == "" {
+		if owners := c.owners[ApplicationAliasKey(application)]; len(owners) == 1 {
+			return owners[0]
+		}
+		return ""
+	}
+	if name, ok := c.services[ApplicationAliasKey(trimmed)]; ok {
+		return name
+	}
+	return trimmed
+}
+
+// NormalizeIncidents applies the catalog to incidents, keeping the original value in
+// BusinessServiceRaw. It runs after application names are normalized, so applications are
+// matched on their canonical names.
+func (c *ServiceCatalog) NormalizeIncidents(incidents []models.Incident) {
+	for i := range incidents {
+		if incidents[i].BusinessServiceRaw == "" {
+			incidents[i].BusinessServiceRaw = incidents[i].BusinessService
+		}
+		incidents[i].BusinessService = c.Normalize(incidents[i].BusinessServiceRaw, incidents[i].ApplicationName)
+	}
+}
+
+// ListServices returns the catalog ordered by business unit and service name
+func (c *ServiceCatalog) ListServices(ctx context.Context) ([]BusinessService, error) {
+	return listBusinessServices(ctx, c.db)
+}
+
+// SaveService creates or replaces the catalog entry for a business service. Neither its name nor
+// its aliases may match the name or an alias of another service.
+func (c *ServiceCatalog) SaveService(ctx context.Context, name, businessUnit string, applications, aliases []string) (*BusinessService, error) {
+	service := &BusinessService{
+		ServiceKey:   ApplicationAliasKey(name),
+		Name:         strings.TrimSpace(name),
+		BusinessUnit: strings.TrimSpace(businessUnit),
+		Applications: uniqueSorted(applications),
+		Aliases:      uniqueSorted(aliases),
+		UpdatedAt:    time.Now(),
+	}
+	if service.ServiceKey == "" {
+		return nil, fmt.Errorf("%w: name must contain letters or digits", ErrInvalidService)
+	}
+	if service.BusinessUnit == "" {
+		return nil, fmt.Errorf("%w: business unit is required", ErrInvalidService)
+	}
+
+	existing, err := c.ListServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]bool{service.ServiceKey: true}
+	for _, alias := range service.Aliases {
+		key := ApplicationAliasKey(alias)
+		if key == "" {
+			return nil, fmt.Errorf("%w: alias %q must contain letters or digits", ErrInvalidService, alias)
+		}
+		keys[key] = true
+	}
+	for _, other := range existing {
+		if other.ServiceKey == service.ServiceKey {
+			continue
+		}
+		for _, taken := range append([]string{other.Name}, other.Aliases...) {
+			if keys[ApplicationAliasKey(taken)] {
+				return nil, fmt.Errorf("%w: %q already names service %s", ErrInvalidService, taken, other.Name)
+			}
+		}
+	}
+
+	applicationsJSON, err := json.Marshal(service.Applications)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode service applications: %w", err)
+	}
+	aliasesJSON, err := json.Marshal(service.Aliases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode service aliases: %w", err)
+	}
+
+	query := `
+		INSERT OR REPLACE INTO service_catalog (service_key, name, business_unit, applications, aliases, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	if _, err := c.db.ExecContext(ctx, query, service.ServiceKey, service.Name, service.BusinessUnit,
+		string(applicationsJSON), string(aliasesJSON), service.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save business service: %w", err)
+	}
+
+	if err := c.LoadServices(ctx); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// DeleteService removes a business service from the catalog, returning sql.ErrNoRows when it does
+// not exist. Incidents keep the service name they were recorded with.
+func (c *ServiceCatalog) DeleteService(ctx context.Context, name string) error {
+	result, err := c.db.ExecContext(ctx, "DELETE FROM service_catalog WHERE service_key = ?", ApplicationAliasKey(name))
+	if err != nil {
+		return fmt.Errorf("failed to delete business service: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return c.LoadServices(ctx)
+}
+
+// listBusinessServices reads the service catalog ordered by business unit and service name
+func listBusinessServices(ctx context.Context, db *sql.DB) ([]BusinessService, error) {
+	query := `
+		SELECT service_key, name, business_unit, applications, aliases, updated_at
+		FROM service_catalog
+		ORDER BY business_unit, name
+	`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query service catalog: %w", err)
+	}
+	defer rows.Close()
+
+	services := []BusinessService{}
+	for rows.Next() {
+		var service BusinessService
+		var applicationsJSON, aliasesJSON string
+		if err := rows.Scan(&service.ServiceKey, &service.Name, &service.BusinessUnit, &applicationsJSON,
+			&aliasesJSON, &service.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan business service: %w", err)
+		}
+		if err := json.Unmarshal([]byte(applicationsJSON), &service.Applications); err != nil {
+			return nil, fmt.Errorf("failed to decode applications of service %s: %w", service.Name, err)
+		}
+		if err := json.Unmarshal([]byte(aliasesJSON), &service.Aliases); err != nil {
+			return nil, fmt.Errorf("failed to decode aliases of service %s: %w", service.Name, err)
+		}
+		services = append(services, service)
+	}
+
+	return services, rows.Err()
+}
+
+// GetServiceAnalysis rolls incident metrics up to business services or business units. Business
+// services are matched to the catalog by name; incidents without one, and at the business unit
+// level services outside the catalog, are reported under "Unassigned". When unit is set only that
+// unit is returned.
+func (s *AnalyticsService) GetServiceAnalysis(ctx context.Context, level, unit string, filters *TimelineFilters) ([]ServiceAnalysis, error) {
+	columns, ok := serviceLevelColumns[level]
+	if !ok {
+		return nil, fmt.Errorf("invalid service level: %s", level)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			%s as unit,
+			%s as business_unit,
+			%s as cataloged,
+			COUNT(DISTINCT NULLIF(i.business_service, '')) as service_count,
+			COUNT(*) as incident_count,
+			COUNT(CASE WHEN i.resolve_date IS NOT NULL THEN 1 END) as resolved_incidents,
+			AVG(COALESCE(i.net_resolution_time_hours, i.resolution_time_hours)) as avg_resolution_time,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY COALESCE(i.net_resolution_time_hours, i.resolution_time_hours)) as median_resolution_time,
+			COUNT(CASE WHEN i.priority = 'P1' THEN 1 END) as p1_count,
+			COUNT(CASE WHEN i.priority = 'P2' THEN 1 END) as p2_count
+		FROM incidents i
+		LEFT JOIN service_catalog c ON c.name = i.business_service
+		WHERE 1=1`, columns.unit, columns.businessUnit, columns.cataloged)
+
+	whereClause, args, nextIdx := buildFilterConditions(ctx, filters, 1)
+	query += whereClause
+	if unit != "" {
+		query += fmt.Sprintf(" AND %s = $%d", columns.unit, nextIdx)
+		args = append(args, unit)
+	}
+	query += " GROUP BY unit ORDER BY incident_count DESC, unit"
+	query += filters.limitClause()
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query service analysis: %w", err)
+	}
+	defer rows.Close()
+
+	analysis := []ServiceAnalysis{}
+	for rows.Next() {
+		data := ServiceAnalysis{Level: level}
+		var avgResolutionTime, medianResolutionTime sql.NullFloat64
+
+		err := rows.Scan(
+			&data.Name,
+			&data.BusinessUnit,
+			&data.Cataloged,
+			&data.ServiceCount,
+			&data.IncidentCount,
+			&data.ResolvedIncidents,
+			&avgResolutionTime,
+			&medianResolutionTime,
+			&data.P1Count,
+			&data.P2Count,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan service analysis row: %w", err)
+		}
+
+		if avgResolutionTime.Valid {
+			data.AvgResolutionTime = avgResolutionTime.Float64
+		}
+		if medianResolutionTime.Valid {
+			data.MedianResolutionTime = medianResolutionTime.Float64
+		}
+		if data.IncidentCount > 0 {
+			data.ResolutionRate = float64(data.ResolvedIncidents) / float64(data.IncidentCount) * 100
+		}
+
+		analysis = append(analysis, data)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating service analysis rows: %w", err)
+	}
+
+	return analysis, s.attachServiceApplications(ctx, level, analysis)
+}
+
+// attachServiceApplications lists the owning applications of each cataloged unit: those of the
+// service, or of every service in the business unit
+func (s *AnalyticsService) attachServiceApplications(ctx context.Context, level string, analysis []ServiceAnalysis) error {
+	services, err := listBusinessServices(ctx, s.db)
+	if err != nil {
+		return err
+	}
+
+	applications := make(map[string][]string)
+	for _, service := range services {
+		unit := service.Name
+		if level == ServiceLevelBusinessUnit {
+			unit = service.BusinessUnit
+		}
+		applications[unit] = append(applications[unit], service.Applications...)
+	}
+
+	for i := range analysis {
+		if analysis[i].Cataloged {
+			analysis[i].Applications = uniqueSorted(applications[analysis[i].Name])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestServiceCatalog_NormalizeIncidents(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	catalog := NewServiceCatalog(dbWrapper.GetConnection())
+	ctx := context.Background()
+
+	if _, err := catalog.SaveService(ctx, "Online Banking", "Retail", []string{"Portal", "Mobile App"}, []string{"OLB", "Internet Banking"}); err != nil {
+		t.Fatalf("Failed to save business service: %v", err)
+	}
+	if _, err := catalog.SaveService(ctx, "Payments", "Retail", []string{"Mobile App"}, nil); err != nil {
+		t.Fatalf("Failed to save business service: %v", err)
+	}
+
+	incidents := []models.Incident{
+		{ApplicationName: "Portal", BusinessService: " internet-banking "},
+		{ApplicationName: "Portal", BusinessService: ""},
+		{ApplicationName: "Mobile App", BusinessService: ""},
+		{ApplicationName: "Portal", BusinessService: "Branch Network"},
+	}
+	catalog.NormalizeIncidents(incidents)
+
+	expected := []string{"Online Banking", "Online Banking", "", "Branch Network"}
+	for i, want := range expected {
+		if incidents[i].BusinessService != want {
+			t.Errorf("Incident %d: expected business service %q, got %q", i, want, incidents[i].BusinessService)
+		}
+	}
+	if incidents[0].BusinessServiceRaw != " internet-banking " {
+		t.Errorf("Expected raw business service to be kept, got %q", incidents[0].BusinessServiceRaw)
+	}
+
+	// An alias may not name another service
+	if _, err := catalog.SaveService(ctx, "Cards", "Retail", nil, []string{"olb"}); !errors.Is(err, ErrInvalidService) {
+		t.Errorf("Expected ErrInvalidService for a taken alias, got %v", err)
+	}
+	if _, err := catalog.SaveService(ctx, "Cards", " ", nil, nil); !errors.Is(err, ErrInvalidService) {
+		t.Errorf("Expected ErrInvalidService for an empty business unit, got %v", err)
+	}
+
+	if err := catalog.DeleteService(ctx, "online banking"); err != nil {
+		t.Fatalf("Failed to delete business service: %v", err)
+	}
+	if got := catalog.Normalize("OLB", "Portal"); got != "OLB" {
+		t.Errorf("Expected deleted alias to be left as is, got %q", got)
+	}
+	if err := catalog.DeleteService(ctx, "Online Banking"); err == nil {
+		t.Error("Expected error deleting missing business service")
+	}
+}
+
+func TestAnalyticsService_GetServiceAnalysis(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	catalog := NewServiceCatalog(db)
+	analyticsService := NewAnalyticsService(db)
+	ctx := context.Background()
+
+	for _, service := range []BusinessService{
+		{Name: "Online Banking", BusinessUnit: "Retail", Applications: []string{"Portal"}},
+		{Name: "Payments", BusinessUnit: "Retail", Applications: []string{"Mobile App"}},
+	} {
+		if _, err := catalog.SaveService(ctx, service.Name, service.BusinessUnit, service.Applications, nil); err != nil {
+			t.Fatalf("Failed to save business service: %v", err)
+		}
+	}
+
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P1", "Open"),
+		diffTestIncident("i2", "upload-1", "INC002", "P2", "Open"),
+		diffTestIncident("i3", "upload-1", "INC003", "P3", "Open"),
+		diffTestIncident("i4", "upload-1", "INC004", "P3", "Open"),
+		diffTestIncident("i5", "upload-1", "INC005", "P3", "Open"),
+	}
+	incidents[0].BusinessService = "Online Banking"
+	incidents[1].BusinessService = "Online Banking"
+	incidents[2].BusinessService = "Payments"
+	incidents[3].BusinessService = "Branch Network"
+
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	services, err := analyticsService.GetServiceAnalysis(ctx, ServiceLevelService, "", nil)
+	if err != nil {
+		t.Fatalf("Failed to get service analysis: %v", err)
+	}
+	if len(services) != 4 {
+		t.Fatalf("Expected 4 services, got %+v", services)
+	}
+	banking := services[0]
+	if banking.Name != "Online Banking" || banking.IncidentCount != 2 || banking.BusinessUnit != "Retail" || !banking.Cataloged {
+		t.Errorf("Unexpected Online Banking rollup: %+v", banking)
+	}
+	if len(banking.Applications) != 1 || banking.Applications[0] != "Portal" || banking.P1Count != 1 {
+		t.Errorf("Unexpected Online Banking rollup details: %+v", banking)
+	}
+
+	units, err := analyticsService.GetServiceAnalysis(ctx, ServiceLevelBusinessUnit, "", nil)
+	if err != nil {
+		t.Fatalf("Failed to get business unit analysis: %v", err)
+	}
+	if len(units) != 2 || units[0].Name != "Retail" || units[0].IncidentCount != 3 || units[0].ServiceCount != 2 {
+		t.Errorf("Unexpected Retail rollup: %+v", units)
+	}
+	if len(units[0].Applications) != 2 {
+		t.Errorf("Expected the applications of both Retail services, got %+v", units[0].Applications)
+	}
+	if len(units) == 2 && (units[1].Name != UnassignedOrgUnit || units[1].IncidentCount != 2 || units[1].Cataloged) {
+		t.Errorf("Expected uncataloged incidents under %q, got %+v", UnassignedOrgUnit, units[1])
+	}
+
+	single, err := analyticsService.GetServiceAnalysis(ctx, ServiceLevelService, "Payments", nil)
+	if err != nil {
+		t.Fatalf("Failed to get single service analysis: %v", err)
+	}
+	if len(single) != 1 || single[0].IncidentCount != 1 {
+		t.Errorf("Unexpected Payments analysis: %+v", single)
+	}
+
+	if _, err := analyticsService.GetServiceAnalysis(ctx, "portfolio", "", nil); err == nil {
+		t.Error("Expected error for invalid service level")
+	}
+}
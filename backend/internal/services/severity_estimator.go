@@ -0,0 +1,209 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"incident-management-system/internal/models"
+)
+
+// DefaultInferredPriority is the priority estimated for incidents whose text has no impact signal
+const DefaultInferredPriority = models.PriorityP3
+
+// severityPhrases weighs the words and phrases of incident text by the severity they signal,
+// from 1 for an outage down to 0.1 for a question or request
+var severityPhrases = map[string]float64{
+	// Outages, data loss and security incidents
+	"outage":           1.0,
+	"down":             0.9,
+	"production down":  1.0,
+	"system down":      1.0,
+	"unavailable":      0.9,
+	"not accessible":   0.9,
+	"inaccessible":     0.9,
+	"all users":        0.9,
+	"complete failure": 1.0,
+	"data loss":        1.0,
+	"data corruption":  1.0,
+	"security breach":  1.0,
+	"breach":           0.9,
+	"ransomware":       1.0,
+	"critical":         0.85,
+	"sev1":             1.0,
+	"emergency":        0.9,
+	// Degraded service for many users
+	"degraded":        0.7,
+	"multiple users":  0.7,
+	"several users":   0.7,
+	"intermittent":    0.6,
+	"performance":     0.6,
+	"slow":            0.6,
+	"timeout":         0.6,
+	"timeouts":        0.6,
+	"payment failure": 0.75,
+	"cannot login":    0.65,
+	"failing":         0.6,
+	"urgent":          0.7,
+	// Faults with a workaround or affecting one user
+	"error":       0.4,
+	"errors":      0.45,
+	"issue":       0.35,
+	"unable":      0.4,
+	"single user": 0.35,
+	"workaround":  0.35,
+	"incorrect":   0.4,
+	// Requests, questions and cosmetic defects
+	"request":        0.15,
+	"access request": 0.1,
+	"how to":         0.1,
+	"question":       0.1,
+	"cosmetic":       0.1,
+	"typo":           0.1,
+	"enhancement":    0.1,
+	"documentation":  0.15,
+	"information":    0.15,
+}
+
+// severityLevels maps impact and urgency values to levels, 1 the highest
+var severityLevels = map[string]int{
+	"1": 1, "high": 1, "critical": 1, "extensive": 1, "widespread": 1,
+	"2": 2, "medium": 2, "moderate": 2, "significant": 2,
+	"3": 3, "low": 3, "minor": 3, "localized": 3, "individual": 3,
+}
+
+// SeverityEstimate is the priority estimated for an incident and what it was based on
+type SeverityEstimate struct {
+	Priority   string   `json:"priority"`
+	Score      float64  `json:"score"`      // 0 to 1, higher is more severe
+	Confidence float64  `json:"confidence"` // 0 to 1
+	Reasons    []string `json:"reasons"`
+}
+
+// SeverityEstimator infers a priority for incidents whose source recorded none, with rules like
+// the automation analyzer's: the impact and urgency matrix when both are recorded, or else the
+// most severe phrase of the incident's text
+type SeverityEstimator struct{}
+
+// NewSeverityEstimator creates a new severity estimator
+func NewSeverityEstimator() *SeverityEstimator {
+	return &SeverityEstimator{}
+}
+
+// EstimatePriority estimates the priority of an incident. Recorded impact and urgency are read as
+// the ITIL priority matrix; otherwise the most severe phrase of the descriptions decides, raised
+// one priority when customers are affected. Incidents with neither get DefaultInferredPriority.
+func (e *SeverityEstimator) EstimatePriority(incident *models.Incident) SeverityEstimate {
+	impact, urgency := severityLevel(incident.Impact), severityLevel(incident.Urgency)
+	if impact > 0 && urgency > 0 {
+		rank := impact + urgency - 1
+		if rank > len(models.ValidPriorities) {
+			rank = len(models.ValidPriorities)
+		}
+		return SeverityEstimate{
+			Priority:   models.ValidPriorities[rank-1],
+			Score:      roundTo(1-float64(rank-1)/float64(len(models.ValidPriorities)-1), 2),
+			Confidence: 0.9,
+			Reasons:    []string{fmt.Sprintf("impact %s and urgency %s", strings.TrimSpace(incident.Impact), strings.TrimSpace(incident.Urgency))},
+		}
+	}
+
+	// Phrases are matched on whole words, with the punctuation the automation analyzer drops
+	words := strings.Fields(tokenPunctuation.ReplaceAllString(strings.ToLower(strings.Join([]string{
+		incident.BriefDescription,
+		incident.Description,
+		incident.Category,
+		incident.Subcategory,
+	}, " ")), " "))
+	text := " " + strings.Join(words, " ") + " "
+
+	var score float64
+	var matched []string
+	for phrase, weight := range severityPhrases {
+		if !strings.Contains(text, " "+phrase+" ") {
+			continue
+		}
+		matched = append(matched, phrase)
+		if weight > score {
+			score = weight
+		}
+	}
+	if len(matched) == 0 {
+		return SeverityEstimate{
+			Priority:   DefaultInferredPriority,
+			Score:      0.4,
+			Confidence: 0.2,
+			Reasons:    []string{"no impact keywords in the description"},
+		}
+	}
+	sort.Strings(matched)
+
+	rank := severityRank(score)
+	reasons := []string{"description mentions " + strings.Join(matched, ", ")}
+	if customerAffected(incident.CustomerAffected) && rank > 1 {
+		rank--
+		reasons = append(reasons, "customers affected")
+	}
+	confidence := 0.5
+	if len(matched) > 1 {
+		confidence = 0.7
+	}
+	return SeverityEstimate{
+		Priority:   models.ValidPriorities[rank-1],
+		Score:      score,
+		Confidence: confidence,
+		Reasons:    reasons,
+	}
+}
+
+// InferPriorities fills in the priority of the incidents that have none with its estimate,
+// flagging it as inferred, and returns how many were filled in. Recorded priorities are kept,
+// even ones that are not valid.
+func (e *SeverityEstimator) InferPriorities(incidents []models.Incident) int {
+	inferred := 0
+	for i := range incidents {
+		if strings.TrimSpace(incidents[i].Priority) != "" {
+			continue
+		}
+		incidents[i].Priority = e.EstimatePriority(&incidents[i]).Priority
+		incidents[i].PriorityInferred = true
+		inferred++
+	}
+	return inferred
+}
+
+// severityRank returns the priority rank, 1 for P1, a phrase weight signals
+func severityRank(score float64) int {
+	switch {
+	case score >= 0.85:
+		return 1
+	case score >= 0.6:
+		return 2
+	case score >= 0.3:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// severityLevel reads an impact or urgency value such as "High" or "2 - Medium" as a level from
+// 1, the highest, to 3; 0 when it is not recognized
+func severityLevel(value string) int {
+	for _, word := range strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}) {
+		if level, ok := severityLevels[word]; ok {
+			return level
+		}
+	}
+	return 0
+}
+
+// customerAffected reports whether a customer affected value means yes
+func customerAffected(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "y", "true", "1":
+		return true
+	}
+	return false
+}
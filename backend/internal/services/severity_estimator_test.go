@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+
+	"incident-management-system/internal/models"
+)
+
+func TestSeverityEstimator_EstimatePriority(t *testing.T) {
+	estimator := NewSeverityEstimator()
+
+	tests := []struct {
+		name     string
+		incident models.Incident
+		expected string
+	}{
+		{"outage", models.Incident{BriefDescription: "Portal outage, all users affected"}, models.PriorityP1},
+		{"degraded service", models.Incident{BriefDescription: "Checkout slow for multiple users"}, models.PriorityP2},
+		{"single fault", models.Incident{BriefDescription: "Report shows incorrect totals"}, models.PriorityP3},
+		{"request", models.Incident{BriefDescription: "Question: how to export a report?"}, models.PriorityP4},
+		{"customers affected", models.Incident{BriefDescription: "Invoice error", CustomerAffected: "Yes"}, models.PriorityP2},
+		{"impact and urgency matrix", models.Incident{BriefDescription: "System down", Impact: "2 - Medium", Urgency: "Low"}, models.PriorityP4},
+		{"high impact and urgency", models.Incident{Impact: "High", Urgency: "1"}, models.PriorityP1},
+		{"no signal", models.Incident{BriefDescription: "Printer"}, DefaultInferredPriority},
+		{"words, not substrings", models.Incident{BriefDescription: "Download of slowness report"}, DefaultInferredPriority},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimate := estimator.EstimatePriority(&tt.incident)
+			if estimate.Priority != tt.expected {
+				t.Errorf("EstimatePriority() = %s (%v), want %s", estimate.Priority, estimate.Reasons, tt.expected)
+			}
+			if len(estimate.Reasons) == 0 {
+				t.Error("expected the estimate to give its reasons")
+			}
+		})
+	}
+}
+
+func TestSeverityEstimator_InferPriorities(t *testing.T) {
+	incidents := []models.Incident{
+		{IncidentID: "INC1", Priority: "P4", BriefDescription: "Production outage"},
+		{IncidentID: "INC2", Priority: " ", BriefDescription: "Production outage"},
+		{IncidentID: "INC3", Priority: "High", BriefDescription: "Production outage"},
+	}
+
+	if inferred := NewSeverityEstimator().InferPriorities(incidents); inferred != 1 {
+		t.Fatalf("expected one priority inferred, got %d", inferred)
+	}
+	if incidents[0].Priority != "P4" || incidents[0].PriorityInferred {
+		t.Errorf("expected a recorded priority to be kept, got %+v", incidents[0])
+	}
+	if incidents[1].Priority != models.PriorityP1 || !incidents[1].PriorityInferred {
+		t.Errorf("expected the missing priority inferred and flagged, got %+v", incidents[1])
+	}
+	if incidents[2].Priority != "High" || incidents[2].PriorityInferred {
+		t.Errorf("expected an invalid recorded priority to be left to validation, got %+v", incidents[2])
+	}
+}
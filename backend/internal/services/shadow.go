@@ -0,0 +1,458 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// maxShadowDisagreements caps the incidents listed in a shadow comparison
+const maxShadowDisagreements = 20
+
+// ErrInvalidShadowPhrase is returned when a shadow sentiment phrase has fewer than two words
+var ErrInvalidShadowPhrase = errors.New("sentiment phrases must contain at least two words")
+
+// ShadowKeyword is an automation keyword override in a shadow configuration
+type ShadowKeyword struct {
+	Keyword string  `json:"keyword"`
+	Kind    string  `json:"kind"`
+	Weight  float64 `json:"weight"`
+}
+
+// ShadowConfig is a candidate analyzer configuration evaluated alongside the active one.
+// Its overrides are applied on top of the persisted phrases and keywords.
+type ShadowConfig struct {
+	ID                 string             `json:"id"`
+	Name               string             `json:"name"`
+	SentimentPhrases   map[string]float64 `json:"sentiment_phrases"`
+	AutomationKeywords []ShadowKeyword    `json:"automation_keywords"`
+	Active             bool               `json:"active"`
+	CreatedAt          time.Time          `json:"created_at"`
+}
+
+// ShadowTransition counts incidents whose value moved from the active result to the shadow result
+type ShadowTransition struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int    `json:"count"`
+}
+
+// ShadowSentimentComparison compares active and shadow sentiment results
+type ShadowSentimentComparison struct {
+	Agreement     int                `json:"agreement"`
+	AgreementRate float64            `json:"agreement_rate"`
+	AvgScoreDelta float64            `json:"avg_score_delta"`
+	Transitions   []ShadowTransition `json:"transitions"`
+}
+
+// ShadowAutomationComparison compares active and shadow automation results
+type ShadowAutomationComparison struct {
+	Agreement           int     `json:"agreement"`
+	AgreementRate       float64 `json:"agreement_rate"`
+	AvgScoreDelta       float64 `json:"avg_score_delta"`
+	BecameFeasible      int     `json:"became_feasible"`
+	BecameInfeasible    int     `json:"became_infeasible"`
+	ProcessGroupChanges int     `json:"process_group_changes"`
+}
+
+// ShadowDisagreement is one incident where the shadow configuration disagrees with the active one
+type ShadowDisagreement struct {
+	UploadID           string `json:"upload_id"`
+	IncidentID         string `json:"incident_id"`
+	ActiveSentiment    string `json:"active_sentiment"`
+	ShadowSentiment    string `json:"shadow_sentiment"`
+	ActiveFeasible     bool   `json:"active_feasible"`
+	ShadowFeasible     bool   `json:"shadow_feasible"`
+	ActiveProcessGroup string `json:"active_process_group"`
+	ShadowProcessGroup string `json:"shadow_process_group"`
+}
+
+// ShadowComparison compares the results of a shadow configuration with the active results
+// for every incident it has analyzed
+type ShadowComparison struct {
+	Config            ShadowConfig               `json:"config"`
+	IncidentsCompared int                        `json:"incidents_compared"`
+	Sentiment         ShadowSentimentComparison  `json:"sentiment"`
+	Automation        ShadowAutomationComparison `json:"automation"`
+	Disagreements     []ShadowDisagreement       `json:"disagreements"`
+}
+
+// ShadowService manages shadow analyzer configurations and their stored results
+type ShadowService struct {
+	db *sql.DB
+}
+
+// NewShadowService creates a new ShadowService instance
+func NewShadowService(db *sql.DB) *ShadowService {
+	return &ShadowService{db: db}
+}
+
+// ListConfigs returns all shadow configurations, newest first
+func (s *ShadowService) ListConfigs(ctx context.Context) ([]ShadowConfig, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, sentiment_phrases, automation_keywords, active, created_at
+		FROM shadow_configs
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shadow configs: %w", err)
+	}
+	defer rows.Close()
+
+	configs := []ShadowConfig{}
+	for rows.Next() {
+		config, err := scanShadowConfig(rows)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, *config)
+	}
+
+	return configs, rows.Err()
+}
+
+// GetConfig returns a shadow configuration, or sql.ErrNoRows when it does not exist
+func (s *ShadowService) GetConfig(ctx context.Context, id string) (*ShadowConfig, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, name, sentiment_phrases, automation_keywords, active, created_at
+		FROM shadow_configs
+		WHERE id = ?
+	`, id)
+	return scanShadowConfig(row)
+}
+
+// ActiveConfig returns the active shadow configuration, or nil when shadow mode is off
+func (s *ShadowService) ActiveConfig(ctx context.Context) (*ShadowConfig, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, name, sentiment_phrases, automation_keywords, active, created_at
+		FROM shadow_configs
+		WHERE active
+		LIMIT 1
+	`)
+	config, err := scanShadowConfig(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return config, err
+}
+
+// scanShadowConfig scans a shadow_configs row, decoding its JSON override columns
+func scanShadowConfig(row interface{ Scan(...interface{}) error }) (*ShadowConfig, error) {
+	var config ShadowConfig
+	var phrases, keywords string
+	if err := row.Scan(&config.ID, &config.Name, &phrases, &keywords, &config.Active, &config.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan shadow config: %w", err)
+	}
+	if err := json.Unmarshal([]byte(phrases), &config.SentimentPhrases); err != nil {
+		return nil, fmt.Errorf("failed to decode shadow sentiment phrases: %w", err)
+	}
+	if err := json.Unmarshal([]byte(keywords), &config.AutomationKeywords); err != nil {
+		return nil, fmt.Errorf("failed to decode shadow automation keywords: %w", err)
+	}
+	return &config, nil
+}
+
+// CreateConfig stores a new shadow configuration. Activating it deactivates any other,
+// since only one shadow configuration runs at a time.
+func (s *ShadowService) CreateConfig(ctx context.Context, name string, phrases map[string]float64, keywords []ShadowKeyword, active bool) (*ShadowConfig, error) {
+	config := &ShadowConfig{
+		ID:                 uuid.New().String(),
+		Name:               strings.TrimSpace(name),
+		SentimentPhrases:   make(map[string]float64, len(phrases)),
+		AutomationKeywords: make([]ShadowKeyword, 0, len(keywords)),
+		Active:             active,
+		CreatedAt:          time.Now(),
+	}
+	tokenizer := NewSimpleSentimentAnalyzer()
+	for phrase, weight := range phrases {
+		tokens := tokenizer.tokenize(phrase)
+		if len(tokens) < 2 {
+			return nil, ErrInvalidShadowPhrase
+		}
+		config.SentimentPhrases[strings.Join(tokens, " ")] = weight
+	}
+	for _, keyword := range keywords {
+		if !IsValidAutomationKeywordKind(keyword.Kind) {
+			return nil, fmt.Errorf("invalid automation keyword kind: %s", keyword.Kind)
+		}
+		normalized, err := normalizeAutomationKeyword(keyword.Keyword)
+		if err != nil {
+			return nil, err
+		}
+		keyword.Keyword = normalized
+		config.AutomationKeywords = append(config.AutomationKeywords, keyword)
+	}
+
+	phrasesJSON, err := json.Marshal(config.SentimentPhrases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode shadow sentiment phrases: %w", err)
+	}
+	keywordsJSON, err := json.Marshal(config.AutomationKeywords)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode shadow automation keywords: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if active {
+		if _, err := tx.ExecContext(ctx, "UPDATE shadow_configs SET active = false WHERE active"); err != nil {
+			return nil, fmt.Errorf("failed to deactivate shadow configs: %w", err)
+		}
+	}
+	query := `
+		INSERT INTO shadow_configs (id, name, sentiment_phrases, automation_keywords, active, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	if _, err := tx.ExecContext(ctx, query, config.ID, config.Name, string(phrasesJSON), string(keywordsJSON), config.Active, config.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save shadow config: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit shadow config: %w", err)
+	}
+	return config, nil
+}
+
+// SetActive turns shadow mode on for a configuration, or off, returning sql.ErrNoRows when
+// the configuration does not exist
+func (s *ShadowService) SetActive(ctx context.Context, id string, active bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if active {
+		if _, err := tx.ExecContext(ctx, "UPDATE shadow_configs SET active = false WHERE active AND id <> ?", id); err != nil {
+			return fmt.Errorf("failed to deactivate shadow configs: %w", err)
+		}
+	}
+	result, err := tx.ExecContext(ctx, "UPDATE shadow_configs SET active = ? WHERE id = ?", active, id)
+	if err != nil {
+		return fmt.Errorf("failed to update shadow config: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit()
+}
+
+// DeleteConfig removes a shadow configuration and its results, returning sql.ErrNoRows when it does not exist
+func (s *ShadowService) DeleteConfig(ctx context.Context, id string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM shadow_results WHERE config_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete shadow results: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, "DELETE FROM shadow_configs WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete shadow config: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit()
+}
+
+// buildAnalyzers creates analyzers with the persisted phrases and keywords plus the shadow overrides
+func (s *ShadowService) buildAnalyzers(ctx context.Context, config *ShadowConfig) (*SimpleSentimentAnalyzer, *SimpleAutomationAnalyzer, error) {
+	sentiment := NewSimpleSentimentAnalyzer()
+	if err := sentiment.LoadPhrases(ctx, s.db); err != nil {
+		return nil, nil, err
+	}
+	sentiment.AddPhrases(config.SentimentPhrases)
+
+	automation := NewSimpleAutomationAnalyzer()
+	if err := automation.LoadKeywords(ctx, s.db); err != nil {
+		return nil, nil, err
+	}
+	automationKeywords := make(map[string]float64)
+	manualKeywords := make(map[string]float64)
+	for _, keyword := range config.AutomationKeywords {
+		if keyword.Kind == AutomationKeywordKindManual {
+			manualKeywords[keyword.Keyword] = keyword.Weight
+		} else {
+			automationKeywords[keyword.Keyword] = keyword.Weight
+		}
+	}
+	automation.AddCustomKeywords(automationKeywords, manualKeywords)
+
+	return sentiment, automation, nil
+}
+
+// RunShadow analyzes incidents with a shadow configuration and stores the results separately
+// from the incidents, so production fields are never overwritten
+func (s *ShadowService) RunShadow(ctx context.Context, config *ShadowConfig, uploadID string, incidents []models.Incident) error {
+	sentimentAnalyzer, automationAnalyzer, err := s.buildAnalyzers(ctx, config)
+	if err != nil {
+		return fmt.Errorf("failed to build shadow analyzers: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR REPLACE INTO shadow_results (
+			config_id, upload_id, incident_id, sentiment_score, sentiment_label,
+			automation_score, automation_feasible, it_process_group, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare shadow insert: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for i := range incidents {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("shadow analysis cancelled after %d of %d incidents: %w", i, len(incidents), err)
+		}
+
+		// Analyze a copy so the production incident is left untouched
+		incident := incidents[i]
+		sentiment, err := sentimentAnalyzer.AnalyzeSentiment(incident.BriefDescription + " " + incident.Description)
+		if err != nil {
+			return fmt.Errorf("shadow sentiment analysis failed for incident %s: %w", incident.IncidentID, err)
+		}
+		automation, err := automationAnalyzer.AnalyzeAutomation(&incident)
+		if err != nil {
+			return fmt.Errorf("shadow automation analysis failed for incident %s: %w", incident.IncidentID, err)
+		}
+
+		if _, err := stmt.ExecContext(ctx, config.ID, uploadID, incident.IncidentID, sentiment.Score, sentiment.Label,
+			automation.Score, automation.Feasible, automation.ITProcessGroup, now); err != nil {
+			return fmt.Errorf("failed to store shadow result for incident %s: %w", incident.IncidentID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit shadow results: %w", err)
+	}
+	return nil
+}
+
+// CompareShadow compares a shadow configuration's results with the active results of the same
+// incidents, returning sql.ErrNoRows when the configuration does not exist
+func (s *ShadowService) CompareShadow(ctx context.Context, id string) (*ShadowComparison, error) {
+	config, err := s.GetConfig(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.upload_id, r.incident_id,
+			COALESCE(i.sentiment_label, ''), COALESCE(i.sentiment_score, 0),
+			COALESCE(r.sentiment_label, ''), COALESCE(r.sentiment_score, 0),
+			COALESCE(i.automation_feasible, false), COALESCE(i.automation_score, 0), COALESCE(i.it_process_group, ''),
+			COALESCE(r.automation_feasible, false), COALESCE(r.automation_score, 0), COALESCE(r.it_process_group, '')
+		FROM shadow_results r
+		JOIN incidents i ON i.upload_id = r.upload_id AND i.incident_id = r.incident_id
+		WHERE r.config_id = ?
+		ORDER BY i.report_date DESC, r.incident_id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shadow results: %w", err)
+	}
+	defer rows.Close()
+
+	comparison := &ShadowComparison{
+		Config:        *config,
+		Disagreements: []ShadowDisagreement{},
+	}
+	comparison.Sentiment.Transitions = []ShadowTransition{}
+	transitions := make(map[[2]string]int)
+	var sentimentDelta, automationDelta float64
+
+	for rows.Next() {
+		var d ShadowDisagreement
+		var activeSentimentScore, shadowSentimentScore, activeAutomationScore, shadowAutomationScore float64
+		if err := rows.Scan(&d.UploadID, &d.IncidentID,
+			&d.ActiveSentiment, &activeSentimentScore, &d.ShadowSentiment, &shadowSentimentScore,
+			&d.ActiveFeasible, &activeAutomationScore, &d.ActiveProcessGroup,
+			&d.ShadowFeasible, &shadowAutomationScore, &d.ShadowProcessGroup); err != nil {
+			return nil, fmt.Errorf("failed to scan shadow result: %w", err)
+		}
+		comparison.IncidentsCompared++
+		sentimentDelta += shadowSentimentScore - activeSentimentScore
+		automationDelta += shadowAutomationScore - activeAutomationScore
+
+		agrees := true
+		if d.ActiveSentiment == d.ShadowSentiment {
+			comparison.Sentiment.Agreement++
+		} else {
+			transitions[[2]string{d.ActiveSentiment, d.ShadowSentiment}]++
+			agrees = false
+		}
+		switch {
+		case d.ActiveFeasible == d.ShadowFeasible:
+			comparison.Automation.Agreement++
+		case d.ShadowFeasible:
+			comparison.Automation.BecameFeasible++
+			agrees = false
+		default:
+			comparison.Automation.BecameInfeasible++
+			agrees = false
+		}
+		if d.ActiveProcessGroup != d.ShadowProcessGroup {
+			comparison.Automation.ProcessGroupChanges++
+			agrees = false
+		}
+
+		if !agrees && len(comparison.Disagreements) < maxShadowDisagreements {
+			comparison.Disagreements = append(comparison.Disagreements, d)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating shadow results: %w", err)
+	}
+
+	if n := float64(comparison.IncidentsCompared); n > 0 {
+		comparison.Sentiment.AgreementRate = float64(comparison.Sentiment.Agreement) * 100.0 / n
+		comparison.Sentiment.AvgScoreDelta = math.Round(sentimentDelta/n*1000) / 1000
+		comparison.Automation.AgreementRate = float64(comparison.Automation.Agreement) * 100.0 / n
+		comparison.Automation.AvgScoreDelta = math.Round(automationDelta/n*1000) / 1000
+	}
+
+	for key, count := range transitions {
+		comparison.Sentiment.Transitions = append(comparison.Sentiment.Transitions, ShadowTransition{From: key[0], To: key[1], Count: count})
+	}
+	sort.Slice(comparison.Sentiment.Transitions, func(i, j int) bool {
+		a, b := comparison.Sentiment.Transitions[i], comparison.Sentiment.Transitions[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+
+	return comparison, nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestShadowService_RunAndCompare(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	service := NewShadowService(db)
+	processing := NewProcessingService(db, nil)
+	ctx := context.Background()
+
+	incidents := []models.Incident{
+		diffTestIncident("i1", "upload-1", "INC001", "P3", "Closed"),
+		diffTestIncident("i2", "upload-1", "INC002", "P3", "Closed"),
+	}
+	incidents[0].Description = "Applied hotfix"
+	incidents[1].BriefDescription = "Printer offline"
+	incidents[1].Description = "Printer offline"
+	if err := processing.processIncidentsWithAnalysis(ctx, incidents); err != nil {
+		t.Fatalf("Failed to analyze incidents: %v", err)
+	}
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	if _, err := service.CreateConfig(ctx, "single word", map[string]float64{"failure": 0.5}, nil, false); err != ErrInvalidShadowPhrase {
+		t.Errorf("Expected ErrInvalidShadowPhrase, got %v", err)
+	}
+
+	// No active config: shadow mode is off
+	processing.runShadowAnalysis(ctx, "upload-1", incidents)
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM shadow_results").Scan(&count); err != nil || count != 0 {
+		t.Fatalf("Expected no shadow results without an active config, got %d (%v)", count, err)
+	}
+
+	first, err := service.CreateConfig(ctx, "baseline", nil, nil, true)
+	if err != nil {
+		t.Fatalf("Failed to create shadow config: %v", err)
+	}
+	shadow, err := service.CreateConfig(ctx, "Login Tuning",
+		map[string]float64{"Login failure": 0.9},
+		[]ShadowKeyword{{Keyword: "Hotfix", Kind: AutomationKeywordKindAutomation, Weight: 1.0}},
+		true)
+	if err != nil {
+		t.Fatalf("Failed to create shadow config: %v", err)
+	}
+
+	// Creating an active config deactivates the previous one
+	active, err := service.ActiveConfig(ctx)
+	if err != nil || active == nil || active.ID != shadow.ID {
+		t.Fatalf("Expected %s to be the active config, got %+v (%v)", shadow.ID, active, err)
+	}
+	if active.SentimentPhrases["login failure"] != 0.9 || active.AutomationKeywords[0].Keyword != "hotfix" {
+		t.Errorf("Expected normalized overrides to round-trip, got %+v", active)
+	}
+
+	processing.runShadowAnalysis(ctx, "upload-1", incidents)
+
+	// Production fields are untouched
+	stored, err := NewIncidentService(db).GetIncidentsByUpload(ctx, "upload-1")
+	if err != nil {
+		t.Fatalf("Failed to read incidents: %v", err)
+	}
+	if stored[0].SentimentLabel != incidents[0].SentimentLabel || *stored[0].AutomationFeasible != *incidents[0].AutomationFeasible {
+		t.Errorf("Shadow analysis changed production fields: %+v", stored[0])
+	}
+
+	comparison, err := service.CompareShadow(ctx, shadow.ID)
+	if err != nil {
+		t.Fatalf("Failed to compare shadow config: %v", err)
+	}
+	if comparison.IncidentsCompared != 2 {
+		t.Fatalf("Expected 2 incidents compared, got %d", comparison.IncidentsCompared)
+	}
+	if comparison.Sentiment.Agreement != 1 || comparison.Sentiment.AgreementRate != 50 {
+		t.Errorf("Expected 1 sentiment agreement, got %+v", comparison.Sentiment)
+	}
+	if len(comparison.Sentiment.Transitions) != 1 || comparison.Sentiment.Transitions[0].From != models.SentimentNegative ||
+		comparison.Sentiment.Transitions[0].To != models.SentimentPositive {
+		t.Errorf("Expected a negative to positive transition, got %+v", comparison.Sentiment.Transitions)
+	}
+	if comparison.Automation.BecameFeasible != 1 || comparison.Automation.Agreement != 1 {
+		t.Errorf("Expected one incident to become feasible, got %+v", comparison.Automation)
+	}
+	if len(comparison.Disagreements) != 1 || comparison.Disagreements[0].IncidentID != "INC001" {
+		t.Errorf("Expected INC001 as the only disagreement, got %+v", comparison.Disagreements)
+	}
+
+	// The inactive baseline has no results
+	comparison, err = service.CompareShadow(ctx, first.ID)
+	if err != nil || comparison.IncidentsCompared != 0 {
+		t.Errorf("Expected empty comparison for inactive config, got %+v (%v)", comparison, err)
+	}
+
+	if err := service.SetActive(ctx, shadow.ID, false); err != nil {
+		t.Fatalf("Failed to deactivate shadow config: %v", err)
+	}
+	if active, _ := service.ActiveConfig(ctx); active != nil {
+		t.Errorf("Expected no active config, got %+v", active)
+	}
+	if err := service.SetActive(ctx, "missing", true); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows activating a missing config, got %v", err)
+	}
+
+	if err := service.DeleteConfig(ctx, shadow.ID); err != nil {
+		t.Fatalf("Failed to delete shadow config: %v", err)
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM shadow_results").Scan(&count); err != nil || count != 0 {
+		t.Errorf("Expected shadow results to be deleted with their config, got %d (%v)", count, err)
+	}
+	if _, err := service.CompareShadow(ctx, shadow.ID); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for deleted config, got %v", err)
+	}
+}
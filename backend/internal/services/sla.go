@@ -0,0 +1,41 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultSLATargetHours holds the resolution target for each priority, in hours
+var DefaultSLATargetHours = map[string]int{
+	"P1": 4,
+	"P2": 8,
+	"P3": 24,
+	"P4": 72,
+}
+
+// resolutionHoursExpr is the resolution time, in hours, that SLA and resolution analytics
+// measure: the time to resolve without the time spent pending on the customer, falling back to
+// the gross time for incidents imported without pending time
+const resolutionHoursExpr = "COALESCE(net_resolution_time_hours, resolution_time_hours)"
+
+// SLATargetHours returns the resolution target for a priority, and false when the priority has none
+func SLATargetHours(priority string) (int, bool) {
+	target, ok := DefaultSLATargetHours[priority]
+	return target, ok
+}
+
+// slaTargetExpression returns a SQL expression giving the resolution target, in hours, of the
+// priority in column, or NULL for priorities without one
+func slaTargetExpression(column string) string {
+	priorities := make([]string, 0, len(DefaultSLATargetHours))
+	for priority := range DefaultSLATargetHours {
+		priorities = append(priorities, priority)
+	}
+	sort.Strings(priorities)
+
+	expression := "CASE " + column
+	for _, priority := range priorities {
+		expression += fmt.Sprintf(" WHEN %s THEN %d", sqlString(priority), DefaultSLATargetHours[priority])
+	}
+	return expression + " END"
+}
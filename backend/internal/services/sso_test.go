@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+)
+
+// fakeIdentityProvider is an OpenID Connect provider issuing ID tokens for the claims of the
+// code it is called with
+type fakeIdentityProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	// claims of the user signing in with each authorization code
+	codes map[string]map[string]interface{}
+	// nonce and code challenge of the last authorization request
+	nonce, challenge string
+}
+
+func newFakeIdentityProvider(t *testing.T) *fakeIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	idp := &fakeIdentityProvider{key: key, codes: map[string]map[string]interface{}{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "key-1", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		claims, ok := idp.codes[r.PostForm.Get("code")]
+		if !ok || base64.RawURLEncoding.EncodeToString(verifier[:]) != idp.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "unknown code"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idp.sign(t, claims)})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize records the nonce and code challenge of an authorization URL and returns its state
+func (idp *fakeIdentityProvider) authorize(t *testing.T, authURL string) string {
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("invalid authorization URL %q: %v", authURL, err)
+	}
+	query := parsed.Query()
+	idp.nonce = query.Get("nonce")
+	idp.challenge = query.Get("code_challenge")
+	return query.Get("state")
+}
+
+// sign issues an ID token for the claims, defaulting the standard claims
+func (idp *fakeIdentityProvider) sign(t *testing.T, claims map[string]interface{}) string {
+	token := map[string]interface{}{
+		"iss":   idp.server.URL,
+		"aud":   "ims",
+		"sub":   "user-1",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": idp.nonce,
+	}
+	for name, value := range claims {
+		token[name] = value
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "key-1"})
+	payload, _ := json.Marshal(token)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign ID token: %v", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestSSOLogin(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	idp := newFakeIdentityProvider(t)
+	ctx := context.Background()
+
+	if _, err := NewSSOService(dbWrapper.GetConnection(), SSOConfig{IssuerURL: idp.server.URL}); !errors.Is(err, ErrInvalidSSOConfig) {
+		t.Errorf("expected ErrInvalidSSOConfig without a client, got %v", err)
+	}
+	if _, err := NewSSOService(dbWrapper.GetConnection(), SSOConfig{
+		IssuerURL: idp.server.URL, ClientID: "ims", RedirectURL: "https://ims.example.com/api/auth/callback",
+		RoleMappings: map[string]string{"ops": "superuser"},
+	}); !errors.Is(err, ErrInvalidSSOConfig) {
+		t.Errorf("expected ErrInvalidSSOConfig for an unknown role, got %v", err)
+	}
+
+	sso, err := NewSSOService(dbWrapper.GetConnection(), SSOConfig{
+		IssuerURL:    idp.server.URL,
+		ClientID:     "ims",
+		RedirectURL:  "https://ims.example.com/api/auth/callback",
+		RoleMappings: map[string]string{"ims-admins": RoleAdmin, "ims-analysts": RoleAnalyst},
+	})
+	if err != nil {
+		t.Fatalf("NewSSOService() error = %v", err)
+	}
+
+	login := func(code string, claims map[string]interface{}) (*SSOSession, string, string, error) {
+		t.Helper()
+		authURL, err := sso.BeginLogin(ctx, "/analytics")
+		if err != nil {
+			t.Fatalf("BeginLogin() error = %v", err)
+		}
+		state := idp.authorize(t, authURL)
+		idp.codes[code] = claims
+		return sso.CompleteLogin(ctx, state, code)
+	}
+
+	// The most privileged mapped group wins
+	session, token, returnTo, err := login("code-1", map[string]interface{}{
+		"email": "alice@example.com", "name": "Alice", "groups": []string{"ims-analysts", "ims-admins", "staff"},
+	})
+	if err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+	if session.UserID != "alice@example.com" || session.Role != RoleAdmin || returnTo != "/analytics" || len(session.Groups) != 3 {
+		t.Errorf("unexpected session %+v returning to %s", session, returnTo)
+	}
+
+	stored, err := sso.Session(ctx, token)
+	if err != nil {
+		t.Fatalf("Session() error = %v", err)
+	}
+	if stored == nil || stored.UserID != "alice@example.com" || stored.Role != RoleAdmin || stored.Name != "Alice" {
+		t.Errorf("unexpected stored session %+v", stored)
+	}
+	if unknown, err := sso.Session(ctx, "not-a-token"); err != nil || unknown != nil {
+		t.Errorf("expected no session for an unknown token, got %+v, %v", unknown, err)
+	}
+
+	if err := sso.Logout(ctx, token); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if stored, _ := sso.Session(ctx, token); stored != nil {
+		t.Errorf("expected the session to end on logout, got %+v", stored)
+	}
+
+	// Users in no mapped group are denied without a default role
+	if _, _, _, err := login("code-2", map[string]interface{}{"groups": "staff"}); !errors.Is(err, ErrSSOAccessDenied) {
+		t.Errorf("expected ErrSSOAccessDenied, got %v", err)
+	}
+
+	// Tokens replayed from another login, for another client or past their expiry are rejected
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+	}{
+		{name: "wrong nonce", claims: map[string]interface{}{"nonce": "replayed", "groups": "ims-admins"}},
+		{name: "wrong audience", claims: map[string]interface{}{"aud": "other-app", "groups": "ims-admins"}},
+		{name: "expired", claims: map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix(), "groups": "ims-admins"}},
+		{name: "wrong issuer", claims: map[string]interface{}{"iss": "https://evil.example.com", "groups": "ims-admins"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := login("code-"+tt.name, tt.claims); !errors.Is(err, ErrSSOLoginFailed) {
+				t.Errorf("expected ErrSSOLoginFailed, got %v", err)
+			}
+		})
+	}
+
+	// A state can only be used once
+	authURL, err := sso.BeginLogin(ctx, "/")
+	if err != nil {
+		t.Fatalf("BeginLogin() error = %v", err)
+	}
+	state := idp.authorize(t, authURL)
+	idp.codes["code-3"] = map[string]interface{}{"groups": "ims-analysts"}
+	if _, _, _, err := sso.CompleteLogin(ctx, state, "code-3"); err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+	if _, _, _, err := sso.CompleteLogin(ctx, state, "code-3"); !errors.Is(err, ErrSSOLoginFailed) {
+		t.Errorf("expected ErrSSOLoginFailed reusing a state, got %v", err)
+	}
+}
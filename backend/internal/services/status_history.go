@@ -0,0 +1,113 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultPendingStatuses are the statuses that pause the resolution clock when a mapping profile
+// names none. Statuses are matched ignoring case, spaces, underscores and hyphens.
+var DefaultPendingStatuses = []string{"Pending Customer", "Awaiting Customer", "Pending User", "Awaiting User"}
+
+// maxTimestampFields is the most space-separated fields a status history timestamp spans, as in
+// 02/03/2024 2:05 PM
+const maxTimestampFields = 3
+
+// statusChange is one entry of a status history: the status an incident moved to and when
+type statusChange struct {
+	at     time.Time
+	status string
+}
+
+// statusHistory reads status history cells and totals the time incidents spent in pending
+// statuses. It is shared by the parse workers.
+type statusHistory struct {
+	pending map[string]bool
+	dates   *dateColumn
+}
+
+// newStatusHistory creates a reader counting pendingStatuses, or DefaultPendingStatuses when
+// none are given. Timestamps are read like the report date column, in the same day-month order.
+func newStatusHistory(pendingStatuses []string, reportDates *dateColumn, location *time.Location) *statusHistory {
+	if len(pendingStatuses) == 0 {
+		pendingStatuses = DefaultPendingStatuses
+	}
+	pending := make(map[string]bool, len(pendingStatuses))
+	for _, status := range pendingStatuses {
+		pending[normalizeColumnName(status)] = true
+	}
+
+	dates, _ := newDateColumn("status_history", "", location, nil)
+	if reportDates != nil {
+		dates.dayFirst = reportDates.dayFirst || strings.HasPrefix(reportDates.stats.Format, "DD")
+	}
+	return &statusHistory{pending: pending, dates: dates}
+}
+
+// parse splits a history cell into its status changes in time order. Entries are separated by
+// semicolons, pipes or line breaks and start with their timestamp, such as
+// "2024-03-01 09:00 Pending Customer". Entries without a readable timestamp are skipped.
+func (h *statusHistory) parse(cell string) []statusChange {
+	entries := strings.FieldsFunc(cell, func(r rune) bool {
+		return r == ';' || r == '|' || r == '\n' || r == '\r'
+	})
+
+	changes := make([]statusChange, 0, len(entries))
+	for _, entry := range entries {
+		fields := strings.Fields(entry)
+		for n := maxTimestampFields; n >= 1; n-- {
+			if n >= len(fields) {
+				continue
+			}
+			at, ok := h.dates.parse(strings.TrimRight(strings.Join(fields[:n], " "), "-:>"))
+			if !ok {
+				continue
+			}
+			status := strings.TrimLeft(strings.Join(fields[n:], " "), "-:> ")
+			changes = append(changes, statusChange{at: at, status: status})
+			break
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].at.Before(changes[j].at) })
+	return changes
+}
+
+// pendingHours totals the hours spent in pending statuses between report and resolve. A pending
+// status lasts until the next change, or until resolve when it is the last one; an open incident
+// still pending is not counted. It returns false when the cell holds no status changes.
+func (h *statusHistory) pendingHours(cell string, report time.Time, resolve *time.Time) (float64, bool) {
+	changes := h.parse(cell)
+	if len(changes) == 0 {
+		return 0, false
+	}
+
+	var paused time.Duration
+	for i, change := range changes {
+		if !h.pending[normalizeColumnName(change.status)] {
+			continue
+		}
+		var end time.Time
+		switch {
+		case i+1 < len(changes):
+			end = changes[i+1].at
+		case resolve != nil:
+			end = *resolve
+		default:
+			continue
+		}
+
+		start := change.at
+		if start.Before(report) {
+			start = report
+		}
+		if resolve != nil && end.After(*resolve) {
+			end = *resolve
+		}
+		if end.After(start) {
+			paused += end.Sub(start)
+		}
+	}
+	return roundTo(paused.Hours(), 2), true
+}
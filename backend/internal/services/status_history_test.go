@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatusHistory_PendingHours(t *testing.T) {
+	report := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	resolve := time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		statuses []string
+		cell     string
+		resolve  *time.Time
+		expected float64
+		found    bool
+	}{
+		{"pending until the next change", nil,
+			"2024-03-01 09:00 New; 2024-03-01 10:00 Pending Customer; 2024-03-01 16:30 In Progress",
+			&resolve, 6.5, true},
+		{"entries out of order on separate lines", nil,
+			"2024-03-01 16:30 -> In Progress\n2024-03-01 10:00 -> pending_customer",
+			&resolve, 6.5, true},
+		{"pending until resolved", nil,
+			"2024-03-01 21:00 Awaiting User", &resolve, 12, true},
+		{"pending before the report date is clipped", nil,
+			"2024-02-29 09:00 Pending Customer | 2024-03-01 12:00 Work In Progress", &resolve, 3, true},
+		{"open incident still pending", nil,
+			"2024-03-01 10:00 Pending Customer", nil, 0, true},
+		{"configured statuses replace the defaults", []string{"On Hold"},
+			"2024-03-01 10:00 Pending Customer; 2024-03-01 12:00 On Hold; 2024-03-01 13:15 Resolved",
+			&resolve, 1.25, true},
+		{"day-first timestamps with AM/PM", nil,
+			"01/03/2024 1:00 PM: Pending Customer; 01/03/2024 3:00 PM: In Progress", &resolve, 2, true},
+		{"no readable entries", nil, "waiting on customer", &resolve, 0, false},
+	}
+
+	reportDates, _ := newDateColumn("report_date", "DD/MM/YYYY", nil, nil)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			history := newStatusHistory(tc.statuses, reportDates, nil)
+			hours, found := history.pendingHours(tc.cell, report, tc.resolve)
+			if found != tc.found {
+				t.Fatalf("Expected found %v, got %v", tc.found, found)
+			}
+			if hours != tc.expected {
+				t.Errorf("Expected %.2f pending hours, got %.2f", tc.expected, hours)
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"incident-management-system/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSetting is returned for an unknown setting or a value the setting does not accept
+var ErrInvalidSetting = errors.New("invalid setting")
+
+// Setting value types
+const (
+	SettingInt   = "int"
+	SettingFloat = "float"
+	SettingBool  = "bool"
+)
+
+// Setting describes a setting admins can change through the config API
+type Setting struct {
+	Key         string
+	Type        string
+	Description string
+	Default     interface{} // int, float64 or bool, matching Type
+	Min, Max    float64     // Accepted range of numeric settings
+	// Apply puts a new value into effect while the server runs. Settings without it are only
+	// read at startup, so changes wait for a restart.
+	Apply func(value interface{})
+}
+
+// SettingValue is the current state of a setting
+type SettingValue struct {
+	Key             string      `json:"key"`
+	Type            string      `json:"type"`
+	Description     string      `json:"description"`
+	Value           interface{} `json:"value"`
+	Default         interface{} `json:"default"`
+	Min             *float64    `json:"min,omitempty"`
+	Max             *float64    `json:"max,omitempty"`
+	RestartRequired bool        `json:"restart_required"`
+	// PendingRestart is set when a restart-only setting differs from the value the server
+	// started with
+	PendingRestart bool       `json:"pending_restart"`
+	UpdatedBy      string     `json:"updated_by,omitempty"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+}
+
+// ConfigChange is an audit entry for one setting change
+type ConfigChange struct {
+	ID        string      `json:"id"`
+	Key       string      `json:"key"`
+	OldValue  interface{} `json:"old_value"`
+	NewValue  interface{} `json:"new_value"`
+	ChangedBy string      `json:"changed_by"`
+	ChangedAt time.Time   `json:"changed_at"`
+}
+
+// storedSetting records who last saved a setting through the config API
+type storedSetting struct {
+	updatedBy string
+	updatedAt time.Time
+}
+
+// SystemConfigService holds the runtime-tunable settings. Values saved through the API are
+// persisted, override the defaults the server was started with, and are applied at once
+// unless the setting is only read at startup. Every change is written to an audit log.
+type SystemConfigService struct {
+	db     *sql.DB
+	logger *logging.Logger
+
+	mu       sync.Mutex
+	settings []*Setting
+	byKey    map[string]*Setting
+	values   map[string]interface{}
+	started  map[string]interface{} // Values of restart-only settings when the server started
+	stored   map[string]*storedSetting
+	raw      map[string]string // Persisted values of settings not registered yet
+}
+
+// NewSystemConfigService creates a config service, reading the settings saved earlier. The
+// saved values take effect as each setting is registered.
+func NewSystemConfigService(ctx context.Context, db *sql.DB) (*SystemConfigService, error) {
+	s := &SystemConfigService{
+		db:      db,
+		logger:  logging.GetGlobalLogger().WithComponent("system_config"),
+		byKey:   make(map[string]*Setting),
+		values:  make(map[string]interface{}),
+		started: make(map[string]interface{}),
+		stored:  make(map[string]*storedSetting),
+		raw:     make(map[string]string),
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT key, value, COALESCE(updated_by, ''), updated_at FROM system_settings`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query system settings: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key, value string
+		stored := &storedSetting{}
+		if err := rows.Scan(&key, &value, &stored.updatedBy, &stored.updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan system setting: %w", err)
+		}
+		s.raw[key] = value
+		s.stored[key] = stored
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read system settings: %w", err)
+	}
+
+	return s, nil
+}
+
+// Register adds a setting. A value saved earlier replaces the default, and live settings are
+// applied straight away; a saved value the setting no longer accepts is ignored.
+func (s *SystemConfigService) Register(setting Setting) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value := setting.Default
+	if raw, ok := s.raw[setting.Key]; ok {
+		var saved interface{}
+		err := json.Unmarshal([]byte(raw), &saved)
+		if err == nil {
+			saved, err = normalizeSetting(&setting, saved)
+		}
+		if err != nil {
+			s.logger.Warn("Ignoring saved setting",
+				s.logger.WithMetadata(map[string]interface{}{"key": setting.Key, "error": err.Error()}))
+			delete(s.stored, setting.Key)
+		} else {
+			value = saved
+		}
+		delete(s.raw, setting.Key)
+	}
+	if setting.Apply != nil {
+		setting.Apply(value)
+	}
+
+	registered := setting
+	s.settings = append(s.settings, &registered)
+	s.byKey[setting.Key] = &registered
+	s.values[setting.Key] = value
+	if setting.Apply == nil {
+		s.started[setting.Key] = value
+	}
+}
+
+// normalizeSetting checks a value against a setting, converting JSON numbers to the setting's
+// type
+func normalizeSetting(setting *Setting, value interface{}) (interface{}, error) {
+	switch setting.Type {
+	case SettingBool:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be true or false", ErrInvalidSetting, setting.Key)
+		}
+		return b, nil
+	case SettingInt, SettingFloat:
+		var number float64
+		switch v := value.(type) {
+		case float64:
+			number = v
+		case int:
+			number = float64(v)
+		default:
+			return nil, fmt.Errorf("%w: %s must be a number", ErrInvalidSetting, setting.Key)
+		}
+		if number < setting.Min || number > setting.Max {
+			return nil, fmt.Errorf("%w: %s must be between %g and %g", ErrInvalidSetting, setting.Key, setting.Min, setting.Max)
+		}
+		if setting.Type == SettingFloat {
+			return number, nil
+		}
+		if number != math.Trunc(number) {
+			return nil, fmt.Errorf("%w: %s must be a whole number", ErrInvalidSetting, setting.Key)
+		}
+		return int(number), nil
+	default:
+		return nil, fmt.Errorf("%w: %s has unknown type %s", ErrInvalidSetting, setting.Key, setting.Type)
+	}
+}
+
+// Int returns the current value of an int setting, or 0 if it is not registered
+func (s *SystemConfigService) Int(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, _ := s.values[key].(int)
+	return value
+}
+
+// Float returns the current value of a float setting, or 0 if it is not registered
+func (s *SystemConfigService) Float(key string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, _ := s.values[key].(float64)
+	return value
+}
+
+// Bool returns the current value of a bool setting, or false if it is not registered
+func (s *SystemConfigService) Bool(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, _ := s.values[key].(bool)
+	return value
+}
+
+// List returns every setting in registration order
+func (s *SystemConfigService) List() []SettingValue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := make([]SettingValue, 0, len(s.settings))
+	for _, setting := range s.settings {
+		value := SettingValue{
+			Key:             setting.Key,
+			Type:            setting.Type,
+			Description:     setting.Description,
+			Value:           s.values[setting.Key],
+			Default:         setting.Default,
+			RestartRequired: setting.Apply == nil,
+		}
+		if setting.Type != SettingBool {
+			min, max := setting.Min, setting.Max
+			value.Min, value.Max = &min, &max
+		}
+		if value.RestartRequired {
+			value.PendingRestart = s.values[setting.Key] != s.started[setting.Key]
+		}
+		if stored, ok := s.stored[setting.Key]; ok {
+			updatedAt := stored.updatedAt
+			value.UpdatedBy = stored.updatedBy
+			value.UpdatedAt = &updatedAt
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// Update validates and saves a set of changes, applying the live ones, and records an audit
+// entry for each setting whose value changed. Nothing is saved if any change is invalid.
+func (s *SystemConfigService) Update(ctx context.Context, changes map[string]interface{}, actor string) ([]ConfigChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	normalized := make(map[string]interface{}, len(changes))
+	for key, value := range changes {
+		setting, ok := s.byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown setting %s", ErrInvalidSetting, key)
+		}
+		converted, err := normalizeSetting(setting, value)
+		if err != nil {
+			return nil, err
+		}
+		normalized[key] = converted
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	applied := []ConfigChange{}
+	// Settings are saved in registration order so audit entries are deterministic
+	for _, setting := range s.settings {
+		value, ok := normalized[setting.Key]
+		if !ok || value == s.values[setting.Key] {
+			continue
+		}
+
+		oldJSON, _ := json.Marshal(s.values[setting.Key])
+		newJSON, _ := json.Marshal(value)
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR REPLACE INTO system_settings (key, value, updated_by, updated_at) VALUES (?, ?, ?, ?)
+		`, setting.Key, string(newJSON), actor, now); err != nil {
+			return nil, fmt.Errorf("failed to save setting %s: %w", setting.Key, err)
+		}
+
+		change := ConfigChange{
+			ID:        uuid.New().String(),
+			Key:       setting.Key,
+			OldValue:  s.values[setting.Key],
+			NewValue:  value,
+			ChangedBy: actor,
+			ChangedAt: now,
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO config_audit (id, setting_key, old_value, new_value, changed_by, changed_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, change.ID, change.Key, string(oldJSON), string(newJSON), actor, now); err != nil {
+			return nil, fmt.Errorf("failed to audit setting %s: %w", setting.Key, err)
+		}
+		applied = append(applied, change)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit settings: %w", err)
+	}
+
+	for _, change := range applied {
+		setting := s.byKey[change.Key]
+		s.values[change.Key] = change.NewValue
+		s.stored[change.Key] = &storedSetting{updatedBy: actor, updatedAt: now}
+		if setting.Apply != nil {
+			setting.Apply(change.NewValue)
+		}
+	}
+
+	return applied, nil
+}
+
+// ListAudit returns the most recent setting changes, newest first, optionally for one setting
+func (s *SystemConfigService) ListAudit(ctx context.Context, key string, limit int) ([]ConfigChange, error) {
+	query := `
+		SELECT id, setting_key, old_value, new_value, COALESCE(changed_by, ''), changed_at
+		FROM config_audit
+	`
+	args := []interface{}{}
+	if key != "" {
+		query += " WHERE setting_key = ?"
+		args = append(args, key)
+	}
+	query += " ORDER BY changed_at DESC, id LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query config audit: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []ConfigChange{}
+	for rows.Next() {
+		var change ConfigChange
+		var oldValue sql.NullString
+		var newValue string
+		if err := rows.Scan(&change.ID, &change.Key, &oldValue, &newValue, &change.ChangedBy, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan config audit: %w", err)
+		}
+		if oldValue.Valid {
+			json.Unmarshal([]byte(oldValue.String), &change.OldValue)
+		}
+		json.Unmarshal([]byte(newValue), &change.NewValue)
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read config audit: %w", err)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"incident-management-system/internal/database"
+)
+
+func TestSystemConfigService_UpdateAppliesPersistsAndAudits(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+
+	var workers int
+	register := func(service *SystemConfigService) {
+		service.Register(Setting{
+			Key: "processing.parser_workers", Type: SettingInt, Default: 4, Min: 1, Max: 64,
+			Apply: func(value interface{}) { workers = value.(int) },
+		})
+		service.Register(Setting{Key: "jobs.workers", Type: SettingInt, Default: 3, Min: 1, Max: 64})
+		service.Register(Setting{Key: "usage.tracking_enabled", Type: SettingBool, Default: true})
+	}
+
+	service, err := NewSystemConfigService(ctx, db)
+	if err != nil {
+		t.Fatalf("NewSystemConfigService() error = %v", err)
+	}
+	register(service)
+	if workers != 4 {
+		t.Errorf("expected the default to be applied on registration, got %d workers", workers)
+	}
+
+	invalid := []map[string]interface{}{
+		{"unknown.setting": 1},
+		{"processing.parser_workers": 0.0},
+		{"processing.parser_workers": 2.5},
+		{"usage.tracking_enabled": "yes"},
+		// A valid change is not saved alongside an invalid one
+		{"jobs.workers": 5.0, "processing.parser_workers": 100.0},
+	}
+	for _, changes := range invalid {
+		if _, err := service.Update(ctx, changes, "admin"); !errors.Is(err, ErrInvalidSetting) {
+			t.Errorf("Update(%v) error = %v, want ErrInvalidSetting", changes, err)
+		}
+	}
+	if service.Int("jobs.workers") != 3 {
+		t.Errorf("rejected update changed jobs.workers to %d", service.Int("jobs.workers"))
+	}
+
+	changes, err := service.Update(ctx, map[string]interface{}{
+		"processing.parser_workers": 8.0,
+		"jobs.workers":              6.0,
+		"usage.tracking_enabled":    true, // unchanged, so not audited
+	}, "alice")
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if len(changes) != 2 || changes[0].Key != "processing.parser_workers" || changes[1].Key != "jobs.workers" {
+		t.Fatalf("expected two changes in registration order, got %+v", changes)
+	}
+	if workers != 8 {
+		t.Errorf("expected the live setting to be applied, got %d workers", workers)
+	}
+
+	values := map[string]SettingValue{}
+	for _, value := range service.List() {
+		values[value.Key] = value
+	}
+	if !values["jobs.workers"].RestartRequired || !values["jobs.workers"].PendingRestart {
+		t.Errorf("expected jobs.workers to wait for a restart: %+v", values["jobs.workers"])
+	}
+	if values["processing.parser_workers"].RestartRequired || values["processing.parser_workers"].UpdatedBy != "alice" {
+		t.Errorf("unexpected parser workers state: %+v", values["processing.parser_workers"])
+	}
+	if values["usage.tracking_enabled"].UpdatedAt != nil {
+		t.Errorf("unchanged setting should not be marked as updated")
+	}
+
+	audit, err := service.ListAudit(ctx, "jobs.workers", 10)
+	if err != nil {
+		t.Fatalf("ListAudit() error = %v", err)
+	}
+	if len(audit) != 1 || audit[0].OldValue != float64(3) || audit[0].NewValue != float64(6) || audit[0].ChangedBy != "alice" {
+		t.Errorf("unexpected audit entries %+v", audit)
+	}
+
+	// A restarted server starts with the saved values
+	workers = 0
+	restarted, err := NewSystemConfigService(ctx, db)
+	if err != nil {
+		t.Fatalf("NewSystemConfigService() error = %v", err)
+	}
+	register(restarted)
+	if workers != 8 || restarted.Int("jobs.workers") != 6 {
+		t.Errorf("saved values not loaded: parser workers %d, jobs.workers %d", workers, restarted.Int("jobs.workers"))
+	}
+	for _, value := range restarted.List() {
+		if value.PendingRestart {
+			t.Errorf("%s should not be pending a restart after one", value.Key)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultTextProfileLimit is how many uploads and applications the text profile lists by default
+const DefaultTextProfileLimit = 20
+
+// Languages reported by DetectLanguage besides the ISO 639-1 codes of languageStopwords
+const (
+	LanguageUnknown = "unknown" // too short, or no common words of a known language
+	LanguageOther   = "other"   // mostly written in a non-Latin script
+)
+
+// minLanguageWords is the fewest words a text needs for its language to be detected
+const minLanguageWords = 3
+
+// Levels past which the text profile reports that the sentiment and automation scores of a
+// source are doubtful: both analyzers match English words in the brief and full descriptions
+const (
+	TextProfileMinEnglishPct   = 80.0
+	TextProfileMaxEmptyPct     = 20.0
+	TextProfileMinAverageWords = 5.0
+)
+
+// Scoring concerns reported by the text profile
+const (
+	TextConcernNonEnglish        = "non_english"
+	TextConcernEmptyDescriptions = "empty_descriptions"
+	TextConcernShortDescriptions = "short_descriptions"
+)
+
+// languageStopwords are frequent words of each detected language that rarely occur in the
+// others, in detection order: ties go to the earlier language
+var languageStopwords = []struct {
+	language string
+	words    []string
+}{
+	{"en", []string{"the", "and", "is", "are", "not", "to", "of", "with", "for", "was", "cannot", "unable", "when", "after", "please"}},
+	{"de", []string{"der", "die", "das", "und", "ist", "nicht", "mit", "für", "auf", "bei", "kann", "ein", "eine", "wird", "nach"}},
+	{"fr", []string{"le", "les", "et", "est", "pas", "avec", "pour", "sur", "une", "dans", "ne", "au", "du", "aux", "sont"}},
+	{"es", []string{"el", "los", "las", "y", "es", "con", "para", "una", "del", "que", "por", "al", "se", "está", "puede"}},
+	{"it", []string{"il", "lo", "gli", "è", "non", "per", "della", "che", "sono", "di", "nel", "alla", "questo", "funziona", "anche"}},
+	{"nl", []string{"het", "een", "niet", "met", "voor", "op", "van", "bij", "geen", "wordt", "werkt", "kan", "ook", "naar", "zijn"}},
+	{"pt", []string{"os", "não", "com", "uma", "do", "da", "em", "ao", "são", "está", "pode", "funciona", "foi", "também", "nos"}},
+}
+
+// languageWords maps each stopword to the languages it belongs to
+var languageWords = func() map[string][]string {
+	words := map[string][]string{}
+	for _, language := range languageStopwords {
+		for _, word := range language.words {
+			words[word] = append(words[word], language.language)
+		}
+	}
+	return words
+}()
+
+// Kinds of personal data DetectPII finds
+const (
+	PIIEmail      = "email"
+	PIIPhone      = "phone"
+	PIICardNumber = "card_number"
+	PIINationalID = "national_id"
+)
+
+var (
+	piiEmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// piiPhonePattern matches international numbers with a leading + and North American style
+	// numbers, but not dates or plain ticket numbers
+	piiPhonePattern = regexp.MustCompile(`(\+\d{1,3}[\s.-]?\(?\d{1,4}\)?([\s.-]?\d{2,4}){2,4}\b|\(?\b\d{3}\)?[\s.-]\d{3}[\s.-]\d{4}\b)`)
+	// piiCardPattern matches 13 to 19 digits, optionally grouped by spaces or dashes; matches are
+	// kept only when they pass the Luhn check
+	piiCardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	// piiNationalIDPattern matches US social security numbers
+	piiNationalIDPattern = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+)
+
+// TextProfile describes the description text of a set of incidents. Percentages are shares of
+// its incidents, except EnglishPct, the share of those whose language was detected.
+type TextProfile struct {
+	ID                   string         `json:"id,omitempty"` // upload ID
+	Name                 string         `json:"name"`
+	Incidents            int            `json:"incidents"`
+	Languages            map[string]int `json:"languages"`
+	EnglishPct           float64        `json:"english_pct"`
+	AvgDescriptionLength float64        `json:"avg_description_length"` // characters, of non-empty descriptions
+	AvgWords             float64        `json:"avg_words"`              // of brief and full description together
+	EmptyDescriptionPct  float64        `json:"empty_description_pct"`
+	PIIIncidents         int            `json:"pii_incidents"`
+	PIIPct               float64        `json:"pii_pct"`
+	PII                  map[string]int `json:"pii"` // incidents by kind of personal data found
+	// ScoringConcerns lists why the sentiment and automation scores of these incidents may not be
+	// trustworthy; empty when none
+	ScoringConcerns []string `json:"scoring_concerns"`
+
+	detected, described, descriptionLength, words int
+}
+
+// TextProfileReport profiles incident descriptions overall, by upload and by application. Uploads
+// and applications are ranked by incident count.
+type TextProfileReport struct {
+	Overall      TextProfile   `json:"overall"`
+	Uploads      []TextProfile `json:"uploads"`
+	Applications []TextProfile `json:"applications"`
+}
+
+// DetectLanguage returns the ISO 639-1 code of the language text is most likely written in, by
+// its common words, LanguageOther for text mostly in a non-Latin script, or LanguageUnknown when
+// it cannot tell
+func DetectLanguage(text string) string {
+	var latin, letters int
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.Is(unicode.Latin, r) {
+				latin++
+			}
+		}
+	}
+	if letters > 0 && latin*2 < letters {
+		return LanguageOther
+	}
+
+	words := textWords(text)
+	if len(words) < minLanguageWords {
+		return LanguageUnknown
+	}
+	hits := map[string]int{}
+	for _, word := range words {
+		for _, language := range languageWords[word] {
+			hits[language]++
+		}
+	}
+	best, bestHits := LanguageUnknown, 0
+	for _, language := range languageStopwords {
+		if hits[language.language] > bestHits {
+			best, bestHits = language.language, hits[language.language]
+		}
+	}
+	return best
+}
+
+// DetectPII returns the kinds of personal data found in text, in a fixed order
+func DetectPII(text string) []string {
+	var kinds []string
+	if piiEmailPattern.MatchString(text) {
+		kinds = append(kinds, PIIEmail)
+	}
+	if piiNationalIDPattern.MatchString(text) {
+		kinds = append(kinds, PIINationalID)
+		text = piiNationalIDPattern.ReplaceAllString(text, " ")
+	}
+	for _, match := range piiCardPattern.FindAllString(text, -1) {
+		if luhnValid(match) {
+			kinds = append(kinds, PIICardNumber)
+			break
+		}
+	}
+	if piiPhonePattern.MatchString(text) {
+		kinds = append(kinds, PIIPhone)
+	}
+	return kinds
+}
+
+// luhnValid reports whether the digits of number pass the Luhn checksum of payment card numbers
+func luhnValid(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		if number[i] < '0' || number[i] > '9' {
+			continue
+		}
+		digit := int(number[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// textWords splits text into lowercase words
+func textWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// newTextProfile creates an empty profile
+func newTextProfile(id, name string) *TextProfile {
+	return &TextProfile{ID: id, Name: name, Languages: map[string]int{}, PII: map[string]int{}, ScoringConcerns: []string{}}
+}
+
+// add counts one incident's descriptions in the profile
+func (p *TextProfile) add(language, brief, description string, pii []string) {
+	p.Incidents++
+	p.Languages[language]++
+	if language != LanguageUnknown {
+		p.detected++
+	}
+	if description = strings.TrimSpace(description); description != "" {
+		p.described++
+		p.descriptionLength += utf8.RuneCountInString(description)
+	}
+	p.words += len(textWords(brief)) + len(textWords(description))
+	if len(pii) > 0 {
+		p.PIIIncidents++
+	}
+	for _, kind := range pii {
+		p.PII[kind]++
+	}
+}
+
+// finish computes the profile's averages, percentages and scoring concerns
+func (p *TextProfile) finish() {
+	if p.Incidents == 0 {
+		return
+	}
+	total := float64(p.Incidents)
+	if p.detected > 0 {
+		p.EnglishPct = math.Round(float64(p.Languages["en"])/float64(p.detected)*1000) / 10
+	}
+	if p.described > 0 {
+		p.AvgDescriptionLength = math.Round(float64(p.descriptionLength)/float64(p.described)*10) / 10
+	}
+	p.AvgWords = math.Round(float64(p.words)/total*10) / 10
+	p.EmptyDescriptionPct = math.Round(float64(p.Incidents-p.described)/total*1000) / 10
+	p.PIIPct = math.Round(float64(p.PIIIncidents)/total*1000) / 10
+
+	if p.detected > 0 && p.EnglishPct < TextProfileMinEnglishPct {
+		p.ScoringConcerns = append(p.ScoringConcerns, TextConcernNonEnglish)
+	}
+	if p.EmptyDescriptionPct > TextProfileMaxEmptyPct {
+		p.ScoringConcerns = append(p.ScoringConcerns, TextConcernEmptyDescriptions)
+	}
+	if p.AvgWords < TextProfileMinAverageWords {
+		p.ScoringConcerns = append(p.ScoringConcerns, TextConcernShortDescriptions)
+	}
+}
+
+// GetTextProfile profiles the descriptions of the incidents matching the filters: the languages
+// they are written in, their length, how many are empty and how many contain personal data, with
+// the concerns these raise for the sentiment and automation scores. Up to limit uploads and
+// applications with the most incidents are listed.
+func (s *AnalyticsService) GetTextProfile(ctx context.Context, filters *TimelineFilters, limit int) (*TextProfileReport, error) {
+	if limit <= 0 {
+		limit = DefaultTextProfileLimit
+	}
+
+	whereClause, args, _ := buildFilterConditions(ctx, filters, 1)
+	query := `
+		SELECT upload_id, COALESCE(application_name, ''), COALESCE(brief_description, ''), COALESCE(description, '')
+		FROM incidents
+		WHERE 1=1` + whereClause
+
+	rows, err := s.db.QueryContext(ctx, s.federate(ctx, query, filters), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incident descriptions: %w", err)
+	}
+	defer rows.Close()
+
+	report := &TextProfileReport{Overall: *newTextProfile("", "all")}
+	uploads := map[string]*TextProfile{}
+	applications := map[string]*TextProfile{}
+	for rows.Next() {
+		var uploadID, application, brief, description string
+		if err := rows.Scan(&uploadID, &application, &brief, &description); err != nil {
+			return nil, fmt.Errorf("failed to scan incident description: %w", err)
+		}
+
+		text := brief + "\n" + description
+		language := DetectLanguage(text)
+		pii := DetectPII(text)
+
+		upload, ok := uploads[uploadID]
+		if !ok {
+			upload = newTextProfile(uploadID, "")
+			uploads[uploadID] = upload
+		}
+		app, ok := applications[application]
+		if !ok {
+			app = newTextProfile("", application)
+			applications[application] = app
+		}
+		for _, profile := range []*TextProfile{&report.Overall, upload, app} {
+			profile.add(language, brief, description, pii)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating incident descriptions: %w", err)
+	}
+
+	report.Overall.finish()
+	report.Uploads = rankTextProfiles(uploads, limit)
+	report.Applications = rankTextProfiles(applications, limit)
+	if err := s.nameUploadProfiles(ctx, report.Uploads); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// rankTextProfiles finishes the profiles and returns up to limit of them, most incidents first
+func rankTextProfiles(profiles map[string]*TextProfile, limit int) []TextProfile {
+	ranked := make([]TextProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		profile.finish()
+		ranked = append(ranked, *profile)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Incidents != ranked[j].Incidents {
+			return ranked[i].Incidents > ranked[j].Incidents
+		}
+		if ranked[i].Name != ranked[j].Name {
+			return ranked[i].Name < ranked[j].Name
+		}
+		return ranked[i].ID < ranked[j].ID
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// nameUploadProfiles names upload profiles after the file uploaded
+func (s *AnalyticsService) nameUploadProfiles(ctx context.Context, profiles []TextProfile) error {
+	if len(profiles) == 0 {
+		return nil
+	}
+	placeholders := make([]string, len(profiles))
+	args := make([]interface{}, len(profiles))
+	for i, profile := range profiles {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = profile.ID
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, original_filename FROM uploads WHERE id IN ("+strings.Join(placeholders, ", ")+")", args...)
+	if err != nil {
+		return fmt.Errorf("failed to query upload names: %w", err)
+	}
+	defer rows.Close()
+
+	names := map[string]string{}
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return fmt.Errorf("failed to scan upload name: %w", err)
+		}
+		names[id] = name
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating upload names: %w", err)
+	}
+	for i := range profiles {
+		profiles[i].Name = names[profiles[i].ID]
+	}
+	return nil
+}
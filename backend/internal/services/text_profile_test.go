@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"incident-management-system/internal/models"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"User is unable to log in to the portal after the upgrade", "en"},
+		{"Der Benutzer kann sich nicht mit dem Portal verbinden", "de"},
+		{"L'utilisateur ne peut pas se connecter avec le portail", "fr"},
+		{"El usuario no puede acceder al portal con su cuenta", "es"},
+		{"De gebruiker kan niet inloggen op het portaal", "nl"},
+		{"VPN down", LanguageUnknown},
+		{"Outlook crashes repeatedly", LanguageUnknown},
+		{"Пользователь не может войти в портал", LanguageOther},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.text); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDetectPII(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Call jane.doe@example.com or +44 20 7946 0958", []string{PIIEmail, PIIPhone}},
+		{"Callback requested on (555) 123-4567", []string{PIIPhone}},
+		{"Customer paid with 4111 1111 1111 1111", []string{PIICardNumber}},
+		{"SSN 123-45-6789 in the ticket", []string{PIINationalID}},
+		{"Reported 2024-01-15 10:30, see INC1234567890123 and 10.0.0.1", nil},
+	}
+	for _, tt := range tests {
+		if got := DetectPII(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DetectPII(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyticsService_GetTextProfile(t *testing.T) {
+	incident := func(id, application, brief, description string) models.Incident {
+		incident := diffTestIncident(id, "upload-1", "INC-"+id, "P3", "Closed")
+		incident.ApplicationName = application
+		incident.BriefDescription = brief
+		incident.Description = description
+		return incident
+	}
+	dbWrapper, db := newSentimentTestDB(t, []models.Incident{
+		incident("1", "Portal", "Login fails", "The user is unable to log in after the password reset, contact jane@example.com"),
+		incident("2", "Portal", "Slow pages", "Pages of the portal are slow to load for all users"),
+		incident("3", "SAP", "Buchung fehlerhaft", "Die Buchung ist nicht mit dem Auftrag verknüpft"),
+		incident("4", "SAP", "Fehler", ""),
+	})
+	defer dbWrapper.Close()
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, "INSERT INTO uploads (id, filename, original_filename, status) VALUES ('upload-1', 'f.xlsx', 'march.xlsx', 'completed')"); err != nil {
+		t.Fatalf("failed to insert upload: %v", err)
+	}
+
+	report, err := NewAnalyticsService(db).GetTextProfile(ctx, nil, 0)
+	if err != nil {
+		t.Fatalf("GetTextProfile() error = %v", err)
+	}
+
+	overall := report.Overall
+	if overall.Incidents != 4 || !reflect.DeepEqual(overall.Languages, map[string]int{"en": 2, "de": 1, LanguageUnknown: 1}) {
+		t.Errorf("unexpected overall languages: %+v", overall)
+	}
+	if overall.EnglishPct != 66.7 || overall.EmptyDescriptionPct != 25 || overall.PIIIncidents != 1 || overall.PII[PIIEmail] != 1 {
+		t.Errorf("unexpected overall profile: %+v", overall)
+	}
+	if !reflect.DeepEqual(overall.ScoringConcerns, []string{TextConcernNonEnglish, TextConcernEmptyDescriptions}) {
+		t.Errorf("unexpected overall concerns: %v", overall.ScoringConcerns)
+	}
+
+	if len(report.Uploads) != 1 || report.Uploads[0].ID != "upload-1" || report.Uploads[0].Name != "march.xlsx" {
+		t.Errorf("unexpected uploads: %+v", report.Uploads)
+	}
+	if len(report.Applications) != 2 || report.Applications[0].Name != "Portal" {
+		t.Fatalf("unexpected applications: %+v", report.Applications)
+	}
+	if portal := report.Applications[0]; portal.EnglishPct != 100 || len(portal.ScoringConcerns) != 0 {
+		t.Errorf("expected no concerns for Portal, got %+v", portal)
+	}
+	sap := report.Applications[1]
+	if !reflect.DeepEqual(sap.ScoringConcerns, []string{TextConcernNonEnglish, TextConcernEmptyDescriptions}) {
+		t.Errorf("unexpected SAP concerns: %v", sap.ScoringConcerns)
+	}
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Continuity check statuses
+const (
+	ContinuityOK            = "ok"
+	ContinuityDiscrepancies = "discrepancies"
+	ContinuityNoPrevious    = "no_previous_upload" // no earlier upload to compare with
+	ContinuityNoOverlap     = "no_overlap"         // the uploads share no report days
+)
+
+// DefaultContinuityThreshold is the percentage by which a day's incident count for an application
+// may differ from the previous upload before it is reported
+const DefaultContinuityThreshold = 20.0
+
+// ContinuityDiscrepancy is a day and application whose incident count differs between an upload
+// and the upload before it by more than the threshold
+type ContinuityDiscrepancy struct {
+	Date            string  `json:"date"`
+	ApplicationName string  `json:"application_name"`
+	PreviousCount   int     `json:"previous_count"`
+	CurrentCount    int     `json:"current_count"`
+	DifferencePct   float64 `json:"difference_pct"`
+}
+
+// UploadContinuity is the result of comparing an upload with the upload before it over the
+// report days both cover. Monthly extracts usually overlap by a few days, and counts for those
+// days should agree; when they do not, one of the extracts is likely incomplete.
+type UploadContinuity struct {
+	UploadID         string `json:"upload_id"`
+	PreviousUploadID string `json:"previous_upload_id,omitempty"`
+	Status           string `json:"status"`
+	OverlapStart     string `json:"overlap_start,omitempty"`
+	OverlapEnd       string `json:"overlap_end,omitempty"`
+	OverlapDays      int    `json:"overlap_days"`
+	// ComparedCount is the number of day and application pairs compared
+	ComparedCount int                     `json:"compared_count"`
+	PreviousTotal int                     `json:"previous_total"`
+	CurrentTotal  int                     `json:"current_total"`
+	ThresholdPct  float64                 `json:"threshold_pct"`
+	Discrepancies []ContinuityDiscrepancy `json:"discrepancies"`
+	AlertID       string                  `json:"alert_id,omitempty"`
+	CheckedAt     time.Time               `json:"checked_at"`
+}
+
+// UploadContinuityService checks each processed upload against the upload before it
+type UploadContinuityService struct {
+	db     *sql.DB
+	alerts *DataQualityService
+
+	mu        sync.Mutex
+	threshold float64
+}
+
+// NewUploadContinuityService creates a new UploadContinuityService instance
+func NewUploadContinuityService(db *sql.DB) *UploadContinuityService {
+	return &UploadContinuityService{
+		db:        db,
+		alerts:    NewDataQualityService(db),
+		threshold: DefaultContinuityThreshold,
+	}
+}
+
+// SetThreshold changes the percentage difference reported as a discrepancy, taking effect for
+// the next check
+func (s *UploadContinuityService) SetThreshold(pct float64) {
+	s.mu.Lock()
+	s.threshold = pct
+	s.mu.Unlock()
+}
+
+// Threshold returns the percentage difference reported as a discrepancy
+func (s *UploadContinuityService) Threshold() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.threshold
+}
+
+// CheckUpload compares the incidents of an upload per report day and application with those of
+// the latest completed upload created before it, over the days both cover. Discrepancies above
+// the threshold raise a data-quality alert. The result replaces any earlier check of the upload.
+func (s *UploadContinuityService) CheckUpload(ctx context.Context, uploadID string) (*UploadContinuity, error) {
+	continuity := &UploadContinuity{
+		UploadID:      uploadID,
+		Status:        ContinuityNoPrevious,
+		ThresholdPct:  s.Threshold(),
+		Discrepancies: []ContinuityDiscrepancy{},
+		CheckedAt:     time.Now().UTC(),
+	}
+
+	previousID, err := s.previousUpload(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if previousID != "" {
+		continuity.PreviousUploadID = previousID
+		if err := s.compare(ctx, continuity); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(continuity.Discrepancies) > 0 {
+		alert, err := s.raiseAlert(ctx, continuity)
+		if err != nil {
+			return nil, err
+		}
+		continuity.AlertID = alert.ID
+	}
+
+	if err := s.save(ctx, continuity); err != nil {
+		return nil, err
+	}
+	return continuity, nil
+}
+
+// previousUpload returns the latest completed upload with incidents created before uploadID, or
+// an empty ID when there is none. The uploads collecting manually entered incidents are not
+// exports of the source system, so they are skipped.
+func (s *UploadContinuityService) previousUpload(ctx context.Context, uploadID string) (string, error) {
+	query := `
+		SELECT u.id
+		FROM uploads u
+		WHERE u.status IN ('completed', 'completed_with_errors') AND u.id <> ? AND u.original_filename NOT LIKE ?
+			AND u.created_at < (SELECT created_at FROM uploads WHERE id = ?)
+			AND EXISTS (SELECT 1 FROM incidents i WHERE i.upload_id = u.id)
+		ORDER BY u.created_at DESC, u.id DESC
+		LIMIT 1
+	`
+	var previousID string
+	err := s.db.QueryRowContext(ctx, query, uploadID, ManualEntryPrefix+"%", uploadID).Scan(&previousID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find previous upload: %w", err)
+	}
+	return previousID, nil
+}
+
+// compare fills in the overlap of the two uploads and the discrepancies found in it
+func (s *UploadContinuityService) compare(ctx context.Context, continuity *UploadContinuity) error {
+	var start, end sql.NullTime
+	var ranges int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT MAX(first_day), MIN(last_day), COUNT(*)
+		FROM (
+			SELECT CAST(MIN(report_date) AS DATE) as first_day, CAST(MAX(report_date) AS DATE) as last_day
+			FROM incidents
+			WHERE upload_id IN (?, ?)
+			GROUP BY upload_id
+		) ranges
+	`, continuity.UploadID, continuity.PreviousUploadID).Scan(&start, &end, &ranges)
+	if err != nil {
+		return fmt.Errorf("failed to find upload overlap: %w", err)
+	}
+	if ranges < 2 || !start.Valid || !end.Valid || start.Time.After(end.Time) {
+		continuity.Status = ContinuityNoOverlap
+		return nil
+	}
+	continuity.OverlapStart = start.Time.Format("2006-01-02")
+	continuity.OverlapEnd = end.Time.Format("2006-01-02")
+	continuity.OverlapDays = int(end.Time.Sub(start.Time).Hours()/24) + 1
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			CAST(report_date AS DATE) as day,
+			COALESCE(application_name, '') as application,
+			COUNT(*) FILTER (WHERE upload_id = ?) as previous_count,
+			COUNT(*) FILTER (WHERE upload_id = ?) as current_count
+		FROM incidents
+		WHERE upload_id IN (?, ?) AND CAST(report_date AS DATE) BETWEEN ? AND ?
+		GROUP BY day, application
+		ORDER BY day, application
+	`, continuity.PreviousUploadID, continuity.UploadID, continuity.PreviousUploadID, continuity.UploadID,
+		start.Time, end.Time)
+	if err != nil {
+		return fmt.Errorf("failed to compare upload counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day time.Time
+		var entry ContinuityDiscrepancy
+		if err := rows.Scan(&day, &entry.ApplicationName, &entry.PreviousCount, &entry.CurrentCount); err != nil {
+			return fmt.Errorf("failed to scan upload counts: %w", err)
+		}
+		continuity.ComparedCount++
+		continuity.PreviousTotal += entry.PreviousCount
+		continuity.CurrentTotal += entry.CurrentCount
+
+		entry.DifferencePct = differencePct(entry.PreviousCount, entry.CurrentCount)
+		if entry.DifferencePct > continuity.ThresholdPct {
+			entry.Date = day.Format("2006-01-02")
+			continuity.Discrepancies = append(continuity.Discrepancies, entry)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating upload counts: %w", err)
+	}
+
+	continuity.Status = ContinuityOK
+	if len(continuity.Discrepancies) > 0 {
+		continuity.Status = ContinuityDiscrepancies
+	}
+	return nil
+}
+
+// differencePct is how much current differs from previous, as a percentage of previous. A count
+// that appears or disappears entirely differs by 100%.
+func differencePct(previous, current int) float64 {
+	if previous == current {
+		return 0
+	}
+	if previous == 0 || current == 0 {
+		return 100
+	}
+	return roundTo(math.Abs(float64(current-previous))/float64(previous)*100, 1)
+}
+
+// raiseAlert records a data-quality alert for the discrepancies of a check. It is high severity
+// when the overlap as a whole disagrees, not just the split across days or applications.
+func (s *UploadContinuityService) raiseAlert(ctx context.Context, continuity *UploadContinuity) (*DataQualityAlert, error) {
+	alert := &DataQualityAlert{
+		Type:     AlertUploadContinuity,
+		Severity: AlertSeverityWarning,
+		UploadID: continuity.UploadID,
+		Message: fmt.Sprintf("Upload %s disagrees with upload %s on %d of %d day and application counts between %s and %s",
+			continuity.UploadID, continuity.PreviousUploadID, len(continuity.Discrepancies), continuity.ComparedCount,
+			continuity.OverlapStart, continuity.OverlapEnd),
+	}
+	if differencePct(continuity.PreviousTotal, continuity.CurrentTotal) > continuity.ThresholdPct {
+		alert.Severity = AlertSeverityHigh
+	}
+
+	details := map[string]interface{}{
+		"previous_upload_id": continuity.PreviousUploadID,
+		"overlap_start":      continuity.OverlapStart,
+		"overlap_end":        continuity.OverlapEnd,
+		"discrepancy_count":  len(continuity.Discrepancies),
+		"previous_total":     continuity.PreviousTotal,
+		"current_total":      continuity.CurrentTotal,
+	}
+	if err := s.alerts.CreateAlert(ctx, alert, details); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// save stores a check, replacing the one recorded by an earlier run of the upload
+func (s *UploadContinuityService) save(ctx context.Context, continuity *UploadContinuity) error {
+	discrepancies, err := json.Marshal(continuity.Discrepancies)
+	if err != nil {
+		return fmt.Errorf("failed to encode continuity discrepancies: %w", err)
+	}
+
+	query := `
+		INSERT OR REPLACE INTO upload_continuity (
+			upload_id, previous_upload_id, status, overlap_start, overlap_end, compared_count,
+			previous_total, current_total, threshold_pct, discrepancies, alert_id, checked_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, continuity.UploadID, nullIfEmpty(continuity.PreviousUploadID),
+		continuity.Status, nullIfEmpty(continuity.OverlapStart), nullIfEmpty(continuity.OverlapEnd),
+		continuity.ComparedCount, continuity.PreviousTotal, continuity.CurrentTotal, continuity.ThresholdPct,
+		string(discrepancies), nullIfEmpty(continuity.AlertID), continuity.CheckedAt); err != nil {
+		return fmt.Errorf("failed to save upload continuity: %w", err)
+	}
+	return nil
+}
+
+// GetContinuity returns the last continuity check of an upload, or sql.ErrNoRows when it has
+// not been checked
+func (s *UploadContinuityService) GetContinuity(ctx context.Context, uploadID string) (*UploadContinuity, error) {
+	var continuity UploadContinuity
+	var start, end sql.NullTime
+	var discrepancies string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT upload_id, COALESCE(previous_upload_id, ''), status, overlap_start, overlap_end, compared_count,
+			previous_total, current_total, threshold_pct, COALESCE(discrepancies, '[]'), COALESCE(alert_id, ''),
+			checked_at
+		FROM upload_continuity
+		WHERE upload_id = ?
+	`, uploadID).Scan(&continuity.UploadID, &continuity.PreviousUploadID, &continuity.Status, &start, &end,
+		&continuity.ComparedCount, &continuity.PreviousTotal, &continuity.CurrentTotal, &continuity.ThresholdPct,
+		&discrepancies, &continuity.AlertID, &continuity.CheckedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to query upload continuity: %w", err)
+	}
+
+	if start.Valid && end.Valid {
+		continuity.OverlapStart = start.Time.Format("2006-01-02")
+		continuity.OverlapEnd = end.Time.Format("2006-01-02")
+		continuity.OverlapDays = int(end.Time.Sub(start.Time).Hours()/24) + 1
+	}
+	if err := json.Unmarshal([]byte(discrepancies), &continuity.Discrepancies); err != nil {
+		return nil, fmt.Errorf("failed to decode continuity discrepancies: %w", err)
+	}
+	return &continuity, nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/storage"
+)
+
+func TestProcessingService_ChecksUploadContinuity(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	dir := t.TempDir()
+	service := NewProcessingService(db, storage.NewFileStore(dir))
+	continuityService := NewUploadContinuityService(db)
+	ctx := context.Background()
+
+	// February's extract runs to March 3rd; March's starts on March 2nd and is missing a Billing
+	// incident of March 3rd
+	writeTestWorkbook(t, dir, "february.xlsx", [][]string{
+		{"Incident ID", "Report Date", "Application", "Priority", "Description"},
+		{"INC001", "2024-02-28", "Portal", "P2", "Login failure"},
+		{"INC002", "2024-03-02", "Portal", "P3", "Slow page"},
+		{"INC003", "2024-03-03", "Billing", "P1", "Outage"},
+		{"INC004", "2024-03-03", "Billing", "P3", "Invoice stuck"},
+	})
+	writeTestWorkbook(t, dir, "march.xlsx", [][]string{
+		{"Incident ID", "Report Date", "Application", "Priority", "Description"},
+		{"INC002", "2024-03-02", "Portal", "P3", "Slow page"},
+		{"INC003", "2024-03-03", "Billing", "P1", "Outage"},
+		{"INC005", "2024-03-10", "Portal", "P4", "Typo"},
+	})
+	created := time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC)
+	for i, upload := range []string{"february", "march"} {
+		if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status, created_at) VALUES (?, ?, ?, ?, ?)`,
+			upload, upload+".xlsx", upload+".xlsx", models.UploadStatusUploaded, created.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatalf("Failed to create upload: %v", err)
+		}
+		if _, err := service.ProcessUpload(ctx, upload); err != nil {
+			t.Fatalf("Failed to process upload %s: %v", upload, err)
+		}
+	}
+
+	first, err := continuityService.GetContinuity(ctx, "february")
+	if err != nil {
+		t.Fatalf("Failed to get continuity of the first upload: %v", err)
+	}
+	if first.Status != ContinuityNoPrevious || first.AlertID != "" {
+		t.Errorf("Expected no previous upload for the first upload, got %+v", first)
+	}
+
+	continuity, err := continuityService.GetContinuity(ctx, "march")
+	if err != nil {
+		t.Fatalf("Failed to get continuity: %v", err)
+	}
+	if continuity.Status != ContinuityDiscrepancies || continuity.PreviousUploadID != "february" {
+		t.Fatalf("Expected discrepancies against february, got %+v", continuity)
+	}
+	if continuity.OverlapStart != "2024-03-02" || continuity.OverlapEnd != "2024-03-03" || continuity.OverlapDays != 2 {
+		t.Errorf("Expected an overlap of March 2nd to 3rd, got %s to %s (%d days)",
+			continuity.OverlapStart, continuity.OverlapEnd, continuity.OverlapDays)
+	}
+	if continuity.ComparedCount != 2 || continuity.PreviousTotal != 3 || continuity.CurrentTotal != 2 {
+		t.Errorf("Expected 2 pairs with 3 and 2 incidents, got %+v", continuity)
+	}
+	expected := ContinuityDiscrepancy{Date: "2024-03-03", ApplicationName: "Billing", PreviousCount: 2, CurrentCount: 1, DifferencePct: 50}
+	if len(continuity.Discrepancies) != 1 || continuity.Discrepancies[0] != expected {
+		t.Errorf("Expected the Billing discrepancy of March 3rd, got %+v", continuity.Discrepancies)
+	}
+
+	alerts, err := NewDataQualityService(db).ListAlerts(ctx, "march", 0)
+	if err != nil {
+		t.Fatalf("Failed to list alerts: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].ID != continuity.AlertID || alerts[0].Type != AlertUploadContinuity {
+		t.Fatalf("Expected the continuity alert, got %+v", alerts)
+	}
+	// A third fewer incidents over the overlap as a whole is above the threshold too
+	if alerts[0].Severity != AlertSeverityHigh {
+		t.Errorf("Expected high severity, got %s", alerts[0].Severity)
+	}
+
+	// A higher threshold tolerates the difference and raises no new alert
+	continuityService.SetThreshold(60)
+	recheck, err := continuityService.CheckUpload(ctx, "march")
+	if err != nil {
+		t.Fatalf("Failed to recheck continuity: %v", err)
+	}
+	if recheck.Status != ContinuityOK || recheck.AlertID != "" || recheck.ThresholdPct != 60 {
+		t.Errorf("Expected no discrepancies at 60%%, got %+v", recheck)
+	}
+	if alerts, _ := NewDataQualityService(db).ListAlerts(ctx, "", 0); len(alerts) != 1 {
+		t.Errorf("Expected still one alert, got %d", len(alerts))
+	}
+
+	// Uploads that share no report days cannot be compared
+	if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status, created_at) VALUES (?, ?, ?, ?, ?)`,
+		"april", "april.xlsx", "april.xlsx", models.UploadStatusCompleted, created.Add(2*time.Hour)); err != nil {
+		t.Fatalf("Failed to create upload: %v", err)
+	}
+	april := diffTestIncident("april-1", "april", "INC100", "P3", "Open")
+	april.ReportDate = time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC)
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, []models.Incident{april}, "april"); err != nil {
+		t.Fatalf("Failed to insert incident: %v", err)
+	}
+	disjoint, err := continuityService.CheckUpload(ctx, "april")
+	if err != nil {
+		t.Fatalf("Failed to check continuity: %v", err)
+	}
+	if disjoint.Status != ContinuityNoOverlap || disjoint.PreviousUploadID != "march" {
+		t.Errorf("Expected no overlap with march, got %+v", disjoint)
+	}
+
+	if _, err := continuityService.GetContinuity(ctx, "missing"); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for an unchecked upload, got %v", err)
+	}
+}
+
+func TestDifferencePct(t *testing.T) {
+	tests := []struct {
+		previous, current int
+		expected          float64
+	}{
+		{previous: 4, current: 4, expected: 0},
+		{previous: 4, current: 5, expected: 25},
+		{previous: 3, current: 2, expected: 33.3},
+		{previous: 0, current: 2, expected: 100},
+		{previous: 2, current: 0, expected: 100},
+	}
+
+	for _, tt := range tests {
+		if got := differencePct(tt.previous, tt.current); got != tt.expected {
+			t.Errorf("differencePct(%d, %d) = %v, expected %v", tt.previous, tt.current, got, tt.expected)
+		}
+	}
+}
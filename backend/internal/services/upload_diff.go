@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"incident-management-system/internal/models"
+)
+
+// FieldChange describes a single field that differs between two versions of an incident
+type FieldChange struct {
+	Field    string `json:"field"`
+	OldValue string `json:"old_value"`
+	NewValue string `json:"new_value"`
+}
+
+// IncidentChange lists the field changes for an incident present in both uploads
+type IncidentChange struct {
+	IncidentID string        `json:"incident_id"`
+	Changes    []FieldChange `json:"changes"`
+}
+
+// UploadDiffSummary holds the counts for an upload diff
+type UploadDiffSummary struct {
+	AddedCount     int `json:"added_count"`
+	RemovedCount   int `json:"removed_count"`
+	ChangedCount   int `json:"changed_count"`
+	UnchangedCount int `json:"unchanged_count"`
+}
+
+// UploadDiff is the structured comparison of two uploads keyed by incident_id.
+// Added incidents exist only in the other upload, removed incidents only in the base upload.
+type UploadDiff struct {
+	BaseUploadID  string            `json:"base_upload_id"`
+	OtherUploadID string            `json:"other_upload_id"`
+	Summary       UploadDiffSummary `json:"summary"`
+	Added         []models.Incident `json:"added"`
+	Removed       []models.Incident `json:"removed"`
+	Changed       []IncidentChange  `json:"changed"`
+}
+
+// diffField extracts a comparable string value for one source field of an incident
+type diffField struct {
+	name  string
+	value func(*models.Incident) string
+}
+
+// incidentDiffFields lists the source fields compared between uploads.
+// Derived analysis fields are excluded since they are recomputed on every upload.
+var incidentDiffFields = []diffField{
+	{"report_date", func(i *models.Incident) string { return formatDiffDate(&i.ReportDate) }},
+	{"resolve_date", func(i *models.Incident) string { return formatDiffDate(i.ResolveDate) }},
+	{"last_resolve_date", func(i *models.Incident) string { return formatDiffDate(i.LastResolveDate) }},
+	{"brief_description", func(i *models.Incident) string { return i.BriefDescription }},
+	{"description", func(i *models.Incident) string { return i.Description }},
+	{"application_name", func(i *models.Incident) string { return i.ApplicationName }},
+	{"resolution_group", func(i *models.Incident) string { return i.ResolutionGroup }},
+	{"resolved_person", func(i *models.Incident) string { return i.ResolvedPerson }},
+	{"priority", func(i *models.Incident) string { return i.Priority }},
+	{"category", func(i *models.Incident) string { return i.Category }},
+	{"subcategory", func(i *models.Incident) string { return i.Subcategory }},
+	{"impact", func(i *models.Incident) string { return i.Impact }},
+	{"urgency", func(i *models.Incident) string { return i.Urgency }},
+	{"status", func(i *models.Incident) string { return i.Status }},
+	{"customer_affected", func(i *models.Incident) string { return i.CustomerAffected }},
+	{"business_service", func(i *models.Incident) string { return i.BusinessService }},
+	{"root_cause", func(i *models.Incident) string { return i.RootCause }},
+	{"resolution_notes", func(i *models.Incident) string { return i.ResolutionNotes }},
+	{"resolution_time_hours", func(i *models.Incident) string {
+		if i.ResolutionTimeHours == nil {
+			return ""
+		}
+		return strconv.Itoa(*i.ResolutionTimeHours)
+	}},
+	{"cost", func(i *models.Incident) string {
+		if i.Cost == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*i.Cost, 'f', -1, 64)
+	}},
+}
+
+// DiffUploads compares the incidents of two uploads by incident_id
+func (s *IncidentService) DiffUploads(ctx context.Context, baseUploadID, otherUploadID string) (*UploadDiff, error) {
+	baseIncidents, err := s.GetIncidentsByUpload(ctx, baseUploadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load base upload incidents: %w", err)
+	}
+
+	otherIncidents, err := s.GetIncidentsByUpload(ctx, otherUploadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load other upload incidents: %w", err)
+	}
+
+	return DiffIncidents(baseUploadID, otherUploadID, baseIncidents, otherIncidents), nil
+}
+
+// DiffIncidents builds a diff between two incident sets keyed by incident_id
+func DiffIncidents(baseUploadID, otherUploadID string, base, other []models.Incident) *UploadDiff {
+	diff := &UploadDiff{
+		BaseUploadID:  baseUploadID,
+		OtherUploadID: otherUploadID,
+		Added:         []models.Incident{},
+		Removed:       []models.Incident{},
+		Changed:       []IncidentChange{},
+	}
+
+	baseByID := make(map[string]*models.Incident, len(base))
+	for i := range base {
+		baseByID[base[i].IncidentID] = &base[i]
+	}
+
+	seen := make(map[string]bool, len(other))
+	for i := range other {
+		incident := &other[i]
+		seen[incident.IncidentID] = true
+
+		previous, exists := baseByID[incident.IncidentID]
+		if !exists {
+			diff.Added = append(diff.Added, *incident)
+			continue
+		}
+
+		changes := diffIncidentFields(previous, incident)
+		if len(changes) == 0 {
+			diff.Summary.UnchangedCount++
+			continue
+		}
+		diff.Changed = append(diff.Changed, IncidentChange{
+			IncidentID: incident.IncidentID,
+			Changes:    changes,
+		})
+	}
+
+	for i := range base {
+		if !seen[base[i].IncidentID] {
+			diff.Removed = append(diff.Removed, base[i])
+		}
+	}
+
+	// Keep the output stable regardless of insertion order
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].IncidentID < diff.Added[j].IncidentID })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].IncidentID < diff.Removed[j].IncidentID })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].IncidentID < diff.Changed[j].IncidentID })
+
+	diff.Summary.AddedCount = len(diff.Added)
+	diff.Summary.RemovedCount = len(diff.Removed)
+	diff.Summary.ChangedCount = len(diff.Changed)
+
+	return diff
+}
+
+// diffIncidentFields returns the source fields that differ between two incidents
+func diffIncidentFields(oldIncident, newIncident *models.Incident) []FieldChange {
+	var changes []FieldChange
+	for _, field := range incidentDiffFields {
+		oldValue := field.value(oldIncident)
+		newValue := field.value(newIncident)
+		if oldValue != newValue {
+			changes = append(changes, FieldChange{
+				Field:    field.name,
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+		}
+	}
+	return changes
+}
+
+// formatDiffDate renders a date for comparison, treating nil as empty
+func formatDiffDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func diffTestIncident(id, uploadID, incidentID, priority, status string) models.Incident {
+	return models.Incident{
+		ID:               id,
+		UploadID:         uploadID,
+		IncidentID:       incidentID,
+		ReportDate:       time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		BriefDescription: "Login failure",
+		ApplicationName:  "Portal",
+		ResolutionGroup:  "Web Team",
+		ResolvedPerson:   "Jane",
+		Priority:         priority,
+		Status:           status,
+		SentimentLabel:   models.SentimentNeutral,
+	}
+}
+
+func TestDiffIncidents(t *testing.T) {
+	base := []models.Incident{
+		diffTestIncident("b1", "base", "INC001", "P1", "Open"),
+		diffTestIncident("b2", "base", "INC002", "P2", "Open"),
+		diffTestIncident("b3", "base", "INC003", "P3", "Closed"),
+	}
+	other := []models.Incident{
+		diffTestIncident("o1", "other", "INC001", "P1", "Open"),
+		diffTestIncident("o2", "other", "INC002", "P1", "Resolved"),
+		diffTestIncident("o4", "other", "INC004", "P4", "Open"),
+	}
+
+	diff := DiffIncidents("base", "other", base, other)
+
+	if diff.Summary.AddedCount != 1 || diff.Added[0].IncidentID != "INC004" {
+		t.Errorf("Expected INC004 added, got %+v", diff.Added)
+	}
+
+	if diff.Summary.RemovedCount != 1 || diff.Removed[0].IncidentID != "INC003" {
+		t.Errorf("Expected INC003 removed, got %+v", diff.Removed)
+	}
+
+	if diff.Summary.UnchangedCount != 1 {
+		t.Errorf("Expected 1 unchanged incident, got %d", diff.Summary.UnchangedCount)
+	}
+
+	if diff.Summary.ChangedCount != 1 || diff.Changed[0].IncidentID != "INC002" {
+		t.Fatalf("Expected INC002 changed, got %+v", diff.Changed)
+	}
+
+	changes := diff.Changed[0].Changes
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 field changes, got %+v", changes)
+	}
+
+	if changes[0].Field != "priority" || changes[0].OldValue != "P2" || changes[0].NewValue != "P1" {
+		t.Errorf("Unexpected priority change: %+v", changes[0])
+	}
+
+	if changes[1].Field != "status" || changes[1].OldValue != "Open" || changes[1].NewValue != "Resolved" {
+		t.Errorf("Unexpected status change: %+v", changes[1])
+	}
+}
+
+func TestIncidentService_DiffUploads(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	service := NewIncidentService(dbWrapper.GetConnection())
+	ctx := context.Background()
+
+	base := []models.Incident{diffTestIncident("b1", "base", "INC001", "P2", "Open")}
+	other := []models.Incident{
+		diffTestIncident("o1", "other", "INC001", "P2", "Closed"),
+		diffTestIncident("o2", "other", "INC002", "P3", "Open"),
+	}
+
+	if _, err := service.BatchInsertIncidents(ctx, base, "base"); err != nil {
+		t.Fatalf("Failed to insert base incidents: %v", err)
+	}
+	if _, err := service.BatchInsertIncidents(ctx, other, "other"); err != nil {
+		t.Fatalf("Failed to insert other incidents: %v", err)
+	}
+
+	diff, err := service.DiffUploads(ctx, "base", "other")
+	if err != nil {
+		t.Fatalf("Failed to diff uploads: %v", err)
+	}
+
+	if diff.Summary.AddedCount != 1 || diff.Summary.RemovedCount != 0 || diff.Summary.ChangedCount != 1 {
+		t.Errorf("Unexpected diff summary: %+v", diff.Summary)
+	}
+}
@@ -0,0 +1,388 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Distribution check statuses
+const (
+	DistributionOK           = "ok"
+	DistributionShifted      = "shifted"
+	DistributionNoPrior      = "no_prior_period"   // no incidents were reported in the period before
+	DistributionInsufficient = "insufficient_data" // a period has too few incidents to compare
+)
+
+// Distribution check metrics
+const (
+	MetricPriorityShare      = "priority_share"
+	MetricApplicationShare   = "application_share"
+	MetricAvgResolutionHours = "avg_resolution_hours"
+)
+
+// DefaultDistributionShiftFactor is how many times larger or smaller than in the prior period a
+// share or average may become before the distribution check flags it
+const DefaultDistributionShiftFactor = 3.0
+
+const (
+	// distributionMinIncidents is how many incidents, or resolved incidents for the average
+	// resolution time, each period needs to be compared
+	distributionMinIncidents = 30
+	// distributionMinSharePct is the share smaller shares are counted as, so that a priority or
+	// application going from 0.1% to 0.5% is not flagged as a fivefold jump
+	distributionMinSharePct = 1.0
+	// distributionTopApplications is how many of the largest applications of each period are compared
+	distributionTopApplications = 5
+)
+
+// DistributionShift compares a metric of an upload's period with the prior period. Shares are
+// percentages of the period's incidents.
+type DistributionShift struct {
+	Metric  string  `json:"metric"`
+	Key     string  `json:"key,omitempty"` // priority or application the metric is of
+	Prior   float64 `json:"prior"`
+	Current float64 `json:"current"`
+	// Ratio is how many times the prior value the current one is, with small shares counted as
+	// distributionMinSharePct
+	Ratio   float64 `json:"ratio"`
+	Flagged bool    `json:"flagged"`
+}
+
+// DistributionCheck is the result of comparing the incidents of an upload with those reported in
+// the period of the same length just before it. A share or average changing by the shift factor
+// or more from one period to the next is improbable, and more likely a broken extract than real.
+type DistributionCheck struct {
+	UploadID     string              `json:"upload_id"`
+	Status       string              `json:"status"`
+	PeriodStart  string              `json:"period_start,omitempty"`
+	PeriodEnd    string              `json:"period_end,omitempty"`
+	PriorStart   string              `json:"prior_start,omitempty"`
+	PriorEnd     string              `json:"prior_end,omitempty"`
+	CurrentCount int                 `json:"current_count"`
+	PriorCount   int                 `json:"prior_count"`
+	ShiftFactor  float64             `json:"shift_factor"`
+	Shifts       []DistributionShift `json:"shifts"`
+	FlaggedCount int                 `json:"flagged_count"`
+	AlertID      string              `json:"alert_id,omitempty"`
+	CheckedAt    time.Time           `json:"checked_at"`
+}
+
+// periodProfile is the distribution of a period's incidents
+type periodProfile struct {
+	count          int
+	priorities     map[string]int
+	applications   map[string]int
+	resolved       int
+	avgResolutionH float64
+}
+
+// DistributionCheckService compares each processed upload's distribution with the prior period
+type DistributionCheckService struct {
+	db     *sql.DB
+	alerts *DataQualityService
+
+	mu     sync.Mutex
+	factor float64
+}
+
+// NewDistributionCheckService creates a new DistributionCheckService instance
+func NewDistributionCheckService(db *sql.DB) *DistributionCheckService {
+	return &DistributionCheckService{
+		db:     db,
+		alerts: NewDataQualityService(db),
+		factor: DefaultDistributionShiftFactor,
+	}
+}
+
+// SetShiftFactor changes how many times larger or smaller a metric may become before it is
+// flagged, taking effect for the next check
+func (s *DistributionCheckService) SetShiftFactor(factor float64) {
+	s.mu.Lock()
+	s.factor = factor
+	s.mu.Unlock()
+}
+
+// ShiftFactor returns how many times larger or smaller a metric may become before it is flagged
+func (s *DistributionCheckService) ShiftFactor() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.factor
+}
+
+// CheckUpload compares the priority shares, largest applications' shares and average resolution
+// time of an upload's incidents with those of the incidents of completed uploads reported in
+// the period of the same length before the upload's first report day. Flagged shifts raise a
+// data-quality alert. The result is stored on the upload, replacing any earlier check.
+func (s *DistributionCheckService) CheckUpload(ctx context.Context, uploadID string) (*DistributionCheck, error) {
+	check := &DistributionCheck{
+		UploadID:    uploadID,
+		Status:      DistributionNoPrior,
+		ShiftFactor: s.ShiftFactor(),
+		Shifts:      []DistributionShift{},
+		CheckedAt:   time.Now().UTC(),
+	}
+
+	var start, end sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT CAST(MIN(report_date) AS DATE), CAST(MAX(report_date) AS DATE)
+		FROM incidents
+		WHERE upload_id = ?
+	`, uploadID).Scan(&start, &end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find upload period: %w", err)
+	}
+	if start.Valid && end.Valid {
+		days := int(end.Time.Sub(start.Time).Hours()/24) + 1
+		priorEnd := start.Time.AddDate(0, 0, -1)
+		priorStart := start.Time.AddDate(0, 0, -days)
+		check.PeriodStart = start.Time.Format("2006-01-02")
+		check.PeriodEnd = end.Time.Format("2006-01-02")
+		check.PriorStart = priorStart.Format("2006-01-02")
+		check.PriorEnd = priorEnd.Format("2006-01-02")
+
+		current, err := s.profile(ctx, "i.upload_id = ?", uploadID)
+		if err != nil {
+			return nil, err
+		}
+		prior, err := s.profile(ctx, `i.upload_id <> ? AND CAST(i.report_date AS DATE) BETWEEN ? AND ?
+			AND i.upload_id IN (SELECT id FROM uploads WHERE status IN ('completed', 'completed_with_errors'))`,
+			uploadID, priorStart, priorEnd)
+		if err != nil {
+			return nil, err
+		}
+		check.CurrentCount, check.PriorCount = current.count, prior.count
+		compareDistributions(check, prior, current)
+	}
+
+	if check.FlaggedCount > 0 {
+		alert, err := s.raiseAlert(ctx, check)
+		if err != nil {
+			return nil, err
+		}
+		check.AlertID = alert.ID
+	}
+
+	if err := s.save(ctx, check); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// profile reads the distribution of the incidents matching where, a condition on incidents i
+func (s *DistributionCheckService) profile(ctx context.Context, where string, args ...interface{}) (*periodProfile, error) {
+	profile := &periodProfile{priorities: map[string]int{}, applications: map[string]int{}}
+
+	for _, group := range []struct {
+		column string
+		counts map[string]int
+	}{
+		{"priority", profile.priorities},
+		{"application_name", profile.applications},
+	} {
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT COALESCE(i.`+group.column+`, ''), COUNT(*)
+			FROM incidents i
+			WHERE `+where+`
+			GROUP BY 1
+		`, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count incidents by %s: %w", group.column, err)
+		}
+		for rows.Next() {
+			var key string
+			var count int
+			if err := rows.Scan(&key, &count); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan incidents by %s: %w", group.column, err)
+			}
+			group.counts[key] = count
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error iterating incidents by %s: %w", group.column, err)
+		}
+	}
+	for _, count := range profile.priorities {
+		profile.count += count
+	}
+
+	var avg sql.NullFloat64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(resolution_time_hours), AVG(resolution_time_hours)
+		FROM incidents i
+		WHERE `+where, args...).Scan(&profile.resolved, &avg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to average resolution time: %w", err)
+	}
+	profile.avgResolutionH = avg.Float64
+	return profile, nil
+}
+
+// compareDistributions fills in the shifts between the prior and current periods and the
+// status of the check
+func compareDistributions(check *DistributionCheck, prior, current *periodProfile) {
+	if prior.count == 0 {
+		check.Status = DistributionNoPrior
+		return
+	}
+	if prior.count < distributionMinIncidents || current.count < distributionMinIncidents {
+		check.Status = DistributionInsufficient
+		return
+	}
+
+	priorities := uniqueSorted(append(mapKeys(prior.priorities), mapKeys(current.priorities)...))
+	for _, priority := range priorities {
+		check.addShare(MetricPriorityShare, priority, prior.priorities[priority], prior.count,
+			current.priorities[priority], current.count)
+	}
+	applications := uniqueSorted(append(topKeys(prior.applications, distributionTopApplications),
+		topKeys(current.applications, distributionTopApplications)...))
+	for _, application := range applications {
+		check.addShare(MetricApplicationShare, application, prior.applications[application], prior.count,
+			current.applications[application], current.count)
+	}
+	if prior.resolved >= distributionMinIncidents && current.resolved >= distributionMinIncidents && prior.avgResolutionH > 0 {
+		check.addShift(DistributionShift{
+			Metric:  MetricAvgResolutionHours,
+			Prior:   roundTo(prior.avgResolutionH, 2),
+			Current: roundTo(current.avgResolutionH, 2),
+			Ratio:   roundTo(current.avgResolutionH/prior.avgResolutionH, 2),
+		})
+	}
+
+	check.Status = DistributionOK
+	if check.FlaggedCount > 0 {
+		check.Status = DistributionShifted
+	}
+}
+
+// addShare compares the share of the period's incidents that key has in each period
+func (check *DistributionCheck) addShare(metric, key string, prior, priorTotal, current, currentTotal int) {
+	priorPct := float64(prior) * 100 / float64(priorTotal)
+	currentPct := float64(current) * 100 / float64(currentTotal)
+	check.addShift(DistributionShift{
+		Metric:  metric,
+		Key:     key,
+		Prior:   roundTo(priorPct, 2),
+		Current: roundTo(currentPct, 2),
+		Ratio:   roundTo(math.Max(currentPct, distributionMinSharePct)/math.Max(priorPct, distributionMinSharePct), 2),
+	})
+}
+
+// addShift records a shift, flagging it when its ratio reaches the shift factor either way
+func (check *DistributionCheck) addShift(shift DistributionShift) {
+	shift.Flagged = shift.Ratio >= check.ShiftFactor || shift.Ratio <= 1/check.ShiftFactor
+	if shift.Flagged {
+		check.FlaggedCount++
+	}
+	check.Shifts = append(check.Shifts, shift)
+}
+
+// mapKeys returns the keys of counts
+func mapKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// topKeys returns the limit keys with the largest counts
+func topKeys(counts map[string]int, limit int) []string {
+	keys := mapKeys(counts)
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys
+}
+
+// warning describes a flagged shift for the quality report
+func (shift DistributionShift) warning(check *DistributionCheck) string {
+	prior := fmt.Sprintf("%s to %s", check.PriorStart, check.PriorEnd)
+	switch shift.Metric {
+	case MetricPriorityShare:
+		return fmt.Sprintf("%s share of incidents moved from %g%% to %g%% (%gx) against %s; check the priority column",
+			shift.Key, shift.Prior, shift.Current, shift.Ratio, prior)
+	case MetricApplicationShare:
+		return fmt.Sprintf("%s share of incidents moved from %g%% to %g%% (%gx) against %s; check the extract covers every application",
+			shift.Key, shift.Prior, shift.Current, shift.Ratio, prior)
+	default:
+		return fmt.Sprintf("Average resolution time moved from %gh to %gh (%gx) against %s; check the report and resolve date columns",
+			shift.Prior, shift.Current, shift.Ratio, prior)
+	}
+}
+
+// raiseAlert records a data-quality alert for the flagged shifts of a check. It is high
+// severity when a metric moved by the square of the shift factor or more.
+func (s *DistributionCheckService) raiseAlert(ctx context.Context, check *DistributionCheck) (*DataQualityAlert, error) {
+	alert := &DataQualityAlert{
+		Type:     AlertDistributionShift,
+		Severity: AlertSeverityWarning,
+		UploadID: check.UploadID,
+		Message: fmt.Sprintf("Upload %s shifted %d distribution metrics by %gx or more against %s to %s; the extract may be incomplete or mis-mapped",
+			check.UploadID, check.FlaggedCount, check.ShiftFactor, check.PriorStart, check.PriorEnd),
+	}
+
+	flagged := []DistributionShift{}
+	severe := check.ShiftFactor * check.ShiftFactor
+	for _, shift := range check.Shifts {
+		if !shift.Flagged {
+			continue
+		}
+		flagged = append(flagged, shift)
+		if shift.Ratio >= severe || shift.Ratio <= 1/severe {
+			alert.Severity = AlertSeverityHigh
+		}
+	}
+
+	details := map[string]interface{}{
+		"period_start": check.PeriodStart,
+		"period_end":   check.PeriodEnd,
+		"prior_start":  check.PriorStart,
+		"prior_end":    check.PriorEnd,
+		"shifts":       flagged,
+	}
+	if err := s.alerts.CreateAlert(ctx, alert, details); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// save stores a check on its upload
+func (s *DistributionCheckService) save(ctx context.Context, check *DistributionCheck) error {
+	data, err := json.Marshal(check)
+	if err != nil {
+		return fmt.Errorf("failed to encode distribution check: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "UPDATE uploads SET distribution_check = ? WHERE id = ?",
+		string(data), check.UploadID); err != nil {
+		return fmt.Errorf("failed to save distribution check: %w", err)
+	}
+	return nil
+}
+
+// DecodeDistributionCheck parses the distribution check stored on an upload, returning nil for
+// uploads not checked or an unreadable value
+func DecodeDistributionCheck(data string) *DistributionCheck {
+	if data == "" {
+		return nil
+	}
+	var check DistributionCheck
+	if err := json.Unmarshal([]byte(data), &check); err != nil {
+		return nil
+	}
+	return &check
+}
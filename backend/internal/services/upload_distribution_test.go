@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/storage"
+)
+
+func TestDistributionCheckService_CheckUpload(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	dir := t.TempDir()
+	processing := NewProcessingService(db, storage.NewFileStore(dir))
+	ctx := context.Background()
+
+	// Each upload has 40 incidents reported from day first of month over span days; p1 of them
+	// are P1 and the rest P3
+	upload := func(id, month string, first, span, p1 int) {
+		rows := [][]string{{"Incident ID", "Report Date", "Application Name", "Priority", "Status", "Resolved Person", "Brief Description", "Resolution Group"}}
+		for i := 0; i < 40; i++ {
+			priority := "P3"
+			if i < p1 {
+				priority = "P1"
+			}
+			rows = append(rows, []string{fmt.Sprintf("%s-%02d", id, i), fmt.Sprintf("%s-%02d", month, i%span+first),
+				"Portal", priority, "Open", "Jane", "Login failure", "Web Team"})
+		}
+		writeTestWorkbook(t, dir, id+".xlsx", rows)
+		if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+			id, id+".xlsx", id+".xlsx", models.UploadStatusUploaded); err != nil {
+			t.Fatalf("Failed to create upload: %v", err)
+		}
+		options := models.DefaultProcessingOptions()
+		options.RunSentiment, options.RunAutomation = false, false
+		if _, err := processing.ProcessUploadWithOptions(ctx, id, options); err != nil {
+			t.Fatalf("Processing failed: %v", err)
+		}
+	}
+
+	upload("upload-jan", "2024-01", 10, 20, 2)
+	report, err := NewUploadQualityService(db).GetReport(ctx, "upload-jan")
+	if err != nil {
+		t.Fatalf("GetReport() error = %v", err)
+	}
+	if report.Distribution == nil || report.Distribution.Status != DistributionNoPrior {
+		t.Errorf("expected the first month checked without a prior period, got %+v", report.Distribution)
+	}
+
+	// P1 going from 5% to 50% of incidents is flagged; the single application is not
+	upload("upload-feb", "2024-02", 1, 28, 20)
+	report, err = NewUploadQualityService(db).GetReport(ctx, "upload-feb")
+	if err != nil {
+		t.Fatalf("GetReport() error = %v", err)
+	}
+	check := report.Distribution
+	if check == nil || check.Status != DistributionShifted || check.PriorCount != 40 || check.AlertID == "" {
+		t.Fatalf("expected the P1 shift flagged against January, got %+v", check)
+	}
+	if check.PriorStart != "2024-01-04" || check.PriorEnd != "2024-01-31" {
+		t.Errorf("expected the prior period to be the 28 days before February, got %s to %s", check.PriorStart, check.PriorEnd)
+	}
+	flagged := map[string]DistributionShift{}
+	for _, shift := range check.Shifts {
+		if shift.Flagged {
+			flagged[shift.Key] = shift
+		}
+	}
+	if len(flagged) != 1 || flagged["P1"].Ratio != 10 || flagged["P1"].Current != 50 {
+		t.Errorf("expected only the P1 share flagged at 10x, got %+v", check.Shifts)
+	}
+	if len(report.Warnings) != 1 {
+		t.Errorf("expected the flagged shift in the report's warnings, got %v", report.Warnings)
+	}
+
+	scoped := WithDataScope(ctx, &DataScope{UserID: "analyst", Applications: []string{"Portal"}})
+	report, err = NewUploadQualityService(db).GetReport(scoped, "upload-feb")
+	if err != nil {
+		t.Fatalf("GetReport() error = %v", err)
+	}
+	if report.Distribution != nil || len(report.Warnings) != 0 {
+		t.Errorf("expected the distribution check left out for a user with a data scope, got %+v, %v", report.Distribution, report.Warnings)
+	}
+
+	alerts, err := NewDataQualityService(db).ListAlerts(ctx, "upload-feb", 0)
+	if err != nil || len(alerts) != 1 || alerts[0].Type != AlertDistributionShift || alerts[0].Severity != AlertSeverityHigh {
+		t.Errorf("expected a high severity distribution alert, got %+v, %v", alerts, err)
+	}
+
+	// A larger factor tolerates the shift
+	service := NewDistributionCheckService(db)
+	service.SetShiftFactor(20)
+	check, err = service.CheckUpload(ctx, "upload-feb")
+	if err != nil {
+		t.Fatalf("CheckUpload() error = %v", err)
+	}
+	if check.Status != DistributionOK || check.FlaggedCount != 0 {
+		t.Errorf("expected no shifts flagged at 20x, got %+v", check)
+	}
+}
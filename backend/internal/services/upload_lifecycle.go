@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/logging"
+	"incident-management-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidUploadTransition is returned when an upload cannot move to a status, either because
+// the lifecycle does not allow it or because another run changed the upload's status first
+var ErrInvalidUploadTransition = errors.New("upload status transition not allowed")
+
+// UploadOutcome is what a processing run found in an upload, recorded with its status
+type UploadOutcome struct {
+	RecordCount    int
+	ProcessedCount int
+	ErrorCount     int
+	Errors         []string
+}
+
+// UploadEvent records one status transition of an upload
+type UploadEvent struct {
+	ID             string    `json:"id"`
+	UploadID       string    `json:"upload_id"`
+	FromStatus     string    `json:"from_status"`
+	ToStatus       string    `json:"to_status"`
+	RecordCount    int       `json:"record_count"`
+	ProcessedCount int       `json:"processed_count"`
+	ErrorCount     int       `json:"error_count"`
+	OccurredAt     time.Time `json:"occurred_at"`
+	// RequestID and UserID identify the request that caused the transition, when known
+	RequestID string `json:"request_id,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+}
+
+// TransitionUpload moves an upload from one of the from statuses to status to, recording the
+// outcome and an upload event in one transaction. The update only applies while the upload is
+// still in the status it was read in, so of two runs racing to move it only one succeeds; the
+// other, like a move the lifecycle does not allow, gets ErrInvalidUploadTransition.
+func (s *IncidentService) TransitionUpload(ctx context.Context, uploadID, to string, outcome UploadOutcome, from ...string) error {
+	for _, status := range from {
+		if !models.CanTransitionUpload(status, to) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidUploadTransition, status, to)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := uploadStatus(ctx, tx, uploadID)
+	if err != nil {
+		return err
+	}
+	if !containsString(from, current) {
+		return fmt.Errorf("%w: upload %s is %s, not %s", ErrInvalidUploadTransition, uploadID, current, strings.Join(from, " or "))
+	}
+
+	if err := s.moveUpload(ctx, tx, uploadID, current, to, outcome); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return s.transitionConflict(ctx, uploadID, current, fmt.Errorf("failed to commit upload status: %w", err))
+	}
+
+	logf(ctx, "Upload %s moved from %s to %s", uploadID, current, to)
+	return nil
+}
+
+// ReplaceUploadFile points a failed upload at a new file, such as the same spreadsheet with its
+// headers fixed, and returns it to uploaded so it can be processed again. Incidents a failed run
+// stored before it stopped are deleted in the same transaction, so processing the new file cannot
+// leave them alongside its own. It returns the file the upload had before.
+func (s *IncidentService) ReplaceUploadFile(ctx context.Context, uploadID, filename, originalFilename string) (string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current, previous string
+	err = tx.QueryRowContext(ctx, "SELECT status, filename FROM uploads WHERE id = ?", uploadID).Scan(&current, &previous)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("upload record not found: %s: %w", uploadID, err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check existing upload: %w", err)
+	}
+	if current != models.UploadStatusFailed {
+		return "", fmt.Errorf("%w: upload %s is %s, only failed uploads can have their file replaced", ErrInvalidUploadTransition, uploadID, current)
+	}
+
+	if err := deleteUploadIncidents(ctx, tx, uploadID); err != nil {
+		return "", err
+	}
+	if err := s.moveUpload(ctx, tx, uploadID, current, models.UploadStatusUploaded, UploadOutcome{}); err != nil {
+		return "", err
+	}
+	// The new file has not been processed or parsed yet
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE uploads
+		SET filename = ?, original_filename = ?, processed_at = NULL, ingested_sheet = NULL, cleanup = NULL
+		WHERE id = ?
+	`, filename, originalFilename, uploadID); err != nil {
+		return "", fmt.Errorf("failed to replace upload file: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", s.transitionConflict(ctx, uploadID, current, fmt.Errorf("failed to commit upload file: %w", err))
+	}
+
+	logf(ctx, "Upload %s file replaced with %s", uploadID, filename)
+	return previous, nil
+}
+
+// moveUpload moves an upload read in status current to status to within tx, storing the outcome
+// and recording an upload event
+func (s *IncidentService) moveUpload(ctx context.Context, tx *sql.Tx, uploadID, current, to string, outcome UploadOutcome) error {
+	// Convert errors to JSON string (simplified for now)
+	errorsJSON := "[]"
+	if len(outcome.Errors) > 0 {
+		// In production, use proper JSON marshaling
+		errorsJSON = fmt.Sprintf(`["%s"]`, strings.Join(outcome.Errors, `", "`))
+	}
+
+	// A run that has not finished has not been processed yet
+	var processedAt interface{}
+	if to != models.UploadStatusQueued && to != models.UploadStatusProcessing {
+		processedAt = time.Now()
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE uploads
+		SET status = ?, record_count = ?, processed_count = ?, error_count = ?, errors = ?, processed_at = ?
+		WHERE id = ? AND status = ?
+	`, to, outcome.RecordCount, outcome.ProcessedCount, outcome.ErrorCount, errorsJSON, processedAt, uploadID, current)
+	if err != nil {
+		return s.transitionConflict(ctx, uploadID, current,
+			fmt.Errorf("failed to update upload status (uploadID=%s, status=%s): %w", uploadID, to, err))
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("%w: upload %s changed status while moving from %s to %s", ErrInvalidUploadTransition, uploadID, current, to)
+	}
+
+	event := UploadEvent{
+		ID:             uuid.New().String(),
+		UploadID:       uploadID,
+		FromStatus:     current,
+		ToStatus:       to,
+		RecordCount:    outcome.RecordCount,
+		ProcessedCount: outcome.ProcessedCount,
+		ErrorCount:     outcome.ErrorCount,
+		OccurredAt:     time.Now(),
+		RequestID:      logging.GetRequestID(ctx),
+		UserID:         logging.GetUserID(ctx),
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO upload_events (id, upload_id, from_status, to_status, record_count, processed_count, error_count,
+			occurred_at, request_id, user_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, event.ID, event.UploadID, event.FromStatus, event.ToStatus, event.RecordCount, event.ProcessedCount,
+		event.ErrorCount, event.OccurredAt, nullIfEmpty(event.RequestID), nullIfEmpty(event.UserID)); err != nil {
+		return fmt.Errorf("failed to record upload event: %w", err)
+	}
+	return nil
+}
+
+// transitionConflict returns ErrInvalidUploadTransition when a failed status update lost a race
+// with another run moving the upload out of status, and err otherwise. DuckDB refuses the update
+// while the other run's transaction is open and lets it through, to match nothing, once the other
+// run has committed.
+func (s *IncidentService) transitionConflict(ctx context.Context, uploadID, status string, err error) error {
+	if strings.Contains(err.Error(), "Conflict on") {
+		return fmt.Errorf("%w: upload %s is being moved from %s by another run", ErrInvalidUploadTransition, uploadID, status)
+	}
+	if current, lookupErr := uploadStatus(ctx, s.db, uploadID); lookupErr == nil && current != status {
+		return fmt.Errorf("%w: upload %s changed status to %s", ErrInvalidUploadTransition, uploadID, current)
+	}
+	return err
+}
+
+// ListUploadEvents returns the status transitions of an upload in the order they happened, with
+// an error wrapping sql.ErrNoRows when the upload does not exist
+func (s *IncidentService) ListUploadEvents(ctx context.Context, uploadID string) ([]UploadEvent, error) {
+	if _, err := uploadStatus(ctx, s.db, uploadID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, upload_id, from_status, to_status, record_count, processed_count, error_count, occurred_at,
+			COALESCE(request_id, ''), COALESCE(user_id, '')
+		FROM upload_events
+		WHERE upload_id = ?
+		ORDER BY occurred_at, id
+	`, uploadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query upload events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []UploadEvent{}
+	for rows.Next() {
+		var event UploadEvent
+		if err := rows.Scan(&event.ID, &event.UploadID, &event.FromStatus, &event.ToStatus, &event.RecordCount,
+			&event.ProcessedCount, &event.ErrorCount, &event.OccurredAt, &event.RequestID, &event.UserID); err != nil {
+			return nil, fmt.Errorf("failed to scan upload event: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// uploadQuerier is satisfied by both *sql.DB and *sql.Tx
+type uploadQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// uploadStatus returns the current status of an upload, with an error wrapping sql.ErrNoRows
+// when it does not exist
+func uploadStatus(ctx context.Context, db uploadQuerier, uploadID string) (string, error) {
+	var status string
+	err := db.QueryRowContext(ctx, "SELECT status FROM uploads WHERE id = ?", uploadID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("upload record not found: %s: %w", uploadID, err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check existing upload: %w", err)
+	}
+	return status, nil
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/storage"
+)
+
+func TestIncidentService_TransitionUpload(t *testing.T) {
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+	db := dbWrapper.GetConnection()
+	service := NewIncidentService(db)
+	ctx := context.Background()
+
+	if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+		"upload-1", "upload-1.xlsx", "upload-1.xlsx", models.UploadStatusUploaded); err != nil {
+		t.Fatalf("Failed to create upload: %v", err)
+	}
+
+	t.Run("unknown upload", func(t *testing.T) {
+		err := service.TransitionUpload(ctx, "missing", models.UploadStatusQueued, UploadOutcome{}, models.UploadStatusUploaded)
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected a missing upload error, got %v", err)
+		}
+		if _, err := service.ListUploadEvents(ctx, "missing"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected no events for a missing upload, got %v", err)
+		}
+	})
+
+	t.Run("transitions outside the lifecycle", func(t *testing.T) {
+		err := service.TransitionUpload(ctx, "upload-1", models.UploadStatusProcessing, UploadOutcome{}, models.UploadStatusUploaded)
+		if !errors.Is(err, ErrInvalidUploadTransition) {
+			t.Errorf("Expected processing to require queueing first, got %v", err)
+		}
+		err = service.TransitionUpload(ctx, "upload-1", models.UploadStatusProcessing, UploadOutcome{}, models.UploadStatusQueued)
+		if !errors.Is(err, ErrInvalidUploadTransition) {
+			t.Errorf("Expected an upload that is not queued to be refused, got %v", err)
+		}
+	})
+
+	t.Run("one of several racing runs queues the upload", func(t *testing.T) {
+		var wg sync.WaitGroup
+		results := make(chan error, 8)
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results <- service.TransitionUpload(ctx, "upload-1", models.UploadStatusQueued, UploadOutcome{}, models.UploadStatusUploaded)
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		queued := 0
+		for err := range results {
+			if err == nil {
+				queued++
+			} else if !errors.Is(err, ErrInvalidUploadTransition) {
+				t.Errorf("Expected losing runs to be refused, got %v", err)
+			}
+		}
+		if queued != 1 {
+			t.Errorf("Expected exactly one run to queue the upload, got %d", queued)
+		}
+	})
+
+	t.Run("events record each transition", func(t *testing.T) {
+		if err := service.TransitionUpload(ctx, "upload-1", models.UploadStatusProcessing, UploadOutcome{}, models.UploadStatusQueued); err != nil {
+			t.Fatalf("Failed to start processing: %v", err)
+		}
+		outcome := UploadOutcome{RecordCount: 10, ProcessedCount: 9, ErrorCount: 1, Errors: []string{"row 4: missing priority"}}
+		if err := service.TransitionUpload(ctx, "upload-1", models.UploadStatusCompletedWithErrors, outcome, models.UploadStatusProcessing); err != nil {
+			t.Fatalf("Failed to finish processing: %v", err)
+		}
+
+		var status string
+		var processed int
+		var processedAt sql.NullTime
+		if err := db.QueryRow("SELECT status, processed_count, processed_at FROM uploads WHERE id = 'upload-1'").
+			Scan(&status, &processed, &processedAt); err != nil {
+			t.Fatalf("Failed to read upload: %v", err)
+		}
+		if status != models.UploadStatusCompletedWithErrors || processed != 9 || !processedAt.Valid {
+			t.Errorf("Expected the outcome on the upload, got status=%s processed=%d processed_at=%v", status, processed, processedAt)
+		}
+
+		events, err := service.ListUploadEvents(ctx, "upload-1")
+		if err != nil {
+			t.Fatalf("Failed to list events: %v", err)
+		}
+		expected := [][2]string{
+			{models.UploadStatusUploaded, models.UploadStatusQueued},
+			{models.UploadStatusQueued, models.UploadStatusProcessing},
+			{models.UploadStatusProcessing, models.UploadStatusCompletedWithErrors},
+		}
+		if len(events) != len(expected) {
+			t.Fatalf("Expected %d events, got %+v", len(expected), events)
+		}
+		for i, event := range events {
+			if event.FromStatus != expected[i][0] || event.ToStatus != expected[i][1] {
+				t.Errorf("Expected event %d to move from %s to %s, got %+v", i, expected[i][0], expected[i][1], event)
+			}
+		}
+		if events[2].ProcessedCount != 9 || events[2].ErrorCount != 1 {
+			t.Errorf("Expected the final event to carry the counts, got %+v", events[2])
+		}
+	})
+}
+
+func TestProcessingService_UploadLifecycle(t *testing.T) {
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+	db := dbWrapper.GetConnection()
+	dir := t.TempDir()
+	service := NewProcessingService(db, storage.NewFileStore(dir))
+	ctx := context.Background()
+
+	writeTestWorkbook(t, dir, "partial.xlsx", [][]string{
+		{"Incident ID", "Report Date", "Application", "Priority", "Description"},
+		{"INC001", "2024-03-01", "Portal", "P2", "Login failure"},
+		{"INC002", "2024-03-02", "Portal", "P9", "Unknown priority"},
+	})
+	if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+		"partial", "partial.xlsx", "partial.xlsx", models.UploadStatusUploaded); err != nil {
+		t.Fatalf("Failed to create upload: %v", err)
+	}
+	options := models.DefaultProcessingOptions()
+	options.ErrorThreshold = 100
+
+	if err := service.QueueUpload(ctx, "partial", options); err != nil {
+		t.Fatalf("Failed to queue upload: %v", err)
+	}
+	// A queued upload cannot be queued again, so a second request cannot process it too
+	if err := service.QueueUpload(ctx, "partial", options); !errors.Is(err, ErrInvalidUploadTransition) {
+		t.Errorf("Expected a queued upload to be refused, got %v", err)
+	}
+	if err := service.RollbackProcessing(ctx, "partial"); !errors.Is(err, ErrInvalidUploadTransition) {
+		t.Errorf("Expected an unfinished run not to be rolled back, got %v", err)
+	}
+
+	progress, err := service.ProcessQueuedUpload(ctx, "partial", options)
+	if err != nil {
+		t.Fatalf("Processing failed: %v", err)
+	}
+	if progress.Status != models.UploadStatusCompletedWithErrors || progress.ProcessedRows != 1 || progress.ErrorCount != 1 {
+		t.Errorf("Expected one stored row and one rejected, got %+v", progress)
+	}
+
+	// Running a finished upload again is refused rather than storing its incidents twice
+	if _, err := service.ProcessQueuedUpload(ctx, "partial", options); !errors.Is(err, ErrInvalidUploadTransition) {
+		t.Errorf("Expected a finished upload not to be processed again, got %v", err)
+	}
+
+	if err := service.RollbackProcessing(ctx, "partial"); err != nil {
+		t.Fatalf("Failed to roll back: %v", err)
+	}
+	events, err := service.incidentService.ListUploadEvents(ctx, "partial")
+	if err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	var path []string
+	for _, event := range events {
+		path = append(path, event.ToStatus)
+	}
+	expected := []string{models.UploadStatusQueued, models.UploadStatusProcessing, models.UploadStatusCompletedWithErrors, models.UploadStatusUploaded}
+	if len(path) != len(expected) {
+		t.Fatalf("Expected transitions %v, got %v", expected, path)
+	}
+	for i := range expected {
+		if path[i] != expected[i] {
+			t.Errorf("Expected transitions %v, got %v", expected, path)
+			break
+		}
+	}
+}
+
+func TestIncidentService_ReplaceUploadFile(t *testing.T) {
+	dbWrapper, err := database.NewDB(&database.Config{DatabasePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+	db := dbWrapper.GetConnection()
+	service := NewIncidentService(db)
+	ctx := context.Background()
+
+	for _, upload := range [][2]string{{"failed", models.UploadStatusFailed}, {"completed", models.UploadStatusCompleted}} {
+		if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status, record_count, error_count, processed_at)
+			VALUES (?, ?, ?, ?, 10, 10, now())`, upload[0], upload[0]+".xlsx", upload[0]+".xlsx", upload[1]); err != nil {
+			t.Fatalf("Failed to create upload: %v", err)
+		}
+	}
+	// A run that was cancelled part way left an incident behind
+	if _, err := db.Exec(`INSERT INTO incidents (id, upload_id, incident_id, report_date, brief_description, application_name,
+		resolution_group, resolved_person, priority, created_at, updated_at)
+		VALUES ('orphan', 'failed', 'INC001', DATE '2024-03-01', 'Login failure', 'Portal', 'Web', 'Sam', 'P2', now(), now())`); err != nil {
+		t.Fatalf("Failed to create incident: %v", err)
+	}
+
+	if _, err := service.ReplaceUploadFile(ctx, "completed", "new.xlsx", "fixed.xlsx"); !errors.Is(err, ErrInvalidUploadTransition) {
+		t.Errorf("Expected a completed upload to keep its file, got %v", err)
+	}
+	if _, err := service.ReplaceUploadFile(ctx, "missing", "new.xlsx", "fixed.xlsx"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected a missing upload error, got %v", err)
+	}
+
+	previous, err := service.ReplaceUploadFile(ctx, "failed", "new.xlsx", "fixed.xlsx")
+	if err != nil {
+		t.Fatalf("Failed to replace file: %v", err)
+	}
+	if previous != "failed.xlsx" {
+		t.Errorf("Expected the previous file to be returned, got %s", previous)
+	}
+
+	var filename, original, status string
+	var errorCount int
+	var processedAt sql.NullTime
+	if err := db.QueryRow("SELECT filename, original_filename, status, error_count, processed_at FROM uploads WHERE id = 'failed'").
+		Scan(&filename, &original, &status, &errorCount, &processedAt); err != nil {
+		t.Fatalf("Failed to read upload: %v", err)
+	}
+	if filename != "new.xlsx" || original != "fixed.xlsx" || status != models.UploadStatusUploaded || errorCount != 0 || processedAt.Valid {
+		t.Errorf("Expected a fresh upload of the new file, got filename=%s original=%s status=%s errors=%d processed_at=%v",
+			filename, original, status, errorCount, processedAt)
+	}
+	if count, err := service.GetIncidentCount(ctx, "failed"); err != nil || count != 0 {
+		t.Errorf("Expected the incidents of the failed run to be deleted, got %d (%v)", count, err)
+	}
+
+	events, err := service.ListUploadEvents(ctx, "failed")
+	if err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	if len(events) != 1 || events[0].FromStatus != models.UploadStatusFailed || events[0].ToStatus != models.UploadStatusUploaded {
+		t.Errorf("Expected the reset to be recorded, got %+v", events)
+	}
+}
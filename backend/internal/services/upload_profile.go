@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Upload processing stages timed in a profile
+const (
+	StageParse      = "parse"
+	StageValidation = "validation"
+	StageAnalysis   = "analysis"
+	StageInsert     = "insert"
+)
+
+// UploadProfile records where the time went while an upload was processed, so slow ingestion
+// can be traced to spreadsheet parsing, validation, the analyzers or the database writes.
+// Durations are in milliseconds.
+type UploadProfile struct {
+	UploadID     string  `json:"upload_id"`
+	Status       string  `json:"status"`
+	DryRun       bool    `json:"dry_run"`
+	TotalRows    int     `json:"total_rows"`
+	StoredRows   int     `json:"stored_rows"`
+	ParseMs      float64 `json:"parse_ms"`
+	ValidationMs float64 `json:"validation_ms"`
+	AnalysisMs   float64 `json:"analysis_ms"`
+	InsertMs     float64 `json:"insert_ms"`
+	TotalMs      float64 `json:"total_ms"`
+	// RowsPerSecond is the spreadsheet rows handled per second of the whole run
+	RowsPerSecond float64 `json:"rows_per_second"`
+	// InsertRowsPerSecond is the incidents stored per second spent writing to the database
+	InsertRowsPerSecond float64   `json:"insert_rows_per_second"`
+	SlowestStage        string    `json:"slowest_stage"`
+	RecordedAt          time.Time `json:"recorded_at"`
+}
+
+// processingTimings accumulates the time spent in each processing stage of one upload
+type processingTimings struct {
+	parse      time.Duration
+	validation time.Duration
+	analysis   time.Duration
+	insert     time.Duration
+}
+
+// stageShare returns the part of a duration spent on behalf of part of whole rows, for stages that
+// run once over all the uploads of a dataset
+func stageShare(d time.Duration, part, whole int) time.Duration {
+	if whole == 0 {
+		return 0
+	}
+	return time.Duration(float64(d) * float64(part) / float64(whole))
+}
+
+// NewUploadProfile builds the profile of a finished processing run
+func NewUploadProfile(progress *ProcessingProgress) *UploadProfile {
+	end := time.Now()
+	if progress.EndTime != nil {
+		end = *progress.EndTime
+	}
+	timings := progress.timings
+
+	profile := &UploadProfile{
+		UploadID:     progress.UploadID,
+		Status:       progress.Status,
+		DryRun:       progress.DryRun,
+		TotalRows:    progress.TotalRows,
+		StoredRows:   progress.ProcessedRows,
+		ParseMs:      milliseconds(timings.parse),
+		ValidationMs: milliseconds(timings.validation),
+		AnalysisMs:   milliseconds(timings.analysis),
+		InsertMs:     milliseconds(timings.insert),
+		TotalMs:      milliseconds(end.Sub(progress.StartTime)),
+		RecordedAt:   end,
+	}
+	profile.fillDerived()
+	return profile
+}
+
+// fillDerived computes the throughput and slowest stage from the stored stage timings
+func (p *UploadProfile) fillDerived() {
+	if p.TotalMs > 0 {
+		p.RowsPerSecond = roundTo(float64(p.TotalRows)/(p.TotalMs/1000), 1)
+	}
+	if p.InsertMs > 0 {
+		p.InsertRowsPerSecond = roundTo(float64(p.StoredRows)/(p.InsertMs/1000), 1)
+	}
+
+	slowest := 0.0
+	for _, stage := range []struct {
+		name string
+		ms   float64
+	}{
+		{StageParse, p.ParseMs}, {StageValidation, p.ValidationMs}, {StageAnalysis, p.AnalysisMs}, {StageInsert, p.InsertMs},
+	} {
+		if stage.ms > slowest {
+			slowest = stage.ms
+			p.SlowestStage = stage.name
+		}
+	}
+}
+
+// milliseconds converts a duration to milliseconds rounded to microsecond precision
+func milliseconds(d time.Duration) float64 {
+	return roundTo(float64(d)/float64(time.Millisecond), 3)
+}
+
+// UploadProfileService stores the processing profile of each upload
+type UploadProfileService struct {
+	db *sql.DB
+}
+
+// NewUploadProfileService creates a new UploadProfileService instance
+func NewUploadProfileService(db *sql.DB) *UploadProfileService {
+	return &UploadProfileService{db: db}
+}
+
+// SaveProfile stores a profile, replacing the one recorded by an earlier run of the upload
+func (s *UploadProfileService) SaveProfile(ctx context.Context, profile *UploadProfile) error {
+	query := `
+		INSERT OR REPLACE INTO upload_profiles (
+			upload_id, status, dry_run, total_rows, stored_rows,
+			parse_ms, validation_ms, analysis_ms, insert_ms, total_ms, recorded_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, profile.UploadID, profile.Status, profile.DryRun,
+		profile.TotalRows, profile.StoredRows, profile.ParseMs, profile.ValidationMs, profile.AnalysisMs,
+		profile.InsertMs, profile.TotalMs, profile.RecordedAt); err != nil {
+		return fmt.Errorf("failed to save upload profile: %w", err)
+	}
+	return nil
+}
+
+// GetProfile returns the profile of the last processing run of an upload, or sql.ErrNoRows
+// when the upload has not been processed since profiling was added
+func (s *UploadProfileService) GetProfile(ctx context.Context, uploadID string) (*UploadProfile, error) {
+	var profile UploadProfile
+	err := s.db.QueryRowContext(ctx, `
+		SELECT upload_id, status, dry_run, total_rows, stored_rows,
+			parse_ms, validation_ms, analysis_ms, insert_ms, total_ms, recorded_at
+		FROM upload_profiles
+		WHERE upload_id = ?
+	`, uploadID).Scan(&profile.UploadID, &profile.Status, &profile.DryRun, &profile.TotalRows, &profile.StoredRows,
+		&profile.ParseMs, &profile.ValidationMs, &profile.AnalysisMs, &profile.InsertMs, &profile.TotalMs,
+		&profile.RecordedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to query upload profile: %w", err)
+	}
+
+	profile.fillDerived()
+	return &profile, nil
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/storage"
+)
+
+func TestProcessingService_RecordsUploadProfile(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	dir := t.TempDir()
+	service := NewProcessingService(db, storage.NewFileStore(dir))
+	profiles := NewUploadProfileService(db)
+	ctx := context.Background()
+
+	writeTestWorkbook(t, dir, "feed.xlsx", [][]string{
+		{"Incident ID", "Report Date", "Application", "Priority", "Description"},
+		{"INC001", "2024-03-01", "Portal", "P2", "Login failure"},
+		{"INC002", "2024-03-02", "Billing", "P3", "Invoice stuck"},
+		{"INC003", "2024-03-03", "Billing", "P1", "Outage"},
+	})
+	if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+		"upload-1", "feed.xlsx", "feed.xlsx", models.UploadStatusUploaded); err != nil {
+		t.Fatalf("Failed to create upload: %v", err)
+	}
+
+	if _, err := profiles.GetProfile(ctx, "upload-1"); err != sql.ErrNoRows {
+		t.Fatalf("Expected no profile before processing, got %v", err)
+	}
+
+	options := models.DefaultProcessingOptions()
+	options.DryRun = true
+	if _, err := service.ProcessUploadWithOptions(ctx, "upload-1", options); err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	profile, err := profiles.GetProfile(ctx, "upload-1")
+	if err != nil {
+		t.Fatalf("Failed to get dry run profile: %v", err)
+	}
+	if !profile.DryRun || profile.StoredRows != 0 || profile.InsertMs != 0 {
+		t.Errorf("Expected a dry run profile without inserts, got %+v", profile)
+	}
+
+	options.DryRun = false
+	if _, err := service.ProcessUploadWithOptions(ctx, "upload-1", options); err != nil {
+		t.Fatalf("Processing failed: %v", err)
+	}
+	profile, err = profiles.GetProfile(ctx, "upload-1")
+	if err != nil {
+		t.Fatalf("Failed to get profile: %v", err)
+	}
+
+	if profile.DryRun || profile.Status != models.UploadStatusCompleted {
+		t.Errorf("Expected the real run to replace the dry run profile, got %+v", profile)
+	}
+	if profile.TotalRows != 3 || profile.StoredRows != 3 {
+		t.Errorf("Expected 3 rows read and stored, got %d and %d", profile.TotalRows, profile.StoredRows)
+	}
+	if profile.ParseMs <= 0 || profile.InsertMs <= 0 || profile.AnalysisMs <= 0 {
+		t.Errorf("Expected parse, analysis and insert to be timed, got %+v", profile)
+	}
+	if sum := profile.ParseMs + profile.ValidationMs + profile.AnalysisMs + profile.InsertMs; sum > profile.TotalMs {
+		t.Errorf("Expected stage timings (%.3fms) within the total (%.3fms)", sum, profile.TotalMs)
+	}
+	if profile.RowsPerSecond <= 0 || profile.InsertRowsPerSecond <= 0 || profile.SlowestStage == "" {
+		t.Errorf("Expected throughput and the slowest stage to be derived, got %+v", profile)
+	}
+}
+
+func TestUploadProfile_FillDerived(t *testing.T) {
+	profile := &UploadProfile{TotalRows: 500, StoredRows: 480, ParseMs: 100, ValidationMs: 5,
+		AnalysisMs: 20, InsertMs: 800, TotalMs: 1000}
+	profile.fillDerived()
+
+	if profile.SlowestStage != StageInsert {
+		t.Errorf("Expected insert to be the slowest stage, got %s", profile.SlowestStage)
+	}
+	if profile.RowsPerSecond != 500 || profile.InsertRowsPerSecond != 600 {
+		t.Errorf("Expected 500 rows/s overall and 600 rows/s inserted, got %v and %v",
+			profile.RowsPerSecond, profile.InsertRowsPerSecond)
+	}
+
+	empty := &UploadProfile{}
+	empty.fillDerived()
+	if empty.SlowestStage != "" || empty.RowsPerSecond != 0 {
+		t.Errorf("Expected no derived values for an empty profile, got %+v", empty)
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"incident-management-system/internal/models"
+)
+
+// UploadQualityReport summarizes how well an upload's workbook could be read: the sheet and
+// range parsed, the rows that failed, how each date column was read and how its distribution
+// compares with the prior period
+type UploadQualityReport struct {
+	UploadID      string                      `json:"upload_id"`
+	Status        string                      `json:"status"`
+	TotalRows     int                         `json:"total_rows"`
+	ErrorCount    int                         `json:"error_count"`
+	IngestedSheet *models.IngestedSheet       `json:"ingested_sheet,omitempty"`
+	Dates         map[string]*DateColumnStats `json:"dates"`
+	// Distribution is the check against the prior period, for uploads processed since it was added
+	Distribution *DistributionCheck `json:"distribution,omitempty"`
+	// Warnings collects the warnings of the sheet's skipped rows and duplicate columns, every date
+	// column and every flagged distribution shift
+	Warnings []string `json:"warnings"`
+}
+
+// UploadQualityService reads the quality reports recorded on uploads when they are parsed
+type UploadQualityService struct {
+	db *sql.DB
+}
+
+// NewUploadQualityService creates a new UploadQualityService instance
+func NewUploadQualityService(db *sql.DB) *UploadQualityService {
+	return &UploadQualityService{db: db}
+}
+
+// GetReport returns the quality report of an upload, or sql.ErrNoRows when it does not exist.
+// Uploads not parsed since quality reports were added have no sheet or date statistics. The
+// distribution check compares the shares of every application of the upload, so it is left out
+// of the report of a user with a data scope.
+func (s *UploadQualityService) GetReport(ctx context.Context, uploadID string) (*UploadQualityReport, error) {
+	report := &UploadQualityReport{UploadID: uploadID, Dates: map[string]*DateColumnStats{}, Warnings: []string{}}
+	var sheetJSON, datesJSON, distributionJSON string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT status, record_count, error_count, COALESCE(ingested_sheet, ''), COALESCE(date_quality, ''),
+			COALESCE(distribution_check, '')
+		FROM uploads
+		WHERE id = ?
+	`, uploadID).Scan(&report.Status, &report.TotalRows, &report.ErrorCount, &sheetJSON, &datesJSON, &distributionJSON)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to query upload quality: %w", err)
+	}
+
+	report.IngestedSheet = DecodeIngestedSheet(sheetJSON)
+	report.Warnings = append(report.Warnings, sheetWarnings(report.IngestedSheet)...)
+	if datesJSON != "" {
+		if err := json.Unmarshal([]byte(datesJSON), &report.Dates); err != nil {
+			return nil, fmt.Errorf("failed to decode date quality of upload %s: %w", uploadID, err)
+		}
+	}
+
+	fields := make([]string, 0, len(report.Dates))
+	for field := range report.Dates {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		report.Warnings = append(report.Warnings, report.Dates[field].Warnings...)
+	}
+
+	if DataScopeFromContext(ctx) == nil {
+		report.Distribution = DecodeDistributionCheck(distributionJSON)
+	}
+	if report.Distribution != nil {
+		for _, shift := range report.Distribution.Shifts {
+			if shift.Flagged {
+				report.Warnings = append(report.Warnings, shift.warning(report.Distribution))
+			}
+		}
+	}
+	return report, nil
+}
+
+// skippedRowWarnings describes each reason rows are skipped for, in report order
+var skippedRowWarnings = []struct{ reason, message string }{
+	{models.SkipRepeatedHeader, "Skipped rows repeating the header row: %s"},
+	{models.SkipDuplicateRow, "Skipped rows repeating an earlier row cell for cell, so they are not counted twice: %s"},
+	{models.SkipSummaryRow, "Skipped summary rows below the data: %s"},
+}
+
+// maxListedRows caps the row numbers a skipped rows warning lists
+const maxListedRows = 10
+
+// sheetWarnings describes the rows skipped and the duplicate columns found reading a sheet
+func sheetWarnings(sheet *models.IngestedSheet) []string {
+	if sheet == nil {
+		return nil
+	}
+	var warnings []string
+	for _, column := range sheet.DuplicateColumns {
+		warnings = append(warnings, fmt.Sprintf("Header %q names more than one column; the last of them was read", column))
+	}
+	for _, kind := range skippedRowWarnings {
+		var rows []string
+		for _, skipped := range sheet.SkippedRows {
+			if skipped.Reason != kind.reason {
+				continue
+			}
+			row := strconv.Itoa(skipped.Row)
+			if skipped.DuplicateOf > 0 {
+				row += fmt.Sprintf(" (row %d)", skipped.DuplicateOf)
+			}
+			rows = append(rows, row)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		listed := strings.Join(topEntries(rows, maxListedRows), ", ")
+		if len(rows) > maxListedRows {
+			listed += fmt.Sprintf(" and %d more", len(rows)-maxListedRows)
+		}
+		warnings = append(warnings, fmt.Sprintf(kind.message, listed))
+	}
+	return warnings
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// topValueLimit is the number of most frequent values reported for each column
+const topValueLimit = 5
+
+// Column kinds decide which statistics are computed for a column
+const (
+	columnKindText    = "text"
+	columnKindDate    = "date"
+	columnKindNumber  = "number"
+	columnKindBoolean = "boolean"
+)
+
+// statsColumn is the incidents column a mapped field is stored in
+type statsColumn struct {
+	column string
+	kind   string
+}
+
+// statsColumns lists the stored column of every mappable field. The status history is not
+// stored itself; the pending hours derived from it are.
+var statsColumns = map[string]statsColumn{
+	"incident_id":         {"incident_id", columnKindText},
+	"application_name":    {"application_name", columnKindText},
+	"report_date":         {"report_date", columnKindDate},
+	"priority":            {"priority", columnKindText},
+	"status":              {"status", columnKindText},
+	"resolved_person":     {"resolved_person", columnKindText},
+	"resolve_date":        {"resolve_date", columnKindDate},
+	"brief_description":   {"brief_description", columnKindText},
+	"resolution_group":    {"resolution_group", columnKindText},
+	"it_process_group":    {"it_process_group", columnKindText},
+	"automation_feasible": {"automation_feasible", columnKindBoolean},
+	"automation_score":    {"automation_score", columnKindNumber},
+	"sentiment_label":     {"sentiment_label", columnKindText},
+	"sentiment_score":     {"sentiment_score", columnKindNumber},
+	"closure_code":        {"closure_code", columnKindText},
+	"cost":                {"cost", columnKindNumber},
+	"pending_hours":       {"pending_hours", columnKindNumber},
+	"status_history":      {"pending_hours", columnKindNumber},
+	"source":              {"source", columnKindText},
+	"region":              {"region", columnKindText},
+}
+
+// ValueCount is a value of a column and the number of incidents holding it
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// ColumnStats describes the values stored for one mapped field of an upload
+type ColumnStats struct {
+	Field    string `json:"field"`
+	Header   string `json:"header,omitempty"` // spreadsheet column the field was read from
+	StoredAs string `json:"stored_as"`
+	Kind     string `json:"kind"`
+	// NullCount counts incidents with no value or an empty one
+	NullCount     int          `json:"null_count"`
+	NullRate      float64      `json:"null_rate"` // percentage of the upload's incidents
+	DistinctCount int          `json:"distinct_count"`
+	MinDate       string       `json:"min_date,omitempty"`
+	MaxDate       string       `json:"max_date,omitempty"`
+	Min           *float64     `json:"min,omitempty"`
+	Max           *float64     `json:"max,omitempty"`
+	TopValues     []ValueCount `json:"top_values,omitempty"`
+}
+
+// UploadStats describes every mapped column of an upload's stored incidents, to verify the
+// column mapping and spot data issues before its analytics are relied on
+type UploadStats struct {
+	UploadID      string        `json:"upload_id"`
+	Status        string        `json:"status"`
+	IncidentCount int           `json:"incident_count"`
+	Columns       []ColumnStats `json:"columns"`
+	// UnmappedFields lists the mappable fields no column of the sheet was mapped to. It is
+	// only known for uploads parsed since mapped columns were recorded.
+	UnmappedFields []string `json:"unmapped_fields,omitempty"`
+}
+
+// UploadStatsService computes column statistics of processed uploads
+type UploadStatsService struct {
+	db *sql.DB
+}
+
+// NewUploadStatsService creates a new UploadStatsService instance
+func NewUploadStatsService(db *sql.DB) *UploadStatsService {
+	return &UploadStatsService{db: db}
+}
+
+// GetStats returns the column statistics of an upload, or sql.ErrNoRows when it does not
+// exist. Uploads parsed before mapped columns were recorded report every mappable field. A
+// restricted user's statistics cover only the incidents of their data scope.
+func (s *UploadStatsService) GetStats(ctx context.Context, uploadID string) (*UploadStats, error) {
+	stats := &UploadStats{UploadID: uploadID, Columns: []ColumnStats{}}
+	var sheetJSON string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT status, COALESCE(ingested_sheet, '') FROM uploads WHERE id = ?
+	`, uploadID).Scan(&stats.Status, &sheetJSON)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to query upload: %w", err)
+	}
+	scope, scopeArgs := scopeClause(ctx)
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM incidents WHERE upload_id = ?"+scope,
+		append([]interface{}{uploadID}, scopeArgs...)...).Scan(&stats.IncidentCount); err != nil {
+		return nil, fmt.Errorf("failed to count upload incidents: %w", err)
+	}
+
+	var headers map[string]string
+	if sheet := DecodeIngestedSheet(sheetJSON); sheet != nil && len(sheet.Columns) > 0 {
+		headers = sheet.Columns
+	}
+	fields := make([]string, 0, len(statsColumns))
+	for field := range statsColumns {
+		if headers == nil {
+			fields = append(fields, field)
+			continue
+		}
+		if _, mapped := headers[field]; mapped {
+			fields = append(fields, field)
+		} else {
+			stats.UnmappedFields = append(stats.UnmappedFields, field)
+		}
+	}
+	sort.Strings(fields)
+	sort.Strings(stats.UnmappedFields)
+
+	for _, field := range fields {
+		column, err := s.columnStats(ctx, uploadID, field, stats.IncidentCount)
+		if err != nil {
+			return nil, err
+		}
+		column.Header = headers[field]
+		stats.Columns = append(stats.Columns, *column)
+	}
+	return stats, nil
+}
+
+// columnStats computes the statistics of one field over the upload's incidents
+func (s *UploadStatsService) columnStats(ctx context.Context, uploadID, field string, total int) (*ColumnStats, error) {
+	spec := statsColumns[field]
+	stats := &ColumnStats{Field: field, StoredAs: spec.column, Kind: spec.kind}
+
+	// Column names come from statsColumns, never from the request
+	empty := spec.column + " IS NULL"
+	if spec.kind == columnKindText {
+		empty += " OR TRIM(" + spec.column + ") = ''"
+	}
+	var minDate, maxDate sql.NullString
+	var minValue, maxValue sql.NullFloat64
+	query := `SELECT COUNT(*) FILTER (WHERE ` + empty + `), COUNT(DISTINCT ` + spec.column + `)`
+	targets := []interface{}{&stats.NullCount, &stats.DistinctCount}
+	switch spec.kind {
+	case columnKindDate:
+		query += `, CAST(MIN(` + spec.column + `) AS VARCHAR), CAST(MAX(` + spec.column + `) AS VARCHAR)`
+		targets = append(targets, &minDate, &maxDate)
+	case columnKindNumber:
+		query += `, MIN(` + spec.column + `), MAX(` + spec.column + `)`
+		targets = append(targets, &minValue, &maxValue)
+	}
+	scope, scopeArgs := scopeClause(ctx)
+	query += ` FROM incidents WHERE upload_id = ?` + scope
+	if err := s.db.QueryRowContext(ctx, query, append([]interface{}{uploadID}, scopeArgs...)...).Scan(targets...); err != nil {
+		return nil, fmt.Errorf("failed to compute statistics of %s: %w", field, err)
+	}
+
+	if total > 0 {
+		stats.NullRate = roundTo(float64(stats.NullCount)*100/float64(total), 2)
+	}
+	stats.MinDate, stats.MaxDate = minDate.String, maxDate.String
+	if minValue.Valid {
+		stats.Min, stats.Max = &minValue.Float64, &maxValue.Float64
+	}
+	if spec.kind == columnKindText || spec.kind == columnKindBoolean {
+		topValues, err := s.topValues(ctx, uploadID, spec.column, empty)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query top values of %s: %w", field, err)
+		}
+		stats.TopValues = topValues
+	}
+	return stats, nil
+}
+
+// topValues returns the most frequent non-empty values of a column, most frequent first
+func (s *UploadStatsService) topValues(ctx context.Context, uploadID, column, empty string) ([]ValueCount, error) {
+	scope, scopeArgs := scopeClause(ctx)
+	args := append([]interface{}{uploadID}, scopeArgs...)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT CAST(`+column+` AS VARCHAR) AS value, COUNT(*) AS count
+		FROM incidents
+		WHERE upload_id = ? AND NOT (`+empty+`)`+scope+`
+		GROUP BY value
+		ORDER BY count DESC, value
+		LIMIT ?
+	`, append(args, topValueLimit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []ValueCount{}
+	for rows.Next() {
+		var value ValueCount
+		if err := rows.Scan(&value.Value, &value.Count); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
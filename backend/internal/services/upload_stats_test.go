@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/storage"
+)
+
+func TestUploadStatsService_GetStats(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	dir := t.TempDir()
+	processing := NewProcessingService(db, storage.NewFileStore(dir))
+	service := NewUploadStatsService(db)
+	ctx := context.Background()
+
+	writeTestWorkbook(t, dir, "stats.xlsx", [][]string{
+		{"Incident ID", "Report Date", "Application Name", "Priority", "Status", "Resolved Person", "Brief Description", "Resolution Group", "Cost"},
+		{"INC001", "2024-01-15", "Portal", "P2", "Closed", "Jane", "Login failure", "Web Team", "120"},
+		{"INC002", "2024-02-01", "Portal", "P3", "", "Jane", "Slow page", "Web Team", "80"},
+		{"INC003", "2024-03-10", "Billing", "P2", "Closed", "Raj", "Invoice error", "Finance", ""},
+		{"INC004", "2024-03-12", "Portal", "P1", "Open", "Raj", "Outage", "Web Team", "400"},
+	})
+	if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+		"upload-stats", "stats.xlsx", "stats.xlsx", models.UploadStatusUploaded); err != nil {
+		t.Fatalf("Failed to create upload: %v", err)
+	}
+	options := models.DefaultProcessingOptions()
+	options.RunSentiment, options.RunAutomation = false, false
+	if _, err := processing.ProcessUploadWithOptions(ctx, "upload-stats", options); err != nil {
+		t.Fatalf("Processing failed: %v", err)
+	}
+
+	stats, err := service.GetStats(ctx, "upload-stats")
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.IncidentCount != 4 || len(stats.Columns) != 9 {
+		t.Fatalf("expected 9 mapped columns over 4 incidents, got %d columns over %d", len(stats.Columns), stats.IncidentCount)
+	}
+	columns := make(map[string]ColumnStats)
+	for _, column := range stats.Columns {
+		columns[column.Field] = column
+	}
+
+	if app := columns["application_name"]; app.Header != "Application Name" || app.DistinctCount != 2 ||
+		len(app.TopValues) != 2 || app.TopValues[0] != (ValueCount{Value: "Portal", Count: 3}) {
+		t.Errorf("expected Portal as the most frequent of 2 applications, got %+v", app)
+	}
+	if status := columns["status"]; status.NullCount != 1 || status.NullRate != 25 {
+		t.Errorf("expected 1 of 4 statuses empty, got %+v", status)
+	}
+	if dates := columns["report_date"]; dates.MinDate != "2024-01-15" || dates.MaxDate != "2024-03-12" || dates.TopValues != nil {
+		t.Errorf("expected the report date range without top values, got %+v", dates)
+	}
+	if cost := columns["cost"]; cost.NullCount != 1 || cost.Min == nil || *cost.Min != 80 || *cost.Max != 400 {
+		t.Errorf("expected costs from 80 to 400 with 1 missing, got %+v", cost)
+	}
+	if _, found := columns["region"]; found {
+		t.Error("expected the unmapped region left out of the columns")
+	}
+	unmapped := false
+	for _, field := range stats.UnmappedFields {
+		unmapped = unmapped || field == "region"
+	}
+	if !unmapped {
+		t.Errorf("expected region among the unmapped fields, got %v", stats.UnmappedFields)
+	}
+
+	// A restricted user's statistics cover only the incidents of their scope
+	scoped := WithDataScope(ctx, &DataScope{UserID: "analyst", Applications: []string{"Billing"}})
+	stats, err = service.GetStats(scoped, "upload-stats")
+	if err != nil {
+		t.Fatalf("GetStats() scoped error = %v", err)
+	}
+	columns = make(map[string]ColumnStats)
+	for _, column := range stats.Columns {
+		columns[column.Field] = column
+	}
+	if stats.IncidentCount != 1 {
+		t.Errorf("expected the scoped statistics to cover 1 incident, got %d", stats.IncidentCount)
+	}
+	if app := columns["application_name"]; app.DistinctCount != 1 || len(app.TopValues) != 1 || app.TopValues[0].Value != "Billing" {
+		t.Errorf("expected only Billing in the scoped statistics, got %+v", app)
+	}
+	if people := columns["resolved_person"]; len(people.TopValues) != 1 || people.TopValues[0].Value != "Raj" {
+		t.Errorf("expected only the resolver of the Billing incident, got %+v", people.TopValues)
+	}
+
+	// Uploads without recorded columns report every mappable field
+	if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+		"upload-old", "old.xlsx", "old.xlsx", models.UploadStatusCompleted); err != nil {
+		t.Fatalf("Failed to create upload: %v", err)
+	}
+	stats, err = service.GetStats(ctx, "upload-old")
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if len(stats.Columns) != len(statsColumns) || len(stats.UnmappedFields) != 0 || stats.Columns[0].NullRate != 0 {
+		t.Errorf("expected every field reported for an upload without recorded columns, got %+v", stats)
+	}
+
+	if _, err := service.GetStats(ctx, "missing"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for a missing upload, got %v", err)
+	}
+}
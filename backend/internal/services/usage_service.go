@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"incident-management-system/internal/logging"
+)
+
+// UsageEvent is one API request as recorded for usage analytics. Users, tenants and filter
+// values are stored only as keyed hashes.
+type UsageEvent struct {
+	RecordedAt  time.Time
+	Method      string
+	Endpoint    string   // Route pattern, such as /api/incidents/:id
+	FilterKeys  []string // Names of the query parameters sent, sorted
+	FiltersHash string   // Hash of the query parameters and their values
+	Status      int
+	LatencyMs   float64
+	UserHash    string
+	TenantHash  string
+}
+
+// UsageConfig configures usage event recording
+type UsageConfig struct {
+	// Salt keys the hashes of users, tenants and filters. Without a fixed salt the hashes change
+	// on every restart and the same user is counted once per server run.
+	Salt          string
+	BufferSize    int           // Events queued before new ones are dropped, default 1024
+	BatchSize     int           // Events written per insert, default 200
+	FlushInterval time.Duration // Longest an event waits to be written, default 5s
+}
+
+// EndpointUsage summarizes the requests to one endpoint
+type EndpointUsage struct {
+	Method       string  `json:"method"`
+	Endpoint     string  `json:"endpoint"`
+	Requests     int     `json:"requests"`
+	Users        int     `json:"users"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	P95LatencyMs float64 `json:"p95_latency_ms"`
+	ErrorRate    float64 `json:"error_rate"`
+}
+
+// FilterUsage counts the requests to an endpoint that used a query parameter
+type FilterUsage struct {
+	Endpoint string `json:"endpoint"`
+	Filter   string `json:"filter"`
+	Requests int    `json:"requests"`
+	Users    int    `json:"users"`
+}
+
+// UserUsage summarizes the requests of one anonymized user
+type UserUsage struct {
+	UserHash  string    `json:"user_hash"`
+	Requests  int       `json:"requests"`
+	Endpoints int       `json:"endpoints"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// UsageSummary aggregates the usage events of a period
+type UsageSummary struct {
+	From          time.Time       `json:"from"`
+	To            time.Time       `json:"to"`
+	TotalRequests int             `json:"total_requests"`
+	Users         int             `json:"users"`
+	Tenants       int             `json:"tenants"`
+	Endpoints     []EndpointUsage `json:"endpoints"`
+	Filters       []FilterUsage   `json:"filters"`
+	TopUsers      []UserUsage     `json:"top_users"`
+	DroppedEvents int64           `json:"dropped_events"`
+}
+
+// UsageService records which endpoints and filters are used, and by how many people. Events are
+// written in batches from a background loop so tracking adds no database work to requests.
+type UsageService struct {
+	db      *sql.DB
+	config  UsageConfig
+	events  chan *UsageEvent
+	enabled atomic.Bool
+	dropped atomic.Int64
+	logger  *logging.Logger
+}
+
+// NewUsageService creates a usage service; call Run to start writing recorded events
+func NewUsageService(db *sql.DB, config UsageConfig) *UsageService {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1024
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 200
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+
+	service := &UsageService{
+		db:     db,
+		config: config,
+		events: make(chan *UsageEvent, config.BufferSize),
+		logger: logging.GetGlobalLogger().WithComponent("usage_service"),
+	}
+	service.enabled.Store(true)
+	return service
+}
+
+// SetEnabled turns recording on or off; events recorded while off are discarded
+func (s *UsageService) SetEnabled(enabled bool) {
+	s.enabled.Store(enabled)
+}
+
+// Enabled reports whether usage events are being recorded
+func (s *UsageService) Enabled() bool {
+	return s.enabled.Load()
+}
+
+// Anonymize returns a keyed hash of an identifier, or "" for an empty one
+func (s *UsageService) Anonymize(value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(s.config.Salt))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// Record queues an event without blocking. When the queue is full the event is dropped.
+func (s *UsageService) Record(event *UsageEvent) {
+	if !s.Enabled() {
+		return
+	}
+	select {
+	case s.events <- event:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// Run writes queued events until ctx is cancelled, then writes what is left
+func (s *UsageService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*UsageEvent, 0, s.config.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		// Writes use their own context so the final flush still runs after shutdown began
+		writeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := s.writeEvents(writeCtx, batch); err != nil {
+			s.logger.Error("Failed to write usage events", err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case event := <-s.events:
+					batch = append(batch, event)
+				default:
+					flush()
+					return
+				}
+			}
+		case event := <-s.events:
+			batch = append(batch, event)
+			if len(batch) >= s.config.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// writeEvents inserts a batch of events in one transaction
+func (s *UsageService) writeEvents(ctx context.Context, events []*UsageEvent) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO usage_events (
+			recorded_at, method, endpoint, filter_keys, filters_hash, status, latency_ms, user_hash, tenant_hash
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare usage insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, event := range events {
+		if _, err := stmt.ExecContext(ctx, event.RecordedAt, event.Method, event.Endpoint,
+			strings.Join(event.FilterKeys, ","), event.FiltersHash, event.Status, event.LatencyMs,
+			event.UserHash, event.TenantHash); err != nil {
+			return fmt.Errorf("failed to insert usage event: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetUsageSummary aggregates the events recorded between from and to, listing at most limit
+// endpoints, filters and users, busiest first
+func (s *UsageService) GetUsageSummary(ctx context.Context, from, to time.Time, limit int) (*UsageSummary, error) {
+	summary := &UsageSummary{
+		From:          from,
+		To:            to,
+		Endpoints:     []EndpointUsage{},
+		Filters:       []FilterUsage{},
+		TopUsers:      []UserUsage{},
+		DroppedEvents: s.dropped.Load(),
+	}
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(DISTINCT NULLIF(user_hash, '')), COUNT(DISTINCT NULLIF(tenant_hash, ''))
+		FROM usage_events
+		WHERE recorded_at >= ? AND recorded_at < ?
+	`, from, to).Scan(&summary.TotalRequests, &summary.Users, &summary.Tenants)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage totals: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT method, endpoint, COUNT(*) AS requests, COUNT(DISTINCT NULLIF(user_hash, '')),
+			AVG(latency_ms), QUANTILE_CONT(latency_ms, 0.95),
+			AVG(CASE WHEN status >= 500 THEN 1.0 ELSE 0.0 END)
+		FROM usage_events
+		WHERE recorded_at >= ? AND recorded_at < ?
+		GROUP BY method, endpoint
+		ORDER BY requests DESC, endpoint, method
+		LIMIT ?
+	`, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query endpoint usage: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var usage EndpointUsage
+		if err := rows.Scan(&usage.Method, &usage.Endpoint, &usage.Requests, &usage.Users,
+			&usage.AvgLatencyMs, &usage.P95LatencyMs, &usage.ErrorRate); err != nil {
+			return nil, fmt.Errorf("failed to scan endpoint usage: %w", err)
+		}
+		usage.AvgLatencyMs = roundTo(usage.AvgLatencyMs, 2)
+		usage.P95LatencyMs = roundTo(usage.P95LatencyMs, 2)
+		usage.ErrorRate = roundTo(usage.ErrorRate, 4)
+		summary.Endpoints = append(summary.Endpoints, usage)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read endpoint usage: %w", err)
+	}
+
+	filterRows, err := s.db.QueryContext(ctx, `
+		SELECT endpoint, filter, COUNT(*) AS requests, COUNT(DISTINCT NULLIF(user_hash, ''))
+		FROM (
+			SELECT endpoint, user_hash, UNNEST(string_split(filter_keys, ',')) AS filter
+			FROM usage_events
+			WHERE recorded_at >= ? AND recorded_at < ? AND filter_keys <> ''
+		)
+		GROUP BY endpoint, filter
+		ORDER BY requests DESC, endpoint, filter
+		LIMIT ?
+	`, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query filter usage: %w", err)
+	}
+	defer filterRows.Close()
+	for filterRows.Next() {
+		var usage FilterUsage
+		if err := filterRows.Scan(&usage.Endpoint, &usage.Filter, &usage.Requests, &usage.Users); err != nil {
+			return nil, fmt.Errorf("failed to scan filter usage: %w", err)
+		}
+		summary.Filters = append(summary.Filters, usage)
+	}
+	if err := filterRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read filter usage: %w", err)
+	}
+
+	userRows, err := s.db.QueryContext(ctx, `
+		SELECT user_hash, COUNT(*) AS requests, COUNT(DISTINCT method || ' ' || endpoint), MAX(recorded_at)
+		FROM usage_events
+		WHERE recorded_at >= ? AND recorded_at < ? AND user_hash <> ''
+		GROUP BY user_hash
+		ORDER BY requests DESC, user_hash
+		LIMIT ?
+	`, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user usage: %w", err)
+	}
+	defer userRows.Close()
+	for userRows.Next() {
+		var usage UserUsage
+		if err := userRows.Scan(&usage.UserHash, &usage.Requests, &usage.Endpoints, &usage.LastSeen); err != nil {
+			return nil, fmt.Errorf("failed to scan user usage: %w", err)
+		}
+		summary.TopUsers = append(summary.TopUsers, usage)
+	}
+	if err := userRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read user usage: %w", err)
+	}
+
+	return summary, nil
+}
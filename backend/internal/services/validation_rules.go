@@ -0,0 +1,385 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"incident-management-system/internal/models"
+)
+
+// ErrInvalidRuleSet is returned when a validation rule set has no rules or a rule is malformed
+var ErrInvalidRuleSet = errors.New("invalid validation rule set")
+
+// Validation rule types
+const (
+	// RuleRequired rejects rows where the field is empty
+	RuleRequired = "required"
+	// RuleAllowedValues rejects rows where the field is set to a value outside Values
+	RuleAllowedValues = "allowed_values"
+	// RulePattern rejects rows where the field is set and does not match Pattern
+	RulePattern = "pattern"
+	// RuleCompare rejects rows where the field does not compare to Other with Operator
+	RuleCompare = "compare"
+	// RuleRequiredIf rejects rows where the field is empty while Other has one of Values
+	RuleRequiredIf = "required_if"
+)
+
+// Comparison operators for RuleCompare. Only eq and ne apply to text fields.
+const (
+	OperatorEqual          = "eq"
+	OperatorNotEqual       = "ne"
+	OperatorLess           = "lt"
+	OperatorLessOrEqual    = "lte"
+	OperatorGreater        = "gt"
+	OperatorGreaterOrEqual = "gte"
+)
+
+// ruleTextFields reads the incident fields rules can check as text
+var ruleTextFields = map[string]func(*models.Incident) string{
+	"incident_id":       func(i *models.Incident) string { return i.IncidentID },
+	"application_name":  func(i *models.Incident) string { return i.ApplicationName },
+	"brief_description": func(i *models.Incident) string { return i.BriefDescription },
+	"resolution_group":  func(i *models.Incident) string { return i.ResolutionGroup },
+	"resolved_person":   func(i *models.Incident) string { return i.ResolvedPerson },
+	"priority":          func(i *models.Incident) string { return i.Priority },
+	"status":            func(i *models.Incident) string { return i.Status },
+	"it_process_group":  func(i *models.Incident) string { return i.ITProcessGroup },
+	"sentiment_label":   func(i *models.Incident) string { return i.SentimentLabel },
+}
+
+// ruleDateFields reads the incident dates rules can check; nil means the date is missing
+var ruleDateFields = map[string]func(*models.Incident) *time.Time{
+	"report_date": func(i *models.Incident) *time.Time {
+		if i.ReportDate.IsZero() {
+			return nil
+		}
+		return &i.ReportDate
+	},
+	"resolve_date": func(i *models.Incident) *time.Time { return i.ResolveDate },
+}
+
+// ValidationRule is one check applied to every parsed row
+type ValidationRule struct {
+	Type     string   `json:"type"`
+	Field    string   `json:"field"`
+	Values   []string `json:"values,omitempty"`
+	Pattern  string   `json:"pattern,omitempty"`
+	Operator string   `json:"operator,omitempty"`
+	Other    string   `json:"other,omitempty"`
+	// Message replaces the generated error message when set
+	Message string `json:"message,omitempty"`
+}
+
+// ValidationRuleSet is a named list of rules selected per upload, so each data source can be
+// checked as strictly as it needs
+type ValidationRuleSet struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Rules       []ValidationRule `json:"rules"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// RuleEngine checks incidents against a compiled rule set
+type RuleEngine struct {
+	rules    []ValidationRule
+	patterns map[int]*regexp.Regexp
+}
+
+// CompileRules validates rules and prepares them for checking incidents
+func CompileRules(rules []ValidationRule) (*RuleEngine, error) {
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("%w: at least one rule is required", ErrInvalidRuleSet)
+	}
+
+	engine := &RuleEngine{rules: rules, patterns: make(map[int]*regexp.Regexp)}
+	for i, rule := range rules {
+		if !isRuleField(rule.Field) {
+			return nil, fmt.Errorf("%w: rule %d: unknown field %q", ErrInvalidRuleSet, i+1, rule.Field)
+		}
+
+		switch rule.Type {
+		case RuleRequired:
+		case RuleAllowedValues:
+			if len(rule.Values) == 0 {
+				return nil, fmt.Errorf("%w: rule %d: allowed_values needs values", ErrInvalidRuleSet, i+1)
+			}
+		case RulePattern:
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil || rule.Pattern == "" {
+				return nil, fmt.Errorf("%w: rule %d: invalid pattern %q", ErrInvalidRuleSet, i+1, rule.Pattern)
+			}
+			engine.patterns[i] = pattern
+		case RuleCompare:
+			if !isRuleField(rule.Other) {
+				return nil, fmt.Errorf("%w: rule %d: unknown field %q", ErrInvalidRuleSet, i+1, rule.Other)
+			}
+			_, fieldIsDate := ruleDateFields[rule.Field]
+			_, otherIsDate := ruleDateFields[rule.Other]
+			if fieldIsDate != otherIsDate {
+				return nil, fmt.Errorf("%w: rule %d: cannot compare %s with %s", ErrInvalidRuleSet, i+1, rule.Field, rule.Other)
+			}
+			switch rule.Operator {
+			case OperatorEqual, OperatorNotEqual:
+			case OperatorLess, OperatorLessOrEqual, OperatorGreater, OperatorGreaterOrEqual:
+				if !fieldIsDate {
+					return nil, fmt.Errorf("%w: rule %d: operator %s needs date fields", ErrInvalidRuleSet, i+1, rule.Operator)
+				}
+			default:
+				return nil, fmt.Errorf("%w: rule %d: unknown operator %q", ErrInvalidRuleSet, i+1, rule.Operator)
+			}
+		case RuleRequiredIf:
+			if !isRuleField(rule.Other) || len(rule.Values) == 0 {
+				return nil, fmt.Errorf("%w: rule %d: required_if needs another field and values", ErrInvalidRuleSet, i+1)
+			}
+		default:
+			return nil, fmt.Errorf("%w: rule %d: unknown type %q", ErrInvalidRuleSet, i+1, rule.Type)
+		}
+	}
+
+	return engine, nil
+}
+
+// Check returns the rules the incident breaks as models.ValidationErrors, or nil when it passes
+func (e *RuleEngine) Check(incident *models.Incident) error {
+	var violations models.ValidationErrors
+	for i, rule := range e.rules {
+		value := ruleFieldText(incident, rule.Field)
+
+		var message string
+		switch rule.Type {
+		case RuleRequired:
+			if value == "" {
+				message = "is required"
+			}
+		case RuleAllowedValues:
+			if value != "" && !containsFold(rule.Values, value) {
+				message = fmt.Sprintf("must be one of: %s", strings.Join(rule.Values, ", "))
+			}
+		case RulePattern:
+			if value != "" && !e.patterns[i].MatchString(value) {
+				message = fmt.Sprintf("must match %s", rule.Pattern)
+			}
+		case RuleCompare:
+			if !compareRuleFields(incident, rule) {
+				message = fmt.Sprintf("must be %s %s", operatorWords[rule.Operator], rule.Other)
+			}
+		case RuleRequiredIf:
+			if value == "" && containsFold(rule.Values, ruleFieldText(incident, rule.Other)) {
+				message = fmt.Sprintf("is required when %s is %s", rule.Other, ruleFieldText(incident, rule.Other))
+			}
+		}
+
+		if message == "" {
+			continue
+		}
+		if rule.Message != "" {
+			message = rule.Message
+		}
+		violations = append(violations, models.ValidationError{
+			Field:   rule.Field,
+			Value:   value,
+			Message: message,
+		})
+	}
+
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+// operatorWords describes each comparison operator in error messages
+var operatorWords = map[string]string{
+	OperatorEqual:          "equal to",
+	OperatorNotEqual:       "different from",
+	OperatorLess:           "before",
+	OperatorLessOrEqual:    "on or before",
+	OperatorGreater:        "after",
+	OperatorGreaterOrEqual: "on or after",
+}
+
+// compareRuleFields reports whether the incident satisfies a compare rule. Rows where either
+// side is missing pass, so optional fields are only checked when present.
+func compareRuleFields(incident *models.Incident, rule ValidationRule) bool {
+	if readDate, ok := ruleDateFields[rule.Field]; ok {
+		left, right := readDate(incident), ruleDateFields[rule.Other](incident)
+		if left == nil || right == nil {
+			return true
+		}
+		switch rule.Operator {
+		case OperatorEqual:
+			return left.Equal(*right)
+		case OperatorNotEqual:
+			return !left.Equal(*right)
+		case OperatorLess:
+			return left.Before(*right)
+		case OperatorLessOrEqual:
+			return !left.After(*right)
+		case OperatorGreater:
+			return left.After(*right)
+		case OperatorGreaterOrEqual:
+			return !left.Before(*right)
+		}
+		return true
+	}
+
+	left, right := ruleFieldText(incident, rule.Field), ruleFieldText(incident, rule.Other)
+	if left == "" || right == "" {
+		return true
+	}
+	if rule.Operator == OperatorNotEqual {
+		return !strings.EqualFold(left, right)
+	}
+	return strings.EqualFold(left, right)
+}
+
+// ruleFieldText returns the trimmed text of a field, formatting dates as YYYY-MM-DD
+func ruleFieldText(incident *models.Incident, field string) string {
+	if readDate, ok := ruleDateFields[field]; ok {
+		if date := readDate(incident); date != nil {
+			return date.Format("2006-01-02")
+		}
+		return ""
+	}
+	return strings.TrimSpace(ruleTextFields[field](incident))
+}
+
+// isRuleField reports whether rules can check field
+func isRuleField(field string) bool {
+	_, isText := ruleTextFields[field]
+	_, isDate := ruleDateFields[field]
+	return isText || isDate
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationRuleSetService manages persisted validation rule sets
+type ValidationRuleSetService struct {
+	db *sql.DB
+}
+
+// NewValidationRuleSetService creates a new ValidationRuleSetService instance
+func NewValidationRuleSetService(db *sql.DB) *ValidationRuleSetService {
+	return &ValidationRuleSetService{db: db}
+}
+
+// ListRuleSets returns all validation rule sets ordered by name
+func (s *ValidationRuleSetService) ListRuleSets(ctx context.Context) ([]ValidationRuleSet, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, COALESCE(description, ''), rules, updated_at
+		FROM validation_rule_sets
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query validation rule sets: %w", err)
+	}
+	defer rows.Close()
+
+	ruleSets := []ValidationRuleSet{}
+	for rows.Next() {
+		ruleSet, err := scanValidationRuleSet(rows)
+		if err != nil {
+			return nil, err
+		}
+		ruleSets = append(ruleSets, *ruleSet)
+	}
+
+	return ruleSets, rows.Err()
+}
+
+// GetRuleSet returns the named validation rule set, or sql.ErrNoRows when it does not exist
+func (s *ValidationRuleSetService) GetRuleSet(ctx context.Context, name string) (*ValidationRuleSet, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT name, COALESCE(description, ''), rules, updated_at
+		FROM validation_rule_sets
+		WHERE name = ?
+	`, name)
+
+	return scanValidationRuleSet(row)
+}
+
+// SaveRuleSet creates or replaces a validation rule set after checking that its rules compile
+func (s *ValidationRuleSetService) SaveRuleSet(ctx context.Context, name, description string, rules []ValidationRule) (*ValidationRuleSet, error) {
+	for i := range rules {
+		rules[i].Field = strings.TrimSpace(rules[i].Field)
+		rules[i].Other = strings.TrimSpace(rules[i].Other)
+	}
+	if _, err := CompileRules(rules); err != nil {
+		return nil, err
+	}
+
+	ruleSet := &ValidationRuleSet{
+		Name:        strings.TrimSpace(name),
+		Description: strings.TrimSpace(description),
+		Rules:       rules,
+		UpdatedAt:   time.Now(),
+	}
+
+	rulesJSON, err := json.Marshal(ruleSet.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode validation rules: %w", err)
+	}
+
+	query := `
+		INSERT OR REPLACE INTO validation_rule_sets (name, description, rules, updated_at)
+		VALUES (?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, ruleSet.Name, nullIfEmpty(ruleSet.Description),
+		string(rulesJSON), ruleSet.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save validation rule set: %w", err)
+	}
+
+	return ruleSet, nil
+}
+
+// DeleteRuleSet removes a validation rule set, returning sql.ErrNoRows when it does not exist.
+// Uploads processed with the rule set keep its name in their recorded processing options.
+func (s *ValidationRuleSetService) DeleteRuleSet(ctx context.Context, name string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM validation_rule_sets WHERE name = ?", name)
+	if err != nil {
+		return fmt.Errorf("failed to delete validation rule set: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// LoadEngine compiles the named rule set, returning sql.ErrNoRows when it does not exist
+func (s *ValidationRuleSetService) LoadEngine(ctx context.Context, name string) (*RuleEngine, error) {
+	ruleSet, err := s.GetRuleSet(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return CompileRules(ruleSet.Rules)
+}
+
+// scanValidationRuleSet scans one validation rule set row and decodes its rules
+func scanValidationRuleSet(scanner interface{ Scan(...interface{}) error }) (*ValidationRuleSet, error) {
+	var ruleSet ValidationRuleSet
+	var rulesJSON string
+	if err := scanner.Scan(&ruleSet.Name, &ruleSet.Description, &rulesJSON, &ruleSet.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan validation rule set: %w", err)
+	}
+	if err := json.Unmarshal([]byte(rulesJSON), &ruleSet.Rules); err != nil {
+		return nil, fmt.Errorf("failed to decode validation rule set %s: %w", ruleSet.Name, err)
+	}
+	return &ruleSet, nil
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+	"incident-management-system/internal/storage"
+)
+
+func TestCompileRules(t *testing.T) {
+	invalid := []struct {
+		name  string
+		rules []ValidationRule
+	}{
+		{name: "no rules"},
+		{name: "unknown field", rules: []ValidationRule{{Type: RuleRequired, Field: "ticket_key"}}},
+		{name: "unknown type", rules: []ValidationRule{{Type: "unique", Field: "incident_id"}}},
+		{name: "allowed values without values", rules: []ValidationRule{{Type: RuleAllowedValues, Field: "status"}}},
+		{name: "bad pattern", rules: []ValidationRule{{Type: RulePattern, Field: "incident_id", Pattern: "INC("}}},
+		{name: "ordering text fields", rules: []ValidationRule{{Type: RuleCompare, Field: "status", Operator: OperatorLess, Other: "priority"}}},
+		{name: "date against text", rules: []ValidationRule{{Type: RuleCompare, Field: "resolve_date", Operator: OperatorEqual, Other: "status"}}},
+		{name: "required_if without values", rules: []ValidationRule{{Type: RuleRequiredIf, Field: "resolve_date", Other: "status"}}},
+	}
+	for _, tt := range invalid {
+		if _, err := CompileRules(tt.rules); !errors.Is(err, ErrInvalidRuleSet) {
+			t.Errorf("%s: expected ErrInvalidRuleSet, got %v", tt.name, err)
+		}
+	}
+
+	engine, err := CompileRules([]ValidationRule{
+		{Type: RuleRequired, Field: "application_name"},
+		{Type: RuleAllowedValues, Field: "priority", Values: []string{"P1", "P2"}},
+		{Type: RulePattern, Field: "incident_id", Pattern: `^INC\d{3}$`, Message: "must be an INC number"},
+		{Type: RuleCompare, Field: "resolve_date", Operator: OperatorGreaterOrEqual, Other: "report_date"},
+		{Type: RuleRequiredIf, Field: "resolve_date", Other: "status", Values: []string{"Closed", "Resolved"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to compile rules: %v", err)
+	}
+
+	reported := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	resolved := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	valid := models.Incident{IncidentID: "INC001", ApplicationName: "Portal", Priority: "p1", Status: "Open", ReportDate: reported}
+	if err := engine.Check(&valid); err != nil {
+		t.Errorf("Expected a valid incident to pass, got %v", err)
+	}
+
+	broken := models.Incident{IncidentID: "TKT-1", Priority: "P4", Status: "closed", ReportDate: reported, ResolveDate: &resolved}
+	violations, ok := engine.Check(&broken).(models.ValidationErrors)
+	if !ok || len(violations) != 4 {
+		t.Fatalf("Expected 4 violations, got %v", violations)
+	}
+	expected := []string{"application_name: is required", "priority: must be one of: P1, P2",
+		"incident_id: must be an INC number", "resolve_date: must be on or after report_date"}
+	for i, violation := range violations {
+		if got := violation.Field + ": " + violation.Message; got != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], got)
+		}
+	}
+
+	closed := models.Incident{IncidentID: "INC002", ApplicationName: "Portal", Priority: "P2", Status: "Resolved", ReportDate: reported}
+	violations, _ = engine.Check(&closed).(models.ValidationErrors)
+	if len(violations) != 1 || violations[0].Message != "is required when status is Resolved" {
+		t.Errorf("Expected the required_if rule to fail, got %v", violations)
+	}
+}
+
+func TestProcessingService_ProcessUploadWithRuleSet(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	dir := t.TempDir()
+	service := NewProcessingService(db, storage.NewFileStore(dir))
+	ctx := context.Background()
+
+	ruleSets := NewValidationRuleSetService(db)
+	if _, err := ruleSets.SaveRuleSet(ctx, "strict", "Vendor feed", []ValidationRule{
+		{Type: RuleRequired, Field: " application_name "},
+		{Type: RuleAllowedValues, Field: "priority", Values: []string{"P1", "P2", "P3"}},
+	}); err != nil {
+		t.Fatalf("Failed to save rule set: %v", err)
+	}
+	if _, err := ruleSets.SaveRuleSet(ctx, "broken", "", []ValidationRule{{Type: RuleRequired, Field: "nope"}}); !errors.Is(err, ErrInvalidRuleSet) {
+		t.Fatalf("Expected ErrInvalidRuleSet for an unknown field, got %v", err)
+	}
+
+	writeTestWorkbook(t, dir, "feed.xlsx", [][]string{
+		{"Incident ID", "Report Date", "Application", "Priority", "Description"},
+		{"INC001", "2024-03-01", "Portal", "P2", "Login failure"},
+		{"INC002", "2024-03-02", "", "P5", "Printer offline"},
+		{"INC003", "2024-03-03", "Billing", "P3", "Invoice stuck"},
+		{"INC004", "2024-03-04", "Billing", "P1", "Outage"},
+	})
+	createUpload := func(id string) {
+		t.Helper()
+		if _, err := db.Exec(`INSERT INTO uploads (id, filename, original_filename, status) VALUES (?, ?, ?, ?)`,
+			id, "feed.xlsx", "feed.xlsx", models.UploadStatusUploaded); err != nil {
+			t.Fatalf("Failed to create upload: %v", err)
+		}
+	}
+
+	options := models.DefaultProcessingOptions()
+	options.RuleSet = "strict"
+	options.ErrorThreshold = 25
+	options.RunSentiment = false
+	options.RunAutomation = false
+
+	t.Run("failing rows are rejected", func(t *testing.T) {
+		createUpload("upload-1")
+		progress, err := service.ProcessUploadWithOptions(ctx, "upload-1", options)
+		if err != nil {
+			t.Fatalf("Processing failed: %v", err)
+		}
+		if progress.ProcessedRows != 3 || progress.ErrorCount != 2 {
+			t.Fatalf("Expected 3 stored rows and 2 errors for one rejected row, got %+v", progress)
+		}
+		if !strings.Contains(progress.Errors[0], "row 3, field 'application_name': is required") {
+			t.Errorf("Expected a row-numbered rule error, got %v", progress.Errors)
+		}
+	})
+
+	t.Run("rejected rows count toward the error threshold", func(t *testing.T) {
+		createUpload("upload-2")
+		strict := options
+		strict.ErrorThreshold = 10
+		if _, err := service.ProcessUploadWithOptions(ctx, "upload-2", strict); err == nil {
+			t.Error("Expected 1 of 4 rejected rows to exceed a 10% threshold")
+		}
+	})
+
+	t.Run("unknown rule set", func(t *testing.T) {
+		createUpload("upload-3")
+		missing := options
+		missing.RuleSet = "lenient"
+		if _, err := service.ProcessUploadWithOptions(ctx, "upload-3", missing); err == nil || !strings.Contains(err.Error(), "validation rule set not found") {
+			t.Errorf("Expected a missing rule set error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,682 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Watchlist entity types select whether a watchlist follows applications or resolution groups
+const (
+	WatchlistApplication = "application"
+	WatchlistGroup       = "group"
+)
+
+// Watched metrics an alert can be raised for
+const (
+	WatchMetricVolume         = "volume"
+	WatchMetricResolutionTime = "resolution_time"
+)
+
+// Watchlist defaults and limits
+const (
+	DefaultWatchPeriodDays      = 7
+	DefaultWatchBaselinePeriods = 4
+	DefaultWatchMinIncidents    = 5
+	maxWatchlistEntities        = 50
+	maxWatchPeriodDays          = 90
+	maxWatchBaselinePeriods     = 12
+	watchlistAlertLimit         = 100
+)
+
+// ErrInvalidWatchlist is returned when a watchlist's entities, thresholds or channels are invalid
+var ErrInvalidWatchlist = errors.New("invalid watchlist")
+
+// Watchlist is a user's list of applications or resolution groups to watch for deterioration.
+// Each evaluation compares the last PeriodDays days with the average of the BaselinePeriods
+// periods before them, and raises an alert for every entity whose volume or average resolution
+// time grew by more than its threshold. A nil threshold leaves that metric unwatched.
+type Watchlist struct {
+	ID                    string     `json:"id"`
+	UserID                string     `json:"user_id"`
+	Name                  string     `json:"name"`
+	EntityType            string     `json:"entity_type"`
+	Entities              []string   `json:"entities"`
+	VolumeIncreasePct     *float64   `json:"volume_increase_pct,omitempty"`
+	ResolutionIncreasePct *float64   `json:"resolution_increase_pct,omitempty"`
+	PeriodDays            int        `json:"period_days"`
+	BaselinePeriods       int        `json:"baseline_periods"`
+	MinIncidents          int        `json:"min_incidents"`
+	Email                 string     `json:"email,omitempty"`
+	SlackWebhookURL       string     `json:"-"`
+	SlackConfigured       bool       `json:"slack_configured"`
+	LastEvaluatedAt       *time.Time `json:"last_evaluated_at,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+// WatchlistAlert records a watched entity that deteriorated beyond its threshold in a period.
+// Notified lists the channels the alert was delivered to.
+type WatchlistAlert struct {
+	ID            string    `json:"id"`
+	WatchlistID   string    `json:"watchlist_id"`
+	Entity        string    `json:"entity"`
+	Metric        string    `json:"metric"`
+	CurrentValue  float64   `json:"current_value"`
+	BaselineValue float64   `json:"baseline_value"`
+	ChangePct     float64   `json:"change_pct"`
+	ThresholdPct  float64   `json:"threshold_pct"`
+	PeriodStart   string    `json:"period_start"`
+	PeriodEnd     string    `json:"period_end"`
+	Notified      []string  `json:"notified"`
+	NotifyError   string    `json:"notify_error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// WatchedEntityMetrics compares a watched entity's current period with its baseline. Baseline
+// volume is the average per period; a nil change means the baseline has nothing to compare with.
+type WatchedEntityMetrics struct {
+	Entity                  string   `json:"entity"`
+	CurrentIncidents        int      `json:"current_incidents"`
+	BaselineIncidents       float64  `json:"baseline_incidents"`
+	VolumeChangePct         *float64 `json:"volume_change_pct"`
+	CurrentResolutionHours  *float64 `json:"current_resolution_hours"`
+	BaselineResolutionHours *float64 `json:"baseline_resolution_hours"`
+	ResolutionChangePct     *float64 `json:"resolution_change_pct"`
+}
+
+// WatchlistEvaluation is the result of evaluating a watchlist. Alerts holds the alerts raised
+// by this evaluation; an entity already alerted on for the period is not alerted on again.
+type WatchlistEvaluation struct {
+	WatchlistID string                 `json:"watchlist_id"`
+	PeriodStart string                 `json:"period_start"`
+	PeriodEnd   string                 `json:"period_end"`
+	Entities    []WatchedEntityMetrics `json:"entities"`
+	Alerts      []WatchlistAlert       `json:"alerts"`
+	EvaluatedAt time.Time              `json:"evaluated_at"`
+}
+
+// WatchlistService manages users' watchlists and evaluates them against their baselines
+type WatchlistService struct {
+	db          *sql.DB
+	scopes      *DataScopeService
+	notifier    *Notifier
+	preferences *NotificationPreferenceService
+}
+
+// NewWatchlistService creates a new WatchlistService instance. Alerts are delivered according
+// to the notification preferences of each watchlist's owner.
+func NewWatchlistService(db *sql.DB, notifier *Notifier) *WatchlistService {
+	return &WatchlistService{
+		db:          db,
+		scopes:      NewDataScopeService(db),
+		notifier:    notifier,
+		preferences: NewNotificationPreferenceService(db, notifier),
+	}
+}
+
+// watchlistColumns lists the columns scanned by scanWatchlist
+const watchlistColumns = `id, user_id, name, entity_type, entities, volume_increase_pct, resolution_increase_pct,
+	period_days, baseline_periods, min_incidents, email, slack_webhook_url, last_evaluated_at, created_at, updated_at`
+
+// watchlistAlertColumns lists the columns scanned by scanWatchlistAlert
+const watchlistAlertColumns = `id, watchlist_id, entity, metric, current_value, baseline_value, change_pct,
+	threshold_pct, period_start, period_end, notified, notify_error, created_at`
+
+// ListWatchlists returns a user's watchlists ordered by name
+func (s *WatchlistService) ListWatchlists(ctx context.Context, userID string) ([]Watchlist, error) {
+	return s.queryWatchlists(ctx, " WHERE user_id = ?", userID)
+}
+
+// GetWatchlist returns a user's watchlist, or sql.ErrNoRows when it does not exist or belongs
+// to another user
+func (s *WatchlistService) GetWatchlist(ctx context.Context, userID, id string) (*Watchlist, error) {
+	row := s.db.QueryRowContext(ctx,
+		"SELECT "+watchlistColumns+" FROM watchlists WHERE id = ? AND user_id = ?", id, userID)
+	return scanWatchlist(row)
+}
+
+// CreateWatchlist stores a new watchlist for a user
+func (s *WatchlistService) CreateWatchlist(ctx context.Context, userID string, watchlist Watchlist) (*Watchlist, error) {
+	if err := normalizeWatchlist(&watchlist); err != nil {
+		return nil, err
+	}
+	entities, err := json.Marshal(watchlist.Entities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode watchlist entities: %w", err)
+	}
+	watchlist.ID = uuid.New().String()
+	watchlist.UserID = userID
+	watchlist.CreatedAt = time.Now()
+	watchlist.UpdatedAt = watchlist.CreatedAt
+
+	query := `
+		INSERT INTO watchlists (id, user_id, name, entity_type, entities, volume_increase_pct, resolution_increase_pct,
+			period_days, baseline_periods, min_incidents, email, slack_webhook_url, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, watchlist.ID, watchlist.UserID, watchlist.Name, watchlist.EntityType,
+		string(entities), watchlist.VolumeIncreasePct, watchlist.ResolutionIncreasePct, watchlist.PeriodDays,
+		watchlist.BaselinePeriods, watchlist.MinIncidents, nullIfEmpty(watchlist.Email),
+		nullIfEmpty(watchlist.SlackWebhookURL), watchlist.CreatedAt, watchlist.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save watchlist: %w", err)
+	}
+	return &watchlist, nil
+}
+
+// UpdateWatchlist replaces the settings of a user's watchlist, returning sql.ErrNoRows when it
+// does not exist or belongs to another user
+func (s *WatchlistService) UpdateWatchlist(ctx context.Context, userID, id string, watchlist Watchlist) (*Watchlist, error) {
+	if err := normalizeWatchlist(&watchlist); err != nil {
+		return nil, err
+	}
+	entities, err := json.Marshal(watchlist.Entities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode watchlist entities: %w", err)
+	}
+
+	query := `
+		UPDATE watchlists
+		SET name = ?, entity_type = ?, entities = ?, volume_increase_pct = ?, resolution_increase_pct = ?,
+			period_days = ?, baseline_periods = ?, min_incidents = ?, email = ?, slack_webhook_url = ?, updated_at = ?
+		WHERE id = ? AND user_id = ?
+	`
+	result, err := s.db.ExecContext(ctx, query, watchlist.Name, watchlist.EntityType, string(entities),
+		watchlist.VolumeIncreasePct, watchlist.ResolutionIncreasePct, watchlist.PeriodDays, watchlist.BaselinePeriods,
+		watchlist.MinIncidents, nullIfEmpty(watchlist.Email), nullIfEmpty(watchlist.SlackWebhookURL), time.Now(),
+		id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update watchlist: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return s.GetWatchlist(ctx, userID, id)
+}
+
+// DeleteWatchlist removes a user's watchlist and its alerts, returning sql.ErrNoRows when it
+// does not exist or belongs to another user
+func (s *WatchlistService) DeleteWatchlist(ctx context.Context, userID, id string) error {
+	if _, err := s.GetWatchlist(ctx, userID, id); err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM watchlist_alerts WHERE watchlist_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete watchlist alerts: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM watchlists WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete watchlist: %w", err)
+	}
+	return nil
+}
+
+// ListAlerts returns the most recent alerts of a user's watchlist, newest first, or
+// sql.ErrNoRows when the watchlist does not exist or belongs to another user
+func (s *WatchlistService) ListAlerts(ctx context.Context, userID, id string) ([]WatchlistAlert, error) {
+	if _, err := s.GetWatchlist(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(
+		"SELECT %s FROM watchlist_alerts WHERE watchlist_id = ? ORDER BY created_at DESC, entity, metric LIMIT %d",
+		watchlistAlertColumns, watchlistAlertLimit), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchlist alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []WatchlistAlert{}
+	for rows.Next() {
+		alert, err := scanWatchlistAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, *alert)
+	}
+	return alerts, rows.Err()
+}
+
+// EvaluateWatchlist evaluates a user's watchlist at now and notifies the user of the alerts it
+// raises, returning sql.ErrNoRows when the watchlist does not exist or belongs to another user
+func (s *WatchlistService) EvaluateWatchlist(ctx context.Context, userID, id string, now time.Time) (*WatchlistEvaluation, error) {
+	watchlist, err := s.GetWatchlist(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.evaluate(ctx, watchlist, now)
+}
+
+// EvaluateDue evaluates every watchlist not yet evaluated for the period ending today, and
+// returns the number of alerts raised. Failures are logged and do not stop the other watchlists.
+func (s *WatchlistService) EvaluateDue(ctx context.Context, now time.Time) int {
+	watchlists, err := s.queryWatchlists(ctx, " WHERE last_evaluated_at IS NULL OR last_evaluated_at < ?", watchPeriodEnd(now))
+	if err != nil {
+		log.Printf("Warning: Failed to list due watchlists: %v", err)
+		return 0
+	}
+
+	raised := 0
+	for i := range watchlists {
+		evaluation, err := s.evaluate(ctx, &watchlists[i], now)
+		if err != nil {
+			log.Printf("Warning: Evaluation of watchlist %s failed: %v", watchlists[i].ID, err)
+			continue
+		}
+		raised += len(evaluation.Alerts)
+	}
+	return raised
+}
+
+// RunScheduler evaluates due watchlists every interval until ctx is cancelled
+func (s *WatchlistService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.EvaluateDue(ctx, now)
+		}
+	}
+}
+
+// evaluate compares the watchlist's entities with their baselines, within the data scope of its
+// owner, records an alert for each threshold exceeded and notifies the owner of the new ones
+func (s *WatchlistService) evaluate(ctx context.Context, watchlist *Watchlist, now time.Time) (*WatchlistEvaluation, error) {
+	scope, err := s.scopes.GetScope(ctx, watchlist.UserID)
+	if err != nil {
+		return nil, err
+	}
+	scopedCtx := WithDataScope(ctx, scope)
+
+	periodEnd := watchPeriodEnd(now)
+	periodStart := periodEnd.AddDate(0, 0, -watchlist.PeriodDays)
+	evaluation := &WatchlistEvaluation{
+		WatchlistID: watchlist.ID,
+		PeriodStart: periodStart.Format("2006-01-02"),
+		PeriodEnd:   periodEnd.Format("2006-01-02"),
+		Alerts:      []WatchlistAlert{},
+		EvaluatedAt: now,
+	}
+	if evaluation.Entities, err = s.entityMetrics(scopedCtx, watchlist, periodStart, periodEnd); err != nil {
+		return nil, err
+	}
+
+	for _, metrics := range evaluation.Entities {
+		if metrics.CurrentIncidents < watchlist.MinIncidents {
+			continue
+		}
+		candidates := []WatchlistAlert{}
+		if threshold := watchlist.VolumeIncreasePct; threshold != nil && metrics.VolumeChangePct != nil && *metrics.VolumeChangePct > *threshold {
+			candidates = append(candidates, WatchlistAlert{
+				Metric:        WatchMetricVolume,
+				CurrentValue:  float64(metrics.CurrentIncidents),
+				BaselineValue: metrics.BaselineIncidents,
+				ChangePct:     *metrics.VolumeChangePct,
+				ThresholdPct:  *threshold,
+			})
+		}
+		if threshold := watchlist.ResolutionIncreasePct; threshold != nil && metrics.ResolutionChangePct != nil && *metrics.ResolutionChangePct > *threshold {
+			candidates = append(candidates, WatchlistAlert{
+				Metric:        WatchMetricResolutionTime,
+				CurrentValue:  *metrics.CurrentResolutionHours,
+				BaselineValue: *metrics.BaselineResolutionHours,
+				ChangePct:     *metrics.ResolutionChangePct,
+				ThresholdPct:  *threshold,
+			})
+		}
+		for _, alert := range candidates {
+			alert.WatchlistID = watchlist.ID
+			alert.Entity = metrics.Entity
+			alert.PeriodStart = evaluation.PeriodStart
+			alert.PeriodEnd = evaluation.PeriodEnd
+			recorded, err := s.recordAlert(ctx, &alert)
+			if err != nil {
+				return nil, err
+			}
+			if recorded {
+				evaluation.Alerts = append(evaluation.Alerts, alert)
+			}
+		}
+	}
+
+	if len(evaluation.Alerts) > 0 {
+		s.notify(ctx, watchlist, evaluation, now)
+	}
+	if _, err := s.db.ExecContext(ctx, "UPDATE watchlists SET last_evaluated_at = ? WHERE id = ?", now, watchlist.ID); err != nil {
+		return nil, fmt.Errorf("failed to record watchlist evaluation: %w", err)
+	}
+	return evaluation, nil
+}
+
+// entityMetrics totals the incidents of each watched entity reported in the current period and
+// in the baseline periods before it, in watchlist order
+func (s *WatchlistService) entityMetrics(ctx context.Context, watchlist *Watchlist, periodStart, periodEnd time.Time) ([]WatchedEntityMetrics, error) {
+	column := "application_name"
+	if watchlist.EntityType == WatchlistGroup {
+		column = "resolution_group"
+	}
+	baselineStart := periodStart.AddDate(0, 0, -watchlist.PeriodDays*watchlist.BaselinePeriods)
+
+	placeholders := make([]string, len(watchlist.Entities))
+	args := []interface{}{periodStart}
+	for i, entity := range watchlist.Entities {
+		placeholders[i] = "?"
+		args = append(args, entity)
+	}
+	args = append(args, baselineStart, periodEnd)
+	scopeCondition, scopeArgs := scopeClause(ctx)
+	args = append(args, scopeArgs...)
+
+	query := fmt.Sprintf(`
+		SELECT %[1]s, report_date >= ? as is_current, COUNT(*), AVG(%[2]s)
+		FROM incidents
+		WHERE %[1]s IN (%[3]s) AND report_date >= ? AND report_date < ?%[4]s
+		GROUP BY 1, 2`, column, resolutionHoursExpr, strings.Join(placeholders, ","), scopeCondition)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watched entity metrics: %w", err)
+	}
+	defer rows.Close()
+
+	type periodTotals struct {
+		count         int
+		avgResolution sql.NullFloat64
+	}
+	current := make(map[string]periodTotals)
+	baseline := make(map[string]periodTotals)
+	for rows.Next() {
+		var entity string
+		var isCurrent bool
+		var totals periodTotals
+		if err := rows.Scan(&entity, &isCurrent, &totals.count, &totals.avgResolution); err != nil {
+			return nil, fmt.Errorf("failed to scan watched entity metrics: %w", err)
+		}
+		if isCurrent {
+			current[entity] = totals
+		} else {
+			baseline[entity] = totals
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating watched entity metrics: %w", err)
+	}
+
+	metrics := make([]WatchedEntityMetrics, 0, len(watchlist.Entities))
+	for _, entity := range watchlist.Entities {
+		cur, base := current[entity], baseline[entity]
+		entityMetrics := WatchedEntityMetrics{
+			Entity:            entity,
+			CurrentIncidents:  cur.count,
+			BaselineIncidents: roundTo(float64(base.count)/float64(watchlist.BaselinePeriods), 2),
+		}
+		if base.count > 0 {
+			change := roundTo((float64(cur.count)*float64(watchlist.BaselinePeriods)/float64(base.count)-1)*100, 1)
+			entityMetrics.VolumeChangePct = &change
+		}
+		if cur.avgResolution.Valid {
+			hours := roundTo(cur.avgResolution.Float64, 1)
+			entityMetrics.CurrentResolutionHours = &hours
+		}
+		if base.avgResolution.Valid {
+			hours := roundTo(base.avgResolution.Float64, 1)
+			entityMetrics.BaselineResolutionHours = &hours
+			if cur.avgResolution.Valid && base.avgResolution.Float64 > 0 {
+				change := roundTo((cur.avgResolution.Float64/base.avgResolution.Float64-1)*100, 1)
+				entityMetrics.ResolutionChangePct = &change
+			}
+		}
+		metrics = append(metrics, entityMetrics)
+	}
+	return metrics, nil
+}
+
+// recordAlert stores an alert unless one was already raised for its entity, metric and period,
+// and reports whether it was stored
+func (s *WatchlistService) recordAlert(ctx context.Context, alert *WatchlistAlert) (bool, error) {
+	alert.ID = uuid.New().String()
+	alert.Notified = []string{}
+	alert.CreatedAt = time.Now()
+
+	result, err := s.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO watchlist_alerts (id, watchlist_id, entity, metric, current_value, baseline_value,
+			change_pct, threshold_pct, period_start, period_end, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, alert.ID, alert.WatchlistID, alert.Entity, alert.Metric, alert.CurrentValue, alert.BaselineValue,
+		alert.ChangePct, alert.ThresholdPct, alert.PeriodStart, alert.PeriodEnd, alert.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to record watchlist alert: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// notify sends one message summarizing the new alerts of the events the owner is subscribed to
+// on each channel of the watchlist, following the owner's notification preferences, and records
+// on the alerts where they were delivered or held and why a delivery failed
+func (s *WatchlistService) notify(ctx context.Context, watchlist *Watchlist, evaluation *WatchlistEvaluation, now time.Time) {
+	if s.notifier == nil {
+		return
+	}
+	preferences, err := s.preferences.GetPreferences(ctx, watchlist.UserID)
+	if err != nil {
+		log.Printf("Warning: Failed to load notification preferences of %s: %v", watchlist.UserID, err)
+		return
+	}
+	var wanted []int
+	var alerts []WatchlistAlert
+	for i, alert := range evaluation.Alerts {
+		if preferences.Wants(watchlistEvent(alert.Metric)) {
+			wanted = append(wanted, i)
+			alerts = append(alerts, alert)
+		}
+	}
+	if len(alerts) == 0 {
+		return
+	}
+
+	subject, body := watchlistMessage(watchlist, evaluation, alerts)
+	result, err := s.preferences.Deliver(ctx, preferences, Notification{
+		UserID:          watchlist.UserID,
+		Event:           watchlistEvent(alerts[0].Metric),
+		Subject:         subject,
+		Body:            body,
+		Email:           watchlist.Email,
+		SlackWebhookURL: watchlist.SlackWebhookURL,
+	}, now)
+	if err != nil {
+		log.Printf("Warning: Failed to deliver alerts of watchlist %s: %v", watchlist.ID, err)
+		return
+	}
+
+	notified, failures := result.Sent, result.Failures
+	for _, channel := range result.Held {
+		notified = append(notified, channel+":held")
+	}
+	if len(notified) == 0 && len(failures) == 0 {
+		return
+	}
+
+	channels := strings.Join(notified, ",")
+	notifyError := strings.Join(failures, "; ")
+	for _, index := range wanted {
+		alert := &evaluation.Alerts[index]
+		if _, err := s.db.ExecContext(ctx, "UPDATE watchlist_alerts SET notified = ?, notify_error = ? WHERE id = ?",
+			nullIfEmpty(channels), nullIfEmpty(notifyError), alert.ID); err != nil {
+			log.Printf("Warning: Failed to record notification of watchlist alert %s: %v", alert.ID, err)
+			continue
+		}
+		if notified != nil {
+			alert.Notified = notified
+		}
+		alert.NotifyError = notifyError
+	}
+}
+
+// watchlistEvent returns the notification event of alerts on a watched metric
+func watchlistEvent(metric string) string {
+	if metric == WatchMetricResolutionTime {
+		return NotifyWatchlistResolutionTime
+	}
+	return NotifyWatchlistVolume
+}
+
+// watchlistMessage returns the subject and plain text body of the notification of new alerts
+func watchlistMessage(watchlist *Watchlist, evaluation *WatchlistEvaluation, alerts []WatchlistAlert) (string, string) {
+	subject := fmt.Sprintf("Watchlist %q: %d alert(s) for %s to %s", watchlist.Name, len(alerts),
+		evaluation.PeriodStart, evaluation.PeriodEnd)
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "The last %d days are compared with the average of the %d periods before them.\n\n",
+		watchlist.PeriodDays, watchlist.BaselinePeriods)
+	for _, alert := range alerts {
+		switch alert.Metric {
+		case WatchMetricVolume:
+			fmt.Fprintf(&body, "- %s: %.0f incidents against a baseline of %.1f (+%.1f%%, threshold %.1f%%)\n",
+				alert.Entity, alert.CurrentValue, alert.BaselineValue, alert.ChangePct, alert.ThresholdPct)
+		case WatchMetricResolutionTime:
+			fmt.Fprintf(&body, "- %s: average resolution %.1fh against a baseline of %.1fh (+%.1f%%, threshold %.1f%%)\n",
+				alert.Entity, alert.CurrentValue, alert.BaselineValue, alert.ChangePct, alert.ThresholdPct)
+		}
+	}
+	return subject, body.String()
+}
+
+// queryWatchlists loads the watchlists matching the where clause ordered by name
+func (s *WatchlistService) queryWatchlists(ctx context.Context, where string, args ...interface{}) ([]Watchlist, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+watchlistColumns+" FROM watchlists"+where+" ORDER BY name, id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchlists: %w", err)
+	}
+	defer rows.Close()
+
+	watchlists := []Watchlist{}
+	for rows.Next() {
+		watchlist, err := scanWatchlist(rows)
+		if err != nil {
+			return nil, err
+		}
+		watchlists = append(watchlists, *watchlist)
+	}
+	return watchlists, rows.Err()
+}
+
+// watchPeriodEnd returns the exclusive end of the period evaluated at now: the start of today (UTC)
+func watchPeriodEnd(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// normalizeWatchlist trims the fields of a watchlist, applies the defaults and checks its
+// entities, thresholds and channels
+func normalizeWatchlist(watchlist *Watchlist) error {
+	watchlist.Name = strings.TrimSpace(watchlist.Name)
+	watchlist.EntityType = strings.TrimSpace(watchlist.EntityType)
+	watchlist.Entities = uniqueSorted(watchlist.Entities)
+	watchlist.Email = strings.TrimSpace(watchlist.Email)
+	watchlist.SlackWebhookURL = strings.TrimSpace(watchlist.SlackWebhookURL)
+	if watchlist.PeriodDays == 0 {
+		watchlist.PeriodDays = DefaultWatchPeriodDays
+	}
+	if watchlist.BaselinePeriods == 0 {
+		watchlist.BaselinePeriods = DefaultWatchBaselinePeriods
+	}
+	if watchlist.MinIncidents == 0 {
+		watchlist.MinIncidents = DefaultWatchMinIncidents
+	}
+	watchlist.SlackConfigured = watchlist.SlackWebhookURL != ""
+
+	switch {
+	case watchlist.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidWatchlist)
+	case watchlist.EntityType != WatchlistApplication && watchlist.EntityType != WatchlistGroup:
+		return fmt.Errorf("%w: entity_type must be %q or %q", ErrInvalidWatchlist, WatchlistApplication, WatchlistGroup)
+	case len(watchlist.Entities) == 0:
+		return fmt.Errorf("%w: at least one entity is required", ErrInvalidWatchlist)
+	case len(watchlist.Entities) > maxWatchlistEntities:
+		return fmt.Errorf("%w: at most %d entities can be watched", ErrInvalidWatchlist, maxWatchlistEntities)
+	case watchlist.VolumeIncreasePct == nil && watchlist.ResolutionIncreasePct == nil:
+		return fmt.Errorf("%w: a volume or resolution threshold is required", ErrInvalidWatchlist)
+	case watchlist.VolumeIncreasePct != nil && *watchlist.VolumeIncreasePct <= 0,
+		watchlist.ResolutionIncreasePct != nil && *watchlist.ResolutionIncreasePct <= 0:
+		return fmt.Errorf("%w: thresholds must be positive percentages", ErrInvalidWatchlist)
+	case watchlist.PeriodDays < 1 || watchlist.PeriodDays > maxWatchPeriodDays:
+		return fmt.Errorf("%w: period_days must be between 1 and %d", ErrInvalidWatchlist, maxWatchPeriodDays)
+	case watchlist.BaselinePeriods < 1 || watchlist.BaselinePeriods > maxWatchBaselinePeriods:
+		return fmt.Errorf("%w: baseline_periods must be between 1 and %d", ErrInvalidWatchlist, maxWatchBaselinePeriods)
+	case watchlist.MinIncidents < 0:
+		return fmt.Errorf("%w: min_incidents cannot be negative", ErrInvalidWatchlist)
+	}
+	if watchlist.Email != "" {
+		if _, err := mail.ParseAddress(watchlist.Email); err != nil {
+			return fmt.Errorf("%w: email %q is not a valid address", ErrInvalidWatchlist, watchlist.Email)
+		}
+	}
+	if watchlist.SlackWebhookURL != "" && !validSlackWebhook(watchlist.SlackWebhookURL) {
+		return fmt.Errorf("%w: slack_webhook_url must be an https URL", ErrInvalidWatchlist)
+	}
+	return nil
+}
+
+// scanWatchlist scans a watchlists row selected with watchlistColumns
+func scanWatchlist(row interface{ Scan(...interface{}) error }) (*Watchlist, error) {
+	var watchlist Watchlist
+	var entities string
+	var volume, resolution sql.NullFloat64
+	var email, slackWebhook sql.NullString
+	var lastEvaluated sql.NullTime
+	if err := row.Scan(&watchlist.ID, &watchlist.UserID, &watchlist.Name, &watchlist.EntityType, &entities,
+		&volume, &resolution, &watchlist.PeriodDays, &watchlist.BaselinePeriods, &watchlist.MinIncidents,
+		&email, &slackWebhook, &lastEvaluated, &watchlist.CreatedAt, &watchlist.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan watchlist: %w", err)
+	}
+	if err := json.Unmarshal([]byte(entities), &watchlist.Entities); err != nil {
+		return nil, fmt.Errorf("failed to decode watchlist entities: %w", err)
+	}
+	if volume.Valid {
+		watchlist.VolumeIncreasePct = &volume.Float64
+	}
+	if resolution.Valid {
+		watchlist.ResolutionIncreasePct = &resolution.Float64
+	}
+	if lastEvaluated.Valid {
+		watchlist.LastEvaluatedAt = &lastEvaluated.Time
+	}
+	watchlist.Email = email.String
+	watchlist.SlackWebhookURL = slackWebhook.String
+	watchlist.SlackConfigured = watchlist.SlackWebhookURL != ""
+	return &watchlist, nil
+}
+
+// scanWatchlistAlert scans a watchlist_alerts row selected with watchlistAlertColumns
+func scanWatchlistAlert(row interface{ Scan(...interface{}) error }) (*WatchlistAlert, error) {
+	var alert WatchlistAlert
+	var periodStart, periodEnd time.Time
+	var notified, notifyError sql.NullString
+	if err := row.Scan(&alert.ID, &alert.WatchlistID, &alert.Entity, &alert.Metric, &alert.CurrentValue,
+		&alert.BaselineValue, &alert.ChangePct, &alert.ThresholdPct, &periodStart, &periodEnd,
+		&notified, &notifyError, &alert.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to scan watchlist alert: %w", err)
+	}
+	alert.PeriodStart = periodStart.Format("2006-01-02")
+	alert.PeriodEnd = periodEnd.Format("2006-01-02")
+	alert.Notified = []string{}
+	if notified.String != "" {
+		alert.Notified = strings.Split(notified.String, ",")
+	}
+	alert.NotifyError = notifyError.String
+	return &alert, nil
+}
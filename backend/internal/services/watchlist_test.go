@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"incident-management-system/internal/database"
+	"incident-management-system/internal/models"
+)
+
+func TestWatchlistService(t *testing.T) {
+	config := &database.Config{
+		DatabasePath: ":memory:",
+	}
+	dbWrapper, err := database.NewDB(config)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer dbWrapper.Close()
+
+	if err := dbWrapper.InitializeDatabase(); err != nil {
+		t.Fatalf("Failed to initialize database schema: %v", err)
+	}
+
+	db := dbWrapper.GetConnection()
+	ctx := context.Background()
+
+	// Evaluated on Feb 1: the current week is Jan 25-31, the baseline the four weeks before it.
+	// Portal goes from one incident resolved in 4h per week to six resolved in 8h; Billing
+	// stays at one incident per week.
+	var incidents []models.Incident
+	add := func(application string, reported time.Time, resolvedAfter time.Duration) {
+		n := len(incidents) + 1
+		incident := diffTestIncident(fmt.Sprintf("i%d", n), "upload-1", fmt.Sprintf("INC%03d", n), "P3", "Closed")
+		incident.ApplicationName = application
+		incident.ReportDate = reported
+		resolved := reported.Add(resolvedAfter)
+		incident.ResolveDate = &resolved
+		incident.CalculateResolutionTime()
+		incidents = append(incidents, incident)
+	}
+	for week := 1; week <= 4; week++ {
+		reported := time.Date(2024, 1, 25, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -7*week)
+		add("Portal", reported, 4*time.Hour)
+		add("Billing", reported, 4*time.Hour)
+	}
+	for day := 25; day <= 30; day++ {
+		add("Portal", time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC), 8*time.Hour)
+	}
+	add("Billing", time.Date(2024, 1, 26, 0, 0, 0, 0, time.UTC), 4*time.Hour)
+	// Reported on the evaluation day, outside the current period
+	add("Portal", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), 4*time.Hour)
+	if _, err := NewIncidentService(db).BatchInsertIncidents(ctx, incidents, "upload-1"); err != nil {
+		t.Fatalf("Failed to insert incidents: %v", err)
+	}
+
+	var slackMessages []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode slack message: %v", err)
+		}
+		slackMessages = append(slackMessages, payload["text"])
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(nil)
+	notifier.httpClient = server.Client()
+	service := NewWatchlistService(db, notifier)
+
+	volume, resolution := 50.0, 25.0
+	watchlist, err := service.CreateWatchlist(ctx, "alice", Watchlist{
+		Name:                  " Customer apps ",
+		EntityType:            WatchlistApplication,
+		Entities:              []string{"Portal", "Billing", "Portal"},
+		VolumeIncreasePct:     &volume,
+		ResolutionIncreasePct: &resolution,
+		Email:                 "alice@example.com",
+		SlackWebhookURL:       server.URL,
+	})
+	if err != nil {
+		t.Fatalf("CreateWatchlist() error = %v", err)
+	}
+	if watchlist.Name != "Customer apps" || len(watchlist.Entities) != 2 || watchlist.PeriodDays != DefaultWatchPeriodDays ||
+		watchlist.BaselinePeriods != DefaultWatchBaselinePeriods || !watchlist.SlackConfigured {
+		t.Errorf("expected a normalized watchlist with defaults, got %+v", watchlist)
+	}
+
+	if _, err := service.CreateWatchlist(ctx, "alice", Watchlist{Name: "No thresholds", EntityType: WatchlistGroup, Entities: []string{"Web Team"}}); !errors.Is(err, ErrInvalidWatchlist) {
+		t.Errorf("expected ErrInvalidWatchlist without thresholds, got %v", err)
+	}
+	if _, err := service.CreateWatchlist(ctx, "alice", Watchlist{Name: "Plain webhook", EntityType: WatchlistGroup,
+		Entities: []string{"Web Team"}, VolumeIncreasePct: &volume, SlackWebhookURL: "http://hooks.example.com/x"}); !errors.Is(err, ErrInvalidWatchlist) {
+		t.Errorf("expected ErrInvalidWatchlist for a plain http webhook, got %v", err)
+	}
+
+	// Watchlists belong to their creator
+	if _, err := service.GetWatchlist(ctx, "bob", watchlist.ID); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for another user's watchlist, got %v", err)
+	}
+	if err := service.DeleteWatchlist(ctx, "bob", watchlist.ID); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows deleting another user's watchlist, got %v", err)
+	}
+	if lists, err := service.ListWatchlists(ctx, "bob"); err != nil || len(lists) != 0 {
+		t.Errorf("expected no watchlists for bob, got %v, %v", lists, err)
+	}
+
+	now := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+	evaluation, err := service.EvaluateWatchlist(ctx, "alice", watchlist.ID, now)
+	if err != nil {
+		t.Fatalf("EvaluateWatchlist() error = %v", err)
+	}
+	if evaluation.PeriodStart != "2024-01-25" || evaluation.PeriodEnd != "2024-02-01" {
+		t.Errorf("expected the period Jan 25 to Feb 1, got %s to %s", evaluation.PeriodStart, evaluation.PeriodEnd)
+	}
+	metrics := make(map[string]WatchedEntityMetrics)
+	for _, entity := range evaluation.Entities {
+		metrics[entity.Entity] = entity
+	}
+	portal := metrics["Portal"]
+	if portal.CurrentIncidents != 6 || portal.BaselineIncidents != 1 || portal.VolumeChangePct == nil || *portal.VolumeChangePct != 500 {
+		t.Errorf("expected Portal volume 6 against 1 (+500%%), got %+v", portal)
+	}
+	if portal.ResolutionChangePct == nil || *portal.ResolutionChangePct != 100 {
+		t.Errorf("expected Portal resolution time to double, got %+v", portal)
+	}
+	if billing := metrics["Billing"]; billing.VolumeChangePct == nil || *billing.VolumeChangePct != 0 {
+		t.Errorf("expected unchanged Billing volume, got %+v", billing)
+	}
+
+	// Billing is below the minimum incidents; Portal breaches both thresholds
+	if len(evaluation.Alerts) != 2 {
+		t.Fatalf("expected 2 alerts, got %+v", evaluation.Alerts)
+	}
+	for _, alert := range evaluation.Alerts {
+		if alert.Entity != "Portal" {
+			t.Errorf("expected only Portal alerts, got %+v", alert)
+		}
+		// No SMTP relay is configured: Slack delivers, email fails
+		if len(alert.Notified) != 1 || alert.Notified[0] != "slack" || !strings.Contains(alert.NotifyError, "email") {
+			t.Errorf("expected a Slack delivery and an email failure, got %+v", alert)
+		}
+	}
+	if len(slackMessages) != 1 || !strings.Contains(slackMessages[0], "Portal") {
+		t.Errorf("expected one Slack message about Portal, got %v", slackMessages)
+	}
+
+	// Alerts are raised once per period, so the scheduler skips the evaluated watchlist today and
+	// a second evaluation does not notify again
+	if raised := service.EvaluateDue(ctx, now.Add(time.Hour)); raised != 0 {
+		t.Errorf("expected no due watchlists, got %d alerts", raised)
+	}
+	again, err := service.EvaluateWatchlist(ctx, "alice", watchlist.ID, now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("EvaluateWatchlist() error = %v", err)
+	}
+	if len(again.Alerts) != 0 || len(slackMessages) != 1 {
+		t.Errorf("expected no new alerts or messages, got %+v and %d messages", again.Alerts, len(slackMessages))
+	}
+
+	alerts, err := service.ListAlerts(ctx, "alice", watchlist.ID)
+	if err != nil {
+		t.Fatalf("ListAlerts() error = %v", err)
+	}
+	if len(alerts) != 2 || alerts[0].PeriodEnd != "2024-02-01" || len(alerts[0].Notified) != 1 {
+		t.Errorf("expected the 2 stored alerts, got %+v", alerts)
+	}
+
+	// A scoped owner only watches the incidents of their scope
+	if _, err := NewDataScopeService(db).SetScope(ctx, "alice", []string{"Billing"}, nil, "admin"); err != nil {
+		t.Fatalf("SetScope() error = %v", err)
+	}
+	scoped, err := service.EvaluateWatchlist(ctx, "alice", watchlist.ID, now.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("EvaluateWatchlist() error = %v", err)
+	}
+	for _, entity := range scoped.Entities {
+		if entity.Entity == "Portal" && entity.CurrentIncidents != 0 {
+			t.Errorf("expected Portal outside alice's scope, got %+v", entity)
+		}
+	}
+
+	if err := service.DeleteWatchlist(ctx, "alice", watchlist.ID); err != nil {
+		t.Fatalf("DeleteWatchlist() error = %v", err)
+	}
+	if _, err := service.ListAlerts(ctx, "alice", watchlist.ID); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows after deleting, got %v", err)
+	}
+}
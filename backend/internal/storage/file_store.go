@@ -64,6 +64,39 @@ func (fs *FileStore) SaveUploadedFile(file *multipart.FileHeader) (string, strin
 	return uniqueFilename, filePath, nil
 }
 
+// SaveGeneratedFile stores a file produced by the server, such as a workbook built from an
+// imported sheet, under a unique name. write receives the destination file.
+func (fs *FileStore) SaveGeneratedFile(originalFilename string, write func(io.Writer) error) (string, error) {
+	if !fs.isValidExcelFile(originalFilename) {
+		return "", fmt.Errorf("invalid file format: only .xlsx and .xls files are supported")
+	}
+
+	uniqueFilename := fs.generateUniqueFilename(originalFilename)
+	filePath := filepath.Join(fs.uploadDir, uniqueFilename)
+
+	// Ensure upload directory exists
+	if err := os.MkdirAll(fs.uploadDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	dst, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create destination file: %w", err)
+	}
+
+	if err := write(dst); err != nil {
+		dst.Close()
+		os.Remove(filePath)
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(filePath)
+		return "", fmt.Errorf("failed to save file: %w", err)
+	}
+
+	return uniqueFilename, nil
+}
+
 // DeleteFile removes a file from storage
 func (fs *FileStore) DeleteFile(filename string) error {
 	filePath := filepath.Join(fs.uploadDir, filename)
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURLSigner(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	signer := NewURLSigner([]byte("key"))
+	signer.now = func() time.Time { return now }
+
+	signed, expiresAt := signer.SignedURL("/files/uploads/u1", time.Minute)
+	if !expiresAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected expiry %v, got %v", now.Add(time.Minute), expiresAt)
+	}
+	path, rawQuery, _ := strings.Cut(signed, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		t.Fatalf("Failed to parse signed URL %s: %v", signed, err)
+	}
+
+	if err := signer.Verify(path, query.Get("expires"), query.Get("signature")); err != nil {
+		t.Errorf("Expected a valid link, got %v", err)
+	}
+	if err := signer.Verify("/files/uploads/u2", query.Get("expires"), query.Get("signature")); err != ErrInvalidSignature {
+		t.Errorf("Expected another path to be rejected, got %v", err)
+	}
+	if err := signer.Verify(path, "not-a-number", query.Get("signature")); err != ErrInvalidSignature {
+		t.Errorf("Expected a malformed expiry to be rejected, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := signer.Verify(path, query.Get("expires"), query.Get("signature")); err != ErrLinkExpired {
+		t.Errorf("Expected the link to expire, got %v", err)
+	}
+
+	// Without a key each signer has its own random key
+	if err := NewURLSigner(nil).Verify(path, query.Get("expires"), query.Get("signature")); err != ErrInvalidSignature {
+		t.Errorf("Expected a link signed with another key to be rejected, got %v", err)
+	}
+}
@@ -1,20 +1,19 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"expvar"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"incident-management-system/internal/database"
-	"incident-management-system/internal/errors"
-	"incident-management-system/internal/handlers"
 	"incident-management-system/internal/logging"
 	"incident-management-system/internal/monitoring"
-	"incident-management-system/internal/services"
-	"incident-management-system/internal/storage"
-
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
+	"incident-management-system/internal/server"
 )
 
 func main() {
@@ -27,135 +26,62 @@ func main() {
 		TimeFormat: "2006-01-02T15:04:05.000Z",
 	}
 
+	// LOG_SINKS ships logs to external destinations as well as stdout, as a JSON array such as
+	// [{"type":"file","path":"logs/server.log","level":"WARN"},{"type":"otlp","url":"http://collector:4318/v1/logs"}]
+	if sinks := os.Getenv("LOG_SINKS"); sinks != "" {
+		if err := json.Unmarshal([]byte(sinks), &logConfig.Sinks); err != nil {
+			log.Fatal("Invalid LOG_SINKS:", err)
+		}
+	}
+
 	if err := logging.InitGlobalLogger(logConfig); err != nil {
 		log.Fatal("Failed to initialize logger:", err)
 	}
 
 	logger := logging.GetGlobalLogger()
+	defer logger.Close()
 	logger.Info("Starting Incident Management System")
 
+	// Server-lifetime context, cancelled on SIGINT/SIGTERM so background work stops on shutdown
+	appCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	// Initialize monitoring
 	monitoring.InitMonitoring(logger)
 
-	// Initialize memory monitoring
-	memConfig := &monitoring.MemoryConfig{
-		CollectionInterval: 30 * time.Second,
-	}
-	memMonitor := monitoring.NewMemoryMonitor(logger, memConfig)
-	memMonitor.Start()
-	defer memMonitor.Stop()
-
-	// Initialize database
-	dbConfig := &database.Config{
-		DatabasePath: "incident_management.db",
-	}
-	db, err := database.NewDB(dbConfig)
+	// Wire the database, services, handlers and routes
+	app, err := server.New(appCtx, server.ConfigFromEnv())
 	if err != nil {
-		logger.Fatal("Failed to initialize database", err)
+		logger.Fatal("Failed to initialize server", err)
 	}
-
-	// Initialize database schema
-	if err := db.InitializeDatabase(); err != nil {
-		logger.Fatal("Failed to initialize database schema", err)
+	// Closed after the HTTP server drains, so running jobs and the last usage events finish
+	defer app.Close()
+
+	expvar.Publish("memory_usage", expvar.Func(func() interface{} {
+		return app.MemoryMonitor.GetMemoryUsage()
+	}))
+	expvar.Publish("log_sinks", expvar.Func(func() interface{} {
+		return logger.SinkStats()
+	}))
+
+	srv := &http.Server{
+		Addr:    ":8080",
+		Handler: app.Router,
 	}
 
-	defer db.Close()
-
-	// Initialize file storage
-	fileStore := storage.NewFileStore("uploads")
-
-	// Initialize services
-	processingService := services.NewProcessingService(db.GetConnection(), fileStore)
-
-	// Initialize handlers
-	uploadHandler := handlers.NewUploadHandler(db.GetConnection(), fileStore, processingService)
-	analyticsHandler := handlers.NewAnalyticsHandler(db.GetConnection())
-
-	// Initialize Gin router with custom mode
-	gin.SetMode(gin.ReleaseMode) // Disable Gin's default logging
-	r := gin.New()
-
-	// Add middleware
-	r.Use(logging.RequestIDMiddleware())
-	r.Use(logging.LoggingMiddleware(logger))
-	r.Use(errors.RecoveryHandler())
-	r.Use(errors.ErrorHandler())
-
-	// Configure CORS
-	corsConfig := cors.DefaultConfig()
-	corsConfig.AllowOrigins = []string{"http://localhost:5173"} // Vite dev server
-	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID"}
-	r.Use(cors.New(corsConfig))
-
-	// Health check endpoint
-	r.GET("/health", func(c *gin.Context) {
-		health := monitoring.GetHealthStatus()
-		c.JSON(http.StatusOK, health)
-	})
-
-	// Monitoring endpoints
-	r.GET("/metrics", func(c *gin.Context) {
-		metrics, err := monitoring.ExportMetrics()
-		if err != nil {
-			errors.SendError(c, errors.InternalServer("Failed to export metrics"))
-			return
+	go func() {
+		logger.Info("Starting server on :8080")
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatal("Failed to start server", err)
 		}
-		c.Data(http.StatusOK, "application/json", metrics)
-	})
-
-	// Memory monitoring endpoints
-	r.GET("/memory", func(c *gin.Context) {
-		memUsage := memMonitor.GetMemoryUsage()
-		c.JSON(http.StatusOK, memUsage)
-	})
-
-	r.POST("/memory/gc", func(c *gin.Context) {
-		memMonitor.ForceGC()
-		c.JSON(http.StatusOK, gin.H{"message": "Garbage collection forced"})
-	})
-
-	// API routes
-	api := r.Group("/api")
-	{
-		// Upload endpoints
-		api.POST("/uploads", uploadHandler.UploadFile)
-		api.GET("/uploads", uploadHandler.GetUploads)
-		api.GET("/uploads/:id", uploadHandler.GetUpload)
-		api.POST("/uploads/:id/process", uploadHandler.ProcessUpload)
-		api.GET("/uploads/:id/status", uploadHandler.GetProcessingStatus)
+	}()
 
-		// Analytics endpoints
-		analytics := api.Group("/analytics")
-		{
-			// Timeline endpoints
-			analytics.GET("/timeline/daily", analyticsHandler.GetDailyTimeline)
-			analytics.GET("/timeline/weekly", analyticsHandler.GetWeeklyTimeline)
-			analytics.GET("/timeline/overview", analyticsHandler.GetTimelineOverview)
-
-			// Trend analysis endpoints
-			analytics.GET("/trends", analyticsHandler.GetTrendAnalysis)
-
-			// Metrics endpoints
-			analytics.GET("/metrics/daily", analyticsHandler.GetTicketsPerDayMetrics)
-			analytics.GET("/metrics/weekly", analyticsHandler.GetTicketsPerWeekMetrics)
-
-			// Priority and Application Analysis endpoints
-			analytics.GET("/priority", analyticsHandler.GetPriorityAnalysis)
-			analytics.GET("/applications", analyticsHandler.GetApplicationAnalysis)
-			analytics.GET("/resolution", analyticsHandler.GetResolutionAnalysis)
-			analytics.GET("/performance", analyticsHandler.GetPerformanceMetrics)
-
-			// Sentiment and Automation Analysis endpoints
-			analytics.GET("/sentiment", analyticsHandler.GetSentimentAnalysis)
-			analytics.GET("/automation", analyticsHandler.GetAutomationAnalysis)
-			analytics.GET("/automation/reporting", analyticsHandler.GetITProcessAutomationReporting)
-			analytics.GET("/summary", analyticsHandler.GetAnalyticsSummary)
-		}
-	}
+	<-appCtx.Done()
+	logger.Info("Shutting down server")
 
-	logger.Info("Starting server on :8080")
-	if err := r.Run(":8080"); err != nil {
-		logger.Fatal("Failed to start server", err)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Server shutdown failed", err)
 	}
 }
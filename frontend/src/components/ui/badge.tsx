@@ -51,6 +51,8 @@ export function StatusBadge({ status, className }: StatusBadgeProps) {
       case 'success':
         return 'success'
       case 'processing':
+      case 'queued':
+      case 'completed_with_errors':
       case 'in_progress':
       case 'pending':
         return 'warning'
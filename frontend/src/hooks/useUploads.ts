@@ -66,7 +66,7 @@ export function useUploadStatus(uploadId: string, enabled = true) {
     enabled: enabled && !!uploadId,
     refetchInterval: (data: ProcessingStatus | undefined) => {
       // Stop polling if processing is complete or failed
-      if (data?.status === 'completed' || data?.status === 'failed') {
+      if (data?.status === 'completed' || data?.status === 'completed_with_errors' || data?.status === 'failed') {
         return false
       }
       // Poll every 2 seconds while processing
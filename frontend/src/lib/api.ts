@@ -2,7 +2,7 @@ import axios, { AxiosError } from 'axios'
 import { Upload, DashboardData, TimelineData, PriorityAnalysis, ApplicationAnalysis, SentimentAnalysis, ResolutionMetrics, AutomationAnalysis } from '@/types'
 import { APIError } from '@/lib/errors'
 
-const API_BASE_URL = import.meta.env.VITE_API_URL || '/api'
+const API_BASE_URL = import.meta.env.VITE_API_URL || '/api/v1'
 
 export interface ValidationError {
   field: string
@@ -13,7 +13,7 @@ export interface ValidationError {
 
 export interface ProcessingStatus {
   upload_id: string
-  status: 'pending' | 'processing' | 'completed' | 'failed'
+  status: 'pending' | 'queued' | 'processing' | 'completed' | 'completed_with_errors' | 'failed'
   progress: number
   message?: string
   errors?: ValidationError[]
@@ -65,6 +65,16 @@ export function formatStatus(status: string) {
     .join(' ')
 }
 
+// Upload statuses: a completed upload may have had rows rejected, and a queued one is waiting
+// for a processing slot
+export function isUploadCompleted(status: string) {
+  return status === 'completed' || status === 'completed_with_errors'
+}
+
+export function isUploadInProgress(status: string) {
+  return status === 'queued' || status === 'processing'
+}
+
 // Priority formatting and colors
 export function getPriorityColor(priority: string) {
   const colors = {
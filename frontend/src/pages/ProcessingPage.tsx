@@ -18,7 +18,7 @@ import {
   BarChart3,
   FileText
 } from 'lucide-react'
-import { cn } from '@/lib/utils'
+import { cn, isUploadCompleted, isUploadInProgress } from '@/lib/utils'
 import { Upload } from '@/types'
 
 
@@ -70,15 +70,18 @@ interface UploadCardProps {
 function UploadCard({ upload, onAnalyze, isAnalyzing }: UploadCardProps) {
   const { error: statusError } = useUploadStatus(
     upload.id, 
-    upload.status === 'processing'
+    isUploadInProgress(upload.status)
   )
 
   const getStatusIcon = () => {
     switch (upload.status) {
       case 'completed':
         return <CheckCircle className="h-5 w-5 text-green-600" />
+      case 'completed_with_errors':
+        return <CheckCircle className="h-5 w-5 text-yellow-600" />
       case 'failed':
         return <AlertCircle className="h-5 w-5 text-red-600" />
+      case 'queued':
       case 'processing':
         return <LoadingSpinner className="h-5 w-5" />
       default:
@@ -89,9 +92,11 @@ function UploadCard({ upload, onAnalyze, isAnalyzing }: UploadCardProps) {
   const getStatusColor = () => {
     switch (upload.status) {
       case 'completed':
+      case 'completed_with_errors':
         return 'default'
       case 'failed':
         return 'destructive'
+      case 'queued':
       case 'processing':
         return 'secondary'
       default:
@@ -104,7 +109,7 @@ function UploadCard({ upload, onAnalyze, isAnalyzing }: UploadCardProps) {
   return (
     <Card className={cn(
       "transition-all duration-200",
-      upload.status === 'processing' && "ring-2 ring-blue-200"
+      isUploadInProgress(upload.status) && "ring-2 ring-blue-200"
     )}>
       <CardHeader className="pb-3">
         <div className="flex items-start justify-between">
@@ -225,7 +230,7 @@ function UploadCard({ upload, onAnalyze, isAnalyzing }: UploadCardProps) {
               </Button>
             )}
             
-            {upload.status === 'completed' && (
+            {isUploadCompleted(upload.status) && (
               <Button variant="outline" size="sm" asChild>
                 <a href="/dashboard">
                   <BarChart3 className="h-3 w-3 mr-1" />
@@ -377,14 +382,14 @@ export function ProcessingPage() {
               
               <div className="text-center">
                 <div className="text-2xl font-bold text-green-600">
-                  {uploads.filter(u => u.status === 'completed').length}
+                  {uploads.filter(u => isUploadCompleted(u.status)).length}
                 </div>
                 <div className="text-sm text-muted-foreground">Completed</div>
               </div>
               
               <div className="text-center">
                 <div className="text-2xl font-bold text-yellow-600">
-                  {uploads.filter(u => u.status === 'processing').length}
+                  {uploads.filter(u => isUploadInProgress(u.status)).length}
                 </div>
                 <div className="text-sm text-muted-foreground">Processing</div>
               </div>
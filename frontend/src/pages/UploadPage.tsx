@@ -7,7 +7,7 @@ import { Badge } from '@/components/ui/badge'
 import { LoadingSpinner } from '@/components/ui/loading-spinner'
 import { useUploadFile, useUploads } from '@/hooks/useUploads'
 import { Upload, Cloud, FileSpreadsheet, AlertCircle, CheckCircle, X, RefreshCw } from 'lucide-react'
-import { cn } from '@/lib/utils'
+import { cn, isUploadCompleted, isUploadInProgress } from '@/lib/utils'
 
 interface FileUploadState {
   file: File | null
@@ -362,11 +362,11 @@ export function UploadPage() {
                     </div>
                     <Badge
                       variant={
-                        upload.status === 'completed'
+                        isUploadCompleted(upload.status)
                           ? 'default'
                           : upload.status === 'failed'
                           ? 'destructive'
-                          : upload.status === 'processing'
+                          : isUploadInProgress(upload.status)
                           ? 'secondary'
                           : 'outline'
                       }
@@ -2,7 +2,7 @@ export interface Upload {
   id: string
   filename: string
   original_filename: string
-  status: 'uploaded' | 'processing' | 'completed' | 'failed'
+  status: 'uploaded' | 'queued' | 'processing' | 'completed' | 'completed_with_errors' | 'failed'
   record_count: number
   processed_count: number
   error_count: number